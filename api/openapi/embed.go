@@ -0,0 +1,8 @@
+// Package openapi embeds the OpenAPI annotations of the services, merged
+// with their routes into the documents they serve
+package openapi
+
+import "embed"
+
+//go:embed *.yaml
+var FS embed.FS
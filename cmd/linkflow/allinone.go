@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	authserver "github.com/linkflow-go/internal/auth/server"
+	credentialserver "github.com/linkflow-go/internal/credential/server"
+	executionserver "github.com/linkflow-go/internal/execution/server"
+	executorserver "github.com/linkflow-go/internal/executor/server"
+	gatewayserver "github.com/linkflow-go/internal/gateway/server"
+	workflowserver "github.com/linkflow-go/internal/workflow/server"
+	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/database"
+	"github.com/linkflow-go/pkg/discovery"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/logger"
+)
+
+// server is what the all-in-one mode needs of a service
+type server interface {
+	Start() error
+	Shutdown(ctx context.Context) error
+}
+
+// component is a service started by the all-in-one mode, on the port it has
+// in docker-compose
+type component struct {
+	name    string
+	route   string // name of the service in the gateway
+	port    int
+	newFunc func(cfg *config.Config, log logger.Logger) (server, error)
+}
+
+// components start in order, the gateway last so its readiness reflects the
+// services behind it
+var components = []component{
+	{"auth-service", "auth", 8001, func(cfg *config.Config, log logger.Logger) (server, error) {
+		return authserver.New(cfg, log)
+	}},
+	{"workflow-service", "workflow", 8003, func(cfg *config.Config, log logger.Logger) (server, error) {
+		return workflowserver.New(cfg, log)
+	}},
+	{"execution-service", "execution", 8004, func(cfg *config.Config, log logger.Logger) (server, error) {
+		return executionserver.New(cfg, log)
+	}},
+	{"credential-service", "credential", 8007, func(cfg *config.Config, log logger.Logger) (server, error) {
+		return credentialserver.New(cfg, log)
+	}},
+	{"executor-service", "", 8017, func(cfg *config.Config, log logger.Logger) (server, error) {
+		return executorserver.New(cfg, log)
+	}},
+	{"graphql-gateway", "", 8000, func(cfg *config.Config, log logger.Logger) (server, error) {
+		return gatewayserver.New(cfg, log)
+	}},
+}
+
+// running is a started component
+type running struct {
+	server    server
+	logger    logger.Logger
+	announcer *discovery.Announcer
+}
+
+// runAllInOne runs the components in this process, with SQLite in dataDir
+// for Postgres, an embedded Redis and an in-process event bus for Kafka
+func runAllInOne(dataDir string) int {
+	redis := miniredis.NewMiniRedis()
+	if err := redis.StartAddr("127.0.0.1:0"); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start embedded Redis: %v\n", err)
+		return 1
+	}
+	defer redis.Close()
+
+	configs := make([]*config.Config, len(components))
+	for i, c := range components {
+		cfg, err := componentConfig(c, dataDir, redis)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid config of %s: %v\n", c.name, err)
+			return 1
+		}
+		configs[i] = cfg
+	}
+
+	if err := createSchema(configs[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create the database schema: %v\n", err)
+		return 1
+	}
+
+	var started []running
+	defer func() {
+		// Stop in reverse order, the gateway first
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		for i := len(started) - 1; i >= 0; i-- {
+			r := started[i]
+			r.announcer.Stop()
+			if err := r.server.Shutdown(ctx); err != nil {
+				r.logger.Error("Server forced to shutdown", "error", err)
+			}
+			logger.Sync(r.logger)
+		}
+	}()
+
+	for i, c := range components {
+		cfg := configs[i]
+		log := logger.New(cfg.Logger.ToLoggerConfig())
+
+		srv, err := c.newFunc(cfg, log)
+		if err != nil {
+			log.Error("Failed to create server", "error", err)
+			logger.Sync(log)
+			return 1
+		}
+
+		go func() {
+			log.Info("Starting "+c.name, "port", cfg.Server.Port)
+			if err := srv.Start(); err != nil {
+				log.Fatal("Failed to start server", "error", err)
+			}
+		}()
+
+		announcer := discovery.NewAnnouncer(cfg, c.name, log)
+		announcer.Start()
+
+		started = append(started, running{server: srv, logger: log, announcer: announcer})
+	}
+
+	fmt.Fprintf(os.Stderr, "LinkFlow is running in all-in-one mode, gateway on :%d, data in %s\n",
+		configs[len(configs)-1].Server.Port, dataDir)
+
+	// Wait for interrupt signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	fmt.Fprintln(os.Stderr, "Shutting down...")
+	return 0
+}
+
+// componentConfig loads the config of a component as its own binary would
+// and points its dependencies at the embedded substitutes
+func componentConfig(c component, dataDir string, redis *miniredis.Miniredis) (*config.Config, error) {
+	cfg, err := config.Load(c.name)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Server.Port = c.port
+	cfg.Server.AdminPort = 0 // admin ports of the services would collide
+
+	cfg.Database.Driver = database.DriverSQLite
+	cfg.Database.Path = filepath.Join(dataDir, "linkflow.db")
+
+	addr := redis.Server().Addr()
+	cfg.Redis.Host = addr.IP.String()
+	cfg.Redis.Port = addr.Port
+	cfg.Redis.Password = ""
+	cfg.Redis.DB = 0
+
+	cfg.Kafka.Backend = events.BackendMemory
+
+	// No collector runs next to a developer machine by default
+	cfg.Telemetry.Enabled = false
+
+	// Route the gateway to the components, the services left out are not
+	// reported as down
+	cfg.Gateway.Services = map[string]string{
+		"schedule": "", "webhook": "", "variable": "", "analytics": "", "node": "",
+	}
+	for _, other := range components {
+		if other.route != "" {
+			cfg.Gateway.Services[other.route] = fmt.Sprintf("http://localhost:%d", other.port)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// createSchema creates the tables that do not exist yet
+func createSchema(cfg *config.Config) error {
+	db, err := database.New(cfg.Database.ToDatabaseConfig())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return migrateSQLite(db)
+}
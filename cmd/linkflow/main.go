@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/linkflow-go/internal/workflow/adapters/templates"
+	"github.com/linkflow-go/migrations"
+	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/database"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/linkflow-go/pkg/tenant"
+	"github.com/redis/go-redis/v9"
+)
+
+const usage = `Usage: linkflow <command> [flags]
+
+Commands:
+  serve --all-in-one    run gateway, auth, workflow, execution, credential
+                        and executor in one process for local development
+  migrate [--check]     create the tables of the SQLite database.path, with
+                        --check only list the tables and columns the models
+                        miss in the configured database, Postgres or SQLite
+  soak --target URL     continuously create, execute and delete disposable
+                        workflows against a staging instance, exposing error
+                        and latency metrics
+  redis-namespace [--tenant ID] [--dry-run]
+                        move the Redis keys written before tenant namespacing
+                        into the namespace of a tenant, default by default
+  webhook-relay --target URL --webhook ID [--forward URL]
+                        receive copies of the requests a webhook accepts and
+                        replay them to a local URL, to develop a workflow
+                        against real payloads
+  template-keys         print a new Ed25519 key pair signing template
+                        bundles, the public key to trust elsewhere
+  lint [--min-schedule-interval D] FILE...
+                        validate and lint workflow documents, JSON or YAML,
+                        without calling the API
+  deploy --target URL [--activate] FILE...
+                        lint then create or update the workflows of the
+                        documents, a new version for those with an id
+  run --target URL [--input FILE] [--environment ENV] ID
+                        execute a workflow, follow it until it ends and
+                        print its output, failing unless it completed
+  export --target URL [--output FILE] ID
+                        write a workflow as the document deploy reads
+
+The deploy, run and export commands authenticate with the API key or access
+token in LINKFLOW_TOKEN.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "serve":
+		os.Exit(serve(os.Args[2:]))
+	case "migrate":
+		os.Exit(migrate(os.Args[2:]))
+	case "soak":
+		os.Exit(soak(os.Args[2:]))
+	case "redis-namespace":
+		os.Exit(redisNamespace(os.Args[2:]))
+	case "webhook-relay":
+		os.Exit(webhookRelay(os.Args[2:]))
+	case "template-keys":
+		os.Exit(templateKeys())
+	case "lint":
+		os.Exit(lint(os.Args[2:]))
+	case "deploy":
+		os.Exit(deploy(os.Args[2:]))
+	case "run":
+		os.Exit(run(os.Args[2:]))
+	case "export":
+		os.Exit(export(os.Args[2:]))
+	case "-h", "--help", "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+func serve(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	allInOne := flags.Bool("all-in-one", false, "run the core services in this process with embedded dependencies")
+	dataDir := flags.String("data-dir", ".linkflow", "directory of the SQLite databases")
+	flags.Parse(args)
+
+	if !*allInOne {
+		fmt.Fprintln(os.Stderr, "serve needs --all-in-one, run the services of cmd/services to deploy them separately")
+		return 2
+	}
+	return runAllInOne(*dataDir)
+}
+
+func migrate(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	check := flags.Bool("check", false, "only list the tables and columns the models miss")
+	flags.Parse(args)
+
+	cfg, err := config.Load("linkflow")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config: %v\n", err)
+		return 1
+	}
+
+	db, err := database.New(cfg.Database.ToDatabaseConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	if !*check {
+		if !db.IsSQLite() {
+			fmt.Fprintln(os.Stderr, "Postgres is migrated with the SQL migrations, run `make migrate-up`")
+			return 2
+		}
+		if err := migrateSQLite(db); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create the database schema: %v\n", err)
+			return 1
+		}
+		fmt.Printf("SQLite database %s is at schema version %d\n", cfg.Database.Path, migrations.Latest())
+	}
+
+	missing, err := checkSchema(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to check the database schema: %v\n", err)
+		return 1
+	}
+	for _, m := range missing {
+		fmt.Printf("missing %s\n", m)
+	}
+	if len(missing) > 0 {
+		return 1
+	}
+	fmt.Printf("all %d models can be stored\n", len(models))
+	return 0
+}
+
+func soak(args []string) int {
+	flags := flag.NewFlagSet("soak", flag.ExitOnError)
+	target := flags.String("target", "", "base URL of the API gateway, or of the workflow service")
+	executionTarget := flags.String("execution-target", "", "base URL of the execution service, the target unless set")
+	rate := flags.Float64("rate", 1, "workflows started per second")
+	concurrency := flags.Int("concurrency", 20, "most workflows in flight, ticks beyond it are skipped")
+	duration := flags.Duration("duration", 0, "how long to run, until interrupted when 0")
+	requestTimeout := flags.Duration("request-timeout", 10*time.Second, "timeout of each API call")
+	executeTimeout := flags.Duration("execute-timeout", time.Minute, "how long an execution may take to finish")
+	metricsAddr := flags.String("metrics-addr", ":9464", "address serving the soak metrics on /metrics, none when empty")
+	maxErrorRate := flags.Float64("max-error-rate", 0.01, "share of failed cycles above which the run fails")
+	flags.Parse(args)
+
+	if *target == "" {
+		fmt.Fprintln(os.Stderr, "soak needs --target, the staging instance to load")
+		return 2
+	}
+	if *rate <= 0 || *concurrency <= 0 {
+		fmt.Fprintln(os.Stderr, "--rate and --concurrency must be positive")
+		return 2
+	}
+	if *executionTarget == "" {
+		*executionTarget = *target
+	}
+
+	return runSoak(soakConfig{
+		target:          strings.TrimSuffix(*target, "/"),
+		executionTarget: strings.TrimSuffix(*executionTarget, "/"),
+		token:           os.Getenv("LINKFLOW_SOAK_TOKEN"),
+		rate:            *rate,
+		concurrency:     *concurrency,
+		duration:        *duration,
+		requestTimeout:  *requestTimeout,
+		executeTimeout:  *executeTimeout,
+		metricsAddr:     *metricsAddr,
+		maxErrorRate:    *maxErrorRate,
+	})
+}
+
+func redisNamespace(args []string) int {
+	flags := flag.NewFlagSet("redis-namespace", flag.ExitOnError)
+	tenantID := flags.String("tenant", tenant.DefaultTenant, "tenant owning the keys written before namespacing")
+	dryRun := flags.Bool("dry-run", false, "only count the keys to move")
+	flags.Parse(args)
+
+	if !tenant.Valid(*tenantID) {
+		fmt.Fprintf(os.Stderr, "invalid tenant %q\n", *tenantID)
+		return 2
+	}
+
+	cfg, err := config.Load("linkflow")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config: %v\n", err)
+		return 1
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	defer client.Close()
+
+	moved, err := rediskey.Namespace(context.Background(), client, *tenantID, *dryRun)
+	for _, m := range moved {
+		if *dryRun {
+			fmt.Printf("%s: %d keys to move\n", m.Family, m.Moved)
+			continue
+		}
+		fmt.Printf("%s: moved %d keys, dropped %d stale keys\n", m.Family, m.Moved, m.Dropped)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to namespace Redis keys: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func templateKeys() int {
+	signingKey, publicKey, keyID, err := templates.GenerateBundleKey()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate key pair: %v\n", err)
+		return 1
+	}
+	fmt.Printf("TEMPLATE_SIGNING_KEY=%s\n", signingKey)
+	fmt.Printf("# key ID %s, trust its bundles elsewhere with\n", keyID)
+	fmt.Printf("TEMPLATE_TRUSTED_KEYS=%s\n", publicKey)
+	return 0
+}
+
+func webhookRelay(args []string) int {
+	flags := flag.NewFlagSet("webhook-relay", flag.ExitOnError)
+	target := flags.String("target", "", "base URL of the webhooks API, the ingress or the webhook service")
+	webhookID := flags.String("webhook", "", "ID of the webhook to relay")
+	forward := flags.String("forward", "", "local URL each request is replayed to, only printed when empty")
+	userID := flags.String("user-id", os.Getenv("LINKFLOW_USER_ID"), "owner of the webhook, sent as X-User-ID to the webhook service directly")
+	flags.Parse(args)
+
+	if *target == "" || *webhookID == "" {
+		fmt.Fprintln(os.Stderr, "webhook-relay needs --target and --webhook")
+		return 2
+	}
+
+	return runRelay(relayConfig{
+		target:    strings.TrimSuffix(*target, "/"),
+		webhookID: *webhookID,
+		forward:   *forward,
+		token:     os.Getenv("LINKFLOW_RELAY_TOKEN"),
+		userID:    *userID,
+	})
+}
+
+// apiFlags defines the flags of the commands calling the API
+func apiFlags(flags *flag.FlagSet, service string) (target, userID *string) {
+	target = flags.String("target", "", "base URL of the API, the ingress or the "+service+" service")
+	userID = flags.String("user-id", os.Getenv("LINKFLOW_USER_ID"), "caller, sent as X-User-ID to the "+service+" service directly")
+	return target, userID
+}
+
+func newAPIClient(userID string) *apiClient {
+	return &apiClient{
+		client: &http.Client{Timeout: 30 * time.Second},
+		token:  os.Getenv("LINKFLOW_TOKEN"),
+		userID: userID,
+	}
+}
+
+func lint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	minInterval := flags.Duration("min-schedule-interval", workflow.DefaultMinScheduleInterval,
+		"shortest interval allowed between the runs of a cron trigger")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "lint needs the workflow documents to validate")
+		return 2
+	}
+	return runLint(flags.Args(), workflow.NewDefaultLinter(workflow.LintOptions{
+		MinScheduleInterval: *minInterval,
+	}))
+}
+
+func deploy(args []string) int {
+	flags := flag.NewFlagSet("deploy", flag.ExitOnError)
+	target, userID := apiFlags(flags, "workflow")
+	activate := flags.Bool("activate", false, "activate the workflows once deployed")
+	flags.Parse(args)
+
+	if *target == "" || flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "deploy needs --target and the workflow documents to deploy")
+		return 2
+	}
+
+	return runDeploy(deployConfig{
+		target:   strings.TrimSuffix(*target, "/"),
+		api:      newAPIClient(*userID),
+		activate: *activate,
+		paths:    flags.Args(),
+	})
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	target, userID := apiFlags(flags, "execution")
+	inputFile := flags.String("input", "", "JSON file of the input data of the execution")
+	environment := flags.String("environment", "", "environment to run in, by ID or name, the default one unless set")
+	debug := flags.Bool("debug", false, "log the run verbosely")
+	timeout := flags.Duration("timeout", 10*time.Minute, "how long to follow the execution, without limit when 0")
+	flags.Parse(args)
+
+	if *target == "" || flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "run needs --target and the ID of the workflow to execute")
+		return 2
+	}
+
+	input := map[string]interface{}{}
+	if *inputFile != "" {
+		data, err := os.ReadFile(*inputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read input: %v\n", err)
+			return 2
+		}
+		if err := json.Unmarshal(data, &input); err != nil {
+			fmt.Fprintf(os.Stderr, "input must be a JSON object: %v\n", err)
+			return 2
+		}
+	}
+
+	return runExecution(runConfig{
+		executionTarget: strings.TrimSuffix(*target, "/"),
+		api:             newAPIClient(*userID),
+		workflowID:      flags.Arg(0),
+		environment:     *environment,
+		input:           input,
+		debug:           *debug,
+		timeout:         *timeout,
+	})
+}
+
+func export(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	target, userID := apiFlags(flags, "workflow")
+	output := flags.String("output", "", "file to write, standard output unless set")
+	format := flags.String("format", "", "json or yaml, from the extension of --output unless set")
+	flags.Parse(args)
+
+	if *target == "" || flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "export needs --target and the ID of the workflow to export")
+		return 2
+	}
+	if *format == "" {
+		*format = documentFormat(*output)
+	}
+	if *format != workflow.GitFormatJSON && *format != workflow.GitFormatYAML {
+		fmt.Fprintln(os.Stderr, "--format must be json or yaml")
+		return 2
+	}
+
+	return runExport(exportConfig{
+		target:     strings.TrimSuffix(*target, "/"),
+		api:        newAPIClient(*userID),
+		workflowID: flags.Arg(0),
+		output:     *output,
+		format:     *format,
+	})
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/linkflow-go/pkg/contracts/webhook"
+)
+
+const (
+	// relayMaxBackoff bounds the wait before reconnecting a dropped relay
+	relayMaxBackoff = 30 * time.Second
+	relayPongWait   = 90 * time.Second
+)
+
+// errRelayRefused is returned when the platform refuses the relay, which
+// reconnecting does not change
+var errRelayRefused = errors.New("relay refused")
+
+// relayConfig configures a webhook relay
+type relayConfig struct {
+	// target serves the webhooks API, the API gateway ingress or the
+	// webhook service
+	target    string
+	webhookID string
+	// forward receives a replay of each request, they are only printed
+	// when empty
+	forward string
+	token   string
+	userID  string
+}
+
+// runRelay receives the requests of a webhook until interrupted, replaying
+// them to the forward URL. Dropped connections are opened again, requests
+// the webhook accepted meanwhile are not relayed.
+func runRelay(cfg relayConfig) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	backoff := time.Second
+	for {
+		connected, err := relayOnce(ctx, cfg, client)
+		if ctx.Err() != nil {
+			return 0
+		}
+		if errors.Is(err, errRelayRefused) {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		if connected {
+			backoff = time.Second
+		}
+		fmt.Fprintf(os.Stderr, "Relay disconnected: %v, reconnecting in %s\n", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, relayMaxBackoff)
+	}
+}
+
+// relayOnce relays requests over one connection until it drops, reporting
+// whether it was opened
+func relayOnce(ctx context.Context, cfg relayConfig, client *http.Client) (bool, error) {
+	header := http.Header{}
+	if cfg.token != "" {
+		header.Set("Authorization", "Bearer "+cfg.token)
+	}
+	if cfg.userID != "" {
+		header.Set("X-User-ID", cfg.userID)
+	}
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, relayURL(cfg), header)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= http.StatusBadRequest && resp.StatusCode < http.StatusInternalServerError {
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			return false, fmt.Errorf("%w: %d %s", errRelayRefused, resp.StatusCode, strings.TrimSpace(string(msg)))
+		}
+		return false, err
+	}
+	defer conn.Close()
+
+	// Closing the connection ends the read below when interrupted
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(relayPongWait))
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(relayPongWait))
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(10*time.Second))
+	})
+
+	fmt.Fprintf(os.Stderr, "Relaying the requests of webhook %s", cfg.webhookID)
+	if cfg.forward != "" {
+		fmt.Fprintf(os.Stderr, " to %s", cfg.forward)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	for {
+		var req webhook.RelayedRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return true, err
+		}
+		conn.SetReadDeadline(time.Now().Add(relayPongWait))
+
+		line := fmt.Sprintf("%s %s %s %s %d bytes",
+			req.ReceivedAt.Local().Format(time.TimeOnly), req.ID, req.Method, req.Path, len(req.Body))
+		if cfg.forward == "" {
+			fmt.Println(line)
+			continue
+		}
+		status, err := replay(ctx, client, cfg.forward, &req)
+		if err != nil {
+			fmt.Printf("%s -> %v\n", line, err)
+			continue
+		}
+		fmt.Printf("%s -> %s\n", line, status)
+	}
+}
+
+// relayURL is the WebSocket URL of the relay of the webhook
+func relayURL(cfg relayConfig) string {
+	target := cfg.target
+	switch {
+	case strings.HasPrefix(target, "https://"):
+		target = "wss://" + strings.TrimPrefix(target, "https://")
+	case strings.HasPrefix(target, "http://"):
+		target = "ws://" + strings.TrimPrefix(target, "http://")
+	}
+	return target + "/api/v1/webhooks/" + url.PathEscape(cfg.webhookID) + "/relay"
+}
+
+// replay sends a relayed request to the forward URL with its method,
+// headers, query and body, returning the status of the answer
+func replay(ctx context.Context, client *http.Client, forward string, relayed *webhook.RelayedRequest) (string, error) {
+	u, err := url.Parse(forward)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	for name, value := range relayed.QueryParams {
+		query.Set(name, value)
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, relayed.Method, u.String(), strings.NewReader(relayed.Body))
+	if err != nil {
+		return "", err
+	}
+	for name, value := range relayed.Headers {
+		switch http.CanonicalHeaderKey(name) {
+		case "Host", "Content-Length", "Connection", "Accept-Encoding":
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	if relayed.ContentType != "" {
+		req.Header.Set("Content-Type", relayed.ContentType)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.Status, nil
+}
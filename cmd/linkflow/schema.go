@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+
+	auditdomain "github.com/linkflow-go/internal/audit/domain"
+	"github.com/linkflow-go/internal/auth/adapters/apikey"
+	billingdomain "github.com/linkflow-go/internal/billing/domain"
+	execrepo "github.com/linkflow-go/internal/execution/adapters/db/repository"
+	nodedomain "github.com/linkflow-go/internal/node/domain"
+	variabledomain "github.com/linkflow-go/internal/variable/domain"
+	"github.com/linkflow-go/internal/workflow/adapters/templates"
+	"github.com/linkflow-go/migrations"
+	"github.com/linkflow-go/pkg/contracts/credential"
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/schedule"
+	"github.com/linkflow-go/pkg/contracts/user"
+	"github.com/linkflow-go/pkg/contracts/webhook"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/database"
+	"github.com/linkflow-go/pkg/outbox"
+	"gorm.io/gorm"
+)
+
+// models are the tables of the services that run on SQLite, those whose
+// schema is in database.Schemas. SQL migrations target Postgres, so the
+// SQLite tables are created from the models instead.
+var models = []interface{}{
+	&user.User{}, &user.Role{}, &user.Permission{}, &user.Session{}, &user.OAuthToken{},
+	&apikey.APIKey{},
+	&workflow.Workflow{}, &workflow.WorkflowVersion{}, &workflow.WorkflowTrigger{},
+	&workflow.Canary{}, &workflow.Shadow{}, &workflow.ShadowComparison{},
+	&workflow.WorkspacePolicy{}, &workflow.WorkflowVariable{}, &workflow.Environment{}, &workflow.Deployment{}, &workflow.Budget{}, &workflow.SLA{}, &workflow.GitConnection{}, &workflow.ManagedWorkflow{}, &workflow.NodeOutputSchema{}, &workflow.WorkflowChange{}, &workflow.TriggerFixture{}, &workflow.WorkflowTestCase{}, &workflow.WorkflowTestRun{},
+	&templates.Template{}, &templates.Review{}, &templates.Use{}, &templates.TemplateVersion{},
+	&execution.Execution{}, &execution.NodeExecution{}, &execution.EvidenceBundle{}, &execution.Backfill{}, &execution.KPI{},
+	&execution.ExecutionCost{}, &execution.NodeTypeCost{}, &execution.CostRollup{}, &execution.NodeTypeCostRollup{},
+	&execution.SampledOut{},
+	&execrepo.StateTransition{},
+	&credential.Credential{},
+	&schedule.Schedule{}, &schedule.ScheduleExecution{},
+	&webhook.Webhook{}, &webhook.WebhookExecution{},
+	&variabledomain.Variable{},
+	&nodedomain.NodeType{},
+	&auditdomain.DataExport{}, &auditdomain.StoredEvent{},
+	&billingdomain.Plan{}, &billingdomain.Subscription{}, &billingdomain.Invoice{},
+	&billingdomain.PaymentMethod{}, &billingdomain.Usage{}, &billingdomain.Coupon{},
+	&billingdomain.ExecutionCost{},
+	&outbox.Message{},
+}
+
+// schemaMigration is the state table of golang-migrate, kept on SQLite so
+// doctor compares the schema with the build as it does on Postgres
+type schemaMigration struct {
+	Version int64 `gorm:"primaryKey;autoIncrement:false"`
+	Dirty   bool  `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrateSQLite creates the missing tables of the models and records the
+// schema as at the latest migration
+func migrateSQLite(db *database.DB) error {
+	if err := db.Migrate(models...); err != nil {
+		return err
+	}
+	if err := db.Migrate(&schemaMigration{}); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&schemaMigration{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{Version: int64(migrations.Latest())}).Error
+	})
+}
+
+// checkSchema lists the tables and columns of the models missing from the
+// database, empty when every model can be stored
+func checkSchema(db *database.DB) ([]string, error) {
+	migrator := db.Migrator()
+
+	var missing []string
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db.DB}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+
+		if !migrator.HasTable(model) {
+			missing = append(missing, fmt.Sprintf("table %s", stmt.Schema.Table))
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if !migrator.HasColumn(model, field.DBName) {
+				missing = append(missing, fmt.Sprintf("column %s.%s", stmt.Schema.Table, field.DBName))
+			}
+		}
+	}
+	return missing, nil
+}
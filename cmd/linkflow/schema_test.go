@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linkflow-go/migrations"
+	"github.com/linkflow-go/pkg/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// postgresEnv names a Postgres database the tests migrate up, check and
+// migrate back down. It must be a database of its own, the tests run the
+// SQL migrations against Postgres only when it is set.
+const postgresEnv = "LINKFLOW_TEST_POSTGRES_DSN"
+
+// sampleTime is stored in every time column, in whole seconds so every
+// database keeps it as it is
+var sampleTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func openSQLite(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.New(database.Config{
+		Driver:       database.DriverSQLite,
+		Path:         filepath.Join(t.TempDir(), "linkflow.db"),
+		MaxOpenConns: 1,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// openPostgres migrates the database of postgresEnv up with the SQL
+// migrations, and back down once the test is over
+func openPostgres(t *testing.T) *database.DB {
+	t.Helper()
+	dsn := os.Getenv(postgresEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresEnv)
+	}
+
+	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	db := &database.DB{DB: gormDB}
+	t.Cleanup(func() { db.Close() })
+
+	up := sqlMigrations(t, ".up.sql")
+	t.Cleanup(func() {
+		down := sqlMigrations(t, ".down.sql")
+		for i := len(down) - 1; i >= 0; i-- {
+			runMigration(t, db, down[i])
+		}
+	})
+	for _, name := range up {
+		runMigration(t, db, name)
+	}
+	return db
+}
+
+// sqlMigrations lists the migrations of a direction by version
+func sqlMigrations(t *testing.T, suffix string) []string {
+	t.Helper()
+	names, err := fs.Glob(migrations.FS, "*"+suffix)
+	if err != nil {
+		t.Fatalf("list migrations: %v", err)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func runMigration(t *testing.T, db *database.DB, name string) {
+	t.Helper()
+	script, err := migrations.FS.ReadFile(name)
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	// Statements without arguments run as one script
+	if err := db.Exec(string(script)).Error; err != nil {
+		t.Fatalf("migration %s: %v", name, err)
+	}
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	db := openSQLite(t)
+	if err := migrateSQLite(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	// Migrating again leaves the schema as it is
+	if err := migrateSQLite(db); err != nil {
+		t.Fatalf("migrate again: %v", err)
+	}
+
+	var recorded []schemaMigration
+	if err := db.Find(&recorded).Error; err != nil {
+		t.Fatalf("read schema version: %v", err)
+	}
+	if len(recorded) != 1 || recorded[0].Version != int64(migrations.Latest()) || recorded[0].Dirty {
+		t.Errorf("schema version recorded as %+v, want %d", recorded, migrations.Latest())
+	}
+
+	checkModels(t, db)
+}
+
+func TestMigratePostgres(t *testing.T) {
+	checkModels(t, openPostgres(t))
+}
+
+// checkModels checks every model has its table and columns and reads back
+// as it was stored
+func checkModels(t *testing.T, db *database.DB) {
+	t.Helper()
+	missing, err := checkSchema(db)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	for _, m := range missing {
+		t.Errorf("missing %s", m)
+	}
+
+	for _, model := range models {
+		model := model
+		name := reflect.TypeOf(model).Elem().String()
+		t.Run(name, func(t *testing.T) {
+			roundTrip(t, db, model)
+		})
+	}
+}
+
+// roundTrip stores a value of a model with every column set and reads it
+// back
+func roundTrip(t *testing.T, db *database.DB, model interface{}) {
+	ctx := context.Background()
+	stmt := &gorm.Statement{DB: db.DB}
+	if err := stmt.Parse(model); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	stored := reflect.New(stmt.Schema.ModelType)
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || !field.Creatable {
+			continue
+		}
+		if value, ok := sampleValue(field.FieldType, field.DefaultValue, field.Size, field.Serializer != nil); ok {
+			field.ReflectValueOf(ctx, stored.Elem()).Set(value)
+		}
+	}
+	if err := db.DB.Create(stored.Interface()).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	keys := map[string]interface{}{}
+	for _, field := range stmt.Schema.PrimaryFields {
+		value, _ := field.ValueOf(ctx, stored.Elem())
+		keys[field.DBName] = value
+	}
+	read := reflect.New(stmt.Schema.ModelType)
+	if err := db.Where(keys).Take(read.Interface()).Error; err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || !field.Readable {
+			continue
+		}
+		want := field.ReflectValueOf(ctx, stored.Elem()).Interface()
+		got := field.ReflectValueOf(ctx, read.Elem()).Interface()
+		if !sameValue(want, got) {
+			t.Errorf("column %s reads back as %v, stored %v", field.DBName, got, want)
+		}
+	}
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	valuerType    = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// sampleValue returns a value of type to store in a column, not ok for
+// types left at their zero value
+func sampleValue(typ reflect.Type, defaultValue string, size int, serialized bool) (reflect.Value, bool) {
+	if typ.Kind() == reflect.Ptr {
+		elem, ok := sampleValue(typ.Elem(), defaultValue, size, serialized)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, true
+	}
+
+	switch {
+	case typ == deletedAtType:
+		// A deleted row is not read back
+		return reflect.Value{}, false
+	case typ == timeType:
+		return reflect.ValueOf(sampleTime), true
+	case serialized:
+		return sampleSerialized(typ)
+	case typ.Implements(valuerType) || reflect.PtrTo(typ).Implements(valuerType):
+		return reflect.Value{}, false
+	}
+
+	var sample interface{}
+	switch typ.Kind() {
+	case reflect.String:
+		sample = sampleString(defaultValue, size)
+	case reflect.Bool:
+		sample = true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sample = int64(7)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		sample = uint64(7)
+	case reflect.Float32, reflect.Float64:
+		sample = 1.5
+	case reflect.Slice:
+		if typ.Elem().Kind() != reflect.Uint8 {
+			return reflect.Value{}, false
+		}
+		sample = []byte("sample")
+	default:
+		return reflect.Value{}, false
+	}
+	return reflect.ValueOf(sample).Convert(typ), true
+}
+
+// sampleString is the default of a column when it has one, so it passes
+// the checks of enumerated columns, or an ID fitting the column
+func sampleString(defaultValue string, size int) string {
+	if value := strings.Trim(defaultValue, "'"); value != "" && !strings.Contains(value, "(") {
+		return value
+	}
+	id := "00000000-0000-4000-8000-000000000007"
+	if size > 0 && size < len(id) {
+		return id[len(id)-size:]
+	}
+	return id
+}
+
+// sampleSerialized returns a value of a column stored as JSON
+func sampleSerialized(typ reflect.Type) (reflect.Value, bool) {
+	value := reflect.New(typ)
+	var sample string
+	switch typ.Kind() {
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		sample = `{}`
+		if elem := typ.Elem().Kind(); elem == reflect.String || elem == reflect.Interface {
+			sample = `{"sample": "value"}`
+		}
+	case reflect.Slice:
+		sample = `[]`
+		if elem := typ.Elem().Kind(); elem == reflect.String || elem == reflect.Interface {
+			sample = `["sample"]`
+		}
+	default:
+		return reflect.Value{}, false
+	}
+	if err := json.Unmarshal([]byte(sample), value.Interface()); err != nil {
+		return reflect.Value{}, false
+	}
+	return value.Elem(), true
+}
+
+// sameValue compares a stored and read value, times by the instant and
+// the others by their JSON form
+func sameValue(want, got interface{}) bool {
+	switch w := want.(type) {
+	case time.Time:
+		g, ok := got.(time.Time)
+		return ok && w.Equal(g)
+	case *time.Time:
+		g, ok := got.(*time.Time)
+		if !ok || (w == nil) != (g == nil) {
+			return false
+		}
+		return w == nil || w.Equal(*g)
+	}
+
+	wantJSON, errWant := json.Marshal(want)
+	gotJSON, errGot := json.Marshal(got)
+	if err := errors.Join(errWant, errGot); err != nil {
+		return reflect.DeepEqual(want, got)
+	}
+	return string(wantJSON) == string(gotJSON)
+}
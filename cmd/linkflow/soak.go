@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// soakTag marks the disposable workflows of the soak test, so leftovers of
+// an interrupted run can be found and deleted
+const soakTag = "soak"
+
+// soakPollInterval is how often a cycle checks whether its execution
+// finished
+const soakPollInterval = 250 * time.Millisecond
+
+// Operations of a soak cycle, the operation label of the soak metrics
+const (
+	opCreate   = "create"
+	opActivate = "activate"
+	opExecute  = "execute"
+	opWait     = "wait"
+	opDelete   = "delete"
+	opCycle    = "cycle"
+)
+
+// soakConfig configures the synthetic workload
+type soakConfig struct {
+	// target serves the workflow API, executionTarget the execution API,
+	// both are the same behind the API gateway
+	target          string
+	executionTarget string
+	token           string
+	rate            float64
+	concurrency     int
+	duration        time.Duration
+	requestTimeout  time.Duration
+	executeTimeout  time.Duration
+	metricsAddr     string
+	maxErrorRate    float64
+}
+
+// soakRunner runs cycles that create, activate, execute, await and delete a
+// disposable workflow
+type soakRunner struct {
+	cfg     soakConfig
+	api     *apiClient
+	summary *soakSummary
+}
+
+// runSoak generates the workload until the duration elapses or the process
+// is interrupted, then waits for the running cycles. It fails when the
+// share of failed cycles exceeds maxErrorRate.
+func runSoak(cfg soakConfig) int {
+	runner := &soakRunner{
+		cfg:     cfg,
+		api:     &apiClient{client: &http.Client{Timeout: cfg.requestTimeout}, token: cfg.token},
+		summary: newSoakSummary(),
+	}
+
+	if cfg.metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		metricsServer := &http.Server{Addr: cfg.metricsAddr, Handler: mux}
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Fprintf(os.Stderr, "metrics server failed: %v\n", err)
+			}
+		}()
+		defer metricsServer.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if cfg.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.duration)
+		defer cancel()
+	}
+
+	fmt.Fprintf(os.Stderr, "Soak test against %s at %.2f workflows/s, up to %d at once, metrics on %s\n",
+		cfg.target, cfg.rate, cfg.concurrency, cfg.metricsAddr)
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.rate))
+	defer ticker.Stop()
+
+	slots := make(chan struct{}, cfg.concurrency)
+	var wg sync.WaitGroup
+
+	started := time.Now()
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+
+		// Skip the tick rather than queue when the target falls behind, the
+		// skipped count shows the rate it sustains
+		select {
+		case slots <- struct{}{}:
+		default:
+			runner.summary.record(opCycle, "skipped", 0)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			runner.cycle()
+		}()
+	}
+
+	fmt.Fprintln(os.Stderr, "Waiting for running cycles...")
+	wg.Wait()
+
+	runner.summary.print(os.Stdout, time.Since(started))
+	if rate := runner.summary.errorRate(); rate > cfg.maxErrorRate {
+		fmt.Fprintf(os.Stderr, "%.2f%% of cycles failed, more than the allowed %.2f%%\n", rate*100, cfg.maxErrorRate*100)
+		return 1
+	}
+	return 0
+}
+
+// cycle runs one workflow through its life. Cycles run to the end once
+// started, so an interrupted soak test leaves no workflow behind.
+func (r *soakRunner) cycle() {
+	metrics.SoakCyclesInFlight.Inc()
+	defer metrics.SoakCyclesInFlight.Dec()
+
+	ctx := logger.WithCorrelationID(context.Background(), uuid.New().String())
+	start := time.Now()
+
+	result := "ok"
+	if err := r.runCycle(ctx); err != nil {
+		result = "error"
+		fmt.Fprintf(os.Stderr, "cycle %s failed: %v\n", logger.CorrelationID(ctx), err)
+	}
+	r.summary.record(opCycle, result, time.Since(start))
+}
+
+func (r *soakRunner) runCycle(ctx context.Context) (err error) {
+	var created workflow.Workflow
+	err = r.step(opCreate, func() error {
+		return r.api.call(ctx, http.MethodPost, r.cfg.target+"/api/v1/workflows", soakWorkflow(), &created)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Delete even when a later step fails, the cycle reports the first
+	// failure
+	defer func() {
+		deleteErr := r.step(opDelete, func() error {
+			return r.api.call(ctx, http.MethodDelete, r.cfg.target+"/api/v1/workflows/"+created.ID, nil, nil)
+		})
+		if err == nil {
+			err = deleteErr
+		}
+	}()
+
+	err = r.step(opActivate, func() error {
+		return r.api.call(ctx, http.MethodPost, r.cfg.target+"/api/v1/workflows/"+created.ID+"/activate", nil, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	var started struct {
+		ExecutionID string `json:"execution_id"`
+	}
+	err = r.step(opExecute, func() error {
+		body := map[string]interface{}{
+			"workflowId": created.ID,
+			"data":       map[string]interface{}{"soak": true},
+		}
+		return r.api.call(ctx, http.MethodPost, r.cfg.executionTarget+"/api/v1/executions", body, &started)
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.step(opWait, func() error {
+		return r.await(ctx, started.ExecutionID)
+	})
+}
+
+// await polls the execution until it finishes, failing unless it completed
+func (r *soakRunner) await(ctx context.Context, executionID string) error {
+	deadline := time.Now().Add(r.cfg.executeTimeout)
+	for {
+		var exec workflow.WorkflowExecution
+		if err := r.api.call(ctx, http.MethodGet, r.cfg.executionTarget+"/api/v1/executions/"+executionID, nil, &exec); err != nil {
+			return err
+		}
+
+		switch execution.Status(exec.Status) {
+		case execution.StatusCompleted:
+			return nil
+		case execution.StatusFailed, execution.StatusCancelled, execution.StatusTimeout:
+			return fmt.Errorf("execution %s %s: %s", executionID, exec.Status, exec.Error)
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("execution %s still %s after %s", executionID, exec.Status, r.cfg.executeTimeout)
+		}
+		time.Sleep(soakPollInterval)
+	}
+}
+
+// step times an operation and records its result
+func (r *soakRunner) step(operation string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+
+	result := "ok"
+	if err != nil {
+		result = "error"
+		err = fmt.Errorf("%s: %w", operation, err)
+	}
+	r.summary.record(operation, result, time.Since(start))
+	return err
+}
+
+// soakWorkflow is the smallest workflow that activates and runs, a manual
+// trigger
+func soakWorkflow() *workflow.CreateWorkflowRequest {
+	return &workflow.CreateWorkflowRequest{
+		Name:        "soak-" + uuid.New().String()[:8],
+		Description: "Disposable workflow of the soak test, safe to delete",
+		Tags:        []string{soakTag},
+		Nodes: []workflow.Node{{
+			ID:         "trigger",
+			Name:       "Manual Trigger",
+			Type:       workflow.NodeTypeTrigger,
+			Parameters: map[string]interface{}{"triggerType": workflow.TriggerTypeManual},
+		}},
+		Connections: []workflow.Connection{},
+	}
+}
+
+// soakSummary counts the results and latencies of each operation for the
+// report printed at the end of the run
+type soakSummary struct {
+	mu      sync.Mutex
+	results map[string]map[string]int
+	latency map[string][]time.Duration
+}
+
+func newSoakSummary() *soakSummary {
+	return &soakSummary{
+		results: make(map[string]map[string]int),
+		latency: make(map[string][]time.Duration),
+	}
+}
+
+func (s *soakSummary) record(operation, result string, duration time.Duration) {
+	metrics.RecordSoakOperation(operation, result, duration.Seconds())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.results[operation] == nil {
+		s.results[operation] = make(map[string]int)
+	}
+	s.results[operation][result]++
+	if result != "skipped" {
+		s.latency[operation] = append(s.latency[operation], duration)
+	}
+}
+
+// errorRate is the share of the cycles run that failed
+func (s *soakSummary) errorRate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cycles := s.results[opCycle]
+	total := cycles["ok"] + cycles["error"]
+	if total == 0 {
+		return 0
+	}
+	return float64(cycles["error"]) / float64(total)
+}
+
+func (s *soakSummary) print(w io.Writer, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprintf(w, "Soak test ran for %s\n\n", elapsed.Round(time.Second))
+	fmt.Fprintf(w, "%-10s %8s %8s %8s %10s %10s %10s\n", "operation", "ok", "error", "skipped", "p50", "p95", "p99")
+	for _, op := range []string{opCreate, opActivate, opExecute, opWait, opDelete, opCycle} {
+		results := s.results[op]
+		latency := s.latency[op]
+		sort.Slice(latency, func(i, j int) bool { return latency[i] < latency[j] })
+
+		fmt.Fprintf(w, "%-10s %8d %8d %8d %10s %10s %10s\n", op,
+			results["ok"], results["error"], results["skipped"],
+			percentile(latency, 0.50), percentile(latency, 0.95), percentile(latency, 0.99))
+	}
+}
+
+// percentile returns the p-th quantile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(p*float64(len(sorted)-1))].Round(time.Millisecond)
+}
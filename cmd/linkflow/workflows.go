@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// runPollInterval is how often run checks the progress of its execution
+const runPollInterval = time.Second
+
+// workflowFile is a workflow document read from a file
+type workflowFile struct {
+	path     string
+	document *workflow.WorkflowDocument
+	workflow *workflow.Workflow
+}
+
+// documentFormat is the format of a workflow document named path, YAML for
+// .yaml and .yml files and JSON otherwise
+func documentFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return workflow.GitFormatYAML
+	default:
+		return workflow.GitFormatJSON
+	}
+}
+
+// readWorkflowFile parses a workflow document, in the format of the
+// documents kept in Git and written by export, refusing unknown keys
+func readWorkflowFile(path string) (*workflowFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := workflow.DecodeWorkflowDocumentStrict(data, documentFormat(path))
+	if err != nil {
+		return nil, err
+	}
+	return &workflowFile{
+		path:     path,
+		document: doc,
+		workflow: doc.Apply(&workflow.Workflow{ID: doc.ID}),
+	}, nil
+}
+
+// lint validates the graph, nodes and settings of the workflow and runs the
+// lint rules of linter, as the workflow service does before saving it
+func (f *workflowFile) lint(linter *workflow.Linter) ([]string, []string) {
+	errs, warnings, _ := workflow.NewValidator(f.workflow).Validate()
+	report := linter.Lint(f.workflow)
+	errs = append(errs, report.Errors()...)
+	warnings = append(warnings, report.Warnings()...)
+	// The validator leaves out the checks of settings, reported once the
+	// graph is valid
+	if len(errs) == 0 {
+		if err := f.workflow.Validate(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	return errs, warnings
+}
+
+// settings returns the settings of the document as the workflow API takes
+// them
+func (f *workflowFile) settings() (map[string]interface{}, error) {
+	data, err := json.Marshal(f.document.Settings)
+	if err != nil {
+		return nil, err
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// runLint validates workflow documents without calling the API, failing
+// when any has errors. Warnings are printed but pass.
+func runLint(paths []string, linter *workflow.Linter) int {
+	failed := false
+	for _, path := range paths {
+		file, err := readWorkflowFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+
+		errs, warnings := file.lint(linter)
+		for _, msg := range errs {
+			fmt.Fprintf(os.Stderr, "%s: error: %s\n", path, msg)
+		}
+		for _, msg := range warnings {
+			fmt.Fprintf(os.Stderr, "%s: warning: %s\n", path, msg)
+		}
+		if len(errs) > 0 {
+			failed = true
+			continue
+		}
+		fmt.Printf("%s: ok\n", path)
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// deployConfig configures the deployment of workflow documents
+type deployConfig struct {
+	// target serves the workflow API, the API gateway ingress or the
+	// workflow service
+	target   string
+	api      *apiClient
+	activate bool
+	paths    []string
+}
+
+// runDeploy lints workflow documents then saves each through the workflow
+// API: a document with an ID updates that workflow as a new version, one
+// without creates a workflow. Nothing is deployed when a document fails
+// lint.
+func runDeploy(cfg deployConfig) int {
+	linter := workflow.NewDefaultLinter(workflow.LintOptions{})
+	files := make([]*workflowFile, 0, len(cfg.paths))
+	for _, path := range cfg.paths {
+		file, err := readWorkflowFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			return 1
+		}
+		if errs, _ := file.lint(linter); len(errs) > 0 {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, strings.Join(errs, "; "))
+			return 1
+		}
+		files = append(files, file)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, file := range files {
+		deployed, err := deployWorkflow(ctx, cfg, file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file.path, err)
+			return 1
+		}
+
+		if file.document.ID == "" {
+			fmt.Printf("%s: created workflow %s, set its id in the file so later deploys update it\n", file.path, deployed.ID)
+		} else {
+			fmt.Printf("%s: deployed workflow %s version %d\n", file.path, deployed.ID, deployed.Version)
+		}
+
+		if cfg.activate && !deployed.IsActive {
+			if err := cfg.api.call(ctx, http.MethodPost, cfg.target+"/api/v1/workflows/"+url.PathEscape(deployed.ID)+"/activate", nil, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", file.path, err)
+				return 1
+			}
+			fmt.Printf("%s: activated workflow %s\n", file.path, deployed.ID)
+		}
+	}
+	return 0
+}
+
+// deployWorkflow creates or updates the workflow of a document
+func deployWorkflow(ctx context.Context, cfg deployConfig, file *workflowFile) (*workflow.Workflow, error) {
+	doc := file.document
+	settings, err := file.settings()
+	if err != nil {
+		return nil, err
+	}
+
+	var deployed workflow.Workflow
+	if doc.ID == "" {
+		err = cfg.api.call(ctx, http.MethodPost, cfg.target+"/api/v1/workflows", &workflow.CreateWorkflowRequest{
+			Name:        doc.Name,
+			Description: doc.Description,
+			Nodes:       doc.Nodes,
+			Connections: doc.Connections,
+			Settings:    settings,
+			Tags:        doc.Tags,
+			Annotations: doc.Annotations,
+		}, &deployed)
+	} else {
+		err = cfg.api.call(ctx, http.MethodPut, cfg.target+"/api/v1/workflows/"+url.PathEscape(doc.ID), &workflow.UpdateWorkflowRequest{
+			Name:        doc.Name,
+			Description: doc.Description,
+			Nodes:       doc.Nodes,
+			Connections: doc.Connections,
+			Settings:    settings,
+			Tags:        doc.Tags,
+			Annotations: doc.Annotations,
+		}, &deployed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deployed, nil
+}
+
+// runConfig configures the execution of a workflow
+type runConfig struct {
+	// executionTarget serves the execution API, the API gateway ingress or
+	// the execution service
+	executionTarget string
+	api             *apiClient
+	workflowID      string
+	environment     string
+	input           map[string]interface{}
+	debug           bool
+	timeout         time.Duration
+}
+
+// runExecution starts an execution of a workflow and follows it, printing
+// its nodes as they finish, until it ends. The output data of a completed
+// execution is printed as JSON; any other outcome fails.
+func runExecution(cfg runConfig) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var started struct {
+		ExecutionID string `json:"execution_id"`
+	}
+	body := map[string]interface{}{
+		"workflowId":  cfg.workflowID,
+		"environment": cfg.environment,
+		"data":        cfg.input,
+		"debug":       cfg.debug,
+	}
+	if err := cfg.api.call(ctx, http.MethodPost, cfg.executionTarget+"/api/v1/executions", body, &started); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start execution: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Execution %s started\n", started.ExecutionID)
+
+	exec, err := tailExecution(ctx, cfg, started.ExecutionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	if execution.Status(exec.Status) != execution.StatusCompleted {
+		fmt.Fprintf(os.Stderr, "Execution %s %s: %s\n", exec.ID, exec.Status, exec.Error)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Execution %s completed in %dms\n", exec.ID, exec.ExecutionTime)
+
+	output, err := json.MarshalIndent(exec.Data, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode output: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(output))
+	return 0
+}
+
+// tailExecution polls an execution until it ends or the timeout elapses,
+// printing each node run once it finished and the status until it ends
+func tailExecution(ctx context.Context, cfg runConfig, executionID string) (*workflow.WorkflowExecution, error) {
+	deadline := time.Now().Add(cfg.timeout)
+	printed := make(map[string]bool)
+	status := ""
+
+	for {
+		var exec workflow.WorkflowExecution
+		if err := cfg.api.call(ctx, http.MethodGet, cfg.executionTarget+"/api/v1/executions/"+url.PathEscape(executionID), nil, &exec); err != nil {
+			return nil, err
+		}
+
+		for _, node := range exec.NodeExecutions {
+			if node.FinishedAt == nil || printed[node.ID] {
+				continue
+			}
+			printed[node.ID] = true
+			line := fmt.Sprintf("  %s (%s) %s in %s", node.NodeID, node.NodeType, node.Status, node.FinishedAt.Sub(node.StartedAt).Round(time.Millisecond))
+			if node.Error != "" {
+				line += ": " + node.Error
+			}
+			fmt.Fprintln(os.Stderr, line)
+		}
+		switch execution.Status(exec.Status) {
+		case execution.StatusCompleted, execution.StatusFailed, execution.StatusCancelled, execution.StatusTimeout:
+			return &exec, nil
+		}
+		if exec.Status != status {
+			status = exec.Status
+			fmt.Fprintf(os.Stderr, "Execution %s %s\n", executionID, status)
+		}
+
+		if cfg.timeout > 0 && time.Now().After(deadline) {
+			return nil, fmt.Errorf("execution %s still %s after %s", executionID, exec.Status, cfg.timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped following execution %s, it keeps running", executionID)
+		case <-time.After(runPollInterval):
+		}
+	}
+}
+
+// exportConfig configures the export of a workflow document
+type exportConfig struct {
+	target     string
+	api        *apiClient
+	workflowID string
+	// output is the file written, standard output when empty
+	output string
+	format string
+}
+
+// runExport writes the definition of a workflow as the document deploy
+// reads, with its ID so deploying it updates the workflow
+func runExport(cfg exportConfig) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var wf workflow.Workflow
+	if err := cfg.api.call(ctx, http.MethodGet, cfg.target+"/api/v1/workflows/"+url.PathEscape(cfg.workflowID), nil, &wf); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to get workflow: %v\n", err)
+		return 1
+	}
+
+	content, err := workflow.EncodeWorkflowDocument(wf.Document(), cfg.format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	if cfg.output == "" {
+		os.Stdout.Write(content)
+		return 0
+	}
+	if err := os.WriteFile(cfg.output, content, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", cfg.output, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Exported workflow %s version %d to %s\n", wf.ID, wf.Version, cfg.output)
+	return 0
+}
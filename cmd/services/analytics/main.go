@@ -9,18 +9,27 @@ import (
 
 	"github.com/linkflow-go/internal/analytics/server"
 	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/discovery"
+	"github.com/linkflow-go/pkg/doctor"
 	"github.com/linkflow-go/pkg/logger"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(doctor.Run("analytics-service", os.Args[2:], doctor.Requirements{
+			Schema: "analytics", Redis: true, EventBus: true,
+		}))
+	}
+
 	// Load configuration
 	cfg, err := config.Load("analytics-service")
 	if err != nil {
 		panic(err)
 	}
 
-	// Initialize logger
+	// Initialize logger, buffered remote sinks are flushed on exit
 	log := logger.New(cfg.Logger.ToLoggerConfig())
+	defer logger.Sync(log)
 
 	// Create and start server
 	srv, err := server.New(cfg, log)
@@ -36,6 +45,16 @@ func main() {
 		}
 	}()
 
+	// Reload tunables such as the log level on SIGHUP
+	watcher := config.NewWatcher(cfg, log)
+	watcher.Start()
+	defer watcher.Stop()
+
+	// Announce this instance in the topology reported by the gateway
+	announcer := discovery.NewAnnouncer(cfg, "analytics-service", log)
+	announcer.Start()
+	defer announcer.Stop()
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
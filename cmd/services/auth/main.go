@@ -9,18 +9,27 @@ import (
 
 	"github.com/linkflow-go/internal/auth/server"
 	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/discovery"
+	"github.com/linkflow-go/pkg/doctor"
 	"github.com/linkflow-go/pkg/logger"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(doctor.Run("auth-service", os.Args[2:], doctor.Requirements{
+			Schema: "auth", Redis: true, EventBus: true,
+		}))
+	}
+
 	// Load configuration
 	cfg, err := config.Load("auth-service")
 	if err != nil {
 		panic(err)
 	}
 
-	// Initialize logger
+	// Initialize logger, buffered remote sinks are flushed on exit
 	log := logger.New(cfg.Logger.ToLoggerConfig())
+	defer logger.Sync(log)
 
 	// Create and start server
 	srv, err := server.New(cfg, log)
@@ -36,6 +45,17 @@ func main() {
 		}
 	}()
 
+	// Reload tunables such as the log level on SIGHUP
+	watcher := config.NewWatcher(cfg, log)
+	watcher.OnChange(srv.ApplyConfig)
+	watcher.Start()
+	defer watcher.Stop()
+
+	// Announce this instance in the topology reported by the gateway
+	announcer := discovery.NewAnnouncer(cfg, "auth-service", log)
+	announcer.Start()
+	defer announcer.Stop()
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/linkflow-go/internal/credential/adapters/db/repository"
+	"github.com/linkflow-go/internal/credential/adapters/vault"
+	"github.com/linkflow-go/internal/credential/app/migration"
+	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/contracts/credential"
+	"github.com/linkflow-go/pkg/database"
+	"github.com/linkflow-go/pkg/logger"
+)
+
+// runMigrateSecrets moves the secrets of all credentials between secret
+// backends, e.g. `credential-service migrate-secrets -from local -to vault`
+func runMigrateSecrets(args []string) int {
+	flags := flag.NewFlagSet("migrate-secrets", flag.ContinueOnError)
+	from := flags.String("from", credential.BackendLocal, "backend to migrate from (local, vault)")
+	to := flags.String("to", credential.BackendVault, "backend to migrate to (local, vault)")
+	batchSize := flags.Int("batch-size", migration.DefaultBatchSize, "credentials per transaction")
+	dryRun := flags.Bool("dry-run", false, "only check every credential can be read from the source")
+	keepSource := flags.Bool("keep-source", false, "keep the secrets in the source backend")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load("credential-service")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	log := logger.New(cfg.Logger.ToLoggerConfig())
+	defer logger.Sync(log)
+
+	db, err := database.New(cfg.Database.ToDatabaseConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	source, err := vault.NewBackend(*from, cfg.Credential, log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "source backend: %v\n", err)
+		return 1
+	}
+	target, err := vault.NewBackend(*to, cfg.Credential, log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "target backend: %v\n", err)
+		return 1
+	}
+
+	migrator := migration.NewMigrator(repository.NewCredentialRepository(db), source, target, log)
+	migrator.OnProgress(func(p migration.Progress) {
+		fmt.Printf("batch %d: %d/%d credentials\n", p.Batch, p.Migrated, p.Total)
+	})
+
+	// Interrupting rolls back what was migrated so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := migrator.Run(ctx, migration.Options{
+		BatchSize:  *batchSize,
+		DryRun:     *dryRun,
+		KeepSource: *keepSource,
+	})
+	if report != nil {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(out))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+		return 1
+	}
+
+	if !*dryRun && report.Migrated > 0 {
+		fmt.Printf("set credential.backend to %q so new credentials are stored there\n", *to)
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	analytics "github.com/linkflow-go/internal/analytics/domain"
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+)
+
+// GetDashboard reports the executions started since the start of the
+// range and the KPIs of its days, of the tenant of ctx. Executions are read
+// from their daily aggregates.
+func (r *AnalyticsRepository) GetDashboard(ctx context.Context, filter analytics.DashboardFilter, since time.Time) (*analytics.Dashboard, error) {
+	db := r.db.WithContext(ctx)
+	dashboard := &analytics.Dashboard{
+		WorkflowID:      filter.WorkflowID,
+		Since:           since,
+		Days:            filter.Days,
+		ExecutionsByDay: []*analytics.DailyCount{},
+		TopWorkflows:    []*analytics.WorkflowSummary{},
+		KPIs:            []*analytics.DailyKPI{},
+		KPITotals:       []*analytics.KPITotal{},
+	}
+
+	workflows := db.Model(&workflow.Workflow{}).Where("deleted_at IS NULL")
+	if filter.WorkflowID != "" {
+		workflows = workflows.Where("id = ?", filter.WorkflowID)
+	}
+	if err := workflows.Count(&dashboard.TotalWorkflows).Error; err != nil {
+		return nil, err
+	}
+	if err := workflows.Where("is_active = ?", true).Count(&dashboard.ActiveWorkflows).Error; err != nil {
+		return nil, err
+	}
+
+	daily := db.Model(&analytics.DailyExecutions{}).Where("day >= ?", analytics.Day(since))
+	if filter.WorkflowID != "" {
+		daily = daily.Where("workflow_id = ?", filter.WorkflowID)
+	}
+
+	err := daily.Session(&gorm.Session{}).
+		Select("day AS date, SUM(started) AS count, SUM(succeeded) AS success, SUM(failed) AS failed").
+		Group("day").
+		Order("day ASC").
+		Scan(&dashboard.ExecutionsByDay).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var success int64
+	for _, d := range dashboard.ExecutionsByDay {
+		dashboard.TotalExecutions += d.Count
+		success += d.Success
+	}
+	if dashboard.TotalExecutions > 0 {
+		dashboard.SuccessRate = float64(success) / float64(dashboard.TotalExecutions) * 100
+	}
+
+	var duration struct {
+		Finished   int64
+		DurationMs int64
+	}
+	err = daily.Session(&gorm.Session{}).
+		Select("COALESCE(SUM(finished), 0) AS finished, COALESCE(SUM(duration_ms), 0) AS duration_ms").
+		Scan(&duration).Error
+	if err != nil {
+		return nil, err
+	}
+	if duration.Finished > 0 {
+		dashboard.AvgExecutionTime = float64(duration.DurationMs) / float64(duration.Finished)
+	}
+
+	if err := r.topWorkflows(ctx, daily, dashboard); err != nil {
+		return nil, err
+	}
+
+	kpis := db.Model(&execution.KPI{}).Where("day >= ?", execution.KPIDay(since))
+	if filter.WorkflowID != "" {
+		kpis = kpis.Where("workflow_id = ?", filter.WorkflowID)
+	}
+	err = kpis.Session(&gorm.Session{}).
+		Select("day AS date, workflow_id, name, value, samples").
+		Order("day ASC, workflow_id ASC, name ASC").
+		Scan(&dashboard.KPIs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = kpis.Session(&gorm.Session{}).
+		Select("name, SUM(value) AS value, SUM(samples) AS samples").
+		Group("name").
+		Order("name ASC").
+		Scan(&dashboard.KPITotals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return dashboard, nil
+}
+
+// topWorkflows lists the most executed workflows of the daily aggregates
+func (r *AnalyticsRepository) topWorkflows(ctx context.Context, daily *gorm.DB, dashboard *analytics.Dashboard) error {
+	var rows []struct {
+		WorkflowID string
+		Count      int64
+		Success    int64
+	}
+	err := daily.Session(&gorm.Session{}).
+		Select("workflow_id, SUM(started) AS count, SUM(succeeded) AS success").
+		Group("workflow_id").
+		Order("count DESC").
+		Limit(analytics.TopWorkflows).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return err
+	}
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.WorkflowID
+	}
+	var names []struct {
+		ID   string
+		Name string
+	}
+	err = r.db.WithContext(ctx).
+		Model(&workflow.Workflow{}).
+		Where("id IN ?", ids).
+		Select("id, name").
+		Scan(&names).Error
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]string, len(names))
+	for _, n := range names {
+		byID[n.ID] = n.Name
+	}
+
+	for _, row := range rows {
+		summary := &analytics.WorkflowSummary{
+			ID:             row.WorkflowID,
+			Name:           byID[row.WorkflowID],
+			ExecutionCount: row.Count,
+		}
+		if row.Count > 0 {
+			summary.SuccessRate = float64(row.Success) / float64(row.Count) * 100
+		}
+		dashboard.TopWorkflows = append(dashboard.TopWorkflows, summary)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	analytics "github.com/linkflow-go/internal/analytics/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordExecutions adds counts to the executions of a workflow started on
+// day, of the tenant of ctx
+func (r *AnalyticsRepository) RecordExecutions(ctx context.Context, workflowID, day string, counts analytics.ExecutionCounts) error {
+	db := r.db.WithContext(ctx)
+
+	// The first execution of the day creates the row, unless another event
+	// created it meanwhile, then it is added like the others
+	for attempt := 0; attempt < 2; attempt++ {
+		result := db.Model(&analytics.DailyExecutions{}).
+			Where("day = ? AND workflow_id = ?", day, workflowID).
+			Updates(map[string]interface{}{
+				"started":     gorm.Expr("started + ?", counts.Started),
+				"succeeded":   gorm.Expr("succeeded + ?", counts.Succeeded),
+				"failed":      gorm.Expr("failed + ?", counts.Failed),
+				"cancelled":   gorm.Expr("cancelled + ?", counts.Cancelled),
+				"finished":    gorm.Expr("finished + ?", counts.Finished),
+				"duration_ms": gorm.Expr("duration_ms + ?", counts.DurationMs),
+				"updated_at":  time.Now(),
+			})
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+
+		result = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&analytics.DailyExecutions{
+			Day:             day,
+			WorkflowID:      workflowID,
+			ExecutionCounts: counts,
+			UpdatedAt:       time.Now(),
+		})
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+	}
+	return nil
+}
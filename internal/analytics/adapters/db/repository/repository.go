@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/database"
 )
 
@@ -21,3 +23,19 @@ func (r *AnalyticsRepository) SaveMetric(ctx context.Context, metric interface{}
 func (r *AnalyticsRepository) GetMetrics(ctx context.Context) ([]interface{}, error) {
 	return []interface{}{}, nil
 }
+
+// GetWorkflowOwner returns the user owning a workflow
+func (r *AnalyticsRepository) GetWorkflowOwner(ctx context.Context, workflowID string) (string, error) {
+	var owners []string
+	err := r.db.WithContext(ctx).
+		Model(&workflow.Workflow{}).
+		Where("id = ?", workflowID).
+		Pluck("user_id", &owners).Error
+	if err != nil {
+		return "", err
+	}
+	if len(owners) == 0 {
+		return "", fmt.Errorf("workflow not found: %s", workflowID)
+	}
+	return owners[0], nil
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/linkflow-go/internal/analytics/app/service"
+	analytics "github.com/linkflow-go/internal/analytics/domain"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/logger"
 )
 
@@ -24,12 +27,25 @@ func (h *AnalyticsHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
-func (h *AnalyticsHandlers) Ready(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
-}
-
+// GetDashboard reports the executions and KPIs of the last days, of one
+// workflow when workflowId is set
 func (h *AnalyticsHandlers) GetDashboard(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Dashboard endpoint"})
+	days := 0
+	if raw := c.Query("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(apperrors.ToHTTP(analytics.ErrInvalidDashboard.WithMessage("days must be a number")))
+			return
+		}
+		days = n
+	}
+
+	dashboard, err := h.service.GetDashboard(c.Request.Context(), c.Query("workflowId"), days)
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+	c.JSON(http.StatusOK, dashboard)
 }
 
 func (h *AnalyticsHandlers) GetCustomDashboard(c *gin.Context) {
@@ -88,8 +104,15 @@ func (h *AnalyticsHandlers) GetUsageTrends(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Usage trends"})
 }
 
+// GetAnomalies lists the latest anomalous durations and error bursts, of
+// one workflow with ?workflowId=
 func (h *AnalyticsHandlers) GetAnomalies(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"anomalies": []interface{}{}})
+	anomalies, err := h.service.ListAnomalies(c.Request.Context(), c.Query("workflowId"))
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"anomalies": anomalies})
 }
 
 func (h *AnalyticsHandlers) DetectAnomalies(c *gin.Context) {
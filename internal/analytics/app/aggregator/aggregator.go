@@ -4,11 +4,18 @@ import (
 	"context"
 	"time"
 
+	analytics "github.com/linkflow-go/internal/analytics/domain"
 	"github.com/linkflow-go/internal/analytics/ports"
+	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/rediskey"
 	"github.com/redis/go-redis/v9"
 )
 
+// countedTTL is how long an execution event is remembered as counted,
+// redeliveries within it are not counted again
+const countedTTL = 7 * 24 * time.Hour
+
 type MetricsAggregator struct {
 	repo   ports.AnalyticsRepository
 	redis  *redis.Client
@@ -53,3 +60,58 @@ func (a *MetricsAggregator) aggregate(ctx context.Context) {
 	a.logger.Info("Aggregating metrics")
 	// Aggregation logic here
 }
+
+// RecordExecution adds an execution event to the executions of its workflow
+// on the day the execution started. Started executions count as started,
+// finished ones by outcome with their duration. Each event of an execution
+// is counted once.
+func (a *MetricsAggregator) RecordExecution(ctx context.Context, event events.Event) error {
+	var payload struct {
+		WorkflowID string     `json:"workflowId"`
+		StartedAt  *time.Time `json:"startedAt"`
+		Duration   int64      `json:"duration"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if payload.WorkflowID == "" || event.AggregateID == "" {
+		return nil
+	}
+
+	var counts analytics.ExecutionCounts
+	switch event.Type {
+	case events.ExecutionStarted:
+		counts.Started = 1
+	case events.ExecutionCompleted:
+		counts.Succeeded = 1
+	case events.ExecutionFailed:
+		counts.Failed = 1
+	case events.ExecutionCancelled:
+		counts.Cancelled = 1
+	default:
+		return nil
+	}
+	if event.Type != events.ExecutionStarted && payload.Duration > 0 {
+		counts.Finished = 1
+		counts.DurationMs = payload.Duration
+	}
+
+	// Events published before they carried the start are counted on the
+	// day they were published
+	startedAt := event.Timestamp
+	if payload.StartedAt != nil && !payload.StartedAt.IsZero() {
+		startedAt = *payload.StartedAt
+	}
+
+	key := rediskey.Tenant(ctx, "analytics", "counted", event.Type, event.AggregateID)
+	first, err := a.redis.SetNX(ctx, key, 1, countedTTL).Result()
+	if err != nil || !first {
+		return err
+	}
+	if err := a.repo.RecordExecutions(ctx, payload.WorkflowID, analytics.Day(startedAt), counts); err != nil {
+		// Counted on the next delivery instead
+		a.redis.Del(context.WithoutCancel(ctx), key)
+		return err
+	}
+	return nil
+}
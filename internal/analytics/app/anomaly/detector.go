@@ -0,0 +1,295 @@
+// Package anomaly learns how long the executions of each workflow run and
+// how often they fail, and reports those far from it
+package anomaly
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	analytics "github.com/linkflow-go/internal/analytics/domain"
+	"github.com/linkflow-go/internal/analytics/ports"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// baselineTTL forgets the baselines of workflows idle for a month
+	baselineTTL = 30 * 24 * time.Hour
+	// observedTTL is how long redeliveries of an execution are not
+	// observed again
+	observedTTL = 48 * time.Hour
+
+	// recentAnomalies bounds the anomalies kept per tenant for listing
+	recentAnomalies = 200
+	recentTTL       = 30 * 24 * time.Hour
+)
+
+// observeScript adds a measure to an exponentially weighted baseline and
+// returns the baseline before it. The first executions weigh as in a plain
+// mean until the weight falls to alpha.
+// KEYS: baseline. ARGV: measure, alpha, expiry in seconds.
+var observeScript = redis.NewScript(`
+local state = redis.call('HMGET', KEYS[1], 'n', 'mean', 'var')
+local n = tonumber(state[1] or '0')
+local mean = tonumber(state[2] or '0')
+local var = tonumber(state[3] or '0')
+local x = tonumber(ARGV[1])
+local alpha = math.max(tonumber(ARGV[2]), 1 / (n + 1))
+local diff = x - mean
+local incr = alpha * diff
+redis.call('HSET', KEYS[1], 'n', n + 1, 'mean', tostring(mean + incr), 'var', tostring((1 - alpha) * (var + diff * incr)))
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+return {tostring(n), tostring(mean), tostring(var)}
+`)
+
+// Detector keeps a duration and a failure rate baseline per workflow in
+// Redis, shared by the replicas, and publishes workflow.anomaly.detected for
+// executions running AnomalySigma deviations longer than their baseline and
+// windows failing as much above it. Baselines keep learning from anomalous
+// executions, a lasting change becomes the new normal.
+type Detector struct {
+	repo     ports.AnalyticsRepository
+	redis    *redis.Client
+	eventBus events.EventBus
+	logger   logger.Logger
+}
+
+// NewDetector creates a detector publishing anomalies on eventBus
+func NewDetector(repo ports.AnalyticsRepository, redis *redis.Client, eventBus events.EventBus, logger logger.Logger) *Detector {
+	return &Detector{
+		repo:     repo,
+		redis:    redis,
+		eventBus: eventBus,
+		logger:   logger,
+	}
+}
+
+// Observe learns from a completed or failed execution, once per execution,
+// and reports it or its window when anomalous
+func (d *Detector) Observe(ctx context.Context, event events.Event) error {
+	if event.Type != events.ExecutionCompleted && event.Type != events.ExecutionFailed {
+		return nil
+	}
+	var payload struct {
+		WorkflowID string `json:"workflowId"`
+		Duration   int64  `json:"duration"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if payload.WorkflowID == "" || event.AggregateID == "" {
+		return nil
+	}
+
+	observed := rediskey.Tenant(ctx, "analytics", "anomaly", "observed", event.AggregateID)
+	first, err := d.redis.SetNX(ctx, observed, 1, observedTTL).Result()
+	if err != nil || !first {
+		return err
+	}
+
+	failed := event.Type == events.ExecutionFailed
+	if !failed && payload.Duration > 0 {
+		err = d.observeDuration(ctx, payload.WorkflowID, event.AggregateID, payload.Duration)
+	}
+	if err == nil {
+		err = d.observeOutcome(ctx, payload.WorkflowID, failed, event.Timestamp)
+	}
+	if err != nil {
+		// Observed on the next delivery instead
+		d.redis.Del(context.WithoutCancel(ctx), observed)
+	}
+	return err
+}
+
+// observeDuration compares the duration of a successful execution with the
+// baseline of its workflow, then learns it. Failed executions stop early or
+// time out, they would skew the baseline.
+func (d *Detector) observeDuration(ctx context.Context, workflowID, executionID string, durationMs int64) error {
+	key := rediskey.Tenant(ctx, "analytics", "baseline", workflowID, analytics.AnomalyDuration)
+	baseline, err := d.observe(ctx, key, float64(durationMs), analytics.DurationAlpha)
+	if err != nil || !baseline.Learned() {
+		return err
+	}
+
+	sigma := baseline.DurationDeviation(float64(durationMs))
+	if sigma < analytics.AnomalySigma {
+		return nil
+	}
+	std := math.Max(math.Sqrt(baseline.Variance), baseline.Mean*analytics.MinDurationStdRatio)
+	d.publish(ctx, &analytics.Anomaly{
+		Kind:        analytics.AnomalyDuration,
+		WorkflowID:  workflowID,
+		ExecutionID: executionID,
+		Value:       float64(durationMs),
+		Baseline:    baseline.Mean,
+		Threshold:   baseline.Mean + analytics.AnomalySigma*std,
+		Sigma:       sigma,
+		DetectedAt:  time.Now().UTC(),
+	})
+	return nil
+}
+
+// observeOutcome counts an execution in the window it finished in and
+// learns whether it failed. A failure taking the failure rate of its window
+// above the threshold reports the window, once.
+func (d *Detector) observeOutcome(ctx context.Context, workflowID string, failed bool, finishedAt time.Time) error {
+	var outcome float64
+	if failed {
+		outcome = 1
+	}
+	key := rediskey.Tenant(ctx, "analytics", "baseline", workflowID, "failure")
+	baseline, err := d.observe(ctx, key, outcome, analytics.FailureAlpha)
+	if err != nil {
+		return err
+	}
+
+	start := analytics.BurstWindowStart(finishedAt)
+	window := rediskey.Tenant(ctx, "analytics", "burst", workflowID, strconv.FormatInt(start.Unix(), 10))
+	pipe := d.redis.TxPipeline()
+	finished := pipe.HIncrBy(ctx, window, "finished", 1)
+	failures := pipe.HIncrBy(ctx, window, "failed", int64(outcome))
+	pipe.Expire(ctx, window, 2*analytics.BurstWindow)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	if !failed || !baseline.Learned() || failures.Val() < analytics.MinBurstFailures {
+		return nil
+	}
+	rate := float64(failures.Val()) / float64(finished.Val())
+	threshold := baseline.BurstThreshold(finished.Val())
+	if rate <= threshold {
+		return nil
+	}
+
+	// One report per window, later failures of the burst are not reported
+	reported := rediskey.Tenant(ctx, "analytics", "anomaly", "burst", workflowID, strconv.FormatInt(start.Unix(), 10))
+	first, err := d.redis.SetNX(ctx, reported, 1, 2*analytics.BurstWindow).Result()
+	if err != nil || !first {
+		return err
+	}
+
+	var sigma float64
+	if se := math.Sqrt(baseline.Mean * (1 - baseline.Mean) / float64(finished.Val())); se > 0 {
+		sigma = (rate - baseline.Mean) / se
+	}
+	d.publish(ctx, &analytics.Anomaly{
+		Kind:        analytics.AnomalyErrorBurst,
+		WorkflowID:  workflowID,
+		Value:       rate,
+		Baseline:    baseline.Mean,
+		Threshold:   threshold,
+		Sigma:       sigma,
+		WindowStart: &start,
+		Failed:      failures.Val(),
+		Finished:    finished.Val(),
+		DetectedAt:  time.Now().UTC(),
+	})
+	return nil
+}
+
+// observe adds a measure to a baseline and returns the baseline before it
+func (d *Detector) observe(ctx context.Context, key string, value, alpha float64) (analytics.Baseline, error) {
+	res, err := observeScript.Run(ctx, d.redis, []string{key},
+		strconv.FormatFloat(value, 'f', -1, 64),
+		strconv.FormatFloat(alpha, 'f', -1, 64),
+		int64(baselineTTL.Seconds()),
+	).StringSlice()
+	if err != nil {
+		return analytics.Baseline{}, err
+	}
+
+	var baseline analytics.Baseline
+	baseline.Count, _ = strconv.ParseInt(res[0], 10, 64)
+	baseline.Mean, _ = strconv.ParseFloat(res[1], 64)
+	baseline.Variance, _ = strconv.ParseFloat(res[2], 64)
+	return baseline, nil
+}
+
+// publish reports an anomaly to the owner of its workflow
+func (d *Detector) publish(ctx context.Context, anomaly *analytics.Anomaly) {
+	userID, err := d.repo.GetWorkflowOwner(ctx, anomaly.WorkflowID)
+	if err != nil {
+		d.logger.Warn("Failed to get owner of anomalous workflow", "workflowId", anomaly.WorkflowID, "error", err)
+	}
+
+	builder := events.NewEventBuilder(events.WorkflowAnomalyDetected).
+		WithAggregateID(anomaly.WorkflowID).
+		WithAggregateType("workflow").
+		WithUserID(userID).
+		WithPayload("kind", anomaly.Kind).
+		WithPayload("workflowId", anomaly.WorkflowID).
+		WithPayload("userId", userID).
+		WithPayload("value", anomaly.Value).
+		WithPayload("baseline", anomaly.Baseline).
+		WithPayload("threshold", anomaly.Threshold).
+		WithPayload("sigma", anomaly.Sigma).
+		WithPayload("detectedAt", anomaly.DetectedAt)
+	if anomaly.ExecutionID != "" {
+		builder.WithPayload("executionId", anomaly.ExecutionID)
+	}
+	if anomaly.WindowStart != nil {
+		builder.WithPayload("windowStart", *anomaly.WindowStart).
+			WithPayload("failed", anomaly.Failed).
+			WithPayload("finished", anomaly.Finished)
+	}
+
+	d.remember(ctx, anomaly)
+	if err := d.eventBus.Publish(ctx, builder.Build()); err != nil {
+		d.logger.Error("Failed to publish anomaly", "workflowId", anomaly.WorkflowID, "kind", anomaly.Kind, "error", err)
+		return
+	}
+	metrics.WorkflowAnomalies.WithLabelValues(anomaly.Kind).Inc()
+
+	d.logger.Warn("Workflow anomaly detected",
+		"workflowId", anomaly.WorkflowID,
+		"kind", anomaly.Kind,
+		"value", anomaly.Value,
+		"baseline", anomaly.Baseline,
+		"sigma", anomaly.Sigma,
+	)
+}
+
+// remember keeps an anomaly among the recent ones of its tenant
+func (d *Detector) remember(ctx context.Context, anomaly *analytics.Anomaly) {
+	data, err := json.Marshal(anomaly)
+	if err != nil {
+		return
+	}
+	key := rediskey.Tenant(ctx, "analytics", "anomalies")
+	pipe := d.redis.TxPipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, recentAnomalies-1)
+	pipe.Expire(ctx, key, recentTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		d.logger.Warn("Failed to keep anomaly", "workflowId", anomaly.WorkflowID, "error", err)
+	}
+}
+
+// Recent returns the latest anomalies of the tenant of ctx, newest first,
+// of one workflow when workflowID is set
+func (d *Detector) Recent(ctx context.Context, workflowID string) ([]*analytics.Anomaly, error) {
+	entries, err := d.redis.LRange(ctx, rediskey.Tenant(ctx, "analytics", "anomalies"), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := make([]*analytics.Anomaly, 0, len(entries))
+	for _, entry := range entries {
+		var anomaly analytics.Anomaly
+		if err := json.Unmarshal([]byte(entry), &anomaly); err != nil {
+			continue
+		}
+		if workflowID != "" && anomaly.WorkflowID != workflowID {
+			continue
+		}
+		anomalies = append(anomalies, &anomaly)
+	}
+	return anomalies, nil
+}
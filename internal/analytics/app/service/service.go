@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"time"
 
+	analytics "github.com/linkflow-go/internal/analytics/domain"
 	"github.com/linkflow-go/internal/analytics/ports"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
@@ -11,6 +13,7 @@ import (
 type AnalyticsService struct {
 	repo       ports.AnalyticsRepository
 	aggregator ports.MetricsAggregator
+	anomalies  ports.AnomalyDetector
 	eventBus   events.EventBus
 	logger     logger.Logger
 }
@@ -29,8 +32,57 @@ func NewAnalyticsService(
 	}
 }
 
+// SetAnomalyDetector reports the finished executions far from the baseline
+// of their workflow
+func (s *AnalyticsService) SetAnomalyDetector(detector ports.AnomalyDetector) {
+	s.anomalies = detector
+}
+
 func (s *AnalyticsService) ProcessEvent(ctx context.Context, event events.Event) error {
+	switch event.Type {
+	case events.ExecutionStarted, events.ExecutionCompleted, events.ExecutionFailed, events.ExecutionCancelled:
+		if err := s.aggregator.RecordExecution(ctx, event); err != nil {
+			s.logger.Error("Failed to record execution", "type", event.Type, "execution_id", event.AggregateID, "error", err)
+			return err
+		}
+		if s.anomalies != nil {
+			if err := s.anomalies.Observe(ctx, event); err != nil {
+				s.logger.Error("Failed to observe execution", "type", event.Type, "execution_id", event.AggregateID, "error", err)
+				return err
+			}
+		}
+		return nil
+	}
+
 	// Process analytics events
 	s.logger.Info("Processing analytics event", "type", event.Type, "id", event.ID)
 	return nil
 }
+
+// GetDashboard reports the executions and KPIs of the last days days, of one
+// workflow when workflowID is set
+func (s *AnalyticsService) GetDashboard(ctx context.Context, workflowID string, days int) (*analytics.Dashboard, error) {
+	if days == 0 {
+		days = analytics.DefaultDashboardDays
+	}
+	if days < 1 || days > analytics.MaxDashboardDays {
+		return nil, analytics.ErrInvalidDashboard.WithMessage("days must be between 1 and 90")
+	}
+
+	filter := analytics.DashboardFilter{WorkflowID: workflowID, Days: days}
+	dashboard, err := s.repo.GetDashboard(ctx, filter, filter.Since(time.Now()))
+	if err != nil {
+		s.logger.Error("Failed to get dashboard", "workflow_id", workflowID, "error", err)
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+// ListAnomalies returns the latest anomalies detected, of one workflow when
+// workflowID is set
+func (s *AnalyticsService) ListAnomalies(ctx context.Context, workflowID string) ([]*analytics.Anomaly, error) {
+	if s.anomalies == nil {
+		return []*analytics.Anomaly{}, nil
+	}
+	return s.anomalies.Recent(ctx, workflowID)
+}
@@ -0,0 +1,96 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+// Anomaly kinds
+const (
+	// AnomalyDuration is an execution running far longer than the workflow
+	// usually runs
+	AnomalyDuration = "duration"
+	// AnomalyErrorBurst is a window with far more failed executions than
+	// the workflow usually fails
+	AnomalyErrorBurst = "error_burst"
+)
+
+const (
+	// AnomalySigma is how many standard deviations above its baseline a
+	// measure is anomalous
+	AnomalySigma = 3
+	// MinBaselineSamples is how many executions of a workflow are learned
+	// before its anomalies are reported
+	MinBaselineSamples = 30
+	// DurationAlpha weighs each execution in the duration baseline, which
+	// follows the last hundred or so executions
+	DurationAlpha = 0.02
+	// FailureAlpha weighs each execution in the failure rate baseline
+	FailureAlpha = 0.01
+	// MinDurationStdRatio floors the deviation of steady durations, as a
+	// share of their mean, so jitter is not reported
+	MinDurationStdRatio = 0.05
+	// BurstWindow is the window failed executions are counted in for
+	// bursts
+	BurstWindow = 5 * time.Minute
+	// MinBurstFailures is how many executions fail within a window before
+	// it can be a burst
+	MinBurstFailures = 5
+)
+
+// Baseline is the exponentially weighted mean and variance of a measure of
+// the executions of a workflow
+type Baseline struct {
+	Count    int64
+	Mean     float64
+	Variance float64
+}
+
+// Learned reports whether enough executions were observed to judge one
+func (b Baseline) Learned() bool {
+	return b.Count >= MinBaselineSamples
+}
+
+// DurationDeviation is how many standard deviations a duration is above
+// the mean
+func (b Baseline) DurationDeviation(durationMs float64) float64 {
+	std := math.Max(math.Sqrt(b.Variance), b.Mean*MinDurationStdRatio)
+	if std == 0 {
+		return 0
+	}
+	return (durationMs - b.Mean) / std
+}
+
+// BurstThreshold is the failure rate of finished executions above which a
+// window is a burst, the mean failure rate of the baseline plus
+// AnomalySigma standard errors of a window of that size
+func (b Baseline) BurstThreshold(finished int64) float64 {
+	p := b.Mean
+	return p + AnomalySigma*math.Sqrt(p*(1-p)/float64(finished))
+}
+
+// BurstWindowStart is the start of the window failures at t are counted in
+func BurstWindowStart(t time.Time) time.Time {
+	return t.UTC().Truncate(BurstWindow)
+}
+
+// Anomaly is an execution, or a window of executions, of a workflow far
+// from its baseline
+type Anomaly struct {
+	Kind       string `json:"kind"`
+	WorkflowID string `json:"workflowId"`
+	// ExecutionID is the execution of a duration anomaly
+	ExecutionID string `json:"executionId,omitempty"`
+	// Value is the duration in ms, or the failure rate of the window
+	Value    float64 `json:"value"`
+	Baseline float64 `json:"baseline"`
+	// Threshold is the value above which it is anomalous
+	Threshold float64 `json:"threshold"`
+	// Sigma is how many standard deviations Value is above the baseline
+	Sigma float64 `json:"sigma"`
+	// WindowStart, Failed and Finished describe the window of a burst
+	WindowStart *time.Time `json:"windowStart,omitempty"`
+	Failed      int64      `json:"failed,omitempty"`
+	Finished    int64      `json:"finished,omitempty"`
+	DetectedAt  time.Time  `json:"detectedAt"`
+}
@@ -0,0 +1,85 @@
+// Package analytics holds the reports of the analytics service
+package analytics
+
+import (
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+const (
+	// DefaultDashboardDays is the range of a dashboard when none is asked
+	DefaultDashboardDays = 7
+	// MaxDashboardDays bounds the range of a dashboard
+	MaxDashboardDays = 90
+	// TopWorkflows is how many of the most executed workflows a dashboard
+	// lists
+	TopWorkflows = 5
+)
+
+var ErrInvalidDashboard = apperrors.New(apperrors.CategoryValidation, "INVALID_DASHBOARD", "invalid dashboard request")
+
+// DashboardFilter selects the executions and KPIs of a dashboard: those of
+// the last Days days, of one workflow when WorkflowID is set
+type DashboardFilter struct {
+	WorkflowID string
+	Days       int
+}
+
+// Since is the start of the first day of the range, in UTC
+func (f DashboardFilter) Since(now time.Time) time.Time {
+	day := now.UTC().Truncate(24 * time.Hour)
+	return day.AddDate(0, 0, -(f.Days - 1))
+}
+
+// Dashboard reports the executions of the workflows of a tenant next to the
+// business metrics their metric nodes emitted, so teams measure outcomes
+// and not only runs
+type Dashboard struct {
+	WorkflowID       string             `json:"workflowId,omitempty"`
+	Since            time.Time          `json:"since"`
+	Days             int                `json:"days"`
+	TotalWorkflows   int64              `json:"totalWorkflows"`
+	ActiveWorkflows  int64              `json:"activeWorkflows"`
+	TotalExecutions  int64              `json:"totalExecutions"`
+	SuccessRate      float64            `json:"successRate"`
+	AvgExecutionTime float64            `json:"avgExecutionTime"`
+	ExecutionsByDay  []*DailyCount      `json:"executionsByDay"`
+	TopWorkflows     []*WorkflowSummary `json:"topWorkflows"`
+	// KPIs are the metrics of each workflow per day, KPITotals their sums
+	// over the range
+	KPIs      []*DailyKPI `json:"kpis"`
+	KPITotals []*KPITotal `json:"kpiTotals"`
+}
+
+// DailyCount counts the executions started on a day
+type DailyCount struct {
+	Date    string `json:"date"`
+	Count   int64  `json:"count"`
+	Success int64  `json:"success"`
+	Failed  int64  `json:"failed"`
+}
+
+// WorkflowSummary reports the executions of a workflow over the range
+type WorkflowSummary struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	ExecutionCount int64   `json:"executionCount"`
+	SuccessRate    float64 `json:"successRate"`
+}
+
+// DailyKPI is the sum of a metric emitted by a workflow on a day
+type DailyKPI struct {
+	Date       string  `json:"date"`
+	WorkflowID string  `json:"workflowId"`
+	Name       string  `json:"name"`
+	Value      float64 `json:"value"`
+	Samples    int64   `json:"samples"`
+}
+
+// KPITotal is the sum of a metric over the range
+type KPITotal struct {
+	Name    string  `json:"name"`
+	Value   float64 `json:"value"`
+	Samples int64   `json:"samples"`
+}
@@ -0,0 +1,39 @@
+package analytics
+
+import "time"
+
+// DayFormat is the form of the UTC days executions are counted in
+const DayFormat = "2006-01-02"
+
+// ExecutionCounts are the executions of a workflow started on a day, by
+// outcome once they finished
+type ExecutionCounts struct {
+	Started   int64 `json:"started"`
+	Succeeded int64 `json:"succeeded"`
+	Failed    int64 `json:"failed"`
+	Cancelled int64 `json:"cancelled"`
+	// Finished counts the executions DurationMs sums the duration of
+	Finished   int64 `json:"finished"`
+	DurationMs int64 `json:"durationMs"`
+}
+
+// DailyExecutions is the aggregate of the executions of a workflow started
+// on a UTC day, maintained from the execution events so dashboards do not
+// scan the executions
+type DailyExecutions struct {
+	TenantID        string `json:"tenantId" gorm:"size:64;primaryKey;default:'default'"`
+	Day             string `json:"day" gorm:"primaryKey;size:10"`
+	WorkflowID      string `json:"workflowId" gorm:"primaryKey"`
+	ExecutionCounts `gorm:"embedded"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (DailyExecutions) TableName() string {
+	return "analytics.daily_executions"
+}
+
+// Day is the day an execution started at t is counted in
+func Day(t time.Time) string {
+	return t.UTC().Format(DayFormat)
+}
@@ -1,8 +1,16 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"time"
+
+	analytics "github.com/linkflow-go/internal/analytics/domain"
+)
 
 type AnalyticsRepository interface {
 	SaveMetric(ctx context.Context, metric interface{}) error
 	GetMetrics(ctx context.Context) ([]interface{}, error)
+	GetDashboard(ctx context.Context, filter analytics.DashboardFilter, since time.Time) (*analytics.Dashboard, error)
+	RecordExecutions(ctx context.Context, workflowID, day string, counts analytics.ExecutionCounts) error
+	GetWorkflowOwner(ctx context.Context, workflowID string) (string, error)
 }
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	analytics "github.com/linkflow-go/internal/analytics/domain"
+	"github.com/linkflow-go/pkg/events"
+)
+
+type AnomalyDetector interface {
+	// Observe learns from a finished execution and reports it when
+	// anomalous
+	Observe(ctx context.Context, event events.Event) error
+	// Recent returns the latest anomalies, of one workflow when workflowID
+	// is set
+	Recent(ctx context.Context, workflowID string) ([]*analytics.Anomaly, error)
+}
@@ -2,9 +2,13 @@ package ports
 
 import (
 	"context"
+
+	"github.com/linkflow-go/pkg/events"
 )
 
 type MetricsAggregator interface {
 	Start(ctx context.Context)
 	Stop()
+	// RecordExecution adds an execution event to the daily aggregates
+	RecordExecution(ctx context.Context, event events.Event) error
 }
@@ -10,12 +10,17 @@ import (
 	"github.com/linkflow-go/internal/analytics/adapters/db/repository"
 	"github.com/linkflow-go/internal/analytics/adapters/http/handlers"
 	"github.com/linkflow-go/internal/analytics/app/aggregator"
+	"github.com/linkflow-go/internal/analytics/app/anomaly"
 	"github.com/linkflow-go/internal/analytics/app/service"
 	"github.com/linkflow-go/internal/analytics/ports"
+	"github.com/linkflow-go/pkg/apidoc"
 	"github.com/linkflow-go/pkg/config"
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/middleware/correlation"
+	tenantmw "github.com/linkflow-go/pkg/middleware/tenant"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 )
@@ -51,7 +56,7 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	}
 
 	// Initialize event bus
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	eventBus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
@@ -65,11 +70,25 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	// Initialize service
 	analyticsService := service.NewAnalyticsService(analyticsRepo, metricsAggregator, eventBus, log)
 
+	// Executions far from the baseline of their workflow are reported
+	analyticsService.SetAnomalyDetector(anomaly.NewDetector(analyticsRepo, redisClient, eventBus, log))
+
 	// Initialize handlers
 	analyticsHandlers := handlers.NewAnalyticsHandlers(analyticsService, log)
 
+	// Readiness reports the state of each dependency
+	checker := health.NewChecker("analytics-service")
+	checker.Critical("database", health.Database(db))
+	checker.Critical("redis", health.Redis(redisClient))
+	checker.Optional("event_bus", health.EventBus(eventBus))
+
 	// Setup HTTP server
-	router := setupRouter(analyticsHandlers, log)
+	router := setupRouter(analyticsHandlers, checker, log)
+
+	// Serve the OpenAPI document of the routes registered above
+	if err := apidoc.Serve(router, "analytics", cfg.Server.StrictAPISpec, log); err != nil {
+		return nil, err
+	}
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -94,21 +113,23 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	}, nil
 }
 
-func setupRouter(h *handlers.AnalyticsHandlers, log logger.Logger) *gin.Engine {
+func setupRouter(h *handlers.AnalyticsHandlers, checker *health.Checker, log logger.Logger) *gin.Engine {
 	router := gin.New()
 
 	// Middleware
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
 	router.Use(corsMiddleware())
 	router.Use(loggingMiddleware(log))
 
 	// Health checks
 	router.GET("/health/live", h.Health)
-	router.GET("/health/ready", h.Ready)
+	router.GET("/health/ready", checker.ReadyHandler())
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// API routes
-	v1 := router.Group("/api/v1/analytics")
+	v1 := router.Group(apidoc.Prefix + "/analytics")
+	v1.Use(tenantmw.Middleware())
 	{
 		// Dashboard endpoints
 		v1.GET("/dashboard", h.GetDashboard)
@@ -147,6 +168,7 @@ func subscribeToEvents(eventBus events.EventBus, service *service.AnalyticsServi
 		"execution.started",
 		"execution.completed",
 		"execution.failed",
+		"execution.cancelled",
 		"workflow.created",
 		"workflow.updated",
 		"workflow.deleted",
@@ -210,7 +232,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -239,7 +261,7 @@ func loggingMiddleware(log logger.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		log.Info("HTTP Request",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP Request",
 			"method", method,
 			"path", path,
 			"status", statusCode,
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/linkflow-go/internal/audit/domain"
 	"github.com/linkflow-go/pkg/database"
+	"gorm.io/gorm/clause"
 )
 
 type AuditRepository struct {
@@ -22,3 +25,87 @@ func (r *AuditRepository) GetAuditLogs(ctx context.Context, filters map[string]i
 	var logs []interface{}
 	return logs, nil
 }
+
+// CreateDataExport records an export, ignoring redeliveries of the same one
+func (r *AuditRepository) CreateDataExport(ctx context.Context, export *domain.DataExport) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(export).Error
+}
+
+// ListDataExports returns the exports made in [from, to), newest first
+func (r *AuditRepository) ListDataExports(ctx context.Context, from, to time.Time) ([]*domain.DataExport, error) {
+	var exports []*domain.DataExport
+	err := r.db.WithContext(ctx).
+		Where("exported_at >= ? AND exported_at < ?", from, to).
+		Order("exported_at DESC").
+		Find(&exports).Error
+
+	return exports, err
+}
+
+// StoreEvent adds an event to the event store, ignoring redeliveries and
+// replays of a stored event
+func (r *AuditRepository) StoreEvent(ctx context.Context, event *domain.StoredEvent) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(event).Error
+}
+
+// GetEvent returns a stored event by ID
+func (r *AuditRepository) GetEvent(ctx context.Context, id string) (*domain.StoredEvent, error) {
+	var event domain.StoredEvent
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// ListEvents returns a page of the stored events matching filter, oldest
+// first, with the number of matching events
+func (r *AuditRepository) ListEvents(ctx context.Context, filter domain.EventFilter) ([]*domain.StoredEvent, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.StoredEvent{})
+	if filter.AggregateID != "" {
+		query = query.Where("aggregate_id = ?", filter.AggregateID)
+	}
+	if filter.AggregateType != "" {
+		query = query.Where("aggregate_type = ?", filter.AggregateType)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.CorrelationID != "" {
+		query = query.Where("correlation_id = ?", filter.CorrelationID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("occurred_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("occurred_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []*domain.StoredEvent
+	err := query.
+		Order("occurred_at ASC, id ASC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&events).Error
+
+	return events, total, err
+}
+
+// GetEventsByIDs returns the stored events with the given IDs, oldest first
+func (r *AuditRepository) GetEventsByIDs(ctx context.Context, ids []string) ([]*domain.StoredEvent, error) {
+	var events []*domain.StoredEvent
+	err := r.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Order("occurred_at ASC, id ASC").
+		Find(&events).Error
+
+	return events, err
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/internal/audit/domain"
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// ListEvents browses the event store. Events are filtered by the
+// aggregateId, aggregateType, type and correlationId query parameters and
+// by RFC 3339 "from" and "to", and paged with limit and offset.
+func (h *AuditHandlers) ListEvents(c *gin.Context) {
+	filter := domain.EventFilter{
+		AggregateID:   c.Query("aggregateId"),
+		AggregateType: c.Query("aggregateType"),
+		Type:          c.Query("type"),
+		CorrelationID: c.Query("correlationId"),
+	}
+
+	var err error
+	if filter.From, err = queryTime(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.To, err = queryTime(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.Limit, err = queryInt(c, "limit"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.Offset, err = queryInt(c, "offset"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.service.ListEvents(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to list events", "error", err)
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetEvent returns a stored event with its payload
+func (h *AuditHandlers) GetEvent(c *gin.Context) {
+	event, err := h.service.GetEvent(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// ReplayEvents publishes stored events again to the consumer of the request
+func (h *AuditHandlers) ReplayEvents(c *gin.Context) {
+	var req domain.ReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	result, err := h.service.ReplayEvents(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to replay events", "consumer", req.Consumer, "error", err)
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// queryTime parses an optional RFC 3339 query parameter
+func queryTime(c *gin.Context, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+	return t, nil
+}
+
+// queryInt parses an optional non-negative integer query parameter
+func queryInt(c *gin.Context, name string) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return n, nil
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/linkflow-go/internal/audit/app/service"
@@ -24,10 +25,6 @@ func (h *AuditHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
-func (h *AuditHandlers) Ready(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
-}
-
 func (h *AuditHandlers) GetAuditLogs(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"logs": []interface{}{}})
 }
@@ -68,6 +65,47 @@ func (h *AuditHandlers) GetHIPAAReport(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"report": "HIPAA compliance report"})
 }
 
+// DefaultExportReportPeriod is used when the report request has no "from"
+const DefaultExportReportPeriod = 30 * 24 * time.Hour
+
+// GetDataExportReport lists all execution data exports in a period, given
+// as RFC 3339 "from" and "to" query parameters
+func (h *AuditHandlers) GetDataExportReport(c *gin.Context) {
+	to := time.Now().UTC()
+	if value := c.Query("to"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp"})
+			return
+		}
+		to = t
+	}
+
+	from := to.Add(-DefaultExportReportPeriod)
+	if value := c.Query("from"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp"})
+			return
+		}
+		from = t
+	}
+
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	report, err := h.service.GetDataExportReport(c.Request.Context(), from, to)
+	if err != nil {
+		h.logger.Error("Failed to build data export report", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build data export report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 func (h *AuditHandlers) GetActivityTimeline(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"timeline": []interface{}{}})
 }
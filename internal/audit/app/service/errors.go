@@ -0,0 +1,9 @@
+package service
+
+import apperrors "github.com/linkflow-go/pkg/errors"
+
+var (
+	ErrEventNotFound  = apperrors.New(apperrors.CategoryNotFound, "EVENT_NOT_FOUND", "Event not found")
+	ErrInvalidReplay  = apperrors.New(apperrors.CategoryValidation, "INVALID_REPLAY", "Replay request is invalid")
+	ErrReplayTooLarge = apperrors.New(apperrors.CategoryValidation, "REPLAY_TOO_LARGE", "Replay selects too many events")
+)
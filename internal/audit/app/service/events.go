@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/linkflow-go/internal/audit/domain"
+	"github.com/linkflow-go/pkg/events"
+	"gorm.io/gorm"
+)
+
+// storeEvent adds an event to the event store
+func (s *AuditService) storeEvent(ctx context.Context, event events.Event) error {
+	stored, err := domain.NewStoredEvent(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+	}
+	if err := s.repo.StoreEvent(ctx, stored); err != nil {
+		return fmt.Errorf("failed to store event %s: %w", event.ID, err)
+	}
+	return nil
+}
+
+// ListEvents returns a page of the stored events matching filter
+func (s *AuditService) ListEvents(ctx context.Context, filter domain.EventFilter) (*domain.EventPage, error) {
+	if filter.Limit <= 0 || filter.Limit > domain.MaxEventPageSize {
+		filter.Limit = domain.MaxEventPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	stored, total, err := s.repo.ListEvents(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	page := &domain.EventPage{
+		Events: make([]*domain.EventView, 0, len(stored)),
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+	for _, e := range stored {
+		view, err := viewEvent(e)
+		if err != nil {
+			return nil, err
+		}
+		page.Events = append(page.Events, view)
+	}
+	return page, nil
+}
+
+// GetEvent returns a stored event by ID
+func (s *AuditService) GetEvent(ctx context.Context, id string) (*domain.EventView, error) {
+	stored, err := s.repo.GetEvent(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrEventNotFound.WithDetail("id", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	return viewEvent(stored)
+}
+
+// ReplayEvents publishes the selected stored events again, oldest first,
+// addressed to the consumer of the request. Other consumers skip them, and
+// handlers see the original event ID so idempotent ones can tell a replay
+// from a new event.
+func (s *AuditService) ReplayEvents(ctx context.Context, req domain.ReplayRequest) (*domain.ReplayResult, error) {
+	if req.Consumer == "" {
+		return nil, ErrInvalidReplay.WithMessage("consumer is required")
+	}
+
+	stored, err := s.selectReplay(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ReplayResult{
+		Consumer: req.Consumer,
+		Selected: len(stored),
+		EventIDs: make([]string, 0, len(stored)),
+		DryRun:   req.DryRun,
+	}
+	for _, e := range stored {
+		result.EventIDs = append(result.EventIDs, e.ID)
+	}
+	if req.DryRun {
+		return result, nil
+	}
+
+	for _, e := range stored {
+		event, err := e.Decode()
+		if err != nil {
+			return result, fmt.Errorf("failed to decode event %s: %w", e.ID, err)
+		}
+		event.Metadata.ReplayTo = req.Consumer
+
+		if err := s.eventBus.Publish(ctx, event); err != nil {
+			return result, fmt.Errorf("failed to replay event %s: %w", e.ID, err)
+		}
+		result.Published++
+	}
+
+	s.logger.Info("Replayed events",
+		"consumer", req.Consumer,
+		"events", result.Published,
+	)
+	return result, nil
+}
+
+// selectReplay loads the events of a replay request, by ID or by filter
+func (s *AuditService) selectReplay(ctx context.Context, req domain.ReplayRequest) ([]*domain.StoredEvent, error) {
+	if len(req.EventIDs) > 0 {
+		if len(req.EventIDs) > domain.MaxReplayEvents {
+			return nil, ErrReplayTooLarge.WithMessage("at most %d events can be replayed at once", domain.MaxReplayEvents)
+		}
+
+		stored, err := s.repo.GetEventsByIDs(ctx, req.EventIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load events: %w", err)
+		}
+		if missing := missingEvents(req.EventIDs, stored); len(missing) > 0 {
+			return nil, ErrEventNotFound.WithDetail("ids", missing)
+		}
+		return stored, nil
+	}
+
+	filter := domain.EventFilter{
+		AggregateID:   req.AggregateID,
+		AggregateType: req.AggregateType,
+		Type:          req.Type,
+		CorrelationID: req.CorrelationID,
+		From:          req.From,
+		To:            req.To,
+		Limit:         domain.MaxReplayEvents,
+	}
+	if filter == (domain.EventFilter{Limit: domain.MaxReplayEvents}) {
+		return nil, ErrInvalidReplay.WithMessage("select events by ID or by at least one filter")
+	}
+
+	stored, total, err := s.repo.ListEvents(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	if total > domain.MaxReplayEvents {
+		return nil, ErrReplayTooLarge.
+			WithMessage("the filter selects %d events, at most %d can be replayed at once", total, domain.MaxReplayEvents)
+	}
+	return stored, nil
+}
+
+func missingEvents(ids []string, stored []*domain.StoredEvent) []string {
+	found := make(map[string]bool, len(stored))
+	for _, e := range stored {
+		found[e.ID] = true
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+func viewEvent(stored *domain.StoredEvent) (*domain.EventView, error) {
+	event, err := stored.Decode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode event %s: %w", stored.ID, err)
+	}
+	return &domain.EventView{
+		StoredEvent: stored,
+		Payload:     event.Payload,
+		Metadata:    event.Metadata,
+	}, nil
+}
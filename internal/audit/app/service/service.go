@@ -2,8 +2,13 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
+	"github.com/linkflow-go/internal/audit/domain"
 	"github.com/linkflow-go/internal/audit/ports"
+	"github.com/linkflow-go/pkg/contracts/execution"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
 )
@@ -24,6 +29,15 @@ func NewAuditService(repo ports.AuditRepository, eventBus events.EventBus, logge
 
 func (s *AuditService) LogEvent(ctx context.Context, event events.Event) error {
 	s.logger.Info("Logging audit event", "type", event.Type, "id", event.ID)
+
+	if err := s.storeEvent(ctx, event); err != nil {
+		return err
+	}
+
+	if event.Type == events.ExecutionDataExported {
+		return s.recordDataExport(ctx, event)
+	}
+
 	// Audit logging logic
 	return nil
 }
@@ -31,3 +45,59 @@ func (s *AuditService) LogEvent(ctx context.Context, event events.Event) error {
 func (s *AuditService) GetAuditLogs(ctx context.Context, filters map[string]interface{}) ([]interface{}, error) {
 	return s.repo.GetAuditLogs(ctx, filters)
 }
+
+// GetDataExportReport lists every data export made in [from, to)
+func (s *AuditService) GetDataExportReport(ctx context.Context, from, to time.Time) (*domain.DataExportReport, error) {
+	exports, err := s.repo.ListDataExports(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data exports: %w", err)
+	}
+	return domain.NewDataExportReport(from, to, exports), nil
+}
+
+// recordDataExport stores the watermark carried by an export event. The
+// payload is decoded through JSON so it reads the same whether the event
+// came over Kafka or in process.
+func (s *AuditService) recordDataExport(ctx context.Context, event events.Event) error {
+	raw, err := json.Marshal(event.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode export payload: %w", err)
+	}
+
+	var payload struct {
+		execution.Watermark
+		StorageKey string `json:"storageKey"`
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return fmt.Errorf("failed to decode export payload: %w", err)
+	}
+	if payload.ExportID == "" {
+		return fmt.Errorf("export event %s has no export id", event.ID)
+	}
+
+	export := &domain.DataExport{
+		ID:          payload.ExportID,
+		ExportedBy:  payload.ExportedBy,
+		ExportedAt:  payload.ExportedAt,
+		Format:      payload.Format,
+		Destination: payload.Destination,
+		Scope:       payload.Scope,
+		RecordCount: payload.RecordCount,
+		Checksum:    payload.Checksum,
+		StorageKey:  payload.StorageKey,
+		EventID:     event.ID,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.repo.CreateDataExport(ctx, export); err != nil {
+		return fmt.Errorf("failed to record data export: %w", err)
+	}
+
+	s.logger.Info("Recorded data export",
+		"exportId", export.ID,
+		"exportedBy", export.ExportedBy,
+		"destination", export.Destination,
+		"records", export.RecordCount,
+	)
+	return nil
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/execution"
+)
+
+// DataExport is the audit record of a watermarked execution data export
+type DataExport struct {
+	ID          string                `json:"id" gorm:"primaryKey"`
+	ExportedBy  string                `json:"exportedBy"`
+	ExportedAt  time.Time             `json:"exportedAt"`
+	Format      string                `json:"format"`
+	Destination string                `json:"destination"`
+	Scope       execution.ExportScope `json:"scope" gorm:"serializer:json"`
+	RecordCount int                   `json:"recordCount"`
+	Checksum    string                `json:"checksum"`
+	StorageKey  string                `json:"storageKey,omitempty"`
+	EventID     string                `json:"eventId"`
+	CreatedAt   time.Time             `json:"createdAt"`
+}
+
+// TableName specifies the table name for GORM
+func (DataExport) TableName() string {
+	return "audit.data_exports"
+}
+
+// DataExportReport summarizes the data exports made in a period
+type DataExportReport struct {
+	From          time.Time      `json:"from"`
+	To            time.Time      `json:"to"`
+	TotalExports  int            `json:"totalExports"`
+	TotalRecords  int            `json:"totalRecords"`
+	ByExporter    map[string]int `json:"byExporter"`
+	ByDestination map[string]int `json:"byDestination"`
+	Exports       []*DataExport  `json:"exports"`
+}
+
+// NewDataExportReport aggregates exports into a report for the period
+func NewDataExportReport(from, to time.Time, exports []*DataExport) *DataExportReport {
+	report := &DataExportReport{
+		From:          from,
+		To:            to,
+		TotalExports:  len(exports),
+		ByExporter:    make(map[string]int),
+		ByDestination: make(map[string]int),
+		Exports:       exports,
+	}
+	if report.Exports == nil {
+		report.Exports = []*DataExport{}
+	}
+
+	for _, export := range exports {
+		report.TotalRecords += export.RecordCount
+		report.ByExporter[export.ExportedBy]++
+		report.ByDestination[export.Destination]++
+	}
+
+	return report
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/linkflow-go/pkg/events"
+)
+
+// MaxEventPageSize bounds the events returned by one browse request
+const MaxEventPageSize = 500
+
+// MaxReplayEvents bounds the events replayed by one request
+const MaxReplayEvents = 1000
+
+// StoredEvent is a published event kept in the event store. The columns
+// are copied from the event for browsing, Event holds it as published.
+type StoredEvent struct {
+	ID            string    `json:"id" gorm:"primaryKey"`
+	Type          string    `json:"type"`
+	AggregateID   string    `json:"aggregateId"`
+	AggregateType string    `json:"aggregateType"`
+	UserID        string    `json:"userId"`
+	CorrelationID string    `json:"correlationId"`
+	Version       int       `json:"version"`
+	Event         string    `json:"-" gorm:"type:jsonb"`
+	OccurredAt    time.Time `json:"occurredAt"`
+	StoredAt      time.Time `json:"storedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (StoredEvent) TableName() string {
+	return "audit.event_store"
+}
+
+// NewStoredEvent copies event for the event store
+func NewStoredEvent(event events.Event) (*StoredEvent, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StoredEvent{
+		ID:            event.ID,
+		Type:          event.Type,
+		AggregateID:   event.AggregateID,
+		AggregateType: event.AggregateType,
+		UserID:        event.UserID,
+		CorrelationID: event.Metadata.CorrelationID,
+		Version:       event.Version,
+		Event:         string(data),
+		OccurredAt:    event.Timestamp,
+		StoredAt:      time.Now().UTC(),
+	}, nil
+}
+
+// Decode returns the event as it was published
+func (e *StoredEvent) Decode() (events.Event, error) {
+	var event events.Event
+	err := json.Unmarshal([]byte(e.Event), &event)
+	return event, err
+}
+
+// EventFilter selects stored events, empty fields match every event
+type EventFilter struct {
+	AggregateID   string
+	AggregateType string
+	Type          string
+	CorrelationID string
+	From          time.Time
+	To            time.Time
+	Limit         int
+	Offset        int
+}
+
+// EventPage is a page of stored events, oldest first
+type EventPage struct {
+	Events []*EventView `json:"events"`
+	Total  int64        `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
+
+// EventView is a stored event with its decoded payload and metadata
+type EventView struct {
+	*StoredEvent
+	Payload  map[string]interface{} `json:"payload"`
+	Metadata events.EventMetadata   `json:"metadata"`
+}
+
+// ReplayRequest selects stored events to publish again to one consumer,
+// by ID or by filter
+type ReplayRequest struct {
+	Consumer string   `json:"consumer" binding:"required"`
+	EventIDs []string `json:"eventIds"`
+	// Filter is used when EventIDs is empty
+	AggregateID   string    `json:"aggregateId"`
+	AggregateType string    `json:"aggregateType"`
+	Type          string    `json:"type"`
+	CorrelationID string    `json:"correlationId"`
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	// DryRun lists the selected events without publishing them
+	DryRun bool `json:"dryRun"`
+}
+
+// ReplayResult reports the events a replay published
+type ReplayResult struct {
+	Consumer  string   `json:"consumer"`
+	Selected  int      `json:"selected"`
+	Published int      `json:"published"`
+	EventIDs  []string `json:"eventIds"`
+	DryRun    bool     `json:"dryRun"`
+}
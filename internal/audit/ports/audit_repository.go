@@ -1,7 +1,18 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/linkflow-go/internal/audit/domain"
+)
 
 type AuditRepository interface {
 	GetAuditLogs(ctx context.Context, filters map[string]interface{}) ([]interface{}, error)
+	CreateDataExport(ctx context.Context, export *domain.DataExport) error
+	ListDataExports(ctx context.Context, from, to time.Time) ([]*domain.DataExport, error)
+	StoreEvent(ctx context.Context, event *domain.StoredEvent) error
+	GetEvent(ctx context.Context, id string) (*domain.StoredEvent, error)
+	ListEvents(ctx context.Context, filter domain.EventFilter) ([]*domain.StoredEvent, int64, error)
+	GetEventsByIDs(ctx context.Context, ids []string) ([]*domain.StoredEvent, error)
 }
@@ -2,18 +2,24 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/linkflow-go/internal/audit/adapters/db/repository"
 	"github.com/linkflow-go/internal/audit/adapters/http/handlers"
 	"github.com/linkflow-go/internal/audit/app/service"
+	"github.com/linkflow-go/pkg/apidoc"
 	"github.com/linkflow-go/pkg/config"
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/events/schema"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/middleware/correlation"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 )
@@ -48,7 +54,7 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	}
 
 	// Initialize event bus
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	eventBus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
@@ -62,8 +68,30 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	// Initialize handlers
 	auditHandlers := handlers.NewAuditHandlers(auditService, log)
 
+	// Readiness reports the state of each dependency
+	checker := health.NewChecker("audit-service")
+	checker.Critical("database", health.Database(db))
+	checker.Critical("redis", health.Redis(redisClient))
+	checker.Optional("event_bus", health.EventBus(eventBus))
+
 	// Setup HTTP server
-	router := setupRouter(auditHandlers, log)
+	router := setupRouter(auditHandlers, checker, log)
+
+	// Browsing and replaying the event store is only served with an admin
+	// token, replays reach every service
+	if cfg.Server.AdminToken != "" {
+		admin := router.Group("/admin/events", adminAuth(cfg.Server.AdminToken))
+		admin.GET("", auditHandlers.ListEvents)
+		admin.GET("/:id", auditHandlers.GetEvent)
+		admin.POST("/replay", auditHandlers.ReplayEvents)
+	} else {
+		log.Info("Event store admin API disabled, no admin token configured")
+	}
+
+	// Serve the OpenAPI document of the routes registered above
+	if err := apidoc.Serve(router, "audit", cfg.Server.StrictAPISpec, log); err != nil {
+		return nil, err
+	}
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -72,7 +100,7 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 	}
 
-	// Subscribe to ALL events for audit logging
+	// Subscribe to ALL events for audit logging and the event store
 	if err := subscribeToEvents(eventBus, auditService); err != nil {
 		return nil, fmt.Errorf("failed to subscribe to events: %w", err)
 	}
@@ -87,21 +115,22 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	}, nil
 }
 
-func setupRouter(h *handlers.AuditHandlers, log logger.Logger) *gin.Engine {
+func setupRouter(h *handlers.AuditHandlers, checker *health.Checker, log logger.Logger) *gin.Engine {
 	router := gin.New()
 
 	// Middleware
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
 	router.Use(corsMiddleware())
 	router.Use(loggingMiddleware(log))
 
 	// Health checks
 	router.GET("/health/live", h.Health)
-	router.GET("/health/ready", h.Ready)
+	router.GET("/health/ready", checker.ReadyHandler())
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// API routes
-	v1 := router.Group("/api/v1/audit")
+	v1 := router.Group(apidoc.Prefix + "/audit")
 	{
 		// Audit log queries
 		v1.GET("/logs", h.GetAuditLogs)
@@ -114,6 +143,7 @@ func setupRouter(h *handlers.AuditHandlers, log logger.Logger) *gin.Engine {
 		v1.GET("/compliance/gdpr", h.GetGDPRReport)
 		v1.GET("/compliance/soc2", h.GetSOC2Report)
 		v1.GET("/compliance/hipaa", h.GetHIPAAReport)
+		v1.GET("/compliance/exports", h.GetDataExportReport)
 
 		// Activity tracking
 		v1.GET("/activity/timeline", h.GetActivityTimeline)
@@ -135,15 +165,33 @@ func setupRouter(h *handlers.AuditHandlers, log logger.Logger) *gin.Engine {
 }
 
 func subscribeToEvents(eventBus events.EventBus, service *service.AuditService) error {
-	// Subscribe to ALL events with wildcard pattern
-	// This ensures complete audit trail
-	if err := eventBus.Subscribe("*.*", service.LogEvent); err != nil {
-		return fmt.Errorf("failed to subscribe to all events: %w", err)
+	// Event types are topics, so subscribe to each type of the schema
+	// catalog
+	for _, eventType := range schema.Default.Types() {
+		if err := eventBus.Subscribe(eventType, service.LogEvent); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", eventType, err)
+		}
 	}
 
 	return nil
 }
 
+// adminAuth requires the admin token as a bearer token
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if provided == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing admin token"})
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
+
 func (s *Server) Start() error {
 	s.logger.Info("Starting HTTP server", "port", s.config.Server.Port)
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -183,7 +231,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -212,7 +260,7 @@ func loggingMiddleware(log logger.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		log.Info("HTTP Request",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP Request",
 			"method", method,
 			"path", path,
 			"status", statusCode,
@@ -12,21 +12,31 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/auth/jwt"
+	authmw "github.com/linkflow-go/pkg/middleware/auth"
 )
 
 // APIKey represents an API key for programmatic access
 type APIKey struct {
-	ID          string     `json:"id" gorm:"primaryKey;size:36"`
-	UserID      string     `json:"userId" gorm:"size:36;index;not null"`
-	Name        string     `json:"name" gorm:"size:255;not null"`
-	KeyPrefix   string     `json:"keyPrefix" gorm:"size:12;not null"` // First 12 chars for identification
-	KeyHash     string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
-	Permissions []string   `json:"permissions" gorm:"-"`
-	PermJSON    string     `json:"-" gorm:"column:permissions;type:text"`
-	LastUsedAt  *time.Time `json:"lastUsedAt"`
-	ExpiresAt   *time.Time `json:"expiresAt"`
-	CreatedAt   time.Time  `json:"createdAt" gorm:"autoCreateTime"`
-	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+	ID          string   `json:"id" gorm:"primaryKey;size:36"`
+	UserID      string   `json:"userId" gorm:"size:36;index;not null"`
+	Name        string   `json:"name" gorm:"size:255;not null"`
+	KeyPrefix   string   `json:"keyPrefix" gorm:"size:12;not null"` // First 12 chars for identification
+	KeyHash     string   `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	Permissions []string `json:"permissions" gorm:"-"`
+	PermJSON    string   `json:"-" gorm:"column:permissions;type:text"`
+	// Tenant, workspace, plan and quota hints let edge services authorize
+	// the key without a round trip to the auth service
+	TenantID    string          `json:"tenantId,omitempty" gorm:"size:64;not null;default:'default';index"`
+	WorkspaceID string          `json:"workspaceId,omitempty" gorm:"size:36;index"`
+	Plan        string          `json:"plan,omitempty" gorm:"size:50"`
+	Scopes      []string        `json:"scopes,omitempty" gorm:"-"`
+	ScopesJSON  string          `json:"-" gorm:"column:scopes;type:text"`
+	Quota       *jwt.QuotaHints `json:"quota,omitempty" gorm:"serializer:json"`
+	LastUsedAt  *time.Time      `json:"lastUsedAt"`
+	ExpiresAt   *time.Time      `json:"expiresAt"`
+	CreatedAt   time.Time       `json:"createdAt" gorm:"autoCreateTime"`
+	RevokedAt   *time.Time      `json:"revokedAt,omitempty"`
 }
 
 // TableName returns the table name for GORM
@@ -47,6 +57,28 @@ func (k *APIKey) IsRevoked() bool {
 	return k.RevokedAt != nil
 }
 
+// TokenScope returns the tenant, workspace, plan, scopes and quota hints of
+// the key
+func (k *APIKey) TokenScope() jwt.TokenScope {
+	return jwt.TokenScope{
+		TenantID:    k.TenantID,
+		WorkspaceID: k.WorkspaceID,
+		Plan:        k.Plan,
+		Scopes:      k.Scopes,
+		Quota:       k.Quota,
+	}
+}
+
+// parseLists restores the comma separated permission and scope columns
+func (k *APIKey) parseLists() {
+	if k.PermJSON != "" {
+		k.Permissions = strings.Split(k.PermJSON, ",")
+	}
+	if k.ScopesJSON != "" {
+		k.Scopes = strings.Split(k.ScopesJSON, ",")
+	}
+}
+
 // APIKeyService handles API key operations
 type APIKeyService struct {
 	repository APIKeyRepository
@@ -74,6 +106,7 @@ type CreateAPIKeyRequest struct {
 	Name        string
 	Permissions []string
 	ExpiresIn   *time.Duration // Optional expiry duration
+	Scope       jwt.TokenScope // Tenant, workspace, plan, scopes and quota hints
 }
 
 // CreateAPIKeyResponse contains the created key and raw key value
@@ -118,6 +151,12 @@ func (s *APIKeyService) Create(ctx context.Context, req CreateAPIKeyRequest) (*C
 		KeyHash:     keyHash,
 		Permissions: req.Permissions,
 		PermJSON:    permJSON,
+		TenantID:    req.Scope.TenantID,
+		WorkspaceID: req.Scope.WorkspaceID,
+		Plan:        req.Scope.Plan,
+		Scopes:      req.Scope.Scopes,
+		ScopesJSON:  strings.Join(req.Scope.Scopes, ","),
+		Quota:       req.Scope.Quota,
 		ExpiresAt:   expiresAt,
 		CreatedAt:   time.Now(),
 	}
@@ -154,10 +193,8 @@ func (s *APIKeyService) Validate(ctx context.Context, rawKey string) (*APIKey, e
 		return nil, errors.New("API key has expired")
 	}
 
-	// Parse permissions from JSON
-	if apiKey.PermJSON != "" {
-		apiKey.Permissions = strings.Split(apiKey.PermJSON, ",")
-	}
+	// Parse permissions and scopes
+	apiKey.parseLists()
 
 	// Update last used timestamp
 	now := time.Now()
@@ -166,6 +203,24 @@ func (s *APIKeyService) Validate(ctx context.Context, rawKey string) (*APIKey, e
 	return apiKey, nil
 }
 
+// Validator adapts the service to the API key middleware, keys carry their
+// scope so RequireScope checks them as it checks tokens
+func (s *APIKeyService) Validator() authmw.APIKeyValidator {
+	return authmw.APIKeyValidatorFunc(func(ctx context.Context, rawKey string) (*authmw.APIKeyInfo, error) {
+		key, err := s.Validate(ctx, rawKey)
+		if err != nil {
+			return nil, err
+		}
+		return &authmw.APIKeyInfo{
+			ID:          key.ID,
+			UserID:      key.UserID,
+			TenantID:    key.TenantID,
+			Permissions: key.Permissions,
+			Scope:       key.TokenScope(),
+		}, nil
+	})
+}
+
 // List returns all API keys for a user (without the actual key values)
 func (s *APIKeyService) List(ctx context.Context, userID string) ([]*APIKey, error) {
 	keys, err := s.repository.GetByUserID(ctx, userID)
@@ -173,11 +228,9 @@ func (s *APIKeyService) List(ctx context.Context, userID string) ([]*APIKey, err
 		return nil, fmt.Errorf("failed to list API keys: %w", err)
 	}
 
-	// Parse permissions for each key
+	// Parse permissions and scopes for each key
 	for _, key := range keys {
-		if key.PermJSON != "" {
-			key.Permissions = strings.Split(key.PermJSON, ",")
-		}
+		key.parseLists()
 	}
 
 	return keys, nil
@@ -3,9 +3,11 @@ package apikey
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/pkg/auth/jwt"
 	"github.com/linkflow-go/pkg/logger"
 )
 
@@ -27,6 +29,7 @@ func NewHandlers(service *APIKeyService, log logger.Logger) *Handlers {
 type CreateRequest struct {
 	Name        string   `json:"name" binding:"required,min=1,max=255"`
 	Permissions []string `json:"permissions"`
+	Scopes      []string `json:"scopes,omitempty"`    // e.g., "webhooks:trigger", empty for unrestricted, within the caller's scopes
 	ExpiresIn   string   `json:"expiresIn,omitempty"` // e.g., "30d", "90d", "1y", or empty for no expiry
 }
 
@@ -37,6 +40,9 @@ type CreateResponse struct {
 	Key         string     `json:"key"` // Only shown once!
 	KeyPrefix   string     `json:"keyPrefix"`
 	Permissions []string   `json:"permissions"`
+	Scopes      []string   `json:"scopes,omitempty"`
+	WorkspaceID string     `json:"workspaceId,omitempty"`
+	Plan        string     `json:"plan,omitempty"`
 	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
 	CreatedAt   time.Time  `json:"createdAt"`
 }
@@ -52,6 +58,9 @@ type APIKeyResponse struct {
 	Name        string     `json:"name"`
 	KeyPrefix   string     `json:"keyPrefix"`
 	Permissions []string   `json:"permissions"`
+	Scopes      []string   `json:"scopes,omitempty"`
+	WorkspaceID string     `json:"workspaceId,omitempty"`
+	Plan        string     `json:"plan,omitempty"`
 	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty"`
 	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
 	CreatedAt   time.Time  `json:"createdAt"`
@@ -90,11 +99,25 @@ func (h *Handlers) Create(c *gin.Context) {
 		permissions = []string{"workflows:read", "workflows:write", "executions:read"}
 	}
 
+	// Keys inherit the tenant, workspace, plan and quota hints of the
+	// caller's token, and at most its scopes
+	var scope jwt.TokenScope
+	if tokenScope, ok := c.Get("tokenScope"); ok {
+		scope, _ = tokenScope.(jwt.TokenScope)
+	}
+	scopes, err := delegatedScopes(scope, req.Scopes)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	scope.Scopes = scopes
+
 	result, err := h.service.Create(c.Request.Context(), CreateAPIKeyRequest{
 		UserID:      userID.(string),
 		Name:        req.Name,
 		Permissions: permissions,
 		ExpiresIn:   expiresIn,
+		Scope:       scope,
 	})
 	if err != nil {
 		h.logger.Error("Failed to create API key", "error", err)
@@ -108,6 +131,9 @@ func (h *Handlers) Create(c *gin.Context) {
 		Key:         result.RawKey, // Only shown once!
 		KeyPrefix:   result.APIKey.KeyPrefix,
 		Permissions: result.APIKey.Permissions,
+		Scopes:      result.APIKey.Scopes,
+		WorkspaceID: result.APIKey.WorkspaceID,
+		Plan:        result.APIKey.Plan,
 		ExpiresAt:   result.APIKey.ExpiresAt,
 		CreatedAt:   result.APIKey.CreatedAt,
 	})
@@ -136,6 +162,9 @@ func (h *Handlers) List(c *gin.Context) {
 			Name:        key.Name,
 			KeyPrefix:   key.KeyPrefix,
 			Permissions: key.Permissions,
+			Scopes:      key.Scopes,
+			WorkspaceID: key.WorkspaceID,
+			Plan:        key.Plan,
 			LastUsedAt:  key.LastUsedAt,
 			ExpiresAt:   key.ExpiresAt,
 			CreatedAt:   key.CreatedAt,
@@ -212,6 +241,29 @@ func (h *Handlers) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "API key deleted permanently"})
 }
 
+// delegatedScopes returns the scopes of a key created by a caller. A key
+// without scopes is unrestricted, so only unrestricted callers may create
+// one, and scoped callers only delegate the scopes they hold.
+func delegatedScopes(caller jwt.TokenScope, requested []string) ([]string, error) {
+	if len(caller.Scopes) == 0 {
+		return requested, nil
+	}
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("scopes required, the token is limited to %s", strings.Join(caller.Scopes, ", "))
+	}
+
+	var denied []string
+	for _, scope := range requested {
+		if !caller.HasScope(scope) {
+			denied = append(denied, scope)
+		}
+	}
+	if len(denied) > 0 {
+		return nil, fmt.Errorf("scopes not held by the token: %s", strings.Join(denied, ", "))
+	}
+	return requested, nil
+}
+
 // parseDuration parses duration strings like "30d", "90d", "1y"
 func parseDuration(s string) (time.Duration, error) {
 	if len(s) < 2 {
@@ -2,13 +2,17 @@ package apikey
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/pkg/auth/jwt"
 	"github.com/linkflow-go/pkg/logger"
+	authmw "github.com/linkflow-go/pkg/middleware/auth"
 	"gorm.io/gorm"
 )
 
-// SetupRoutes registers API key routes on the given router group
-// This should be called with the protected auth routes group
-func SetupRoutes(protected *gin.RouterGroup, db *gorm.DB, log logger.Logger) *Handlers {
+// SetupRoutes registers API key routes on the given router group. Callers
+// authenticate with an access token, through authenticate, or with an API
+// key, and need the scope to manage keys; middleware runs once they are
+// authenticated.
+func SetupRoutes(router *gin.RouterGroup, db *gorm.DB, log logger.Logger, authenticate gin.HandlerFunc, middleware ...gin.HandlerFunc) *Handlers {
 	// Create repository
 	// Note: Database migrations are handled via SQL migration files in /migrations
 	repo := NewGormAPIKeyRepository(db)
@@ -20,7 +24,10 @@ func SetupRoutes(protected *gin.RouterGroup, db *gorm.DB, log logger.Logger) *Ha
 	handlers := NewHandlers(service, log)
 
 	// Register routes
-	apiKeys := protected.Group("/api-keys")
+	apiKeys := router.Group("/api-keys")
+	apiKeys.Use(authmw.CombinedAuthMiddleware(authenticate, authmw.APIKeyMiddleware(service.Validator())))
+	apiKeys.Use(middleware...)
+	apiKeys.Use(authmw.RequireScope(jwt.ScopeAPIKeysManage, ""))
 	{
 		apiKeys.POST("", handlers.Create)
 		apiKeys.GET("", handlers.List)
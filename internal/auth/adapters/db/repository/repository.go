@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/linkflow-go/pkg/auth/jwt"
 	"github.com/linkflow-go/pkg/contracts/user"
 	"github.com/linkflow-go/pkg/database"
 	"gorm.io/gorm"
@@ -221,3 +223,63 @@ func (r *AuthRepository) GetOAuthToken(ctx context.Context, userID, provider str
 func (r *AuthRepository) UpdateOAuthToken(ctx context.Context, token *user.OAuthToken) error {
 	return r.db.WithContext(ctx).Save(token).Error
 }
+
+// GetTokenScope resolves the workspace, plan and quota hints embedded in
+// issued tokens from the user's active subscription. Users without a
+// subscription get an empty scope.
+func (r *AuthRepository) GetTokenScope(ctx context.Context, userID string) (*jwt.TokenScope, error) {
+	var row struct {
+		TeamID             *string
+		Slug               string
+		MaxWorkflows       int
+		MaxExecutionsMonth int
+		Features           string
+	}
+
+	err := r.db.WithContext(ctx).
+		Raw(`SELECT s.team_id, p.slug, p.max_workflows, p.max_executions_month, COALESCE(CAST(p.features AS TEXT), '{}') AS features
+			FROM billing.subscriptions s
+			JOIN billing.plans p ON p.id = s.plan_id
+			WHERE s.user_id = ? AND s.status IN ('active', 'trialing')
+			ORDER BY s.created_at DESC
+			LIMIT 1`, userID).
+		Scan(&row).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load subscription: %w", err)
+	}
+
+	if row.Slug == "" {
+		return &jwt.TokenScope{WorkspaceID: userID}, nil
+	}
+
+	// API request and webhook limits live in the plan features
+	var features struct {
+		APIRequests    *int `json:"apiRequests"`
+		WebhooksPerDay *int `json:"webhooksPerDay"`
+	}
+	json.Unmarshal([]byte(row.Features), &features)
+
+	quota := &jwt.QuotaHints{
+		Workflows:           row.MaxWorkflows,
+		ExecutionsPerMonth:  row.MaxExecutionsMonth,
+		APIRequestsPerMonth: -1,
+		WebhooksPerDay:      -1,
+	}
+	if features.APIRequests != nil {
+		quota.APIRequestsPerMonth = *features.APIRequests
+	}
+	if features.WebhooksPerDay != nil {
+		quota.WebhooksPerDay = *features.WebhooksPerDay
+	}
+
+	workspaceID := userID
+	if row.TeamID != nil && *row.TeamID != "" {
+		workspaceID = *row.TeamID
+	}
+
+	return &jwt.TokenScope{
+		WorkspaceID: workspaceID,
+		Plan:        row.Slug,
+		Quota:       quota,
+	}, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/linkflow-go/internal/auth/app/service"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/logger"
 )
 
@@ -45,18 +46,13 @@ type ChangePasswordRequest struct {
 func (h *AuthHandlers) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	user, err := h.service.Register(c.Request.Context(), req.Email, req.Password, req.FirstName, req.LastName)
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
-			return
-		}
-		h.logger.Error("Failed to register user", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
+		h.respondError(c, err, "Failed to register user")
 		return
 	}
 
@@ -69,46 +65,13 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 func (h *AuthHandlers) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request format",
-			"details": err.Error(),
-		})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	tokens, user, err := h.service.Login(c.Request.Context(), req.Email, req.Password, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
-		errMsg := err.Error()
-
-		// Handle specific error cases with proper messages
-		switch {
-		case strings.Contains(errMsg, "invalid credentials"):
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid credentials",
-				"message": "The email or password you entered is incorrect",
-			})
-		case strings.Contains(errMsg, "email not verified"):
-			c.JSON(http.StatusForbidden, gin.H{
-				"error":   "Email not verified",
-				"message": "Please verify your email address before logging in",
-			})
-		case strings.Contains(errMsg, "temporarily locked"):
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":   "Account locked",
-				"message": "Your account has been temporarily locked due to too many failed login attempts. Please try again in 15 minutes.",
-			})
-		case strings.Contains(errMsg, "account is not active"):
-			c.JSON(http.StatusForbidden, gin.H{
-				"error":   "Account inactive",
-				"message": "Your account is not active. Please contact support.",
-			})
-		default:
-			h.logger.Error("Failed to login", "error", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Login failed",
-				"message": "An unexpected error occurred. Please try again later.",
-			})
-		}
+		h.respondError(c, err, "Login failed")
 		return
 	}
 
@@ -123,13 +86,13 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 func (h *AuthHandlers) RefreshToken(c *gin.Context) {
 	var req RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	tokens, err := h.service.RefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		h.respondError(c, err, "Failed to refresh token")
 		return
 	}
 
@@ -158,7 +121,7 @@ func (h *AuthHandlers) GetCurrentUser(c *gin.Context) {
 
 	user, err := h.service.GetUser(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.JSON(apperrors.ToHTTP(service.ErrUserNotFound.Wrap(err)))
 		return
 	}
 
@@ -170,7 +133,7 @@ func (h *AuthHandlers) UpdateProfile(c *gin.Context) {
 
 	var req map[string]interface{}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
@@ -189,17 +152,12 @@ func (h *AuthHandlers) ChangePassword(c *gin.Context) {
 
 	var req ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	if err := h.service.ChangePassword(c.Request.Context(), userID, req.OldPassword, req.NewPassword); err != nil {
-		if strings.Contains(err.Error(), "incorrect") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect old password"})
-			return
-		}
-		h.logger.Error("Failed to change password", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		h.respondError(c, err, "Failed to change password")
 		return
 	}
 
@@ -209,12 +167,12 @@ func (h *AuthHandlers) ChangePassword(c *gin.Context) {
 func (h *AuthHandlers) VerifyEmail(c *gin.Context) {
 	token := c.Query("token")
 	if token == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Verification token required"})
+		c.JSON(apperrors.ToHTTP(service.ErrTokenRequired.WithMessage("Verification token required")))
 		return
 	}
 
 	if err := h.service.VerifyEmail(c.Request.Context(), token); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+		h.respondError(c, err, "Failed to verify email")
 		return
 	}
 
@@ -227,7 +185,7 @@ func (h *AuthHandlers) ForgotPassword(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
@@ -248,12 +206,12 @@ func (h *AuthHandlers) ResetPassword(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	if err := h.service.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		h.respondError(c, err, "Failed to reset password")
 		return
 	}
 
@@ -320,7 +278,7 @@ func (h *AuthHandlers) Verify2FA(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
@@ -340,17 +298,12 @@ func (h *AuthHandlers) Disable2FA(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	if err := h.service.Disable2FA(c.Request.Context(), userID, req.Password); err != nil {
-		if strings.Contains(err.Error(), "incorrect") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect password"})
-			return
-		}
-		h.logger.Error("Failed to disable 2FA", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable 2FA"})
+		h.respondError(c, err, "Failed to disable 2FA")
 		return
 	}
 
@@ -376,16 +329,7 @@ func (h *AuthHandlers) RevokeSession(c *gin.Context) {
 	sessionID := c.Param("sessionId")
 
 	if err := h.service.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
-			return
-		}
-		if strings.Contains(err.Error(), "unauthorized") {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Cannot revoke this session"})
-			return
-		}
-		h.logger.Error("Failed to revoke session", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		h.respondError(c, err, "Failed to revoke session")
 		return
 	}
 
@@ -407,13 +351,13 @@ func (h *AuthHandlers) RevokeAllSessions(c *gin.Context) {
 func (h *AuthHandlers) ValidateToken(c *gin.Context) {
 	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
 	if token == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Token required"})
+		c.JSON(apperrors.ToHTTP(service.ErrTokenRequired))
 		return
 	}
 
 	session, err := h.service.ValidateSession(c.Request.Context(), token)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		h.respondError(c, err, "Failed to validate token")
 		return
 	}
 
@@ -423,6 +367,44 @@ func (h *AuthHandlers) ValidateToken(c *gin.Context) {
 	})
 }
 
+// TokenRequest is the form body of the RFC 7662 introspection and RFC 7009
+// revocation endpoints
+type TokenRequest struct {
+	Token         string `form:"token" json:"token" binding:"required"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint"`
+}
+
+// IntrospectToken implements RFC 7662 token introspection
+func (h *AuthHandlers) IntrospectToken(c *gin.Context) {
+	var req TokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, h.service.IntrospectToken(c.Request.Context(), req.Token, req.TokenTypeHint))
+}
+
+// RevokeToken implements RFC 7009 token revocation. Unknown or invalid tokens
+// also get a 200 response so token validity is not leaked.
+func (h *AuthHandlers) RevokeToken(c *gin.Context) {
+	var req TokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": err.Error()})
+		return
+	}
+
+	if err := h.service.RevokeToken(c.Request.Context(), req.Token, req.TokenTypeHint); err != nil {
+		h.logger.Error("Failed to revoke token", "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "temporarily_unavailable"})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
 // RBAC handlers
 func (h *AuthHandlers) AssignRole(c *gin.Context) {
 	userID := c.Param("userId")
@@ -432,17 +414,12 @@ func (h *AuthHandlers) AssignRole(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	if err := h.service.AssignRole(c.Request.Context(), userID, req.Role); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			return
-		}
-		h.logger.Error("Failed to assign role", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign role"})
+		h.respondError(c, err, "Failed to assign role")
 		return
 	}
 
@@ -454,12 +431,7 @@ func (h *AuthHandlers) RemoveRole(c *gin.Context) {
 	role := c.Param("role")
 
 	if err := h.service.RemoveRole(c.Request.Context(), userID, role); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			return
-		}
-		h.logger.Error("Failed to remove role", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove role"})
+		h.respondError(c, err, "Failed to remove role")
 		return
 	}
 
@@ -471,12 +443,7 @@ func (h *AuthHandlers) GetUserRoles(c *gin.Context) {
 
 	roles, err := h.service.GetUserRoles(c.Request.Context(), userID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			return
-		}
-		h.logger.Error("Failed to get user roles", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user roles"})
+		h.respondError(c, err, "Failed to get user roles")
 		return
 	}
 
@@ -509,7 +476,7 @@ func (h *AuthHandlers) CheckPermission(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
@@ -536,18 +503,13 @@ func (h *AuthHandlers) Health(c *gin.Context) {
 	})
 }
 
-func (h *AuthHandlers) Ready(c *gin.Context) {
-	// Check if service is ready (database, redis, etc.)
-	if err := h.service.CheckReadiness(c.Request.Context()); err != nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"status": "not ready",
-			"error":  err.Error(),
-		})
-		return
+// respondError writes err as a coded error response. Uncoded errors are
+// logged and reported to the client as msg.
+func (h *AuthHandlers) respondError(c *gin.Context, err error, msg string) {
+	status, body := apperrors.ToHTTP(err)
+	if status >= http.StatusInternalServerError {
+		h.logger.Error(msg, "error", err)
+		body.Error = msg
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "ready",
-		"service": "auth-service",
-	})
+	c.JSON(status, body)
 }
@@ -2,14 +2,15 @@ package oauth
 
 import (
 	"context"
-	"errors"
 	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
 )
 
 var (
-	ErrProviderNotFound = errors.New("OAuth provider not found")
-	ErrInvalidCode      = errors.New("invalid authorization code")
-	ErrTokenExpired     = errors.New("token has expired")
+	ErrProviderNotFound = apperrors.New(apperrors.CategoryNotFound, "OAUTH_PROVIDER_NOT_FOUND", "OAuth provider not found")
+	ErrInvalidCode      = apperrors.New(apperrors.CategoryAuth, "INVALID_AUTHORIZATION_CODE", "invalid authorization code")
+	ErrTokenExpired     = apperrors.New(apperrors.CategoryAuth, "TOKEN_EXPIRED", "token has expired")
 )
 
 // Provider represents an OAuth2 provider
@@ -0,0 +1,52 @@
+// Package rpc serves the auth gRPC API, token checks for the other
+// services
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/linkflow-go/internal/auth/app/service"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/rpc/authv1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements authv1.AuthServiceServer
+type Server struct {
+	authv1.UnimplementedAuthServiceServer
+	service *service.AuthService
+}
+
+// NewServer creates the auth API server
+func NewServer(service *service.AuthService) *Server {
+	return &Server{service: service}
+}
+
+// IntrospectToken reports whether a token is active and its claims
+func (s *Server) IntrospectToken(ctx context.Context, req *authv1.IntrospectTokenRequest) (*authv1.IntrospectTokenResponse, error) {
+	if req.GetToken() == "" {
+		return nil, apperrors.New(apperrors.CategoryValidation, apperrors.CodeInvalidRequest, "token is required")
+	}
+
+	result := s.service.IntrospectToken(ctx, req.GetToken(), req.GetTokenTypeHint())
+	if !result.Active {
+		return &authv1.IntrospectTokenResponse{Active: false}, nil
+	}
+
+	resp := &authv1.IntrospectTokenResponse{
+		Active:      true,
+		TokenType:   result.TokenType,
+		UserId:      result.Sub,
+		Username:    result.Username,
+		WorkspaceId: result.WorkspaceID,
+		Plan:        result.Plan,
+		Roles:       result.Roles,
+		Scope:       result.Scope,
+		TenantId:    result.TenantID,
+	}
+	if result.Exp != 0 {
+		resp.ExpiresAt = timestamppb.New(time.Unix(result.Exp, 0))
+	}
+	return resp, nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var (
+	ErrUserExists               = apperrors.New(apperrors.CategoryConflict, "USER_EXISTS", "User already exists")
+	ErrUserNotFound             = apperrors.New(apperrors.CategoryNotFound, "USER_NOT_FOUND", "User not found")
+	ErrInvalidCredentials       = apperrors.New(apperrors.CategoryAuth, "INVALID_CREDENTIALS", "The email or password you entered is incorrect")
+	ErrEmailNotVerified         = apperrors.New(apperrors.CategoryPermission, "EMAIL_NOT_VERIFIED", "Please verify your email address before logging in")
+	ErrAccountInactive          = apperrors.New(apperrors.CategoryPermission, "ACCOUNT_INACTIVE", "Your account is not active. Please contact support.")
+	ErrAccountLocked            = apperrors.New(apperrors.CategoryRateLimit, "ACCOUNT_LOCKED", "Your account has been temporarily locked due to too many failed login attempts. Please try again in 15 minutes.")
+	ErrInvalidRefreshToken      = apperrors.New(apperrors.CategoryAuth, "INVALID_REFRESH_TOKEN", "Invalid or expired refresh token")
+	ErrRefreshTokenReused       = apperrors.New(apperrors.CategoryAuth, "REFRESH_TOKEN_REUSED", "Refresh token has already been used")
+	ErrIncorrectPassword        = apperrors.New(apperrors.CategoryValidation, "INCORRECT_PASSWORD", "Incorrect old password")
+	ErrInvalidVerificationToken = apperrors.New(apperrors.CategoryValidation, "INVALID_VERIFICATION_TOKEN", "Invalid or expired verification token")
+	ErrEmailAlreadyVerified     = apperrors.New(apperrors.CategoryConflict, "EMAIL_ALREADY_VERIFIED", "Email already verified")
+	ErrInvalidResetToken        = apperrors.New(apperrors.CategoryValidation, "INVALID_RESET_TOKEN", "Invalid or expired reset token")
+	ErrSessionNotFound          = apperrors.New(apperrors.CategoryNotFound, "SESSION_NOT_FOUND", "Session not found")
+	ErrSessionNotOwned          = apperrors.New(apperrors.CategoryPermission, "SESSION_NOT_OWNED", "Cannot revoke this session")
+	ErrSessionRevoked           = apperrors.New(apperrors.CategoryAuth, "SESSION_REVOKED", "Session has been revoked")
+	ErrInvalidSession           = apperrors.New(apperrors.CategoryAuth, "INVALID_SESSION", "Invalid or expired token")
+	ErrSessionExpired           = apperrors.New(apperrors.CategoryAuth, "SESSION_EXPIRED", "Session expired")
+	ErrTokenRequired            = apperrors.New(apperrors.CategoryValidation, "TOKEN_REQUIRED", "Token required")
+)
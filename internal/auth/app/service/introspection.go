@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/linkflow-go/pkg/auth/jwt"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// Token type hints defined by RFC 7009 and RFC 7662
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+var errTokenTypeMismatch = errors.New("token does not match the expected type")
+
+// TokenIntrospection is the RFC 7662 introspection response. Inactive tokens
+// only carry Active=false so nothing about them is disclosed.
+type TokenIntrospection struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Nbf       int64    `json:"nbf,omitempty"`
+	Sub       string   `json:"sub,omitempty"`
+	Aud       []string `json:"aud,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+
+	// LinkFlow extensions
+	WorkspaceID string   `json:"workspace_id,omitempty"`
+	Plan        string   `json:"plan,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	TenantID    string   `json:"tenant_id,omitempty"`
+}
+
+// parsedToken is a validated token with the metadata needed for
+// introspection and revocation
+type parsedToken struct {
+	hint      string
+	userID    string
+	expiresAt time.Time
+	access    *jwt.Claims
+	refresh   *jwt.RefreshClaims
+}
+
+// IntrospectToken reports whether a token is active and, if so, its claims
+func (s *AuthService) IntrospectToken(ctx context.Context, token, hint string) *TokenIntrospection {
+	parsed, err := s.parseToken(token, hint)
+	if err != nil {
+		return &TokenIntrospection{Active: false}
+	}
+
+	if s.isTokenRevoked(ctx, token, parsed.hint) {
+		return &TokenIntrospection{Active: false}
+	}
+
+	if parsed.refresh != nil {
+		claims := parsed.refresh
+		return &TokenIntrospection{
+			Active:    true,
+			TokenType: TokenTypeHintRefreshToken,
+			Exp:       numericDate(claims.ExpiresAt),
+			Iat:       numericDate(claims.IssuedAt),
+			Nbf:       numericDate(claims.NotBefore),
+			Sub:       claims.UserID,
+			Aud:       claims.Audience,
+			Iss:       claims.Issuer,
+			Jti:       claims.ID,
+		}
+	}
+
+	claims := parsed.access
+	return &TokenIntrospection{
+		Active:      true,
+		Scope:       strings.Join(claims.Scopes, " "),
+		Username:    claims.Email,
+		TokenType:   "Bearer",
+		Exp:         numericDate(claims.ExpiresAt),
+		Iat:         numericDate(claims.IssuedAt),
+		Nbf:         numericDate(claims.NotBefore),
+		Sub:         claims.UserID,
+		Aud:         claims.Audience,
+		Iss:         claims.Issuer,
+		Jti:         claims.ID,
+		WorkspaceID: claims.WorkspaceID,
+		Plan:        claims.Plan,
+		Roles:       claims.Roles,
+		TenantID:    claims.TenantID,
+	}
+}
+
+// RevokeToken revokes an access or refresh token. Per RFC 7009 invalid or
+// already expired tokens are not an error, there is nothing left to revoke.
+func (s *AuthService) RevokeToken(ctx context.Context, token, hint string) error {
+	parsed, err := s.parseToken(token, hint)
+	if err != nil {
+		return nil
+	}
+
+	ttl := time.Until(parsed.expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	switch parsed.hint {
+	case TokenTypeHintRefreshToken:
+		if err := s.redis.Set(ctx, fmt.Sprintf("blacklist:refresh:%s", token), "1", ttl).Err(); err != nil {
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+	default:
+		if err := s.redis.Set(ctx, fmt.Sprintf("blacklist:%s", token), "1", ttl).Err(); err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+		// The session may already be gone, revocation still succeeds
+		s.repository.DeleteSession(ctx, token)
+	}
+
+	event := events.NewEventBuilder("auth.token.revoked").
+		WithAggregateID(parsed.userID).
+		WithAggregateType("user").
+		WithUserID(parsed.userID).
+		WithPayload("tokenType", parsed.hint).
+		Build()
+
+	s.eventBus.Publish(ctx, event)
+
+	return nil
+}
+
+// parseToken validates a token, trying the hinted type first. Unknown hints
+// are ignored as required by RFC 7009.
+func (s *AuthService) parseToken(token, hint string) (*parsedToken, error) {
+	if token == "" {
+		return nil, ErrTokenRequired
+	}
+
+	if hint == TokenTypeHintRefreshToken {
+		if parsed, err := s.parseRefreshToken(token); err == nil {
+			return parsed, nil
+		}
+		return s.parseAccessToken(token)
+	}
+
+	if parsed, err := s.parseAccessToken(token); err == nil {
+		return parsed, nil
+	}
+	return s.parseRefreshToken(token)
+}
+
+func (s *AuthService) parseAccessToken(token string) (*parsedToken, error) {
+	// Refresh tokens share the signing key, reject them here
+	if refresh, err := s.jwtManager.ParseRefreshToken(token); err == nil && refresh.TokenUse == jwt.TokenUseRefresh {
+		return nil, errTokenTypeMismatch
+	}
+
+	claims, err := s.jwtManager.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsedToken{
+		hint:      TokenTypeHintAccessToken,
+		userID:    claims.UserID,
+		expiresAt: expiryTime(claims.ExpiresAt),
+		access:    claims,
+	}, nil
+}
+
+func (s *AuthService) parseRefreshToken(token string) (*parsedToken, error) {
+	claims, err := s.jwtManager.ParseRefreshToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenUse != jwt.TokenUseRefresh {
+		return nil, errTokenTypeMismatch
+	}
+
+	return &parsedToken{
+		hint:      TokenTypeHintRefreshToken,
+		userID:    claims.UserID,
+		expiresAt: expiryTime(claims.ExpiresAt),
+		refresh:   claims,
+	}, nil
+}
+
+func (s *AuthService) isTokenRevoked(ctx context.Context, token, hint string) bool {
+	key := fmt.Sprintf("blacklist:%s", token)
+	if hint == TokenTypeHintRefreshToken {
+		key = fmt.Sprintf("blacklist:refresh:%s", token)
+	}
+
+	revoked, err := s.redis.Exists(ctx, key).Result()
+	if err != nil {
+		// Fail closed, a token that cannot be checked is not active
+		s.logger.Warn("Failed to check token revocation", "error", err)
+		return true
+	}
+	return revoked > 0
+}
+
+func numericDate(date *gojwt.NumericDate) int64 {
+	if date == nil {
+		return 0
+	}
+	return date.Unix()
+}
+
+// expiryTime returns the token expiry, tokens without one are kept revoked
+// for the refresh token lifetime
+func expiryTime(date *gojwt.NumericDate) time.Time {
+	if date == nil {
+		return time.Now().Add(7 * 24 * time.Hour)
+	}
+	return date.Time
+}
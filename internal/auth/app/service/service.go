@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
@@ -13,16 +12,20 @@ import (
 	"github.com/linkflow-go/pkg/contracts/user"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/tenant"
 	"github.com/redis/go-redis/v9"
 )
 
 type AuthService struct {
 	repository ports.AuthRepository
+	tx         ports.Transactor
 	jwtManager *jwt.Manager
 	redis      *redis.Client
 	eventBus   events.EventBus
 	rbac       ports.RBACEnforcer
 	logger     logger.Logger
+	// isolateSignups gives each registered user a tenant of their own
+	isolateSignups bool
 }
 
 type Tokens struct {
@@ -31,14 +34,17 @@ type Tokens struct {
 	ExpiresIn    int    `json:"expiresIn"`
 }
 
-func NewAuthService(repo ports.AuthRepository, jwtManager *jwt.Manager, redis *redis.Client, eventBus events.EventBus, rbacEnforcer ports.RBACEnforcer, logger logger.Logger) *AuthService {
+func NewAuthService(repo ports.AuthRepository, tx ports.Transactor, jwtManager *jwt.Manager, redis *redis.Client, eventBus events.EventBus, rbacEnforcer ports.RBACEnforcer, logger logger.Logger, isolateSignups bool) *AuthService {
 	return &AuthService{
 		repository: repo,
+		tx:         tx,
 		jwtManager: jwtManager,
 		redis:      redis,
 		eventBus:   eventBus,
 		rbac:       rbacEnforcer,
 		logger:     logger,
+
+		isolateSignups: isolateSignups,
 	}
 }
 
@@ -46,7 +52,7 @@ func (s *AuthService) Register(ctx context.Context, email, password, firstName,
 	// Check if user already exists
 	existingUser, _ := s.repository.GetUserByEmail(ctx, email)
 	if existingUser != nil {
-		return nil, errors.New("user already exists")
+		return nil, ErrUserExists
 	}
 
 	// Create new user
@@ -54,24 +60,26 @@ func (s *AuthService) Register(ctx context.Context, email, password, firstName,
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
+	newUser.TenantID = s.signupTenant(ctx, newUser)
 
-	// Save user to database
-	if err := s.repository.CreateUser(ctx, newUser); err != nil {
-		return nil, fmt.Errorf("failed to save user: %w", err)
-	}
-
-	// Publish user registered event
-	event := events.NewEventBuilder(events.UserRegistered).
-		WithAggregateID(newUser.ID).
-		WithAggregateType("user").
-		WithUserID(newUser.ID).
-		WithPayload("email", newUser.Email).
-		WithPayload("firstName", newUser.FirstName).
-		WithPayload("lastName", newUser.LastName).
-		Build()
+	// Save user to database with the user registered event
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repository.CreateUser(ctx, newUser); err != nil {
+			return err
+		}
 
-	if err := s.eventBus.Publish(ctx, event); err != nil {
-		s.logger.Error("Failed to publish user registered event", "error", err)
+		event := events.NewEventBuilder(events.UserRegistered).
+			WithAggregateID(newUser.ID).
+			WithAggregateType("user").
+			WithUserID(newUser.ID).
+			WithPayload("email", newUser.Email).
+			WithPayload("firstName", newUser.FirstName).
+			WithPayload("lastName", newUser.LastName).
+			Build()
+		return s.eventBus.Publish(ctx, event)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save user: %w", err)
 	}
 
 	// Assign default role to new user
@@ -87,25 +95,38 @@ func (s *AuthService) Register(ctx context.Context, email, password, firstName,
 	return newUser, nil
 }
 
+// signupTenant returns the tenant a new user joins: the tenant of the
+// request registering them, else a tenant of their own when signups are
+// isolated, else the default tenant
+func (s *AuthService) signupTenant(ctx context.Context, u *user.User) string {
+	if id := tenant.FromContext(ctx); id != "" {
+		return id
+	}
+	if s.isolateSignups {
+		return u.ID
+	}
+	return tenant.DefaultTenant
+}
+
 func (s *AuthService) Login(ctx context.Context, email, password, ipAddress, userAgent string) (*Tokens, *user.User, error) {
 	// Check if account is locked due to too many failed attempts
 	lockKey := fmt.Sprintf("lockout:%s", email)
 	locked, _ := s.redis.Exists(ctx, lockKey).Result()
 	if locked > 0 {
-		return nil, nil, errors.New("account is temporarily locked due to too many failed login attempts")
+		return nil, nil, ErrAccountLocked
 	}
 
 	// Get user by email
 	u, err := s.repository.GetUserByEmail(ctx, email)
 	if err != nil {
 		s.trackFailedLogin(ctx, email, ipAddress)
-		return nil, nil, errors.New("invalid credentials")
+		return nil, nil, ErrInvalidCredentials
 	}
 
 	// Check password
 	if !u.CheckPassword(password) {
 		s.trackFailedLogin(ctx, email, ipAddress)
-		return nil, nil, errors.New("invalid credentials")
+		return nil, nil, ErrInvalidCredentials
 	}
 
 	// Clear failed login attempts on successful login
@@ -113,12 +134,12 @@ func (s *AuthService) Login(ctx context.Context, email, password, ipAddress, use
 
 	// Check if email is verified
 	if !u.EmailVerified {
-		return nil, nil, errors.New("email not verified")
+		return nil, nil, ErrEmailNotVerified
 	}
 
 	// Check if account is active
 	if u.Status != user.StatusActive {
-		return nil, nil, errors.New("account is not active")
+		return nil, nil, ErrAccountInactive
 	}
 
 	// Get roles from RBAC
@@ -154,7 +175,7 @@ func (s *AuthService) Login(ctx context.Context, email, password, ipAddress, use
 	}
 
 	// Generate tokens
-	accessToken, err := s.jwtManager.GenerateToken(u.ID, u.Email, roles, permissions)
+	accessToken, err := s.jwtManager.GenerateScopedToken(u.ID, u.Email, roles, permissions, s.tokenScope(ctx, u))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
@@ -205,28 +226,41 @@ func (s *AuthService) Login(ctx context.Context, email, password, ipAddress, use
 	return tokens, u, nil
 }
 
+// tokenScope loads the workspace, plan and quota hints embedded in access
+// tokens. Lookup failures issue a token scoped to the tenant of the user
+// only rather than failing login.
+func (s *AuthService) tokenScope(ctx context.Context, u *user.User) jwt.TokenScope {
+	scope, err := s.repository.GetTokenScope(ctx, u.ID)
+	if err != nil || scope == nil {
+		s.logger.Warn("Failed to load token scope", "userID", u.ID, "error", err)
+		scope = &jwt.TokenScope{}
+	}
+	scope.TenantID = u.TenantID
+	return *scope
+}
+
 func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*Tokens, error) {
 	// Check if refresh token is blacklisted (already used)
 	blacklisted, _ := s.redis.Exists(ctx, fmt.Sprintf("blacklist:refresh:%s", refreshToken)).Result()
 	if blacklisted > 0 {
-		return nil, errors.New("refresh token has already been used")
+		return nil, ErrRefreshTokenReused
 	}
 
 	// Validate refresh token
 	userID, err := s.jwtManager.ValidateRefreshToken(refreshToken)
 	if err != nil {
-		return nil, errors.New("invalid refresh token")
+		return nil, ErrInvalidRefreshToken.Wrap(err)
 	}
 
 	// Get user
 	u, err := s.repository.GetUserByID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 
 	// Check if account is still active
 	if u.Status != user.StatusActive {
-		return nil, errors.New("account is not active")
+		return nil, ErrAccountInactive
 	}
 
 	// Get current roles from RBAC
@@ -241,7 +275,7 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*T
 	}
 
 	// Generate new tokens
-	accessToken, err := s.jwtManager.GenerateToken(u.ID, u.Email, roles, u.GetPermissions())
+	accessToken, err := s.jwtManager.GenerateScopedToken(u.ID, u.Email, roles, u.GetPermissions(), s.tokenScope(ctx, u))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
@@ -314,20 +348,24 @@ func (s *AuthService) UpdateProfile(ctx context.Context, userID string, updates
 
 	u.UpdatedAt = time.Now()
 
-	if err := s.repository.UpdateUser(ctx, u); err != nil {
+	// Save with the user updated event
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repository.UpdateUser(ctx, u); err != nil {
+			return err
+		}
+
+		event := events.NewEventBuilder(events.UserUpdated).
+			WithAggregateID(u.ID).
+			WithAggregateType("user").
+			WithUserID(u.ID).
+			WithPayload("updates", updates).
+			Build()
+		return s.eventBus.Publish(ctx, event)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
-	// Publish user updated event
-	event := events.NewEventBuilder(events.UserUpdated).
-		WithAggregateID(u.ID).
-		WithAggregateType("user").
-		WithUserID(u.ID).
-		WithPayload("updates", updates).
-		Build()
-
-	s.eventBus.Publish(ctx, event)
-
 	return u, nil
 }
 
@@ -339,7 +377,7 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID, oldPassword, n
 
 	// Verify old password
 	if !u.CheckPassword(oldPassword) {
-		return errors.New("incorrect old password")
+		return ErrIncorrectPassword
 	}
 
 	// Set new password
@@ -358,12 +396,12 @@ func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
 	// Get user by verification token
 	u, err := s.repository.GetUserByEmailVerifyToken(ctx, token)
 	if err != nil {
-		return errors.New("invalid or expired verification token")
+		return ErrInvalidVerificationToken.Wrap(err)
 	}
 
 	// Check if already verified
 	if u.EmailVerified {
-		return errors.New("email already verified")
+		return ErrEmailAlreadyVerified
 	}
 
 	// Mark email as verified
@@ -371,20 +409,22 @@ func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
 	u.EmailVerifyToken = "" // Clear the token
 	u.UpdatedAt = time.Now()
 
-	if err := s.repository.UpdateUser(ctx, u); err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
-	}
-
-	// Publish email verified event
-	event := events.NewEventBuilder("user.email.verified").
-		WithAggregateID(u.ID).
-		WithAggregateType("user").
-		WithUserID(u.ID).
-		WithPayload("email", u.Email).
-		Build()
+	// Save with the email verified event
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repository.UpdateUser(ctx, u); err != nil {
+			return err
+		}
 
-	if err := s.eventBus.Publish(ctx, event); err != nil {
-		s.logger.Error("Failed to publish email verified event", "error", err)
+		event := events.NewEventBuilder("user.email.verified").
+			WithAggregateID(u.ID).
+			WithAggregateType("user").
+			WithUserID(u.ID).
+			WithPayload("email", u.Email).
+			Build()
+		return s.eventBus.Publish(ctx, event)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
 	}
 
 	s.logger.Info("Email verified successfully", "email", u.Email)
@@ -414,7 +454,7 @@ func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword stri
 	// Get user ID from token
 	userID, err := s.redis.Get(ctx, fmt.Sprintf("reset:%s", token)).Result()
 	if err != nil {
-		return errors.New("invalid or expired reset token")
+		return ErrInvalidResetToken.Wrap(err)
 	}
 
 	// Get user
@@ -474,20 +514,6 @@ func (s *AuthService) Disable2FA(ctx context.Context, userID, password string) e
 	return nil
 }
 
-func (s *AuthService) CheckReadiness(ctx context.Context) error {
-	// Check database connection
-	if _, err := s.repository.GetUserByID(ctx, "test"); err != nil {
-		// Ignore not found error
-	}
-
-	// Check Redis connection
-	if err := s.redis.Ping(ctx).Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func (s *AuthService) sendVerificationEmail(u *user.User) {
 	// Send verification email
 	s.logger.Info("Sending verification email", "email", u.Email, "token", u.EmailVerifyToken)
@@ -565,12 +591,12 @@ func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID strin
 	// Get the session to verify ownership
 	session, err := s.repository.GetSessionByID(ctx, sessionID)
 	if err != nil {
-		return fmt.Errorf("session not found")
+		return ErrSessionNotFound.Wrap(err)
 	}
 
 	// Verify the session belongs to the user
 	if session.UserID != userID {
-		return errors.New("unauthorized: session does not belong to user")
+		return ErrSessionNotOwned
 	}
 
 	// Add token to blacklist
@@ -631,20 +657,20 @@ func (s *AuthService) ValidateSession(ctx context.Context, token string) (*user.
 	// Check if token is blacklisted
 	blacklisted, _ := s.redis.Exists(ctx, fmt.Sprintf("blacklist:%s", token)).Result()
 	if blacklisted > 0 {
-		return nil, errors.New("session has been revoked")
+		return nil, ErrSessionRevoked
 	}
 
 	// Get session from database
 	session, err := s.repository.GetSession(ctx, token)
 	if err != nil {
-		return nil, errors.New("invalid session")
+		return nil, ErrInvalidSession.Wrap(err)
 	}
 
 	// Check if session is expired
 	if time.Now().After(session.ExpiresAt) {
 		// Delete expired session
 		s.repository.DeleteSession(ctx, token)
-		return nil, errors.New("session expired")
+		return nil, ErrSessionExpired
 	}
 
 	return session, nil
@@ -655,7 +681,7 @@ func (s *AuthService) ValidateSession(ctx context.Context, token string) (*user.
 func (s *AuthService) AssignRole(ctx context.Context, userID, role string) error {
 	// Verify user exists
 	if _, err := s.repository.GetUserByID(ctx, userID); err != nil {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
 	}
 
 	// Assign role in RBAC
@@ -681,7 +707,7 @@ func (s *AuthService) AssignRole(ctx context.Context, userID, role string) error
 func (s *AuthService) RemoveRole(ctx context.Context, userID, role string) error {
 	// Verify user exists
 	if _, err := s.repository.GetUserByID(ctx, userID); err != nil {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
 	}
 
 	// Remove role in RBAC
@@ -707,7 +733,7 @@ func (s *AuthService) RemoveRole(ctx context.Context, userID, role string) error
 func (s *AuthService) GetUserRoles(ctx context.Context, userID string) ([]string, error) {
 	// Verify user exists
 	if _, err := s.repository.GetUserByID(ctx, userID); err != nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, ErrUserNotFound
 	}
 
 	if s.rbac != nil {
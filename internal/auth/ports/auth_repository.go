@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 
+	"github.com/linkflow-go/pkg/auth/jwt"
 	"github.com/linkflow-go/pkg/contracts/user"
 )
 
@@ -19,4 +20,5 @@ type AuthRepository interface {
 	DeleteSession(ctx context.Context, token string) error
 	DeleteSessionByID(ctx context.Context, sessionID string) error
 	DeleteUserSessions(ctx context.Context, userID string) error
+	GetTokenScope(ctx context.Context, userID string) (*jwt.TokenScope, error)
 }
@@ -12,24 +12,38 @@ import (
 	"github.com/linkflow-go/internal/auth/adapters/db/repository"
 	"github.com/linkflow-go/internal/auth/adapters/http/handlers"
 	"github.com/linkflow-go/internal/auth/adapters/rbac"
+	authrpc "github.com/linkflow-go/internal/auth/adapters/rpc"
 	"github.com/linkflow-go/internal/auth/app/service"
+	"github.com/linkflow-go/pkg/apidoc"
 	"github.com/linkflow-go/pkg/auth/jwt"
 	"github.com/linkflow-go/pkg/config"
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/linkflow-go/pkg/middleware/correlation"
+	tenantmw "github.com/linkflow-go/pkg/middleware/tenant"
+	"github.com/linkflow-go/pkg/outbox"
 	"github.com/linkflow-go/pkg/ratelimit"
+	"github.com/linkflow-go/pkg/rpc"
+	"github.com/linkflow-go/pkg/rpc/authv1"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
 	config     *config.Config
 	logger     logger.Logger
 	httpServer *http.Server
+	rpcServer  *grpc.Server
 	db         *database.DB
 	redis      *redis.Client
-	eventBus   events.EventBus
+	eventBus   *outbox.Outbox
+
+	// loginLimiter throttles login attempts, its limits are reloadable
+	loginLimiter *ratelimit.InMemoryRateLimiter
 }
 
 func New(cfg *config.Config, log logger.Logger) (*Server, error) {
@@ -56,11 +70,13 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	// Initialize event bus
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	// Initialize event bus, events are published through the outbox so they
+	// commit with the state change that raised them
+	bus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
+	eventBus := outbox.New(db, bus, "auth-service", log)
 
 	// Initialize JWT manager
 	jwtManager, err := jwt.NewManager(cfg.Auth)
@@ -78,68 +94,97 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	authRepo := repository.NewAuthRepository(db)
 
 	// Initialize service
-	authService := service.NewAuthService(authRepo, jwtManager, redisClient, eventBus, rbacEnforcer, log)
+	authService := service.NewAuthService(authRepo, db, jwtManager, redisClient, eventBus, rbacEnforcer, log, cfg.Auth.IsolateSignups)
 
 	// Initialize handlers
 	authHandlers := handlers.NewAuthHandlers(authService, log)
 
+	// Readiness reports the state of each dependency
+	checker := health.NewChecker("auth-service")
+	checker.Critical("database", health.Database(db))
+	checker.Critical("redis", health.Redis(redisClient))
+	checker.Optional("event_bus", health.EventBus(eventBus))
+
+	// Throttle login attempts per client, blocked clients wait out the window
+	loginLimiter := ratelimit.NewInMemoryRateLimiter(cfg.RateLimit.LoginAttempts,
+		time.Duration(cfg.RateLimit.LoginWindow)*time.Second)
+
 	// Setup HTTP server
-	router := setupRouter(authHandlers, jwtManager, redisClient, db, log)
+	router := setupRouter(authHandlers, jwtManager, redisClient, db, loginLimiter, checker, log)
+
+	// Serve the OpenAPI document of the routes registered above
+	if err := apidoc.Serve(router, "auth", cfg.Server.StrictAPISpec, log); err != nil {
+		return nil, err
+	}
 
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
 		Handler: router,
 	}
 
+	// Token checks for the other services over gRPC
+	var rpcServer *grpc.Server
+	if cfg.RPC.Port != 0 {
+		rpcServer = rpc.NewServer(cfg.RPC.Token, log)
+		authv1.RegisterAuthServiceServer(rpcServer, authrpc.NewServer(authService))
+	}
+
 	return &Server{
-		config:     cfg,
-		logger:     log,
-		httpServer: httpServer,
-		db:         db,
-		redis:      redisClient,
-		eventBus:   eventBus,
+		config:       cfg,
+		logger:       log,
+		httpServer:   httpServer,
+		rpcServer:    rpcServer,
+		db:           db,
+		redis:        redisClient,
+		eventBus:     eventBus,
+		loginLimiter: loginLimiter,
 	}, nil
 }
 
-func setupRouter(h *handlers.AuthHandlers, jwtManager *jwt.Manager, redisClient *redis.Client, db *database.DB, log logger.Logger) *gin.Engine {
+func setupRouter(h *handlers.AuthHandlers, jwtManager *jwt.Manager, redisClient *redis.Client, db *database.DB, loginLimiter *ratelimit.InMemoryRateLimiter, checker *health.Checker, log logger.Logger) *gin.Engine {
 	router := gin.New()
 
-	// Middleware
+	// Middleware, metrics first so recovered panics are counted as 500s
+	router.Use(metrics.HTTPMiddleware("auth-service"))
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
 	router.Use(corsMiddleware())
 	router.Use(loggingMiddleware(log))
 
 	// Health checks
 	router.GET("/health/live", h.Health)
-	router.GET("/health/ready", h.Ready)
+	router.GET("/health/ready", checker.ReadyHandler())
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
-	// OpenAPI/Swagger documentation
+	// Swagger UI of the OpenAPI document served at apidoc.SpecPath
 	router.GET("/api/docs", serveSwaggerUI())
-	router.StaticFile("/api/openapi.yaml", "api/openapi/auth.yaml")
-
-	// Create rate limiter for login attempts
-	// Allow 5 attempts per 15 minutes, then block for 15 minutes
-	loginRateLimiter := ratelimit.NewInMemoryRateLimiter(5, 15*time.Minute)
 
 	// API routes
-	v1 := router.Group("/api/v1/auth")
+	v1 := router.Group(apidoc.Prefix + "/auth")
 	{
 		// Public routes
 		v1.POST("/register", h.Register)
-		v1.POST("/login", ratelimit.LoginRateLimitMiddleware(loginRateLimiter), h.Login)
+		v1.POST("/login", ratelimit.LoginRateLimitMiddleware(loginLimiter), h.Login)
 		v1.POST("/refresh", h.RefreshToken)
 		v1.POST("/verify-email", h.VerifyEmail)
 		v1.POST("/forgot-password", h.ForgotPassword)
 		v1.POST("/reset-password", h.ResetPassword)
 
+		// RFC 7009 revocation, possession of the token authorizes revoking it
+		v1.POST("/revoke", h.RevokeToken)
+
 		// OAuth routes
 		v1.GET("/oauth/:provider", h.OAuthLogin)
 		v1.GET("/oauth/:provider/callback", h.OAuthCallback)
 
+		// API Key management endpoints, which API keys authenticate too
+		if db != nil {
+			apikey.SetupRoutes(v1, db.DB, log, authMiddleware(jwtManager, redisClient), tenantmw.Middleware())
+		}
+
 		// Protected routes
 		protected := v1.Group("")
-		protected.Use(authMiddleware(jwtManager, redisClient))
+		protected.Use(authMiddleware(jwtManager, redisClient), tenantmw.Middleware())
 		{
 			protected.POST("/logout", h.Logout)
 			protected.GET("/me", h.GetCurrentUser)
@@ -155,10 +200,8 @@ func setupRouter(h *handlers.AuthHandlers, jwtManager *jwt.Manager, redisClient
 			protected.DELETE("/sessions", h.RevokeAllSessions)
 			protected.POST("/validate", h.ValidateToken)
 
-			// API Key management endpoints
-			if db != nil {
-				apikey.SetupRoutes(protected, db.DB, log)
-			}
+			// RFC 7662 introspection for resource servers
+			protected.POST("/introspect", h.IntrospectToken)
 
 			// RBAC endpoints (admin only)
 			rbac := protected.Group("/rbac")
@@ -178,6 +221,15 @@ func setupRouter(h *handlers.AuthHandlers, jwtManager *jwt.Manager, redisClient
 }
 
 func (s *Server) Start() error {
+	// Relay events stored in the outbox
+	s.eventBus.Start()
+
+	if s.rpcServer != nil {
+		if err := rpc.Serve(s.rpcServer, s.config.RPC.Port, s.logger); err != nil {
+			return err
+		}
+	}
+
 	s.logger.Info("Starting HTTP server", "port", s.config.Server.Port)
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start HTTP server: %w", err)
@@ -185,6 +237,16 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// ApplyConfig applies reloaded tunables to the running service
+func (s *Server) ApplyConfig(prev, next *config.Config) {
+	if next.RateLimit != prev.RateLimit {
+		s.loginLimiter.SetLimits(next.RateLimit.LoginAttempts,
+			time.Duration(next.RateLimit.LoginWindow)*time.Second)
+		s.logger.Info("Login rate limit changed",
+			"attempts", next.RateLimit.LoginAttempts, "windowSeconds", next.RateLimit.LoginWindow)
+	}
+}
+
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("Shutting down server...")
 
@@ -193,7 +255,12 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("failed to shutdown HTTP server: %w", err)
 	}
 
-	// Close event bus
+	// Stop gRPC API
+	if s.rpcServer != nil {
+		rpc.Stop(ctx, s.rpcServer)
+	}
+
+	// Stop the outbox relay and close event bus
 	if err := s.eventBus.Close(); err != nil {
 		s.logger.Error("Failed to close event bus", "error", err)
 	}
@@ -216,7 +283,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -248,7 +315,7 @@ func loggingMiddleware(log logger.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		log.Info("HTTP Request",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP Request",
 			"method", method,
 			"path", path,
 			"status", statusCode,
@@ -300,6 +367,10 @@ func authMiddleware(jwtManager *jwt.Manager, redisClient *redis.Client) gin.Hand
 		c.Set("email", claims.Email)
 		c.Set("roles", claims.Roles)
 		c.Set("permissions", claims.Permissions)
+		c.Set("tenantId", claims.TenantID)
+		c.Set("workspaceId", claims.WorkspaceID)
+		c.Set("plan", claims.Plan)
+		c.Set("tokenScope", claims.TokenScope)
 		c.Set("token", token) // Store token for logout
 
 		c.Next()
@@ -368,7 +439,7 @@ func serveSwaggerUI() gin.HandlerFunc {
     <script>
         window.onload = function() {
             SwaggerUIBundle({
-                url: "/api/openapi.yaml",
+                url: "/api/v1/openapi.json",
                 dom_id: '#swagger-ui',
                 presets: [
                     SwaggerUIBundle.presets.apis,
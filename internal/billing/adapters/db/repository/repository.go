@@ -6,6 +6,8 @@ import (
 
 	billing "github.com/linkflow-go/internal/billing/domain"
 	"github.com/linkflow-go/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BillingRepository struct {
@@ -103,6 +105,29 @@ func (r *BillingRepository) ListSubscriptions(ctx context.Context, userID string
 	return subscriptions, err
 }
 
+// ListSubscriptionsDue returns the active subscriptions whose period ended
+// by at
+func (r *BillingRepository) ListSubscriptionsDue(ctx context.Context, at time.Time) ([]*billing.Subscription, error) {
+	var subscriptions []*billing.Subscription
+	err := r.db.WithContext(ctx).
+		Where("status IN ? AND current_period_end <= ?",
+			[]string{billing.SubscriptionStatusActive, billing.SubscriptionStatusTrialing}, at).
+		Order("current_period_end ASC").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// ListProviderSubscriptions returns the active subscriptions of a payment
+// provider
+func (r *BillingRepository) ListProviderSubscriptions(ctx context.Context, provider string) ([]*billing.Subscription, error) {
+	var subscriptions []*billing.Subscription
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND status IN ?", provider,
+			[]string{billing.SubscriptionStatusActive, billing.SubscriptionStatusTrialing}).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
 func (r *BillingRepository) UpdateSubscription(ctx context.Context, subscription *billing.Subscription) error {
 	subscription.UpdatedAt = time.Now()
 	return r.db.WithContext(ctx).Save(subscription).Error
@@ -144,6 +169,23 @@ func (r *BillingRepository) ListInvoices(ctx context.Context, userID string, lim
 	return invoices, err
 }
 
+// CreateInvoiceForPeriod stores the invoice of the current period of a
+// subscription and moves the subscription to its next period. An invoice
+// already stored under the same number is kept.
+func (r *BillingRepository) CreateInvoiceForPeriod(ctx context.Context, invoice *billing.Invoice, subscription *billing.Subscription) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if invoice != nil {
+			err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "invoice_number"}}, DoNothing: true}).
+				Create(invoice).Error
+			if err != nil {
+				return err
+			}
+		}
+		subscription.NextPeriod()
+		return tx.Save(subscription).Error
+	})
+}
+
 func (r *BillingRepository) UpdateInvoice(ctx context.Context, invoice *billing.Invoice) error {
 	invoice.UpdatedAt = time.Now()
 	return r.db.WithContext(ctx).Save(invoice).Error
@@ -233,6 +275,35 @@ func (r *BillingRepository) GetUsageByMetric(ctx context.Context, subscriptionID
 	return total, err
 }
 
+// Execution cost operations
+
+// RecordExecutionCost stores the cost of an execution once, redeliveries of
+// the same cost are ignored
+func (r *BillingRepository) RecordExecutionCost(ctx context.Context, cost *billing.ExecutionCost) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "execution_id"}}, DoNothing: true}).
+		Create(cost).Error
+}
+
+// SumExecutionCosts aggregates per workflow the execution costs calculated
+// within [start, end) of a team, or of the workflows of a user outside any
+// team when teamID is empty
+func (r *BillingRepository) SumExecutionCosts(ctx context.Context, userID, teamID string, start, end time.Time) ([]billing.WorkflowUsage, error) {
+	query := r.db.WithContext(ctx).
+		Model(&billing.ExecutionCost{}).
+		Select("workflow_id, COUNT(*) AS executions, COALESCE(SUM(total), 0) AS total").
+		Where("calculated_at >= ? AND calculated_at < ?", start, end)
+	if teamID != "" {
+		query = query.Where("team_id = ?", teamID)
+	} else {
+		query = query.Where("user_id = ? AND (team_id = '' OR team_id IS NULL)", userID)
+	}
+
+	var usage []billing.WorkflowUsage
+	err := query.Group("workflow_id").Order("workflow_id").Scan(&usage).Error
+	return usage, err
+}
+
 // Coupon operations
 
 func (r *BillingRepository) GetCoupon(ctx context.Context, code string) (*billing.Coupon, error) {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/linkflow-go/internal/billing/app/service"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/logger"
 )
 
@@ -24,10 +25,6 @@ func (h *BillingHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
-func (h *BillingHandlers) Ready(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
-}
-
 // Plan handlers
 
 func (h *BillingHandlers) ListPlans(c *gin.Context) {
@@ -117,6 +114,27 @@ func (h *BillingHandlers) GetInvoice(c *gin.Context) {
 	c.JSON(http.StatusOK, invoice)
 }
 
+// PreviewCharges prices the current period of the active subscription of
+// the caller so far, as it would be invoiced
+func (h *BillingHandlers) PreviewCharges(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+
+	charges, err := h.service.PreviewCharges(c.Request.Context(), userID)
+	if err != nil {
+		if !apperrors.HasCategory(err, apperrors.CategoryNotFound) {
+			h.logger.Error("Failed to preview charges", "userId", userID, "error", err)
+		}
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, charges)
+}
+
 // Payment Method handlers
 
 func (h *BillingHandlers) ListPaymentMethods(c *gin.Context) {
@@ -3,6 +3,7 @@ package stripe
 import (
 	"context"
 	"fmt"
+	"time"
 
 	billing "github.com/linkflow-go/internal/billing/domain"
 	"github.com/stripe/stripe-go/v76"
@@ -12,6 +13,7 @@ import (
 	"github.com/stripe/stripe-go/v76/price"
 	"github.com/stripe/stripe-go/v76/product"
 	"github.com/stripe/stripe-go/v76/subscription"
+	"github.com/stripe/stripe-go/v76/usagerecord"
 	"github.com/stripe/stripe-go/v76/webhook"
 )
 
@@ -201,6 +203,36 @@ func (c *Client) VoidInvoice(ctx context.Context, invoiceID string) (*stripe.Inv
 	return invoice.VoidInvoice(invoiceID, nil)
 }
 
+// Usage operations
+
+// ReportUsage sets the usage of the metered item of a subscription at a
+// time. The metered price should aggregate the last usage of the period,
+// each report carries the usage of the period so far.
+func (c *Client) ReportUsage(ctx context.Context, sub *billing.Subscription, quantity int64, at time.Time) error {
+	if sub.ProviderSubscriptionID == "" {
+		return fmt.Errorf("subscription %s has no Stripe subscription", sub.ID)
+	}
+	stripeSub, err := subscription.Get(sub.ProviderSubscriptionID, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range stripeSub.Items.Data {
+		if item.Price == nil || item.Price.Recurring == nil ||
+			item.Price.Recurring.UsageType != stripe.PriceRecurringUsageTypeMetered {
+			continue
+		}
+		_, err := usagerecord.New(&stripe.UsageRecordParams{
+			SubscriptionItem: stripe.String(item.ID),
+			Quantity:         stripe.Int64(quantity),
+			Timestamp:        stripe.Int64(at.Unix()),
+			Action:           stripe.String("set"),
+		})
+		return err
+	}
+	return fmt.Errorf("Stripe subscription %s has no metered item", sub.ProviderSubscriptionID)
+}
+
 // Webhook handling
 
 func (c *Client) ConstructWebhookEvent(payload []byte, signature string) (stripe.Event, error) {
@@ -0,0 +1,265 @@
+// Package invoicing bills subscriptions for the executions of their owners.
+// Ended periods are invoiced from the execution costs calculated within
+// them, and the usage of the subscriptions Stripe invoices is reported to it.
+package invoicing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	billing "github.com/linkflow-go/internal/billing/domain"
+	"github.com/linkflow-go/internal/billing/ports"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	lockKey = "billing:invoicing:lock"
+
+	// dueIn is how long an invoice can be paid after it is issued
+	dueIn = 14 * 24 * time.Hour
+
+	// maxCatchUp bounds the ended periods of a subscription invoiced at
+	// once, after the service was down for long
+	maxCatchUp = 12
+)
+
+// Invoicer invoices the ended periods of subscriptions and reports metered
+// usage, on one replica at a time
+type Invoicer struct {
+	repo     ports.BillingRepository
+	reporter ports.UsageReporter
+	redis    *redis.Client
+	interval time.Duration
+	logger   logger.Logger
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// NewInvoicer creates an invoicer running every interval once started
+func NewInvoicer(repo ports.BillingRepository, redis *redis.Client, interval time.Duration, log logger.Logger) *Invoicer {
+	return &Invoicer{
+		repo:     repo,
+		redis:    redis,
+		interval: interval,
+		logger:   log,
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// SetUsageReporter reports the usage of Stripe subscriptions to Stripe,
+// which invoices them instead of the invoicer
+func (i *Invoicer) SetUsageReporter(reporter ports.UsageReporter) {
+	i.reporter = reporter
+}
+
+// Preview prices the current period of a subscription so far
+func (i *Invoicer) Preview(ctx context.Context, sub *billing.Subscription) (*billing.Charges, error) {
+	end := time.Now()
+	if sub.CurrentPeriodEnd.Before(end) {
+		end = sub.CurrentPeriodEnd
+	}
+	charges, err := i.charges(ctx, sub, sub.CurrentPeriodStart, end)
+	if err != nil {
+		return nil, err
+	}
+	charges.PeriodEnd = sub.CurrentPeriodEnd
+	return charges, nil
+}
+
+// charges prices a subscription from start to end
+func (i *Invoicer) charges(ctx context.Context, sub *billing.Subscription, start, end time.Time) (*billing.Charges, error) {
+	plan, err := i.repo.GetPlan(ctx, sub.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	teamID := ""
+	if sub.TeamID != nil {
+		teamID = *sub.TeamID
+	}
+	usage, err := i.repo.SumExecutionCosts(ctx, sub.UserID, teamID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum execution costs: %w", err)
+	}
+	return billing.NewCharges(sub, plan, start, end, usage), nil
+}
+
+// Start invoices and reports usage every interval until Stop
+func (i *Invoicer) Start() {
+	i.startOnce.Do(func() {
+		go i.run()
+	})
+}
+
+// Stop ends the background runs and waits for the current one
+func (i *Invoicer) Stop() {
+	i.stopOnce.Do(func() {
+		close(i.stopCh)
+	})
+	i.startOnce.Do(func() {
+		close(i.done)
+	})
+	<-i.done
+}
+
+func (i *Invoicer) run() {
+	defer close(i.done)
+
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-i.stopCh:
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-i.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		if err := i.Tick(ctx); err != nil {
+			i.logger.Error("Failed to invoice subscriptions", "error", err)
+		}
+		cancel()
+	}
+}
+
+// Tick invoices the subscriptions whose period ended, then reports the
+// usage of the Stripe subscriptions, unless another replica is doing so
+func (i *Invoicer) Tick(ctx context.Context) error {
+	owner := uuid.New().String()
+	ok, err := i.redis.SetNX(ctx, lockKey, owner, i.interval).Result()
+	if err != nil || !ok {
+		return err
+	}
+	defer i.unlock(owner)
+
+	if err := i.invoiceDue(ctx); err != nil {
+		return err
+	}
+	if i.reporter == nil {
+		return nil
+	}
+	return i.reportUsage(ctx)
+}
+
+// unlock releases the lock if it is still held by owner
+func (i *Invoicer) unlock(owner string) {
+	ctx := context.Background()
+	if held, err := i.redis.Get(ctx, lockKey).Result(); err == nil && held == owner {
+		i.redis.Del(ctx, lockKey)
+	}
+}
+
+// invoiceDue invoices each ended period of the subscriptions and moves them
+// to their current period
+func (i *Invoicer) invoiceDue(ctx context.Context) error {
+	now := time.Now()
+	subs, err := i.repo.ListSubscriptionsDue(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to list subscriptions due: %w", err)
+	}
+
+	for _, sub := range subs {
+		for n := 0; n < maxCatchUp && !sub.CurrentPeriodEnd.After(now); n++ {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err := i.closePeriod(ctx, sub); err != nil {
+				i.logger.Error("Failed to invoice subscription", "subscriptionId", sub.ID, "error", err)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// closePeriod invoices the current period of a subscription, which ended.
+// Stripe invoices its subscriptions itself, it gets their final usage.
+func (i *Invoicer) closePeriod(ctx context.Context, sub *billing.Subscription) error {
+	start, end := sub.CurrentPeriodStart, sub.CurrentPeriodEnd
+	charges, err := i.charges(ctx, sub, start, end)
+	if err != nil {
+		return err
+	}
+
+	var invoice *billing.Invoice
+	if sub.Provider == billing.ProviderStripe {
+		if i.reporter != nil {
+			// Within the ended period, usage reported at its end belongs to the next
+			i.report(ctx, sub, charges, end.Add(-time.Second))
+		}
+	} else {
+		number := fmt.Sprintf("INV-%s-%s", start.UTC().Format("20060102"), sub.ID)
+		invoice = charges.Invoice(sub, number, dueIn)
+	}
+
+	if err := i.repo.CreateInvoiceForPeriod(ctx, invoice, sub); err != nil {
+		return err
+	}
+	if invoice != nil {
+		metrics.BillingInvoices.Inc()
+		i.logger.Info("Subscription invoiced",
+			"subscriptionId", sub.ID,
+			"invoiceNumber", invoice.InvoiceNumber,
+			"total", invoice.Total,
+			"executions", charges.Executions,
+		)
+	}
+	return nil
+}
+
+// reportUsage reports the usage of the current period so far of the Stripe
+// subscriptions
+func (i *Invoicer) reportUsage(ctx context.Context) error {
+	subs, err := i.repo.ListProviderSubscriptions(ctx, billing.ProviderStripe)
+	if err != nil {
+		return fmt.Errorf("failed to list Stripe subscriptions: %w", err)
+	}
+
+	now := time.Now()
+	for _, sub := range subs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !sub.CurrentPeriodEnd.After(now) {
+			// Reported when its period is closed
+			continue
+		}
+		charges, err := i.charges(ctx, sub, sub.CurrentPeriodStart, now)
+		if err != nil {
+			i.logger.Error("Failed to price subscription usage", "subscriptionId", sub.ID, "error", err)
+			continue
+		}
+		i.report(ctx, sub, charges, now)
+	}
+	return nil
+}
+
+// report sends the execution usage of charges in cents, the plan price is
+// billed by Stripe from the licensed item of the subscription
+func (i *Invoicer) report(ctx context.Context, sub *billing.Subscription, charges *billing.Charges, at time.Time) {
+	cents := int64(math.Round(charges.Usage * 100))
+	if err := i.reporter.ReportUsage(ctx, sub, cents, at); err != nil {
+		metrics.BillingUsageReports.WithLabelValues("failed").Inc()
+		i.logger.Error("Failed to report subscription usage", "subscriptionId", sub.ID, "error", err)
+		return
+	}
+	metrics.BillingUsageReports.WithLabelValues("reported").Inc()
+}
@@ -3,7 +3,9 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"github.com/linkflow-go/internal/billing/app/invoicing"
 	billing "github.com/linkflow-go/internal/billing/domain"
 	"github.com/linkflow-go/internal/billing/ports"
 	"github.com/linkflow-go/pkg/events"
@@ -16,6 +18,7 @@ type BillingService struct {
 	repo     ports.BillingRepository
 	eventBus events.EventBus
 	redis    *redis.Client
+	invoicer *invoicing.Invoicer
 	logger   logger.Logger
 }
 
@@ -34,6 +37,11 @@ func NewBillingService(
 	}
 }
 
+// SetInvoicer enables billing the execution costs of subscriptions
+func (s *BillingService) SetInvoicer(invoicer *invoicing.Invoicer) {
+	s.invoicer = invoicer
+}
+
 // GetPlan returns a plan by ID
 func (s *BillingService) GetPlan(ctx context.Context, id string) (*billing.Plan, error) {
 	return s.repo.GetPlan(ctx, id)
@@ -89,3 +97,53 @@ func (s *BillingService) RecordUsage(ctx context.Context, subscriptionID, metric
 func (s *BillingService) GetCoupon(ctx context.Context, code string) (*billing.Coupon, error) {
 	return s.repo.GetCoupon(ctx, code)
 }
+
+// PreviewCharges prices the current period of the active subscription of a
+// user so far
+func (s *BillingService) PreviewCharges(ctx context.Context, userID string) (*billing.Charges, error) {
+	if s.invoicer == nil {
+		return nil, billing.ErrBillingDisabled
+	}
+	sub, err := s.repo.GetActiveSubscription(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.invoicer.Preview(ctx, sub)
+}
+
+// HandleCostCalculated records the cost of a completed execution, to be
+// billed to the owners of its workflow
+func (s *BillingService) HandleCostCalculated(ctx context.Context, event events.Event) error {
+	var payload struct {
+		Cost struct {
+			ExecutionID  string        `json:"execution_id"`
+			WorkflowID   string        `json:"workflow_id"`
+			UserID       string        `json:"user_id"`
+			TeamID       string        `json:"team_id"`
+			ComputeTime  time.Duration `json:"compute_time"`
+			SubTotal     float64       `json:"subtotal"`
+			TotalCost    float64       `json:"total_cost"`
+			CalculatedAt time.Time     `json:"calculated_at"`
+		} `json:"cost"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+	cost := payload.Cost
+	if cost.UserID == "" {
+		s.logger.Warn("Execution cost has no owner, not billed", "executionId", cost.ExecutionID)
+		return nil
+	}
+
+	return s.repo.RecordExecutionCost(ctx, &billing.ExecutionCost{
+		ExecutionID:    cost.ExecutionID,
+		WorkflowID:     cost.WorkflowID,
+		UserID:         cost.UserID,
+		TeamID:         cost.TeamID,
+		ComputeSeconds: cost.ComputeTime.Seconds(),
+		Subtotal:       cost.SubTotal,
+		Total:          cost.TotalCost,
+		CalculatedAt:   cost.CalculatedAt,
+		CreatedAt:      time.Now(),
+	})
+}
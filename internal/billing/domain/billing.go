@@ -1,21 +1,22 @@
 package billing
 
 import (
-	"errors"
 	"time"
 
 	"github.com/google/uuid"
+	apperrors "github.com/linkflow-go/pkg/errors"
 )
 
 // Errors
 var (
-	ErrSubscriptionNotFound  = errors.New("subscription not found")
-	ErrPlanNotFound          = errors.New("plan not found")
-	ErrInvoiceNotFound       = errors.New("invoice not found")
-	ErrPaymentMethodNotFound = errors.New("payment method not found")
-	ErrInvalidPlan           = errors.New("invalid plan")
-	ErrSubscriptionCancelled = errors.New("subscription is cancelled")
-	ErrPaymentFailed         = errors.New("payment failed")
+	ErrSubscriptionNotFound  = apperrors.New(apperrors.CategoryNotFound, "SUBSCRIPTION_NOT_FOUND", "subscription not found")
+	ErrPlanNotFound          = apperrors.New(apperrors.CategoryNotFound, "PLAN_NOT_FOUND", "plan not found")
+	ErrInvoiceNotFound       = apperrors.New(apperrors.CategoryNotFound, "INVOICE_NOT_FOUND", "invoice not found")
+	ErrPaymentMethodNotFound = apperrors.New(apperrors.CategoryNotFound, "PAYMENT_METHOD_NOT_FOUND", "payment method not found")
+	ErrInvalidPlan           = apperrors.New(apperrors.CategoryValidation, "INVALID_PLAN", "invalid plan")
+	ErrSubscriptionCancelled = apperrors.New(apperrors.CategoryConflict, "SUBSCRIPTION_CANCELLED", "subscription is cancelled")
+	ErrPaymentFailed         = apperrors.New(apperrors.CategoryUpstream, "PAYMENT_FAILED", "payment failed")
+	ErrBillingDisabled       = apperrors.New(apperrors.CategoryInternal, "BILLING_DISABLED", "billing is not enabled")
 )
 
 // Subscription statuses
@@ -27,6 +28,12 @@ const (
 	SubscriptionStatusPaused    = "paused"
 )
 
+// Payment providers
+const (
+	ProviderStripe = "stripe"
+	ProviderManual = "manual"
+)
+
 // Plan intervals
 const (
 	IntervalMonthly = "monthly"
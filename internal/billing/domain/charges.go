@@ -0,0 +1,117 @@
+package billing
+
+import (
+	"math"
+	"time"
+)
+
+// ExecutionCost is the price of one completed execution, as calculated by
+// the cost calculator of the execution service. Executions are billed to the
+// team of their workflow, to its user when it has no team.
+type ExecutionCost struct {
+	ExecutionID    string    `json:"executionId" gorm:"primaryKey;column:execution_id"`
+	WorkflowID     string    `json:"workflowId" gorm:"column:workflow_id;index"`
+	UserID         string    `json:"userId" gorm:"column:user_id;index"`
+	TeamID         string    `json:"teamId" gorm:"column:team_id;index"`
+	ComputeSeconds float64   `json:"computeSeconds" gorm:"column:compute_seconds"`
+	Subtotal       float64   `json:"subtotal"`
+	Total          float64   `json:"total"`
+	CalculatedAt   time.Time `json:"calculatedAt" gorm:"column:calculated_at;index"`
+	CreatedAt      time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ExecutionCost) TableName() string {
+	return "billing.execution_costs"
+}
+
+// WorkflowUsage aggregates the execution costs of a workflow over a period
+type WorkflowUsage struct {
+	WorkflowID string  `json:"workflowId"`
+	Executions int     `json:"executions"`
+	Total      float64 `json:"total"`
+}
+
+// Charges are what a subscription owes for a period: the price of its plan
+// and the costs of the executions of its owner
+type Charges struct {
+	SubscriptionID string        `json:"subscriptionId"`
+	PeriodStart    time.Time     `json:"periodStart"`
+	PeriodEnd      time.Time     `json:"periodEnd"`
+	Currency       string        `json:"currency"`
+	Executions     int           `json:"executions"`
+	LineItems      []InvoiceLine `json:"lineItems"`
+	// Usage is the part of Total charged for executions
+	Usage float64 `json:"usage"`
+	Total float64 `json:"total"`
+}
+
+// NewCharges prices a period of a subscription on its plan, with a line for
+// the plan and one per workflow that ran
+func NewCharges(sub *Subscription, plan *Plan, start, end time.Time, usage []WorkflowUsage) *Charges {
+	charges := &Charges{
+		SubscriptionID: sub.ID,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		Currency:       plan.Currency,
+		LineItems:      []InvoiceLine{},
+	}
+
+	price := plan.PriceMonthly
+	if sub.BillingCycle == IntervalYearly {
+		price = plan.PriceYearly
+	}
+	if price > 0 {
+		charges.LineItems = append(charges.LineItems, InvoiceLine{
+			Description: plan.Name + " plan",
+			Quantity:    1,
+			UnitAmount:  price,
+			Amount:      price,
+		})
+		charges.Total += price
+	}
+
+	for _, u := range usage {
+		amount := roundCents(u.Total)
+		charges.LineItems = append(charges.LineItems, InvoiceLine{
+			Description: "Executions of workflow " + u.WorkflowID,
+			Quantity:    u.Executions,
+			UnitAmount:  roundCents(u.Total / float64(u.Executions)),
+			Amount:      amount,
+		})
+		charges.Executions += u.Executions
+		charges.Usage += amount
+		charges.Total += amount
+	}
+	charges.Usage = roundCents(charges.Usage)
+	charges.Total = roundCents(charges.Total)
+	return charges
+}
+
+// Invoice turns the charges into an open invoice due after dueIn
+func (c *Charges) Invoice(sub *Subscription, invoiceNumber string, dueIn time.Duration) *Invoice {
+	invoice := NewInvoice(sub.ID, sub.UserID, c.Total)
+	invoice.InvoiceNumber = invoiceNumber
+	invoice.Status = InvoiceStatusOpen
+	invoice.Currency = c.Currency
+	invoice.Subtotal = c.Total
+	invoice.LineItems = c.LineItems
+	due := invoice.CreatedAt.Add(dueIn)
+	invoice.DueDate = &due
+	return invoice
+}
+
+// NextPeriod moves a subscription to its next billing period
+func (s *Subscription) NextPeriod() {
+	s.CurrentPeriodStart = s.CurrentPeriodEnd
+	if s.BillingCycle == IntervalYearly {
+		s.CurrentPeriodEnd = s.CurrentPeriodStart.AddDate(1, 0, 0)
+	} else {
+		s.CurrentPeriodEnd = s.CurrentPeriodStart.AddDate(0, 1, 0)
+	}
+	s.UpdatedAt = time.Now()
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	billing "github.com/linkflow-go/internal/billing/domain"
 )
@@ -14,11 +15,24 @@ type BillingRepository interface {
 	GetSubscription(ctx context.Context, id string) (*billing.Subscription, error)
 	GetActiveSubscription(ctx context.Context, userID string) (*billing.Subscription, error)
 	ListSubscriptions(ctx context.Context, userID string) ([]*billing.Subscription, error)
+	ListSubscriptionsDue(ctx context.Context, at time.Time) ([]*billing.Subscription, error)
+	ListProviderSubscriptions(ctx context.Context, provider string) ([]*billing.Subscription, error)
+	UpdateSubscription(ctx context.Context, subscription *billing.Subscription) error
 
 	GetInvoice(ctx context.Context, id string) (*billing.Invoice, error)
 	ListInvoices(ctx context.Context, userID string, limit int) ([]*billing.Invoice, error)
+	CreateInvoiceForPeriod(ctx context.Context, invoice *billing.Invoice, subscription *billing.Subscription) error
 
 	ListPaymentMethods(ctx context.Context, userID string) ([]*billing.PaymentMethod, error)
 	RecordUsage(ctx context.Context, usage *billing.Usage) error
 	GetCoupon(ctx context.Context, code string) (*billing.Coupon, error)
+
+	RecordExecutionCost(ctx context.Context, cost *billing.ExecutionCost) error
+	SumExecutionCosts(ctx context.Context, userID, teamID string, start, end time.Time) ([]billing.WorkflowUsage, error)
+}
+
+// UsageReporter reports the metered usage of the subscriptions a payment
+// provider invoices
+type UsageReporter interface {
+	ReportUsage(ctx context.Context, subscription *billing.Subscription, quantity int64, at time.Time) error
 }
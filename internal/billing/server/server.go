@@ -9,11 +9,16 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/linkflow-go/internal/billing/adapters/db/repository"
 	"github.com/linkflow-go/internal/billing/adapters/http/handlers"
+	"github.com/linkflow-go/internal/billing/adapters/stripe"
+	"github.com/linkflow-go/internal/billing/app/invoicing"
 	"github.com/linkflow-go/internal/billing/app/service"
+	"github.com/linkflow-go/pkg/apidoc"
 	"github.com/linkflow-go/pkg/config"
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/middleware/correlation"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 )
@@ -25,6 +30,7 @@ type Server struct {
 	db         *database.DB
 	redis      *redis.Client
 	eventBus   events.EventBus
+	invoicer   *invoicing.Invoicer
 }
 
 func New(cfg *config.Config, log logger.Logger) (*Server, error) {
@@ -44,16 +50,45 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	eventBus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
 
 	billingRepo := repository.NewBillingRepository(db)
 	billingService := service.NewBillingService(billingRepo, eventBus, redisClient, log)
+
+	// Subscriptions are billed the execution costs of their owners
+	var invoicer *invoicing.Invoicer
+	if cfg.Billing.Enabled {
+		invoicer = invoicing.NewInvoicer(billingRepo, redisClient,
+			time.Duration(cfg.Billing.InvoiceInterval)*time.Second, log)
+		if cfg.Billing.Stripe.SecretKey != "" {
+			invoicer.SetUsageReporter(stripe.NewClient(cfg.Billing.Stripe.SecretKey, cfg.Billing.Stripe.WebhookSecret))
+		} else {
+			log.Warn("Stripe is not configured, usage of Stripe subscriptions is not reported")
+		}
+		billingService.SetInvoicer(invoicer)
+
+		if err := eventBus.Subscribe("cost.calculated", billingService.HandleCostCalculated); err != nil {
+			return nil, fmt.Errorf("failed to subscribe to execution costs: %w", err)
+		}
+	}
+
 	billingHandlers := handlers.NewBillingHandlers(billingService, log)
 
-	router := setupRouter(billingHandlers, log)
+	// Readiness reports the state of each dependency
+	checker := health.NewChecker("billing-service")
+	checker.Critical("database", health.Database(db))
+	checker.Critical("redis", health.Redis(redisClient))
+	checker.Optional("event_bus", health.EventBus(eventBus))
+
+	router := setupRouter(billingHandlers, checker, log)
+
+	// Serve the OpenAPI document of the routes registered above
+	if err := apidoc.Serve(router, "billing", cfg.Server.StrictAPISpec, log); err != nil {
+		return nil, err
+	}
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -69,20 +104,22 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		db:         db,
 		redis:      redisClient,
 		eventBus:   eventBus,
+		invoicer:   invoicer,
 	}, nil
 }
 
-func setupRouter(h *handlers.BillingHandlers, log logger.Logger) *gin.Engine {
+func setupRouter(h *handlers.BillingHandlers, checker *health.Checker, log logger.Logger) *gin.Engine {
 	router := gin.New()
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
 	router.Use(corsMiddleware())
 	router.Use(loggingMiddleware(log))
 
 	router.GET("/health/live", h.Health)
-	router.GET("/health/ready", h.Ready)
+	router.GET("/health/ready", checker.ReadyHandler())
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
-	v1 := router.Group("/api/v1/billing")
+	v1 := router.Group(apidoc.Prefix + "/billing")
 	{
 		v1.GET("/subscriptions", h.GetSubscriptions)
 		v1.GET("/subscriptions/:id", h.GetSubscription)
@@ -91,6 +128,7 @@ func setupRouter(h *handlers.BillingHandlers, log logger.Logger) *gin.Engine {
 		v1.GET("/payment-methods", h.ListPaymentMethods)
 		v1.GET("/invoices", h.ListInvoices)
 		v1.GET("/invoices/:id", h.GetInvoice)
+		v1.GET("/preview", h.PreviewCharges)
 		v1.GET("/coupons/:code", h.GetCoupon)
 	}
 
@@ -98,6 +136,11 @@ func setupRouter(h *handlers.BillingHandlers, log logger.Logger) *gin.Engine {
 }
 
 func (s *Server) Start() error {
+	// Invoice ended periods and report usage to Stripe
+	if s.invoicer != nil {
+		s.invoicer.Start()
+	}
+
 	s.logger.Info("Starting HTTP server", "port", s.config.Server.Port)
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start HTTP server: %w", err)
@@ -108,6 +151,10 @@ func (s *Server) Start() error {
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("Shutting down server...")
 
+	if s.invoicer != nil {
+		s.invoicer.Stop()
+	}
+
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown HTTP server: %w", err)
 	}
@@ -131,7 +178,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -160,7 +207,7 @@ func loggingMiddleware(log logger.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		log.Info("HTTP Request",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP Request",
 			"method", method,
 			"path", path,
 			"status", statusCode,
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/linkflow-go/pkg/contracts/credential"
 	"github.com/linkflow-go/pkg/database"
+	"gorm.io/gorm"
 )
 
 type CredentialRepository struct {
@@ -38,3 +40,45 @@ func (r *CredentialRepository) UpdateCredential(ctx context.Context, cred *crede
 func (r *CredentialRepository) DeleteCredential(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&credential.Credential{}).Error
 }
+
+func (r *CredentialRepository) CountCredentialsByBackend(ctx context.Context, backend string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&credential.Credential{}).
+		Where("secret_backend = ?", backend).
+		Count(&count).Error
+	return count, err
+}
+
+// ListCredentialsByBackend pages through the credentials of a secret backend
+// ordered by ID, starting after afterID
+func (r *CredentialRepository) ListCredentialsByBackend(ctx context.Context, backend, afterID string, limit int) ([]*credential.Credential, error) {
+	var creds []*credential.Credential
+	query := r.db.WithContext(ctx).Where("secret_backend = ?", backend)
+	if afterID != "" {
+		query = query.Where("id > ?", afterID)
+	}
+	err := query.Order("id ASC").Limit(limit).Find(&creds).Error
+	return creds, err
+}
+
+// UpdateCredentialSecrets saves the data and secret backend of a batch of
+// credentials in one transaction. The batch fails if any credential was
+// changed since it was read.
+func (r *CredentialRepository) UpdateCredentialSecrets(ctx context.Context, creds []*credential.Credential) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, cred := range creds {
+			readAt := cred.UpdatedAt
+			result := tx.Model(cred).
+				Select("data", "secret_backend", "updated_at").
+				Where("updated_at = ?", readAt).
+				Updates(cred)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("credential %s was changed or deleted concurrently", cred.ID)
+			}
+		}
+		return nil
+	})
+}
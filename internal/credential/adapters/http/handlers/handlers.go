@@ -25,10 +25,6 @@ func (h *CredentialHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
-func (h *CredentialHandlers) Ready(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
-}
-
 func (h *CredentialHandlers) ListCredentials(c *gin.Context) {
 	userID := c.GetHeader("X-User-ID")
 	if userID == "" {
@@ -0,0 +1,50 @@
+// Package rpc serves the credential gRPC API, resolving credentials for the
+// services using them
+package rpc
+
+import (
+	"context"
+
+	"github.com/linkflow-go/internal/credential/app/service"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/rpc"
+	"github.com/linkflow-go/pkg/rpc/credentialv1"
+)
+
+// Server implements credentialv1.CredentialServiceServer
+type Server struct {
+	credentialv1.UnimplementedCredentialServiceServer
+	service *service.CredentialService
+}
+
+// NewServer creates the credential API server
+func NewServer(service *service.CredentialService) *Server {
+	return &Server{service: service}
+}
+
+// ResolveCredential returns a credential with its data decrypted
+func (s *Server) ResolveCredential(ctx context.Context, req *credentialv1.ResolveCredentialRequest) (*credentialv1.Credential, error) {
+	if req.GetCredentialId() == "" || req.GetUserId() == "" {
+		return nil, apperrors.New(apperrors.CategoryValidation, apperrors.CodeInvalidRequest, "credential_id and user_id are required")
+	}
+
+	cred, err := s.service.GetDecryptedCredential(ctx, req.GetCredentialId(), req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := rpc.Struct(cred.Data)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	return &credentialv1.Credential{
+		Id:        cred.ID,
+		Name:      cred.Name,
+		Type:      cred.Type,
+		UserId:    cred.UserID,
+		TeamId:    cred.TeamID,
+		Data:      data,
+		IsActive:  cred.IsActive,
+		ExpiresAt: rpc.Timestamp(cred.ExpiresAt),
+	}, nil
+}
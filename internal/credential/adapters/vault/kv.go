@@ -0,0 +1,197 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/linkflow-go/internal/credential/ports"
+	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/contracts/credential"
+	"github.com/linkflow-go/pkg/logger"
+)
+
+// secretRefKey replaces the relocated fields in the credential data
+const secretRefKey = "secretRef"
+
+// KVStore keeps the sensitive fields of credentials in the KV v2 secrets
+// engine of HashiCorp Vault. The credential row only holds a reference.
+type KVStore struct {
+	address string
+	token   string
+	mount   string
+	path    string
+	client  *http.Client
+	logger  logger.Logger
+}
+
+func NewKVStore(cfg config.VaultConfig, logger logger.Logger) (*KVStore, error) {
+	if cfg.Address == "" || cfg.Token == "" {
+		return nil, errors.New("vault address and token are required")
+	}
+
+	return &KVStore{
+		address: strings.TrimRight(cfg.Address, "/"),
+		token:   cfg.Token,
+		mount:   strings.Trim(cfg.Mount, "/"),
+		path:    strings.Trim(cfg.Path, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+		logger:  logger,
+	}, nil
+}
+
+// Name identifies the backend on credentials
+func (s *KVStore) Name() string {
+	return credential.BackendVault
+}
+
+// HealthURL is the Vault endpoint answering 5xx while sealed or uninitialized
+func (s *KVStore) HealthURL() string {
+	return s.address + "/v1/sys/health"
+}
+
+// EncryptCredential moves the sensitive fields of a credential to Vault
+func (s *KVStore) EncryptCredential(ctx context.Context, cred *credential.Credential) error {
+	secrets := make(map[string]interface{})
+	for _, field := range sensitiveFields(cred.Type) {
+		if value, ok := cred.Data[field].(string); ok && value != "" {
+			secrets[field] = value
+		}
+	}
+	if len(secrets) == 0 {
+		cred.SecretBackend = s.Name()
+		return nil
+	}
+
+	body := map[string]interface{}{"data": secrets}
+	if err := s.do(ctx, http.MethodPost, s.dataURL(cred.ID), body, nil); err != nil {
+		return fmt.Errorf("failed to write secrets of credential %s: %w", cred.ID, err)
+	}
+
+	for field := range secrets {
+		delete(cred.Data, field)
+	}
+	cred.Data[secretRefKey] = fmt.Sprintf("vault://%s/%s", s.mount, s.secretPath(cred.ID))
+	cred.Data["encrypted"] = true
+	cred.SecretBackend = s.Name()
+	return nil
+}
+
+// DecryptCredential reads the sensitive fields of a credential back from Vault
+func (s *KVStore) DecryptCredential(ctx context.Context, cred *credential.Credential) error {
+	if encrypted, ok := cred.Data["encrypted"].(bool); !ok || !encrypted {
+		return nil
+	}
+	if _, ok := cred.Data[secretRefKey].(string); !ok {
+		return fmt.Errorf("credential %s has no vault secret reference", cred.ID)
+	}
+
+	var resp struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := s.do(ctx, http.MethodGet, s.dataURL(cred.ID), nil, &resp); err != nil {
+		return fmt.Errorf("failed to read secrets of credential %s: %w", cred.ID, err)
+	}
+
+	for field, value := range resp.Data.Data {
+		cred.Data[field] = value
+	}
+	delete(cred.Data, secretRefKey)
+	cred.Data["encrypted"] = false
+	return nil
+}
+
+// DeleteSecrets removes every version of the secrets of a credential
+func (s *KVStore) DeleteSecrets(ctx context.Context, cred *credential.Credential) error {
+	url := fmt.Sprintf("%s/v1/%s/metadata/%s", s.address, s.mount, s.secretPath(cred.ID))
+	if err := s.do(ctx, http.MethodDelete, url, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete secrets of credential %s: %w", cred.ID, err)
+	}
+	return nil
+}
+
+func (s *KVStore) secretPath(id string) string {
+	if s.path == "" {
+		return id
+	}
+	return s.path + "/" + id
+}
+
+func (s *KVStore) dataURL(id string) string {
+	return fmt.Sprintf("%s/v1/%s/data/%s", s.address, s.mount, s.secretPath(id))
+}
+
+func (s *KVStore) do(ctx context.Context, method, url string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// sensitiveFields lists the data fields kept secret per credential type,
+// matching the fields VaultManager encrypts
+func sensitiveFields(credType string) []string {
+	switch credType {
+	case credential.TypeAPIKey:
+		return []string{"apiKey"}
+	case credential.TypeOAuth2:
+		return []string{"accessToken", "refreshToken", "clientSecret"}
+	case credential.TypeBasicAuth:
+		return []string{"password"}
+	case credential.TypeSSHKey:
+		return []string{"privateKey", "passphrase"}
+	case credential.TypeDatabase:
+		return []string{"password", "connectionString"}
+	default:
+		return nil
+	}
+}
+
+// NewBackend creates the secret backend with the given name
+func NewBackend(name string, cfg config.CredentialConfig, logger logger.Logger) (ports.SecretBackend, error) {
+	switch name {
+	case credential.BackendLocal:
+		return NewVaultManager(cfg.EncryptionKey, logger)
+	case credential.BackendVault:
+		return NewKVStore(cfg.Vault, logger)
+	default:
+		return nil, fmt.Errorf("unknown secret backend %q", name)
+	}
+}
@@ -0,0 +1,92 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/linkflow-go/internal/credential/ports"
+	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/contracts/credential"
+	"github.com/linkflow-go/pkg/logger"
+)
+
+// Router encrypts new secrets with the configured backend and reads each
+// credential from the backend it was stored in, so credentials keep working
+// while they are migrated between backends
+type Router struct {
+	primary  ports.SecretBackend
+	backends map[string]ports.SecretBackend
+}
+
+// NewRouter creates the configured backend along with every other backend
+// that has enough config to read from
+func NewRouter(cfg config.CredentialConfig, logger logger.Logger) (*Router, error) {
+	primary, err := NewBackend(cfg.Backend, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Router{
+		primary:  primary,
+		backends: map[string]ports.SecretBackend{primary.Name(): primary},
+	}
+
+	if _, ok := r.backends[credential.BackendLocal]; !ok {
+		if local, err := NewVaultManager(cfg.EncryptionKey, logger); err == nil {
+			r.backends[local.Name()] = local
+		}
+	}
+	if _, ok := r.backends[credential.BackendVault]; !ok && cfg.Vault.Address != "" {
+		if kv, err := NewKVStore(cfg.Vault, logger); err == nil {
+			r.backends[kv.Name()] = kv
+		}
+	}
+
+	return r, nil
+}
+
+// Primary returns the backend new secrets are written to
+func (r *Router) Primary() ports.SecretBackend {
+	return r.primary
+}
+
+// Name identifies the backend new secrets are written to
+func (r *Router) Name() string {
+	return r.primary.Name()
+}
+
+// EncryptCredential stores the secrets of a credential in the primary backend
+func (r *Router) EncryptCredential(ctx context.Context, cred *credential.Credential) error {
+	return r.primary.EncryptCredential(ctx, cred)
+}
+
+// DecryptCredential reads the secrets of a credential from its backend
+func (r *Router) DecryptCredential(ctx context.Context, cred *credential.Credential) error {
+	backend, err := r.backendOf(cred)
+	if err != nil {
+		return err
+	}
+	return backend.DecryptCredential(ctx, cred)
+}
+
+// DeleteSecrets removes the secrets of a credential from its backend
+func (r *Router) DeleteSecrets(ctx context.Context, cred *credential.Credential) error {
+	backend, err := r.backendOf(cred)
+	if err != nil {
+		return err
+	}
+	return backend.DeleteSecrets(ctx, cred)
+}
+
+func (r *Router) backendOf(cred *credential.Credential) (ports.SecretBackend, error) {
+	name := cred.SecretBackend
+	if name == "" {
+		name = credential.BackendLocal
+	}
+
+	backend, ok := r.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("secret backend %q of credential %s is not configured", name, cred.ID)
+	}
+	return backend, nil
+}
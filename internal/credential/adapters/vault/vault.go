@@ -30,6 +30,16 @@ func NewVaultManager(key string, logger logger.Logger) (*VaultManager, error) {
 	}, nil
 }
 
+// Name identifies the backend on credentials
+func (v *VaultManager) Name() string {
+	return credential.BackendLocal
+}
+
+// DeleteSecrets is a no-op, the secrets are encrypted in the credential row
+func (v *VaultManager) DeleteSecrets(ctx context.Context, cred *credential.Credential) error {
+	return nil
+}
+
 // Encrypt encrypts credential data
 func (v *VaultManager) Encrypt(plaintext string) (string, error) {
 	block, err := aes.NewCipher(v.encryptionKey)
@@ -92,6 +102,8 @@ func (v *VaultManager) Decrypt(ciphertext string) (string, error) {
 
 // EncryptCredential encrypts a credential's sensitive data
 func (v *VaultManager) EncryptCredential(ctx context.Context, cred *credential.Credential) error {
+	cred.SecretBackend = v.Name()
+
 	// Encrypt based on credential type
 	switch cred.Type {
 	case credential.TypeAPIKey:
@@ -0,0 +1,245 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/linkflow-go/internal/credential/ports"
+	"github.com/linkflow-go/pkg/contracts/credential"
+	"github.com/linkflow-go/pkg/logger"
+)
+
+// DefaultBatchSize is the number of credentials migrated per transaction
+const DefaultBatchSize = 100
+
+// Options controls a secret backend migration
+type Options struct {
+	BatchSize int
+	// DryRun only checks every credential can be read from the source
+	DryRun bool
+	// KeepSource leaves the secrets in the source backend after success
+	KeepSource bool
+}
+
+// Progress is reported after every batch
+type Progress struct {
+	Batch    int
+	Migrated int
+	Total    int64
+}
+
+// Report summarizes a migration
+type Report struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	DryRun   bool          `json:"dryRun"`
+	Total    int64         `json:"total"`
+	Migrated int           `json:"migrated"`
+	Batches  int           `json:"batches"`
+	Duration time.Duration `json:"duration"`
+	// RolledBack is set when a failure restored the migrated credentials
+	RolledBack bool `json:"rolledBack"`
+	// PurgeFailures lists credentials whose source secrets could not be removed
+	PurgeFailures []string `json:"purgeFailures,omitempty"`
+}
+
+// Migrator moves the secrets of all credentials from one backend to another.
+// Batches are verified and committed atomically, a failure restores every
+// credential migrated so far.
+type Migrator struct {
+	repo       ports.CredentialRepository
+	from       ports.SecretBackend
+	to         ports.SecretBackend
+	logger     logger.Logger
+	onProgress func(Progress)
+}
+
+// migrated pairs a committed credential with its state before the migration
+type migrated struct {
+	original *credential.Credential
+	current  *credential.Credential
+}
+
+func NewMigrator(repo ports.CredentialRepository, from, to ports.SecretBackend, logger logger.Logger) *Migrator {
+	return &Migrator{
+		repo:   repo,
+		from:   from,
+		to:     to,
+		logger: logger,
+	}
+}
+
+// OnProgress registers a function called after every batch
+func (m *Migrator) OnProgress(fn func(Progress)) {
+	m.onProgress = fn
+}
+
+// Run migrates all credentials of the source backend
+func (m *Migrator) Run(ctx context.Context, opts Options) (*Report, error) {
+	if m.from.Name() == m.to.Name() {
+		return nil, fmt.Errorf("source and target backend are both %q", m.from.Name())
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	start := time.Now()
+	report := &Report{From: m.from.Name(), To: m.to.Name(), DryRun: opts.DryRun}
+
+	total, err := m.repo.CountCredentialsByBackend(ctx, m.from.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to count credentials: %w", err)
+	}
+	report.Total = total
+
+	m.logger.Info("Starting secret backend migration",
+		"from", report.From, "to", report.To, "total", total, "dryRun", opts.DryRun)
+
+	var committed []migrated
+	afterID := ""
+	for {
+		batch, err := m.repo.ListCredentialsByBackend(ctx, m.from.Name(), afterID, opts.BatchSize)
+		if err != nil {
+			return m.fail(ctx, report, committed, nil, fmt.Errorf("failed to list credentials: %w", err))
+		}
+		if len(batch) == 0 {
+			break
+		}
+		afterID = batch[len(batch)-1].ID
+
+		pending := make([]migrated, 0, len(batch))
+		for _, cred := range batch {
+			pending = append(pending, migrated{original: clone(cred), current: cred})
+			if err := m.migrate(ctx, cred, opts.DryRun); err != nil {
+				return m.fail(ctx, report, committed, pending, fmt.Errorf("credential %s: %w", cred.ID, err))
+			}
+		}
+
+		if !opts.DryRun {
+			if err := m.repo.UpdateCredentialSecrets(ctx, batch); err != nil {
+				return m.fail(ctx, report, committed, pending, fmt.Errorf("failed to save batch %d: %w", report.Batches+1, err))
+			}
+			committed = append(committed, pending...)
+		}
+
+		report.Batches++
+		report.Migrated += len(batch)
+		m.logger.Info("Migrated credential batch",
+			"batch", report.Batches, "migrated", report.Migrated, "total", total)
+		if m.onProgress != nil {
+			m.onProgress(Progress{Batch: report.Batches, Migrated: report.Migrated, Total: total})
+		}
+	}
+
+	// Secrets leave the source only once every credential reads from the target
+	if !opts.DryRun && !opts.KeepSource {
+		for _, c := range committed {
+			if err := m.from.DeleteSecrets(ctx, c.original); err != nil {
+				m.logger.Warn("Failed to remove source secrets", "id", c.original.ID, "error", err)
+				report.PurgeFailures = append(report.PurgeFailures, c.original.ID)
+			}
+		}
+	}
+
+	report.Duration = time.Since(start)
+	m.logger.Info("Secret backend migration completed",
+		"from", report.From, "to", report.To, "migrated", report.Migrated, "duration", report.Duration)
+	return report, nil
+}
+
+// migrate moves the secrets of one credential to the target backend and
+// checks they read back unchanged
+func (m *Migrator) migrate(ctx context.Context, cred *credential.Credential, dryRun bool) error {
+	if err := m.from.DecryptCredential(ctx, cred); err != nil {
+		return fmt.Errorf("failed to read from %s: %w", m.from.Name(), err)
+	}
+	if dryRun {
+		return nil
+	}
+	plain := clone(cred)
+
+	if err := m.to.EncryptCredential(ctx, cred); err != nil {
+		return fmt.Errorf("failed to write to %s: %w", m.to.Name(), err)
+	}
+
+	check := clone(cred)
+	if err := m.to.DecryptCredential(ctx, check); err != nil {
+		return fmt.Errorf("failed to verify in %s: %w", m.to.Name(), err)
+	}
+	if !sameSecrets(plain.Data, check.Data) {
+		return errors.New("verification failed, secrets read back from the target differ")
+	}
+	return nil
+}
+
+// fail restores every migrated credential and removes what was written to
+// the target, then returns the cause
+func (m *Migrator) fail(ctx context.Context, report *Report, committed, pending []migrated, cause error) (*Report, error) {
+	m.logger.Error("Secret backend migration failed, rolling back",
+		"migrated", len(committed), "error", cause)
+
+	// The rollback must run even if the migration was cancelled
+	ctx = context.WithoutCancel(ctx)
+
+	var restoreErr error
+	for i := 0; i < len(committed); i += DefaultBatchSize {
+		chunk := committed[i:min(i+DefaultBatchSize, len(committed))]
+		restore := make([]*credential.Credential, 0, len(chunk))
+		for _, c := range chunk {
+			r := clone(c.original)
+			// Guard against overwriting changes made after the migration
+			r.UpdatedAt = c.current.UpdatedAt
+			restore = append(restore, r)
+		}
+		if err := m.repo.UpdateCredentialSecrets(ctx, restore); err != nil {
+			// These credentials still read from the target, keep their secrets
+			restoreErr = errors.Join(restoreErr, err)
+			continue
+		}
+		m.deleteTargetSecrets(ctx, chunk)
+	}
+	m.deleteTargetSecrets(ctx, pending)
+
+	if restoreErr != nil {
+		return report, fmt.Errorf("%w, rollback incomplete: %v", cause, restoreErr)
+	}
+	report.Migrated = 0
+	report.RolledBack = true
+	return report, cause
+}
+
+func (m *Migrator) deleteTargetSecrets(ctx context.Context, creds []migrated) {
+	for _, c := range creds {
+		if err := m.to.DeleteSecrets(ctx, c.original); err != nil {
+			m.logger.Warn("Failed to remove target secrets", "id", c.original.ID, "error", err)
+		}
+	}
+}
+
+func clone(cred *credential.Credential) *credential.Credential {
+	c := *cred
+	c.Data = make(map[string]interface{}, len(cred.Data))
+	for k, v := range cred.Data {
+		c.Data[k] = v
+	}
+	return &c
+}
+
+// sameSecrets compares decrypted data, ignoring the encryption marker
+func sameSecrets(a, b map[string]interface{}) bool {
+	a, b = withoutMarker(a), withoutMarker(b)
+	return reflect.DeepEqual(a, b)
+}
+
+func withoutMarker(data map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if k != "encrypted" {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -8,13 +8,21 @@ import (
 
 	"github.com/linkflow-go/internal/credential/ports"
 	"github.com/linkflow-go/pkg/contracts/credential"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/rediskey"
 	"github.com/redis/go-redis/v9"
 )
 
+var (
+	ErrCredentialNotFound     = apperrors.New(apperrors.CategoryNotFound, "CREDENTIAL_NOT_FOUND", "credential not found")
+	ErrCredentialAccessDenied = apperrors.New(apperrors.CategoryPermission, "CREDENTIAL_ACCESS_DENIED", "access denied")
+)
+
 type CredentialService struct {
 	repo     ports.CredentialRepository
+	tx       ports.Transactor
 	vault    ports.Vault
 	eventBus events.EventBus
 	redis    *redis.Client
@@ -23,6 +31,7 @@ type CredentialService struct {
 
 func NewCredentialService(
 	repo ports.CredentialRepository,
+	tx ports.Transactor,
 	vault ports.Vault,
 	eventBus events.EventBus,
 	redis *redis.Client,
@@ -30,6 +39,7 @@ func NewCredentialService(
 ) *CredentialService {
 	return &CredentialService{
 		repo:     repo,
+		tx:       tx,
 		vault:    vault,
 		eventBus: eventBus,
 		redis:    redis,
@@ -56,20 +66,24 @@ func (s *CredentialService) CreateCredential(ctx context.Context, req CreateCred
 		return nil, fmt.Errorf("failed to encrypt credential: %w", err)
 	}
 
-	// Save to database
-	if err := s.repo.CreateCredential(ctx, cred); err != nil {
+	// Save to database with the created event
+	err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateCredential(ctx, cred); err != nil {
+			return err
+		}
+
+		event := events.NewEventBuilder("credential.created").
+			WithAggregateID(cred.ID).
+			WithUserID(req.UserID).
+			WithPayload("name", cred.Name).
+			WithPayload("type", cred.Type).
+			Build()
+		return s.eventBus.Publish(ctx, event)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to save credential: %w", err)
 	}
 
-	// Publish event
-	event := events.NewEventBuilder("credential.created").
-		WithAggregateID(cred.ID).
-		WithUserID(req.UserID).
-		WithPayload("name", cred.Name).
-		WithPayload("type", cred.Type).
-		Build()
-	s.eventBus.Publish(ctx, event)
-
 	s.logger.Info("Credential created", "id", cred.ID, "type", cred.Type)
 	return cred, nil
 }
@@ -78,12 +92,12 @@ func (s *CredentialService) CreateCredential(ctx context.Context, req CreateCred
 func (s *CredentialService) GetCredential(ctx context.Context, id, userID string) (*credential.Credential, error) {
 	cred, err := s.repo.GetCredential(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("credential not found: %w", err)
+		return nil, ErrCredentialNotFound.Wrap(err)
 	}
 
 	// Check ownership or sharing
 	if cred.UserID != userID && !cred.IsShared {
-		return nil, fmt.Errorf("access denied")
+		return nil, ErrCredentialAccessDenied
 	}
 
 	return cred, nil
@@ -144,17 +158,22 @@ func (s *CredentialService) UpdateCredential(ctx context.Context, id string, req
 	}
 	cred.UpdatedAt = time.Now()
 
-	if err := s.repo.UpdateCredential(ctx, cred); err != nil {
+	// Save with the updated event
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateCredential(ctx, cred); err != nil {
+			return err
+		}
+
+		event := events.NewEventBuilder("credential.updated").
+			WithAggregateID(cred.ID).
+			WithUserID(req.UserID).
+			Build()
+		return s.eventBus.Publish(ctx, event)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to update credential: %w", err)
 	}
 
-	// Publish event
-	event := events.NewEventBuilder("credential.updated").
-		WithAggregateID(cred.ID).
-		WithUserID(req.UserID).
-		Build()
-	s.eventBus.Publish(ctx, event)
-
 	s.logger.Info("Credential updated", "id", cred.ID)
 	return cred, nil
 }
@@ -170,19 +189,31 @@ func (s *CredentialService) DeleteCredential(ctx context.Context, id, userID str
 		return fmt.Errorf("access denied")
 	}
 
-	if err := s.repo.DeleteCredential(ctx, id); err != nil {
+	// Delete with the deleted event
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteCredential(ctx, id); err != nil {
+			return err
+		}
+
+		event := events.NewEventBuilder("credential.deleted").
+			WithAggregateID(id).
+			WithUserID(userID).
+			Build()
+		return s.eventBus.Publish(ctx, event)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete credential: %w", err)
 	}
 
-	// Clear from cache
-	s.redis.Del(ctx, fmt.Sprintf("credential:%s", id))
+	// Remove secrets kept outside of the credential row
+	if backend, ok := s.vault.(ports.SecretBackend); ok {
+		if err := backend.DeleteSecrets(ctx, cred); err != nil {
+			s.logger.Error("Failed to delete credential secrets", "id", id, "error", err)
+		}
+	}
 
-	// Publish event
-	event := events.NewEventBuilder("credential.deleted").
-		WithAggregateID(id).
-		WithUserID(userID).
-		Build()
-	s.eventBus.Publish(ctx, event)
+	// Clear from cache
+	s.redis.Del(ctx, rediskey.Tenant(ctx, "credential", id))
 
 	s.logger.Info("Credential deleted", "id", id)
 	return nil
@@ -260,12 +291,12 @@ func (s *CredentialService) CacheCredential(ctx context.Context, cred *credentia
 	if err != nil {
 		return err
 	}
-	return s.redis.Set(ctx, fmt.Sprintf("credential:%s", cred.ID), data, 15*time.Minute).Err()
+	return s.redis.Set(ctx, rediskey.Tenant(ctx, "credential", cred.ID), data, 15*time.Minute).Err()
 }
 
 // GetCachedCredential retrieves a credential from cache
 func (s *CredentialService) GetCachedCredential(ctx context.Context, id string) (*credential.Credential, error) {
-	data, err := s.redis.Get(ctx, fmt.Sprintf("credential:%s", id)).Result()
+	data, err := s.redis.Get(ctx, rediskey.Tenant(ctx, "credential", id)).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -12,4 +12,9 @@ type CredentialRepository interface {
 	UpdateCredential(ctx context.Context, cred *credential.Credential) error
 	ListCredentials(ctx context.Context, userID string) ([]*credential.Credential, error)
 	DeleteCredential(ctx context.Context, id string) error
+
+	// Secret backend migrations
+	CountCredentialsByBackend(ctx context.Context, backend string) (int64, error)
+	ListCredentialsByBackend(ctx context.Context, backend, afterID string, limit int) ([]*credential.Credential, error)
+	UpdateCredentialSecrets(ctx context.Context, creds []*credential.Credential) error
 }
@@ -0,0 +1,9 @@
+package ports
+
+import "context"
+
+// Transactor runs fn in a database transaction. Repository calls and events
+// published with the context passed to fn commit or roll back together.
+type Transactor interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -10,3 +10,13 @@ type Vault interface {
 	EncryptCredential(ctx context.Context, cred *credential.Credential) error
 	DecryptCredential(ctx context.Context, cred *credential.Credential) error
 }
+
+// SecretBackend is a Vault credentials can be migrated from or to
+type SecretBackend interface {
+	Vault
+	// Name is stored on each credential as its secret backend
+	Name() string
+	// DeleteSecrets removes the secrets a backend keeps outside of the
+	// credential row, a no-op for backends encrypting in place
+	DeleteSecrets(ctx context.Context, cred *credential.Credential) error
+}
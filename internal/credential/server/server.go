@@ -9,24 +9,37 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/linkflow-go/internal/credential/adapters/db/repository"
 	"github.com/linkflow-go/internal/credential/adapters/http/handlers"
+	credentialrpc "github.com/linkflow-go/internal/credential/adapters/rpc"
 	"github.com/linkflow-go/internal/credential/adapters/vault"
 	"github.com/linkflow-go/internal/credential/app/service"
 	"github.com/linkflow-go/internal/credential/ports"
+	"github.com/linkflow-go/pkg/apidoc"
 	"github.com/linkflow-go/pkg/config"
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/linkflow-go/pkg/middleware/correlation"
+	tenantmw "github.com/linkflow-go/pkg/middleware/tenant"
+	"github.com/linkflow-go/pkg/outbox"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/linkflow-go/pkg/rpc"
+	"github.com/linkflow-go/pkg/rpc/credentialv1"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
 	config     *config.Config
 	logger     logger.Logger
 	httpServer *http.Server
+	rpcServer  *grpc.Server
 	db         *database.DB
 	redis      *redis.Client
-	eventBus   events.EventBus
+	redisQuota *rediskey.Quota
+	eventBus   *outbox.Outbox
 	vault      ports.Vault
 }
 
@@ -50,16 +63,22 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	// Initialize event bus
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	// Bound the Redis commands and memory of each tenant
+	quota := cfg.Redis.TenantQuota
+	redisQuota := rediskey.NewQuota(redisClient, quota.OpsPerSecond, quota.MaxMemoryMB,
+		time.Duration(quota.UsageInterval)*time.Second, log)
+	redisClient.AddHook(redisQuota)
+
+	// Initialize event bus, events are published through the outbox so they
+	// commit with the state change that raised them
+	bus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
+	eventBus := outbox.New(db, bus, "credential-service", log)
 
-	// Initialize vault
-	// TODO: Add Vault configuration to config.Config
-	// Using a 32-byte key for AES-256
-	credVault, err := vault.NewVaultManager("temporary-32-byte-encryption-key", log)
+	// Initialize secret backends, new secrets go to the configured one
+	credVault, err := vault.NewRouter(cfg.Credential, log)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize vault: %w", err)
 	}
@@ -68,13 +87,27 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	credentialRepo := repository.NewCredentialRepository(db)
 
 	// Initialize service
-	credentialService := service.NewCredentialService(credentialRepo, credVault, eventBus, redisClient, log)
+	credentialService := service.NewCredentialService(credentialRepo, db, credVault, eventBus, redisClient, log)
 
 	// Initialize handlers
 	credentialHandlers := handlers.NewCredentialHandlers(credentialService, log)
 
+	// Readiness reports the state of each dependency
+	checker := health.NewChecker("credential-service")
+	checker.Critical("database", health.Database(db))
+	checker.Critical("redis", health.Redis(redisClient))
+	checker.Optional("event_bus", health.EventBus(eventBus))
+	if kv, ok := credVault.Primary().(*vault.KVStore); ok {
+		checker.Critical("secret_backend", health.HTTP(nil, kv.HealthURL()))
+	}
+
 	// Setup HTTP server
-	router := setupRouter(credentialHandlers, log)
+	router := setupRouter(credentialHandlers, checker, log)
+
+	// Serve the OpenAPI document of the routes registered above
+	if err := apidoc.Serve(router, "credential", cfg.Server.StrictAPISpec, log); err != nil {
+		return nil, err
+	}
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -88,32 +121,44 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		return nil, fmt.Errorf("failed to subscribe to events: %w", err)
 	}
 
+	// Credentials resolved for the other services over gRPC
+	var rpcServer *grpc.Server
+	if cfg.RPC.Port != 0 {
+		rpcServer = rpc.NewServer(cfg.RPC.Token, log)
+		credentialv1.RegisterCredentialServiceServer(rpcServer, credentialrpc.NewServer(credentialService))
+	}
+
 	return &Server{
 		config:     cfg,
 		logger:     log,
 		httpServer: httpServer,
+		rpcServer:  rpcServer,
 		db:         db,
 		redis:      redisClient,
+		redisQuota: redisQuota,
 		eventBus:   eventBus,
 		vault:      credVault,
 	}, nil
 }
 
-func setupRouter(h *handlers.CredentialHandlers, log logger.Logger) *gin.Engine {
+func setupRouter(h *handlers.CredentialHandlers, checker *health.Checker, log logger.Logger) *gin.Engine {
 	router := gin.New()
 
-	// Middleware
+	// Middleware, metrics first so recovered panics are counted as 500s
+	router.Use(metrics.HTTPMiddleware("credential-service"))
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
 	router.Use(corsMiddleware())
 	router.Use(loggingMiddleware(log))
 
 	// Health checks
 	router.GET("/health/live", h.Health)
-	router.GET("/health/ready", h.Ready)
+	router.GET("/health/ready", checker.ReadyHandler())
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// API routes
-	v1 := router.Group("/api/v1/credentials")
+	v1 := router.Group(apidoc.Prefix + "/credentials")
+	v1.Use(tenantmw.Middleware())
 	{
 		// Credential CRUD
 		v1.GET("", h.ListCredentials)
@@ -199,9 +244,21 @@ func subscribeToEvents(eventBus events.EventBus, service *service.CredentialServ
 }
 
 func (s *Server) Start() error {
+	// Relay events stored in the outbox
+	s.eventBus.Start()
+
+	// Measure the Redis memory of the tenants
+	s.redisQuota.Start()
+
 	// Start background tasks
 	go s.startBackgroundTasks()
 
+	if s.rpcServer != nil {
+		if err := rpc.Serve(s.rpcServer, s.config.RPC.Port, s.logger); err != nil {
+			return err
+		}
+	}
+
 	s.logger.Info("Starting HTTP server", "port", s.config.Server.Port)
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start HTTP server: %w", err)
@@ -231,9 +288,16 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("failed to shutdown HTTP server: %w", err)
 	}
 
+	// Stop gRPC API
+	if s.rpcServer != nil {
+		rpc.Stop(ctx, s.rpcServer)
+	}
+
+	s.redisQuota.Stop()
+
 	// VaultManager doesn't need explicit closing
 
-	// Close event bus
+	// Stop the outbox relay and close event bus
 	if err := s.eventBus.Close(); err != nil {
 		s.logger.Error("Failed to close event bus", "error", err)
 	}
@@ -256,7 +320,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -285,7 +349,7 @@ func loggingMiddleware(log logger.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		log.Info("HTTP Request",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP Request",
 			"method", method,
 			"path", path,
 			"status", statusCode,
@@ -12,8 +12,10 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/execution"
 	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/database"
+	"github.com/linkflow-go/pkg/events"
 	"gorm.io/gorm"
 )
 
@@ -170,8 +172,10 @@ type Archiver struct {
 	db            *database.DB
 	storage       Storage
 	compressor    Compressor
+	eventBus      events.EventBus
 	retentionDays int
 	batchSize     int
+	payloads      workflow.PayloadStore
 }
 
 // NewArchiver creates a new archiver
@@ -185,6 +189,20 @@ func NewArchiver(db *database.DB, storage Storage, compressor Compressor, retent
 	}
 }
 
+// ArchiverActor is recorded as the exporter of archive watermarks
+const ArchiverActor = "system:archiver"
+
+// SetEventBus enables audit events for archives written to storage
+func (a *Archiver) SetEventBus(eventBus events.EventBus) {
+	a.eventBus = eventBus
+}
+
+// SetPayloadStore deletes the fields spilled by the nodes of archived
+// executions with them, archives keep the references only
+func (a *Archiver) SetPayloadStore(store workflow.PayloadStore) {
+	a.payloads = store
+}
+
 // ArchiveExecutions archives old execution data
 func (a *Archiver) ArchiveExecutions(ctx context.Context, before time.Time) error {
 	// Process in batches to avoid memory issues
@@ -218,6 +236,11 @@ func (a *Archiver) ArchiveExecutions(ctx context.Context, before time.Time) erro
 		if err := a.deleteArchivedExecutions(ctx, executions); err != nil {
 			return fmt.Errorf("failed to delete archived executions: %w", err)
 		}
+		for _, exec := range executions {
+			if err := workflow.DeletePayloads(ctx, a.payloads, exec.ID); err != nil {
+				return fmt.Errorf("failed to delete payloads of execution %s: %w", exec.ID, err)
+			}
+		}
 
 		offset += len(executions)
 	}
@@ -237,10 +260,22 @@ func (a *Archiver) archiveBatch(ctx context.Context, executions []workflow.Workf
 
 	// Archive each date group
 	for date, execs := range byDate {
+		day, _ := time.Parse("2006-01-02", date)
+		nextDay := day.AddDate(0, 0, 1)
+		watermark := execution.NewWatermark(ArchiverActor, execution.ExportFormatJSON, execution.ExportDestinationArchive,
+			execution.ExportScope{From: &day, To: &nextDay})
+
+		records, err := json.Marshal(execs)
+		if err != nil {
+			return fmt.Errorf("failed to serialize executions: %w", err)
+		}
+		watermark.Seal(len(execs), records)
+
 		archive := &ExecutionArchive{
 			ID:         uuid.New().String(),
 			Date:       date,
 			Count:      len(execs),
+			Watermark:  watermark,
 			Executions: execs,
 			CreatedAt:  time.Now(),
 		}
@@ -282,11 +317,34 @@ func (a *Archiver) archiveBatch(ctx context.Context, executions []workflow.Workf
 		if err := a.db.WithContext(ctx).Create(metadata).Error; err != nil {
 			return fmt.Errorf("failed to save archive metadata: %w", err)
 		}
+
+		if err := a.publishExport(ctx, watermark, key); err != nil {
+			return fmt.Errorf("failed to audit archive: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// publishExport records the archive with the audit service. Executions are
+// only deleted after this succeeds.
+func (a *Archiver) publishExport(ctx context.Context, watermark *execution.Watermark, key string) error {
+	if a.eventBus == nil {
+		return nil
+	}
+
+	builder := events.NewEventBuilder(events.ExecutionDataExported).
+		WithAggregateID(watermark.ExportID).
+		WithAggregateType("execution_export").
+		WithUserID(watermark.ExportedBy).
+		WithPayload("storageKey", key)
+	for k, v := range watermark.EventPayload() {
+		builder.WithPayload(k, v)
+	}
+
+	return a.eventBus.Publish(ctx, builder.Build())
+}
+
 // deleteArchivedExecutions deletes executions that have been archived
 func (a *Archiver) deleteArchivedExecutions(ctx context.Context, executions []workflow.WorkflowExecution) error {
 	ids := make([]string, len(executions))
@@ -445,6 +503,7 @@ type ExecutionArchive struct {
 	ID         string                       `json:"id"`
 	Date       string                       `json:"date"`
 	Count      int                          `json:"count"`
+	Watermark  *execution.Watermark         `json:"watermark,omitempty"`
 	Executions []workflow.WorkflowExecution `json:"executions"`
 	CreatedAt  time.Time                    `json:"createdAt"`
 }
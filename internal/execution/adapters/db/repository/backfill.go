@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+)
+
+// GetTrigger returns a trigger of a workflow
+func (r *ExecutionRepository) GetTrigger(ctx context.Context, triggerID string) (*workflow.WorkflowTrigger, error) {
+	var trigger workflow.WorkflowTrigger
+	err := r.db.WithContext(ctx).Where("id = ?", triggerID).First(&trigger).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("trigger not found: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &trigger, nil
+}
+
+// CreateBackfill stores a backfill about to start
+func (r *ExecutionRepository) CreateBackfill(ctx context.Context, backfill *execution.Backfill) error {
+	return r.db.WithContext(ctx).Create(backfill).Error
+}
+
+// UpdateBackfill stores the progress of a backfill
+func (r *ExecutionRepository) UpdateBackfill(ctx context.Context, backfill *execution.Backfill) error {
+	return r.db.WithContext(ctx).Save(backfill).Error
+}
+
+// GetBackfill returns a backfill
+func (r *ExecutionRepository) GetBackfill(ctx context.Context, id string) (*execution.Backfill, error) {
+	var backfill execution.Backfill
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&backfill).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("backfill not found: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &backfill, nil
+}
+
+// ListBackfills returns the backfills of a workflow, all workflows when
+// workflowID is empty, most recent first
+func (r *ExecutionRepository) ListBackfills(ctx context.Context, workflowID string) ([]*execution.Backfill, error) {
+	query := r.db.WithContext(ctx).Model(&execution.Backfill{})
+	if workflowID != "" {
+		query = query.Where("workflow_id = ?", workflowID)
+	}
+
+	var backfills []*execution.Backfill
+	err := query.Order("created_at DESC").Limit(100).Find(&backfills).Error
+	return backfills, err
+}
+
+// ListRunningBackfills returns the backfills still starting executions, of
+// the tenant of ctx or of every tenant when ctx has none
+func (r *ExecutionRepository) ListRunningBackfills(ctx context.Context) ([]*execution.Backfill, error) {
+	var backfills []*execution.Backfill
+	err := r.db.WithContext(ctx).
+		Where("status = ?", execution.BackfillRunning).
+		Order("created_at ASC").
+		Find(&backfills).Error
+	return backfills, err
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveExecutionCost stores the cost of an execution with its node type
+// shares once, recalculations of a redelivered execution are ignored
+func (r *ExecutionRepository) SaveExecutionCost(ctx context.Context, cost *execution.ExecutionCost, nodes []*execution.NodeTypeCost) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(cost)
+		if result.Error != nil || result.RowsAffected == 0 || len(nodes) == 0 {
+			return result.Error
+		}
+		return tx.Create(&nodes).Error
+	})
+}
+
+// RollupCosts sums again the costs calculated since the start of the day of
+// since into the rollups of those days, for every tenant
+func (r *ExecutionRepository) RollupCosts(ctx context.Context, since time.Time) error {
+	day := execution.CostDay(since)
+	start, err := time.Parse(execution.KPIDayFormat, day)
+	if err != nil {
+		return err
+	}
+	costDay := r.db.Day("calculated_at")
+	now := time.Now().UTC()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rollups []*execution.CostRollup
+		err := tx.Model(&execution.ExecutionCost{}).
+			Select("tenant_id, "+costDay+" AS day, workflow_id, user_id, team_id, "+
+				"COUNT(*) AS executions, SUM(compute_seconds) AS compute_seconds, SUM(total) AS total").
+			Where("calculated_at >= ?", start).
+			Group("tenant_id, " + costDay + ", workflow_id, user_id, team_id").
+			Scan(&rollups).Error
+		if err != nil {
+			return err
+		}
+
+		var nodeRollups []*execution.NodeTypeCostRollup
+		err = tx.Model(&execution.NodeTypeCost{}).
+			Select("tenant_id, "+costDay+" AS day, workflow_id, team_id, node_type, "+
+				"COUNT(*) AS executions, SUM(nodes) AS nodes, SUM(cost) AS cost").
+			Where("calculated_at >= ?", start).
+			Group("tenant_id, " + costDay + ", workflow_id, team_id, node_type").
+			Scan(&nodeRollups).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("day >= ?", day).Delete(&execution.CostRollup{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("day >= ?", day).Delete(&execution.NodeTypeCostRollup{}).Error; err != nil {
+			return err
+		}
+
+		for _, rollup := range rollups {
+			rollup.UpdatedAt = now
+		}
+		for _, rollup := range nodeRollups {
+			rollup.UpdatedAt = now
+		}
+		if len(rollups) > 0 {
+			if err := tx.CreateInBatches(rollups, 500).Error; err != nil {
+				return err
+			}
+		}
+		if len(nodeRollups) > 0 {
+			return tx.CreateInBatches(nodeRollups, 500).Error
+		}
+		return nil
+	})
+}
+
+// GetCostReport sums the rolled up costs of the tenant of ctx per workflow,
+// day, node type or team
+func (r *ExecutionRepository) GetCostReport(ctx context.Context, filter execution.CostReportFilter) (*execution.CostReport, error) {
+	report := &execution.CostReport{
+		GroupBy:    filter.GroupBy,
+		From:       execution.CostDay(filter.From),
+		To:         execution.CostDay(filter.To),
+		WorkflowID: filter.WorkflowID,
+		TeamID:     filter.TeamID,
+		Rows:       []*execution.CostReportRow{},
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("day >= ? AND day <= ?", report.From, report.To)
+		if filter.WorkflowID != "" {
+			db = db.Where("workflow_id = ?", filter.WorkflowID)
+		}
+		if filter.TeamID != "" {
+			db = db.Where("team_id = ?", filter.TeamID)
+		}
+		return db
+	}
+
+	var query *gorm.DB
+	switch filter.GroupBy {
+	case execution.CostByNodeType:
+		query = r.db.WithContext(ctx).
+			Model(&execution.NodeTypeCostRollup{}).
+			Select("node_type AS key, SUM(executions) AS executions, SUM(nodes) AS nodes, SUM(cost) AS total").
+			Group("node_type")
+	default:
+		column := map[string]string{
+			execution.CostByWorkflow: "workflow_id",
+			execution.CostByDay:      "day",
+			execution.CostByTeam:     "team_id",
+		}[filter.GroupBy]
+		query = r.db.WithContext(ctx).
+			Model(&execution.CostRollup{}).
+			Select(column + " AS key, SUM(executions) AS executions, " +
+				"SUM(compute_seconds) AS compute_seconds, SUM(total) AS total").
+			Group(column)
+	}
+
+	query = query.Scopes(scope)
+	if filter.GroupBy == execution.CostByDay {
+		query = query.Order("key ASC")
+	} else {
+		query = query.Order("total DESC")
+	}
+
+	if err := query.Scan(&report.Rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range report.Rows {
+		report.Total += row.Total
+		if filter.GroupBy != execution.CostByNodeType {
+			report.Executions += row.Executions
+		}
+	}
+	if filter.GroupBy == execution.CostByNodeType {
+		// Executions running nodes of several types are counted once
+		var executions int64
+		err := r.db.WithContext(ctx).
+			Model(&execution.CostRollup{}).
+			Select("COALESCE(SUM(executions), 0)").
+			Scopes(scope).
+			Scan(&executions).Error
+		if err != nil {
+			return nil, err
+		}
+		report.Executions = executions
+	}
+	return report, nil
+}
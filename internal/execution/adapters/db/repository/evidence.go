@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"gorm.io/gorm"
+)
+
+// CreateEvidenceBundle stores a bundle about to be generated
+func (r *ExecutionRepository) CreateEvidenceBundle(ctx context.Context, bundle *execution.EvidenceBundle) error {
+	return r.db.WithContext(ctx).Create(bundle).Error
+}
+
+// UpdateEvidenceBundle stores the outcome of generating a bundle
+func (r *ExecutionRepository) UpdateEvidenceBundle(ctx context.Context, bundle *execution.EvidenceBundle) error {
+	return r.db.WithContext(ctx).Save(bundle).Error
+}
+
+// GetEvidenceBundle returns a bundle with its archive
+func (r *ExecutionRepository) GetEvidenceBundle(ctx context.Context, id string) (*execution.EvidenceBundle, error) {
+	var bundle execution.EvidenceBundle
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&bundle).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("evidence bundle not found: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &bundle, nil
+}
+
+// GetCredentialUses lists the credentials accessed by an execution, oldest
+// first, with the version each credential is at
+func (r *ExecutionRepository) GetCredentialUses(ctx context.Context, executionID string) ([]execution.CredentialUse, error) {
+	var uses []execution.CredentialUse
+	err := r.db.WithContext(ctx).
+		Table("credential.credential_usage_log AS l").
+		Select("l.credential_id, c.name, c.type, c.updated_at AS version, l.action, l.created_at AS used_at").
+		Joins("JOIN credential.credentials c ON c.id = l.credential_id").
+		Where("l.execution_id = ?", executionID).
+		Order("l.created_at ASC").
+		Scan(&uses).Error
+
+	return uses, err
+}
+
+// GetApprovals lists the resumptions of an execution from the paused state,
+// the user resuming it is recorded with the state transition
+func (r *ExecutionRepository) GetApprovals(ctx context.Context, executionID string) ([]execution.Approval, error) {
+	transitions, err := r.GetStateTransitions(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var approvals []execution.Approval
+	for _, t := range transitions {
+		if t.FromState != string(execution.StatusPaused) {
+			continue
+		}
+		approval := execution.Approval{
+			FromState: t.FromState,
+			ToState:   t.ToState,
+			At:        t.Timestamp,
+		}
+		approval.ApprovedBy, _ = t.Metadata["userId"].(string)
+		approval.Comment, _ = t.Metadata["comment"].(string)
+		approvals = append(approvals, approval)
+	}
+
+	return approvals, nil
+}
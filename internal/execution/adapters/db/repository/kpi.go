@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordKPI adds a value emitted by a metric node to the KPI of its
+// workflow for the day
+func (r *ExecutionRepository) RecordKPI(ctx context.Context, workflowID, day, name string, value float64) error {
+	db := r.db.WithContext(ctx)
+
+	// The first value of the day creates the row, unless another execution
+	// created it meanwhile, then it is added like the others
+	for attempt := 0; attempt < 2; attempt++ {
+		result := db.Model(&execution.KPI{}).
+			Where("workflow_id = ? AND day = ? AND name = ?", workflowID, day, name).
+			Updates(map[string]interface{}{
+				"value":      gorm.Expr("value + ?", value),
+				"samples":    gorm.Expr("samples + 1"),
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+
+		result = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&execution.KPI{
+			WorkflowID: workflowID,
+			Day:        day,
+			Name:       name,
+			Value:      value,
+			Samples:    1,
+			UpdatedAt:  time.Now(),
+		})
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+	}
+	return nil
+}
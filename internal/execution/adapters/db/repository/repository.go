@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/linkflow-go/internal/execution/ports"
+	"github.com/linkflow-go/pkg/contracts/execution"
 	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/database"
 	"gorm.io/gorm"
@@ -113,7 +116,7 @@ func (r *ExecutionRepository) GetByID(ctx context.Context, id string) (*workflow
 		First(&execution).Error
 
 	if err == gorm.ErrRecordNotFound {
-		return nil, fmt.Errorf("execution not found")
+		return nil, fmt.Errorf("execution not found: %w", err)
 	}
 
 	return &execution, err
@@ -132,6 +135,221 @@ func (r *ExecutionRepository) GetWorkflow(ctx context.Context, workflowID string
 	return &wf, err
 }
 
+// LiveWorkflows returns the IDs among ids of the workflows not deleted
+func (r *ExecutionRepository) LiveWorkflows(ctx context.Context, ids []string) ([]string, error) {
+	var live []string
+	err := r.db.WithContext(ctx).
+		Model(&workflow.Workflow{}).
+		Where("id IN ? AND deleted_at IS NULL", ids).
+		Pluck("id", &live).Error
+	return live, err
+}
+
+// ListBudgets returns the budgets bounding the executions of a workflow:
+// its own and the one of its workspace
+func (r *ExecutionRepository) ListBudgets(ctx context.Context, workflowID, workspaceID string) ([]*workflow.Budget, error) {
+	var budgets []*workflow.Budget
+	err := r.db.WithContext(ctx).
+		Where("(scope = ? AND scope_id = ?) OR (scope = ? AND scope_id = ?)",
+			workflow.BudgetScopeWorkflow, workflowID, workflow.BudgetScopeWorkspace, workspaceID).
+		Find(&budgets).Error
+	return budgets, err
+}
+
+// ListSLAs returns the SLAs of the active workflows, of every tenant unless
+// ctx names one
+func (r *ExecutionRepository) ListSLAs(ctx context.Context) ([]*workflow.SLA, error) {
+	var slas []*workflow.SLA
+	err := r.db.WithContext(ctx).
+		Where("workflow_id IN (?)", r.db.Model(&workflow.Workflow{}).
+			Select("id").
+			Where("is_active = ? AND deleted_at IS NULL", true)).
+		Find(&slas).Error
+	return slas, err
+}
+
+// OldestRunning returns when the longest running execution of a workflow
+// started, nil when none is running
+func (r *ExecutionRepository) OldestRunning(ctx context.Context, workflowID string) (*time.Time, error) {
+	var execution workflow.WorkflowExecution
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ? AND status = ?", workflowID, workflow.ExecutionRunning).
+		Order("started_at").
+		First(&execution).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &execution.StartedAt, nil
+}
+
+// GetWorkspacePolicy returns the policy of a workspace, nil when it has none
+func (r *ExecutionRepository) GetWorkspacePolicy(ctx context.Context, workspaceID string) (*workflow.WorkspacePolicy, error) {
+	var policy workflow.WorkspacePolicy
+	err := r.db.WithContext(ctx).
+		Where("workspace_id = ?", workspaceID).
+		First(&policy).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetWorkflowVersion loads the definition stored for a version of a workflow
+func (r *ExecutionRepository) GetWorkflowVersion(ctx context.Context, workflowID string, version int) (*workflow.Workflow, error) {
+	var wv workflow.WorkflowVersion
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ? AND version = ?", workflowID, version).
+		First(&wv).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("workflow version not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var wf workflow.Workflow
+	if err := json.Unmarshal([]byte(wv.Data), &wf); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow version: %w", err)
+	}
+	wf.Version = wv.Version
+	wf.Checksum = wv.Checksum
+
+	return &wf, nil
+}
+
+// GetEnvironment returns an environment of a workflow by ID or by name, its
+// default environment when environment is empty, or nil when it has none
+func (r *ExecutionRepository) GetEnvironment(ctx context.Context, workflowID, environment string) (*workflow.Environment, error) {
+	query := r.db.WithContext(ctx).Where("workflow_id = ?", workflowID)
+	switch _, err := uuid.Parse(environment); {
+	case environment == "":
+		query = query.Where("is_default = ?", true)
+	case err == nil:
+		query = query.Where("id = ?", environment)
+	default:
+		query = query.Where("name = ?", environment)
+	}
+
+	var env workflow.Environment
+	err := query.First(&env).Error
+	if err == gorm.ErrRecordNotFound {
+		if environment == "" {
+			return nil, nil
+		}
+		return nil, workflow.ErrEnvironmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &env, nil
+}
+
+// ListWorkflowVariables lists the variables of a workflow
+func (r *ExecutionRepository) ListWorkflowVariables(ctx context.Context, workflowID string) ([]*workflow.WorkflowVariable, error) {
+	var vars []*workflow.WorkflowVariable
+	err := r.db.WithContext(ctx).Where("workflow_id = ?", workflowID).Find(&vars).Error
+	if err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// ListSharedVariables lists the global variables of the variable service
+// and those of a team
+func (r *ExecutionRepository) ListSharedVariables(ctx context.Context, teamID string) ([]workflow.SharedVariable, error) {
+	query := r.db.WithContext(ctx).Where("team_id IS NULL")
+	if _, err := uuid.Parse(teamID); err == nil {
+		query = r.db.WithContext(ctx).Where("team_id IS NULL OR team_id = ?", teamID)
+	}
+
+	var vars []workflow.SharedVariable
+	if err := query.Find(&vars).Error; err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// GetRunningCanary returns the canary routing executions of a workflow, or
+// nil when there is none
+func (r *ExecutionRepository) GetRunningCanary(ctx context.Context, workflowID string) (*workflow.Canary, error) {
+	var canary workflow.Canary
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ? AND status = ?", workflowID, workflow.CanaryRunning).
+		First(&canary).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &canary, nil
+}
+
+// GetRunningShadow returns the shadow run of a workflow, or nil when there
+// is none
+func (r *ExecutionRepository) GetRunningShadow(ctx context.Context, workflowID string) (*workflow.Shadow, error) {
+	var shadow workflow.Shadow
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ? AND status = ?", workflowID, workflow.ShadowRunning).
+		First(&shadow).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &shadow, nil
+}
+
+// CreateShadowComparison stores the diff of a live execution and its shadow
+func (r *ExecutionRepository) CreateShadowComparison(ctx context.Context, comparison *workflow.ShadowComparison) error {
+	if comparison.ID == "" {
+		comparison.ID = uuid.New().String()
+	}
+	return r.db.WithContext(ctx).Create(comparison).Error
+}
+
+// GetTestRun returns a test run of a workflow
+func (r *ExecutionRepository) GetTestRun(ctx context.Context, runID string) (*workflow.WorkflowTestRun, error) {
+	var run workflow.WorkflowTestRun
+	err := r.db.WithContext(ctx).Where("id = ?", runID).First(&run).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, workflow.ErrTestRunNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// UpdateTestRun stores the results of a test run
+func (r *ExecutionRepository) UpdateTestRun(ctx context.Context, run *workflow.WorkflowTestRun) error {
+	return r.db.WithContext(ctx).Save(run).Error
+}
+
+// ListTestCases returns the test cases of a workflow by name
+func (r *ExecutionRepository) ListTestCases(ctx context.Context, workflowID string) ([]*workflow.WorkflowTestCase, error) {
+	var testCases []*workflow.WorkflowTestCase
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ?", workflowID).
+		Order("name ASC").
+		Find(&testCases).Error
+	return testCases, err
+}
+
 func (r *ExecutionRepository) CreateNodeExecution(ctx context.Context, nodeExec *workflow.NodeExecution) error {
 	return r.db.WithContext(ctx).Create(nodeExec).Error
 }
@@ -150,7 +368,9 @@ func (r *ExecutionRepository) GetNodeExecutions(ctx context.Context, executionID
 	return nodeExecutions, err
 }
 
-func (r *ExecutionRepository) ListExecutions(ctx context.Context, filter ExecutionFilter, pagination *database.Pagination) ([]*workflow.WorkflowExecution, error) {
+// ListExecutions returns the page of executions matching filter, newest
+// first unless the page sorts otherwise
+func (r *ExecutionRepository) ListExecutions(ctx context.Context, filter ExecutionFilter, page *database.CursorPage) ([]*workflow.WorkflowExecution, int64, error) {
 	query := r.db.WithContext(ctx).Model(&workflow.WorkflowExecution{})
 
 	// Apply filters
@@ -170,8 +390,36 @@ func (r *ExecutionRepository) ListExecutions(ctx context.Context, filter Executi
 		query = query.Where("started_at <= ?", filter.StartedBefore)
 	}
 
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	var executions []*workflow.WorkflowExecution
-	err := r.db.Paginate(ctx, &executions, pagination, query)
+	err := r.db.PaginateCursor(ctx, &executions, page, query)
+
+	return executions, total, err
+}
+
+// ListForExport returns executions in scope, oldest first, capped at limit
+func (r *ExecutionRepository) ListForExport(ctx context.Context, scope execution.ExportScope, limit int) ([]*workflow.WorkflowExecution, error) {
+	query := r.db.WithContext(ctx).Model(&workflow.WorkflowExecution{})
+
+	if scope.WorkflowID != "" {
+		query = query.Where("workflow_id = ?", scope.WorkflowID)
+	}
+	if scope.Status != "" {
+		query = query.Where("status = ?", scope.Status)
+	}
+	if scope.From != nil {
+		query = query.Where("created_at >= ?", *scope.From)
+	}
+	if scope.To != nil {
+		query = query.Where("created_at < ?", *scope.To)
+	}
+
+	var executions []*workflow.WorkflowExecution
+	err := query.Order("created_at ASC").Limit(limit).Find(&executions).Error
 
 	return executions, err
 }
@@ -185,8 +433,8 @@ func (r *ExecutionRepository) GetRunningExecutions(ctx context.Context) ([]*work
 	return executions, err
 }
 
-func (r *ExecutionRepository) GetExecutionStats(ctx context.Context, workflowID string) (*ExecutionStats, error) {
-	var stats ExecutionStats
+func (r *ExecutionRepository) GetExecutionStats(ctx context.Context, workflowID string) (*execution.Stats, error) {
+	stats := execution.Stats{WorkflowID: workflowID}
 
 	// Total executions
 	r.db.WithContext(ctx).
@@ -317,24 +565,33 @@ func (r *ExecutionRepository) CleanupOldExecutions(ctx context.Context, retentio
 	return nil
 }
 
-// Filter and stats types
-type ExecutionFilter struct {
-	WorkflowID    string
-	Status        string
-	UserID        string
-	StartedAfter  time.Time
-	StartedBefore time.Time
-}
+// StorageByTenant returns the bytes of execution data stored by each
+// tenant, across every tenant whatever the tenant of ctx
+func (r *ExecutionRepository) StorageByTenant(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		TenantID string
+		Bytes    int64
+	}
+	err := r.db.WithContext(ctx).
+		Raw("SELECT tenant_id, COALESCE(SUM(LENGTH(CAST(data AS TEXT))), 0) AS bytes FROM workflow_executions GROUP BY tenant_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
 
-type ExecutionStats struct {
-	Total                int64
-	Successful           int64
-	Failed               int64
-	Running              int64
-	AverageExecutionTime float64
-	LastExecutionAt      *time.Time
+	storage := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		storage[row.TenantID] = row.Bytes
+	}
+	return storage, nil
 }
 
+// ExecutionFilter is kept in this package for backward compatibility.
+// The canonical definition lives in internal/execution/ports.
+type ExecutionFilter = ports.ExecutionFilter
+
+// Stats types
+
 type GlobalExecutionStats struct {
 	ExecutionsToday     int64
 	ExecutionsThisWeek  int64
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordSampledOut counts a successful execution of a workflow dropped by
+// its sampling policy in the day
+func (r *ExecutionRepository) RecordSampledOut(ctx context.Context, workflowID, day string) error {
+	db := r.db.WithContext(ctx)
+
+	// The first drop of the day creates the row, unless another execution
+	// created it meanwhile, then it is counted like the others
+	for attempt := 0; attempt < 2; attempt++ {
+		result := db.Model(&execution.SampledOut{}).
+			Where("workflow_id = ? AND day = ?", workflowID, day).
+			Updates(map[string]interface{}{
+				"executions": gorm.Expr("executions + 1"),
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+
+		result = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&execution.SampledOut{
+			WorkflowID: workflowID,
+			Day:        day,
+			Executions: 1,
+			UpdatedAt:  time.Now(),
+		})
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// CountSampledOut returns how many successful executions of a workflow its
+// sampling policy dropped
+func (r *ExecutionRepository) CountSampledOut(ctx context.Context, workflowID string) (int64, error) {
+	var dropped int64
+	err := r.db.WithContext(ctx).
+		Model(&execution.SampledOut{}).
+		Where("workflow_id = ?", workflowID).
+		Select("COALESCE(SUM(executions), 0)").
+		Scan(&dropped).Error
+	return dropped, err
+}
+
+// DeleteExecution deletes an execution with its node executions
+func (r *ExecutionRepository) DeleteExecution(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("execution_id = ?", id).Delete(&workflow.NodeExecution{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&workflow.WorkflowExecution{}).Error
+	})
+}
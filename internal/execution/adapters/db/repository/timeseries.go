@@ -20,6 +20,11 @@ type StateTransition struct {
 	Metadata    map[string]interface{} `json:"metadata" gorm:"serializer:json"`
 }
 
+// TableName specifies the table name for GORM
+func (StateTransition) TableName() string {
+	return "execution.state_transitions"
+}
+
 // ExecutionMetric represents performance metrics for an execution
 type ExecutionMetric struct {
 	ID          string    `json:"id" gorm:"primaryKey"`
@@ -113,11 +118,11 @@ func (r *ExecutionRepository) GetAggregatedMetrics(ctx context.Context, workflow
 	var timeBucket string
 	switch {
 	case interval < time.Hour:
-		timeBucket = "date_trunc('minute', em.timestamp)"
+		timeBucket = r.db.DateTrunc("minute", "em.timestamp")
 	case interval < 24*time.Hour:
-		timeBucket = "date_trunc('hour', em.timestamp)"
+		timeBucket = r.db.DateTrunc("hour", "em.timestamp")
 	default:
-		timeBucket = "date_trunc('day', em.timestamp)"
+		timeBucket = r.db.DateTrunc("day", "em.timestamp")
 	}
 
 	query := fmt.Sprintf(`
@@ -212,23 +217,25 @@ func (r *ExecutionRepository) GetPerformanceHistory(ctx context.Context, workflo
 	}
 
 	// Get daily execution counts
-	query := `
+	query := fmt.Sprintf(`
 		SELECT 
-			date_trunc('day', started_at) as date,
+			%s as date,
 			COUNT(*) as total,
 			COUNT(CASE WHEN status = ? THEN 1 END) as successful,
 			COUNT(CASE WHEN status = ? THEN 1 END) as failed,
 			AVG(execution_time) as avg_time,
 			MIN(execution_time) as min_time,
 			MAX(execution_time) as max_time,
-			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY execution_time) as median_time,
-			PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY execution_time) as p95_time
+			%s as median_time,
+			%s as p95_time
 		FROM workflow_executions
 		WHERE workflow_id = ?
 			AND started_at >= ?
 		GROUP BY date
 		ORDER BY date ASC
-	`
+	`, r.db.DateTrunc("day", "started_at"),
+		r.db.Percentile(0.5, "execution_time"),
+		r.db.Percentile(0.95, "execution_time"))
 
 	type DailyStats struct {
 		Date       time.Time
@@ -288,7 +295,7 @@ func (r *ExecutionRepository) ArchiveOldMetrics(ctx context.Context, retentionDa
 
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Create archive entries
-		archiveQuery := `
+		archiveQuery := fmt.Sprintf(`
 			INSERT INTO execution_metrics_archive (
 				execution_id, node_id, metric_type, 
 				avg_value, min_value, max_value, count,
@@ -302,12 +309,12 @@ func (r *ExecutionRepository) ArchiveOldMetrics(ctx context.Context, retentionDa
 				MIN(value) as min_value,
 				MAX(value) as max_value,
 				COUNT(*) as count,
-				date_trunc('day', timestamp) as date,
+				%s as date,
 				NOW() as created_at
 			FROM execution_metrics
 			WHERE timestamp < ?
 			GROUP BY execution_id, node_id, metric_type, date
-		`
+		`, r.db.DateTrunc("day", "timestamp"))
 
 		if err := tx.Exec(archiveQuery, cutoffDate).Error; err != nil {
 			return fmt.Errorf("failed to archive metrics: %w", err)
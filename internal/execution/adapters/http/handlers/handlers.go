@@ -1,15 +1,33 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/internal/execution/app/cost"
+	"github.com/linkflow-go/internal/execution/app/export"
+	"github.com/linkflow-go/internal/execution/app/logging"
+	"github.com/linkflow-go/internal/execution/app/orchestrator"
 	"github.com/linkflow-go/internal/execution/app/service"
+	"github.com/linkflow-go/internal/execution/ports"
+	"github.com/linkflow-go/pkg/auth/signedurl"
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/database"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/quota"
 )
 
 type ExecutionHandlers struct {
 	service *service.ExecutionService
+	signer  *signedurl.Signer
 	logger  logger.Logger
 }
 
@@ -20,29 +38,161 @@ func NewExecutionHandlers(service *service.ExecutionService, logger logger.Logge
 	}
 }
 
+// SetURLSigner enables signed download links for evidence bundles
+func (h *ExecutionHandlers) SetURLSigner(signer *signedurl.Signer) {
+	h.signer = signer
+}
+
 func (h *ExecutionHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
-func (h *ExecutionHandlers) Ready(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+// IdempotencyKeyHeader lets clients safely retry execution requests
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type startExecutionRequest struct {
+	WorkflowID string                 `json:"workflowId" binding:"required"`
+	Data       map[string]interface{} `json:"data"`
+	// Debug logs the run verbosely, see logger.WithDebug
+	Debug bool `json:"debug"`
+	// Environment, by ID or name, runs the version deployed to it with its
+	// variables and credentials, the default environment when empty
+	Environment string `json:"environment"`
 }
 
 func (h *ExecutionHandlers) StartExecution(c *gin.Context) {
-	c.JSON(http.StatusAccepted, gin.H{"execution_id": "exec_123", "status": "started"})
+	var req startExecutionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.startExecution(c, req.WorkflowID, req.Environment, req.Data, req.Debug, "started")
+}
+
+// SimulateExecution runs a workflow with nodes replaced by mocks and
+// returns the outcome of each node. The run is not stored.
+func (h *ExecutionHandlers) SimulateExecution(c *gin.Context) {
+	var req workflow.SimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.SimulateWorkflow(c.Request.Context(), &req)
+	if err != nil {
+		if !apperrors.HasCategory(err, apperrors.CategoryValidation) &&
+			!apperrors.HasCategory(err, apperrors.CategoryNotFound) &&
+			!apperrors.HasCategory(err, apperrors.CategoryPermission) {
+			h.logger.Error("Failed to simulate workflow", "workflowId", req.WorkflowID, "error", err)
+		}
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// startExecution runs the workflow detached from the request context so the
+// execution outlives the HTTP call, deduplicating on the Idempotency-Key header.
+// A debug run logs verbosely until it ends.
+func (h *ExecutionHandlers) startExecution(c *gin.Context, workflowID, environment string, data map[string]interface{}, debug bool, status string) {
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+	ctx := context.WithoutCancel(c.Request.Context())
+	if debug {
+		ctx = logger.WithDebug(ctx)
+	}
+
+	executionID, duplicate, err := h.service.StartExecutionIdempotent(ctx, workflowID, environment, idempotencyKey, data)
+	if err != nil {
+		if errors.Is(err, orchestrator.ErrExecutionInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, workflow.ErrNodeTypeBlocked) || errors.Is(err, quota.ErrQuotaExceeded) ||
+			errors.Is(err, workflow.ErrEnvironmentNotFound) || errors.Is(err, workflow.ErrEnvironmentNotDeployed) {
+			c.JSON(apperrors.ToHTTP(err))
+			return
+		}
+		h.logger.Error("Failed to start execution", "workflowId", workflowID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start execution"})
+		return
+	}
+
+	if duplicate {
+		c.Header("Idempotent-Replayed", "true")
+		c.JSON(http.StatusOK, gin.H{"execution_id": executionID, "status": status, "duplicate": true})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"execution_id": executionID, "status": status})
 }
 
 func (h *ExecutionHandlers) GetExecution(c *gin.Context) {
 	id := c.Param("id")
-	c.JSON(http.StatusOK, gin.H{"id": id, "status": "running"})
+
+	execution, err := h.service.GetExecution(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrExecutionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to get execution", "executionId", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get execution"})
+		return
+	}
+
+	c.JSON(http.StatusOK, execution)
 }
 
+// ListExecutions pages through executions newest first, filtered by
+// workflowId and status. Pass nextCursor of a page as cursor to get the
+// following one.
 func (h *ExecutionHandlers) ListExecutions(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"executions": []interface{}{}})
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page := database.NewCursorPage(limit, c.Query("cursor"))
+	filter := ports.ExecutionFilter{
+		WorkflowID: c.Query("workflowId"),
+		Status:     c.Query("status"),
+	}
+
+	executions, total, err := h.service.ListExecutions(c.Request.Context(), filter, page)
+	if err != nil {
+		if apperrors.HasCategory(err, apperrors.CategoryValidation) {
+			c.JSON(apperrors.ToHTTP(err))
+			return
+		}
+		h.logger.Error("Failed to list executions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list executions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"executions": executions,
+		"total":      total,
+		"limit":      page.Limit,
+		"nextCursor": page.Next,
+	})
 }
 
 func (h *ExecutionHandlers) StopExecution(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Execution stopped"})
+	id := c.Param("id")
+
+	if err := h.service.StopExecution(c.Request.Context(), id); err != nil {
+		if errors.Is(err, orchestrator.ErrExecutionNotRunning) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to stop execution", "executionId", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop execution"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Execution stopped", "id": id})
 }
 
 func (h *ExecutionHandlers) PauseExecution(c *gin.Context) {
@@ -70,14 +220,51 @@ func (h *ExecutionHandlers) StreamExecutionEvents(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Streaming events"})
 }
 
+// DeleteExecution deletes a finished execution
 func (h *ExecutionHandlers) DeleteExecution(c *gin.Context) {
 	id := c.Param("id")
+	if err := h.service.DeleteExecution(c.Request.Context(), id); err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Execution deleted", "id": id})
 }
 
+// GetExecutionLog returns the log lines of an execution oldest first,
+// filtered by node, node execution, level and time. limit keeps the latest
+// lines.
 func (h *ExecutionHandlers) GetExecutionLog(c *gin.Context) {
-	id := c.Param("id")
-	c.JSON(http.StatusOK, gin.H{"id": id, "logs": []interface{}{}})
+	since, err := timeQuery(c, "since")
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(errors.New("limit must be a positive number"))))
+		return
+	}
+
+	filter := logging.LogFilter{
+		Level:           logging.LogLevel(c.Query("level")),
+		NodeID:          c.Query("nodeId"),
+		NodeExecutionID: c.Query("nodeExecutionId"),
+		Limit:           limit,
+	}
+	if since != nil {
+		filter.StartTime = *since
+	}
+
+	logs, err := h.service.GetExecutionLogs(c.Request.Context(), c.Param("id"), filter)
+	if err != nil {
+		if !errors.Is(err, service.ErrExecutionNotFound) {
+			h.logger.Error("Failed to get execution logs", "executionId", c.Param("id"), "error", err)
+		}
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, logs)
 }
 
 func (h *ExecutionHandlers) GetNodeExecutions(c *gin.Context) {
@@ -85,8 +272,272 @@ func (h *ExecutionHandlers) GetNodeExecutions(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"execution_id": id, "nodes": []interface{}{}})
 }
 
+// ExportExecutions downloads a watermarked CSV or JSON export of the
+// executions matching the query. Every export is recorded by the audit service.
+func (h *ExecutionHandlers) ExportExecutions(c *gin.Context) {
+	from, err := timeQuery(c, "from")
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+	to, err := timeQuery(c, "to")
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	scope := execution.ExportScope{
+		WorkflowID: c.Query("workflowId"),
+		Status:     c.Query("status"),
+		From:       from,
+		To:         to,
+	}
+
+	result, err := h.service.ExportExecutions(c.Request.Context(), export.Request{
+		ExportedBy:  c.GetHeader("X-User-ID"),
+		Format:      c.DefaultQuery("format", execution.ExportFormatCSV),
+		Destination: execution.ExportDestinationDownload,
+		Scope:       scope,
+	})
+	if err != nil {
+		if !apperrors.HasCategory(err, apperrors.CategoryValidation) && !apperrors.HasCategory(err, apperrors.CategoryAuth) {
+			h.logger.Error("Failed to export executions", "error", err)
+		}
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	watermark := result.Watermark
+	filename := fmt.Sprintf("executions-%s.%s", watermark.ExportedAt.Format("20060102T150405Z"), watermark.Format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("X-Export-ID", watermark.ExportID)
+	c.Header("X-Export-Checksum", watermark.Checksum)
+	c.Data(http.StatusOK, result.ContentType, result.Data)
+}
+
+// RequestEvidence starts generating the evidence bundle of an execution
+// for auditors. The bundle is polled with GetEvidence until it is ready.
+func (h *ExecutionHandlers) RequestEvidence(c *gin.Context) {
+	bundle, err := h.service.RequestEvidence(c.Request.Context(), c.Param("id"), c.GetHeader("X-User-ID"))
+	if err != nil {
+		h.respondEvidenceError(c, err, "Failed to request evidence bundle")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, bundle)
+}
+
+// GetEvidence reports the status of an evidence bundle, with a signed
+// download link once it is ready
+func (h *ExecutionHandlers) GetEvidence(c *gin.Context) {
+	bundle, err := h.service.GetEvidence(c.Request.Context(), c.Param("id"), c.Param("bundleId"))
+	if err != nil {
+		h.respondEvidenceError(c, err, "Failed to get evidence bundle")
+		return
+	}
+
+	if bundle.Status != execution.EvidenceReady {
+		c.JSON(http.StatusOK, gin.H{"bundle": bundle})
+		return
+	}
+
+	if h.signer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Signed URLs are not configured"})
+		return
+	}
+
+	path := fmt.Sprintf("/api/v1/executions/evidence/%s", url.PathEscape(bundle.ID))
+	signed, expiresAt, err := h.signer.SignFor(path)
+	if err != nil {
+		h.respondEvidenceError(c, err, "Failed to create evidence download URL")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bundle": bundle, "downloadUrl": signed, "expiresAt": expiresAt})
+}
+
+// DownloadEvidence serves an evidence bundle through a signed URL, the
+// middleware has verified the link
+func (h *ExecutionHandlers) DownloadEvidence(c *gin.Context) {
+	bundle, err := h.service.DownloadEvidence(c.Request.Context(), c.Param("bundleId"))
+	if err != nil {
+		h.respondEvidenceError(c, err, "Failed to download evidence bundle")
+		return
+	}
+
+	filename := fmt.Sprintf("evidence-%s-%s.tar.gz", bundle.ExecutionID, bundle.ID)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("X-Evidence-Checksum", bundle.Checksum)
+	c.Header("X-Evidence-Signature", bundle.Signature)
+	c.Data(http.StatusOK, "application/gzip", bundle.Archive)
+}
+
+func (h *ExecutionHandlers) respondEvidenceError(c *gin.Context, err error, message string) {
+	if !apperrors.HasCategory(err, apperrors.CategoryValidation) &&
+		!apperrors.HasCategory(err, apperrors.CategoryAuth) &&
+		!apperrors.HasCategory(err, apperrors.CategoryNotFound) &&
+		!apperrors.HasCategory(err, apperrors.CategoryConflict) {
+		h.logger.Error(message, "executionId", c.Param("id"), "bundleId", c.Param("bundleId"), "error", err)
+	}
+	c.JSON(apperrors.ToHTTP(err))
+}
+
+// StartBackfill runs a schedule trigger once per time it would have fired
+// over a past range. Progress is polled with GetBackfill.
+func (h *ExecutionHandlers) StartBackfill(c *gin.Context) {
+	var req execution.StartBackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	backfill, err := h.service.StartBackfill(c.Request.Context(), req, c.GetHeader("X-User-ID"))
+	if err != nil {
+		h.respondBackfillError(c, err, "Failed to start backfill")
+		return
+	}
+
+	c.JSON(http.StatusCreated, backfill)
+}
+
+// ListBackfills returns the latest backfills, of one workflow with the
+// workflowId query parameter
+func (h *ExecutionHandlers) ListBackfills(c *gin.Context) {
+	backfills, err := h.service.ListBackfills(c.Request.Context(), c.Query("workflowId"))
+	if err != nil {
+		h.respondBackfillError(c, err, "Failed to list backfills")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"backfills": backfills})
+}
+
+// GetBackfill reports the progress of a backfill
+func (h *ExecutionHandlers) GetBackfill(c *gin.Context) {
+	backfill, err := h.service.GetBackfill(c.Request.Context(), c.Param("backfillId"))
+	if err != nil {
+		h.respondBackfillError(c, err, "Failed to get backfill")
+		return
+	}
+
+	c.JSON(http.StatusOK, backfill)
+}
+
+// CancelBackfill stops starting executions for a backfill, those already
+// started run to the end
+func (h *ExecutionHandlers) CancelBackfill(c *gin.Context) {
+	backfill, err := h.service.CancelBackfill(c.Request.Context(), c.Param("backfillId"))
+	if err != nil {
+		h.respondBackfillError(c, err, "Failed to cancel backfill")
+		return
+	}
+
+	c.JSON(http.StatusOK, backfill)
+}
+
+// GetCostReport sums the costs of executions over a range, grouped by the
+// groupBy query parameter
+func (h *ExecutionHandlers) GetCostReport(c *gin.Context) {
+	report, ok := h.costReport(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportCostReport downloads a cost report as CSV
+func (h *ExecutionHandlers) ExportCostReport(c *gin.Context) {
+	report, ok := h.costReport(c)
+	if !ok {
+		return
+	}
+
+	data, err := cost.ReportCSV(report)
+	if err != nil {
+		h.logger.Error("Failed to render cost report", "error", err)
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	filename := fmt.Sprintf("costs-by-%s-%s-%s.csv", report.GroupBy, report.From, report.To)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv", data)
+}
+
+// costReport reads the cost report the query asks for, or responds with
+// the error
+func (h *ExecutionHandlers) costReport(c *gin.Context) (*execution.CostReport, bool) {
+	from, err := timeQuery(c, "from")
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return nil, false
+	}
+	to, err := timeQuery(c, "to")
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return nil, false
+	}
+
+	filter := execution.CostReportFilter{
+		GroupBy:    c.Query("groupBy"),
+		WorkflowID: c.Query("workflowId"),
+		TeamID:     c.Query("teamId"),
+	}
+	if from != nil {
+		filter.From = *from
+	}
+	if to != nil {
+		filter.To = *to
+	}
+
+	report, err := h.service.GetCostReport(c.Request.Context(), filter)
+	if err != nil {
+		if !apperrors.HasCategory(err, apperrors.CategoryValidation) {
+			h.logger.Error("Failed to get cost report", "groupBy", filter.GroupBy, "error", err)
+		}
+		c.JSON(apperrors.ToHTTP(err))
+		return nil, false
+	}
+	return report, true
+}
+
+func (h *ExecutionHandlers) respondBackfillError(c *gin.Context, err error, message string) {
+	if !apperrors.HasCategory(err, apperrors.CategoryValidation) &&
+		!apperrors.HasCategory(err, apperrors.CategoryNotFound) &&
+		!apperrors.HasCategory(err, apperrors.CategoryConflict) {
+		h.logger.Error(message, "backfillId", c.Param("backfillId"), "error", err)
+	}
+	c.JSON(apperrors.ToHTTP(err))
+}
+
+// timeQuery parses an optional RFC 3339 query parameter
+func timeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+	return &t, nil
+}
+
+// GetExecutionStats summarizes the stored executions of the workflow named
+// by the workflowId query parameter, with its sample rate when sampled
 func (h *ExecutionHandlers) GetExecutionStats(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"stats": map[string]interface{}{}})
+	workflowID := c.Query("workflowId")
+	if workflowID == "" {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(errors.New("workflowId is required"))))
+		return
+	}
+
+	stats, err := h.service.GetExecutionStats(c.Request.Context(), workflowID)
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"stats": stats})
 }
 
 func (h *ExecutionHandlers) StreamExecution(c *gin.Context) {
@@ -95,7 +546,16 @@ func (h *ExecutionHandlers) StreamExecution(c *gin.Context) {
 }
 
 func (h *ExecutionHandlers) TriggerWorkflow(c *gin.Context) {
-	c.JSON(http.StatusAccepted, gin.H{"execution_id": "exec_triggered", "status": "triggered"})
+	var data map[string]interface{}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	debug, _ := strconv.ParseBool(c.Query("debug"))
+	h.startExecution(c, c.Param("workflowId"), c.Query("environment"), data, debug, "triggered")
 }
 
 func (h *ExecutionHandlers) ManualTrigger(c *gin.Context) {
@@ -0,0 +1,71 @@
+// Package rpc serves the execution gRPC API to the other services
+package rpc
+
+import (
+	"context"
+
+	"github.com/linkflow-go/internal/execution/app/service"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/rpc"
+	"github.com/linkflow-go/pkg/rpc/executionv1"
+)
+
+// Server implements executionv1.ExecutionServiceServer
+type Server struct {
+	executionv1.UnimplementedExecutionServiceServer
+	service *service.ExecutionService
+}
+
+// NewServer creates the execution API server
+func NewServer(service *service.ExecutionService) *Server {
+	return &Server{service: service}
+}
+
+// StartExecution starts a workflow, once per idempotency key when one is
+// given
+func (s *Server) StartExecution(ctx context.Context, req *executionv1.StartExecutionRequest) (*executionv1.StartExecutionResponse, error) {
+	if req.GetWorkflowId() == "" {
+		return nil, apperrors.New(apperrors.CategoryValidation, apperrors.CodeInvalidRequest, "workflow_id is required")
+	}
+
+	data := rpc.Map(req.GetData())
+	if req.GetIdempotencyKey() == "" {
+		id, err := s.service.StartExecution(ctx, req.GetWorkflowId(), "", data)
+		if err != nil {
+			return nil, err
+		}
+		return &executionv1.StartExecutionResponse{ExecutionId: id, Created: true}, nil
+	}
+
+	id, duplicate, err := s.service.StartExecutionIdempotent(ctx, req.GetWorkflowId(), "", req.GetIdempotencyKey(), data)
+	if err != nil {
+		return nil, err
+	}
+	return &executionv1.StartExecutionResponse{ExecutionId: id, Created: !duplicate}, nil
+}
+
+// GetExecution returns an execution and the executions of its nodes
+func (s *Server) GetExecution(ctx context.Context, req *executionv1.GetExecutionRequest) (*executionv1.Execution, error) {
+	if req.GetExecutionId() == "" {
+		return nil, apperrors.New(apperrors.CategoryValidation, apperrors.CodeInvalidRequest, "execution_id is required")
+	}
+
+	execution, err := s.service.GetExecution(ctx, req.GetExecutionId())
+	if err != nil {
+		return nil, err
+	}
+	return workflow.ExecutionToMessage(execution)
+}
+
+// StopExecution cancels a running execution
+func (s *Server) StopExecution(ctx context.Context, req *executionv1.StopExecutionRequest) (*executionv1.StopExecutionResponse, error) {
+	if req.GetExecutionId() == "" {
+		return nil, apperrors.New(apperrors.CategoryValidation, apperrors.CodeInvalidRequest, "execution_id is required")
+	}
+
+	if err := s.service.StopExecution(ctx, req.GetExecutionId()); err != nil {
+		return nil, err
+	}
+	return &executionv1.StopExecutionResponse{}, nil
+}
@@ -0,0 +1,383 @@
+// Package backfill runs schedule triggers over past ranges, one execution
+// per time the schedule would have fired.
+package backfill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/internal/execution/app/orchestrator"
+	"github.com/linkflow-go/internal/execution/ports"
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/linkflow-go/pkg/quota"
+	"github.com/linkflow-go/pkg/tenant"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// tickInterval is how often finished executions are collected and the
+	// next ones started
+	tickInterval = 5 * time.Second
+
+	// lockTTL bounds how long a replica that died advancing a backfill
+	// keeps the others from advancing it
+	lockTTL = time.Minute
+
+	lockPrefix = "execution:backfill:lock:"
+
+	// cancelWait is how long a cancellation waits for the backfill to be
+	// released by the replica advancing it
+	cancelWait = 10 * time.Second
+)
+
+var (
+	ErrTriggerNotFound = apperrors.New(apperrors.CategoryNotFound, "TRIGGER_NOT_FOUND",
+		"trigger not found")
+	ErrNotScheduleTrigger = apperrors.New(apperrors.CategoryValidation, "BACKFILL_NOT_SCHEDULE",
+		"only schedule triggers can be backfilled")
+	ErrBackfillNotRunning = apperrors.New(apperrors.CategoryConflict, "BACKFILL_NOT_RUNNING",
+		"backfill is not running")
+	ErrBackfillBusy = apperrors.New(apperrors.CategoryConflict, "BACKFILL_BUSY",
+		"backfill is being advanced, retry")
+)
+
+// Starter starts executions at most once per idempotency key
+type Starter interface {
+	ExecuteWorkflowIdempotent(ctx context.Context, workflowID, environment, idempotencyKey string, inputData map[string]interface{}) (*workflow.WorkflowExecution, bool, error)
+}
+
+// Runner starts the executions of the running backfills of every tenant.
+// Each backfill is advanced by one replica at a time: the executions it
+// started that finished are counted, then the next fire times are started
+// up to its concurrency.
+type Runner struct {
+	repo    ports.ExecutionRepository
+	starter Starter
+	redis   *redis.Client
+	logger  logger.Logger
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// NewRunner creates the runner of the backfills stored in repo
+func NewRunner(repo ports.ExecutionRepository, starter Starter, redis *redis.Client, log logger.Logger) *Runner {
+	return &Runner{
+		repo:    repo,
+		starter: starter,
+		redis:   redis,
+		logger:  log,
+		stopCh:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Create starts backfilling a schedule trigger of a workflow of the tenant
+// of ctx. Its first executions start within tickInterval.
+func (r *Runner) Create(ctx context.Context, req execution.StartBackfillRequest, createdBy string) (*execution.Backfill, error) {
+	trigger, err := r.repo.GetTrigger(ctx, req.TriggerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTriggerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trigger: %w", err)
+	}
+	if trigger.Type != workflow.TriggerTypeSchedule {
+		return nil, ErrNotScheduleTrigger.WithDetail("type", trigger.Type)
+	}
+	// Triggers have no tenant, their workflow does
+	wf, err := r.repo.GetWorkflow(ctx, trigger.WorkflowID)
+	if err != nil {
+		return nil, ErrTriggerNotFound
+	}
+	if !wf.IsActive {
+		return nil, execution.ErrInvalidBackfill.WithMessage("workflow is not active")
+	}
+
+	var config struct {
+		CronExpression string `json:"cronExpression"`
+	}
+	if err := json.Unmarshal(trigger.Config, &config); err != nil {
+		return nil, execution.ErrInvalidBackfill.WithMessage("invalid trigger config: %v", err)
+	}
+
+	backfill, err := execution.NewBackfill(wf.ID, trigger.ID, config.CronExpression, req, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	backfill.EnvironmentID = trigger.EnvironmentID
+	if err := r.repo.CreateBackfill(ctx, backfill); err != nil {
+		return nil, fmt.Errorf("failed to create backfill: %w", err)
+	}
+
+	r.logger.Info("Backfill created",
+		"backfillId", backfill.ID,
+		"workflowId", backfill.WorkflowID,
+		"triggerId", backfill.TriggerID,
+		"runs", backfill.Total,
+	)
+	return backfill, nil
+}
+
+// Get returns a backfill of the tenant of ctx with its progress
+func (r *Runner) Get(ctx context.Context, id string) (*execution.Backfill, error) {
+	backfill, err := r.repo.GetBackfill(ctx, id)
+	if err != nil {
+		return nil, execution.ErrBackfillNotFound.Wrap(err)
+	}
+	return backfill, nil
+}
+
+// List returns the latest backfills of a workflow, of every workflow when
+// workflowID is empty
+func (r *Runner) List(ctx context.Context, workflowID string) ([]*execution.Backfill, error) {
+	return r.repo.ListBackfills(ctx, workflowID)
+}
+
+// Cancel stops starting the executions of a backfill. Executions already
+// started run to their end but are no longer counted.
+func (r *Runner) Cancel(ctx context.Context, id string) (*execution.Backfill, error) {
+	owner, err := r.waitLock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer r.unlock(id, owner)
+
+	backfill, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !backfill.IsRunning() {
+		return nil, ErrBackfillNotRunning.WithDetail("status", backfill.Status)
+	}
+
+	now := time.Now().UTC()
+	backfill.Status = execution.BackfillCancelled
+	backfill.NextFireAt = nil
+	backfill.FinishedAt = &now
+	if err := r.repo.UpdateBackfill(ctx, backfill); err != nil {
+		return nil, fmt.Errorf("failed to cancel backfill: %w", err)
+	}
+
+	r.logger.Info("Backfill cancelled", "backfillId", id)
+	return backfill, nil
+}
+
+// Start advances the running backfills every tickInterval until Stop
+func (r *Runner) Start() {
+	r.startOnce.Do(func() {
+		go r.run()
+	})
+}
+
+// Stop ends the background advances and waits for the running one
+func (r *Runner) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.startOnce.Do(func() {
+		close(r.done)
+	})
+	<-r.done
+}
+
+func (r *Runner) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.stopCh:
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-r.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		if err := r.Tick(ctx); err != nil {
+			r.logger.Error("Failed to advance backfills", "error", err)
+		}
+		cancel()
+	}
+}
+
+// Tick advances every running backfill of every tenant once
+func (r *Runner) Tick(ctx context.Context) error {
+	backfills, err := r.repo.ListRunningBackfills(ctx)
+	if err != nil {
+		return err
+	}
+	for _, backfill := range backfills {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := r.advance(tenant.WithTenant(ctx, backfill.TenantID), backfill.ID); err != nil {
+			r.logger.Error("Failed to advance backfill", "backfillId", backfill.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// advance counts the finished executions of a backfill and starts the next
+// fire times, unless another replica is advancing it
+func (r *Runner) advance(ctx context.Context, id string) error {
+	owner := uuid.New().String()
+	ok, err := r.redis.SetNX(ctx, lockPrefix+id, owner, lockTTL).Result()
+	if err != nil || !ok {
+		return err
+	}
+	defer r.unlock(id, owner)
+
+	// Reloaded under the lock, it may have been cancelled since listed
+	backfill, err := r.repo.GetBackfill(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !backfill.IsRunning() {
+		return nil
+	}
+
+	r.collect(ctx, backfill)
+	r.enqueue(ctx, backfill)
+
+	if backfill.IsRunning() && backfill.NextFireAt == nil && len(backfill.Running) == 0 {
+		now := time.Now().UTC()
+		backfill.Status = execution.BackfillCompleted
+		backfill.FinishedAt = &now
+		r.logger.Info("Backfill completed",
+			"backfillId", id,
+			"completed", backfill.Completed,
+			"failed", backfill.Failed,
+		)
+	}
+	return r.repo.UpdateBackfill(ctx, backfill)
+}
+
+// collect counts the started executions that finished
+func (r *Runner) collect(ctx context.Context, backfill *execution.Backfill) {
+	running := backfill.Running[:0]
+	for _, executionID := range backfill.Running {
+		exec, err := r.repo.GetByID(ctx, executionID)
+		if err != nil {
+			// Deleted before it was counted
+			backfill.Failed++
+			metrics.BackfillExecutions.WithLabelValues("failed").Inc()
+			continue
+		}
+		switch workflow.ExecutionStatus(exec.Status) {
+		case workflow.ExecutionCompleted:
+			backfill.Completed++
+			metrics.BackfillExecutions.WithLabelValues("completed").Inc()
+		case workflow.ExecutionFailed, workflow.ExecutionCancelled, workflow.ExecutionTimeout:
+			backfill.Failed++
+			metrics.BackfillExecutions.WithLabelValues("failed").Inc()
+		default:
+			running = append(running, executionID)
+		}
+	}
+	backfill.Running = running
+}
+
+// enqueue starts the next fire times of a backfill up to its concurrency
+func (r *Runner) enqueue(ctx context.Context, backfill *execution.Backfill) {
+	schedule, err := backfill.Schedule()
+	if err != nil {
+		r.fail(backfill, err)
+		return
+	}
+
+	for backfill.NextFireAt != nil && len(backfill.Running) < backfill.Concurrency {
+		fireAt := *backfill.NextFireAt
+		input := map[string]interface{}{
+			"scheduled_time": fireAt,
+			"_backfill":      backfill.ID,
+		}
+		// Keyed by fire time, a fire time started by a replica that died
+		// before saving the backfill is not started twice
+		key := fmt.Sprintf("backfill:%s:%d", backfill.ID, fireAt.Unix())
+
+		// Executions outlive the advance that started them
+		exec, _, err := r.starter.ExecuteWorkflowIdempotent(context.WithoutCancel(ctx), backfill.WorkflowID, backfill.EnvironmentID, key, input)
+		if errors.Is(err, quota.ErrQuotaExceeded) || errors.Is(err, orchestrator.ErrExecutionInProgress) {
+			// Retried on the next tick
+			r.logger.Warn("Backfill execution deferred", "backfillId", backfill.ID, "error", err)
+			return
+		}
+		if err != nil {
+			r.fail(backfill, err)
+			return
+		}
+
+		backfill.Running = append(backfill.Running, exec.ID)
+		backfill.Enqueued++
+		metrics.BackfillExecutions.WithLabelValues("started").Inc()
+
+		next := schedule.Next(fireAt)
+		if next.After(backfill.End) {
+			backfill.NextFireAt = nil
+		} else {
+			backfill.NextFireAt = &next
+		}
+	}
+}
+
+// fail stops a backfill that cannot start its executions
+func (r *Runner) fail(backfill *execution.Backfill, err error) {
+	now := time.Now().UTC()
+	backfill.Status = execution.BackfillFailed
+	backfill.Error = err.Error()
+	backfill.FinishedAt = &now
+	r.logger.Error("Backfill failed", "backfillId", backfill.ID, "error", err)
+}
+
+// waitLock takes the lock of a backfill, waiting up to cancelWait for the
+// replica advancing it
+func (r *Runner) waitLock(ctx context.Context, id string) (string, error) {
+	owner := uuid.New().String()
+	deadline := time.Now().Add(cancelWait)
+	for {
+		ok, err := r.redis.SetNX(ctx, lockPrefix+id, owner, lockTTL).Result()
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return owner, nil
+		}
+		if time.Now().After(deadline) {
+			return "", ErrBackfillBusy
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// unlock releases the lock of a backfill if it is still held by owner
+func (r *Runner) unlock(id, owner string) {
+	ctx := context.Background()
+	if held, err := r.redis.Get(ctx, lockPrefix+id).Result(); err == nil && held == owner {
+		r.redis.Del(ctx, lockPrefix+id)
+	}
+}
@@ -16,6 +16,7 @@ type Manager struct {
 	mu            sync.RWMutex
 	cancellations map[string]*CancellationContext
 	timeouts      map[string]*TimeoutContext
+	cancelFuncs   map[string]context.CancelFunc
 	eventBus      events.EventBus
 	logger        logger.Logger
 
@@ -67,6 +68,22 @@ type TimeoutContext struct {
 	NodeTimers       map[string]*time.Timer   `json:"-"`
 	EscalationPolicy TimeoutEscalationPolicy  `json:"escalation_policy"`
 	StartedAt        time.Time                `json:"started_at"`
+	Cause            *TimeoutCause            `json:"cause,omitempty"`
+}
+
+// TimeoutCause describes which limit an execution ran into
+type TimeoutCause struct {
+	NodeID     string        `json:"node_id,omitempty"`
+	Timeout    time.Duration `json:"timeout"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
+
+// Reason returns a short machine-readable reason for the timeout
+func (c *TimeoutCause) Reason() string {
+	if c.NodeID != "" {
+		return "node_timeout"
+	}
+	return "execution_timeout"
 }
 
 // TimeoutEscalationPolicy defines how to handle timeouts
@@ -82,6 +99,7 @@ func NewManager(eventBus events.EventBus, logger logger.Logger) *Manager {
 	return &Manager{
 		cancellations: make(map[string]*CancellationContext),
 		timeouts:      make(map[string]*TimeoutContext),
+		cancelFuncs:   make(map[string]context.CancelFunc),
 		eventBus:      eventBus,
 		logger:        logger,
 		stopCh:        make(chan struct{}),
@@ -136,6 +154,23 @@ func (m *Manager) Stop(ctx context.Context) error {
 	return nil
 }
 
+// RegisterExecution records the cancel function of a running execution so a
+// cancellation request can stop it
+func (m *Manager) RegisterExecution(executionID string, cancel context.CancelFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cancelFuncs[executionID] = cancel
+}
+
+// UnregisterExecution forgets the cancel function once the execution ends
+func (m *Manager) UnregisterExecution(executionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.cancelFuncs, executionID)
+}
+
 // CancelExecution cancels an execution
 func (m *Manager) CancelExecution(ctx context.Context, executionID string, config CancelConfig) error {
 	m.mu.Lock()
@@ -155,6 +190,7 @@ func (m *Manager) CancelExecution(ctx context.Context, executionID string, confi
 	cancelCtx := &CancellationContext{
 		ExecutionID:    executionID,
 		WorkflowID:     config.WorkflowID,
+		CancelFunc:     m.cancelFuncs[executionID],
 		Reason:         config.Reason,
 		RequestedBy:    config.RequestedBy,
 		RequestedAt:    time.Now(),
@@ -263,14 +299,7 @@ func (m *Manager) SetTimeout(ctx context.Context, executionID string, config Tim
 		})
 	}
 
-	// Set node timeout timers
-	for nodeID, timeout := range config.NodeTimeouts {
-		nodeTimer := time.AfterFunc(timeout, func() {
-			m.handleTimeout(executionID, nodeID)
-		})
-		timeoutCtx.NodeTimers[nodeID] = nodeTimer
-	}
-
+	// Node timers are armed by StartNodeTimer once the node begins running
 	m.timeouts[executionID] = timeoutCtx
 
 	m.logger.Info("Timeout set for execution",
@@ -290,6 +319,60 @@ func (m *Manager) SetTimeout(ctx context.Context, executionID string, config Tim
 	return nil
 }
 
+// StartNodeTimer arms the timeout of a node when it starts running. Nodes
+// without a configured timeout are ignored.
+func (m *Manager) StartNodeTimer(executionID, nodeID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	timeout, exists := m.timeouts[executionID]
+	if !exists {
+		return
+	}
+
+	limit, ok := timeout.NodeTimeouts[nodeID]
+	if !ok || limit <= 0 {
+		return
+	}
+
+	if timer, running := timeout.NodeTimers[nodeID]; running {
+		timer.Stop()
+	}
+
+	timeout.NodeTimers[nodeID] = time.AfterFunc(limit, func() {
+		m.handleTimeout(executionID, nodeID)
+	})
+}
+
+// StopNodeTimer disarms the timeout of a node once it has finished
+func (m *Manager) StopNodeTimer(executionID, nodeID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	timeout, exists := m.timeouts[executionID]
+	if !exists {
+		return
+	}
+
+	if timer, running := timeout.NodeTimers[nodeID]; running {
+		timer.Stop()
+		delete(timeout.NodeTimers, nodeID)
+	}
+}
+
+// GetTimeoutCause returns what caused an execution to time out, if it did
+func (m *Manager) GetTimeoutCause(executionID string) (*TimeoutCause, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	timeout, exists := m.timeouts[executionID]
+	if !exists || timeout.Cause == nil {
+		return nil, false
+	}
+
+	return timeout.Cause, true
+}
+
 // ClearTimeout clears a timeout for an execution
 func (m *Manager) ClearTimeout(executionID string) {
 	m.mu.Lock()
@@ -312,15 +395,26 @@ func (m *Manager) ClearTimeout(executionID string) {
 
 // handleTimeout handles execution timeout
 func (m *Manager) handleTimeout(executionID string, nodeID string) {
-	m.mu.RLock()
+	m.mu.Lock()
 	timeout, exists := m.timeouts[executionID]
-	m.mu.RUnlock()
-
 	if !exists {
+		m.mu.Unlock()
 		return
 	}
 
+	cause := &TimeoutCause{
+		NodeID:     nodeID,
+		Timeout:    timeout.GlobalTimeout,
+		OccurredAt: time.Now(),
+	}
+	if nodeID != "" {
+		cause.Timeout = timeout.NodeTimeouts[nodeID]
+	}
+	if timeout.Cause == nil {
+		timeout.Cause = cause
+	}
 	m.totalTimeouts++
+	m.mu.Unlock()
 
 	if nodeID != "" {
 		m.logger.Warn("Node execution timed out",
@@ -356,7 +450,8 @@ func (m *Manager) handleTimeout(executionID string, nodeID string) {
 	event := events.NewEventBuilder("execution.timeout").
 		WithAggregateID(executionID).
 		WithPayload("nodeId", nodeID).
-		WithPayload("timeout", timeout.GlobalTimeout).
+		WithPayload("timeout", cause.Timeout).
+		WithPayload("cause", cause.Reason()).
 		Build()
 
 	m.eventBus.Publish(context.Background(), event)
@@ -374,18 +469,30 @@ func (m *Manager) handleTimeoutWarning(executionID string) {
 	m.eventBus.Publish(context.Background(), event)
 }
 
-// stopRunningNodes stops all running nodes for an execution
+// stopRunningNodes asks executor workers to abort every node still running
+// for the execution. Workers cancel the node context, which aborts HTTP
+// requests and rolls back open database transactions.
 func (m *Manager) stopRunningNodes(ctx context.Context, cancel *CancellationContext) error {
-	// This would interact with the node executor to stop nodes
-	// For now, just publish stop events
-
-	event := events.NewEventBuilder("nodes.stop.request").
+	event := events.NewEventBuilder(events.NodesStopRequest).
 		WithAggregateID(cancel.ExecutionID).
+		WithPayload("executionId", cancel.ExecutionID).
+		WithPayload("reason", cancel.Reason).
+		WithPayload("force", cancel.ForceCancel).
 		Build()
 
 	return m.eventBus.Publish(ctx, event)
 }
 
+// RecordCancelledNode records a node that was stopped by a cancellation
+func (m *Manager) RecordCancelledNode(executionID, nodeID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cancel, exists := m.cancellations[executionID]; exists {
+		cancel.NodesCancelled = append(cancel.NodesCancelled, nodeID)
+	}
+}
+
 // cleanupResources cleans up resources for a cancelled execution
 func (m *Manager) cleanupResources(ctx context.Context, cancel *CancellationContext) error {
 	// Cleanup temporary files, connections, etc.
@@ -0,0 +1,134 @@
+package cost
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// spendTTL keeps the spend of a month readable through the next one
+	spendTTL = 62 * 24 * time.Hour
+
+	// countedTTL is how long redeliveries of an execution are not counted
+	// again
+	countedTTL = 48 * time.Hour
+)
+
+// BudgetFunc returns the budgets bounding the executions of a workflow of a
+// workspace
+type BudgetFunc func(ctx context.Context, workflowID, workspaceID string) ([]*workflow.Budget, error)
+
+// BudgetTracker counts the monthly spend of each workflow and workspace in
+// Redis, and reports the budgets whose thresholds the spend reaches
+type BudgetTracker struct {
+	redis    *redis.Client
+	budgets  BudgetFunc
+	eventBus events.EventBus
+	logger   logger.Logger
+}
+
+// NewBudgetTracker creates a tracker reading the budgets from budgets
+func NewBudgetTracker(redis *redis.Client, budgets BudgetFunc, eventBus events.EventBus, logger logger.Logger) *BudgetTracker {
+	return &BudgetTracker{
+		redis:    redis,
+		budgets:  budgets,
+		eventBus: eventBus,
+		logger:   logger,
+	}
+}
+
+// Record adds the cost of an execution to the spend of its workflow and
+// workspace this month, once per execution, then publishes a
+// budget.threshold event for each threshold of their budgets it reaches
+func (t *BudgetTracker) Record(ctx context.Context, cost *ExecutionCost) error {
+	if cost.WorkflowID == "" || cost.TotalCost <= 0 {
+		return nil
+	}
+
+	counted := rediskey.Tenant(ctx, "budget", "counted", cost.ExecutionID)
+	ok, err := t.redis.SetNX(ctx, counted, "1", countedTTL).Result()
+	if err != nil || !ok {
+		return err
+	}
+
+	workspaceID := cost.TeamID
+	if workspaceID == "" {
+		workspaceID = cost.UserID
+	}
+	month := workflow.BudgetMonth(cost.CalculatedAt)
+
+	spent := map[string]float64{}
+	scopes := map[string]string{
+		workflow.BudgetScopeWorkflow:  cost.WorkflowID,
+		workflow.BudgetScopeWorkspace: workspaceID,
+	}
+	for scope, scopeID := range scopes {
+		if scopeID == "" {
+			continue
+		}
+		key := rediskey.Tenant(ctx, workflow.BudgetSpendKey(scope, scopeID, month)...)
+		pipe := t.redis.TxPipeline()
+		incr := pipe.IncrByFloat(ctx, key, cost.TotalCost)
+		pipe.Expire(ctx, key, spendTTL)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		spent[scope] = incr.Val()
+	}
+
+	budgets, err := t.budgets(ctx, cost.WorkflowID, workspaceID)
+	if err != nil {
+		return err
+	}
+	for _, budget := range budgets {
+		after, ok := spent[budget.Scope]
+		if !ok {
+			continue
+		}
+		for _, threshold := range budget.Crossed(after-cost.TotalCost, after) {
+			t.publish(ctx, budget, cost, threshold, after, month)
+		}
+	}
+	return nil
+}
+
+// publish reports that the spend of a budget reached threshold
+func (t *BudgetTracker) publish(ctx context.Context, budget *workflow.Budget, cost *ExecutionCost, threshold int, spent float64, month string) {
+	event := events.NewEventBuilder(events.BudgetThreshold).
+		WithAggregateID(budget.ID).
+		WithPayload("budgetId", budget.ID).
+		WithPayload("scope", budget.Scope).
+		WithPayload("scopeId", budget.ScopeID).
+		WithPayload("workflowId", cost.WorkflowID).
+		WithPayload("userId", cost.UserID).
+		WithPayload("updatedBy", budget.UpdatedBy).
+		WithPayload("threshold", threshold).
+		WithPayload("spent", spent).
+		WithPayload("limit", budget.MonthlyLimit).
+		WithPayload("month", month).
+		WithPayload("hardCap", budget.HardCap).
+		Build()
+
+	if err := t.eventBus.Publish(ctx, event); err != nil {
+		t.logger.Error("Failed to publish budget threshold", "budgetId", budget.ID, "threshold", threshold, "error", err)
+		return
+	}
+	metrics.BudgetThresholds.WithLabelValues(strconv.Itoa(threshold)).Inc()
+
+	t.logger.Warn("Budget threshold reached",
+		"budgetId", budget.ID,
+		"scope", budget.Scope,
+		"scopeId", budget.ScopeID,
+		"threshold", threshold,
+		"spent", spent,
+		"limit", budget.MonthlyLimit,
+	)
+}
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/linkflow-go/internal/execution/ports"
+	"github.com/linkflow-go/pkg/contracts/execution"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
 )
@@ -16,6 +18,9 @@ type Calculator struct {
 	costModel    CostModel
 	pricingRules map[string]PricingRule
 	usageTracker *UsageTracker
+	owners       OwnerFunc
+	budgets      *BudgetTracker
+	repo         ports.ExecutionRepository
 	eventBus     events.EventBus
 	logger       logger.Logger
 
@@ -29,6 +34,9 @@ type Calculator struct {
 	totalExecutions     int64
 }
 
+// OwnerFunc returns the user and team a workflow is billed to
+type OwnerFunc func(ctx context.Context, workflowID string) (userID, teamID string, err error)
+
 // CostModel defines the cost model for execution
 type CostModel struct {
 	ComputeCostPerSecond float64 `json:"compute_cost_per_second"`
@@ -182,6 +190,18 @@ func (c *Calculator) registerDefaultRules() {
 	c.RegisterPricingRule(&ResourceOptimizationRule{})
 }
 
+// SetOwnerLookup attributes the costs of executions to the owners of their
+// workflow, so they can be billed
+func (c *Calculator) SetOwnerLookup(owners OwnerFunc) {
+	c.owners = owners
+}
+
+// SetBudgetTracker counts the calculated costs against the budgets of their
+// workflow
+func (c *Calculator) SetBudgetTracker(budgets *BudgetTracker) {
+	c.budgets = budgets
+}
+
 // Start starts the cost calculator
 func (c *Calculator) Start(ctx context.Context) error {
 	c.logger.Info("Starting cost calculator")
@@ -214,12 +234,26 @@ func (c *Calculator) RegisterPricingRule(rule PricingRule) {
 
 // CalculateExecutionCost calculates the cost for an execution
 func (c *Calculator) CalculateExecutionCost(ctx context.Context, executionID string, usage ResourceUsage) (*ExecutionCost, error) {
+	return c.calculate(ctx, executionID, "", usage)
+}
+
+// calculate prices an execution of a workflow, attributed to the owners of
+// the workflow when known
+func (c *Calculator) calculate(ctx context.Context, executionID, workflowID string, usage ResourceUsage) (*ExecutionCost, error) {
 	cost := &ExecutionCost{
 		ExecutionID:  executionID,
+		WorkflowID:   workflowID,
 		StartTime:    time.Now(),
 		CalculatedAt: time.Now(),
 		NodeCosts:    make(map[string]float64),
 	}
+	if workflowID != "" && c.owners != nil {
+		userID, teamID, err := c.owners(ctx, workflowID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get owner of workflow %s: %w", workflowID, err)
+		}
+		cost.UserID, cost.TeamID = userID, teamID
+	}
 
 	// Calculate resource costs
 	cost.ComputeTime = usage.ComputeTime
@@ -257,6 +291,13 @@ func (c *Calculator) CalculateExecutionCost(ctx context.Context, executionID str
 	// Calculate final cost
 	cost.TotalCost = finalCost * (1 - discount)
 
+	// Persisted first, a failure leaves the execution to be priced again
+	if c.repo != nil && workflowID != "" {
+		if err := c.persist(ctx, cost); err != nil {
+			return nil, fmt.Errorf("failed to save cost of execution %s: %w", executionID, err)
+		}
+	}
+
 	// Store cost
 	c.mu.Lock()
 	c.executionCosts[executionID] = cost
@@ -267,6 +308,12 @@ func (c *Calculator) CalculateExecutionCost(ctx context.Context, executionID str
 	// Publish cost event
 	c.publishCostEvent(ctx, cost)
 
+	if c.budgets != nil {
+		if err := c.budgets.Record(ctx, cost); err != nil {
+			c.logger.Error("Failed to record execution cost against budgets", "executionId", executionID, "error", err)
+		}
+	}
+
 	c.logger.Info("Execution cost calculated",
 		"executionId", executionID,
 		"totalCost", cost.TotalCost,
@@ -447,7 +494,6 @@ func (c *Calculator) publishCostEvent(ctx context.Context, cost *ExecutionCost)
 func (c *Calculator) subscribeToEvents(ctx context.Context) error {
 	events := map[string]events.HandlerFunc{
 		events.ExecutionCompleted: c.handleExecutionCompleted,
-		"resource.usage.reported": c.handleResourceUsage,
 	}
 
 	for eventType, handler := range events {
@@ -464,27 +510,47 @@ func (c *Calculator) subscribeToEvents(ctx context.Context) error {
 func (c *Calculator) handleExecutionCompleted(ctx context.Context, event events.Event) error {
 	executionID := event.AggregateID
 
-	// Get resource usage from tracker
-	usage, err := c.usageTracker.GetUsage(executionID)
-	if err != nil {
+	var payload struct {
+		WorkflowID string           `json:"workflowId"`
+		Duration   int64            `json:"duration"`
+		Usage      *execution.Usage `json:"usage"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
 		return err
 	}
+	duration := time.Duration(payload.Duration) * time.Millisecond
+
+	// The usage the workers measured for the nodes comes with the event,
+	// then the tracker is asked, executions neither has usage for are
+	// charged for their duration
+	var usage *ResourceUsage
+	if payload.Usage != nil {
+		usage = reportedUsage(executionID, duration, *payload.Usage)
+	} else if tracked, err := c.usageTracker.GetUsage(executionID); err == nil {
+		usage = tracked
+	} else {
+		usage = &ResourceUsage{
+			ExecutionID: executionID,
+			ComputeTime: duration,
+		}
+	}
 
 	// Calculate cost
-	_, err = c.CalculateExecutionCost(ctx, executionID, *usage)
+	_, err := c.calculate(ctx, executionID, payload.WorkflowID, *usage)
 	return err
 }
 
-func (c *Calculator) handleResourceUsage(ctx context.Context, event events.Event) error {
-	executionID, _ := event.Payload["executionId"].(string)
-
-	// Track resource usage
-	usage := ResourceUsage{
-		ExecutionID: executionID,
-		// Extract usage from event payload
+// reportedUsage prices the usage the workers reported for an execution.
+// Compute stays charged for the duration of the execution, memory for the
+// highest heap in use while its nodes ran.
+func reportedUsage(executionID string, duration time.Duration, reported execution.Usage) *ResourceUsage {
+	return &ResourceUsage{
+		ExecutionID:  executionID,
+		ComputeTime:  duration,
+		MemoryBytes:  reported.MemoryPeakBytes,
+		NetworkBytes: reported.NetworkBytes(),
+		APICallCount: reported.APICalls,
 	}
-
-	return c.usageTracker.TrackUsage(executionID, usage)
 }
 
 // GetMetrics returns cost calculator metrics
@@ -0,0 +1,40 @@
+package cost
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+
+	"github.com/linkflow-go/pkg/contracts/execution"
+)
+
+var reportColumns = []string{"executions", "nodes", "compute_seconds", "total"}
+
+// ReportCSV renders a cost report as CSV, one row per group with its key
+// under the name of the grouping
+func ReportCSV(report *execution.CostReport) ([]byte, error) {
+	var body bytes.Buffer
+	w := csv.NewWriter(&body)
+
+	if err := w.Write(append([]string{report.GroupBy}, reportColumns...)); err != nil {
+		return nil, err
+	}
+	for _, row := range report.Rows {
+		record := []string{
+			row.Key,
+			strconv.FormatInt(row.Executions, 10),
+			strconv.FormatInt(row.Nodes, 10),
+			strconv.FormatFloat(row.ComputeSeconds, 'f', 3, 64),
+			strconv.FormatFloat(row.Total, 'f', 6, 64),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write csv: %w", err)
+	}
+	return body.Bytes(), nil
+}
@@ -0,0 +1,108 @@
+package cost
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/internal/execution/ports"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+const rollupLockKey = "execution:cost:rollup:lock"
+
+// Roller sums the persisted execution costs into daily rollups every
+// interval, on one replica at a time. Each run sums again the current and
+// previous day, costs calculated around midnight land in either.
+type Roller struct {
+	repo     ports.ExecutionRepository
+	redis    *redis.Client
+	interval time.Duration
+	logger   logger.Logger
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// NewRoller creates a roller running every interval once started
+func NewRoller(repo ports.ExecutionRepository, redis *redis.Client, interval time.Duration, log logger.Logger) *Roller {
+	return &Roller{
+		repo:     repo,
+		redis:    redis,
+		interval: interval,
+		logger:   log,
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start rolls up the costs every interval until Stop
+func (r *Roller) Start() {
+	r.startOnce.Do(func() {
+		go r.run()
+	})
+}
+
+// Stop ends the background runs and waits for the current one
+func (r *Roller) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+	r.startOnce.Do(func() {
+		close(r.done)
+	})
+	<-r.done
+}
+
+func (r *Roller) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.stopCh:
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-r.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		if err := r.Tick(ctx); err != nil {
+			r.logger.Error("Failed to roll up execution costs", "error", err)
+		}
+		cancel()
+	}
+}
+
+// Tick rolls up the costs of the current and previous day, unless another
+// replica is doing so
+func (r *Roller) Tick(ctx context.Context) error {
+	owner := uuid.New().String()
+	ok, err := r.redis.SetNX(ctx, rollupLockKey, owner, r.interval).Result()
+	if err != nil || !ok {
+		return err
+	}
+	defer r.unlock(owner)
+
+	return r.repo.RollupCosts(ctx, time.Now().UTC().AddDate(0, 0, -1))
+}
+
+// unlock releases the lock if it is still held by owner
+func (r *Roller) unlock(owner string) {
+	ctx := context.Background()
+	if held, err := r.redis.Get(ctx, rollupLockKey).Result(); err == nil && held == owner {
+		r.redis.Del(ctx, rollupLockKey)
+	}
+}
@@ -0,0 +1,94 @@
+package cost
+
+import (
+	"context"
+	"sort"
+
+	"github.com/linkflow-go/internal/execution/ports"
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// unknownNodeType attributes the cost of nodes recorded without their type
+const unknownNodeType = "unknown"
+
+// SetRepository persists the calculated costs, split per node type from the
+// node executions, for the cost reports
+func (c *Calculator) SetRepository(repo ports.ExecutionRepository) {
+	c.repo = repo
+}
+
+// persist splits the cost of an execution over the types of the nodes it
+// ran, then stores it
+func (c *Calculator) persist(ctx context.Context, cost *ExecutionCost) error {
+	nodes, err := c.repo.GetNodeExecutions(ctx, cost.ExecutionID)
+	if err != nil {
+		return err
+	}
+	shares := nodeTypeCosts(cost, nodes)
+	for _, share := range shares {
+		cost.NodeCosts[share.NodeType] = share.Cost
+	}
+
+	return c.repo.SaveExecutionCost(ctx, &execution.ExecutionCost{
+		ExecutionID:    cost.ExecutionID,
+		WorkflowID:     cost.WorkflowID,
+		UserID:         cost.UserID,
+		TeamID:         cost.TeamID,
+		ComputeSeconds: cost.ComputeTime.Seconds(),
+		Subtotal:       cost.SubTotal,
+		Discount:       cost.Discount,
+		Total:          cost.TotalCost,
+		Currency:       c.costModel.Currency,
+		CalculatedAt:   cost.CalculatedAt,
+	}, shares)
+}
+
+// nodeTypeCosts splits the total cost of an execution over the types of its
+// nodes by the time each node ran, evenly when no time was recorded
+func nodeTypeCosts(cost *ExecutionCost, nodes []*workflow.NodeExecution) []*execution.NodeTypeCost {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	byType := make(map[string]*execution.NodeTypeCost)
+	seconds := make(map[string]float64)
+	var totalSeconds float64
+	for _, node := range nodes {
+		nodeType := node.NodeType
+		if nodeType == "" {
+			nodeType = unknownNodeType
+		}
+		share, ok := byType[nodeType]
+		if !ok {
+			share = &execution.NodeTypeCost{
+				ExecutionID:  cost.ExecutionID,
+				NodeType:     nodeType,
+				WorkflowID:   cost.WorkflowID,
+				TeamID:       cost.TeamID,
+				CalculatedAt: cost.CalculatedAt,
+			}
+			byType[nodeType] = share
+		}
+		share.Nodes++
+		if node.FinishedAt != nil && node.FinishedAt.After(node.StartedAt) {
+			ran := node.FinishedAt.Sub(node.StartedAt).Seconds()
+			seconds[nodeType] += ran
+			totalSeconds += ran
+		}
+	}
+
+	shares := make([]*execution.NodeTypeCost, 0, len(byType))
+	for nodeType, share := range byType {
+		if totalSeconds > 0 {
+			share.Cost = cost.TotalCost * seconds[nodeType] / totalSeconds
+		} else {
+			share.Cost = cost.TotalCost * float64(share.Nodes) / float64(len(nodes))
+		}
+		shares = append(shares, share)
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		return shares[i].NodeType < shares[j].NodeType
+	})
+	return shares
+}
@@ -0,0 +1,377 @@
+// Package evidence packages everything about one execution into a signed
+// archive for auditors.
+package evidence
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/linkflow-go/internal/execution/app/logging"
+	"github.com/linkflow-go/internal/execution/ports"
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/logger"
+)
+
+// FormatVersion is the layout of the archive, recorded in its manifest
+const FormatVersion = 1
+
+// generateTimeout bounds the generation of a single bundle
+const generateTimeout = 5 * time.Minute
+
+var (
+	ErrRequesterNeeded   = apperrors.New(apperrors.CategoryAuth, "EVIDENCE_REQUESTER_REQUIRED", "evidence bundles must be attributed to a user")
+	ErrExecutionRunning  = apperrors.New(apperrors.CategoryConflict, "EXECUTION_NOT_FINISHED", "evidence can only be bundled once the execution has finished")
+	ErrBundleNotFound    = apperrors.New(apperrors.CategoryNotFound, "EVIDENCE_BUNDLE_NOT_FOUND", "evidence bundle not found")
+	ErrBundleNotReady    = apperrors.New(apperrors.CategoryConflict, "EVIDENCE_BUNDLE_NOT_READY", "evidence bundle is not ready for download")
+	ErrSigningKeyMissing = errors.New("evidence bundles require a signing key")
+)
+
+// sensitiveKeys are masked wherever they appear in inputs, outputs,
+// definitions and logs
+var sensitiveKeys = []string{
+	"password", "apikey", "secret", "token",
+	"credential", "authorization", "privatekey",
+}
+
+// masked replaces the values of sensitive keys
+const masked = "***MASKED***"
+
+// LogSource reads the logs of an execution
+type LogSource interface {
+	GetLogs(ctx context.Context, executionID string, filter logging.LogFilter) ([]*logging.ExecutionLog, error)
+}
+
+// Manifest lists the files of a bundle with their checksums. Its HMAC is
+// stored next to it as manifest.sig, so verifying the signature and then
+// the checksums proves the archive is unaltered.
+type Manifest struct {
+	FormatVersion   int               `json:"formatVersion"`
+	BundleID        string            `json:"bundleId"`
+	ExecutionID     string            `json:"executionId"`
+	WorkflowID      string            `json:"workflowId"`
+	WorkflowVersion int               `json:"workflowVersion"`
+	RequestedBy     string            `json:"requestedBy"`
+	GeneratedAt     time.Time         `json:"generatedAt"`
+	Algorithm       string            `json:"algorithm"`
+	Files           map[string]string `json:"files"`
+}
+
+// NodeRecord is the timing and masked data of one node execution
+type NodeRecord struct {
+	NodeID     string                 `json:"nodeId"`
+	Status     string                 `json:"status"`
+	StartedAt  time.Time              `json:"startedAt"`
+	FinishedAt *time.Time             `json:"finishedAt,omitempty"`
+	DurationMs int64                  `json:"durationMs"`
+	RetryCount int                    `json:"retryCount"`
+	Error      string                 `json:"error,omitempty"`
+	Input      map[string]interface{} `json:"input"`
+	Output     map[string]interface{} `json:"output"`
+}
+
+// Bundler generates evidence bundles in the background and records each
+// one with the audit service
+type Bundler struct {
+	repo     ports.ExecutionRepository
+	logs     LogSource
+	key      []byte
+	eventBus events.EventBus
+	logger   logger.Logger
+}
+
+// NewBundler creates a bundler signing manifests with key
+func NewBundler(repo ports.ExecutionRepository, logs LogSource, key string, eventBus events.EventBus, logger logger.Logger) (*Bundler, error) {
+	if key == "" {
+		return nil, ErrSigningKeyMissing
+	}
+	return &Bundler{
+		repo:     repo,
+		logs:     logs,
+		key:      []byte(key),
+		eventBus: eventBus,
+		logger:   logger,
+	}, nil
+}
+
+// Request stores a pending bundle for a finished execution and generates
+// it in the background. The returned bundle is polled with Get until it is
+// ready or failed.
+func (b *Bundler) Request(ctx context.Context, exec *workflow.WorkflowExecution, requestedBy string) (*execution.EvidenceBundle, error) {
+	if requestedBy == "" {
+		return nil, ErrRequesterNeeded
+	}
+	if !isFinished(exec.Status) {
+		return nil, ErrExecutionRunning.WithDetail("status", exec.Status)
+	}
+
+	bundle := execution.NewEvidenceBundle(exec.ID, requestedBy)
+	if err := b.repo.CreateEvidenceBundle(ctx, bundle); err != nil {
+		return nil, fmt.Errorf("failed to create evidence bundle: %w", err)
+	}
+
+	// Generation outlives the request that asked for it
+	genCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), generateTimeout)
+	go func() {
+		defer cancel()
+		b.generate(genCtx, bundle, exec)
+	}()
+
+	return bundle, nil
+}
+
+// Get returns a bundle of an execution, with its archive when ready
+func (b *Bundler) Get(ctx context.Context, executionID, bundleID string) (*execution.EvidenceBundle, error) {
+	bundle, err := b.repo.GetEvidenceBundle(ctx, bundleID)
+	if err != nil {
+		return nil, ErrBundleNotFound.Wrap(err)
+	}
+	if executionID != "" && bundle.ExecutionID != executionID {
+		return nil, ErrBundleNotFound
+	}
+	return bundle, nil
+}
+
+// Download returns the archive of a ready bundle
+func (b *Bundler) Download(ctx context.Context, bundleID string) (*execution.EvidenceBundle, error) {
+	bundle, err := b.Get(ctx, "", bundleID)
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Status != execution.EvidenceReady {
+		return nil, ErrBundleNotReady.WithDetail("status", bundle.Status)
+	}
+	return bundle, nil
+}
+
+func (b *Bundler) generate(ctx context.Context, bundle *execution.EvidenceBundle, exec *workflow.WorkflowExecution) {
+	archive, signature, err := b.build(ctx, bundle, exec)
+
+	now := time.Now().UTC()
+	bundle.CompletedAt = &now
+	if err != nil {
+		b.logger.Error("Failed to generate evidence bundle", "bundleId", bundle.ID, "executionId", exec.ID, "error", err)
+		bundle.Status = execution.EvidenceFailed
+		bundle.Error = err.Error()
+	} else {
+		sum := sha256.Sum256(archive)
+		bundle.Status = execution.EvidenceReady
+		bundle.Archive = archive
+		bundle.Size = int64(len(archive))
+		bundle.Checksum = hex.EncodeToString(sum[:])
+		bundle.Signature = signature
+	}
+
+	if err := b.repo.UpdateEvidenceBundle(ctx, bundle); err != nil {
+		b.logger.Error("Failed to store evidence bundle", "bundleId", bundle.ID, "error", err)
+		return
+	}
+	if bundle.Status != execution.EvidenceReady {
+		return
+	}
+
+	event := events.NewEventBuilder(events.ExecutionEvidenceReady).
+		WithAggregateID(exec.ID).
+		WithAggregateType("execution").
+		WithUserID(bundle.RequestedBy).
+		WithPayload("bundleId", bundle.ID).
+		WithPayload("executionId", exec.ID).
+		WithPayload("requestedBy", bundle.RequestedBy).
+		WithPayload("checksum", bundle.Checksum).
+		WithPayload("size", bundle.Size).
+		Build()
+	if err := b.eventBus.Publish(ctx, event); err != nil {
+		b.logger.Error("Failed to audit evidence bundle", "bundleId", bundle.ID, "error", err)
+	}
+
+	b.logger.Info("Evidence bundle generated",
+		"bundleId", bundle.ID,
+		"executionId", exec.ID,
+		"requestedBy", bundle.RequestedBy,
+		"size", bundle.Size,
+	)
+}
+
+// build collects the evidence and writes the archive, returning it with
+// the signature of its manifest
+func (b *Bundler) build(ctx context.Context, bundle *execution.EvidenceBundle, exec *workflow.WorkflowExecution) ([]byte, string, error) {
+	definition, err := b.repo.GetWorkflowVersion(ctx, exec.WorkflowID, exec.Version)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load workflow version %d: %w", exec.Version, err)
+	}
+
+	nodeExecs, err := b.repo.GetNodeExecutions(ctx, exec.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load node executions: %w", err)
+	}
+	nodes := make([]NodeRecord, 0, len(nodeExecs))
+	for _, n := range nodeExecs {
+		record := NodeRecord{
+			NodeID:     n.NodeID,
+			Status:     n.Status,
+			StartedAt:  n.StartedAt,
+			FinishedAt: n.FinishedAt,
+			RetryCount: n.RetryCount,
+			Error:      n.Error,
+			Input:      Mask(n.InputData),
+			Output:     Mask(n.OutputData),
+		}
+		if n.FinishedAt != nil {
+			record.DurationMs = n.FinishedAt.Sub(n.StartedAt).Milliseconds()
+		}
+		nodes = append(nodes, record)
+	}
+
+	logs, err := b.logs.GetLogs(ctx, exec.ID, logging.LogFilter{})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load logs: %w", err)
+	}
+	maskedLogs := make([]logging.ExecutionLog, 0, len(logs))
+	for _, l := range logs {
+		entry := *l
+		entry.Data = Mask(l.Data)
+		maskedLogs = append(maskedLogs, entry)
+	}
+
+	credentials, err := b.repo.GetCredentialUses(ctx, exec.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load credential uses: %w", err)
+	}
+	approvals, err := b.repo.GetApprovals(ctx, exec.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load approvals: %w", err)
+	}
+
+	summary := *exec
+	summary.Data = Mask(exec.Data)
+	summary.NodeExecutions = nil
+
+	maskedDefinition := *definition
+	maskedDefinition.Nodes = make([]workflow.Node, len(definition.Nodes))
+	for i, node := range definition.Nodes {
+		node.Parameters = Mask(node.Parameters)
+		maskedDefinition.Nodes[i] = node
+	}
+
+	files := map[string]interface{}{
+		"execution.json":   summary,
+		"definition.json":  maskedDefinition,
+		"nodes.json":       nodes,
+		"logs.json":        maskedLogs,
+		"credentials.json": nonNil(credentials),
+		"approvals.json":   nonNil(approvals),
+	}
+
+	manifest := Manifest{
+		FormatVersion:   FormatVersion,
+		BundleID:        bundle.ID,
+		ExecutionID:     exec.ID,
+		WorkflowID:      exec.WorkflowID,
+		WorkflowVersion: exec.Version,
+		RequestedBy:     bundle.RequestedBy,
+		GeneratedAt:     time.Now().UTC(),
+		Algorithm:       "sha256",
+		Files:           make(map[string]string, len(files)),
+	}
+
+	contents := make(map[string][]byte, len(files)+2)
+	for name, value := range files {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+		sum := sha256.Sum256(data)
+		manifest.Files[name] = hex.EncodeToString(sum[:])
+		contents[name] = data
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	signature := b.sign(manifestData)
+	contents["manifest.json"] = manifestData
+	contents["manifest.sig"] = []byte(signature + "\n")
+
+	archive, err := writeArchive(bundle.ID, manifest.GeneratedAt, contents)
+	if err != nil {
+		return nil, "", err
+	}
+	return archive, signature, nil
+}
+
+// sign returns the hex HMAC-SHA256 of data
+func (b *Bundler) sign(data []byte) string {
+	mac := hmac.New(sha256.New, b.key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// writeArchive writes the files as a gzipped tarball under a directory
+// named after the bundle, the manifest first
+func writeArchive(bundleID string, modTime time.Time, contents map[string][]byte) ([]byte, error) {
+	names := make([]string, 0, len(contents))
+	for name := range contents {
+		if name != "manifest.json" && name != "manifest.sig" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append([]string{"manifest.json", "manifest.sig"}, names...)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range names {
+		data := contents[name]
+		header := &tar.Header{
+			Name:    fmt.Sprintf("evidence-%s/%s", bundleID, name),
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: modTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to write archive: %w", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func isFinished(status string) bool {
+	switch workflow.ExecutionStatus(status) {
+	case workflow.ExecutionCompleted, workflow.ExecutionFailed, workflow.ExecutionCancelled, workflow.ExecutionTimeout:
+		return true
+	}
+	return false
+}
+
+// nonNil keeps empty lists as [] rather than null in the archive
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
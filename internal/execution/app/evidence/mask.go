@@ -0,0 +1,45 @@
+package evidence
+
+import "strings"
+
+// Mask returns a copy of data with the values of sensitive keys replaced,
+// at any depth. Keys match when they contain a sensitive word, so apiKey
+// and X-Api-Key are both masked.
+func Mask(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if isSensitive(key) {
+			out[key] = masked
+			continue
+		}
+		out[key] = maskValue(value)
+	}
+	return out
+}
+
+func maskValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return Mask(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = maskValue(item)
+		}
+		return out
+	}
+	return value
+}
+
+func isSensitive(key string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(key))
+	for _, word := range sensitiveKeys {
+		if strings.Contains(normalized, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/linkflow-go/internal/execution/ports"
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/logger"
+)
+
+// DefaultMaxRecords caps a single export, larger ranges must be split
+const DefaultMaxRecords = 10000
+
+var (
+	ErrInvalidFormat  = apperrors.New(apperrors.CategoryValidation, "INVALID_EXPORT_FORMAT", "export format must be csv or json")
+	ErrExporterNeeded = apperrors.New(apperrors.CategoryAuth, "EXPORTER_REQUIRED", "exports must be attributed to a user")
+	ErrTooManyRecords = apperrors.New(apperrors.CategoryValidation, "EXPORT_TOO_LARGE", "export exceeds the maximum number of records, narrow the scope")
+	ErrAuditFailed    = apperrors.New(apperrors.CategoryUpstream, "EXPORT_AUDIT_FAILED", "export could not be recorded in the audit log")
+)
+
+var csvColumns = []string{
+	"id", "workflow_id", "version", "workflow_checksum", "status",
+	"started_at", "finished_at", "execution_time_ms", "error", "created_by", "created_at",
+}
+
+// Request describes an execution data export
+type Request struct {
+	ExportedBy  string
+	Format      string
+	Destination string
+	Scope       execution.ExportScope
+}
+
+// Result is a rendered export with its watermark
+type Result struct {
+	Watermark   *execution.Watermark
+	ContentType string
+	Data        []byte
+}
+
+// Document is the JSON export layout, the watermark travels with the data
+type Document struct {
+	Watermark  *execution.Watermark          `json:"watermark"`
+	Executions []*workflow.WorkflowExecution `json:"executions"`
+}
+
+// Exporter renders watermarked execution exports and records each one with
+// the audit service
+type Exporter struct {
+	repo       ports.ExecutionRepository
+	eventBus   events.EventBus
+	logger     logger.Logger
+	maxRecords int
+}
+
+// NewExporter creates a new exporter
+func NewExporter(repo ports.ExecutionRepository, eventBus events.EventBus, logger logger.Logger) *Exporter {
+	return &Exporter{
+		repo:       repo,
+		eventBus:   eventBus,
+		logger:     logger,
+		maxRecords: DefaultMaxRecords,
+	}
+}
+
+// Export renders the executions in scope. The export is only returned once
+// the audit event is published, so no data leaves without an audit record.
+func (e *Exporter) Export(ctx context.Context, req Request) (*Result, error) {
+	if req.ExportedBy == "" {
+		return nil, ErrExporterNeeded
+	}
+	if req.Format != execution.ExportFormatCSV && req.Format != execution.ExportFormatJSON {
+		return nil, ErrInvalidFormat
+	}
+	if req.Destination == "" {
+		req.Destination = execution.ExportDestinationDownload
+	}
+
+	// Fetch one extra row to detect truncation
+	executions, err := e.repo.ListForExport(ctx, req.Scope, e.maxRecords+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list executions: %w", err)
+	}
+	if len(executions) > e.maxRecords {
+		return nil, ErrTooManyRecords.WithDetail("maxRecords", e.maxRecords)
+	}
+
+	watermark := execution.NewWatermark(req.ExportedBy, req.Format, req.Destination, req.Scope)
+
+	var result *Result
+	if req.Format == execution.ExportFormatCSV {
+		result, err = renderCSV(watermark, executions)
+	} else {
+		result, err = renderJSON(watermark, executions)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	builder := events.NewEventBuilder(events.ExecutionDataExported).
+		WithAggregateID(watermark.ExportID).
+		WithAggregateType("execution_export").
+		WithUserID(req.ExportedBy)
+	for key, value := range watermark.EventPayload() {
+		builder.WithPayload(key, value)
+	}
+	event := builder.Build()
+
+	if err := e.eventBus.Publish(ctx, event); err != nil {
+		e.logger.Error("Failed to audit execution export", "exportId", watermark.ExportID, "error", err)
+		return nil, ErrAuditFailed.Wrap(err)
+	}
+
+	e.logger.Info("Execution data exported",
+		"exportId", watermark.ExportID,
+		"exportedBy", watermark.ExportedBy,
+		"format", watermark.Format,
+		"records", watermark.RecordCount,
+	)
+
+	return result, nil
+}
+
+// renderCSV writes the watermark as leading comment lines. The checksum
+// covers the CSV rows that follow it.
+func renderCSV(watermark *execution.Watermark, executions []*workflow.WorkflowExecution) (*Result, error) {
+	var body bytes.Buffer
+	w := csv.NewWriter(&body)
+
+	if err := w.Write(csvColumns); err != nil {
+		return nil, err
+	}
+	for _, exec := range executions {
+		finishedAt := ""
+		if exec.FinishedAt != nil {
+			finishedAt = exec.FinishedAt.UTC().Format(time.RFC3339)
+		}
+		row := []string{
+			exec.ID,
+			exec.WorkflowID,
+			strconv.Itoa(exec.Version),
+			exec.WorkflowChecksum,
+			exec.Status,
+			exec.StartedAt.UTC().Format(time.RFC3339),
+			finishedAt,
+			strconv.FormatInt(exec.ExecutionTime, 10),
+			exec.Error,
+			exec.CreatedBy,
+			exec.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	watermark.Seal(len(executions), body.Bytes())
+
+	return &Result{
+		Watermark:   watermark,
+		ContentType: "text/csv",
+		Data:        append([]byte(watermark.CSVHeader()), body.Bytes()...),
+	}, nil
+}
+
+// renderJSON wraps the executions in a document carrying the watermark. The
+// checksum covers the marshaled executions array.
+func renderJSON(watermark *execution.Watermark, executions []*workflow.WorkflowExecution) (*Result, error) {
+	if executions == nil {
+		executions = []*workflow.WorkflowExecution{}
+	}
+
+	records, err := json.Marshal(executions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal executions: %w", err)
+	}
+	watermark.Seal(len(executions), records)
+
+	data, err := json.Marshal(Document{Watermark: watermark, Executions: executions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal export: %w", err)
+	}
+
+	return &Result{
+		Watermark:   watermark,
+		ContentType: "application/json",
+		Data:        data,
+	}, nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/rediskey"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -25,6 +26,7 @@ type ExecutionLogger struct {
 	// Log storage
 	logs                map[string][]*ExecutionLog
 	maxLogsPerExecution int
+	retention           time.Duration
 
 	// WebSocket streaming
 	wsConnections map[string][]*websocket.Conn
@@ -37,20 +39,21 @@ type ExecutionLogger struct {
 
 // ExecutionLog represents a log entry for an execution
 type ExecutionLog struct {
-	ID          string                 `json:"id"`
-	ExecutionID string                 `json:"execution_id"`
-	NodeID      string                 `json:"node_id,omitempty"`
-	Level       LogLevel               `json:"level"`
-	Message     string                 `json:"message"`
-	Data        map[string]interface{} `json:"data,omitempty"`
-	Timestamp   time.Time              `json:"timestamp"`
-	Source      string                 `json:"source"`
+	ID              string                 `json:"id"`
+	ExecutionID     string                 `json:"executionId"`
+	NodeID          string                 `json:"nodeId,omitempty"`
+	NodeExecutionID string                 `json:"nodeExecutionId,omitempty"` // run of the node that logged the line
+	Level           LogLevel               `json:"level"`
+	Message         string                 `json:"message"`
+	Data            map[string]interface{} `json:"data,omitempty"`
+	Timestamp       time.Time              `json:"timestamp"`
+	Source          string                 `json:"source"`
 
 	// Additional context
-	WorkflowID string `json:"workflow_id,omitempty"`
-	UserID     string `json:"user_id,omitempty"`
-	TraceID    string `json:"trace_id,omitempty"`
-	SpanID     string `json:"span_id,omitempty"`
+	WorkflowID string `json:"workflowId,omitempty"`
+	UserID     string `json:"userId,omitempty"`
+	TraceID    string `json:"traceId,omitempty"`
+	SpanID     string `json:"spanId,omitempty"`
 }
 
 // LogLevel represents the log level
@@ -72,6 +75,7 @@ func NewExecutionLogger(redis *redis.Client, eventBus events.EventBus, logger lo
 		eventBus:            eventBus,
 		logs:                make(map[string][]*ExecutionLog),
 		maxLogsPerExecution: 10000,
+		retention:           7 * 24 * time.Hour,
 		wsConnections:       make(map[string][]*websocket.Conn),
 		wsUpgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
@@ -82,6 +86,13 @@ func NewExecutionLogger(redis *redis.Client, eventBus events.EventBus, logger lo
 	}
 }
 
+// SetRetention keeps the logs of an execution for d after its last line
+func (el *ExecutionLogger) SetRetention(d time.Duration) {
+	if d > 0 {
+		el.retention = d
+	}
+}
+
 // Start starts the execution logger
 func (el *ExecutionLogger) Start(ctx context.Context) error {
 	el.logger.Info("Starting execution logger")
@@ -202,24 +213,19 @@ func (el *ExecutionLogger) LogError(ctx context.Context, executionID, nodeID, me
 	})
 }
 
-// GetLogs retrieves logs for an execution
+// GetLogs retrieves logs for an execution. Redis holds the lines logged by
+// every instance, memory only those of this one and is read when Redis is
+// unavailable.
 func (el *ExecutionLogger) GetLogs(ctx context.Context, executionID string, filter LogFilter) ([]*ExecutionLog, error) {
-	// Try memory first
-	el.mu.RLock()
-	logs, exists := el.logs[executionID]
-	el.mu.RUnlock()
-
-	if !exists {
-		// Load from Redis
-		logs, err := el.loadLogsFromRedis(ctx, executionID)
-		if err != nil {
+	logs, err := el.loadLogsFromRedis(ctx, executionID)
+	if err != nil {
+		el.mu.RLock()
+		cached, exists := el.logs[executionID]
+		el.mu.RUnlock()
+		if !exists {
 			return nil, err
 		}
-
-		// Cache in memory
-		el.mu.Lock()
-		el.logs[executionID] = logs
-		el.mu.Unlock()
+		logs = cached
 	}
 
 	// Apply filter
@@ -264,7 +270,7 @@ func (el *ExecutionLogger) StreamLogs(conn *websocket.Conn, executionID string)
 
 // persistLog persists a log to Redis
 func (el *ExecutionLogger) persistLog(ctx context.Context, log *ExecutionLog) error {
-	key := fmt.Sprintf("logs:execution:%s", log.ExecutionID)
+	key := rediskey.Tenant(ctx, "logs", "execution", log.ExecutionID)
 
 	data, err := json.Marshal(log)
 	if err != nil {
@@ -277,7 +283,7 @@ func (el *ExecutionLogger) persistLog(ctx context.Context, log *ExecutionLog) er
 	}
 
 	// Set TTL
-	el.redis.Expire(ctx, key, 7*24*time.Hour)
+	el.redis.Expire(ctx, key, el.retention)
 
 	// Trim list to max size
 	el.redis.LTrim(ctx, key, int64(-el.maxLogsPerExecution), -1)
@@ -287,7 +293,7 @@ func (el *ExecutionLogger) persistLog(ctx context.Context, log *ExecutionLog) er
 
 // loadLogsFromRedis loads logs from Redis
 func (el *ExecutionLogger) loadLogsFromRedis(ctx context.Context, executionID string) ([]*ExecutionLog, error) {
-	key := fmt.Sprintf("logs:execution:%s", executionID)
+	key := rediskey.Tenant(ctx, "logs", "execution", executionID)
 
 	// Get all logs
 	data, err := el.redis.LRange(ctx, key, 0, -1).Result()
@@ -367,6 +373,9 @@ func (el *ExecutionLogger) applyFilter(logs []*ExecutionLog, filter LogFilter) [
 		if filter.NodeID != "" && log.NodeID != filter.NodeID {
 			continue
 		}
+		if filter.NodeExecutionID != "" && log.NodeExecutionID != filter.NodeExecutionID {
+			continue
+		}
 
 		// Filter by time range
 		if !filter.StartTime.IsZero() && log.Timestamp.Before(filter.StartTime) {
@@ -456,6 +465,7 @@ func (el *ExecutionLogger) subscribeToEvents(ctx context.Context) error {
 		events.ExecutionFailed:        el.handleExecutionFailed,
 		events.NodeExecutionStarted:   el.handleNodeExecutionStarted,
 		events.NodeExecutionCompleted: el.handleNodeExecutionCompleted,
+		events.NodeLog:                el.handleNodeLog,
 	}
 
 	for eventType, handler := range events {
@@ -512,14 +522,43 @@ func (el *ExecutionLogger) handleNodeExecutionCompleted(ctx context.Context, eve
 	return nil
 }
 
+// handleNodeLog keeps a log line a node emitted while executing
+func (el *ExecutionLogger) handleNodeLog(ctx context.Context, event events.Event) error {
+	log := &ExecutionLog{
+		ID:        event.ID,
+		Level:     nodeLogLevel(event.Payload["level"]),
+		Timestamp: event.Timestamp,
+		Source:    "node",
+		TraceID:   event.Metadata.TraceID,
+	}
+	log.ExecutionID, _ = event.Payload["executionId"].(string)
+	log.NodeID, _ = event.Payload["nodeId"].(string)
+	log.NodeExecutionID, _ = event.Payload["nodeExecutionId"].(string)
+	log.Message, _ = event.Payload["message"].(string)
+	log.Data, _ = event.Payload["data"].(map[string]interface{})
+
+	return el.Log(ctx, log)
+}
+
+// nodeLogLevel maps the level of a node log line, unknown ones are info
+func nodeLogLevel(level interface{}) LogLevel {
+	switch l, _ := level.(string); LogLevel(l) {
+	case LogLevelDebug, LogLevelWarning, LogLevelError, LogLevelFatal:
+		return LogLevel(l)
+	default:
+		return LogLevelInfo
+	}
+}
+
 // LogFilter represents filter criteria for logs
 type LogFilter struct {
-	Level     LogLevel  `json:"level,omitempty"`
-	NodeID    string    `json:"node_id,omitempty"`
-	StartTime time.Time `json:"start_time,omitempty"`
-	EndTime   time.Time `json:"end_time,omitempty"`
-	Search    string    `json:"search,omitempty"`
-	Limit     int       `json:"limit,omitempty"`
+	Level           LogLevel  `json:"level,omitempty"`
+	NodeID          string    `json:"node_id,omitempty"`
+	NodeExecutionID string    `json:"node_execution_id,omitempty"`
+	StartTime       time.Time `json:"start_time,omitempty"`
+	EndTime         time.Time `json:"end_time,omitempty"`
+	Search          string    `json:"search,omitempty"`
+	Limit           int       `json:"limit,omitempty"`
 }
 
 // Helper functions
@@ -0,0 +1,457 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// DefaultMaxParallelNodes bounds the nodes of an execution running at once
+// unless configured otherwise
+const DefaultMaxParallelNodes = 4
+
+// SetMaxParallelNodes bounds the nodes of an execution running at once,
+// workflows may lower it in their settings. Below 1 runs nodes one by one.
+func (o *Orchestrator) SetMaxParallelNodes(limit int) {
+	if limit < 1 {
+		limit = 1
+	}
+	o.maxParallelNodes = limit
+}
+
+// parallelism is how many nodes of the execution may run at once
+func (e *WorkflowExecutor) parallelism() int {
+	limit := e.orchestrator.maxParallelNodes
+	if limit < 1 {
+		limit = DefaultMaxParallelNodes
+	}
+	if own := e.workflow.Settings.MaxParallelNodes; own > 0 && own < limit {
+		limit = own
+	}
+	return limit
+}
+
+// Scheduling states of a node within an execution
+const (
+	nodePending = iota
+	nodeReady
+	nodeRunning
+	nodeDone
+	nodeSkipped
+)
+
+// nodeResult is a node finishing, sent back by the goroutine running it
+type nodeResult struct {
+	nodeID string
+	err    error
+}
+
+// branchScheduler runs the nodes of an execution as soon as every node
+// before them settled, independent branches side by side. Its state is only
+// touched by the goroutine of executeNodes.
+type branchScheduler struct {
+	e            *WorkflowExecutor
+	predecessors map[string][]string
+	successors   map[string][]string
+	state        map[string]int
+	// remaining counts the predecessors of a node yet to settle
+	remaining map[string]int
+	// activated marks nodes a predecessor of ran, those skipped otherwise
+	activated map[string]bool
+	ready     []string
+	// merges are the parameters of the merge nodes
+	merges map[string]workflow.MergeParameters
+	// deadlines end the wait of merge nodes some branches arrived at
+	deadlines map[string]time.Time
+}
+
+func newBranchScheduler(e *WorkflowExecutor) *branchScheduler {
+	s := &branchScheduler{
+		e:            e,
+		predecessors: make(map[string][]string),
+		successors:   make(map[string][]string),
+		state:        make(map[string]int),
+		remaining:    make(map[string]int),
+		activated:    make(map[string]bool),
+		merges:       make(map[string]workflow.MergeParameters),
+		deadlines:    make(map[string]time.Time),
+	}
+
+	nodes := make(map[string]bool, len(e.workflow.Nodes))
+	for _, node := range e.workflow.Nodes {
+		nodes[node.ID] = true
+		s.state[node.ID] = nodePending
+		if node.Type != workflow.NodeTypeMerge {
+			continue
+		}
+		// Invalid parameters fail the node when it runs
+		if merge, err := workflow.ParseMergeParameters(node.Parameters); err == nil {
+			s.merges[node.ID] = merge
+		}
+	}
+
+	// Connections in their order, a join merges its inputs in that order
+	seen := make(map[[2]string]bool)
+	for _, conn := range e.workflow.Connections {
+		edge := [2]string{conn.Source, conn.Target}
+		if !nodes[conn.Source] || !nodes[conn.Target] || seen[edge] {
+			continue
+		}
+		seen[edge] = true
+		s.successors[conn.Source] = append(s.successors[conn.Source], conn.Target)
+		s.predecessors[conn.Target] = append(s.predecessors[conn.Target], conn.Source)
+		s.remaining[conn.Target]++
+	}
+	return s
+}
+
+// executeNodes runs the nodes of the workflow from its triggers. A node
+// runs once every node before it ran or can no longer run, at most
+// parallelism nodes at once. A failure not caught nor continued stops
+// new nodes from starting; the running ones finish before it is returned.
+func (e *WorkflowExecutor) executeNodes(ctx context.Context) error {
+	s := newBranchScheduler(e)
+	for _, nodeID := range e.findStartNodes(e.buildExecutionGraph()) {
+		s.markReady(nodeID)
+	}
+
+	limit := e.parallelism()
+	results := make(chan nodeResult, limit)
+	running := 0
+	var failure error
+
+	for {
+		// Start what is ready, unless the execution is failing
+		for failure == nil && running < limit && len(s.ready) > 0 {
+			nodeID := s.ready[0]
+			s.ready = s.ready[1:]
+			if s.state[nodeID] != nodeReady {
+				continue
+			}
+			s.state[nodeID] = nodeRunning
+			if _, ok := s.merges[nodeID]; ok {
+				e.setJoin(nodeID, s.predecessors[nodeID], s.unsettled(nodeID))
+			} else if len(s.predecessors[nodeID]) > 1 {
+				e.mergeJoinInputs(s.predecessors[nodeID])
+			}
+
+			running++
+			go func(nodeID string) {
+				results <- nodeResult{nodeID: nodeID, err: e.executeNode(ctx, nodeID)}
+			}(nodeID)
+		}
+
+		if running == 0 {
+			if failure != nil || !s.releaseStalled() {
+				return failure
+			}
+			continue
+		}
+
+		expiry, stop := s.waitTimer()
+		select {
+		case result := <-results:
+			running--
+			if err := s.finish(ctx, result); err != nil && failure == nil {
+				failure = err
+			}
+		case <-expiry:
+			s.expire(time.Now())
+		case <-ctx.Done():
+			stop()
+			if failure == nil {
+				failure = fmt.Errorf("execution cancelled: %w", ctx.Err())
+			}
+			// Running nodes see the cancellation, wait for them
+			for ; running > 0; running-- {
+				<-results
+			}
+			return failure
+		}
+		stop()
+	}
+}
+
+// finish records a node that ran and releases the nodes after it. The error
+// is returned when it fails the execution.
+func (s *branchScheduler) finish(ctx context.Context, result nodeResult) error {
+	e := s.e
+	nodeID, err := result.nodeID, result.err
+	s.state[nodeID] = nodeDone
+
+	if err == nil {
+		s.settle(nodeID, true)
+		return nil
+	}
+
+	if boundary := e.workflow.FindErrorBoundary(nodeID); boundary != nil {
+		// Skip the rest of the try block and continue at the catch node
+		e.catchError(ctx, boundary, nodeID, err)
+		s.settle(nodeID, false)
+		for _, tryNode := range boundary.TryNodes {
+			if state := s.state[tryNode]; state == nodePending || state == nodeReady {
+				s.skip(tryNode)
+			}
+		}
+		if s.state[boundary.CatchNode] == nodePending {
+			s.markReady(boundary.CatchNode)
+		}
+		return nil
+	}
+
+	if !e.shouldContinueOnFail(e.findNode(nodeID)) {
+		return err
+	}
+
+	e.context.mu.Lock()
+	e.context.Errors = append(e.context.Errors, ExecutionErrorDetail{
+		NodeID:          nodeID,
+		Error:           err.Error(),
+		Timestamp:       time.Now(),
+		Retryable:       false,
+		ContinuedOnFail: true,
+		Cause:           timeoutCause(err),
+	})
+	e.context.Stats.ContinuedOnFail++
+	e.context.mu.Unlock()
+
+	e.orchestrator.logger.Warn("Node failed, continuing execution",
+		"executionId", e.execution.ID,
+		"nodeId", nodeID,
+		"error", err,
+	)
+
+	s.settle(nodeID, true)
+	return nil
+}
+
+func (s *branchScheduler) markReady(nodeID string) {
+	delete(s.deadlines, nodeID)
+	s.state[nodeID] = nodeReady
+	s.activated[nodeID] = true
+	s.ready = append(s.ready, nodeID)
+}
+
+// settle counts a node off the nodes after it. Those with every
+// predecessor settled are ready when one of them ran, skipped otherwise.
+// Merge nodes waiting for any branch are ready with the first that ran.
+func (s *branchScheduler) settle(nodeID string, ran bool) {
+	for _, next := range s.successors[nodeID] {
+		if s.state[next] != nodePending {
+			continue
+		}
+		s.remaining[next]--
+		if ran {
+			s.activated[next] = true
+			if merge, ok := s.merges[next]; ok {
+				if !merge.WaitsForAll() {
+					s.markReady(next)
+					continue
+				}
+				if _, waiting := s.deadlines[next]; !waiting && merge.WaitTimeout > 0 {
+					s.deadlines[next] = time.Now().Add(merge.WaitTimeout)
+				}
+			}
+		}
+		if s.remaining[next] > 0 {
+			continue
+		}
+		if s.activated[next] {
+			s.markReady(next)
+		} else {
+			s.skip(next)
+		}
+	}
+}
+
+// skip marks a node that will not run, settling the nodes after it
+func (s *branchScheduler) skip(nodeID string) {
+	delete(s.deadlines, nodeID)
+	s.state[nodeID] = nodeSkipped
+	s.settle(nodeID, false)
+}
+
+// waitTimer fires at the earliest deadline of the merge nodes, never
+// without any. stop releases the timer.
+func (s *branchScheduler) waitTimer() (<-chan time.Time, func()) {
+	var earliest time.Time
+	for _, deadline := range s.deadlines {
+		if earliest.IsZero() || deadline.Before(earliest) {
+			earliest = deadline
+		}
+	}
+	if earliest.IsZero() {
+		return nil, func() {}
+	}
+	timer := time.NewTimer(time.Until(earliest))
+	return timer.C, func() { timer.Stop() }
+}
+
+// expire runs the merge nodes whose wait timed out with the branches that
+// arrived, the merge node fails or continues as it is set to
+func (s *branchScheduler) expire(now time.Time) {
+	for nodeID, deadline := range s.deadlines {
+		if !deadline.After(now) {
+			s.markReady(nodeID)
+		}
+	}
+}
+
+// unsettled lists the predecessors of a node yet to run or be skipped
+func (s *branchScheduler) unsettled(nodeID string) []string {
+	var nodeIDs []string
+	for _, prev := range s.predecessors[nodeID] {
+		if state := s.state[prev]; state != nodeDone && state != nodeSkipped {
+			nodeIDs = append(nodeIDs, prev)
+		}
+	}
+	return nodeIDs
+}
+
+// releaseStalled unblocks nodes waiting on predecessors that can no longer
+// run, nodes no waiting node leads to, such as branches off a catch node
+// that caught nothing. It reports whether any node became ready.
+func (s *branchScheduler) releaseStalled() bool {
+	var waiting []string
+	for _, node := range s.e.workflow.Nodes {
+		if s.state[node.ID] == nodePending && s.activated[node.ID] {
+			waiting = append(waiting, node.ID)
+		}
+	}
+	if len(waiting) == 0 {
+		return false
+	}
+
+	// Whatever a waiting node leads to may still run
+	live := make(map[string]bool)
+	queue := append([]string{}, waiting...)
+	for _, nodeID := range queue {
+		live[nodeID] = true
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range s.successors[current] {
+			if !live[next] {
+				live[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	for _, node := range s.e.workflow.Nodes {
+		if s.state[node.ID] == nodePending && !live[node.ID] {
+			s.skip(node.ID)
+		}
+	}
+	if len(s.ready) > 0 {
+		return true
+	}
+
+	// Only a cycle, which validation rejects, is left: run what waits
+	s.e.orchestrator.logger.Warn("Releasing nodes waiting on a cycle",
+		"executionId", s.e.execution.ID,
+		"nodes", waiting,
+	)
+	for _, nodeID := range waiting {
+		s.markReady(nodeID)
+	}
+	return true
+}
+
+// mergeJoinInputs merges the outputs of the branches joining at a node into
+// the variables, in the order of their connections, so the join reads the
+// same input whichever branch finished last
+func (e *WorkflowExecutor) mergeJoinInputs(predecessors []string) {
+	e.context.mu.Lock()
+	defer e.context.mu.Unlock()
+
+	for _, nodeID := range predecessors {
+		output, ok := e.context.NodeOutputs[nodeID].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for k, v := range output {
+			e.context.Variables[k] = v
+		}
+	}
+}
+
+// joinInputs are the branches a merge node combines, taken when it starts
+type joinInputs struct {
+	inputs []workflow.MergeInput
+	// missing are the branches still running when the wait timed out
+	missing []string
+}
+
+// setJoin takes the outputs of the branches that arrived at a merge node, in
+// the order of their connections, for the node to combine
+func (e *WorkflowExecutor) setJoin(nodeID string, predecessors, missing []string) {
+	e.context.mu.Lock()
+	defer e.context.mu.Unlock()
+
+	join := joinInputs{missing: missing}
+	for _, prev := range predecessors {
+		if output, ok := e.context.NodeOutputs[prev].(map[string]interface{}); ok {
+			join.inputs = append(join.inputs, workflow.MergeInput{NodeID: prev, Data: output})
+		}
+	}
+	if e.joins == nil {
+		e.joins = make(map[string]joinInputs)
+	}
+	e.joins[nodeID] = join
+}
+
+// executeMergeNode combines the branches joining at the node by its
+// strategy. A wait that timed out fails the node unless it continues with
+// the branches that arrived, listing the others as missingBranches.
+func (e *WorkflowExecutor) executeMergeNode(ctx context.Context, node *workflow.Node) (map[string]interface{}, error) {
+	merge, err := workflow.ParseMergeParameters(node.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	e.context.mu.RLock()
+	join := e.joins[node.ID]
+	e.context.mu.RUnlock()
+
+	if len(join.missing) > 0 && merge.OnTimeout != workflow.MergeTimeoutContinue {
+		return nil, fmt.Errorf("%w: node %s waited %s for %s", workflow.ErrMergeTimeout,
+			node.ID, merge.WaitTimeout, strings.Join(join.missing, ", "))
+	}
+
+	// Combining items needs their content, passing branches on does not
+	inputs := join.inputs
+	if merge.Strategy == workflow.MergeAppend || merge.Strategy == workflow.MergeByKey {
+		inputs = make([]workflow.MergeInput, len(join.inputs))
+		for i, input := range join.inputs {
+			data, err := workflow.ResolvePayloads(ctx, e.orchestrator.payloads, input.Data)
+			if err != nil {
+				return nil, err
+			}
+			inputs[i] = workflow.MergeInput{NodeID: input.NodeID, Data: data}
+		}
+	}
+
+	output := merge.Merge(inputs)
+	if len(join.missing) > 0 {
+		output["missingBranches"] = join.missing
+	}
+	return output, nil
+}
+
+// variablesSnapshot copies the variables, the input of a node, so parallel
+// branches merging their outputs do not change it while it runs
+func (e *WorkflowExecutor) variablesSnapshot() map[string]interface{} {
+	e.context.mu.RLock()
+	defer e.context.mu.RUnlock()
+
+	snapshot := make(map[string]interface{}, len(e.context.Variables))
+	for k, v := range e.context.Variables {
+		snapshot[k] = v
+	}
+	return snapshot
+}
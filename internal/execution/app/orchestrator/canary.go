@@ -0,0 +1,39 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// routeCanary picks the version an execution runs while a canary is in
+// progress. Executions hashed outside the canary share run the stable
+// release from its stored version. Any failure to route falls back to the
+// current definition so triggers keep firing.
+func (o *Orchestrator) routeCanary(ctx context.Context, wf *workflow.Workflow, executionID string) *workflow.Workflow {
+	canary, err := o.repository.GetRunningCanary(ctx, wf.ID)
+	if err != nil {
+		o.logger.Warn("Failed to look up workflow canary", "workflowId", wf.ID, "error", err)
+		return wf
+	}
+	// Edits after the canary started make it stale, the new definition wins
+	if canary == nil || canary.CanaryVersion != wf.Version {
+		return wf
+	}
+
+	version := canary.Route(executionID)
+	if version == wf.Version {
+		return wf
+	}
+
+	stable, err := o.workflowVersion(ctx, wf, version)
+	if err != nil {
+		o.logger.Warn("Failed to load stable workflow version for canary",
+			"workflowId", wf.ID,
+			"version", version,
+			"error", err,
+		)
+		return wf
+	}
+	return stable
+}
@@ -0,0 +1,132 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// binding is what an execution runs once bound to its environment
+type binding struct {
+	workflow *workflow.Workflow
+	env      *workflow.Environment
+	input    map[string]interface{}
+	// secrets are the secret variables the execution inherits, still
+	// sealed: only the workers open them, when resolving node parameters
+	secrets map[string]string
+}
+
+// record returns what the execution was bound to, nil when bound to no
+// credentials nor variables
+func (b *binding) record() *workflow.ExecutionBindings {
+	variables, _ := b.input[workflow.EnvironmentVariablesKey].(map[string]interface{})
+	var credentials map[string]string
+	if b.env != nil {
+		credentials = b.env.Credentials
+	}
+	if len(variables) == 0 && len(credentials) == 0 {
+		return nil
+	}
+	return &workflow.ExecutionBindings{Credentials: credentials, Variables: variables}
+}
+
+// bindEnvironment resolves the environment an execution runs in, the
+// default environment of the workflow when none is named. The execution
+// runs the version deployed to it, unless pinned to another version, with
+// its credentials, and the variables it inherits in the input under
+// workflow.EnvironmentVariablesKey. A workflow without environments runs
+// its live version unless pinned. Recorded bindings, when given, replace
+// the credentials of the environment.
+func (o *Orchestrator) bindEnvironment(ctx context.Context, wf *workflow.Workflow, environment string, version int, inputData map[string]interface{}, recorded *workflow.ExecutionBindings) (*binding, error) {
+	env, err := o.repository.GetEnvironment(ctx, wf.ID, environment)
+	if err != nil {
+		return nil, err
+	}
+	if env == nil {
+		if wf, err = o.workflowVersion(ctx, wf, version); err != nil {
+			return nil, err
+		}
+		return o.bindVariables(ctx, wf, nil, inputData)
+	}
+	// A named environment runs what was deployed to it, the default one
+	// runs the live version until a version is deployed
+	if version == 0 {
+		if env.DeployedVersion == 0 && environment != "" {
+			return nil, workflow.ErrEnvironmentNotDeployed.WithMessage("no workflow version is deployed to %s", env.Name)
+		}
+		version = env.DeployedVersion
+	}
+
+	if wf, err = o.workflowVersion(ctx, wf, version); err != nil {
+		return nil, err
+	}
+	if recorded != nil {
+		env.Credentials = recorded.Credentials
+	}
+	env.Bind(wf)
+
+	return o.bindVariables(ctx, wf, env, inputData)
+}
+
+// workflowVersion returns the definition of a version of a workflow: the
+// live definition when it is that version, or none is asked, and its
+// stored snapshot otherwise
+func (o *Orchestrator) workflowVersion(ctx context.Context, live *workflow.Workflow, version int) (*workflow.Workflow, error) {
+	if version == 0 || version == live.Version {
+		return live, nil
+	}
+	snapshot, err := o.repository.GetWorkflowVersion(ctx, live.ID, version)
+	if err != nil {
+		return nil, workflow.ErrVersionUnavailable.WithMessage("version %d of workflow %s cannot be loaded: %v", version, live.ID, err)
+	}
+	// The stored snapshot keeps the activation state of when it was saved
+	snapshot.ID = live.ID
+	snapshot.Status = live.Status
+	snapshot.IsActive = live.IsActive
+	return snapshot, nil
+}
+
+// bindVariables puts in the input the variables an execution inherits, from
+// the global ones to those of the team, the workflow, the environment and
+// the overrides of the input itself. Secrets are left out of the input:
+// those of the variable service stay there, the sealed secret variables of
+// the workflow are kept for the workers.
+func (o *Orchestrator) bindVariables(ctx context.Context, wf *workflow.Workflow, env *workflow.Environment, inputData map[string]interface{}) (*binding, error) {
+	shared, err := o.repository.ListSharedVariables(ctx, wf.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load variables: %w", err)
+	}
+	variables, err := o.repository.ListWorkflowVariables(ctx, wf.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workflow variables: %w", err)
+	}
+
+	layers := append(workflow.SharedVariableLayers(shared, wf.TeamID), workflow.WorkflowVariableLayer(wf.ID, variables))
+	if env != nil {
+		layers = append(layers, env.VariableLayer(variables))
+	}
+	layers = append(layers, workflow.InputVariableLayer(inputData))
+
+	bound := &binding{workflow: wf, env: env, input: inputData}
+	resolved := o.variables.Resolve(layers...)
+	if len(resolved) == 0 && env == nil {
+		return bound, nil
+	}
+
+	for _, variable := range resolved {
+		if sealed, ok := variable.Value.(string); ok && variable.Secret {
+			if bound.secrets == nil {
+				bound.secrets = make(map[string]string)
+			}
+			bound.secrets[variable.Key] = sealed
+		}
+	}
+
+	bound.input = make(map[string]interface{}, len(inputData)+1)
+	for k, v := range inputData {
+		bound.input[k] = v
+	}
+	bound.input[workflow.EnvironmentVariablesKey] = workflow.ResolvedValues(resolved)
+	return bound, nil
+}
@@ -0,0 +1,119 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/redisgc"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultIdempotencyTTL is how long an idempotency key is remembered
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// idempotencyClaimTTL bounds how long a key stays claimed while its
+	// execution is created, a request that crashed in between frees it
+	// after this instead of blocking retries for a day
+	idempotencyClaimTTL = time.Minute
+
+	idempotencyKeyPrefix = "execution:idempotency"
+	idempotencyPending   = "pending"
+)
+
+var (
+	// ErrExecutionInProgress is returned when a duplicate request arrives
+	// while the original request is still creating its execution
+	ErrExecutionInProgress = apperrors.New(apperrors.CategoryConflict, "EXECUTION_IN_PROGRESS", "execution for idempotency key is already in progress")
+)
+
+func idempotencyRedisKey(workflowID, key string) string {
+	return fmt.Sprintf("%s:%s:%s", idempotencyKeyPrefix, workflowID, key)
+}
+
+// IdempotencyGCRule tags the idempotency keys with the workflow they
+// deduplicate executions of. Keys of deleted workflows are orphans, reclaimed
+// before their TTL ends.
+func (o *Orchestrator) IdempotencyGCRule() redisgc.Rule {
+	return redisgc.Rule{
+		Owner:   "execution.idempotency",
+		Pattern: idempotencyKeyPrefix + ":*",
+		OwnerID: func(key string) string {
+			// execution:idempotency:<workflowID>:<key>, the key may hold colons
+			rest := strings.TrimPrefix(key, idempotencyKeyPrefix+":")
+			workflowID, _, ok := strings.Cut(rest, ":")
+			if !ok {
+				return ""
+			}
+			return workflowID
+		},
+		Live: o.repository.LiveWorkflows,
+	}
+}
+
+// ExecuteWorkflowIdempotent starts a workflow execution at most once per
+// idempotency key. If the key was already used, the original execution is
+// returned and duplicate is true. An empty key disables deduplication.
+func (o *Orchestrator) ExecuteWorkflowIdempotent(ctx context.Context, workflowID, environment, idempotencyKey string, inputData map[string]interface{}) (execution *workflow.WorkflowExecution, duplicate bool, err error) {
+	if idempotencyKey == "" || o.redis == nil {
+		execution, err = o.ExecuteWorkflow(ctx, workflowID, environment, inputData)
+		return execution, false, err
+	}
+
+	key := idempotencyRedisKey(workflowID, idempotencyKey)
+
+	// Claim the key before creating the execution so concurrent deliveries race on Redis
+	claimed, err := o.redis.SetNX(ctx, key, idempotencyPending, idempotencyClaimTTL).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	if !claimed {
+		existingID, err := o.redis.Get(ctx, key).Result()
+		if err == redis.Nil {
+			// Key expired between SETNX and GET; treat as a fresh request
+			return o.ExecuteWorkflowIdempotent(ctx, workflowID, environment, idempotencyKey, inputData)
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read idempotency key: %w", err)
+		}
+		if existingID == idempotencyPending {
+			return nil, true, ErrExecutionInProgress
+		}
+
+		existing, err := o.repository.GetByID(ctx, existingID)
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to load deduplicated execution: %w", err)
+		}
+
+		o.logger.Info("Duplicate execution request suppressed",
+			"workflowId", workflowID,
+			"idempotencyKey", idempotencyKey,
+			"executionId", existingID,
+		)
+		return existing, true, nil
+	}
+
+	// Release the claim when no execution was created, so the caller can
+	// retry, even once its context is done
+	defer func() {
+		if execution == nil {
+			o.redis.Del(context.WithoutCancel(ctx), key)
+		}
+	}()
+
+	execution, err = o.ExecuteWorkflow(ctx, workflowID, environment, inputData)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := o.redis.Set(ctx, key, execution.ID, DefaultIdempotencyTTL).Err(); err != nil {
+		o.logger.Error("Failed to record idempotency key", "key", idempotencyKey, "error", err)
+	}
+
+	return execution, false, nil
+}
@@ -2,18 +2,31 @@ package orchestrator
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/linkflow-go/internal/execution/app/cancellation"
+	"github.com/linkflow-go/internal/execution/app/sampling"
 	"github.com/linkflow-go/internal/execution/ports"
+	"github.com/linkflow-go/pkg/contracts/execution"
 	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/quota"
+	"github.com/linkflow-go/pkg/telemetry"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ErrExecutionNotRunning is returned when cancelling an execution this
+// orchestrator is not running
+var ErrExecutionNotRunning = apperrors.New(apperrors.CategoryNotFound, "EXECUTION_NOT_RUNNING", "execution is not running")
+
 // Orchestrator is the main workflow orchestrator
 type Orchestrator struct {
 	repository   ports.ExecutionRepository
@@ -24,7 +37,17 @@ type Orchestrator struct {
 	executorsMux sync.RWMutex
 	pendingMux   sync.Mutex
 	pending      map[string]chan map[string]interface{}
-	stopCh       chan struct{}
+	cancellation *cancellation.Manager
+	quota        *quota.Enforcer
+	sampler      *sampling.Sampler
+	variables    *workflow.VariableManager
+	// maxParallelNodes bounds the nodes of an execution running at once
+	maxParallelNodes int
+	// payloads keeps node data larger than payloadThreshold bytes, nil
+	// passes all data inline
+	payloads         workflow.PayloadStore
+	payloadThreshold int64
+	stopCh           chan struct{}
 }
 
 // WorkflowOrchestrator is an alias for Orchestrator for backward compatibility
@@ -37,6 +60,20 @@ type WorkflowExecutor struct {
 	context      *ExecutionContext
 	stateMachine *ExecutionStateMachine
 	cancelFunc   context.CancelFunc
+	// completed records successfully executed nodes in order for rollback
+	completed []string
+	// discarded records nodes whose partial output was thrown away
+	discarded []string
+	// input is the trigger input as received, replayed by shadow runs
+	input map[string]interface{}
+	// shadow is set when this executor is a shadow run of a live execution
+	shadow *shadowReplay
+	// simulation is set when this executor runs a workflow against mocks
+	simulation *simulation
+	// secrets are the sealed secret variables the nodes may reference
+	secrets map[string]string
+	// joins are the branches merge nodes combine, guarded by context.mu
+	joins map[string]joinInputs
 }
 
 type ExecutionContext struct {
@@ -44,16 +81,28 @@ type ExecutionContext struct {
 	Variables   map[string]interface{} `json:"variables"`
 	NodeOutputs map[string]interface{} `json:"node_outputs"`
 	Errors      []ExecutionErrorDetail `json:"errors"`
-	StartTime   time.Time              `json:"start_time"`
-	Metadata    map[string]string      `json:"metadata"`
-	mu          sync.RWMutex
+	Stats       ExecutionNodeStats     `json:"stats"`
+	// Usage sums the resources the workers report for the nodes
+	Usage     execution.Usage   `json:"usage"`
+	StartTime time.Time         `json:"start_time"`
+	Metadata  map[string]string `json:"metadata"`
+	mu        sync.RWMutex
 }
 
 type ExecutionErrorDetail struct {
-	NodeID    string    `json:"node_id"`
-	Error     string    `json:"error"`
-	Timestamp time.Time `json:"timestamp"`
-	Retryable bool      `json:"retryable"`
+	NodeID          string    `json:"node_id"`
+	Error           string    `json:"error"`
+	Timestamp       time.Time `json:"timestamp"`
+	Retryable       bool      `json:"retryable"`
+	ContinuedOnFail bool      `json:"continued_on_fail,omitempty"`
+	Cause           string    `json:"cause,omitempty"`
+}
+
+// ExecutionNodeStats counts node outcomes within a single execution
+type ExecutionNodeStats struct {
+	CompletedNodes  int `json:"completed_nodes"`
+	FailedNodes     int `json:"failed_nodes"`
+	ContinuedOnFail int `json:"continued_on_fail"`
 }
 
 func NewOrchestrator(repo ports.ExecutionRepository, eventBus events.EventBus, redis *redis.Client, logger logger.Logger) *Orchestrator {
@@ -64,10 +113,31 @@ func NewOrchestrator(repo ports.ExecutionRepository, eventBus events.EventBus, r
 		logger:     logger,
 		executors:  make(map[string]*WorkflowExecutor),
 		pending:    make(map[string]chan map[string]interface{}),
+		variables:  workflow.NewVariableManager(),
 		stopCh:     make(chan struct{}),
+
+		maxParallelNodes: DefaultMaxParallelNodes,
 	}
 }
 
+// SetCancellationManager wires the cancellation manager that tracks
+// execution and per-node timeouts
+func (o *Orchestrator) SetCancellationManager(manager *cancellation.Manager) {
+	o.cancellation = manager
+}
+
+// SetQuota wires the enforcer bounding the executions of each tenant by
+// its plan
+func (o *Orchestrator) SetQuota(enforcer *quota.Enforcer) {
+	o.quota = enforcer
+}
+
+// SetSampler wires the sampler dropping the successful executions of
+// workflows with a sampling policy
+func (o *Orchestrator) SetSampler(sampler *sampling.Sampler) {
+	o.sampler = sampler
+}
+
 func (o *Orchestrator) registerPending(requestID string) chan map[string]interface{} {
 	o.pendingMux.Lock()
 	defer o.pendingMux.Unlock()
@@ -143,7 +213,86 @@ func (o *Orchestrator) Stop() {
 	o.executorsMux.Unlock()
 }
 
-func (o *Orchestrator) ExecuteWorkflow(ctx context.Context, workflowID string, inputData map[string]interface{}) (*workflow.WorkflowExecution, error) {
+// CancelExecution stops a running execution. The running node receives the
+// cancelled context, executor workers are told to abort their work and
+// partial node outputs are discarded.
+func (o *Orchestrator) CancelExecution(ctx context.Context, executionID, reason string) error {
+	o.executorsMux.RLock()
+	executor, ok := o.executors[executionID]
+	o.executorsMux.RUnlock()
+
+	if !ok {
+		return ErrExecutionNotRunning
+	}
+
+	if o.cancellation != nil {
+		return o.cancellation.CancelExecution(ctx, executionID, cancellation.CancelConfig{
+			WorkflowID: executor.workflow.ID,
+			Reason:     reason,
+		})
+	}
+
+	executor.cancelFunc()
+
+	event := events.NewEventBuilder(events.NodesStopRequest).
+		WithAggregateID(executionID).
+		WithPayload("executionId", executionID).
+		WithPayload("reason", reason).
+		Build()
+
+	return o.eventBus.Publish(ctx, event)
+}
+
+// ExecuteWorkflow starts an execution of a workflow in an environment, by ID
+// or name, or in its default environment when environment is empty
+func (o *Orchestrator) ExecuteWorkflow(ctx context.Context, workflowID, environment string, inputData map[string]interface{}) (*workflow.WorkflowExecution, error) {
+	return o.execute(ctx, "", workflowID, environment, 0, inputData, nil)
+}
+
+// ExecuteWorkflowAs is ExecuteWorkflow under the ID the execution was
+// requested with
+func (o *Orchestrator) ExecuteWorkflowAs(ctx context.Context, executionID, workflowID, environment string, inputData map[string]interface{}) (*workflow.WorkflowExecution, error) {
+	return o.execute(ctx, executionID, workflowID, environment, 0, inputData, nil)
+}
+
+// ExecuteWorkflowVersion starts an execution pinned to the version of a
+// workflow it was requested with, under the ID it was requested with unless
+// empty. The stored snapshot of the version runs, whatever version is live,
+// deployed to the environment or in a canary by then; when the snapshot
+// cannot be loaded the execution fails with workflow.ErrVersionUnavailable
+// instead of running another definition.
+func (o *Orchestrator) ExecuteWorkflowVersion(ctx context.Context, executionID, workflowID, environment string, version int, inputData map[string]interface{}) (*workflow.WorkflowExecution, error) {
+	if version <= 0 {
+		return nil, workflow.ErrVersionUnavailable.WithMessage("execution of workflow %s names no version", workflowID)
+	}
+	return o.execute(ctx, executionID, workflowID, environment, version, inputData, nil)
+}
+
+// RestartExecution starts an execution over under a new ID with input, the
+// input it started with, on the version, in the environment and with the
+// credentials and variables it was bound to, whatever changed since. The
+// data of the record is not the input once the execution ended, it holds
+// the outputs of its nodes.
+func (o *Orchestrator) RestartExecution(ctx context.Context, executionID string, input map[string]interface{}) (*workflow.WorkflowExecution, error) {
+	previous, err := o.repository.GetByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get execution: %w", err)
+	}
+
+	// The input holds the variables it inherited, which override those
+	// inherited now
+	bindings := previous.Bindings
+	if bindings == nil {
+		bindings = &workflow.ExecutionBindings{}
+	}
+	return o.execute(ctx, "", previous.WorkflowID, previous.EnvironmentID, previous.Version, input, bindings)
+}
+
+// execute starts an execution of a workflow, of a version of it when
+// version is not 0. The definition, its credentials and variables are
+// bound once, the execution holds them until it ends. Restarts pass the
+// bindings of the execution they restart.
+func (o *Orchestrator) execute(ctx context.Context, executionID, workflowID, environment string, version int, inputData map[string]interface{}, recorded *workflow.ExecutionBindings) (*workflow.WorkflowExecution, error) {
 	// Get workflow
 	wf, err := o.repository.GetWorkflow(ctx, workflowID)
 	if err != nil {
@@ -155,18 +304,76 @@ func (o *Orchestrator) ExecuteWorkflow(ctx context.Context, workflowID string, i
 		return nil, fmt.Errorf("workflow is not active")
 	}
 
+	// The environment decides the version run, unless pinned, its
+	// credentials and variables
+	bound, err := o.bindEnvironment(ctx, wf, environment, version, inputData, recorded)
+	if err != nil {
+		return nil, err
+	}
+	wf, env, inputData := bound.workflow, bound.env, bound.input
+
+	// An idempotent workflow does not run an input again while its output
+	// is cached
+	if cached := o.cachedExecution(ctx, wf, inputData); cached != nil {
+		o.logger.Info("Returning cached result of idempotent workflow",
+			"workflowId", workflowID,
+			"executionId", cached.ID,
+		)
+		return cached, nil
+	}
+
+	// A running canary decides which version this execution runs, unless
+	// the execution or the environment pins another one
+	if executionID == "" {
+		executionID = uuid.New().String()
+	}
+	if version == 0 && (env == nil || env.DeployedVersion == 0) {
+		wf = o.routeCanary(ctx, wf, executionID)
+	}
+
+	// Node types blocked after the workflow was activated never run
+	if err := o.checkNodeTypes(ctx, wf); err != nil {
+		return nil, err
+	}
+
+	// Record the definition actually run so it can be audited against the
+	// stored version later
+	checksum, err := wf.ComputeChecksum()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute workflow checksum: %w", err)
+	}
+	if wf.Checksum != "" && wf.Checksum != checksum {
+		o.logger.Warn("Workflow definition does not match its recorded checksum",
+			"workflowId", workflowID,
+			"expected", wf.Checksum,
+			"actual", checksum,
+		)
+	}
+
+	// The plan of the tenant bounds its executions per month, running
+	// executions and stored execution data
+	if err := o.quota.StartExecution(ctx, executionID); err != nil {
+		return nil, err
+	}
+
 	// Create execution record
 	execution := &workflow.WorkflowExecution{
-		ID:         uuid.New().String(),
-		WorkflowID: workflowID,
-		Version:    wf.Version,
-		Status:     string(workflow.ExecutionRunning),
-		StartedAt:  time.Now(),
-		Data:       inputData,
-		CreatedAt:  time.Now(),
+		ID:               executionID,
+		WorkflowID:       workflowID,
+		Version:          wf.Version,
+		WorkflowChecksum: checksum,
+		Status:           string(workflow.ExecutionRunning),
+		StartedAt:        time.Now(),
+		Data:             inputData,
+		Bindings:         bound.record(),
+		CreatedAt:        time.Now(),
+	}
+	if env != nil {
+		execution.EnvironmentID = env.ID
 	}
 
 	if err := o.repository.Create(ctx, execution); err != nil {
+		o.quota.FinishExecution(ctx, executionID)
 		return nil, fmt.Errorf("failed to create execution: %w", err)
 	}
 
@@ -176,12 +383,19 @@ func (o *Orchestrator) ExecuteWorkflow(ctx context.Context, workflowID string, i
 		WithAggregateType("execution").
 		WithPayload("workflowId", workflowID).
 		WithPayload("executionId", execution.ID).
+		WithPayload("debug", logger.Debugging(ctx)).
 		Build()
 
 	if err := o.eventBus.Publish(ctx, event); err != nil {
 		o.logger.Error("Failed to publish execution started event", "error", err)
 	}
 
+	// Keep the input as received, node outputs are merged into the variables
+	input := make(map[string]interface{}, len(inputData))
+	for k, v := range inputData {
+		input[k] = v
+	}
+
 	// Create execution context
 	execContext := &ExecutionContext{
 		ExecutionID: execution.ID,
@@ -191,6 +405,9 @@ func (o *Orchestrator) ExecuteWorkflow(ctx context.Context, workflowID string, i
 		StartTime:   time.Now(),
 		Metadata:    make(map[string]string),
 	}
+	if logger.Debugging(ctx) {
+		execContext.Metadata["debug"] = "true"
+	}
 
 	// Create state machine
 	stateMachine := NewExecutionStateMachine(
@@ -210,6 +427,8 @@ func (o *Orchestrator) ExecuteWorkflow(ctx context.Context, workflowID string, i
 		context:      execContext,
 		stateMachine: stateMachine,
 		cancelFunc:   cancel,
+		input:        input,
+		secrets:      bound.secrets,
 	}
 
 	// Store executor
@@ -217,6 +436,18 @@ func (o *Orchestrator) ExecuteWorkflow(ctx context.Context, workflowID string, i
 	o.executors[execution.ID] = executor
 	o.executorsMux.Unlock()
 
+	// Register execution and node timeouts
+	if o.cancellation != nil {
+		timeoutConfig := cancellation.TimeoutConfig{
+			GlobalTimeout: time.Duration(wf.Settings.Timeout) * time.Second,
+			NodeTimeouts:  wf.NodeTimeouts(),
+		}
+		if err := o.cancellation.SetTimeout(ctx, execution.ID, timeoutConfig); err != nil {
+			o.logger.Error("Failed to set execution timeout", "executionId", execution.ID, "error", err)
+		}
+		o.cancellation.RegisterExecution(execution.ID, cancel)
+	}
+
 	// Start execution in background
 	go executor.Execute(execCtx)
 
@@ -224,12 +455,25 @@ func (o *Orchestrator) ExecuteWorkflow(ctx context.Context, workflowID string, i
 }
 
 func (e *WorkflowExecutor) Execute(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "workflow.execute", trace.WithAttributes(
+		telemetry.WorkflowIDAttribute(e.workflow.ID),
+		telemetry.ExecutionIDAttribute(e.execution.ID),
+	))
+	var execErr error
+	defer func() { endSpan(span, execErr) }()
+
 	defer func() {
 		// Clean up executor
 		e.orchestrator.executorsMux.Lock()
 		delete(e.orchestrator.executors, e.execution.ID)
 		e.orchestrator.executorsMux.Unlock()
 
+		if e.orchestrator.cancellation != nil {
+			e.orchestrator.cancellation.ClearTimeout(e.execution.ID)
+			e.orchestrator.cancellation.UnregisterExecution(e.execution.ID)
+		}
+		e.orchestrator.quota.FinishExecution(context.WithoutCancel(ctx), e.execution.ID)
+
 		// Cancel context
 		e.cancelFunc()
 	}()
@@ -237,85 +481,195 @@ func (e *WorkflowExecutor) Execute(ctx context.Context) {
 	// Transition to running state
 	if err := e.stateMachine.Transition(ctx, EventStart, nil); err != nil {
 		e.orchestrator.logger.Error("Failed to transition to running state", "error", err)
+		execErr = err
 		e.handleExecutionError(ctx, err)
 		return
 	}
 
 	// Execute workflow nodes
 	if err := e.executeNodes(ctx); err != nil {
+		execErr = err
+		e.compensate(ctx, err)
+		if errors.Is(err, context.Canceled) {
+			e.handleExecutionCancelled(context.WithoutCancel(ctx), err)
+			return
+		}
 		e.handleExecutionError(ctx, err)
+		e.orchestrator.shadowExecution(ctx, e)
 		return
 	}
 
 	// Mark execution as completed
 	e.completeExecution(ctx)
+	e.orchestrator.shadowExecution(ctx, e)
 }
 
-func (e *WorkflowExecutor) executeNodes(ctx context.Context) error {
-	// Build execution graph
-	graph := e.buildExecutionGraph()
+// catchError records a failure inside a try block and exposes the error
+// object to the catch branch under the "error" variable
+func (e *WorkflowExecutor) catchError(ctx context.Context, boundary *workflow.ErrorBoundary, nodeID string, err error) {
+	now := time.Now()
+	errorObject := map[string]interface{}{
+		"message":    err.Error(),
+		"nodeId":     nodeID,
+		"boundaryId": boundary.ID,
+		"timestamp":  now,
+	}
 
-	// Find starting nodes (triggers)
-	startNodes := e.findStartNodes(graph)
+	e.context.mu.Lock()
+	e.context.Errors = append(e.context.Errors, ExecutionErrorDetail{
+		NodeID:    nodeID,
+		Error:     err.Error(),
+		Timestamp: now,
+		Retryable: false,
+	})
+	e.context.Variables["error"] = errorObject
+	e.context.mu.Unlock()
+
+	e.orchestrator.logger.Info("Error caught by error boundary",
+		"executionId", e.execution.ID,
+		"nodeId", nodeID,
+		"boundaryId", boundary.ID,
+		"catchNode", boundary.CatchNode,
+	)
 
-	// Execute nodes in order
-	executed := make(map[string]bool)
-	queue := startNodes
+	event := events.NewEventBuilder(events.ExecutionErrorCaught).
+		WithAggregateID(e.execution.ID).
+		WithAggregateType("execution").
+		WithPayload("workflowId", e.workflow.ID).
+		WithPayload("nodeId", nodeID).
+		WithPayload("boundaryId", boundary.ID).
+		WithPayload("catchNode", boundary.CatchNode).
+		WithError(err).
+		Build()
 
-	for len(queue) > 0 {
-		// Check context cancellation
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("execution cancelled")
-		default:
+	if e.shadow == nil && e.simulation == nil {
+		e.orchestrator.eventBus.Publish(ctx, event)
+	}
+}
+
+// findNode looks up a node of the executing workflow by ID
+func (e *WorkflowExecutor) findNode(nodeID string) *workflow.Node {
+	for i := range e.workflow.Nodes {
+		if e.workflow.Nodes[i].ID == nodeID {
+			return &e.workflow.Nodes[i]
 		}
+	}
+	return nil
+}
 
-		nodeID := queue[0]
-		queue = queue[1:]
+// shouldContinueOnFail reports whether a failure of the node should be
+// recorded and skipped instead of failing the execution. The node option
+// takes effect on its own; the workflow setting applies to every node.
+func (e *WorkflowExecutor) shouldContinueOnFail(node *workflow.Node) bool {
+	if node != nil && node.ContinueOnFail {
+		return true
+	}
+	return e.workflow.Settings.ErrorHandling.ContinueOnFail
+}
 
-		if executed[nodeID] {
-			continue
-		}
+// failedNodeOutput builds the output exposed for a node that failed with
+// continue-on-fail enabled so downstream nodes can inspect the failure
+func failedNodeOutput(nodeID string, err error) map[string]interface{} {
+	return map[string]interface{}{
+		"error": map[string]interface{}{
+			"message":   err.Error(),
+			"nodeId":    nodeID,
+			"timestamp": time.Now(),
+		},
+		"continuedOnFail": true,
+	}
+}
 
-		// Execute node
-		if err := e.executeNode(ctx, nodeID); err != nil {
-			if e.workflow.Settings.ErrorHandling.ContinueOnFail {
-				e.context.mu.Lock()
-				e.context.Errors = append(e.context.Errors, ExecutionErrorDetail{
-					NodeID:    nodeID,
-					Error:     err.Error(),
-					Timestamp: time.Now(),
-					Retryable: false,
-				})
-				e.context.mu.Unlock()
-			} else {
-				return err
-			}
+// compensate runs the compensation nodes of already completed nodes in
+// reverse completion order, giving saga-style rollback when a downstream
+// node fails. A failing compensation is recorded and the rollback goes on.
+func (e *WorkflowExecutor) compensate(ctx context.Context, cause error) {
+	e.context.mu.Lock()
+	var pending []string
+	for i := len(e.completed) - 1; i >= 0; i-- {
+		if node := e.findNode(e.completed[i]); node != nil && node.CompensationNode != "" {
+			pending = append(pending, node.ID)
 		}
+	}
+	if len(pending) > 0 {
+		e.context.Variables["error"] = map[string]interface{}{
+			"message":   cause.Error(),
+			"timestamp": time.Now(),
+		}
+	}
+	e.context.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
 
-		executed[nodeID] = true
+	// Rollback must run even when the execution context was cancelled
+	ctx = context.WithoutCancel(ctx)
 
-		// Add downstream nodes to queue
-		for _, conn := range e.workflow.Connections {
-			if conn.Source == nodeID && !executed[conn.Target] {
-				queue = append(queue, conn.Target)
-			}
+	e.orchestrator.logger.Info("Compensating failed execution",
+		"executionId", e.execution.ID,
+		"nodes", len(pending),
+	)
+
+	event := events.NewEventBuilder(events.ExecutionCompensating).
+		WithAggregateID(e.execution.ID).
+		WithAggregateType("execution").
+		WithPayload("workflowId", e.workflow.ID).
+		WithPayload("nodes", pending).
+		WithError(cause).
+		Build()
+
+	e.orchestrator.eventBus.Publish(ctx, event)
+
+	var failed []string
+	for _, nodeID := range pending {
+		compensationNode := e.findNode(nodeID).CompensationNode
+		if err := e.executeNode(ctx, compensationNode); err != nil {
+			e.orchestrator.logger.Error("Compensation node failed",
+				"executionId", e.execution.ID,
+				"nodeId", nodeID,
+				"compensationNode", compensationNode,
+				"error", err,
+			)
+
+			e.context.mu.Lock()
+			e.context.Errors = append(e.context.Errors, ExecutionErrorDetail{
+				NodeID:    compensationNode,
+				Error:     err.Error(),
+				Timestamp: time.Now(),
+				Retryable: false,
+			})
+			e.context.mu.Unlock()
+
+			failed = append(failed, compensationNode)
 		}
 	}
 
-	return nil
+	event = events.NewEventBuilder(events.ExecutionCompensated).
+		WithAggregateID(e.execution.ID).
+		WithAggregateType("execution").
+		WithPayload("workflowId", e.workflow.ID).
+		WithPayload("nodes", pending).
+		WithPayload("failed", failed).
+		Build()
+
+	e.orchestrator.eventBus.Publish(ctx, event)
 }
 
-func (e *WorkflowExecutor) executeNode(ctx context.Context, nodeID string) error {
-	// Find node
-	var node *workflow.Node
-	for _, n := range e.workflow.Nodes {
-		if n.ID == nodeID {
-			node = &n
-			break
-		}
+// discardedNodeOutput marks the partial output of a cancelled node as
+// discarded so it is never mistaken for a result
+func discardedNodeOutput(partial map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"discarded":   true,
+		"reason":      "cancelled",
+		"partial":     partial,
+		"discardedAt": time.Now(),
 	}
+}
 
+func (e *WorkflowExecutor) executeNode(ctx context.Context, nodeID string) (err error) {
+	// Find node
+	node := e.findNode(nodeID)
 	if node == nil {
 		return fmt.Errorf("node not found: %s", nodeID)
 	}
@@ -325,19 +679,35 @@ func (e *WorkflowExecutor) executeNode(ctx context.Context, nodeID string) error
 		return nil
 	}
 
+	if e.shadow != nil {
+		return e.executeShadowNode(ctx, node)
+	}
+	if e.simulation != nil {
+		return e.executeSimulatedNode(ctx, node)
+	}
+
+	ctx, span := tracer.Start(ctx, "node.execute "+node.Type, trace.WithAttributes(
+		telemetry.ExecutionIDAttribute(e.execution.ID),
+		telemetry.NodeIDAttribute(node.ID),
+		telemetry.NodeTypeAttribute(node.Type),
+	))
+	defer func() { endSpan(span, err) }()
+
 	// Create node execution record
 	nodeExec := &workflow.NodeExecution{
 		ID:          uuid.New().String(),
 		ExecutionID: e.execution.ID,
 		NodeID:      nodeID,
+		NodeType:    node.Type,
 		Status:      string(workflow.NodeExecutionRunning),
 		StartedAt:   time.Now(),
-		InputData:   e.context.Variables,
+		InputData:   e.workflow.RedactData(e.variablesSnapshot()),
 	}
 
 	if err := e.orchestrator.repository.CreateNodeExecution(ctx, nodeExec); err != nil {
 		return fmt.Errorf("failed to create node execution: %w", err)
 	}
+	ctx = context.WithValue(ctx, nodeExecutionKey{}, nodeExec.ID)
 
 	// Publish node execution started event
 	event := events.NewEventBuilder(events.NodeExecutionStarted).
@@ -350,13 +720,32 @@ func (e *WorkflowExecutor) executeNode(ctx context.Context, nodeID string) error
 
 	e.orchestrator.eventBus.Publish(ctx, event)
 
-	// Execute node based on type
-	outputData, err := e.executeNodeByType(ctx, node)
+	// Execute node based on type, bounded by its own timeout if configured
+	outputData, err := e.executeNodeWithTimeout(ctx, node)
 
 	// Update node execution
 	finishedAt := time.Now()
 	nodeExec.FinishedAt = &finishedAt
 
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		// The execution was cancelled while the node ran: keep whatever it
+		// produced out of the execution and flag it as discarded
+		nodeExec.Status = string(workflow.NodeExecutionCancelled)
+		nodeExec.Error = err.Error()
+		nodeExec.OutputData = discardedNodeOutput(e.workflow.RedactNodeOutput(nodeID, outputData))
+
+		e.context.mu.Lock()
+		e.discarded = append(e.discarded, nodeID)
+		e.context.mu.Unlock()
+
+		if manager := e.orchestrator.cancellation; manager != nil {
+			manager.RecordCancelledNode(e.execution.ID, nodeID)
+		}
+
+		e.orchestrator.repository.UpdateNodeExecution(context.WithoutCancel(ctx), nodeExec)
+		return err
+	}
+
 	if err != nil {
 		nodeExec.Status = string(workflow.NodeExecutionFailed)
 		nodeExec.Error = err.Error()
@@ -367,12 +756,28 @@ func (e *WorkflowExecutor) executeNode(ctx context.Context, nodeID string) error
 			time.Sleep(time.Second * 2) // Basic retry delay
 			return e.executeNode(ctx, nodeID)
 		}
+
+		e.context.mu.Lock()
+		e.context.Stats.FailedNodes++
+		if e.shouldContinueOnFail(node) {
+			// Keep the failure visible in the node outputs
+			nodeExec.OutputData = failedNodeOutput(nodeID, err)
+			e.context.NodeOutputs[nodeID] = nodeExec.OutputData
+		}
+		e.context.mu.Unlock()
 	} else {
+		// Large fields are passed on by reference
+		outputData = e.spillOutput(ctx, nodeID, outputData)
+
 		nodeExec.Status = string(workflow.NodeExecutionCompleted)
-		nodeExec.OutputData = outputData
+		// Downstream nodes read the output as it is, the record keeps it
+		// with its sensitive fields redacted
+		nodeExec.OutputData = e.workflow.RedactNodeOutput(nodeID, outputData)
 
 		// Update execution context with output data
 		e.context.mu.Lock()
+		e.context.Stats.CompletedNodes++
+		e.completed = append(e.completed, nodeID)
 		e.context.NodeOutputs[nodeID] = outputData
 		// Merge output into variables for next nodes
 		if outputData != nil {
@@ -385,10 +790,22 @@ func (e *WorkflowExecutor) executeNode(ctx context.Context, nodeID string) error
 
 	e.orchestrator.repository.UpdateNodeExecution(ctx, nodeExec)
 
+	// Written for debug runs whatever the level of the service
+	logger.FromContext(ctx, e.orchestrator.logger).Debug("Node executed",
+		"executionId", e.execution.ID,
+		"nodeId", nodeID,
+		"nodeType", node.Type,
+		"status", nodeExec.Status,
+		"input", nodeExec.InputData,
+		"output", nodeExec.OutputData,
+	)
+
 	// Publish node execution completed event
 	event = events.NewEventBuilder(events.NodeExecutionCompleted).
 		WithAggregateID(nodeExec.ID).
 		WithAggregateType("node_execution").
+		WithPayload("executionId", e.execution.ID).
+		WithPayload("nodeId", nodeID).
 		WithPayload("status", nodeExec.Status).
 		Build()
 
@@ -397,6 +814,51 @@ func (e *WorkflowExecutor) executeNode(ctx context.Context, nodeID string) error
 	return err
 }
 
+// nodeExecutionKey carries the ID of the node execution a node runs as, the
+// log lines of the node are filed under it
+type nodeExecutionKey struct{}
+
+func nodeExecutionID(ctx context.Context) string {
+	id, _ := ctx.Value(nodeExecutionKey{}).(string)
+	return id
+}
+
+// executeNodeWithTimeout runs a node under its effective timeout and keeps
+// the cancellation manager's node timer in step with the node's lifetime
+func (e *WorkflowExecutor) executeNodeWithTimeout(ctx context.Context, node *workflow.Node) (map[string]interface{}, error) {
+	timeout := e.workflow.NodeTimeout(node.ID)
+	if timeout <= 0 {
+		return e.executeNodeByType(ctx, node)
+	}
+
+	if manager := e.orchestrator.cancellation; manager != nil {
+		manager.StartNodeTimer(e.execution.ID, node.ID)
+		defer manager.StopNodeTimer(e.execution.ID, node.ID)
+	}
+
+	nodeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	outputData, err := e.executeNodeByType(nodeCtx, node)
+	if err != nil && errors.Is(nodeCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, fmt.Errorf("%w: node %s exceeded %s", workflow.ErrNodeTimeout, node.ID, timeout)
+	}
+
+	return outputData, err
+}
+
+// timeoutCause classifies an execution error as a node or execution timeout
+func timeoutCause(err error) string {
+	switch {
+	case errors.Is(err, workflow.ErrNodeTimeout):
+		return "node_timeout"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "execution_timeout"
+	default:
+		return ""
+	}
+}
+
 func (e *WorkflowExecutor) executeNodeByType(ctx context.Context, node *workflow.Node) (map[string]interface{}, error) {
 	switch node.Type {
 	case workflow.NodeTypeTrigger:
@@ -409,6 +871,12 @@ func (e *WorkflowExecutor) executeNodeByType(ctx context.Context, node *workflow
 		return e.executeConditionNode(ctx, node)
 	case workflow.NodeTypeLoop:
 		return e.executeLoopNode(ctx, node)
+	case workflow.NodeTypeMerge:
+		return e.executeMergeNode(ctx, node)
+	case workflow.NodeTypeTransform:
+		return e.executeTransformNode(ctx, node)
+	case workflow.NodeTypeMetric:
+		return e.executeMetricNode(ctx, node)
 	default:
 		// Send to executor service for processing
 		return e.sendToExecutorService(ctx, node)
@@ -417,10 +885,7 @@ func (e *WorkflowExecutor) executeNodeByType(ctx context.Context, node *workflow
 
 func (e *WorkflowExecutor) executeTriggerNode(ctx context.Context, node *workflow.Node) (map[string]interface{}, error) {
 	// Trigger nodes just pass through the input data
-	e.context.mu.RLock()
-	data := e.context.Variables
-	e.context.mu.RUnlock()
-	return data, nil
+	return e.variablesSnapshot(), nil
 }
 
 func (e *WorkflowExecutor) executeHTTPNode(ctx context.Context, node *workflow.Node) (map[string]interface{}, error) {
@@ -442,25 +907,54 @@ func (e *WorkflowExecutor) executeCodeNode(ctx context.Context, node *workflow.N
 
 func (e *WorkflowExecutor) executeConditionNode(ctx context.Context, node *workflow.Node) (map[string]interface{}, error) {
 	// Evaluate condition and determine next path
-	e.context.mu.RLock()
-	data := e.context.Variables
-	e.context.mu.RUnlock()
-	return data, nil
+	return e.variablesSnapshot(), nil
 }
 
 func (e *WorkflowExecutor) executeLoopNode(ctx context.Context, node *workflow.Node) (map[string]interface{}, error) {
 	// Execute loop logic
-	e.context.mu.RLock()
-	data := e.context.Variables
-	e.context.mu.RUnlock()
+	return e.variablesSnapshot(), nil
+}
+
+// executeMetricNode adds the business metrics of the node to the KPIs of
+// the workflow for the day, then passes its input through
+func (e *WorkflowExecutor) executeMetricNode(ctx context.Context, node *workflow.Node) (map[string]interface{}, error) {
+	data := e.variablesSnapshot()
+	resolved, err := workflow.ResolvePayloads(ctx, e.orchestrator.payloads, data)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := workflow.MetricValues(node.Parameters, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	day := execution.KPIDay(time.Now())
+	for name, value := range values {
+		if err := e.orchestrator.repository.RecordKPI(ctx, e.workflow.ID, day, name, value); err != nil {
+			return nil, fmt.Errorf("failed to record metric %s: %w", name, err)
+		}
+	}
 	return data, nil
 }
 
+// executeTransformNode maps the node input through the field mappings of
+// the node
+func (e *WorkflowExecutor) executeTransformNode(ctx context.Context, node *workflow.Node) (map[string]interface{}, error) {
+	transform, err := workflow.ParseTransformParameters(node.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	input, err := e.resolvedInput(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return transform.Apply(input)
+}
+
 func (e *WorkflowExecutor) sendToExecutorService(ctx context.Context, node *workflow.Node) (map[string]interface{}, error) {
 	// Send node to executor service via event bus
-	e.context.mu.RLock()
-	inputData := e.context.Variables
-	e.context.mu.RUnlock()
+	inputData := e.variablesSnapshot()
 
 	requestID := uuid.New().String()
 	ch := e.orchestrator.registerPending(requestID)
@@ -472,7 +966,12 @@ func (e *WorkflowExecutor) sendToExecutorService(ctx context.Context, node *work
 		WithPayload("nodeId", node.ID).
 		WithPayload("nodeType", node.Type).
 		WithPayload("parameters", node.Parameters).
+		WithPayload("secrets", e.nodeSecrets(node)).
+		WithPayload("sensitiveFields", e.workflow.SensitivePaths(node.ID)).
 		WithPayload("inputData", inputData).
+		WithPayload("userId", e.workflow.UserID).
+		WithPayload("nodeExecutionId", nodeExecutionID(ctx)).
+		WithPayload("debug", logger.Debugging(ctx)).
 		Build()
 
 	if err := e.orchestrator.eventBus.Publish(ctx, event); err != nil {
@@ -482,14 +981,78 @@ func (e *WorkflowExecutor) sendToExecutorService(ctx context.Context, node *work
 	// Wait for response
 	select {
 	case result := <-ch:
-		return result, nil
+		e.recordUsage(result)
+		return nodeResultOutput(result)
 	case <-ctx.Done():
+		// Abort the node on the worker instead of leaving it running
+		stop := events.NewEventBuilder(events.NodesStopRequest).
+			WithAggregateID(e.execution.ID).
+			WithPayload("executionId", e.execution.ID).
+			WithPayload("requestId", requestID).
+			WithPayload("reason", ctx.Err().Error()).
+			Build()
+		e.orchestrator.eventBus.Publish(context.WithoutCancel(ctx), stop)
 		return nil, ctx.Err()
 	case <-time.After(10 * time.Second):
 		return nil, fmt.Errorf("timeout waiting for node execution response")
 	}
 }
 
+// nodeSecrets returns the sealed secret variables the parameters of a node
+// reference, the workers open them
+func (e *WorkflowExecutor) nodeSecrets(node *workflow.Node) map[string]string {
+	secrets := map[string]string{}
+	for _, key := range workflow.SecretReferences(node.Parameters) {
+		if sealed, ok := e.secrets[key]; ok {
+			secrets[key] = sealed
+		}
+	}
+	return secrets
+}
+
+// recordUsage adds the resources a worker reports for a node to the usage
+// of the execution
+func (e *WorkflowExecutor) recordUsage(result map[string]interface{}) {
+	raw, ok := result["usage"]
+	if !ok {
+		return
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+	var usage execution.Usage
+	if err := json.Unmarshal(data, &usage); err != nil {
+		e.orchestrator.logger.Warn("Invalid node usage", "executionId", e.execution.ID, "error", err)
+		return
+	}
+
+	e.context.mu.Lock()
+	e.context.Usage.Add(usage)
+	e.context.mu.Unlock()
+}
+
+// nodeResultOutput unwraps a worker result into the node output. Results
+// reporting a failure or cancellation are turned into errors.
+func nodeResultOutput(result map[string]interface{}) (map[string]interface{}, error) {
+	if cancelled, _ := result["cancelled"].(bool); cancelled {
+		return nil, context.Canceled
+	}
+
+	success, ok := result["success"].(bool)
+	if !ok {
+		return result, nil
+	}
+
+	if !success {
+		message, _ := result["error"].(string)
+		return nil, fmt.Errorf("node execution failed: %s", message)
+	}
+
+	output, _ := result["output"].(map[string]interface{})
+	return output, nil
+}
+
 func (e *WorkflowExecutor) buildExecutionGraph() map[string][]string {
 	graph := make(map[string][]string)
 	for _, conn := range e.workflow.Connections {
@@ -521,6 +1084,10 @@ func (e *WorkflowExecutor) handleExecutionError(ctx context.Context, err error)
 
 	e.execution.Status = string(workflow.ExecutionFailed)
 	e.execution.Error = err.Error()
+	cause := timeoutCause(err)
+	if cause != "" {
+		e.execution.Status = string(workflow.ExecutionTimeout)
+	}
 	finishedAt := time.Now()
 	e.execution.FinishedAt = &finishedAt
 	e.execution.ExecutionTime = int64(finishedAt.Sub(e.execution.StartedAt).Milliseconds())
@@ -531,7 +1098,56 @@ func (e *WorkflowExecutor) handleExecutionError(ctx context.Context, err error)
 	event := events.NewEventBuilder(events.ExecutionFailed).
 		WithAggregateID(e.execution.ID).
 		WithAggregateType("execution").
-		WithPayload("error", err.Error()).
+		WithError(err).
+		WithPayload("workflowId", e.workflow.ID).
+		WithPayload("cause", cause).
+		WithPayload("startedAt", e.execution.StartedAt).
+		WithPayload("duration", e.execution.ExecutionTime).
+		Build()
+
+	e.orchestrator.eventBus.Publish(ctx, event)
+}
+
+// handleExecutionCancelled finalizes an execution stopped by a cancellation
+// request and records which node outputs were discarded
+func (e *WorkflowExecutor) handleExecutionCancelled(ctx context.Context, err error) {
+	if transErr := e.stateMachine.Transition(ctx, EventCancel, map[string]interface{}{
+		"reason":    err.Error(),
+		"timestamp": time.Now(),
+	}); transErr != nil {
+		e.orchestrator.logger.Error("Failed to transition to cancelled state", "error", transErr)
+	}
+
+	e.context.mu.RLock()
+	discarded := append([]string(nil), e.discarded...)
+	e.execution.Data = e.workflow.RedactData(e.context.Variables)
+	e.context.mu.RUnlock()
+
+	if e.execution.Data == nil {
+		e.execution.Data = make(map[string]interface{})
+	}
+	e.execution.Data["_discardedNodes"] = discarded
+
+	e.execution.Status = string(workflow.ExecutionCancelled)
+	e.execution.Error = err.Error()
+	finishedAt := time.Now()
+	e.execution.FinishedAt = &finishedAt
+	e.execution.ExecutionTime = int64(finishedAt.Sub(e.execution.StartedAt).Milliseconds())
+
+	e.orchestrator.repository.Update(ctx, e.execution)
+
+	e.orchestrator.logger.Info("Execution cancelled",
+		"executionId", e.execution.ID,
+		"discardedNodes", len(discarded),
+	)
+
+	event := events.NewEventBuilder(events.ExecutionCancelled).
+		WithAggregateID(e.execution.ID).
+		WithAggregateType("execution").
+		WithPayload("workflowId", e.workflow.ID).
+		WithPayload("discardedNodes", discarded).
+		WithPayload("startedAt", e.execution.StartedAt).
+		WithPayload("duration", e.execution.ExecutionTime).
 		Build()
 
 	e.orchestrator.eventBus.Publish(ctx, event)
@@ -550,10 +1166,13 @@ func (e *WorkflowExecutor) completeExecution(ctx context.Context) {
 
 	// Store final data
 	e.context.mu.RLock()
-	e.execution.Data = e.context.Variables
+	e.execution.Data = e.workflow.RedactData(e.context.Variables)
+	stats := e.context.Stats
+	usage := e.context.Usage
 	e.context.mu.RUnlock()
 
 	e.orchestrator.repository.Update(ctx, e.execution)
+	e.cacheResult(ctx)
 
 	// Publish execution completed event
 	event := events.NewEventBuilder(events.ExecutionCompleted).
@@ -561,9 +1180,18 @@ func (e *WorkflowExecutor) completeExecution(ctx context.Context) {
 		WithAggregateType("execution").
 		WithPayload("workflowId", e.workflow.ID).
 		WithPayload("duration", e.execution.ExecutionTime).
+		WithPayload("completedNodes", stats.CompletedNodes).
+		WithPayload("failedNodes", stats.FailedNodes).
+		WithPayload("continuedOnFail", stats.ContinuedOnFail).
+		WithPayload("usage", usage).
+		WithPayload("startedAt", e.execution.StartedAt).
 		Build()
 
 	e.orchestrator.eventBus.Publish(ctx, event)
+
+	if e.orchestrator.sampler != nil {
+		e.orchestrator.sampler.Sample(ctx, e.workflow, e.execution)
+	}
 }
 
 func (o *Orchestrator) monitorExecutions() {
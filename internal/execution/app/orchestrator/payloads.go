@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// SetPayloadStore wires the store node data larger than threshold bytes is
+// spilled to. Nodes then pass references to it, resolved only by the nodes
+// computing on the data.
+func (o *Orchestrator) SetPayloadStore(store workflow.PayloadStore, threshold int64) {
+	o.payloads = store
+	o.payloadThreshold = threshold
+}
+
+// DeletePayloads removes the fields the nodes of a deleted execution
+// spilled
+func (o *Orchestrator) DeletePayloads(ctx context.Context, executionID string) error {
+	return workflow.DeletePayloads(ctx, o.payloads, executionID)
+}
+
+// spillOutput spills the large fields of the output of a node. The output
+// is kept inline when the store fails, the node did run.
+func (e *WorkflowExecutor) spillOutput(ctx context.Context, nodeID string, output map[string]interface{}) map[string]interface{} {
+	store := e.orchestrator.payloads
+	if store == nil {
+		return output
+	}
+
+	spilled, err := workflow.SpillPayloads(ctx, store, output, e.orchestrator.payloadThreshold, func(field string) string {
+		return workflow.PayloadKey(e.execution.ID, nodeID, field)
+	})
+	if err != nil {
+		e.orchestrator.logger.Warn("Failed to spill node output, passing it inline",
+			"executionId", e.execution.ID,
+			"nodeId", nodeID,
+			"error", err,
+		)
+		return output
+	}
+	return spilled
+}
+
+// resolvedInput is the input of a node with its spilled fields read back,
+// for the nodes computing on the data itself
+func (e *WorkflowExecutor) resolvedInput(ctx context.Context) (map[string]interface{}, error) {
+	return workflow.ResolvePayloads(ctx, e.orchestrator.payloads, e.variablesSnapshot())
+}
@@ -0,0 +1,31 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// checkNodeTypes fails when wf uses node types blocked by the policy of its
+// workspace. A policy that cannot be loaded fails the execution, blocked
+// nodes must not run while the database is flaky.
+func (o *Orchestrator) checkNodeTypes(ctx context.Context, wf *workflow.Workflow) error {
+	policy, err := o.repository.GetWorkspacePolicy(ctx, wf.WorkspaceID())
+	if err != nil {
+		return fmt.Errorf("failed to get workspace policy: %w", err)
+	}
+	if policy == nil {
+		return nil
+	}
+
+	if err := policy.CheckNodeTypes(wf); err != nil {
+		o.logger.Warn("Execution refused by workspace node type policy",
+			"workflowId", wf.ID,
+			"workspaceId", policy.WorkspaceID,
+			"error", err,
+		)
+		return err
+	}
+	return nil
+}
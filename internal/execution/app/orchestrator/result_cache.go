@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// cachedExecution returns the execution whose output is cached for the
+// input of an idempotent workflow, nil when the workflow is not idempotent
+// or the input was not run within the TTL
+func (o *Orchestrator) cachedExecution(ctx context.Context, wf *workflow.Workflow, inputData map[string]interface{}) *workflow.WorkflowExecution {
+	if o.redis == nil || wf.ResultCacheTTL() == 0 {
+		return nil
+	}
+	hash, err := workflow.InputHash(inputData)
+	if err != nil {
+		return nil
+	}
+
+	data, err := o.redis.Get(ctx, workflow.ResultCacheKey(wf.ID, hash)).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			o.logger.Error("Failed to read cached workflow result", "workflowId", wf.ID, "error", err)
+		}
+		metrics.ExecutionResultCache.WithLabelValues("execution", "miss").Inc()
+		return nil
+	}
+	var cached workflow.CachedResult
+	if err := json.Unmarshal(data, &cached); err != nil {
+		metrics.ExecutionResultCache.WithLabelValues("execution", "miss").Inc()
+		return nil
+	}
+
+	// The cached run may have been deleted with its retention
+	execution, err := o.repository.GetByID(ctx, cached.ExecutionID)
+	if err != nil {
+		metrics.ExecutionResultCache.WithLabelValues("execution", "miss").Inc()
+		return nil
+	}
+	metrics.ExecutionResultCache.WithLabelValues("execution", "hit").Inc()
+	return execution
+}
+
+// cacheResult caches the output of a completed run of an idempotent
+// workflow for its input
+func (e *WorkflowExecutor) cacheResult(ctx context.Context) {
+	o := e.orchestrator
+	ttl := e.workflow.ResultCacheTTL()
+	if o.redis == nil || ttl == 0 || e.shadow != nil || e.simulation != nil {
+		return
+	}
+	hash, err := workflow.InputHash(e.input)
+	if err != nil {
+		o.logger.Error("Failed to hash workflow input", "executionId", e.execution.ID, "error", err)
+		return
+	}
+
+	data, err := json.Marshal(workflow.CachedResult{
+		ExecutionID: e.execution.ID,
+		Output:      e.execution.Data,
+		CompletedAt: time.Now(),
+	})
+	if err != nil {
+		o.logger.Error("Failed to encode workflow result", "executionId", e.execution.ID, "error", err)
+		return
+	}
+	if err := o.redis.Set(ctx, workflow.ResultCacheKey(e.workflow.ID, hash), data, ttl).Err(); err != nil {
+		o.logger.Error("Failed to cache workflow result", "executionId", e.execution.ID, "error", err)
+		return
+	}
+	metrics.ExecutionResultCache.WithLabelValues("execution", "store").Inc()
+}
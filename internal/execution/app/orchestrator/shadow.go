@@ -0,0 +1,231 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// shadowReplay serves the outputs of nodes a shadow run must not execute
+// because they have side effects
+type shadowReplay struct {
+	mu       sync.Mutex
+	live     map[string]interface{}
+	replayed []string
+	mocked   []string
+}
+
+// output returns the live output of the node, or a mock output when the
+// live run did not produce one
+func (r *shadowReplay) output(node *workflow.Node) map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if output, ok := r.live[node.ID].(map[string]interface{}); ok {
+		r.replayed = append(r.replayed, node.ID)
+		return output
+	}
+
+	r.mocked = append(r.mocked, node.ID)
+	return map[string]interface{}{
+		"mocked":   true,
+		"nodeId":   node.ID,
+		"nodeType": node.Type,
+	}
+}
+
+// shadowExecution starts a shadow run of a finished live execution when the
+// workflow has a running shadow sampling it
+func (o *Orchestrator) shadowExecution(ctx context.Context, live *WorkflowExecutor) {
+	if live.shadow != nil {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	shadow, err := o.repository.GetRunningShadow(ctx, live.workflow.ID)
+	if err != nil {
+		o.logger.Warn("Failed to look up workflow shadow", "workflowId", live.workflow.ID, "error", err)
+		return
+	}
+	if shadow == nil || shadow.ShadowVersion == live.execution.Version || !shadow.Samples(live.execution.ID) {
+		return
+	}
+
+	// Snapshot the live run before the executor is released
+	live.context.mu.RLock()
+	outputs := make(map[string]interface{}, len(live.context.NodeOutputs))
+	for nodeID, output := range live.context.NodeOutputs {
+		outputs[nodeID] = output
+	}
+	live.context.mu.RUnlock()
+
+	comparison := &workflow.ShadowComparison{
+		ShadowID:        shadow.ID,
+		WorkflowID:      live.workflow.ID,
+		LiveExecutionID: live.execution.ID,
+		LiveVersion:     live.execution.Version,
+		ShadowVersion:   shadow.ShadowVersion,
+		LiveStatus:      live.execution.Status,
+		LiveDuration:    live.execution.ExecutionTime,
+	}
+
+	go o.runShadow(ctx, comparison, live.input, live.secrets, live.workflow.Settings.SensitiveFields, outputs)
+}
+
+// runShadow executes the shadow version on the live input and stores how
+// its node outputs diverge from the live ones. The divergences are stored
+// with the fields sensitive in either version redacted.
+func (o *Orchestrator) runShadow(ctx context.Context, comparison *workflow.ShadowComparison, input map[string]interface{}, secrets map[string]string, sensitiveFields map[string][]string, liveOutputs map[string]interface{}) {
+	replay := &shadowReplay{live: liveOutputs}
+	shadowOutputs := map[string]interface{}{}
+
+	wf, err := o.repository.GetWorkflowVersion(ctx, comparison.WorkflowID, comparison.ShadowVersion)
+	if err == nil {
+		err = o.checkNodeTypes(ctx, wf)
+	}
+	if err == nil {
+		variables := make(map[string]interface{}, len(input))
+		for k, v := range input {
+			variables[k] = v
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, time.Duration(wf.Settings.Timeout)*time.Second)
+		executor := &WorkflowExecutor{
+			workflow: wf,
+			execution: &workflow.WorkflowExecution{
+				ID:         uuid.New().String(),
+				WorkflowID: comparison.WorkflowID,
+				Version:    comparison.ShadowVersion,
+				StartedAt:  time.Now(),
+			},
+			orchestrator: o,
+			context: &ExecutionContext{
+				Variables:   variables,
+				NodeOutputs: make(map[string]interface{}),
+				Errors:      []ExecutionErrorDetail{},
+				StartTime:   time.Now(),
+				Metadata:    map[string]string{"mode": "shadow"},
+			},
+			cancelFunc: cancel,
+			shadow:     replay,
+			secrets:    secrets,
+		}
+		executor.context.ExecutionID = executor.execution.ID
+
+		start := time.Now()
+		err = executor.executeNodes(runCtx)
+		cancel()
+		comparison.ShadowDuration = time.Since(start).Milliseconds()
+
+		executor.context.mu.RLock()
+		for nodeID, output := range executor.context.NodeOutputs {
+			shadowOutputs[nodeID] = output
+		}
+		executor.context.mu.RUnlock()
+	}
+
+	comparison.ShadowStatus = string(workflow.ExecutionCompleted)
+	if err != nil {
+		comparison.ShadowStatus = string(workflow.ExecutionFailed)
+		comparison.ShadowError = err.Error()
+	}
+
+	redact := func(outputs map[string]interface{}) map[string]interface{} {
+		outputs = workflow.RedactOutputs(outputs, sensitiveFields)
+		if wf != nil {
+			outputs = wf.RedactNodeOutputs(outputs)
+		}
+		return outputs
+	}
+	comparison.Divergences = workflow.DiffNodeOutputs(redact(liveOutputs), redact(shadowOutputs))
+	if comparison.LiveStatus != comparison.ShadowStatus {
+		comparison.Divergences = append([]workflow.ShadowDivergence{{
+			Kind:   workflow.DivergenceStatusChanged,
+			Live:   comparison.LiveStatus,
+			Shadow: comparison.ShadowStatus,
+		}}, comparison.Divergences...)
+	}
+	comparison.Diverged = len(comparison.Divergences) > 0
+
+	replay.mu.Lock()
+	comparison.ReplayedNodes = append([]string{}, replay.replayed...)
+	comparison.MockedNodes = append([]string{}, replay.mocked...)
+	replay.mu.Unlock()
+
+	comparison.CreatedAt = time.Now()
+	if err := o.repository.CreateShadowComparison(ctx, comparison); err != nil {
+		o.logger.Error("Failed to store shadow comparison",
+			"workflowId", comparison.WorkflowID,
+			"executionId", comparison.LiveExecutionID,
+			"error", err,
+		)
+		return
+	}
+
+	if !comparison.Diverged {
+		return
+	}
+
+	o.logger.Info("Shadow run diverged from live execution",
+		"workflowId", comparison.WorkflowID,
+		"executionId", comparison.LiveExecutionID,
+		"shadowVersion", comparison.ShadowVersion,
+		"divergences", len(comparison.Divergences),
+	)
+
+	event := events.NewEventBuilder(events.ExecutionShadowDiverged).
+		WithAggregateID(comparison.LiveExecutionID).
+		WithAggregateType("execution").
+		WithPayload("workflowId", comparison.WorkflowID).
+		WithPayload("shadowId", comparison.ShadowID).
+		WithPayload("comparisonId", comparison.ID).
+		WithPayload("liveVersion", comparison.LiveVersion).
+		WithPayload("shadowVersion", comparison.ShadowVersion).
+		WithPayload("divergences", len(comparison.Divergences)).
+		Build()
+
+	if err := o.eventBus.Publish(ctx, event); err != nil {
+		o.logger.Warn("Failed to publish shadow divergence event", "error", err)
+	}
+}
+
+// executeShadowNode runs a node of a shadow run. Side-effect free nodes are
+// executed, the others replay the live output. Nothing is persisted or
+// published so the shadow stays invisible outside its comparison.
+func (e *WorkflowExecutor) executeShadowNode(ctx context.Context, node *workflow.Node) error {
+	var outputData map[string]interface{}
+	var err error
+
+	if workflow.IsSideEffectFree(node.Type) {
+		if timeout := e.workflow.NodeTimeout(node.ID); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		outputData, err = e.executeNodeByType(ctx, node)
+	} else {
+		outputData = e.shadow.output(node)
+	}
+
+	e.context.mu.Lock()
+	defer e.context.mu.Unlock()
+
+	if err != nil {
+		e.context.Stats.FailedNodes++
+		if e.shouldContinueOnFail(node) {
+			e.context.NodeOutputs[node.ID] = failedNodeOutput(node.ID, err)
+		}
+		return err
+	}
+
+	e.context.Stats.CompletedNodes++
+	e.context.NodeOutputs[node.ID] = outputData
+	for k, v := range outputData {
+		e.context.Variables[k] = v
+	}
+	return nil
+}
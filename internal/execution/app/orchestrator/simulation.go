@@ -0,0 +1,186 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// simulation holds the mocks of a simulated run and the outcome of its
+// nodes
+type simulation struct {
+	request  *workflow.SimulationRequest
+	mu       sync.Mutex
+	nodes    []workflow.SimulatedNode
+	unmocked []string
+}
+
+// record adds the outcome of a node
+func (s *simulation) record(node workflow.SimulatedNode, unmocked bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nodes = append(s.nodes, node)
+	if unmocked {
+		s.unmocked = append(s.unmocked, node.NodeID)
+	}
+}
+
+// Simulate runs a workflow on an input with its nodes replaced by the
+// mocks of the request, waiting for the run to end. Nodes without side
+// effects run for real unless mocked; the others answer with their mock, a
+// placeholder when unmocked. Like a shadow run nothing is persisted or
+// published, the result is only returned.
+func (o *Orchestrator) Simulate(ctx context.Context, wf *workflow.Workflow, req *workflow.SimulationRequest) (*workflow.SimulationResult, error) {
+	if err := o.checkNodeTypes(ctx, wf); err != nil {
+		return nil, err
+	}
+	if req.Data == nil {
+		req.Data = make(map[string]interface{})
+	}
+	bound, err := o.bindVariables(ctx, wf, nil, req.Data)
+	if err != nil {
+		return nil, err
+	}
+	variables := make(map[string]interface{}, len(bound.input))
+	for k, v := range bound.input {
+		variables[k] = v
+	}
+
+	sim := &simulation{request: req}
+	runCtx, cancel := context.WithTimeout(ctx, time.Duration(wf.Settings.Timeout)*time.Second)
+	defer cancel()
+	executor := &WorkflowExecutor{
+		workflow: wf,
+		execution: &workflow.WorkflowExecution{
+			ID:         uuid.New().String(),
+			WorkflowID: wf.ID,
+			Version:    wf.Version,
+			StartedAt:  time.Now(),
+		},
+		orchestrator: o,
+		context: &ExecutionContext{
+			Variables:   variables,
+			NodeOutputs: make(map[string]interface{}),
+			Errors:      []ExecutionErrorDetail{},
+			StartTime:   time.Now(),
+			Metadata:    map[string]string{"mode": "simulation"},
+		},
+		cancelFunc: cancel,
+		simulation: sim,
+		secrets:    bound.secrets,
+	}
+	executor.context.ExecutionID = executor.execution.ID
+
+	start := time.Now()
+	runErr := executor.executeNodes(runCtx)
+
+	result := &workflow.SimulationResult{
+		WorkflowID: wf.ID,
+		Version:    wf.Version,
+		Status:     string(workflow.ExecutionCompleted),
+		Duration:   time.Since(start).Milliseconds(),
+		Skipped:    []string{},
+	}
+	if runErr != nil {
+		result.Status = string(workflow.ExecutionFailed)
+		result.Error = runErr.Error()
+	}
+
+	executor.context.mu.RLock()
+	result.Output = wf.RedactData(executor.context.Variables)
+	executor.context.mu.RUnlock()
+
+	sim.mu.Lock()
+	result.Nodes = append([]workflow.SimulatedNode{}, sim.nodes...)
+	result.Unmocked = append([]string{}, sim.unmocked...)
+	sim.mu.Unlock()
+
+	for _, node := range wf.Nodes {
+		if !node.Disabled && result.Node(node.ID) == nil {
+			result.Skipped = append(result.Skipped, node.ID)
+		}
+	}
+
+	o.logger.Info("Simulated workflow",
+		"workflowId", wf.ID,
+		"version", wf.Version,
+		"status", result.Status,
+		"nodes", len(result.Nodes),
+		"unmocked", len(result.Unmocked),
+	)
+	return result, nil
+}
+
+// executeSimulatedNode runs a node of a simulation. A mocked node answers
+// with its mock, an unmocked node runs when free of side effects and gets a
+// placeholder output otherwise, or fails in a strict simulation.
+func (e *WorkflowExecutor) executeSimulatedNode(ctx context.Context, node *workflow.Node) error {
+	var outputData map[string]interface{}
+	var err error
+	start := time.Now()
+
+	mock := e.simulation.request.MockFor(node)
+	unmocked := false
+	switch {
+	case mock != nil && mock.Error != "":
+		err = errors.New(mock.Error)
+	case mock != nil:
+		outputData = make(map[string]interface{}, len(mock.Output))
+		for k, v := range mock.Output {
+			outputData[k] = v
+		}
+	case workflow.IsSideEffectFree(node.Type):
+		if timeout := e.workflow.NodeTimeout(node.ID); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		outputData, err = e.executeNodeByType(ctx, node)
+	case e.simulation.request.Strict:
+		err = workflow.ErrNodeNotMocked.WithMessage("node %s of type %s has no mock", node.ID, node.Type)
+	default:
+		unmocked = true
+		outputData = map[string]interface{}{
+			"mocked":   true,
+			"nodeId":   node.ID,
+			"nodeType": node.Type,
+		}
+	}
+
+	outcome := workflow.SimulatedNode{
+		NodeID:   node.ID,
+		NodeType: node.Type,
+		Status:   string(workflow.NodeExecutionCompleted),
+		Mocked:   mock != nil || unmocked,
+		Output:   e.workflow.RedactNodeOutput(node.ID, outputData),
+		Duration: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		outcome.Status = string(workflow.NodeExecutionFailed)
+		outcome.Error = err.Error()
+	}
+	e.simulation.record(outcome, unmocked)
+
+	e.context.mu.Lock()
+	defer e.context.mu.Unlock()
+
+	if err != nil {
+		e.context.Stats.FailedNodes++
+		if e.shouldContinueOnFail(node) {
+			e.context.NodeOutputs[node.ID] = failedNodeOutput(node.ID, err)
+		}
+		return err
+	}
+
+	e.context.Stats.CompletedNodes++
+	e.context.NodeOutputs[node.ID] = outputData
+	for k, v := range outputData {
+		e.context.Variables[k] = v
+	}
+	return nil
+}
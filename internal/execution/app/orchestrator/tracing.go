@@ -0,0 +1,19 @@
+package orchestrator
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates execution and node spans. Node requests sent to executor
+// workers carry the node span through the event metadata.
+var tracer = otel.Tracer("linkflow/execution")
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
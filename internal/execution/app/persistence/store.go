@@ -11,6 +11,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/linkflow-go/pkg/tenant"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -44,6 +46,9 @@ type Checkpoint struct {
 	Timestamp   time.Time              `json:"timestamp"`
 	Version     int                    `json:"version"`
 	Metadata    map[string]interface{} `json:"metadata"`
+
+	// tenantID is the tenant of a checkpoint saved asynchronously
+	tenantID string
 }
 
 // ExecutionState represents the complete state of an execution
@@ -149,6 +154,7 @@ func (s *Store) SaveCheckpoint(ctx context.Context, checkpoint *Checkpoint) erro
 		checkpoint.ID = uuid.New().String()
 	}
 	checkpoint.Timestamp = time.Now()
+	checkpoint.tenantID = tenant.FromContext(ctx)
 
 	// Add to queue for async processing
 	select {
@@ -372,6 +378,10 @@ func (s *Store) processCheckpoints(ctx context.Context) {
 // saveBatch saves a batch of checkpoints
 func (s *Store) saveBatch(ctx context.Context, checkpoints []*Checkpoint) {
 	for _, checkpoint := range checkpoints {
+		ctx := ctx
+		if checkpoint.tenantID != "" {
+			ctx = tenant.WithTenant(ctx, checkpoint.tenantID)
+		}
 		if err := s.SaveCheckpointSync(ctx, checkpoint); err != nil {
 			s.logger.Error("Failed to save checkpoint",
 				"checkpointId", checkpoint.ID,
@@ -422,7 +432,7 @@ func (s *Store) saveToDatabase(ctx context.Context, checkpoint *Checkpoint) erro
 
 // saveToRedis saves a checkpoint to Redis
 func (s *Store) saveToRedis(ctx context.Context, checkpoint *Checkpoint) error {
-	key := fmt.Sprintf("checkpoint:%s:latest", checkpoint.ExecutionID)
+	key := rediskey.Tenant(ctx, "checkpoint", checkpoint.ExecutionID, "latest")
 
 	data, err := json.Marshal(checkpoint)
 	if err != nil {
@@ -434,7 +444,7 @@ func (s *Store) saveToRedis(ctx context.Context, checkpoint *Checkpoint) error {
 
 // getFromRedis gets a checkpoint from Redis
 func (s *Store) getFromRedis(ctx context.Context, executionID string) (*Checkpoint, error) {
-	key := fmt.Sprintf("checkpoint:%s:latest", executionID)
+	key := rediskey.Tenant(ctx, "checkpoint", executionID, "latest")
 
 	data, err := s.redis.Get(ctx, key).Result()
 	if err != nil {
@@ -494,7 +504,7 @@ func (s *Store) getFromDatabase(ctx context.Context, executionID string) (*Check
 func (s *Store) deleteFromRedis(ctx context.Context, checkpointID string) {
 	// Find and delete the checkpoint
 	// This is simplified - in production, would maintain proper index
-	iter := s.redis.Scan(ctx, 0, "checkpoint:*", 0).Iterator()
+	iter := s.redis.Scan(ctx, 0, rediskey.Tenant(ctx, "checkpoint", "*"), 0).Iterator()
 
 	for iter.Next(ctx) {
 		key := iter.Val()
@@ -309,8 +309,10 @@ func (m *Manager) restartExecution(ctx context.Context, task *RecoveryTask) erro
 		"workflowId", state.WorkflowID,
 	)
 
-	// Start new execution with original input
-	_, err := m.orchestrator.ExecuteWorkflow(ctx, state.WorkflowID, state.Context)
+	// Start new execution with the original input the checkpoint holds, on
+	// the version, in the environment and with the bindings the execution
+	// record holds
+	_, err := m.orchestrator.RestartExecution(ctx, task.ExecutionID, state.Context)
 
 	return err
 }
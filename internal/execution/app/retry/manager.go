@@ -257,7 +257,7 @@ func (m *Manager) getOrCreateCircuitBreaker(operationID string) *gobreaker.Circu
 func (m *Manager) triggerErrorWorkflow(ctx context.Context, workflowID string, err error) {
 	event := events.NewEventBuilder("error.workflow.trigger").
 		WithPayload("workflowId", workflowID).
-		WithPayload("error", err.Error()).
+		WithError(err).
 		Build()
 
 	if err := m.eventBus.Publish(ctx, event); err != nil {
@@ -0,0 +1,236 @@
+// Package sampling drops the successful executions of high volume workflows
+// their sampling policy does not store
+package sampling
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/internal/execution/ports"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/linkflow-go/pkg/tenant"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// droppedKey orders the dropped executions of every tenant, as
+	// tenant/workflow/day/execution, by the time they are deleted at
+	droppedKey = "execution:sampling:dropped"
+	pruneLock  = "execution:sampling:prune:lock"
+
+	// dayTTL keeps the first and last execution of a day through the next
+	dayTTL = 48 * time.Hour
+
+	// pruneBatch bounds the executions deleted per run
+	pruneBatch = 500
+)
+
+// Sampler decides which successful executions of a workflow with a sampling
+// policy are stored. Dropped executions stay stored for a grace period, so
+// the cost calculator, backfills and result cache reading them once they
+// complete still find them, then are deleted every interval on one replica
+// at a time.
+type Sampler struct {
+	repo     ports.ExecutionRepository
+	redis    *redis.Client
+	grace    time.Duration
+	interval time.Duration
+	logger   logger.Logger
+	// payloads keeps the fields spilled by the nodes of executions, nil
+	// when nothing is spilled
+	payloads workflow.PayloadStore
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// NewSampler creates a sampler deleting the executions dropped more than
+// grace ago every interval once started
+func NewSampler(repo ports.ExecutionRepository, redis *redis.Client, grace, interval time.Duration, log logger.Logger) *Sampler {
+	return &Sampler{
+		repo:     repo,
+		redis:    redis,
+		grace:    grace,
+		interval: interval,
+		logger:   log,
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// SetPayloadStore deletes the fields spilled by the nodes of dropped
+// executions with them
+func (s *Sampler) SetPayloadStore(store workflow.PayloadStore) {
+	s.payloads = store
+}
+
+// Sample applies the sampling policy of a workflow to one of its completed
+// executions. The first execution of the day is kept, then those within the
+// success rate. The others are dropped, except the latest of the day when
+// the last is kept, which replaces the one kept before it.
+func (s *Sampler) Sample(ctx context.Context, wf *workflow.Workflow, exec *workflow.WorkflowExecution) {
+	policy := wf.Sampling()
+	if policy == nil || exec.Status != string(workflow.ExecutionCompleted) {
+		return
+	}
+	day := workflow.SamplingDay(time.Now())
+
+	if policy.KeepFirstOfDay {
+		key := rediskey.Tenant(ctx, workflow.SamplingKey(wf.ID, day, "first")...)
+		first, err := s.redis.SetNX(ctx, key, exec.ID, dayTTL).Result()
+		if err != nil {
+			s.logger.Error("Failed to sample execution", "executionId", exec.ID, "error", err)
+			return
+		}
+		if first {
+			metrics.ExecutionSampling.WithLabelValues("first").Inc()
+			return
+		}
+	}
+
+	if policy.Sampled(exec.ID) {
+		metrics.ExecutionSampling.WithLabelValues("sampled").Inc()
+		return
+	}
+
+	dropID := exec.ID
+	if policy.KeepLastOfDay {
+		key := rediskey.Tenant(ctx, workflow.SamplingKey(wf.ID, day, "last")...)
+		previous, err := s.redis.SetArgs(ctx, key, exec.ID, redis.SetArgs{Get: true, TTL: dayTTL}).Result()
+		if err != nil && err != redis.Nil {
+			s.logger.Error("Failed to sample execution", "executionId", exec.ID, "error", err)
+			return
+		}
+		metrics.ExecutionSampling.WithLabelValues("last").Inc()
+		if previous == "" {
+			return
+		}
+		dropID = previous
+	}
+
+	if err := s.drop(ctx, wf.ID, dropID, day); err != nil {
+		s.logger.Error("Failed to drop sampled out execution", "executionId", dropID, "error", err)
+		return
+	}
+	metrics.ExecutionSampling.WithLabelValues("dropped").Inc()
+}
+
+// drop schedules the deletion of an execution, it is counted as sampled
+// out once deleted
+func (s *Sampler) drop(ctx context.Context, workflowID, executionID, day string) error {
+	member := strings.Join([]string{tenant.FromContext(ctx), workflowID, day, executionID}, "/")
+	deleteAt := float64(time.Now().Add(s.grace).Unix())
+	return s.redis.ZAdd(ctx, droppedKey, redis.Z{Score: deleteAt, Member: member}).Err()
+}
+
+// Start deletes the dropped executions every interval until Stop
+func (s *Sampler) Start() {
+	s.startOnce.Do(func() {
+		go s.run()
+	})
+}
+
+// Stop ends the background runs and waits for the current one
+func (s *Sampler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.startOnce.Do(func() {
+		close(s.done)
+	})
+	<-s.done
+}
+
+func (s *Sampler) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.stopCh:
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-s.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		if err := s.Tick(ctx); err != nil {
+			s.logger.Error("Failed to delete sampled out executions", "error", err)
+		}
+		cancel()
+	}
+}
+
+// Tick deletes the dropped executions whose grace period is over, unless
+// another replica is doing so
+func (s *Sampler) Tick(ctx context.Context) error {
+	owner := uuid.New().String()
+	ok, err := s.redis.SetNX(ctx, pruneLock, owner, s.interval).Result()
+	if err != nil || !ok {
+		return err
+	}
+	defer s.unlock(owner)
+
+	due, err := s.redis.ZRangeByScore(ctx, droppedKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(time.Now().Unix(), 10),
+		Count: pruneBatch,
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, member := range due {
+		parts := strings.SplitN(member, "/", 4)
+		if len(parts) != 4 {
+			s.redis.ZRem(ctx, droppedKey, member)
+			continue
+		}
+		tenantID, workflowID, day, executionID := parts[0], parts[1], parts[2], parts[3]
+		execCtx := ctx
+		if tenantID != "" {
+			execCtx = tenant.WithTenant(ctx, tenantID)
+		}
+		if err := s.repo.DeleteExecution(execCtx, executionID); err != nil {
+			return err
+		}
+		if err := workflow.DeletePayloads(execCtx, s.payloads, executionID); err != nil {
+			s.logger.Warn("Failed to delete spilled payloads", "executionId", executionID, "error", err)
+		}
+		if err := s.repo.RecordSampledOut(execCtx, workflowID, day); err != nil {
+			return err
+		}
+		if err := s.redis.ZRem(ctx, droppedKey, member).Err(); err != nil {
+			return err
+		}
+	}
+	if len(due) > 0 {
+		s.logger.Info("Deleted sampled out executions", "count", len(due))
+	}
+	return nil
+}
+
+// unlock releases the lock if it is still held by owner
+func (s *Sampler) unlock(owner string) {
+	ctx := context.Background()
+	if held, err := s.redis.Get(ctx, pruneLock).Result(); err == nil && held == owner {
+		s.redis.Del(ctx, pruneLock)
+	}
+}
@@ -2,17 +2,42 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/linkflow-go/internal/execution/app/backfill"
+	"github.com/linkflow-go/internal/execution/app/evidence"
+	"github.com/linkflow-go/internal/execution/app/export"
+	"github.com/linkflow-go/internal/execution/app/logging"
 	"github.com/linkflow-go/internal/execution/app/orchestrator"
 	"github.com/linkflow-go/internal/execution/ports"
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/database"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/quota"
 	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExecutionNotFound = apperrors.New(apperrors.CategoryNotFound, "EXECUTION_NOT_FOUND", "execution not found")
+	ErrEvidenceDisabled  = apperrors.New(apperrors.CategoryInternal, "EVIDENCE_DISABLED", "evidence bundles are not configured")
+	ErrBackfillDisabled  = apperrors.New(apperrors.CategoryInternal, "BACKFILL_DISABLED", "backfills are not configured")
+	ErrWorkflowNotFound  = apperrors.New(apperrors.CategoryNotFound, "WORKFLOW_NOT_FOUND", "workflow not found")
+	ErrExecutionActive   = apperrors.New(apperrors.CategoryConflict, "EXECUTION_ACTIVE", "execution has not finished")
 )
 
 type ExecutionService struct {
 	repo         ports.ExecutionRepository
 	orchestrator *orchestrator.Orchestrator
+	exporter     *export.Exporter
+	evidence     *evidence.Bundler
+	backfills    *backfill.Runner
+	logs         *logging.ExecutionLogger
 	eventBus     events.EventBus
 	redis        *redis.Client
 	logger       logger.Logger
@@ -28,26 +53,302 @@ func NewExecutionService(
 	return &ExecutionService{
 		repo:         repo,
 		orchestrator: orchestrator,
+		exporter:     export.NewExporter(repo, eventBus, logger),
 		eventBus:     eventBus,
 		redis:        redis,
 		logger:       logger,
 	}
 }
 
-func (s *ExecutionService) StartExecution(ctx context.Context, workflowID string, data map[string]interface{}) (string, error) {
-	s.logger.Info("Starting execution", "workflowId", workflowID)
-	execution, err := s.orchestrator.ExecuteWorkflow(ctx, workflowID, data)
+// SetEvidenceBundler enables evidence bundles for auditors
+func (s *ExecutionService) SetEvidenceBundler(bundler *evidence.Bundler) {
+	s.evidence = bundler
+}
+
+// SetBackfillRunner enables backfills of schedule triggers
+func (s *ExecutionService) SetBackfillRunner(runner *backfill.Runner) {
+	s.backfills = runner
+}
+
+// SetExecutionLogger serves the log lines kept for executions
+func (s *ExecutionService) SetExecutionLogger(logs *logging.ExecutionLogger) {
+	s.logs = logs
+}
+
+// StartExecution starts an execution in an environment, the default one of
+// the workflow when environment is empty
+func (s *ExecutionService) StartExecution(ctx context.Context, workflowID, environment string, data map[string]interface{}) (string, error) {
+	s.logger.Info("Starting execution", "workflowId", workflowID, "environment", environment)
+	execution, err := s.orchestrator.ExecuteWorkflow(ctx, workflowID, environment, data)
 	if err != nil {
 		return "", err
 	}
 	return execution.ID, nil
 }
 
+// StartExecutionIdempotent starts an execution unless one was already started
+// for the same idempotency key, in which case the existing execution ID is returned
+func (s *ExecutionService) StartExecutionIdempotent(ctx context.Context, workflowID, environment, idempotencyKey string, data map[string]interface{}) (string, bool, error) {
+	s.logger.Info("Starting execution", "workflowId", workflowID, "environment", environment, "idempotencyKey", idempotencyKey)
+	execution, duplicate, err := s.orchestrator.ExecuteWorkflowIdempotent(ctx, workflowID, environment, idempotencyKey, data)
+	if err != nil {
+		return "", duplicate, err
+	}
+	return execution.ID, duplicate, nil
+}
+
+// SimulateWorkflow runs a workflow, or a version of it, against the mocks of
+// the request and reports how each node behaved. Nothing is stored.
+func (s *ExecutionService) SimulateWorkflow(ctx context.Context, req *workflow.SimulationRequest) (*workflow.SimulationResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var wf *workflow.Workflow
+	var err error
+	if req.Version == 0 {
+		wf, err = s.repo.GetWorkflow(ctx, req.WorkflowID)
+	} else {
+		wf, err = s.repo.GetWorkflowVersion(ctx, req.WorkflowID, req.Version)
+	}
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	wf.ID = req.WorkflowID
+
+	s.logger.Info("Simulating workflow", "workflowId", req.WorkflowID, "version", wf.Version, "mocks", len(req.Mocks))
+	return s.orchestrator.Simulate(ctx, wf, req)
+}
+
+// RunWorkflowTests simulates every test case of a test run and records
+// whether each met its assertions. Runs already finished are left alone.
+func (s *ExecutionService) RunWorkflowTests(ctx context.Context, runID string) (*workflow.WorkflowTestRun, error) {
+	run, err := s.repo.GetTestRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status != workflow.TestRunRunning {
+		return run, nil
+	}
+
+	results := []workflow.TestCaseResult{}
+	wf, err := s.repo.GetWorkflow(ctx, run.WorkflowID)
+	if err == nil && wf.Version != run.Version {
+		wf, err = s.repo.GetWorkflowVersion(ctx, run.WorkflowID, run.Version)
+	}
+	var testCases []*workflow.WorkflowTestCase
+	if err == nil {
+		testCases, err = s.repo.ListTestCases(ctx, run.WorkflowID)
+	}
+	if err != nil {
+		run.Error = err.Error()
+	} else {
+		wf.ID = run.WorkflowID
+		for _, testCase := range testCases {
+			results = append(results, s.runTestCase(ctx, wf, testCase))
+		}
+	}
+
+	run.Finish(results)
+	if err := s.repo.UpdateTestRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to save test run: %w", err)
+	}
+
+	event := events.NewEventBuilder(events.WorkflowTestsCompleted).
+		WithAggregateID(run.WorkflowID).
+		WithPayload("runId", run.ID).
+		WithPayload("workflowId", run.WorkflowID).
+		WithPayload("version", run.Version).
+		WithPayload("status", run.Status).
+		WithPayload("passed", run.Passed).
+		WithPayload("failed", run.Failed).
+		Build()
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		s.logger.Error("Failed to publish test run completion", "runId", run.ID, "error", err)
+	}
+
+	s.logger.Info("Workflow tests run", "runId", run.ID, "workflowId", run.WorkflowID, "status", run.Status, "passed", run.Passed, "failed", run.Failed)
+	return run, nil
+}
+
+// runTestCase simulates a test case on a workflow and checks its assertions
+func (s *ExecutionService) runTestCase(ctx context.Context, wf *workflow.Workflow, testCase *workflow.WorkflowTestCase) workflow.TestCaseResult {
+	result := workflow.TestCaseResult{CaseID: testCase.ID, Name: testCase.Name}
+	start := time.Now()
+	simulated, err := s.orchestrator.Simulate(ctx, wf, testCase.Simulation(wf.Version))
+	result.Duration = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = simulated.Status
+	result.Failures = testCase.Check(simulated)
+	result.Passed = len(result.Failures) == 0
+	return result
+}
+
+// GetExecution returns an execution with its node executions
+func (s *ExecutionService) GetExecution(ctx context.Context, executionID string) (*workflow.WorkflowExecution, error) {
+	execution, err := s.repo.GetByID(ctx, executionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrExecutionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get execution: %w", err)
+	}
+	return execution, nil
+}
+
+// DeleteExecution deletes a finished execution with its node executions and
+// the fields its nodes spilled
+func (s *ExecutionService) DeleteExecution(ctx context.Context, executionID string) error {
+	execution, err := s.GetExecution(ctx, executionID)
+	if err != nil {
+		return err
+	}
+	switch workflow.ExecutionStatus(execution.Status) {
+	case workflow.ExecutionPending, workflow.ExecutionQueued, workflow.ExecutionRunning, workflow.ExecutionPaused:
+		return ErrExecutionActive
+	}
+
+	if err := s.repo.DeleteExecution(ctx, executionID); err != nil {
+		return fmt.Errorf("failed to delete execution: %w", err)
+	}
+	if err := s.orchestrator.DeletePayloads(ctx, executionID); err != nil {
+		s.logger.Warn("Failed to delete spilled payloads", "executionId", executionID, "error", err)
+	}
+	return nil
+}
+
+// GetExecutionLogs returns the log lines of an execution matching filter,
+// oldest first
+func (s *ExecutionService) GetExecutionLogs(ctx context.Context, executionID string, filter logging.LogFilter) ([]*logging.ExecutionLog, error) {
+	if _, err := s.GetExecution(ctx, executionID); err != nil {
+		return nil, err
+	}
+	if s.logs == nil {
+		return []*logging.ExecutionLog{}, nil
+	}
+	return s.logs.GetLogs(ctx, executionID, filter)
+}
+
+// ListExecutions returns the page of executions matching filter, newest
+// first. page.Next is set to the cursor of the following page.
+func (s *ExecutionService) ListExecutions(ctx context.Context, filter ports.ExecutionFilter, page *database.CursorPage) ([]*workflow.WorkflowExecution, int64, error) {
+	return s.repo.ListExecutions(ctx, filter, page)
+}
+
 func (s *ExecutionService) StopExecution(ctx context.Context, executionID string) error {
 	s.logger.Info("Stopping execution", "executionId", executionID)
-	// TODO: Implement stop for specific execution
-	// For now, we'll just log it
-	return nil
+	return s.orchestrator.CancelExecution(ctx, executionID, "stopped by user")
+}
+
+// ExportExecutions renders a watermarked export of the executions in scope
+// and records it with the audit service
+func (s *ExecutionService) ExportExecutions(ctx context.Context, req export.Request) (*export.Result, error) {
+	s.logger.Info("Exporting executions", "exportedBy", req.ExportedBy, "format", req.Format, "scope", req.Scope.String())
+	return s.exporter.Export(ctx, req)
+}
+
+// RequestEvidence starts generating an evidence bundle of a finished
+// execution, the bundle is returned pending
+func (s *ExecutionService) RequestEvidence(ctx context.Context, executionID, requestedBy string) (*execution.EvidenceBundle, error) {
+	if s.evidence == nil {
+		return nil, ErrEvidenceDisabled
+	}
+	exec, err := s.GetExecution(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Bundling execution evidence", "executionId", executionID, "requestedBy", requestedBy)
+	return s.evidence.Request(ctx, exec, requestedBy)
+}
+
+// GetEvidence returns an evidence bundle of an execution
+func (s *ExecutionService) GetEvidence(ctx context.Context, executionID, bundleID string) (*execution.EvidenceBundle, error) {
+	if s.evidence == nil {
+		return nil, ErrEvidenceDisabled
+	}
+	return s.evidence.Get(ctx, executionID, bundleID)
+}
+
+// DownloadEvidence returns a ready evidence bundle with its archive
+func (s *ExecutionService) DownloadEvidence(ctx context.Context, bundleID string) (*execution.EvidenceBundle, error) {
+	if s.evidence == nil {
+		return nil, ErrEvidenceDisabled
+	}
+	return s.evidence.Download(ctx, bundleID)
+}
+
+// StartBackfill starts running a schedule trigger over a past range
+func (s *ExecutionService) StartBackfill(ctx context.Context, req execution.StartBackfillRequest, createdBy string) (*execution.Backfill, error) {
+	if s.backfills == nil {
+		return nil, ErrBackfillDisabled
+	}
+	s.logger.Info("Starting backfill", "triggerId", req.TriggerID, "start", req.Start, "end", req.End)
+	return s.backfills.Create(ctx, req, createdBy)
+}
+
+// GetBackfill returns a backfill with its progress
+func (s *ExecutionService) GetBackfill(ctx context.Context, id string) (*execution.Backfill, error) {
+	if s.backfills == nil {
+		return nil, ErrBackfillDisabled
+	}
+	return s.backfills.Get(ctx, id)
+}
+
+// ListBackfills returns the latest backfills of a workflow, of every
+// workflow when workflowID is empty
+func (s *ExecutionService) ListBackfills(ctx context.Context, workflowID string) ([]*execution.Backfill, error) {
+	if s.backfills == nil {
+		return nil, ErrBackfillDisabled
+	}
+	return s.backfills.List(ctx, workflowID)
+}
+
+// GetCostReport sums the rolled up costs of the executions of the tenant
+// over a range, per workflow, day, node type or team
+func (s *ExecutionService) GetCostReport(ctx context.Context, filter execution.CostReportFilter) (*execution.CostReport, error) {
+	if err := filter.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCostReport(ctx, filter)
+}
+
+// GetExecutionStats summarizes the stored executions of a workflow. When
+// its successful executions are sampled, the stats report the sample rate
+// and how many were not stored.
+func (s *ExecutionService) GetExecutionStats(ctx context.Context, workflowID string) (*execution.Stats, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	stats, err := s.repo.GetExecutionStats(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	dropped, err := s.repo.CountSampledOut(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	successRate := float64(100)
+	if policy := wf.Sampling(); policy != nil {
+		successRate = policy.SuccessRate
+	} else if dropped == 0 {
+		return stats, nil
+	}
+	stats.Sampling = execution.NewSamplingStats(successRate, stats.Successful, dropped, stats.Total)
+	return stats, nil
+}
+
+// CancelBackfill stops starting the executions of a backfill
+func (s *ExecutionService) CancelBackfill(ctx context.Context, id string) (*execution.Backfill, error) {
+	if s.backfills == nil {
+		return nil, ErrBackfillDisabled
+	}
+	return s.backfills.Cancel(ctx, id)
 }
 
 func (s *ExecutionService) HandleWorkflowActivated(ctx context.Context, event events.Event) error {
@@ -62,20 +363,137 @@ func (s *ExecutionService) HandleWorkflowDeactivated(ctx context.Context, event
 	return nil
 }
 
+// HandleWorkflowTestsRequested runs the test cases of a requested test run
+func (s *ExecutionService) HandleWorkflowTestsRequested(ctx context.Context, event events.Event) error {
+	s.logger.Info("Handling workflow tests requested event", "type", event.Type, "id", event.ID)
+
+	runID, _ := event.Payload["runId"].(string)
+	if runID == "" {
+		return fmt.Errorf("missing run id in %s event", event.Type)
+	}
+	_, err := s.RunWorkflowTests(ctx, runID)
+	if errors.Is(err, workflow.ErrTestRunNotFound) {
+		s.logger.Warn("Dropping tests of a deleted test run", "runId", runID)
+		return nil
+	}
+	return err
+}
+
+// HandleExecutionRequested starts an execution requested through the
+// workflow service, under the ID returned to the caller. The execution is
+// pinned to the version and environment of the request, so edits or
+// deployments made since do not change what it runs.
+func (s *ExecutionService) HandleExecutionRequested(ctx context.Context, event events.Event) error {
+	s.logger.Info("Handling execution requested event", "type", event.Type, "id", event.ID)
+
+	executionID, _ := event.Payload["execution_id"].(string)
+	workflowID, _ := event.Payload["workflow_id"].(string)
+	if executionID == "" || workflowID == "" {
+		return fmt.Errorf("missing execution or workflow id in %s event", event.Type)
+	}
+	// A redelivered request was started already
+	if existing, err := s.repo.GetByID(ctx, executionID); err == nil && existing != nil {
+		return nil
+	}
+
+	// Numbers decode from the wire as float64
+	version, _ := event.Payload["version"].(float64)
+	environment, _ := event.Payload["environment"].(string)
+	data, _ := event.Payload["input_data"].(map[string]interface{})
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	if debug, _ := event.Payload["debug"].(bool); debug {
+		ctx = logger.WithDebug(ctx)
+	}
+
+	// A request naming no version runs the one its environment routes it to
+	var err error
+	if version == 0 {
+		_, err = s.orchestrator.ExecuteWorkflowAs(ctx, executionID, workflowID, environment, data)
+	} else {
+		_, err = s.orchestrator.ExecuteWorkflowVersion(ctx, executionID, workflowID, environment, int(version), data)
+	}
+	// Requests refused by the plan, for a version gone or a workflow
+	// blocking its node types would be refused again
+	if errors.Is(err, quota.ErrQuotaExceeded) || errors.Is(err, workflow.ErrVersionUnavailable) ||
+		errors.Is(err, workflow.ErrNodeTypeBlocked) || errors.Is(err, workflow.ErrEnvironmentNotFound) {
+		s.logger.Warn("Dropping execution request", "executionId", executionID, "workflowId", workflowID, "version", int(version), "error", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start execution: %w", err)
+	}
+	return nil
+}
+
 func (s *ExecutionService) HandleTriggerFired(ctx context.Context, event events.Event) error {
 	s.logger.Info("Handling trigger fired event", "type", event.Type, "id", event.ID)
-	// Handle trigger fired logic
-	return nil
+
+	workflowID, _ := event.Payload["workflow_id"].(string)
+	return s.startFromEvent(ctx, event, workflowID)
 }
 
 func (s *ExecutionService) HandleWebhookReceived(ctx context.Context, event events.Event) error {
 	s.logger.Info("Handling webhook received event", "type", event.Type, "id", event.ID)
-	// Handle webhook received logic
-	return nil
+
+	workflowID, _ := event.Payload["workflowId"].(string)
+	return s.startFromEvent(ctx, event, workflowID)
 }
 
 func (s *ExecutionService) HandleScheduleTriggered(ctx context.Context, event events.Event) error {
 	s.logger.Info("Handling schedule triggered event", "type", event.Type, "id", event.ID)
-	// Handle schedule triggered logic
+
+	workflowID, _ := event.Payload["workflowId"].(string)
+	return s.startFromEvent(ctx, event, workflowID)
+}
+
+// startFromEvent starts an execution for a trigger event. The idempotency key
+// comes from the publisher when provided, otherwise the event ID is used so
+// event-bus redeliveries of the same message are deduplicated.
+func (s *ExecutionService) startFromEvent(ctx context.Context, event events.Event, workflowID string) error {
+	if workflowID == "" {
+		return fmt.Errorf("missing workflow id in %s event", event.Type)
+	}
+
+	idempotencyKey, _ := event.Payload["idempotencyKey"].(string)
+	if idempotencyKey == "" {
+		idempotencyKey = event.ID
+	}
+
+	data, _ := event.Payload["data"].(map[string]interface{})
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	// Triggers bound to an environment start executions in it
+	environment, _ := event.Payload["environment"].(string)
+
+	executionID, duplicate, err := s.StartExecutionIdempotent(ctx, workflowID, environment, idempotencyKey, data)
+	if errors.Is(err, orchestrator.ErrExecutionInProgress) {
+		s.logger.Info("Skipping duplicate trigger delivery", "workflowId", workflowID, "idempotencyKey", idempotencyKey)
+		return nil
+	}
+	// Redelivering a trigger refused by the plan of its tenant would be
+	// refused again
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		s.logger.Warn("Dropping trigger refused by tenant quota", "workflowId", workflowID, "error", err)
+		return nil
+	}
+	if errors.Is(err, workflow.ErrEnvironmentNotFound) || errors.Is(err, workflow.ErrEnvironmentNotDeployed) {
+		s.logger.Warn("Dropping trigger for an environment without a deployment", "workflowId", workflowID, "environment", environment, "error", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start execution: %w", err)
+	}
+
+	if duplicate {
+		s.logger.Info("Skipping duplicate trigger delivery",
+			"workflowId", workflowID,
+			"idempotencyKey", idempotencyKey,
+			"executionId", executionID,
+		)
+	}
 	return nil
 }
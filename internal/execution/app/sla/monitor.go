@@ -0,0 +1,390 @@
+// Package sla holds the executions of workflows to their SLAs and alerts
+// when an objective is breached
+package sla
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/internal/execution/ports"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/linkflow-go/pkg/tenant"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	evaluateLock = "execution:sla:evaluate:lock"
+
+	// outcomesTTL keeps the counts of a minute through the longest window
+	outcomesTTL = (workflow.MaxSLAWindow + 1) * time.Minute
+	// lastTTL forgets the last execution of workflows idle for a month
+	lastTTL = 30 * 24 * time.Hour
+	// statusTTL drops the status of SLAs no longer evaluated
+	statusTTL = 24 * time.Hour
+	// countedTTL is how long redeliveries of an execution are not counted
+	// again
+	countedTTL = 48 * time.Hour
+)
+
+// Monitor measures the executions of the workflows with an SLA in Redis,
+// on every replica, and evaluates the SLAs every interval on one replica at
+// a time. Each objective breached or met again is published once, as
+// sla.breached or sla.recovered. SLAs set or removed are picked up by the
+// next interval.
+type Monitor struct {
+	repo     ports.ExecutionRepository
+	redis    *redis.Client
+	eventBus events.EventBus
+	interval time.Duration
+	logger   logger.Logger
+
+	mu        sync.RWMutex
+	monitored map[string]bool
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// NewMonitor creates a monitor evaluating the SLAs every interval once
+// started
+func NewMonitor(repo ports.ExecutionRepository, redis *redis.Client, eventBus events.EventBus, interval time.Duration, log logger.Logger) *Monitor {
+	return &Monitor{
+		repo:      repo,
+		redis:     redis,
+		eventBus:  eventBus,
+		interval:  interval,
+		logger:    log,
+		monitored: map[string]bool{},
+		stopCh:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start measures the executions and evaluates the SLAs until Stop
+func (m *Monitor) Start(ctx context.Context) error {
+	if _, err := m.refresh(ctx); err != nil {
+		m.logger.Warn("Failed to load SLAs", "error", err)
+	}
+
+	subscriptions := map[string]events.HandlerFunc{
+		events.ExecutionStarted:   m.handleStarted,
+		events.ExecutionCompleted: m.handleFinished,
+		events.ExecutionFailed:    m.handleFinished,
+	}
+	for eventType, handler := range subscriptions {
+		if err := m.eventBus.Subscribe(eventType, handler); err != nil {
+			return err
+		}
+	}
+
+	m.startOnce.Do(func() {
+		go m.run()
+	})
+	return nil
+}
+
+// Stop ends the background evaluations and waits for the current one
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+	})
+	m.startOnce.Do(func() {
+		close(m.done)
+	})
+	<-m.done
+}
+
+func (m *Monitor) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.stopCh:
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-m.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		if err := m.Tick(ctx); err != nil {
+			m.logger.Error("Failed to evaluate SLAs", "error", err)
+		}
+		cancel()
+	}
+}
+
+// Tick reloads the SLAs, then evaluates them unless another replica is
+// doing so
+func (m *Monitor) Tick(ctx context.Context) error {
+	slas, err := m.refresh(ctx)
+	if err != nil {
+		return err
+	}
+
+	owner := uuid.New().String()
+	ok, err := m.redis.SetNX(ctx, evaluateLock, owner, m.interval).Result()
+	if err != nil || !ok {
+		return err
+	}
+	defer m.unlock(owner)
+
+	now := time.Now().UTC()
+	for _, sla := range slas {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := m.evaluate(tenant.WithTenant(ctx, sla.TenantID), sla, now); err != nil {
+			m.logger.Error("Failed to evaluate SLA", "workflowId", sla.WorkflowID, "error", err)
+		}
+	}
+	return nil
+}
+
+// refresh loads the SLAs of every tenant and the workflows they monitor
+func (m *Monitor) refresh(ctx context.Context) ([]*workflow.SLA, error) {
+	slas, err := m.repo.ListSLAs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	monitored := make(map[string]bool, len(slas))
+	for _, sla := range slas {
+		monitored[sla.TenantID+"/"+sla.WorkflowID] = true
+	}
+
+	m.mu.Lock()
+	m.monitored = monitored
+	m.mu.Unlock()
+	return slas, nil
+}
+
+// isMonitored reports whether the workflow of the tenant of ctx has an SLA
+func (m *Monitor) isMonitored(ctx context.Context, workflowID string) bool {
+	tenantID := tenant.FromContext(ctx)
+	if tenantID == "" {
+		tenantID = tenant.DefaultTenant
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.monitored[tenantID+"/"+workflowID]
+}
+
+// handleStarted records when the last execution of a workflow started
+func (m *Monitor) handleStarted(ctx context.Context, event events.Event) error {
+	var payload struct {
+		WorkflowID string `json:"workflowId"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if !m.isMonitored(ctx, payload.WorkflowID) {
+		return nil
+	}
+
+	key := rediskey.Tenant(ctx, workflow.SLALastKey(payload.WorkflowID)...)
+	pipe := m.redis.TxPipeline()
+	pipe.HSet(ctx, key, "startedAt", event.Timestamp.UTC().Format(time.RFC3339Nano))
+	pipe.Expire(ctx, key, lastTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// handleFinished counts a finished execution in the minute it finished,
+// once per execution, and records how long it ran
+func (m *Monitor) handleFinished(ctx context.Context, event events.Event) error {
+	var payload struct {
+		WorkflowID string `json:"workflowId"`
+		Duration   int64  `json:"duration"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if !m.isMonitored(ctx, payload.WorkflowID) {
+		return nil
+	}
+
+	counted := rediskey.Tenant(ctx, "sla", "counted", event.AggregateID)
+	ok, err := m.redis.SetNX(ctx, counted, "1", countedTTL).Result()
+	if err != nil || !ok {
+		return err
+	}
+
+	outcomes := rediskey.Tenant(ctx, workflow.SLAOutcomesKey(payload.WorkflowID, workflow.SLAMinute(event.Timestamp))...)
+	last := rediskey.Tenant(ctx, workflow.SLALastKey(payload.WorkflowID)...)
+	pipe := m.redis.TxPipeline()
+	pipe.HIncrBy(ctx, outcomes, "finished", 1)
+	if event.Type == events.ExecutionFailed {
+		pipe.HIncrBy(ctx, outcomes, "failed", 1)
+	}
+	pipe.Expire(ctx, outcomes, outcomesTTL)
+	pipe.HSet(ctx, last, "durationMs", payload.Duration)
+	pipe.Expire(ctx, last, lastTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		m.redis.Del(ctx, counted)
+		return err
+	}
+	return nil
+}
+
+// evaluate compares the measures of the workflow of an SLA with its
+// objectives, stores the status and publishes the objectives whose state
+// changed since the previous evaluation
+func (m *Monitor) evaluate(ctx context.Context, sla *workflow.SLA, now time.Time) error {
+	measures, err := m.measure(ctx, sla, now)
+	if err != nil {
+		return err
+	}
+
+	statusKey := rediskey.Tenant(ctx, workflow.SLAStatusKey(sla.WorkflowID)...)
+	var previous *workflow.SLAStatus
+	if data, err := m.redis.Get(ctx, statusKey).Bytes(); err == nil {
+		previous = &workflow.SLAStatus{}
+		if json.Unmarshal(data, previous) != nil {
+			previous = nil
+		}
+	} else if err != redis.Nil {
+		return err
+	}
+
+	status := sla.Evaluate(measures, previous, now)
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if err := m.redis.Set(ctx, statusKey, data, statusTTL).Err(); err != nil {
+		return err
+	}
+
+	for _, objective := range status.Objectives {
+		wasBreached := false
+		if previous != nil {
+			if prev := previous.Objective(objective.Objective); prev != nil {
+				wasBreached = prev.Breached
+			}
+		}
+		switch {
+		case objective.Breached && !wasBreached:
+			m.publish(ctx, events.SLABreached, sla, objective)
+		case !objective.Breached && wasBreached:
+			m.publish(ctx, events.SLARecovered, sla, objective)
+		}
+	}
+	return nil
+}
+
+// measure reads what the executions of the workflow of an SLA did
+func (m *Monitor) measure(ctx context.Context, sla *workflow.SLA, now time.Time) (workflow.SLAMeasures, error) {
+	var measures workflow.SLAMeasures
+
+	last, err := m.redis.HGetAll(ctx, rediskey.Tenant(ctx, workflow.SLALastKey(sla.WorkflowID)...)).Result()
+	if err != nil {
+		return measures, err
+	}
+	if startedAt, err := time.Parse(time.RFC3339Nano, last["startedAt"]); err == nil {
+		measures.LastStartedAt = startedAt
+	}
+	measures.LongestDurationMs, _ = strconv.ParseInt(last["durationMs"], 10, 64)
+
+	if sla.MaxDurationMs > 0 {
+		running, err := m.repo.OldestRunning(ctx, sla.WorkflowID)
+		if err != nil {
+			return measures, err
+		}
+		if running != nil {
+			measures.LongestDurationMs = max(measures.LongestDurationMs, now.Sub(*running).Milliseconds())
+		}
+	}
+
+	if sla.MaxFailureRate > 0 {
+		pipe := m.redis.Pipeline()
+		counts := make([]*redis.SliceCmd, 0, sla.WindowMinutes)
+		for i := 0; i < sla.WindowMinutes; i++ {
+			minute := workflow.SLAMinute(now.Add(-time.Duration(i) * time.Minute))
+			key := rediskey.Tenant(ctx, workflow.SLAOutcomesKey(sla.WorkflowID, minute)...)
+			counts = append(counts, pipe.HMGet(ctx, key, "finished", "failed"))
+		}
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return measures, err
+		}
+		for _, cmd := range counts {
+			values := cmd.Val()
+			if len(values) != 2 {
+				continue
+			}
+			measures.Finished += count(values[0])
+			measures.Failed += count(values[1])
+		}
+	}
+	return measures, nil
+}
+
+// count parses a count read with HMGET, nil when the field is missing
+func count(value interface{}) int64 {
+	s, _ := value.(string)
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+// publish reports an objective of an SLA breached or met again
+func (m *Monitor) publish(ctx context.Context, eventType string, sla *workflow.SLA, objective workflow.SLAObjectiveStatus) {
+	var userID string
+	if wf, err := m.repo.GetWorkflow(ctx, sla.WorkflowID); err == nil {
+		userID = wf.UserID
+	}
+
+	event := events.NewEventBuilder(eventType).
+		WithAggregateID(sla.ID).
+		WithPayload("slaId", sla.ID).
+		WithPayload("workflowId", sla.WorkflowID).
+		WithPayload("userId", userID).
+		WithPayload("updatedBy", sla.UpdatedBy).
+		WithPayload("objective", objective.Objective).
+		WithPayload("target", objective.Target).
+		WithPayload("actual", objective.Actual).
+		WithPayload("since", objective.Since).
+		Build()
+
+	if err := m.eventBus.Publish(ctx, event); err != nil {
+		m.logger.Error("Failed to publish SLA state", "workflowId", sla.WorkflowID, "objective", objective.Objective, "error", err)
+		return
+	}
+
+	if eventType == events.SLABreached {
+		metrics.SLABreaches.WithLabelValues(objective.Objective).Inc()
+		m.logger.Warn("SLA breached",
+			"workflowId", sla.WorkflowID,
+			"objective", objective.Objective,
+			"target", objective.Target,
+			"actual", objective.Actual,
+		)
+		return
+	}
+	m.logger.Info("SLA met again", "workflowId", sla.WorkflowID, "objective", objective.Objective)
+}
+
+// unlock releases the lock if it is still held by owner
+func (m *Monitor) unlock(owner string) {
+	ctx := context.Background()
+	if held, err := m.redis.Get(ctx, evaluateLock).Result(); err == nil && held == owner {
+		m.redis.Del(ctx, evaluateLock)
+	}
+}
@@ -2,15 +2,62 @@ package ports
 
 import (
 	"context"
+	"time"
 
+	"github.com/linkflow-go/pkg/contracts/execution"
 	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/database"
 )
 
 type ExecutionRepository interface {
 	Create(ctx context.Context, execution *workflow.WorkflowExecution) error
 	Update(ctx context.Context, execution *workflow.WorkflowExecution) error
 	GetByID(ctx context.Context, id string) (*workflow.WorkflowExecution, error)
+	ListExecutions(ctx context.Context, filter ExecutionFilter, page *database.CursorPage) ([]*workflow.WorkflowExecution, int64, error)
 	GetWorkflow(ctx context.Context, workflowID string) (*workflow.Workflow, error)
+	LiveWorkflows(ctx context.Context, ids []string) ([]string, error)
+	GetWorkflowVersion(ctx context.Context, workflowID string, version int) (*workflow.Workflow, error)
+	GetEnvironment(ctx context.Context, workflowID, environment string) (*workflow.Environment, error)
+	ListWorkflowVariables(ctx context.Context, workflowID string) ([]*workflow.WorkflowVariable, error)
+	ListSharedVariables(ctx context.Context, teamID string) ([]workflow.SharedVariable, error)
+	GetWorkspacePolicy(ctx context.Context, workspaceID string) (*workflow.WorkspacePolicy, error)
+	GetRunningCanary(ctx context.Context, workflowID string) (*workflow.Canary, error)
+	GetRunningShadow(ctx context.Context, workflowID string) (*workflow.Shadow, error)
+	CreateShadowComparison(ctx context.Context, comparison *workflow.ShadowComparison) error
+	GetTestRun(ctx context.Context, runID string) (*workflow.WorkflowTestRun, error)
+	UpdateTestRun(ctx context.Context, run *workflow.WorkflowTestRun) error
+	ListTestCases(ctx context.Context, workflowID string) ([]*workflow.WorkflowTestCase, error)
 	CreateNodeExecution(ctx context.Context, nodeExec *workflow.NodeExecution) error
 	UpdateNodeExecution(ctx context.Context, nodeExec *workflow.NodeExecution) error
+	ListForExport(ctx context.Context, scope execution.ExportScope, limit int) ([]*workflow.WorkflowExecution, error)
+	GetNodeExecutions(ctx context.Context, executionID string) ([]*workflow.NodeExecution, error)
+	CreateEvidenceBundle(ctx context.Context, bundle *execution.EvidenceBundle) error
+	UpdateEvidenceBundle(ctx context.Context, bundle *execution.EvidenceBundle) error
+	GetEvidenceBundle(ctx context.Context, id string) (*execution.EvidenceBundle, error)
+	GetCredentialUses(ctx context.Context, executionID string) ([]execution.CredentialUse, error)
+	GetApprovals(ctx context.Context, executionID string) ([]execution.Approval, error)
+	GetTrigger(ctx context.Context, triggerID string) (*workflow.WorkflowTrigger, error)
+	CreateBackfill(ctx context.Context, backfill *execution.Backfill) error
+	UpdateBackfill(ctx context.Context, backfill *execution.Backfill) error
+	GetBackfill(ctx context.Context, id string) (*execution.Backfill, error)
+	ListBackfills(ctx context.Context, workflowID string) ([]*execution.Backfill, error)
+	ListRunningBackfills(ctx context.Context) ([]*execution.Backfill, error)
+	RecordKPI(ctx context.Context, workflowID, day, name string, value float64) error
+	SaveExecutionCost(ctx context.Context, cost *execution.ExecutionCost, nodes []*execution.NodeTypeCost) error
+	RollupCosts(ctx context.Context, since time.Time) error
+	GetCostReport(ctx context.Context, filter execution.CostReportFilter) (*execution.CostReport, error)
+	GetExecutionStats(ctx context.Context, workflowID string) (*execution.Stats, error)
+	RecordSampledOut(ctx context.Context, workflowID, day string) error
+	CountSampledOut(ctx context.Context, workflowID string) (int64, error)
+	DeleteExecution(ctx context.Context, id string) error
+	ListSLAs(ctx context.Context) ([]*workflow.SLA, error)
+	OldestRunning(ctx context.Context, workflowID string) (*time.Time, error)
+}
+
+type ExecutionFilter struct {
+	WorkflowID    string
+	Status        string
+	UserID        string
+	StartedAfter  time.Time
+	StartedBefore time.Time
 }
@@ -9,27 +9,65 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/linkflow-go/internal/execution/adapters/db/repository"
 	"github.com/linkflow-go/internal/execution/adapters/http/handlers"
+	executionrpc "github.com/linkflow-go/internal/execution/adapters/rpc"
+	"github.com/linkflow-go/internal/execution/app/backfill"
+	"github.com/linkflow-go/internal/execution/app/cancellation"
+	"github.com/linkflow-go/internal/execution/app/cost"
+	"github.com/linkflow-go/internal/execution/app/evidence"
+	"github.com/linkflow-go/internal/execution/app/logging"
 	"github.com/linkflow-go/internal/execution/app/orchestrator"
+	"github.com/linkflow-go/internal/execution/app/sampling"
 	"github.com/linkflow-go/internal/execution/app/service"
+	"github.com/linkflow-go/internal/execution/app/sla"
+	"github.com/linkflow-go/pkg/apidoc"
+	"github.com/linkflow-go/pkg/auth/signedurl"
 	"github.com/linkflow-go/pkg/config"
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	authmw "github.com/linkflow-go/pkg/middleware/auth"
+	"github.com/linkflow-go/pkg/middleware/correlation"
+	tenantmw "github.com/linkflow-go/pkg/middleware/tenant"
+	"github.com/linkflow-go/pkg/payload"
+	"github.com/linkflow-go/pkg/quota"
+	"github.com/linkflow-go/pkg/redisgc"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/linkflow-go/pkg/rpc"
+	"github.com/linkflow-go/pkg/rpc/executionv1"
+	"github.com/linkflow-go/pkg/telemetry"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
 	config       *config.Config
 	logger       logger.Logger
 	httpServer   *http.Server
+	rpcServer    *grpc.Server
 	db           *database.DB
 	redis        *redis.Client
 	eventBus     events.EventBus
 	orchestrator *orchestrator.WorkflowOrchestrator
+	cancellation *cancellation.Manager
+	execLogger   *logging.ExecutionLogger
+	redisGC      *redisgc.Collector
+	redisQuota   *rediskey.Quota
+	quota        *quota.Enforcer
+	backfills    *backfill.Runner
+	costs        *cost.Calculator
+	costRoller   *cost.Roller
+	sampler      *sampling.Sampler
+	slaMonitor   *sla.Monitor
+	telemetry    *telemetry.Telemetry
 }
 
 func New(cfg *config.Config, log logger.Logger) (*Server, error) {
+	// Initialize tracing
+	tel := telemetry.Setup(cfg.Telemetry, "execution-service", log)
+
 	// Initialize database
 	db, err := database.New(cfg.Database.ToDatabaseConfig())
 	if err != nil {
@@ -49,8 +87,14 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
+	// Bound the Redis commands and memory of each tenant
+	redisLimits := cfg.Redis.TenantQuota
+	redisQuota := rediskey.NewQuota(redisClient, redisLimits.OpsPerSecond, redisLimits.MaxMemoryMB,
+		time.Duration(redisLimits.UsageInterval)*time.Second, log)
+	redisClient.AddHook(redisQuota)
+
 	// Initialize event bus
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	eventBus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
@@ -62,17 +106,110 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	workflowOrchestrator := orchestrator.NewOrchestrator(
 		execRepo, eventBus, redisClient, log,
 	)
+	workflowOrchestrator.SetMaxParallelNodes(cfg.Execution.MaxParallelNodes)
+
+	// Large node data is spilled to the object storage and passed by reference
+	payloads, err := payload.New(cfg.Storage, cfg.Execution.PayloadBucket)
+	if err != nil {
+		log.Warn("Payload spillover disabled", "error", err)
+	} else if payloads != nil {
+		workflowOrchestrator.SetPayloadStore(payloads, cfg.Execution.PayloadSpillBytes)
+	}
+
+	// Initialize cancellation manager for execution and node timeouts
+	cancellationManager := cancellation.NewManager(eventBus, log)
+	workflowOrchestrator.SetCancellationManager(cancellationManager)
+
+	// Plans bound the executions and stored execution data of each tenant
+	enforcer := quota.New(redisClient, cfg.Quota, log)
+	enforcer.MeasureStorage(execRepo.StorageByTenant)
+	workflowOrchestrator.SetQuota(enforcer)
+
+	// Workflows with a sampling policy store a share of their successes
+	sampler := sampling.NewSampler(execRepo, redisClient,
+		time.Duration(cfg.Execution.SamplingGrace)*time.Second,
+		time.Duration(cfg.Execution.SamplingPruneInterval)*time.Second, log)
+	if payloads != nil {
+		sampler.SetPayloadStore(payloads)
+	}
+	workflowOrchestrator.SetSampler(sampler)
+
+	// Workflows are held to their SLAs, breaches are published for alerting
+	slaMonitor := sla.NewMonitor(execRepo, redisClient, eventBus,
+		time.Duration(cfg.Execution.SLAInterval)*time.Second, log)
+
+	// Completed executions are priced for billing
+	var costCalculator *cost.Calculator
+	var costRoller *cost.Roller
+	if cfg.Billing.Enabled {
+		costCalculator = cost.NewCalculator(cost.CostModel{
+			ComputeCostPerSecond: cfg.Billing.ComputeCostPerSecond,
+			MemoryCostPerGB:      cfg.Billing.MemoryCostPerGB,
+			StorageCostPerGB:     cfg.Billing.StorageCostPerGB,
+			NetworkCostPerGB:     cfg.Billing.NetworkCostPerGB,
+			APICallCost:          cfg.Billing.APICallCost,
+			DatabaseQueryCost:    cfg.Billing.DatabaseQueryCost,
+			Currency:             cfg.Billing.Currency,
+		}, eventBus, log)
+		costCalculator.SetOwnerLookup(func(ctx context.Context, workflowID string) (string, string, error) {
+			wf, err := execRepo.GetWorkflow(ctx, workflowID)
+			if err != nil {
+				return "", "", err
+			}
+			return wf.UserID, wf.TeamID, nil
+		})
+		costCalculator.SetBudgetTracker(cost.NewBudgetTracker(redisClient, execRepo.ListBudgets, eventBus, log))
+		costCalculator.SetRepository(execRepo)
+		costRoller = cost.NewRoller(execRepo, redisClient,
+			time.Duration(cfg.Billing.CostRollupInterval)*time.Second, log)
+	}
 
 	// Initialize service
 	execService := service.NewExecutionService(
 		execRepo, workflowOrchestrator, eventBus, redisClient, log,
 	)
 
+	// Execution logs are kept in Redis, with the lines nodes emit
+	execLogger := logging.NewExecutionLogger(redisClient, eventBus, log)
+	execLogger.SetRetention(time.Duration(cfg.Execution.LogRetentionDays) * 24 * time.Hour)
+	execService.SetExecutionLogger(execLogger)
+
+	// Evidence bundles read the execution logs kept in Redis
+	bundler, err := evidence.NewBundler(execRepo, execLogger,
+		cfg.Auth.EvidenceKey, eventBus, log)
+	if err != nil {
+		log.Warn("Execution evidence bundles disabled", "error", err)
+	}
+	execService.SetEvidenceBundler(bundler)
+
+	// Backfills start past fire times of schedule triggers through the orchestrator
+	backfillRunner := backfill.NewRunner(execRepo, workflowOrchestrator, redisClient, log)
+	execService.SetBackfillRunner(backfillRunner)
+
 	// Initialize handlers
 	execHandlers := handlers.NewExecutionHandlers(execService, log)
 
+	// Signed URLs let auditors download evidence bundles without an API token
+	signer, err := signedurl.NewSigner(cfg.Auth.SignedURL.SecretKey, cfg.Auth.SignedURL.BaseURL,
+		time.Duration(cfg.Auth.SignedURL.ExpiryMinutes)*time.Minute)
+	if err != nil {
+		log.Warn("Signed evidence URLs disabled", "error", err)
+	}
+	execHandlers.SetURLSigner(signer)
+
+	// Readiness reports the state of each dependency
+	checker := health.NewChecker("execution-service")
+	checker.Critical("database", health.Database(db))
+	checker.Critical("redis", health.Redis(redisClient))
+	checker.Optional("event_bus", health.EventBus(eventBus))
+
 	// Setup HTTP server
-	router := setupRouter(execHandlers, log)
+	router := setupRouter(execHandlers, signer, tel, checker, log)
+
+	// Serve the OpenAPI document of the routes registered above
+	if err := apidoc.Serve(router, "execution", cfg.Server.StrictAPISpec, log); err != nil {
+		return nil, err
+	}
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -90,36 +227,71 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		return nil, fmt.Errorf("failed to subscribe to node execute responses: %w", err)
 	}
 
+	// Reclaim Redis keys whose owner is gone
+	redisGC := redisgc.New(redisClient, "execution-service",
+		[]redisgc.Rule{workflowOrchestrator.IdempotencyGCRule()},
+		time.Duration(cfg.Redis.GCInterval)*time.Second, log)
+
+	// Executions started and inspected by the other services over gRPC
+	var rpcServer *grpc.Server
+	if cfg.RPC.Port != 0 {
+		rpcServer = rpc.NewServer(cfg.RPC.Token, log)
+		executionv1.RegisterExecutionServiceServer(rpcServer, executionrpc.NewServer(execService))
+	}
+
 	return &Server{
 		config:       cfg,
 		logger:       log,
 		httpServer:   httpServer,
+		rpcServer:    rpcServer,
 		db:           db,
 		redis:        redisClient,
+		redisQuota:   redisQuota,
+		quota:        enforcer,
+		backfills:    backfillRunner,
+		costs:        costCalculator,
+		costRoller:   costRoller,
+		sampler:      sampler,
+		slaMonitor:   slaMonitor,
 		eventBus:     eventBus,
 		orchestrator: workflowOrchestrator,
+		cancellation: cancellationManager,
+		execLogger:   execLogger,
+		redisGC:      redisGC,
+		telemetry:    tel,
 	}, nil
 }
 
-func setupRouter(h *handlers.ExecutionHandlers, log logger.Logger) *gin.Engine {
+func setupRouter(h *handlers.ExecutionHandlers, signer *signedurl.Signer, tel *telemetry.Telemetry, checker *health.Checker, log logger.Logger) *gin.Engine {
 	router := gin.New()
 
-	// Middleware
+	// Middleware, metrics first so recovered panics are counted as 500s
+	router.Use(metrics.HTTPMiddleware("execution-service"))
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
+	router.Use(tel.HTTPMiddleware())
 	router.Use(corsMiddleware())
 	router.Use(loggingMiddleware(log))
-	router.Use(metricsMiddleware())
 
 	// Health checks
 	router.GET("/health/live", h.Health)
-	router.GET("/health/ready", h.Ready)
+	router.GET("/health/ready", checker.ReadyHandler())
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	// Signed evidence downloads authenticate through the URL signature
+	evidenceDownloads := router.Group(apidoc.Prefix + "/executions/evidence")
+	evidenceDownloads.Use(authmw.SignedURLMiddleware(signer))
+	{
+		evidenceDownloads.GET("/:bundleId", h.DownloadEvidence)
+	}
+
 	// API routes
-	v1 := router.Group("/api/v1/executions")
+	v1 := router.Group(apidoc.Prefix + "/executions")
+	v1.Use(tenantmw.Middleware())
 	{
 		v1.GET("", h.ListExecutions)
 		v1.POST("", h.StartExecution)
+		v1.POST("/simulate", h.SimulateExecution)
 		v1.GET("/:id", h.GetExecution)
 		v1.POST("/:id/stop", h.StopExecution)
 		v1.POST("/:id/retry", h.RetryExecution)
@@ -127,13 +299,23 @@ func setupRouter(h *handlers.ExecutionHandlers, log logger.Logger) *gin.Engine {
 		v1.GET("/:id/log", h.GetExecutionLog)
 		v1.GET("/:id/nodes", h.GetNodeExecutions)
 		v1.GET("/stats", h.GetExecutionStats)
+		v1.GET("/export", h.ExportExecutions)
+		v1.POST("/:id/evidence", h.RequestEvidence)
+		v1.GET("/:id/evidence/:bundleId", h.GetEvidence)
+		v1.POST("/backfills", h.StartBackfill)
+		v1.GET("/backfills", h.ListBackfills)
+		v1.GET("/backfills/:backfillId", h.GetBackfill)
+		v1.POST("/backfills/:backfillId/cancel", h.CancelBackfill)
+		v1.GET("/costs", h.GetCostReport)
+		v1.GET("/costs/export", h.ExportCostReport)
 
 		// WebSocket for real-time updates
 		v1.GET("/:id/stream", h.StreamExecution)
 	}
 
 	// Workflow execution triggers
-	triggers := router.Group("/api/v1/trigger")
+	triggers := router.Group(apidoc.Prefix + "/trigger")
+	triggers.Use(tenantmw.Middleware())
 	{
 		triggers.POST("/workflow/:workflowId", h.TriggerWorkflow)
 		triggers.POST("/manual/:workflowId", h.ManualTrigger)
@@ -153,6 +335,15 @@ func subscribeToEvents(eventBus events.EventBus, service *service.ExecutionServi
 		return err
 	}
 
+	if err := eventBus.Subscribe(events.WorkflowTestsRequested, service.HandleWorkflowTestsRequested); err != nil {
+		return err
+	}
+
+	// Executions requested through the workflow service
+	if err := eventBus.Subscribe(events.ExecutionRequested, service.HandleExecutionRequested); err != nil {
+		return err
+	}
+
 	// Subscribe to trigger events
 	if err := eventBus.Subscribe("trigger.fired", service.HandleTriggerFired); err != nil {
 		return err
@@ -170,9 +361,53 @@ func subscribeToEvents(eventBus events.EventBus, service *service.ExecutionServi
 }
 
 func (s *Server) Start() error {
+	// Start cancellation manager
+	if err := s.cancellation.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start cancellation manager: %w", err)
+	}
+
 	// Start orchestrator
 	go s.orchestrator.Start()
 
+	// Record the execution and node logs
+	if err := s.execLogger.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start execution logger: %w", err)
+	}
+
+	// Reclaim the idempotency keys of deleted workflows
+	s.redisGC.Start()
+
+	// Measure the Redis memory of the tenants
+	s.redisQuota.Start()
+
+	// Measure the execution data stored by the tenants
+	s.quota.Start()
+
+	// Start the executions of running backfills
+	s.backfills.Start()
+
+	// Delete the executions dropped by sampling policies
+	s.sampler.Start()
+
+	// Evaluate the SLAs of the workflows
+	if err := s.slaMonitor.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start SLA monitor: %w", err)
+	}
+
+	// Price completed executions
+	if s.costs != nil {
+		if err := s.costs.Start(context.Background()); err != nil {
+			return fmt.Errorf("failed to start cost calculator: %w", err)
+		}
+		s.costRoller.Start()
+	}
+
+	if s.rpcServer != nil {
+		if err := rpc.Serve(s.rpcServer, s.config.RPC.Port, s.logger); err != nil {
+			return err
+		}
+	}
+
 	s.logger.Info("Starting HTTP server", "port", s.config.Server.Port)
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start HTTP server: %w", err)
@@ -185,12 +420,38 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 	// Stop orchestrator
 	s.orchestrator.Stop()
+	s.redisGC.Stop()
+	s.redisQuota.Stop()
+	s.quota.Stop()
+	s.backfills.Stop()
+	s.sampler.Stop()
+	s.slaMonitor.Stop()
+	if s.costs != nil {
+		if err := s.costs.Stop(ctx); err != nil {
+			s.logger.Error("Failed to stop cost calculator", "error", err)
+		}
+		s.costRoller.Stop()
+	}
+
+	// Stop cancellation manager
+	if err := s.cancellation.Stop(ctx); err != nil {
+		s.logger.Error("Failed to stop cancellation manager", "error", err)
+	}
+
+	if err := s.execLogger.Stop(ctx); err != nil {
+		s.logger.Error("Failed to stop execution logger", "error", err)
+	}
 
 	// Shutdown HTTP server
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown HTTP server: %w", err)
 	}
 
+	// Stop gRPC API
+	if s.rpcServer != nil {
+		rpc.Stop(ctx, s.rpcServer)
+	}
+
 	// Close event bus
 	if err := s.eventBus.Close(); err != nil {
 		s.logger.Error("Failed to close event bus", "error", err)
@@ -206,6 +467,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.logger.Error("Failed to close database", "error", err)
 	}
 
+	// Flush pending spans
+	if err := s.telemetry.Close(); err != nil {
+		s.logger.Error("Failed to close telemetry", "error", err)
+	}
+
 	return nil
 }
 
@@ -214,7 +480,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -243,7 +509,7 @@ func loggingMiddleware(log logger.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		log.Info("HTTP Request",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP Request",
 			"method", method,
 			"path", path,
 			"status", statusCode,
@@ -252,19 +518,3 @@ func loggingMiddleware(log logger.Logger) gin.HandlerFunc {
 		)
 	}
 }
-
-func metricsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		start := time.Now()
-
-		c.Next()
-
-		duration := time.Since(start).Seconds()
-		status := c.Writer.Status()
-
-		// Record metrics
-		// This would integrate with Prometheus metrics
-		_ = duration
-		_ = status
-	}
-}
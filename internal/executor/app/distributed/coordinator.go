@@ -8,8 +8,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -24,10 +26,23 @@ type Coordinator struct {
 	eventBus        events.EventBus
 	logger          logger.Logger
 
+	// queued holds the executions assigned to each worker that it has not
+	// started yet, oldest first, the ones work stealing may move
+	queued   map[string][]string
+	handoffs map[string]*handoff // executionID -> pending steal
+
+	// id names the coordinator in the lease, leader tells whether it
+	// holds it
+	id             string
+	leader         atomic.Bool
+	leaseRenewedAt time.Time
+
 	// Configuration
 	rebalanceInterval   time.Duration
 	healthCheckInterval time.Duration
 	maxWorkPerWorker    int
+	handoffTimeout      time.Duration
+	leaseTTL            time.Duration
 
 	// Metrics
 	totalExecutions     int64
@@ -75,6 +90,11 @@ type CoordinatorConfig struct {
 	RebalanceInterval   time.Duration
 	HealthCheckInterval time.Duration
 	MaxWorkPerWorker    int
+	// HandoffTimeout bounds the wait for a worker to acknowledge a steal
+	HandoffTimeout time.Duration
+	// LeaseTTL is how long a coordinator leads without renewing its lease,
+	// and so how long coordination pauses when the leader dies
+	LeaseTTL time.Duration
 }
 
 // NewCoordinator creates a new distributed coordinator
@@ -94,10 +114,18 @@ func NewCoordinator(
 	if config.MaxWorkPerWorker == 0 {
 		config.MaxWorkPerWorker = 100
 	}
+	if config.HandoffTimeout == 0 {
+		config.HandoffTimeout = 10 * time.Second
+	}
+	if config.LeaseTTL == 0 {
+		config.LeaseTTL = 15 * time.Second
+	}
 
 	coord := &Coordinator{
 		workers:             make(map[string]*WorkerNode),
 		partitions:          make(map[string]string),
+		queued:              make(map[string][]string),
+		handoffs:            make(map[string]*handoff),
 		registry:            registry,
 		redis:               redis,
 		eventBus:            eventBus,
@@ -105,6 +133,9 @@ func NewCoordinator(
 		rebalanceInterval:   config.RebalanceInterval,
 		healthCheckInterval: config.HealthCheckInterval,
 		maxWorkPerWorker:    config.MaxWorkPerWorker,
+		handoffTimeout:      config.HandoffTimeout,
+		leaseTTL:            config.LeaseTTL,
+		id:                  uuid.New().String(),
 		stopCh:              make(chan struct{}),
 	}
 
@@ -122,13 +153,10 @@ func (c *Coordinator) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to subscribe to events: %w", err)
 	}
 
-	// Load existing workers from registry
-	if err := c.loadWorkers(ctx); err != nil {
-		c.logger.Error("Failed to load workers from registry", "error", err)
-	}
-
-	// Start background tasks
-	c.wg.Add(3)
+	// Start background tasks, the workers are loaded once the coordinator
+	// takes the lease
+	c.wg.Add(4)
+	go c.leaseLoop(ctx)
 	go c.healthCheckLoop(ctx)
 	go c.rebalanceLoop(ctx)
 	go c.metricsLoop(ctx)
@@ -161,6 +189,8 @@ func (c *Coordinator) Stop(ctx context.Context) error {
 
 // AssignWork assigns work to an appropriate worker
 func (c *Coordinator) AssignWork(ctx context.Context, executionID string, workflowID string, requirements WorkRequirements) (*WorkerNode, error) {
+	startedAt := time.Now()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -171,19 +201,26 @@ func (c *Coordinator) AssignWork(ctx context.Context, executionID string, workfl
 		}
 		// Worker no longer available, reassign
 		delete(c.partitions, executionID)
+		delete(c.handoffs, executionID)
+		c.dequeue(workerID, executionID)
 	}
 
 	// Find suitable worker
 	worker := c.selectWorker(requirements)
 	if worker == nil {
+		atomic.AddInt64(&c.failedDistributions, 1)
+		metrics.RecordAssignment(metricsComponent, "failed", time.Since(startedAt).Seconds())
 		return nil, fmt.Errorf("no suitable worker available")
 	}
 
 	// Assign work
 	c.partitions[executionID] = worker.ID
+	c.enqueue(worker.ID, executionID)
 	worker.CurrentLoad++
 
 	atomic.AddInt64(&c.distributedWork, 1)
+	metrics.RecordAssignment(metricsComponent, "assigned", time.Since(startedAt).Seconds())
+	c.observeWorker(worker)
 
 	// Publish assignment event
 	event := events.NewEventBuilder("work.assigned").
@@ -383,6 +420,8 @@ func (c *Coordinator) UnregisterWorker(ctx context.Context, workerID string) err
 
 	// Remove from workers
 	delete(c.workers, workerID)
+	metrics.ExecutorWorkerLoad.DeleteLabelValues(metricsComponent, workerID)
+	metrics.ExecutorWorkerCapacity.DeleteLabelValues(metricsComponent, workerID)
 
 	// Remove from registry
 	c.registry.Unregister(ctx, workerID)
@@ -416,8 +455,9 @@ func (c *Coordinator) UpdateWorkerHeartbeat(ctx context.Context, workerID string
 	worker.ExecutionsFailed = metrics.ExecutionsFailed
 	worker.AverageExecutionTime = metrics.AverageExecutionTime
 
-	// Update status based on health
-	if worker.Status == WorkerStatusUnhealthy && metrics.Healthy {
+	// Update status based on health, a worker declared offline that comes
+	// back starts over with the work it reports
+	if (worker.Status == WorkerStatusUnhealthy || worker.Status == WorkerStatusOffline) && metrics.Healthy {
 		worker.Status = WorkerStatusActive
 		c.logger.Info("Worker recovered", "workerId", workerID)
 	}
@@ -450,6 +490,10 @@ func (c *Coordinator) performHealthCheck(ctx context.Context) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if !c.leading() {
+		return
+	}
+
 	now := time.Now()
 	unhealthyThreshold := 30 * time.Second
 	offlineThreshold := 60 * time.Second
@@ -464,8 +508,12 @@ func (c *Coordinator) performHealthCheck(ctx context.Context) {
 				worker.Status = WorkerStatusOffline
 				c.logger.Warn("Worker offline", "workerId", worker.ID, "lastSeen", timeSinceHeartbeat)
 
-				// Reassign work
-				go c.reassignWorkFromWorker(ctx, worker.ID)
+				// Reassign work once the health check releases the lock
+				go func(workerID string) {
+					c.mu.Lock()
+					defer c.mu.Unlock()
+					c.reassignWorkFromWorker(ctx, workerID)
+				}(worker.ID)
 			}
 
 		case timeSinceHeartbeat > unhealthyThreshold:
@@ -510,6 +558,10 @@ func (c *Coordinator) performRebalance(ctx context.Context) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if !c.leading() {
+		return
+	}
+
 	// Calculate average load
 	totalCapacity := 0
 	totalLoad := 0
@@ -554,22 +606,16 @@ func (c *Coordinator) performRebalance(ctx context.Context) {
 			"averageLoad", averageLoadPercentage,
 		)
 
-		// Move work from overloaded to underloaded workers
-		// This is simplified - in production, would move actual executions
-		for _, overWorker := range overloaded {
-			for _, underWorker := range underloaded {
-				if overWorker.CurrentLoad > overWorker.Capacity/2 &&
-					underWorker.CurrentLoad < underWorker.Capacity/2 {
-					// Simulate moving work
-					overWorker.CurrentLoad--
-					underWorker.CurrentLoad++
-				}
-			}
-		}
+		// Move queued executions from overloaded to underloaded workers,
+		// the ones already running stay where they are
+		c.stealWork(ctx, overloaded, underloaded, averageLoadPercentage)
+	} else {
+		c.expireHandoffs()
 	}
 }
 
-// reassignWorkFromWorker reassigns work from a specific worker
+// reassignWorkFromWorker reassigns work from a specific worker. Callers must
+// hold c.mu.
 func (c *Coordinator) reassignWorkFromWorker(ctx context.Context, workerID string) {
 	// Find executions assigned to this worker
 	var executionsToReassign []string
@@ -585,6 +631,10 @@ func (c *Coordinator) reassignWorkFromWorker(ctx context.Context, workerID strin
 		"executions", len(executionsToReassign),
 	)
 
+	// Steals from or to the worker will not be acknowledged
+	c.cancelHandoffs(workerID)
+	delete(c.queued, workerID)
+
 	// Reassign each execution
 	for _, execID := range executionsToReassign {
 		delete(c.partitions, execID)
@@ -595,17 +645,7 @@ func (c *Coordinator) reassignWorkFromWorker(ctx context.Context, workerID strin
 		})
 
 		if worker != nil {
-			c.partitions[execID] = worker.ID
-			worker.CurrentLoad++
-
-			// Publish reassignment event
-			event := events.NewEventBuilder("work.reassigned").
-				WithAggregateID(execID).
-				WithPayload("fromWorkerId", workerID).
-				WithPayload("toWorkerId", worker.ID).
-				Build()
-
-			c.eventBus.Publish(ctx, event)
+			c.moveWork(ctx, execID, workerID, worker, ReassignReasonWorkerLost)
 		} else {
 			c.logger.Error("Failed to reassign work - no available workers", "executionId", execID)
 		}
@@ -636,6 +676,10 @@ func (c *Coordinator) reportMetrics(ctx context.Context) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	if !c.leading() {
+		return
+	}
+
 	activeWorkers := 0
 	totalCapacity := 0
 	totalLoad := 0
@@ -659,6 +703,8 @@ func (c *Coordinator) reportMetrics(ctx context.Context) {
 		FailedDistributions: atomic.LoadInt64(&c.failedDistributions),
 	}
 
+	c.exportMetrics()
+
 	// Publish metrics event
 	event := events.NewEventBuilder("coordinator.metrics").
 		WithPayload("metrics", metrics).
@@ -674,30 +720,99 @@ func (c *Coordinator) reportMetrics(ctx context.Context) {
 	)
 }
 
+// metricsComponent labels coordinator series in the shared executor metrics
+const metricsComponent = "coordinator"
+
+// observeWorker updates the Prometheus series of one worker and the running
+// executions gauge. Callers must hold c.mu.
+func (c *Coordinator) observeWorker(worker *WorkerNode) {
+	metrics.ExecutorWorkerLoad.WithLabelValues(metricsComponent, worker.ID).Set(float64(worker.CurrentLoad))
+	metrics.ExecutorWorkerCapacity.WithLabelValues(metricsComponent, worker.ID).Set(float64(worker.Capacity))
+	metrics.ExecutorRunningExecutions.WithLabelValues(metricsComponent).Set(float64(len(c.partitions)))
+}
+
+// exportMetrics refreshes every coordinator gauge. Callers must hold c.mu.
+func (c *Coordinator) exportMetrics() {
+	statuses := map[WorkerStatus]int{
+		WorkerStatusActive:    0,
+		WorkerStatusUnhealthy: 0,
+		WorkerStatusDraining:  0,
+		WorkerStatusOffline:   0,
+	}
+
+	for _, worker := range c.workers {
+		statuses[worker.Status]++
+		c.observeWorker(worker)
+	}
+
+	for status, count := range statuses {
+		metrics.ExecutorWorkers.WithLabelValues(metricsComponent, string(status)).Set(float64(count))
+	}
+	metrics.ExecutorRunningExecutions.WithLabelValues(metricsComponent).Set(float64(len(c.partitions)))
+}
+
 // subscribeToEvents subscribes to relevant events
 func (c *Coordinator) subscribeToEvents(ctx context.Context) error {
 	// Subscribe to worker lifecycle events
-	if err := c.eventBus.Subscribe("worker.heartbeat", c.handleWorkerHeartbeat); err != nil {
+	if err := c.eventBus.Subscribe(EventWorkerHeartbeat, c.handleWorkerHeartbeat); err != nil {
+		return err
+	}
+
+	if err := c.eventBus.Subscribe(EventWorkCompleted, c.handleWorkCompleted); err != nil {
 		return err
 	}
 
-	if err := c.eventBus.Subscribe("work.completed", c.handleWorkCompleted); err != nil {
+	// Subscribe to the work stealing protocol
+	if err := c.eventBus.Subscribe(EventWorkQueued, c.handleWorkQueued); err != nil {
+		return err
+	}
+
+	if err := c.eventBus.Subscribe(EventWorkStarted, c.handleWorkStarted); err != nil {
+		return err
+	}
+
+	if err := c.eventBus.Subscribe(EventHandoffAcknowledged, c.handleHandoffAcknowledged); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// handleWorkerHeartbeat handles worker heartbeat events
+// handleWorkerHeartbeat handles worker heartbeat events, registering the
+// workers the coordinator does not know yet
 func (c *Coordinator) handleWorkerHeartbeat(ctx context.Context, event events.Event) error {
+	if !c.leading() {
+		return nil
+	}
+
 	workerID, _ := event.Payload["workerId"].(string)
+	if workerID == "" {
+		return nil
+	}
 
+	// Numbers decode from the wire as float64
 	metricsData, _ := event.Payload["metrics"].(map[string]interface{})
+	currentLoad, _ := metricsData["currentLoad"].(float64)
+	completed, _ := metricsData["executionsCompleted"].(float64)
+	failed, _ := metricsData["executionsFailed"].(float64)
+	capacity, _ := metricsData["capacity"].(float64)
+	healthy, _ := metricsData["healthy"].(bool)
 	metrics := WorkerMetrics{
-		CurrentLoad:         int(metricsData["currentLoad"].(float64)),
-		ExecutionsCompleted: int64(metricsData["executionsCompleted"].(float64)),
-		ExecutionsFailed:    int64(metricsData["executionsFailed"].(float64)),
-		Healthy:             metricsData["healthy"].(bool),
+		CurrentLoad:         int(currentLoad),
+		ExecutionsCompleted: int64(completed),
+		ExecutionsFailed:    int64(failed),
+		Healthy:             healthy,
+	}
+
+	c.mu.RLock()
+	_, known := c.workers[workerID]
+	c.mu.RUnlock()
+	if !known {
+		address, _ := event.Payload["address"].(string)
+		worker := &WorkerNode{ID: workerID, Address: address, Capacity: int(capacity)}
+		if err := c.RegisterWorker(ctx, worker); err != nil {
+			c.logger.Error("Failed to register worker", "workerId", workerID, "error", err)
+		}
 	}
 
 	return c.UpdateWorkerHeartbeat(ctx, workerID, metrics)
@@ -711,8 +826,14 @@ func (c *Coordinator) handleWorkCompleted(ctx context.Context, event events.Even
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if !c.leading() {
+		return nil
+	}
+
 	// Remove from partitions
 	delete(c.partitions, executionID)
+	delete(c.handoffs, executionID)
+	c.dequeue(workerID, executionID)
 
 	// Update worker load
 	if worker, exists := c.workers[workerID]; exists {
@@ -720,6 +841,7 @@ func (c *Coordinator) handleWorkCompleted(ctx context.Context, event events.Even
 		if worker.CurrentLoad < 0 {
 			worker.CurrentLoad = 0
 		}
+		c.observeWorker(worker)
 	}
 
 	atomic.AddInt64(&c.totalExecutions, 1)
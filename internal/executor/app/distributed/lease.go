@@ -0,0 +1,136 @@
+package distributed
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Every executor instance runs a coordinator, the one holding the lease
+// leads: it tracks the workers, checks their health and steals work. The
+// others ignore the events they receive until they take the lease over.
+const leaseKey = "executor:coordinator:leader"
+
+// renewScript extends the lease if this coordinator still holds it
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript drops the lease if this coordinator still holds it
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// leading reports whether this coordinator holds the lease
+func (c *Coordinator) leading() bool {
+	return c.leader.Load()
+}
+
+// leaseLoop takes the lease when it is free and renews it while it is held
+func (c *Coordinator) leaseLoop(ctx context.Context) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.leaseTTL / 3)
+	defer ticker.Stop()
+
+	c.holdLease(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.stopCh:
+			c.releaseLease()
+			return
+		case <-ticker.C:
+			c.holdLease(ctx)
+		}
+	}
+}
+
+// holdLease takes or renews the lease, and follows the changes of
+// leadership
+func (c *Coordinator) holdLease(ctx context.Context) {
+	var held bool
+	if c.leading() {
+		renewed, err := renewScript.Run(ctx, c.redis, []string{leaseKey}, c.id, c.leaseTTL.Milliseconds()).Int()
+		if err != nil {
+			// Kept until the lease would have expired, another coordinator
+			// cannot take it earlier
+			if time.Since(c.leaseRenewedAt) < c.leaseTTL {
+				c.logger.Warn("Failed to renew coordinator lease", "error", err)
+				return
+			}
+			c.logger.Error("Failed to renew coordinator lease", "error", err)
+		}
+		held = renewed == 1
+	} else {
+		acquired, err := c.redis.SetNX(ctx, leaseKey, c.id, c.leaseTTL).Result()
+		if err != nil {
+			c.logger.Error("Failed to acquire coordinator lease", "error", err)
+			return
+		}
+		held = acquired
+	}
+
+	if held {
+		c.leaseRenewedAt = time.Now()
+	}
+	if held == c.leading() {
+		return
+	}
+
+	if held {
+		c.promote(ctx)
+	} else {
+		c.demote()
+	}
+}
+
+// promote starts leading from the workers of the registry, the executions
+// they hold are learnt from their next events
+func (c *Coordinator) promote(ctx context.Context) {
+	c.mu.Lock()
+	c.leader.Store(true)
+	c.mu.Unlock()
+
+	if err := c.loadWorkers(ctx); err != nil {
+		c.logger.Error("Failed to load workers from registry", "error", err)
+	}
+	c.logger.Info("Leading distributed coordination", "coordinatorId", c.id)
+}
+
+// demote stops leading and forgets the state of the workers, which goes
+// stale while another coordinator leads
+func (c *Coordinator) demote() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.leader.Store(false)
+	c.workers = make(map[string]*WorkerNode)
+	c.partitions = make(map[string]string)
+	c.queued = make(map[string][]string)
+	c.handoffs = make(map[string]*handoff)
+	c.logger.Warn("Lost the coordinator lease", "coordinatorId", c.id)
+}
+
+// releaseLease hands the lease over on shutdown rather than letting it
+// expire
+func (c *Coordinator) releaseLease() {
+	if !c.leading() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := releaseScript.Run(ctx, c.redis, []string{leaseKey}, c.id).Result(); err != nil {
+		c.logger.Warn("Failed to release coordinator lease", "error", err)
+	}
+	c.leader.Store(false)
+}
@@ -0,0 +1,313 @@
+package distributed
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/linkflow-go/pkg/events"
+)
+
+// Work stealing moves executions that are assigned but not started yet from
+// overloaded workers to underloaded ones. The coordinator never takes an
+// execution away on its own: it asks the worker holding it to hand it off,
+// and moves it once the worker acknowledges it released the execution from
+// its local queue. A worker that already started the execution refuses and
+// keeps it.
+const (
+	// EventWorkQueued is published by a worker when it queues the first
+	// request of an execution it did not hold
+	EventWorkQueued = "work.queued"
+	// EventWorkStarted is published by a worker when it starts a queued
+	// execution, which can no longer be stolen
+	EventWorkStarted = "work.started"
+	// EventHandoffRequested asks fromWorkerId to release a queued execution
+	// for toWorkerId
+	EventHandoffRequested = "work.handoff.requested"
+	// EventHandoffAcknowledged answers a handoff request, released tells
+	// whether the worker gave the execution up
+	EventHandoffAcknowledged = "work.handoff.acknowledged"
+	// EventWorkReassigned tells toWorkerId it now owns an execution
+	EventWorkReassigned = "work.reassigned"
+)
+
+// Events of the workers the coordinator follows besides work stealing
+const (
+	// EventWorkerHeartbeat reports the load and health of a worker
+	EventWorkerHeartbeat = "worker.heartbeat"
+	// EventWorkCompleted is published by a worker once it holds no more
+	// requests of an execution
+	EventWorkCompleted = "work.completed"
+)
+
+// Reasons of a reassignment
+const (
+	ReassignReasonWorkerLost = "worker_lost"
+	ReassignReasonStolen     = "stolen"
+)
+
+// handoff is a steal waiting for the acknowledgement of the worker holding
+// the execution
+type handoff struct {
+	executionID string
+	from        string
+	to          string
+	requestedAt time.Time
+}
+
+// enqueue records an execution as queued on a worker. Callers must hold c.mu.
+func (c *Coordinator) enqueue(workerID, executionID string) {
+	c.queued[workerID] = append(c.queued[workerID], executionID)
+}
+
+// dequeue forgets a queued execution, reporting whether it was queued on the
+// worker. Callers must hold c.mu.
+func (c *Coordinator) dequeue(workerID, executionID string) bool {
+	queue := c.queued[workerID]
+	for i, id := range queue {
+		if id == executionID {
+			c.queued[workerID] = append(queue[:i:i], queue[i+1:]...)
+			if len(c.queued[workerID]) == 0 {
+				delete(c.queued, workerID)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// QueuedWork returns the executions assigned to a worker that it has not
+// started yet, oldest first
+func (c *Coordinator) QueuedWork(workerID string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]string(nil), c.queued[workerID]...)
+}
+
+// stealWork requests handoffs of queued executions from the overloaded
+// workers to the underloaded ones until the latter reach the average load.
+// The oldest queued executions move first, they have waited the longest.
+// Callers must hold c.mu.
+func (c *Coordinator) stealWork(ctx context.Context, overloaded, underloaded []*WorkerNode, averageLoad float64) {
+	c.expireHandoffs()
+
+	// Loads as they will be once the requested handoffs complete
+	pending := make(map[string]int)
+	for _, h := range c.handoffs {
+		pending[h.from]--
+		pending[h.to]++
+	}
+	load := func(w *WorkerNode) float64 {
+		return float64(w.CurrentLoad+pending[w.ID]) / float64(w.Capacity)
+	}
+
+	// Fill the idlest workers first, from the busiest ones
+	sort.Slice(underloaded, func(i, j int) bool { return load(underloaded[i]) < load(underloaded[j]) })
+	sort.Slice(overloaded, func(i, j int) bool { return load(overloaded[i]) > load(overloaded[j]) })
+
+	requested := 0
+	for _, to := range underloaded {
+		for _, from := range overloaded {
+			for load(to) < averageLoad && load(from) > averageLoad {
+				executionID := c.stealable(from.ID)
+				if executionID == "" {
+					break
+				}
+
+				c.handoffs[executionID] = &handoff{
+					executionID: executionID,
+					from:        from.ID,
+					to:          to.ID,
+					requestedAt: time.Now(),
+				}
+				pending[from.ID]--
+				pending[to.ID]++
+				requested++
+
+				event := events.NewEventBuilder(EventHandoffRequested).
+					WithAggregateID(executionID).
+					WithPayload("fromWorkerId", from.ID).
+					WithPayload("toWorkerId", to.ID).
+					Build()
+				if err := c.eventBus.Publish(ctx, event); err != nil {
+					c.logger.Error("Failed to request handoff", "executionId", executionID, "error", err)
+					delete(c.handoffs, executionID)
+					pending[from.ID]++
+					pending[to.ID]--
+					requested--
+					return
+				}
+			}
+		}
+	}
+
+	if requested > 0 {
+		c.logger.Info("Requested work handoffs", "executions", requested)
+	}
+}
+
+// stealable returns the oldest queued execution of a worker that is not
+// already being handed off, empty when there is none. Callers must hold c.mu.
+func (c *Coordinator) stealable(workerID string) string {
+	for _, executionID := range c.queued[workerID] {
+		if _, pending := c.handoffs[executionID]; !pending {
+			return executionID
+		}
+	}
+	return ""
+}
+
+// expireHandoffs drops the handoffs not acknowledged in time, the executions
+// stay with the workers holding them. Callers must hold c.mu.
+func (c *Coordinator) expireHandoffs() {
+	for executionID, h := range c.handoffs {
+		if time.Since(h.requestedAt) > c.handoffTimeout {
+			delete(c.handoffs, executionID)
+			c.logger.Warn("Work handoff not acknowledged",
+				"executionId", executionID,
+				"fromWorkerId", h.from,
+				"toWorkerId", h.to,
+			)
+		}
+	}
+}
+
+// cancelHandoffs drops the handoffs from or to a worker that went away.
+// Callers must hold c.mu.
+func (c *Coordinator) cancelHandoffs(workerID string) {
+	for executionID, h := range c.handoffs {
+		if h.from == workerID || h.to == workerID {
+			delete(c.handoffs, executionID)
+		}
+	}
+}
+
+// handleHandoffAcknowledged moves an execution its worker released to the
+// worker stealing it
+func (c *Coordinator) handleHandoffAcknowledged(ctx context.Context, event events.Event) error {
+	executionID := event.AggregateID
+	workerID, _ := event.Payload["workerId"].(string)
+	released, _ := event.Payload["released"].(bool)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.leading() {
+		return nil
+	}
+
+	h, ok := c.handoffs[executionID]
+	if ok && h.from != workerID {
+		// Not the worker that was asked, the request still stands
+		return nil
+	}
+	delete(c.handoffs, executionID)
+
+	if !released {
+		// The worker started the execution meanwhile, it keeps it
+		c.dequeue(workerID, executionID)
+		c.logger.Debug("Work handoff refused", "executionId", executionID, "workerId", workerID)
+		return nil
+	}
+
+	if c.partitions[executionID] != workerID {
+		// Completed or reassigned since the request
+		return nil
+	}
+
+	c.dequeue(workerID, executionID)
+	if from, exists := c.workers[workerID]; exists {
+		from.CurrentLoad = max(from.CurrentLoad-1, 0)
+		c.observeWorker(from)
+	}
+
+	// The execution was released even when the handoff expired, it needs a
+	// new worker either way
+	var to *WorkerNode
+	if ok {
+		if worker, exists := c.workers[h.to]; exists && worker.Status == WorkerStatusActive {
+			to = worker
+		}
+	}
+	if to == nil {
+		to = c.selectWorker(WorkRequirements{SelectionStrategy: SelectionStrategyLeastLoaded})
+	}
+	if to == nil {
+		delete(c.partitions, executionID)
+		atomic.AddInt64(&c.failedDistributions, 1)
+		c.logger.Error("Failed to reassign released work - no available workers", "executionId", executionID)
+		return nil
+	}
+
+	c.moveWork(ctx, executionID, workerID, to, ReassignReasonStolen)
+	return nil
+}
+
+// handleWorkQueued records an execution queued on a worker, which may be
+// stolen until the worker starts it
+func (c *Coordinator) handleWorkQueued(ctx context.Context, event events.Event) error {
+	executionID := event.AggregateID
+	workerID, _ := event.Payload["workerId"].(string)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.leading() || c.partitions[executionID] == workerID {
+		// Already queued there by a reassignment
+		return nil
+	}
+
+	if previous, ok := c.partitions[executionID]; ok {
+		c.dequeue(previous, executionID)
+	}
+	c.partitions[executionID] = workerID
+	c.enqueue(workerID, executionID)
+	if worker, exists := c.workers[workerID]; exists {
+		worker.CurrentLoad++
+		c.observeWorker(worker)
+	}
+	return nil
+}
+
+// handleWorkStarted stops offering an execution a worker started for
+// stealing
+func (c *Coordinator) handleWorkStarted(ctx context.Context, event events.Event) error {
+	workerID, _ := event.Payload["workerId"].(string)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.leading() {
+		return nil
+	}
+
+	c.dequeue(workerID, event.AggregateID)
+	return nil
+}
+
+// moveWork assigns an execution to a new worker, queued there, and tells it.
+// Callers must hold c.mu.
+func (c *Coordinator) moveWork(ctx context.Context, executionID, fromWorkerID string, to *WorkerNode, reason string) {
+	c.partitions[executionID] = to.ID
+	c.enqueue(to.ID, executionID)
+	to.CurrentLoad++
+	c.observeWorker(to)
+
+	event := events.NewEventBuilder(EventWorkReassigned).
+		WithAggregateID(executionID).
+		WithPayload("fromWorkerId", fromWorkerID).
+		WithPayload("toWorkerId", to.ID).
+		WithPayload("reason", reason).
+		Build()
+
+	c.eventBus.Publish(ctx, event)
+
+	c.logger.Info("Work reassigned",
+		"executionId", executionID,
+		"fromWorkerId", fromWorkerID,
+		"toWorkerId", to.ID,
+		"reason", reason,
+	)
+}
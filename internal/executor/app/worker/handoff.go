@@ -0,0 +1,284 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/linkflow-go/internal/executor/app/distributed"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// The pool is a worker of the distributed coordinator: it reports the
+// executions it holds and hands queued ones off when the coordinator steals
+// them. Released requests wait in Redis for the worker stealing them.
+const (
+	// heartbeatInterval is how often the pool reports its load, well within
+	// the 30 seconds after which the coordinator deems a worker unhealthy
+	heartbeatInterval = 10 * time.Second
+	// handoffTTL bounds how long released requests wait for their new
+	// worker, the orchestrator times the nodes out meanwhile
+	handoffTTL = 10 * time.Minute
+	// handoffKeyPrefix prefixes the Redis keys of released requests, by
+	// execution ID
+	handoffKeyPrefix = "executor:handoff:"
+)
+
+// holding is an execution the pool holds requests of. It can be stolen
+// until one of its requests starts.
+type holding struct {
+	queued  []*task
+	active  int
+	started bool
+	failed  bool
+}
+
+// handedOff is a released request as stored for the worker stealing it
+type handedOff struct {
+	Request         NodeExecutionRequest `json:"request"`
+	Secrets         map[string]string    `json:"secrets,omitempty"`
+	SensitiveFields []string             `json:"sensitiveFields,omitempty"`
+	Aggregate       string               `json:"aggregate"`
+	Debug           bool                 `json:"debug,omitempty"`
+}
+
+// hold records a queued request, telling the coordinator about executions
+// the pool did not hold yet
+func (p *Pool) hold(ctx context.Context, t *task) {
+	executionID := t.request.ExecutionID
+
+	p.heldMux.Lock()
+	h, ok := p.held[executionID]
+	if !ok {
+		h = &holding{}
+		p.held[executionID] = h
+	}
+	h.queued = append(h.queued, t)
+	p.heldMux.Unlock()
+
+	if !ok {
+		p.publishWork(ctx, distributed.EventWorkQueued, executionID)
+	}
+}
+
+// start takes a request off the queued ones, false when it was handed off
+// to another worker
+func (p *Pool) start(t *task) bool {
+	executionID := t.request.ExecutionID
+
+	p.heldMux.Lock()
+	if t.released {
+		p.heldMux.Unlock()
+		return false
+	}
+	h := p.held[executionID]
+	for i, queued := range h.queued {
+		if queued == t {
+			h.queued = append(h.queued[:i:i], h.queued[i+1:]...)
+			break
+		}
+	}
+	h.active++
+	first := !h.started
+	h.started = true
+	p.heldMux.Unlock()
+
+	if first {
+		p.publishWork(t.ctx, distributed.EventWorkStarted, executionID)
+	}
+	return true
+}
+
+// finish records a processed request, the execution is completed on this
+// worker once it holds no more of its requests
+func (p *Pool) finish(t *task, success bool) {
+	executionID := t.request.ExecutionID
+
+	p.heldMux.Lock()
+	h := p.held[executionID]
+	h.active--
+	h.failed = h.failed || !success
+	done := h.active == 0 && len(h.queued) == 0
+	if done {
+		delete(p.held, executionID)
+	}
+	p.heldMux.Unlock()
+
+	if !done {
+		return
+	}
+	if h.failed {
+		atomic.AddInt64(&p.executionsFailed, 1)
+	} else {
+		atomic.AddInt64(&p.executionsCompleted, 1)
+	}
+	p.publishWork(t.ctx, distributed.EventWorkCompleted, executionID)
+}
+
+// publishWork reports a change of an execution held by the pool to the
+// coordinator
+func (p *Pool) publishWork(ctx context.Context, eventType, executionID string) {
+	event := events.NewEventBuilder(eventType).
+		WithAggregateID(executionID).
+		WithPayload("executionId", executionID).
+		WithPayload("workerId", p.instance).
+		Build()
+	if err := p.eventBus.Publish(context.WithoutCancel(ctx), event); err != nil {
+		p.logger.Error("Failed to publish work event", "type", eventType, "executionId", executionID, "error", err)
+	}
+}
+
+// handleHandoffRequested releases the queued requests of an execution the
+// coordinator steals, unless one of them started already
+func (p *Pool) handleHandoffRequested(ctx context.Context, event events.Event) error {
+	if from, _ := event.Payload["fromWorkerId"].(string); from != p.instance {
+		return nil
+	}
+	executionID := event.AggregateID
+
+	released, err := p.release(ctx, executionID)
+	if err != nil {
+		p.logger.Error("Failed to hand off work, keeping it", "executionId", executionID, "error", err)
+	}
+
+	ack := events.NewEventBuilder(distributed.EventHandoffAcknowledged).
+		WithAggregateID(executionID).
+		WithPayload("workerId", p.instance).
+		WithPayload("released", released).
+		Build()
+	return p.eventBus.Publish(ctx, ack)
+}
+
+// release takes the queued requests of an execution off the queue and
+// stores them for the worker stealing them. The lock is held while they are
+// stored so no worker starts one meanwhile.
+func (p *Pool) release(ctx context.Context, executionID string) (bool, error) {
+	p.heldMux.Lock()
+	h, ok := p.held[executionID]
+	if !ok || h.started || len(h.queued) == 0 {
+		p.heldMux.Unlock()
+		return false, nil
+	}
+
+	stash := make([]handedOff, 0, len(h.queued))
+	for _, t := range h.queued {
+		stash = append(stash, handedOff{
+			Request:         t.request,
+			Secrets:         t.request.Secrets,
+			SensitiveFields: t.request.SensitiveFields,
+			Aggregate:       t.aggregate,
+			Debug:           t.debug,
+		})
+	}
+	data, err := json.Marshal(stash)
+	if err == nil {
+		err = p.redis.Set(ctx, handoffKeyPrefix+executionID, data, handoffTTL).Err()
+	}
+	if err != nil {
+		p.heldMux.Unlock()
+		return false, err
+	}
+
+	released := h.queued
+	for _, t := range released {
+		t.released = true
+	}
+	delete(p.held, executionID)
+	p.heldMux.Unlock()
+
+	// The queue drops released requests as the workers reach them
+	for _, t := range released {
+		p.untrack(t.request)
+		t.cancel()
+	}
+
+	p.logger.Info("Handed off queued work", "executionId", executionID, "requests", len(released))
+	return true, nil
+}
+
+// handleWorkReassigned queues the requests handed off to this pool. A
+// reassignment away from a lost worker has none, the orchestrator retries
+// its nodes.
+func (p *Pool) handleWorkReassigned(ctx context.Context, event events.Event) error {
+	if to, _ := event.Payload["toWorkerId"].(string); to != p.instance {
+		return nil
+	}
+	executionID := event.AggregateID
+
+	data, err := p.redis.GetDel(ctx, handoffKeyPrefix+executionID).Bytes()
+	if err == redis.Nil {
+		p.logger.Debug("No work handed off", "executionId", executionID, "reason", event.Payload["reason"])
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load handed off work: %w", err)
+	}
+
+	var stash []handedOff
+	if err := json.Unmarshal(data, &stash); err != nil {
+		return fmt.Errorf("failed to decode handed off work: %w", err)
+	}
+
+	for _, s := range stash {
+		request := s.Request
+		request.Secrets = s.Secrets
+		request.SensitiveFields = s.SensitiveFields
+
+		reqCtx := logger.WithFields(context.WithoutCancel(ctx), "executionId", request.ExecutionID, "nodeId", request.NodeID)
+		if s.Debug {
+			reqCtx = logger.WithDebug(reqCtx)
+		}
+		if err := p.enqueue(reqCtx, request, s.Aggregate, s.Debug); err != nil {
+			return err
+		}
+	}
+
+	p.logger.Info("Accepted handed off work",
+		"executionId", executionID,
+		"fromWorkerId", event.Payload["fromWorkerId"],
+		"requests", len(stash),
+	)
+	return nil
+}
+
+// heartbeat reports the load of the pool to the coordinator until the pool
+// stops
+func (p *Pool) heartbeat() {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	p.publishHeartbeat()
+	for {
+		select {
+		case <-ticker.C:
+			p.publishHeartbeat()
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+func (p *Pool) publishHeartbeat() {
+	p.heldMux.Lock()
+	load := len(p.held)
+	p.heldMux.Unlock()
+
+	event := events.NewEventBuilder(distributed.EventWorkerHeartbeat).
+		WithAggregateID(p.instance).
+		WithPayload("workerId", p.instance).
+		WithPayload("metrics", map[string]interface{}{
+			"currentLoad":         load,
+			"capacity":            p.Size(),
+			"executionsCompleted": atomic.LoadInt64(&p.executionsCompleted),
+			"executionsFailed":    atomic.LoadInt64(&p.executionsFailed),
+			"healthy":             true,
+		}).
+		Build()
+	if err := p.eventBus.Publish(context.Background(), event); err != nil {
+		p.logger.Error("Failed to publish worker heartbeat", "error", err)
+	}
+}
@@ -9,23 +9,55 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/linkflow-go/pkg/auth/sealed"
+	"github.com/linkflow-go/pkg/contracts/credential"
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/rpc/credentialv1"
 	"github.com/redis/go-redis/v9"
 )
 
+// maxDebugBody bounds the request and response bodies logged by debug runs
+const maxDebugBody = 64 << 10
+
+// redactedHeaders are not logged by debug runs, they carry secrets
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+}
+
 type NodeExecutor struct {
-	eventBus events.EventBus
-	redis    *redis.Client
-	logger   logger.Logger
-	client   *http.Client
+	eventBus    events.EventBus
+	redis       *redis.Client
+	credentials credentialv1.CredentialServiceClient
+	logger      logger.Logger
+	client      *http.Client
+	// sealer opens secret variables, nil when no encryption key is set
+	sealer *sealed.Sealer
 }
 
 type NodeExecutionRequest struct {
-	NodeID     string                 `json:"nodeId"`
-	NodeType   string                 `json:"nodeType"`
-	Parameters map[string]interface{} `json:"parameters"`
-	InputData  map[string]interface{} `json:"inputData"`
+	RequestID   string                 `json:"requestId"`
+	ExecutionID string                 `json:"executionId"`
+	NodeID      string                 `json:"nodeId"`
+	NodeType    string                 `json:"nodeType"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	InputData   map[string]interface{} `json:"inputData"`
+	// UserID owns the workflow, credentials are resolved on their behalf
+	UserID string `json:"userId,omitempty"`
+	// NodeExecutionID files the log lines of the node
+	NodeExecutionID string `json:"nodeExecutionId,omitempty"`
+	// Credential is the credential named by the credentialId parameter
+	Credential *credentialv1.Credential `json:"-"`
+	// Secrets are the sealed secret variables the parameters reference
+	Secrets map[string]string `json:"-"`
+	// SensitiveFields are the output fields redacted from the node logs
+	SensitiveFields []string `json:"-"`
 }
 
 type NodeExecutionResult struct {
@@ -34,23 +66,82 @@ type NodeExecutionResult struct {
 	Error   string                 `json:"error,omitempty"`
 }
 
-func NewNodeExecutor(eventBus events.EventBus, redis *redis.Client, logger logger.Logger) *NodeExecutor {
+// NewNodeExecutor creates a node executor, credentials may be nil when
+// nodes run without resolving their credentials
+func NewNodeExecutor(eventBus events.EventBus, redis *redis.Client, credentials credentialv1.CredentialServiceClient, logger logger.Logger) *NodeExecutor {
 	return &NodeExecutor{
-		eventBus: eventBus,
-		redis:    redis,
-		logger:   logger,
+		eventBus:    eventBus,
+		redis:       redis,
+		credentials: credentials,
+		logger:      logger,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: &meteredTransport{base: http.DefaultTransport},
 		},
 	}
 }
 
+// SetSealer wires the sealer opening the secret variables nodes reference
+func (e *NodeExecutor) SetSealer(sealer *sealed.Sealer) {
+	e.sealer = sealer
+}
+
 func (e *NodeExecutor) Execute(ctx context.Context, request NodeExecutionRequest) (*NodeExecutionResult, error) {
-	e.logger.Info("Executing node",
-		"nodeId", request.NodeID,
+	// Do not start work for an execution that was already cancelled
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// executionId and nodeId come from the context
+	logger.FromContext(ctx, e.logger).Info("Executing node",
 		"nodeType", request.NodeType,
 	)
 
+	result, err := e.execute(ctx, request)
+	if err == nil && result != nil && !result.Success {
+		e.nodeLog(ctx, request, execution.LogLevelError, result.Error, nil)
+	}
+	return result, err
+}
+
+// nodeLog emits a structured log line of a node, kept with the logs of its
+// execution and streamed to the clients watching it. Debug lines are only
+// emitted by debug runs.
+func (e *NodeExecutor) nodeLog(ctx context.Context, request NodeExecutionRequest, level, message string, data map[string]interface{}) {
+	if level == execution.LogLevelDebug && !logger.Debugging(ctx) {
+		return
+	}
+
+	builder := events.NewEventBuilder(events.NodeLog).
+		WithAggregateID(request.ExecutionID).
+		WithPayload("executionId", request.ExecutionID).
+		WithPayload("nodeId", request.NodeID).
+		WithPayload("nodeExecutionId", request.NodeExecutionID).
+		WithPayload("level", level).
+		WithPayload("message", message)
+	if data != nil {
+		builder = builder.WithPayload("data", data)
+	}
+
+	if err := e.eventBus.Publish(ctx, builder.Build()); err != nil {
+		logger.FromContext(ctx, e.logger).Warn("Failed to publish node log", "error", err)
+	}
+}
+
+func (e *NodeExecutor) execute(ctx context.Context, request NodeExecutionRequest) (*NodeExecutionResult, error) {
+	if err := e.resolveSecrets(&request); err != nil {
+		return &NodeExecutionResult{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to resolve secret variables: %v", err),
+		}, nil
+	}
+	if err := e.resolveCredential(ctx, &request); err != nil {
+		return &NodeExecutionResult{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to resolve credential: %v", err),
+		}, nil
+	}
+
 	switch request.NodeType {
 	case "http-request":
 		return e.executeHTTPRequest(ctx, request)
@@ -75,6 +166,99 @@ func (e *NodeExecutor) Execute(ctx context.Context, request NodeExecutionRequest
 	}
 }
 
+// inputFields returns the fields of its input a node reads, all of them
+// when it computes on the input as a whole. Other nodes pass their input
+// through or do not read it.
+func inputFields(request NodeExecutionRequest) ([]string, bool) {
+	switch request.NodeType {
+	case "code":
+		return nil, true
+	case "transform":
+		if transformType, _ := request.Parameters["type"].(string); transformType != "map" {
+			return nil, false
+		}
+		mapping, _ := request.Parameters["mapping"].(map[string]interface{})
+		fields := make([]string, 0, len(mapping))
+		for _, value := range mapping {
+			if inputKey, ok := value.(string); ok {
+				fields = append(fields, inputKey)
+			}
+		}
+		return fields, false
+	}
+	return nil, false
+}
+
+// resolveSecrets replaces the {{ $secrets.KEY }} references in the
+// parameters with the opened secret variables. Only the parameters of the
+// node are resolved, secrets never enter its input or output.
+func (e *NodeExecutor) resolveSecrets(request *NodeExecutionRequest) error {
+	if len(workflow.SecretReferences(request.Parameters)) == 0 {
+		return nil
+	}
+	if e.sealer == nil {
+		return workflow.ErrSecretsUnavailable
+	}
+
+	parameters, err := workflow.ResolveSecretReferences(request.Parameters, func(key string) (string, error) {
+		sealed, ok := request.Secrets[key]
+		if !ok {
+			return "", fmt.Errorf("not defined")
+		}
+		return e.sealer.Open(sealed)
+	})
+	if err != nil {
+		return err
+	}
+	request.Parameters = parameters
+	return nil
+}
+
+// resolveCredential fetches the credential named by the credentialId
+// parameter from the credential service
+func (e *NodeExecutor) resolveCredential(ctx context.Context, request *NodeExecutionRequest) error {
+	credentialID, _ := request.Parameters["credentialId"].(string)
+	if credentialID == "" || e.credentials == nil {
+		return nil
+	}
+
+	cred, err := e.credentials.ResolveCredential(ctx, &credentialv1.ResolveCredentialRequest{
+		CredentialId: credentialID,
+		UserId:       request.UserID,
+	})
+	if err != nil {
+		return err
+	}
+	request.Credential = cred
+	return nil
+}
+
+// applyCredential authenticates req with the credential of the node
+func applyCredential(req *http.Request, cred *credentialv1.Credential) {
+	if cred == nil {
+		return
+	}
+
+	data := cred.GetData().AsMap()
+	switch cred.GetType() {
+	case credential.TypeAPIKey:
+		apiKey, _ := data["apiKey"].(string)
+		header, _ := data["headerName"].(string)
+		if header == "" {
+			header = "X-API-Key"
+		}
+		req.Header.Set(header, apiKey)
+	case credential.TypeOAuth2:
+		if token, _ := data["accessToken"].(string); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	case credential.TypeBasicAuth:
+		username, _ := data["username"].(string)
+		password, _ := data["password"].(string)
+		req.SetBasicAuth(username, password)
+	}
+}
+
 func (e *NodeExecutor) executeHTTPRequest(ctx context.Context, request NodeExecutionRequest) (*NodeExecutionResult, error) {
 	// Extract parameters
 	url, _ := request.Parameters["url"].(string)
@@ -95,8 +279,10 @@ func (e *NodeExecutor) executeHTTPRequest(ctx context.Context, request NodeExecu
 
 	// Prepare request body
 	var reqBody io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return &NodeExecutionResult{
 				Success: false,
@@ -121,10 +307,23 @@ func (e *NodeExecutor) executeHTTPRequest(ctx context.Context, request NodeExecu
 			req.Header.Set(key, strValue)
 		}
 	}
+	applyCredential(req, request.Credential)
+
+	e.nodeLog(ctx, request, execution.LogLevelDebug, "Sending HTTP request", map[string]interface{}{
+		"method":  method,
+		"url":     url,
+		"headers": debugHeaders(req.Header),
+		"body":    debugBody(jsonBody),
+	})
 
 	// Execute request
+	started := time.Now()
 	resp, err := e.client.Do(req)
 	if err != nil {
+		// Surface cancellation so the request is reported as aborted
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return &NodeExecutionResult{
 			Success: false,
 			Error:   fmt.Sprintf("Request failed: %v", err),
@@ -135,12 +334,31 @@ func (e *NodeExecutor) executeHTTPRequest(ctx context.Context, request NodeExecu
 	// Read response
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return &NodeExecutionResult{
 			Success: false,
 			Error:   fmt.Sprintf("Failed to read response: %v", err),
 		}, nil
 	}
 
+	level := execution.LogLevelInfo
+	if resp.StatusCode >= http.StatusBadRequest {
+		level = execution.LogLevelWarning
+	}
+	e.nodeLog(ctx, request, level, "HTTP request completed", map[string]interface{}{
+		"method":     method,
+		"url":        url,
+		"statusCode": resp.StatusCode,
+		"durationMs": time.Since(started).Milliseconds(),
+	})
+	e.nodeLog(ctx, request, execution.LogLevelDebug, "HTTP response received", map[string]interface{}{
+		"statusCode": resp.StatusCode,
+		"headers":    debugHeaders(resp.Header),
+		"body":       debugResponseBody(respBody, request.SensitiveFields),
+	})
+
 	// Parse response
 	var responseData interface{}
 	if err := json.Unmarshal(respBody, &responseData); err != nil {
@@ -158,6 +376,49 @@ func (e *NodeExecutor) executeHTTPRequest(ctx context.Context, request NodeExecu
 	}, nil
 }
 
+// debugHeaders returns headers as logged by debug runs, secrets redacted
+func debugHeaders(headers http.Header) map[string]string {
+	logged := make(map[string]string, len(headers))
+	for name := range headers {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			logged[name] = "[REDACTED]"
+			continue
+		}
+		logged[name] = headers.Get(name)
+	}
+	return logged
+}
+
+// debugBody returns a body as logged by debug runs, cut at maxDebugBody
+func debugBody(body []byte) string {
+	if len(body) > maxDebugBody {
+		return string(body[:maxDebugBody]) + "...(truncated)"
+	}
+	return string(body)
+}
+
+// debugResponseBody returns a response body as logged by debug runs, the
+// sensitive fields of the node output under "body" redacted
+func debugResponseBody(body []byte, sensitiveFields []string) string {
+	if len(sensitiveFields) == 0 {
+		return debugBody(body)
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		parsed = string(body)
+	}
+	redacted := workflow.RedactFields(map[string]interface{}{"body": parsed}, sensitiveFields)["body"]
+	if s, ok := redacted.(string); ok {
+		return debugBody([]byte(s))
+	}
+	encoded, err := json.Marshal(redacted)
+	if err != nil {
+		return workflow.RedactedValue
+	}
+	return debugBody(encoded)
+}
+
 func (e *NodeExecutor) executeDatabaseQuery(ctx context.Context, request NodeExecutionRequest) (*NodeExecutionResult, error) {
 	// Database query execution logic
 	// This would connect to the specified database and execute the query
@@ -165,7 +426,7 @@ func (e *NodeExecutor) executeDatabaseQuery(ctx context.Context, request NodeExe
 	query, _ := request.Parameters["query"].(string)
 	dbType, _ := request.Parameters["type"].(string)
 
-	e.logger.Info("Executing database query",
+	logger.FromContext(ctx, e.logger).Info("Executing database query",
 		"type", dbType,
 		"query", query,
 	)
@@ -187,7 +448,7 @@ func (e *NodeExecutor) executeEmail(ctx context.Context, request NodeExecutionRe
 	subject, _ := request.Parameters["subject"].(string)
 	// body, _ := request.Parameters["body"].(string)
 
-	e.logger.Info("Sending email",
+	logger.FromContext(ctx, e.logger).Info("Sending email",
 		"to", to,
 		"subject", subject,
 	)
@@ -208,7 +469,7 @@ func (e *NodeExecutor) executeSlack(ctx context.Context, request NodeExecutionRe
 	channel, _ := request.Parameters["channel"].(string)
 	// message, _ := request.Parameters["message"].(string)
 
-	e.logger.Info("Sending Slack message",
+	logger.FromContext(ctx, e.logger).Info("Sending Slack message",
 		"channel", channel,
 	)
 
@@ -227,9 +488,12 @@ func (e *NodeExecutor) executeCode(ctx context.Context, request NodeExecutionReq
 	language, _ := request.Parameters["language"].(string)
 	code, _ := request.Parameters["code"].(string)
 
-	e.logger.Info("Executing code",
+	logger.FromContext(ctx, e.logger).Info("Executing code",
 		"language", language,
 	)
+	e.nodeLog(ctx, request, execution.LogLevelInfo, "Running code", map[string]interface{}{
+		"language": language,
+	})
 
 	// In production, this would execute code in a secure sandbox
 	// For now, we'll only support simple JavaScript execution
@@ -296,9 +560,16 @@ func (e *NodeExecutor) executeMap(ctx context.Context, request NodeExecutionRequ
 	output := make(map[string]interface{})
 	for key, value := range mapping {
 		if inputKey, ok := value.(string); ok {
-			if inputValue, exists := request.InputData[inputKey]; exists {
+			inputValue, exists := request.InputData[inputKey]
+			if exists {
 				output[key] = inputValue
 			}
+			e.nodeLog(ctx, request, execution.LogLevelDebug, "Evaluated mapping", map[string]interface{}{
+				"field":      key,
+				"expression": inputKey,
+				"resolved":   exists,
+				"value":      workflow.RedactFields(map[string]interface{}{key: inputValue}, request.SensitiveFields)[key],
+			})
 		}
 	}
 
@@ -357,9 +628,12 @@ func (e *NodeExecutor) executeCustomNode(ctx context.Context, request NodeExecut
 	// For custom nodes, we'll check if there's a registered handler
 	// This would integrate with a plugin system
 
-	e.logger.Warn("Unknown node type, using passthrough",
+	logger.FromContext(ctx, e.logger).Warn("Unknown node type, using passthrough",
 		"nodeType", request.NodeType,
 	)
+	e.nodeLog(ctx, request, execution.LogLevelWarning, "Unknown node type, input passed through", map[string]interface{}{
+		"nodeType": request.NodeType,
+	})
 
 	return &NodeExecutionResult{
 		Success: true,
@@ -3,24 +3,82 @@ package worker
 import (
 	"context"
 	"fmt"
+	"os"
 	"runtime"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/linkflow-go/internal/executor/app/distributed"
+	"github.com/linkflow-go/pkg/auth/sealed"
 	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/linkflow-go/pkg/payload"
+	"github.com/linkflow-go/pkg/rpc"
+	"github.com/linkflow-go/pkg/rpc/credentialv1"
+	"github.com/linkflow-go/pkg/telemetry"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 )
 
 type Pool struct {
-	config   *config.Config
-	logger   logger.Logger
-	workers  []*Worker
-	eventBus events.EventBus
-	redis    *redis.Client
-	stopCh   chan struct{}
-	wg       sync.WaitGroup
+	config     *config.Config
+	logger     logger.Logger
+	workers    []*Worker
+	workersMux sync.RWMutex
+	started    bool
+	eventBus   events.EventBus
+	redis      *redis.Client
+	stopCh     chan struct{}
+
+	// protocol delivers the work stealing requests of the coordinator to
+	// every instance, each picks those naming it
+	protocol events.EventBus
+
+	// credentials resolves the credentials of nodes, nil when the
+	// credential API is not configured
+	credentials    credentialv1.CredentialServiceClient
+	credentialConn *grpc.ClientConn
+	wg             sync.WaitGroup
+
+	// sealer opens the secret variables nodes reference, nil when no
+	// credential encryption key is configured
+	sealer *sealed.Sealer
+
+	// payloads keeps node data spilled by reference, nil passes all data
+	// inline
+	payloads workflow.PayloadStore
+
+	// queue holds node requests until a worker picks them up
+	queue chan *task
+
+	// running tracks cancel functions of queued and in-flight node requests
+	// by execution ID and request ID
+	running    map[string]map[string]context.CancelFunc
+	runningMux sync.Mutex
+
+	// held tracks the executions the pool holds requests of for the
+	// coordinator
+	held    map[string]*holding
+	heldMux sync.Mutex
+
+	executionsCompleted int64
+	executionsFailed    int64
+
+	// instance names the pool in its worker pool events and as a worker of
+	// the coordinator, exhausted tracks whether the last report found it
+	// exhausted
+	instance  string
+	exhausted bool
 }
 
 type Worker struct {
@@ -28,15 +86,54 @@ type Worker struct {
 	pool     *Pool
 	executor *NodeExecutor
 	stopCh   chan struct{}
+	load     int64
+}
+
+// task is a queued node execution request
+type task struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	request    NodeExecutionRequest
+	aggregate  string
+	debug      bool
+	enqueuedAt time.Time
+	// released is set once the request is handed off to another worker,
+	// guarded by the held lock of the pool
+	released bool
 }
 
+// queueSize bounds the number of node requests waiting for a worker. A full
+// queue applies backpressure to the event consumer.
+const queueSize = 1000
+
+// metricsComponent labels pool series in the shared executor metrics
+const metricsComponent = "pool"
+
+// A pool whose workers are all busy is exhausted once its queue fills up to
+// exhaustedDepth, and recovers once the queue drains below recoveredDepth
+const (
+	exhaustedDepth = queueSize / 2
+	recoveredDepth = queueSize / 4
+)
+
+// maxWorkers caps the pool size, matching the config validation
+const maxWorkers = 100
+
 func NewPool(cfg *config.Config, log logger.Logger) (*Pool, error) {
 	// Initialize event bus
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	eventBus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
 
+	// Every instance needs the requests naming it
+	protocolConfig := cfg.Kafka.ToKafkaConfig()
+	protocolConfig.Broadcast = true
+	protocol, err := events.New(protocolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create protocol event bus: %w", err)
+	}
+
 	// Initialize Redis
 	redisClient := redis.NewClient(&redis.Options{
 		Addr:     cfg.Redis.Addr(),
@@ -50,61 +147,161 @@ func NewPool(cfg *config.Config, log logger.Logger) (*Pool, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	// Determine number of workers (default to number of CPUs)
-	numWorkers := runtime.NumCPU()
-	if cfg.Server.Port > 0 { // Use port as worker count if specified
-		numWorkers = cfg.Server.Port / 1000 // Simple calculation
-	}
-	if numWorkers < 2 {
-		numWorkers = 2
-	}
-	if numWorkers > 100 {
-		numWorkers = 100
-	}
+	numWorkers := WorkerCount(cfg.Executor.Workers)
 
 	pool := &Pool{
 		config:   cfg,
 		logger:   log,
 		workers:  make([]*Worker, numWorkers),
 		eventBus: eventBus,
+		protocol: protocol,
 		redis:    redisClient,
 		stopCh:   make(chan struct{}),
+		queue:    make(chan *task, queueSize),
+		running:  make(map[string]map[string]context.CancelFunc),
+		held:     make(map[string]*holding),
+		instance: poolInstance(),
+	}
+
+	if addr := cfg.RPC.Services[rpc.ServiceCredential]; addr != "" {
+		conn, err := rpc.Dial(addr, cfg.RPC.Token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial credential service: %w", err)
+		}
+		pool.credentialConn = conn
+		pool.credentials = credentialv1.NewCredentialServiceClient(conn)
+	}
+
+	// Secret variables are sealed with the credential encryption key
+	sealer, err := sealed.NewSealer(cfg.Credential.EncryptionKey)
+	if err != nil {
+		log.Warn("Secret workflow variables disabled", "error", err)
+	}
+	pool.sealer = sealer
+
+	// Large node data is read from and spilled to the object storage
+	payloads, err := payload.New(cfg.Storage, cfg.Execution.PayloadBucket)
+	if err != nil {
+		log.Warn("Payload spillover disabled", "error", err)
+	} else if payloads != nil {
+		pool.payloads = payloads
 	}
 
 	// Create workers
 	for i := 0; i < numWorkers; i++ {
-		worker := &Worker{
-			id:       i + 1,
-			pool:     pool,
-			executor: NewNodeExecutor(eventBus, redisClient, log),
-			stopCh:   make(chan struct{}),
-		}
-		pool.workers[i] = worker
+		pool.workers[i] = pool.newWorker(i + 1)
 	}
 
 	return pool, nil
 }
 
+// WorkerCount resolves the configured pool size, 0 means one worker per
+// CPU with at least two
+func WorkerCount(configured int) int {
+	if configured > 0 {
+		return min(configured, maxWorkers)
+	}
+	return min(max(runtime.NumCPU(), 2), maxWorkers)
+}
+
+func (p *Pool) newWorker(id int) *Worker {
+	executor := NewNodeExecutor(p.eventBus, p.redis, p.credentials, p.logger)
+	executor.SetSealer(p.sealer)
+
+	return &Worker{
+		id:       id,
+		pool:     p,
+		executor: executor,
+		stopCh:   make(chan struct{}),
+	}
+}
+
 func (p *Pool) Size() int {
+	p.workersMux.RLock()
+	defer p.workersMux.RUnlock()
 	return len(p.workers)
 }
 
+// Resize grows or shrinks the pool to the configured number of workers.
+// Removed workers finish the node they are executing before they exit
+func (p *Pool) Resize(configured int) {
+	n := WorkerCount(configured)
+
+	p.workersMux.Lock()
+	defer p.workersMux.Unlock()
+
+	current := len(p.workers)
+	if n == current {
+		return
+	}
+
+	if n > current {
+		for i := current; i < n; i++ {
+			worker := p.newWorker(i + 1)
+			p.workers = append(p.workers, worker)
+			if p.started {
+				p.wg.Add(1)
+				go worker.run()
+			}
+		}
+	} else {
+		for _, worker := range p.workers[n:] {
+			close(worker.stopCh)
+			metrics.ExecutorWorkerCapacity.DeleteLabelValues(metricsComponent, strconv.Itoa(worker.id))
+		}
+		p.workers = p.workers[:n]
+	}
+
+	p.logger.Info("Worker pool resized", "from", current, "to", n)
+}
+
+// RegisterHealthChecks adds the workers and the dependencies of the pool to
+// the readiness checks
+func (p *Pool) RegisterHealthChecks(checker *health.Checker) {
+	checker.Critical("workers", func(ctx context.Context) error {
+		if p.Size() == 0 {
+			return fmt.Errorf("no workers running")
+		}
+		return nil
+	})
+	checker.Critical("redis", health.Redis(p.redis))
+	checker.Critical("event_bus", health.EventBus(p.eventBus))
+}
+
 func (p *Pool) Start() error {
 	// Subscribe to node execution requests
 	if err := p.eventBus.Subscribe("node.execute.request", p.handleNodeExecutionRequest); err != nil {
 		return fmt.Errorf("failed to subscribe to events: %w", err)
 	}
 
+	// Subscribe to stop requests for running nodes
+	if err := p.eventBus.Subscribe(events.NodesStopRequest, p.handleNodesStopRequest); err != nil {
+		return fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+
+	// Subscribe to the work stealing requests of the coordinator
+	if err := p.protocol.Subscribe(distributed.EventHandoffRequested, p.handleHandoffRequested); err != nil {
+		return fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+	if err := p.protocol.Subscribe(distributed.EventWorkReassigned, p.handleWorkReassigned); err != nil {
+		return fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+
 	// Start all workers
+	p.workersMux.Lock()
 	for _, worker := range p.workers {
 		p.wg.Add(1)
 		go worker.run()
 	}
+	p.started = true
+	size := len(p.workers)
+	p.workersMux.Unlock()
 
-	// Start monitoring
+	// Start monitoring and reporting to the coordinator
 	go p.monitor()
+	go p.heartbeat()
 
-	p.logger.Info("Worker pool started", "workers", len(p.workers))
+	p.logger.Info("Worker pool started", "workers", size)
 	return nil
 }
 
@@ -114,10 +311,23 @@ func (p *Pool) Shutdown(ctx context.Context) error {
 	// Signal all workers to stop
 	close(p.stopCh)
 
+	// Abort nodes that are still running
+	p.runningMux.Lock()
+	for _, requests := range p.running {
+		for _, cancel := range requests {
+			cancel()
+		}
+	}
+	p.runningMux.Unlock()
+
 	// Stop all workers
+	p.workersMux.Lock()
 	for _, worker := range p.workers {
 		close(worker.stopCh)
 	}
+	p.workers = nil
+	p.started = false
+	p.workersMux.Unlock()
 
 	// Wait for all workers to finish with timeout
 	done := make(chan struct{})
@@ -138,53 +348,297 @@ func (p *Pool) Shutdown(ctx context.Context) error {
 		p.logger.Error("Failed to close event bus", "error", err)
 	}
 
+	if err := p.protocol.Close(); err != nil {
+		p.logger.Error("Failed to close protocol event bus", "error", err)
+	}
+
 	if err := p.redis.Close(); err != nil {
 		p.logger.Error("Failed to close Redis", "error", err)
 	}
 
+	if p.credentialConn != nil {
+		if err := p.credentialConn.Close(); err != nil {
+			p.logger.Error("Failed to close credential service connection", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// payloadSecrets reads the sealed secrets of a node request, a map of
+// strings once decoded from the wire
+func payloadSecrets(value interface{}) map[string]string {
+	switch v := value.(type) {
+	case map[string]string:
+		return v
+	case map[string]interface{}:
+		secrets := make(map[string]string, len(v))
+		for key, item := range v {
+			if s, ok := item.(string); ok {
+				secrets[key] = s
+			}
+		}
+		return secrets
+	}
+	return nil
+}
+
+// payloadStrings reads a list of strings of a node request, a list of
+// interfaces once decoded from the wire
+func payloadStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
 	return nil
 }
 
 func (p *Pool) handleNodeExecutionRequest(ctx context.Context, event events.Event) error {
-	// Find available worker and assign task
-	// In production, this would use a proper work queue
+	request := NodeExecutionRequest{ExecutionID: event.AggregateID}
+	request.RequestID, _ = event.Payload["requestId"].(string)
+	request.NodeID, _ = event.Payload["nodeId"].(string)
+	request.NodeType, _ = event.Payload["nodeType"].(string)
+	request.Parameters, _ = event.Payload["parameters"].(map[string]interface{})
+	request.InputData, _ = event.Payload["inputData"].(map[string]interface{})
+	request.UserID, _ = event.Payload["userId"].(string)
+	request.NodeExecutionID, _ = event.Payload["nodeExecutionId"].(string)
+	request.Secrets = payloadSecrets(event.Payload["secrets"])
+	request.SensitiveFields = payloadStrings(event.Payload["sensitiveFields"])
+
+	// Every log written while executing the node names it
+	ctx = logger.WithFields(ctx, "executionId", request.ExecutionID, "nodeId", request.NodeID)
+	debug, _ := event.Payload["debug"].(bool)
+	if debug {
+		ctx = logger.WithDebug(ctx)
+	}
 
-	// For now, just log the request
-	p.logger.Info("Received node execution request",
-		"nodeId", event.Payload["nodeId"],
-		"nodeType", event.Payload["nodeType"],
+	logger.FromContext(ctx, p.logger).Info("Received node execution request",
+		"nodeType", request.NodeType,
 	)
 
-	// Execute node (simplified)
-	result := map[string]interface{}{
-		"status": "completed",
-		"output": "Node executed successfully",
+	return p.enqueue(ctx, request, event.AggregateID, debug)
+}
+
+// enqueue queues a request for the workers
+func (p *Pool) enqueue(ctx context.Context, request NodeExecutionRequest, aggregate string, debug bool) error {
+	// Run detached from the consumer so stop requests can be handled while
+	// the node is queued or still executing
+	execCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	p.track(request, cancel)
+
+	t := &task{
+		ctx:        execCtx,
+		cancel:     cancel,
+		request:    request,
+		aggregate:  aggregate,
+		debug:      debug,
+		enqueuedAt: time.Now(),
 	}
+	p.hold(ctx, t)
+
+	select {
+	case p.queue <- t:
+		metrics.ExecutorQueueDepth.WithLabelValues(metricsComponent).Set(float64(len(p.queue)))
+		return nil
+	case <-p.stopCh:
+		p.untrack(request)
+		cancel()
+		return fmt.Errorf("worker pool is shutting down")
+	}
+}
+
+// process executes a queued request and publishes the response, skipping
+// the ones handed off to another worker
+func (w *Worker) process(t *task) {
+	p := w.pool
+	if !p.start(t) {
+		return
+	}
+	defer p.untrack(t.request)
+	defer t.cancel()
+
+	metrics.RecordAssignment(metricsComponent, "assigned", time.Since(t.enqueuedAt).Seconds())
+	metrics.ExecutorQueueDepth.WithLabelValues(metricsComponent).Set(float64(len(p.queue)))
+
+	w.setLoad(atomic.AddInt64(&w.load, 1))
+	defer func() { w.setLoad(atomic.AddInt64(&w.load, -1)) }()
+
+	// Continues the orchestrator's node span carried by the request event
+	ctx, span := otel.Tracer("linkflow/executor").Start(t.ctx, "worker.execute "+t.request.NodeType,
+		trace.WithAttributes(
+			telemetry.ExecutionIDAttribute(t.request.ExecutionID),
+			telemetry.NodeIDAttribute(t.request.NodeID),
+			telemetry.NodeTypeAttribute(t.request.NodeType),
+		),
+	)
+	ctx, meter := startUsage(ctx)
+	result := w.execute(ctx, t.request)
+	w.spillResult(ctx, t.request, result)
+	result["usage"] = meter.stop()
+	success, _ := result["success"].(bool)
+	if !success {
+		message, _ := result["error"].(string)
+		span.SetStatus(codes.Error, message)
+	}
+	span.End()
+	defer p.finish(t, success)
 
-	// Publish result
 	responseEvent := events.NewEventBuilder("node.execute.response").
-		WithAggregateID(event.AggregateID).
-		WithPayload("requestId", event.Payload["requestId"]).
-		WithPayload("nodeId", event.Payload["nodeId"]).
+		WithAggregateID(t.aggregate).
+		WithPayload("requestId", t.request.RequestID).
+		WithPayload("nodeId", t.request.NodeID).
 		WithPayload("result", result).
 		Build()
 
-	return p.eventBus.Publish(ctx, responseEvent)
+	if err := p.eventBus.Publish(context.WithoutCancel(ctx), responseEvent); err != nil {
+		logger.FromContext(ctx, p.logger).Error("Failed to publish node execution response",
+			"requestId", t.request.RequestID,
+			"error", err,
+		)
+	}
+}
+
+func (w *Worker) setLoad(load int64) {
+	metrics.ExecutorWorkerLoad.WithLabelValues(metricsComponent, strconv.Itoa(w.id)).Set(float64(load))
+}
+
+// execute runs the request and converts the outcome into the response
+// payload expected by the orchestrator
+func (w *Worker) execute(ctx context.Context, request NodeExecutionRequest) map[string]interface{} {
+	// Spilled fields of the input are read back when the node reads them,
+	// the others pass on by reference
+	input, err := w.resolveInput(ctx, request)
+	if err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		}
+	}
+	request.InputData = input
+
+	result, err := w.executor.Execute(ctx, request)
+	if ctx.Err() != nil {
+		// Anything produced after cancellation is not trustworthy
+		return map[string]interface{}{
+			"success":   false,
+			"cancelled": true,
+			"discarded": true,
+			"error":     ctx.Err().Error(),
+		}
+	}
+	if err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		}
+	}
+
+	return map[string]interface{}{
+		"success": result.Success,
+		"output":  result.Output,
+		"error":   result.Error,
+	}
+}
+
+// resolveInput reads back the spilled fields of the input of a node it reads
+func (w *Worker) resolveInput(ctx context.Context, request NodeExecutionRequest) (map[string]interface{}, error) {
+	fields, all := inputFields(request)
+	if all {
+		return workflow.ResolvePayloads(ctx, w.pool.payloads, request.InputData)
+	}
+	return workflow.ResolvePayloadFields(ctx, w.pool.payloads, request.InputData, fields)
+}
+
+// spillResult passes the large fields of a node output by reference instead
+// of in the response event. They stay inline when the store fails.
+func (w *Worker) spillResult(ctx context.Context, request NodeExecutionRequest, result map[string]interface{}) {
+	output, ok := result["output"].(map[string]interface{})
+	if !ok || w.pool.payloads == nil {
+		return
+	}
+
+	spilled, err := workflow.SpillPayloads(ctx, w.pool.payloads, output, w.pool.config.Execution.PayloadSpillBytes,
+		func(field string) string {
+			return workflow.PayloadKey(request.ExecutionID, request.NodeID, field)
+		})
+	if err != nil {
+		logger.FromContext(ctx, w.pool.logger).Warn("Failed to spill node output, passing it inline", "error", err)
+		return
+	}
+	result["output"] = spilled
+}
+
+// handleNodesStopRequest cancels the running nodes of an execution, or a
+// single request when requestId is set
+func (p *Pool) handleNodesStopRequest(ctx context.Context, event events.Event) error {
+	executionID, _ := event.Payload["executionId"].(string)
+	if executionID == "" {
+		executionID = event.AggregateID
+	}
+	requestID, _ := event.Payload["requestId"].(string)
+
+	p.runningMux.Lock()
+	defer p.runningMux.Unlock()
+
+	stopped := 0
+	for id, cancel := range p.running[executionID] {
+		if requestID != "" && id != requestID {
+			continue
+		}
+		cancel()
+		stopped++
+	}
+
+	if stopped > 0 {
+		p.logger.Info("Stopped running nodes",
+			"executionId", executionID,
+			"requests", stopped,
+			"reason", event.Payload["reason"],
+		)
+	}
+
+	return nil
+}
+
+func (p *Pool) track(request NodeExecutionRequest, cancel context.CancelFunc) {
+	p.runningMux.Lock()
+	defer p.runningMux.Unlock()
+
+	if p.running[request.ExecutionID] == nil {
+		p.running[request.ExecutionID] = make(map[string]context.CancelFunc)
+	}
+	p.running[request.ExecutionID][request.RequestID] = cancel
+}
+
+func (p *Pool) untrack(request NodeExecutionRequest) {
+	p.runningMux.Lock()
+	defer p.runningMux.Unlock()
+
+	delete(p.running[request.ExecutionID], request.RequestID)
+	if len(p.running[request.ExecutionID]) == 0 {
+		delete(p.running, request.ExecutionID)
+	}
 }
 
 func (w *Worker) run() {
 	defer w.pool.wg.Done()
 
 	w.pool.logger.Info("Worker started", "workerId", w.id)
-
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	metrics.ExecutorWorkerCapacity.WithLabelValues(metricsComponent, strconv.Itoa(w.id)).Set(1)
+	w.setLoad(0)
 
 	for {
 		select {
-		case <-ticker.C:
-			// Check for work (simplified - in production would use proper queue)
-			w.checkForWork()
+		case t := <-w.pool.queue:
+			w.process(t)
 		case <-w.stopCh:
 			w.pool.logger.Info("Worker stopped", "workerId", w.id)
 			return
@@ -195,16 +649,6 @@ func (w *Worker) run() {
 	}
 }
 
-func (w *Worker) checkForWork() {
-	// In production, this would:
-	// 1. Pull work from a queue (Redis, RabbitMQ, etc.)
-	// 2. Execute the node
-	// 3. Report results back
-
-	// For now, just heartbeat
-	w.pool.logger.Debug("Worker checking for work", "workerId", w.id)
-}
-
 func (p *Pool) monitor() {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
@@ -221,20 +665,94 @@ func (p *Pool) monitor() {
 
 func (p *Pool) reportMetrics() {
 	// Report worker pool metrics
+	p.workersMux.RLock()
+	workers := append([]*Worker(nil), p.workers...)
+	p.workersMux.RUnlock()
+
 	activeWorkers := 0
-	for _, worker := range p.workers {
+	busyWorkers := 0
+	for _, worker := range workers {
 		select {
 		case <-worker.stopCh:
 			// Worker is stopped
 		default:
 			activeWorkers++
+			if atomic.LoadInt64(&worker.load) > 0 {
+				busyWorkers++
+			}
 		}
 	}
 
-	p.logger.Info("Worker pool metrics",
-		"totalWorkers", len(p.workers),
+	p.runningMux.Lock()
+	running := 0
+	for _, requests := range p.running {
+		running += len(requests)
+	}
+	p.runningMux.Unlock()
+
+	queueDepth := len(p.queue)
+	// Tracked requests include queued ones, only count those with a worker
+	running -= queueDepth
+	if running < 0 {
+		running = 0
+	}
+
+	metrics.ExecutorQueueDepth.WithLabelValues(metricsComponent).Set(float64(queueDepth))
+	metrics.ExecutorRunningExecutions.WithLabelValues(metricsComponent).Set(float64(running))
+	metrics.ExecutorWorkers.WithLabelValues(metricsComponent, "busy").Set(float64(busyWorkers))
+	metrics.ExecutorWorkers.WithLabelValues(metricsComponent, "idle").Set(float64(activeWorkers - busyWorkers))
+	metrics.ExecutorWorkers.WithLabelValues(metricsComponent, "stopped").Set(float64(len(workers) - activeWorkers))
+
+	p.logger.Debug("Worker pool metrics",
+		"totalWorkers", len(workers),
 		"activeWorkers", activeWorkers,
+		"busyWorkers", busyWorkers,
+		"queueDepth", queueDepth,
+		"running", running,
 	)
 
-	// In production, this would send metrics to Prometheus
+	p.reportExhaustion(activeWorkers, busyWorkers, queueDepth)
+}
+
+// reportExhaustion publishes worker_pool.exhausted when the pool stops
+// keeping up with its queue, and worker_pool.recovered once it catches up
+func (p *Pool) reportExhaustion(workers, busyWorkers, queueDepth int) {
+	eventType := ""
+	switch {
+	case !p.exhausted && busyWorkers >= workers && queueDepth >= exhaustedDepth:
+		eventType = events.WorkerPoolExhausted
+	case p.exhausted && queueDepth < recoveredDepth:
+		eventType = events.WorkerPoolRecovered
+	default:
+		return
+	}
+
+	event := events.NewEventBuilder(eventType).
+		WithAggregateID(p.instance).
+		WithPayload("instance", p.instance).
+		WithPayload("workers", workers).
+		WithPayload("busyWorkers", busyWorkers).
+		WithPayload("queueDepth", queueDepth).
+		WithPayload("queueCapacity", queueSize).
+		Build()
+	if err := p.eventBus.Publish(context.Background(), event); err != nil {
+		// Left unchanged, the next report publishes it again
+		p.logger.Error("Failed to publish worker pool event", "type", eventType, "error", err)
+		return
+	}
+	p.exhausted = eventType == events.WorkerPoolExhausted
+	if p.exhausted {
+		p.logger.Warn("Worker pool exhausted", "busyWorkers", busyWorkers, "queueDepth", queueDepth)
+	} else {
+		p.logger.Info("Worker pool recovered", "queueDepth", queueDepth)
+	}
+}
+
+// poolInstance names the pool after its host, which stays the same across
+// restarts so an incident left open by a crash is resolved by the next run
+func poolInstance() string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return uuid.New().String()
 }
@@ -0,0 +1,165 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	runtimemetrics "runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/execution"
+)
+
+const (
+	heapAllocsMetric  = "/gc/heap/allocs:bytes"
+	heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+	// memorySampleInterval is how often the heap in use is sampled while a
+	// node runs for its watermark
+	memorySampleInterval = 50 * time.Millisecond
+)
+
+type usageMeterKey struct{}
+
+// usageMeter measures the resources used by one node. Memory is read from
+// the runtime of the worker process, so it includes the nodes running
+// alongside it.
+type usageMeter struct {
+	started       time.Time
+	startAllocs   uint64
+	peak          atomic.Uint64
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+	apiCalls      atomic.Int64
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	done     chan struct{}
+}
+
+// startUsage starts measuring a node, the returned context carries the meter
+// to the HTTP requests it makes
+func startUsage(ctx context.Context) (context.Context, *usageMeter) {
+	allocs, inUse := readHeap()
+	m := &usageMeter{
+		started:     time.Now(),
+		startAllocs: allocs,
+		stopCh:      make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	m.peak.Store(inUse)
+	go m.sample()
+	return context.WithValue(ctx, usageMeterKey{}, m), m
+}
+
+// usageFromContext returns the meter of the node running with ctx, nil
+// outside of a node
+func usageFromContext(ctx context.Context) *usageMeter {
+	m, _ := ctx.Value(usageMeterKey{}).(*usageMeter)
+	return m
+}
+
+// sample raises the watermark until stop
+func (m *usageMeter) sample() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(memorySampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_, inUse := readHeap()
+			m.raisePeak(inUse)
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+func (m *usageMeter) raisePeak(inUse uint64) {
+	for {
+		peak := m.peak.Load()
+		if inUse <= peak || m.peak.CompareAndSwap(peak, inUse) {
+			return
+		}
+	}
+}
+
+// stop ends the measure and returns the usage of the node
+func (m *usageMeter) stop() execution.Usage {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+	})
+	<-m.done
+
+	allocs, inUse := readHeap()
+	m.raisePeak(inUse)
+
+	usage := execution.Usage{
+		WallTimeMs:      time.Since(m.started).Milliseconds(),
+		MemoryPeakBytes: int64(m.peak.Load()),
+		BytesSent:       m.bytesSent.Load(),
+		BytesReceived:   m.bytesReceived.Load(),
+		APICalls:        int(m.apiCalls.Load()),
+	}
+	if allocs > m.startAllocs {
+		usage.AllocatedBytes = int64(allocs - m.startAllocs)
+	}
+	return usage
+}
+
+// readHeap returns the bytes allocated on the heap since the process started
+// and those in use now
+func readHeap() (allocs, inUse uint64) {
+	samples := []runtimemetrics.Sample{
+		{Name: heapAllocsMetric},
+		{Name: heapObjectsMetric},
+	}
+	runtimemetrics.Read(samples)
+	if samples[0].Value.Kind() == runtimemetrics.KindUint64 {
+		allocs = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == runtimemetrics.KindUint64 {
+		inUse = samples[1].Value.Uint64()
+	}
+	return allocs, inUse
+}
+
+// meteredTransport counts the HTTP requests of nodes and the bytes of their
+// bodies on the meter of the node making them
+type meteredTransport struct {
+	base http.RoundTripper
+}
+
+func (t *meteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	m := usageFromContext(req.Context())
+	if m == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	m.apiCalls.Add(1)
+	if req.ContentLength > 0 {
+		m.bytesSent.Add(req.ContentLength)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &meteredBody{ReadCloser: resp.Body, meter: m}
+	return resp, nil
+}
+
+// meteredBody counts the bytes of a response body as the node reads them
+type meteredBody struct {
+	io.ReadCloser
+	meter *usageMeter
+}
+
+func (b *meteredBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.meter.bytesReceived.Add(int64(n))
+	return n, err
+}
@@ -205,6 +205,11 @@ func (e *DatabaseNodeExecutor) executeSelect(ctx context.Context, db *sql.DB, qu
 	count := 0
 
 	for rows.Next() && count < maxRows {
+		// Stop reading as soon as the execution is cancelled
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		// Create a slice of interface{} to hold column values
 		values := make([]interface{}, len(columns))
 		valuePtrs := make([]interface{}, len(columns))
@@ -238,6 +243,10 @@ func (e *DatabaseNodeExecutor) executeSelect(ctx context.Context, db *sql.DB, qu
 		count++
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
 	return map[string]interface{}{
 		"rows":     results,
 		"rowCount": count,
@@ -264,6 +273,12 @@ func (e *DatabaseNodeExecutor) executeModification(ctx context.Context, db *sql.
 			return nil, fmt.Errorf("query execution failed: %w", err)
 		}
 
+		// Never commit work for a cancelled execution
+		if err := ctx.Err(); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
 		if err := tx.Commit(); err != nil {
 			return nil, fmt.Errorf("failed to commit transaction: %w", err)
 		}
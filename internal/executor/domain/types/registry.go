@@ -106,6 +106,7 @@ func (r *NodeRegistry) RegisterBuiltinNodes() {
 	r.Register("json", NewJSONNodeExecutor())
 	r.Register("math", NewMathNodeExecutor())
 	r.Register("text", NewTextNodeExecutor())
+	r.Register("validate", NewValidateNodeExecutor())
 
 	// Integration nodes
 	r.Register("email", NewEmailNodeExecutor())
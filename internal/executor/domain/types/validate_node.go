@@ -0,0 +1,415 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Output ports for the validate node
+const (
+	ValidatePortValid   = "valid"
+	ValidatePortInvalid = "invalid"
+)
+
+// ValidationError describes a single validation failure for an item
+type ValidationError struct {
+	Path    string `json:"path"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidateNodeExecutor checks items against a JSON Schema and/or a rule set,
+// routing valid items to the "valid" port and invalid ones to "invalid"
+type ValidateNodeExecutor struct {
+	BaseNodeExecutor
+}
+
+func NewValidateNodeExecutor() *ValidateNodeExecutor {
+	return &ValidateNodeExecutor{
+		BaseNodeExecutor: BaseNodeExecutor{timeout: 30 * time.Second},
+	}
+}
+
+func (e *ValidateNodeExecutor) ValidateInput(node Node, input map[string]interface{}) error {
+	params := node.Parameters
+
+	schema, hasSchema := params["schema"]
+	rules, hasRules := params["rules"]
+	if !hasSchema && !hasRules {
+		return fmt.Errorf("validate node requires a schema or rules")
+	}
+
+	if hasSchema {
+		if _, err := parseSchema(schema); err != nil {
+			return fmt.Errorf("invalid schema: %w", err)
+		}
+	}
+
+	if hasRules {
+		if _, ok := rules.([]interface{}); !ok {
+			return fmt.Errorf("rules must be an array")
+		}
+	}
+
+	return nil
+}
+
+func (e *ValidateNodeExecutor) Execute(ctx context.Context, node Node, input map[string]interface{}) (map[string]interface{}, error) {
+	params := node.Parameters
+	field, _ := params["field"].(string)
+	stopOnFirstError, _ := params["stopOnFirstError"].(bool)
+
+	var schema map[string]interface{}
+	if raw, ok := params["schema"]; ok {
+		parsed, err := parseSchema(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: %w", err)
+		}
+		schema = parsed
+	}
+	rules, _ := params["rules"].([]interface{})
+
+	// Validate either the array at field or the whole input as a single item
+	var items []interface{}
+	if field != "" {
+		value := getNestedValue(input, field)
+		if arr, ok := value.([]interface{}); ok {
+			items = arr
+		} else {
+			items = []interface{}{value}
+		}
+	} else {
+		items = []interface{}{input}
+	}
+
+	valid := make([]interface{}, 0, len(items))
+	invalid := make([]interface{}, 0)
+
+	for i, item := range items {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		var errs []ValidationError
+		if schema != nil {
+			errs = append(errs, validateAgainstSchema(item, schema, "$")...)
+		}
+		if len(rules) > 0 && (len(errs) == 0 || !stopOnFirstError) {
+			errs = append(errs, validateAgainstRules(item, rules)...)
+		}
+
+		if len(errs) == 0 {
+			valid = append(valid, item)
+			continue
+		}
+
+		if stopOnFirstError {
+			errs = errs[:1]
+		}
+		invalid = append(invalid, map[string]interface{}{
+			"index":  i,
+			"item":   item,
+			"errors": errs,
+		})
+	}
+
+	branch := ValidatePortValid
+	if len(invalid) > 0 {
+		branch = ValidatePortInvalid
+	}
+
+	return map[string]interface{}{
+		ValidatePortValid:   valid,
+		ValidatePortInvalid: invalid,
+		"validCount":        len(valid),
+		"invalidCount":      len(invalid),
+		"branch":            branch,
+	}, nil
+}
+
+// parseSchema accepts a schema as an object or a JSON string
+func parseSchema(raw interface{}) (map[string]interface{}, error) {
+	switch s := raw.(type) {
+	case map[string]interface{}:
+		return s, nil
+	case string:
+		var schema map[string]interface{}
+		if err := json.Unmarshal([]byte(s), &schema); err != nil {
+			return nil, err
+		}
+		return schema, nil
+	default:
+		return nil, fmt.Errorf("schema must be an object or JSON string")
+	}
+}
+
+// validateAgainstRules evaluates condition rules (same format as the IF node)
+func validateAgainstRules(item interface{}, rules []interface{}) []ValidationError {
+	data, ok := item.(map[string]interface{})
+	if !ok {
+		data = map[string]interface{}{"value": item}
+	}
+
+	var errs []ValidationError
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		field, _ := rule["field"].(string)
+		operator, _ := rule["operator"].(string)
+		message, _ := rule["message"].(string)
+
+		passed, err := evaluateCondition(data, rule)
+		if err != nil {
+			errs = append(errs, ValidationError{Path: field, Rule: operator, Message: err.Error()})
+			continue
+		}
+		if !passed {
+			if message == "" {
+				message = fmt.Sprintf("field %s failed rule %s", field, operator)
+			}
+			errs = append(errs, ValidationError{Path: field, Rule: operator, Message: message})
+		}
+	}
+	return errs
+}
+
+// validateAgainstSchema validates a value against a JSON Schema subset:
+// type, enum, const, required, properties, additionalProperties, items,
+// min/max length, min/max items, minimum/maximum, pattern and format
+func validateAgainstSchema(value interface{}, schema map[string]interface{}, path string) []ValidationError {
+	var errs []ValidationError
+	fail := func(rule, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Path: path, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if t, ok := schema["type"]; ok {
+		if !matchesSchemaType(value, t) {
+			fail("type", "expected type %v, got %s", t, schemaTypeOf(value))
+			return errs
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if reflect.DeepEqual(normalizeNumber(candidate), normalizeNumber(value)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("enum", "value must be one of %v", enum)
+		}
+	}
+
+	if constant, ok := schema["const"]; ok {
+		if !reflect.DeepEqual(normalizeNumber(constant), normalizeNumber(value)) {
+			fail("const", "value must equal %v", constant)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, r := range required {
+				key := fmt.Sprintf("%v", r)
+				if _, exists := v[key]; !exists {
+					errs = append(errs, ValidationError{
+						Path:    path + "." + key,
+						Rule:    "required",
+						Message: fmt.Sprintf("missing required property %s", key),
+					})
+				}
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+		for key, propSchema := range properties {
+			child, exists := v[key]
+			propMap, ok := propSchema.(map[string]interface{})
+			if !exists || !ok {
+				continue
+			}
+			errs = append(errs, validateAgainstSchema(child, propMap, path+"."+key)...)
+		}
+
+		if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+			for key := range v {
+				if _, declared := properties[key]; !declared {
+					errs = append(errs, ValidationError{
+						Path:    path + "." + key,
+						Rule:    "additionalProperties",
+						Message: fmt.Sprintf("property %s is not allowed", key),
+					})
+				}
+			}
+		}
+
+	case []interface{}:
+		if min, ok := schemaNumber(schema, "minItems"); ok && float64(len(v)) < min {
+			fail("minItems", "array must have at least %v items", min)
+		}
+		if max, ok := schemaNumber(schema, "maxItems"); ok && float64(len(v)) > max {
+			fail("maxItems", "array must have at most %v items", max)
+		}
+		if itemSchema, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				errs = append(errs, validateAgainstSchema(item, itemSchema, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+
+	case string:
+		length := float64(len([]rune(v)))
+		if min, ok := schemaNumber(schema, "minLength"); ok && length < min {
+			fail("minLength", "string must be at least %v characters", min)
+		}
+		if max, ok := schemaNumber(schema, "maxLength"); ok && length > max {
+			fail("maxLength", "string must be at most %v characters", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				fail("pattern", "invalid pattern %s: %v", pattern, err)
+			} else if !re.MatchString(v) {
+				fail("pattern", "string does not match pattern %s", pattern)
+			}
+		}
+		if format, ok := schema["format"].(string); ok {
+			if err := checkStringFormat(v, format); err != nil {
+				fail("format", "%v", err)
+			}
+		}
+
+	default:
+		if num, err := toFloat64(value); err == nil && value != nil {
+			if min, ok := schemaNumber(schema, "minimum"); ok && num < min {
+				fail("minimum", "value must be >= %v", min)
+			}
+			if max, ok := schemaNumber(schema, "maximum"); ok && num > max {
+				fail("maximum", "value must be <= %v", max)
+			}
+			if min, ok := schemaNumber(schema, "exclusiveMinimum"); ok && num <= min {
+				fail("exclusiveMinimum", "value must be > %v", min)
+			}
+			if max, ok := schemaNumber(schema, "exclusiveMaximum"); ok && num >= max {
+				fail("exclusiveMaximum", "value must be < %v", max)
+			}
+		}
+	}
+
+	return errs
+}
+
+func schemaNumber(schema map[string]interface{}, key string) (float64, bool) {
+	raw, ok := schema[key]
+	if !ok {
+		return 0, false
+	}
+	num, err := toFloat64(raw)
+	if err != nil {
+		return 0, false
+	}
+	return num, true
+}
+
+func matchesSchemaType(value interface{}, t interface{}) bool {
+	switch typ := t.(type) {
+	case string:
+		return matchesSingleType(value, typ)
+	case []interface{}:
+		for _, candidate := range typ {
+			if s, ok := candidate.(string); ok && matchesSingleType(value, s) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func matchesSingleType(value interface{}, typ string) bool {
+	actual := schemaTypeOf(value)
+	if typ == "number" && actual == "integer" {
+		return true
+	}
+	return actual == typ
+}
+
+func schemaTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case int, int32, int64:
+		return "integer"
+	case float32, float64:
+		f, _ := toFloat64(v)
+		if f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	default:
+		return reflect.TypeOf(value).Kind().String()
+	}
+}
+
+// normalizeNumber makes numeric values comparable regardless of Go type
+func normalizeNumber(value interface{}) interface{} {
+	switch value.(type) {
+	case int, int32, int64, float32:
+		f, _ := toFloat64(value)
+		return f
+	}
+	return value
+}
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	uriPattern  = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://`)
+)
+
+func checkStringFormat(value, format string) error {
+	switch strings.ToLower(format) {
+	case "email":
+		if _, err := mail.ParseAddress(value); err != nil {
+			return fmt.Errorf("invalid email address")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("invalid date-time")
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("invalid date")
+		}
+	case "uuid":
+		if !uuidPattern.MatchString(value) {
+			return fmt.Errorf("invalid uuid")
+		}
+	case "uri", "url":
+		if !uriPattern.MatchString(value) {
+			return fmt.Errorf("invalid uri")
+		}
+	}
+	return nil
+}
@@ -7,10 +7,16 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/internal/executor/app/distributed"
 	"github.com/linkflow-go/internal/executor/app/worker"
 	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/middleware/correlation"
+	"github.com/linkflow-go/pkg/telemetry"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 )
 
 type Server struct {
@@ -18,17 +24,50 @@ type Server struct {
 	logger     logger.Logger
 	httpServer *http.Server
 	pool       *worker.Pool
+	telemetry  *telemetry.Telemetry
+
+	// coordinator steals queued work between the instances, led by one of
+	// them at a time
+	coordinator *distributed.Coordinator
+	eventBus    events.EventBus
+	redis       *redis.Client
 }
 
 func New(cfg *config.Config, log logger.Logger) (*Server, error) {
+	// Initialize tracing
+	tel := telemetry.Setup(cfg.Telemetry, "executor-service", log)
+
 	// Create worker pool
 	pool, err := worker.NewPool(cfg, log)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create worker pool: %w", err)
 	}
 
+	// The coordinator of every instance follows all the worker events, so
+	// whichever instance takes the lease has them
+	busConfig := cfg.Kafka.ToKafkaConfig()
+	busConfig.Broadcast = true
+	eventBus, err := events.New(busConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event bus: %w", err)
+	}
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+		PoolSize: cfg.Redis.PoolSize,
+	})
+
+	registry := distributed.NewWorkerRegistry(distributed.NewRedisBackend(redisClient, "", log), log)
+	coordinator := distributed.NewCoordinator(distributed.CoordinatorConfig{}, registry, redisClient, eventBus, log)
+
+	// Readiness reports the workers and the dependencies they need
+	checker := health.NewChecker("executor-service")
+	pool.RegisterHealthChecks(checker)
+
 	// Setup HTTP server for health checks
-	router := setupRouter(pool, log)
+	router := setupRouter(pool, checker, log)
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -38,29 +77,28 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	}
 
 	return &Server{
-		config:     cfg,
-		logger:     log,
-		httpServer: httpServer,
-		pool:       pool,
+		config:      cfg,
+		logger:      log,
+		httpServer:  httpServer,
+		pool:        pool,
+		telemetry:   tel,
+		coordinator: coordinator,
+		eventBus:    eventBus,
+		redis:       redisClient,
 	}, nil
 }
 
-func setupRouter(pool *worker.Pool, log logger.Logger) *gin.Engine {
+func setupRouter(pool *worker.Pool, checker *health.Checker, log logger.Logger) *gin.Engine {
 	router := gin.New()
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
 
 	// Health endpoints
 	router.GET("/health/live", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "alive"})
 	})
 
-	router.GET("/health/ready", func(c *gin.Context) {
-		if pool.Size() > 0 {
-			c.JSON(http.StatusOK, gin.H{"status": "ready", "workers": pool.Size()})
-		} else {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready"})
-		}
-	})
+	router.GET("/health/ready", checker.ReadyHandler())
 
 	// Metrics endpoint
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
@@ -83,6 +121,10 @@ func (s *Server) Start() error {
 		return fmt.Errorf("failed to start worker pool: %w", err)
 	}
 
+	if err := s.coordinator.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start coordinator: %w", err)
+	}
+
 	// Start HTTP server
 	s.logger.Info("Starting HTTP server", "port", s.config.Server.Port)
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -92,6 +134,13 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// ApplyConfig applies reloaded tunables to the running service
+func (s *Server) ApplyConfig(prev, next *config.Config) {
+	if next.Executor.Workers != prev.Executor.Workers {
+		s.pool.Resize(next.Executor.Workers)
+	}
+}
+
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("Shutting down executor server...")
 
@@ -100,10 +149,26 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.logger.Error("Failed to shutdown HTTP server", "error", err)
 	}
 
+	// Hand the coordinator lease over before the workers go
+	if err := s.coordinator.Stop(ctx); err != nil {
+		s.logger.Error("Failed to stop coordinator", "error", err)
+	}
+	if err := s.eventBus.Close(); err != nil {
+		s.logger.Error("Failed to close event bus", "error", err)
+	}
+	if err := s.redis.Close(); err != nil {
+		s.logger.Error("Failed to close Redis", "error", err)
+	}
+
 	// Shutdown worker pool
 	if err := s.pool.Shutdown(ctx); err != nil {
 		s.logger.Error("Failed to shutdown worker pool", "error", err)
 	}
 
+	// Flush pending spans
+	if err := s.telemetry.Close(); err != nil {
+		s.logger.Error("Failed to close telemetry", "error", err)
+	}
+
 	return nil
 }
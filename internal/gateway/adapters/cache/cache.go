@@ -0,0 +1,284 @@
+// Package cache serves read-heavy REST routes of the services through the
+// gateway from Redis. Responses are cached per caller with an ETag, so
+// clients revalidating with If-None-Match get a 304 without a body, and
+// entries are dropped when the events of the bus report a change.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix = "gateway:cache:"
+	tagPrefix = "gateway:cache:tag:"
+
+	// maxBodySize bounds the responses cached, larger ones are passed
+	// through
+	maxBodySize = 1 << 20
+
+	// StatusHeader tells whether a response came from the cache
+	StatusHeader = "X-Cache"
+)
+
+// Authenticator returns the user a token belongs to, or an error when it
+// is not valid
+type Authenticator func(ctx context.Context, token string) (string, error)
+
+// TagFunc names the tags of a request, the entry is dropped when one of
+// them is invalidated
+type TagFunc func(c *gin.Context) []string
+
+// Cache is a read-through response cache in Redis
+type Cache struct {
+	redis        *redis.Client
+	client       *http.Client
+	ttl          time.Duration
+	authenticate Authenticator
+	logger       logger.Logger
+}
+
+// entry is a cached response
+type entry struct {
+	ETag        string `json:"etag"`
+	ContentType string `json:"contentType"`
+	Body        []byte `json:"body"`
+}
+
+// New creates a cache keeping responses for ttl. Callers are told apart by
+// the user their token authenticates, requests with an invalid token are
+// passed through uncached.
+func New(redisClient *redis.Client, client *http.Client, ttl time.Duration, authenticate Authenticator, log logger.Logger) *Cache {
+	return &Cache{
+		redis:        redisClient,
+		client:       client,
+		ttl:          ttl,
+		authenticate: authenticate,
+		logger:       log,
+	}
+}
+
+// Handler serves GET requests from the service at baseURL, the path and
+// query are forwarded as received
+func (c *Cache) Handler(baseURL string, tags TagFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, ok := c.caller(ctx)
+		if !ok {
+			c.proxy(ctx, baseURL)
+			return
+		}
+
+		key := keyPrefix + hash(user+"\n"+ctx.Request.URL.RequestURI())
+		if e, err := c.get(ctx.Request.Context(), key); err == nil && e != nil {
+			ctx.Header(StatusHeader, "HIT")
+			c.respond(ctx, e)
+			return
+		} else if err != nil {
+			c.logger.Warn("Failed to read cached response", "error", err)
+		}
+
+		resp, err := c.forward(ctx, baseURL)
+		if err != nil {
+			ctx.JSON(http.StatusBadGateway, gin.H{"error": "service unavailable"})
+			return
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
+		if err != nil {
+			ctx.JSON(http.StatusBadGateway, gin.H{"error": "service unavailable"})
+			return
+		}
+
+		// Errors and oversized bodies are not cached
+		if resp.StatusCode != http.StatusOK || len(body) > maxBodySize {
+			ctx.Header(StatusHeader, "BYPASS")
+			body = append(body, mustRead(resp.Body)...)
+			ctx.Data(resp.StatusCode, resp.Header.Get("Content-Type"), body)
+			return
+		}
+
+		e := &entry{
+			ETag:        etag(body),
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        body,
+		}
+		if err := c.set(ctx.Request.Context(), key, e, tags(ctx)); err != nil {
+			c.logger.Warn("Failed to cache response", "error", err)
+		}
+
+		ctx.Header(StatusHeader, "MISS")
+		c.respond(ctx, e)
+	}
+}
+
+// Invalidate drops the entries of a tag
+func (c *Cache) Invalidate(ctx context.Context, tag string) error {
+	tagKey := tagPrefix + tag
+	keys, err := c.redis.SMembers(ctx, tagKey).Result()
+	if err != nil {
+		return err
+	}
+	return c.redis.Del(ctx, append(keys, tagKey)...).Err()
+}
+
+// InvalidateOn drops the entries tagged by tag(event) for each event of
+// the topics
+func (c *Cache) InvalidateOn(bus events.EventBus, topics []string, tag func(events.Event) string) error {
+	for _, topic := range topics {
+		err := bus.Subscribe(topic, func(ctx context.Context, event events.Event) error {
+			t := tag(event)
+			if t == "" {
+				return nil
+			}
+			if err := c.Invalidate(ctx, t); err != nil {
+				c.logger.Warn("Failed to invalidate cached responses", "tag", t, "error", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+// caller returns the user of the request, empty for anonymous ones. It is
+// not ok when the request carries a token that does not authenticate.
+func (c *Cache) caller(ctx *gin.Context) (string, bool) {
+	header := ctx.GetHeader("Authorization")
+	if header == "" {
+		return "", true
+	}
+
+	token := strings.TrimPrefix(header, "Bearer ")
+	if token == header {
+		return "", false
+	}
+	user, err := c.authenticate(ctx.Request.Context(), token)
+	if err != nil {
+		return "", false
+	}
+	return user, true
+}
+
+// respond writes an entry, or 304 when the client has it already
+func (c *Cache) respond(ctx *gin.Context, e *entry) {
+	ctx.Header("ETag", e.ETag)
+	ctx.Header("Cache-Control", "private, no-cache")
+	if matches(ctx.GetHeader("If-None-Match"), e.ETag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	ctx.Data(http.StatusOK, e.ContentType, e.Body)
+}
+
+// proxy passes a request through uncached
+func (c *Cache) proxy(ctx *gin.Context, baseURL string) {
+	resp, err := c.forward(ctx, baseURL)
+	if err != nil {
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "service unavailable"})
+		return
+	}
+	defer resp.Body.Close()
+
+	ctx.Header(StatusHeader, "BYPASS")
+	ctx.DataFromReader(resp.StatusCode, resp.ContentLength, resp.Header.Get("Content-Type"), resp.Body, nil)
+}
+
+// forward sends a request to the service with the headers it needs
+func (c *Cache) forward(ctx *gin.Context, baseURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx.Request.Context(), http.MethodGet, baseURL+ctx.Request.URL.RequestURI(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range []string{"Authorization", "Accept", logger.CorrelationIDHeader} {
+		if value := ctx.GetHeader(header); value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.logger.Error("Failed to reach service", "url", req.URL.String(), "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Cache) get(ctx context.Context, key string) (*entry, error) {
+	data, err := c.redis.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// set stores an entry and adds it to its tags, which live as long as the
+// entries they list
+func (c *Cache) set(ctx context.Context, key string, e *entry, tags []string) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.redis.TxPipeline()
+	pipe.Set(ctx, key, data, c.ttl)
+	for _, tag := range tags {
+		pipe.SAdd(ctx, tagPrefix+tag, key)
+		pipe.Expire(ctx, tagPrefix+tag, c.ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// matches reports whether an If-None-Match header lists the ETag, weak
+// validators compare equal to strong ones
+func matches(header, tag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func etag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// mustRead returns what is left of a body, an error ends it early
+func mustRead(r io.Reader) []byte {
+	data, _ := io.ReadAll(r)
+	return data
+}
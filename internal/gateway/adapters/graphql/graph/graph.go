@@ -1,4 +1,19 @@
 package graph
 
+import (
+	_ "embed"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
 // This file will be used for GraphQL schema definitions
 // The actual schema would be generated using gqlgen
+
+//go:embed schema.graphqls
+var schemaSource string
+
+// Schema parses the gateway schema, operations are validated against it
+func Schema() (*ast.Schema, error) {
+	return gqlparser.LoadSchema(&ast.Source{Name: "schema.graphqls", Input: schemaSource})
+}
@@ -0,0 +1,275 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/tenant"
+)
+
+// feedBufferSize bounds the events waiting for a subscriber, further events
+// are dropped until it catches up
+const feedBufferSize = 64
+
+// executionTopics are the events streamed to executionUpdates subscribers
+var executionTopics = []string{
+	events.ExecutionStarted,
+	events.ExecutionStateChanged,
+	events.ExecutionCompleted,
+	events.ExecutionFailed,
+	events.ExecutionCancelled,
+	events.NodeExecutionStarted,
+	events.NodeExecutionCompleted,
+	events.NodeExecutionFailed,
+	events.NodeLog,
+}
+
+// workflowTopics are the events streamed to workflowChanged subscribers
+var workflowTopics = []string{
+	events.WorkflowUpdated,
+	events.WorkflowDeleted,
+	events.WorkflowActivated,
+	events.WorkflowDeactivated,
+	"workflow.version.rollback",
+}
+
+// notificationTopics are the events streamed to the notifications and
+// unreadNotificationCount subscribers of their user
+var notificationTopics = []string{
+	events.NotificationCreated,
+	events.NotificationUnreadChanged,
+}
+
+// feed fans the events of the bus out to the subscriptions of this gateway,
+// keyed by the execution, workflow or user they are about
+type feed struct {
+	mu   sync.RWMutex
+	subs map[string]map[chan events.Event]struct{}
+}
+
+func newFeed() *feed {
+	return &feed{subs: make(map[string]map[chan events.Event]struct{})}
+}
+
+// subscribe returns the events about key, until cancel is called
+func (f *feed) subscribe(key string) (<-chan events.Event, func()) {
+	ch := make(chan events.Event, feedBufferSize)
+
+	f.mu.Lock()
+	if f.subs[key] == nil {
+		f.subs[key] = make(map[chan events.Event]struct{})
+	}
+	f.subs[key][ch] = struct{}{}
+	f.mu.Unlock()
+
+	cancel := func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.subs[key], ch)
+		if len(f.subs[key]) == 0 {
+			delete(f.subs, key)
+		}
+	}
+	return ch, cancel
+}
+
+func (f *feed) dispatch(key string, event events.Event) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for ch := range f.subs[key] {
+		// A slow subscriber must not hold up the others
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// StreamEvents subscribes to the execution, workflow and notification events
+// of the bus, feeding the subscriptions. Every gateway instance needs all of
+// them, so the bus should be a broadcast one.
+func (r *Resolver) StreamEvents(bus events.EventBus) error {
+	f := newFeed()
+
+	for _, topic := range executionTopics {
+		err := bus.Subscribe(topic, func(ctx context.Context, event events.Event) error {
+			if id := executionIDOf(event); id != "" {
+				f.dispatch("execution:"+id, event)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+		}
+	}
+
+	for _, topic := range workflowTopics {
+		err := bus.Subscribe(topic, func(ctx context.Context, event events.Event) error {
+			if id, _ := event.Payload["workflow_id"].(string); id != "" {
+				f.dispatch("workflow:"+id, event)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+		}
+	}
+
+	for _, topic := range notificationTopics {
+		err := bus.Subscribe(topic, func(ctx context.Context, event events.Event) error {
+			if id, _ := event.Payload["userId"].(string); id != "" {
+				f.dispatch("user:"+id, event)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+		}
+	}
+
+	r.feed = f
+	return nil
+}
+
+// executionIDOf returns the execution an event is about, node events carry
+// it in the payload
+func executionIDOf(event events.Event) string {
+	if strings.HasPrefix(event.Type, "node.") {
+		id, _ := event.Payload["executionId"].(string)
+		return id
+	}
+	return event.AggregateID
+}
+
+// executionUpdateFromEvent converts an execution or node event to the
+// update sent to subscribers
+func executionUpdateFromEvent(executionID string, event events.Event) *ExecutionUpdate {
+	update := &ExecutionUpdate{
+		ExecutionID: executionID,
+		Event:       event.Type,
+		Data:        event.Payload,
+		Timestamp:   event.Timestamp,
+	}
+	if nodeID, ok := event.Payload["nodeId"].(string); ok {
+		update.NodeID = &nodeID
+	}
+
+	switch event.Type {
+	case events.ExecutionStarted, events.NodeExecutionStarted, events.NodeLog:
+		update.Status = ExecutionStatusRunning
+	case events.ExecutionCompleted:
+		update.Status = ExecutionStatusCompleted
+	case events.ExecutionFailed, events.NodeExecutionFailed:
+		update.Status = ExecutionStatusFailed
+	case events.ExecutionCancelled:
+		update.Status = ExecutionStatusCancelled
+	case events.ExecutionStateChanged:
+		state, _ := event.Payload["toState"].(string)
+		if state == "" {
+			state, _ = event.Payload["state"].(string)
+		}
+		update.Status = ExecutionStatus(strings.ToUpper(state))
+	default:
+		status, _ := event.Payload["status"].(string)
+		update.Status = ExecutionStatus(strings.ToUpper(status))
+	}
+	return update
+}
+
+// workflowChangeFromEvent converts a workflow event to the change sent to
+// subscribers
+func workflowChangeFromEvent(workflowID string, event events.Event) *WorkflowChange {
+	change := &WorkflowChange{
+		WorkflowID: workflowID,
+		ChangeType: strings.TrimPrefix(event.Type, "workflow."),
+		Timestamp:  event.Timestamp,
+	}
+	if userID, ok := event.Payload["user_id"].(string); ok {
+		change.ChangedBy = &userID
+	}
+	// Numbers decode from the wire as float64
+	if version, ok := event.Payload["version"].(float64); ok {
+		change.Version = toIntPtr(int(version))
+	}
+	return change
+}
+
+// notificationFromEvent converts a notification.created event to the
+// notification sent to subscribers
+func notificationFromEvent(event events.Event) *Notification {
+	n := &Notification{ID: event.AggregateID, CreatedAt: event.Timestamp}
+	n.Type, _ = event.Payload["type"].(string)
+	n.Title, _ = event.Payload["subject"].(string)
+	n.Message, _ = event.Payload["body"].(string)
+	n.Data, _ = event.Payload["data"].(map[string]interface{})
+	return n
+}
+
+// unreadCountOf returns the unread count a notification event carries
+func unreadCountOf(event events.Event) (int, bool) {
+	// Numbers decode from the wire as float64
+	switch count := event.Payload["unreadCount"].(type) {
+	case float64:
+		return int(count), true
+	case int64:
+		return int(count), true
+	case int:
+		return count, true
+	}
+	return 0, false
+}
+
+// isFinal reports whether the execution is over, ending its subscriptions
+func isFinal(event events.Event) bool {
+	switch event.Type {
+	case events.ExecutionCompleted, events.ExecutionFailed, events.ExecutionCancelled:
+		return true
+	}
+	return false
+}
+
+type authContextKey struct{}
+
+// WithAuth returns a context carrying the authenticated user, scoped to the
+// tenant of its token, and the token forwarded to the services when
+// checking their access
+func WithAuth(ctx context.Context, userID, tenantID, token string) context.Context {
+	if !tenant.Valid(tenantID) {
+		tenantID = tenant.DefaultTenant
+	}
+	ctx = tenant.WithTenant(ctx, tenantID)
+	ctx = context.WithValue(ctx, "userID", userID)
+	return context.WithValue(ctx, authContextKey{}, token)
+}
+
+// authorize checks the caller may read the resource at url, asking the
+// service owning it with the caller's token
+func (r *Resolver) authorize(ctx context.Context, client *http.Client, url string) error {
+	token, _ := ctx.Value(authContextKey{}).(string)
+	if token == "" {
+		return apperrors.New(apperrors.CategoryAuth, apperrors.CodeUnauthenticated, "unauthorized")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to check access: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return apperrors.FromResponse(resp)
+	}
+	return nil
+}
@@ -6,6 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	neturl "net/url"
+
+	workflowDomain "github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
 )
 
 // Login authenticates a user
@@ -13,14 +17,14 @@ func (r *mutationResolver) Login(ctx context.Context, input LoginInput) (*AuthPa
 	url := fmt.Sprintf("%s/api/v1/auth/login", r.baseURLs["auth"])
 
 	body, _ := json.Marshal(input)
-	resp, err := r.clients.AuthClient.Post(url, "application/json", bytes.NewBuffer(body))
+	resp, err := post(ctx, r.clients.AuthClient, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to login: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("invalid credentials")
+		return nil, apperrors.FromResponse(resp)
 	}
 
 	var payload AuthPayload
@@ -36,14 +40,14 @@ func (r *mutationResolver) Register(ctx context.Context, input RegisterInput) (*
 	url := fmt.Sprintf("%s/api/v1/auth/register", r.baseURLs["auth"])
 
 	body, _ := json.Marshal(input)
-	resp, err := r.clients.AuthClient.Post(url, "application/json", bytes.NewBuffer(body))
+	resp, err := post(ctx, r.clients.AuthClient, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to register: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("registration failed")
+		return nil, apperrors.FromResponse(resp)
 	}
 
 	var payload AuthPayload
@@ -59,14 +63,14 @@ func (r *mutationResolver) CreateWorkflow(ctx context.Context, input CreateWorkf
 	url := fmt.Sprintf("%s/api/v1/workflows", r.baseURLs["workflow"])
 
 	body, _ := json.Marshal(input)
-	resp, err := r.clients.WorkflowClient.Post(url, "application/json", bytes.NewBuffer(body))
+	resp, err := post(ctx, r.clients.WorkflowClient, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create workflow: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("failed to create workflow")
+		return nil, apperrors.FromResponse(resp)
 	}
 
 	var workflow Workflow
@@ -92,7 +96,7 @@ func (r *mutationResolver) UpdateWorkflow(ctx context.Context, id string, input
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to update workflow")
+		return nil, apperrors.FromResponse(resp)
 	}
 
 	var workflow Workflow
@@ -117,19 +121,97 @@ func (r *mutationResolver) DeleteWorkflow(ctx context.Context, id string) (bool,
 	return resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusOK, nil
 }
 
-// ExecuteWorkflow executes a workflow
-func (r *mutationResolver) ExecuteWorkflow(ctx context.Context, workflowID string, input map[string]interface{}) (*Execution, error) {
+// ActivateWorkflow activates a workflow and its triggers, the workflow
+// returned carries the new status and version
+func (r *mutationResolver) ActivateWorkflow(ctx context.Context, id string) (*Workflow, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/activate", r.baseURLs["workflow"], id)
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, nil)
+
+	return r.workflow(req, "activate workflow")
+}
+
+// DeactivateWorkflow deactivates a workflow and its triggers, the workflow
+// returned carries the new status and version
+func (r *mutationResolver) DeactivateWorkflow(ctx context.Context, id string) (*Workflow, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/deactivate", r.baseURLs["workflow"], id)
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, nil)
+
+	return r.workflow(req, "deactivate workflow")
+}
+
+// workflow sends a request answered with the workflow it changed
+func (r *mutationResolver) workflow(req *http.Request, action string) (*Workflow, error) {
+	resp, err := r.clients.WorkflowClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.FromResponse(resp)
+	}
+
+	var workflow Workflow
+	if err := json.NewDecoder(resp.Body).Decode(&workflow); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &workflow, nil
+}
+
+// ActivateTrigger activates a trigger of an active workflow
+func (r *mutationResolver) ActivateTrigger(ctx context.Context, workflowID, id string) (*Trigger, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/triggers/%s/activate", r.baseURLs["workflow"], workflowID, id)
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, nil)
+
+	return r.trigger(req, "activate trigger")
+}
+
+// DeactivateTrigger deactivates a trigger
+func (r *mutationResolver) DeactivateTrigger(ctx context.Context, workflowID, id string) (*Trigger, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/triggers/%s/deactivate", r.baseURLs["workflow"], workflowID, id)
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, nil)
+
+	return r.trigger(req, "deactivate trigger")
+}
+
+// trigger sends a request answered with the trigger it changed
+func (r *mutationResolver) trigger(req *http.Request, action string) (*Trigger, error) {
+	resp, err := r.clients.WorkflowClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.FromResponse(resp)
+	}
+
+	var trigger workflowDomain.WorkflowTrigger
+	if err := json.NewDecoder(resp.Body).Decode(&trigger); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return TriggerFromDomain(&trigger), nil
+}
+
+// ExecuteWorkflow executes a workflow, a debug run logs verbosely
+func (r *mutationResolver) ExecuteWorkflow(ctx context.Context, workflowID string, input map[string]interface{}, debug *bool) (*Execution, error) {
 	url := fmt.Sprintf("%s/api/v1/workflows/%s/execute", r.baseURLs["execution"], workflowID)
 
-	body, _ := json.Marshal(map[string]interface{}{"input": input})
-	resp, err := r.clients.ExecutionClient.Post(url, "application/json", bytes.NewBuffer(body))
+	body, _ := json.Marshal(map[string]interface{}{"input": input, "debug": debug != nil && *debug})
+	resp, err := post(ctx, r.clients.ExecutionClient, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute workflow: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("failed to execute workflow")
+		return nil, apperrors.FromResponse(resp)
 	}
 
 	var execution Execution
@@ -144,7 +226,7 @@ func (r *mutationResolver) ExecuteWorkflow(ctx context.Context, workflowID strin
 func (r *mutationResolver) CancelExecution(ctx context.Context, id string) (*Execution, error) {
 	url := fmt.Sprintf("%s/api/v1/executions/%s/cancel", r.baseURLs["execution"], id)
 
-	resp, err := r.clients.ExecutionClient.Post(url, "application/json", nil)
+	resp, err := post(ctx, r.clients.ExecutionClient, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to cancel execution: %w", err)
 	}
@@ -163,14 +245,14 @@ func (r *mutationResolver) CreateCredential(ctx context.Context, input CreateCre
 	url := fmt.Sprintf("%s/api/v1/credentials", r.baseURLs["credential"])
 
 	body, _ := json.Marshal(input)
-	resp, err := r.clients.CredentialClient.Post(url, "application/json", bytes.NewBuffer(body))
+	resp, err := post(ctx, r.clients.CredentialClient, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create credential: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("failed to create credential")
+		return nil, apperrors.FromResponse(resp)
 	}
 
 	var credential Credential
@@ -200,14 +282,14 @@ func (r *mutationResolver) CreateSchedule(ctx context.Context, input CreateSched
 	url := fmt.Sprintf("%s/api/v1/schedules", r.baseURLs["schedule"])
 
 	body, _ := json.Marshal(input)
-	resp, err := r.clients.ScheduleClient.Post(url, "application/json", bytes.NewBuffer(body))
+	resp, err := post(ctx, r.clients.ScheduleClient, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create schedule: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("failed to create schedule")
+		return nil, apperrors.FromResponse(resp)
 	}
 
 	var schedule Schedule
@@ -245,7 +327,7 @@ func (r *mutationResolver) SetVariable(ctx context.Context, key string, value st
 	}
 
 	body, _ := json.Marshal(input)
-	resp, err := r.clients.VariableClient.Post(url, "application/json", bytes.NewBuffer(body))
+	resp, err := post(ctx, r.clients.VariableClient, url, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to set variable: %w", err)
 	}
@@ -272,3 +354,117 @@ func (r *mutationResolver) DeleteVariable(ctx context.Context, key string) (bool
 
 	return resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusOK, nil
 }
+
+// SetWorkflowVariable creates or replaces a variable of a workflow
+func (r *mutationResolver) SetWorkflowVariable(ctx context.Context, workflowID string, input WorkflowVariableInput) (*WorkflowVariable, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/variables/%s", r.baseURLs["workflow"], workflowID, neturl.PathEscape(input.Key))
+
+	body, _ := json.Marshal(input)
+	req, _ := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.clients.WorkflowClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set workflow variable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.FromResponse(resp)
+	}
+
+	var variable workflowDomain.WorkflowVariable
+	if err := json.NewDecoder(resp.Body).Decode(&variable); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return WorkflowVariableFromDomain(&variable), nil
+}
+
+// DeleteWorkflowVariable deletes a variable of a workflow
+func (r *mutationResolver) DeleteWorkflowVariable(ctx context.Context, workflowID, key string) (bool, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/variables/%s", r.baseURLs["workflow"], workflowID, neturl.PathEscape(key))
+
+	req, _ := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	resp, err := r.clients.WorkflowClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete workflow variable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return false, apperrors.FromResponse(resp)
+	}
+
+	return true, nil
+}
+
+// CreateEnvironment creates an environment of a workflow
+func (r *mutationResolver) CreateEnvironment(ctx context.Context, workflowID string, input EnvironmentInput) (*Environment, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/environments", r.baseURLs["workflow"], workflowID)
+
+	body, _ := json.Marshal(input)
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	return r.environment(req, http.StatusCreated, "create environment")
+}
+
+// UpdateEnvironment changes the name, description or variables of an
+// environment
+func (r *mutationResolver) UpdateEnvironment(ctx context.Context, workflowID, id string, input EnvironmentInput) (*Environment, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/environments/%s", r.baseURLs["workflow"], workflowID, id)
+
+	body, _ := json.Marshal(input)
+	req, _ := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	return r.environment(req, http.StatusOK, "update environment")
+}
+
+// DeleteEnvironment deletes an environment other than the default one
+func (r *mutationResolver) DeleteEnvironment(ctx context.Context, workflowID, id string) (bool, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/environments/%s", r.baseURLs["workflow"], workflowID, id)
+
+	req, _ := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	resp, err := r.clients.WorkflowClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete environment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return false, apperrors.FromResponse(resp)
+	}
+
+	return true, nil
+}
+
+// SetDefaultEnvironment makes an environment the default of its workflow
+func (r *mutationResolver) SetDefaultEnvironment(ctx context.Context, workflowID, id string) (*Environment, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/environments/%s/default", r.baseURLs["workflow"], workflowID, id)
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, nil)
+
+	return r.environment(req, http.StatusOK, "set default environment")
+}
+
+// environment sends a request answered with an environment
+func (r *mutationResolver) environment(req *http.Request, status int, action string) (*Environment, error) {
+	resp, err := r.clients.WorkflowClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != status {
+		return nil, apperrors.FromResponse(resp)
+	}
+
+	var env workflowDomain.Environment
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return EnvironmentFromDomain(&env), nil
+}
@@ -0,0 +1,45 @@
+package resolver
+
+import (
+	"net/url"
+	"strconv"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// ErrBackwardPagination is returned for last and before, the services only
+// page forward
+var ErrBackwardPagination = apperrors.New(apperrors.CategoryValidation, "BACKWARD_PAGINATION", "last and before are not supported, page with first and after")
+
+// pageQuery turns the connection arguments into the limit and cursor query
+// parameters of the list endpoints
+func pageQuery(pagination *PaginationInput) (url.Values, error) {
+	query := url.Values{}
+	if pagination == nil {
+		return query, nil
+	}
+	if pagination.Last != nil || pagination.Before != nil {
+		return nil, ErrBackwardPagination
+	}
+	if pagination.First != nil {
+		query.Set("limit", strconv.Itoa(*pagination.First))
+	}
+	if pagination.After != nil {
+		query.Set("cursor", *pagination.After)
+	}
+	return query, nil
+}
+
+// pageInfo describes the page of edges with the cursors given, next is the
+// cursor the service returned for the following page
+func pageInfo(pagination *PaginationInput, cursors []string, next string) *PageInfo {
+	info := &PageInfo{
+		HasNextPage:     next != "",
+		HasPreviousPage: pagination != nil && pagination.After != nil && *pagination.After != "",
+	}
+	if len(cursors) > 0 {
+		info.StartCursor = &cursors[0]
+		info.EndCursor = &cursors[len(cursors)-1]
+	}
+	return info
+}
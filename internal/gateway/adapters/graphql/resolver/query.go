@@ -6,6 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+
+	workflowDomain "github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/database"
+	apperrors "github.com/linkflow-go/pkg/errors"
 )
 
 // Me returns the current user
@@ -13,7 +18,7 @@ func (r *queryResolver) Me(ctx context.Context) (*User, error) {
 	// Get user from context (set by auth middleware)
 	userID := ctx.Value("userID")
 	if userID == nil {
-		return nil, fmt.Errorf("unauthorized")
+		return nil, apperrors.New(apperrors.CategoryAuth, apperrors.CodeUnauthenticated, "unauthorized")
 	}
 
 	return r.User(ctx, userID.(string))
@@ -23,14 +28,14 @@ func (r *queryResolver) Me(ctx context.Context) (*User, error) {
 func (r *queryResolver) User(ctx context.Context, id string) (*User, error) {
 	url := fmt.Sprintf("%s/api/v1/users/%s", r.baseURLs["auth"], id)
 
-	resp, err := r.clients.AuthClient.Get(url)
+	resp, err := get(ctx, r.clients.AuthClient, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch user: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("user not found")
+		return nil, apperrors.FromResponse(resp)
 	}
 
 	var user User
@@ -45,14 +50,14 @@ func (r *queryResolver) User(ctx context.Context, id string) (*User, error) {
 func (r *queryResolver) Workflow(ctx context.Context, id string) (*Workflow, error) {
 	url := fmt.Sprintf("%s/api/v1/workflows/%s", r.baseURLs["workflow"], id)
 
-	resp, err := r.clients.WorkflowClient.Get(url)
+	resp, err := get(ctx, r.clients.WorkflowClient, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch workflow: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("workflow not found")
+		return nil, apperrors.FromResponse(resp)
 	}
 
 	var workflow Workflow
@@ -63,39 +68,51 @@ func (r *queryResolver) Workflow(ctx context.Context, id string) (*Workflow, err
 	return &workflow, nil
 }
 
-// Workflows returns a list of workflows
+// Workflows returns a page of workflows, most recently updated first
 func (r *queryResolver) Workflows(ctx context.Context, filter *WorkflowFilter, pagination *PaginationInput) (*WorkflowConnection, error) {
-	url := fmt.Sprintf("%s/api/v1/workflows", r.baseURLs["workflow"])
+	query, err := pageQuery(pagination)
+	if err != nil {
+		return nil, err
+	}
+	if filter != nil && filter.Status != nil {
+		query.Set("status", strings.ToLower(string(*filter.Status)))
+	}
+	url := fmt.Sprintf("%s/api/v1/workflows?%s", r.baseURLs["workflow"], query.Encode())
 
-	resp, err := r.clients.WorkflowClient.Get(url)
+	resp, err := get(ctx, r.clients.WorkflowClient, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch workflows: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.FromResponse(resp)
+	}
+
 	var result struct {
-		Data  []Workflow `json:"data"`
-		Total int        `json:"total"`
+		Workflows  []Workflow `json:"workflows"`
+		Total      int        `json:"total"`
+		NextCursor string     `json:"nextCursor"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode workflows: %w", err)
 	}
 
-	edges := make([]*WorkflowEdge, len(result.Data))
-	for i := range result.Data {
+	edges := make([]*WorkflowEdge, len(result.Workflows))
+	cursors := make([]string, len(result.Workflows))
+	for i := range result.Workflows {
+		// The workflow service pages by updated_at
+		cursors[i] = database.CursorOf(result.Workflows[i].UpdatedAt, result.Workflows[i].ID)
 		edges[i] = &WorkflowEdge{
-			Node:   &result.Data[i],
-			Cursor: result.Data[i].ID,
+			Node:   &result.Workflows[i],
+			Cursor: cursors[i],
 		}
 	}
 
 	return &WorkflowConnection{
 		Edges:      edges,
 		TotalCount: result.Total,
-		PageInfo: &PageInfo{
-			HasNextPage:     false,
-			HasPreviousPage: false,
-		},
+		PageInfo:   pageInfo(pagination, cursors, result.NextCursor),
 	}, nil
 }
 
@@ -103,14 +120,14 @@ func (r *queryResolver) Workflows(ctx context.Context, filter *WorkflowFilter, p
 func (r *queryResolver) Execution(ctx context.Context, id string) (*Execution, error) {
 	url := fmt.Sprintf("%s/api/v1/executions/%s", r.baseURLs["execution"], id)
 
-	resp, err := r.clients.ExecutionClient.Get(url)
+	resp, err := get(ctx, r.clients.ExecutionClient, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch execution: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("execution not found")
+		return nil, apperrors.FromResponse(resp)
 	}
 
 	var execution Execution
@@ -121,39 +138,54 @@ func (r *queryResolver) Execution(ctx context.Context, id string) (*Execution, e
 	return &execution, nil
 }
 
-// Executions returns a list of executions
+// Executions returns a page of executions, newest first
 func (r *queryResolver) Executions(ctx context.Context, filter *ExecutionFilter, pagination *PaginationInput) (*ExecutionConnection, error) {
-	url := fmt.Sprintf("%s/api/v1/executions", r.baseURLs["execution"])
+	query, err := pageQuery(pagination)
+	if err != nil {
+		return nil, err
+	}
+	if filter != nil && filter.WorkflowID != nil {
+		query.Set("workflowId", *filter.WorkflowID)
+	}
+	if filter != nil && filter.Status != nil {
+		query.Set("status", strings.ToLower(string(*filter.Status)))
+	}
+	url := fmt.Sprintf("%s/api/v1/executions?%s", r.baseURLs["execution"], query.Encode())
 
-	resp, err := r.clients.ExecutionClient.Get(url)
+	resp, err := get(ctx, r.clients.ExecutionClient, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch executions: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.FromResponse(resp)
+	}
+
 	var result struct {
-		Data  []Execution `json:"data"`
-		Total int         `json:"total"`
+		Executions []Execution `json:"executions"`
+		Total      int         `json:"total"`
+		NextCursor string      `json:"nextCursor"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode executions: %w", err)
 	}
 
-	edges := make([]*ExecutionEdge, len(result.Data))
-	for i := range result.Data {
+	edges := make([]*ExecutionEdge, len(result.Executions))
+	cursors := make([]string, len(result.Executions))
+	for i := range result.Executions {
+		// The execution service pages by created_at
+		cursors[i] = database.CursorOf(result.Executions[i].CreatedAt, result.Executions[i].ID)
 		edges[i] = &ExecutionEdge{
-			Node:   &result.Data[i],
-			Cursor: result.Data[i].ID,
+			Node:   &result.Executions[i],
+			Cursor: cursors[i],
 		}
 	}
 
 	return &ExecutionConnection{
 		Edges:      edges,
 		TotalCount: result.Total,
-		PageInfo: &PageInfo{
-			HasNextPage:     false,
-			HasPreviousPage: false,
-		},
+		PageInfo:   pageInfo(pagination, cursors, result.NextCursor),
 	}, nil
 }
 
@@ -190,7 +222,7 @@ func (r *queryResolver) NodeTypes(ctx context.Context) ([]*NodeType, error) {
 func (r *queryResolver) Credentials(ctx context.Context) ([]*Credential, error) {
 	url := fmt.Sprintf("%s/api/v1/credentials", r.baseURLs["credential"])
 
-	resp, err := r.clients.CredentialClient.Get(url)
+	resp, err := get(ctx, r.clients.CredentialClient, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch credentials: %w", err)
 	}
@@ -218,7 +250,7 @@ func (r *queryResolver) Schedules(ctx context.Context, workflowID *string) ([]*S
 		url = fmt.Sprintf("%s?workflowId=%s", url, *workflowID)
 	}
 
-	resp, err := r.clients.ScheduleClient.Get(url)
+	resp, err := get(ctx, r.clients.ScheduleClient, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch schedules: %w", err)
 	}
@@ -246,7 +278,7 @@ func (r *queryResolver) Webhooks(ctx context.Context, workflowID *string) ([]*We
 		url = fmt.Sprintf("%s?workflowId=%s", url, *workflowID)
 	}
 
-	resp, err := r.clients.WebhookClient.Get(url)
+	resp, err := get(ctx, r.clients.WebhookClient, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch webhooks: %w", err)
 	}
@@ -271,7 +303,7 @@ func (r *queryResolver) Webhooks(ctx context.Context, workflowID *string) ([]*We
 func (r *queryResolver) Variables(ctx context.Context) ([]*Variable, error) {
 	url := fmt.Sprintf("%s/api/v1/variables", r.baseURLs["variable"])
 
-	resp, err := r.clients.VariableClient.Get(url)
+	resp, err := get(ctx, r.clients.VariableClient, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch variables: %w", err)
 	}
@@ -292,11 +324,95 @@ func (r *queryResolver) Variables(ctx context.Context) ([]*Variable, error) {
 	return variables, nil
 }
 
+// WorkflowVariables returns the variables of a workflow
+func (r *queryResolver) WorkflowVariables(ctx context.Context, workflowID string) ([]*WorkflowVariable, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/variables", r.baseURLs["workflow"], workflowID)
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, err := r.clients.WorkflowClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workflow variables: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.FromResponse(resp)
+	}
+
+	var result struct {
+		Variables []*workflowDomain.WorkflowVariable `json:"variables"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode workflow variables: %w", err)
+	}
+
+	variables := make([]*WorkflowVariable, len(result.Variables))
+	for i, v := range result.Variables {
+		variables[i] = WorkflowVariableFromDomain(v)
+	}
+
+	return variables, nil
+}
+
+// Environments returns the environments of a workflow
+func (r *queryResolver) Environments(ctx context.Context, workflowID string) ([]*Environment, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/environments", r.baseURLs["workflow"], workflowID)
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, err := r.clients.WorkflowClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch environments: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.FromResponse(resp)
+	}
+
+	var result struct {
+		Environments []*workflowDomain.Environment `json:"environments"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode environments: %w", err)
+	}
+
+	environments := make([]*Environment, len(result.Environments))
+	for i, e := range result.Environments {
+		environments[i] = EnvironmentFromDomain(e)
+	}
+
+	return environments, nil
+}
+
+// Environment returns an environment of a workflow by ID
+func (r *queryResolver) Environment(ctx context.Context, workflowID, id string) (*Environment, error) {
+	url := fmt.Sprintf("%s/api/v1/workflows/%s/environments/%s", r.baseURLs["workflow"], workflowID, id)
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, err := r.clients.WorkflowClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch environment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.FromResponse(resp)
+	}
+
+	var env workflowDomain.Environment
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, fmt.Errorf("failed to decode environment: %w", err)
+	}
+
+	return EnvironmentFromDomain(&env), nil
+}
+
 // Dashboard returns analytics dashboard
 func (r *queryResolver) Dashboard(ctx context.Context) (*Dashboard, error) {
-	url := fmt.Sprintf("%s/api/v1/dashboard", r.baseURLs["analytics"])
+	url := fmt.Sprintf("%s/api/v1/analytics/dashboard", r.baseURLs["analytics"])
 
-	resp, err := r.clients.AnalyticsClient.Get(url)
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, err := r.clients.AnalyticsClient.Do(req)
 	if err != nil {
 		// Return default dashboard if analytics service is unavailable
 		return &Dashboard{
@@ -311,6 +427,10 @@ func (r *queryResolver) Dashboard(ctx context.Context) (*Dashboard, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, apperrors.FromResponse(resp)
+	}
+
 	body, _ := io.ReadAll(resp.Body)
 	var dashboard Dashboard
 	if err := json.Unmarshal(body, &dashboard); err != nil {
@@ -2,22 +2,28 @@ package resolver
 
 import (
 	"context"
+	"io"
 	"net/http"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/linkflow-go/pkg/config"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/telemetry"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 // ServiceClients holds HTTP clients for microservices
 type ServiceClients struct {
-	AuthClient       *http.Client
-	WorkflowClient   *http.Client
-	ExecutionClient  *http.Client
-	CredentialClient *http.Client
-	ScheduleClient   *http.Client
-	WebhookClient    *http.Client
-	VariableClient   *http.Client
-	AnalyticsClient  *http.Client
+	AuthClient         *http.Client
+	WorkflowClient     *http.Client
+	ExecutionClient    *http.Client
+	CredentialClient   *http.Client
+	ScheduleClient     *http.Client
+	WebhookClient      *http.Client
+	VariableClient     *http.Client
+	AnalyticsClient    *http.Client
+	NotificationClient *http.Client
 }
 
 // Resolver is the GraphQL resolver root
@@ -26,30 +32,45 @@ type Resolver struct {
 	logger   logger.Logger
 	clients  *ServiceClients
 	baseURLs map[string]string
+	// feed is set by StreamEvents, subscriptions need it
+	feed *feed
 }
 
-// NewResolver creates a new GraphQL resolver
-func NewResolver(cfg *config.Config, log logger.Logger) *Resolver {
+// NewResolver creates a new GraphQL resolver calling the services through
+// transport, http.DefaultTransport when nil
+func NewResolver(cfg *config.Config, log logger.Logger, transport http.RoundTripper) *Resolver {
+	// Traced transport propagates the gateway span to downstream services
+	transport = telemetry.NewTransport(transport)
 	clients := &ServiceClients{
-		AuthClient:       &http.Client{},
-		WorkflowClient:   &http.Client{},
-		ExecutionClient:  &http.Client{},
-		CredentialClient: &http.Client{},
-		ScheduleClient:   &http.Client{},
-		WebhookClient:    &http.Client{},
-		VariableClient:   &http.Client{},
-		AnalyticsClient:  &http.Client{},
+		AuthClient:         &http.Client{Transport: transport},
+		WorkflowClient:     &http.Client{Transport: transport},
+		ExecutionClient:    &http.Client{Transport: transport},
+		CredentialClient:   &http.Client{Transport: transport},
+		ScheduleClient:     &http.Client{Transport: transport},
+		WebhookClient:      &http.Client{Transport: transport},
+		VariableClient:     &http.Client{Transport: transport},
+		AnalyticsClient:    &http.Client{Transport: transport},
+		NotificationClient: &http.Client{Transport: transport},
 	}
 
 	baseURLs := map[string]string{
-		"auth":       "http://auth-service:8080",
-		"workflow":   "http://workflow-service:8080",
-		"execution":  "http://execution-service:8080",
-		"credential": "http://credential-service:8080",
-		"schedule":   "http://schedule-service:8080",
-		"webhook":    "http://webhook-service:8080",
-		"variable":   "http://variable-service:8080",
-		"analytics":  "http://analytics-service:8080",
+		"auth":         "http://auth-service:8080",
+		"workflow":     "http://workflow-service:8080",
+		"execution":    "http://execution-service:8080",
+		"credential":   "http://credential-service:8080",
+		"node":         "http://node-service:8080",
+		"schedule":     "http://schedule-service:8080",
+		"webhook":      "http://webhook-service:8080",
+		"variable":     "http://variable-service:8080",
+		"analytics":    "http://analytics-service:8080",
+		"notification": "http://notification-service:8080",
+	}
+	for name, url := range cfg.Gateway.Services {
+		if url == "" {
+			delete(baseURLs, name)
+			continue
+		}
+		baseURLs[name] = url
 	}
 
 	return &Resolver{
@@ -60,6 +81,43 @@ func NewResolver(cfg *config.Config, log logger.Logger) *Resolver {
 	}
 }
 
+// ServiceURLs returns the base URL of each downstream service by name
+func (r *Resolver) ServiceURLs() map[string]string {
+	urls := make(map[string]string, len(r.baseURLs))
+	for name, url := range r.baseURLs {
+		urls[name] = url
+	}
+	return urls
+}
+
+// get calls a service with the context of the request, which carries the
+// tenant of the caller on to the service
+func get(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// post sends a JSON body to a service with the context of the request
+func post(ctx context.Context, client *http.Client, url string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return client.Do(req)
+}
+
+// ErrorPresenter renders resolver errors with their code and category in
+// the extensions, matching the error bodies of the REST services
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := apperrors.ToGraphQL(err)
+	gqlErr.Path = graphql.GetPath(ctx)
+	return gqlErr
+}
+
 // Query returns the query resolver
 func (r *Resolver) Query() QueryResolver {
 	return &queryResolver{r}
@@ -88,6 +146,9 @@ type QueryResolver interface {
 	Schedules(ctx context.Context, workflowID *string) ([]*Schedule, error)
 	Webhooks(ctx context.Context, workflowID *string) ([]*Webhook, error)
 	Variables(ctx context.Context) ([]*Variable, error)
+	WorkflowVariables(ctx context.Context, workflowID string) ([]*WorkflowVariable, error)
+	Environments(ctx context.Context, workflowID string) ([]*Environment, error)
+	Environment(ctx context.Context, workflowID, id string) (*Environment, error)
 	Dashboard(ctx context.Context) (*Dashboard, error)
 }
 
@@ -98,7 +159,11 @@ type MutationResolver interface {
 	CreateWorkflow(ctx context.Context, input CreateWorkflowInput) (*Workflow, error)
 	UpdateWorkflow(ctx context.Context, id string, input UpdateWorkflowInput) (*Workflow, error)
 	DeleteWorkflow(ctx context.Context, id string) (bool, error)
-	ExecuteWorkflow(ctx context.Context, workflowID string, input map[string]interface{}) (*Execution, error)
+	ActivateWorkflow(ctx context.Context, id string) (*Workflow, error)
+	DeactivateWorkflow(ctx context.Context, id string) (*Workflow, error)
+	ActivateTrigger(ctx context.Context, workflowID, id string) (*Trigger, error)
+	DeactivateTrigger(ctx context.Context, workflowID, id string) (*Trigger, error)
+	ExecuteWorkflow(ctx context.Context, workflowID string, input map[string]interface{}, debug *bool) (*Execution, error)
 	CancelExecution(ctx context.Context, id string) (*Execution, error)
 	CreateCredential(ctx context.Context, input CreateCredentialInput) (*Credential, error)
 	DeleteCredential(ctx context.Context, id string) (bool, error)
@@ -106,13 +171,21 @@ type MutationResolver interface {
 	DeleteSchedule(ctx context.Context, id string) (bool, error)
 	SetVariable(ctx context.Context, key string, value string, varType *VariableType) (*Variable, error)
 	DeleteVariable(ctx context.Context, key string) (bool, error)
+	SetWorkflowVariable(ctx context.Context, workflowID string, input WorkflowVariableInput) (*WorkflowVariable, error)
+	DeleteWorkflowVariable(ctx context.Context, workflowID, key string) (bool, error)
+	CreateEnvironment(ctx context.Context, workflowID string, input EnvironmentInput) (*Environment, error)
+	UpdateEnvironment(ctx context.Context, workflowID, id string, input EnvironmentInput) (*Environment, error)
+	DeleteEnvironment(ctx context.Context, workflowID, id string) (bool, error)
+	SetDefaultEnvironment(ctx context.Context, workflowID, id string) (*Environment, error)
 }
 
 // SubscriptionResolver interface
 type SubscriptionResolver interface {
-	ExecutionUpdated(ctx context.Context, executionID string) (<-chan *ExecutionUpdate, error)
+	ExecutionUpdates(ctx context.Context, executionID string) (<-chan *ExecutionUpdate, error)
+	WorkflowChanged(ctx context.Context, workflowID string) (<-chan *WorkflowChange, error)
 	WorkflowExecutions(ctx context.Context, workflowID string) (<-chan *Execution, error)
 	Notifications(ctx context.Context) (<-chan *Notification, error)
+	UnreadNotificationCount(ctx context.Context) (<-chan *int, error)
 }
 
 type queryResolver struct{ *Resolver }
@@ -2,47 +2,90 @@ package resolver
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/events"
 )
 
-// ExecutionUpdated subscribes to execution updates
-func (r *subscriptionResolver) ExecutionUpdated(ctx context.Context, executionID string) (<-chan *ExecutionUpdate, error) {
+// errSubscriptionsDisabled is returned when the gateway has no event bus
+// feeding the event subscriptions
+var errSubscriptionsDisabled = apperrors.New(apperrors.CategoryUpstream, apperrors.CodeUpstream, "subscriptions are unavailable")
+
+// ExecutionUpdates streams the events of an execution the caller can read,
+// completing once it finishes
+func (r *subscriptionResolver) ExecutionUpdates(ctx context.Context, executionID string) (<-chan *ExecutionUpdate, error) {
+	if r.feed == nil {
+		return nil, errSubscriptionsDisabled
+	}
+
+	url := fmt.Sprintf("%s/api/v1/executions/%s", r.baseURLs["execution"], executionID)
+	if err := r.authorize(ctx, r.clients.ExecutionClient, url); err != nil {
+		return nil, err
+	}
+
+	in, cancel := r.feed.subscribe("execution:" + executionID)
 	ch := make(chan *ExecutionUpdate, 10)
 
 	go func() {
 		defer close(ch)
-
-		// Poll for updates (in production, use WebSocket or SSE)
-		ticker := time.NewTicker(1 * time.Second)
-		defer ticker.Stop()
+		defer cancel()
 
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-ticker.C:
-				// Fetch execution status
-				execution, err := r.Query().Execution(ctx, executionID)
-				if err != nil {
-					continue
+			case event := <-in:
+				select {
+				case ch <- executionUpdateFromEvent(executionID, event):
+				case <-ctx.Done():
+					return
 				}
 
-				update := &ExecutionUpdate{
-					ExecutionID: executionID,
-					Status:      execution.Status,
-					Timestamp:   time.Now(),
+				if isFinal(event) {
+					return
 				}
+			}
+		}
+	}()
 
+	return ch, nil
+}
+
+// WorkflowChanged streams the changes of a workflow the caller can read,
+// completing once it is deleted
+func (r *subscriptionResolver) WorkflowChanged(ctx context.Context, workflowID string) (<-chan *WorkflowChange, error) {
+	if r.feed == nil {
+		return nil, errSubscriptionsDisabled
+	}
+
+	url := fmt.Sprintf("%s/api/v1/workflows/%s", r.baseURLs["workflow"], workflowID)
+	if err := r.authorize(ctx, r.clients.WorkflowClient, url); err != nil {
+		return nil, err
+	}
+
+	in, cancel := r.feed.subscribe("workflow:" + workflowID)
+	ch := make(chan *WorkflowChange, 10)
+
+	go func() {
+		defer close(ch)
+		defer cancel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-in:
 				select {
-				case ch <- update:
+				case ch <- workflowChangeFromEvent(workflowID, event):
 				case <-ctx.Done():
 					return
 				}
 
-				// Stop if execution is complete
-				if execution.Status == ExecutionStatusCompleted ||
-					execution.Status == ExecutionStatusFailed ||
-					execution.Status == ExecutionStatusCancelled {
+				if event.Type == events.WorkflowDeleted {
 					return
 				}
 			}
@@ -93,23 +136,82 @@ func (r *subscriptionResolver) WorkflowExecutions(ctx context.Context, workflowI
 	return ch, nil
 }
 
-// Notifications subscribes to user notifications
+// Notifications streams the notifications added to the inbox of the caller
 func (r *subscriptionResolver) Notifications(ctx context.Context) (<-chan *Notification, error) {
+	if r.feed == nil {
+		return nil, errSubscriptionsDisabled
+	}
+	userID, _ := ctx.Value("userID").(string)
+	if userID == "" {
+		return nil, apperrors.New(apperrors.CategoryAuth, apperrors.CodeUnauthenticated, "unauthorized")
+	}
+
+	in, cancel := r.feed.subscribe("user:" + userID)
 	ch := make(chan *Notification, 10)
 
 	go func() {
 		defer close(ch)
+		defer cancel()
 
-		// In production, connect to notification service via WebSocket
-		ticker := time.NewTicker(10 * time.Second)
-		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-in:
+				if event.Type != events.NotificationCreated {
+					continue
+				}
+				select {
+				case ch <- notificationFromEvent(event):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// UnreadNotificationCount streams the unread count of the inbox of the
+// caller, its current value first then each change
+func (r *subscriptionResolver) UnreadNotificationCount(ctx context.Context) (<-chan *int, error) {
+	if r.feed == nil {
+		return nil, errSubscriptionsDisabled
+	}
+	userID, _ := ctx.Value("userID").(string)
+	if userID == "" {
+		return nil, apperrors.New(apperrors.CategoryAuth, apperrors.CodeUnauthenticated, "unauthorized")
+	}
+
+	// Subscribed before reading the count, no change falls in between
+	in, cancel := r.feed.subscribe("user:" + userID)
+	current, err := r.unreadCount(ctx, userID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	ch := make(chan *int, 10)
+	ch <- &current
+
+	go func() {
+		defer close(ch)
+		defer cancel()
 
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-ticker.C:
-				// Placeholder - in production, receive from notification service
+			case event := <-in:
+				count, ok := unreadCountOf(event)
+				if !ok {
+					continue
+				}
+				select {
+				case ch <- &count:
+				case <-ctx.Done():
+					return
+				}
 			}
 		}
 	}()
@@ -117,6 +219,36 @@ func (r *subscriptionResolver) Notifications(ctx context.Context) (<-chan *Notif
 	return ch, nil
 }
 
+// unreadCount asks the notification service for the unread count of a user
+func (r *subscriptionResolver) unreadCount(ctx context.Context, userID string) (int, error) {
+	url := fmt.Sprintf("%s/api/v1/notifications/unread-count", r.baseURLs["notification"])
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-User-ID", userID)
+	if token, _ := ctx.Value(authContextKey{}).(string); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := r.clients.NotificationClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get unread notifications: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, apperrors.FromResponse(resp)
+	}
+	var body struct {
+		UnreadCount int `json:"unreadCount"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to decode unread notifications: %w", err)
+	}
+	return body.UnreadCount, nil
+}
+
 func intPtr(i int) *int {
 	return &i
 }
@@ -1,10 +1,10 @@
 package resolver
 
 import (
+	"encoding/json"
 	"time"
 
 	credentialDomain "github.com/linkflow-go/pkg/contracts/credential"
-	executionDomain "github.com/linkflow-go/pkg/contracts/execution"
 	notificationDomain "github.com/linkflow-go/pkg/contracts/notification"
 	scheduleDomain "github.com/linkflow-go/pkg/contracts/schedule"
 	userDomain "github.com/linkflow-go/pkg/contracts/user"
@@ -182,6 +182,51 @@ type Variable struct {
 	UpdatedAt   time.Time    `json:"updatedAt"`
 }
 
+// WorkflowVariable represents a variable of a single workflow
+type WorkflowVariable struct {
+	WorkflowID  string      `json:"workflowId"`
+	Key         string      `json:"key"`
+	Name        *string     `json:"name"`
+	Type        string      `json:"type"`
+	Value       interface{} `json:"value"`
+	Description *string     `json:"description"`
+	Environment *string     `json:"environment"`
+	Encrypted   bool        `json:"encrypted"`
+	ReadOnly    bool        `json:"readOnly"`
+	Required    bool        `json:"required"`
+	CreatedAt   *time.Time  `json:"createdAt"`
+	UpdatedAt   *time.Time  `json:"updatedAt"`
+}
+
+// Environment represents the variable overrides of a workflow
+type Environment struct {
+	ID          string                 `json:"id"`
+	WorkflowID  string                 `json:"workflowId"`
+	Name        string                 `json:"name"`
+	Description *string                `json:"description"`
+	Variables   map[string]interface{} `json:"variables"`
+	IsDefault   bool                   `json:"isDefault"`
+	CreatedAt   *time.Time             `json:"createdAt"`
+	UpdatedAt   *time.Time             `json:"updatedAt"`
+}
+
+// Trigger represents a trigger of a workflow
+type Trigger struct {
+	ID          string                 `json:"id"`
+	WorkflowID  string                 `json:"workflowId"`
+	Type        string                 `json:"type"`
+	Name        string                 `json:"name"`
+	Description *string                `json:"description"`
+	Status      string                 `json:"status"`
+	Config      map[string]interface{} `json:"config"`
+	FireCount   int                    `json:"fireCount"`
+	ErrorCount  int                    `json:"errorCount"`
+	LastFired   *time.Time             `json:"lastFired"`
+	LastError   *string                `json:"lastError"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+}
+
 // Dashboard represents analytics dashboard
 type Dashboard struct {
 	TotalWorkflows   int                `json:"totalWorkflows"`
@@ -217,15 +262,26 @@ type AuthPayload struct {
 	User         *User  `json:"user"`
 }
 
-// ExecutionUpdate represents execution update event
+// ExecutionUpdate represents execution update event. Status is the status
+// of the node for node events.
 type ExecutionUpdate struct {
 	ExecutionID string                 `json:"executionId"`
+	Event       string                 `json:"event"`
 	Status      ExecutionStatus        `json:"status"`
 	NodeID      *string                `json:"nodeId"`
 	Data        map[string]interface{} `json:"data"`
 	Timestamp   time.Time              `json:"timestamp"`
 }
 
+// WorkflowChange represents workflow change event
+type WorkflowChange struct {
+	WorkflowID string    `json:"workflowId"`
+	ChangeType string    `json:"changeType"`
+	ChangedBy  *string   `json:"changedBy"`
+	Version    *int      `json:"version"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
 // Notification represents a notification
 type Notification struct {
 	ID        string                 `json:"id"`
@@ -233,6 +289,7 @@ type Notification struct {
 	Title     string                 `json:"title"`
 	Message   string                 `json:"message"`
 	Data      map[string]interface{} `json:"data"`
+	Read      bool                   `json:"read"`
 	CreatedAt time.Time              `json:"createdAt"`
 }
 
@@ -363,6 +420,24 @@ type CreateScheduleInput struct {
 	Data           map[string]interface{} `json:"data"`
 }
 
+type WorkflowVariableInput struct {
+	Key         string      `json:"key"`
+	Name        *string     `json:"name,omitempty"`
+	Type        *string     `json:"type,omitempty"`
+	Value       interface{} `json:"value,omitempty"`
+	Description *string     `json:"description,omitempty"`
+	Environment *string     `json:"environment,omitempty"`
+	Encrypted   *bool       `json:"encrypted,omitempty"`
+	ReadOnly    *bool       `json:"readOnly,omitempty"`
+	Required    *bool       `json:"required,omitempty"`
+}
+
+type EnvironmentInput struct {
+	Name        *string                `json:"name,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Variables   map[string]interface{} `json:"variables,omitempty"`
+}
+
 type PaginationInput struct {
 	First  *int    `json:"first"`
 	After  *string `json:"after"`
@@ -457,22 +532,39 @@ func WorkflowFromDomain(w *workflowDomain.Workflow) *Workflow {
 }
 
 // ExecutionFromDomain converts a domain execution to GraphQL DTO
-func ExecutionFromDomain(e *executionDomain.Execution) *Execution {
+func ExecutionFromDomain(e *workflowDomain.WorkflowExecution) *Execution {
 	if e == nil {
 		return nil
 	}
-	return &Execution{
+	startedAt := e.StartedAt
+	exec := &Execution{
 		ID:            e.ID,
 		WorkflowID:    e.WorkflowID,
 		Version:       e.Version,
 		Status:        ExecutionStatus(e.Status),
-		StartedAt:     e.StartedAt,
+		StartedAt:     &startedAt,
 		FinishedAt:    e.FinishedAt,
 		ExecutionTime: toIntPtr(int(e.ExecutionTime)),
-		Data:          e.OutputData,
+		Data:          e.Data,
 		Error:         strPtr(e.Error),
 		CreatedAt:     e.CreatedAt,
 	}
+	for _, n := range e.NodeExecutions {
+		nodeStartedAt := n.StartedAt
+		exec.NodeExecutions = append(exec.NodeExecutions, &NodeExecution{
+			ID:         n.ID,
+			NodeID:     n.NodeID,
+			NodeType:   n.NodeType,
+			Status:     ExecutionStatus(n.Status),
+			StartedAt:  &nodeStartedAt,
+			FinishedAt: n.FinishedAt,
+			InputData:  n.InputData,
+			OutputData: n.OutputData,
+			Error:      strPtr(n.Error),
+			RetryCount: n.RetryCount,
+		})
+	}
+	return exec
 }
 
 // CredentialFromDomain converts a domain credential to GraphQL DTO
@@ -527,6 +619,77 @@ func WebhookFromDomain(w *webhookDomain.Webhook) *Webhook {
 	}
 }
 
+// WorkflowVariableFromDomain converts a domain workflow variable to GraphQL
+// DTO, leaving out the value of secrets
+func WorkflowVariableFromDomain(v *workflowDomain.WorkflowVariable) *WorkflowVariable {
+	if v == nil {
+		return nil
+	}
+	variable := &WorkflowVariable{
+		WorkflowID:  v.WorkflowID,
+		Key:         v.Key,
+		Name:        strPtr(v.Name),
+		Type:        v.Type,
+		Value:       v.Value,
+		Description: strPtr(v.Description),
+		Environment: strPtr(v.Environment),
+		Encrypted:   v.Encrypted,
+		ReadOnly:    v.ReadOnly,
+		Required:    v.Required,
+		CreatedAt:   timePtr(v.CreatedAt),
+		UpdatedAt:   timePtr(v.UpdatedAt),
+	}
+	if v.Type == workflowDomain.VarTypeSecret || v.Encrypted {
+		variable.Value = nil
+	}
+	return variable
+}
+
+// EnvironmentFromDomain converts a domain environment to GraphQL DTO
+func EnvironmentFromDomain(e *workflowDomain.Environment) *Environment {
+	if e == nil {
+		return nil
+	}
+	variables := e.Variables
+	if variables == nil {
+		variables = map[string]interface{}{}
+	}
+	return &Environment{
+		ID:          e.ID,
+		WorkflowID:  e.WorkflowID,
+		Name:        e.Name,
+		Description: strPtr(e.Description),
+		Variables:   variables,
+		IsDefault:   e.IsDefault,
+		CreatedAt:   timePtr(e.CreatedAt),
+		UpdatedAt:   timePtr(e.UpdatedAt),
+	}
+}
+
+// TriggerFromDomain converts a domain trigger to GraphQL DTO
+func TriggerFromDomain(t *workflowDomain.WorkflowTrigger) *Trigger {
+	if t == nil {
+		return nil
+	}
+	var config map[string]interface{}
+	_ = json.Unmarshal(t.Config, &config)
+	return &Trigger{
+		ID:          t.ID,
+		WorkflowID:  t.WorkflowID,
+		Type:        t.Type,
+		Name:        t.Name,
+		Description: strPtr(t.Description),
+		Status:      t.Status,
+		Config:      config,
+		FireCount:   int(t.FireCount),
+		ErrorCount:  int(t.ErrorCount),
+		LastFired:   t.LastFired,
+		LastError:   strPtr(t.LastError),
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
 // NotificationFromDomain converts a domain notification to GraphQL DTO
 func NotificationFromDomain(n *notificationDomain.Notification) *Notification {
 	if n == nil {
@@ -538,6 +701,7 @@ func NotificationFromDomain(n *notificationDomain.Notification) *Notification {
 		Title:     n.Subject,
 		Message:   n.Body,
 		Data:      n.Data,
+		Read:      n.ReadAt != nil,
 		CreatedAt: n.CreatedAt,
 	}
 }
@@ -553,3 +717,13 @@ func strPtr(s string) *string {
 func toIntPtr(i int) *int {
 	return &i
 }
+
+// timePtr parses the RFC 3339 timestamps some domain models keep as
+// strings, nil when unset or malformed
+func timePtr(s string) *time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"encoding/json"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// project renders a resolved value with the fields selected by the client,
+// under their aliases. The value is read through its JSON form, whose field
+// names match the schema.
+func project(value interface{}, selection ast.SelectionSet, vars map[string]interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil
+	}
+	return selectFields(decoded, selection, vars)
+}
+
+func selectFields(value interface{}, selection ast.SelectionSet, vars map[string]interface{}) interface{} {
+	// Scalars, including JSON objects, are returned whole
+	if len(selection) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{})
+		for _, field := range collectFields(selection, vars) {
+			if field.Name == "__typename" {
+				out[field.Alias] = field.ObjectDefinition.Name
+				continue
+			}
+			out[field.Alias] = selectFields(v[field.Name], field.SelectionSet, vars)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = selectFields(item, selection, vars)
+		}
+		return out
+	}
+	return value
+}
+
+// collectFields flattens the fragments of a selection set, dropping the
+// selections skipped by @skip or @include
+func collectFields(selection ast.SelectionSet, vars map[string]interface{}) []*ast.Field {
+	var fields []*ast.Field
+	for _, sel := range selection {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if included(s.Directives, vars) {
+				fields = append(fields, s)
+			}
+		case *ast.InlineFragment:
+			if included(s.Directives, vars) {
+				fields = append(fields, collectFields(s.SelectionSet, vars)...)
+			}
+		case *ast.FragmentSpread:
+			if included(s.Directives, vars) && s.Definition != nil {
+				fields = append(fields, collectFields(s.Definition.SelectionSet, vars)...)
+			}
+		}
+	}
+	return fields
+}
+
+func included(directives ast.DirectiveList, vars map[string]interface{}) bool {
+	if d := directives.ForName("skip"); d != nil {
+		if skip, _ := d.ArgumentMap(vars)["if"].(bool); skip {
+			return false
+		}
+	}
+	if d := directives.ForName("include"); d != nil {
+		if include, _ := d.ArgumentMap(vars)["if"].(bool); !include {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,405 @@
+// Package transport serves GraphQL subscriptions over WebSocket with the
+// graphql-ws protocol (subprotocol graphql-transport-ws).
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/linkflow-go/internal/gateway/adapters/graphql/resolver"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// Protocol is the WebSocket subprotocol of graphql-ws
+const Protocol = "graphql-transport-ws"
+
+const (
+	initTimeout = 10 * time.Second
+	writeWait   = 10 * time.Second
+)
+
+// Message types of the graphql-ws protocol
+const (
+	msgConnectionInit = "connection_init"
+	msgConnectionAck  = "connection_ack"
+	msgPing           = "ping"
+	msgPong           = "pong"
+	msgSubscribe      = "subscribe"
+	msgNext           = "next"
+	msgError          = "error"
+	msgComplete       = "complete"
+)
+
+// Close codes of the graphql-ws protocol
+const (
+	closeBadRequest   = 4400
+	closeUnauthorized = 4401
+	closeForbidden    = 4403
+	closeInitTimeout  = 4408
+	closeDuplicateID  = 4409
+	closeTooManyInits = 4429
+)
+
+// Authenticator returns the user a token belongs to and its tenant, or an
+// error when it is not valid
+type Authenticator func(ctx context.Context, token string) (userID, tenantID string, err error)
+
+// Websocket serves the subscriptions of the schema, each connection
+// authenticates with the token of its connection_init payload or of the
+// Authorization header of the upgrade request
+type Websocket struct {
+	schema       *ast.Schema
+	resolver     resolver.SubscriptionResolver
+	authenticate Authenticator
+	logger       logger.Logger
+	upgrader     websocket.Upgrader
+}
+
+// NewWebsocket creates the WebSocket transport
+func NewWebsocket(schema *ast.Schema, res resolver.SubscriptionResolver, authenticate Authenticator, log logger.Logger) *Websocket {
+	return &Websocket{
+		schema:       schema,
+		resolver:     res,
+		authenticate: authenticate,
+		logger:       log,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			Subprotocols:    []string{Protocol},
+			// Connections authenticate with a token rather than cookies
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+type message struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type subscribePayload struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type connection struct {
+	*Websocket
+	ws     *websocket.Conn
+	header string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	writeMu sync.Mutex
+
+	mu   sync.Mutex
+	subs map[string]context.CancelFunc
+}
+
+func (w *Websocket) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	ws, err := w.upgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		w.logger.Error("Failed to upgrade connection", "error", err)
+		return
+	}
+
+	if ws.Subprotocol() != Protocol {
+		closeWith(ws, websocket.CloseProtocolError, "subprotocol "+Protocol+" required")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &connection{
+		Websocket: w,
+		ws:        ws,
+		header:    r.Header.Get("Authorization"),
+		ctx:       ctx,
+		cancel:    cancel,
+		subs:      make(map[string]context.CancelFunc),
+	}
+	c.run()
+}
+
+// run reads the messages of the connection until it closes
+func (c *connection) run() {
+	defer c.ws.Close()
+	defer c.cancel()
+
+	// The client has a while to send connection_init
+	c.ws.SetReadDeadline(time.Now().Add(initTimeout))
+	initialized := false
+
+	for {
+		var msg message
+		if err := c.ws.ReadJSON(&msg); err != nil {
+			if !initialized && isTimeout(err) {
+				c.close(closeInitTimeout, "Connection initialisation timeout")
+			} else if _, ok := err.(*json.SyntaxError); ok {
+				c.close(closeBadRequest, "Invalid message")
+			}
+			return
+		}
+
+		switch msg.Type {
+		case msgConnectionInit:
+			if initialized {
+				c.close(closeTooManyInits, "Too many initialisation requests")
+				return
+			}
+			userID, tenantID, token, err := c.init(msg.Payload)
+			if err != nil {
+				c.close(closeForbidden, "Forbidden")
+				return
+			}
+			c.ctx = resolver.WithAuth(c.ctx, userID, tenantID, token)
+			initialized = true
+			c.ws.SetReadDeadline(time.Time{})
+			c.write(message{Type: msgConnectionAck})
+
+		case msgPing:
+			c.write(message{Type: msgPong})
+
+		case msgPong:
+
+		case msgSubscribe:
+			if !initialized {
+				c.close(closeUnauthorized, "Unauthorized")
+				return
+			}
+			var payload subscribePayload
+			if msg.ID == "" || json.Unmarshal(msg.Payload, &payload) != nil {
+				c.close(closeBadRequest, "Invalid subscribe message")
+				return
+			}
+			if !c.start(msg.ID, payload) {
+				c.close(closeDuplicateID, "Subscriber for "+msg.ID+" already exists")
+				return
+			}
+
+		case msgComplete:
+			c.stop(msg.ID)
+
+		default:
+			c.close(closeBadRequest, "Unknown message type "+msg.Type)
+			return
+		}
+	}
+}
+
+// init authenticates the connection with the token of the connection_init
+// payload, falling back to the header of the upgrade request
+func (c *connection) init(payload json.RawMessage) (string, string, string, error) {
+	var params map[string]interface{}
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &params); err != nil {
+			return "", "", "", err
+		}
+	}
+
+	token := c.header
+	for _, key := range []string{"Authorization", "authorization", "token"} {
+		if value, ok := params[key].(string); ok && value != "" {
+			token = value
+			break
+		}
+	}
+	token = strings.TrimPrefix(token, "Bearer ")
+	if token == "" {
+		return "", "", "", fmt.Errorf("no token")
+	}
+
+	userID, tenantID, err := c.authenticate(c.ctx, token)
+	if err != nil {
+		return "", "", "", err
+	}
+	return userID, tenantID, token, nil
+}
+
+// start runs a subscription, reporting false when its ID is taken
+func (c *connection) start(id string, payload subscribePayload) bool {
+	c.mu.Lock()
+	if _, ok := c.subs[id]; ok {
+		c.mu.Unlock()
+		return false
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.subs[id] = cancel
+	c.mu.Unlock()
+
+	go func() {
+		defer c.stop(id)
+
+		field, vars, errs := c.prepare(payload)
+		if errs != nil {
+			c.writeErrors(id, errs)
+			return
+		}
+
+		stream, err := c.resolve(ctx, field, vars)
+		if err != nil {
+			gqlErr := apperrors.ToGraphQL(err)
+			gqlErr.Path = ast.Path{ast.PathName(field.Alias)}
+			c.writeErrors(id, gqlerror.List{gqlErr})
+			return
+		}
+
+		for value := range stream {
+			data := map[string]interface{}{
+				field.Alias: project(value, field.SelectionSet, vars),
+			}
+			payload, err := json.Marshal(map[string]interface{}{"data": data})
+			if err != nil {
+				c.logger.Error("Failed to marshal subscription result", "error", err)
+				continue
+			}
+			c.write(message{ID: id, Type: msgNext, Payload: payload})
+		}
+
+		// The stream ended on its own rather than on a client complete
+		if ctx.Err() == nil {
+			c.write(message{ID: id, Type: msgComplete})
+		}
+	}()
+	return true
+}
+
+func (c *connection) stop(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cancel, ok := c.subs[id]; ok {
+		cancel()
+		delete(c.subs, id)
+	}
+}
+
+// prepare validates the operation and returns its root field with the
+// coerced variables
+func (c *connection) prepare(payload subscribePayload) (*ast.Field, map[string]interface{}, gqlerror.List) {
+	doc, errs := gqlparser.LoadQuery(c.schema, payload.Query)
+	if errs != nil {
+		return nil, nil, errs
+	}
+
+	op := doc.Operations.ForName(payload.OperationName)
+	if op == nil {
+		return nil, nil, gqlerror.List{gqlerror.Errorf("operation %s not found", payload.OperationName)}
+	}
+	if op.Operation != ast.Subscription {
+		return nil, nil, gqlerror.List{gqlerror.Errorf("only subscriptions are served over WebSocket")}
+	}
+
+	vars, err := validator.VariableValues(c.schema, op, payload.Variables)
+	if err != nil {
+		return nil, nil, gqlerror.List{gqlerror.WrapIfUnwrapped(err)}
+	}
+
+	// Validation leaves subscriptions with a single root field
+	fields := collectFields(op.SelectionSet, vars)
+	if len(fields) == 0 {
+		return nil, nil, gqlerror.List{gqlerror.Errorf("subscription selects no field")}
+	}
+	return fields[0], vars, nil
+}
+
+// resolve starts the resolver of the subscription field
+func (c *connection) resolve(ctx context.Context, field *ast.Field, vars map[string]interface{}) (<-chan interface{}, error) {
+	args := field.ArgumentMap(vars)
+
+	switch field.Name {
+	case "executionUpdates":
+		id, _ := args["executionId"].(string)
+		ch, err := c.resolver.ExecutionUpdates(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return forward(ch), nil
+
+	case "workflowChanged":
+		id, _ := args["workflowId"].(string)
+		ch, err := c.resolver.WorkflowChanged(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return forward(ch), nil
+
+	case "notifications":
+		ch, err := c.resolver.Notifications(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return forward(ch), nil
+
+	case "unreadNotificationCount":
+		ch, err := c.resolver.UnreadNotificationCount(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return forward(ch), nil
+	}
+
+	return nil, apperrors.New(apperrors.CategoryValidation, apperrors.CodeInvalidRequest,
+		fmt.Sprintf("subscription %s is not supported", field.Name))
+}
+
+// forward relays a typed resolver stream as values to project
+func forward[T any](in <-chan *T) <-chan interface{} {
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		for value := range in {
+			out <- value
+		}
+	}()
+	return out
+}
+
+func (c *connection) writeErrors(id string, errs gqlerror.List) {
+	payload, err := json.Marshal(errs)
+	if err != nil {
+		c.logger.Error("Failed to marshal subscription errors", "error", err)
+		return
+	}
+	c.write(message{ID: id, Type: msgError, Payload: payload})
+}
+
+func (c *connection) write(msg message) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+	if err := c.ws.WriteJSON(msg); err != nil {
+		// Reading fails too once the connection is gone, ending it
+		c.logger.Debug("Failed to write message", "type", msg.Type, "error", err)
+	}
+}
+
+func (c *connection) close(code int, reason string) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	closeWith(c.ws, code, reason)
+}
+
+func closeWith(ws *websocket.Conn, code int, reason string) {
+	msg := websocket.FormatCloseMessage(code, reason)
+	ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+	ws.Close()
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(interface{ Timeout() bool })
+	return ok && netErr.Timeout()
+}
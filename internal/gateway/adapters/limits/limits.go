@@ -0,0 +1,265 @@
+// Package limits rate limits the clients of the gateway by the plan of
+// their user. Each plan allows a number of requests per minute and a daily
+// quota, counted in sliding windows in Redis so every gateway instance
+// sees the same counts. Responses report what is left in X-RateLimit-* and
+// X-Quota-* headers.
+package limits
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/pkg/config"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/ratelimit"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// AnonymousPlan limits the clients without a valid token, by IP address
+	AnonymousPlan = "anonymous"
+	// DefaultPlan limits the users whose plan has no limits configured
+	DefaultPlan = "free"
+
+	counterPrefix = "gateway:ratelimit:"
+	// plansKey holds the limits set through the admin API, by plan
+	plansKey = "gateway:ratelimit:plans"
+
+	// refreshInterval bounds how long a change of the limits takes to
+	// reach every gateway instance
+	refreshInterval = 10 * time.Second
+)
+
+// ErrRateLimited is returned to clients over their limit or quota
+var ErrRateLimited = apperrors.New(apperrors.CategoryRateLimit, apperrors.CodeRateLimited, "rate limit exceeded")
+
+// Client is who a request is counted against
+type Client struct {
+	// Key tells clients apart, such as user:<id> or ip:<address>
+	Key  string
+	Plan string
+}
+
+// Identifier names the client of a request
+type Identifier func(c *gin.Context) Client
+
+// Limiter enforces the limits of the plans
+type Limiter struct {
+	redis    *redis.Client
+	defaults map[string]config.RateLimitPlan
+	identify Identifier
+	logger   logger.Logger
+
+	mu        sync.Mutex
+	plans     map[string]config.RateLimitPlan
+	refreshed time.Time
+}
+
+// New creates a limiter with the configured limits of the plans
+func New(redisClient *redis.Client, defaults map[string]config.RateLimitPlan, identify Identifier, log logger.Logger) *Limiter {
+	return &Limiter{
+		redis:    redisClient,
+		defaults: defaults,
+		identify: identify,
+		logger:   log,
+	}
+}
+
+// Middleware counts each request against the limit and quota of its
+// client. Requests over either are refused with 429. Counting fails open,
+// the gateway keeps serving when Redis is unavailable.
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		client := l.identify(c)
+		plan := l.plan(ctx, client.Plan)
+
+		windows := []struct {
+			name   string
+			limit  int
+			window time.Duration
+			header string
+		}{
+			{"minute", plan.RequestsPerMinute, time.Minute, "X-RateLimit"},
+			{"day", plan.RequestsPerDay, 24 * time.Hour, "X-Quota"},
+		}
+		for _, w := range windows {
+			if w.limit <= 0 {
+				continue
+			}
+
+			limiter := ratelimit.NewSlidingWindowLimiter(l.redis, w.limit, w.window)
+			quota, err := limiter.Check(ctx, counterPrefix+w.name+":"+client.Key)
+			if err != nil {
+				l.logger.Warn("Failed to check rate limit", "client", client.Key, "error", err)
+				break
+			}
+
+			c.Header(w.header+"-Limit", strconv.Itoa(quota.Limit))
+			c.Header(w.header+"-Remaining", strconv.Itoa(quota.Remaining))
+			c.Header(w.header+"-Reset", strconv.FormatInt(quota.Reset.Unix(), 10))
+			if !quota.Allowed {
+				retryAfter := math.Ceil(time.Until(quota.Reset).Seconds())
+				c.Header("Retry-After", strconv.Itoa(max(int(retryAfter), 1)))
+				c.AbortWithStatusJSON(apperrors.ToHTTP(ErrRateLimited))
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// Plans returns the limits in effect, by plan
+func (l *Limiter) Plans(ctx context.Context) (map[string]config.RateLimitPlan, error) {
+	overrides, err := l.overrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make(map[string]config.RateLimitPlan, len(l.defaults)+len(overrides))
+	for name, plan := range l.defaults {
+		plans[name] = plan
+	}
+	for name, plan := range overrides {
+		plans[name] = plan
+	}
+	return plans, nil
+}
+
+// SetPlan overrides the limits of a plan on every gateway instance
+func (l *Limiter) SetPlan(ctx context.Context, name string, plan config.RateLimitPlan) error {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return err
+	}
+	if err := l.redis.HSet(ctx, plansKey, name, data).Err(); err != nil {
+		return fmt.Errorf("failed to set plan limits: %w", err)
+	}
+	l.invalidate()
+	return nil
+}
+
+// ResetPlan drops the override of a plan, its configured limits apply
+// again. It reports whether the plan was overridden.
+func (l *Limiter) ResetPlan(ctx context.Context, name string) (bool, error) {
+	n, err := l.redis.HDel(ctx, plansKey, name).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to reset plan limits: %w", err)
+	}
+	l.invalidate()
+	return n > 0, nil
+}
+
+// plan returns the limits of a plan, read from Redis at most every
+// refreshInterval
+func (l *Limiter) plan(ctx context.Context, name string) config.RateLimitPlan {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.plans == nil || time.Since(l.refreshed) > refreshInterval {
+		plans, err := l.Plans(ctx)
+		if err != nil {
+			l.logger.Warn("Failed to load plan limits", "error", err)
+			plans = l.plans
+			if plans == nil {
+				plans = l.defaults
+			}
+		}
+		l.plans = plans
+		l.refreshed = time.Now()
+	}
+
+	if plan, ok := l.plans[name]; ok {
+		return plan
+	}
+	return l.plans[DefaultPlan]
+}
+
+// overrides returns the limits set through the admin API
+func (l *Limiter) overrides(ctx context.Context) (map[string]config.RateLimitPlan, error) {
+	fields, err := l.redis.HGetAll(ctx, plansKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plan limits: %w", err)
+	}
+
+	plans := make(map[string]config.RateLimitPlan, len(fields))
+	for name, data := range fields {
+		var plan config.RateLimitPlan
+		if err := json.Unmarshal([]byte(data), &plan); err != nil {
+			l.logger.Warn("Ignoring invalid plan limits", "plan", name, "error", err)
+			continue
+		}
+		plans[name] = plan
+	}
+	return plans, nil
+}
+
+// invalidate makes the next request of this instance read the limits
+func (l *Limiter) invalidate() {
+	l.mu.Lock()
+	l.plans = nil
+	l.mu.Unlock()
+}
+
+// Handlers serve the admin API of the limits
+type Handlers struct {
+	limiter *Limiter
+}
+
+// NewHandlers creates the admin API of a limiter
+func NewHandlers(limiter *Limiter) *Handlers {
+	return &Handlers{limiter: limiter}
+}
+
+// ListPlans returns the limits in effect, by plan
+func (h *Handlers) ListPlans(c *gin.Context) {
+	plans, err := h.limiter.Plans(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"plans": plans})
+}
+
+// SetPlan overrides the limits of a plan, new plans can be added
+func (h *Handlers) SetPlan(c *gin.Context) {
+	var plan config.RateLimitPlan
+	if err := c.ShouldBindJSON(&plan); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+	if plan.RequestsPerMinute < 0 || plan.RequestsPerDay < 0 {
+		c.JSON(apperrors.ToHTTP(apperrors.New(apperrors.CategoryValidation, apperrors.CodeInvalidRequest,
+			"limits must be 0 (unlimited) or more")))
+		return
+	}
+
+	if err := h.limiter.SetPlan(c.Request.Context(), c.Param("plan"), plan); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"plan": c.Param("plan"), "limits": plan})
+}
+
+// ResetPlan reverts a plan to its configured limits
+func (h *Handlers) ResetPlan(c *gin.Context) {
+	found, err := h.limiter.ResetPlan(c.Request.Context(), c.Param("plan"))
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "plan limits are not overridden"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
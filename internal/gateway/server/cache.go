@@ -0,0 +1,66 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/internal/gateway/adapters/cache"
+	"github.com/linkflow-go/pkg/apidoc"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// Tags of cached responses
+const (
+	tagTemplates = "templates"
+	tagNodeTypes = "node-types"
+)
+
+// definitionTopics change what the definition of a workflow reads as
+var definitionTopics = []string{
+	events.WorkflowUpdated,
+	events.WorkflowDeleted,
+	events.WorkflowActivated,
+	events.WorkflowDeactivated,
+	"workflow.version.rollback",
+}
+
+// registerCachedRoutes serves the read-heavy routes of the services from
+// the cache: workflow definitions, templates and the node type catalog.
+// Routes of services left out of the gateway are not served.
+func registerCachedRoutes(router *gin.Engine, responses *cache.Cache, urls map[string]string) {
+	if url := urls["workflow"]; url != "" {
+		router.GET(apidoc.Prefix+"/workflows/templates", responses.Handler(url, tags(tagTemplates)))
+		router.GET(apidoc.Prefix+"/workflows/templates/:id", responses.Handler(url, tags(tagTemplates)))
+		router.GET(apidoc.Prefix+"/workflows/:id", responses.Handler(url, func(c *gin.Context) []string {
+			return []string{workflowTag(c.Param("id"))}
+		}))
+	}
+	if url := urls["node"]; url != "" {
+		router.GET(apidoc.Prefix+"/nodes/types", responses.Handler(url, tags(tagNodeTypes)))
+		router.GET(apidoc.Prefix+"/nodes/types/:type", responses.Handler(url, tags(tagNodeTypes)))
+	}
+}
+
+// invalidateDefinitions drops the cached definition of a workflow when an
+// event reports it changed. Templates and node types have no events, they
+// expire.
+func invalidateDefinitions(responses *cache.Cache, bus events.EventBus) error {
+	return responses.InvalidateOn(bus, definitionTopics, func(event events.Event) string {
+		id, _ := event.Payload["workflow_id"].(string)
+		if id == "" {
+			id = event.AggregateID
+		}
+		if id == "" {
+			return ""
+		}
+		return workflowTag(id)
+	})
+}
+
+func workflowTag(id string) string {
+	return "workflow:" + id
+}
+
+func tags(names ...string) cache.TagFunc {
+	return func(*gin.Context) []string {
+		return names
+	}
+}
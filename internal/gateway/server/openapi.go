@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/pkg/apidoc"
+)
+
+// openapiHandler serves the documents of the services behind the gateway
+// merged into one. Services that cannot be reached are listed rather than
+// failing the whole document.
+func openapiHandler(urls map[string]string) gin.HandlerFunc {
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		docs := make(map[string]apidoc.Document, len(urls))
+		unavailable := []string{}
+		for name, url := range urls {
+			wg.Add(1)
+			go func(name, url string) {
+				defer wg.Done()
+				doc, err := fetchDocument(ctx, client, url+apidoc.SpecPath)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					unavailable = append(unavailable, name)
+					return
+				}
+				docs[name] = doc
+			}(name, url)
+		}
+		wg.Wait()
+
+		merged := apidoc.Merge(docs)
+		if len(unavailable) > 0 {
+			sort.Strings(unavailable)
+			merged["x-unavailable"] = unavailable
+		}
+		c.JSON(http.StatusOK, merged)
+	}
+}
+
+func fetchDocument(ctx context.Context, client *http.Client, url string) (apidoc.Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var doc apidoc.Document
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
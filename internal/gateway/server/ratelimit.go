@@ -0,0 +1,50 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/internal/gateway/adapters/limits"
+)
+
+// rateLimitClient counts requests against the user of a valid access
+// token, under the plan of the token. API keys are counted by key under
+// the default plan, the services check them. Other requests are counted
+// by IP address.
+func rateLimitClient(identify identifier) limits.Identifier {
+	return func(c *gin.Context) limits.Client {
+		header := c.GetHeader("Authorization")
+		if key := c.GetHeader("X-API-Key"); key != "" {
+			return apiKeyClient(key)
+		}
+		if key, ok := strings.CutPrefix(header, "ApiKey "); ok {
+			return apiKeyClient(key)
+		}
+
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok && token != "" {
+			if caller, err := identify(c.Request.Context(), token); err == nil {
+				plan := caller.Plan
+				if plan == "" {
+					plan = limits.DefaultPlan
+				}
+				return limits.Client{Key: "user:" + caller.UserID, Plan: plan}
+			}
+		}
+
+		return limits.Client{Key: "ip:" + c.ClientIP(), Plan: limits.AnonymousPlan}
+	}
+}
+
+func apiKeyClient(key string) limits.Client {
+	sum := sha256.Sum256([]byte(key))
+	return limits.Client{Key: "key:" + hex.EncodeToString(sum[:16]), Plan: limits.DefaultPlan}
+}
+
+// registerRateLimitRoutes serves the admin API of the plan limits
+func registerRateLimitRoutes(admin *gin.RouterGroup, h *limits.Handlers) {
+	admin.GET("/rate-limits", h.ListPlans)
+	admin.PUT("/rate-limits/:plan", h.SetPlan)
+	admin.DELETE("/rate-limits/:plan", h.ResetPlan)
+}
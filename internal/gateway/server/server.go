@@ -4,30 +4,160 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/internal/gateway/adapters/cache"
+	"github.com/linkflow-go/internal/gateway/adapters/graphql/graph"
 	"github.com/linkflow-go/internal/gateway/adapters/graphql/graph/generated"
 	"github.com/linkflow-go/internal/gateway/adapters/graphql/resolver"
+	"github.com/linkflow-go/internal/gateway/adapters/graphql/transport"
+	"github.com/linkflow-go/internal/gateway/adapters/limits"
+	"github.com/linkflow-go/pkg/apidoc"
+	"github.com/linkflow-go/pkg/auth/jwt"
+	"github.com/linkflow-go/pkg/balancer"
 	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/discovery"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/linkflow-go/pkg/middleware/correlation"
+	"github.com/linkflow-go/pkg/middleware/security"
+	tenantmw "github.com/linkflow-go/pkg/middleware/tenant"
+	"github.com/linkflow-go/pkg/rpc"
+	"github.com/linkflow-go/pkg/rpc/authv1"
+	"github.com/linkflow-go/pkg/telemetry"
+	"github.com/linkflow-go/pkg/tenant"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
 	config     *config.Config
 	logger     logger.Logger
 	httpServer *http.Server
+	telemetry  *telemetry.Telemetry
+	redis      *redis.Client
+	eventBus   events.EventBus
+	authConn   *grpc.ClientConn
 }
 
 func New(cfg *config.Config, log logger.Logger) (*Server, error) {
-	// Create GraphQL resolver (endpoint wiring is currently disabled until schema generation is enabled)
-	res := resolver.NewResolver(cfg, log)
-	_ = res
+	// Initialize tracing, gateway spans are the root of request traces
+	tel := telemetry.Setup(cfg.Telemetry, "graphql-gateway", log)
+
+	// Services announce their instances in Redis, read for the topology
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	registry := discovery.NewRedisDiscovery(redisClient, discovery.DefaultInstanceTTL)
+
+	// Calls to the services are spread over their instances
+	var upstream http.RoundTripper
+	if cfg.LoadBalancing.Enabled {
+		upstream = balancer.NewTransport(balancer.FromConfig(cfg.LoadBalancing, registry, log), nil)
+	}
+
+	// Create GraphQL resolver (query and mutation wiring is disabled until schema generation is enabled)
+	res := resolver.NewResolver(cfg, log, upstream)
 	_ = generated.Config{}
 
-	router := setupRouter()
+	// Readiness reports each downstream service, the gateway degrades
+	// rather than going down when one of them is unreachable
+	checker := health.NewChecker("graphql-gateway")
+	client := &http.Client{Timeout: health.DefaultTimeout}
+	for name, url := range res.ServiceURLs() {
+		checker.Optional(name+"-service", health.HTTP(client, url+"/health/live"))
+	}
+	checker.Optional("redis", health.Redis(redisClient))
+
+	// Subscriptions stream events to the clients connected to this
+	// instance, so it needs every event rather than a share of them
+	busConfig := cfg.Kafka.ToKafkaConfig()
+	busConfig.Broadcast = true
+	eventBus, err := events.New(busConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event bus: %w", err)
+	}
+	if err := res.StreamEvents(eventBus); err != nil {
+		return nil, fmt.Errorf("failed to stream events: %w", err)
+	}
+	checker.Optional("event_bus", health.EventBus(eventBus))
+
+	schema, err := graph.Schema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GraphQL schema: %w", err)
+	}
+	jwtManager, err := jwt.NewManager(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWT manager: %w", err)
+	}
+
+	// Tokens are checked by the auth service when its API is configured,
+	// locally otherwise
+	identify := authenticator(jwtManager, redisClient)
+	var authConn *grpc.ClientConn
+	if addr := cfg.RPC.Services[rpc.ServiceAuth]; addr != "" {
+		authConn, err = rpc.Dial(addr, cfg.RPC.Token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial auth service: %w", err)
+		}
+		identify = introspector(authv1.NewAuthServiceClient(authConn))
+	}
+	authn := identify.userID
+
+	router := setupRouter(tel, checker, cfg.Gateway.Security)
+
+	// Services take the tenant of the caller from the gateway, never from
+	// the client
+	router.Use(scopeTenant(identify))
+
+	var limiter *limits.Limiter
+	if cfg.Gateway.RateLimit.Enabled {
+		limiter = limits.New(redisClient, cfg.Gateway.RateLimit.Plans, rateLimitClient(identify), log)
+	}
+
+	// Topology reveals versions and hosts and the limits are changed by
+	// operators, only served with an admin token. Admin routes are not rate
+	// limited so operators can raise the limits of a client locked out.
+	if cfg.Server.AdminToken != "" {
+		admin := router.Group("/admin", adminAuth(cfg.Server.AdminToken))
+		admin.GET("/topology", topologyHandler(registry, cfg))
+		if limiter != nil {
+			registerRateLimitRoutes(admin, limits.NewHandlers(limiter))
+		}
+	} else {
+		log.Info("Admin endpoints disabled, no admin token configured")
+	}
+
+	// Every route below counts against the limits of the client's plan
+	if limiter != nil {
+		router.Use(limiter.Middleware())
+	}
+
+	// GraphQL subscriptions over graphql-ws
+	ws := transport.NewWebsocket(schema, res.Subscription(), identify.user, log)
+	router.GET("/graphql", gin.WrapH(ws))
+
+	// The REST API of the services behind the gateway in one document
+	router.GET(apidoc.SpecPath, openapiHandler(res.ServiceURLs()))
+
+	// Read-heavy routes of the services, cached in Redis
+	if cfg.Gateway.CacheTTL > 0 {
+		client := &http.Client{Transport: telemetry.NewTransport(upstream), Timeout: 30 * time.Second}
+		ttl := time.Duration(cfg.Gateway.CacheTTL) * time.Second
+		responses := cache.New(redisClient, client, ttl, cache.Authenticator(authn), log)
+		if err := invalidateDefinitions(responses, eventBus); err != nil {
+			return nil, fmt.Errorf("failed to invalidate cached responses: %w", err)
+		}
+		registerCachedRoutes(router, responses, res.ServiceURLs())
+	}
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -40,21 +170,33 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		config:     cfg,
 		logger:     log,
 		httpServer: httpServer,
+		telemetry:  tel,
+		redis:      redisClient,
+		eventBus:   eventBus,
+		authConn:   authConn,
 	}, nil
 }
 
-func setupRouter() *gin.Engine {
+func setupRouter(tel *telemetry.Telemetry, checker *health.Checker, cfg config.GatewaySecurityConfig) *gin.Engine {
 	router := gin.New()
+	router.Use(metrics.HTTPMiddleware("graphql-gateway"))
 	router.Use(gin.Recovery())
-	router.Use(corsMiddleware())
+	router.Use(tenantmw.StripInternal())
+	router.Use(correlation.Middleware())
+	router.Use(tel.HTTPMiddleware())
+	if cfg.Headers.Enabled {
+		router.Use(security.Headers(cfg.Headers))
+	}
+	router.Use(security.CORS(cfg.CORS))
+	if cfg.CSRF.Enabled {
+		router.Use(security.CSRF(cfg.CSRF))
+	}
 
 	// Health checks
 	router.GET("/health/live", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "alive"})
 	})
-	router.GET("/health/ready", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ready"})
-	})
+	router.GET("/health/ready", checker.ReadyHandler())
 
 	// Metrics
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
@@ -78,28 +220,114 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown HTTP server: %w", err)
 	}
+
+	if err := s.eventBus.Close(); err != nil {
+		s.logger.Error("Failed to close event bus", "error", err)
+	}
+
+	// Flush pending spans
+	if err := s.telemetry.Close(); err != nil {
+		s.logger.Error("Failed to close telemetry", "error", err)
+	}
+
+	if err := s.redis.Close(); err != nil {
+		s.logger.Error("Failed to close Redis", "error", err)
+	}
+
+	if s.authConn != nil {
+		if err := s.authConn.Close(); err != nil {
+			s.logger.Error("Failed to close auth service connection", "error", err)
+		}
+	}
 	return nil
 }
 
-func playgroundHandler() gin.HandlerFunc {
-	h := playground.Handler("GraphQL Playground", "/graphql")
-	return func(c *gin.Context) {
-		h.ServeHTTP(c.Writer, c.Request)
+// caller is who an access token authenticates
+type caller struct {
+	UserID   string
+	TenantID string
+	Plan     string
+}
+
+// identifier returns the caller of an access token, or an error when it is
+// not valid
+type identifier func(ctx context.Context, token string) (caller, error)
+
+// userID authenticates a token as its user
+func (identify identifier) userID(ctx context.Context, token string) (string, error) {
+	c, err := identify(ctx, token)
+	return c.UserID, err
+}
+
+// user authenticates a token as its user and the tenant of the user
+func (identify identifier) user(ctx context.Context, token string) (string, string, error) {
+	c, err := identify(ctx, token)
+	return c.UserID, c.TenantID, err
+}
+
+// authenticator accepts the access tokens the JWT middleware of the
+// services accepts, refusing revoked ones
+func authenticator(manager *jwt.Manager, redisClient *redis.Client) identifier {
+	return func(ctx context.Context, token string) (caller, error) {
+		revoked, err := redisClient.Exists(ctx, "blacklist:"+token).Result()
+		if err == nil && revoked > 0 {
+			return caller{}, fmt.Errorf("token has been revoked")
+		}
+
+		claims, err := manager.ValidateToken(token)
+		if err != nil {
+			return caller{}, err
+		}
+		return caller{UserID: claims.UserID, TenantID: claims.TenantID, Plan: claims.Plan}, nil
 	}
 }
 
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+// introspector accepts the tokens the auth service reports active
+func introspector(client authv1.AuthServiceClient) identifier {
+	return func(ctx context.Context, token string) (caller, error) {
+		resp, err := client.IntrospectToken(ctx, &authv1.IntrospectTokenRequest{
+			Token:         token,
+			TokenTypeHint: "access_token",
+		})
+		if err != nil {
+			return caller{}, err
+		}
+		// Refresh tokens are active too but do not authenticate requests
+		if !resp.GetActive() || resp.GetTokenType() != "Bearer" {
+			return caller{}, fmt.Errorf("token is not active")
 		}
+		return caller{UserID: resp.GetUserId(), TenantID: resp.GetTenantId(), Plan: resp.GetPlan()}, nil
+	}
+}
 
+// scopeTenant scopes the requests of callers with a valid token to their
+// tenant, which the calls to the services carry on. Requests without one
+// belong to the default tenant.
+func scopeTenant(identify identifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := tenant.DefaultTenant
+		if token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "); token != "" {
+			if caller, err := identify(c.Request.Context(), token); err == nil && tenant.Valid(caller.TenantID) {
+				id = caller.TenantID
+			}
+		}
+		c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), id))
 		c.Next()
 	}
 }
+
+// playgroundPolicy lets the playground page load GraphiQL from its CDN and
+// run its inline script, the API responses keep the strict policy
+const playgroundPolicy = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline' https://cdn.jsdelivr.net; " +
+	"style-src 'self' 'unsafe-inline' https://cdn.jsdelivr.net; " +
+	"img-src 'self' data: https://cdn.jsdelivr.net; " +
+	"connect-src 'self' ws: wss:; frame-ancestors 'none'"
+
+func playgroundHandler() gin.HandlerFunc {
+	h := playground.Handler("GraphQL Playground", "/graphql")
+	return func(c *gin.Context) {
+		c.Header("Content-Security-Policy", playgroundPolicy)
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
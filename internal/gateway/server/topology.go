@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/discovery"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/version"
+)
+
+// Topology is what is running in the cluster, as announced by the service
+// instances themselves
+type Topology struct {
+	GeneratedAt time.Time         `json:"generatedAt"`
+	Gateway     BuildInfo         `json:"gateway"`
+	EventBus    EventBusInfo      `json:"eventBus"`
+	Services    []ServiceTopology `json:"services"`
+}
+
+// BuildInfo identifies a binary
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+}
+
+// EventBusInfo is the event bus the gateway is configured with
+type EventBusInfo struct {
+	Backend string   `json:"backend"`
+	Brokers []string `json:"brokers"`
+}
+
+// ServiceTopology summarizes the live instances of a service. A service
+// that registered before but has no live instance reports zero replicas.
+type ServiceTopology struct {
+	Name            string `json:"name"`
+	Replicas        int    `json:"replicas"`
+	DesiredReplicas int    `json:"desiredReplicas,omitempty"`
+	// Versions lists every version running, more than one during a
+	// rollout or when it is stuck
+	Versions []string `json:"versions"`
+	// Features enabled on any replica
+	Features []string `json:"features"`
+	// Workers sums the worker pools of executor replicas
+	Workers   int                          `json:"workers,omitempty"`
+	Instances []*discovery.ServiceInstance `json:"instances"`
+}
+
+// topologyHandler serves the topology, services are read concurrently
+func topologyHandler(d *discovery.RedisDiscovery, cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		names, err := d.Services(ctx)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		sort.Strings(names)
+
+		services := make([]ServiceTopology, len(names))
+		errs := make([]error, len(names))
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				instances, err := d.Discover(ctx, name)
+				services[i], errs[i] = summarize(name, instances), err
+			}(i, name)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, Topology{
+			GeneratedAt: time.Now().UTC(),
+			Gateway: BuildInfo{
+				Version:   version.Version,
+				Commit:    version.CommitHash,
+				BuildTime: version.BuildTime,
+			},
+			EventBus: eventBusInfo(cfg),
+			Services: services,
+		})
+	}
+}
+
+// eventBusInfo lists the servers of the configured backend
+func eventBusInfo(cfg *config.Config) EventBusInfo {
+	if cfg.Kafka.Backend == events.BackendNATS {
+		return EventBusInfo{Backend: cfg.Kafka.Backend, Brokers: strings.Split(cfg.Kafka.NATS.URL, ",")}
+	}
+	return EventBusInfo{Backend: cfg.Kafka.Backend, Brokers: cfg.Kafka.Brokers}
+}
+
+func summarize(name string, instances []*discovery.ServiceInstance) ServiceTopology {
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+
+	service := ServiceTopology{
+		Name:      name,
+		Replicas:  len(instances),
+		Versions:  []string{},
+		Features:  []string{},
+		Instances: instances,
+	}
+
+	versions := make(map[string]bool)
+	features := make(map[string]bool)
+	for _, instance := range instances {
+		versions[instance.Metadata[discovery.MetadataVersion]] = true
+		for _, feature := range strings.Split(instance.Metadata[discovery.MetadataFeatures], ",") {
+			if feature != "" {
+				features[feature] = true
+			}
+		}
+		if workers, err := strconv.Atoi(instance.Metadata[discovery.MetadataWorkers]); err == nil {
+			service.Workers += workers
+		}
+		if desired, err := strconv.Atoi(instance.Metadata[discovery.MetadataDesiredReplicas]); err == nil {
+			service.DesiredReplicas = desired
+		}
+	}
+
+	for v := range versions {
+		service.Versions = append(service.Versions, v)
+	}
+	for f := range features {
+		service.Features = append(service.Features, f)
+	}
+	sort.Strings(service.Versions)
+	sort.Strings(service.Features)
+	return service
+}
+
+// adminAuth requires the shared admin token as a bearer token
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if provided == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing admin token"})
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
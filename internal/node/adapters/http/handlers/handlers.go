@@ -24,10 +24,6 @@ func (h *NodeHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
-func (h *NodeHandlers) Ready(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
-}
-
 func (h *NodeHandlers) ListNodeTypes(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"node_types": []interface{}{}})
 }
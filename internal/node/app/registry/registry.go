@@ -371,6 +371,81 @@ func (r *NodeRegistry) RegisterBuiltinNodes() {
 			Status:    "active",
 			IsBuiltin: true,
 		},
+		{
+			ID:          uuid.New().String(),
+			Type:        "validate",
+			Name:        "Validate",
+			Description: "Validate items against a JSON Schema or rule set",
+			Category:    "transform",
+			Icon:        "check-circle",
+			Color:       "#55efc4",
+			Version:     "1.0.0",
+			Schema: node.NodeSchema{
+				Inputs: []node.SchemaField{
+					{
+						Name:        "field",
+						Type:        "string",
+						Label:       "Items Field",
+						Description: "Path to the array of items to validate (defaults to the whole input)",
+					},
+					{
+						Name:  "schema",
+						Type:  "json",
+						Label: "JSON Schema",
+					},
+					{
+						Name:        "rules",
+						Type:        "array",
+						Label:       "Rules",
+						Description: "Conditions with field, operator, value and message",
+					},
+					{
+						Name:    "stopOnFirstError",
+						Type:    "boolean",
+						Label:   "Stop On First Error",
+						Default: false,
+					},
+				},
+				Outputs: []node.SchemaField{
+					{
+						Name:  "valid",
+						Type:  "array",
+						Label: "Valid Items",
+					},
+					{
+						Name:  "invalid",
+						Type:  "array",
+						Label: "Invalid Items",
+					},
+				},
+			},
+			Status:    "active",
+			IsBuiltin: true,
+		},
+		{
+			ID:          uuid.New().String(),
+			Type:        "metric",
+			Name:        "Metric",
+			Description: "Count business outcomes, such as invoices processed, on the workflow dashboard",
+			Category:    "transform",
+			Icon:        "bar-chart",
+			Color:       "#00cec9",
+			Version:     "1.0.0",
+			Schema: node.NodeSchema{
+				Inputs: []node.SchemaField{
+					{
+						Name:        "metrics",
+						Type:        "json",
+						Label:       "Metrics",
+						Required:    true,
+						Description: "Metric names mapped to a number or the path of a numeric input field, summed per day",
+						Placeholder: "{\n  \"invoices_processed\": 1,\n  \"revenue\": \"invoice.total\"\n}",
+					},
+				},
+			},
+			Status:    "active",
+			IsBuiltin: true,
+		},
 		// Control flow nodes
 		{
 			ID:          uuid.New().String(),
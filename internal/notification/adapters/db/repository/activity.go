@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/contracts/user"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+)
+
+// maxDigestApprovals bounds the pending approvals listed in a summary
+const maxDigestApprovals = 10
+
+// ListWorkflowOwners lists the users owning a workflow
+func (r *NotificationRepository) ListWorkflowOwners(ctx context.Context) ([]string, error) {
+	var owners []string
+	err := r.db.WithContext(ctx).
+		Model(&workflow.Workflow{}).
+		Where("deleted_at IS NULL").
+		Distinct().
+		Order("user_id").
+		Pluck("user_id", &owners).Error
+	return owners, err
+}
+
+// GetUser returns the account of a user
+func (r *NotificationRepository) GetUser(ctx context.Context, userID string) (*user.User, error) {
+	var u user.User
+	err := r.db.WithContext(ctx).
+		Select("id", "email", "username", "first_name", "last_name", "status").
+		Where("id = ?", userID).
+		First(&u).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("user not found: %s", userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// GetActivitySummary summarizes the executions started from from to to of
+// the workflows of a user, their cost and the executions of them paused for
+// an approval now
+func (r *NotificationRepository) GetActivitySummary(ctx context.Context, userID string, from, to time.Time) (*notification.ActivitySummary, error) {
+	summary := &notification.ActivitySummary{
+		UserID:    userID,
+		From:      from,
+		To:        to,
+		Workflows: []notification.WorkflowActivity{},
+		Approvals: []notification.PendingApproval{},
+	}
+
+	var workflows []workflow.Workflow
+	if err := r.db.WithContext(ctx).
+		Select("id", "name").
+		Where("user_id = ? AND deleted_at IS NULL", userID).
+		Find(&workflows).Error; err != nil {
+		return nil, err
+	}
+	if len(workflows) == 0 {
+		return summary, nil
+	}
+	ids := make([]string, 0, len(workflows))
+	names := make(map[string]string, len(workflows))
+	for _, w := range workflows {
+		ids = append(ids, w.ID)
+		names[w.ID] = w.Name
+	}
+
+	var rows []notification.WorkflowActivity
+	if err := r.db.WithContext(ctx).
+		Model(&workflow.WorkflowExecution{}).
+		Select(`workflow_id,
+			COUNT(*) AS executions,
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS succeeded,
+			COALESCE(SUM(CASE WHEN status IN (?, ?) THEN 1 ELSE 0 END), 0) AS failed`,
+			workflow.ExecutionCompleted, workflow.ExecutionFailed, workflow.ExecutionTimeout).
+		Where("workflow_id IN ? AND created_at >= ? AND created_at < ?", ids, from, to).
+		Group("workflow_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		row.Name = names[row.WorkflowID]
+		summary.Executions += row.Executions
+		summary.Succeeded += row.Succeeded
+		summary.Failed += row.Failed
+		summary.Workflows = append(summary.Workflows, row)
+	}
+	sort.Slice(summary.Workflows, func(i, j int) bool {
+		a, b := summary.Workflows[i], summary.Workflows[j]
+		if a.Failed != b.Failed {
+			return a.Failed > b.Failed
+		}
+		if a.Executions != b.Executions {
+			return a.Executions > b.Executions
+		}
+		return a.Name < b.Name
+	})
+
+	var cost struct {
+		Total    float64
+		Currency string
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&execution.ExecutionCost{}).
+		Select("COALESCE(SUM(total), 0) AS total, COALESCE(MAX(currency), '') AS currency").
+		Where("user_id = ? AND calculated_at >= ? AND calculated_at < ?", userID, from, to).
+		Scan(&cost).Error; err != nil {
+		return nil, err
+	}
+	summary.Cost = cost.Total
+	summary.Currency = cost.Currency
+
+	if err := r.db.WithContext(ctx).
+		Model(&workflow.WorkflowExecution{}).
+		Where("workflow_id IN ? AND status = ?", ids, workflow.ExecutionPaused).
+		Count(&summary.PendingApprovals).Error; err != nil {
+		return nil, err
+	}
+	if summary.PendingApprovals > 0 {
+		var executions []workflow.WorkflowExecution
+		if err := r.db.WithContext(ctx).
+			Select("id", "workflow_id", "started_at").
+			Where("workflow_id IN ? AND status = ?", ids, workflow.ExecutionPaused).
+			Order("started_at").
+			Limit(maxDigestApprovals).
+			Find(&executions).Error; err != nil {
+			return nil, err
+		}
+		for _, e := range executions {
+			summary.Approvals = append(summary.Approvals, notification.PendingApproval{
+				ExecutionID:  e.ID,
+				WorkflowID:   e.WorkflowID,
+				WorkflowName: names[e.WorkflowID],
+				Since:        e.StartedAt,
+			})
+		}
+	}
+
+	return summary, nil
+}
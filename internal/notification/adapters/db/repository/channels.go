@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"gorm.io/gorm"
+)
+
+// GetChannel returns the channel of a type of a user
+func (r *NotificationRepository) GetChannel(ctx context.Context, userID, channelType string) (*notification.Channel, error) {
+	var channel notification.Channel
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND type = ?", userID, channelType).
+		First(&channel).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, notification.ErrChannelNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &channel, nil
+}
+
+// SaveChannel creates or replaces a channel
+func (r *NotificationRepository) SaveChannel(ctx context.Context, channel *notification.Channel) error {
+	return r.db.WithContext(ctx).Save(channel).Error
+}
+
+// ListIncidentChannels lists the active incident channels of a user
+func (r *NotificationRepository) ListIncidentChannels(ctx context.Context, userID string) ([]*notification.Channel, error) {
+	var channels []*notification.Channel
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_active = ? AND type IN ?", userID, true,
+			[]string{notification.ChannelTypePagerDuty, notification.ChannelTypeOpsgenie}).
+		Find(&channels).Error
+	return channels, err
+}
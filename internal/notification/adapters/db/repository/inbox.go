@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/database"
+	"gorm.io/gorm"
+)
+
+// inbox scopes a query to the notifications in the inbox of a user at now,
+// as Notification.InInbox
+func (r *NotificationRepository) inbox(ctx context.Context, userID string, now time.Time) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&notification.Notification{}).
+		Where("user_id = ?", userID).
+		Where("status NOT IN ?", []string{notification.StatusQueued, notification.StatusDigested}).
+		Where("scheduled_at IS NULL OR scheduled_at <= ?", now)
+}
+
+// ListInbox lists a page of the inbox of a user, newest first, the unread
+// notifications only when unreadOnly
+func (r *NotificationRepository) ListInbox(ctx context.Context, userID string, unreadOnly bool, now time.Time, page *database.CursorPage) ([]*notification.Notification, error) {
+	query := r.inbox(ctx, userID, now)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []*notification.Notification
+	if err := r.db.PaginateCursor(ctx, &notifications, page, query); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// CountUnread counts the unread notifications in the inbox of a user
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID string, now time.Time) (int64, error) {
+	var count int64
+	err := r.inbox(ctx, userID, now).
+		Where("read_at IS NULL").
+		Count(&count).Error
+	return count, err
+}
+
+// GetNotification returns a notification of a user
+func (r *NotificationRepository) GetNotification(ctx context.Context, userID, id string) (*notification.Notification, error) {
+	var n notification.Notification
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&n).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, notification.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+// MarkRead marks a notification of a user as read, keeping when it was
+// first read
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id string, at time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&notification.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Updates(map[string]interface{}{
+			"status":  notification.StatusRead,
+			"read_at": gorm.Expr("COALESCE(read_at, ?)", at),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notification.ErrNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks the unread notifications in the inbox of a user as
+// read and returns how many there were
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string, at time.Time) (int64, error) {
+	result := r.inbox(ctx, userID, at).
+		Where("read_at IS NULL").
+		Updates(map[string]interface{}{
+			"status":  notification.StatusRead,
+			"read_at": at,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteNotification removes a notification of a user
+func (r *NotificationRepository) DeleteNotification(ctx context.Context, userID, id string) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&notification.Notification{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notification.ErrNotFound
+	}
+	return nil
+}
+
+// PruneNotifications removes the notifications created before and those
+// read or delivered within a digest before readBefore, returning how many
+func (r *NotificationRepository) PruneNotifications(ctx context.Context, before, readBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("created_at < ?", before).
+		Or("read_at < ?", readBefore).
+		Or("status = ? AND sent_at < ?", notification.StatusDigested, readBefore).
+		Delete(&notification.Notification{})
+	return result.RowsAffected, result.Error
+}
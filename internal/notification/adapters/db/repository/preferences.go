@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+)
+
+// GetPreferences returns the notification preferences of a user
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID string) (*notification.Preferences, error) {
+	var preferences notification.Preferences
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		First(&preferences).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, notification.ErrPreferencesNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &preferences, nil
+}
+
+// SavePreferences creates or replaces the preferences of a user
+func (r *NotificationRepository) SavePreferences(ctx context.Context, preferences *notification.Preferences) error {
+	return r.db.WithContext(ctx).Save(preferences).Error
+}
+
+// MarkActivityDigestSent records when the last activity digest of a user
+// was sent
+func (r *NotificationRepository) MarkActivityDigestSent(ctx context.Context, userID string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&notification.Preferences{}).
+		Where("user_id = ?", userID).
+		Update("activity_digest_sent_at", at).Error
+}
+
+// ListDigestDue lists the notifications held for a digest due by now,
+// oldest first
+func (r *NotificationRepository) ListDigestDue(ctx context.Context, now time.Time) ([]*notification.Notification, error) {
+	var notifications []*notification.Notification
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND scheduled_at <= ?", notification.StatusQueued, now).
+		Order("user_id, created_at").
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// MarkDigested marks notifications as delivered within a digest
+func (r *NotificationRepository) MarkDigested(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Model(&notification.Notification{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"status":  notification.StatusDigested,
+			"sent_at": time.Now(),
+		}).Error
+}
+
+// GetWorkflowOwner returns the user owning a workflow
+func (r *NotificationRepository) GetWorkflowOwner(ctx context.Context, workflowID string) (string, error) {
+	var owners []string
+	err := r.db.WithContext(ctx).
+		Model(&workflow.Workflow{}).
+		Where("id = ?", workflowID).
+		Pluck("user_id", &owners).Error
+	if err != nil {
+		return "", err
+	}
+	if len(owners) == 0 {
+		return "", fmt.Errorf("workflow not found: %s", workflowID)
+	}
+	return owners[0], nil
+}
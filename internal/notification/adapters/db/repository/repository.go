@@ -15,14 +15,5 @@ func NewNotificationRepository(db *database.DB) *NotificationRepository {
 }
 
 func (r *NotificationRepository) CreateNotification(ctx context.Context, notification interface{}) error {
-	return r.db.WithContext(ctx).Create(&notification).Error
-}
-
-func (r *NotificationRepository) GetNotifications(ctx context.Context, userID string) ([]interface{}, error) {
-	var notifications []interface{}
-	return notifications, nil
-}
-
-func (r *NotificationRepository) MarkAsRead(ctx context.Context, id string) error {
-	return nil
+	return r.db.WithContext(ctx).Create(notification).Error
 }
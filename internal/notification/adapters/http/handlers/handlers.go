@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/linkflow-go/internal/notification/app/service"
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/database"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/logger"
 )
 
@@ -24,10 +28,6 @@ func (h *NotificationHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
-func (h *NotificationHandlers) Ready(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
-}
-
 func (h *NotificationHandlers) SendNotification(c *gin.Context) {
 	c.JSON(http.StatusAccepted, gin.H{"message": "Notification sent"})
 }
@@ -40,24 +40,114 @@ func (h *NotificationHandlers) BroadcastNotification(c *gin.Context) {
 	c.JSON(http.StatusAccepted, gin.H{"message": "Broadcast sent"})
 }
 
+// ListNotifications lists a page of the inbox of the user, newest first,
+// the unread notifications only with ?unread=true
 func (h *NotificationHandlers) ListNotifications(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"notifications": []interface{}{}})
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page := database.NewCursorPage(limit, c.Query("cursor"))
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, err := h.service.ListInbox(c.Request.Context(), userID, unreadOnly, page)
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+	unread, err := h.service.UnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"unreadCount":   unread,
+		"nextCursor":    page.Next,
+	})
+}
+
+// GetUnreadCount returns how many notifications in the inbox of the user
+// are unread
+func (h *NotificationHandlers) GetUnreadCount(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+
+	unread, err := h.service.UnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unreadCount": unread})
 }
 
 func (h *NotificationHandlers) GetNotification(c *gin.Context) {
-	id := c.Param("id")
-	c.JSON(http.StatusOK, gin.H{"id": id, "notification": "Notification details"})
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+
+	n, err := h.service.GetNotification(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notification": n})
 }
 
 func (h *NotificationHandlers) MarkAsRead(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+
+	if err := h.service.MarkRead(c.Request.Context(), userID, c.Param("id")); err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Marked as read"})
 }
 
 func (h *NotificationHandlers) MarkAllAsRead(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "All marked as read"})
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+
+	marked, err := h.service.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All marked as read", "marked": marked})
 }
 
 func (h *NotificationHandlers) DeleteNotification(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+
+	if err := h.service.DeleteNotification(c.Request.Context(), userID, c.Param("id")); err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
 	c.Status(http.StatusNoContent)
 }
 
@@ -81,35 +171,168 @@ func (h *NotificationHandlers) DeleteTemplate(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// GetPreferences returns the notification preferences of the user
 func (h *NotificationHandlers) GetPreferences(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"preferences": map[string]interface{}{}})
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+
+	preferences, err := h.service.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preferences})
 }
 
+// UpdatePreferences sets the notification preferences of the user
 func (h *NotificationHandlers) UpdatePreferences(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Preferences updated"})
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+
+	// Fields left out of the request keep their value
+	current, err := h.service.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+	req := current.Request()
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preferences, err := h.service.SetPreferences(c.Request.Context(), userID, req)
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preferences})
 }
 
+// Unsubscribe stops the notifications of a type to the user
 func (h *NotificationHandlers) Unsubscribe(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Unsubscribed"})
+	h.setSubscribed(c, false)
 }
 
+// Subscribe resumes the notifications of a type to the user
 func (h *NotificationHandlers) Subscribe(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Subscribed"})
+	h.setSubscribed(c, true)
 }
 
+func (h *NotificationHandlers) setSubscribed(c *gin.Context, subscribed bool) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+
+	var req struct {
+		Type string `json:"type" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preferences, err := h.service.SetSubscribed(c.Request.Context(), userID, req.Type, subscribed)
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preferences})
+}
+
+// ListChannels lists the channel types, pagerduty and opsgenie being the
+// incident channels users configure
 func (h *NotificationHandlers) ListChannels(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"channels": []string{"email", "sms", "slack", "push", "teams"}})
+	c.JSON(http.StatusOK, gin.H{"channels": []string{
+		"email", "sms", "slack", "push", "teams",
+		notification.ChannelTypePagerDuty, notification.ChannelTypeOpsgenie,
+	}})
 }
 
+// GetChannelConfig returns the configuration of an incident channel of the
+// user, its keys masked
 func (h *NotificationHandlers) GetChannelConfig(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"config": map[string]interface{}{}})
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+	if !notification.IsIncidentChannel(c.Param("channel")) {
+		c.JSON(http.StatusOK, gin.H{"config": map[string]interface{}{}})
+		return
+	}
+
+	channel, err := h.service.IncidentChannel(c.Request.Context(), userID, c.Param("channel"))
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"config": channel.Masked(), "isActive": channel.IsActive})
 }
 
+// UpdateChannelConfig sets the configuration of an incident channel of the
+// user: the routing key of a PagerDuty service or the API key of an
+// Opsgenie integration, and the region of the account
 func (h *NotificationHandlers) UpdateChannelConfig(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Channel config updated"})
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+	if !notification.IsIncidentChannel(c.Param("channel")) {
+		c.JSON(http.StatusOK, gin.H{"message": "Channel config updated"})
+		return
+	}
+
+	var req struct {
+		Config   map[string]string `json:"config" binding:"required"`
+		IsActive *bool             `json:"isActive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	active := req.IsActive == nil || *req.IsActive
+
+	channel, err := h.service.SetIncidentChannel(c.Request.Context(), userID, c.Param("channel"), req.Config, active)
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"config": channel.Masked(), "isActive": channel.IsActive})
 }
 
+// TestChannel pages a test incident through an incident channel of the
+// user and resolves it
 func (h *NotificationHandlers) TestChannel(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+	if !notification.IsIncidentChannel(c.Param("channel")) {
+		c.JSON(http.StatusOK, gin.H{"success": true})
+		return
+	}
+
+	if err := h.service.TestIncidentChannel(c.Request.Context(), userID, c.Param("channel")); err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
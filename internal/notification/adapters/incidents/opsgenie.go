@@ -0,0 +1,64 @@
+package incidents
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+)
+
+// Opsgenie pages incidents through the Alert API of Opsgenie, the dedup key
+// of an incident is the alias of its alert
+type Opsgenie struct {
+	client *http.Client
+}
+
+// NewOpsgenie creates an Opsgenie client
+func NewOpsgenie() *Opsgenie {
+	return &Opsgenie{client: &http.Client{Timeout: requestTimeout}}
+}
+
+// opsgeniePriorities map the severities of incidents to alert priorities
+var opsgeniePriorities = map[string]string{
+	notification.SeverityCritical: "P1",
+	notification.SeverityError:    "P2",
+	notification.SeverityWarning:  "P3",
+}
+
+// Page creates the alert of the incident with the API key of config, or
+// closes it
+func (o *Opsgenie) Page(ctx context.Context, config map[string]string, incident *notification.Incident) error {
+	base := "https://api.opsgenie.com/v2/alerts"
+	if config[notification.ConfigRegion] == notification.RegionEU {
+		base = "https://api.eu.opsgenie.com/v2/alerts"
+	}
+	header := http.Header{"Authorization": {"GenieKey " + config[notification.ConfigAPIKey]}}
+
+	if incident.Resolved {
+		closeURL := fmt.Sprintf("%s/%s/close?identifierType=alias", base, url.PathEscape(truncate(incident.DedupKey, 512)))
+		return post(ctx, o.client, closeURL, header, map[string]string{
+			"source": source,
+			"note":   truncate(incident.Summary, 25000),
+		})
+	}
+
+	details := make(map[string]string, len(incident.Details))
+	for k, v := range incident.Details {
+		details[k] = fmt.Sprint(v)
+	}
+	priority, ok := opsgeniePriorities[incident.Severity]
+	if !ok {
+		priority = "P3"
+	}
+	return post(ctx, o.client, base, header, map[string]interface{}{
+		"message":     truncate(incident.Summary, 130),
+		"alias":       truncate(incident.DedupKey, 512),
+		"description": truncate(incident.Summary, 15000),
+		"priority":    priority,
+		"source":      source,
+		"tags":        []string{source, incident.Source},
+		"details":     details,
+	})
+}
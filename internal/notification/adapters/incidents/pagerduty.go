@@ -0,0 +1,111 @@
+// Package incidents pages incidents to incident management services
+package incidents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+)
+
+// source names LinkFlow as the origin of the alerts
+const source = "linkflow"
+
+// requestTimeout bounds each call to a service
+const requestTimeout = 10 * time.Second
+
+// PagerDuty pages incidents through the Events API v2 of PagerDuty
+type PagerDuty struct {
+	client *http.Client
+}
+
+// NewPagerDuty creates a PagerDuty client
+func NewPagerDuty() *PagerDuty {
+	return &PagerDuty{client: &http.Client{Timeout: requestTimeout}}
+}
+
+// pagerDutyEvent is an event of the Events API v2
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp"`
+	Component     string                 `json:"component"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// Page triggers the incident on the service of the routing key of config,
+// or resolves it
+func (p *PagerDuty) Page(ctx context.Context, config map[string]string, incident *notification.Incident) error {
+	event := pagerDutyEvent{
+		RoutingKey:  config[notification.ConfigRoutingKey],
+		EventAction: "trigger",
+		DedupKey:    incident.DedupKey,
+	}
+	if incident.Resolved {
+		event.EventAction = "resolve"
+	} else {
+		event.Payload = &pagerDutyPayload{
+			Summary:       truncate(incident.Summary, 1024),
+			Source:        source,
+			Severity:      incident.Severity,
+			Timestamp:     incident.Timestamp.UTC().Format(time.RFC3339),
+			Component:     incident.Source,
+			CustomDetails: incident.Details,
+		}
+	}
+
+	url := "https://events.pagerduty.com/v2/enqueue"
+	if config[notification.ConfigRegion] == notification.RegionEU {
+		url = "https://events.eu.pagerduty.com/v2/enqueue"
+	}
+	return post(ctx, p.client, url, nil, event)
+}
+
+// post sends body as JSON and fails on any status but 2xx
+func post(ctx context.Context, client *http.Client, url string, header http.Header, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s answered %d: %s", req.URL.Host, resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// truncate shortens s to the max bytes a field of a service takes
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,187 @@
+// Package activity emails users a daily or weekly digest of the activity of
+// their workflows: executions, failures, cost and pending approvals
+package activity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/internal/notification/ports"
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+const activityLockKey = "notification:activity:lock"
+
+// Mailer sends the rendered digests, a *notification.Email each
+type Mailer interface {
+	Send(ctx context.Context, recipient string, message interface{}) error
+}
+
+// Generator sends, every interval and on one replica at a time, the
+// activity digests due to the users owning workflows
+type Generator struct {
+	repo     ports.NotificationRepository
+	mailer   Mailer
+	redis    *redis.Client
+	interval time.Duration
+	logger   logger.Logger
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// NewGenerator creates a generator running every interval once started
+func NewGenerator(repo ports.NotificationRepository, mailer Mailer, redis *redis.Client, interval time.Duration, log logger.Logger) *Generator {
+	return &Generator{
+		repo:     repo,
+		mailer:   mailer,
+		redis:    redis,
+		interval: interval,
+		logger:   log,
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start sends the due digests every interval until Stop
+func (g *Generator) Start() {
+	g.startOnce.Do(func() {
+		go g.run()
+	})
+}
+
+// Stop ends the background runs and waits for the current one
+func (g *Generator) Stop() {
+	g.stopOnce.Do(func() {
+		close(g.stopCh)
+	})
+	g.startOnce.Do(func() {
+		close(g.done)
+	})
+	<-g.done
+}
+
+func (g *Generator) run() {
+	defer close(g.done)
+
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-g.stopCh:
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-g.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		if err := g.Tick(ctx); err != nil {
+			g.logger.Error("Failed to send activity digests", "error", err)
+		}
+		cancel()
+	}
+}
+
+// Tick sends the digests due by now, unless another replica is doing so.
+// A digest failing to send is retried on the next tick.
+func (g *Generator) Tick(ctx context.Context) error {
+	owner := uuid.New().String()
+	ok, err := g.redis.SetNX(ctx, activityLockKey, owner, g.interval).Result()
+	if err != nil || !ok {
+		return err
+	}
+	defer g.unlock(owner)
+
+	users, err := g.repo.ListWorkflowOwners(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var errs []error
+	for _, userID := range users {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := g.send(ctx, userID, now); err != nil {
+			errs = append(errs, fmt.Errorf("activity digest of %s: %w", userID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send emails a user the digest due by now, if any. Digests of periods
+// without activity, and of users not receiving email, are skipped but
+// count as sent.
+func (g *Generator) send(ctx context.Context, userID string, now time.Time) error {
+	preferences, err := g.repo.GetPreferences(ctx, userID)
+	stored := err == nil
+	if errors.Is(err, notification.ErrPreferencesNotFound) {
+		preferences = notification.NewPreferences(userID)
+	} else if err != nil {
+		return err
+	}
+
+	from, to, due := preferences.ActivityPeriod(now)
+	if !due {
+		return nil
+	}
+
+	summary, err := g.repo.GetActivitySummary(ctx, userID, from.UTC(), to.UTC())
+	if err != nil {
+		return err
+	}
+	summary.Frequency = preferences.ActivityDigest
+
+	if !summary.Empty() && preferences.EmailEnabled {
+		account, err := g.repo.GetUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if account.Email != "" {
+			name := account.FirstName
+			if name == "" {
+				name = account.Username
+			}
+			email, err := Render(summary, name, preferences.Location())
+			if err != nil {
+				return err
+			}
+			if err := g.mailer.Send(ctx, account.Email, email); err != nil {
+				return err
+			}
+			g.logger.Info("Activity digest sent", "userId", userID, "frequency", summary.Frequency,
+				"executions", summary.Executions, "failed", summary.Failed)
+		}
+	}
+
+	sentAt := now.UTC()
+	if stored {
+		return g.repo.MarkActivityDigestSent(ctx, userID, sentAt)
+	}
+	preferences.ActivityDigestSentAt = &sentAt
+	return g.repo.SavePreferences(ctx, preferences)
+}
+
+// unlock releases the lock if it is still held by owner
+func (g *Generator) unlock(owner string) {
+	ctx := context.Background()
+	if held, err := g.redis.Get(ctx, activityLockKey).Result(); err == nil && held == owner {
+		g.redis.Del(ctx, activityLockKey)
+	}
+}
@@ -0,0 +1,90 @@
+package activity
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// maxDigestWorkflows bounds the workflows listed in a digest
+const maxDigestWorkflows = 10
+
+var funcs = map[string]interface{}{
+	"date": func(t time.Time) string {
+		return t.Format("Mon Jan 2 15:04 MST")
+	},
+	"plural": plural,
+	"money": func(amount float64, currency string) string {
+		if currency == "" {
+			return fmt.Sprintf("%.2f", amount)
+		}
+		return fmt.Sprintf("%.2f %s", amount, currency)
+	},
+}
+
+var (
+	htmlDigest = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(funcs).ParseFS(templates, "templates/digest.html"))
+	textDigest = texttemplate.Must(texttemplate.New("digest.txt").Funcs(funcs).ParseFS(templates, "templates/digest.txt"))
+)
+
+// digestData is what the digest templates render
+type digestData struct {
+	*notification.ActivitySummary
+	Name string
+	// More counts the workflows left out of Workflows
+	More int
+}
+
+// Render renders the activity digest email of a summary, addressed to name
+// and dated in loc
+func Render(summary *notification.ActivitySummary, name string, loc *time.Location) (*notification.Email, error) {
+	local := *summary
+	local.From = summary.From.In(loc)
+	local.To = summary.To.In(loc)
+	local.Approvals = make([]notification.PendingApproval, len(summary.Approvals))
+	for i, approval := range summary.Approvals {
+		approval.Since = approval.Since.In(loc)
+		local.Approvals[i] = approval
+	}
+
+	data := digestData{ActivitySummary: &local, Name: name}
+	if len(local.Workflows) > maxDigestWorkflows {
+		data.More = len(local.Workflows) - maxDigestWorkflows
+		local.Workflows = local.Workflows[:maxDigestWorkflows]
+	}
+
+	var html, text bytes.Buffer
+	if err := htmlDigest.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render activity digest: %w", err)
+	}
+	if err := textDigest.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render activity digest: %w", err)
+	}
+
+	subject := fmt.Sprintf("Your %s LinkFlow activity: %s, %d failed", summary.Frequency, plural(summary.Executions, "execution"), summary.Failed)
+	if summary.PendingApprovals > 0 {
+		subject += fmt.Sprintf(", %d awaiting approval", summary.PendingApprovals)
+	}
+
+	return &notification.Email{
+		Subject: subject,
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
+
+// plural counts n of a noun taking an s in the plural
+func plural(n int64, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
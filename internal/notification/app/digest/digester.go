@@ -0,0 +1,179 @@
+// Package digest delivers the notifications held for the digests of the
+// users who asked for them
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/internal/notification/ports"
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+const digestLockKey = "notification:digest:lock"
+
+// Inbox stores the digests in the inboxes of their users
+type Inbox interface {
+	AddNotification(ctx context.Context, n *notification.Notification) error
+}
+
+// maxDigestLines bounds the notifications listed in the body of a digest,
+// all of them are in its data
+const maxDigestLines = 20
+
+// Digester gathers the notifications held for a digest into one digest
+// notification per user once it is due, every interval, on one replica at
+// a time
+type Digester struct {
+	repo     ports.NotificationRepository
+	inbox    Inbox
+	redis    *redis.Client
+	interval time.Duration
+	logger   logger.Logger
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// NewDigester creates a digester running every interval once started
+func NewDigester(repo ports.NotificationRepository, inbox Inbox, redis *redis.Client, interval time.Duration, log logger.Logger) *Digester {
+	return &Digester{
+		repo:     repo,
+		inbox:    inbox,
+		redis:    redis,
+		interval: interval,
+		logger:   log,
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start delivers the due digests every interval until Stop
+func (d *Digester) Start() {
+	d.startOnce.Do(func() {
+		go d.run()
+	})
+}
+
+// Stop ends the background runs and waits for the current one
+func (d *Digester) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stopCh)
+	})
+	d.startOnce.Do(func() {
+		close(d.done)
+	})
+	<-d.done
+}
+
+func (d *Digester) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-d.stopCh:
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-d.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		if err := d.Tick(ctx); err != nil {
+			d.logger.Error("Failed to deliver notification digests", "error", err)
+		}
+		cancel()
+	}
+}
+
+// Tick delivers the digests due by now, unless another replica is doing so
+func (d *Digester) Tick(ctx context.Context) error {
+	owner := uuid.New().String()
+	ok, err := d.redis.SetNX(ctx, digestLockKey, owner, d.interval).Result()
+	if err != nil || !ok {
+		return err
+	}
+	defer d.unlock(owner)
+
+	due, err := d.repo.ListDigestDue(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	var users []string
+	held := make(map[string][]*notification.Notification)
+	for _, n := range due {
+		if _, ok := held[n.UserID]; !ok {
+			users = append(users, n.UserID)
+		}
+		held[n.UserID] = append(held[n.UserID], n)
+	}
+
+	var errs []error
+	for _, userID := range users {
+		if err := d.deliver(ctx, userID, held[userID]); err != nil {
+			errs = append(errs, fmt.Errorf("digest of %s: %w", userID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver creates the digest of the notifications held for a user and
+// marks them as delivered within it
+func (d *Digester) deliver(ctx context.Context, userID string, held []*notification.Notification) error {
+	ids := make([]string, 0, len(held))
+	var body strings.Builder
+	fmt.Fprintf(&body, "Notifications since your last digest (%d):\n", len(held))
+	for i, n := range held {
+		ids = append(ids, n.ID)
+		if i < maxDigestLines {
+			fmt.Fprintf(&body, "- %s\n", n.Subject)
+		}
+	}
+	if len(held) > maxDigestLines {
+		fmt.Fprintf(&body, "- and %d more\n", len(held)-maxDigestLines)
+	}
+
+	digest := notification.NewNotification(userID, notification.TypeDigest, "Your notification digest", body.String())
+	digest.Data = map[string]interface{}{"notifications": ids}
+	if preferences, err := d.repo.GetPreferences(ctx, userID); err == nil {
+		digest.Channels = preferences.ChannelTypes()
+	} else {
+		digest.Channels = notification.NewPreferences(userID).ChannelTypes()
+	}
+
+	if err := d.inbox.AddNotification(ctx, digest); err != nil {
+		return err
+	}
+	if err := d.repo.MarkDigested(ctx, ids); err != nil {
+		return err
+	}
+
+	d.logger.Info("Notification digest delivered", "userId", userID, "notifications", len(held))
+	return nil
+}
+
+// unlock releases the lock if it is still held by owner
+func (d *Digester) unlock(owner string) {
+	ctx := context.Background()
+	if held, err := d.redis.Get(ctx, digestLockKey).Result(); err == nil && held == owner {
+		d.redis.Del(ctx, digestLockKey)
+	}
+}
@@ -0,0 +1,121 @@
+// Package retention removes the notifications past their retention
+package retention
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/internal/notification/ports"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+const pruneLockKey = "notification:retention:lock"
+
+// Pruner removes, every interval and on one replica at a time, the
+// notifications older than the retention and those read or delivered
+// within a digest longer ago than the read retention
+type Pruner struct {
+	repo          ports.NotificationRepository
+	redis         *redis.Client
+	interval      time.Duration
+	retention     time.Duration
+	readRetention time.Duration
+	logger        logger.Logger
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// NewPruner creates a pruner running every interval once started
+func NewPruner(repo ports.NotificationRepository, redis *redis.Client, interval, retention, readRetention time.Duration, log logger.Logger) *Pruner {
+	return &Pruner{
+		repo:          repo,
+		redis:         redis,
+		interval:      interval,
+		retention:     retention,
+		readRetention: readRetention,
+		logger:        log,
+		stopCh:        make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start prunes the notifications every interval until Stop
+func (p *Pruner) Start() {
+	p.startOnce.Do(func() {
+		go p.run()
+	})
+}
+
+// Stop ends the background runs and waits for the current one
+func (p *Pruner) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+	p.startOnce.Do(func() {
+		close(p.done)
+	})
+	<-p.done
+}
+
+func (p *Pruner) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.stopCh:
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-p.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		if err := p.Tick(ctx); err != nil {
+			p.logger.Error("Failed to prune notifications", "error", err)
+		}
+		cancel()
+	}
+}
+
+// Tick prunes the notifications past their retention, unless another
+// replica is doing so
+func (p *Pruner) Tick(ctx context.Context) error {
+	owner := uuid.New().String()
+	ok, err := p.redis.SetNX(ctx, pruneLockKey, owner, p.interval).Result()
+	if err != nil || !ok {
+		return err
+	}
+	defer p.unlock(owner)
+
+	now := time.Now()
+	pruned, err := p.repo.PruneNotifications(ctx, now.Add(-p.retention), now.Add(-p.readRetention))
+	if err != nil {
+		return err
+	}
+	if pruned > 0 {
+		p.logger.Info("Notifications pruned", "count", pruned)
+	}
+	return nil
+}
+
+// unlock releases the lock if it is still held by owner
+func (p *Pruner) unlock(owner string) {
+	ctx := context.Background()
+	if held, err := p.redis.Get(ctx, pruneLockKey).Result(); err == nil && held == owner {
+		p.redis.Del(ctx, pruneLockKey)
+	}
+}
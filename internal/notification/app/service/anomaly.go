@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// HandleWorkflowAnomaly alerts the owner of a workflow that one of its
+// executions ran far longer than usual, or that it fails far more than usual
+func (s *NotificationService) HandleWorkflowAnomaly(ctx context.Context, event events.Event) error {
+	var payload struct {
+		Kind        string     `json:"kind"`
+		WorkflowID  string     `json:"workflowId"`
+		UserID      string     `json:"userId"`
+		ExecutionID string     `json:"executionId"`
+		Value       float64    `json:"value"`
+		Baseline    float64    `json:"baseline"`
+		Sigma       float64    `json:"sigma"`
+		WindowStart *time.Time `json:"windowStart"`
+		Failed      int64      `json:"failed"`
+		Finished    int64      `json:"finished"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if payload.UserID == "" {
+		s.logger.Warn("Workflow anomaly has no owner to alert", "workflowId", payload.WorkflowID)
+		return nil
+	}
+
+	var subject, body string
+	switch payload.Kind {
+	case "error_burst":
+		since := event.Timestamp
+		if payload.WindowStart != nil {
+			since = *payload.WindowStart
+		}
+		subject = "Workflow failing more than usual"
+		body = fmt.Sprintf("Workflow %s failed %d of its %d executions since %s UTC, it usually fails %.1f%% of them.",
+			payload.WorkflowID, payload.Failed, payload.Finished,
+			since.UTC().Format("15:04"), payload.Baseline*100)
+	default:
+		subject = "Workflow execution slower than usual"
+		body = fmt.Sprintf("Execution %s of workflow %s ran %.0f ms, it usually runs %.0f ms.",
+			payload.ExecutionID, payload.WorkflowID, payload.Value, payload.Baseline)
+	}
+
+	n := notification.NewNotification(payload.UserID, notification.TypeAnomalyAlert, subject, body)
+	n.Priority = notification.PriorityHigh
+	n.Data = map[string]interface{}{
+		"kind":        payload.Kind,
+		"workflowId":  payload.WorkflowID,
+		"executionId": payload.ExecutionID,
+		"value":       payload.Value,
+		"baseline":    payload.Baseline,
+		"sigma":       payload.Sigma,
+	}
+	if err := s.deliver(ctx, n); err != nil {
+		return fmt.Errorf("failed to create anomaly alert: %w", err)
+	}
+
+	s.logger.Info("Anomaly alert created", "workflowId", payload.WorkflowID, "kind", payload.Kind)
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// HandleBudgetThreshold alerts the owner of the workflow whose execution
+// reached a threshold of a budget, and whoever set the budget
+func (s *NotificationService) HandleBudgetThreshold(ctx context.Context, event events.Event) error {
+	var payload struct {
+		BudgetID   string  `json:"budgetId"`
+		Scope      string  `json:"scope"`
+		ScopeID    string  `json:"scopeId"`
+		WorkflowID string  `json:"workflowId"`
+		UserID     string  `json:"userId"`
+		UpdatedBy  string  `json:"updatedBy"`
+		Threshold  int     `json:"threshold"`
+		Spent      float64 `json:"spent"`
+		Limit      float64 `json:"limit"`
+		Month      string  `json:"month"`
+		HardCap    bool    `json:"hardCap"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("Budget %d%% reached", payload.Threshold)
+	body := fmt.Sprintf("The %s budget of %s spent %.2f of its %.2f limit for %s.",
+		payload.Scope, payload.ScopeID, payload.Spent, payload.Limit, payload.Month)
+	priority := notification.PriorityNormal
+	switch {
+	case payload.Threshold >= 100 && payload.HardCap:
+		priority = notification.PriorityUrgent
+		body += " Its workflows are being deactivated."
+	case payload.Threshold >= 80:
+		priority = notification.PriorityHigh
+	}
+
+	recipients := []string{payload.UserID}
+	if payload.UpdatedBy != "" && payload.UpdatedBy != payload.UserID {
+		recipients = append(recipients, payload.UpdatedBy)
+	}
+	for _, userID := range recipients {
+		if userID == "" {
+			continue
+		}
+		n := notification.NewNotification(userID, notification.TypeBillingAlert, subject, body)
+		n.Priority = priority
+		n.Data = map[string]interface{}{
+			"budgetId":   payload.BudgetID,
+			"scope":      payload.Scope,
+			"scopeId":    payload.ScopeID,
+			"workflowId": payload.WorkflowID,
+			"threshold":  payload.Threshold,
+			"spent":      payload.Spent,
+			"limit":      payload.Limit,
+			"month":      payload.Month,
+		}
+		if err := s.deliver(ctx, n); err != nil {
+			return fmt.Errorf("failed to create budget alert: %w", err)
+		}
+	}
+
+	s.logger.Info("Budget alert created",
+		"budgetId", payload.BudgetID,
+		"threshold", payload.Threshold,
+		"recipients", len(recipients),
+	)
+	return nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/linkflow-go/pkg/contracts/execution"
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// HandleExecutionFailed alerts the owner of a workflow that one of its
+// executions failed or timed out, and pages an incident once too many
+// failed in a row
+func (s *NotificationService) HandleExecutionFailed(ctx context.Context, event events.Event) error {
+	var payload struct {
+		WorkflowID string `json:"workflowId"`
+		Error      string `json:"error"`
+		ErrorCode  string `json:"errorCode"`
+		Cause      string `json:"cause"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+	userID := s.owner(ctx, event.UserID, payload.WorkflowID)
+	s.trackFailure(ctx, payload.WorkflowID, userID, event.AggregateID, payload.Error)
+	if userID == "" {
+		return nil
+	}
+
+	subject := "Workflow execution failed"
+	body := fmt.Sprintf("Execution %s of workflow %s failed: %s", event.AggregateID, payload.WorkflowID, payload.Error)
+	if payload.Cause != "" {
+		subject = "Workflow execution timed out"
+		body = fmt.Sprintf("Execution %s of workflow %s timed out: %s", event.AggregateID, payload.WorkflowID, payload.Error)
+	}
+
+	n := notification.NewNotification(userID, notification.TypeExecutionFailure, subject, body)
+	n.Priority = notification.PriorityHigh
+	n.Data = map[string]interface{}{
+		"workflowId":  payload.WorkflowID,
+		"executionId": event.AggregateID,
+		"errorCode":   payload.ErrorCode,
+		"cause":       payload.Cause,
+	}
+	if err := s.deliver(ctx, n); err != nil {
+		return fmt.Errorf("failed to create execution failure alert: %w", err)
+	}
+	return nil
+}
+
+// HandleExecutionPaused asks the owner of a workflow to approve one of its
+// executions, paused until it is resumed
+func (s *NotificationService) HandleExecutionPaused(ctx context.Context, event events.Event) error {
+	var payload struct {
+		WorkflowID string `json:"workflowId"`
+		ToState    string `json:"toState"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if payload.ToState != string(execution.StatusPaused) {
+		return nil
+	}
+	userID := s.owner(ctx, event.UserID, payload.WorkflowID)
+	if userID == "" {
+		return nil
+	}
+
+	n := notification.NewNotification(userID, notification.TypeApprovalRequest,
+		"Workflow execution awaiting approval",
+		fmt.Sprintf("Execution %s of workflow %s is paused until it is approved and resumed.", event.AggregateID, payload.WorkflowID))
+	n.Priority = notification.PriorityHigh
+	n.Data = map[string]interface{}{
+		"workflowId":  payload.WorkflowID,
+		"executionId": event.AggregateID,
+	}
+	if err := s.deliver(ctx, n); err != nil {
+		return fmt.Errorf("failed to create approval request: %w", err)
+	}
+
+	s.logger.Info("Approval request created", "executionId", event.AggregateID, "workflowId", payload.WorkflowID)
+	return nil
+}
+
+// owner returns the user an event is for, the user it names or else the
+// owner of its workflow. Empty when there is none to alert.
+func (s *NotificationService) owner(ctx context.Context, userID, workflowID string) string {
+	if userID != "" || workflowID == "" {
+		return userID
+	}
+	userID, err := s.repo.GetWorkflowOwner(ctx, workflowID)
+	if err != nil {
+		s.logger.Warn("Failed to get owner of workflow to alert", "workflowId", workflowID, "error", err)
+	}
+	return userID
+}
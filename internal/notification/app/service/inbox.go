@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/database"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// AddNotification stores a notification, announcing it to the
+// subscriptions of its user when it is in their inbox at once
+func (s *NotificationService) AddNotification(ctx context.Context, n *notification.Notification) error {
+	if err := s.repo.CreateNotification(ctx, n); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if !n.InInbox(now) {
+		return nil
+	}
+	unread, err := s.repo.CountUnread(ctx, n.UserID, now)
+	if err != nil {
+		s.logger.Warn("Failed to count unread notifications", "userId", n.UserID, "error", err)
+		return nil
+	}
+
+	event := events.NewEventBuilder(events.NotificationCreated).
+		WithAggregateID(n.ID).
+		WithAggregateType("notification").
+		WithUserID(n.UserID).
+		WithPayload("notificationId", n.ID).
+		WithPayload("userId", n.UserID).
+		WithPayload("type", n.Type).
+		WithPayload("priority", n.Priority).
+		WithPayload("subject", n.Subject).
+		WithPayload("body", n.Body).
+		WithPayload("data", n.Data).
+		WithPayload("unreadCount", unread).
+		WithPayload("createdAt", n.CreatedAt).
+		Build()
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		s.logger.Warn("Failed to publish notification", "notificationId", n.ID, "error", err)
+	}
+	return nil
+}
+
+// ListInbox lists a page of the inbox of a user, newest first
+func (s *NotificationService) ListInbox(ctx context.Context, userID string, unreadOnly bool, page *database.CursorPage) ([]*notification.Notification, error) {
+	return s.repo.ListInbox(ctx, userID, unreadOnly, time.Now(), page)
+}
+
+// UnreadCount counts the unread notifications in the inbox of a user
+func (s *NotificationService) UnreadCount(ctx context.Context, userID string) (int64, error) {
+	return s.repo.CountUnread(ctx, userID, time.Now())
+}
+
+// GetNotification returns a notification of a user
+func (s *NotificationService) GetNotification(ctx context.Context, userID, id string) (*notification.Notification, error) {
+	return s.repo.GetNotification(ctx, userID, id)
+}
+
+// MarkRead marks a notification of a user as read
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id string) error {
+	if err := s.repo.MarkRead(ctx, userID, id, time.Now()); err != nil {
+		return err
+	}
+	s.publishUnread(ctx, userID)
+	return nil
+}
+
+// MarkAllRead marks every notification in the inbox of a user as read and
+// returns how many were unread
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	marked, err := s.repo.MarkAllRead(ctx, userID, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	if marked > 0 {
+		s.publishUnread(ctx, userID)
+	}
+	return marked, nil
+}
+
+// DeleteNotification removes a notification of a user
+func (s *NotificationService) DeleteNotification(ctx context.Context, userID, id string) error {
+	if err := s.repo.DeleteNotification(ctx, userID, id); err != nil {
+		return err
+	}
+	s.publishUnread(ctx, userID)
+	return nil
+}
+
+// publishUnread announces the unread count of a user to their
+// subscriptions
+func (s *NotificationService) publishUnread(ctx context.Context, userID string) {
+	unread, err := s.repo.CountUnread(ctx, userID, time.Now())
+	if err != nil {
+		s.logger.Warn("Failed to count unread notifications", "userId", userID, "error", err)
+		return
+	}
+
+	event := events.NewEventBuilder(events.NotificationUnreadChanged).
+		WithAggregateID(userID).
+		WithAggregateType("user").
+		WithUserID(userID).
+		WithPayload("userId", userID).
+		WithPayload("unreadCount", unread).
+		Build()
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		s.logger.Warn("Failed to publish unread count", "userId", userID, "error", err)
+	}
+}
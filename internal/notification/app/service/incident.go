@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/resilience"
+	"github.com/redis/go-redis/v9"
+)
+
+// Pager pages incidents to an incident management service with the
+// configuration of an incident channel
+type Pager interface {
+	Page(ctx context.Context, config map[string]string, incident *notification.Incident) error
+}
+
+// Incidents routes the critical events to incident management services
+type Incidents struct {
+	// Pagers page the incidents of each incident channel type
+	Pagers map[string]Pager
+	// Routes are the incident channels of the platform, paged with every
+	// incident
+	Routes []*notification.Channel
+	// FailureThreshold is how many executions of a workflow failing in a
+	// row open an incident
+	FailureThreshold int
+}
+
+// failuresKey counts the executions of a workflow failing in a row, until
+// one completes or none fails for failuresTTL
+const (
+	failuresKey = "notification:failures:"
+	failuresTTL = 24 * time.Hour
+)
+
+// pageRetry retries the services answering with an error for a few
+// seconds at most, the events paging them wait meanwhile
+var pageRetry = resilience.RetryConfig{
+	MaxAttempts:       3,
+	InitialDelay:      500 * time.Millisecond,
+	MaxDelay:          2 * time.Second,
+	BackoffMultiplier: 2.0,
+	Jitter:            0.1,
+}
+
+// escalate pages an incident to the incident channels of the platform and
+// to those of its user. Routes failing are logged rather than failing the
+// event, redelivering it would deliver its notifications twice.
+func (s *NotificationService) escalate(ctx context.Context, incident *notification.Incident) {
+	routes := s.incidents.Routes
+	if incident.UserID != "" {
+		channels, err := s.repo.ListIncidentChannels(ctx, incident.UserID)
+		if err != nil {
+			s.logger.Warn("Failed to list incident channels", "userId", incident.UserID, "error", err)
+		}
+		routes = append(append([]*notification.Channel(nil), routes...), channels...)
+	}
+
+	for _, route := range routes {
+		if err := s.page(ctx, route, incident); err != nil {
+			s.logger.Error("Failed to page incident",
+				"channel", route.Type,
+				"dedupKey", incident.DedupKey,
+				"resolved", incident.Resolved,
+				"error", err,
+			)
+			continue
+		}
+		s.logger.Info("Incident paged",
+			"channel", route.Type,
+			"dedupKey", incident.DedupKey,
+			"resolved", incident.Resolved,
+		)
+	}
+}
+
+// page pages an incident through one incident channel
+func (s *NotificationService) page(ctx context.Context, channel *notification.Channel, incident *notification.Incident) error {
+	pager, ok := s.incidents.Pagers[channel.Type]
+	if !ok {
+		return fmt.Errorf("no pager for channel type %s", channel.Type)
+	}
+	return resilience.Retry(ctx, pageRetry, func() error {
+		return pager.Page(ctx, channel.Config, incident)
+	})
+}
+
+// trackFailure counts an execution of a workflow failing, and opens an
+// incident once the executions failing in a row reach the threshold
+func (s *NotificationService) trackFailure(ctx context.Context, workflowID, userID, executionID, message string) {
+	if workflowID == "" || s.incidents.FailureThreshold <= 0 {
+		return
+	}
+
+	key := failuresKey + workflowID
+	failures, err := s.redis.Incr(ctx, key).Result()
+	if err != nil {
+		s.logger.Warn("Failed to count execution failure", "workflowId", workflowID, "error", err)
+		return
+	}
+	s.redis.Expire(ctx, key, failuresTTL)
+	if failures != int64(s.incidents.FailureThreshold) {
+		return
+	}
+
+	incident := notification.NewIncident(notification.IncidentExecutionFailures, notification.SeverityError,
+		fmt.Sprintf("Workflow %s failed %d executions in a row: %s", workflowID, failures, message),
+		workflowID)
+	incident.UserID = userID
+	incident.Details = map[string]interface{}{
+		"workflowId":      workflowID,
+		"lastExecutionId": executionID,
+		"failures":        failures,
+	}
+	s.escalate(ctx, incident)
+}
+
+// HandleExecutionCompleted ends the failures in a row of the workflow of a
+// completed execution, resolving the incident they opened
+func (s *NotificationService) HandleExecutionCompleted(ctx context.Context, event events.Event) error {
+	var payload struct {
+		WorkflowID string `json:"workflowId"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if payload.WorkflowID == "" || s.incidents.FailureThreshold <= 0 {
+		return nil
+	}
+
+	failures, err := s.redis.GetDel(ctx, failuresKey+payload.WorkflowID).Int64()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reset execution failures: %w", err)
+	}
+	if failures < int64(s.incidents.FailureThreshold) {
+		return nil
+	}
+
+	incident := notification.NewIncident(notification.IncidentExecutionFailures, notification.SeverityError, "",
+		payload.WorkflowID).
+		Resolve(fmt.Sprintf("Workflow %s completed execution %s after %d failures", payload.WorkflowID, event.AggregateID, failures))
+	incident.UserID = s.owner(ctx, event.UserID, payload.WorkflowID)
+	s.escalate(ctx, incident)
+	return nil
+}
+
+// HandleWorkerPoolState pages the on-call of the platform when the worker
+// pool of an executor is exhausted, and resolves the incident once it
+// recovers
+func (s *NotificationService) HandleWorkerPoolState(ctx context.Context, event events.Event) error {
+	var payload struct {
+		Instance      string `json:"instance"`
+		Workers       int    `json:"workers"`
+		BusyWorkers   int    `json:"busyWorkers"`
+		QueueDepth    int    `json:"queueDepth"`
+		QueueCapacity int    `json:"queueCapacity"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+
+	incident := notification.NewIncident(notification.IncidentWorkerPool, notification.SeverityCritical,
+		fmt.Sprintf("Executor %s exhausted its worker pool: %d of %d workers busy, %d of %d node requests queued",
+			payload.Instance, payload.BusyWorkers, payload.Workers, payload.QueueDepth, payload.QueueCapacity),
+		payload.Instance)
+	if event.Type == events.WorkerPoolRecovered {
+		incident.Resolve(fmt.Sprintf("Executor %s caught up with its queue, %d node requests queued",
+			payload.Instance, payload.QueueDepth))
+	}
+	incident.Details = map[string]interface{}{
+		"instance":      payload.Instance,
+		"workers":       payload.Workers,
+		"busyWorkers":   payload.BusyWorkers,
+		"queueDepth":    payload.QueueDepth,
+		"queueCapacity": payload.QueueCapacity,
+	}
+	s.escalate(ctx, incident)
+	return nil
+}
+
+// IncidentChannel returns the incident channel of a type of a user
+func (s *NotificationService) IncidentChannel(ctx context.Context, userID, channelType string) (*notification.Channel, error) {
+	if !notification.IsIncidentChannel(channelType) {
+		return nil, notification.ErrInvalidChannel.WithMessage("not an incident channel: %s", channelType)
+	}
+	return s.repo.GetChannel(ctx, userID, channelType)
+}
+
+// SetIncidentChannel updates the configuration of the incident channel of a
+// type of a user, and whether it is paged. Keys left out of config keep
+// their value, keys set empty are removed.
+func (s *NotificationService) SetIncidentChannel(ctx context.Context, userID, channelType string, config map[string]string, active bool) (*notification.Channel, error) {
+	channel, err := s.IncidentChannel(ctx, userID, channelType)
+	if errors.Is(err, notification.ErrChannelNotFound) {
+		channel = notification.NewChannel(userID, channelType, channelType)
+	} else if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]string, len(channel.Config)+len(config))
+	for k, v := range channel.Config {
+		merged[k] = v
+	}
+	for k, v := range config {
+		if v == "" {
+			delete(merged, k)
+		} else {
+			merged[k] = v
+		}
+	}
+	channel.Config = merged
+	channel.IsActive = active
+	channel.UpdatedAt = time.Now()
+	if err := channel.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveChannel(ctx, channel); err != nil {
+		return nil, fmt.Errorf("failed to save incident channel: %w", err)
+	}
+
+	s.logger.Info("Incident channel updated", "userId", userID, "channel", channelType, "active", active)
+	return channel, nil
+}
+
+// TestIncidentChannel pages a test incident through the incident channel
+// of a type of a user, and resolves it at once
+func (s *NotificationService) TestIncidentChannel(ctx context.Context, userID, channelType string) error {
+	channel, err := s.IncidentChannel(ctx, userID, channelType)
+	if err != nil {
+		return err
+	}
+
+	incident := notification.NewIncident(notification.IncidentTest, notification.SeverityWarning,
+		"LinkFlow test incident, resolved at once", userID)
+	if err := s.page(ctx, channel, incident); err != nil {
+		return notification.ErrInvalidChannelConfig.WithMessage("%s rejected the test incident: %v", channelType, err)
+	}
+	return s.page(ctx, channel, incident.Resolve("LinkFlow test incident resolved"))
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+)
+
+// GetPreferences returns the notification preferences of a user, the
+// defaults when they never set them
+func (s *NotificationService) GetPreferences(ctx context.Context, userID string) (*notification.Preferences, error) {
+	preferences, err := s.repo.GetPreferences(ctx, userID)
+	if errors.Is(err, notification.ErrPreferencesNotFound) {
+		return notification.NewPreferences(userID), nil
+	}
+	return preferences, err
+}
+
+// SetPreferences replaces the notification preferences of a user
+func (s *NotificationService) SetPreferences(ctx context.Context, userID string, req notification.SetPreferencesRequest) (*notification.Preferences, error) {
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := preferences.Apply(req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SavePreferences(ctx, preferences); err != nil {
+		return nil, fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+
+	s.logger.Info("Notification preferences updated", "userId", userID, "delivery", preferences.Delivery)
+	return preferences, nil
+}
+
+// SetSubscribed subscribes a user to a notification type, or unsubscribes
+// them
+func (s *NotificationService) SetSubscribed(ctx context.Context, userID, notifType string, subscribed bool) (*notification.Preferences, error) {
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := preferences.SetSubscribed(notifType, subscribed); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SavePreferences(ctx, preferences); err != nil {
+		return nil, fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return preferences, nil
+}
+
+// deliver stores a notification as the preferences of its user ask: not at
+// all when they are unsubscribed from its type, through the channels they
+// enabled, held until the quiet hours end or for the digest. Every alert
+// is delivered through it.
+func (s *NotificationService) deliver(ctx context.Context, n *notification.Notification) error {
+	preferences, err := s.GetPreferences(ctx, n.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	if !preferences.Wants(n.Type) {
+		s.logger.Debug("Notification type unsubscribed", "userId", n.UserID, "type", n.Type)
+		return nil
+	}
+
+	preferences.Schedule(n, time.Now())
+	return s.AddNotification(ctx, n)
+}
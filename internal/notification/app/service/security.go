@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// HandleIntegrityViolation alerts the user who verified a workflow that its
+// stored versions or executions do not match their checksums, a sign they
+// were tampered with
+func (s *NotificationService) HandleIntegrityViolation(ctx context.Context, event events.Event) error {
+	var payload struct {
+		WorkflowID string        `json:"workflow_id"`
+		UserID     string        `json:"user_id"`
+		Issues     []interface{} `json:"issues"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+	userID := s.owner(ctx, payload.UserID, payload.WorkflowID)
+	if userID == "" {
+		return nil
+	}
+
+	n := notification.NewNotification(userID, notification.TypeSecurityAlert,
+		"Workflow integrity check failed",
+		fmt.Sprintf("Workflow %s has %d versions or executions not matching their checksums, they may have been tampered with.",
+			payload.WorkflowID, len(payload.Issues)))
+	n.Priority = notification.PriorityUrgent
+	n.Data = map[string]interface{}{
+		"workflowId": payload.WorkflowID,
+		"issues":     len(payload.Issues),
+	}
+	if err := s.deliver(ctx, n); err != nil {
+		return fmt.Errorf("failed to create security alert: %w", err)
+	}
+
+	s.logger.Warn("Security alert created", "workflowId", payload.WorkflowID, "issues", len(payload.Issues))
+	return nil
+}
@@ -24,6 +24,7 @@ type NotificationService struct {
 	pushChannel    Channel
 	teamsChannel   Channel
 	discordChannel Channel
+	incidents      Incidents
 }
 
 func NewNotificationService(
@@ -32,6 +33,7 @@ func NewNotificationService(
 	redis *redis.Client,
 	logger logger.Logger,
 	emailChannel, smsChannel, slackChannel, pushChannel, teamsChannel, discordChannel Channel,
+	incidents Incidents,
 ) *NotificationService {
 	return &NotificationService{
 		repo:           repo,
@@ -44,6 +46,7 @@ func NewNotificationService(
 		pushChannel:    pushChannel,
 		teamsChannel:   teamsChannel,
 		discordChannel: discordChannel,
+		incidents:      incidents,
 	}
 }
 
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// HandleSLAState alerts the owner of a workflow, and whoever set its SLA,
+// that an objective of the SLA was breached or is met again, and pages the
+// breach as an incident resolved once the objective is met again
+func (s *NotificationService) HandleSLAState(ctx context.Context, event events.Event) error {
+	var payload struct {
+		SLAID      string  `json:"slaId"`
+		WorkflowID string  `json:"workflowId"`
+		UserID     string  `json:"userId"`
+		UpdatedBy  string  `json:"updatedBy"`
+		Objective  string  `json:"objective"`
+		Target     float64 `json:"target"`
+		Actual     float64 `json:"actual"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+
+	breached := event.Type == events.SLABreached
+	var measure string
+	switch payload.Objective {
+	case workflow.SLAObjectiveDuration:
+		measure = fmt.Sprintf("an execution ran %.0f ms, the SLA allows %.0f ms", payload.Actual, payload.Target)
+	case workflow.SLAObjectiveFailureRate:
+		measure = fmt.Sprintf("%.1f%% of its executions failed, the SLA allows %.1f%%", payload.Actual, payload.Target)
+	case workflow.SLAObjectiveSchedule:
+		measure = fmt.Sprintf("no execution started for %.0f s, the SLA expects one every %.0f s", payload.Actual, payload.Target)
+	default:
+		measure = fmt.Sprintf("measured %v against a target of %v", payload.Actual, payload.Target)
+	}
+
+	subject := fmt.Sprintf("SLA %s objective breached", payload.Objective)
+	body := fmt.Sprintf("Workflow %s breached its SLA: %s.", payload.WorkflowID, measure)
+	priority := notification.PriorityHigh
+	if !breached {
+		subject = fmt.Sprintf("SLA %s objective met again", payload.Objective)
+		body = fmt.Sprintf("Workflow %s meets the %s objective of its SLA again.", payload.WorkflowID, payload.Objective)
+		priority = notification.PriorityNormal
+	}
+
+	recipients := []string{payload.UserID}
+	if payload.UpdatedBy != "" && payload.UpdatedBy != payload.UserID {
+		recipients = append(recipients, payload.UpdatedBy)
+	}
+	for _, userID := range recipients {
+		if userID == "" {
+			continue
+		}
+		n := notification.NewNotification(userID, notification.TypeSLAAlert, subject, body)
+		n.Priority = priority
+		n.Data = map[string]interface{}{
+			"slaId":      payload.SLAID,
+			"workflowId": payload.WorkflowID,
+			"objective":  payload.Objective,
+			"target":     payload.Target,
+			"actual":     payload.Actual,
+			"breached":   breached,
+		}
+		if err := s.deliver(ctx, n); err != nil {
+			return fmt.Errorf("failed to create SLA alert: %w", err)
+		}
+	}
+
+	incident := notification.NewIncident(notification.IncidentSLABreach, notification.SeverityCritical,
+		fmt.Sprintf("Workflow %s breached the %s objective of its SLA: %s", payload.WorkflowID, payload.Objective, measure),
+		payload.SLAID, payload.Objective)
+	if !breached {
+		incident.Resolve(body)
+	}
+	incident.UserID = payload.UserID
+	incident.Details = map[string]interface{}{
+		"slaId":      payload.SLAID,
+		"workflowId": payload.WorkflowID,
+		"objective":  payload.Objective,
+		"target":     payload.Target,
+		"actual":     payload.Actual,
+	}
+	s.escalate(ctx, incident)
+
+	s.logger.Info("SLA alert created",
+		"slaId", payload.SLAID,
+		"objective", payload.Objective,
+		"breached", breached,
+		"recipients", len(recipients),
+	)
+	return nil
+}
@@ -1,9 +1,45 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/notification"
+	"github.com/linkflow-go/pkg/contracts/user"
+	"github.com/linkflow-go/pkg/database"
+)
 
 type NotificationRepository interface {
 	CreateNotification(ctx context.Context, notification interface{}) error
-	GetNotifications(ctx context.Context, userID string) ([]interface{}, error)
-	MarkAsRead(ctx context.Context, id string) error
+
+	// Inbox
+	ListInbox(ctx context.Context, userID string, unreadOnly bool, now time.Time, page *database.CursorPage) ([]*notification.Notification, error)
+	CountUnread(ctx context.Context, userID string, now time.Time) (int64, error)
+	GetNotification(ctx context.Context, userID, id string) (*notification.Notification, error)
+	MarkRead(ctx context.Context, userID, id string, at time.Time) error
+	MarkAllRead(ctx context.Context, userID string, at time.Time) (int64, error)
+	DeleteNotification(ctx context.Context, userID, id string) error
+	PruneNotifications(ctx context.Context, before, readBefore time.Time) (int64, error)
+
+	// Preferences
+	GetPreferences(ctx context.Context, userID string) (*notification.Preferences, error)
+	SavePreferences(ctx context.Context, preferences *notification.Preferences) error
+	MarkActivityDigestSent(ctx context.Context, userID string, at time.Time) error
+
+	// Incident channels
+	GetChannel(ctx context.Context, userID, channelType string) (*notification.Channel, error)
+	SaveChannel(ctx context.Context, channel *notification.Channel) error
+	ListIncidentChannels(ctx context.Context, userID string) ([]*notification.Channel, error)
+
+	// Digests
+	ListDigestDue(ctx context.Context, now time.Time) ([]*notification.Notification, error)
+	MarkDigested(ctx context.Context, ids []string) error
+
+	// Activity digests
+	ListWorkflowOwners(ctx context.Context) ([]string, error)
+	GetUser(ctx context.Context, userID string) (*user.User, error)
+	GetActivitySummary(ctx context.Context, userID string, from, to time.Time) (*notification.ActivitySummary, error)
+
+	// GetWorkflowOwner returns the user owning a workflow
+	GetWorkflowOwner(ctx context.Context, workflowID string) (string, error)
 }
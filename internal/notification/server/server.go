@@ -10,11 +10,19 @@ import (
 	"github.com/linkflow-go/internal/notification/adapters/channels"
 	"github.com/linkflow-go/internal/notification/adapters/db/repository"
 	"github.com/linkflow-go/internal/notification/adapters/http/handlers"
+	"github.com/linkflow-go/internal/notification/adapters/incidents"
+	"github.com/linkflow-go/internal/notification/app/activity"
+	"github.com/linkflow-go/internal/notification/app/digest"
+	"github.com/linkflow-go/internal/notification/app/retention"
 	"github.com/linkflow-go/internal/notification/app/service"
+	"github.com/linkflow-go/pkg/apidoc"
 	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/contracts/notification"
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/middleware/correlation"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 )
@@ -26,6 +34,9 @@ type Server struct {
 	db         *database.DB
 	redis      *redis.Client
 	eventBus   events.EventBus
+	digester   *digest.Digester
+	pruner     *retention.Pruner
+	activity   *activity.Generator
 }
 
 func New(cfg *config.Config, log logger.Logger) (*Server, error) {
@@ -49,7 +60,7 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	}
 
 	// Initialize event bus
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	eventBus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
@@ -78,13 +89,40 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		pushChannel,
 		teamsChannel,
 		discordChannel,
+		incidentRouting(cfg.Notification.Incidents),
 	)
 
+	// Deliver the notifications held for digests once due
+	digester := digest.NewDigester(notificationRepo, notificationService, redisClient,
+		time.Duration(cfg.Notification.DigestInterval)*time.Second, log)
+
+	// Remove the notifications past their retention
+	pruner := retention.NewPruner(notificationRepo, redisClient,
+		time.Duration(cfg.Notification.PruneInterval)*time.Second,
+		time.Duration(cfg.Notification.RetentionDays)*24*time.Hour,
+		time.Duration(cfg.Notification.ReadRetentionDays)*24*time.Hour,
+		log)
+
+	// Email the activity digests of workflow owners once due
+	activityDigests := activity.NewGenerator(notificationRepo, emailChannel, redisClient,
+		time.Duration(cfg.Notification.ActivityDigestInterval)*time.Second, log)
+
 	// Initialize handlers
 	notificationHandlers := handlers.NewNotificationHandlers(notificationService, log)
 
+	// Readiness reports the state of each dependency
+	checker := health.NewChecker("notification-service")
+	checker.Critical("database", health.Database(db))
+	checker.Critical("redis", health.Redis(redisClient))
+	checker.Optional("event_bus", health.EventBus(eventBus))
+
 	// Setup HTTP server
-	router := setupRouter(notificationHandlers, log)
+	router := setupRouter(notificationHandlers, checker, log)
+
+	// Serve the OpenAPI document of the routes registered above
+	if err := apidoc.Serve(router, "notification", cfg.Server.StrictAPISpec, log); err != nil {
+		return nil, err
+	}
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -105,24 +143,53 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		db:         db,
 		redis:      redisClient,
 		eventBus:   eventBus,
+		digester:   digester,
+		pruner:     pruner,
+		activity:   activityDigests,
 	}, nil
 }
 
-func setupRouter(h *handlers.NotificationHandlers, log logger.Logger) *gin.Engine {
+// incidentRouting pages the critical events to the PagerDuty service and
+// Opsgenie integration of the platform whose keys are set
+func incidentRouting(cfg config.IncidentConfig) service.Incidents {
+	routing := service.Incidents{
+		Pagers: map[string]service.Pager{
+			notification.ChannelTypePagerDuty: incidents.NewPagerDuty(),
+			notification.ChannelTypeOpsgenie:  incidents.NewOpsgenie(),
+		},
+		FailureThreshold: cfg.FailureThreshold,
+	}
+	if cfg.PagerDutyRoutingKey != "" {
+		route := notification.NewChannel("", notification.ChannelTypePagerDuty, "platform")
+		route.Config[notification.ConfigRoutingKey] = cfg.PagerDutyRoutingKey
+		route.Config[notification.ConfigRegion] = cfg.PagerDutyRegion
+		routing.Routes = append(routing.Routes, route)
+	}
+	if cfg.OpsgenieAPIKey != "" {
+		route := notification.NewChannel("", notification.ChannelTypeOpsgenie, "platform")
+		route.Config[notification.ConfigAPIKey] = cfg.OpsgenieAPIKey
+		route.Config[notification.ConfigRegion] = cfg.OpsgenieRegion
+		routing.Routes = append(routing.Routes, route)
+	}
+	return routing
+}
+
+func setupRouter(h *handlers.NotificationHandlers, checker *health.Checker, log logger.Logger) *gin.Engine {
 	router := gin.New()
 
 	// Middleware
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
 	router.Use(corsMiddleware())
 	router.Use(loggingMiddleware(log))
 
 	// Health checks
 	router.GET("/health/live", h.Health)
-	router.GET("/health/ready", h.Ready)
+	router.GET("/health/ready", checker.ReadyHandler())
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// API routes
-	v1 := router.Group("/api/v1/notifications")
+	v1 := router.Group(apidoc.Prefix + "/notifications")
 	{
 		// Send notifications
 		v1.POST("/send", h.SendNotification)
@@ -131,6 +198,7 @@ func setupRouter(h *handlers.NotificationHandlers, log logger.Logger) *gin.Engin
 
 		// Notification management
 		v1.GET("", h.ListNotifications)
+		v1.GET("/unread-count", h.GetUnreadCount)
 		v1.GET("/:id", h.GetNotification)
 		v1.PUT("/:id/mark-read", h.MarkAsRead)
 		v1.PUT("/mark-all-read", h.MarkAllAsRead)
@@ -174,14 +242,52 @@ func setupRouter(h *handlers.NotificationHandlers, log logger.Logger) *gin.Engin
 }
 
 func subscribeToEvents(eventBus events.EventBus, service *service.NotificationService) error {
+	// Alert on the thresholds reached by budgets
+	if err := eventBus.Subscribe(events.BudgetThreshold, service.HandleBudgetThreshold); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", events.BudgetThreshold, err)
+	}
+
+	// Alert on the objectives of SLAs breached and met again
+	for _, eventType := range []string{events.SLABreached, events.SLARecovered} {
+		if err := eventBus.Subscribe(eventType, service.HandleSLAState); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", eventType, err)
+		}
+	}
+
+	// Alert on the anomalous durations and error bursts of workflows
+	if err := eventBus.Subscribe(events.WorkflowAnomalyDetected, service.HandleWorkflowAnomaly); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", events.WorkflowAnomalyDetected, err)
+	}
+
+	// Alert on failed executions, executions paused for an approval and
+	// workflows failing their integrity checks. Completed executions
+	// resolve the incidents of executions failing in a row.
+	if err := eventBus.Subscribe(events.ExecutionFailed, service.HandleExecutionFailed); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", events.ExecutionFailed, err)
+	}
+	if err := eventBus.Subscribe(events.ExecutionCompleted, service.HandleExecutionCompleted); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", events.ExecutionCompleted, err)
+	}
+	if err := eventBus.Subscribe(events.ExecutionStateChanged, service.HandleExecutionPaused); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", events.ExecutionStateChanged, err)
+	}
+	if err := eventBus.Subscribe(events.WorkflowIntegrityViolation, service.HandleIntegrityViolation); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", events.WorkflowIntegrityViolation, err)
+	}
+
+	// Page the exhausted worker pools of executors
+	for _, eventType := range []string{events.WorkerPoolExhausted, events.WorkerPoolRecovered} {
+		if err := eventBus.Subscribe(eventType, service.HandleWorkerPoolState); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", eventType, err)
+		}
+	}
+
 	// Subscribe to workflow events
 	events := []string{
 		"workflow.executed",
 		"workflow.failed",
 		"workflow.error",
 		"execution.started",
-		"execution.completed",
-		"execution.failed",
 		"user.registered",
 		"user.password_reset",
 		"user.invitation",
@@ -202,6 +308,12 @@ func subscribeToEvents(eventBus events.EventBus, service *service.NotificationSe
 }
 
 func (s *Server) Start() error {
+	// Start delivering digests, emailing activity digests and pruning
+	// notifications
+	s.digester.Start()
+	s.activity.Start()
+	s.pruner.Start()
+
 	s.logger.Info("Starting HTTP server", "port", s.config.Server.Port)
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start HTTP server: %w", err)
@@ -217,6 +329,12 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("failed to shutdown HTTP server: %w", err)
 	}
 
+	// Stop delivering digests, emailing activity digests and pruning
+	// notifications
+	s.digester.Stop()
+	s.activity.Stop()
+	s.pruner.Stop()
+
 	// Close event bus
 	if err := s.eventBus.Close(); err != nil {
 		s.logger.Error("Failed to close event bus", "error", err)
@@ -240,7 +358,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -269,7 +387,7 @@ func loggingMiddleware(log logger.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		log.Info("HTTP Request",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP Request",
 			"method", method,
 			"path", path,
 			"status", statusCode,
@@ -304,6 +304,7 @@ func (j *scheduleJob) Run() {
 		WithAggregateType("schedule").
 		WithPayload("workflowId", j.schedule.WorkflowID).
 		WithPayload("executionId", execution.ID).
+		WithPayload("idempotencyKey", fmt.Sprintf("schedule:%s:%d", j.schedule.ID, now.Truncate(time.Minute).Unix())).
 		WithPayload("data", j.schedule.Data).
 		Build()
 
@@ -22,7 +22,7 @@ func (r *SearchRepository) SearchWorkflows(ctx context.Context, query string, us
 
 	baseQuery := r.db.WithContext(ctx).Model(&workflow.Workflow{}).
 		Where("user_id = ? AND deleted_at IS NULL", userID).
-		Where("name ILIKE ? OR description ILIKE ?", "%"+query+"%", "%"+query+"%")
+		Where(r.db.ILike("name")+" OR "+r.db.ILike("description"), "%"+query+"%", "%"+query+"%")
 
 	// Get total count
 	if err := baseQuery.Count(&total).Error; err != nil {
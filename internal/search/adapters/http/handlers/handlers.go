@@ -24,10 +24,6 @@ func (h *SearchHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
-func (h *SearchHandlers) Ready(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
-}
-
 func (h *SearchHandlers) Search(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"results": []interface{}{}})
 }
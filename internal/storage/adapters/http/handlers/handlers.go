@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/linkflow-go/internal/storage/app/service"
@@ -24,10 +29,6 @@ func (h *StorageHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
-func (h *StorageHandlers) Ready(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
-}
-
 func (h *StorageHandlers) UploadFile(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"message": "File uploaded"})
 }
@@ -52,16 +53,96 @@ func (h *StorageHandlers) UpdateFileMetadata(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Metadata updated"})
 }
 
+// PresignedURLRequest selects the object and lifetime of a presigned URL
+type PresignedURLRequest struct {
+	Bucket    string `json:"bucket" binding:"required"`
+	Key       string `json:"key" binding:"required"`
+	Operation string `json:"operation"`
+	ExpiresIn int    `json:"expiresIn"` // seconds
+	// Signed returns an HMAC signed link served by this service instead of
+	// an object store URL, for deployments validating at the gateway
+	Signed bool `json:"signed"`
+}
+
 func (h *StorageHandlers) GetPresignedURL(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"url": "https://presigned.url"})
+	h.presign(c, "")
 }
 
 func (h *StorageHandlers) GetUploadPresignedURL(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"url": "https://upload.presigned.url"})
+	h.presign(c, "upload")
 }
 
 func (h *StorageHandlers) GetDownloadPresignedURL(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"url": "https://download.presigned.url"})
+	h.presign(c, "download")
+}
+
+func (h *StorageHandlers) presign(c *gin.Context, operation string) {
+	var req PresignedURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if operation == "" {
+		operation = req.Operation
+	}
+
+	if req.Signed {
+		if operation != "download" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "signed links only support downloads"})
+			return
+		}
+		url, expiresAt, err := h.service.SignDownloadURL(req.Bucket, req.Key)
+		if err != nil {
+			h.logger.Error("Failed to sign download URL", "error", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to sign download URL"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"url": url, "expiresAt": expiresAt})
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresIn) * time.Second
+	url, expiresAt, err := h.service.GetPresignedURL(c.Request.Context(), req.Bucket, req.Key, operation, ttl)
+	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedOperation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to presign URL", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to presign URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"url": url, "expiresAt": expiresAt})
+}
+
+// DownloadSigned streams an object to a client holding a signed URL
+func (h *StorageHandlers) DownloadSigned(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	object, err := h.service.OpenObject(c.Request.Context(), bucket, key)
+	if err != nil {
+		h.logger.Error("Failed to open object", "bucket", bucket, "key", key, "error", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	defer object.Body.Close()
+
+	contentType := object.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	c.Header("Content-Type", contentType)
+	if object.ContentLength > 0 {
+		c.Header("Content-Length", strconv.FormatInt(object.ContentLength, 10))
+	}
+	c.Status(http.StatusOK)
+
+	if _, err := io.Copy(c.Writer, object.Body); err != nil {
+		h.logger.Warn("Signed download interrupted", "bucket", bucket, "key", key, "error", err)
+	}
 }
 
 func (h *StorageHandlers) ListFolders(c *gin.Context) {
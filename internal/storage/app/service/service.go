@@ -2,20 +2,41 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/linkflow-go/internal/storage/ports"
+	"github.com/linkflow-go/pkg/auth/signedurl"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
 	"github.com/redis/go-redis/v9"
 )
 
+// ErrUnsupportedOperation is returned for presign operations other than
+// upload and download
+var ErrUnsupportedOperation = apperrors.New(apperrors.CategoryValidation, "UNSUPPORTED_PRESIGN_OPERATION", "unsupported presign operation")
+
 type StorageService struct {
 	repo     ports.StorageRepository
 	s3Client *s3.S3
 	eventBus events.EventBus
 	redis    *redis.Client
 	logger   logger.Logger
+	signer   *signedurl.Signer
+}
+
+// Object is a stored object opened for download
+type Object struct {
+	Body          io.ReadCloser
+	ContentType   string
+	ContentLength int64
 }
 
 func NewStorageService(
@@ -40,7 +61,77 @@ func (s *StorageService) UploadFile(ctx context.Context, bucket, key string, dat
 	return nil
 }
 
-func (s *StorageService) GetPresignedURL(ctx context.Context, bucket, key string, operation string) (string, error) {
-	// Generate presigned URL
-	return "https://presigned.url", nil
+// SetURLSigner enables HMAC signed download links served by this service
+func (s *StorageService) SetURLSigner(signer *signedurl.Signer) {
+	s.signer = signer
+}
+
+// GetPresignedURL returns an object store URL validated by S3 itself, so the
+// bytes never pass through LinkFlow services
+func (s *StorageService) GetPresignedURL(ctx context.Context, bucket, key string, operation string, ttl time.Duration) (string, time.Time, error) {
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+
+	var presign func(time.Duration) (string, error)
+	switch operation {
+	case "download", "get":
+		req, _ := s.s3Client.GetObjectRequest(&s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		presign = req.Presign
+	case "upload", "put":
+		req, _ := s.s3Client.PutObjectRequest(&s3.PutObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		presign = req.Presign
+	default:
+		return "", time.Time{}, fmt.Errorf("%w: %s", ErrUnsupportedOperation, operation)
+	}
+
+	signed, err := presign(ttl)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to presign %s url: %w", operation, err)
+	}
+
+	return signed, time.Now().Add(ttl), nil
+}
+
+// SignDownloadURL returns a time-limited link to the signed download route
+// for gateways that validate the HMAC instead of the object store
+func (s *StorageService) SignDownloadURL(bucket, key string) (string, time.Time, error) {
+	if s.signer == nil {
+		return "", time.Time{}, errors.New("signed urls are not configured")
+	}
+
+	path := fmt.Sprintf("/api/v1/storage/signed/%s/%s", url.PathEscape(bucket), escapeKey(key))
+	return s.signer.SignFor(path)
+}
+
+// OpenObject opens an object for streaming to a signed URL download
+func (s *StorageService) OpenObject(ctx context.Context, bucket, key string) (*Object, error) {
+	out, err := s.s3Client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open object: %w", err)
+	}
+
+	return &Object{
+		Body:          out.Body,
+		ContentType:   aws.StringValue(out.ContentType),
+		ContentLength: aws.Int64Value(out.ContentLength),
+	}, nil
+}
+
+// escapeKey escapes each segment of an object key while keeping the slashes
+func escapeKey(key string) string {
+	segments := strings.Split(strings.TrimPrefix(key, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
 }
@@ -13,10 +13,15 @@ import (
 	"github.com/linkflow-go/internal/storage/adapters/db/repository"
 	"github.com/linkflow-go/internal/storage/adapters/http/handlers"
 	"github.com/linkflow-go/internal/storage/app/service"
+	"github.com/linkflow-go/pkg/apidoc"
+	"github.com/linkflow-go/pkg/auth/signedurl"
 	"github.com/linkflow-go/pkg/config"
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	authmw "github.com/linkflow-go/pkg/middleware/auth"
+	"github.com/linkflow-go/pkg/middleware/correlation"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 )
@@ -52,15 +57,15 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	}
 
 	// Initialize event bus
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	eventBus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
 
 	// Initialize S3 client (or MinIO)
 	sess, err := session.NewSession(&aws.Config{
-		Region:   aws.String("us-east-1"),
-		Endpoint: aws.String("http://localhost:9000"), // MinIO endpoint for local dev
+		Region:   aws.String(cfg.Storage.Region),
+		Endpoint: aws.String(cfg.Storage.Endpoint),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create S3 session: %w", err)
@@ -73,11 +78,34 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	// Initialize service
 	storageService := service.NewStorageService(storageRepo, s3Client, eventBus, redisClient, log)
 
+	// Signed URLs let artifacts be downloaded without authenticated API calls
+	signer, err := signedurl.NewSigner(cfg.Auth.SignedURL.SecretKey, cfg.Auth.SignedURL.BaseURL,
+		time.Duration(cfg.Auth.SignedURL.ExpiryMinutes)*time.Minute)
+	if err != nil {
+		log.Warn("Signed download URLs disabled", "error", err)
+	}
+	storageService.SetURLSigner(signer)
+
 	// Initialize handlers
 	storageHandlers := handlers.NewStorageHandlers(storageService, log)
 
+	// Readiness reports the state of each dependency
+	checker := health.NewChecker("storage-service")
+	checker.Critical("database", health.Database(db))
+	checker.Critical("redis", health.Redis(redisClient))
+	checker.Optional("event_bus", health.EventBus(eventBus))
+	checker.Optional("object_storage", func(ctx context.Context) error {
+		_, err := s3Client.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
+		return err
+	})
+
 	// Setup HTTP server
-	router := setupRouter(storageHandlers, log)
+	router := setupRouter(storageHandlers, signer, checker, log)
+
+	// Serve the OpenAPI document of the routes registered above
+	if err := apidoc.Serve(router, "storage", cfg.Server.StrictAPISpec, log); err != nil {
+		return nil, err
+	}
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -97,21 +125,29 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	}, nil
 }
 
-func setupRouter(h *handlers.StorageHandlers, log logger.Logger) *gin.Engine {
+func setupRouter(h *handlers.StorageHandlers, signer *signedurl.Signer, checker *health.Checker, log logger.Logger) *gin.Engine {
 	router := gin.New()
 
 	// Middleware
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
 	router.Use(corsMiddleware())
 	router.Use(loggingMiddleware(log))
 
 	// Health checks
 	router.GET("/health/live", h.Health)
-	router.GET("/health/ready", h.Ready)
+	router.GET("/health/ready", checker.ReadyHandler())
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	// Signed downloads authenticate through the URL signature
+	signed := router.Group(apidoc.Prefix + "/storage/signed")
+	signed.Use(authmw.SignedURLMiddleware(signer))
+	{
+		signed.GET("/:bucket/*key", h.DownloadSigned)
+	}
+
 	// API routes
-	v1 := router.Group("/api/v1/storage")
+	v1 := router.Group(apidoc.Prefix + "/storage")
 	{
 		// File operations
 		v1.POST("/upload", h.UploadFile)
@@ -196,7 +232,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -225,7 +261,7 @@ func loggingMiddleware(log logger.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		log.Info("HTTP Request",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP Request",
 			"method", method,
 			"path", path,
 			"status", statusCode,
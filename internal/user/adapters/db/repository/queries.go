@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/linkflow-go/pkg/contracts/user"
+	"github.com/linkflow-go/pkg/tenant"
 	"gorm.io/gorm"
 )
 
@@ -145,19 +146,19 @@ func (r *UserRepository) SearchByMultipleFields(ctx context.Context, opts Search
 
 	// Apply search filters
 	if opts.Email != "" {
-		query = query.Where("email ILIKE ?", "%"+opts.Email+"%")
+		query = query.Where(r.db.ILike("email"), "%"+opts.Email+"%")
 	}
 
 	if opts.Username != "" {
-		query = query.Where("username ILIKE ?", "%"+opts.Username+"%")
+		query = query.Where(r.db.ILike("username"), "%"+opts.Username+"%")
 	}
 
 	if opts.FirstName != "" {
-		query = query.Where("first_name ILIKE ?", "%"+opts.FirstName+"%")
+		query = query.Where(r.db.ILike("first_name"), "%"+opts.FirstName+"%")
 	}
 
 	if opts.LastName != "" {
-		query = query.Where("last_name ILIKE ?", "%"+opts.LastName+"%")
+		query = query.Where(r.db.ILike("last_name"), "%"+opts.LastName+"%")
 	}
 
 	if opts.Status != "" {
@@ -327,10 +328,13 @@ func (r *UserRepository) GetTeamMembers(ctx context.Context, userID string) ([]*
 		)
 		AND u.id != ?
 		AND u.status = ?
+		AND (? = '' OR u.tenant_id = ?)
 	`
 
+	// Raw SQL escapes the tenant plugin, scope it explicitly
+	tenantID := tenant.FromContext(ctx)
 	err := r.db.WithContext(ctx).
-		Raw(query, userID, userID, user.StatusActive).
+		Raw(query, userID, userID, user.StatusActive, tenantID, tenantID).
 		Scan(&users).Error
 
 	return users, err
@@ -26,14 +26,6 @@ func (h *UserHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy", "service": "user-service"})
 }
 
-func (h *UserHandlers) Ready(c *gin.Context) {
-	if err := h.service.CheckReady(); err != nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, gin.H{"status": "ready", "service": "user-service"})
-}
-
 // ========== User Handlers ==========
 
 func (h *UserHandlers) ListUsers(c *gin.Context) {
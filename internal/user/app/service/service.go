@@ -11,6 +11,7 @@ import (
 	"github.com/linkflow-go/pkg/contracts/user"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/rediskey"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -163,7 +164,7 @@ func (s *UserService) CreateTeam(ctx context.Context, req CreateTeamRequest) (*T
 	}
 
 	// For now, store in Redis as JSON (could be a separate table)
-	key := fmt.Sprintf("team:%s", team.ID)
+	key := rediskey.Tenant(ctx, "team", team.ID)
 	if err := s.redis.HSet(ctx, key,
 		"id", team.ID,
 		"name", team.Name,
@@ -178,14 +179,14 @@ func (s *UserService) CreateTeam(ctx context.Context, req CreateTeamRequest) (*T
 	s.redis.SAdd(ctx, "teams:index", team.ID)
 
 	// Add owner as member
-	s.redis.SAdd(ctx, fmt.Sprintf("team:%s:members", team.ID), team.OwnerID)
+	s.redis.SAdd(ctx, rediskey.Tenant(ctx, "team", team.ID, "members"), team.OwnerID)
 
 	return team, nil
 }
 
 // GetTeam retrieves a team by ID
 func (s *UserService) GetTeam(ctx context.Context, id string) (*Team, error) {
-	key := fmt.Sprintf("team:%s", id)
+	key := rediskey.Tenant(ctx, "team", id)
 	data, err := s.redis.HGetAll(ctx, key).Result()
 	if err != nil || len(data) == 0 {
 		return nil, errors.New("team not found")
@@ -234,7 +235,7 @@ func (s *UserService) UpdateTeam(ctx context.Context, id string, req UpdateTeamR
 	}
 	team.UpdatedAt = time.Now()
 
-	key := fmt.Sprintf("team:%s", id)
+	key := rediskey.Tenant(ctx, "team", id)
 	s.redis.HSet(ctx, key, "name", team.Name, "description", team.Description)
 
 	return team, nil
@@ -242,9 +243,9 @@ func (s *UserService) UpdateTeam(ctx context.Context, id string, req UpdateTeamR
 
 // DeleteTeam deletes a team
 func (s *UserService) DeleteTeam(ctx context.Context, id string) error {
-	key := fmt.Sprintf("team:%s", id)
+	key := rediskey.Tenant(ctx, "team", id)
 	s.redis.Del(ctx, key)
-	s.redis.Del(ctx, fmt.Sprintf("team:%s:members", id))
+	s.redis.Del(ctx, rediskey.Tenant(ctx, "team", id, "members"))
 	s.redis.SRem(ctx, "teams:index", id)
 	return nil
 }
@@ -260,19 +261,19 @@ func (s *UserService) AddTeamMember(ctx context.Context, teamID, userID string)
 		return errors.New("user not found")
 	}
 
-	s.redis.SAdd(ctx, fmt.Sprintf("team:%s:members", teamID), userID)
+	s.redis.SAdd(ctx, rediskey.Tenant(ctx, "team", teamID, "members"), userID)
 	return nil
 }
 
 // RemoveTeamMember removes a user from a team
 func (s *UserService) RemoveTeamMember(ctx context.Context, teamID, userID string) error {
-	s.redis.SRem(ctx, fmt.Sprintf("team:%s:members", teamID), userID)
+	s.redis.SRem(ctx, rediskey.Tenant(ctx, "team", teamID, "members"), userID)
 	return nil
 }
 
 // GetTeamMembers gets all members of a team
 func (s *UserService) GetTeamMembers(ctx context.Context, teamID string) ([]*user.User, error) {
-	memberIDs, err := s.redis.SMembers(ctx, fmt.Sprintf("team:%s:members", teamID)).Result()
+	memberIDs, err := s.redis.SMembers(ctx, rediskey.Tenant(ctx, "team", teamID, "members")).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -341,10 +342,6 @@ func (s *UserService) HandleWorkflowCreated(ctx context.Context, event events.Ev
 	return nil
 }
 
-func (s *UserService) CheckReady() error {
-	return nil
-}
-
 // ========== Request/Response Types ==========
 
 type ListUsersRequest struct {
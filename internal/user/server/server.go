@@ -10,10 +10,15 @@ import (
 	"github.com/linkflow-go/internal/user/adapters/db/repository"
 	"github.com/linkflow-go/internal/user/adapters/http/handlers"
 	"github.com/linkflow-go/internal/user/app/service"
+	"github.com/linkflow-go/pkg/apidoc"
 	"github.com/linkflow-go/pkg/config"
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/middleware/correlation"
+	tenantmw "github.com/linkflow-go/pkg/middleware/tenant"
+	"github.com/linkflow-go/pkg/rediskey"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 )
@@ -24,6 +29,7 @@ type Server struct {
 	httpServer *http.Server
 	db         *database.DB
 	redis      *redis.Client
+	redisQuota *rediskey.Quota
 	eventBus   events.EventBus
 }
 
@@ -47,8 +53,14 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
+	// Bound the Redis commands and memory of each tenant
+	quota := cfg.Redis.TenantQuota
+	redisQuota := rediskey.NewQuota(redisClient, quota.OpsPerSecond, quota.MaxMemoryMB,
+		time.Duration(quota.UsageInterval)*time.Second, log)
+	redisClient.AddHook(redisQuota)
+
 	// Initialize event bus
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	eventBus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
@@ -62,8 +74,19 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	// Initialize handlers
 	userHandlers := handlers.NewUserHandlers(userService, log)
 
+	// Readiness reports the state of each dependency
+	checker := health.NewChecker("user-service")
+	checker.Critical("database", health.Database(db))
+	checker.Critical("redis", health.Redis(redisClient))
+	checker.Optional("event_bus", health.EventBus(eventBus))
+
 	// Setup HTTP server
-	router := setupRouter(userHandlers, log)
+	router := setupRouter(userHandlers, checker, log)
+
+	// Serve the OpenAPI document of the routes registered above
+	if err := apidoc.Serve(router, "user", cfg.Server.StrictAPISpec, log); err != nil {
+		return nil, err
+	}
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -83,29 +106,31 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		httpServer: httpServer,
 		db:         db,
 		redis:      redisClient,
+		redisQuota: redisQuota,
 		eventBus:   eventBus,
 	}, nil
 }
 
-func setupRouter(h *handlers.UserHandlers, log logger.Logger) *gin.Engine {
+func setupRouter(h *handlers.UserHandlers, checker *health.Checker, log logger.Logger) *gin.Engine {
 	router := gin.New()
 
 	// Middleware
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
 	router.Use(corsMiddleware())
 	router.Use(loggingMiddleware(log))
 
 	// Health checks
 	router.GET("/health/live", h.Health)
-	router.GET("/health/ready", h.Ready)
+	router.GET("/health/ready", checker.ReadyHandler())
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
-	// OpenAPI/Swagger documentation
+	// Swagger UI of the OpenAPI document served at apidoc.SpecPath
 	router.GET("/api/docs", serveSwaggerUI())
-	router.StaticFile("/api/openapi.yaml", "api/openapi/user.yaml")
 
 	// API routes
-	v1 := router.Group("/api/v1/users")
+	v1 := router.Group(apidoc.Prefix + "/users")
+	v1.Use(tenantmw.Middleware())
 	{
 		v1.GET("", h.ListUsers)
 		v1.GET("/:id", h.GetUser)
@@ -158,6 +183,9 @@ func subscribeToEvents(eventBus events.EventBus, service *service.UserService) e
 }
 
 func (s *Server) Start() error {
+	// Measure the Redis memory of the tenants
+	s.redisQuota.Start()
+
 	s.logger.Info("Starting HTTP server", "port", s.config.Server.Port)
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start HTTP server: %w", err)
@@ -173,6 +201,8 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("failed to shutdown HTTP server: %w", err)
 	}
 
+	s.redisQuota.Stop()
+
 	// Close event bus
 	if err := s.eventBus.Close(); err != nil {
 		s.logger.Error("Failed to close event bus", "error", err)
@@ -196,7 +226,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -225,7 +255,7 @@ func loggingMiddleware(log logger.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		log.Info("HTTP Request",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP Request",
 			"method", method,
 			"path", path,
 			"status", statusCode,
@@ -253,7 +283,7 @@ func serveSwaggerUI() gin.HandlerFunc {
     <script>
         window.onload = function() {
             SwaggerUIBundle({
-                url: "/api/openapi.yaml",
+                url: "/api/v1/openapi.json",
                 dom_id: '#swagger-ui',
                 presets: [SwaggerUIBundle.presets.apis, SwaggerUIBundle.SwaggerUIStandalonePreset],
                 layout: "BaseLayout",
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 
+	"gorm.io/gorm"
+
 	variable "github.com/linkflow-go/internal/variable/domain"
 	"github.com/linkflow-go/pkg/database"
 )
@@ -30,16 +32,16 @@ func (r *VariableRepository) GetByID(ctx context.Context, id string) (*variable.
 
 func (r *VariableRepository) GetByKey(ctx context.Context, key string) (*variable.Variable, error) {
 	var v variable.Variable
-	err := r.db.WithContext(ctx).Where("key = ?", key).First(&v).Error
+	err := inTeam(r.db.WithContext(ctx), "").Where("key = ?", key).First(&v).Error
 	if err != nil {
 		return nil, variable.ErrVariableNotFound
 	}
 	return &v, nil
 }
 
-func (r *VariableRepository) List(ctx context.Context) ([]*variable.Variable, error) {
+func (r *VariableRepository) List(ctx context.Context, teamID string) ([]*variable.Variable, error) {
 	var variables []*variable.Variable
-	err := r.db.WithContext(ctx).Order("key ASC").Find(&variables).Error
+	err := inTeam(r.db.WithContext(ctx), teamID).Order("key ASC").Find(&variables).Error
 	return variables, err
 }
 
@@ -51,14 +53,23 @@ func (r *VariableRepository) Delete(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&variable.Variable{}).Error
 }
 
-func (r *VariableRepository) Exists(ctx context.Context, key string) (bool, error) {
+func (r *VariableRepository) Exists(ctx context.Context, teamID, key string) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&variable.Variable{}).Where("key = ?", key).Count(&count).Error
+	err := inTeam(r.db.WithContext(ctx).Model(&variable.Variable{}), teamID).Where("key = ?", key).Count(&count).Error
 	return count > 0, err
 }
 
+// inTeam selects the variables of a team, the global ones when teamID is
+// empty
+func inTeam(query *gorm.DB, teamID string) *gorm.DB {
+	if teamID == "" {
+		return query.Where("team_id IS NULL")
+	}
+	return query.Where("team_id = ?", teamID)
+}
+
 func (r *VariableRepository) GetAllAsMap(ctx context.Context) (map[string]string, error) {
-	variables, err := r.List(ctx)
+	variables, err := r.List(ctx, "")
 	if err != nil {
 		return nil, err
 	}
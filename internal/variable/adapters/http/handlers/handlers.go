@@ -25,12 +25,12 @@ func (h *VariableHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
-func (h *VariableHandlers) Ready(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
-}
-
 func (h *VariableHandlers) List(c *gin.Context) {
-	variables, err := h.service.List(c.Request.Context())
+	variables, err := h.service.List(c.Request.Context(), c.Query("teamId"))
+	if err == variable.ErrInvalidTeam {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to list variables", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list variables"})
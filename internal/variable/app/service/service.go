@@ -46,13 +46,16 @@ func (s *VariableService) Create(ctx context.Context, req CreateRequest) (*varia
 	if err := variable.ValidateKey(req.Key); err != nil {
 		return nil, err
 	}
+	if err := variable.ValidateTeam(req.TeamID); err != nil {
+		return nil, err
+	}
 
-	exists, _ := s.repo.Exists(ctx, req.Key)
+	exists, _ := s.repo.Exists(ctx, req.TeamID, req.Key)
 	if exists {
 		return nil, variable.ErrVariableExists
 	}
 
-	v := variable.NewVariable(req.Key, req.Value, req.Type)
+	v := variable.NewVariable(req.Key, req.Value, req.Type, req.TeamID)
 	v.Description = req.Description
 
 	if err := v.Validate(); err != nil {
@@ -91,8 +94,12 @@ func (s *VariableService) GetByKey(ctx context.Context, key string) (*variable.V
 	return s.repo.GetByKey(ctx, key)
 }
 
-func (s *VariableService) List(ctx context.Context) ([]*variable.Variable, error) {
-	return s.repo.List(ctx)
+// List lists the variables of a team, the global ones when teamID is empty
+func (s *VariableService) List(ctx context.Context, teamID string) ([]*variable.Variable, error) {
+	if err := variable.ValidateTeam(teamID); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, teamID)
 }
 
 func (s *VariableService) Update(ctx context.Context, id string, req UpdateRequest) (*variable.Variable, error) {
@@ -105,7 +112,7 @@ func (s *VariableService) Update(ctx context.Context, id string, req UpdateReque
 		if err := variable.ValidateKey(req.Key); err != nil {
 			return nil, err
 		}
-		exists, _ := s.repo.Exists(ctx, req.Key)
+		exists, _ := s.repo.Exists(ctx, v.Team(), req.Key)
 		if exists {
 			return nil, variable.ErrVariableExists
 		}
@@ -191,7 +198,7 @@ func (s *VariableService) GetAllForExecution(ctx context.Context) (map[string]st
 		return cached, nil
 	}
 
-	variables, err := s.repo.List(ctx)
+	variables, err := s.repo.List(ctx, "")
 	if err != nil {
 		return nil, err
 	}
@@ -277,6 +284,9 @@ type CreateRequest struct {
 	Value       string `json:"value" binding:"required"`
 	Type        string `json:"type"`
 	Description string `json:"description"`
+	// TeamID makes a team variable, which overrides the global variable of
+	// the same key in the workflows of the team
+	TeamID string `json:"teamId"`
 }
 
 type UpdateRequest struct {
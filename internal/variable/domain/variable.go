@@ -1,11 +1,11 @@
 package variable
 
 import (
-	"errors"
 	"regexp"
 	"time"
 
 	"github.com/google/uuid"
+	apperrors "github.com/linkflow-go/pkg/errors"
 )
 
 // Variable types
@@ -16,22 +16,33 @@ const (
 	TypeSecret  = "secret"
 )
 
+// Variable scopes
+const (
+	ScopeGlobal = "global"
+	ScopeTeam   = "team"
+)
+
 var (
-	ErrVariableNotFound    = errors.New("variable not found")
-	ErrInvalidVariableName = errors.New("invalid variable name")
-	ErrVariableExists      = errors.New("variable already exists")
-	ErrInvalidVariableType = errors.New("invalid variable type")
+	ErrVariableNotFound    = apperrors.New(apperrors.CategoryNotFound, "VARIABLE_NOT_FOUND", "variable not found")
+	ErrInvalidVariableName = apperrors.New(apperrors.CategoryValidation, "INVALID_VARIABLE_NAME", "invalid variable name")
+	ErrVariableExists      = apperrors.New(apperrors.CategoryConflict, "VARIABLE_EXISTS", "variable already exists")
+	ErrInvalidVariableType = apperrors.New(apperrors.CategoryValidation, "INVALID_VARIABLE_TYPE", "invalid variable type")
+	ErrInvalidTeam         = apperrors.New(apperrors.CategoryValidation, "INVALID_TEAM", "team ID must be a UUID")
 )
 
-// Variable represents a global variable available to all workflows
+// Variable represents a variable available to all workflows, or to the
+// workflows of a team, which override the global variables of the same key
 type Variable struct {
 	ID          string    `json:"id" gorm:"primaryKey"`
-	Key         string    `json:"key" gorm:"uniqueIndex;not null"`
+	Key         string    `json:"key" gorm:"index;not null"`
 	Value       string    `json:"value" gorm:"not null"` // Stored encrypted for secrets
 	Type        string    `json:"type" gorm:"not null;default:'string'"`
 	Description string    `json:"description"`
 	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at"`
 	UpdatedAt   time.Time `json:"updatedAt" gorm:"column:updated_at"`
+	// TeamID is the team of a team variable, nil for a global one
+	TeamID *string `json:"teamId,omitempty" gorm:"index"`
+	Scope  string  `json:"scope" gorm:"not null;default:'global'"`
 }
 
 // TableName specifies the table name for GORM
@@ -39,19 +50,33 @@ func (Variable) TableName() string {
 	return "variable.variables"
 }
 
-// NewVariable creates a new variable
-func NewVariable(key, value, varType string) *Variable {
+// NewVariable creates a new variable, global when teamID is empty
+func NewVariable(key, value, varType, teamID string) *Variable {
 	if varType == "" {
 		varType = TypeString
 	}
-	return &Variable{
+	v := &Variable{
 		ID:        uuid.New().String(),
 		Key:       key,
 		Value:     value,
 		Type:      varType,
+		Scope:     ScopeGlobal,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+	if teamID != "" {
+		v.TeamID = &teamID
+		v.Scope = ScopeTeam
+	}
+	return v
+}
+
+// Team returns the team of a team variable, empty for a global one
+func (v *Variable) Team() string {
+	if v.TeamID == nil {
+		return ""
+	}
+	return *v.TeamID
 }
 
 // Validate validates the variable
@@ -70,7 +95,7 @@ func (v *Variable) Validate() error {
 		return ErrInvalidVariableType
 	}
 
-	return nil
+	return ValidateTeam(v.Team())
 }
 
 // IsSecret returns true if this is a secret variable
@@ -106,6 +131,17 @@ func ValidateKey(key string) error {
 	return nil
 }
 
+// ValidateTeam validates the team of a team variable
+func ValidateTeam(teamID string) error {
+	if teamID == "" {
+		return nil
+	}
+	if _, err := uuid.Parse(teamID); err != nil {
+		return ErrInvalidTeam
+	}
+	return nil
+}
+
 // VariableResponse is the API response (masks secrets)
 type VariableResponse struct {
 	ID          string    `json:"id"`
@@ -113,6 +149,8 @@ type VariableResponse struct {
 	Value       string    `json:"value"`
 	Type        string    `json:"type"`
 	Description string    `json:"description"`
+	TeamID      string    `json:"teamId,omitempty"`
+	Scope       string    `json:"scope"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
 }
@@ -125,6 +163,8 @@ func (v *Variable) ToResponse() VariableResponse {
 		Value:       v.MaskedValue(),
 		Type:        v.Type,
 		Description: v.Description,
+		TeamID:      v.Team(),
+		Scope:       v.Scope,
 		CreatedAt:   v.CreatedAt,
 		UpdatedAt:   v.UpdatedAt,
 	}
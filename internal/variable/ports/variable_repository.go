@@ -6,12 +6,14 @@ import (
 	variable "github.com/linkflow-go/internal/variable/domain"
 )
 
+// VariableRepository stores variables. An empty teamID selects the global
+// variables.
 type VariableRepository interface {
-	Exists(ctx context.Context, key string) (bool, error)
+	Exists(ctx context.Context, teamID, key string) (bool, error)
 	Create(ctx context.Context, v *variable.Variable) error
 	GetByID(ctx context.Context, id string) (*variable.Variable, error)
 	GetByKey(ctx context.Context, key string) (*variable.Variable, error)
-	List(ctx context.Context) ([]*variable.Variable, error)
+	List(ctx context.Context, teamID string) ([]*variable.Variable, error)
 	Update(ctx context.Context, v *variable.Variable) error
 	Delete(ctx context.Context, id string) error
 }
@@ -24,10 +24,6 @@ func (h *WebhookHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
-func (h *WebhookHandlers) Ready(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
-}
-
 func (h *WebhookHandlers) ListWebhooks(c *gin.Context) {
 	userID := c.GetHeader("X-User-ID")
 	if userID == "" {
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+const (
+	relayWriteWait  = 10 * time.Second
+	relayPongWait   = 60 * time.Second
+	relayPingPeriod = relayPongWait * 9 / 10
+)
+
+// relayUpgrader accepts relays from the CLI, which sends no Origin
+var relayUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// RelayWebhook upgrades to a WebSocket receiving a JSON message with a copy
+// of each request the webhook accepts while it is open
+func (h *WebhookHandlers) RelayWebhook(c *gin.Context) {
+	userID := c.GetHeader("X-User-ID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID required"})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	requests, err := h.service.Relay(ctx, c.Param("id"), userID)
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+
+	conn, err := relayUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader already answered the request
+		return
+	}
+	defer conn.Close()
+
+	// The relay sends nothing but pongs, reading notices it closed
+	conn.SetReadDeadline(time.Now().Add(relayPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(relayPongWait))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(relayPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case req, ok := <-requests:
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+					time.Now().Add(relayWriteWait))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(relayWriteWait))
+			if err := conn.WriteJSON(req); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(relayWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/linkflow-go/pkg/contracts/webhook"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/redis/go-redis/v9"
+)
+
+// relayBuffer bounds the requests waiting for a slow relay, requests
+// beyond it are not relayed
+const relayBuffer = 64
+
+// Relay streams copies of the requests a webhook accepts to its owner until
+// ctx is done. Requests are relayed from every replica of the service, those
+// accepted while no relay is open are not kept.
+func (s *WebhookService) Relay(ctx context.Context, id, userID string) (<-chan *webhook.RelayedRequest, error) {
+	wh, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, webhook.ErrWebhookNotFound
+	}
+	if wh.UserID != userID {
+		return nil, webhook.ErrRelayForbidden
+	}
+
+	pubsub := s.redis.Subscribe(ctx, rediskey.Tenant(ctx, webhook.RelayChannel(wh.ID)...))
+	// Wait for the subscription so no request accepted after Relay returns
+	// is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	requests := make(chan *webhook.RelayedRequest, relayBuffer)
+	go func() {
+		defer close(requests)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			var msg *redis.Message
+			select {
+			case <-ctx.Done():
+				return
+			case msg = <-messages:
+			}
+			if msg == nil {
+				return
+			}
+
+			var req webhook.RelayedRequest
+			if err := json.Unmarshal([]byte(msg.Payload), &req); err != nil {
+				s.logger.Error("Invalid relayed webhook request", "webhookId", wh.ID, "error", err)
+				continue
+			}
+			select {
+			case requests <- &req:
+			default:
+				s.logger.Warn("Webhook relay is behind, dropping request", "webhookId", wh.ID, "requestId", req.ID)
+			}
+		}
+	}()
+
+	s.logger.Info("Webhook relay opened", "webhookId", wh.ID, "userId", userID)
+	return requests, nil
+}
+
+// relay publishes a copy of a request the webhook accepted to its relays
+func (s *WebhookService) relay(ctx context.Context, wh *webhook.Webhook, execution *webhook.WebhookExecution) {
+	data, err := json.Marshal(webhook.NewRelayedRequest(wh, execution))
+	if err != nil {
+		return
+	}
+	if err := s.redis.Publish(ctx, rediskey.Tenant(ctx, webhook.RelayChannel(wh.ID)...), data).Err(); err != nil {
+		s.logger.Warn("Failed to relay webhook request", "webhookId", wh.ID, "error", err)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,9 +13,12 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/linkflow-go/internal/webhook/ports"
+	"github.com/linkflow-go/pkg/auth/jwt"
 	"github.com/linkflow-go/pkg/contracts/webhook"
+	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -25,6 +29,7 @@ type WebhookService struct {
 	logger     logger.Logger
 	webhooks   map[string]*webhook.Webhook // path -> webhook
 	webhooksMu sync.RWMutex
+	tokens     *jwt.Manager
 }
 
 func NewWebhookService(
@@ -42,6 +47,12 @@ func NewWebhookService(
 	}
 }
 
+// SetTokenManager enables the "jwt" webhook auth type, which authorizes
+// callers from the scope and quota hints embedded in LinkFlow tokens
+func (s *WebhookService) SetTokenManager(tokens *jwt.Manager) {
+	s.tokens = tokens
+}
+
 func (s *WebhookService) Start(ctx context.Context) error {
 	s.logger.Info("Starting webhook service")
 
@@ -261,6 +272,9 @@ func (s *WebhookService) HandleWebhook(ctx context.Context, path string, r *http
 	// Verify authentication if required
 	if wh.RequireAuth {
 		if err := s.verifyAuth(wh, r); err != nil {
+			if errors.Is(err, webhook.ErrQuotaNotIncluded) {
+				return nil, http.StatusPaymentRequired, err
+			}
 			return nil, http.StatusUnauthorized, err
 		}
 	}
@@ -319,6 +333,30 @@ func (s *WebhookService) HandleWebhook(ctx context.Context, path string, r *http
 		CreatedAt:   time.Now(),
 	}
 
+	// Developers relaying the webhook get a copy, it is handled as usual
+	s.relay(ctx, wh, execution)
+
+	// Repeat deliveries to an idempotent workflow get its cached output
+	// without running it again
+	if cached := s.cachedResult(ctx, wh.WorkflowID, payload); cached != nil {
+		now := time.Now()
+		execution.Status = "cached"
+		execution.ProcessedAt = &now
+		execution.Duration = now.Sub(execution.CreatedAt).Milliseconds()
+		if err := s.repo.RecordExecution(ctx, execution); err != nil {
+			s.logger.Error("Failed to record webhook execution", "error", err)
+		}
+		wh.RecordCall()
+		s.repo.Update(ctx, wh)
+
+		return &webhook.WebhookResponse{
+			Success:     true,
+			ExecutionID: cached.ExecutionID,
+			Message:     "Returned the cached result of an identical delivery",
+			Data:        cached.Output,
+		}, http.StatusOK, nil
+	}
+
 	if err := s.repo.RecordExecution(ctx, execution); err != nil {
 		s.logger.Error("Failed to record webhook execution", "error", err)
 	}
@@ -330,6 +368,7 @@ func (s *WebhookService) HandleWebhook(ctx context.Context, path string, r *http
 		WithPayload("workflowId", wh.WorkflowID).
 		WithPayload("nodeId", wh.NodeID).
 		WithPayload("executionId", execution.ID).
+		WithPayload("idempotencyKey", deliveryID(r)).
 		WithPayload("data", payload).
 		Build()
 
@@ -359,6 +398,51 @@ func (s *WebhookService) HandleWebhook(ctx context.Context, path string, r *http
 	}, http.StatusOK, nil
 }
 
+// deliveryIDHeaders are provider headers that uniquely identify a delivery and
+// stay the same when the sender retries it
+var deliveryIDHeaders = []string{
+	"Idempotency-Key",
+	"X-Idempotency-Key",
+	"X-Webhook-Delivery",
+	"X-GitHub-Delivery",
+	"X-Gitlab-Event-UUID",
+	"X-Shopify-Webhook-Id",
+	"Stripe-Idempotency-Key",
+}
+
+// deliveryID returns the sender's delivery identifier used to deduplicate
+// retried webhook deliveries, or an empty string if none was sent
+func deliveryID(r *http.Request) string {
+	for _, header := range deliveryIDHeaders {
+		if value := r.Header.Get(header); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// cachedResult returns the output the execution service cached for the
+// payload, only idempotent workflows have their output cached
+func (s *WebhookService) cachedResult(ctx context.Context, workflowID string, payload map[string]interface{}) *workflow.CachedResult {
+	hash, err := workflow.InputHash(payload)
+	if err != nil {
+		return nil
+	}
+	data, err := s.redis.Get(ctx, workflow.ResultCacheKey(workflowID, hash)).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			s.logger.Error("Failed to read cached workflow result", "workflowId", workflowID, "error", err)
+		}
+		return nil
+	}
+	var cached workflow.CachedResult
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil
+	}
+	metrics.ExecutionResultCache.WithLabelValues("webhook", "hit").Inc()
+	return &cached
+}
+
 // checkRateLimit checks if the webhook has exceeded its rate limit
 func (s *WebhookService) checkRateLimit(ctx context.Context, wh *webhook.Webhook) error {
 	key := fmt.Sprintf("webhook:ratelimit:%s", wh.ID)
@@ -404,7 +488,40 @@ func (s *WebhookService) verifyAuth(wh *webhook.Webhook, r *http.Request) error
 		if token != wh.AuthConfig["token"] {
 			return fmt.Errorf("invalid bearer token")
 		}
+	case "jwt":
+		return s.verifyTokenScope(wh, r)
+	}
+	return nil
+}
+
+// verifyTokenScope makes a fast-path decision from the claims of a LinkFlow
+// token without calling the auth service: the token must belong to the
+// webhook owner or its workspace, grant the webhook scope and come from a
+// plan that includes webhooks
+func (s *WebhookService) verifyTokenScope(wh *webhook.Webhook, r *http.Request) error {
+	if s.tokens == nil {
+		return fmt.Errorf("jwt auth is not configured")
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	claims, err := s.tokens.ValidateToken(token)
+	if err != nil {
+		return fmt.Errorf("invalid token")
 	}
+
+	workspaceID := wh.AuthConfig["workspaceId"]
+	if claims.UserID != wh.UserID && (workspaceID == "" || claims.WorkspaceID != workspaceID) {
+		return fmt.Errorf("token does not belong to webhook workspace")
+	}
+
+	if !claims.HasScope(jwt.ScopeWebhooksTrigger) {
+		return fmt.Errorf("token scope does not allow triggering webhooks")
+	}
+
+	if !claims.Quota.Allows(jwt.QuotaWebhooks) {
+		return webhook.ErrQuotaNotIncluded
+	}
+
 	return nil
 }
 
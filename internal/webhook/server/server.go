@@ -11,10 +11,14 @@ import (
 	"github.com/linkflow-go/internal/webhook/adapters/http/handlers"
 	"github.com/linkflow-go/internal/webhook/adapters/http/router"
 	"github.com/linkflow-go/internal/webhook/app/service"
+	"github.com/linkflow-go/pkg/apidoc"
+	"github.com/linkflow-go/pkg/auth/jwt"
 	"github.com/linkflow-go/pkg/config"
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/middleware/correlation"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 )
@@ -50,7 +54,7 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	}
 
 	// Initialize event bus
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	eventBus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
@@ -61,14 +65,32 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	// Initialize service
 	webhookService := service.NewWebhookService(webhookRepo, eventBus, redisClient, log)
 
+	// Enable token-scoped webhook auth when JWT validation is configured
+	if jwtManager, err := jwt.NewManager(cfg.Auth); err != nil {
+		log.Warn("JWT webhook auth disabled", "error", err)
+	} else {
+		webhookService.SetTokenManager(jwtManager)
+	}
+
 	// Initialize webhook router
 	webhookRouter := router.NewWebhookRouter(redisClient, log)
 
 	// Initialize handlers
 	webhookHandlers := handlers.NewWebhookHandlers(webhookService, log)
 
+	// Readiness reports the state of each dependency
+	checker := health.NewChecker("webhook-service")
+	checker.Critical("database", health.Database(db))
+	checker.Critical("redis", health.Redis(redisClient))
+	checker.Optional("event_bus", health.EventBus(eventBus))
+
 	// Setup HTTP server
-	r := setupRouter(webhookHandlers, webhookRouter, log)
+	r := setupRouter(webhookHandlers, checker, log)
+
+	// Serve the OpenAPI document of the routes registered above
+	if err := apidoc.Serve(r, "webhook", cfg.Server.StrictAPISpec, log); err != nil {
+		return nil, err
+	}
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -98,25 +120,26 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	}, nil
 }
 
-func setupRouter(h *handlers.WebhookHandlers, wr *router.WebhookRouter, log logger.Logger) *gin.Engine {
+func setupRouter(h *handlers.WebhookHandlers, checker *health.Checker, log logger.Logger) *gin.Engine {
 	r := gin.New()
 
 	// Middleware
 	r.Use(gin.Recovery())
+	r.Use(correlation.Middleware())
 	r.Use(corsMiddleware())
 	r.Use(loggingMiddleware(log))
 
 	// Health checks
 	r.GET("/health/live", h.Health)
-	r.GET("/health/ready", h.Ready)
+	r.GET("/health/ready", checker.ReadyHandler())
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// Webhook endpoint (dynamic routing)
-	r.Any("/webhook/:path", wr.RouteWebhook)
-	r.Any("/webhooks/:path", wr.RouteWebhook)
+	r.Any("/webhook/:path", h.HandleIncomingWebhook)
+	r.Any("/webhooks/:path", h.HandleIncomingWebhook)
 
 	// API routes for webhook management
-	v1 := r.Group("/api/v1/webhooks")
+	v1 := r.Group(apidoc.Prefix + "/webhooks")
 	{
 		// Webhook CRUD
 		v1.GET("", h.ListWebhooks)
@@ -130,6 +153,7 @@ func setupRouter(h *handlers.WebhookHandlers, wr *router.WebhookRouter, log logg
 		v1.POST("/:id/disable", h.DisableWebhook)
 		v1.POST("/:id/regenerate-secret", h.RegenerateSecret)
 		v1.POST("/:id/test", h.TestWebhook)
+		v1.GET("/:id/relay", h.RelayWebhook)
 
 		// Webhook logs
 		v1.GET("/:id/logs", h.GetWebhookLogs)
@@ -210,7 +234,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -239,7 +263,7 @@ func loggingMiddleware(log logger.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		log.Info("HTTP Request",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP Request",
 			"method", method,
 			"path", path,
 			"status", statusCode,
@@ -10,7 +10,9 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/linkflow-go/pkg/config"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/middleware/correlation"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -32,7 +34,7 @@ type Server struct {
 
 func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	// Initialize event bus
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	eventBus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
@@ -40,7 +42,11 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	hub := NewHub(log)
 	go hub.Run()
 
-	router := setupRouter(hub, log)
+	// Readiness reports the event bus feeding the hub
+	checker := health.NewChecker("websocket-service")
+	checker.Optional("event_bus", health.EventBus(eventBus))
+
+	router := setupRouter(hub, checker, log)
 
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
@@ -56,17 +62,16 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	}, nil
 }
 
-func setupRouter(hub *Hub, log logger.Logger) *gin.Engine {
+func setupRouter(hub *Hub, checker *health.Checker, log logger.Logger) *gin.Engine {
 	router := gin.New()
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
 
 	// Health checks
 	router.GET("/health/live", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 	})
-	router.GET("/health/ready", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ready", "connections": hub.ConnectionCount()})
-	})
+	router.GET("/health/ready", checker.ReadyHandler())
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// WebSocket endpoint
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Client calls the trigger admin API, for use by operations tooling
+type Client struct {
+	conn  *grpc.ClientConn
+	token string
+}
+
+// NewClient wraps an existing connection to the workflow service admin port
+func NewClient(conn *grpc.ClientConn, token string) *Client {
+	return &Client{conn: conn, token: token}
+}
+
+// PauseWorkspaceSchedules pauses every active schedule in a workspace
+func (c *Client) PauseWorkspaceSchedules(ctx context.Context, req *PauseWorkspaceSchedulesRequest) (*PauseWorkspaceSchedulesResponse, error) {
+	resp := new(PauseWorkspaceSchedulesResponse)
+	return resp, c.invoke(ctx, "PauseWorkspaceSchedules", req, resp)
+}
+
+// ListUpcomingTriggers lists schedules firing within the requested window
+func (c *Client) ListUpcomingTriggers(ctx context.Context, req *ListUpcomingTriggersRequest) (*ListUpcomingTriggersResponse, error) {
+	resp := new(ListUpcomingTriggersResponse)
+	return resp, c.invoke(ctx, "ListUpcomingTriggers", req, resp)
+}
+
+// ReloadTrigger reloads a trigger definition from the database
+func (c *Client) ReloadTrigger(ctx context.Context, req *ReloadTriggerRequest) (*ReloadTriggerResponse, error) {
+	resp := new(ReloadTriggerResponse)
+	return resp, c.invoke(ctx, "ReloadTrigger", req, resp)
+}
+
+func (c *Client) invoke(ctx context.Context, method string, req, resp interface{}) error {
+	if c.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.token)
+	}
+	return c.conn.Invoke(ctx, "/"+ServiceName+"/"+method, req, resp, grpc.CallContentSubtype(codecName))
+}
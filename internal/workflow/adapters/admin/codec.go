@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// codecName is the gRPC content subtype of the admin API. Messages are plain
+// JSON so operations tooling needs no generated stubs.
+const codecName = "json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
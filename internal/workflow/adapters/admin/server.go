@@ -0,0 +1,245 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/linkflow-go/internal/workflow/adapters/triggers"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceName is the fully qualified gRPC service name of the admin API
+const ServiceName = "linkflow.workflow.admin.v1.TriggerAdmin"
+
+// defaultUpcomingWindow is used when ListUpcomingTriggers has no window
+const defaultUpcomingWindow = time.Hour
+
+// PauseWorkspaceSchedulesRequest pauses all schedules of a workspace
+type PauseWorkspaceSchedulesRequest struct {
+	WorkspaceID string `json:"workspaceId"`
+}
+
+// PauseWorkspaceSchedulesResponse lists the triggers that were paused
+type PauseWorkspaceSchedulesResponse struct {
+	TriggerIDs []string `json:"triggerIds"`
+}
+
+// ListUpcomingTriggersRequest selects triggers firing within WindowSeconds
+type ListUpcomingTriggersRequest struct {
+	WindowSeconds int64 `json:"windowSeconds"`
+}
+
+// ListUpcomingTriggersResponse lists upcoming triggers, soonest first
+type ListUpcomingTriggersResponse struct {
+	Triggers []*triggers.UpcomingTrigger `json:"triggers"`
+}
+
+// ReloadTriggerRequest reloads a single trigger definition
+type ReloadTriggerRequest struct {
+	TriggerID string `json:"triggerId"`
+}
+
+// ReloadTriggerResponse is the trigger as it was reloaded
+type ReloadTriggerResponse struct {
+	Trigger *workflow.WorkflowTrigger `json:"trigger"`
+}
+
+// TriggerAdmin is the internal fleet operations API of the trigger manager
+type TriggerAdmin interface {
+	PauseWorkspaceSchedules(ctx context.Context, req *PauseWorkspaceSchedulesRequest) (*PauseWorkspaceSchedulesResponse, error)
+	ListUpcomingTriggers(ctx context.Context, req *ListUpcomingTriggersRequest) (*ListUpcomingTriggersResponse, error)
+	ReloadTrigger(ctx context.Context, req *ReloadTriggerRequest) (*ReloadTriggerResponse, error)
+}
+
+// Server serves the trigger admin API over gRPC
+type Server struct {
+	manager    *triggers.TriggerManager
+	logger     logger.Logger
+	grpcServer *grpc.Server
+}
+
+// NewServer creates an admin server. Every call must carry token as a bearer
+// token in the authorization metadata, all calls are refused without one.
+func NewServer(manager *triggers.TriggerManager, token string, logger logger.Logger) *Server {
+	s := &Server{
+		manager: manager,
+		logger:  logger,
+	}
+
+	s.grpcServer = grpc.NewServer(grpc.ChainUnaryInterceptor(
+		recoveryInterceptor(logger),
+		authInterceptor(token),
+	))
+	s.grpcServer.RegisterService(&serviceDesc, s)
+
+	return s
+}
+
+// Serve accepts admin connections on lis until Stop is called
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpcServer.Serve(lis)
+}
+
+// Stop waits for in-flight calls to finish, or aborts them once ctx is done
+func (s *Server) Stop(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+	}
+}
+
+// PauseWorkspaceSchedules pauses every active schedule in a workspace
+func (s *Server) PauseWorkspaceSchedules(ctx context.Context, req *PauseWorkspaceSchedulesRequest) (*PauseWorkspaceSchedulesResponse, error) {
+	if req.WorkspaceID == "" {
+		return nil, status.Error(codes.InvalidArgument, "workspaceId is required")
+	}
+
+	paused, err := s.manager.PauseWorkspaceSchedules(ctx, req.WorkspaceID)
+	if err != nil {
+		s.logger.Error("Failed to pause workspace schedules", "workspace_id", req.WorkspaceID, "paused", len(paused), "error", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &PauseWorkspaceSchedulesResponse{TriggerIDs: paused}, nil
+}
+
+// ListUpcomingTriggers lists schedules firing within the requested window
+func (s *Server) ListUpcomingTriggers(ctx context.Context, req *ListUpcomingTriggersRequest) (*ListUpcomingTriggersResponse, error) {
+	if req.WindowSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "windowSeconds must not be negative")
+	}
+
+	window := time.Duration(req.WindowSeconds) * time.Second
+	if window == 0 {
+		window = defaultUpcomingWindow
+	}
+
+	upcoming, err := s.manager.ListUpcomingTriggers(ctx, window)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &ListUpcomingTriggersResponse{Triggers: upcoming}, nil
+}
+
+// ReloadTrigger reloads a trigger definition from the database
+func (s *Server) ReloadTrigger(ctx context.Context, req *ReloadTriggerRequest) (*ReloadTriggerResponse, error) {
+	if req.TriggerID == "" {
+		return nil, status.Error(codes.InvalidArgument, "triggerId is required")
+	}
+
+	trigger, err := s.manager.ReloadTrigger(ctx, req.TriggerID)
+	if err != nil {
+		if errors.Is(err, triggers.ErrTriggerNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &ReloadTriggerResponse{Trigger: trigger}, nil
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*TriggerAdmin)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PauseWorkspaceSchedules",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(PauseWorkspaceSchedulesRequest)
+				return unary(srv, ctx, dec, interceptor, req, "PauseWorkspaceSchedules", func(ctx context.Context) (interface{}, error) {
+					return srv.(TriggerAdmin).PauseWorkspaceSchedules(ctx, req)
+				})
+			},
+		},
+		{
+			MethodName: "ListUpcomingTriggers",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(ListUpcomingTriggersRequest)
+				return unary(srv, ctx, dec, interceptor, req, "ListUpcomingTriggers", func(ctx context.Context) (interface{}, error) {
+					return srv.(TriggerAdmin).ListUpcomingTriggers(ctx, req)
+				})
+			},
+		},
+		{
+			MethodName: "ReloadTrigger",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(ReloadTriggerRequest)
+				return unary(srv, ctx, dec, interceptor, req, "ReloadTrigger", func(ctx context.Context) (interface{}, error) {
+					return srv.(TriggerAdmin).ReloadTrigger(ctx, req)
+				})
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// unary decodes req and runs call through the server interceptors
+func unary(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor,
+	req interface{}, method string, call func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if err := dec(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if interceptor == nil {
+		return call(ctx)
+	}
+
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + ServiceName + "/" + method,
+	}
+	return interceptor(ctx, req, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return call(ctx)
+	})
+}
+
+// authInterceptor requires the shared admin token on every call
+func authInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if token == "" {
+			return nil, status.Error(codes.Unauthenticated, "admin token not configured")
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "missing admin token")
+		}
+
+		provided := strings.TrimPrefix(values[0], "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			return nil, status.Error(codes.PermissionDenied, "invalid admin token")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// recoveryInterceptor turns handler panics into Internal errors
+func recoveryInterceptor(log logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("Admin call panicked", "method", info.FullMethod, "panic", r)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// ListManagedWorkflows returns the workflows the applies of a namespace
+// created in a workspace
+func (r *WorkflowRepository) ListManagedWorkflows(ctx context.Context, workspaceID, namespace string) ([]*workflow.ManagedWorkflow, error) {
+	var managed []*workflow.ManagedWorkflow
+	err := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND namespace = ?", workspaceID, namespace).
+		Order("key ASC").
+		Find(&managed).Error
+	return managed, err
+}
+
+// SaveManagedWorkflow creates or replaces the record of a workflow created
+// by an apply
+func (r *WorkflowRepository) SaveManagedWorkflow(ctx context.Context, managed *workflow.ManagedWorkflow) error {
+	if managed.ID == "" {
+		managed.ID = uuid.New().String()
+	}
+	return r.db.WithContext(ctx).Save(managed).Error
+}
+
+// DeleteManagedWorkflow removes the record of a workflow created by an
+// apply
+func (r *WorkflowRepository) DeleteManagedWorkflow(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&workflow.ManagedWorkflow{}).Error
+}
+
+// FindCredentialIDs returns the IDs of the credentials of a user with each
+// of names, keyed by name. Names several credentials share have several IDs.
+func (r *WorkflowRepository) FindCredentialIDs(ctx context.Context, userID string, names []string) (map[string][]string, error) {
+	var rows []struct {
+		ID   string
+		Name string
+	}
+	err := r.db.WithContext(ctx).
+		Table("credential.credentials").
+		Select("id, name").
+		Where("user_id = ? AND name IN ?", userID, names).
+		Order("name ASC, id ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string][]string, len(rows))
+	for _, row := range rows {
+		ids[row.Name] = append(ids[row.Name], row.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+)
+
+// GetBudget returns the budget of a workflow or workspace
+func (r *WorkflowRepository) GetBudget(ctx context.Context, scope, scopeID string) (*workflow.Budget, error) {
+	var budget workflow.Budget
+	err := r.db.WithContext(ctx).
+		Where("scope = ? AND scope_id = ?", scope, scopeID).
+		First(&budget).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, workflow.ErrBudgetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &budget, nil
+}
+
+// SaveBudget creates or replaces a budget
+func (r *WorkflowRepository) SaveBudget(ctx context.Context, budget *workflow.Budget) error {
+	return r.db.WithContext(ctx).Save(budget).Error
+}
+
+// DeleteBudget removes the budget of a workflow or workspace
+func (r *WorkflowRepository) DeleteBudget(ctx context.Context, scope, scopeID string) error {
+	result := r.db.WithContext(ctx).
+		Where("scope = ? AND scope_id = ?", scope, scopeID).
+		Delete(&workflow.Budget{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return workflow.ErrBudgetNotFound
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+)
+
+// CreateCanary stores a new running canary
+func (r *WorkflowRepository) CreateCanary(ctx context.Context, canary *workflow.Canary) error {
+	if canary.ID == "" {
+		canary.ID = uuid.New().String()
+	}
+	return r.db.WithContext(ctx).Create(canary).Error
+}
+
+// GetRunningCanary returns the running canary of a workflow
+func (r *WorkflowRepository) GetRunningCanary(ctx context.Context, workflowID string) (*workflow.Canary, error) {
+	var canary workflow.Canary
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ? AND status = ?", workflowID, workflow.CanaryRunning).
+		First(&canary).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, workflow.ErrCanaryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &canary, nil
+}
+
+// UpdateCanary saves a canary
+func (r *WorkflowRepository) UpdateCanary(ctx context.Context, canary *workflow.Canary) error {
+	return r.db.WithContext(ctx).Save(canary).Error
+}
+
+// GetVersionStats summarizes the finished executions of a workflow version
+// since a point in time
+func (r *WorkflowRepository) GetVersionStats(ctx context.Context, workflowID string, version int, since time.Time) (*workflow.CanaryStats, error) {
+	var row struct {
+		Executions  int64
+		Failures    int64
+		AvgDuration float64
+	}
+
+	err := r.db.WithContext(ctx).
+		Model(&workflow.WorkflowExecution{}).
+		Select(`COUNT(*) AS executions,
+			COALESCE(SUM(CASE WHEN status IN (?, ?) THEN 1 ELSE 0 END), 0) AS failures,
+			COALESCE(AVG(execution_time), 0) AS avg_duration`,
+			workflow.ExecutionFailed, workflow.ExecutionTimeout).
+		Where("workflow_id = ? AND version = ? AND created_at >= ?", workflowID, version, since).
+		Where("status IN ?", []workflow.ExecutionStatus{workflow.ExecutionCompleted, workflow.ExecutionFailed, workflow.ExecutionTimeout}).
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return workflow.NewCanaryStats(version, row.Executions, row.Failures, row.AvgDuration), nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/database"
+)
+
+// CreateWorkflowChange records the summary of a save of a workflow
+func (r *WorkflowRepository) CreateWorkflowChange(ctx context.Context, change *workflow.WorkflowChange) error {
+	if change.ID == "" {
+		change.ID = uuid.New().String()
+	}
+	return r.db.WithContext(ctx).Create(change).Error
+}
+
+// ListWorkflowChanges lists the changes of a workflow newest first, those
+// of one user when userID is set
+func (r *WorkflowRepository) ListWorkflowChanges(ctx context.Context, workflowID, userID string, page *database.CursorPage) ([]*workflow.WorkflowChange, error) {
+	var changes []*workflow.WorkflowChange
+	query := r.db.Where("workflow_id = ?", workflowID)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := r.db.PaginateCursor(ctx, &changes, page, query); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/linkflow-go/internal/workflow/ports"
 	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/database"
+	"github.com/linkflow-go/pkg/tenant"
 	"gorm.io/gorm"
 )
 
@@ -64,6 +67,8 @@ func (r *WorkflowRepository) CreateCategory(ctx context.Context, category map[st
 func (r *WorkflowRepository) GetWorkflowStats(ctx context.Context, workflowID string) (ports.WorkflowStats, error) {
 	var stats ports.WorkflowStats
 
+	// Raw SQL escapes the tenant plugin, scope it explicitly
+	tenantID := tenant.FromContext(ctx)
 	err := r.db.WithContext(ctx).Raw(`
 		SELECT
 			COUNT(*) as total_executions,
@@ -73,12 +78,13 @@ func (r *WorkflowRepository) GetWorkflowStats(ctx context.Context, workflowID st
 			MAX(created_at) as last_execution_time
 		FROM workflow.workflow_executions
 		WHERE workflow_id = ?
-	`, workflowID).Scan(&stats).Error
+		AND (? = '' OR tenant_id = ?)
+	`, workflowID, tenantID, tenantID).Scan(&stats).Error
 
 	return stats, err
 }
 
-func (r *WorkflowRepository) ListWorkflowExecutions(ctx context.Context, workflowID string, offset, limit int) ([]workflow.WorkflowExecution, int64, error) {
+func (r *WorkflowRepository) ListWorkflowExecutions(ctx context.Context, workflowID string, page *database.CursorPage) ([]workflow.WorkflowExecution, int64, error) {
 	var total int64
 	var executions []workflow.WorkflowExecution
 
@@ -89,13 +95,8 @@ func (r *WorkflowRepository) ListWorkflowExecutions(ctx context.Context, workflo
 		return nil, 0, err
 	}
 
-	err := r.db.WithContext(ctx).
-		Where("workflow_id = ?", workflowID).
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(limit).
-		Find(&executions).Error
-	if err != nil {
+	query := r.db.Where("workflow_id = ?", workflowID)
+	if err := r.db.PaginateCursor(ctx, &executions, page, query); err != nil {
 		return nil, 0, err
 	}
 
@@ -115,17 +116,31 @@ func (r *WorkflowRepository) GetLatestWorkflowExecution(ctx context.Context, wor
 	return &exec, nil
 }
 
+// ListExecutionChecksums lists the version and definition checksum recorded
+// by each execution of a workflow
+func (r *WorkflowRepository) ListExecutionChecksums(ctx context.Context, workflowID string) ([]workflow.WorkflowExecution, error) {
+	var executions []workflow.WorkflowExecution
+	err := r.db.WithContext(ctx).
+		Select("id", "version", "workflow_checksum").
+		Where("workflow_id = ? AND workflow_checksum <> ''", workflowID).
+		Order("created_at DESC").
+		Find(&executions).Error
+	return executions, err
+}
+
 func (r *WorkflowRepository) GetPopularTags(ctx context.Context, limit int) ([]string, error) {
 	var tags []string
 
+	tenantID := tenant.FromContext(ctx)
 	err := r.db.WithContext(ctx).Raw(`
 		SELECT DISTINCT unnest(tags) as tag
 		FROM workflow.workflows
 		WHERE deleted_at IS NULL
+		AND (? = '' OR tenant_id = ?)
 		GROUP BY tag
 		ORDER BY COUNT(*) DESC
 		LIMIT ?
-	`, limit).Scan(&tags).Error
+	`, tenantID, tenantID, limit).Scan(&tags).Error
 	if err != nil {
 		return nil, err
 	}
@@ -174,6 +189,21 @@ func (r *WorkflowRepository) DeleteWorkflowVariable(ctx context.Context, workflo
 	return result.RowsAffected, nil
 }
 
+// ListSharedVariables lists the global variables of the variable service
+// and those of a team
+func (r *WorkflowRepository) ListSharedVariables(ctx context.Context, teamID string) ([]workflow.SharedVariable, error) {
+	query := r.db.WithContext(ctx).Where("team_id IS NULL")
+	if _, err := uuid.Parse(teamID); err == nil {
+		query = r.db.WithContext(ctx).Where("team_id IS NULL OR team_id = ?", teamID)
+	}
+
+	var vars []workflow.SharedVariable
+	if err := query.Find(&vars).Error; err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
 // Environments
 
 func (r *WorkflowRepository) CountEnvironments(ctx context.Context, workflowID string) (int64, error) {
@@ -227,8 +257,61 @@ func (r *WorkflowRepository) UpdateEnvironment(ctx context.Context, workflowID,
 	return result.RowsAffected, nil
 }
 
+// DeleteEnvironment deletes an environment, the environments promoting from
+// it no longer promote from any
 func (r *WorkflowRepository) DeleteEnvironment(ctx context.Context, env *workflow.Environment) error {
-	return r.db.WithContext(ctx).Delete(env).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&workflow.Environment{}).
+			Where("workflow_id = ? AND promotes_from = ?", env.WorkflowID, env.ID).
+			Update("promotes_from", "").Error; err != nil {
+			return err
+		}
+		return tx.Delete(env).Error
+	})
+}
+
+func (r *WorkflowRepository) GetEnvironmentByName(ctx context.Context, workflowID, name string) (*workflow.Environment, error) {
+	var env workflow.Environment
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ? AND name = ?", workflowID, name).
+		First(&env).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &env, nil
+}
+
+// DeployEnvironment records the version deployed to an environment with
+// its deployment
+func (r *WorkflowRepository) DeployEnvironment(ctx context.Context, env *workflow.Environment, deployment *workflow.Deployment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&workflow.Environment{}).
+			Where("workflow_id = ? AND id = ?", env.WorkflowID, env.ID).
+			Updates(map[string]interface{}{
+				"deployed_version": env.DeployedVersion,
+				"deployed_by":      env.DeployedBy,
+				"deployed_at":      env.DeployedAt,
+				"updated_at":       env.UpdatedAt,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Create(deployment).Error
+	})
+}
+
+// ListDeployments lists the deployments of an environment, latest first
+func (r *WorkflowRepository) ListDeployments(ctx context.Context, workflowID, envID string) ([]*workflow.Deployment, error) {
+	var deployments []*workflow.Deployment
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ? AND environment_id = ?", workflowID, envID).
+		Order("created_at DESC").
+		Find(&deployments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
 }
 
 func (r *WorkflowRepository) SetDefaultEnvironment(ctx context.Context, workflowID, envID string) (int64, error) {
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+)
+
+// CreateTriggerFixture stores a fixture of a trigger
+func (r *WorkflowRepository) CreateTriggerFixture(ctx context.Context, fixture *workflow.TriggerFixture) error {
+	if fixture.ID == "" {
+		fixture.ID = uuid.New().String()
+	}
+	return r.db.WithContext(ctx).Create(fixture).Error
+}
+
+// ListTriggerFixtures returns the fixtures of a trigger by name
+func (r *WorkflowRepository) ListTriggerFixtures(ctx context.Context, triggerID string) ([]*workflow.TriggerFixture, error) {
+	var fixtures []*workflow.TriggerFixture
+	err := r.db.WithContext(ctx).
+		Where("trigger_id = ?", triggerID).
+		Order("name ASC").
+		Find(&fixtures).Error
+	return fixtures, err
+}
+
+// GetTriggerFixture returns a fixture of a trigger by name
+func (r *WorkflowRepository) GetTriggerFixture(ctx context.Context, triggerID, name string) (*workflow.TriggerFixture, error) {
+	var fixture workflow.TriggerFixture
+	err := r.db.WithContext(ctx).
+		Where("trigger_id = ? AND name = ?", triggerID, name).
+		First(&fixture).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, workflow.ErrFixtureNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &fixture, nil
+}
+
+// DeleteTriggerFixture deletes a fixture of a trigger by name, all of them
+// when name is empty
+func (r *WorkflowRepository) DeleteTriggerFixture(ctx context.Context, triggerID, name string) error {
+	query := r.db.WithContext(ctx).Where("trigger_id = ?", triggerID)
+	if name == "" {
+		return query.Delete(&workflow.TriggerFixture{}).Error
+	}
+
+	result := query.Where("name = ?", name).Delete(&workflow.TriggerFixture{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return workflow.ErrFixtureNotFound
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+)
+
+// GetGitConnection returns the Git connection of a workflow
+func (r *WorkflowRepository) GetGitConnection(ctx context.Context, workflowID string) (*workflow.GitConnection, error) {
+	var conn workflow.GitConnection
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ?", workflowID).
+		First(&conn).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, workflow.ErrGitConnectionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conn.HasToken = conn.Token != ""
+	return &conn, nil
+}
+
+// SaveGitConnection creates or replaces a Git connection
+func (r *WorkflowRepository) SaveGitConnection(ctx context.Context, conn *workflow.GitConnection) error {
+	if conn.ID == "" {
+		conn.ID = uuid.New().String()
+	}
+	conn.HasToken = conn.Token != ""
+	return r.db.WithContext(ctx).Save(conn).Error
+}
+
+// DeleteGitConnection removes the Git connection of a workflow
+func (r *WorkflowRepository) DeleteGitConnection(ctx context.Context, workflowID string) error {
+	result := r.db.WithContext(ctx).
+		Where("workflow_id = ?", workflowID).
+		Delete(&workflow.GitConnection{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return workflow.ErrGitConnectionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// ListNodeOutputSchemas returns the inferred output schemas of the nodes of
+// a workflow
+func (r *WorkflowRepository) ListNodeOutputSchemas(ctx context.Context, workflowID string) ([]*workflow.NodeOutputSchema, error) {
+	var schemas []*workflow.NodeOutputSchema
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ?", workflowID).
+		Order("node_id ASC").
+		Find(&schemas).Error
+	return schemas, err
+}
+
+// SaveNodeOutputSchema creates or replaces the output schema of a node
+func (r *WorkflowRepository) SaveNodeOutputSchema(ctx context.Context, schema *workflow.NodeOutputSchema) error {
+	if schema.ID == "" {
+		schema.ID = uuid.New().String()
+	}
+	return r.db.WithContext(ctx).Save(schema).Error
+}
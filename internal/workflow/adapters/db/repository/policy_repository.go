@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+)
+
+// GetWorkspacePolicy returns the policy of a workspace
+func (r *WorkflowRepository) GetWorkspacePolicy(ctx context.Context, workspaceID string) (*workflow.WorkspacePolicy, error) {
+	var policy workflow.WorkspacePolicy
+	err := r.db.WithContext(ctx).
+		Where("workspace_id = ?", workspaceID).
+		First(&policy).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, workflow.ErrPolicyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// SaveWorkspacePolicy creates or replaces the policy of a workspace
+func (r *WorkflowRepository) SaveWorkspacePolicy(ctx context.Context, policy *workflow.WorkspacePolicy) error {
+	return r.db.WithContext(ctx).Save(policy).Error
+}
+
+// ListWorkspaceWorkflows returns the workflows of a team, or those of a user
+// outside any team when workspaceID is a user
+func (r *WorkflowRepository) ListWorkspaceWorkflows(ctx context.Context, workspaceID string) ([]*workflow.Workflow, error) {
+	var workflows []*workflow.Workflow
+	err := r.db.WithContext(ctx).
+		Where("deleted_at IS NULL").
+		Where("team_id = ? OR (COALESCE(CAST(team_id AS TEXT), '') = '' AND user_id = ?)", workspaceID, workspaceID).
+		Order("created_at ASC").
+		Find(&workflows).Error
+
+	return workflows, err
+}
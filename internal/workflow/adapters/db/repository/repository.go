@@ -10,6 +10,7 @@ import (
 	"github.com/linkflow-go/internal/workflow/ports"
 	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/database"
+	"github.com/linkflow-go/pkg/tenant"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -24,6 +25,10 @@ func NewWorkflowRepository(db *database.DB) *WorkflowRepository {
 
 // CreateWithVersion creates a new workflow with initial version
 func (r *WorkflowRepository) CreateWithVersion(ctx context.Context, w *workflow.Workflow) error {
+	if err := w.UpdateChecksum(); err != nil {
+		return err
+	}
+
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Create workflow
 		if err := tx.Create(w).Error; err != nil {
@@ -41,6 +46,7 @@ func (r *WorkflowRepository) CreateWithVersion(ctx context.Context, w *workflow.
 			WorkflowID: w.ID,
 			Version:    1,
 			Data:       workflowJSON,
+			Checksum:   w.Checksum,
 			ChangedBy:  w.UserID,
 			ChangeNote: "Initial version",
 			CreatedAt:  time.Now(),
@@ -111,6 +117,9 @@ func (r *WorkflowRepository) UpdateWithVersion(ctx context.Context, w *workflow.
 		// Increment version
 		w.Version = currentVersion + 1
 		w.UpdatedAt = time.Now()
+		if err := w.UpdateChecksum(); err != nil {
+			return err
+		}
 
 		// Update workflow
 		if err := tx.Save(w).Error; err != nil {
@@ -128,6 +137,7 @@ func (r *WorkflowRepository) UpdateWithVersion(ctx context.Context, w *workflow.
 			WorkflowID: w.ID,
 			Version:    w.Version,
 			Data:       workflowJSON,
+			Checksum:   w.Checksum,
 			ChangedBy:  w.UserID,
 			ChangeNote: changeNote,
 			CreatedAt:  time.Now(),
@@ -177,6 +187,11 @@ func (r *WorkflowRepository) RestoreVersion(ctx context.Context, workflowID stri
 			return err
 		}
 
+		// Never restore a definition that was altered after it was recorded
+		if err := verifyVersionChecksum(&wv, &restoredWorkflow); err != nil {
+			return err
+		}
+
 		// Get current version number
 		var currentVersion int
 		err := tx.Model(&workflow.WorkflowVersion{}).
@@ -201,6 +216,7 @@ func (r *WorkflowRepository) RestoreVersion(ctx context.Context, workflowID stri
 			WorkflowID: workflowID,
 			Version:    restoredWorkflow.Version,
 			Data:       wv.Data,
+			Checksum:   restoredWorkflow.Checksum,
 			ChangedBy:  userID,
 			ChangeNote: fmt.Sprintf("Restored from version %d", version),
 			CreatedAt:  time.Now(),
@@ -242,7 +258,7 @@ func (r *WorkflowRepository) ListWorkflows(ctx context.Context, opts ListWorkflo
 	// Search by name or description
 	if opts.Search != "" {
 		searchTerm := "%" + opts.Search + "%"
-		query = query.Where("name ILIKE ? OR description ILIKE ?", searchTerm, searchTerm)
+		query = query.Where(r.db.ILike("name")+" OR "+r.db.ILike("description"), searchTerm, searchTerm)
 	}
 
 	// Exclude deleted
@@ -253,6 +269,15 @@ func (r *WorkflowRepository) ListWorkflows(ctx context.Context, opts ListWorkflo
 		return nil, 0, err
 	}
 
+	// Keyset pages order the rows themselves
+	if opts.Cursor != nil {
+		if opts.Cursor.Sort == "" {
+			opts.Cursor.Sort = "updated_at"
+		}
+		err := r.db.PaginateCursor(ctx, &workflows, opts.Cursor, query)
+		return workflows, total, err
+	}
+
 	// Apply sorting
 	if opts.SortBy != "" {
 		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: opts.SortBy}, Desc: opts.SortDesc})
@@ -320,13 +345,16 @@ func (r *WorkflowRepository) GetWorkflowsByNodeType(ctx context.Context, nodeTyp
 	query := fmt.Sprintf(`
 		SELECT * FROM workflows 
 		WHERE deleted_at IS NULL 
+		AND (? = '' OR tenant_id = ?)
 		AND EXISTS (
-			SELECT 1 FROM jsonb_array_elements(nodes) AS node 
-			WHERE node->>'type' = '%s'
+			SELECT 1 FROM %s 
+			WHERE node.value->>'type' = ?
 		)
-	`, nodeType)
+	`, r.db.JSONArrayElements("nodes", "node"))
 
-	err := r.db.WithContext(ctx).Raw(query).Scan(&workflows).Error
+	// Raw SQL escapes the tenant plugin, scope it explicitly
+	tenantID := tenant.FromContext(ctx)
+	err := r.db.WithContext(ctx).Raw(query, tenantID, tenantID, nodeType).Scan(&workflows).Error
 	return workflows, err
 }
 
@@ -412,6 +440,16 @@ func (r *WorkflowRepository) UpdateConnections(ctx context.Context, workflowID s
 		}).Error
 }
 
+// CountActiveWorkflows counts the active workflows of the tenant of ctx
+func (r *WorkflowRepository) CountActiveWorkflows(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&workflow.Workflow{}).
+		Where("is_active = ?", true).
+		Count(&count).Error
+	return count, err
+}
+
 // ListWorkflowsOptions is kept in this package for backward compatibility.
 // The canonical definition lives in internal/workflow/ports.
 type ListWorkflowsOptions = ports.ListWorkflowsOptions
@@ -425,6 +463,12 @@ func (r *WorkflowRepository) GetWorkflow(ctx context.Context, workflowID, userID
 	return r.GetByIDAndUser(ctx, workflowID, userID)
 }
 
+// GetWorkflowByID gets a workflow whoever owns it, callers check the user
+// may access it
+func (r *WorkflowRepository) GetWorkflowByID(ctx context.Context, workflowID string) (*workflow.Workflow, error) {
+	return r.GetWithNodes(ctx, workflowID)
+}
+
 func (r *WorkflowRepository) UpdateWorkflow(ctx context.Context, w *workflow.Workflow) error {
 	return r.UpdateWithVersion(ctx, w, "Updated")
 }
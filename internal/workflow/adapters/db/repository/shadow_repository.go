@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+)
+
+// CreateShadow stores a new running shadow
+func (r *WorkflowRepository) CreateShadow(ctx context.Context, shadow *workflow.Shadow) error {
+	if shadow.ID == "" {
+		shadow.ID = uuid.New().String()
+	}
+	return r.db.WithContext(ctx).Create(shadow).Error
+}
+
+// GetRunningShadow returns the running shadow of a workflow
+func (r *WorkflowRepository) GetRunningShadow(ctx context.Context, workflowID string) (*workflow.Shadow, error) {
+	return r.findShadow(r.db.WithContext(ctx).
+		Where("workflow_id = ? AND status = ?", workflowID, workflow.ShadowRunning))
+}
+
+// GetLatestShadow returns the most recently started shadow of a workflow,
+// running or stopped
+func (r *WorkflowRepository) GetLatestShadow(ctx context.Context, workflowID string) (*workflow.Shadow, error) {
+	return r.findShadow(r.db.WithContext(ctx).
+		Where("workflow_id = ?", workflowID).
+		Order("started_at DESC"))
+}
+
+// UpdateShadow saves a shadow
+func (r *WorkflowRepository) UpdateShadow(ctx context.Context, shadow *workflow.Shadow) error {
+	return r.db.WithContext(ctx).Save(shadow).Error
+}
+
+// ListShadowComparisons returns the latest comparisons of a shadow, newest
+// first
+func (r *WorkflowRepository) ListShadowComparisons(ctx context.Context, shadowID string, limit int) ([]*workflow.ShadowComparison, error) {
+	var comparisons []*workflow.ShadowComparison
+	err := r.db.WithContext(ctx).
+		Where("shadow_id = ?", shadowID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&comparisons).Error
+
+	return comparisons, err
+}
+
+func (r *WorkflowRepository) findShadow(query *gorm.DB) (*workflow.Shadow, error) {
+	var shadow workflow.Shadow
+	err := query.First(&shadow).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, workflow.ErrShadowNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &shadow, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+)
+
+// GetSLA returns the SLA of a workflow
+func (r *WorkflowRepository) GetSLA(ctx context.Context, workflowID string) (*workflow.SLA, error) {
+	var sla workflow.SLA
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ?", workflowID).
+		First(&sla).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, workflow.ErrSLANotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &sla, nil
+}
+
+// SaveSLA creates or replaces an SLA
+func (r *WorkflowRepository) SaveSLA(ctx context.Context, sla *workflow.SLA) error {
+	return r.db.WithContext(ctx).Save(sla).Error
+}
+
+// DeleteSLA removes the SLA of a workflow
+func (r *WorkflowRepository) DeleteSLA(ctx context.Context, workflowID string) error {
+	result := r.db.WithContext(ctx).
+		Where("workflow_id = ?", workflowID).
+		Delete(&workflow.SLA{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return workflow.ErrSLANotFound
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"gorm.io/gorm"
+)
+
+// CreateTestCase stores a test case of a workflow
+func (r *WorkflowRepository) CreateTestCase(ctx context.Context, testCase *workflow.WorkflowTestCase) error {
+	if testCase.ID == "" {
+		testCase.ID = uuid.New().String()
+	}
+	return r.db.WithContext(ctx).Create(testCase).Error
+}
+
+// UpdateTestCase replaces a test case of a workflow
+func (r *WorkflowRepository) UpdateTestCase(ctx context.Context, testCase *workflow.WorkflowTestCase) error {
+	return r.db.WithContext(ctx).Save(testCase).Error
+}
+
+// ListTestCases returns the test cases of a workflow by name
+func (r *WorkflowRepository) ListTestCases(ctx context.Context, workflowID string) ([]*workflow.WorkflowTestCase, error) {
+	var testCases []*workflow.WorkflowTestCase
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ?", workflowID).
+		Order("name ASC").
+		Find(&testCases).Error
+	return testCases, err
+}
+
+// GetTestCase returns a test case of a workflow
+func (r *WorkflowRepository) GetTestCase(ctx context.Context, workflowID, caseID string) (*workflow.WorkflowTestCase, error) {
+	var testCase workflow.WorkflowTestCase
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND workflow_id = ?", caseID, workflowID).
+		First(&testCase).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, workflow.ErrTestCaseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &testCase, nil
+}
+
+// DeleteTestCase deletes a test case of a workflow
+func (r *WorkflowRepository) DeleteTestCase(ctx context.Context, workflowID, caseID string) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND workflow_id = ?", caseID, workflowID).
+		Delete(&workflow.WorkflowTestCase{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return workflow.ErrTestCaseNotFound
+	}
+	return nil
+}
+
+// CreateTestRun stores a test run of a workflow
+func (r *WorkflowRepository) CreateTestRun(ctx context.Context, run *workflow.WorkflowTestRun) error {
+	if run.ID == "" {
+		run.ID = uuid.New().String()
+	}
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+// ListTestRuns returns the latest test runs of a workflow, newest first
+func (r *WorkflowRepository) ListTestRuns(ctx context.Context, workflowID string, limit int) ([]*workflow.WorkflowTestRun, error) {
+	var runs []*workflow.WorkflowTestRun
+	err := r.db.WithContext(ctx).
+		Where("workflow_id = ?", workflowID).
+		Order("started_at DESC").
+		Limit(limit).
+		Find(&runs).Error
+	return runs, err
+}
+
+// GetTestRun returns a test run of a workflow
+func (r *WorkflowRepository) GetTestRun(ctx context.Context, workflowID, runID string) (*workflow.WorkflowTestRun, error) {
+	var run workflow.WorkflowTestRun
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND workflow_id = ?", runID, workflowID).
+		First(&run).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return nil, workflow.ErrTestRunNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
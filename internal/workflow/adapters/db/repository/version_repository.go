@@ -29,6 +29,10 @@ func (r *WorkflowVersionRepository) Create(ctx context.Context, wv *workflow.Wor
 
 // CreateFromWorkflow creates a new version from a workflow
 func (r *WorkflowVersionRepository) CreateFromWorkflow(ctx context.Context, w *workflow.Workflow, changeNote string) error {
+	if err := w.UpdateChecksum(); err != nil {
+		return err
+	}
+
 	workflowJSON, err := w.ToJSON()
 	if err != nil {
 		return err
@@ -39,6 +43,7 @@ func (r *WorkflowVersionRepository) CreateFromWorkflow(ctx context.Context, w *w
 		WorkflowID: w.ID,
 		Version:    w.Version,
 		Data:       workflowJSON,
+		Checksum:   w.Checksum,
 		ChangedBy:  w.UserID,
 		ChangeNote: changeNote,
 		CreatedAt:  time.Now(),
@@ -146,6 +151,13 @@ func (r *WorkflowVersionRepository) Compare(ctx context.Context, workflowID stri
 	comparison.ConnectionsAdded = countAddedConnections(w1.Connections, w2.Connections)
 	comparison.ConnectionsRemoved = countRemovedConnections(w1.Connections, w2.Connections)
 
+	// Compare annotations
+	for _, field := range workflow.ChangedFields(&w1, &w2) {
+		if field == "annotations" {
+			comparison.AnnotationsChanged = true
+		}
+	}
+
 	return comparison, nil
 }
 
@@ -164,6 +176,11 @@ func (r *WorkflowVersionRepository) Restore(ctx context.Context, workflowID stri
 			return err
 		}
 
+		// Never restore a definition that was altered after it was recorded
+		if err := verifyVersionChecksum(&wv, &restoredWorkflow); err != nil {
+			return err
+		}
+
 		// Get the latest version number
 		var latestVersion int
 		if err := tx.Model(&workflow.WorkflowVersion{}).
@@ -179,6 +196,7 @@ func (r *WorkflowVersionRepository) Restore(ctx context.Context, workflowID stri
 			WorkflowID: workflowID,
 			Version:    latestVersion + 1,
 			Data:       wv.Data,
+			Checksum:   restoredWorkflow.Checksum,
 			ChangedBy:  userID,
 			ChangeNote: fmt.Sprintf("Restored from version %d", versionToRestore),
 			CreatedAt:  time.Now(),
@@ -255,6 +273,7 @@ type VersionComparison struct {
 	NodesModified      int       `json:"nodesModified"`
 	ConnectionsAdded   int       `json:"connectionsAdded"`
 	ConnectionsRemoved int       `json:"connectionsRemoved"`
+	AnnotationsChanged bool      `json:"annotationsChanged"`
 }
 
 // Helper functions for version comparison
@@ -340,3 +359,19 @@ func countRemovedConnections(old, new []workflow.Connection) int {
 	}
 	return count
 }
+
+// verifyVersionChecksum checks a version snapshot against its recorded
+// checksum and stamps the parsed workflow with it. Versions recorded before
+// checksums existed are accepted and get a fresh checksum.
+func verifyVersionChecksum(wv *workflow.WorkflowVersion, w *workflow.Workflow) error {
+	checksum, err := w.ComputeChecksum()
+	if err != nil {
+		return err
+	}
+	if wv.Checksum != "" && wv.Checksum != checksum {
+		return workflow.ErrChecksumMismatch.WithDetail("version", wv.Version)
+	}
+
+	w.Checksum = checksum
+	return nil
+}
@@ -0,0 +1,256 @@
+package gitsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// Author of the commits pushed by LinkFlow
+const (
+	authorName  = "LinkFlow"
+	authorEmail = "linkflow@localhost"
+)
+
+// Client syncs workflow documents with Git repositories through the git
+// command. Each connection has its own checkout under the work directory,
+// reset to the head of its branch before every push or read, and used by
+// one operation at a time.
+type Client struct {
+	workDir string
+	timeout time.Duration
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewClient creates a client keeping its checkouts in workDir, it fails
+// when git is not installed
+func NewClient(workDir string, timeout time.Duration) (*Client, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git not found: %w", err)
+	}
+	if err := os.MkdirAll(workDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create git work directory: %w", err)
+	}
+
+	return &Client{
+		workDir: workDir,
+		timeout: timeout,
+		locks:   make(map[string]*sync.Mutex),
+	}, nil
+}
+
+// Push commits content at the path of a connection on top of its branch
+// and pushes it, creating the branch when the repository has none. It
+// returns the commit of the branch head, unchanged when the content
+// already is the one at the head.
+func (c *Client) Push(ctx context.Context, conn *workflow.GitConnection, token string, content []byte, message string) (string, error) {
+	unlock := c.lock(conn.ID)
+	defer unlock()
+
+	repo := c.repository(conn, token)
+	exists, err := repo.checkout(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	file := filepath.Join(repo.dir, filepath.FromSlash(conn.Path))
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return "", fmt.Errorf("failed to create document directory: %w", err)
+	}
+	if err := os.WriteFile(file, content, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write document: %w", err)
+	}
+
+	if _, err := repo.git(ctx, "add", "--", conn.Path); err != nil {
+		return "", err
+	}
+	// diff --cached --quiet exits with 1 when something is staged
+	if _, err := repo.git(ctx, "diff", "--cached", "--quiet"); err == nil && exists {
+		return repo.head(ctx)
+	}
+
+	if _, err := repo.git(ctx, "commit", "-q", "--no-verify", "-m", message); err != nil {
+		return "", err
+	}
+	if _, err := repo.git(ctx, "push", "-q", "origin", "HEAD:refs/heads/"+conn.Branch); err != nil {
+		return "", err
+	}
+
+	return repo.head(ctx)
+}
+
+// Read returns the document at the path of a connection on the head of
+// its branch, with the commit of that head
+func (c *Client) Read(ctx context.Context, conn *workflow.GitConnection, token string) ([]byte, string, error) {
+	unlock := c.lock(conn.ID)
+	defer unlock()
+
+	repo := c.repository(conn, token)
+	exists, err := repo.checkout(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if !exists {
+		return nil, "", workflow.ErrGitDocumentNotFound.WithMessage("branch %s does not exist", conn.Branch)
+	}
+
+	content, err := os.ReadFile(filepath.Join(repo.dir, filepath.FromSlash(conn.Path)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", workflow.ErrGitDocumentNotFound.WithMessage("%s not found on %s", conn.Path, conn.Branch)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read document: %w", err)
+	}
+
+	commit, err := repo.head(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return content, commit, nil
+}
+
+// Remove deletes the checkout of a connection
+func (c *Client) Remove(connectionID string) error {
+	unlock := c.lock(connectionID)
+	defer unlock()
+
+	return os.RemoveAll(filepath.Join(c.workDir, connectionID))
+}
+
+// lock serializes the operations on the checkout of a connection
+func (c *Client) lock(connectionID string) func() {
+	c.mu.Lock()
+	lock, ok := c.locks[connectionID]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.locks[connectionID] = lock
+	}
+	c.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+func (c *Client) repository(conn *workflow.GitConnection, token string) *repository {
+	return &repository{
+		dir:     filepath.Join(c.workDir, conn.ID),
+		url:     conn.RepositoryURL,
+		branch:  conn.Branch,
+		token:   token,
+		timeout: c.timeout,
+	}
+}
+
+// repository is the checkout of a connection
+type repository struct {
+	dir     string
+	url     string
+	branch  string
+	token   string
+	timeout time.Duration
+}
+
+// checkout resets the checkout to the head of the branch, initializing it
+// on first use. It reports whether the branch exists; when it does not the
+// checkout is left empty on an unborn branch.
+func (r *repository) checkout(ctx context.Context) (bool, error) {
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); err != nil {
+		if err := os.MkdirAll(r.dir, 0o700); err != nil {
+			return false, fmt.Errorf("failed to create checkout: %w", err)
+		}
+		if _, err := r.git(ctx, "init", "-q"); err != nil {
+			return false, err
+		}
+		if _, err := r.git(ctx, "remote", "add", "origin", r.url); err != nil {
+			return false, err
+		}
+	} else if _, err := r.git(ctx, "remote", "set-url", "origin", r.url); err != nil {
+		return false, err
+	}
+
+	heads, err := r.git(ctx, "ls-remote", "--heads", "origin", "refs/heads/"+r.branch)
+	if err != nil {
+		return false, err
+	}
+
+	if heads == "" {
+		// An empty repository or a branch yet to be created
+		if _, err := r.git(ctx, "symbolic-ref", "HEAD", "refs/heads/"+r.branch); err != nil {
+			return false, err
+		}
+		if _, err := r.git(ctx, "read-tree", "--empty"); err != nil {
+			return false, err
+		}
+		if _, err := r.git(ctx, "clean", "-q", "-f", "-d", "-x"); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	if _, err := r.git(ctx, "fetch", "-q", "--depth", "1", "origin", "refs/heads/"+r.branch); err != nil {
+		return false, err
+	}
+	if _, err := r.git(ctx, "checkout", "-q", "-f", "-B", r.branch, "FETCH_HEAD"); err != nil {
+		return false, err
+	}
+	if _, err := r.git(ctx, "clean", "-q", "-f", "-d", "-x"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *repository) head(ctx context.Context) (string, error) {
+	return r.git(ctx, "rev-parse", "HEAD")
+}
+
+// git runs a git command in the checkout and returns its trimmed output.
+// The token is passed through the environment, never on the command line
+// or in the configuration of the checkout.
+func (r *repository) git(ctx context.Context, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_SSH_COMMAND=ssh -o BatchMode=yes",
+		"GIT_AUTHOR_NAME="+authorName,
+		"GIT_AUTHOR_EMAIL="+authorEmail,
+		"GIT_COMMITTER_NAME="+authorName,
+		"GIT_COMMITTER_EMAIL="+authorEmail,
+	)
+	if r.token != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + r.token))
+		cmd.Env = append(cmd.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", workflow.ErrGitSyncFailed.WithMessage("git %s: %s", args[0], message)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -1,18 +1,29 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/internal/workflow/adapters/templates"
 	"github.com/linkflow-go/internal/workflow/app/service"
+	"github.com/linkflow-go/pkg/auth/signedurl"
 	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/database"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/logger"
+	authmw "github.com/linkflow-go/pkg/middleware/auth"
 )
 
 type WorkflowHandlers struct {
 	service *service.WorkflowService
 	logger  logger.Logger
+	signer  *signedurl.Signer
 }
 
 func NewWorkflowHandlers(service *service.WorkflowService, logger logger.Logger) *WorkflowHandlers {
@@ -22,38 +33,34 @@ func NewWorkflowHandlers(service *service.WorkflowService, logger logger.Logger)
 	}
 }
 
+// SetURLSigner enables signed download links for workflow exports
+func (h *WorkflowHandlers) SetURLSigner(signer *signedurl.Signer) {
+	h.signer = signer
+}
+
 // Health check handlers
 func (h *WorkflowHandlers) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
 
-func (h *WorkflowHandlers) Ready(c *gin.Context) {
-	if err := h.service.CheckReady(); err != nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, gin.H{"status": "ready"})
-}
-
 // Workflow CRUD
 func (h *WorkflowHandlers) ListWorkflows(c *gin.Context) {
 	userID := c.GetString("user_id")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page := database.NewCursorPage(limit, c.Query("cursor"))
 	status := c.Query("status")
 
-	workflows, total, err := h.service.ListWorkflows(c.Request.Context(), userID, page, limit, status)
+	workflows, total, err := h.service.ListWorkflows(c.Request.Context(), userID, status, page)
 	if err != nil {
-		h.logger.Error("Failed to list workflows", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list workflows"})
+		h.respondError(c, err, "Failed to list workflows")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"workflows": workflows,
-		"total":     total,
-		"page":      page,
-		"limit":     limit,
+		"workflows":  workflows,
+		"total":      total,
+		"limit":      page.Limit,
+		"nextCursor": page.Next,
 	})
 }
 
@@ -63,12 +70,7 @@ func (h *WorkflowHandlers) GetWorkflow(c *gin.Context) {
 
 	workflow, err := h.service.GetWorkflow(c.Request.Context(), workflowID, userID)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to get workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workflow"})
+		h.respondError(c, err, "Failed to get workflow")
 		return
 	}
 
@@ -78,20 +80,16 @@ func (h *WorkflowHandlers) GetWorkflow(c *gin.Context) {
 func (h *WorkflowHandlers) CreateWorkflow(c *gin.Context) {
 	var req workflow.CreateWorkflowRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	req.UserID = c.GetString("user_id")
+	req.WorkspaceID = c.GetString("workspace_id")
 
 	workflow, err := h.service.CreateWorkflow(c.Request.Context(), &req)
 	if err != nil {
-		if err == service.ErrInvalidWorkflow {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		h.logger.Error("Failed to create workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workflow"})
+		h.respondError(c, err, "Failed to create workflow")
 		return
 	}
 
@@ -104,7 +102,7 @@ func (h *WorkflowHandlers) UpdateWorkflow(c *gin.Context) {
 
 	var req workflow.UpdateWorkflowRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
@@ -113,37 +111,59 @@ func (h *WorkflowHandlers) UpdateWorkflow(c *gin.Context) {
 
 	workflow, err := h.service.UpdateWorkflow(c.Request.Context(), &req)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		if err == service.ErrUnauthorized {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		h.logger.Error("Failed to update workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workflow"})
+		h.respondError(c, err, "Failed to update workflow")
 		return
 	}
 
 	c.JSON(http.StatusOK, workflow)
 }
 
+// JoinEditSession starts or renews the edit session of the caller, claiming
+// the nodes open in the editor
+func (h *WorkflowHandlers) JoinEditSession(c *gin.Context) {
+	var req workflow.EditSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	session, err := h.service.JoinEditSession(c.Request.Context(), c.Param("id"), c.GetString("user_id"), req)
+	if err != nil {
+		h.respondError(c, err, "Failed to join edit session")
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// LeaveEditSession ends the edit session of the caller
+func (h *WorkflowHandlers) LeaveEditSession(c *gin.Context) {
+	if err := h.service.LeaveEditSession(c.Request.Context(), c.Param("id"), c.GetString("user_id")); err != nil {
+		h.respondError(c, err, "Failed to leave edit session")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListEditors lists the users editing a workflow and the nodes they have
+// open
+func (h *WorkflowHandlers) ListEditors(c *gin.Context) {
+	editors, err := h.service.ListEditors(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to list editors")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"editors": editors})
+}
+
 func (h *WorkflowHandlers) DeleteWorkflow(c *gin.Context) {
 	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
 
 	if err := h.service.DeleteWorkflow(c.Request.Context(), workflowID, userID); err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		if err == service.ErrUnauthorized {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		h.logger.Error("Failed to delete workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete workflow"})
+		h.respondError(c, err, "Failed to delete workflow")
 		return
 	}
 
@@ -157,18 +177,32 @@ func (h *WorkflowHandlers) GetWorkflowVersions(c *gin.Context) {
 
 	versions, err := h.service.GetWorkflowVersions(c.Request.Context(), workflowID, userID)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to get workflow versions", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workflow versions"})
+		h.respondError(c, err, "Failed to get workflow versions")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"versions": versions})
 }
 
+// ListWorkflowChanges lists who changed what in a workflow, save by save,
+// optionally those of one user
+func (h *WorkflowHandlers) ListWorkflowChanges(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page := database.NewCursorPage(limit, c.Query("cursor"))
+
+	changes, err := h.service.ListWorkflowChanges(c.Request.Context(), c.Param("id"), c.GetString("user_id"), c.Query("userId"), page)
+	if err != nil {
+		h.respondError(c, err, "Failed to list workflow changes")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"changes":    changes,
+		"limit":      page.Limit,
+		"nextCursor": page.Next,
+	})
+}
+
 func (h *WorkflowHandlers) GetWorkflowVersion(c *gin.Context) {
 	workflowID := c.Param("id")
 	version, _ := strconv.Atoi(c.Param("version"))
@@ -176,12 +210,7 @@ func (h *WorkflowHandlers) GetWorkflowVersion(c *gin.Context) {
 
 	workflow, err := h.service.GetWorkflowVersion(c.Request.Context(), workflowID, version, userID)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow version not found"})
-			return
-		}
-		h.logger.Error("Failed to get workflow version", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workflow version"})
+		h.respondError(c, err, "Failed to get workflow version")
 		return
 	}
 
@@ -194,18 +223,13 @@ func (h *WorkflowHandlers) CreateWorkflowVersion(c *gin.Context) {
 
 	var req workflow.CreateVersionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	version, err := h.service.CreateWorkflowVersion(c.Request.Context(), workflowID, userID, &req)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to create workflow version", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create workflow version"})
+		h.respondError(c, err, "Failed to create workflow version")
 		return
 	}
 
@@ -217,17 +241,13 @@ func (h *WorkflowHandlers) RollbackWorkflowVersion(c *gin.Context) {
 	version, _ := strconv.Atoi(c.Param("version"))
 	userID := c.GetString("user_id")
 
-	if err := h.service.RollbackWorkflowVersion(c.Request.Context(), workflowID, version, userID); err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow version not found"})
-			return
-		}
-		h.logger.Error("Failed to rollback workflow version", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rollback workflow version"})
+	workflow, err := h.service.RollbackWorkflowVersion(c.Request.Context(), workflowID, version, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to rollback workflow version")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Workflow rolled back successfully"})
+	c.JSON(http.StatusOK, workflow)
 }
 
 // Workflow operations
@@ -235,34 +255,47 @@ func (h *WorkflowHandlers) ActivateWorkflow(c *gin.Context) {
 	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	if err := h.service.ActivateWorkflow(c.Request.Context(), workflowID, userID); err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to activate workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to activate workflow"})
+	override, err := activationOverride(c)
+	if err != nil {
+		h.respondError(c, err, "Failed to activate workflow")
+		return
+	}
+
+	workflow, err := h.service.ActivateWorkflow(c.Request.Context(), workflowID, userID, override)
+	if err != nil {
+		h.respondError(c, err, "Failed to activate workflow")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Workflow activated"})
+	c.JSON(http.StatusOK, workflow)
+}
+
+// activationOverride reports whether a request asks to override the
+// activation policy with ?override=true, which only platform admins may
+func activationOverride(c *gin.Context) (bool, error) {
+	if c.Query("override") != "true" {
+		return false, nil
+	}
+	roles, _ := authmw.GetUserRoles(c)
+	for _, role := range roles {
+		if role == "admin" || role == "super_admin" {
+			return true, nil
+		}
+	}
+	return false, workflow.ErrOverrideDenied
 }
 
 func (h *WorkflowHandlers) DeactivateWorkflow(c *gin.Context) {
 	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	if err := h.service.DeactivateWorkflow(c.Request.Context(), workflowID, userID); err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to deactivate workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate workflow"})
+	workflow, err := h.service.DeactivateWorkflow(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to deactivate workflow")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Workflow deactivated"})
+	c.JSON(http.StatusOK, workflow)
 }
 
 func (h *WorkflowHandlers) DuplicateWorkflow(c *gin.Context) {
@@ -273,18 +306,13 @@ func (h *WorkflowHandlers) DuplicateWorkflow(c *gin.Context) {
 		Name string `json:"name" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	workflow, err := h.service.DuplicateWorkflow(c.Request.Context(), workflowID, userID, req.Name)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to duplicate workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to duplicate workflow"})
+		h.respondError(c, err, "Failed to duplicate workflow")
 		return
 	}
 
@@ -295,48 +323,86 @@ func (h *WorkflowHandlers) ValidateWorkflow(c *gin.Context) {
 	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	errors, warnings, err := h.service.ValidateWorkflow(c.Request.Context(), workflowID, userID)
+	result, err := h.service.ValidateWorkflow(c.Request.Context(), workflowID, userID)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to validate workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate workflow"})
+		h.respondError(c, err, "Failed to validate workflow")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"valid":    len(errors) == 0,
-		"errors":   errors,
-		"warnings": warnings,
+		"valid":       result.Valid,
+		"errors":      result.Errors,
+		"warnings":    result.Warnings,
+		"diagnostics": result.Diagnostics,
 	})
 }
 
+// LintWorkflow reports the lint findings of a workflow
+func (h *WorkflowHandlers) LintWorkflow(c *gin.Context) {
+	report, err := h.service.LintWorkflow(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to lint workflow")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ListOutputSchemas lists the output schemas inferred for the nodes of a
+// workflow by its test runs
+func (h *WorkflowHandlers) ListOutputSchemas(c *gin.Context) {
+	schemas, err := h.service.ListOutputSchemas(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to list output schemas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schemas": schemas})
+}
+
+// PreviewTransform shows the output of a transform node for sample inputs
+func (h *WorkflowHandlers) PreviewTransform(c *gin.Context) {
+	var req struct {
+		Samples    []map[string]interface{} `json:"samples" binding:"required,min=1"`
+		Parameters map[string]interface{}   `json:"parameters"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	preview, err := h.service.PreviewTransform(c.Request.Context(), c.Param("id"), c.Param("nodeId"),
+		c.GetString("user_id"), req.Parameters, req.Samples)
+	if err != nil {
+		h.respondError(c, err, "Failed to preview transform")
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
 func (h *WorkflowHandlers) ExecuteWorkflow(c *gin.Context) {
 	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
 
 	var req struct {
-		Data map[string]interface{} `json:"data"`
+		Data        map[string]interface{} `json:"data"`
+		Debug       bool                   `json:"debug"`
+		Environment string                 `json:"environment"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
-	executionID, err := h.service.ExecuteWorkflow(c.Request.Context(), workflowID, userID, req.Data)
+	ctx := c.Request.Context()
+	if req.Debug {
+		ctx = logger.WithDebug(ctx)
+	}
+
+	executionID, err := h.service.ExecuteWorkflow(ctx, workflowID, userID, req.Environment, req.Data)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		if err == service.ErrWorkflowInactive {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Workflow is inactive"})
-			return
-		}
-		h.logger.Error("Failed to execute workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to execute workflow"})
+		h.respondError(c, err, "Failed to execute workflow")
 		return
 	}
 
@@ -350,22 +416,20 @@ func (h *WorkflowHandlers) TestWorkflow(c *gin.Context) {
 	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	var req struct {
-		Data map[string]interface{} `json:"data"`
-	}
+	var req workflow.TestWorkflowRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
-	result, err := h.service.TestWorkflow(c.Request.Context(), workflowID, userID, req.Data)
+	ctx := c.Request.Context()
+	if req.Debug {
+		ctx = logger.WithDebug(ctx)
+	}
+
+	result, err := h.service.TestWorkflow(ctx, workflowID, userID, req)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to test workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to test workflow"})
+		h.respondError(c, err, "Failed to test workflow")
 		return
 	}
 
@@ -379,12 +443,7 @@ func (h *WorkflowHandlers) GetWorkflowPermissions(c *gin.Context) {
 
 	permissions, err := h.service.GetWorkflowPermissions(c.Request.Context(), workflowID, userID)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to get workflow permissions", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workflow permissions"})
+		h.respondError(c, err, "Failed to get workflow permissions")
 		return
 	}
 
@@ -400,21 +459,28 @@ func (h *WorkflowHandlers) ShareWorkflow(c *gin.Context) {
 		Permission string `json:"permission" binding:"required,oneof=view edit admin"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	if err := h.service.ShareWorkflow(c.Request.Context(), workflowID, userID, req.UserID, req.Permission); err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to share workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share workflow"})
+		h.respondError(c, err, "Failed to share workflow")
+		return
+	}
+
+	h.respondPermissions(c, workflowID, userID)
+}
+
+// respondPermissions responds with the permissions of a workflow after they
+// changed, so clients need not fetch them again
+func (h *WorkflowHandlers) respondPermissions(c *gin.Context, workflowID, userID string) {
+	permissions, err := h.service.GetWorkflowPermissions(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get workflow permissions")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Workflow shared successfully"})
+	c.JSON(http.StatusOK, gin.H{"permissions": permissions})
 }
 
 func (h *WorkflowHandlers) UnshareWorkflow(c *gin.Context) {
@@ -423,16 +489,11 @@ func (h *WorkflowHandlers) UnshareWorkflow(c *gin.Context) {
 	userID := c.GetString("user_id")
 
 	if err := h.service.UnshareWorkflow(c.Request.Context(), workflowID, userID, targetUserID); err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to unshare workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unshare workflow"})
+		h.respondError(c, err, "Failed to unshare workflow")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Workflow unshared successfully"})
+	h.respondPermissions(c, workflowID, userID)
 }
 
 func (h *WorkflowHandlers) PublishWorkflow(c *gin.Context) {
@@ -444,21 +505,17 @@ func (h *WorkflowHandlers) PublishWorkflow(c *gin.Context) {
 		Tags        []string `json:"tags"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
-	if err := h.service.PublishWorkflow(c.Request.Context(), workflowID, userID, req.Description, req.Tags); err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to publish workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to publish workflow"})
+	template, err := h.service.PublishWorkflow(c.Request.Context(), workflowID, userID, req.Description, req.Tags)
+	if err != nil {
+		h.respondError(c, err, "Failed to publish workflow")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Workflow published successfully"})
+	c.JSON(http.StatusOK, template)
 }
 
 // Workflow templates
@@ -467,8 +524,7 @@ func (h *WorkflowHandlers) ListTemplates(c *gin.Context) {
 
 	templates, err := h.service.ListTemplates(c.Request.Context(), category)
 	if err != nil {
-		h.logger.Error("Failed to list templates", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list templates"})
+		h.respondError(c, err, "Failed to list templates")
 		return
 	}
 
@@ -480,12 +536,7 @@ func (h *WorkflowHandlers) GetTemplate(c *gin.Context) {
 
 	template, err := h.service.GetTemplate(c.Request.Context(), templateID)
 	if err != nil {
-		if err == service.ErrTemplateNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
-			return
-		}
-		h.logger.Error("Failed to get template", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get template"})
+		h.respondError(c, err, "Failed to get template")
 		return
 	}
 
@@ -497,7 +548,7 @@ func (h *WorkflowHandlers) CreateTemplate(c *gin.Context) {
 
 	var req workflow.CreateTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
@@ -505,8 +556,7 @@ func (h *WorkflowHandlers) CreateTemplate(c *gin.Context) {
 
 	template, err := h.service.CreateTemplate(c.Request.Context(), &req)
 	if err != nil {
-		h.logger.Error("Failed to create template", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
+		h.respondError(c, err, "Failed to create template")
 		return
 	}
 
@@ -522,388 +572,1576 @@ func (h *WorkflowHandlers) CreateFromTemplate(c *gin.Context) {
 		Variables map[string]interface{} `json:"variables"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	workflow, err := h.service.CreateFromTemplate(c.Request.Context(), templateID, userID, req.Name, req.Variables)
 	if err != nil {
-		if err == service.ErrTemplateNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
-			return
-		}
-		h.logger.Error("Failed to create from template", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create from template"})
+		h.respondError(c, err, "Failed to create from template")
 		return
 	}
 
 	c.JSON(http.StatusCreated, workflow)
 }
 
-// Workflow import/export
-func (h *WorkflowHandlers) ImportWorkflow(c *gin.Context) {
+// SearchTemplates searches the template marketplace, ?q= over the names,
+// descriptions and tags of templates, sorted by ?sort=relevance, trending,
+// most_used, top_rated or newest
+func (h *WorkflowHandlers) SearchTemplates(c *gin.Context) {
+	page, limit := templatePage(c)
+
+	found, total, err := h.service.SearchTemplates(c.Request.Context(), templates.SearchQuery{
+		Text:     c.Query("q"),
+		Category: c.Query("category"),
+		Tag:      c.Query("tag"),
+		Sort:     c.Query("sort"),
+		Limit:    limit,
+		Offset:   (page - 1) * limit,
+	})
+	if err != nil {
+		h.respondError(c, err, "Failed to search templates")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": found,
+		"total":     total,
+		"page":      page,
+		"limit":     limit,
+	})
+}
+
+// RateTemplate rates a marketplace template for the user, replacing their
+// previous review of it
+func (h *WorkflowHandlers) RateTemplate(c *gin.Context) {
 	userID := c.GetString("user_id")
 
 	var req struct {
-		Data   interface{} `json:"data" binding:"required"`
-		Format string      `json:"format" binding:"required,oneof=json yaml n8n"`
+		Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+		Comment string `json:"comment" binding:"max=2000"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
-	workflow, err := h.service.ImportWorkflow(c.Request.Context(), userID, req.Data, req.Format)
+	review, err := h.service.RateTemplate(c.Request.Context(), c.Param("id"), userID, req.Rating, req.Comment)
 	if err != nil {
-		h.logger.Error("Failed to import workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import workflow"})
+		h.respondError(c, err, "Failed to rate template")
 		return
 	}
 
-	c.JSON(http.StatusCreated, workflow)
+	c.JSON(http.StatusOK, review)
 }
 
-func (h *WorkflowHandlers) ExportWorkflow(c *gin.Context) {
-	workflowID := c.Param("id")
-	userID := c.GetString("user_id")
-	format := c.DefaultQuery("format", "json")
+// ListTemplateReviews lists a page of the reviews of a marketplace template,
+// newest first
+func (h *WorkflowHandlers) ListTemplateReviews(c *gin.Context) {
+	page, limit := templatePage(c)
 
-	data, err := h.service.ExportWorkflow(c.Request.Context(), workflowID, userID, format)
+	reviews, err := h.service.ListTemplateReviews(c.Request.Context(), c.Param("id"), limit, (page-1)*limit)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to export workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export workflow"})
+		h.respondError(c, err, "Failed to list template reviews")
 		return
 	}
 
-	c.JSON(http.StatusOK, data)
+	c.JSON(http.StatusOK, gin.H{
+		"reviews": reviews,
+		"page":    page,
+		"limit":   limit,
+	})
 }
 
-// Workflow statistics
-func (h *WorkflowHandlers) GetWorkflowStats(c *gin.Context) {
-	workflowID := c.Param("id")
-	userID := c.GetString("user_id")
-
-	stats, err := h.service.GetWorkflowStats(c.Request.Context(), workflowID, userID)
+// ListTemplateModeration lists the public templates awaiting moderation,
+// or those in the ?status= given
+func (h *WorkflowHandlers) ListTemplateModeration(c *gin.Context) {
+	found, err := h.service.ListTemplateModeration(c.Request.Context(), c.DefaultQuery("status", templates.ModerationPending))
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to get workflow stats", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workflow stats"})
+		h.respondError(c, err, "Failed to list templates to moderate")
 		return
 	}
 
-	c.JSON(http.StatusOK, stats)
+	c.JSON(http.StatusOK, gin.H{"templates": found})
 }
 
-func (h *WorkflowHandlers) GetWorkflowExecutions(c *gin.Context) {
-	workflowID := c.Param("id")
-	userID := c.GetString("user_id")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+// ModerateTemplate approves a public template into the marketplace or
+// rejects it with a note for its creator
+func (h *WorkflowHandlers) ModerateTemplate(c *gin.Context) {
+	var req struct {
+		Status string `json:"status" binding:"required,oneof=approved rejected"`
+		Note   string `json:"note" binding:"max=2000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
 
-	executions, total, err := h.service.GetWorkflowExecutions(c.Request.Context(), workflowID, userID, page, limit)
+	template, err := h.service.ModerateTemplate(c.Request.Context(), c.Param("id"), req.Status, req.Note)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to get workflow executions", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workflow executions"})
+		h.respondError(c, err, "Failed to moderate template")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"executions": executions,
-		"total":      total,
-		"page":       page,
-		"limit":      limit,
-	})
+	c.JSON(http.StatusOK, template)
 }
 
-func (h *WorkflowHandlers) GetLatestRun(c *gin.Context) {
-	workflowID := c.Param("id")
+// PublishTemplateVersion publishes a new version of a template created by
+// the user
+func (h *WorkflowHandlers) PublishTemplateVersion(c *gin.Context) {
 	userID := c.GetString("user_id")
 
-	execution, err := h.service.GetLatestRun(c.Request.Context(), workflowID, userID)
+	var req struct {
+		Workflow  workflow.Workflow    `json:"workflow"`
+		Variables []templates.Variable `json:"variables"`
+		Changelog string               `json:"changelog" binding:"max=5000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	version, err := h.service.PublishTemplateVersion(c.Request.Context(), c.Param("id"), userID, &req.Workflow, req.Variables, req.Changelog)
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to get latest run", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get latest run"})
+		h.respondError(c, err, "Failed to publish template version")
 		return
 	}
 
-	c.JSON(http.StatusOK, execution)
+	c.JSON(http.StatusCreated, version)
 }
 
-// Categories and tags
-func (h *WorkflowHandlers) ListCategories(c *gin.Context) {
-	categories, err := h.service.ListCategories(c.Request.Context())
+// ListTemplateVersions lists the versions of a template, newest first
+func (h *WorkflowHandlers) ListTemplateVersions(c *gin.Context) {
+	versions, err := h.service.ListTemplateVersions(c.Request.Context(), c.Param("id"))
 	if err != nil {
-		h.logger.Error("Failed to list categories", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list categories"})
+		h.respondError(c, err, "Failed to list template versions")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"categories": categories})
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
 }
 
-func (h *WorkflowHandlers) CreateCategory(c *gin.Context) {
-	var req struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
-		Icon        string `json:"icon"`
-	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+// GetTemplateUpgrade shows whether the template of a workflow has a newer
+// version and how upgrading to it changes the workflow
+func (h *WorkflowHandlers) GetTemplateUpgrade(c *gin.Context) {
+	userID := c.GetString("user_id")
 
-	category, err := h.service.CreateCategory(c.Request.Context(), req.Name, req.Description, req.Icon)
+	upgrade, err := h.service.GetTemplateUpgrade(c.Request.Context(), c.Param("id"), userID)
 	if err != nil {
-		h.logger.Error("Failed to create category", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
+		h.respondError(c, err, "Failed to get template upgrade")
 		return
 	}
 
-	c.JSON(http.StatusCreated, category)
+	c.JSON(http.StatusOK, upgrade)
 }
 
-func (h *WorkflowHandlers) SearchWorkflows(c *gin.Context) {
+// UpgradeFromTemplate upgrades a workflow to the latest version of its
+// template, with the values of the variables missing or to change
+func (h *WorkflowHandlers) UpgradeFromTemplate(c *gin.Context) {
 	userID := c.GetString("user_id")
-	query := c.Query("q")
-	category := c.Query("category")
-	tags := c.QueryArray("tags")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
-	workflows, total, err := h.service.SearchWorkflows(c.Request.Context(), userID, query, category, tags, page, limit)
+	var req struct {
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+			return
+		}
+	}
+
+	wf, err := h.service.UpgradeFromTemplate(c.Request.Context(), c.Param("id"), userID, req.Variables)
 	if err != nil {
-		h.logger.Error("Failed to search workflows", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search workflows"})
+		h.respondError(c, err, "Failed to upgrade workflow from template")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"workflows": workflows,
-		"total":     total,
-		"page":      page,
-		"limit":     limit,
-	})
+	c.JSON(http.StatusOK, wf)
 }
 
-func (h *WorkflowHandlers) GetPopularTags(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+// maxTemplateBundle bounds the bundles imported
+const maxTemplateBundle = 16 << 20
 
-	tags, err := h.service.GetPopularTags(c.Request.Context(), limit)
+// ExportTemplateBundle downloads a template as a signed bundle, a gzipped
+// tarball or with ?format=json a JSON document
+func (h *WorkflowHandlers) ExportTemplateBundle(c *gin.Context) {
+	userID := c.GetString("user_id")
+	format := c.DefaultQuery("format", templates.BundleFormatTar)
+
+	data, name, err := h.service.ExportTemplateBundle(c.Request.Context(), c.Param("id"), userID, format)
 	if err != nil {
-		h.logger.Error("Failed to get popular tags", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get popular tags"})
+		h.respondError(c, err, "Failed to export template bundle")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"tags": tags})
+	h.sendTemplateBundle(c, data, name, format)
+}
+
+// ExportWorkflowBundle downloads a workflow as the signed bundle of a
+// template
+func (h *WorkflowHandlers) ExportWorkflowBundle(c *gin.Context) {
+	userID := c.GetString("user_id")
+	format := c.DefaultQuery("format", templates.BundleFormatTar)
+
+	data, name, err := h.service.ExportWorkflowBundle(c.Request.Context(), c.Param("id"), userID, format)
+	if err != nil {
+		h.respondError(c, err, "Failed to export workflow bundle")
+		return
+	}
+
+	h.sendTemplateBundle(c, data, name, format)
+}
+
+// sendTemplateBundle sends a bundle as an attachment named after its
+// template
+func (h *WorkflowHandlers) sendTemplateBundle(c *gin.Context, data []byte, name, format string) {
+	contentType := "application/gzip"
+	if format == templates.BundleFormatJSON {
+		contentType = "application/json"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", templates.BundleFileName(name, format)))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// ImportTemplateBundle creates a private template from a bundle exported
+// by a trusted deployment, the request body. ?name= renames the template.
+func (h *WorkflowHandlers) ImportTemplateBundle(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxTemplateBundle))
+	if err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	imported, err := h.service.ImportTemplateBundle(c.Request.Context(), data, userID, c.Query("name"))
+	if err != nil {
+		h.respondError(c, err, "Failed to import template bundle")
+		return
+	}
+
+	c.JSON(http.StatusCreated, imported)
+}
+
+// templatePage reads ?page= from 1 and ?limit= up to 100
+func templatePage(c *gin.Context) (page, limit int) {
+	page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ = strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+// Workflow import/export
+func (h *WorkflowHandlers) ImportWorkflow(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req struct {
+		Data   interface{} `json:"data" binding:"required"`
+		Format string      `json:"format" binding:"required,oneof=json yaml n8n"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	workflow, err := h.service.ImportWorkflow(c.Request.Context(), userID, req.Data, req.Format)
+	if err != nil {
+		h.respondError(c, err, "Failed to import workflow")
+		return
+	}
+
+	c.JSON(http.StatusCreated, workflow)
+}
+
+// Apply reconciles the workflows of a namespace with a declared bundle,
+// returning the plan of its changes. ?dryRun=true only returns the plan.
+func (h *WorkflowHandlers) Apply(c *gin.Context) {
+	userID := c.GetString("user_id")
+	workspaceID := c.GetString("workspace_id")
+
+	var bundle workflow.ApplyBundle
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	plan, err := h.service.Apply(c.Request.Context(), userID, workspaceID, &bundle, c.Query("dryRun") == "true")
+	if err != nil {
+		h.respondError(c, err, "Failed to apply bundle")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h *WorkflowHandlers) ExportWorkflow(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+	format := c.DefaultQuery("format", "json")
+
+	data, err := h.service.ExportWorkflow(c.Request.Context(), workflowID, userID, format)
+	if err != nil {
+		h.respondError(c, err, "Failed to export workflow")
+		return
+	}
+
+	c.JSON(http.StatusOK, data)
+}
+
+// CreateExportURL returns a time-limited link that downloads the export
+// without credentials, so large exports do not go through the API call
+func (h *WorkflowHandlers) CreateExportURL(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+	format := c.DefaultQuery("format", "json")
+
+	if h.signer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Signed URLs are not configured"})
+		return
+	}
+
+	// Fail early instead of handing out a link to nothing
+	if _, err := h.service.GetWorkflow(c.Request.Context(), workflowID, userID); err != nil {
+		h.respondError(c, err, "Failed to create export URL")
+		return
+	}
+
+	query := url.Values{}
+	query.Set("format", format)
+	query.Set("user", userID)
+	path := fmt.Sprintf("/api/v1/workflows/exports/%s?%s", url.PathEscape(workflowID), query.Encode())
+
+	signed, expiresAt, err := h.signer.SignFor(path)
+	if err != nil {
+		h.respondError(c, err, "Failed to create export URL")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"url": signed, "expiresAt": expiresAt})
+}
+
+// DownloadExport serves an export through a signed URL. The user is taken
+// from the signed query, the middleware has verified it was not altered.
+func (h *WorkflowHandlers) DownloadExport(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.Query("user")
+	format := c.DefaultQuery("format", "json")
+
+	data, err := h.service.ExportWorkflow(c.Request.Context(), workflowID, userID, format)
+	if err != nil {
+		h.respondError(c, err, "Failed to export workflow")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("workflow-%s-%s.json", workflowID, format)))
+	c.JSON(http.StatusOK, data)
+}
+
+// CheckIntegrity verifies stored definitions against their recorded checksums
+func (h *WorkflowHandlers) CheckIntegrity(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	report, err := h.service.CheckIntegrity(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to check workflow integrity")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// StartCanary routes a share of executions to the current workflow version
+func (h *WorkflowHandlers) StartCanary(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	var req workflow.StartCanaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	canary, err := h.service.StartCanary(c.Request.Context(), workflowID, userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to start canary")
+		return
+	}
+
+	c.JSON(http.StatusCreated, canary)
+}
+
+// GetCanary returns the running canary with the stats of both versions
+func (h *WorkflowHandlers) GetCanary(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	report, err := h.service.GetCanary(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get canary")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// PromoteCanary keeps the new version for all executions
+func (h *WorkflowHandlers) PromoteCanary(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	canary, err := h.service.PromoteCanary(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to promote canary")
+		return
+	}
+
+	c.JSON(http.StatusOK, canary)
+}
+
+// RollbackCanary restores the stable version
+func (h *WorkflowHandlers) RollbackCanary(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	canary, err := h.service.RollbackCanary(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to roll back canary")
+		return
+	}
+
+	c.JSON(http.StatusOK, canary)
+}
+
+// SetCanaryTraffic shifts the share of executions running the new version
+func (h *WorkflowHandlers) SetCanaryTraffic(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	var req workflow.CanaryTrafficRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	canary, err := h.service.SetCanaryTraffic(c.Request.Context(), workflowID, userID, *req.Percentage)
+	if err != nil {
+		h.respondError(c, err, "Failed to shift canary traffic")
+		return
+	}
+
+	c.JSON(http.StatusOK, canary)
+}
+
+// CancelCanary stops the canary without a decision
+func (h *WorkflowHandlers) CancelCanary(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	canary, err := h.service.CancelCanary(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to cancel canary")
+		return
+	}
+
+	c.JSON(http.StatusOK, canary)
+}
+
+// StartShadow runs a stored version next to live executions for comparison
+func (h *WorkflowHandlers) StartShadow(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	var req workflow.StartShadowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	shadow, err := h.service.StartShadow(c.Request.Context(), workflowID, userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to start shadow")
+		return
+	}
+
+	c.JSON(http.StatusCreated, shadow)
+}
+
+// GetShadowReport summarizes divergences of the latest shadow
+func (h *WorkflowHandlers) GetShadowReport(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	report, err := h.service.GetShadowReport(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get shadow report")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// StopShadow stops shadowing live executions
+func (h *WorkflowHandlers) StopShadow(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	shadow, err := h.service.StopShadow(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to stop shadow")
+		return
+	}
+
+	c.JSON(http.StatusOK, shadow)
+}
+
+// GetBudget returns the budget of a workflow with its spend this month
+func (h *WorkflowHandlers) GetBudget(c *gin.Context) {
+	budget, err := h.service.GetWorkflowBudget(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// SetBudget creates or replaces the budget of a workflow
+func (h *WorkflowHandlers) SetBudget(c *gin.Context) {
+	var req workflow.SetBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	budget, err := h.service.SetWorkflowBudget(c.Request.Context(), c.Param("id"), c.GetString("user_id"), req)
+	if err != nil {
+		h.respondError(c, err, "Failed to set budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// DeleteBudget removes the budget of a workflow
+func (h *WorkflowHandlers) DeleteBudget(c *gin.Context) {
+	if err := h.service.DeleteWorkflowBudget(c.Request.Context(), c.Param("id"), c.GetString("user_id")); err != nil {
+		h.respondError(c, err, "Failed to delete budget")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSLA returns the SLA of a workflow
+func (h *WorkflowHandlers) GetSLA(c *gin.Context) {
+	sla, err := h.service.GetSLA(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get SLA")
+		return
+	}
+
+	c.JSON(http.StatusOK, sla)
+}
+
+// SetSLA creates or replaces the SLA of a workflow
+func (h *WorkflowHandlers) SetSLA(c *gin.Context) {
+	var req workflow.SetSLARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	sla, err := h.service.SetSLA(c.Request.Context(), c.Param("id"), c.GetString("user_id"), req)
+	if err != nil {
+		h.respondError(c, err, "Failed to set SLA")
+		return
+	}
+
+	c.JSON(http.StatusOK, sla)
+}
+
+// DeleteSLA removes the SLA of a workflow
+func (h *WorkflowHandlers) DeleteSLA(c *gin.Context) {
+	if err := h.service.DeleteSLA(c.Request.Context(), c.Param("id"), c.GetString("user_id")); err != nil {
+		h.respondError(c, err, "Failed to delete SLA")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSLAStatus returns whether a workflow meets each objective of its SLA
+func (h *WorkflowHandlers) GetSLAStatus(c *gin.Context) {
+	status, err := h.service.GetSLAStatus(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get SLA status")
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// GetWorkspaceBudget returns the budget of the workspace of the caller with
+// its spend this month
+func (h *WorkflowHandlers) GetWorkspaceBudget(c *gin.Context) {
+	budget, err := h.service.GetWorkspaceBudget(c.Request.Context(), c.GetString("workspace_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get workspace budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// SetWorkspaceBudget creates or replaces the budget of the workspace of the
+// caller
+func (h *WorkflowHandlers) SetWorkspaceBudget(c *gin.Context) {
+	var req workflow.SetBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	budget, err := h.service.SetWorkspaceBudget(c.Request.Context(), c.GetString("workspace_id"), c.GetString("user_id"), req)
+	if err != nil {
+		h.respondError(c, err, "Failed to set workspace budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// DeleteWorkspaceBudget removes the budget of the workspace of the caller
+func (h *WorkflowHandlers) DeleteWorkspaceBudget(c *gin.Context) {
+	if err := h.service.DeleteWorkspaceBudget(c.Request.Context(), c.GetString("workspace_id")); err != nil {
+		h.respondError(c, err, "Failed to delete workspace budget")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetPolicy returns the policy of the workspace of the caller
+func (h *WorkflowHandlers) GetPolicy(c *gin.Context) {
+	policy, err := h.service.GetWorkspacePolicy(c.Request.Context(), c.GetString("workspace_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get workspace policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// GetWorkspacePolicy returns the policy of a workspace
+func (h *WorkflowHandlers) GetWorkspacePolicy(c *gin.Context) {
+	policy, err := h.service.GetWorkspacePolicy(c.Request.Context(), c.Param("workspaceId"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get workspace policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdateWorkspacePolicy replaces the defaults and locks of a workspace
+func (h *WorkflowHandlers) UpdateWorkspacePolicy(c *gin.Context) {
+	var req workflow.UpdatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	updatedBy := c.GetHeader("X-User-ID")
+	if updatedBy == "" {
+		updatedBy = "admin"
+	}
+
+	policy, err := h.service.UpdateWorkspacePolicy(c.Request.Context(), c.Param("workspaceId"), updatedBy, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update workspace policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// GetComplianceReport lists the workflows of a workspace deviating from its
+// policy
+func (h *WorkflowHandlers) GetComplianceReport(c *gin.Context) {
+	report, err := h.service.GetComplianceReport(c.Request.Context(), c.Param("workspaceId"))
+	if err != nil {
+		h.respondError(c, err, "Failed to build compliance report")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Workflow statistics
+func (h *WorkflowHandlers) GetWorkflowStats(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	stats, err := h.service.GetWorkflowStats(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get workflow stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *WorkflowHandlers) GetWorkflowExecutions(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page := database.NewCursorPage(limit, c.Query("cursor"))
+
+	executions, total, err := h.service.GetWorkflowExecutions(c.Request.Context(), workflowID, userID, page)
+	if err != nil {
+		h.respondError(c, err, "Failed to get workflow executions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"executions": executions,
+		"total":      total,
+		"limit":      page.Limit,
+		"nextCursor": page.Next,
+	})
+}
+
+func (h *WorkflowHandlers) GetLatestRun(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	execution, err := h.service.GetLatestRun(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get latest run")
+		return
+	}
+
+	c.JSON(http.StatusOK, execution)
+}
+
+// Categories and tags
+func (h *WorkflowHandlers) ListCategories(c *gin.Context) {
+	categories, err := h.service.ListCategories(c.Request.Context())
+	if err != nil {
+		h.respondError(c, err, "Failed to list categories")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": categories})
+}
+
+func (h *WorkflowHandlers) CreateCategory(c *gin.Context) {
+	var req struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+		Icon        string `json:"icon"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	category, err := h.service.CreateCategory(c.Request.Context(), req.Name, req.Description, req.Icon)
+	if err != nil {
+		h.respondError(c, err, "Failed to create category")
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *WorkflowHandlers) SearchWorkflows(c *gin.Context) {
+	userID := c.GetString("user_id")
+	query := c.Query("q")
+	category := c.Query("category")
+	tags := c.QueryArray("tags")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	workflows, total, err := h.service.SearchWorkflows(c.Request.Context(), userID, query, category, tags, page, limit)
+	if err != nil {
+		h.respondError(c, err, "Failed to search workflows")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"workflows": workflows,
+		"total":     total,
+		"page":      page,
+		"limit":     limit,
+	})
+}
+
+func (h *WorkflowHandlers) GetPopularTags(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	tags, err := h.service.GetPopularTags(c.Request.Context(), limit)
+	if err != nil {
+		h.respondError(c, err, "Failed to get popular tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
 }
 
 // Trigger handlers
 
-// CreateTrigger creates a new trigger for a workflow
-func (h *WorkflowHandlers) CreateTrigger(c *gin.Context) {
+// CreateTrigger creates a new trigger for a workflow
+func (h *WorkflowHandlers) CreateTrigger(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	var config map[string]interface{}
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	trigger, err := h.service.CreateTrigger(c.Request.Context(), workflowID, userID, config)
+	if err != nil {
+		h.respondError(c, err, "Failed to create trigger")
+		return
+	}
+
+	c.JSON(http.StatusCreated, trigger)
+}
+
+// ListTriggers lists all triggers for a workflow
+func (h *WorkflowHandlers) ListTriggers(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	triggers, err := h.service.ListTriggers(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to list triggers")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"triggers": triggers})
+}
+
+// GetTrigger gets a specific trigger
+func (h *WorkflowHandlers) GetTrigger(c *gin.Context) {
+	triggerID := c.Param("triggerId")
+	userID := c.GetString("user_id")
+
+	trigger, err := h.service.GetTrigger(c.Request.Context(), triggerID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get trigger")
+		return
+	}
+
+	c.JSON(http.StatusOK, trigger)
+}
+
+// UpdateTrigger updates a trigger
+func (h *WorkflowHandlers) UpdateTrigger(c *gin.Context) {
+	triggerID := c.Param("triggerId")
+	userID := c.GetString("user_id")
+
+	var updates map[string]interface{}
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	trigger, err := h.service.UpdateTrigger(c.Request.Context(), triggerID, userID, updates)
+	if err != nil {
+		h.respondError(c, err, "Failed to update trigger")
+		return
+	}
+
+	c.JSON(http.StatusOK, trigger)
+}
+
+// DeleteTrigger deletes a trigger
+func (h *WorkflowHandlers) DeleteTrigger(c *gin.Context) {
+	triggerID := c.Param("triggerId")
+	userID := c.GetString("user_id")
+
+	if err := h.service.DeleteTrigger(c.Request.Context(), triggerID, userID); err != nil {
+		h.respondError(c, err, "Failed to delete trigger")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ActivateTrigger activates a trigger
+func (h *WorkflowHandlers) ActivateTrigger(c *gin.Context) {
+	triggerID := c.Param("triggerId")
+	userID := c.GetString("user_id")
+
+	trigger, err := h.service.ActivateTrigger(c.Request.Context(), triggerID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to activate trigger")
+		return
+	}
+
+	c.JSON(http.StatusOK, trigger)
+}
+
+// DeactivateTrigger deactivates a trigger
+func (h *WorkflowHandlers) DeactivateTrigger(c *gin.Context) {
+	triggerID := c.Param("triggerId")
+	userID := c.GetString("user_id")
+
+	trigger, err := h.service.DeactivateTrigger(c.Request.Context(), triggerID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to deactivate trigger")
+		return
+	}
+
+	c.JSON(http.StatusOK, trigger)
+}
+
+// TestTrigger tests a trigger with sample data, or the payload of the
+// fixture named by the fixture query parameter
+func (h *WorkflowHandlers) TestTrigger(c *gin.Context) {
+	triggerID := c.Param("triggerId")
+	userID := c.GetString("user_id")
+
+	var testData map[string]interface{}
+	if c.Query("fixture") == "" || c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&testData); err != nil {
+			c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+			return
+		}
+	}
+
+	result, err := h.service.TestTrigger(c.Request.Context(), triggerID, userID, c.Query("fixture"), testData)
+	if err != nil {
+		h.respondError(c, err, "Failed to test trigger")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Trigger fixtures
+
+// ListTriggerFixtures lists the fixtures of a trigger
+func (h *WorkflowHandlers) ListTriggerFixtures(c *gin.Context) {
+	fixtures, err := h.service.ListTriggerFixtures(c.Request.Context(), c.Param("id"), c.Param("triggerId"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to list trigger fixtures")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"fixtures": fixtures})
+}
+
+// GetTriggerFixture gets a fixture of a trigger by name
+func (h *WorkflowHandlers) GetTriggerFixture(c *gin.Context) {
+	fixture, err := h.service.GetTriggerFixture(c.Request.Context(), c.Param("id"), c.Param("triggerId"), c.Param("name"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get trigger fixture")
+		return
+	}
+
+	c.JSON(http.StatusOK, fixture)
+}
+
+// CreateTriggerFixture saves a payload of a trigger as a fixture
+func (h *WorkflowHandlers) CreateTriggerFixture(c *gin.Context) {
+	var req workflow.CreateFixtureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	fixture, err := h.service.CreateTriggerFixture(c.Request.Context(), c.Param("id"), c.Param("triggerId"), c.GetString("user_id"), req)
+	if err != nil {
+		h.respondError(c, err, "Failed to save trigger fixture")
+		return
+	}
+
+	c.JSON(http.StatusCreated, fixture)
+}
+
+// DeleteTriggerFixture deletes a fixture of a trigger
+func (h *WorkflowHandlers) DeleteTriggerFixture(c *gin.Context) {
+	if err := h.service.DeleteTriggerFixture(c.Request.Context(), c.Param("id"), c.Param("triggerId"), c.Param("name"), c.GetString("user_id")); err != nil {
+		h.respondError(c, err, "Failed to delete trigger fixture")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CaptureTriggerFixture captures the next payload a trigger receives as a
+// fixture
+func (h *WorkflowHandlers) CaptureTriggerFixture(c *gin.Context) {
+	var req workflow.CaptureFixtureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	capture, err := h.service.CaptureTriggerFixture(c.Request.Context(), c.Param("id"), c.Param("triggerId"), c.GetString("user_id"), req)
+	if err != nil {
+		h.respondError(c, err, "Failed to capture trigger fixture")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, capture)
+}
+
+// ReplayTriggerFixtures replays every fixture of a trigger through the
+// trigger and workflow tests
+func (h *WorkflowHandlers) ReplayTriggerFixtures(c *gin.Context) {
+	replays, err := h.service.ReplayTriggerFixtures(c.Request.Context(), c.Param("id"), c.Param("triggerId"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to replay trigger fixtures")
+		return
+	}
+
+	passed := true
+	for _, replay := range replays {
+		passed = passed && replay.Passed
+	}
+	c.JSON(http.StatusOK, gin.H{"passed": passed, "fixtures": replays})
+}
+
+// Workflow tests
+
+// ListTestCases lists the test cases of a workflow
+func (h *WorkflowHandlers) ListTestCases(c *gin.Context) {
+	testCases, err := h.service.ListTestCases(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to list test cases")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tests": testCases})
+}
+
+// GetTestCase gets a test case of a workflow
+func (h *WorkflowHandlers) GetTestCase(c *gin.Context) {
+	testCase, err := h.service.GetTestCase(c.Request.Context(), c.Param("id"), c.Param("caseId"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get test case")
+		return
+	}
+
+	c.JSON(http.StatusOK, testCase)
+}
+
+// CreateTestCase adds a test case to a workflow
+func (h *WorkflowHandlers) CreateTestCase(c *gin.Context) {
+	var req workflow.TestCaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	testCase, err := h.service.CreateTestCase(c.Request.Context(), c.Param("id"), c.GetString("user_id"), req)
+	if err != nil {
+		h.respondError(c, err, "Failed to create test case")
+		return
+	}
+
+	c.JSON(http.StatusCreated, testCase)
+}
+
+// UpdateTestCase replaces a test case of a workflow
+func (h *WorkflowHandlers) UpdateTestCase(c *gin.Context) {
+	var req workflow.TestCaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	testCase, err := h.service.UpdateTestCase(c.Request.Context(), c.Param("id"), c.Param("caseId"), c.GetString("user_id"), req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update test case")
+		return
+	}
+
+	c.JSON(http.StatusOK, testCase)
+}
+
+// DeleteTestCase deletes a test case of a workflow
+func (h *WorkflowHandlers) DeleteTestCase(c *gin.Context) {
+	if err := h.service.DeleteTestCase(c.Request.Context(), c.Param("id"), c.Param("caseId"), c.GetString("user_id")); err != nil {
+		h.respondError(c, err, "Failed to delete test case")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RunWorkflowTests starts a run of every test case of a workflow
+func (h *WorkflowHandlers) RunWorkflowTests(c *gin.Context) {
+	run, err := h.service.RunWorkflowTests(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to run workflow tests")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
+
+// ListTestRuns lists the latest test runs of a workflow
+func (h *WorkflowHandlers) ListTestRuns(c *gin.Context) {
+	runs, err := h.service.ListTestRuns(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to list test runs")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// GetTestRun gets a test run of a workflow with the results of its cases
+func (h *WorkflowHandlers) GetTestRun(c *gin.Context) {
+	run, err := h.service.GetTestRun(c.Request.Context(), c.Param("id"), c.Param("runId"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to get test run")
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// Workflow variables
+
+// ListVariables lists the variables of a workflow
+func (h *WorkflowHandlers) ListVariables(c *gin.Context) {
 	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	var config map[string]interface{}
-	if err := c.ShouldBindJSON(&config); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	variables, err := h.service.ListWorkflowVariables(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to list variables")
 		return
 	}
 
-	trigger, err := h.service.CreateTrigger(c.Request.Context(), workflowID, userID, config)
+	c.JSON(http.StatusOK, gin.H{"variables": variables})
+}
+
+// GetVariable gets a variable of a workflow by key
+func (h *WorkflowHandlers) GetVariable(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	variable, err := h.service.GetWorkflowVariable(c.Request.Context(), workflowID, userID, c.Param("key"))
 	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to create trigger", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create trigger"})
+		h.respondError(c, err, "Failed to get variable")
 		return
 	}
 
-	c.JSON(http.StatusCreated, trigger)
+	c.JSON(http.StatusOK, variable)
 }
 
-// ListTriggers lists all triggers for a workflow
-func (h *WorkflowHandlers) ListTriggers(c *gin.Context) {
+// SetVariable creates or replaces the variable with the key of the path
+func (h *WorkflowHandlers) SetVariable(c *gin.Context) {
 	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	triggers, err := h.service.ListTriggers(c.Request.Context(), workflowID, userID)
-	if err != nil {
-		if err == service.ErrWorkflowNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
-			return
-		}
-		h.logger.Error("Failed to list triggers", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list triggers"})
+	var variable workflow.WorkflowVariable
+	if err := c.ShouldBindJSON(&variable); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
+	variable.Key = c.Param("key")
 
-	c.JSON(http.StatusOK, gin.H{"triggers": triggers})
+	if err := h.service.SetWorkflowVariable(c.Request.Context(), workflowID, userID, &variable); err != nil {
+		h.respondError(c, err, "Failed to set variable")
+		return
+	}
+
+	c.JSON(http.StatusOK, variable.Masked())
 }
 
-// GetTrigger gets a specific trigger
-func (h *WorkflowHandlers) GetTrigger(c *gin.Context) {
-	triggerID := c.Param("triggerId")
+// DeleteVariable deletes a variable of a workflow
+func (h *WorkflowHandlers) DeleteVariable(c *gin.Context) {
+	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	trigger, err := h.service.GetTrigger(c.Request.Context(), triggerID, userID)
+	if err := h.service.DeleteWorkflowVariable(c.Request.Context(), workflowID, userID, c.Param("key")); err != nil {
+		h.respondError(c, err, "Failed to delete variable")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// EffectiveVariables shows the variables an execution sees in the
+// environment of the query, the default one when none is given, with the
+// source of each value
+func (h *WorkflowHandlers) EffectiveVariables(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	variables, env, err := h.service.ResolveVariables(c.Request.Context(), workflowID, userID, c.Query("environment"))
 	if err != nil {
-		if err == service.ErrUnauthorized {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		h.logger.Error("Failed to get trigger", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trigger"})
+		h.respondError(c, err, "Failed to resolve variables")
 		return
 	}
 
-	c.JSON(http.StatusOK, trigger)
+	response := gin.H{"variables": variables}
+	if env != nil {
+		response["environment"] = env.Name
+	}
+	c.JSON(http.StatusOK, response)
 }
 
-// UpdateTrigger updates a trigger
-func (h *WorkflowHandlers) UpdateTrigger(c *gin.Context) {
-	triggerID := c.Param("triggerId")
+// Workflow environments
+
+// environmentRequest holds the fields of an environment clients may set
+type environmentRequest struct {
+	Name         *string                `json:"name"`
+	Description  *string                `json:"description"`
+	Variables    map[string]interface{} `json:"variables"`
+	Credentials  map[string]string      `json:"credentials"`
+	PromotesFrom *string                `json:"promotesFrom"`
+}
+
+// ListEnvironments lists the environments of a workflow
+func (h *WorkflowHandlers) ListEnvironments(c *gin.Context) {
+	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	environments, err := h.service.ListEnvironments(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to list environments")
 		return
 	}
 
-	trigger, err := h.service.UpdateTrigger(c.Request.Context(), triggerID, userID, updates)
+	c.JSON(http.StatusOK, gin.H{"environments": environments})
+}
+
+// GetEnvironment gets an environment of a workflow
+func (h *WorkflowHandlers) GetEnvironment(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	env, err := h.service.GetEnvironment(c.Request.Context(), workflowID, userID, c.Param("envId"))
 	if err != nil {
-		if err == service.ErrUnauthorized {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		h.logger.Error("Failed to update trigger", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update trigger"})
+		h.respondError(c, err, "Failed to get environment")
 		return
 	}
 
-	c.JSON(http.StatusOK, trigger)
+	c.JSON(http.StatusOK, env)
 }
 
-// DeleteTrigger deletes a trigger
-func (h *WorkflowHandlers) DeleteTrigger(c *gin.Context) {
-	triggerID := c.Param("triggerId")
+// CreateEnvironment creates an environment, the first one of a workflow is
+// its default
+func (h *WorkflowHandlers) CreateEnvironment(c *gin.Context) {
+	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	if err := h.service.DeleteTrigger(c.Request.Context(), triggerID, userID); err != nil {
-		if err == service.ErrUnauthorized {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		h.logger.Error("Failed to delete trigger", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete trigger"})
+	var req environmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+	if req.Name == nil || *req.Name == "" {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(errors.New("name is required"))))
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	env := &workflow.Environment{Name: *req.Name, Variables: req.Variables, Credentials: req.Credentials}
+	if req.Description != nil {
+		env.Description = *req.Description
+	}
+	if req.PromotesFrom != nil {
+		env.PromotesFrom = *req.PromotesFrom
+	}
+	if env.Variables == nil {
+		env.Variables = map[string]interface{}{}
+	}
+	if env.Credentials == nil {
+		env.Credentials = map[string]string{}
+	}
+
+	if err := h.service.CreateEnvironment(c.Request.Context(), workflowID, userID, env); err != nil {
+		h.respondError(c, err, "Failed to create environment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, env)
 }
 
-// ActivateTrigger activates a trigger
-func (h *WorkflowHandlers) ActivateTrigger(c *gin.Context) {
-	triggerID := c.Param("triggerId")
+// UpdateEnvironment changes the name, description, variables, credentials
+// or promotion order of an environment
+func (h *WorkflowHandlers) UpdateEnvironment(c *gin.Context) {
+	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
+	envID := c.Param("envId")
+
+	var req environmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
 
-	if err := h.service.ActivateTrigger(c.Request.Context(), triggerID, userID); err != nil {
-		if err == service.ErrUnauthorized {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Variables != nil {
+		encoded, err := json.Marshal(req.Variables)
+		if err != nil {
+			c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 			return
 		}
-		if err == service.ErrWorkflowInactive {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Workflow is not active"})
+		updates["variables"] = string(encoded)
+	}
+	if req.Credentials != nil {
+		encoded, err := json.Marshal(req.Credentials)
+		if err != nil {
+			c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 			return
 		}
-		h.logger.Error("Failed to activate trigger", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to activate trigger"})
+		updates["credentials"] = string(encoded)
+	}
+	if req.PromotesFrom != nil {
+		updates["promotes_from"] = *req.PromotesFrom
+	}
+
+	ctx := c.Request.Context()
+	if err := h.service.UpdateEnvironment(ctx, workflowID, userID, envID, updates); err != nil {
+		h.respondError(c, err, "Failed to update environment")
+		return
+	}
+
+	env, err := h.service.GetEnvironment(ctx, workflowID, userID, envID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get environment")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Trigger activated"})
+	c.JSON(http.StatusOK, env)
 }
 
-// DeactivateTrigger deactivates a trigger
-func (h *WorkflowHandlers) DeactivateTrigger(c *gin.Context) {
-	triggerID := c.Param("triggerId")
+// DeleteEnvironment deletes an environment other than the default one
+func (h *WorkflowHandlers) DeleteEnvironment(c *gin.Context) {
+	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	if err := h.service.DeactivateTrigger(c.Request.Context(), triggerID, userID); err != nil {
-		if err == service.ErrUnauthorized {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		h.logger.Error("Failed to deactivate trigger", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate trigger"})
+	if err := h.service.DeleteEnvironment(c.Request.Context(), workflowID, userID, c.Param("envId")); err != nil {
+		h.respondError(c, err, "Failed to delete environment")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Trigger deactivated"})
+	c.Status(http.StatusNoContent)
 }
 
-// TestTrigger tests a trigger with sample data
-func (h *WorkflowHandlers) TestTrigger(c *gin.Context) {
-	triggerID := c.Param("triggerId")
+// SetDefaultEnvironment makes an environment the default of its workflow
+func (h *WorkflowHandlers) SetDefaultEnvironment(c *gin.Context) {
+	workflowID := c.Param("id")
 	userID := c.GetString("user_id")
+	envID := c.Param("envId")
 
-	var testData map[string]interface{}
-	if err := c.ShouldBindJSON(&testData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	ctx := c.Request.Context()
+	if err := h.service.SetDefaultEnvironment(ctx, workflowID, userID, envID); err != nil {
+		h.respondError(c, err, "Failed to set default environment")
 		return
 	}
 
-	result, err := h.service.TestTrigger(c.Request.Context(), triggerID, userID, testData)
+	env, err := h.service.GetEnvironment(ctx, workflowID, userID, envID)
 	if err != nil {
-		if err == service.ErrUnauthorized {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-			return
-		}
-		h.logger.Error("Failed to test trigger", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to test trigger"})
+		h.respondError(c, err, "Failed to get environment")
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, env)
+}
+
+// DeployEnvironment deploys a workflow version to an environment
+func (h *WorkflowHandlers) DeployEnvironment(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	var req struct {
+		Version int `json:"version" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	override, err := activationOverride(c)
+	if err != nil {
+		h.respondError(c, err, "Failed to deploy workflow version")
+		return
+	}
+
+	env, err := h.service.DeployEnvironment(c.Request.Context(), workflowID, userID, c.Param("envId"), req.Version, override)
+	if err != nil {
+		h.respondError(c, err, "Failed to deploy workflow version")
+		return
+	}
+
+	c.JSON(http.StatusOK, env)
+}
+
+// PromoteEnvironment deploys to an environment the version deployed to the
+// environment it promotes from
+func (h *WorkflowHandlers) PromoteEnvironment(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	override, err := activationOverride(c)
+	if err != nil {
+		h.respondError(c, err, "Failed to promote workflow version")
+		return
+	}
+
+	env, err := h.service.PromoteEnvironment(c.Request.Context(), workflowID, userID, c.Param("envId"), override)
+	if err != nil {
+		h.respondError(c, err, "Failed to promote workflow version")
+		return
+	}
+
+	c.JSON(http.StatusOK, env)
+}
+
+// ListDeployments lists the versions deployed to an environment
+func (h *WorkflowHandlers) ListDeployments(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	deployments, err := h.service.ListDeployments(c.Request.Context(), workflowID, userID, c.Param("envId"))
+	if err != nil {
+		h.respondError(c, err, "Failed to list deployments")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deployments": deployments})
+}
+
+// GetGitConnection returns the Git connection of a workflow
+func (h *WorkflowHandlers) GetGitConnection(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	conn, err := h.service.GetGitConnection(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get Git connection")
+		return
+	}
+
+	c.JSON(http.StatusOK, conn)
+}
+
+// ConnectGit connects a workflow to a Git repository and pushes its
+// current version
+func (h *WorkflowHandlers) ConnectGit(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	var req workflow.ConnectGitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	conn, err := h.service.ConnectGit(c.Request.Context(), workflowID, userID, &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to connect Git repository")
+		return
+	}
+
+	c.JSON(http.StatusOK, conn)
+}
+
+// DisconnectGit removes the Git connection of a workflow
+func (h *WorkflowHandlers) DisconnectGit(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	if err := h.service.DisconnectGit(c.Request.Context(), workflowID, userID); err != nil {
+		h.respondError(c, err, "Failed to disconnect Git repository")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// PushToGit pushes the current version of a workflow to its repository
+func (h *WorkflowHandlers) PushToGit(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	conn, err := h.service.PushToGit(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to push workflow to Git")
+		return
+	}
+
+	c.JSON(http.StatusOK, conn)
+}
+
+// PreviewGitPull shows what pulling the workflow from Git would change
+func (h *WorkflowHandlers) PreviewGitPull(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	preview, err := h.service.PreviewGitPull(c.Request.Context(), workflowID, userID)
+	if err != nil {
+		h.respondError(c, err, "Failed to preview Git pull")
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// ApplyGitPull applies the workflow in Git as a new version
+func (h *WorkflowHandlers) ApplyGitPull(c *gin.Context) {
+	workflowID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	var req struct {
+		Commit string `json:"commit"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+
+	wf, err := h.service.ApplyGitPull(c.Request.Context(), workflowID, userID, req.Commit)
+	if err != nil {
+		h.respondError(c, err, "Failed to apply Git pull")
+		return
+	}
+
+	c.JSON(http.StatusOK, wf)
 }
 
 // Admin handlers (stubs for auth example)
 func (h *WorkflowHandlers) ListAllWorkflows(c *gin.Context) {
 	// Admin endpoint to list all workflows
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page := database.NewCursorPage(limit, c.Query("cursor"))
 
-	workflows, total, err := h.service.ListWorkflows(c.Request.Context(), "", page, limit, "")
+	workflows, total, err := h.service.ListWorkflows(c.Request.Context(), "", "", page)
 	if err != nil {
-		h.logger.Error("Failed to list all workflows", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list workflows"})
+		h.respondError(c, err, "Failed to list workflows")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"workflows": workflows,
-		"total":     total,
-		"page":      page,
-		"limit":     limit,
+		"workflows":  workflows,
+		"total":      total,
+		"limit":      page.Limit,
+		"nextCursor": page.Next,
 	})
 }
 
@@ -914,15 +2152,14 @@ func (h *WorkflowHandlers) ForceExecuteWorkflow(c *gin.Context) {
 		Data map[string]interface{} `json:"data"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
 		return
 	}
 
 	// Admin force execute (bypasses activation check)
-	executionID, err := h.service.ExecuteWorkflow(c.Request.Context(), workflowID, "admin", req.Data)
+	executionID, err := h.service.ExecuteWorkflow(c.Request.Context(), workflowID, "admin", "", req.Data)
 	if err != nil {
-		h.logger.Error("Failed to force execute workflow", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to execute workflow"})
+		h.respondError(c, err, "Failed to execute workflow")
 		return
 	}
 
@@ -954,3 +2191,14 @@ func (h *WorkflowHandlers) GetExecutionAnalytics(c *gin.Context) {
 		"avg_duration_ms":  0,
 	})
 }
+
+// respondError writes err as a coded error response. Uncoded errors are
+// logged and reported to the client as msg.
+func (h *WorkflowHandlers) respondError(c *gin.Context, err error, msg string) {
+	status, body := apperrors.ToHTTP(err)
+	if status >= http.StatusInternalServerError {
+		h.logger.Error(msg, "error", err)
+		body.Error = msg
+	}
+	c.JSON(status, body)
+}
@@ -0,0 +1,50 @@
+// Package rpc serves the workflow gRPC API, the definitions of workflows
+// for the services running them
+package rpc
+
+import (
+	"context"
+
+	"github.com/linkflow-go/internal/workflow/app/service"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/rpc/workflowv1"
+)
+
+// Server implements workflowv1.WorkflowServiceServer
+type Server struct {
+	workflowv1.UnimplementedWorkflowServiceServer
+	service *service.WorkflowService
+}
+
+// NewServer creates the workflow API server
+func NewServer(service *service.WorkflowService) *Server {
+	return &Server{service: service}
+}
+
+// GetWorkflow returns the current definition of a workflow
+func (s *Server) GetWorkflow(ctx context.Context, req *workflowv1.GetWorkflowRequest) (*workflowv1.Workflow, error) {
+	if req.GetWorkflowId() == "" {
+		return nil, apperrors.New(apperrors.CategoryValidation, apperrors.CodeInvalidRequest, "workflow_id is required")
+	}
+
+	wf, err := s.service.GetWorkflow(ctx, req.GetWorkflowId(), req.GetUserId())
+	if err != nil {
+		return nil, service.ErrWorkflowNotFound
+	}
+	return workflow.ToMessage(wf)
+}
+
+// GetWorkflowVersion returns the definition of a workflow as saved in a
+// version
+func (s *Server) GetWorkflowVersion(ctx context.Context, req *workflowv1.GetWorkflowVersionRequest) (*workflowv1.Workflow, error) {
+	if req.GetWorkflowId() == "" || req.GetVersion() < 1 {
+		return nil, apperrors.New(apperrors.CategoryValidation, apperrors.CodeInvalidRequest, "workflow_id and a positive version are required")
+	}
+
+	wf, err := s.service.GetWorkflowVersion(ctx, req.GetWorkflowId(), int(req.GetVersion()), req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	return workflow.ToMessage(wf)
+}
@@ -0,0 +1,657 @@
+package templates
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// BundleFormatVersion is the layout of template bundles, recorded in their
+// manifest
+const BundleFormatVersion = 1
+
+// Formats of template bundles
+const (
+	BundleFormatTar  = "tar"
+	BundleFormatJSON = "json"
+)
+
+// maxBundleFile bounds each file read from an imported bundle
+const maxBundleFile = 8 << 20
+
+var (
+	ErrBundlesDisabled = apperrors.New(apperrors.CategoryInternal, "TEMPLATE_BUNDLES_DISABLED", "template bundles require a signing key")
+	ErrInvalidBundle   = apperrors.New(apperrors.CategoryValidation, "INVALID_TEMPLATE_BUNDLE", "invalid template bundle")
+	ErrUntrustedBundle = apperrors.New(apperrors.CategoryPermission, "TEMPLATE_BUNDLE_UNTRUSTED", "template bundle is not signed by a trusted key")
+	ErrNotExportable   = apperrors.New(apperrors.CategoryPermission, "TEMPLATE_ACCESS_DENIED", "only marketplace templates and your own can be exported")
+
+	errBundleKeysMissing = errors.New("template bundles require a signing key or trusted keys")
+)
+
+// bundleFiles are the files of a bundle besides its manifest and signature
+var bundleFiles = []string{"template.json", "variables.json", "nodes.json"}
+
+// nodeSchemaColumns are the columns of node.node_types describing the
+// inputs, outputs and configuration of a node type, catalogs have either
+// the first or the others
+var nodeSchemaColumns = []string{"schema", "input_schema", "output_schema", "config_schema"}
+
+// BundleManifest lists the files of a bundle with their checksums. Its
+// Ed25519 signature is stored next to it, so verifying the signature and
+// then the checksums proves the bundle is the one exported. Checksums and
+// signature cover the compacted JSON of each file, bundles reformatted on
+// the way still verify.
+type BundleManifest struct {
+	FormatVersion   int               `json:"formatVersion"`
+	TemplateID      string            `json:"templateId"`
+	TemplateName    string            `json:"templateName"`
+	TemplateVersion int               `json:"templateVersion"`
+	ExportedBy      string            `json:"exportedBy"`
+	ExportedAt      time.Time         `json:"exportedAt"`
+	Algorithm       string            `json:"algorithm"`
+	KeyID           string            `json:"keyId"`
+	Files           map[string]string `json:"files"`
+}
+
+// Bundle is a template bundle in the JSON format. The tar format holds the
+// same files, the signature as manifest.sig.
+type Bundle struct {
+	Manifest  json.RawMessage            `json:"manifest"`
+	Signature string                     `json:"signature"`
+	Files     map[string]json.RawMessage `json:"files"`
+}
+
+// BundledTemplate is the template of a bundle, its variables aside
+type BundledTemplate struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Category    string                 `json:"category"`
+	Icon        string                 `json:"icon"`
+	Tags        []string               `json:"tags"`
+	Workflow    json.RawMessage        `json:"workflow"`
+	Config      map[string]interface{} `json:"config,omitempty"`
+}
+
+// NodeSchema is the schema of a node type the template uses, as the node
+// catalog of the exporting deployment describes it
+type NodeSchema struct {
+	Type    string                     `json:"type"`
+	Name    string                     `json:"name,omitempty"`
+	Version string                     `json:"version,omitempty"`
+	Schema  map[string]json.RawMessage `json:"schema,omitempty"`
+}
+
+// Imported is a template created from a bundle. Warnings name the node
+// types of the bundle this deployment lacks or has another version of.
+type Imported struct {
+	Template *Template      `json:"template"`
+	Manifest BundleManifest `json:"manifest"`
+	Warnings []string       `json:"warnings"`
+}
+
+// BundleKeys sign the bundles exported and verify those imported
+type BundleKeys struct {
+	signing ed25519.PrivateKey
+	trusted map[string]ed25519.PublicKey
+}
+
+// NewBundleKeys decodes the base64 Ed25519 keys of template bundles. The
+// signing key, a private key or its seed, may be empty to only import
+// bundles; its public key is trusted.
+func NewBundleKeys(signingKey string, trustedKeys []string) (*BundleKeys, error) {
+	keys := &BundleKeys{trusted: make(map[string]ed25519.PublicKey)}
+
+	if signingKey != "" {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signingKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid template signing key: %w", err)
+		}
+		switch len(raw) {
+		case ed25519.SeedSize:
+			keys.signing = ed25519.NewKeyFromSeed(raw)
+		case ed25519.PrivateKeySize:
+			keys.signing = ed25519.PrivateKey(raw)
+		default:
+			return nil, fmt.Errorf("invalid template signing key: %d bytes, want an Ed25519 private key", len(raw))
+		}
+		public := keys.signing.Public().(ed25519.PublicKey)
+		keys.trusted[KeyID(public)] = public
+	}
+
+	for _, key := range trustedKeys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted template key: %w", err)
+		}
+		if len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid trusted template key: %d bytes, want an Ed25519 public key", len(raw))
+		}
+		keys.trusted[KeyID(raw)] = ed25519.PublicKey(raw)
+	}
+
+	if len(keys.trusted) == 0 {
+		return nil, errBundleKeysMissing
+	}
+	return keys, nil
+}
+
+// GenerateBundleKey returns a new Ed25519 key pair for template bundles,
+// base64 encoded, and the ID of its public key
+func GenerateBundleKey() (signingKey, publicKey, keyID string, err error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(private.Seed()), base64.StdEncoding.EncodeToString(public), KeyID(public), nil
+}
+
+// KeyID identifies a public key in manifests, the hex of the first 8 bytes
+// of its SHA-256
+func KeyID(public ed25519.PublicKey) string {
+	sum := sha256.Sum256(public)
+	return hex.EncodeToString(sum[:8])
+}
+
+// SetBundleKeys enables template bundles, signed and verified with keys
+func (tm *TemplateManager) SetBundleKeys(keys *BundleKeys) {
+	tm.bundleKeys = keys
+}
+
+// Exportable reports whether a user may export the template: marketplace
+// templates and their own
+func (t *Template) Exportable(userID string) bool {
+	return t.Listed() || t.CreatorID == userID
+}
+
+// ExportBundle writes a template, its variables and the schemas of the node
+// types it uses as a signed bundle in format, tar or json
+func (tm *TemplateManager) ExportBundle(ctx context.Context, template *Template, exportedBy, format string) ([]byte, error) {
+	if tm.bundleKeys == nil || tm.bundleKeys.signing == nil {
+		return nil, ErrBundlesDisabled
+	}
+	if format != BundleFormatTar && format != BundleFormatJSON {
+		return nil, ErrInvalidBundle.WithMessage("unknown bundle format %q, want tar or json", format)
+	}
+
+	definition := &workflow.Workflow{}
+	if len(template.Workflow) > 0 {
+		parsed, err := tm.applyVariables(template.Workflow, template.Variables, probeValues(template.Variables))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template workflow: %w", err)
+		}
+		definition = parsed
+	}
+	nodes, err := tm.nodeSchemas(ctx, definition)
+	if err != nil {
+		// Bundles go without schemas rather than failing where the node
+		// catalog is out of reach
+		tm.logger.Warn("Failed to read node schemas for template bundle", "template_id", template.ID, "error", err)
+		nodes = []NodeSchema{}
+	}
+
+	files := map[string]interface{}{
+		"template.json": BundledTemplate{
+			Name:        template.Name,
+			Description: template.Description,
+			Category:    template.Category,
+			Icon:        template.Icon,
+			Tags:        template.Tags,
+			Workflow:    template.Workflow,
+			Config:      template.Config,
+		},
+		"variables.json": nonNilVariables(template.Variables),
+		"nodes.json":     nodes,
+	}
+
+	public := tm.bundleKeys.signing.Public().(ed25519.PublicKey)
+	manifest := BundleManifest{
+		FormatVersion:   BundleFormatVersion,
+		TemplateID:      template.ID,
+		TemplateName:    template.Name,
+		TemplateVersion: template.Version,
+		ExportedBy:      exportedBy,
+		ExportedAt:      time.Now().UTC(),
+		Algorithm:       "ed25519",
+		KeyID:           KeyID(public),
+		Files:           make(map[string]string, len(files)),
+	}
+
+	bundle := Bundle{Files: make(map[string]json.RawMessage, len(files))}
+	for name, value := range files {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+		sum := sha256.Sum256(data)
+		manifest.Files[name] = hex.EncodeToString(sum[:])
+		bundle.Files[name] = data
+	}
+
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	bundle.Manifest = manifestData
+	bundle.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(tm.bundleKeys.signing, manifestData))
+
+	tm.logger.Info("Template bundle exported", "template_id", template.ID, "format", format, "key_id", manifest.KeyID)
+	if format == BundleFormatJSON {
+		return json.MarshalIndent(bundle, "", "  ")
+	}
+	return writeBundleArchive(slug(template.Name), manifest.ExportedAt, &bundle)
+}
+
+// ImportBundle verifies a bundle, tar or json, and creates its template as
+// a private template of the user, named name unless empty
+func (tm *TemplateManager) ImportBundle(ctx context.Context, data []byte, userID, name string) (*Imported, error) {
+	if tm.bundleKeys == nil {
+		return nil, ErrBundlesDisabled
+	}
+
+	var bundle *Bundle
+	var err error
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		bundle, err = readBundleArchive(data)
+	} else {
+		bundle = &Bundle{}
+		if jsonErr := json.Unmarshal(data, bundle); jsonErr != nil {
+			err = ErrInvalidBundle.WithMessage("neither a gzipped tarball nor a JSON bundle: %v", jsonErr)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := tm.verifyBundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	var bundled BundledTemplate
+	if err := json.Unmarshal(bundle.Files["template.json"], &bundled); err != nil {
+		return nil, ErrInvalidBundle.WithMessage("template.json: %v", err)
+	}
+	var variables []Variable
+	if err := json.Unmarshal(bundle.Files["variables.json"], &variables); err != nil {
+		return nil, ErrInvalidBundle.WithMessage("variables.json: %v", err)
+	}
+	var nodes []NodeSchema
+	if err := json.Unmarshal(bundle.Files["nodes.json"], &nodes); err != nil {
+		return nil, ErrInvalidBundle.WithMessage("nodes.json: %v", err)
+	}
+
+	template := &Template{
+		Name:        bundled.Name,
+		Description: bundled.Description,
+		Category:    bundled.Category,
+		Icon:        bundled.Icon,
+		Tags:        bundled.Tags,
+		Workflow:    bundled.Workflow,
+		Variables:   variables,
+		Config:      bundled.Config,
+		CreatorID:   userID,
+		IsPublic:    false,
+	}
+	if name != "" {
+		template.Name = name
+	}
+	if string(template.Workflow) == "null" {
+		template.Workflow = nil
+	}
+	if err := tm.validateTemplate(template); err != nil {
+		return nil, ErrInvalidBundle.WithMessage("%v", err)
+	}
+	if err := tm.CreateTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+
+	warnings, err := tm.nodeWarnings(ctx, nodes)
+	if err != nil {
+		tm.logger.Warn("Failed to compare node types of template bundle", "template_id", template.ID, "error", err)
+		warnings = []string{"node types could not be compared with the node catalog"}
+	}
+
+	tm.logger.Info("Template bundle imported",
+		"template_id", template.ID,
+		"source_template_id", manifest.TemplateID,
+		"source_version", manifest.TemplateVersion,
+		"key_id", manifest.KeyID)
+	return &Imported{Template: template, Manifest: *manifest, Warnings: warnings}, nil
+}
+
+// verifyBundle checks the manifest of a bundle is signed by a trusted key,
+// then the files against their checksums
+func (tm *TemplateManager) verifyBundle(bundle *Bundle) (*BundleManifest, error) {
+	if len(bundle.Manifest) == 0 || bundle.Signature == "" {
+		return nil, ErrInvalidBundle.WithMessage("bundle has no signed manifest")
+	}
+	manifestData, err := compact(bundle.Manifest)
+	if err != nil {
+		return nil, ErrInvalidBundle.WithMessage("manifest: %v", err)
+	}
+	var manifest BundleManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, ErrInvalidBundle.WithMessage("manifest: %v", err)
+	}
+	if manifest.FormatVersion != BundleFormatVersion || manifest.Algorithm != "ed25519" {
+		return nil, ErrInvalidBundle.WithMessage("unsupported bundle format %d signed with %q", manifest.FormatVersion, manifest.Algorithm)
+	}
+
+	public, ok := tm.bundleKeys.trusted[manifest.KeyID]
+	if !ok {
+		return nil, ErrUntrustedBundle.WithMessage("bundle is signed with key %s, which is not trusted", manifest.KeyID)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(bundle.Signature))
+	if err != nil || !ed25519.Verify(public, manifestData, signature) {
+		return nil, ErrUntrustedBundle.WithMessage("signature of the manifest does not verify with key %s", manifest.KeyID)
+	}
+
+	for _, name := range bundleFiles {
+		want, ok := manifest.Files[name]
+		if !ok {
+			return nil, ErrInvalidBundle.WithMessage("manifest does not list %s", name)
+		}
+		data, err := compact(bundle.Files[name])
+		if err != nil {
+			return nil, ErrInvalidBundle.WithMessage("%s: %v", name, err)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != want {
+			return nil, ErrInvalidBundle.WithMessage("checksum of %s does not match the manifest", name)
+		}
+		bundle.Files[name] = data
+	}
+	return &manifest, nil
+}
+
+// nodeSchemas reads the schemas of the node types a workflow uses from the
+// node catalog. Types missing from it, built into the executor, are listed
+// without schema.
+func (tm *TemplateManager) nodeSchemas(ctx context.Context, definition *workflow.Workflow) ([]NodeSchema, error) {
+	types := usedNodeTypes(definition)
+	schemas := make([]NodeSchema, 0, len(types))
+	if len(types) == 0 {
+		return schemas, nil
+	}
+
+	catalog, err := tm.nodeCatalog(ctx, types)
+	if err != nil {
+		return nil, err
+	}
+	for _, nodeType := range types {
+		if schema, ok := catalog[nodeType]; ok {
+			schemas = append(schemas, schema)
+			continue
+		}
+		schemas = append(schemas, NodeSchema{Type: nodeType})
+	}
+	return schemas, nil
+}
+
+// nodeWarnings compares the node types of a bundle with the node catalog
+func (tm *TemplateManager) nodeWarnings(ctx context.Context, nodes []NodeSchema) ([]string, error) {
+	warnings := []string{}
+	types := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		// Types without schema were missing from the exporting catalog too
+		if len(node.Schema) > 0 || node.Version != "" {
+			types = append(types, node.Type)
+		}
+	}
+	if len(types) == 0 {
+		return warnings, nil
+	}
+
+	catalog, err := tm.nodeCatalog(ctx, types)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if len(node.Schema) == 0 && node.Version == "" {
+			continue
+		}
+		local, ok := catalog[node.Type]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("node type %s is not installed", node.Type))
+			continue
+		}
+		if node.Version != "" && local.Version != node.Version {
+			warnings = append(warnings, fmt.Sprintf("node type %s is version %s here, the bundle was exported with version %s",
+				node.Type, local.Version, node.Version))
+		}
+	}
+	return warnings, nil
+}
+
+// nodeCatalog reads node types from node.node_types by type
+func (tm *TemplateManager) nodeCatalog(ctx context.Context, types []string) (map[string]NodeSchema, error) {
+	var rows []map[string]interface{}
+	if err := tm.db.WithContext(ctx).
+		Table("node.node_types").
+		Where("type IN ?", types).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read node types: %w", err)
+	}
+
+	catalog := make(map[string]NodeSchema, len(rows))
+	for _, row := range rows {
+		schema := NodeSchema{
+			Type:    fmt.Sprint(row["type"]),
+			Name:    text(row["name"]),
+			Version: text(row["version"]),
+			Schema:  make(map[string]json.RawMessage),
+		}
+		for _, column := range nodeSchemaColumns {
+			if raw := rawJSON(row[column]); raw != nil {
+				schema.Schema[column] = raw
+			}
+		}
+		catalog[schema.Type] = schema
+	}
+	return catalog, nil
+}
+
+// usedNodeTypes lists the node types of a workflow, sorted
+func usedNodeTypes(definition *workflow.Workflow) []string {
+	seen := make(map[string]bool)
+	types := []string{}
+	for _, node := range definition.Nodes {
+		if node.Type != "" && !seen[node.Type] {
+			seen[node.Type] = true
+			types = append(types, node.Type)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// text is a column read as text, empty when null
+func text(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// rawJSON is a JSON column, drivers read it as text, bytes or decoded
+func rawJSON(value interface{}) json.RawMessage {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		data = encoded
+	}
+	compacted, err := compact(data)
+	if err != nil {
+		return nil
+	}
+	return compacted
+}
+
+// compact strips the insignificant whitespace of JSON
+func compact(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty")
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// nonNilVariables writes templates without variables as an empty list
+func nonNilVariables(variables []Variable) []Variable {
+	if variables == nil {
+		return []Variable{}
+	}
+	return variables
+}
+
+// BundleFileName is the file name of the bundle of a template in format
+func BundleFileName(name, format string) string {
+	if format == BundleFormatJSON {
+		return slug(name) + ".json"
+	}
+	return slug(name) + ".tar.gz"
+}
+
+// slug names the directory of a bundle archive after its template
+func slug(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteRune('-')
+		}
+	}
+	if s := strings.Trim(b.String(), "-"); s != "" {
+		return "template-" + s
+	}
+	return "template"
+}
+
+// writeBundleArchive writes a bundle as a gzipped tarball under a
+// directory, the manifest first. Files are indented for reading.
+func writeBundleArchive(dir string, modTime time.Time, bundle *Bundle) ([]byte, error) {
+	contents := map[string][]byte{"manifest.sig": []byte(bundle.Signature + "\n")}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, bundle.Manifest, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	contents["manifest.json"] = append([]byte(nil), indented.Bytes()...)
+	for name, data := range bundle.Files {
+		indented.Reset()
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			return nil, fmt.Errorf("failed to write bundle: %w", err)
+		}
+		contents[name] = append([]byte(nil), indented.Bytes()...)
+	}
+
+	names := append([]string{"manifest.json", "manifest.sig"}, bundleFiles...)
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range names {
+		data := contents[name]
+		header := &tar.Header{
+			Name:    dir + "/" + name,
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: modTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to write bundle: %w", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// readBundleArchive reads the files of a bundle tarball, whatever the
+// directory they are under
+func readBundleArchive(data []byte) (*Bundle, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidBundle.WithMessage("%v", err)
+	}
+	defer gz.Close()
+
+	bundle := &Bundle{Files: make(map[string]json.RawMessage)}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidBundle.WithMessage("%v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Size > maxBundleFile {
+			return nil, ErrInvalidBundle.WithMessage("%s is larger than %d bytes", header.Name, maxBundleFile)
+		}
+		content, err := io.ReadAll(io.LimitReader(tr, maxBundleFile))
+		if err != nil {
+			return nil, ErrInvalidBundle.WithMessage("%v", err)
+		}
+
+		switch name := path.Base(header.Name); name {
+		case "manifest.json":
+			bundle.Manifest = content
+		case "manifest.sig":
+			bundle.Signature = string(content)
+		default:
+			bundle.Files[name] = content
+		}
+	}
+	return bundle, nil
+}
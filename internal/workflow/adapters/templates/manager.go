@@ -11,16 +11,17 @@ import (
 	"github.com/google/uuid"
 	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/database"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/logger"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrTemplateNotFound    = errors.New("template not found")
-	ErrInvalidTemplate     = errors.New("invalid template")
-	ErrDuplicateTemplate   = errors.New("template already exists")
-	ErrVariableRequired    = errors.New("required variable not provided")
-	ErrInvalidVariableType = errors.New("invalid variable type")
+	ErrTemplateNotFound    = apperrors.New(apperrors.CategoryNotFound, "TEMPLATE_NOT_FOUND", "template not found")
+	ErrInvalidTemplate     = apperrors.New(apperrors.CategoryValidation, "INVALID_TEMPLATE", "invalid template")
+	ErrDuplicateTemplate   = apperrors.New(apperrors.CategoryConflict, "TEMPLATE_EXISTS", "template already exists")
+	ErrVariableRequired    = apperrors.New(apperrors.CategoryValidation, "TEMPLATE_VARIABLE_REQUIRED", "required variable not provided")
+	ErrInvalidVariableType = apperrors.New(apperrors.CategoryValidation, "INVALID_VARIABLE_TYPE", "invalid variable type")
 )
 
 // Variable types
@@ -58,9 +59,22 @@ type Template struct {
 	CreatorID   string                 `json:"creatorId"`
 	UsageCount  int64                  `json:"usageCount" gorm:"default:0"`
 	Rating      float32                `json:"rating" gorm:"default:0"`
+	ReviewCount int64                  `json:"reviewCount" gorm:"default:0"`
 	Config      map[string]interface{} `json:"config" gorm:"serializer:json"`
-	CreatedAt   time.Time              `json:"createdAt"`
-	UpdatedAt   time.Time              `json:"updatedAt"`
+	// Version is the latest version published, workflows created from an
+	// older one can upgrade to it
+	Version int `json:"version" gorm:"not null;default:1"`
+	// Moderation is the review state of public templates, listed in the
+	// marketplace once approved. Empty for private templates.
+	Moderation     string    `json:"moderation,omitempty"`
+	ModerationNote string    `json:"moderationNote,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (Template) TableName() string {
+	return "workflow.templates"
 }
 
 // Variable represents a template variable
@@ -73,6 +87,9 @@ type Variable struct {
 	DefaultValue interface{} `json:"defaultValue"`
 	Options      []Option    `json:"options,omitempty"`
 	Validation   Validation  `json:"validation,omitempty"`
+	// Targets are paths of the workflow the value is set at, beside the
+	// placeholders of the variable, as in nodes.fetch.parameters.url
+	Targets []string `json:"targets,omitempty"`
 }
 
 // Option represents a variable option
@@ -95,6 +112,8 @@ type TemplateManager struct {
 	db               *database.DB
 	logger           logger.Logger
 	builtInTemplates map[string]*Template
+	// bundleKeys sign and verify template bundles, disabled when nil
+	bundleKeys *BundleKeys
 }
 
 // NewTemplateManager creates a new template manager
@@ -336,6 +355,8 @@ func (tm *TemplateManager) initBuiltInTemplates() {
 
 // registerBuiltInTemplate registers a built-in template
 func (tm *TemplateManager) registerBuiltInTemplate(template *Template) {
+	template.Moderation = ModerationApproved
+	template.Version = 1
 	template.CreatedAt = time.Now()
 	template.UpdatedAt = time.Now()
 	tm.builtInTemplates[template.ID] = template
@@ -353,6 +374,14 @@ func (tm *TemplateManager) CreateTemplate(ctx context.Context, template *Templat
 		template.ID = "template-" + uuid.New().String()
 	}
 
+	template.Version = 1
+
+	// Public submissions are listed in the marketplace once approved
+	template.Moderation = ""
+	if template.IsPublic {
+		template.Moderation = ModerationPending
+	}
+
 	// Set timestamps
 	template.CreatedAt = time.Now()
 	template.UpdatedAt = time.Now()
@@ -372,8 +401,16 @@ func (tm *TemplateManager) CreateTemplate(ctx context.Context, template *Templat
 // GetTemplate retrieves a template by ID
 func (tm *TemplateManager) GetTemplate(ctx context.Context, templateID string) (*Template, error) {
 	// Check built-in templates first
-	if template, ok := tm.builtInTemplates[templateID]; ok {
-		return template, nil
+	if _, ok := tm.builtInTemplates[templateID]; ok {
+		builtIns, err := tm.builtIns(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, template := range builtIns {
+			if template.ID == templateID {
+				return template, nil
+			}
+		}
 	}
 
 	// Check database
@@ -391,7 +428,11 @@ func (tm *TemplateManager) ListTemplates(ctx context.Context, category string, i
 	templates := []*Template{}
 
 	// Add built-in templates
-	for _, template := range tm.builtInTemplates {
+	builtIns, err := tm.builtIns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	for _, template := range builtIns {
 		if category != "" && template.Category != category {
 			continue
 		}
@@ -431,15 +472,9 @@ func (tm *TemplateManager) InstantiateTemplate(ctx context.Context, templateID,
 	}
 
 	// Validate and apply variables
-	processedVars, err := tm.processVariables(template.Variables, variables)
+	templateWorkflow, processedVars, err := tm.render(template.Workflow, template.Variables, variables)
 	if err != nil {
-		return nil, fmt.Errorf("variable processing failed: %w", err)
-	}
-
-	// Parse workflow from template
-	var templateWorkflow workflow.Workflow
-	if err := json.Unmarshal(template.Workflow, &templateWorkflow); err != nil {
-		return nil, fmt.Errorf("failed to parse template workflow: %w", err)
+		return nil, err
 	}
 
 	// Create new workflow instance
@@ -447,17 +482,13 @@ func (tm *TemplateManager) InstantiateTemplate(ctx context.Context, templateID,
 	wf.Nodes = templateWorkflow.Nodes
 	wf.Connections = templateWorkflow.Connections
 	wf.Settings = templateWorkflow.Settings
+	wf.Annotations = templateWorkflow.Annotations
 	wf.Tags = template.Tags
 
-	// Apply variable substitutions
-	if err := tm.applyVariables(wf, processedVars); err != nil {
-		return nil, fmt.Errorf("failed to apply variables: %w", err)
-	}
-
-	// Increment template usage count
-	if !template.IsBuiltIn {
-		tm.db.Model(&Template{}).Where("id = ?", templateID).
-			UpdateColumn("usage_count", gorm.Expr("usage_count + 1"))
+	// Count the use, the uses of the last days rank trending templates and
+	// the values kept are applied again by upgrades
+	if err := tm.recordUse(ctx, template, userID, wf.ID, processedVars); err != nil {
+		tm.logger.Warn("Failed to record template use", "template_id", templateID, "error", err)
 	}
 
 	tm.logger.Info("Workflow instantiated from template",
@@ -537,14 +568,6 @@ func (tm *TemplateManager) validateTemplate(template *Template) error {
 		return fmt.Errorf("invalid category: %s", template.Category)
 	}
 
-	// Validate workflow JSON
-	if len(template.Workflow) > 0 {
-		var wf workflow.Workflow
-		if err := json.Unmarshal(template.Workflow, &wf); err != nil {
-			return fmt.Errorf("invalid workflow JSON: %w", err)
-		}
-	}
-
 	// Validate variables
 	for _, v := range template.Variables {
 		if err := tm.validateVariable(&v); err != nil {
@@ -552,6 +575,20 @@ func (tm *TemplateManager) validateTemplate(template *Template) error {
 		}
 	}
 
+	// Validate workflow JSON, its placeholders and targets given values of
+	// the type of their variable
+	if len(template.Workflow) > 0 {
+		if _, err := tm.applyVariables(template.Workflow, template.Variables, probeValues(template.Variables)); err != nil {
+			return err
+		}
+	} else {
+		for _, v := range template.Variables {
+			if len(v.Targets) > 0 {
+				return fmt.Errorf("variable %s has targets but the template has no workflow", v.Key)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -685,42 +722,6 @@ func (tm *TemplateManager) validateVariableValue(v *Variable, value interface{})
 	return nil
 }
 
-// applyVariables applies variable substitutions to a workflow
-func (tm *TemplateManager) applyVariables(wf *workflow.Workflow, variables map[string]interface{}) error {
-	// Convert workflow to JSON for string replacement
-	wfJSON, err := json.Marshal(wf)
-	if err != nil {
-		return err
-	}
-
-	wfStr := string(wfJSON)
-
-	// Replace variable placeholders
-	for key, value := range variables {
-		placeholder := fmt.Sprintf("{{%s}}", key)
-
-		// Convert value to string for replacement
-		var valueStr string
-		switch v := value.(type) {
-		case string:
-			valueStr = v
-		case int, int32, int64, float32, float64:
-			valueStr = fmt.Sprintf("%v", v)
-		case bool:
-			valueStr = fmt.Sprintf("%v", v)
-		default:
-			// For complex types, marshal to JSON
-			jsonBytes, _ := json.Marshal(v)
-			valueStr = string(jsonBytes)
-		}
-
-		wfStr = strings.ReplaceAll(wfStr, placeholder, valueStr)
-	}
-
-	// Parse back to workflow
-	return json.Unmarshal([]byte(wfStr), wf)
-}
-
 // GetCategories returns all available template categories
 func (tm *TemplateManager) GetCategories() []map[string]interface{} {
 	return []map[string]interface{}{
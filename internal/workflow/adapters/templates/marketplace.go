@@ -0,0 +1,456 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidReview = apperrors.New(apperrors.CategoryValidation, "INVALID_TEMPLATE_REVIEW", "invalid template review")
+
+// Moderation states of public templates
+const (
+	ModerationPending  = "pending"
+	ModerationApproved = "approved"
+	ModerationRejected = "rejected"
+)
+
+// Marketplace sort orders
+const (
+	SortRelevance = "relevance"
+	SortTrending  = "trending"
+	SortMostUsed  = "most_used"
+	SortTopRated  = "top_rated"
+	SortNewest    = "newest"
+)
+
+// trendingWindow is how far back the uses ranking trending templates go
+const trendingWindow = 7 * 24 * time.Hour
+
+// Review is the rating of a template by a user, one per user and template
+type Review struct {
+	ID         string    `json:"id" gorm:"primaryKey"`
+	TemplateID string    `json:"templateId" gorm:"not null;uniqueIndex:idx_template_reviews_user,priority:1"`
+	UserID     string    `json:"userId" gorm:"not null;uniqueIndex:idx_template_reviews_user,priority:2"`
+	Rating     int       `json:"rating" gorm:"not null"`
+	Comment    string    `json:"comment"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (Review) TableName() string {
+	return "workflow.template_reviews"
+}
+
+// Use is a workflow created from a template
+type Use struct {
+	ID         string `json:"id" gorm:"primaryKey"`
+	TemplateID string `json:"templateId" gorm:"not null;index"`
+	UserID     string `json:"userId"`
+	WorkflowID string `json:"workflowId" gorm:"index"`
+	// Version is the version of the template the workflow was created
+	// from, or last upgraded to
+	Version int `json:"version" gorm:"not null;default:1"`
+	// Variables are the values the variables of the template were given,
+	// secrets aside
+	Variables map[string]interface{} `json:"variables,omitempty" gorm:"serializer:json"`
+	CreatedAt time.Time              `json:"createdAt" gorm:"index"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (Use) TableName() string {
+	return "workflow.template_uses"
+}
+
+// SearchQuery selects and orders the templates of the marketplace
+type SearchQuery struct {
+	// Text matches templates whose name, description or tags contain each
+	// of its words
+	Text     string
+	Category string
+	Tag      string
+	// Sort is relevance by default when Text is set, most_used otherwise
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// Listed reports whether the template is in the marketplace: public and
+// approved
+func (t *Template) Listed() bool {
+	return t.IsPublic && t.Moderation == ModerationApproved
+}
+
+// SearchTemplates lists a page of the marketplace templates matching q and
+// how many match in all
+func (tm *TemplateManager) SearchTemplates(ctx context.Context, q SearchQuery) ([]*Template, int64, error) {
+	terms := strings.Fields(strings.ToLower(q.Text))
+	if q.Sort == "" {
+		q.Sort = SortMostUsed
+		if len(terms) > 0 {
+			q.Sort = SortRelevance
+		}
+	}
+	since := time.Now().Add(-trendingWindow)
+	phrase := "%" + strings.Join(terms, " ") + "%"
+
+	var order clause.Expr
+	switch q.Sort {
+	case SortRelevance:
+		order = clause.Expr{SQL: "CASE WHEN " + tm.db.ILike("name") + " THEN 0 ELSE 1 END, usage_count DESC, id", Vars: []interface{}{phrase}}
+	case SortTrending:
+		order = clause.Expr{SQL: "(SELECT COUNT(*) FROM workflow.template_uses WHERE template_uses.template_id = templates.id AND template_uses.created_at >= ?) DESC, usage_count DESC, id", Vars: []interface{}{since}}
+	case SortMostUsed:
+		order = clause.Expr{SQL: "usage_count DESC, id"}
+	case SortTopRated:
+		order = clause.Expr{SQL: "rating DESC, review_count DESC, id"}
+	case SortNewest:
+		order = clause.Expr{SQL: "created_at DESC, id"}
+	default:
+		return nil, 0, ErrInvalidTemplate.WithMessage("unknown sort: %s", q.Sort)
+	}
+
+	query := tm.db.WithContext(ctx).Model(&Template{}).
+		Where("is_public = ? AND moderation = ?", true, ModerationApproved)
+	if q.Category != "" {
+		query = query.Where("category = ?", q.Category)
+	}
+	if q.Tag != "" {
+		tag, _ := json.Marshal(strings.ToLower(q.Tag))
+		query = query.Where(tm.db.ILike("CAST(tags AS TEXT)"), "%"+string(tag)+"%")
+	}
+	for _, term := range terms {
+		pattern := "%" + term + "%"
+		query = query.Where(tm.db.ILike("name")+" OR "+tm.db.ILike("description")+" OR "+tm.db.ILike("CAST(tags AS TEXT)"),
+			pattern, pattern, pattern)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Built-in templates rank among the first offset+limit of the database
+	var found []*Template
+	if err := query.Order(order).Limit(q.Offset + q.Limit).Find(&found).Error; err != nil {
+		return nil, 0, err
+	}
+	builtIns, err := tm.builtIns(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, template := range builtIns {
+		if template.matches(q.Category, q.Tag, terms) {
+			found = append(found, template)
+			total++
+		}
+	}
+
+	recent, err := tm.recentUses(ctx, found, since)
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return rank(q.Sort, phrase, recent, found[i], found[j])
+	})
+
+	if q.Offset >= len(found) {
+		return []*Template{}, total, nil
+	}
+	end := q.Offset + q.Limit
+	if end > len(found) {
+		end = len(found)
+	}
+	return found[q.Offset:end], total, nil
+}
+
+// rank orders templates a before b as the SQL of the sort does
+func rank(sortBy, phrase string, recent map[string]int64, a, b *Template) bool {
+	type key struct {
+		primary, secondary float64
+	}
+	keys := func(t *Template) key {
+		switch sortBy {
+		case SortRelevance:
+			match := 0.0
+			if strings.Contains(strings.ToLower(t.Name), strings.Trim(phrase, "%")) {
+				match = 1
+			}
+			return key{match, float64(t.UsageCount)}
+		case SortTrending:
+			return key{float64(recent[t.ID]), float64(t.UsageCount)}
+		case SortTopRated:
+			return key{float64(t.Rating), float64(t.ReviewCount)}
+		case SortNewest:
+			return key{float64(t.CreatedAt.UnixNano()), 0}
+		default:
+			return key{float64(t.UsageCount), 0}
+		}
+	}
+	ka, kb := keys(a), keys(b)
+	if ka.primary != kb.primary {
+		return ka.primary > kb.primary
+	}
+	if ka.secondary != kb.secondary {
+		return ka.secondary > kb.secondary
+	}
+	return a.ID < b.ID
+}
+
+// matches reports whether a built-in template is listed by the filters of
+// a search
+func (t *Template) matches(category, tag string, terms []string) bool {
+	if category != "" && t.Category != category {
+		return false
+	}
+	tags := strings.ToLower(strings.Join(t.Tags, "\n"))
+	if tag != "" {
+		found := false
+		for _, candidate := range t.Tags {
+			if strings.EqualFold(candidate, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	text := strings.ToLower(t.Name + "\n" + t.Description + "\n" + tags)
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// recentUses counts the uses of templates since a time
+func (tm *TemplateManager) recentUses(ctx context.Context, templates []*Template, since time.Time) (map[string]int64, error) {
+	ids := make([]string, 0, len(templates))
+	for _, t := range templates {
+		ids = append(ids, t.ID)
+	}
+	recent := make(map[string]int64, len(ids))
+	if len(ids) == 0 {
+		return recent, nil
+	}
+
+	var rows []struct {
+		TemplateID string
+		Uses       int64
+	}
+	err := tm.db.WithContext(ctx).Model(&Use{}).
+		Select("template_id, COUNT(*) AS uses").
+		Where("template_id IN ? AND created_at >= ?", ids, since).
+		Group("template_id").
+		Scan(&rows).Error
+	for _, row := range rows {
+		recent[row.TemplateID] = row.Uses
+	}
+	return recent, err
+}
+
+// builtIns returns copies of the built-in templates with their uses and
+// ratings, which are only stored as uses and reviews
+func (tm *TemplateManager) builtIns(ctx context.Context) ([]*Template, error) {
+	ids := make([]string, 0, len(tm.builtInTemplates))
+	for id := range tm.builtInTemplates {
+		ids = append(ids, id)
+	}
+
+	var uses []struct {
+		TemplateID string
+		Uses       int64
+	}
+	if err := tm.db.WithContext(ctx).Model(&Use{}).
+		Select("template_id, COUNT(*) AS uses").
+		Where("template_id IN ?", ids).
+		Group("template_id").
+		Scan(&uses).Error; err != nil {
+		return nil, err
+	}
+	var ratings []struct {
+		TemplateID string
+		Rating     float64
+		Reviews    int64
+	}
+	if err := tm.db.WithContext(ctx).Model(&Review{}).
+		Select("template_id, AVG(rating) AS rating, COUNT(*) AS reviews").
+		Where("template_id IN ?", ids).
+		Group("template_id").
+		Scan(&ratings).Error; err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]*Template, len(ids))
+	for id, builtIn := range tm.builtInTemplates {
+		template := *builtIn
+		templates[id] = &template
+	}
+	for _, row := range uses {
+		templates[row.TemplateID].UsageCount = row.Uses
+	}
+	for _, row := range ratings {
+		templates[row.TemplateID].Rating = float32(row.Rating)
+		templates[row.TemplateID].ReviewCount = row.Reviews
+	}
+
+	list := make([]*Template, 0, len(templates))
+	for _, id := range ids {
+		list = append(list, templates[id])
+	}
+	return list, nil
+}
+
+// RateTemplate rates a marketplace template for a user, replacing the
+// review they gave it before. Creators do not rate their own templates.
+func (tm *TemplateManager) RateTemplate(ctx context.Context, templateID, userID string, rating int, comment string) (*Review, error) {
+	if rating < 1 || rating > 5 {
+		return nil, ErrInvalidReview.WithMessage("rating must be between 1 and 5, got %d", rating)
+	}
+	template, err := tm.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if !template.Listed() {
+		return nil, ErrTemplateNotFound
+	}
+	if template.CreatorID != "" && template.CreatorID == userID {
+		return nil, ErrInvalidReview.WithMessage("templates cannot be rated by their creator")
+	}
+
+	now := time.Now()
+	review := &Review{
+		ID:         uuid.New().String(),
+		TemplateID: templateID,
+		UserID:     userID,
+		Rating:     rating,
+		Comment:    comment,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	err = tm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "template_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rating", "comment", "updated_at"}),
+		}).Create(review).Error; err != nil {
+			return err
+		}
+		// On conflict the review kept its first ID and creation time
+		saved := &Review{}
+		if err := tx.Where("template_id = ? AND user_id = ?", templateID, userID).First(saved).Error; err != nil {
+			return err
+		}
+		review = saved
+		if template.IsBuiltIn {
+			return nil
+		}
+
+		// The rating of a template is the average of its reviews
+		var stats struct {
+			Rating  float64
+			Reviews int64
+		}
+		if err := tx.Model(&Review{}).
+			Select("COALESCE(AVG(rating), 0) AS rating, COUNT(*) AS reviews").
+			Where("template_id = ?", templateID).
+			Scan(&stats).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Template{}).Where("id = ?", templateID).
+			UpdateColumns(map[string]interface{}{"rating": stats.Rating, "review_count": stats.Reviews}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tm.logger.Info("Template rated", "template_id", templateID, "user_id", userID, "rating", rating)
+	return review, nil
+}
+
+// ListReviews lists a page of the reviews of a template, newest first
+func (tm *TemplateManager) ListReviews(ctx context.Context, templateID string, limit, offset int) ([]*Review, error) {
+	reviews := []*Review{}
+	err := tm.db.WithContext(ctx).
+		Where("template_id = ?", templateID).
+		Order("updated_at DESC, id").
+		Limit(limit).
+		Offset(offset).
+		Find(&reviews).Error
+	return reviews, err
+}
+
+// ListModeration lists the public templates in a moderation state, oldest
+// first
+func (tm *TemplateManager) ListModeration(ctx context.Context, status string) ([]*Template, error) {
+	templates := []*Template{}
+	err := tm.db.WithContext(ctx).
+		Where("is_public = ? AND moderation = ?", true, status).
+		Order("created_at, id").
+		Find(&templates).Error
+	return templates, err
+}
+
+// ModerateTemplate approves a public template into the marketplace, or
+// rejects it out of it with a note for its creator
+func (tm *TemplateManager) ModerateTemplate(ctx context.Context, templateID, status, note string) (*Template, error) {
+	if status != ModerationApproved && status != ModerationRejected {
+		return nil, ErrInvalidTemplate.WithMessage("moderation is approved or rejected, got %q", status)
+	}
+	if _, ok := tm.builtInTemplates[templateID]; ok {
+		return nil, ErrInvalidTemplate.WithMessage("built-in templates are not moderated")
+	}
+
+	result := tm.db.WithContext(ctx).Model(&Template{}).
+		Where("id = ? AND is_public = ?", templateID, true).
+		UpdateColumns(map[string]interface{}{
+			"moderation":      status,
+			"moderation_note": note,
+			"updated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrTemplateNotFound
+	}
+
+	tm.logger.Info("Template moderated", "template_id", templateID, "moderation", status)
+	return tm.GetTemplate(ctx, templateID)
+}
+
+// recordUse counts a workflow created from a template with the values of
+// its variables
+func (tm *TemplateManager) recordUse(ctx context.Context, template *Template, userID, workflowID string, variables map[string]interface{}) error {
+	now := time.Now()
+	return tm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&Use{
+			ID:         uuid.New().String(),
+			TemplateID: template.ID,
+			UserID:     userID,
+			WorkflowID: workflowID,
+			Version:    template.Version,
+			Variables:  kept(template.Variables, variables),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}).Error; err != nil {
+			return err
+		}
+		if template.IsBuiltIn {
+			return nil
+		}
+		return tx.Model(&Template{}).Where("id = ?", template.ID).
+			UpdateColumn("usage_count", gorm.Expr("usage_count + 1")).Error
+	})
+}
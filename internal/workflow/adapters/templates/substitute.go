@@ -0,0 +1,206 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// placeholderPattern matches {{key}} placeholders, spaces inside the braces
+// allowed
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// applyVariables substitutes the variables of a template in its workflow
+// and decodes it. The workflow is walked as a JSON document: a string that
+// is a placeholder and nothing else takes the value of its variable with
+// its type, a number, a boolean or a JSON value; placeholders within longer
+// strings are interpolated as text. Values are inserted once, placeholders
+// they contain are kept as written. Variables with targets are then set at
+// those paths. Placeholders of variables without a value are left in place.
+func (tm *TemplateManager) applyVariables(definition json.RawMessage, templateVars []Variable, variables map[string]interface{}) (*workflow.Workflow, error) {
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(definition))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, ErrInvalidTemplate.WithMessage("invalid workflow JSON: %v", err)
+	}
+
+	document = substitute(document, variables)
+	for _, v := range templateVars {
+		value, ok := variables[v.Key]
+		if !ok {
+			continue
+		}
+		for _, target := range v.Targets {
+			if err := setPath(document, target, value); err != nil {
+				return nil, ErrInvalidTemplate.WithMessage("variable %s: %v", v.Key, err)
+			}
+		}
+	}
+
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var wf workflow.Workflow
+	if err := json.Unmarshal(data, &wf); err != nil {
+		return nil, ErrInvalidTemplate.WithMessage("workflow after substituting variables: %v", err)
+	}
+	return &wf, nil
+}
+
+// probeValues are values of the variables of a template to check its
+// workflow with: their default, or the zero value of their type
+func probeValues(templateVars []Variable) map[string]interface{} {
+	values := make(map[string]interface{}, len(templateVars))
+	for _, v := range templateVars {
+		if v.DefaultValue != nil {
+			values[v.Key] = v.DefaultValue
+			continue
+		}
+		switch v.Type {
+		case VariableTypeNumber:
+			values[v.Key] = 0
+		case VariableTypeBoolean:
+			values[v.Key] = false
+		case VariableTypeJSON:
+			values[v.Key] = map[string]interface{}{}
+		default:
+			values[v.Key] = ""
+		}
+	}
+	return values
+}
+
+// substitute replaces the placeholders of the strings of a JSON document,
+// object keys included
+func substitute(node interface{}, variables map[string]interface{}) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if name, ok := interpolate(key, variables).(string); ok {
+				key = name
+			}
+			result[key] = substitute(value, variables)
+		}
+		return result
+	case []interface{}:
+		for i, item := range v {
+			v[i] = substitute(item, variables)
+		}
+		return v
+	case string:
+		return interpolate(v, variables)
+	default:
+		return v
+	}
+}
+
+// interpolate substitutes the placeholders of a string. A string that is
+// a single placeholder becomes the value of its variable.
+func interpolate(s string, variables map[string]interface{}) interface{} {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	if match := placeholderPattern.FindStringSubmatch(s); match != nil && match[0] == s {
+		if value, ok := variables[match[1]]; ok {
+			return value
+		}
+		return s
+	}
+	return placeholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		key := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		value, ok := variables[key]
+		if !ok {
+			return placeholder
+		}
+		return formatValue(value)
+	})
+}
+
+// formatValue is a value interpolated in a string, JSON for objects and
+// arrays
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case int, int32, int64, float32, float64, bool:
+		return fmt.Sprintf("%v", v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
+
+// setPath sets the field a target names to value. Targets are dot
+// separated field names; in arrays, a segment picks the item by index or
+// the object whose id it is, as in nodes.fetch.parameters.url. The field
+// itself may be missing, its parent must exist. A nil value only checks
+// the target.
+func setPath(document interface{}, target string, value interface{}) error {
+	segments := strings.Split(target, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return fmt.Errorf("invalid target %q", target)
+		}
+	}
+
+	current := document
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch node := current.(type) {
+		case map[string]interface{}:
+			if last {
+				if value != nil {
+					node[segment] = value
+				}
+				return nil
+			}
+			next, ok := node[segment]
+			if !ok || next == nil {
+				return fmt.Errorf("target %s: no field %s", target, strings.Join(segments[:i+1], "."))
+			}
+			current = next
+		case []interface{}:
+			index, ok := itemIndex(node, segment)
+			if !ok {
+				return fmt.Errorf("target %s: no item %s", target, strings.Join(segments[:i+1], "."))
+			}
+			if last {
+				if value != nil {
+					node[index] = value
+				}
+				return nil
+			}
+			current = node[index]
+		default:
+			return fmt.Errorf("target %s: %s is not an object or array", target, strings.Join(segments[:i], "."))
+		}
+	}
+	return nil
+}
+
+// itemIndex finds the item of an array a target segment picks, by index or
+// by id
+func itemIndex(items []interface{}, segment string) (int, bool) {
+	for i, item := range items {
+		if object, ok := item.(map[string]interface{}); ok && object["id"] == segment {
+			return i, true
+		}
+	}
+	if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(items) {
+		return index, true
+	}
+	return 0, false
+}
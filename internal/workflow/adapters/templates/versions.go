@@ -0,0 +1,325 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotTemplateCreator = apperrors.New(apperrors.CategoryPermission, "TEMPLATE_ACCESS_DENIED", "only the creator of a template publishes its versions")
+	ErrInvalidVersion     = apperrors.New(apperrors.CategoryValidation, "INVALID_TEMPLATE_VERSION", "invalid template version")
+	ErrVersionConflict    = apperrors.New(apperrors.CategoryConflict, "TEMPLATE_VERSION_CONFLICT", "template version was published concurrently")
+	ErrNotFromTemplate    = apperrors.New(apperrors.CategoryNotFound, "WORKFLOW_NOT_FROM_TEMPLATE", "workflow was not created from a template")
+	ErrUpgradeUnavailable = apperrors.New(apperrors.CategoryConflict, "TEMPLATE_UPGRADE_UNAVAILABLE", "template has no version to upgrade to")
+)
+
+// TemplateVersion is a version of a template as its creator published it
+type TemplateVersion struct {
+	ID         string          `json:"id" gorm:"primaryKey"`
+	TemplateID string          `json:"templateId" gorm:"not null;uniqueIndex:idx_template_versions_version,priority:1"`
+	Version    int             `json:"version" gorm:"not null;uniqueIndex:idx_template_versions_version,priority:2"`
+	Workflow   json.RawMessage `json:"workflow" gorm:"type:jsonb"`
+	Variables  []Variable      `json:"variables" gorm:"serializer:json"`
+	Changelog  string          `json:"changelog"`
+	CreatedBy  string          `json:"createdBy"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// TableName specifies the table name for GORM
+func (TemplateVersion) TableName() string {
+	return "workflow.template_versions"
+}
+
+// Upgrade is what upgrading a workflow to the latest version of its
+// template changes
+type Upgrade struct {
+	TemplateID   string `json:"templateId"`
+	TemplateName string `json:"templateName"`
+	FromVersion  int    `json:"fromVersion"`
+	ToVersion    int    `json:"toVersion"`
+	// Available is set when the template has a version newer than the one
+	// of the workflow
+	Available bool `json:"available"`
+	// Changelog lists the versions published since the one of the
+	// workflow, newest first
+	Changelog []Change `json:"changelog"`
+	// MissingVariables are the required variables without a value kept,
+	// new ones and secrets, to give with the upgrade
+	MissingVariables []Variable               `json:"missingVariables"`
+	Diff             *workflow.DefinitionDiff `json:"diff"`
+
+	// Workflow is the workflow as upgraded
+	Workflow *workflow.Workflow `json:"-"`
+
+	use    *Use
+	values map[string]interface{}
+}
+
+// Change is the changelog of a version of a template
+type Change struct {
+	Version   int       `json:"version"`
+	Changelog string    `json:"changelog"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// upgradable reports whether a user may upgrade workflows to the latest
+// version of the template. Public versions wait for moderation, but for
+// their creator.
+func (t *Template) upgradable(userID string) bool {
+	return t.Moderation == "" || t.Moderation == ModerationApproved || t.CreatorID == userID
+}
+
+// PublishVersion publishes a new version of a template, which becomes its
+// latest. Public templates go back to moderation.
+func (tm *TemplateManager) PublishVersion(ctx context.Context, templateID, userID string, definition json.RawMessage, variables []Variable, changelog string) (*TemplateVersion, error) {
+	if _, ok := tm.builtInTemplates[templateID]; ok {
+		return nil, ErrNotTemplateCreator.WithMessage("built-in templates cannot be modified")
+	}
+	template, err := tm.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template.CreatorID != userID {
+		return nil, ErrNotTemplateCreator
+	}
+
+	candidate := *template
+	candidate.Workflow = definition
+	candidate.Variables = variables
+	if err := tm.validateTemplate(&candidate); err != nil {
+		return nil, ErrInvalidVersion.WithMessage("%v", err)
+	}
+
+	version := &TemplateVersion{
+		ID:         uuid.New().String(),
+		TemplateID: templateID,
+		Version:    template.Version + 1,
+		Workflow:   definition,
+		Variables:  variables,
+		Changelog:  changelog,
+		CreatedBy:  userID,
+		CreatedAt:  time.Now(),
+	}
+	updated := &Template{
+		Workflow:       definition,
+		Variables:      variables,
+		Version:        version.Version,
+		Moderation:     template.Moderation,
+		ModerationNote: template.ModerationNote,
+		UpdatedAt:      version.CreatedAt,
+	}
+	if template.IsPublic {
+		updated.Moderation = ModerationPending
+		updated.ModerationNote = ""
+	}
+
+	err = tm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Templates created before versions have none for their current one
+		var current int64
+		if err := tx.Model(&TemplateVersion{}).
+			Where("template_id = ? AND version = ?", templateID, template.Version).
+			Count(&current).Error; err != nil {
+			return err
+		}
+		if current == 0 {
+			if err := tx.Create(snapshot(template)).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+
+		// The version read is the one replaced, a concurrent publish fails
+		result := tx.Model(&Template{}).
+			Where("id = ? AND version = ?", templateID, template.Version).
+			Select("workflow", "variables", "version", "moderation", "moderation_note", "updated_at").
+			Updates(updated)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish template version: %w", err)
+	}
+
+	tm.logger.Info("Template version published", "template_id", templateID, "version", version.Version)
+	return version, nil
+}
+
+// ListVersions lists the versions of a template, newest first
+func (tm *TemplateManager) ListVersions(ctx context.Context, templateID string) ([]*TemplateVersion, error) {
+	template, err := tm.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return tm.versionsSince(ctx, template, 0)
+}
+
+// versionsSince lists the versions of a template newer than since, newest
+// first. The current version of templates created before versions is
+// listed from the template itself.
+func (tm *TemplateManager) versionsSince(ctx context.Context, template *Template, since int) ([]*TemplateVersion, error) {
+	var versions []*TemplateVersion
+	if !template.IsBuiltIn {
+		if err := tm.db.WithContext(ctx).
+			Where("template_id = ? AND version > ?", template.ID, since).
+			Order("version DESC").
+			Find(&versions).Error; err != nil {
+			return nil, fmt.Errorf("failed to list template versions: %w", err)
+		}
+	}
+	if template.Version > since && (len(versions) == 0 || versions[0].Version < template.Version) {
+		versions = append([]*TemplateVersion{snapshot(template)}, versions...)
+	}
+	return versions, nil
+}
+
+// snapshot is the current version of a template
+func snapshot(template *Template) *TemplateVersion {
+	return &TemplateVersion{
+		ID:         uuid.New().String(),
+		TemplateID: template.ID,
+		Version:    template.Version,
+		Workflow:   template.Workflow,
+		Variables:  template.Variables,
+		CreatedBy:  template.CreatorID,
+		CreatedAt:  template.UpdatedAt,
+	}
+}
+
+// PlanUpgrade renders the latest version of the template a workflow was
+// created from with the variable values kept, overridden by variables, and
+// compares it with the workflow. The upgrade replaces the nodes and
+// connections of the workflow, its settings are kept.
+func (tm *TemplateManager) PlanUpgrade(ctx context.Context, wf *workflow.Workflow, userID string, variables map[string]interface{}) (*Upgrade, error) {
+	var use Use
+	err := tm.db.WithContext(ctx).
+		Where("workflow_id = ?", wf.ID).
+		Order("created_at DESC").
+		First(&use).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrNotFromTemplate
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template of workflow: %w", err)
+	}
+
+	template, err := tm.GetTemplate(ctx, use.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	upgrade := &Upgrade{
+		TemplateID:       template.ID,
+		TemplateName:     template.Name,
+		FromVersion:      use.Version,
+		ToVersion:        template.Version,
+		Available:        template.Version > use.Version && template.upgradable(userID),
+		Changelog:        []Change{},
+		MissingVariables: []Variable{},
+		use:              &use,
+	}
+	if !template.upgradable(userID) {
+		upgrade.ToVersion = use.Version
+		upgrade.Diff = workflow.DiffDefinitions(wf, wf)
+		return upgrade, nil
+	}
+
+	versions, err := tm.versionsSince(ctx, template, use.Version)
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range versions {
+		upgrade.Changelog = append(upgrade.Changelog, Change{
+			Version:   version.Version,
+			Changelog: version.Changelog,
+			CreatedAt: version.CreatedAt,
+		})
+	}
+
+	values := make(map[string]interface{}, len(use.Variables)+len(variables))
+	for k, v := range use.Variables {
+		values[k] = v
+	}
+	for k, v := range variables {
+		values[k] = v
+	}
+
+	// Placeholders of the variables missing are left for the diff
+	rendered := make([]Variable, 0, len(template.Variables))
+	for _, v := range template.Variables {
+		if _, ok := values[v.Key]; !ok && v.Required && v.DefaultValue == nil {
+			upgrade.MissingVariables = append(upgrade.MissingVariables, v)
+			continue
+		}
+		rendered = append(rendered, v)
+	}
+
+	definition, processed, err := tm.render(template.Workflow, rendered, values)
+	if err != nil {
+		return nil, err
+	}
+	upgraded := *wf
+	upgraded.Nodes = definition.Nodes
+	upgraded.Connections = definition.Connections
+	// The notes of the workflow are its own, those of nodes the new version
+	// drops go with them
+	upgraded.PruneAnnotations()
+	upgrade.Workflow = &upgraded
+	upgrade.Diff = workflow.DiffDefinitions(wf, &upgraded)
+	upgrade.values = kept(template.Variables, processed)
+	return upgrade, nil
+}
+
+// RecordUpgrade records the version a workflow was upgraded to and the
+// variable values it was upgraded with
+func (tm *TemplateManager) RecordUpgrade(ctx context.Context, upgrade *Upgrade) error {
+	return tm.db.WithContext(ctx).Model(&Use{}).
+		Where("id = ?", upgrade.use.ID).
+		Select("version", "variables", "updated_at").
+		Updates(&Use{Version: upgrade.ToVersion, Variables: upgrade.values, UpdatedAt: time.Now()}).Error
+}
+
+// render parses the workflow of a template and substitutes its variables,
+// returning the values applied
+func (tm *TemplateManager) render(definition json.RawMessage, templateVars []Variable, variables map[string]interface{}) (*workflow.Workflow, map[string]interface{}, error) {
+	processed, err := tm.processVariables(templateVars, variables)
+	if err != nil {
+		return nil, nil, fmt.Errorf("variable processing failed: %w", err)
+	}
+
+	wf, err := tm.applyVariables(definition, templateVars, processed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to apply variables: %w", err)
+	}
+	return wf, processed, nil
+}
+
+// kept are the variable values kept for upgrades, secrets are given again
+func kept(templateVars []Variable, values map[string]interface{}) map[string]interface{} {
+	secret := make(map[string]bool)
+	for _, v := range templateVars {
+		if v.Type == VariableTypeSecret {
+			secret[v.Key] = true
+		}
+	}
+
+	result := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if !secret[k] {
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,129 @@
+package triggers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// UpcomingTrigger is a scheduled trigger and the time it next fires
+type UpcomingTrigger struct {
+	TriggerID  string    `json:"triggerId"`
+	WorkflowID string    `json:"workflowId"`
+	Name       string    `json:"name"`
+	NextFire   time.Time `json:"nextFire"`
+}
+
+// PauseWorkspaceSchedules pauses every active schedule trigger belonging to
+// workflows of a workspace and returns the IDs of the paused triggers
+func (tm *TriggerManager) PauseWorkspaceSchedules(ctx context.Context, workspaceID string) ([]string, error) {
+	var triggers []*workflow.WorkflowTrigger
+	err := tm.db.WithContext(ctx).
+		Where("type = ? AND status = ?", workflow.TriggerTypeSchedule, workflow.TriggerStatusActive).
+		Where("workflow_id IN (?)", tm.db.Model(&workflow.Workflow{}).Select("id").Where("team_id = ?", workspaceID)).
+		Find(&triggers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace schedules: %w", err)
+	}
+
+	paused := make([]string, 0, len(triggers))
+	for _, trigger := range triggers {
+		if err := tm.deactivateScheduleTrigger(trigger.ID); err != nil {
+			return paused, fmt.Errorf("failed to pause trigger %s: %w", trigger.ID, err)
+		}
+
+		trigger.Status = workflow.TriggerStatusPaused
+		trigger.UpdatedAt = time.Now()
+		if err := tm.db.WithContext(ctx).Save(trigger).Error; err != nil {
+			return paused, fmt.Errorf("failed to update trigger status: %w", err)
+		}
+
+		tm.publishEvent(ctx, "trigger.paused", map[string]interface{}{
+			"trigger_id":   trigger.ID,
+			"workflow_id":  trigger.WorkflowID,
+			"workspace_id": workspaceID,
+		})
+
+		paused = append(paused, trigger.ID)
+	}
+
+	tm.logger.Info("Workspace schedules paused", "workspace_id", workspaceID, "count", len(paused))
+	return paused, nil
+}
+
+// ListUpcomingTriggers lists schedule triggers that fire within the window,
+// soonest first
+func (tm *TriggerManager) ListUpcomingTriggers(ctx context.Context, window time.Duration) ([]*UpcomingTrigger, error) {
+	deadline := time.Now().Add(window)
+
+	nextFire := make(map[string]time.Time)
+	tm.mu.RLock()
+	for triggerID, entryID := range tm.schedules {
+		entry := tm.cronScheduler.Entry(*entryID)
+		if entry.Valid() && !entry.Next.IsZero() && !entry.Next.After(deadline) {
+			nextFire[triggerID] = entry.Next
+		}
+	}
+	tm.mu.RUnlock()
+
+	if len(nextFire) == 0 {
+		return []*UpcomingTrigger{}, nil
+	}
+
+	ids := make([]string, 0, len(nextFire))
+	for id := range nextFire {
+		ids = append(ids, id)
+	}
+
+	var triggers []*workflow.WorkflowTrigger
+	if err := tm.db.WithContext(ctx).Where("id IN ?", ids).Find(&triggers).Error; err != nil {
+		return nil, fmt.Errorf("failed to load triggers: %w", err)
+	}
+
+	upcoming := make([]*UpcomingTrigger, 0, len(triggers))
+	for _, trigger := range triggers {
+		upcoming = append(upcoming, &UpcomingTrigger{
+			TriggerID:  trigger.ID,
+			WorkflowID: trigger.WorkflowID,
+			Name:       trigger.Name,
+			NextFire:   nextFire[trigger.ID],
+		})
+	}
+
+	sort.Slice(upcoming, func(i, j int) bool {
+		return upcoming[i].NextFire.Before(upcoming[j].NextFire)
+	})
+
+	return upcoming, nil
+}
+
+// ReloadTrigger re-reads a trigger definition from the database and
+// re-registers it, picking up config edited outside the manager
+func (tm *TriggerManager) ReloadTrigger(ctx context.Context, triggerID string) (*workflow.WorkflowTrigger, error) {
+	trigger, err := tm.GetTrigger(ctx, triggerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tm.deactivateTrigger(ctx, trigger); err != nil {
+		return nil, fmt.Errorf("failed to unload trigger: %w", err)
+	}
+
+	if trigger.Status == workflow.TriggerStatusActive {
+		if err := tm.activateTrigger(ctx, trigger); err != nil {
+			return nil, fmt.Errorf("failed to activate trigger: %w", err)
+		}
+	}
+
+	tm.publishEvent(ctx, "trigger.reloaded", map[string]interface{}{
+		"trigger_id":  trigger.ID,
+		"workflow_id": trigger.WorkflowID,
+		"status":      trigger.Status,
+	})
+
+	tm.logger.Info("Trigger reloaded", "trigger_id", triggerID, "status", trigger.Status)
+	return trigger, nil
+}
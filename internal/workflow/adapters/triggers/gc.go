@@ -0,0 +1,50 @@
+package triggers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/redisgc"
+)
+
+// GCRules tags the Redis keys of event and email triggers, kept without TTL
+// while their trigger is active. Keys of triggers deactivated or deleted
+// without their keys, e.g. when the status update failed, are orphans.
+func (tm *TriggerManager) GCRules() []redisgc.Rule {
+	return []redisgc.Rule{
+		{
+			// trigger:event:<eventType>:<triggerID>
+			Owner:   "workflow.trigger.event",
+			Pattern: "trigger:event:*",
+			OwnerID: lastSegment,
+			Live:    tm.activeTriggers,
+		},
+		{
+			// trigger:email:<triggerID>
+			Owner:   "workflow.trigger.email",
+			Pattern: "trigger:email:*",
+			OwnerID: lastSegment,
+			Live:    tm.activeTriggers,
+		},
+	}
+}
+
+// activeTriggers returns the IDs among ids of the active triggers
+func (tm *TriggerManager) activeTriggers(ctx context.Context, ids []string) ([]string, error) {
+	var active []string
+	err := tm.db.WithContext(ctx).
+		Model(&workflow.WorkflowTrigger{}).
+		Where("id IN ? AND status = ?", ids, workflow.TriggerStatusActive).
+		Pluck("id", &active).Error
+	return active, err
+}
+
+// lastSegment returns the part of key after its last colon
+func lastSegment(key string) string {
+	i := strings.LastIndex(key, ":")
+	if i < 0 {
+		return ""
+	}
+	return key[i+1:]
+}
@@ -3,27 +3,30 @@ package triggers
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/database"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/quota"
+	"github.com/linkflow-go/pkg/tenant"
 	"github.com/redis/go-redis/v9"
 	"github.com/robfig/cron/v3"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrTriggerNotFound      = errors.New("trigger not found")
-	ErrInvalidTriggerType   = errors.New("invalid trigger type")
-	ErrTriggerAlreadyActive = errors.New("trigger already active")
-	ErrTriggerNotActive     = errors.New("trigger not active")
-	ErrWorkflowNotActive    = errors.New("workflow not active")
-	ErrDuplicateTrigger     = errors.New("duplicate trigger exists")
+	ErrTriggerNotFound      = apperrors.New(apperrors.CategoryNotFound, "TRIGGER_NOT_FOUND", "trigger not found")
+	ErrInvalidTriggerType   = apperrors.New(apperrors.CategoryValidation, "INVALID_TRIGGER_TYPE", "invalid trigger type")
+	ErrTriggerAlreadyActive = apperrors.New(apperrors.CategoryConflict, "TRIGGER_ALREADY_ACTIVE", "trigger already active")
+	ErrTriggerNotActive     = apperrors.New(apperrors.CategoryConflict, "TRIGGER_NOT_ACTIVE", "trigger not active")
+	ErrWorkflowNotActive    = apperrors.New(apperrors.CategoryValidation, "WORKFLOW_INACTIVE", "workflow not active")
+	ErrDuplicateTrigger     = apperrors.New(apperrors.CategoryConflict, "TRIGGER_EXISTS", "duplicate trigger exists")
 )
 
 // TriggerManager manages workflow triggers
@@ -38,6 +41,13 @@ type TriggerManager struct {
 	schedules     map[string]*cron.EntryID
 	mu            sync.RWMutex
 	shutdownCh    chan struct{}
+	quota         *quota.Enforcer
+
+	// draining refuses new fires once Stop is called, inflight counts the
+	// fires still publishing
+	draining     bool
+	inflight     sync.WaitGroup
+	drainTimeout time.Duration
 }
 
 // NewTriggerManager creates a new trigger manager
@@ -55,6 +65,19 @@ func NewTriggerManager(db *database.DB, redis *redis.Client, eventBus events.Eve
 	}
 }
 
+// SetQuota wires the enforcer skipping the schedules of tenants at the
+// execution limits of their plan
+func (tm *TriggerManager) SetQuota(enforcer *quota.Enforcer) {
+	tm.quota = enforcer
+}
+
+// SetDrainTimeout bounds how long Stop waits for in-flight fires and
+// persists webhook state, within the deadline of its context. Zero only
+// uses the context deadline.
+func (tm *TriggerManager) SetDrainTimeout(timeout time.Duration) {
+	tm.drainTimeout = timeout
+}
+
 // Start starts the trigger manager
 func (tm *TriggerManager) Start(ctx context.Context) error {
 	tm.logger.Info("Starting trigger manager")
@@ -77,14 +100,48 @@ func (tm *TriggerManager) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop stops the trigger manager
+// Stop drains the trigger manager: it stops accepting new fires, waits for
+// the fires in flight to publish, persists the state of the registered
+// webhooks, and only then clears the active triggers. Fires still running
+// at the deadline are abandoned.
 func (tm *TriggerManager) Stop(ctx context.Context) error {
 	tm.logger.Info("Stopping trigger manager")
 
+	if tm.drainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tm.drainTimeout)
+		defer cancel()
+	}
+
+	tm.mu.Lock()
+	if tm.draining {
+		tm.mu.Unlock()
+		return nil
+	}
+	tm.draining = true
+	tm.mu.Unlock()
+
 	close(tm.shutdownCh)
 
-	// Stop cron scheduler
-	tm.cronScheduler.Stop()
+	// Stop scheduling fires, then wait for the running ones
+	cronDone := tm.cronScheduler.Stop()
+	drained := make(chan struct{})
+	go func() {
+		<-cronDone.Done()
+		tm.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		tm.logger.Warn("Trigger manager drain deadline reached, in-flight fires abandoned")
+	}
+
+	err := tm.persistWebhooks(ctx)
+	if err != nil {
+		tm.logger.Error("Failed to persist webhook state", "error", err)
+	}
 
 	// Clear active triggers
 	tm.mu.Lock()
@@ -93,7 +150,54 @@ func (tm *TriggerManager) Stop(ctx context.Context) error {
 	tm.mu.Unlock()
 
 	tm.logger.Info("Trigger manager stopped")
-	return nil
+	return err
+}
+
+// beginFire registers a fire unless the manager is draining, the fire
+// must call tm.inflight.Done once published
+func (tm *TriggerManager) beginFire() bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if tm.draining {
+		return false
+	}
+	tm.inflight.Add(1)
+	return true
+}
+
+// persistWebhooks saves the fires the registered webhooks counted in memory
+// since they were registered to their triggers
+func (tm *TriggerManager) persistWebhooks(ctx context.Context) error {
+	tm.mu.RLock()
+	webhooks := make([]*workflow.WebhookTrigger, 0, len(tm.webhooks))
+	for _, webhook := range tm.webhooks {
+		if webhook.FireCount > 0 {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	tm.mu.RUnlock()
+
+	var failed error
+	for _, webhook := range webhooks {
+		updates := map[string]interface{}{
+			"fire_count": gorm.Expr("fire_count + ?", webhook.FireCount),
+		}
+		if webhook.LastFired != nil {
+			updates["last_fired"] = *webhook.LastFired
+		}
+		err := tm.db.WithContext(ctx).
+			Model(&workflow.WorkflowTrigger{}).
+			Where("id = ?", webhook.ID).
+			Updates(updates).Error
+		if err != nil {
+			failed = fmt.Errorf("failed to persist webhook %s: %w", webhook.ID, err)
+			continue
+		}
+		webhook.FireCount = 0
+	}
+
+	tm.logger.Info("Webhook state persisted", "count", len(webhooks))
+	return failed
 }
 
 // CreateTrigger creates a new trigger for a workflow
@@ -107,6 +211,13 @@ func (tm *TriggerManager) CreateTrigger(ctx context.Context, workflowID string,
 		return nil, ErrInvalidTriggerType
 	}
 
+	// The environment the trigger starts executions in is not part of the
+	// configuration of its type
+	environmentID, err := tm.triggerEnvironment(ctx, workflowID, config)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create trigger instance
 	trigger, err := tm.factory.CreateTrigger(triggerType, config)
 	if err != nil {
@@ -131,15 +242,16 @@ func (tm *TriggerManager) CreateTrigger(ctx context.Context, workflowID string,
 
 	// Create database record
 	wt := &workflow.WorkflowTrigger{
-		ID:          trigger.GetID(),
-		WorkflowID:  workflowID,
-		Type:        triggerType,
-		Name:        config["name"].(string),
-		Description: getStringFromConfig(config, "description"),
-		Status:      workflow.TriggerStatusInactive,
-		Config:      configJSON,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:            trigger.GetID(),
+		WorkflowID:    workflowID,
+		Type:          triggerType,
+		Name:          config["name"].(string),
+		Description:   getStringFromConfig(config, "description"),
+		Status:        workflow.TriggerStatusInactive,
+		Config:        configJSON,
+		EnvironmentID: environmentID,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	// Save to database
@@ -196,6 +308,14 @@ func (tm *TriggerManager) UpdateTrigger(ctx context.Context, triggerID string, u
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	if _, ok := updates["environment"]; ok {
+		environmentID, err := tm.triggerEnvironment(ctx, trigger.WorkflowID, updates)
+		if err != nil {
+			return nil, err
+		}
+		trigger.EnvironmentID = environmentID
+	}
+
 	// Merge updates
 	for key, value := range updates {
 		if key != "id" && key != "workflowId" && key != "type" {
@@ -364,8 +484,20 @@ func (tm *TriggerManager) TestTrigger(ctx context.Context, triggerID string, tes
 		return nil, fmt.Errorf("failed to create trigger instance: %w", err)
 	}
 
-	// Test trigger
-	shouldFire := triggerInstance.ShouldFire(testData)
+	// The trigger is tested as if active, schedules at the scheduled_time
+	// of the data or now
+	triggerInstance.SetStatus(workflow.TriggerStatusActive)
+	var event interface{} = testData
+	if trigger.Type == workflow.TriggerTypeSchedule {
+		scheduled := time.Now()
+		if value, ok := testData["scheduled_time"].(string); ok {
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				scheduled = t
+			}
+		}
+		event = scheduled
+	}
+	shouldFire := triggerInstance.ShouldFire(event)
 
 	result := map[string]interface{}{
 		"trigger_id":   triggerID,
@@ -535,27 +667,103 @@ func (tm *TriggerManager) deactivateEmailTrigger(triggerID string) error {
 
 // fireScheduleTrigger fires a schedule trigger
 func (tm *TriggerManager) fireScheduleTrigger(triggerID, workflowID string) {
-	ctx := context.Background()
+	if !tm.beginFire() {
+		tm.logger.Warn("Schedule trigger skipped, trigger manager draining",
+			"trigger_id", triggerID, "workflow_id", workflowID)
+		return
+	}
+	defer tm.inflight.Done()
+
+	ctx := tm.workflowTenant(context.Background(), workflowID)
+	firedAt := time.Now()
+
+	// A slot refused by the plan of the tenant is skipped, not queued
+	if err := tm.quota.CheckExecution(ctx); err != nil {
+		tm.logger.Warn("Schedule trigger skipped by tenant quota",
+			"trigger_id", triggerID, "workflow_id", workflowID, "error", err)
+		tm.db.Model(&workflow.WorkflowTrigger{}).
+			Where("id = ?", triggerID).
+			Updates(map[string]interface{}{
+				"error_count": gorm.Expr("error_count + 1"),
+				"last_error":  err.Error(),
+			})
+		return
+	}
 
 	// Update last fired time
 	tm.db.Model(&workflow.WorkflowTrigger{}).
 		Where("id = ?", triggerID).
 		Updates(map[string]interface{}{
-			"last_fired": time.Now(),
+			"last_fired": firedAt,
 			"fire_count": gorm.Expr("fire_count + 1"),
 		})
 
-	// Publish execution event
-	tm.publishEvent(ctx, "trigger.fired", map[string]interface{}{
-		"trigger_id":  triggerID,
-		"workflow_id": workflowID,
-		"type":        workflow.TriggerTypeSchedule,
-		"data":        map[string]interface{}{"scheduled_time": time.Now()},
-	})
+	// Publish execution event; the idempotency key is stable per cron slot so
+	// replicas firing the same schedule only start one execution
+	payload := map[string]interface{}{
+		"trigger_id":     triggerID,
+		"workflow_id":    workflowID,
+		"type":           workflow.TriggerTypeSchedule,
+		"idempotencyKey": fmt.Sprintf("trigger:%s:%d", triggerID, firedAt.Truncate(time.Minute).Unix()),
+		"data":           map[string]interface{}{"scheduled_time": firedAt},
+	}
+	var environmentID string
+	tm.db.WithContext(ctx).
+		Model(&workflow.WorkflowTrigger{}).
+		Select("environment_id").
+		Where("id = ?", triggerID).
+		Scan(&environmentID)
+	if environmentID != "" {
+		payload["environment"] = environmentID
+	}
+	tm.publishEvent(ctx, "trigger.fired", payload)
 
 	tm.logger.Info("Schedule trigger fired", "trigger_id", triggerID, "workflow_id", workflowID)
 }
 
+// triggerEnvironment takes the environment, by ID or name, out of the
+// configuration of a trigger and returns its ID. No environment is the
+// default environment of the workflow.
+func (tm *TriggerManager) triggerEnvironment(ctx context.Context, workflowID string, config map[string]interface{}) (string, error) {
+	environment, _ := config["environment"].(string)
+	delete(config, "environment")
+	if environment == "" {
+		return "", nil
+	}
+
+	// IDs are UUIDs, looking a name up as an ID fails on Postgres
+	column := "name"
+	if _, err := uuid.Parse(environment); err == nil {
+		column = "id"
+	}
+
+	var env workflow.Environment
+	err := tm.db.WithContext(ctx).
+		Where("workflow_id = ? AND "+column+" = ?", workflowID, environment).
+		First(&env).Error
+	if err == gorm.ErrRecordNotFound {
+		return "", workflow.ErrEnvironmentNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return env.ID, nil
+}
+
+// workflowTenant scopes ctx to the tenant owning a workflow, triggers fire
+// outside of any request
+func (tm *TriggerManager) workflowTenant(ctx context.Context, workflowID string) context.Context {
+	var tenantID string
+	if err := tm.db.WithContext(ctx).
+		Model(&workflow.Workflow{}).
+		Select("tenant_id").
+		Where("id = ?", workflowID).
+		Scan(&tenantID).Error; err != nil || tenantID == "" {
+		return ctx
+	}
+	return tenant.WithTenant(ctx, tenantID)
+}
+
 // loadActiveTriggers loads all active triggers on startup
 func (tm *TriggerManager) loadActiveTriggers(ctx context.Context) error {
 	var triggers []*workflow.WorkflowTrigger
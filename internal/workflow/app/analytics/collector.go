@@ -3,13 +3,13 @@ package analytics
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"sync"
 	"time"
 
 	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/rediskey"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
@@ -354,7 +354,7 @@ func (sc *StatsCollector) GetTimeSeries(ctx context.Context, workflowID string,
 	// This would typically query from a time-series database
 	// For now, we'll use Redis to store recent time-series data
 
-	key := fmt.Sprintf("timeseries:%s:%s", workflowID, interval)
+	key := rediskey.Tenant(ctx, "timeseries", workflowID, interval)
 	data, err := sc.redis.Get(ctx, key).Result()
 	if err != nil {
 		return []TimeSeriesData{}, nil
@@ -416,7 +416,7 @@ func (sc *StatsCollector) flush(ctx context.Context) {
 
 // storeInRedis stores statistics in Redis for fast access
 func (sc *StatsCollector) storeInRedis(ctx context.Context, stats *WorkflowStats) {
-	key := fmt.Sprintf("stats:workflow:%s", stats.WorkflowID)
+	key := rediskey.Tenant(ctx, "stats", "workflow", stats.WorkflowID)
 	data, _ := json.Marshal(stats)
 
 	// Store with TTL
@@ -425,7 +425,7 @@ func (sc *StatsCollector) storeInRedis(ctx context.Context, stats *WorkflowStats
 
 // getFromRedis retrieves statistics from Redis
 func (sc *StatsCollector) getFromRedis(ctx context.Context, workflowID string) *WorkflowStats {
-	key := fmt.Sprintf("stats:workflow:%s", workflowID)
+	key := rediskey.Tenant(ctx, "stats", "workflow", workflowID)
 	data, err := sc.redis.Get(ctx, key).Result()
 	if err != nil {
 		return nil
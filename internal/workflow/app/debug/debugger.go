@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/rediskey"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -30,10 +32,10 @@ const (
 )
 
 var (
-	ErrSessionNotFound      = errors.New("debug session not found")
-	ErrSessionAlreadyExists = errors.New("debug session already exists")
-	ErrInvalidBreakpoint    = errors.New("invalid breakpoint")
-	ErrNotPaused            = errors.New("session not paused")
+	ErrSessionNotFound      = apperrors.New(apperrors.CategoryNotFound, "DEBUG_SESSION_NOT_FOUND", "debug session not found")
+	ErrSessionAlreadyExists = apperrors.New(apperrors.CategoryConflict, "DEBUG_SESSION_EXISTS", "debug session already exists")
+	ErrInvalidBreakpoint    = apperrors.New(apperrors.CategoryValidation, "INVALID_BREAKPOINT", "invalid breakpoint")
+	ErrNotPaused            = apperrors.New(apperrors.CategoryConflict, "DEBUG_SESSION_NOT_PAUSED", "session not paused")
 )
 
 // DebugSession represents a workflow debug session
@@ -554,13 +556,13 @@ func (d *Debugger) Subscribe(sessionID string) (<-chan DebugEvent, func()) {
 // Helper methods
 
 func (d *Debugger) storeSession(ctx context.Context, session *DebugSession) {
-	key := fmt.Sprintf("debug:session:%s", session.ID)
+	key := rediskey.Tenant(ctx, "debug", "session", session.ID)
 	data, _ := json.Marshal(session)
 	d.redis.Set(ctx, key, string(data), 1*time.Hour)
 }
 
 func (d *Debugger) loadSession(ctx context.Context, sessionID string) *DebugSession {
-	key := fmt.Sprintf("debug:session:%s", sessionID)
+	key := rediskey.Tenant(ctx, "debug", "session", sessionID)
 	data, err := d.redis.Get(ctx, key).Result()
 	if err != nil {
 		return nil
@@ -572,7 +574,7 @@ func (d *Debugger) loadSession(ctx context.Context, sessionID string) *DebugSess
 }
 
 func (d *Debugger) deleteSession(ctx context.Context, sessionID string) {
-	key := fmt.Sprintf("debug:session:%s", sessionID)
+	key := rediskey.Tenant(ctx, "debug", "session", sessionID)
 	d.redis.Del(ctx, key)
 }
 
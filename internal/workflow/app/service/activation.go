@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// enforceActivationPolicy checks version, a definition of the workflow
+// current, against an activation policy: it must validate without errors
+// and its latest finished test run must have passed, as the policy
+// requires. An override, which callers allow platform admins only, lets
+// the version through failing checks.
+func (s *WorkflowService) enforceActivationPolicy(ctx context.Context, policy *workflow.ActivationPolicy, current, version *workflow.Workflow, userID string, override bool) error {
+	failures, err := s.activationFailures(ctx, policy, version)
+	if err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	if !override {
+		return workflow.ErrActivationBlocked.
+			WithMessage("workflow version %d does not pass the activation policy: %s", version.Version, strings.Join(failures, "; ")).
+			WithDetail("failures", failures)
+	}
+	s.logger.Warn("Activation policy overridden",
+		"workflow_id", current.ID,
+		"version", version.Version,
+		"user_id", userID,
+		"failures", failures,
+	)
+	return nil
+}
+
+// activationFailures describes the checks of an activation policy a
+// definition of a workflow fails
+func (s *WorkflowService) activationFailures(ctx context.Context, policy *workflow.ActivationPolicy, wf *workflow.Workflow) ([]string, error) {
+	failures := []string{}
+
+	if policy.RequireValid {
+		errs, _, err := s.validationService.ValidateWorkflow(ctx, wf)
+		if err == nil {
+			if dagErr := s.validationService.ValidateDAG(ctx, wf); dagErr != nil {
+				errs = append(errs, dagErr.Error())
+			}
+		} else if len(errs) == 0 {
+			errs = append(errs, err.Error())
+		}
+		for _, e := range errs {
+			failures = append(failures, "validation: "+e)
+		}
+	}
+
+	if policy.RequireTests {
+		runs, err := s.repo.ListTestRuns(ctx, wf.ID, testRunsListed)
+		if err != nil {
+			return nil, err
+		}
+		var latest *workflow.WorkflowTestRun
+		for _, run := range runs {
+			if run.Version == wf.Version && run.Status != workflow.TestRunRunning {
+				latest = run
+				break
+			}
+		}
+		switch {
+		case latest == nil:
+			failures = append(failures, fmt.Sprintf("tests: no test run of version %d finished", wf.Version))
+		case latest.Status != workflow.TestRunPassed:
+			failures = append(failures, fmt.Sprintf("tests: %d of %d cases failed in test run %s", latest.Failed, latest.Passed+latest.Failed, latest.ID))
+		}
+	}
+
+	return failures, nil
+}
+
+// deployedDefinition returns the definition of a stored version of a
+// workflow
+func (s *WorkflowService) deployedDefinition(ctx context.Context, workflowID string, version int) (*workflow.Workflow, error) {
+	wv, err := s.repo.GetVersion(ctx, workflowID, version)
+	if err != nil {
+		return nil, workflow.ErrInvalidPromotion.WithMessage("version %d not found", version)
+	}
+	var wf workflow.Workflow
+	if err := json.Unmarshal([]byte(wv.Data), &wf); err != nil {
+		return nil, err
+	}
+	wf.ID = workflowID
+	wf.Version = wv.Version
+	return &wf, nil
+}
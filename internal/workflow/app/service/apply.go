@@ -0,0 +1,611 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// applyTarget is a declared workflow resolved against the workflow it
+// manages, with the changes reconciling them
+type applyTarget struct {
+	spec    workflow.ApplyWorkflow
+	managed *workflow.ManagedWorkflow
+	// existing is nil when the workflow is to be created, desired is the
+	// workflow as declared
+	existing  *workflow.Workflow
+	desired   *workflow.Workflow
+	change    workflow.ApplyChange
+	triggers  []triggerChange
+	variables []variableChange
+}
+
+type triggerChange struct {
+	action   string
+	existing *workflow.WorkflowTrigger
+	spec     *workflow.ApplyTrigger
+}
+
+type variableChange struct {
+	action string
+	key    string
+	spec   *workflow.ApplyVariable
+	fields []string
+}
+
+// Apply reconciles the workflows an apply created in the namespace of a
+// bundle with it: declared workflows missing are created, those differing
+// updated as a new version, with their triggers and variables, and with
+// Prune those no longer declared deleted. The whole bundle is resolved and
+// validated before anything changes. With dryRun only the plan is
+// returned. An apply failing part way leaves the changes made so far,
+// applying the bundle again completes it.
+func (s *WorkflowService) Apply(ctx context.Context, userID, workspaceID string, bundle *workflow.ApplyBundle, dryRun bool) (*workflow.ApplyPlan, error) {
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+	if workspaceID == "" {
+		workspaceID = userID
+	}
+
+	managed, err := s.repo.ListManagedWorkflows(ctx, workspaceID, bundle.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*workflow.ManagedWorkflow, len(managed))
+	for _, m := range managed {
+		byKey[m.Key] = m
+	}
+
+	credentials, err := s.resolveCredentials(ctx, userID, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &workflow.ApplyPlan{Namespace: bundle.Namespace, Changes: []workflow.ApplyChange{}}
+	targets := make([]*applyTarget, 0, len(bundle.Workflows))
+	for _, spec := range bundle.Workflows {
+		target, err := s.planWorkflow(ctx, userID, workspaceID, spec, byKey[spec.Key], credentials)
+		if err != nil {
+			return nil, err
+		}
+		delete(byKey, spec.Key)
+		targets = append(targets, target)
+
+		plan.Add(target.change)
+		for _, t := range target.triggers {
+			plan.Add(workflow.ApplyChange{Action: t.action, Kind: workflow.ApplyKindTrigger, Key: spec.Key, Name: t.name(), WorkflowID: target.change.WorkflowID})
+		}
+		for _, v := range target.variables {
+			plan.Add(workflow.ApplyChange{Action: v.action, Kind: workflow.ApplyKindVariable, Key: spec.Key, Name: v.key, WorkflowID: target.change.WorkflowID, Fields: v.fields})
+		}
+	}
+
+	var pruned []*workflow.ManagedWorkflow
+	if bundle.Prune {
+		for _, m := range managed {
+			if _, undeclared := byKey[m.Key]; undeclared {
+				pruned = append(pruned, m)
+				plan.Add(workflow.ApplyChange{Action: workflow.ApplyDelete, Kind: workflow.ApplyKindWorkflow, Key: m.Key, Name: m.Spec.Name, WorkflowID: m.WorkflowID})
+			}
+		}
+	}
+
+	if dryRun {
+		// Workflows are created with another ID when applied
+		created := make(map[string]bool)
+		for _, target := range targets {
+			created[target.spec.Key] = target.change.Action == workflow.ApplyCreate
+		}
+		for i := range plan.Changes {
+			if created[plan.Changes[i].Key] {
+				plan.Changes[i].WorkflowID = ""
+			}
+		}
+		return plan, nil
+	}
+
+	for _, target := range targets {
+		if err := s.applyWorkflow(ctx, userID, workspaceID, bundle.Namespace, target); err != nil {
+			s.logger.Error("Failed to apply workflow", "namespace", bundle.Namespace, "key", target.spec.Key, "error", err)
+			return nil, err
+		}
+	}
+	for _, m := range pruned {
+		if err := s.DeleteWorkflow(ctx, m.WorkflowID, userID); err != nil && !errors.Is(err, ErrWorkflowNotFound) {
+			return nil, err
+		}
+		if err := s.repo.DeleteManagedWorkflow(ctx, m.ID); err != nil {
+			return nil, err
+		}
+	}
+	plan.Applied = true
+
+	s.logger.Info("Bundle applied",
+		"namespace", bundle.Namespace,
+		"workspace_id", workspaceID,
+		"creates", plan.Creates,
+		"updates", plan.Updates,
+		"deletes", plan.Deletes,
+	)
+	return plan, nil
+}
+
+// resolveCredentials maps the credential names a bundle uses to the IDs of
+// the credentials of the caller, failing on unknown and ambiguous names
+func (s *WorkflowService) resolveCredentials(ctx context.Context, userID string, bundle *workflow.ApplyBundle) (map[string]string, error) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, wf := range bundle.Workflows {
+		for _, name := range wf.Credentials {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	sort.Strings(names)
+
+	found, err := s.repo.FindCredentialIDs(ctx, userID, names)
+	if err != nil {
+		return nil, err
+	}
+	resolved := make(map[string]string, len(names))
+	for _, name := range names {
+		switch ids := found[name]; len(ids) {
+		case 0:
+			return nil, workflow.ErrUnknownCredential.WithMessage("no credential named %q", name)
+		case 1:
+			resolved[name] = ids[0]
+		default:
+			return nil, workflow.ErrUnknownCredential.WithMessage("%d credentials are named %q", len(ids), name)
+		}
+	}
+	return resolved, nil
+}
+
+// planWorkflow resolves a declared workflow and the changes it needs
+func (s *WorkflowService) planWorkflow(ctx context.Context, userID, workspaceID string, spec workflow.ApplyWorkflow, managed *workflow.ManagedWorkflow, credentials map[string]string) (*applyTarget, error) {
+	target := &applyTarget{spec: spec, managed: managed}
+	// A workflow deleted since it was applied is created again
+	if managed != nil {
+		if wf, err := s.repo.GetWorkflow(ctx, managed.WorkflowID, userID); err == nil {
+			target.existing = wf
+		}
+	}
+
+	desired, err := s.desiredWorkflow(ctx, userID, workspaceID, &spec, target.existing, credentials)
+	if err != nil {
+		return nil, err
+	}
+	target.desired = desired
+
+	target.change = workflow.ApplyChange{
+		Action:     workflow.ApplyCreate,
+		Kind:       workflow.ApplyKindWorkflow,
+		Key:        spec.Key,
+		Name:       spec.Name,
+		WorkflowID: desired.ID,
+	}
+	if target.existing == nil {
+		target.triggers = make([]triggerChange, 0, len(spec.Triggers))
+		for i := range spec.Triggers {
+			target.triggers = append(target.triggers, triggerChange{action: workflow.ApplyCreate, spec: &spec.Triggers[i]})
+		}
+		target.variables = make([]variableChange, 0, len(spec.Variables))
+		for i := range spec.Variables {
+			target.variables = append(target.variables, variableChange{action: workflow.ApplyCreate, key: spec.Variables[i].Key, spec: &spec.Variables[i]})
+		}
+		return target, nil
+	}
+
+	fields := workflow.ChangedFields(target.existing, desired)
+	if !sameJSON(target.existing.Nodes, desired.Nodes) {
+		fields = append(fields, "nodes")
+	}
+	if !sameJSON(target.existing.Connections, desired.Connections) {
+		fields = append(fields, "connections")
+	}
+	if spec.Active != nil && *spec.Active != target.existing.IsActive {
+		fields = append(fields, "active")
+	}
+	target.change.Action = workflow.ApplyNoop
+	if len(fields) > 0 {
+		target.change.Action = workflow.ApplyUpdate
+		target.change.Fields = fields
+	}
+
+	if target.triggers, err = s.planTriggers(ctx, target); err != nil {
+		return nil, err
+	}
+	if target.variables, err = s.planVariables(ctx, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// desiredWorkflow builds the workflow a declaration describes. Settings are
+// declared in full over the defaults of the workspace, as for a new
+// workflow, and credentials are bound to the nodes naming them.
+func (s *WorkflowService) desiredWorkflow(ctx context.Context, userID, workspaceID string, spec *workflow.ApplyWorkflow, existing *workflow.Workflow, credentials map[string]string) (*workflow.Workflow, error) {
+	var desired *workflow.Workflow
+	if existing != nil {
+		copied := *existing
+		desired = &copied
+	} else {
+		desired = workflow.NewWorkflow(spec.Name, spec.Description, userID)
+		if workspaceID != userID {
+			desired.TeamID = workspaceID
+		}
+	}
+	desired.Name = spec.Name
+	desired.Description = spec.Description
+	desired.Tags = spec.Tags
+
+	policy, err := s.GetWorkspacePolicy(ctx, desired.WorkspaceID())
+	if err != nil {
+		return nil, err
+	}
+	desired.Settings = workflow.NewWorkflow("", "", "").Settings
+	policy.Apply(&desired.Settings)
+	if err := applySettings(desired, spec.Settings); err != nil {
+		return nil, err
+	}
+
+	// Copied, the declaration is kept as it was given
+	desired.Nodes = []workflow.Node{}
+	desired.Connections = []workflow.Connection{}
+	if err := copyJSON(spec.Nodes, &desired.Nodes); err != nil {
+		return nil, ErrInvalidWorkflow.WithMessage("workflow %s: %v", spec.Key, err)
+	}
+	if err := copyJSON(spec.Connections, &desired.Connections); err != nil {
+		return nil, ErrInvalidWorkflow.WithMessage("workflow %s: %v", spec.Key, err)
+	}
+	// Annotations are edited in the editor, declarations leave them as they
+	// are but for those of nodes no longer declared
+	desired.PruneAnnotations()
+	for i := range desired.Nodes {
+		name, ok := spec.Credentials[desired.Nodes[i].ID]
+		if !ok {
+			continue
+		}
+		if desired.Nodes[i].Parameters == nil {
+			desired.Nodes[i].Parameters = make(map[string]interface{})
+		}
+		desired.Nodes[i].Parameters["credentialId"] = credentials[name]
+	}
+
+	if len(desired.Nodes) > 0 {
+		if err := desired.Validate(); err != nil {
+			return nil, ErrInvalidWorkflow.WithMessage("workflow %s: %v", spec.Key, err)
+		}
+	}
+	if err := policy.CheckLocked(desired, existing); err != nil {
+		return nil, err
+	}
+	if err := policy.CheckNodeTypes(desired); err != nil {
+		return nil, err
+	}
+	return desired, nil
+}
+
+// planTriggers matches the declared triggers with those of the workflow by
+// name. A trigger changing type is replaced, one whose configuration
+// differs from the one last applied is updated.
+func (s *WorkflowService) planTriggers(ctx context.Context, target *applyTarget) ([]triggerChange, error) {
+	existing, err := s.triggerManager.ListTriggers(ctx, target.existing.ID)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*workflow.WorkflowTrigger, len(existing))
+	for _, t := range existing {
+		byName[t.Name] = t
+	}
+
+	changes := []triggerChange{}
+	for i := range target.spec.Triggers {
+		spec := &target.spec.Triggers[i]
+		current, ok := byName[spec.Name]
+
+		switch {
+		case !ok:
+			changes = append(changes, triggerChange{action: workflow.ApplyCreate, spec: spec})
+		case current.Type != spec.Type:
+			changes = append(changes,
+				triggerChange{action: workflow.ApplyDelete, existing: current},
+				triggerChange{action: workflow.ApplyCreate, spec: spec})
+		default:
+			var applied *workflow.ApplyTrigger
+			if target.managed != nil {
+				applied = target.managed.Spec.Trigger(spec.Name)
+			}
+			if applied == nil || !sameJSON(applied.Config, spec.Config) {
+				changes = append(changes, triggerChange{action: workflow.ApplyUpdate, existing: current, spec: spec})
+			}
+		}
+	}
+
+	for _, t := range existing {
+		if target.spec.Trigger(t.Name) == nil {
+			changes = append(changes, triggerChange{action: workflow.ApplyDelete, existing: t})
+		}
+	}
+	return changes, nil
+}
+
+// planVariables compares the declared variables with those of the
+// workflow, opening secret values to compare them
+func (s *WorkflowService) planVariables(ctx context.Context, target *applyTarget) ([]variableChange, error) {
+	existing, err := s.repo.ListWorkflowVariables(ctx, target.existing.ID)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*workflow.WorkflowVariable, len(existing))
+	for _, v := range existing {
+		byKey[v.Key] = v
+	}
+
+	changes := []variableChange{}
+	for i := range target.spec.Variables {
+		spec := &target.spec.Variables[i]
+		current, ok := byKey[spec.Key]
+		delete(byKey, spec.Key)
+		if !ok {
+			changes = append(changes, variableChange{action: workflow.ApplyCreate, key: spec.Key, spec: spec})
+			continue
+		}
+
+		var fields []string
+		if current.Type != spec.Type {
+			fields = append(fields, "type")
+		}
+		if current.Description != spec.Description {
+			fields = append(fields, "description")
+		}
+		same, err := s.sameVariableValue(current, spec)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			fields = append(fields, "value")
+		}
+		if len(fields) > 0 {
+			changes = append(changes, variableChange{action: workflow.ApplyUpdate, key: spec.Key, spec: spec, fields: fields})
+		}
+	}
+
+	for _, v := range existing {
+		if _, ok := byKey[v.Key]; ok {
+			changes = append(changes, variableChange{action: workflow.ApplyDelete, key: v.Key})
+		}
+	}
+	return changes, nil
+}
+
+// sameVariableValue reports whether a variable holds the declared value
+func (s *WorkflowService) sameVariableValue(current *workflow.WorkflowVariable, spec *workflow.ApplyVariable) (bool, error) {
+	if !current.Encrypted || spec.Type != workflow.VarTypeSecret {
+		return sameJSON(current.Value, spec.Value), nil
+	}
+
+	if s.sealer == nil {
+		return false, workflow.ErrSecretsUnavailable
+	}
+	sealedValue, _ := current.Value.(string)
+	plaintext, err := s.sealer.Open(sealedValue)
+	if err != nil {
+		// Sealed with a key since rotated away, set again
+		return false, nil
+	}
+	declared, err := workflow.SecretPlaintext(spec.Value)
+	if err != nil {
+		return false, apperrors.InvalidRequest(err)
+	}
+	return plaintext == declared, nil
+}
+
+// applyWorkflow makes the changes planned for a declared workflow: the
+// definition, then variables, triggers and activation. The declaration is
+// recorded last, triggers being compared with it.
+func (s *WorkflowService) applyWorkflow(ctx context.Context, userID, workspaceID, namespace string, target *applyTarget) error {
+	wf := target.desired
+	managed := target.managed
+	if managed == nil {
+		managed = &workflow.ManagedWorkflow{
+			WorkspaceID: workspaceID,
+			Namespace:   namespace,
+			Key:         target.spec.Key,
+			CreatedAt:   time.Now(),
+		}
+	}
+	managed.WorkflowID = wf.ID
+	managed.AppliedBy = userID
+	managed.UpdatedAt = time.Now()
+
+	switch target.change.Action {
+	case workflow.ApplyCreate:
+		// Recorded with the workflow, so a failure later on does not leave
+		// a workflow the next apply would create again
+		err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+			if err := s.repo.CreateWorkflow(ctx, wf); err != nil {
+				return err
+			}
+			if err := s.repo.SaveManagedWorkflow(ctx, managed); err != nil {
+				return err
+			}
+			return s.eventBus.Publish(ctx, events.Event{
+				Type: "workflow.created",
+				Payload: map[string]interface{}{
+					"workflow_id": wf.ID,
+					"user_id":     wf.UserID,
+					"name":        wf.Name,
+				},
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+	case workflow.ApplyUpdate:
+		if s.definitionChanged(target) {
+			previousVersion := target.existing.Version
+			err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+				if err := s.repo.UpdateWithVersion(ctx, wf, fmt.Sprintf("Applied from namespace %s", namespace)); err != nil {
+					return err
+				}
+				return s.eventBus.Publish(ctx, events.Event{
+					Type: "workflow.updated",
+					Payload: map[string]interface{}{
+						"workflow_id":      wf.ID,
+						"user_id":          userID,
+						"version":          wf.Version,
+						"previous_version": previousVersion,
+					},
+				})
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, change := range target.variables {
+		if err := s.applyVariable(ctx, wf.ID, userID, change); err != nil {
+			return err
+		}
+	}
+
+	active := wf.IsActive
+	if target.spec.Active != nil {
+		active = *target.spec.Active
+	}
+	for _, change := range target.triggers {
+		// Triggers created on a workflow staying active start right away,
+		// activating the workflow starts the others
+		if err := s.applyTrigger(ctx, wf.ID, change, active && wf.IsActive); err != nil {
+			return err
+		}
+	}
+
+	if active != wf.IsActive {
+		var err error
+		if active {
+			_, err = s.ActivateWorkflow(ctx, wf.ID, userID, false)
+		} else {
+			_, err = s.DeactivateWorkflow(ctx, wf.ID, userID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	managed.Spec = target.spec
+	return s.repo.SaveManagedWorkflow(ctx, managed)
+}
+
+// definitionChanged reports whether an update changes more than the
+// activation of the workflow
+func (s *WorkflowService) definitionChanged(target *applyTarget) bool {
+	for _, field := range target.change.Fields {
+		if field != "active" {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *WorkflowService) applyVariable(ctx context.Context, workflowID, userID string, change variableChange) error {
+	if change.action == workflow.ApplyDelete {
+		err := s.DeleteWorkflowVariable(ctx, workflowID, userID, change.key)
+		if errors.Is(err, workflow.ErrVariableNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return s.SetWorkflowVariable(ctx, workflowID, userID, &workflow.WorkflowVariable{
+		Key:         change.spec.Key,
+		Name:        change.spec.Key,
+		Type:        change.spec.Type,
+		Value:       change.spec.Value,
+		Description: change.spec.Description,
+		Scope:       workflow.ScopeWorkflow,
+	})
+}
+
+func (s *WorkflowService) applyTrigger(ctx context.Context, workflowID string, change triggerChange, activate bool) error {
+	switch change.action {
+	case workflow.ApplyDelete:
+		return s.triggerManager.DeleteTrigger(ctx, change.existing.ID)
+
+	case workflow.ApplyUpdate:
+		_, err := s.triggerManager.UpdateTrigger(ctx, change.existing.ID, change.config())
+		return err
+
+	default:
+		trigger, err := s.triggerManager.CreateTrigger(ctx, workflowID, change.config())
+		if err != nil {
+			return err
+		}
+		if activate {
+			return s.triggerManager.ActivateTrigger(ctx, trigger.ID)
+		}
+		return nil
+	}
+}
+
+// name is the name of the trigger changed
+func (c triggerChange) name() string {
+	if c.spec != nil {
+		return c.spec.Name
+	}
+	return c.existing.Name
+}
+
+// config is the configuration the trigger manager takes for the declared
+// trigger
+func (c triggerChange) config() map[string]interface{} {
+	config := make(map[string]interface{}, len(c.spec.Config)+2)
+	for k, v := range c.spec.Config {
+		config[k] = v
+	}
+	config["name"] = c.spec.Name
+	config["type"] = c.spec.Type
+	return config
+}
+
+// sameJSON reports whether two values encode the same, unset and empty
+// collections aside
+func sameJSON(a, b interface{}) bool {
+	left, _ := json.Marshal(a)
+	right, _ := json.Marshal(b)
+	return normalizeJSON(left) == normalizeJSON(right)
+}
+
+func normalizeJSON(data []byte) string {
+	switch s := string(data); s {
+	case "[]", "{}":
+		return "null"
+	default:
+		return s
+	}
+}
+
+// copyJSON deep copies src into dst through its JSON encoding
+func copyJSON(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
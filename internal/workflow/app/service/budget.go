@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetWorkflowBudget returns the budget of a workflow with its spend this
+// month
+func (s *WorkflowService) GetWorkflowBudget(ctx context.Context, workflowID, userID string) (*workflow.BudgetStatus, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	return s.getBudget(ctx, workflow.BudgetScopeWorkflow, workflowID)
+}
+
+// SetWorkflowBudget creates or replaces the budget of a workflow
+func (s *WorkflowService) SetWorkflowBudget(ctx context.Context, workflowID, userID string, req workflow.SetBudgetRequest) (*workflow.BudgetStatus, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	return s.setBudget(ctx, workflow.BudgetScopeWorkflow, workflowID, userID, req)
+}
+
+// DeleteWorkflowBudget removes the budget of a workflow
+func (s *WorkflowService) DeleteWorkflowBudget(ctx context.Context, workflowID, userID string) error {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return ErrWorkflowNotFound
+	}
+	return s.repo.DeleteBudget(ctx, workflow.BudgetScopeWorkflow, workflowID)
+}
+
+// GetWorkspaceBudget returns the budget of a workspace with its spend this
+// month
+func (s *WorkflowService) GetWorkspaceBudget(ctx context.Context, workspaceID string) (*workflow.BudgetStatus, error) {
+	return s.getBudget(ctx, workflow.BudgetScopeWorkspace, workspaceID)
+}
+
+// SetWorkspaceBudget creates or replaces the budget of a workspace, shared
+// by all its workflows
+func (s *WorkflowService) SetWorkspaceBudget(ctx context.Context, workspaceID, userID string, req workflow.SetBudgetRequest) (*workflow.BudgetStatus, error) {
+	return s.setBudget(ctx, workflow.BudgetScopeWorkspace, workspaceID, userID, req)
+}
+
+// DeleteWorkspaceBudget removes the budget of a workspace
+func (s *WorkflowService) DeleteWorkspaceBudget(ctx context.Context, workspaceID string) error {
+	return s.repo.DeleteBudget(ctx, workflow.BudgetScopeWorkspace, workspaceID)
+}
+
+func (s *WorkflowService) getBudget(ctx context.Context, scope, scopeID string) (*workflow.BudgetStatus, error) {
+	budget, err := s.repo.GetBudget(ctx, scope, scopeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.budgetStatus(ctx, budget)
+}
+
+func (s *WorkflowService) setBudget(ctx context.Context, scope, scopeID, userID string, req workflow.SetBudgetRequest) (*workflow.BudgetStatus, error) {
+	budget, err := s.repo.GetBudget(ctx, scope, scopeID)
+	switch {
+	case errors.Is(err, workflow.ErrBudgetNotFound):
+		budget, err = workflow.NewBudget(scope, scopeID, req, userID)
+	case err == nil:
+		err = budget.Apply(req, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveBudget(ctx, budget); err != nil {
+		s.logger.Error("Failed to save budget", "scope", scope, "scope_id", scopeID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Budget set",
+		"scope", scope,
+		"scope_id", scopeID,
+		"monthly_limit", budget.MonthlyLimit,
+		"hard_cap", budget.HardCap,
+		"updated_by", userID,
+	)
+	return s.budgetStatus(ctx, budget)
+}
+
+// budgetStatus adds the spend of this month, counted by the cost calculator
+// of the execution service, to a budget
+func (s *WorkflowService) budgetStatus(ctx context.Context, budget *workflow.Budget) (*workflow.BudgetStatus, error) {
+	month := workflow.BudgetMonth(time.Now())
+	key := rediskey.Tenant(ctx, workflow.BudgetSpendKey(budget.Scope, budget.ScopeID, month)...)
+	spent, err := s.redis.Get(ctx, key).Float64()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	return budget.Status(month, spent), nil
+}
+
+// HandleBudgetThreshold deactivates the workflows of a budget with a hard
+// cap once its monthly limit is reached. They stay inactive until
+// reactivated, after the limit is raised or the month is over.
+func (s *WorkflowService) HandleBudgetThreshold(ctx context.Context, event events.Event) error {
+	var payload struct {
+		Scope     string `json:"scope"`
+		ScopeID   string `json:"scopeId"`
+		UserID    string `json:"userId"`
+		Threshold int    `json:"threshold"`
+		HardCap   bool   `json:"hardCap"`
+	}
+	if err := event.DecodePayload(&payload); err != nil {
+		return err
+	}
+	if !payload.HardCap || payload.Threshold < 100 {
+		return nil
+	}
+
+	var workflows []*workflow.Workflow
+	switch payload.Scope {
+	case workflow.BudgetScopeWorkflow:
+		wf, err := s.repo.GetWorkflow(ctx, payload.ScopeID, payload.UserID)
+		if err != nil {
+			s.logger.Warn("Failed to get workflow over budget", "workflow_id", payload.ScopeID, "error", err)
+			return nil
+		}
+		workflows = append(workflows, wf)
+	case workflow.BudgetScopeWorkspace:
+		list, err := s.repo.ListWorkspaceWorkflows(ctx, payload.ScopeID)
+		if err != nil {
+			return err
+		}
+		workflows = list
+	default:
+		return nil
+	}
+
+	for _, wf := range workflows {
+		if !wf.IsActive {
+			continue
+		}
+		if _, err := s.DeactivateWorkflow(ctx, wf.ID, wf.UserID); err != nil {
+			s.logger.Error("Failed to deactivate workflow over budget", "workflow_id", wf.ID, "error", err)
+			continue
+		}
+		s.logger.Warn("Workflow deactivated, hard cap of its budget reached",
+			"workflow_id", wf.ID,
+			"scope", payload.Scope,
+			"scope_id", payload.ScopeID,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// StartCanary routes a share of executions to the current version of a
+// workflow while the rest keep running the previous release. The versions
+// are compared as executions finish and the canary is promoted or rolled
+// back once the thresholds decide.
+func (s *WorkflowService) StartCanary(ctx context.Context, workflowID, userID string, req *workflow.StartCanaryRequest) (*workflow.Canary, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	if _, err := s.repo.GetRunningCanary(ctx, workflowID); err == nil {
+		return nil, workflow.ErrCanaryAlreadyActive
+	} else if !errors.Is(err, workflow.ErrCanaryNotFound) {
+		return nil, err
+	}
+
+	stableVersion := req.StableVersion
+	if stableVersion == 0 {
+		stableVersion, err = s.previousVersion(ctx, workflowID, wf.Version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	canary := &workflow.Canary{
+		WorkflowID:    workflowID,
+		CanaryVersion: wf.Version,
+		StableVersion: stableVersion,
+		Strategy:      req.Strategy,
+		Percentage:    req.Percentage,
+		Thresholds:    req.Thresholds,
+		Status:        workflow.CanaryRunning,
+		CreatedBy:     userID,
+		StartedAt:     time.Now(),
+	}
+	canary.ApplyDefaults()
+
+	if err := canary.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Stable executions run the stored snapshot, so it has to exist
+	if _, err := s.repo.GetVersion(ctx, workflowID, stableVersion); err != nil {
+		return nil, workflow.ErrInvalidCanary.WithMessage("stable version %d not found", stableVersion)
+	}
+
+	if err := s.repo.CreateCanary(ctx, canary); err != nil {
+		s.logger.Error("Failed to create canary", "workflow_id", workflowID, "error", err)
+		return nil, err
+	}
+
+	s.publishCanaryEvent(ctx, events.WorkflowCanaryStarted, canary)
+
+	s.logger.Info("Workflow canary started",
+		"workflow_id", workflowID,
+		"canary_version", canary.CanaryVersion,
+		"stable_version", canary.StableVersion,
+		"strategy", canary.Strategy,
+		"percentage", canary.Percentage,
+	)
+	return canary, nil
+}
+
+// GetCanary returns the running canary of a workflow with the current stats
+// of both versions
+func (s *WorkflowService) GetCanary(ctx context.Context, workflowID, userID string) (*workflow.CanaryReport, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	canary, err := s.repo.GetRunningCanary(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.canaryReport(ctx, canary)
+}
+
+// PromoteCanary ends a canary keeping the new version for all executions
+func (s *WorkflowService) PromoteCanary(ctx context.Context, workflowID, userID string) (*workflow.Canary, error) {
+	canary, err := s.runningCanary(ctx, workflowID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.promoteCanary(ctx, canary, "promoted manually"); err != nil {
+		return nil, err
+	}
+	return canary, nil
+}
+
+// RollbackCanary ends a canary restoring the stable version as the current
+// definition
+func (s *WorkflowService) RollbackCanary(ctx context.Context, workflowID, userID string) (*workflow.Canary, error) {
+	canary, err := s.runningCanary(ctx, workflowID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.rollbackCanary(ctx, canary, userID, "rolled back manually"); err != nil {
+		return nil, err
+	}
+	return canary, nil
+}
+
+// SetCanaryTraffic shifts the share of executions running the new version
+// of a running canary, such as the steps of a blue/green cut over
+func (s *WorkflowService) SetCanaryTraffic(ctx context.Context, workflowID, userID string, percentage int) (*workflow.Canary, error) {
+	canary, err := s.runningCanary(ctx, workflowID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := canary.Percentage
+	canary.Percentage = percentage
+	if err := canary.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateCanary(ctx, canary); err != nil {
+		return nil, err
+	}
+
+	s.publishCanaryEvent(ctx, events.WorkflowCanaryShifted, canary)
+	s.logger.Info("Workflow canary traffic shifted",
+		"workflow_id", workflowID,
+		"canary_id", canary.ID,
+		"from", previous,
+		"to", percentage,
+	)
+	return canary, nil
+}
+
+// CancelCanary stops routing executions to the stable version without
+// judging the new one
+func (s *WorkflowService) CancelCanary(ctx context.Context, workflowID, userID string) (*workflow.Canary, error) {
+	canary, err := s.runningCanary(ctx, workflowID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	canary.Decide(workflow.CanaryCancelled, "cancelled")
+	if err := s.repo.UpdateCanary(ctx, canary); err != nil {
+		return nil, err
+	}
+
+	s.publishCanaryEvent(ctx, events.WorkflowCanaryCancelled, canary)
+	s.logger.Info("Workflow canary cancelled", "workflow_id", workflowID, "canary_id", canary.ID)
+	return canary, nil
+}
+
+// evaluateCanary compares the versions of the running canary of a workflow,
+// if any, and promotes or rolls it back once the thresholds decide
+func (s *WorkflowService) evaluateCanary(ctx context.Context, workflowID string) error {
+	canary, err := s.repo.GetRunningCanary(ctx, workflowID)
+	if errors.Is(err, workflow.ErrCanaryNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	report, err := s.canaryReport(ctx, canary)
+	if err != nil {
+		return err
+	}
+
+	decision, reason := canary.Evaluate(report.Stable, report.New)
+	switch decision {
+	case workflow.CanaryPromote:
+		return s.promoteCanary(ctx, canary, reason)
+	case workflow.CanaryRollback:
+		return s.rollbackCanary(ctx, canary, canary.CreatedBy, reason)
+	}
+	return nil
+}
+
+func (s *WorkflowService) promoteCanary(ctx context.Context, canary *workflow.Canary, reason string) error {
+	canary.Decide(workflow.CanaryPromoted, reason)
+	if err := s.repo.UpdateCanary(ctx, canary); err != nil {
+		return err
+	}
+
+	s.publishCanaryEvent(ctx, events.WorkflowCanaryPromoted, canary)
+	s.logger.Info("Workflow canary promoted", "workflow_id", canary.WorkflowID, "version", canary.CanaryVersion, "reason", reason)
+	return nil
+}
+
+func (s *WorkflowService) rollbackCanary(ctx context.Context, canary *workflow.Canary, userID, reason string) error {
+	if err := s.repo.RestoreVersion(ctx, canary.WorkflowID, canary.StableVersion, userID); err != nil {
+		s.logger.Error("Failed to restore stable version for canary rollback",
+			"workflow_id", canary.WorkflowID,
+			"version", canary.StableVersion,
+			"error", err,
+		)
+		return err
+	}
+
+	canary.Decide(workflow.CanaryRolledBack, reason)
+	if err := s.repo.UpdateCanary(ctx, canary); err != nil {
+		return err
+	}
+
+	s.publishCanaryEvent(ctx, events.WorkflowCanaryRolledBack, canary)
+	s.logger.Warn("Workflow canary rolled back", "workflow_id", canary.WorkflowID, "version", canary.CanaryVersion, "reason", reason)
+	return nil
+}
+
+func (s *WorkflowService) runningCanary(ctx context.Context, workflowID, userID string) (*workflow.Canary, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	return s.repo.GetRunningCanary(ctx, workflowID)
+}
+
+func (s *WorkflowService) canaryReport(ctx context.Context, canary *workflow.Canary) (*workflow.CanaryReport, error) {
+	stable, err := s.repo.GetVersionStats(ctx, canary.WorkflowID, canary.StableVersion, canary.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetVersionStats(ctx, canary.WorkflowID, canary.CanaryVersion, canary.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &workflow.CanaryReport{Canary: canary, Stable: stable, New: current}, nil
+}
+
+// previousVersion returns the latest saved version older than version
+func (s *WorkflowService) previousVersion(ctx context.Context, workflowID string, version int) (int, error) {
+	versions, err := s.repo.ListVersions(ctx, workflowID)
+	if err != nil {
+		return 0, err
+	}
+
+	previous := 0
+	for _, v := range versions {
+		if v.Version < version && v.Version > previous {
+			previous = v.Version
+		}
+	}
+	if previous == 0 {
+		return 0, workflow.ErrInvalidCanary.WithMessage("workflow has no previous version to compare against")
+	}
+	return previous, nil
+}
+
+func (s *WorkflowService) publishCanaryEvent(ctx context.Context, eventType string, canary *workflow.Canary) {
+	event := events.Event{
+		Type: eventType,
+		Payload: map[string]interface{}{
+			"workflow_id":    canary.WorkflowID,
+			"canary_id":      canary.ID,
+			"canary_version": canary.CanaryVersion,
+			"stable_version": canary.StableVersion,
+			"strategy":       canary.Strategy,
+			"percentage":     canary.Percentage,
+			"reason":         canary.Reason,
+		},
+	}
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		s.logger.Warn("Failed to publish canary event", "type", eventType, "error", err)
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/database"
+)
+
+// ListWorkflowChanges returns the change history of a workflow, who changed
+// what field by field, newest first. Author narrows it to the changes of
+// one user.
+func (s *WorkflowService) ListWorkflowChanges(ctx context.Context, workflowID, userID, author string, page *database.CursorPage) ([]*workflow.WorkflowChange, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	changes, err := s.repo.ListWorkflowChanges(ctx, workflowID, author, page)
+	if err != nil {
+		s.logger.Error("Failed to list workflow changes", "workflow_id", workflowID, "error", err)
+		return nil, err
+	}
+	return changes, nil
+}
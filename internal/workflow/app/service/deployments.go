@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/internal/workflow/app/sharing"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// DeployEnvironment deploys a version of a workflow to an environment.
+// An environment promoting from another only accepts the version deployed
+// there, so versions move through dev, staging and prod in order.
+func (s *WorkflowService) DeployEnvironment(ctx context.Context, workflowID, userID, envID string, version int, override bool) (*workflow.Environment, error) {
+	wf, err := s.sharedWorkflow(ctx, workflowID, userID, sharing.PermissionAdmin)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	env, err := s.repo.GetEnvironment(ctx, workflowID, envID)
+	if err != nil {
+		return nil, workflow.ErrEnvironmentNotFound
+	}
+
+	if env.PromotesFrom != "" {
+		upstream, err := s.repo.GetEnvironment(ctx, workflowID, env.PromotesFrom)
+		if err != nil {
+			return nil, workflow.ErrEnvironmentNotFound
+		}
+		if upstream.DeployedVersion == 0 {
+			return nil, workflow.ErrEnvironmentNotDeployed.WithMessage("no workflow version is deployed to %s", upstream.Name)
+		}
+		if upstream.DeployedVersion != version {
+			return nil, workflow.ErrPromotionOrder.WithMessage("environment %s only accepts version %d, deployed to %s", env.Name, upstream.DeployedVersion, upstream.Name)
+		}
+	}
+
+	return s.deploy(ctx, wf, env, version, env.PromotesFrom, userID, override)
+}
+
+// PromoteEnvironment deploys to an environment the version deployed to the
+// environment it promotes from
+func (s *WorkflowService) PromoteEnvironment(ctx context.Context, workflowID, userID, envID string, override bool) (*workflow.Environment, error) {
+	wf, err := s.sharedWorkflow(ctx, workflowID, userID, sharing.PermissionAdmin)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	env, err := s.repo.GetEnvironment(ctx, workflowID, envID)
+	if err != nil {
+		return nil, workflow.ErrEnvironmentNotFound
+	}
+	if env.PromotesFrom == "" {
+		return nil, workflow.ErrInvalidPromotion.WithMessage("environment %s does not promote from another environment", env.Name)
+	}
+
+	upstream, err := s.repo.GetEnvironment(ctx, workflowID, env.PromotesFrom)
+	if err != nil {
+		return nil, workflow.ErrEnvironmentNotFound
+	}
+	if upstream.DeployedVersion == 0 {
+		return nil, workflow.ErrEnvironmentNotDeployed.WithMessage("no workflow version is deployed to %s", upstream.Name)
+	}
+
+	return s.deploy(ctx, wf, env, upstream.DeployedVersion, upstream.ID, userID, override)
+}
+
+// ListDeployments lists the versions deployed to an environment, latest
+// first
+func (s *WorkflowService) ListDeployments(ctx context.Context, workflowID, userID, envID string) ([]*workflow.Deployment, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	if _, err := s.repo.GetEnvironment(ctx, workflowID, envID); err != nil {
+		return nil, workflow.ErrEnvironmentNotFound
+	}
+
+	return s.repo.ListDeployments(ctx, workflowID, envID)
+}
+
+// deploy records a version of wf as deployed to an environment. Environments
+// gated by the activation policy of the workspace only take versions passing
+// it, unless an admin overrides it.
+func (s *WorkflowService) deploy(ctx context.Context, wf *workflow.Workflow, env *workflow.Environment, version int, promotedFrom, userID string, override bool) (*workflow.Environment, error) {
+	definition, err := s.deployedDefinition(ctx, env.WorkflowID, version)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.GetWorkspacePolicy(ctx, wf.WorkspaceID())
+	if err != nil {
+		return nil, err
+	}
+	if policy.Activation.Gates(env.Name) {
+		if err := s.enforceActivationPolicy(ctx, &policy.Activation, wf, definition, userID, override); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	deployment := &workflow.Deployment{
+		ID:              uuid.New().String(),
+		WorkflowID:      env.WorkflowID,
+		EnvironmentID:   env.ID,
+		Version:         version,
+		PreviousVersion: env.DeployedVersion,
+		PromotedFrom:    promotedFrom,
+		DeployedBy:      userID,
+		CreatedAt:       now,
+	}
+	env.DeployedVersion = version
+	env.DeployedBy = userID
+	env.DeployedAt = &now
+	env.UpdatedAt = now.Format(time.RFC3339)
+
+	if err := s.repo.DeployEnvironment(ctx, env, deployment); err != nil {
+		s.logger.Error("Failed to deploy workflow version", "workflow_id", env.WorkflowID, "environment", env.Name, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Workflow version deployed",
+		"workflow_id", env.WorkflowID,
+		"environment", env.Name,
+		"version", version,
+		"previous_version", deployment.PreviousVersion,
+	)
+	return env, nil
+}
+
+// resolveEnvironment finds an environment of a workflow by ID or by name
+func (s *WorkflowService) resolveEnvironment(ctx context.Context, workflowID, environment string) (*workflow.Environment, error) {
+	if _, err := uuid.Parse(environment); err == nil {
+		if env, err := s.repo.GetEnvironment(ctx, workflowID, environment); err == nil {
+			return env, nil
+		}
+	}
+	env, err := s.repo.GetEnvironmentByName(ctx, workflowID, environment)
+	if err != nil {
+		return nil, workflow.ErrEnvironmentNotFound
+	}
+	return env, nil
+}
+
+// checkPromotesFrom checks an environment may promote from another: both
+// belong to the workflow and the promotion order has no cycle
+func (s *WorkflowService) checkPromotesFrom(ctx context.Context, workflowID, envID, promotesFrom string) error {
+	seen := map[string]bool{envID: true}
+	for current := promotesFrom; current != ""; {
+		if seen[current] {
+			return workflow.ErrInvalidPromotion.WithMessage("environments would promote from each other")
+		}
+		seen[current] = true
+
+		upstream, err := s.repo.GetEnvironment(ctx, workflowID, current)
+		if err != nil {
+			return workflow.ErrInvalidPromotion.WithMessage("environment %s not found", current)
+		}
+		current = upstream.PromotesFrom
+	}
+	return nil
+}
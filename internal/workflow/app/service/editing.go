@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/linkflow-go/internal/workflow/app/sharing"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/redis/go-redis/v9"
+)
+
+// editSessionAttempts bounds the retries of a session update racing with
+// another editor of the same workflow
+const editSessionAttempts = 3
+
+// JoinEditSession starts or renews the edit session of a user, claiming the
+// nodes the user has open. Nodes claimed by another live session are
+// refused. The owner and the users the workflow is shared with to edit it
+// take part.
+func (s *WorkflowService) JoinEditSession(ctx context.Context, workflowID, userID string, req workflow.EditSessionRequest) (*workflow.EditSession, error) {
+	if _, err := s.sharedWorkflow(ctx, workflowID, userID, sharing.PermissionEdit); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	key := rediskey.Tenant(ctx, workflow.EditSessionsKey(workflowID)...)
+	var session *workflow.EditSession
+	claim := func(tx *redis.Tx) error {
+		now := time.Now()
+		sessions, expired, err := loadEditSessions(ctx, tx, key, now)
+		if err != nil {
+			return err
+		}
+		for _, other := range sessions {
+			if other.UserID == userID {
+				continue
+			}
+			for _, nodeID := range req.Nodes {
+				if other.Holds(nodeID) {
+					return workflow.ErrNodeLocked.
+						WithMessage("node %s is being edited by %s", nodeID, other.UserID).
+						WithDetail("nodeId", nodeID).
+						WithDetail("userId", other.UserID)
+				}
+			}
+		}
+
+		session = sessions[userID]
+		if session == nil {
+			session = &workflow.EditSession{UserID: userID, StartedAt: now}
+		}
+		session.Nodes = req.Nodes
+		if session.Nodes == nil {
+			session.Nodes = []string{}
+		}
+		session.ExpiresAt = now.Add(workflow.EditSessionTTL)
+		data, err := json.Marshal(session)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, userID, data)
+			if len(expired) > 0 {
+				pipe.HDel(ctx, key, expired...)
+			}
+			// The hash goes with the last session
+			pipe.Expire(ctx, key, workflow.EditSessionTTL)
+			return nil
+		})
+		return err
+	}
+
+	var err error
+	for attempt := 0; attempt < editSessionAttempts; attempt++ {
+		if err = s.redis.Watch(ctx, claim, key); err != redis.TxFailedErr {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// LeaveEditSession ends the edit session of a user, releasing its nodes
+func (s *WorkflowService) LeaveEditSession(ctx context.Context, workflowID, userID string) error {
+	if _, err := s.sharedWorkflow(ctx, workflowID, userID, sharing.PermissionEdit); err != nil {
+		return ErrWorkflowNotFound
+	}
+	return s.redis.HDel(ctx, rediskey.Tenant(ctx, workflow.EditSessionsKey(workflowID)...), userID).Err()
+}
+
+// ListEditors returns the live edit sessions of a workflow, the users who
+// have it open and the nodes they are editing
+func (s *WorkflowService) ListEditors(ctx context.Context, workflowID, userID string) ([]*workflow.EditSession, error) {
+	if _, err := s.sharedWorkflow(ctx, workflowID, userID, sharing.PermissionEdit); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	sessions, _, err := loadEditSessions(ctx, s.redis, rediskey.Tenant(ctx, workflow.EditSessionsKey(workflowID)...), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	editors := make([]*workflow.EditSession, 0, len(sessions))
+	for _, session := range sessions {
+		editors = append(editors, session)
+	}
+	workflow.SortEditSessions(editors)
+	return editors, nil
+}
+
+// checkNodeClaims refuses an update changing nodes claimed by the edit
+// session of another user. Saves go through when the sessions cannot be
+// read, claims guard edits in progress and are not a permission.
+func (s *WorkflowService) checkNodeClaims(ctx context.Context, workflowID, userID string, edited *workflow.DefinitionDiff) error {
+	if edited == nil || len(edited.Nodes) == 0 {
+		return nil
+	}
+
+	sessions, _, err := loadEditSessions(ctx, s.redis, rediskey.Tenant(ctx, workflow.EditSessionsKey(workflowID)...), time.Now())
+	if err != nil {
+		s.logger.Warn("Failed to load edit sessions", "workflow_id", workflowID, "error", err)
+		return nil
+	}
+	for _, change := range edited.Nodes {
+		for _, other := range sessions {
+			if other.UserID != userID && other.Holds(change.NodeID) {
+				return workflow.ErrNodeLocked.
+					WithMessage("node %s is being edited by %s", change.NodeID, other.UserID).
+					WithDetail("nodeId", change.NodeID).
+					WithDetail("userId", other.UserID)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeConcurrentEdit merges an update started from an older version with
+// the changes saved since. It returns the merged workflow and the changes
+// of the update itself, or ErrEditConflict listing where both changed the
+// same thing.
+func (s *WorkflowService) mergeConcurrentEdit(ctx context.Context, current *workflow.Workflow, req *workflow.UpdateWorkflowRequest) (*workflow.Workflow, *workflow.DefinitionDiff, error) {
+	version, err := s.repo.GetVersion(ctx, current.ID, req.Version)
+	if err != nil {
+		return nil, nil, workflow.ErrEditConflict.
+			WithMessage("version %d the edit started from is not known", req.Version).
+			WithDetail("version", current.Version)
+	}
+
+	// Decoded twice, the update must not alter the base it is compared to
+	var base, ours workflow.Workflow
+	if err := json.Unmarshal([]byte(version.Data), &base); err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal([]byte(version.Data), &ours); err != nil {
+		return nil, nil, err
+	}
+	if err := applyUpdate(&ours, req); err != nil {
+		return nil, nil, err
+	}
+
+	merged, conflicts := workflow.MergeDefinitions(&base, current, &ours)
+	if len(conflicts) > 0 {
+		s.logger.Warn("Concurrent edit conflicts",
+			"id", current.ID,
+			"base", req.Version,
+			"actual", current.Version,
+			"conflicts", len(conflicts),
+		)
+		return nil, nil, workflow.ErrEditConflict.
+			WithDetail("conflicts", conflicts).
+			WithDetail("version", current.Version)
+	}
+	return merged, workflow.DiffDefinitions(&base, &ours), nil
+}
+
+// loadEditSessions reads the sessions of a hash, keyed by user, and the
+// users whose session expired at now
+func loadEditSessions(ctx context.Context, client redis.Cmdable, key string, now time.Time) (map[string]*workflow.EditSession, []string, error) {
+	fields, err := client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sessions := make(map[string]*workflow.EditSession, len(fields))
+	var expired []string
+	for userID, data := range fields {
+		var session workflow.EditSession
+		if err := json.Unmarshal([]byte(data), &session); err != nil || !session.Live(now) {
+			expired = append(expired, userID)
+			continue
+		}
+		sessions[userID] = &session
+	}
+	return sessions, expired, nil
+}
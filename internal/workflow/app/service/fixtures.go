@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/linkflow-go/internal/workflow/adapters/triggers"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/rediskey"
+)
+
+// workflowTrigger returns a trigger of a workflow the user can read
+func (s *WorkflowService) workflowTrigger(ctx context.Context, workflowID, triggerID, userID string) (*workflow.WorkflowTrigger, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	trigger, err := s.triggerManager.GetTrigger(ctx, triggerID)
+	if err != nil {
+		return nil, err
+	}
+	if trigger.WorkflowID != workflowID {
+		return nil, triggers.ErrTriggerNotFound
+	}
+	return trigger, nil
+}
+
+// ListTriggerFixtures returns the fixtures of a trigger
+func (s *WorkflowService) ListTriggerFixtures(ctx context.Context, workflowID, triggerID, userID string) ([]*workflow.TriggerFixture, error) {
+	if _, err := s.workflowTrigger(ctx, workflowID, triggerID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTriggerFixtures(ctx, triggerID)
+}
+
+// GetTriggerFixture returns a fixture of a trigger by name
+func (s *WorkflowService) GetTriggerFixture(ctx context.Context, workflowID, triggerID, name, userID string) (*workflow.TriggerFixture, error) {
+	if _, err := s.workflowTrigger(ctx, workflowID, triggerID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTriggerFixture(ctx, triggerID, name)
+}
+
+// CreateTriggerFixture saves a payload of a trigger under a name
+func (s *WorkflowService) CreateTriggerFixture(ctx context.Context, workflowID, triggerID, userID string, req workflow.CreateFixtureRequest) (*workflow.TriggerFixture, error) {
+	if _, err := s.workflowTrigger(ctx, workflowID, triggerID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkFixtureName(ctx, triggerID, req.Name); err != nil {
+		return nil, err
+	}
+
+	fixture := &workflow.TriggerFixture{
+		WorkflowID: workflowID,
+		TriggerID:  triggerID,
+		Name:       req.Name,
+		Payload:    req.Payload,
+		Source:     workflow.FixtureManual,
+		CreatedBy:  userID,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.repo.CreateTriggerFixture(ctx, fixture); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Trigger fixture saved", "trigger_id", triggerID, "fixture", req.Name)
+	return fixture, nil
+}
+
+// DeleteTriggerFixture deletes a fixture of a trigger
+func (s *WorkflowService) DeleteTriggerFixture(ctx context.Context, workflowID, triggerID, name, userID string) error {
+	if _, err := s.workflowTrigger(ctx, workflowID, triggerID, userID); err != nil {
+		return err
+	}
+	if name == "" {
+		return workflow.ErrFixtureNotFound
+	}
+	return s.repo.DeleteTriggerFixture(ctx, triggerID, name)
+}
+
+// CaptureTriggerFixture captures the next payload the trigger receives as a
+// fixture under a name, unless none arrives within workflow.FixtureCaptureTTL.
+// Capturing again replaces the pending capture of the trigger.
+func (s *WorkflowService) CaptureTriggerFixture(ctx context.Context, workflowID, triggerID, userID string, req workflow.CaptureFixtureRequest) (*workflow.FixtureCapture, error) {
+	if _, err := s.workflowTrigger(ctx, workflowID, triggerID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkFixtureName(ctx, triggerID, req.Name); err != nil {
+		return nil, err
+	}
+
+	capture := &workflow.FixtureCapture{
+		Name:      req.Name,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(workflow.FixtureCaptureTTL),
+	}
+	data, err := json.Marshal(capture)
+	if err != nil {
+		return nil, err
+	}
+
+	key := rediskey.Tenant(ctx, workflow.FixtureCapturesKey(workflowID)...)
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(ctx, key, triggerID, data)
+	// The hash goes with the last capture
+	pipe.Expire(ctx, key, workflow.FixtureCaptureTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Capturing trigger fixture", "trigger_id", triggerID, "fixture", req.Name)
+	return capture, nil
+}
+
+// checkFixtureName checks a fixture name is valid and not taken
+func (s *WorkflowService) checkFixtureName(ctx context.Context, triggerID, name string) error {
+	if err := workflow.ValidateFixtureName(name); err != nil {
+		return err
+	}
+	_, err := s.repo.GetTriggerFixture(ctx, triggerID, name)
+	if err == nil {
+		return workflow.ErrFixtureExists.WithMessage("trigger already has a fixture named %s", name)
+	}
+	if !errors.Is(err, workflow.ErrFixtureNotFound) {
+		return err
+	}
+	return nil
+}
+
+// fixtureData returns the payload of a fixture of a trigger of a workflow
+func (s *WorkflowService) fixtureData(ctx context.Context, workflowID, triggerID, name string) (map[string]interface{}, error) {
+	trigger, err := s.triggerManager.GetTrigger(ctx, triggerID)
+	if err != nil {
+		return nil, err
+	}
+	if trigger.WorkflowID != workflowID {
+		return nil, triggers.ErrTriggerNotFound
+	}
+	fixture, err := s.repo.GetTriggerFixture(ctx, triggerID, name)
+	if err != nil {
+		return nil, err
+	}
+	return fixture.Payload, nil
+}
+
+// ReplayTriggerFixtures replays every fixture of a trigger through the
+// trigger test and the workflow test. A fixture passes when the trigger
+// fires on it and the workflow validates with it as the data.
+func (s *WorkflowService) ReplayTriggerFixtures(ctx context.Context, workflowID, triggerID, userID string) ([]*workflow.FixtureReplay, error) {
+	if _, err := s.workflowTrigger(ctx, workflowID, triggerID, userID); err != nil {
+		return nil, err
+	}
+	fixtures, err := s.repo.ListTriggerFixtures(ctx, triggerID)
+	if err != nil {
+		return nil, err
+	}
+
+	replays := make([]*workflow.FixtureReplay, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		fired, err := s.triggerManager.TestTrigger(ctx, triggerID, fixture.Payload)
+		if err != nil {
+			return nil, err
+		}
+		tested, err := s.TestWorkflow(ctx, workflowID, userID, workflow.TestWorkflowRequest{Data: fixture.Payload})
+		if err != nil {
+			return nil, err
+		}
+
+		replay := &workflow.FixtureReplay{Fixture: fixture.Name}
+		replay.WouldFire, _ = fired["would_fire"].(bool)
+		if result, ok := tested.(map[string]interface{}); ok {
+			replay.Valid, _ = result["valid"].(bool)
+			replay.Errors, _ = result["errors"].([]string)
+			replay.Warnings, _ = result["warnings"].([]string)
+		}
+		replay.Passed = replay.WouldFire && replay.Valid
+		replays = append(replays, replay)
+	}
+
+	s.logger.Info("Trigger fixtures replayed", "trigger_id", triggerID, "fixtures", len(replays))
+	return replays, nil
+}
+
+// HandleTriggerPayload captures the payload of a fired trigger or a
+// received webhook as a fixture when a capture of the trigger is pending
+func (s *WorkflowService) HandleTriggerPayload(ctx context.Context, event events.Event) error {
+	workflowID, _ := event.Payload["workflowId"].(string)
+	if workflowID == "" {
+		workflowID, _ = event.Payload["workflow_id"].(string)
+	}
+	payload, _ := event.Payload["data"].(map[string]interface{})
+	if workflowID == "" || payload == nil {
+		return nil
+	}
+
+	key := rediskey.Tenant(ctx, workflow.FixtureCapturesKey(workflowID)...)
+	pending, err := s.redis.HGetAll(ctx, key).Result()
+	if err != nil || len(pending) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for triggerID, data := range pending {
+		var capture workflow.FixtureCapture
+		if err := json.Unmarshal([]byte(data), &capture); err != nil || !capture.Live(now) {
+			s.redis.HDel(ctx, key, triggerID)
+			continue
+		}
+		if !s.receivedBy(ctx, event, triggerID) {
+			continue
+		}
+		// Only the replica removing the capture stores the fixture
+		if removed, err := s.redis.HDel(ctx, key, triggerID).Result(); err != nil || removed == 0 {
+			continue
+		}
+
+		fixture := &workflow.TriggerFixture{
+			WorkflowID: workflowID,
+			TriggerID:  triggerID,
+			Name:       capture.Name,
+			Payload:    workflow.CapturedPayload(payload),
+			Source:     workflow.FixtureCaptured,
+			CreatedBy:  capture.UserID,
+			CreatedAt:  now,
+		}
+		if err := s.repo.CreateTriggerFixture(ctx, fixture); err != nil {
+			s.logger.Error("Failed to store captured trigger fixture", "trigger_id", triggerID, "fixture", capture.Name, "error", err)
+			continue
+		}
+		s.logger.Info("Trigger fixture captured", "trigger_id", triggerID, "fixture", capture.Name)
+	}
+	return nil
+}
+
+// receivedBy reports whether the payload of a trigger event was received by
+// a trigger: the trigger fired, or the webhook trigger the request reached
+func (s *WorkflowService) receivedBy(ctx context.Context, event events.Event, triggerID string) bool {
+	if fired, ok := event.Payload["trigger_id"].(string); ok {
+		return fired == triggerID
+	}
+
+	trigger, err := s.triggerManager.GetTrigger(ctx, triggerID)
+	if err != nil || trigger.Type != workflow.TriggerTypeWebhook {
+		return false
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(trigger.Config, &config); err != nil {
+		return false
+	}
+	path, _ := config["path"].(string)
+	if path == "" {
+		return true
+	}
+	request, _ := event.Payload["data"].(map[string]interface{})["_webhook"].(map[string]interface{})
+	received, _ := request["path"].(string)
+	return received == path
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/internal/workflow/ports"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// SetGitSync wires the client syncing workflows with Git repositories, nil
+// disables Git sync
+func (s *WorkflowService) SetGitSync(git ports.GitSync) {
+	s.git = git
+}
+
+// GetGitConnection returns the Git connection of a workflow
+func (s *WorkflowService) GetGitConnection(ctx context.Context, workflowID, userID string) (*workflow.GitConnection, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	return s.repo.GetGitConnection(ctx, workflowID)
+}
+
+// ConnectGit connects a workflow to a Git repository, replacing its
+// connection if any, and pushes the current version. The connection is
+// only kept once that push succeeded. A connection to the same repository
+// keeps its token when the request gives none.
+func (s *WorkflowService) ConnectGit(ctx context.Context, workflowID, userID string, req *workflow.ConnectGitRequest) (*workflow.GitConnection, error) {
+	if s.git == nil {
+		return nil, workflow.ErrGitSyncDisabled
+	}
+
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	conn, err := workflow.NewGitConnection(workflowID, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetGitConnection(ctx, workflowID)
+	switch {
+	case errors.Is(err, workflow.ErrGitConnectionNotFound):
+		conn.ID = uuid.New().String()
+		conn.CreatedAt = time.Now()
+	case err == nil:
+		conn.ID = existing.ID
+		conn.CreatedAt = existing.CreatedAt
+		if req.Token == "" && existing.RepositoryURL == conn.RepositoryURL {
+			conn.Token = existing.Token
+		}
+		// The checkout follows the new repository and branch
+		if err := s.git.Remove(existing.ID); err != nil {
+			s.logger.Warn("Failed to remove Git checkout", "workflow_id", workflowID, "error", err)
+		}
+	default:
+		return nil, err
+	}
+
+	if req.Token != "" {
+		if s.sealer == nil {
+			return nil, workflow.ErrSecretsUnavailable
+		}
+		sealed, err := s.sealer.Seal(req.Token)
+		if err != nil {
+			return nil, err
+		}
+		conn.Token = sealed
+	}
+
+	if err := s.pushToGit(ctx, conn, wf); err != nil {
+		return nil, err
+	}
+
+	conn.UpdatedAt = time.Now()
+	if err := s.repo.SaveGitConnection(ctx, conn); err != nil {
+		s.logger.Error("Failed to save Git connection", "workflow_id", workflowID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Workflow connected to Git",
+		"workflow_id", workflowID,
+		"repository", conn.RepositoryURL,
+		"branch", conn.Branch,
+		"path", conn.Path,
+		"commit", conn.LastPushedCommit,
+	)
+	return conn, nil
+}
+
+// DisconnectGit removes the Git connection of a workflow, the repository
+// is left as it is
+func (s *WorkflowService) DisconnectGit(ctx context.Context, workflowID, userID string) error {
+	conn, err := s.GetGitConnection(ctx, workflowID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteGitConnection(ctx, workflowID); err != nil {
+		return err
+	}
+	if s.git != nil {
+		if err := s.git.Remove(conn.ID); err != nil {
+			s.logger.Warn("Failed to remove Git checkout", "workflow_id", workflowID, "error", err)
+		}
+	}
+
+	s.logger.Info("Workflow disconnected from Git", "workflow_id", workflowID)
+	return nil
+}
+
+// PushToGit pushes the current version of a workflow to its repository
+func (s *WorkflowService) PushToGit(ctx context.Context, workflowID, userID string) (*workflow.GitConnection, error) {
+	if s.git == nil {
+		return nil, workflow.ErrGitSyncDisabled
+	}
+
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	conn, err := s.repo.GetGitConnection(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	pushErr := s.pushToGit(ctx, conn, wf)
+	if err := s.repo.SaveGitConnection(ctx, conn); err != nil {
+		return nil, err
+	}
+	if pushErr != nil {
+		return nil, pushErr
+	}
+	return conn, nil
+}
+
+// PreviewGitPull reads the document of a workflow on the head of its
+// branch and shows what applying it would change, validated as an update
+// of the workflow would be
+func (s *WorkflowService) PreviewGitPull(ctx context.Context, workflowID, userID string) (*workflow.GitPullPreview, error) {
+	if s.git == nil {
+		return nil, workflow.ErrGitSyncDisabled
+	}
+
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	conn, err := s.repo.GetGitConnection(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.gitToken(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	content, commit, err := s.git.Read(ctx, conn, token)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := workflow.DecodeWorkflowDocument(content, conn.Format)
+	if err != nil {
+		return nil, err
+	}
+	if doc.ID != "" && doc.ID != wf.ID {
+		return nil, workflow.ErrInvalidGitDocument.WithMessage("document is of workflow %s", doc.ID)
+	}
+
+	candidate := doc.Apply(wf)
+	preview := &workflow.GitPullPreview{
+		Commit:   commit,
+		Fields:   workflow.ChangedFields(wf, candidate),
+		Diff:     workflow.DiffDefinitions(wf, candidate),
+		Errors:   []string{},
+		Warnings: []string{},
+		Workflow: candidate,
+	}
+	preview.UpToDate = preview.Diff.Empty() && len(preview.Fields) == 0
+
+	validationErrors, warnings, _ := s.validationService.ValidateWorkflow(ctx, candidate)
+	preview.Errors = append(preview.Errors, validationErrors...)
+	preview.Warnings = append(preview.Warnings, warnings...)
+
+	// Settings locked and node types blocked by the workspace stay so
+	policy, err := s.GetWorkspacePolicy(ctx, wf.WorkspaceID())
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.CheckLocked(candidate, wf); err != nil {
+		preview.Errors = append(preview.Errors, err.Error())
+	}
+	if err := policy.CheckNodeTypes(candidate); err != nil {
+		preview.Errors = append(preview.Errors, err.Error())
+	}
+	preview.Valid = len(preview.Errors) == 0
+
+	return preview, nil
+}
+
+// ApplyGitPull applies the document of a workflow on the head of its
+// branch as a new version. With a commit, the branch must still be at the
+// commit previewed.
+func (s *WorkflowService) ApplyGitPull(ctx context.Context, workflowID, userID, commit string) (*workflow.Workflow, error) {
+	preview, err := s.PreviewGitPull(ctx, workflowID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if commit != "" && commit != preview.Commit {
+		return nil, workflow.ErrGitPullStale.WithMessage("the branch is at %s, the preview was of %s", preview.Commit, commit)
+	}
+	if !preview.Valid {
+		return nil, ErrInvalidWorkflow.WithMessage("%s", strings.Join(preview.Errors, "; "))
+	}
+
+	conn, err := s.repo.GetGitConnection(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	conn.LastPulledCommit = preview.Commit
+
+	wf := preview.Workflow
+	if preview.UpToDate {
+		if err := s.repo.SaveGitConnection(ctx, conn); err != nil {
+			return nil, err
+		}
+		return wf, nil
+	}
+
+	previousVersion := wf.Version
+	wf.UpdatedAt = time.Now()
+
+	// Save the version with the WorkflowUpdated event, which pushes it back
+	// unchanged
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateWithVersion(ctx, wf, fmt.Sprintf("Pulled from Git at %s", shortCommit(preview.Commit))); err != nil {
+			return err
+		}
+		if err := s.repo.SaveGitConnection(ctx, conn); err != nil {
+			return err
+		}
+		return s.eventBus.Publish(ctx, events.Event{
+			Type: "workflow.updated",
+			Payload: map[string]interface{}{
+				"workflow_id":      wf.ID,
+				"user_id":          userID,
+				"version":          wf.Version,
+				"previous_version": previousVersion,
+				"git_commit":       preview.Commit,
+			},
+		})
+	})
+	if err != nil {
+		s.logger.Error("Failed to apply Git pull", "workflow_id", workflowID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Workflow pulled from Git",
+		"workflow_id", workflowID,
+		"commit", preview.Commit,
+		"version", wf.Version,
+		"changed_nodes", len(preview.Diff.Nodes),
+	)
+	return wf, nil
+}
+
+// HandleWorkflowVersioned pushes a new version of a workflow to its
+// repository when the workflow is connected with auto push. A failed push
+// is recorded on the connection and left for the next version or a manual
+// push.
+func (s *WorkflowService) HandleWorkflowVersioned(ctx context.Context, event events.Event) error {
+	if s.git == nil {
+		return nil
+	}
+	workflowID, _ := event.Payload["workflow_id"].(string)
+	userID, _ := event.Payload["user_id"].(string)
+	if workflowID == "" {
+		return nil
+	}
+
+	conn, err := s.repo.GetGitConnection(ctx, workflowID)
+	if errors.Is(err, workflow.ErrGitConnectionNotFound) {
+		return nil
+	}
+	if err != nil {
+		s.logger.Warn("Failed to look up Git connection", "workflow_id", workflowID, "error", err)
+		return nil
+	}
+	if !conn.AutoPush {
+		return nil
+	}
+
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		s.logger.Warn("Failed to load workflow to push to Git", "workflow_id", workflowID, "error", err)
+		return nil
+	}
+	// Redelivered events have nothing new to push
+	if wf.Version <= conn.LastPushedVersion {
+		return nil
+	}
+
+	if err := s.pushToGit(ctx, conn, wf); err != nil {
+		s.logger.Warn("Failed to push workflow to Git", "workflow_id", workflowID, "version", wf.Version, "error", err)
+	}
+	if err := s.repo.SaveGitConnection(ctx, conn); err != nil {
+		s.logger.Warn("Failed to save Git connection", "workflow_id", workflowID, "error", err)
+	}
+	return nil
+}
+
+// pushToGit pushes a workflow to the repository of a connection and
+// records the outcome on the connection
+func (s *WorkflowService) pushToGit(ctx context.Context, conn *workflow.GitConnection, wf *workflow.Workflow) error {
+	err := s.pushDocument(ctx, conn, wf)
+
+	now := time.Now()
+	conn.LastSyncedAt = &now
+	conn.LastError = ""
+	if err != nil {
+		conn.LastError = err.Error()
+	}
+	return err
+}
+
+func (s *WorkflowService) pushDocument(ctx context.Context, conn *workflow.GitConnection, wf *workflow.Workflow) error {
+	token, err := s.gitToken(conn)
+	if err != nil {
+		return err
+	}
+	content, err := workflow.EncodeWorkflowDocument(wf.Document(), conn.Format)
+	if err != nil {
+		return err
+	}
+
+	commit, err := s.git.Push(ctx, conn, token, content, fmt.Sprintf("Update %s to version %d", wf.Name, wf.Version))
+	if err != nil {
+		return err
+	}
+	conn.LastPushedVersion = wf.Version
+	conn.LastPushedCommit = commit
+	return nil
+}
+
+// gitToken opens the token of a connection, empty for none
+func (s *WorkflowService) gitToken(conn *workflow.GitConnection) (string, error) {
+	if conn.Token == "" {
+		return "", nil
+	}
+	if s.sealer == nil {
+		return "", workflow.ErrSecretsUnavailable
+	}
+	return s.sealer.Open(conn.Token)
+}
+
+// shortCommit abbreviates a commit hash for version notes
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// CheckIntegrity recomputes the checksum of the workflow and each stored
+// version and compares them with the checksums recorded when they were saved
+// and when executions ran. Records without a checksum to compare against are
+// counted as unverified rather than flagged.
+func (s *WorkflowService) CheckIntegrity(ctx context.Context, workflowID, userID string) (*workflow.IntegrityReport, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	current, err := wf.ComputeChecksum()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &workflow.IntegrityReport{
+		WorkflowID: wf.ID,
+		Version:    wf.Version,
+		Checksum:   current,
+		Issues:     []workflow.IntegrityIssue{},
+		CheckedAt:  time.Now(),
+	}
+
+	if wf.Checksum == "" {
+		report.Unverified++
+	} else {
+		report.Verified++
+		if wf.Checksum != current {
+			report.Issues = append(report.Issues, workflow.IntegrityIssue{
+				Kind:     workflow.IntegrityWorkflowMismatch,
+				Version:  wf.Version,
+				Expected: wf.Checksum,
+				Actual:   current,
+			})
+		}
+	}
+
+	versions, err := s.repo.ListVersions(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Recorded checksum of each version, used to audit executions
+	recorded := make(map[int]string, len(versions))
+	for _, version := range versions {
+		if version.Checksum == "" {
+			report.Unverified++
+			continue
+		}
+		report.Verified++
+		recorded[version.Version] = version.Checksum
+
+		actual, err := version.ComputeChecksum()
+		if err != nil {
+			s.logger.Warn("Failed to checksum workflow version", "workflow_id", workflowID, "version", version.Version, "error", err)
+			actual = ""
+		}
+		if actual != version.Checksum {
+			report.Issues = append(report.Issues, workflow.IntegrityIssue{
+				Kind:     workflow.IntegrityVersionMismatch,
+				Version:  version.Version,
+				Expected: version.Checksum,
+				Actual:   actual,
+			})
+		}
+	}
+
+	executions, err := s.repo.ListExecutionChecksums(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, execution := range executions {
+		expected, ok := recorded[execution.Version]
+		if !ok {
+			// Version pruned by retention or saved before checksums existed
+			report.Unverified++
+			continue
+		}
+		report.Verified++
+
+		if expected != execution.WorkflowChecksum {
+			report.Issues = append(report.Issues, workflow.IntegrityIssue{
+				Kind:        workflow.IntegrityExecutionMismatch,
+				Version:     execution.Version,
+				ExecutionID: execution.ID,
+				Expected:    expected,
+				Actual:      execution.WorkflowChecksum,
+			})
+		}
+	}
+
+	report.Valid = len(report.Issues) == 0
+
+	if !report.Valid {
+		s.logger.Warn("Workflow integrity check failed", "workflow_id", workflowID, "issues", len(report.Issues))
+
+		event := events.Event{
+			Type: events.WorkflowIntegrityViolation,
+			Payload: map[string]interface{}{
+				"workflow_id": wf.ID,
+				"user_id":     userID,
+				"issues":      report.Issues,
+			},
+		}
+		if err := s.eventBus.Publish(ctx, event); err != nil {
+			s.logger.Warn("Failed to publish integrity violation event", "error", err)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	"github.com/linkflow-go/internal/workflow/adapters/templates"
+)
+
+// SearchTemplates lists a page of the marketplace: the public templates
+// approved by a moderator, and the built-in ones
+func (s *WorkflowService) SearchTemplates(ctx context.Context, q templates.SearchQuery) ([]*templates.Template, int64, error) {
+	return s.templateManager.SearchTemplates(ctx, q)
+}
+
+// RateTemplate rates a marketplace template for a user, 1 to 5 stars with
+// an optional comment
+func (s *WorkflowService) RateTemplate(ctx context.Context, templateID, userID string, rating int, comment string) (*templates.Review, error) {
+	return s.templateManager.RateTemplate(ctx, templateID, userID, rating, comment)
+}
+
+// ListTemplateReviews lists a page of the reviews of a marketplace template
+func (s *WorkflowService) ListTemplateReviews(ctx context.Context, templateID string, limit, offset int) ([]*templates.Review, error) {
+	template, err := s.templateManager.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if !template.Listed() {
+		return nil, ErrTemplateNotFound
+	}
+	return s.templateManager.ListReviews(ctx, templateID, limit, offset)
+}
+
+// ListTemplateModeration lists the public templates in a moderation state
+func (s *WorkflowService) ListTemplateModeration(ctx context.Context, status string) ([]*templates.Template, error) {
+	return s.templateManager.ListModeration(ctx, status)
+}
+
+// ModerateTemplate approves a public template into the marketplace or
+// rejects it
+func (s *WorkflowService) ModerateTemplate(ctx context.Context, templateID, status, note string) (*templates.Template, error) {
+	return s.templateManager.ModerateTemplate(ctx, templateID, status, note)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// ListOutputSchemas returns the output schemas inferred for the nodes of a
+// workflow by its test runs
+func (s *WorkflowService) ListOutputSchemas(ctx context.Context, workflowID, userID string) ([]*workflow.NodeOutputSchema, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	return s.repo.ListNodeOutputSchemas(ctx, workflowID)
+}
+
+// recordOutputSchemas infers the output schemas of the nodes a test run
+// has sample outputs of and merges them into those stored, so fields seen
+// in any run are known. Triggers output the test input. Reset replaces the
+// stored schemas instead.
+func (s *WorkflowService) recordOutputSchemas(ctx context.Context, wf *workflow.Workflow, userID string, input map[string]interface{}, outputs map[string]map[string]interface{}, reset bool) (map[string]*workflow.FieldSchema, error) {
+	stored, err := s.repo.ListNodeOutputSchemas(ctx, wf.ID)
+	if err != nil {
+		return nil, err
+	}
+	byNode := make(map[string]*workflow.NodeOutputSchema, len(stored))
+	for _, schema := range stored {
+		byNode[schema.NodeID] = schema
+	}
+
+	inferred := make(map[string]*workflow.FieldSchema)
+	for _, node := range wf.Nodes {
+		sample, ok := outputs[node.ID]
+		if !ok && input != nil && (node.Type == workflow.NodeTypeTrigger || node.Type == workflow.NodeTypeWebhook) {
+			sample, ok = input, true
+		}
+		if !ok {
+			continue
+		}
+
+		record, exists := byNode[node.ID]
+		if !exists {
+			record = &workflow.NodeOutputSchema{WorkflowID: wf.ID, NodeID: node.ID}
+		}
+		if reset || !exists {
+			record.Schema, record.Samples = nil, 0
+		}
+		record.Schema = workflow.MergeSchemas(record.Schema, workflow.InferSchema(sample))
+		record.Samples++
+		record.UpdatedBy = userID
+
+		if err := s.repo.SaveNodeOutputSchema(ctx, record); err != nil {
+			return nil, err
+		}
+		inferred[node.ID] = record.Schema
+	}
+
+	if len(inferred) > 0 {
+		s.logger.Info("Node output schemas recorded",
+			"workflow_id", wf.ID,
+			"nodes", len(inferred),
+			"reset", reset,
+		)
+	}
+	return inferred, nil
+}
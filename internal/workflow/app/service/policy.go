@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// GetWorkspacePolicy returns the policy of a workspace, without defaults
+// when none was set
+func (s *WorkflowService) GetWorkspacePolicy(ctx context.Context, workspaceID string) (*workflow.WorkspacePolicy, error) {
+	policy, err := s.repo.GetWorkspacePolicy(ctx, workspaceID)
+	if errors.Is(err, workflow.ErrPolicyNotFound) {
+		return &workflow.WorkspacePolicy{WorkspaceID: workspaceID, Locked: []string{}}, nil
+	}
+	return policy, err
+}
+
+// UpdateWorkspacePolicy replaces the defaults and locks of a workspace.
+// Existing workflows keep their settings, deviations from newly locked
+// settings show up as violations in the compliance report.
+func (s *WorkflowService) UpdateWorkspacePolicy(ctx context.Context, workspaceID, updatedBy string, req *workflow.UpdatePolicyRequest) (*workflow.WorkspacePolicy, error) {
+	policy, err := s.GetWorkspacePolicy(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if policy.CreatedAt.IsZero() {
+		policy.CreatedAt = now
+	}
+	policy.Defaults = req.Defaults
+	policy.Locked = req.Locked
+	policy.NodeTypes = req.NodeTypes
+	policy.Activation = req.Activation
+	if policy.Locked == nil {
+		policy.Locked = []string{}
+	}
+	policy.UpdatedBy = updatedBy
+	policy.UpdatedAt = now
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveWorkspacePolicy(ctx, policy); err != nil {
+		s.logger.Error("Failed to save workspace policy", "workspace_id", workspaceID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Workspace policy updated",
+		"workspace_id", workspaceID,
+		"locked", policy.Locked,
+		"allowed_node_types", policy.NodeTypes.Allow,
+		"denied_node_types", policy.NodeTypes.Deny,
+		"require_valid", policy.Activation.RequireValid,
+		"require_tests", policy.Activation.RequireTests,
+		"updated_by", updatedBy,
+	)
+	return policy, nil
+}
+
+// GetComplianceReport lists the workflows of a workspace whose settings
+// deviate from its policy
+func (s *WorkflowService) GetComplianceReport(ctx context.Context, workspaceID string) (*workflow.ComplianceReport, error) {
+	policy, err := s.GetWorkspacePolicy(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflows, err := s.repo.ListWorkspaceWorkflows(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &workflow.ComplianceReport{
+		WorkspaceID: workspaceID,
+		Policy:      policy,
+		Workflows:   len(workflows),
+		Deviating:   []workflow.WorkflowCompliance{},
+		GeneratedAt: time.Now().UTC(),
+	}
+	for _, wf := range workflows {
+		deviations := policy.Deviations(wf)
+		blocked := policy.BlockedNodes(wf)
+		if len(deviations) == 0 && len(blocked) == 0 {
+			report.Compliant++
+			continue
+		}
+
+		entry := workflow.WorkflowCompliance{
+			WorkflowID:   wf.ID,
+			Name:         wf.Name,
+			Deviations:   deviations,
+			BlockedNodes: blocked,
+			Violation:    len(blocked) > 0,
+		}
+		if entry.Deviations == nil {
+			entry.Deviations = []workflow.PolicyDeviation{}
+		}
+		for _, dev := range deviations {
+			if dev.Locked {
+				entry.Violation = true
+			}
+		}
+		if entry.Violation {
+			report.Violations++
+		}
+		report.Deviating = append(report.Deviating, entry)
+	}
+
+	return report, nil
+}
+
+// checkNodeTypes fails when wf uses node types blocked by the policy of the
+// workspace
+func (s *WorkflowService) checkNodeTypes(ctx context.Context, workspaceID string, wf *workflow.Workflow) error {
+	policy, err := s.GetWorkspacePolicy(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	return policy.CheckNodeTypes(wf)
+}
+
+// applySettings overrides the settings of wf with those given in a request,
+// settings left out keep their value
+func applySettings(wf *workflow.Workflow, settings map[string]interface{}) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return ErrInvalidWorkflow.WithMessage("invalid settings: %v", err)
+	}
+	if err := json.Unmarshal(data, &wf.Settings); err != nil {
+		return ErrInvalidWorkflow.WithMessage("invalid settings: %v", err)
+	}
+	return nil
+}
@@ -8,23 +8,30 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/linkflow-go/internal/workflow/adapters/templates"
+	"github.com/linkflow-go/internal/workflow/app/sharing"
 	"github.com/linkflow-go/internal/workflow/ports"
+	"github.com/linkflow-go/pkg/auth/sealed"
 	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/database"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/events"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/quota"
 	"github.com/redis/go-redis/v9"
 )
 
 var (
-	ErrWorkflowNotFound = errors.New("workflow not found")
-	ErrInvalidWorkflow  = errors.New("invalid workflow")
-	ErrUnauthorized     = errors.New("unauthorized")
-	ErrWorkflowInactive = errors.New("workflow is inactive")
-	ErrTemplateNotFound = errors.New("template not found")
+	ErrWorkflowNotFound = apperrors.New(apperrors.CategoryNotFound, "WORKFLOW_NOT_FOUND", "workflow not found")
+	ErrInvalidWorkflow  = apperrors.New(apperrors.CategoryValidation, "INVALID_WORKFLOW", "invalid workflow")
+	ErrUnauthorized     = apperrors.New(apperrors.CategoryPermission, "WORKFLOW_ACCESS_DENIED", "unauthorized")
+	ErrWorkflowInactive = apperrors.New(apperrors.CategoryValidation, "WORKFLOW_INACTIVE", "workflow is inactive")
+	ErrTemplateNotFound = apperrors.New(apperrors.CategoryNotFound, "TEMPLATE_NOT_FOUND", "template not found")
+	ErrNodeNotFound     = apperrors.New(apperrors.CategoryNotFound, "NODE_NOT_FOUND", "node not found")
 )
 
 type WorkflowService struct {
 	repo              ports.WorkflowRepository
+	tx                ports.Transactor
 	eventBus          events.EventBus
 	redis             *redis.Client
 	logger            logger.Logger
@@ -32,50 +39,56 @@ type WorkflowService struct {
 	triggerManager    ports.TriggerManager
 	templateManager   ports.TemplateManager
 	variableManager   *workflow.VariableManager
+	quota             *quota.Enforcer
+	sealer            *sealed.Sealer
+	git               ports.GitSync
 }
 
 func NewWorkflowService(
 	repo ports.WorkflowRepository,
+	tx ports.Transactor,
 	eventBus events.EventBus,
 	redis *redis.Client,
 	logger logger.Logger,
 	triggerManager ports.TriggerManager,
 	templateManager ports.TemplateManager,
 ) *WorkflowService {
+	validationService := NewValidationService(redis, logger)
+	validationService.SetOutputSchemas(repo.ListNodeOutputSchemas)
+
 	return &WorkflowService{
 		repo:              repo,
+		tx:                tx,
 		eventBus:          eventBus,
 		redis:             redis,
 		logger:            logger,
-		validationService: NewValidationService(redis, logger),
+		validationService: validationService,
 		triggerManager:    triggerManager,
 		templateManager:   templateManager,
 		variableManager:   workflow.NewVariableManager(),
 	}
 }
 
-func (s *WorkflowService) CheckReady() error {
-	// Check database connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := s.repo.Ping(ctx); err != nil {
-		return err
-	}
-
-	// Check Redis connection
-	if err := s.redis.Ping(ctx).Err(); err != nil {
-		return err
-	}
+// SetQuota wires the enforcer bounding the active workflows of each tenant
+// by its plan
+func (s *WorkflowService) SetQuota(enforcer *quota.Enforcer) {
+	s.quota = enforcer
+}
 
-	return nil
+// SetSealer wires the sealer encrypting secret variables at rest, nil
+// rejects secret variables
+func (s *WorkflowService) SetSealer(sealer *sealed.Sealer) {
+	s.sealer = sealer
 }
 
-func (s *WorkflowService) ListWorkflows(ctx context.Context, userID string, page, limit int, status string) ([]*workflow.Workflow, int64, error) {
+// ListWorkflows returns the page of workflows of a user, all users when
+// userID is empty, most recently updated first. page.Next is set to the
+// cursor of the following page.
+func (s *WorkflowService) ListWorkflows(ctx context.Context, userID, status string, page *database.CursorPage) ([]*workflow.Workflow, int64, error) {
 	opts := ports.ListWorkflowsOptions{
 		UserID: userID,
-		Page:   page,
-		Limit:  limit,
 		Status: status,
+		Cursor: page,
 	}
 	return s.repo.ListWorkflows(ctx, opts)
 }
@@ -92,6 +105,19 @@ func (s *WorkflowService) CreateWorkflow(ctx context.Context, req *workflow.Crea
 
 	// Create new workflow
 	wf := workflow.NewWorkflow(req.Name, req.Description, req.UserID)
+	if req.WorkspaceID != "" && req.WorkspaceID != req.UserID {
+		wf.TeamID = req.WorkspaceID
+	}
+
+	// Inherit the workspace defaults, then apply the overrides of the request
+	policy, err := s.GetWorkspacePolicy(ctx, wf.WorkspaceID())
+	if err != nil {
+		return nil, err
+	}
+	policy.Apply(&wf.Settings)
+	if err := applySettings(wf, req.Settings); err != nil {
+		return nil, err
+	}
 
 	// Set nodes and connections if provided
 	if req.Nodes != nil {
@@ -103,6 +129,9 @@ func (s *WorkflowService) CreateWorkflow(ctx context.Context, req *workflow.Crea
 	if req.Tags != nil {
 		wf.Tags = req.Tags
 	}
+	if req.Annotations != nil {
+		wf.Annotations = req.Annotations
+	}
 
 	// Validate workflow structure (DAG validation)
 	if len(wf.Nodes) > 0 {
@@ -112,23 +141,30 @@ func (s *WorkflowService) CreateWorkflow(ctx context.Context, req *workflow.Crea
 		}
 	}
 
-	// Store in database
-	if err := s.repo.CreateWorkflow(ctx, wf); err != nil {
-		s.logger.Error("Failed to create workflow", "error", err)
+	if err := policy.CheckLocked(wf, nil); err != nil {
 		return nil, err
 	}
-
-	// Publish WorkflowCreated event
-	event := events.Event{
-		Type: "workflow.created",
-		Payload: map[string]interface{}{
-			"workflow_id": wf.ID,
-			"user_id":     wf.UserID,
-			"name":        wf.Name,
-		},
+	if err := policy.CheckNodeTypes(wf); err != nil {
+		return nil, err
 	}
-	if err := s.eventBus.Publish(ctx, event); err != nil {
-		s.logger.Warn("Failed to publish workflow created event", "error", err)
+
+	// Store in database with the WorkflowCreated event
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateWorkflow(ctx, wf); err != nil {
+			return err
+		}
+		return s.eventBus.Publish(ctx, events.Event{
+			Type: "workflow.created",
+			Payload: map[string]interface{}{
+				"workflow_id": wf.ID,
+				"user_id":     wf.UserID,
+				"name":        wf.Name,
+			},
+		})
+	})
+	if err != nil {
+		s.logger.Error("Failed to create workflow", "error", err)
+		return nil, err
 	}
 
 	s.logger.Info("Workflow created", "id", wf.ID, "user", wf.UserID)
@@ -136,37 +172,38 @@ func (s *WorkflowService) CreateWorkflow(ctx context.Context, req *workflow.Crea
 }
 
 func (s *WorkflowService) UpdateWorkflow(ctx context.Context, req *workflow.UpdateWorkflowRequest) (*workflow.Workflow, error) {
-	// Get existing workflow
-	wf, err := s.repo.GetWorkflow(ctx, req.WorkflowID, req.UserID)
+	// Get existing workflow, editable by its owner and the users it is
+	// shared with to edit it
+	wf, err := s.sharedWorkflow(ctx, req.WorkflowID, req.UserID, sharing.PermissionEdit)
 	if err != nil {
 		s.logger.Error("Workflow not found", "id", req.WorkflowID, "error", err)
 		return nil, ErrWorkflowNotFound
 	}
 
-	// Check version for optimistic locking
-	if req.Version > 0 && wf.Version != req.Version {
-		s.logger.Warn("Version mismatch", "expected", req.Version, "actual", wf.Version)
-		return nil, errors.New("version mismatch - workflow was modified by another user")
-	}
-
 	// Store previous version for history
 	previousVersion := wf.Version
+	previous := *wf
 
-	// Update workflow fields
-	if req.Name != "" {
-		wf.Name = req.Name
-	}
-	if req.Description != "" {
-		wf.Description = req.Description
-	}
-	if req.Nodes != nil {
-		wf.Nodes = req.Nodes
-	}
-	if req.Connections != nil {
-		wf.Connections = req.Connections
+	// Edits started from an older version are merged with the changes
+	// saved since, node by node, instead of failing
+	var edited *workflow.DefinitionDiff
+	if req.Version > 0 && wf.Version != req.Version {
+		s.logger.Info("Merging concurrent edit", "id", wf.ID, "base", req.Version, "actual", wf.Version)
+		merged, diff, err := s.mergeConcurrentEdit(ctx, wf, req)
+		if err != nil {
+			return nil, err
+		}
+		wf, edited = merged, diff
+	} else {
+		if err := applyUpdate(wf, req); err != nil {
+			return nil, err
+		}
+		edited = workflow.DiffDefinitions(&previous, wf)
 	}
-	if req.Tags != nil {
-		wf.Tags = req.Tags
+
+	// Nodes other users have open cannot be changed under them
+	if err := s.checkNodeClaims(ctx, wf.ID, req.UserID, edited); err != nil {
+		return nil, err
 	}
 
 	// Increment version
@@ -181,30 +218,85 @@ func (s *WorkflowService) UpdateWorkflow(ctx context.Context, req *workflow.Upda
 		}
 	}
 
-	// Save to database
-	if err := s.repo.UpdateWorkflow(ctx, wf); err != nil {
-		s.logger.Error("Failed to update workflow", "error", err)
+	// Settings locked by the workspace cannot be changed, deviations the
+	// workflow had before the lock may stay
+	policy, err := s.GetWorkspacePolicy(ctx, wf.WorkspaceID())
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.CheckLocked(wf, &previous); err != nil {
+		return nil, err
+	}
+	if err := policy.CheckNodeTypes(wf); err != nil {
 		return nil, err
 	}
 
-	// Publish WorkflowUpdated event
-	event := events.Event{
-		Type: "workflow.updated",
-		Payload: map[string]interface{}{
-			"workflow_id":      wf.ID,
-			"user_id":          wf.UserID,
-			"version":          wf.Version,
-			"previous_version": previousVersion,
-		},
+	// Save to database with the summary of the changes and the
+	// WorkflowUpdated event
+	change := workflow.NewWorkflowChange(&previous, wf, req.UserID)
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateWorkflow(ctx, wf); err != nil {
+			return err
+		}
+		if change != nil {
+			if err := s.repo.CreateWorkflowChange(ctx, change); err != nil {
+				return err
+			}
+		}
+		return s.eventBus.Publish(ctx, events.Event{
+			Type: "workflow.updated",
+			Payload: map[string]interface{}{
+				"workflow_id":      wf.ID,
+				"user_id":          wf.UserID,
+				"version":          wf.Version,
+				"previous_version": previousVersion,
+			},
+		})
+	})
+	if err != nil {
+		s.logger.Error("Failed to update workflow", "error", err)
+		return nil, err
 	}
-	if err := s.eventBus.Publish(ctx, event); err != nil {
-		s.logger.Warn("Failed to publish workflow updated event", "error", err)
+
+	// Fields read that the nodes before no longer output are reported by
+	// validation, the save goes through
+	if warnings := s.validationService.CheckOutputContracts(ctx, wf); len(warnings) > 0 {
+		s.logger.Warn("Workflow reads fields missing from node outputs",
+			"id", wf.ID,
+			"warnings", warnings,
+		)
 	}
 
 	s.logger.Info("Workflow updated", "id", wf.ID, "version", wf.Version)
 	return wf, nil
 }
 
+// applyUpdate sets the fields of a workflow an update request gives
+func applyUpdate(wf *workflow.Workflow, req *workflow.UpdateWorkflowRequest) error {
+	if req.Name != "" {
+		wf.Name = req.Name
+	}
+	if req.Description != "" {
+		wf.Description = req.Description
+	}
+	if req.Nodes != nil {
+		wf.Nodes = req.Nodes
+	}
+	if req.Connections != nil {
+		wf.Connections = req.Connections
+	}
+	if req.Tags != nil {
+		wf.Tags = req.Tags
+	}
+	if req.Annotations != nil {
+		wf.Annotations = req.Annotations
+	} else if req.Nodes != nil {
+		// Saves of the nodes alone keep the annotations of the nodes left
+		wf.PruneAnnotations()
+	}
+	return applySettings(wf, req.Settings)
+}
+
 func (s *WorkflowService) DeleteWorkflow(ctx context.Context, workflowID, userID string) error {
 	// Check if workflow exists before deletion
 	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
@@ -213,23 +305,26 @@ func (s *WorkflowService) DeleteWorkflow(ctx context.Context, workflowID, userID
 		return ErrWorkflowNotFound
 	}
 
-	// Perform soft delete in database
-	if err := s.repo.DeleteWorkflow(ctx, workflowID, userID); err != nil {
+	// Perform soft delete in database with the WorkflowDeleted event
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteWorkflow(ctx, workflowID, userID); err != nil {
+			return err
+		}
+		return s.eventBus.Publish(ctx, events.Event{
+			Type: "workflow.deleted",
+			Payload: map[string]interface{}{
+				"workflow_id": workflowID,
+				"user_id":     userID,
+				"name":        wf.Name,
+			},
+		})
+	})
+	if err != nil {
 		s.logger.Error("Failed to delete workflow", "error", err)
 		return err
 	}
-
-	// Publish WorkflowDeleted event
-	event := events.Event{
-		Type: "workflow.deleted",
-		Payload: map[string]interface{}{
-			"workflow_id": workflowID,
-			"user_id":     userID,
-			"name":        wf.Name,
-		},
-	}
-	if err := s.eventBus.Publish(ctx, event); err != nil {
-		s.logger.Warn("Failed to publish workflow deleted event", "error", err)
+	if wf.IsActive {
+		s.recordActiveWorkflows(ctx)
 	}
 
 	s.logger.Info("Workflow deleted", "id", workflowID, "user", userID)
@@ -302,59 +397,106 @@ func (s *WorkflowService) CreateWorkflowVersion(ctx context.Context, workflowID,
 	return wf.Version, nil
 }
 
-func (s *WorkflowService) RollbackWorkflowVersion(ctx context.Context, workflowID string, version int, userID string) error {
+// RollbackWorkflowVersion restores a version and returns the workflow as
+// restored
+func (s *WorkflowService) RollbackWorkflowVersion(ctx context.Context, workflowID string, version int, userID string) (*workflow.Workflow, error) {
 	// Verify workflow exists and user has permission
-	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
-		return ErrWorkflowNotFound
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
 	}
 
-	// Restore to specific version
-	if err := s.repo.RestoreVersion(ctx, workflowID, version, userID); err != nil {
-		s.logger.Error("Failed to rollback workflow version", "workflow_id", workflowID, "version", version, "error", err)
-		return err
+	// Versions saved before the workspace blocked a node type stay blocked
+	restored, err := s.GetWorkflowVersion(ctx, workflowID, version, userID)
+	if err != nil {
+		return nil, err
 	}
-
-	// Publish event
-	event := events.Event{
-		Type: "workflow.version.rollback",
-		Payload: map[string]interface{}{
-			"workflow_id": workflowID,
-			"version":     version,
-			"user_id":     userID,
-		},
+	if err := s.checkNodeTypes(ctx, wf.WorkspaceID(), restored); err != nil {
+		return nil, err
 	}
-	if err := s.eventBus.Publish(ctx, event); err != nil {
-		s.logger.Warn("Failed to publish rollback event", "error", err)
+
+	// Restore to specific version with the rollback event
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.RestoreVersion(ctx, workflowID, version, userID); err != nil {
+			return err
+		}
+		return s.eventBus.Publish(ctx, events.Event{
+			Type: "workflow.version.rollback",
+			Payload: map[string]interface{}{
+				"workflow_id": workflowID,
+				"version":     version,
+				"user_id":     userID,
+			},
+		})
+	})
+	if err != nil {
+		s.logger.Error("Failed to rollback workflow version", "workflow_id", workflowID, "version", version, "error", err)
+		return nil, err
 	}
 
 	s.logger.Info("Workflow rolled back", "workflow_id", workflowID, "version", version)
-	return nil
+	return s.repo.GetWorkflow(ctx, workflowID, userID)
 }
 
-func (s *WorkflowService) ActivateWorkflow(ctx context.Context, workflowID, userID string) error {
+// ActivateWorkflow activates a workflow and its triggers and returns the
+// workflow with its new status and version
+func (s *WorkflowService) ActivateWorkflow(ctx context.Context, workflowID, userID string, override bool) (*workflow.Workflow, error) {
 	// Get workflow
-	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	wf, err := s.sharedWorkflow(ctx, workflowID, userID, sharing.PermissionAdmin)
 	if err != nil {
-		return ErrWorkflowNotFound
+		return nil, ErrWorkflowNotFound
 	}
 
 	// Validate workflow before activation
 	if len(wf.Nodes) > 0 {
 		if err := wf.Validate(); err != nil {
 			s.logger.Error("Workflow validation failed during activation", "error", err)
-			return ErrInvalidWorkflow
+			return nil, ErrInvalidWorkflow
+		}
+	}
+	if err := s.checkNodeTypes(ctx, wf.WorkspaceID(), wf); err != nil {
+		return nil, err
+	}
+
+	// The workspace may require the version to validate and pass its tests
+	policy, err := s.GetWorkspacePolicy(ctx, wf.WorkspaceID())
+	if err != nil {
+		return nil, err
+	}
+	if policy.Activation.Enabled() {
+		if err := s.enforceActivationPolicy(ctx, &policy.Activation, wf, wf, userID, override); err != nil {
+			return nil, err
+		}
+	}
+
+	// The plan of the tenant bounds its active workflows
+	if !wf.IsActive {
+		if err := s.checkActivation(ctx); err != nil {
+			return nil, err
 		}
 	}
 
 	// Activate workflow
 	if err := wf.Activate(); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Update in database
-	if err := s.repo.UpdateWorkflow(ctx, wf); err != nil {
+	// Update in database with the activation event
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateWorkflow(ctx, wf); err != nil {
+			return err
+		}
+		return s.eventBus.Publish(ctx, events.Event{
+			Type: "workflow.activated",
+			Payload: map[string]interface{}{
+				"workflow_id": workflowID,
+				"user_id":     userID,
+			},
+		})
+	})
+	if err != nil {
 		s.logger.Error("Failed to activate workflow", "error", err)
-		return err
+		return nil, err
 	}
 
 	// Activate associated triggers
@@ -367,36 +509,39 @@ func (s *WorkflowService) ActivateWorkflow(ctx context.Context, workflowID, user
 		}
 	}
 
-	// Publish event
-	event := events.Event{
-		Type: "workflow.activated",
-		Payload: map[string]interface{}{
-			"workflow_id": workflowID,
-			"user_id":     userID,
-		},
-	}
-	if err := s.eventBus.Publish(ctx, event); err != nil {
-		s.logger.Warn("Failed to publish activation event", "error", err)
-	}
-
+	s.recordActiveWorkflows(ctx)
 	s.logger.Info("Workflow activated", "workflow_id", workflowID)
-	return nil
+	return wf, nil
 }
 
-func (s *WorkflowService) DeactivateWorkflow(ctx context.Context, workflowID, userID string) error {
+// DeactivateWorkflow deactivates a workflow and its triggers and returns the
+// workflow with its new status and version
+func (s *WorkflowService) DeactivateWorkflow(ctx context.Context, workflowID, userID string) (*workflow.Workflow, error) {
 	// Get workflow
 	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
 	if err != nil {
-		return ErrWorkflowNotFound
+		return nil, ErrWorkflowNotFound
 	}
 
 	// Deactivate workflow
 	wf.Deactivate()
 
-	// Update in database
-	if err := s.repo.UpdateWorkflow(ctx, wf); err != nil {
+	// Update in database with the deactivation event
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateWorkflow(ctx, wf); err != nil {
+			return err
+		}
+		return s.eventBus.Publish(ctx, events.Event{
+			Type: "workflow.deactivated",
+			Payload: map[string]interface{}{
+				"workflow_id": workflowID,
+				"user_id":     userID,
+			},
+		})
+	})
+	if err != nil {
 		s.logger.Error("Failed to deactivate workflow", "error", err)
-		return err
+		return nil, err
 	}
 
 	// Deactivate associated triggers
@@ -409,20 +554,36 @@ func (s *WorkflowService) DeactivateWorkflow(ctx context.Context, workflowID, us
 		}
 	}
 
-	// Publish event
-	event := events.Event{
-		Type: "workflow.deactivated",
-		Payload: map[string]interface{}{
-			"workflow_id": workflowID,
-			"user_id":     userID,
-		},
+	s.recordActiveWorkflows(ctx)
+	s.logger.Info("Workflow deactivated", "workflow_id", workflowID)
+	return wf, nil
+}
+
+// checkActivation admits the activation of one more workflow of the tenant
+// of ctx
+func (s *WorkflowService) checkActivation(ctx context.Context) error {
+	if s.quota == nil {
+		return nil
 	}
-	if err := s.eventBus.Publish(ctx, event); err != nil {
-		s.logger.Warn("Failed to publish deactivation event", "error", err)
+	active, err := s.repo.CountActiveWorkflows(ctx)
+	if err != nil {
+		return err
 	}
+	return s.quota.CheckActivation(ctx, active)
+}
 
-	s.logger.Info("Workflow deactivated", "workflow_id", workflowID)
-	return nil
+// recordActiveWorkflows records the active workflows of the tenant of ctx
+// for its usage report
+func (s *WorkflowService) recordActiveWorkflows(ctx context.Context) {
+	if s.quota == nil {
+		return
+	}
+	active, err := s.repo.CountActiveWorkflows(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to count active workflows", "error", err)
+		return
+	}
+	s.quota.SetActiveWorkflows(ctx, active)
 }
 
 func (s *WorkflowService) DuplicateWorkflow(ctx context.Context, workflowID, userID, name string) (*workflow.Workflow, error) {
@@ -435,36 +596,41 @@ func (s *WorkflowService) DuplicateWorkflow(ctx context.Context, workflowID, use
 	// Clone workflow
 	clone := original.Clone(name)
 	clone.UserID = userID
-
-	// Save clone
-	if err := s.repo.CreateWorkflow(ctx, clone); err != nil {
-		s.logger.Error("Failed to duplicate workflow", "error", err)
+	if err := s.checkNodeTypes(ctx, clone.WorkspaceID(), clone); err != nil {
 		return nil, err
 	}
 
-	// Publish event
-	event := events.Event{
-		Type: "workflow.duplicated",
-		Payload: map[string]interface{}{
-			"original_id": workflowID,
-			"clone_id":    clone.ID,
-			"user_id":     userID,
-		},
-	}
-	if err := s.eventBus.Publish(ctx, event); err != nil {
-		s.logger.Warn("Failed to publish duplication event", "error", err)
+	// Save clone with the duplication event
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateWorkflow(ctx, clone); err != nil {
+			return err
+		}
+		return s.eventBus.Publish(ctx, events.Event{
+			Type: "workflow.duplicated",
+			Payload: map[string]interface{}{
+				"original_id": workflowID,
+				"clone_id":    clone.ID,
+				"user_id":     userID,
+			},
+		})
+	})
+	if err != nil {
+		s.logger.Error("Failed to duplicate workflow", "error", err)
+		return nil, err
 	}
 
 	s.logger.Info("Workflow duplicated", "original_id", workflowID, "clone_id", clone.ID)
 	return clone, nil
 }
 
-func (s *WorkflowService) ValidateWorkflow(ctx context.Context, workflowID, userID string) ([]string, []string, error) {
+// ValidateWorkflow validates a workflow and diagnoses its graph. A workflow
+// failing validation is reported in the result, not as an error.
+func (s *WorkflowService) ValidateWorkflow(ctx context.Context, workflowID, userID string) (*ValidationResult, error) {
 	// Get the workflow
 	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
 	if err != nil {
 		s.logger.Error("Failed to get workflow for validation", "id", workflowID, "error", err)
-		return nil, nil, ErrWorkflowNotFound
+		return nil, ErrWorkflowNotFound
 	}
 
 	// Perform comprehensive validation
@@ -478,12 +644,19 @@ func (s *WorkflowService) ValidateWorkflow(ctx context.Context, workflowID, user
 		}
 	}
 
+	result := &ValidationResult{
+		Errors:      errors,
+		Warnings:    warnings,
+		Valid:       err == nil,
+		Diagnostics: s.validationService.Diagnose(ctx, wf),
+	}
+
 	// Publish validation event
 	event := events.Event{
 		Type: "workflow.validated",
 		Payload: map[string]interface{}{
 			"workflow_id": workflowID,
-			"valid":       err == nil,
+			"valid":       result.Valid,
 			"errors":      len(errors),
 			"warnings":    len(warnings),
 		},
@@ -492,10 +665,60 @@ func (s *WorkflowService) ValidateWorkflow(ctx context.Context, workflowID, user
 		s.logger.Warn("Failed to publish validation event", "error", pubErr)
 	}
 
-	return errors, warnings, err
+	return result, nil
+}
+
+// LintWorkflow runs the lint rules against a workflow
+func (s *WorkflowService) LintWorkflow(ctx context.Context, workflowID, userID string) (*workflow.LintReport, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	return s.validationService.Lint(ctx, wf), nil
+}
+
+// SetLintOptions tunes the built-in lint rules run by validation
+func (s *WorkflowService) SetLintOptions(opts workflow.LintOptions) {
+	s.validationService.SetLinter(workflow.NewDefaultLinter(opts))
 }
 
-func (s *WorkflowService) ExecuteWorkflow(ctx context.Context, workflowID, userID string, data map[string]interface{}) (string, error) {
+// PreviewTransform dry runs a transform node of a workflow over sample
+// inputs. Parameters, when given, are tried instead of those saved on the
+// node so mappings can be previewed while they are edited.
+func (s *WorkflowService) PreviewTransform(ctx context.Context, workflowID, nodeID, userID string, parameters map[string]interface{}, samples []map[string]interface{}) (*workflow.TransformPreview, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	var node *workflow.Node
+	for i := range wf.Nodes {
+		if wf.Nodes[i].ID == nodeID {
+			node = &wf.Nodes[i]
+			break
+		}
+	}
+	if node == nil {
+		return nil, ErrNodeNotFound.WithMessage("node %s not found", nodeID)
+	}
+	if node.Type != workflow.NodeTypeTransform {
+		return nil, workflow.ErrInvalidTransform.WithMessage("node %s is a %s node, not a transform node", nodeID, node.Type)
+	}
+
+	if parameters == nil {
+		parameters = node.Parameters
+	}
+	transform, err := workflow.ParseTransformParameters(parameters)
+	if err != nil {
+		return nil, workflow.ErrInvalidTransform.WithMessage("%v", err)
+	}
+	return transform.Preview(samples), nil
+}
+
+// ExecuteWorkflow requests an execution of a workflow. Given an environment,
+// by ID or name, the execution runs the version deployed to it with its
+// variables and credentials; otherwise it runs in the default environment.
+func (s *WorkflowService) ExecuteWorkflow(ctx context.Context, workflowID, userID, environment string, data map[string]interface{}) (string, error) {
 	// Get workflow
 	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
 	if err != nil {
@@ -510,17 +733,32 @@ func (s *WorkflowService) ExecuteWorkflow(ctx context.Context, workflowID, userI
 	// Generate execution ID
 	executionID := uuid.New().String()
 
+	payload := map[string]interface{}{
+		"execution_id": executionID,
+		"workflow_id":  workflowID,
+		"user_id":      userID,
+		"input_data":   data,
+		"debug":        logger.Debugging(ctx),
+	}
+	// Without an environment the execution service resolves the version as
+	// it starts the run, from the default environment or a canary
+	if environment != "" {
+		env, err := s.resolveEnvironment(ctx, workflowID, environment)
+		if err != nil {
+			return "", err
+		}
+		if env.DeployedVersion == 0 {
+			return "", workflow.ErrEnvironmentNotDeployed.WithMessage("no workflow version is deployed to %s", env.Name)
+		}
+		payload["environment"] = env.ID
+		payload["version"] = env.DeployedVersion
+	}
+
 	// Publish execution request event
 	event := events.Event{
-		Type:        "execution.requested",
+		Type:        events.ExecutionRequested,
 		AggregateID: executionID,
-		Payload: map[string]interface{}{
-			"execution_id": executionID,
-			"workflow_id":  workflowID,
-			"user_id":      userID,
-			"input_data":   data,
-			"version":      wf.Version,
-		},
+		Payload:     payload,
 	}
 	if err := s.eventBus.Publish(ctx, event); err != nil {
 		s.logger.Error("Failed to publish execution request", "error", err)
@@ -531,13 +769,33 @@ func (s *WorkflowService) ExecuteWorkflow(ctx context.Context, workflowID, userI
 	return executionID, nil
 }
 
-func (s *WorkflowService) TestWorkflow(ctx context.Context, workflowID, userID string, data map[string]interface{}) (interface{}, error) {
+func (s *WorkflowService) TestWorkflow(ctx context.Context, workflowID, userID string, req workflow.TestWorkflowRequest) (interface{}, error) {
+	data := req.Data
+
 	// Get workflow
 	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
 	if err != nil {
 		return nil, ErrWorkflowNotFound
 	}
 
+	// A fixture of a trigger replays a payload it received
+	if req.Fixture != "" {
+		if req.TriggerID == "" {
+			return nil, workflow.ErrInvalidFixture.WithMessage("a fixture is replayed with the ID of its trigger")
+		}
+		if data, err = s.fixtureData(ctx, workflowID, req.TriggerID, req.Fixture); err != nil {
+			return nil, err
+		}
+	}
+
+	// Schemas of the sample outputs are recorded before validation checks
+	// the fields nodes read against them
+	schemas, err := s.recordOutputSchemas(ctx, wf, userID, data, req.NodeOutputs, req.ResetSchemas)
+	if err != nil {
+		s.logger.Error("Failed to record node output schemas", "workflow_id", workflowID, "error", err)
+		return nil, err
+	}
+
 	// Validate workflow
 	errors, warnings, validationErr := s.validationService.ValidateWorkflow(ctx, wf)
 
@@ -551,6 +809,9 @@ func (s *WorkflowService) TestWorkflow(ctx context.Context, workflowID, userID s
 		"input_data":  data,
 		"test_mode":   true,
 	}
+	if len(schemas) > 0 {
+		result["output_schemas"] = schemas
+	}
 
 	// If valid, simulate execution order
 	if validationErr == nil {
@@ -559,6 +820,18 @@ func (s *WorkflowService) TestWorkflow(ctx context.Context, workflowID, userID s
 		result["complexity"] = s.validationService.AnalyzeComplexity(ctx, wf)
 	}
 
+	// Written for debug runs whatever the level of the service
+	if logger.Debugging(ctx) {
+		result["debug"] = true
+		logger.FromContext(ctx, s.logger).Debug("Workflow tested",
+			"workflow_id", workflowID,
+			"valid", validationErr == nil,
+			"errors", errors,
+			"warnings", warnings,
+			"execution_order", result["execution_order"],
+		)
+	}
+
 	return result, nil
 }
 
@@ -634,11 +907,13 @@ func (s *WorkflowService) UnshareWorkflow(ctx context.Context, workflowID, userI
 	return nil
 }
 
-func (s *WorkflowService) PublishWorkflow(ctx context.Context, workflowID, userID, description string, tags []string) error {
+// PublishWorkflow publishes a workflow as a public template and returns the
+// template
+func (s *WorkflowService) PublishWorkflow(ctx context.Context, workflowID, userID, description string, tags []string) (*templates.Template, error) {
 	// Get workflow
 	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
 	if err != nil {
-		return ErrWorkflowNotFound
+		return nil, ErrWorkflowNotFound
 	}
 
 	// Create template from workflow
@@ -656,11 +931,11 @@ func (s *WorkflowService) PublishWorkflow(ctx context.Context, workflowID, userI
 
 	if err := s.templateManager.CreateTemplate(ctx, template); err != nil {
 		s.logger.Error("Failed to publish workflow", "error", err)
-		return err
+		return nil, err
 	}
 
 	s.logger.Info("Workflow published", "workflow_id", workflowID, "template_id", template.ID)
-	return nil
+	return template, nil
 }
 
 func (s *WorkflowService) ImportWorkflow(ctx context.Context, userID string, data interface{}, format string) (*workflow.Workflow, error) {
@@ -693,6 +968,18 @@ func (s *WorkflowService) ImportWorkflow(ctx context.Context, userID string, dat
 	wf.CreatedAt = time.Now()
 	wf.UpdatedAt = time.Now()
 
+	// Imported settings are kept, unless the workspace locked them
+	policy, err := s.GetWorkspacePolicy(ctx, wf.WorkspaceID())
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.CheckLocked(wf, nil); err != nil {
+		return nil, err
+	}
+	if err := policy.CheckNodeTypes(wf); err != nil {
+		return nil, err
+	}
+
 	// Save workflow
 	if err := s.repo.CreateWorkflow(ctx, wf); err != nil {
 		s.logger.Error("Failed to import workflow", "error", err)
@@ -733,13 +1020,12 @@ func (s *WorkflowService) GetWorkflowStats(ctx context.Context, workflowID, user
 	return stats, nil
 }
 
-func (s *WorkflowService) GetWorkflowExecutions(ctx context.Context, workflowID, userID string, page, limit int) ([]interface{}, int64, error) {
+func (s *WorkflowService) GetWorkflowExecutions(ctx context.Context, workflowID, userID string, page *database.CursorPage) ([]interface{}, int64, error) {
 	// Verify workflow exists
 	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
 		return nil, 0, ErrWorkflowNotFound
 	}
-	offset := (page - 1) * limit
-	executions, total, err := s.repo.ListWorkflowExecutions(ctx, workflowID, offset, limit)
+	executions, total, err := s.repo.ListWorkflowExecutions(ctx, workflowID, page)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -840,11 +1126,25 @@ func convertToN8NFormat(wf *workflow.Workflow) map[string]interface{} {
 
 func (s *WorkflowService) HandleExecutionCompleted(ctx context.Context, event events.Event) error {
 	s.logger.Info("Handling execution completed for workflow stats")
-	return nil
+	return s.handleCanaryExecution(ctx, event)
 }
 
 func (s *WorkflowService) HandleExecutionFailed(ctx context.Context, event events.Event) error {
 	s.logger.Info("Handling execution failed for workflow stats")
+	return s.handleCanaryExecution(ctx, event)
+}
+
+// handleCanaryExecution re-evaluates the canary of the workflow a finished
+// execution belongs to
+func (s *WorkflowService) handleCanaryExecution(ctx context.Context, event events.Event) error {
+	workflowID, _ := event.Payload["workflowId"].(string)
+	if workflowID == "" {
+		return nil
+	}
+
+	if err := s.evaluateCanary(ctx, workflowID); err != nil {
+		s.logger.Warn("Failed to evaluate workflow canary", "workflow_id", workflowID, "error", err)
+	}
 	return nil
 }
 
@@ -941,64 +1241,70 @@ func (s *WorkflowService) DeleteTrigger(ctx context.Context, triggerID, userID s
 		return err
 	}
 
+	// Fixtures go with their trigger
+	if err := s.repo.DeleteTriggerFixture(ctx, triggerID, ""); err != nil {
+		s.logger.Warn("Failed to delete trigger fixtures", "trigger_id", triggerID, "error", err)
+	}
+
 	s.logger.Info("Trigger deleted", "trigger_id", triggerID)
 	return nil
 }
 
-// ActivateTrigger activates a trigger
-func (s *WorkflowService) ActivateTrigger(ctx context.Context, triggerID, userID string) error {
+// ActivateTrigger activates a trigger and returns it with its new status
+func (s *WorkflowService) ActivateTrigger(ctx context.Context, triggerID, userID string) (*workflow.WorkflowTrigger, error) {
 	// Get trigger to check workflow
 	trigger, err := s.triggerManager.GetTrigger(ctx, triggerID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Verify user has permission
 	wf, err := s.repo.GetWorkflow(ctx, trigger.WorkflowID, userID)
 	if err != nil {
-		return ErrUnauthorized
+		return nil, ErrUnauthorized
 	}
 
 	// Check if workflow is active
 	if !wf.IsActive {
-		return ErrWorkflowInactive
+		return nil, ErrWorkflowInactive
 	}
 
 	// Activate trigger
 	if err := s.triggerManager.ActivateTrigger(ctx, triggerID); err != nil {
 		s.logger.Error("Failed to activate trigger", "trigger_id", triggerID, "error", err)
-		return err
+		return nil, err
 	}
 
 	s.logger.Info("Trigger activated", "trigger_id", triggerID)
-	return nil
+	return s.triggerManager.GetTrigger(ctx, triggerID)
 }
 
-// DeactivateTrigger deactivates a trigger
-func (s *WorkflowService) DeactivateTrigger(ctx context.Context, triggerID, userID string) error {
+// DeactivateTrigger deactivates a trigger and returns it with its new status
+func (s *WorkflowService) DeactivateTrigger(ctx context.Context, triggerID, userID string) (*workflow.WorkflowTrigger, error) {
 	// Get trigger to check workflow
 	trigger, err := s.triggerManager.GetTrigger(ctx, triggerID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Verify user has permission
 	if _, err := s.repo.GetWorkflow(ctx, trigger.WorkflowID, userID); err != nil {
-		return ErrUnauthorized
+		return nil, ErrUnauthorized
 	}
 
 	// Deactivate trigger
 	if err := s.triggerManager.DeactivateTrigger(ctx, triggerID); err != nil {
 		s.logger.Error("Failed to deactivate trigger", "trigger_id", triggerID, "error", err)
-		return err
+		return nil, err
 	}
 
 	s.logger.Info("Trigger deactivated", "trigger_id", triggerID)
-	return nil
+	return s.triggerManager.GetTrigger(ctx, triggerID)
 }
 
-// TestTrigger tests a trigger with sample data
-func (s *WorkflowService) TestTrigger(ctx context.Context, triggerID, userID string, testData map[string]interface{}) (map[string]interface{}, error) {
+// TestTrigger tests a trigger with sample data, or the payload of one of
+// its fixtures when fixture is set
+func (s *WorkflowService) TestTrigger(ctx context.Context, triggerID, userID, fixture string, testData map[string]interface{}) (map[string]interface{}, error) {
 	// Get trigger to check workflow
 	trigger, err := s.triggerManager.GetTrigger(ctx, triggerID)
 	if err != nil {
@@ -1010,6 +1316,12 @@ func (s *WorkflowService) TestTrigger(ctx context.Context, triggerID, userID str
 		return nil, ErrUnauthorized
 	}
 
+	if fixture != "" {
+		if testData, err = s.fixtureData(ctx, trigger.WorkflowID, triggerID, fixture); err != nil {
+			return nil, err
+		}
+	}
+
 	// Test trigger
 	result, err := s.triggerManager.TestTrigger(ctx, triggerID, testData)
 	if err != nil {
@@ -1094,6 +1406,19 @@ func (s *WorkflowService) CreateFromTemplate(ctx context.Context, templateID, us
 		return nil, err
 	}
 
+	// Inherit the workspace defaults like any new workflow
+	policy, err := s.GetWorkspacePolicy(ctx, wf.WorkspaceID())
+	if err != nil {
+		return nil, err
+	}
+	policy.Apply(&wf.Settings)
+	if err := policy.CheckLocked(wf, nil); err != nil {
+		return nil, err
+	}
+	if err := policy.CheckNodeTypes(wf); err != nil {
+		return nil, err
+	}
+
 	// Save workflow to database
 	if err := s.repo.CreateWorkflow(ctx, wf); err != nil {
 		s.logger.Error("Failed to save workflow from template", "error", err)
@@ -1135,6 +1460,23 @@ func (s *WorkflowService) SetWorkflowVariable(ctx context.Context, workflowID, u
 	variable.CreatedAt = time.Now().Format(time.RFC3339)
 	variable.UpdatedAt = time.Now().Format(time.RFC3339)
 
+	// Secrets are sealed at rest, only workers open them
+	variable.Encrypted = variable.Type == workflow.VarTypeSecret
+	if variable.Encrypted {
+		if s.sealer == nil {
+			return workflow.ErrSecretsUnavailable
+		}
+		plaintext, err := workflow.SecretPlaintext(variable.Value)
+		if err != nil {
+			return apperrors.InvalidRequest(err)
+		}
+		sealedValue, err := s.sealer.Seal(plaintext)
+		if err != nil {
+			return err
+		}
+		variable.Value = sealedValue
+	}
+
 	// Save to database
 	if err := s.repo.SaveWorkflowVariable(ctx, variable); err != nil {
 		s.logger.Error("Failed to save workflow variable", "error", err)
@@ -1142,7 +1484,7 @@ func (s *WorkflowService) SetWorkflowVariable(ctx context.Context, workflowID, u
 	}
 
 	// Update in-memory manager
-	s.variableManager.SetVariable(workflowID, variable)
+	s.variableManager.SetVariable(workflowID, variable.Masked())
 
 	s.logger.Info("Workflow variable set", "workflow_id", workflowID, "key", variable.Key)
 	return nil
@@ -1160,7 +1502,7 @@ func (s *WorkflowService) GetWorkflowVariable(ctx context.Context, workflowID, u
 		return nil, workflow.ErrVariableNotFound
 	}
 
-	return variable, nil
+	return variable.Masked(), nil
 }
 
 // ListWorkflowVariables lists all variables for a workflow
@@ -1170,7 +1512,14 @@ func (s *WorkflowService) ListWorkflowVariables(ctx context.Context, workflowID,
 		return nil, ErrWorkflowNotFound
 	}
 
-	return s.repo.ListWorkflowVariables(ctx, workflowID)
+	variables, err := s.repo.ListWorkflowVariables(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	for i, variable := range variables {
+		variables[i] = variable.Masked()
+	}
+	return variables, nil
 }
 
 // DeleteWorkflowVariable deletes a workflow variable
@@ -1196,6 +1545,51 @@ func (s *WorkflowService) DeleteWorkflowVariable(ctx context.Context, workflowID
 	return nil
 }
 
+// ResolveVariables shows the variables an execution of a workflow in an
+// environment sees, the default environment when none is named: the
+// effective value of each, the source it comes from and the values it
+// overrides. Secret values are masked.
+func (s *WorkflowService) ResolveVariables(ctx context.Context, workflowID, userID, environment string) ([]*workflow.ResolvedVariable, *workflow.Environment, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, nil, ErrWorkflowNotFound
+	}
+
+	var env *workflow.Environment
+	if environment != "" {
+		if env, err = s.resolveEnvironment(ctx, workflowID, environment); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		envs, err := s.repo.ListEnvironments(ctx, workflowID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, e := range envs {
+			if e.IsDefault {
+				env = e
+			}
+		}
+	}
+
+	shared, err := s.repo.ListSharedVariables(ctx, wf.TeamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	variables, err := s.repo.ListWorkflowVariables(ctx, workflowID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	layers := append(workflow.SharedVariableLayers(shared, wf.TeamID), workflow.WorkflowVariableLayer(workflowID, variables))
+	if env != nil {
+		layers = append(layers, env.VariableLayer(variables))
+	}
+	resolved := s.variableManager.Resolve(layers...)
+	workflow.MaskSecrets(resolved)
+	return resolved, env, nil
+}
+
 // CreateEnvironment creates an environment for a workflow
 func (s *WorkflowService) CreateEnvironment(ctx context.Context, workflowID, userID string, env *workflow.Environment) error {
 	// Verify workflow exists and user has permission
@@ -1208,6 +1602,10 @@ func (s *WorkflowService) CreateEnvironment(ctx context.Context, workflowID, use
 	env.CreatedAt = time.Now().Format(time.RFC3339)
 	env.UpdatedAt = time.Now().Format(time.RFC3339)
 
+	if err := s.checkPromotesFrom(ctx, workflowID, env.ID, env.PromotesFrom); err != nil {
+		return err
+	}
+
 	// If this is the first environment, make it default
 	count, err := s.repo.CountEnvironments(ctx, workflowID)
 	if err != nil {
@@ -1239,7 +1637,7 @@ func (s *WorkflowService) GetEnvironment(ctx context.Context, workflowID, userID
 
 	env, err := s.repo.GetEnvironment(ctx, workflowID, envID)
 	if err != nil {
-		return nil, err
+		return nil, workflow.ErrEnvironmentNotFound
 	}
 
 	return env, nil
@@ -1262,13 +1660,19 @@ func (s *WorkflowService) UpdateEnvironment(ctx context.Context, workflowID, use
 		return ErrWorkflowNotFound
 	}
 
+	if promotesFrom, ok := updates["promotes_from"].(string); ok {
+		if err := s.checkPromotesFrom(ctx, workflowID, envID, promotesFrom); err != nil {
+			return err
+		}
+	}
+
 	rows, err := s.repo.UpdateEnvironment(ctx, workflowID, envID, updates)
 	if err != nil {
 		return err
 	}
 
 	if rows == 0 {
-		return errors.New("environment not found")
+		return workflow.ErrEnvironmentNotFound
 	}
 
 	s.logger.Info("Environment updated", "id", envID, "workflow_id", workflowID)
@@ -1285,11 +1689,11 @@ func (s *WorkflowService) DeleteEnvironment(ctx context.Context, workflowID, use
 	// Check if it's the default environment
 	env, err := s.repo.GetEnvironment(ctx, workflowID, envID)
 	if err != nil {
-		return err
+		return workflow.ErrEnvironmentNotFound
 	}
 
 	if env.IsDefault {
-		return errors.New("cannot delete default environment")
+		return workflow.ErrDefaultEnvironment
 	}
 
 	// Delete environment
@@ -1313,7 +1717,7 @@ func (s *WorkflowService) SetDefaultEnvironment(ctx context.Context, workflowID,
 		return err
 	}
 	if rows == 0 {
-		return errors.New("environment not found")
+		return workflow.ErrEnvironmentNotFound
 	}
 
 	s.logger.Info("Default environment set", "id", envID, "workflow_id", workflowID)
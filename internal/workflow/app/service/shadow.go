@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// shadowReportRecent is how many diverging comparisons a report lists
+const shadowReportRecent = 20
+
+// StartShadow runs a stored version of a workflow next to every sampled
+// live execution so its outputs can be compared without it affecting
+// anything outside
+func (s *WorkflowService) StartShadow(ctx context.Context, workflowID, userID string, req *workflow.StartShadowRequest) (*workflow.Shadow, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	if _, err := s.repo.GetRunningShadow(ctx, workflowID); err == nil {
+		return nil, workflow.ErrShadowAlreadyActive
+	} else if !errors.Is(err, workflow.ErrShadowNotFound) {
+		return nil, err
+	}
+
+	if req.Version == wf.Version {
+		return nil, workflow.ErrInvalidShadow.WithMessage("version %d is the live version", req.Version)
+	}
+	if _, err := s.repo.GetVersion(ctx, workflowID, req.Version); err != nil {
+		return nil, workflow.ErrInvalidShadow.WithMessage("version %d not found", req.Version)
+	}
+
+	percentage := req.Percentage
+	if percentage == 0 {
+		percentage = 100
+	}
+
+	shadow := &workflow.Shadow{
+		WorkflowID:    workflowID,
+		ShadowVersion: req.Version,
+		Percentage:    percentage,
+		Status:        workflow.ShadowRunning,
+		CreatedBy:     userID,
+		StartedAt:     time.Now(),
+	}
+
+	if err := s.repo.CreateShadow(ctx, shadow); err != nil {
+		s.logger.Error("Failed to create shadow", "workflow_id", workflowID, "error", err)
+		return nil, err
+	}
+
+	s.publishShadowEvent(ctx, events.WorkflowShadowStarted, shadow)
+
+	s.logger.Info("Workflow shadow started",
+		"workflow_id", workflowID,
+		"shadow_version", shadow.ShadowVersion,
+		"percentage", shadow.Percentage,
+	)
+	return shadow, nil
+}
+
+// GetShadowReport summarizes how the outputs of the latest shadow of a
+// workflow diverged from the live executions
+func (s *WorkflowService) GetShadowReport(ctx context.Context, workflowID, userID string) (*workflow.ShadowReport, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	shadow, err := s.repo.GetLatestShadow(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	comparisons, err := s.repo.ListShadowComparisons(ctx, shadow.ID, workflow.ShadowReportSampleSize)
+	if err != nil {
+		s.logger.Error("Failed to list shadow comparisons", "shadow_id", shadow.ID, "error", err)
+		return nil, err
+	}
+
+	return workflow.BuildShadowReport(shadow, comparisons, shadowReportRecent), nil
+}
+
+// StopShadow stops shadowing live executions, keeping the comparisons
+func (s *WorkflowService) StopShadow(ctx context.Context, workflowID, userID string) (*workflow.Shadow, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	shadow, err := s.repo.GetRunningShadow(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	shadow.Stop()
+	if err := s.repo.UpdateShadow(ctx, shadow); err != nil {
+		return nil, err
+	}
+
+	s.publishShadowEvent(ctx, events.WorkflowShadowStopped, shadow)
+	s.logger.Info("Workflow shadow stopped", "workflow_id", workflowID, "shadow_id", shadow.ID)
+	return shadow, nil
+}
+
+func (s *WorkflowService) publishShadowEvent(ctx context.Context, eventType string, shadow *workflow.Shadow) {
+	event := events.Event{
+		Type: eventType,
+		Payload: map[string]interface{}{
+			"workflow_id":    shadow.WorkflowID,
+			"shadow_id":      shadow.ID,
+			"shadow_version": shadow.ShadowVersion,
+			"percentage":     shadow.Percentage,
+		},
+	}
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		s.logger.Warn("Failed to publish shadow event", "type", eventType, "error", err)
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/redis/go-redis/v9"
+)
+
+// GetSLA returns the SLA of a workflow
+func (s *WorkflowService) GetSLA(ctx context.Context, workflowID, userID string) (*workflow.SLA, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	return s.repo.GetSLA(ctx, workflowID)
+}
+
+// SetSLA creates or replaces the SLA of a workflow
+func (s *WorkflowService) SetSLA(ctx context.Context, workflowID, userID string, req workflow.SetSLARequest) (*workflow.SLA, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	sla, err := s.repo.GetSLA(ctx, workflowID)
+	switch {
+	case errors.Is(err, workflow.ErrSLANotFound):
+		sla, err = workflow.NewSLA(workflowID, req, userID)
+	case err == nil:
+		err = sla.Apply(req, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveSLA(ctx, sla); err != nil {
+		s.logger.Error("Failed to save SLA", "workflow_id", workflowID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("SLA set",
+		"workflow_id", workflowID,
+		"max_duration_ms", sla.MaxDurationMs,
+		"max_failure_rate", sla.MaxFailureRate,
+		"expected_interval_seconds", sla.ExpectedIntervalSeconds,
+		"updated_by", userID,
+	)
+	return sla, nil
+}
+
+// DeleteSLA removes the SLA of a workflow and its latest status
+func (s *WorkflowService) DeleteSLA(ctx context.Context, workflowID, userID string) error {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return ErrWorkflowNotFound
+	}
+	if err := s.repo.DeleteSLA(ctx, workflowID); err != nil {
+		return err
+	}
+	s.redis.Del(ctx, rediskey.Tenant(ctx, workflow.SLAStatusKey(workflowID)...))
+	return nil
+}
+
+// GetSLAStatus returns the latest evaluation of the SLA of a workflow by
+// the SLA monitor of the execution service. An SLA not evaluated yet is
+// reported with no objectives.
+func (s *WorkflowService) GetSLAStatus(ctx context.Context, workflowID, userID string) (*workflow.SLAStatus, error) {
+	sla, err := s.GetSLA(ctx, workflowID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.redis.Get(ctx, rediskey.Tenant(ctx, workflow.SLAStatusKey(workflowID)...)).Bytes()
+	if err == redis.Nil {
+		return &workflow.SLAStatus{SLA: sla, Objectives: []workflow.SLAObjectiveStatus{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var status workflow.SLAStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, err
+	}
+	// The SLA may have changed since it was evaluated
+	status.SLA = sla
+	return &status, nil
+}
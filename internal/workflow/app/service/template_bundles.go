@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+
+	"github.com/linkflow-go/internal/workflow/adapters/templates"
+)
+
+// ExportTemplateBundle exports a template as a signed bundle, returning it
+// with the name of the template
+func (s *WorkflowService) ExportTemplateBundle(ctx context.Context, templateID, userID, format string) ([]byte, string, error) {
+	template, err := s.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !template.Exportable(userID) {
+		return nil, "", templates.ErrNotExportable
+	}
+
+	data, err := s.templateManager.ExportBundle(ctx, template, userID, format)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, template.Name, nil
+}
+
+// ExportWorkflowBundle exports a workflow as the signed bundle of a
+// template, without variables, to import elsewhere as a template
+func (s *WorkflowService) ExportWorkflowBundle(ctx context.Context, workflowID, userID, format string) ([]byte, string, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, "", ErrWorkflowNotFound
+	}
+
+	wfJSON, err := wf.ToJSON()
+	if err != nil {
+		return nil, "", err
+	}
+	template := &templates.Template{
+		Name:        wf.Name,
+		Description: wf.Description,
+		Category:    templates.CategoryCustom,
+		Tags:        wf.Tags,
+		Workflow:    []byte(wfJSON),
+		CreatorID:   userID,
+		Version:     1,
+	}
+
+	data, err := s.templateManager.ExportBundle(ctx, template, userID, format)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, wf.Name, nil
+}
+
+// ImportTemplateBundle verifies a bundle exported by a trusted deployment
+// and creates its template as a private template of the user
+func (s *WorkflowService) ImportTemplateBundle(ctx context.Context, data []byte, userID, name string) (*templates.Imported, error) {
+	imported, err := s.templateManager.ImportBundle(ctx, data, userID, name)
+	if err != nil {
+		s.logger.Error("Failed to import template bundle", "user_id", userID, "error", err)
+		return nil, err
+	}
+	return imported, nil
+}
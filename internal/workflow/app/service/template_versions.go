@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/linkflow-go/internal/workflow/adapters/templates"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// ErrInvalidUpgrade is an upgrade whose workflow fails validation, the
+// workflow is left as it was
+var ErrInvalidUpgrade = apperrors.New(apperrors.CategoryValidation, "INVALID_TEMPLATE_UPGRADE", "upgraded workflow is invalid")
+
+// PublishTemplateVersion publishes a new version of a template created by
+// the user
+func (s *WorkflowService) PublishTemplateVersion(ctx context.Context, templateID, userID string, definition *workflow.Workflow, variables []templates.Variable, changelog string) (*templates.TemplateVersion, error) {
+	if len(definition.Nodes) == 0 {
+		return nil, templates.ErrInvalidVersion.WithMessage("workflow has no nodes")
+	}
+	if err := definition.Validate(); err != nil {
+		return nil, templates.ErrInvalidVersion.WithMessage("%v", err)
+	}
+
+	wfJSON, err := definition.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	return s.templateManager.PublishVersion(ctx, templateID, userID, json.RawMessage(wfJSON), variables, changelog)
+}
+
+// ListTemplateVersions lists the versions of a template, newest first
+func (s *WorkflowService) ListTemplateVersions(ctx context.Context, templateID string) ([]*templates.TemplateVersion, error) {
+	return s.templateManager.ListVersions(ctx, templateID)
+}
+
+// GetTemplateUpgrade returns whether the template a workflow was created
+// from has a newer version, and how upgrading to it changes the workflow
+func (s *WorkflowService) GetTemplateUpgrade(ctx context.Context, workflowID, userID string) (*templates.Upgrade, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	return s.templateManager.PlanUpgrade(ctx, wf, userID, nil)
+}
+
+// UpgradeFromTemplate upgrades a workflow to the latest version of its
+// template, keeping its variable values but for those given. The upgrade
+// is saved as a new version of the workflow only when it validates, and
+// rolled back with the version of the template recorded if saving fails.
+func (s *WorkflowService) UpgradeFromTemplate(ctx context.Context, workflowID, userID string, variables map[string]interface{}) (*workflow.Workflow, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+
+	upgrade, err := s.templateManager.PlanUpgrade(ctx, wf, userID, variables)
+	if err != nil {
+		return nil, err
+	}
+	if !upgrade.Available {
+		return nil, templates.ErrUpgradeUnavailable.WithMessage("workflow is on version %d of template %s, no newer version to upgrade to",
+			upgrade.FromVersion, upgrade.TemplateID)
+	}
+	if len(upgrade.MissingVariables) > 0 {
+		keys := make([]string, len(upgrade.MissingVariables))
+		for i, v := range upgrade.MissingVariables {
+			keys[i] = v.Key
+		}
+		return nil, fmt.Errorf("%w: %s", templates.ErrVariableRequired, strings.Join(keys, ", "))
+	}
+
+	upgraded := upgrade.Workflow
+	if len(upgraded.Nodes) > 0 {
+		if err := upgraded.Validate(); err != nil {
+			return nil, ErrInvalidUpgrade.WithMessage("version %d of template %s: %v", upgrade.ToVersion, upgrade.TemplateID, err)
+		}
+	}
+	if err := s.checkNodeTypes(ctx, wf.WorkspaceID(), upgraded); err != nil {
+		return nil, err
+	}
+
+	previousVersion := wf.Version
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		note := fmt.Sprintf("Upgraded to version %d of template %s", upgrade.ToVersion, upgrade.TemplateName)
+		if err := s.repo.UpdateWithVersion(ctx, upgraded, note); err != nil {
+			return err
+		}
+		if err := s.templateManager.RecordUpgrade(ctx, upgrade); err != nil {
+			return err
+		}
+		return s.eventBus.Publish(ctx, events.Event{
+			Type: "workflow.updated",
+			Payload: map[string]interface{}{
+				"workflow_id":      upgraded.ID,
+				"user_id":          upgraded.UserID,
+				"version":          upgraded.Version,
+				"previous_version": previousVersion,
+				"template_id":      upgrade.TemplateID,
+				"template_version": upgrade.ToVersion,
+			},
+		})
+	})
+	if err != nil {
+		s.logger.Error("Failed to upgrade workflow from template", "workflow_id", workflowID, "template_id", upgrade.TemplateID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Workflow upgraded from template",
+		"workflow_id", workflowID,
+		"template_id", upgrade.TemplateID,
+		"from_version", upgrade.FromVersion,
+		"to_version", upgrade.ToVersion)
+	return upgraded, nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/events"
+)
+
+// testRunsListed is how many test runs of a workflow are listed
+const testRunsListed = 20
+
+// ListTestCases returns the test cases of a workflow
+func (s *WorkflowService) ListTestCases(ctx context.Context, workflowID, userID string) ([]*workflow.WorkflowTestCase, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	return s.repo.ListTestCases(ctx, workflowID)
+}
+
+// GetTestCase returns a test case of a workflow
+func (s *WorkflowService) GetTestCase(ctx context.Context, workflowID, caseID, userID string) (*workflow.WorkflowTestCase, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	return s.repo.GetTestCase(ctx, workflowID, caseID)
+}
+
+// CreateTestCase adds a test case to a workflow
+func (s *WorkflowService) CreateTestCase(ctx context.Context, workflowID, userID string, req workflow.TestCaseRequest) (*workflow.WorkflowTestCase, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	if err := req.Validate(wf); err != nil {
+		return nil, err
+	}
+	if err := s.checkTestCaseName(ctx, workflowID, "", req.Name); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	testCase := &workflow.WorkflowTestCase{
+		WorkflowID:  workflowID,
+		Name:        req.Name,
+		Description: req.Description,
+		Input:       req.Input,
+		Mocks:       req.Mocks,
+		Strict:      req.Strict,
+		Assertions:  req.Assertions,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.CreateTestCase(ctx, testCase); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Workflow test case created", "workflow_id", workflowID, "test_case", req.Name)
+	return testCase, nil
+}
+
+// UpdateTestCase replaces a test case of a workflow
+func (s *WorkflowService) UpdateTestCase(ctx context.Context, workflowID, caseID, userID string, req workflow.TestCaseRequest) (*workflow.WorkflowTestCase, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	testCase, err := s.repo.GetTestCase(ctx, workflowID, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Validate(wf); err != nil {
+		return nil, err
+	}
+	if err := s.checkTestCaseName(ctx, workflowID, caseID, req.Name); err != nil {
+		return nil, err
+	}
+
+	testCase.Name = req.Name
+	testCase.Description = req.Description
+	testCase.Input = req.Input
+	testCase.Mocks = req.Mocks
+	testCase.Strict = req.Strict
+	testCase.Assertions = req.Assertions
+	testCase.UpdatedAt = time.Now()
+	if err := s.repo.UpdateTestCase(ctx, testCase); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Workflow test case updated", "workflow_id", workflowID, "test_case", req.Name)
+	return testCase, nil
+}
+
+// DeleteTestCase deletes a test case of a workflow
+func (s *WorkflowService) DeleteTestCase(ctx context.Context, workflowID, caseID, userID string) error {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return ErrWorkflowNotFound
+	}
+	return s.repo.DeleteTestCase(ctx, workflowID, caseID)
+}
+
+// checkTestCaseName checks no other test case of the workflow has a name
+func (s *WorkflowService) checkTestCaseName(ctx context.Context, workflowID, caseID, name string) error {
+	testCases, err := s.repo.ListTestCases(ctx, workflowID)
+	if err != nil {
+		return err
+	}
+	for _, testCase := range testCases {
+		if testCase.Name == name && testCase.ID != caseID {
+			return workflow.ErrTestCaseExists.WithMessage("workflow already has a test case named %s", name)
+		}
+	}
+	return nil
+}
+
+// RunWorkflowTests starts a run of every test case of a workflow on its
+// current version. The execution service simulates the cases; the run
+// reports their results once it is no longer running.
+func (s *WorkflowService) RunWorkflowTests(ctx context.Context, workflowID, userID string) (*workflow.WorkflowTestRun, error) {
+	wf, err := s.repo.GetWorkflow(ctx, workflowID, userID)
+	if err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	testCases, err := s.repo.ListTestCases(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	if len(testCases) == 0 {
+		return nil, workflow.ErrNoTestCases
+	}
+
+	run := &workflow.WorkflowTestRun{
+		WorkflowID:  workflowID,
+		Version:     wf.Version,
+		Status:      workflow.TestRunRunning,
+		Cases:       []workflow.TestCaseResult{},
+		RequestedBy: userID,
+		StartedAt:   time.Now(),
+	}
+	if err := s.repo.CreateTestRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	event := events.NewEventBuilder(events.WorkflowTestsRequested).
+		WithAggregateID(workflowID).
+		WithUserID(userID).
+		WithPayload("runId", run.ID).
+		WithPayload("workflowId", workflowID).
+		WithPayload("version", wf.Version).
+		Build()
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		s.logger.Error("Failed to publish workflow tests request", "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Workflow tests requested", "run_id", run.ID, "workflow_id", workflowID, "cases", len(testCases))
+	return run, nil
+}
+
+// ListTestRuns returns the latest test runs of a workflow, newest first
+func (s *WorkflowService) ListTestRuns(ctx context.Context, workflowID, userID string) ([]*workflow.WorkflowTestRun, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	return s.repo.ListTestRuns(ctx, workflowID, testRunsListed)
+}
+
+// GetTestRun returns a test run of a workflow
+func (s *WorkflowService) GetTestRun(ctx context.Context, workflowID, runID, userID string) (*workflow.WorkflowTestRun, error) {
+	if _, err := s.repo.GetWorkflow(ctx, workflowID, userID); err != nil {
+		return nil, ErrWorkflowNotFound
+	}
+	run, err := s.repo.GetTestRun(ctx, workflowID, runID)
+	if errors.Is(err, workflow.ErrTestRunNotFound) {
+		return nil, workflow.ErrTestRunNotFound.WithMessage("workflow has no test run %s", runID)
+	}
+	return run, err
+}
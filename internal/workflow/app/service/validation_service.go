@@ -7,6 +7,7 @@ import (
 
 	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/rediskey"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -14,6 +15,10 @@ import (
 type ValidationService struct {
 	redis  *redis.Client
 	logger logger.Logger
+	linter *workflow.Linter
+	// outputSchemas looks up the inferred output schemas of the nodes of a
+	// workflow, nil skips the output contract checks
+	outputSchemas func(ctx context.Context, workflowID string) ([]*workflow.NodeOutputSchema, error)
 }
 
 // NewValidationService creates a new validation service
@@ -21,9 +26,51 @@ func NewValidationService(redis *redis.Client, logger logger.Logger) *Validation
 	return &ValidationService{
 		redis:  redis,
 		logger: logger,
+		linter: workflow.NewDefaultLinter(workflow.LintOptions{}),
 	}
 }
 
+// SetLinter replaces the linter run by workflow validation, to add rules or
+// tune the built-in ones
+func (vs *ValidationService) SetLinter(linter *workflow.Linter) {
+	vs.linter = linter
+}
+
+// SetOutputSchemas wires the lookup of the inferred output schemas of nodes
+// the fields nodes read are checked against
+func (vs *ValidationService) SetOutputSchemas(lookup func(ctx context.Context, workflowID string) ([]*workflow.NodeOutputSchema, error)) {
+	vs.outputSchemas = lookup
+}
+
+// CheckOutputContracts warns of the fields nodes read that the inferred
+// output schemas of the nodes before them do not have
+func (vs *ValidationService) CheckOutputContracts(ctx context.Context, wf *workflow.Workflow) []string {
+	if vs.outputSchemas == nil || wf.ID == "" {
+		return nil
+	}
+	stored, err := vs.outputSchemas(ctx, wf.ID)
+	if err != nil {
+		vs.logger.Warn("Failed to load node output schemas", "workflow_id", wf.ID, "error", err)
+		return nil
+	}
+
+	schemas := make(map[string]*workflow.FieldSchema, len(stored))
+	for _, schema := range stored {
+		schemas[schema.NodeID] = schema.Schema
+	}
+	return workflow.CheckOutputContracts(wf, schemas)
+}
+
+// Lint runs the lint rules against a workflow
+func (vs *ValidationService) Lint(ctx context.Context, wf *workflow.Workflow) *workflow.LintReport {
+	report := vs.linter.Lint(wf)
+	vs.logger.Debug("Workflow linted",
+		"workflow_id", wf.ID,
+		"findings", len(report.Findings),
+		"suppressed", len(report.Suppressed))
+	return report
+}
+
 // ValidateWorkflow performs comprehensive workflow validation
 func (vs *ValidationService) ValidateWorkflow(ctx context.Context, wf *workflow.Workflow) ([]string, []string, error) {
 	startTime := time.Now()
@@ -34,7 +81,7 @@ func (vs *ValidationService) ValidateWorkflow(ctx context.Context, wf *workflow.
 	}()
 
 	// Check cache for recent validation results
-	cacheKey := fmt.Sprintf("validation:%s:v%d", wf.ID, wf.Version)
+	cacheKey := rediskey.Tenant(ctx, "validation", wf.ID, fmt.Sprintf("v%d", wf.Version))
 	if cached, err := vs.getValidationCache(ctx, cacheKey); err == nil && cached != nil {
 		vs.logger.Debug("Using cached validation result", "workflow_id", wf.ID)
 		return cached.Errors, cached.Warnings, nil
@@ -46,6 +93,15 @@ func (vs *ValidationService) ValidateWorkflow(ctx context.Context, wf *workflow.
 	// Perform validation
 	errors, warnings, err := validator.Validate()
 
+	// Lint findings of error severity fail validation like structural errors
+	report := vs.Lint(ctx, wf)
+	errors = append(errors, report.Errors()...)
+	warnings = append(warnings, report.Warnings()...)
+	warnings = append(warnings, vs.CheckOutputContracts(ctx, wf)...)
+	if err == nil && len(errors) > 0 {
+		err = fmt.Errorf("validation failed with %d errors", len(errors))
+	}
+
 	// Log validation results
 	if err != nil {
 		vs.logger.Error("Workflow validation failed",
@@ -97,6 +153,16 @@ func (vs *ValidationService) ValidateDAG(ctx context.Context, wf *workflow.Workf
 	return nil
 }
 
+// Diagnose locates the dangling connections, cycles, orphaned nodes and
+// ambiguous start nodes of a workflow
+func (vs *ValidationService) Diagnose(ctx context.Context, wf *workflow.Workflow) []workflow.Diagnostic {
+	diagnostics := workflow.NewDAG(wf).Diagnose()
+	vs.logger.Debug("Workflow graph diagnosed",
+		"workflow_id", wf.ID,
+		"diagnostics", len(diagnostics))
+	return diagnostics
+}
+
 // ValidateNode validates a single node configuration
 func (vs *ValidationService) ValidateNode(ctx context.Context, node *workflow.Node) []string {
 	errors := []string{}
@@ -115,6 +181,7 @@ func (vs *ValidationService) ValidateNode(ctx context.Context, node *workflow.No
 		workflow.NodeTypeCode:        true,
 		workflow.NodeTypeEmail:       true,
 		workflow.NodeTypeSlack:       true,
+		workflow.NodeTypeTransform:   true,
 	}
 
 	if !validTypes[node.Type] {
@@ -133,6 +200,8 @@ func (vs *ValidationService) ValidateNode(ctx context.Context, node *workflow.No
 		errors = append(errors, vs.validateSlackNode(node)...)
 	case workflow.NodeTypeCode:
 		errors = append(errors, vs.validateCodeNode(node)...)
+	case workflow.NodeTypeMerge:
+		errors = append(errors, vs.validateMergeNode(node)...)
 	}
 
 	return errors
@@ -287,6 +356,14 @@ func (vs *ValidationService) validateCodeNode(node *workflow.Node) []string {
 	return errors
 }
 
+// validateMergeNode validates merge node parameters
+func (vs *ValidationService) validateMergeNode(node *workflow.Node) []string {
+	if _, err := workflow.ParseMergeParameters(node.Parameters); err != nil {
+		return []string{fmt.Sprintf("Merge node %s: %v", node.ID, err)}
+	}
+	return nil
+}
+
 // ValidateConnection validates a connection between two nodes
 func (vs *ValidationService) ValidateConnection(source, target *workflow.Node, conn *workflow.Connection) error {
 	// Check if source can have outputs
@@ -348,6 +425,8 @@ type ValidationResult struct {
 	Errors   []string
 	Warnings []string
 	Valid    bool
+	// Diagnostics locate the problems of the graph
+	Diagnostics []workflow.Diagnostic
 }
 
 // getValidationCache retrieves cached validation results
@@ -364,7 +443,8 @@ func (vs *ValidationService) cacheValidationResult(ctx context.Context, key stri
 	vs.logger.Debug("Would cache validation result", "key", key, "valid", result.Valid)
 }
 
-// AnalyzeComplexity analyzes workflow complexity metrics
+// AnalyzeComplexity analyzes workflow complexity metrics and advises on
+// making the workflow faster
 func (vs *ValidationService) AnalyzeComplexity(ctx context.Context, wf *workflow.Workflow) map[string]interface{} {
 	dag := workflow.NewDAG(wf)
 
@@ -411,6 +491,12 @@ func (vs *ValidationService) AnalyzeComplexity(ctx context.Context, wf *workflow
 		"complexity_score":     calculateComplexityScore(len(wf.Nodes), len(wf.Connections), maxDepth),
 	}
 
+	// Estimate the run time and look for serial chains and per item calls
+	performance := dag.Advise()
+	metrics["estimated_duration_ms"] = performance.EstimatedDurationMs
+	metrics["weighted_critical_path"] = performance.CriticalPath
+	metrics["advice"] = performance.Advice
+
 	vs.logger.Info("Workflow complexity analysis",
 		"workflow_id", wf.ID,
 		"metrics", metrics)
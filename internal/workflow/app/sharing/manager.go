@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/linkflow-go/pkg/database"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/logger"
 	"gorm.io/gorm"
 )
@@ -29,11 +30,11 @@ const (
 )
 
 var (
-	ErrPermissionDenied    = errors.New("permission denied")
-	ErrShareNotFound       = errors.New("share not found")
-	ErrCannotShareWithSelf = errors.New("cannot share with yourself")
-	ErrInvalidPermission   = errors.New("invalid permission level")
-	ErrShareAlreadyExists  = errors.New("share already exists")
+	ErrPermissionDenied    = apperrors.New(apperrors.CategoryPermission, "PERMISSION_DENIED", "permission denied")
+	ErrShareNotFound       = apperrors.New(apperrors.CategoryNotFound, "SHARE_NOT_FOUND", "share not found")
+	ErrCannotShareWithSelf = apperrors.New(apperrors.CategoryValidation, "CANNOT_SHARE_WITH_SELF", "cannot share with yourself")
+	ErrInvalidPermission   = apperrors.New(apperrors.CategoryValidation, "INVALID_PERMISSION_LEVEL", "invalid permission level")
+	ErrShareAlreadyExists  = apperrors.New(apperrors.CategoryConflict, "SHARE_EXISTS", "share already exists")
 )
 
 // WorkflowShare represents a workflow sharing record
@@ -402,6 +403,11 @@ func isValidPermission(permission string) bool {
 	return validPermissions[permission]
 }
 
+// Allows reports whether a granted permission includes a required one
+func Allows(grantedPermission, requiredPermission string) bool {
+	return hasPermission(grantedPermission, requiredPermission)
+}
+
 func hasPermission(grantedPermission, requiredPermission string) bool {
 	// Permission hierarchy: admin > edit > execute > view
 	hierarchy := map[string]int{
@@ -2,11 +2,11 @@ package transfer
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"time"
 
 	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/logger"
 	"gopkg.in/yaml.v3"
 )
@@ -23,8 +23,8 @@ const (
 const ExportVersion = "1.0.0"
 
 var (
-	ErrInvalidFormat = errors.New("invalid export format")
-	ErrExportFailed  = errors.New("export failed")
+	ErrInvalidFormat = apperrors.New(apperrors.CategoryValidation, "INVALID_EXPORT_FORMAT", "invalid export format")
+	ErrExportFailed  = apperrors.New(apperrors.CategoryInternal, "EXPORT_FAILED", "export failed")
 )
 
 // WorkflowExport represents an exported workflow
@@ -34,6 +34,7 @@ type WorkflowExport struct {
 	Workflow    WorkflowData           `json:"workflow" yaml:"workflow"`
 	Nodes       []NodeExport           `json:"nodes" yaml:"nodes"`
 	Connections []ConnectionExport     `json:"connections" yaml:"connections"`
+	Annotations []AnnotationExport     `json:"annotations,omitempty" yaml:"annotations,omitempty"`
 	Variables   []VariableExport       `json:"variables,omitempty" yaml:"variables,omitempty"`
 	Triggers    []TriggerExport        `json:"triggers,omitempty" yaml:"triggers,omitempty"`
 	Credentials []CredentialReference  `json:"requiredCredentials,omitempty" yaml:"requiredCredentials,omitempty"`
@@ -52,14 +53,16 @@ type WorkflowData struct {
 
 // NodeExport represents an exported node
 type NodeExport struct {
-	ID         string                 `json:"id" yaml:"id"`
-	Name       string                 `json:"name" yaml:"name"`
-	Type       string                 `json:"type" yaml:"type"`
-	Position   map[string]float64     `json:"position" yaml:"position"`
-	Parameters map[string]interface{} `json:"parameters" yaml:"parameters"`
-	Disabled   bool                   `json:"disabled,omitempty" yaml:"disabled,omitempty"`
-	RetryCount int                    `json:"retryCount,omitempty" yaml:"retryCount,omitempty"`
-	Timeout    int                    `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	ID               string                 `json:"id" yaml:"id"`
+	Name             string                 `json:"name" yaml:"name"`
+	Type             string                 `json:"type" yaml:"type"`
+	Position         map[string]float64     `json:"position" yaml:"position"`
+	Parameters       map[string]interface{} `json:"parameters" yaml:"parameters"`
+	Disabled         bool                   `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	RetryCount       int                    `json:"retryCount,omitempty" yaml:"retryCount,omitempty"`
+	Timeout          int                    `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	ContinueOnFail   bool                   `json:"continueOnFail,omitempty" yaml:"continueOnFail,omitempty"`
+	CompensationNode string                 `json:"compensationNode,omitempty" yaml:"compensationNode,omitempty"`
 }
 
 // ConnectionExport represents an exported connection
@@ -72,6 +75,19 @@ type ConnectionExport struct {
 	Data       map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
 }
 
+// AnnotationExport represents an exported sticky note, node description or
+// group
+type AnnotationExport struct {
+	ID       string             `json:"id" yaml:"id"`
+	Type     string             `json:"type" yaml:"type"`
+	Text     string             `json:"text,omitempty" yaml:"text,omitempty"`
+	Color    string             `json:"color,omitempty" yaml:"color,omitempty"`
+	Position map[string]float64 `json:"position" yaml:"position"`
+	Size     map[string]float64 `json:"size,omitempty" yaml:"size,omitempty"`
+	NodeID   string             `json:"nodeId,omitempty" yaml:"nodeId,omitempty"`
+	NodeIDs  []string           `json:"nodeIds,omitempty" yaml:"nodeIds,omitempty"`
+}
+
 // VariableExport represents an exported variable
 type VariableExport struct {
 	Key          string      `json:"key" yaml:"key"`
@@ -158,10 +174,12 @@ func (e *Exporter) createExportStructure(wf *workflow.Workflow, options ExportOp
 				"x": node.Position.X,
 				"y": node.Position.Y,
 			},
-			Parameters: node.Parameters,
-			Disabled:   node.Disabled,
-			RetryCount: node.RetryCount,
-			Timeout:    node.Timeout,
+			Parameters:       node.Parameters,
+			Disabled:         node.Disabled,
+			RetryCount:       node.RetryCount,
+			Timeout:          node.Timeout,
+			ContinueOnFail:   node.ContinueOnFail,
+			CompensationNode: node.CompensationNode,
 		})
 
 		// Extract credential requirements
@@ -182,6 +200,29 @@ func (e *Exporter) createExportStructure(wf *workflow.Workflow, options ExportOp
 		})
 	}
 
+	// Export annotations
+	for _, annotation := range wf.Annotations {
+		exported := AnnotationExport{
+			ID:    annotation.ID,
+			Type:  annotation.Type,
+			Text:  annotation.Text,
+			Color: annotation.Color,
+			Position: map[string]float64{
+				"x": annotation.Position.X,
+				"y": annotation.Position.Y,
+			},
+			NodeID:  annotation.NodeID,
+			NodeIDs: annotation.NodeIDs,
+		}
+		if annotation.Size != nil {
+			exported.Size = map[string]float64{
+				"width":  annotation.Size.Width,
+				"height": annotation.Size.Height,
+			}
+		}
+		export.Annotations = append(export.Annotations, exported)
+	}
+
 	// Add metadata
 	if options.IncludeMetadata {
 		export.Metadata["exportedBy"] = options.ExportedBy
@@ -2,20 +2,20 @@ package transfer
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/linkflow-go/pkg/contracts/workflow"
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/linkflow-go/pkg/logger"
 	"gopkg.in/yaml.v3"
 )
 
 var (
-	ErrInvalidImportFormat = errors.New("invalid import format")
-	ErrVersionMismatch     = errors.New("incompatible export version")
-	ErrImportValidation    = errors.New("import validation failed")
+	ErrInvalidImportFormat = apperrors.New(apperrors.CategoryValidation, "INVALID_IMPORT_FORMAT", "invalid import format")
+	ErrVersionMismatch     = apperrors.New(apperrors.CategoryValidation, "IMPORT_VERSION_MISMATCH", "incompatible export version")
+	ErrImportValidation    = apperrors.New(apperrors.CategoryValidation, "IMPORT_VALIDATION_FAILED", "import validation failed")
 )
 
 // Importer handles workflow import operations
@@ -99,6 +99,17 @@ func (i *Importer) createWorkflowFromExport(export *WorkflowExport, options Impo
 		}
 	}
 
+	// Remap node timeout settings to the imported node IDs
+	if len(wf.Settings.NodeTimeouts) > 0 {
+		nodeTimeouts := make(map[string]int, len(wf.Settings.NodeTimeouts))
+		for nodeID, seconds := range wf.Settings.NodeTimeouts {
+			if newID, ok := nodeIDMap[nodeID]; ok {
+				nodeTimeouts[newID] = seconds
+			}
+		}
+		wf.Settings.NodeTimeouts = nodeTimeouts
+	}
+
 	// Import nodes
 	wf.Nodes = []workflow.Node{}
 	for _, exportNode := range export.Nodes {
@@ -110,10 +121,15 @@ func (i *Importer) createWorkflowFromExport(export *WorkflowExport, options Impo
 				X: exportNode.Position["x"],
 				Y: exportNode.Position["y"],
 			},
-			Parameters: exportNode.Parameters,
-			Disabled:   exportNode.Disabled,
-			RetryCount: exportNode.RetryCount,
-			Timeout:    exportNode.Timeout,
+			Parameters:     exportNode.Parameters,
+			Disabled:       exportNode.Disabled,
+			RetryCount:     exportNode.RetryCount,
+			Timeout:        exportNode.Timeout,
+			ContinueOnFail: exportNode.ContinueOnFail,
+		}
+
+		if exportNode.CompensationNode != "" {
+			node.CompensationNode = nodeIDMap[exportNode.CompensationNode]
 		}
 
 		// Handle credential mapping
@@ -143,6 +159,39 @@ func (i *Importer) createWorkflowFromExport(export *WorkflowExport, options Impo
 		wf.Connections = append(wf.Connections, conn)
 	}
 
+	// Import annotations, following the nodes they describe or group
+	wf.Annotations = []workflow.Annotation{}
+	for _, exportAnnotation := range export.Annotations {
+		annotation := workflow.Annotation{
+			ID:    exportAnnotation.ID,
+			Type:  exportAnnotation.Type,
+			Text:  exportAnnotation.Text,
+			Color: exportAnnotation.Color,
+			Position: workflow.Position{
+				X: exportAnnotation.Position["x"],
+				Y: exportAnnotation.Position["y"],
+			},
+		}
+		if exportAnnotation.Size != nil {
+			annotation.Size = &workflow.Size{
+				Width:  exportAnnotation.Size["width"],
+				Height: exportAnnotation.Size["height"],
+			}
+		}
+		if exportAnnotation.NodeID != "" {
+			annotation.NodeID = nodeIDMap[exportAnnotation.NodeID]
+		}
+		for _, nodeID := range exportAnnotation.NodeIDs {
+			annotation.NodeIDs = append(annotation.NodeIDs, nodeIDMap[nodeID])
+		}
+
+		if options.RemapIDs {
+			annotation.ID = uuid.New().String()
+		}
+
+		wf.Annotations = append(wf.Annotations, annotation)
+	}
+
 	// Validate imported workflow
 	if options.ValidateOnImport {
 		if err := wf.Validate(); err != nil {
@@ -330,6 +379,16 @@ func (i *Importer) mapToSettings(m map[string]interface{}) workflow.Settings {
 		}
 	}
 
+	// Handle per-node timeouts
+	if nodeTimeouts, ok := m["nodeTimeouts"].(map[string]interface{}); ok {
+		settings.NodeTimeouts = make(map[string]int, len(nodeTimeouts))
+		for nodeID, timeout := range nodeTimeouts {
+			if seconds, ok := timeout.(float64); ok {
+				settings.NodeTimeouts[nodeID] = int(seconds)
+			}
+		}
+	}
+
 	return settings
 }
 
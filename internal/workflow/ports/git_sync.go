@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// GitSync pushes workflow documents to the repository of a connection and
+// reads them back. token authenticates HTTPS remotes, empty for none.
+type GitSync interface {
+	Push(ctx context.Context, conn *workflow.GitConnection, token string, content []byte, message string) (string, error)
+	Read(ctx context.Context, conn *workflow.GitConnection, token string) ([]byte, string, error)
+	Remove(connectionID string) error
+}
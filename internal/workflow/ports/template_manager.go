@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/linkflow-go/internal/workflow/adapters/templates"
 	"github.com/linkflow-go/pkg/contracts/workflow"
@@ -13,4 +14,21 @@ type TemplateManager interface {
 	GetTemplate(ctx context.Context, templateID string) (*templates.Template, error)
 	InstantiateTemplate(ctx context.Context, templateID, userID, name string, variables map[string]interface{}) (*workflow.Workflow, error)
 	GetCategories() []map[string]interface{}
+
+	// Marketplace
+	SearchTemplates(ctx context.Context, q templates.SearchQuery) ([]*templates.Template, int64, error)
+	RateTemplate(ctx context.Context, templateID, userID string, rating int, comment string) (*templates.Review, error)
+	ListReviews(ctx context.Context, templateID string, limit, offset int) ([]*templates.Review, error)
+	ListModeration(ctx context.Context, status string) ([]*templates.Template, error)
+	ModerateTemplate(ctx context.Context, templateID, status, note string) (*templates.Template, error)
+
+	// Versions
+	PublishVersion(ctx context.Context, templateID, userID string, definition json.RawMessage, variables []templates.Variable, changelog string) (*templates.TemplateVersion, error)
+	ListVersions(ctx context.Context, templateID string) ([]*templates.TemplateVersion, error)
+	PlanUpgrade(ctx context.Context, wf *workflow.Workflow, userID string, variables map[string]interface{}) (*templates.Upgrade, error)
+	RecordUpgrade(ctx context.Context, upgrade *templates.Upgrade) error
+
+	// Bundles
+	ExportBundle(ctx context.Context, template *templates.Template, exportedBy, format string) ([]byte, error)
+	ImportBundle(ctx context.Context, data []byte, userID, name string) (*templates.Imported, error)
 }
@@ -2,8 +2,10 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/linkflow-go/pkg/contracts/workflow"
+	"github.com/linkflow-go/pkg/database"
 )
 
 type WorkflowRepository interface {
@@ -12,6 +14,7 @@ type WorkflowRepository interface {
 	CreateWorkflow(ctx context.Context, w *workflow.Workflow) error
 	CreateWithVersion(ctx context.Context, w *workflow.Workflow) error
 	GetWorkflow(ctx context.Context, workflowID, userID string) (*workflow.Workflow, error)
+	GetWorkflowByID(ctx context.Context, workflowID string) (*workflow.Workflow, error)
 	UpdateWorkflow(ctx context.Context, w *workflow.Workflow) error
 	UpdateWithVersion(ctx context.Context, w *workflow.Workflow, changeNote string) error
 	DeleteWorkflow(ctx context.Context, workflowID, userID string) error
@@ -22,6 +25,58 @@ type WorkflowRepository interface {
 	GetVersion(ctx context.Context, workflowID string, version int) (*workflow.WorkflowVersion, error)
 	RestoreVersion(ctx context.Context, workflowID string, version int, userID string) error
 
+	// Canaries
+	CreateCanary(ctx context.Context, canary *workflow.Canary) error
+	GetRunningCanary(ctx context.Context, workflowID string) (*workflow.Canary, error)
+	UpdateCanary(ctx context.Context, canary *workflow.Canary) error
+	GetVersionStats(ctx context.Context, workflowID string, version int, since time.Time) (*workflow.CanaryStats, error)
+
+	// Shadows
+	CreateShadow(ctx context.Context, shadow *workflow.Shadow) error
+	GetRunningShadow(ctx context.Context, workflowID string) (*workflow.Shadow, error)
+	GetLatestShadow(ctx context.Context, workflowID string) (*workflow.Shadow, error)
+	UpdateShadow(ctx context.Context, shadow *workflow.Shadow) error
+	ListShadowComparisons(ctx context.Context, shadowID string, limit int) ([]*workflow.ShadowComparison, error)
+
+	// Workspace policies
+	GetWorkspacePolicy(ctx context.Context, workspaceID string) (*workflow.WorkspacePolicy, error)
+	SaveWorkspacePolicy(ctx context.Context, policy *workflow.WorkspacePolicy) error
+	ListWorkspaceWorkflows(ctx context.Context, workspaceID string) ([]*workflow.Workflow, error)
+
+	// Budgets
+	GetBudget(ctx context.Context, scope, scopeID string) (*workflow.Budget, error)
+	SaveBudget(ctx context.Context, budget *workflow.Budget) error
+	DeleteBudget(ctx context.Context, scope, scopeID string) error
+
+	// SLAs
+	GetSLA(ctx context.Context, workflowID string) (*workflow.SLA, error)
+	SaveSLA(ctx context.Context, sla *workflow.SLA) error
+	DeleteSLA(ctx context.Context, workflowID string) error
+
+	// Change history
+	CreateWorkflowChange(ctx context.Context, change *workflow.WorkflowChange) error
+	ListWorkflowChanges(ctx context.Context, workflowID, userID string, page *database.CursorPage) ([]*workflow.WorkflowChange, error)
+
+	// Trigger fixtures
+	CreateTriggerFixture(ctx context.Context, fixture *workflow.TriggerFixture) error
+	ListTriggerFixtures(ctx context.Context, triggerID string) ([]*workflow.TriggerFixture, error)
+	GetTriggerFixture(ctx context.Context, triggerID, name string) (*workflow.TriggerFixture, error)
+	DeleteTriggerFixture(ctx context.Context, triggerID, name string) error
+
+	// Workflow tests
+	CreateTestCase(ctx context.Context, testCase *workflow.WorkflowTestCase) error
+	UpdateTestCase(ctx context.Context, testCase *workflow.WorkflowTestCase) error
+	ListTestCases(ctx context.Context, workflowID string) ([]*workflow.WorkflowTestCase, error)
+	GetTestCase(ctx context.Context, workflowID, caseID string) (*workflow.WorkflowTestCase, error)
+	DeleteTestCase(ctx context.Context, workflowID, caseID string) error
+	CreateTestRun(ctx context.Context, run *workflow.WorkflowTestRun) error
+	ListTestRuns(ctx context.Context, workflowID string, limit int) ([]*workflow.WorkflowTestRun, error)
+	GetTestRun(ctx context.Context, workflowID, runID string) (*workflow.WorkflowTestRun, error)
+
+	// Node output schemas
+	ListNodeOutputSchemas(ctx context.Context, workflowID string) ([]*workflow.NodeOutputSchema, error)
+	SaveNodeOutputSchema(ctx context.Context, schema *workflow.NodeOutputSchema) error
+
 	// Permissions
 	ListWorkflowPermissions(ctx context.Context, workflowID string) ([]map[string]interface{}, error)
 	CreateWorkflowPermission(ctx context.Context, permission map[string]interface{}) error
@@ -32,15 +87,18 @@ type WorkflowRepository interface {
 
 	// Stats & Executions
 	GetWorkflowStats(ctx context.Context, workflowID string) (WorkflowStats, error)
-	ListWorkflowExecutions(ctx context.Context, workflowID string, offset, limit int) ([]workflow.WorkflowExecution, int64, error)
+	ListWorkflowExecutions(ctx context.Context, workflowID string, page *database.CursorPage) ([]workflow.WorkflowExecution, int64, error)
 	GetLatestWorkflowExecution(ctx context.Context, workflowID string) (*workflow.WorkflowExecution, error)
+	ListExecutionChecksums(ctx context.Context, workflowID string) ([]workflow.WorkflowExecution, error)
 	GetPopularTags(ctx context.Context, limit int) ([]string, error)
+	CountActiveWorkflows(ctx context.Context) (int64, error)
 
 	// Variables
 	SaveWorkflowVariable(ctx context.Context, variable *workflow.WorkflowVariable) error
 	GetWorkflowVariable(ctx context.Context, workflowID, key string) (*workflow.WorkflowVariable, error)
 	ListWorkflowVariables(ctx context.Context, workflowID string) ([]*workflow.WorkflowVariable, error)
 	DeleteWorkflowVariable(ctx context.Context, workflowID, key string) (int64, error)
+	ListSharedVariables(ctx context.Context, teamID string) ([]workflow.SharedVariable, error)
 
 	// Environments
 	CountEnvironments(ctx context.Context, workflowID string) (int64, error)
@@ -50,6 +108,20 @@ type WorkflowRepository interface {
 	UpdateEnvironment(ctx context.Context, workflowID, envID string, updates map[string]interface{}) (int64, error)
 	DeleteEnvironment(ctx context.Context, env *workflow.Environment) error
 	SetDefaultEnvironment(ctx context.Context, workflowID, envID string) (int64, error)
+	GetEnvironmentByName(ctx context.Context, workflowID, name string) (*workflow.Environment, error)
+	DeployEnvironment(ctx context.Context, env *workflow.Environment, deployment *workflow.Deployment) error
+	ListDeployments(ctx context.Context, workflowID, envID string) ([]*workflow.Deployment, error)
+
+	// Git connections
+	GetGitConnection(ctx context.Context, workflowID string) (*workflow.GitConnection, error)
+	SaveGitConnection(ctx context.Context, conn *workflow.GitConnection) error
+	DeleteGitConnection(ctx context.Context, workflowID string) error
+
+	// Declarative applies
+	ListManagedWorkflows(ctx context.Context, workspaceID, namespace string) ([]*workflow.ManagedWorkflow, error)
+	SaveManagedWorkflow(ctx context.Context, managed *workflow.ManagedWorkflow) error
+	DeleteManagedWorkflow(ctx context.Context, id string) error
+	FindCredentialIDs(ctx context.Context, userID string, names []string) (map[string][]string, error)
 }
 
 type WorkflowStats struct {
@@ -71,4 +143,6 @@ type ListWorkflowsOptions struct {
 	Limit    int
 	SortBy   string
 	SortDesc bool
+	// Cursor pages by keyset instead of Page and Limit when set
+	Cursor *database.CursorPage
 }
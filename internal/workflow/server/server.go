@@ -2,34 +2,66 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/internal/workflow/adapters/admin"
 	"github.com/linkflow-go/internal/workflow/adapters/db/repository"
+	"github.com/linkflow-go/internal/workflow/adapters/gitsync"
 	"github.com/linkflow-go/internal/workflow/adapters/http/handlers"
+	workflowrpc "github.com/linkflow-go/internal/workflow/adapters/rpc"
 	"github.com/linkflow-go/internal/workflow/adapters/templates"
 	"github.com/linkflow-go/internal/workflow/adapters/triggers"
 	"github.com/linkflow-go/internal/workflow/app/service"
+	"github.com/linkflow-go/pkg/apidoc"
+	"github.com/linkflow-go/pkg/auth/sealed"
+	"github.com/linkflow-go/pkg/auth/signedurl"
 	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/contracts/workflow"
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	authmw "github.com/linkflow-go/pkg/middleware/auth"
+	"github.com/linkflow-go/pkg/middleware/correlation"
+	tenantmw "github.com/linkflow-go/pkg/middleware/tenant"
+	"github.com/linkflow-go/pkg/outbox"
+	"github.com/linkflow-go/pkg/quota"
+	"github.com/linkflow-go/pkg/redisgc"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/linkflow-go/pkg/rpc"
+	"github.com/linkflow-go/pkg/rpc/workflowv1"
+	"github.com/linkflow-go/pkg/telemetry"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
-	config     *config.Config
-	logger     logger.Logger
-	httpServer *http.Server
-	db         *database.DB
-	redis      *redis.Client
-	eventBus   events.EventBus
+	config         *config.Config
+	logger         logger.Logger
+	httpServer     *http.Server
+	adminServer    *admin.Server
+	rpcServer      *grpc.Server
+	db             *database.DB
+	redis          *redis.Client
+	eventBus       *outbox.Outbox
+	triggerManager *triggers.TriggerManager
+	redisGC        *redisgc.Collector
+	redisQuota     *rediskey.Quota
+	telemetry      *telemetry.Telemetry
 }
 
 func New(cfg *config.Config, log logger.Logger) (*Server, error) {
+	// Initialize tracing
+	tel := telemetry.Setup(cfg.Telemetry, "workflow-service", log)
+
 	// Initialize database
 	db, err := database.New(cfg.Database.ToDatabaseConfig())
 	if err != nil {
@@ -49,11 +81,19 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	// Initialize event bus
-	eventBus, err := events.NewKafkaEventBus(cfg.Kafka.ToKafkaConfig())
+	// Bound the Redis commands and memory of each tenant
+	redisLimits := cfg.Redis.TenantQuota
+	redisQuota := rediskey.NewQuota(redisClient, redisLimits.OpsPerSecond, redisLimits.MaxMemoryMB,
+		time.Duration(redisLimits.UsageInterval)*time.Second, log)
+	redisClient.AddHook(redisQuota)
+
+	// Initialize event bus, events are published through the outbox so they
+	// commit with the state change that raised them
+	bus, err := events.New(cfg.Kafka.ToKafkaConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event bus: %w", err)
 	}
+	eventBus := outbox.New(db, bus, "workflow-service", log)
 
 	// Initialize repository
 	workflowRepo := repository.NewWorkflowRepository(db)
@@ -62,14 +102,95 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 	triggerManager := triggers.NewTriggerManager(db, redisClient, eventBus, log)
 	templateManager := templates.NewTemplateManager(db, log)
 
+	// Template bundles are signed for import by other deployments
+	bundleKeys, err := templates.NewBundleKeys(cfg.Auth.TemplateBundles.SigningKey, cfg.Auth.TemplateBundles.TrustedKeys)
+	if err != nil {
+		log.Warn("Template bundles disabled", "error", err)
+	}
+	templateManager.SetBundleKeys(bundleKeys)
+
 	// Initialize service
-	workflowService := service.NewWorkflowService(workflowRepo, eventBus, redisClient, log, triggerManager, templateManager)
+	workflowService := service.NewWorkflowService(workflowRepo, db, eventBus, redisClient, log, triggerManager, templateManager)
+
+	// Plans bound the active workflows and scheduled executions of each tenant
+	enforcer := quota.New(redisClient, cfg.Quota, log)
+	workflowService.SetQuota(enforcer)
+	triggerManager.SetQuota(enforcer)
+
+	// Secret variables are sealed with the credential encryption key
+	sealer, err := sealed.NewSealer(cfg.Credential.EncryptionKey)
+	if err != nil {
+		log.Warn("Secret workflow variables disabled", "error", err)
+	}
+	workflowService.SetSealer(sealer)
+
+	// Workflows connected to Git push their versions there
+	gitClient, err := gitsync.NewClient(cfg.GitSync.WorkDir, time.Duration(cfg.GitSync.Timeout)*time.Second)
+	if err != nil {
+		log.Warn("Git sync disabled", "error", err)
+	} else {
+		workflowService.SetGitSync(gitClient)
+	}
+	triggerManager.SetDrainTimeout(time.Duration(cfg.Server.TriggerDrainTimeout) * time.Second)
+	workflowService.SetLintOptions(workflow.LintOptions{
+		MinScheduleInterval: time.Duration(cfg.Server.MinScheduleInterval) * time.Second,
+	})
 
 	// Initialize handlers
 	workflowHandlers := handlers.NewWorkflowHandlers(workflowService, log)
 
+	// Signed URLs let large exports be downloaded without proxying the API
+	signer, err := signedurl.NewSigner(cfg.Auth.SignedURL.SecretKey, cfg.Auth.SignedURL.BaseURL,
+		time.Duration(cfg.Auth.SignedURL.ExpiryMinutes)*time.Minute)
+	if err != nil {
+		log.Warn("Signed export URLs disabled", "error", err)
+	}
+	workflowHandlers.SetURLSigner(signer)
+
+	// Readiness reports the state of each dependency
+	checker := health.NewChecker("workflow-service")
+	checker.Critical("database", health.Database(db))
+	checker.Critical("redis", health.Redis(redisClient))
+	checker.Optional("event_bus", health.EventBus(eventBus))
+
 	// Setup HTTP server
-	router := setupRouter(workflowHandlers, log)
+	router := setupRouter(workflowHandlers, signer, tel, checker, log)
+
+	// Tenants read their usage, plans are assigned through the admin token
+	if quotaHandlers := quota.NewHandlers(enforcer); quotaHandlers != nil {
+		usage := router.Group(apidoc.Prefix + "/quota")
+		usage.Use(authMiddleware())
+		usage.Use(tenantmw.Middleware())
+		usage.GET("", quotaHandlers.Usage)
+
+		if cfg.Server.AdminToken != "" {
+			plans := router.Group("/admin/quota/tenants/:tenantId", adminAuth(cfg.Server.AdminToken))
+			plans.GET("", quotaHandlers.GetTenant)
+			plans.PUT("", quotaHandlers.SetTenantPlan)
+			plans.DELETE("", quotaHandlers.ResetTenantPlan)
+		}
+	}
+
+	// Workspace policies and template moderation are managed through the
+	// admin token
+	if cfg.Server.AdminToken != "" {
+		policies := router.Group("/admin/workspaces/:workspaceId", adminAuth(cfg.Server.AdminToken))
+		policies.GET("/policy", workflowHandlers.GetWorkspacePolicy)
+		policies.PUT("/policy", workflowHandlers.UpdateWorkspacePolicy)
+		policies.GET("/compliance", workflowHandlers.GetComplianceReport)
+
+		// Public templates enter the marketplace once approved
+		moderation := router.Group("/admin/templates", adminAuth(cfg.Server.AdminToken))
+		moderation.GET("/moderation", workflowHandlers.ListTemplateModeration)
+		moderation.PUT("/:id/moderation", workflowHandlers.ModerateTemplate)
+	} else {
+		log.Info("Workspace policy and template moderation admin APIs disabled, no admin token configured")
+	}
+
+	// Serve the OpenAPI document of the routes registered above
+	if err := apidoc.Serve(router, "workflow", cfg.Server.StrictAPISpec, log); err != nil {
+		return nil, err
+	}
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -78,37 +199,72 @@ func New(cfg *config.Config, log logger.Logger) (*Server, error) {
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 	}
 
+	// Internal gRPC admin API for trigger fleet operations, served only
+	// behind the admin token
+	var adminServer *admin.Server
+	if cfg.Server.AdminPort != 0 && cfg.Server.AdminToken != "" {
+		adminServer = admin.NewServer(triggerManager, cfg.Server.AdminToken, log)
+	}
+
+	// Workflow definitions for the other services over gRPC
+	var rpcServer *grpc.Server
+	if cfg.RPC.Port != 0 {
+		rpcServer = rpc.NewServer(cfg.RPC.Token, log)
+		workflowv1.RegisterWorkflowServiceServer(rpcServer, workflowrpc.NewServer(workflowService))
+	}
+
 	// Subscribe to events
 	if err := subscribeToEvents(eventBus, workflowService); err != nil {
 		return nil, fmt.Errorf("failed to subscribe to events: %w", err)
 	}
 
+	// Reclaim Redis keys whose owner is gone
+	redisGC := redisgc.New(redisClient, "workflow-service", triggerManager.GCRules(),
+		time.Duration(cfg.Redis.GCInterval)*time.Second, log)
+
 	return &Server{
-		config:     cfg,
-		logger:     log,
-		httpServer: httpServer,
-		db:         db,
-		redis:      redisClient,
-		eventBus:   eventBus,
+		config:         cfg,
+		logger:         log,
+		httpServer:     httpServer,
+		adminServer:    adminServer,
+		rpcServer:      rpcServer,
+		db:             db,
+		redis:          redisClient,
+		redisQuota:     redisQuota,
+		eventBus:       eventBus,
+		triggerManager: triggerManager,
+		redisGC:        redisGC,
+		telemetry:      tel,
 	}, nil
 }
 
-func setupRouter(h *handlers.WorkflowHandlers, log logger.Logger) *gin.Engine {
+func setupRouter(h *handlers.WorkflowHandlers, signer *signedurl.Signer, tel *telemetry.Telemetry, checker *health.Checker, log logger.Logger) *gin.Engine {
 	router := gin.New()
 
-	// Middleware
+	// Middleware, metrics first so recovered panics are counted as 500s
+	router.Use(metrics.HTTPMiddleware("workflow-service"))
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
+	router.Use(tel.HTTPMiddleware())
 	router.Use(corsMiddleware())
 	router.Use(loggingMiddleware(log))
 
 	// Health checks
 	router.GET("/health/live", h.Health)
-	router.GET("/health/ready", h.Ready)
+	router.GET("/health/ready", checker.ReadyHandler())
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	// Signed export downloads authenticate through the URL signature
+	exports := router.Group(apidoc.Prefix + "/workflows/exports")
+	exports.Use(authmw.SignedURLMiddleware(signer))
+	{
+		exports.GET("/:id", h.DownloadExport)
+	}
+
 	// API routes
-	v1 := router.Group("/api/v1/workflows")
+	v1 := router.Group(apidoc.Prefix + "/workflows")
 	v1.Use(authMiddleware()) // Add authentication middleware
+	v1.Use(tenantmw.Middleware())
 	{
 		// Workflow CRUD
 		v1.GET("", h.ListWorkflows)
@@ -117,19 +273,52 @@ func setupRouter(h *handlers.WorkflowHandlers, log logger.Logger) *gin.Engine {
 		v1.PUT("/:id", h.UpdateWorkflow)
 		v1.DELETE("/:id", h.DeleteWorkflow)
 
+		// Edit sessions of users editing a workflow together
+		v1.GET("/:id/editors", h.ListEditors)
+		v1.PUT("/:id/editors", h.JoinEditSession)
+		v1.DELETE("/:id/editors", h.LeaveEditSession)
+
 		// Workflow versions
 		v1.GET("/:id/versions", h.GetWorkflowVersions)
 		v1.GET("/:id/versions/:version", h.GetWorkflowVersion)
 		v1.POST("/:id/versions", h.CreateWorkflowVersion)
 		v1.POST("/:id/rollback/:version", h.RollbackWorkflowVersion)
+		v1.GET("/:id/changes", h.ListWorkflowChanges)
+		v1.GET("/:id/integrity", h.CheckIntegrity)
+
+		// Canary rollouts
+		v1.POST("/:id/canary", h.StartCanary)
+		v1.GET("/:id/canary", h.GetCanary)
+		v1.POST("/:id/canary/promote", h.PromoteCanary)
+		v1.POST("/:id/canary/rollback", h.RollbackCanary)
+		v1.PUT("/:id/canary/traffic", h.SetCanaryTraffic)
+		v1.DELETE("/:id/canary", h.CancelCanary)
+
+		// Shadow runs
+		v1.POST("/:id/shadow", h.StartShadow)
+		v1.GET("/:id/shadow", h.GetShadowReport)
+		v1.DELETE("/:id/shadow", h.StopShadow)
 
 		// Workflow operations
 		v1.POST("/:id/activate", h.ActivateWorkflow)
 		v1.POST("/:id/deactivate", h.DeactivateWorkflow)
 		v1.POST("/:id/duplicate", h.DuplicateWorkflow)
 		v1.POST("/:id/validate", h.ValidateWorkflow)
+		v1.GET("/:id/lint", h.LintWorkflow)
+		v1.POST("/:id/nodes/:nodeId/transform/preview", h.PreviewTransform)
 		v1.POST("/:id/execute", h.ExecuteWorkflow)
 		v1.POST("/:id/test", h.TestWorkflow)
+		v1.GET("/:id/schemas", h.ListOutputSchemas)
+
+		// Workflow tests
+		v1.GET("/:id/tests", h.ListTestCases)
+		v1.POST("/:id/tests", h.CreateTestCase)
+		v1.POST("/:id/tests/run", h.RunWorkflowTests)
+		v1.GET("/:id/tests/:caseId", h.GetTestCase)
+		v1.PUT("/:id/tests/:caseId", h.UpdateTestCase)
+		v1.DELETE("/:id/tests/:caseId", h.DeleteTestCase)
+		v1.GET("/:id/test-runs", h.ListTestRuns)
+		v1.GET("/:id/test-runs/:runId", h.GetTestRun)
 
 		// Workflow sharing
 		v1.GET("/:id/permissions", h.GetWorkflowPermissions)
@@ -137,15 +326,44 @@ func setupRouter(h *handlers.WorkflowHandlers, log logger.Logger) *gin.Engine {
 		v1.DELETE("/:id/share/:userId", h.UnshareWorkflow)
 		v1.POST("/:id/publish", h.PublishWorkflow)
 
+		// Budgets
+		v1.GET("/:id/budget", h.GetBudget)
+		v1.PUT("/:id/budget", h.SetBudget)
+		v1.DELETE("/:id/budget", h.DeleteBudget)
+		v1.GET("/budget", h.GetWorkspaceBudget)
+		v1.PUT("/budget", h.SetWorkspaceBudget)
+		v1.DELETE("/budget", h.DeleteWorkspaceBudget)
+
+		// SLAs
+		v1.GET("/:id/sla", h.GetSLA)
+		v1.PUT("/:id/sla", h.SetSLA)
+		v1.DELETE("/:id/sla", h.DeleteSLA)
+		v1.GET("/:id/sla/status", h.GetSLAStatus)
+
+		// Workspace policy
+		v1.GET("/policy", h.GetPolicy)
+
 		// Workflow templates
 		v1.GET("/templates", h.ListTemplates)
+		v1.GET("/templates/search", h.SearchTemplates)
 		v1.GET("/templates/:id", h.GetTemplate)
 		v1.POST("/templates", h.CreateTemplate)
+		v1.GET("/templates/:id/reviews", h.ListTemplateReviews)
+		v1.POST("/templates/:id/reviews", h.RateTemplate)
+		v1.GET("/templates/:id/versions", h.ListTemplateVersions)
+		v1.POST("/templates/:id/versions", h.PublishTemplateVersion)
+		v1.GET("/templates/:id/bundle", h.ExportTemplateBundle)
+		v1.POST("/templates/import", h.ImportTemplateBundle)
 		v1.POST("/from-template/:templateId", h.CreateFromTemplate)
+		v1.GET("/:id/template/upgrade", h.GetTemplateUpgrade)
+		v1.POST("/:id/template/upgrade", h.UpgradeFromTemplate)
+		v1.GET("/:id/template/bundle", h.ExportWorkflowBundle)
 
 		// Workflow import/export
 		v1.POST("/import", h.ImportWorkflow)
+		v1.POST("/apply", h.Apply)
 		v1.GET("/:id/export", h.ExportWorkflow)
+		v1.GET("/:id/export/url", h.CreateExportURL)
 
 		// Workflow statistics
 		v1.GET("/:id/stats", h.GetWorkflowStats)
@@ -169,6 +387,36 @@ func setupRouter(h *handlers.WorkflowHandlers, log logger.Logger) *gin.Engine {
 		v1.POST("/:id/triggers/:triggerId/activate", h.ActivateTrigger)
 		v1.POST("/:id/triggers/:triggerId/deactivate", h.DeactivateTrigger)
 		v1.POST("/:id/triggers/:triggerId/test", h.TestTrigger)
+		v1.GET("/:id/triggers/:triggerId/fixtures", h.ListTriggerFixtures)
+		v1.POST("/:id/triggers/:triggerId/fixtures", h.CreateTriggerFixture)
+		v1.POST("/:id/triggers/:triggerId/fixtures/capture", h.CaptureTriggerFixture)
+		v1.POST("/:id/triggers/:triggerId/fixtures/replay", h.ReplayTriggerFixtures)
+		v1.GET("/:id/triggers/:triggerId/fixtures/:name", h.GetTriggerFixture)
+		v1.DELETE("/:id/triggers/:triggerId/fixtures/:name", h.DeleteTriggerFixture)
+
+		// Variables and environments
+		v1.GET("/:id/variables", h.ListVariables)
+		v1.GET("/:id/effective-variables", h.EffectiveVariables)
+		v1.GET("/:id/variables/:key", h.GetVariable)
+		v1.PUT("/:id/variables/:key", h.SetVariable)
+		v1.DELETE("/:id/variables/:key", h.DeleteVariable)
+		v1.GET("/:id/environments", h.ListEnvironments)
+		v1.POST("/:id/environments", h.CreateEnvironment)
+		v1.GET("/:id/environments/:envId", h.GetEnvironment)
+		v1.PUT("/:id/environments/:envId", h.UpdateEnvironment)
+		v1.DELETE("/:id/environments/:envId", h.DeleteEnvironment)
+		v1.POST("/:id/environments/:envId/default", h.SetDefaultEnvironment)
+		v1.POST("/:id/environments/:envId/deploy", h.DeployEnvironment)
+		v1.POST("/:id/environments/:envId/promote", h.PromoteEnvironment)
+		v1.GET("/:id/environments/:envId/deployments", h.ListDeployments)
+
+		// Git sync
+		v1.GET("/:id/git", h.GetGitConnection)
+		v1.PUT("/:id/git", h.ConnectGit)
+		v1.DELETE("/:id/git", h.DisconnectGit)
+		v1.POST("/:id/git/push", h.PushToGit)
+		v1.GET("/:id/git/pull", h.PreviewGitPull)
+		v1.POST("/:id/git/pull", h.ApplyGitPull)
 	}
 
 	return router
@@ -184,6 +432,27 @@ func subscribeToEvents(eventBus events.EventBus, service *service.WorkflowServic
 		return err
 	}
 
+	// Deactivate the workflows of budgets reaching their hard cap
+	if err := eventBus.Subscribe(events.BudgetThreshold, service.HandleBudgetThreshold); err != nil {
+		return err
+	}
+
+	// Push new versions of the workflows connected to Git
+	if err := eventBus.Subscribe("workflow.updated", service.HandleWorkflowVersioned); err != nil {
+		return err
+	}
+	if err := eventBus.Subscribe("workflow.version.rollback", service.HandleWorkflowVersioned); err != nil {
+		return err
+	}
+
+	// Capture the payloads of triggers as fixtures
+	if err := eventBus.Subscribe("trigger.fired", service.HandleTriggerPayload); err != nil {
+		return err
+	}
+	if err := eventBus.Subscribe("webhook.received", service.HandleTriggerPayload); err != nil {
+		return err
+	}
+
 	// Subscribe to node events for workflow validation
 	if err := eventBus.Subscribe("node.updated", service.HandleNodeUpdated); err != nil {
 		return err
@@ -192,7 +461,52 @@ func subscribeToEvents(eventBus events.EventBus, service *service.WorkflowServic
 	return nil
 }
 
+// adminAuth requires the admin token as a bearer token
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if provided == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing admin token"})
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
+
 func (s *Server) Start() error {
+	// Relay events stored in the outbox
+	s.eventBus.Start()
+
+	// Reclaim the Redis keys of deactivated triggers
+	s.redisGC.Start()
+
+	// Measure the Redis memory of the tenants
+	s.redisQuota.Start()
+
+	if s.adminServer != nil {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.config.Server.AdminPort))
+		if err != nil {
+			return fmt.Errorf("failed to listen on admin port: %w", err)
+		}
+
+		s.logger.Info("Starting trigger admin gRPC server", "port", s.config.Server.AdminPort)
+		go func() {
+			if err := s.adminServer.Serve(lis); err != nil {
+				s.logger.Error("Trigger admin gRPC server stopped", "error", err)
+			}
+		}()
+	}
+
+	if s.rpcServer != nil {
+		if err := rpc.Serve(s.rpcServer, s.config.RPC.Port, s.logger); err != nil {
+			return err
+		}
+	}
+
 	s.logger.Info("Starting HTTP server", "port", s.config.Server.Port)
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start HTTP server: %w", err)
@@ -208,7 +522,25 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("failed to shutdown HTTP server: %w", err)
 	}
 
-	// Close event bus
+	// Stop admin API
+	if s.adminServer != nil {
+		s.adminServer.Stop(ctx)
+	}
+
+	// Stop gRPC API
+	if s.rpcServer != nil {
+		rpc.Stop(ctx, s.rpcServer)
+	}
+
+	// Drain trigger fires before the event bus they publish on closes
+	if err := s.triggerManager.Stop(ctx); err != nil {
+		s.logger.Error("Failed to drain trigger manager", "error", err)
+	}
+
+	s.redisGC.Stop()
+	s.redisQuota.Stop()
+
+	// Stop the outbox relay and close event bus
 	if err := s.eventBus.Close(); err != nil {
 		s.logger.Error("Failed to close event bus", "error", err)
 	}
@@ -223,6 +555,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.logger.Error("Failed to close database", "error", err)
 	}
 
+	// Flush pending spans
+	if err := s.telemetry.Close(); err != nil {
+		s.logger.Error("Failed to close telemetry", "error", err)
+	}
+
 	return nil
 }
 
@@ -231,7 +568,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-User-ID")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-User-ID, X-Workspace-ID, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -273,8 +610,24 @@ func authMiddleware() gin.HandlerFunc {
 			}
 		}
 
-		// Set user ID in context
+		// Set user ID in context, the workspace is the team of the request
+		// or the user
 		c.Set("user_id", userID)
+		workspaceID := c.GetHeader("X-Workspace-ID")
+		if workspaceID == "" {
+			workspaceID = userID
+		}
+		c.Set("workspace_id", workspaceID)
+
+		// Platform roles of the caller, forwarded from the claims of its
+		// token alongside the user ID
+		var roles []string
+		for _, role := range strings.Split(c.GetHeader("X-User-Roles"), ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
+		c.Set("roles", roles)
 		c.Next()
 	}
 }
@@ -314,7 +667,7 @@ func loggingMiddleware(log logger.Logger) gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 
-		log.Info("HTTP Request",
+		logger.FromContext(c.Request.Context(), log).Info("HTTP Request",
 			"method", method,
 			"path", path,
 			"status", statusCode,
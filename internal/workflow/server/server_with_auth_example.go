@@ -11,13 +11,15 @@ import (
 	"github.com/linkflow-go/internal/workflow/adapters/http/handlers"
 	"github.com/linkflow-go/pkg/auth/jwt"
 	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/health"
 	"github.com/linkflow-go/pkg/logger"
 	"github.com/linkflow-go/pkg/middleware/auth"
+	"github.com/linkflow-go/pkg/middleware/correlation"
 	"github.com/redis/go-redis/v9"
 )
 
 // setupRouterWithAuth shows how to integrate auth middleware
-func setupRouterWithAuth(h *handlers.WorkflowHandlers, cfg *config.Config, redisClient *redis.Client, log logger.Logger) (*gin.Engine, error) {
+func setupRouterWithAuth(h *handlers.WorkflowHandlers, cfg *config.Config, redisClient *redis.Client, checker *health.Checker, log logger.Logger) (*gin.Engine, error) {
 	router := gin.New()
 
 	// Initialize JWT manager for this service
@@ -38,6 +40,7 @@ func setupRouterWithAuth(h *handlers.WorkflowHandlers, cfg *config.Config, redis
 
 	// Global middleware
 	router.Use(gin.Recovery())
+	router.Use(correlation.Middleware())
 	router.Use(corsMiddleware())
 	router.Use(loggingMiddleware(log))
 
@@ -49,7 +52,7 @@ func setupRouterWithAuth(h *handlers.WorkflowHandlers, cfg *config.Config, redis
 
 	// Health checks (public, no auth required)
 	router.GET("/health/live", h.Health)
-	router.GET("/health/ready", h.Ready)
+	router.GET("/health/ready", checker.ReadyHandler())
 
 	// API routes
 	v1 := router.Group("/api/v1")
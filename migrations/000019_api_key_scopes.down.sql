@@ -0,0 +1,16 @@
+-- ============================================================================
+-- Migration: 000019_api_key_scopes (ROLLBACK)
+-- Description: Drop workspace, plan, scopes and quota hints from API keys
+-- ============================================================================
+
+BEGIN;
+
+DROP INDEX IF EXISTS auth.idx_api_keys_workspace_id;
+
+ALTER TABLE auth.api_keys
+    DROP COLUMN IF EXISTS quota,
+    DROP COLUMN IF EXISTS scopes,
+    DROP COLUMN IF EXISTS plan,
+    DROP COLUMN IF EXISTS workspace_id;
+
+COMMIT;
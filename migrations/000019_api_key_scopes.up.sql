@@ -0,0 +1,16 @@
+-- ============================================================================
+-- Migration: 000019_api_key_scopes
+-- Description: Store workspace, plan, scopes and quota hints on API keys
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE auth.api_keys
+    ADD COLUMN IF NOT EXISTS workspace_id VARCHAR(36),
+    ADD COLUMN IF NOT EXISTS plan         VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS scopes       TEXT,
+    ADD COLUMN IF NOT EXISTS quota        JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_workspace_id ON auth.api_keys(workspace_id);
+
+COMMIT;
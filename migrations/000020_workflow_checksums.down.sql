@@ -0,0 +1,19 @@
+-- ============================================================================
+-- Migration: 000020_workflow_checksums (ROLLBACK)
+-- Description: Drop definition checksums from workflows, versions and executions
+-- ============================================================================
+
+BEGIN;
+
+DROP INDEX IF EXISTS execution.idx_workflow_executions_checksum;
+
+ALTER TABLE execution.workflow_executions
+    DROP COLUMN IF EXISTS workflow_checksum;
+
+ALTER TABLE workflow.workflow_versions
+    DROP COLUMN IF EXISTS checksum;
+
+ALTER TABLE workflow.workflows
+    DROP COLUMN IF EXISTS checksum;
+
+COMMIT;
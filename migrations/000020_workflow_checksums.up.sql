@@ -0,0 +1,21 @@
+-- ============================================================================
+-- Migration: 000020_workflow_checksums
+-- Description: Store definition checksums on workflows, versions and executions
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE workflow.workflows
+    ADD COLUMN IF NOT EXISTS checksum VARCHAR(71);
+
+ALTER TABLE workflow.workflow_versions
+    ADD COLUMN IF NOT EXISTS checksum VARCHAR(71);
+
+ALTER TABLE execution.workflow_executions
+    ADD COLUMN IF NOT EXISTS workflow_checksum VARCHAR(71);
+
+CREATE INDEX IF NOT EXISTS idx_workflow_executions_checksum
+    ON execution.workflow_executions(workflow_id, workflow_version)
+    WHERE workflow_checksum IS NOT NULL;
+
+COMMIT;
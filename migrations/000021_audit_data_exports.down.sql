@@ -0,0 +1,12 @@
+-- ============================================================================
+-- Migration: 000021_audit_data_exports (ROLLBACK)
+-- Description: Drop the data exports audit table
+-- ============================================================================
+
+BEGIN;
+
+DELETE FROM audit.retention_policies WHERE table_name = 'audit.data_exports';
+
+DROP TABLE IF EXISTS audit.data_exports;
+
+COMMIT;
@@ -0,0 +1,38 @@
+-- ============================================================================
+-- Migration: 000021_audit_data_exports
+-- Description: Record every execution data export for compliance reporting
+-- Schema: audit
+-- ============================================================================
+
+BEGIN;
+
+-- ---------------------------------------------------------------------------
+-- Data Exports table - One row per watermarked export
+-- ---------------------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS audit.data_exports (
+    id              UUID PRIMARY KEY,
+
+    -- Actor (a user ID, or system:<component> for automated exports)
+    exported_by     VARCHAR(255) NOT NULL,
+    exported_at     TIMESTAMP NOT NULL,
+
+    -- What was exported and where it went
+    format          VARCHAR(20) NOT NULL,
+    destination     VARCHAR(50) NOT NULL,
+    scope           JSONB DEFAULT '{}',
+    record_count    INTEGER NOT NULL DEFAULT 0,
+    checksum        VARCHAR(71),
+    storage_key     TEXT,
+
+    event_id        VARCHAR(100),
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_exports_exported_at ON audit.data_exports(exported_at DESC);
+CREATE INDEX IF NOT EXISTS idx_data_exports_exported_by ON audit.data_exports(exported_by, exported_at DESC);
+
+INSERT INTO audit.retention_policies (table_name, retention_days) VALUES
+    ('audit.data_exports', 2555)
+ON CONFLICT (table_name) DO NOTHING;
+
+COMMIT;
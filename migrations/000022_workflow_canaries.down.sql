@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000022_workflow_canaries (ROLLBACK)
+-- Description: Drop canary rollouts
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS workflow.workflow_canaries;
+
+COMMIT;
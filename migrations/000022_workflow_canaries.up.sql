@@ -0,0 +1,36 @@
+-- ============================================================================
+-- Migration: 000022_workflow_canaries
+-- Description: Canary rollouts routing a share of executions to a new version
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS workflow.workflow_canaries (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workflow_id     UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+
+    -- Versions compared, percentage of executions routed to the canary
+    canary_version  INTEGER NOT NULL,
+    stable_version  INTEGER NOT NULL,
+    percentage      INTEGER NOT NULL CHECK (percentage BETWEEN 1 AND 99),
+    thresholds      JSONB DEFAULT '{}',
+
+    -- Outcome
+    status          VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'promoted', 'rolled_back', 'cancelled')),
+    reason          TEXT,
+
+    created_by      UUID,
+    started_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    decided_at      TIMESTAMP
+);
+
+-- At most one running canary per workflow
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workflow_canaries_running
+    ON workflow.workflow_canaries(workflow_id)
+    WHERE status = 'running';
+
+CREATE INDEX IF NOT EXISTS idx_workflow_canaries_workflow
+    ON workflow.workflow_canaries(workflow_id, started_at DESC);
+
+COMMIT;
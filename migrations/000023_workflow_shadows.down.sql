@@ -0,0 +1,11 @@
+-- ============================================================================
+-- Migration: 000023_workflow_shadows (ROLLBACK)
+-- Description: Drop shadow runs and their comparisons
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS workflow.workflow_shadow_comparisons;
+DROP TABLE IF EXISTS workflow.workflow_shadows;
+
+COMMIT;
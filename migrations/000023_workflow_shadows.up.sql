@@ -0,0 +1,62 @@
+-- ============================================================================
+-- Migration: 000023_workflow_shadows
+-- Description: Shadow runs of workflow versions and their output comparisons
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+-- ---------------------------------------------------------------------------
+-- Shadows table - A stored version run next to the live one
+-- ---------------------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS workflow.workflow_shadows (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workflow_id     UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+
+    shadow_version  INTEGER NOT NULL,
+    percentage      INTEGER NOT NULL DEFAULT 100 CHECK (percentage BETWEEN 1 AND 100),
+    status          VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'stopped')),
+
+    created_by      UUID,
+    started_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    stopped_at      TIMESTAMP
+);
+
+-- At most one running shadow per workflow
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workflow_shadows_running
+    ON workflow.workflow_shadows(workflow_id)
+    WHERE status = 'running';
+
+CREATE INDEX IF NOT EXISTS idx_workflow_shadows_workflow
+    ON workflow.workflow_shadows(workflow_id, started_at DESC);
+
+-- ---------------------------------------------------------------------------
+-- Shadow comparisons table - One row per shadowed live execution
+-- ---------------------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS workflow.workflow_shadow_comparisons (
+    id                  UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    shadow_id           UUID NOT NULL REFERENCES workflow.workflow_shadows(id) ON DELETE CASCADE,
+    workflow_id         UUID NOT NULL,
+    live_execution_id   UUID NOT NULL,
+
+    live_version        INTEGER NOT NULL,
+    shadow_version      INTEGER NOT NULL,
+    live_status         VARCHAR(20) NOT NULL,
+    shadow_status       VARCHAR(20) NOT NULL,
+    shadow_error        TEXT,
+
+    -- Per-node differences and nodes not executed by the shadow
+    diverged            BOOLEAN NOT NULL DEFAULT false,
+    divergences         JSONB DEFAULT '[]',
+    replayed_nodes      JSONB DEFAULT '[]',
+    mocked_nodes        JSONB DEFAULT '[]',
+
+    live_duration       BIGINT,
+    shadow_duration     BIGINT,
+    created_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_workflow_shadow_comparisons_shadow
+    ON workflow.workflow_shadow_comparisons(shadow_id, created_at DESC);
+
+COMMIT;
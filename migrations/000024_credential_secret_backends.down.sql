@@ -0,0 +1,13 @@
+-- ============================================================================
+-- Migration: 000024_credential_secret_backends (ROLLBACK)
+-- Description: Drop the secret backend of credentials
+-- ============================================================================
+
+BEGIN;
+
+DROP INDEX IF EXISTS credential.idx_credentials_secret_backend;
+
+ALTER TABLE credential.credentials
+    DROP COLUMN IF EXISTS secret_backend;
+
+COMMIT;
@@ -0,0 +1,16 @@
+-- ============================================================================
+-- Migration: 000024_credential_secret_backends
+-- Description: Track the secret backend holding each credential's secrets
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE credential.credentials
+    ADD COLUMN IF NOT EXISTS secret_backend VARCHAR(20) NOT NULL DEFAULT 'local'
+        CHECK (secret_backend IN ('local', 'vault'));
+
+-- Backend migrations page through one backend at a time
+CREATE INDEX IF NOT EXISTS idx_credentials_secret_backend
+    ON credential.credentials(secret_backend, id);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000025_event_outbox (ROLLBACK)
+-- Description: Drop the event outbox
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS public.event_outbox;
+
+COMMIT;
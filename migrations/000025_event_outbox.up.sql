@@ -0,0 +1,32 @@
+-- ============================================================================
+-- Migration: 000025_event_outbox
+-- Description: Outbox of events written with the state change that raised
+--              them and relayed to the event bus by each service
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.event_outbox (
+    id UUID PRIMARY KEY,
+    source VARCHAR(100) NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    aggregate_id VARCHAR(255),
+    event JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Dispatchers claim the pending events of their service in order
+CREATE INDEX IF NOT EXISTS idx_event_outbox_pending
+    ON public.event_outbox(source, available_at, created_at)
+    WHERE published_at IS NULL;
+
+-- Published events are pruned after the retention period
+CREATE INDEX IF NOT EXISTS idx_event_outbox_published
+    ON public.event_outbox(published_at)
+    WHERE published_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,12 @@
+-- ============================================================================
+-- Migration: 000026_audit_event_store (ROLLBACK)
+-- Description: Drop the event store
+-- ============================================================================
+
+BEGIN;
+
+DELETE FROM audit.retention_policies WHERE table_name = 'audit.event_store';
+
+DROP TABLE IF EXISTS audit.event_store;
+
+COMMIT;
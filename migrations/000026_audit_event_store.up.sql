@@ -0,0 +1,37 @@
+-- ============================================================================
+-- Migration: 000026_audit_event_store
+-- Description: Store every published event for browsing and replay
+-- Schema: audit
+-- ============================================================================
+
+BEGIN;
+
+-- ---------------------------------------------------------------------------
+-- Event Store table - One row per event, as it was published
+-- ---------------------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS audit.event_store (
+    id              VARCHAR(100) PRIMARY KEY,
+    type            VARCHAR(255) NOT NULL,
+    aggregate_id    VARCHAR(255),
+    aggregate_type  VARCHAR(100),
+    user_id         VARCHAR(255),
+    correlation_id  VARCHAR(100),
+    version         INTEGER NOT NULL DEFAULT 1,
+
+    -- The whole event, replayed as is
+    event           JSONB NOT NULL,
+
+    occurred_at     TIMESTAMP WITH TIME ZONE NOT NULL,
+    stored_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_store_occurred_at ON audit.event_store(occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_event_store_aggregate ON audit.event_store(aggregate_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_event_store_type ON audit.event_store(type, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_event_store_correlation ON audit.event_store(correlation_id);
+
+INSERT INTO audit.retention_policies (table_name, retention_days) VALUES
+    ('audit.event_store', 365)
+ON CONFLICT (table_name) DO NOTHING;
+
+COMMIT;
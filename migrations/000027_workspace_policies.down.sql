@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000027_workspace_policies (ROLLBACK)
+-- Description: Drop the workspace policies
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS workflow.workspace_policies;
+
+COMMIT;
@@ -0,0 +1,24 @@
+-- ============================================================================
+-- Migration: 000027_workspace_policies
+-- Description: Workspace defaults inherited by new workflows, with the
+--              settings admins locked
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS workflow.workspace_policies (
+    -- Team ID, or user ID for workflows outside any team
+    workspace_id    VARCHAR(255) PRIMARY KEY,
+
+    -- Default timeout, retry policy, worker class and retention, and the
+    -- names of the locked settings
+    defaults        JSONB NOT NULL DEFAULT '{}',
+    locked          JSONB NOT NULL DEFAULT '[]',
+
+    updated_by      VARCHAR(255),
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMIT;
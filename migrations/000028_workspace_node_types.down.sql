@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000028_workspace_node_types (ROLLBACK)
+-- Description: Drop the workspace node type policies
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE workflow.workspace_policies DROP COLUMN IF EXISTS node_types;
+
+COMMIT;
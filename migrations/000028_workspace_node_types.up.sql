@@ -0,0 +1,14 @@
+-- ============================================================================
+-- Migration: 000028_workspace_node_types
+-- Description: Node types allowed and denied per workspace, enforced on
+--              save, import, activation and execution
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+-- {"allow": [...], "deny": [...]}, deny wins over allow
+ALTER TABLE workflow.workspace_policies
+    ADD COLUMN IF NOT EXISTS node_types JSONB NOT NULL DEFAULT '{}';
+
+COMMIT;
@@ -0,0 +1,12 @@
+-- ============================================================================
+-- Migration: 000029_execution_evidence_bundles (ROLLBACK)
+-- Description: Drop execution evidence bundles and state transitions
+-- ============================================================================
+
+BEGIN;
+
+DROP INDEX IF EXISTS credential.idx_credential_usage_execution_id;
+DROP TABLE IF EXISTS execution.state_transitions;
+DROP TABLE IF EXISTS execution.evidence_bundles;
+
+COMMIT;
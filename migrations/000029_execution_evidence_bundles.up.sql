@@ -0,0 +1,53 @@
+-- ============================================================================
+-- Migration: 000029_execution_evidence_bundles
+-- Description: Signed evidence archives of single executions for auditors,
+--              and the state transitions they include
+-- Schema: execution
+-- ============================================================================
+
+BEGIN;
+
+-- ---------------------------------------------------------------------------
+-- Evidence bundles table - One generated archive per request
+-- ---------------------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS execution.evidence_bundles (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    execution_id    UUID NOT NULL REFERENCES execution.workflow_executions(id) ON DELETE CASCADE,
+    requested_by    UUID,
+
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready', 'failed')),
+
+    -- Gzipped tarball, its SHA-256 and the HMAC of its manifest
+    archive         BYTEA,
+    size            BIGINT NOT NULL DEFAULT 0,
+    checksum        VARCHAR(64),
+    signature       VARCHAR(64),
+    error           TEXT,
+
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at    TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_evidence_bundles_execution
+    ON execution.evidence_bundles(execution_id, created_at DESC);
+
+-- ---------------------------------------------------------------------------
+-- State transitions table - Bundled as the approvals of paused executions
+-- ---------------------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS execution.state_transitions (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    execution_id    UUID NOT NULL REFERENCES execution.workflow_executions(id) ON DELETE CASCADE,
+    from_state      VARCHAR(20),
+    to_state        VARCHAR(20) NOT NULL,
+    timestamp       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    metadata        JSONB DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_state_transitions_execution
+    ON execution.state_transitions(execution_id, timestamp);
+
+-- Credential uses are looked up per execution when bundling
+CREATE INDEX IF NOT EXISTS idx_credential_usage_execution_id
+    ON credential.credential_usage_log(execution_id);
+
+COMMIT;
@@ -0,0 +1,12 @@
+-- ============================================================================
+-- Migration: 000030_keyset_pagination_indexes (ROLLBACK)
+-- Description: Drop the keyset pagination indexes
+-- ============================================================================
+
+BEGIN;
+
+DROP INDEX IF EXISTS execution.idx_executions_workflow_created_at_id;
+DROP INDEX IF EXISTS execution.idx_executions_created_at_id;
+DROP INDEX IF EXISTS workflow.idx_workflows_updated_at_id;
+
+COMMIT;
@@ -0,0 +1,19 @@
+-- ============================================================================
+-- Migration: 000030_keyset_pagination_indexes
+-- Description: Indexes matching the cursor order of the workflow and
+--              execution listings, the sort column then the id
+-- ============================================================================
+
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_workflows_updated_at_id
+    ON workflow.workflows(updated_at DESC, id DESC)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_executions_created_at_id
+    ON execution.workflow_executions(created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_executions_workflow_created_at_id
+    ON execution.workflow_executions(workflow_id, created_at DESC, id DESC);
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- ============================================================================
+-- Migration: 000031_workflow_variables (ROLLBACK)
+-- Description: Drop workflow variables and environments
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS workflow.environments;
+DROP TABLE IF EXISTS workflow.workflow_variables;
+
+COMMIT;
@@ -0,0 +1,49 @@
+-- ============================================================================
+-- Migration: 000031_workflow_variables
+-- Description: Variables of single workflows and the environments
+--              overriding them
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+-- ---------------------------------------------------------------------------
+-- Workflow variables table - Keyed by workflow and variable key
+-- ---------------------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS workflow.workflow_variables (
+    workflow_id     UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+    key             VARCHAR(255) NOT NULL,
+    name            VARCHAR(255),
+    type            VARCHAR(50) NOT NULL DEFAULT 'string',
+    value           JSONB,
+    description     TEXT,
+    scope           VARCHAR(50),
+    environment     VARCHAR(255),
+    encrypted       BOOLEAN DEFAULT FALSE,
+    read_only       BOOLEAN DEFAULT FALSE,
+    required        BOOLEAN DEFAULT FALSE,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (workflow_id, key)
+);
+
+-- ---------------------------------------------------------------------------
+-- Environments table - Variable overrides, one default per workflow
+-- ---------------------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS workflow.environments (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workflow_id     UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+    name            VARCHAR(255) NOT NULL,
+    description     TEXT,
+    variables       JSONB NOT NULL DEFAULT '{}',
+    is_default      BOOLEAN DEFAULT FALSE,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_environments_workflow_id ON workflow.environments(workflow_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_environments_default
+    ON workflow.environments(workflow_id) WHERE is_default;
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- ============================================================================
+-- Migration: 000032_tenant_isolation (ROLLBACK)
+-- Description: Drop the tenant of workflows, executions, credentials, users
+--              and API keys
+-- ============================================================================
+
+BEGIN;
+
+DROP INDEX IF EXISTS auth.idx_api_keys_tenant_id;
+DROP INDEX IF EXISTS auth.idx_users_tenant_id;
+DROP INDEX IF EXISTS credential.idx_credentials_tenant_id;
+DROP INDEX IF EXISTS execution.idx_workflow_executions_tenant_id;
+DROP INDEX IF EXISTS workflow.idx_workflows_tenant_id;
+
+ALTER TABLE auth.api_keys DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE auth.users DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE credential.credentials DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE execution.workflow_executions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE workflow.workflows DROP COLUMN IF EXISTS tenant_id;
+
+COMMIT;
@@ -0,0 +1,31 @@
+-- ============================================================================
+-- Migration: 000032_tenant_isolation
+-- Description: Tenant of the workflows, executions, credentials, users and
+--              API keys. Existing rows belong to the default tenant.
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE workflow.workflows
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+ALTER TABLE execution.workflow_executions
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+ALTER TABLE credential.credentials
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+ALTER TABLE auth.users
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+ALTER TABLE auth.api_keys
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- Every query of a tenant filters on it first
+CREATE INDEX IF NOT EXISTS idx_workflows_tenant_id ON workflow.workflows(tenant_id, created_at DESC, id);
+CREATE INDEX IF NOT EXISTS idx_workflow_executions_tenant_id ON execution.workflow_executions(tenant_id, created_at DESC, id);
+CREATE INDEX IF NOT EXISTS idx_credentials_tenant_id ON credential.credentials(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON auth.users(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id ON auth.api_keys(tenant_id);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000033_execution_backfills (ROLLBACK)
+-- Description: Drop execution backfills
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS execution.backfills;
+
+COMMIT;
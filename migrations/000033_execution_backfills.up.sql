@@ -0,0 +1,49 @@
+-- ============================================================================
+-- Migration: 000033_execution_backfills
+-- Description: Runs of schedule triggers over past ranges, one execution per
+--              fire time, and their progress
+-- Schema: execution
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS execution.backfills (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id       VARCHAR(64) NOT NULL DEFAULT 'default',
+    workflow_id     UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+    trigger_id      VARCHAR(255) NOT NULL,
+    cron_expression VARCHAR(255) NOT NULL,
+
+    -- Both ends of the range are fire times when the schedule matches them
+    range_start     TIMESTAMP NOT NULL,
+    range_end       TIMESTAMP NOT NULL,
+    concurrency     INTEGER NOT NULL DEFAULT 1 CHECK (concurrency > 0),
+
+    status          VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'cancelled', 'failed')),
+    total           INTEGER NOT NULL DEFAULT 0,
+    enqueued        INTEGER NOT NULL DEFAULT 0,
+    completed       INTEGER NOT NULL DEFAULT 0,
+    failed          INTEGER NOT NULL DEFAULT 0,
+    next_fire_at    TIMESTAMP,
+    -- Started executions that have not finished yet
+    running         JSONB NOT NULL DEFAULT '[]',
+    error           TEXT,
+
+    created_by      VARCHAR(255),
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finished_at     TIMESTAMP,
+
+    CONSTRAINT chk_backfills_range CHECK (range_end > range_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_backfills_tenant_created
+    ON execution.backfills(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_backfills_workflow
+    ON execution.backfills(workflow_id, created_at DESC);
+
+-- The runner only loads the backfills still starting executions
+CREATE INDEX IF NOT EXISTS idx_backfills_running
+    ON execution.backfills(created_at) WHERE status = 'running';
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- ============================================================================
+-- Migration: 000034_billing_execution_costs (ROLLBACK)
+-- Description: Drop billed execution costs
+-- ============================================================================
+
+BEGIN;
+
+DROP INDEX IF EXISTS billing.idx_subscriptions_period_end;
+DROP TABLE IF EXISTS billing.execution_costs;
+
+COMMIT;
@@ -0,0 +1,36 @@
+-- ============================================================================
+-- Migration: 000034_billing_execution_costs
+-- Description: Costs of completed executions, invoiced per subscription
+--              period to the team or user owning their workflow
+-- Schema: billing
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS billing.execution_costs (
+    -- One cost per execution, redelivered cost events are ignored
+    execution_id    UUID PRIMARY KEY,
+    workflow_id     UUID NOT NULL,
+    user_id         UUID NOT NULL,
+    team_id         VARCHAR(255) NOT NULL DEFAULT '',
+
+    compute_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    subtotal        DECIMAL(14, 6) NOT NULL DEFAULT 0,
+    total           DECIMAL(14, 6) NOT NULL DEFAULT 0,
+
+    calculated_at   TIMESTAMP NOT NULL,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Periods are summed per team, or per user for workflows outside teams
+CREATE INDEX IF NOT EXISTS idx_execution_costs_team_period
+    ON billing.execution_costs(team_id, calculated_at);
+CREATE INDEX IF NOT EXISTS idx_execution_costs_user_period
+    ON billing.execution_costs(user_id, calculated_at);
+
+-- Ended periods are looked up by the invoicer
+CREATE INDEX IF NOT EXISTS idx_subscriptions_period_end
+    ON billing.subscriptions(current_period_end)
+    WHERE status IN ('active', 'trialing');
+
+COMMIT;
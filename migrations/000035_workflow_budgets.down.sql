@@ -0,0 +1,21 @@
+-- ============================================================================
+-- Migration: 000035_workflow_budgets (ROLLBACK)
+-- Description: Drop workflow budgets and the notification delivery columns
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE notification.notifications
+    DROP COLUMN IF EXISTS error_message,
+    DROP COLUMN IF EXISTS sent_at,
+    DROP COLUMN IF EXISTS scheduled_at,
+    DROP COLUMN IF EXISTS max_retries,
+    DROP COLUMN IF EXISTS retry_count,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS body,
+    DROP COLUMN IF EXISTS subject,
+    DROP COLUMN IF EXISTS channel_id;
+
+DROP TABLE IF EXISTS workflow.budgets;
+
+COMMIT;
@@ -0,0 +1,44 @@
+-- ============================================================================
+-- Migration: 000035_workflow_budgets
+-- Description: Monthly cost budgets of workflows and workspaces, and the
+--              columns notifications are stored with by the notification
+--              service, which records budget alerts
+-- Schema: workflow, notification
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS workflow.budgets (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id       VARCHAR(64) NOT NULL DEFAULT 'default',
+
+    -- A workflow, or a workspace: a team or a user outside any team
+    scope           VARCHAR(20) NOT NULL CHECK (scope IN ('workflow', 'workspace')),
+    scope_id        VARCHAR(255) NOT NULL,
+
+    monthly_limit   DECIMAL(14, 6) NOT NULL CHECK (monthly_limit > 0),
+    -- Deactivate the workflows of the scope once the limit is reached
+    hard_cap        BOOLEAN NOT NULL DEFAULT FALSE,
+
+    updated_by      VARCHAR(255),
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_scope ON workflow.budgets(scope, scope_id);
+CREATE INDEX IF NOT EXISTS idx_budgets_tenant_id ON workflow.budgets(tenant_id);
+
+-- Notifications are stored with a subject, body and delivery status
+ALTER TABLE notification.notifications
+    ALTER COLUMN title DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS channel_id    VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS subject       VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS body          TEXT,
+    ADD COLUMN IF NOT EXISTS status        VARCHAR(20) DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS retry_count   INTEGER DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS max_retries   INTEGER DEFAULT 3,
+    ADD COLUMN IF NOT EXISTS scheduled_at  TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS sent_at       TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS error_message TEXT;
+
+COMMIT;
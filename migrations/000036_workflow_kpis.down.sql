@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000036_workflow_kpis (ROLLBACK)
+-- Description: Drop workflow KPIs
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS execution.workflow_kpis;
+
+COMMIT;
@@ -0,0 +1,28 @@
+-- ============================================================================
+-- Migration: 000036_workflow_kpis
+-- Description: Business metrics emitted by the metric nodes of workflows,
+--              summed per workflow and day for the analytics dashboard
+-- Schema: execution
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS execution.workflow_kpis (
+    tenant_id       VARCHAR(64) NOT NULL DEFAULT 'default',
+    workflow_id     VARCHAR(255) NOT NULL,
+    -- UTC day, YYYY-MM-DD
+    day             VARCHAR(10) NOT NULL,
+    name            VARCHAR(63) NOT NULL,
+
+    -- Sum of the values emitted on the day, and how many were
+    value           DOUBLE PRECISION NOT NULL DEFAULT 0,
+    samples         BIGINT NOT NULL DEFAULT 0,
+
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (tenant_id, workflow_id, day, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workflow_kpis_day ON execution.workflow_kpis(tenant_id, day);
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- ============================================================================
+-- Migration: 000037_execution_costs (ROLLBACK)
+-- Description: Drop persisted execution costs and their rollups
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE execution.node_executions ALTER COLUMN node_type DROP DEFAULT;
+
+DROP TABLE IF EXISTS execution.node_type_cost_rollups;
+DROP TABLE IF EXISTS execution.cost_rollups;
+DROP TABLE IF EXISTS execution.node_type_costs;
+DROP TABLE IF EXISTS execution.execution_costs;
+
+COMMIT;
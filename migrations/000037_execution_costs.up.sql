@@ -0,0 +1,91 @@
+-- ============================================================================
+-- Migration: 000037_execution_costs
+-- Description: Costs of completed executions, their split per node type and
+--              the daily rollups cost reports are read from, and the type of
+--              each node execution
+-- Schema: execution
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS execution.execution_costs (
+    -- One cost per execution, recalculations are ignored
+    execution_id    VARCHAR(255) PRIMARY KEY,
+    tenant_id       VARCHAR(64) NOT NULL DEFAULT 'default',
+    workflow_id     VARCHAR(255) NOT NULL,
+    user_id         VARCHAR(255) NOT NULL DEFAULT '',
+    team_id         VARCHAR(255) NOT NULL DEFAULT '',
+
+    compute_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    subtotal        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    discount        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    total           DOUBLE PRECISION NOT NULL DEFAULT 0,
+    currency        VARCHAR(3) NOT NULL DEFAULT 'USD',
+
+    calculated_at   TIMESTAMP NOT NULL,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_execution_costs_calculated_at ON execution.execution_costs(calculated_at);
+CREATE INDEX IF NOT EXISTS idx_execution_costs_workflow_id ON execution.execution_costs(workflow_id);
+CREATE INDEX IF NOT EXISTS idx_execution_costs_tenant_id ON execution.execution_costs(tenant_id);
+
+CREATE TABLE IF NOT EXISTS execution.node_type_costs (
+    execution_id    VARCHAR(255) NOT NULL REFERENCES execution.execution_costs(execution_id) ON DELETE CASCADE,
+    node_type       VARCHAR(100) NOT NULL,
+    tenant_id       VARCHAR(64) NOT NULL DEFAULT 'default',
+    workflow_id     VARCHAR(255) NOT NULL,
+    team_id         VARCHAR(255) NOT NULL DEFAULT '',
+
+    -- Share of the execution total, split by the time each node ran
+    nodes           INTEGER NOT NULL DEFAULT 0,
+    cost            DOUBLE PRECISION NOT NULL DEFAULT 0,
+
+    calculated_at   TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (execution_id, node_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_type_costs_calculated_at ON execution.node_type_costs(calculated_at);
+
+-- Rebuilt for the current and previous UTC day by every rollup run
+CREATE TABLE IF NOT EXISTS execution.cost_rollups (
+    tenant_id       VARCHAR(64) NOT NULL DEFAULT 'default',
+    day             VARCHAR(10) NOT NULL,
+    workflow_id     VARCHAR(255) NOT NULL,
+    user_id         VARCHAR(255) NOT NULL,
+    team_id         VARCHAR(255) NOT NULL,
+
+    executions      BIGINT NOT NULL DEFAULT 0,
+    compute_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+    total           DOUBLE PRECISION NOT NULL DEFAULT 0,
+
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (tenant_id, day, workflow_id, user_id, team_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cost_rollups_day ON execution.cost_rollups(day);
+
+CREATE TABLE IF NOT EXISTS execution.node_type_cost_rollups (
+    tenant_id       VARCHAR(64) NOT NULL DEFAULT 'default',
+    day             VARCHAR(10) NOT NULL,
+    workflow_id     VARCHAR(255) NOT NULL,
+    team_id         VARCHAR(255) NOT NULL,
+    node_type       VARCHAR(100) NOT NULL,
+
+    executions      BIGINT NOT NULL DEFAULT 0,
+    nodes           BIGINT NOT NULL DEFAULT 0,
+    cost            DOUBLE PRECISION NOT NULL DEFAULT 0,
+
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (tenant_id, day, workflow_id, team_id, node_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_type_cost_rollups_day ON execution.node_type_cost_rollups(day);
+
+-- Node executions are recorded with the type of their node
+ALTER TABLE execution.node_executions ALTER COLUMN node_type SET DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000038_execution_sampling (ROLLBACK)
+-- Description: Drop sampled out execution counts
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS execution.sampled_out;
+
+COMMIT;
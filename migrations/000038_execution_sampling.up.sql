@@ -0,0 +1,24 @@
+-- ============================================================================
+-- Migration: 000038_execution_sampling
+-- Description: Successful executions dropped by the sampling policies of
+--              workflows, counted per workflow and day for execution stats
+-- Schema: execution
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS execution.sampled_out (
+    tenant_id       VARCHAR(64) NOT NULL DEFAULT 'default',
+    workflow_id     VARCHAR(255) NOT NULL,
+    -- UTC day, YYYY-MM-DD
+    day             VARCHAR(10) NOT NULL,
+
+    -- Successful executions deleted on the day
+    executions      BIGINT NOT NULL DEFAULT 0,
+
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (tenant_id, workflow_id, day)
+);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000039_analytics_daily_executions (ROLLBACK)
+-- Description: Drop the daily execution aggregates
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS analytics.daily_executions;
+
+COMMIT;
@@ -0,0 +1,30 @@
+-- ============================================================================
+-- Migration: 000039_analytics_daily_executions
+-- Description: Executions of each workflow per day started, by outcome,
+--              maintained from the execution events for the dashboard
+-- Schema: analytics
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS analytics.daily_executions (
+    tenant_id       VARCHAR(64) NOT NULL DEFAULT 'default',
+    -- UTC day the executions started, YYYY-MM-DD
+    day             VARCHAR(10) NOT NULL,
+    workflow_id     VARCHAR(255) NOT NULL,
+
+    started         BIGINT NOT NULL DEFAULT 0,
+    succeeded       BIGINT NOT NULL DEFAULT 0,
+    failed          BIGINT NOT NULL DEFAULT 0,
+    cancelled       BIGINT NOT NULL DEFAULT 0,
+
+    -- Finished executions with a duration and the sum of their durations
+    finished        BIGINT NOT NULL DEFAULT 0,
+    duration_ms     BIGINT NOT NULL DEFAULT 0,
+
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (tenant_id, day, workflow_id)
+);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000040_workflow_slas (ROLLBACK)
+-- Description: Drop the SLAs of workflows
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS workflow.slas;
+
+COMMIT;
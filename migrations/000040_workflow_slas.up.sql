@@ -0,0 +1,31 @@
+-- ============================================================================
+-- Migration: 000040_workflow_slas
+-- Description: SLAs of workflows: the longest an execution runs, the share
+--              of executions failing over a window and the longest time
+--              between two executions starting
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS workflow.slas (
+    id                          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id                   VARCHAR(64) NOT NULL DEFAULT 'default',
+    workflow_id                 UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+
+    -- Objectives at 0 are not monitored
+    max_duration_ms             BIGINT NOT NULL DEFAULT 0 CHECK (max_duration_ms >= 0),
+    max_failure_rate            DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK (max_failure_rate BETWEEN 0 AND 100),
+    window_minutes              INTEGER NOT NULL DEFAULT 60 CHECK (window_minutes BETWEEN 1 AND 1440),
+    min_executions              INTEGER NOT NULL DEFAULT 1,
+    expected_interval_seconds   BIGINT NOT NULL DEFAULT 0 CHECK (expected_interval_seconds >= 0),
+
+    updated_by                  VARCHAR(255),
+    created_at                  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at                  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_slas_workflow_id ON workflow.slas(workflow_id);
+CREATE INDEX IF NOT EXISTS idx_slas_tenant_id ON workflow.slas(tenant_id);
+
+COMMIT;
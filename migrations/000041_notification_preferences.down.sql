@@ -0,0 +1,29 @@
+-- ============================================================================
+-- Migration: 000041_notification_preferences (ROLLBACK)
+-- Description: Drop the channels, alerts, quiet hours and digests of
+--              notification preferences
+-- ============================================================================
+
+BEGIN;
+
+DROP INDEX IF EXISTS notification.idx_notifications_digest_due;
+
+ALTER TABLE notification.notifications
+    DROP COLUMN IF EXISTS channels;
+
+ALTER TABLE notification.preferences
+    ALTER COLUMN quiet_hours_start TYPE TIME USING NULLIF(quiet_hours_start, '')::TIME,
+    ALTER COLUMN quiet_hours_end TYPE TIME USING NULLIF(quiet_hours_end, '')::TIME;
+
+ALTER TABLE notification.preferences
+    DROP COLUMN IF EXISTS digest_hour,
+    DROP COLUMN IF EXISTS delivery,
+    DROP COLUMN IF EXISTS approval_requests,
+    DROP COLUMN IF EXISTS anomaly_alerts,
+    DROP COLUMN IF EXISTS sla_alerts,
+    DROP COLUMN IF EXISTS webhook_enabled,
+    DROP COLUMN IF EXISTS slack_enabled,
+    DROP COLUMN IF EXISTS push_enabled,
+    DROP COLUMN IF EXISTS email_enabled;
+
+COMMIT;
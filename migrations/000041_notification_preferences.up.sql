@@ -0,0 +1,40 @@
+-- ============================================================================
+-- Migration: 000041_notification_preferences
+-- Description: Notification preferences of users: the channels they are
+--              delivered through, the alerts received, quiet hours and
+--              digests, and the notifications held for digests
+-- Schema: notification
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE notification.preferences
+    -- Channels besides the inbox
+    ADD COLUMN IF NOT EXISTS email_enabled      BOOLEAN DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS push_enabled       BOOLEAN DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS slack_enabled      BOOLEAN DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS webhook_enabled    BOOLEAN DEFAULT FALSE,
+
+    -- Alerts
+    ADD COLUMN IF NOT EXISTS sla_alerts         BOOLEAN DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS anomaly_alerts     BOOLEAN DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS approval_requests  BOOLEAN DEFAULT TRUE,
+
+    -- Immediate delivery, or a daily digest at digest_hour
+    ADD COLUMN IF NOT EXISTS delivery           VARCHAR(20) NOT NULL DEFAULT 'immediate' CHECK (delivery IN ('immediate', 'digest')),
+    ADD COLUMN IF NOT EXISTS digest_hour        INTEGER NOT NULL DEFAULT 8 CHECK (digest_hour BETWEEN 0 AND 23);
+
+-- Quiet hours are HH:MM in the timezone of the user, empty when unset
+ALTER TABLE notification.preferences
+    ALTER COLUMN quiet_hours_start TYPE VARCHAR(5) USING COALESCE(to_char(quiet_hours_start, 'HH24:MI'), ''),
+    ALTER COLUMN quiet_hours_end TYPE VARCHAR(5) USING COALESCE(to_char(quiet_hours_end, 'HH24:MI'), '');
+
+-- Channels a notification is delivered through besides the inbox
+ALTER TABLE notification.notifications
+    ADD COLUMN IF NOT EXISTS channels JSONB DEFAULT '[]';
+
+-- Notifications held for a digest until it is due
+CREATE INDEX IF NOT EXISTS idx_notifications_digest_due
+    ON notification.notifications(scheduled_at) WHERE status = 'queued';
+
+COMMIT;
@@ -0,0 +1,12 @@
+-- ============================================================================
+-- Migration: 000042_notification_inbox (ROLLBACK)
+-- Description: Drop the indexes of the notification inbox
+-- ============================================================================
+
+BEGIN;
+
+DROP INDEX IF EXISTS notification.idx_notifications_read_at;
+DROP INDEX IF EXISTS notification.idx_notifications_unread;
+DROP INDEX IF EXISTS notification.idx_notifications_inbox;
+
+COMMIT;
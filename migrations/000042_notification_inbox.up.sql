@@ -0,0 +1,22 @@
+-- ============================================================================
+-- Migration: 000042_notification_inbox
+-- Description: Indexes of the in-app notification inbox: its pages newest
+--              first, its unread count and the retention of notifications
+-- Schema: notification
+-- ============================================================================
+
+BEGIN;
+
+-- Pages of an inbox in the cursor order
+CREATE INDEX IF NOT EXISTS idx_notifications_inbox
+    ON notification.notifications(user_id, created_at DESC, id DESC);
+
+-- Unread count of an inbox
+CREATE INDEX IF NOT EXISTS idx_notifications_unread
+    ON notification.notifications(user_id) WHERE read_at IS NULL;
+
+-- Notifications read longer ago than the read retention
+CREATE INDEX IF NOT EXISTS idx_notifications_read_at
+    ON notification.notifications(read_at) WHERE read_at IS NOT NULL;
+
+COMMIT;
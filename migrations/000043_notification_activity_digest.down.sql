@@ -0,0 +1,17 @@
+-- ============================================================================
+-- Migration: 000043_notification_activity_digest (ROLLBACK)
+-- Description: Restore the weekly digest flag of notification preferences
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE notification.preferences
+    ADD COLUMN IF NOT EXISTS weekly_digest BOOLEAN DEFAULT TRUE;
+
+UPDATE notification.preferences SET weekly_digest = (activity_digest <> 'off');
+
+ALTER TABLE notification.preferences
+    DROP COLUMN IF EXISTS activity_digest_sent_at,
+    DROP COLUMN IF EXISTS activity_digest;
+
+COMMIT;
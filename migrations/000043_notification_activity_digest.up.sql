@@ -0,0 +1,21 @@
+-- ============================================================================
+-- Migration: 000043_notification_activity_digest
+-- Description: Daily or weekly activity digest emails, replacing the weekly
+--              digest flag of notification preferences
+-- Schema: notification
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE notification.preferences
+    ADD COLUMN IF NOT EXISTS activity_digest         VARCHAR(10) NOT NULL DEFAULT 'weekly' CHECK (activity_digest IN ('off', 'daily', 'weekly')),
+    -- When the last digest was sent, the next is due after its period
+    ADD COLUMN IF NOT EXISTS activity_digest_sent_at TIMESTAMP;
+
+-- Users who opted out of the weekly digest stay out of the activity digest
+UPDATE notification.preferences SET activity_digest = 'off' WHERE weekly_digest = FALSE;
+
+ALTER TABLE notification.preferences
+    DROP COLUMN IF EXISTS weekly_digest;
+
+COMMIT;
@@ -0,0 +1,16 @@
+-- ============================================================================
+-- Migration: 000044_notification_incident_channels (ROLLBACK)
+-- Description: Remove the PagerDuty and Opsgenie channels
+-- ============================================================================
+
+BEGIN;
+
+DROP INDEX IF EXISTS notification.idx_notification_channels_incident;
+
+DELETE FROM notification.channels WHERE type IN ('pagerduty', 'opsgenie');
+
+ALTER TABLE notification.channels DROP CONSTRAINT IF EXISTS channels_type_check;
+ALTER TABLE notification.channels ADD CONSTRAINT channels_type_check
+    CHECK (type IN ('email', 'slack', 'webhook', 'sms', 'push'));
+
+COMMIT;
@@ -0,0 +1,18 @@
+-- ============================================================================
+-- Migration: 000044_notification_incident_channels
+-- Description: PagerDuty and Opsgenie channels critical events are paged to
+-- Schema: notification
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE notification.channels DROP CONSTRAINT IF EXISTS channels_type_check;
+ALTER TABLE notification.channels ADD CONSTRAINT channels_type_check
+    CHECK (type IN ('email', 'slack', 'webhook', 'sms', 'push', 'pagerduty', 'opsgenie'));
+
+-- A user has one channel of each incident type
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_channels_incident
+    ON notification.channels(user_id, type)
+    WHERE type IN ('pagerduty', 'opsgenie');
+
+COMMIT;
@@ -0,0 +1,13 @@
+-- ============================================================================
+-- Migration: 000045_workflow_template_marketplace (ROLLBACK)
+-- Description: Drop the templates of the workflow service, their reviews
+--              and uses
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS workflow.template_uses;
+DROP TABLE IF EXISTS workflow.template_reviews;
+DROP TABLE IF EXISTS workflow.templates;
+
+COMMIT;
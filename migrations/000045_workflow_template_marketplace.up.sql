@@ -0,0 +1,72 @@
+-- ============================================================================
+-- Migration: 000045_workflow_template_marketplace
+-- Description: Templates of the workflow service with their ratings, reviews
+--              and uses, and the moderation of public templates before they
+--              are listed in the marketplace
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS workflow.templates (
+    id              VARCHAR(64) PRIMARY KEY,
+    name            VARCHAR(200) NOT NULL,
+    description     TEXT,
+    category        VARCHAR(50),
+    icon            VARCHAR(50),
+
+    workflow        JSONB,
+    variables       JSONB,
+    tags            JSONB,
+    config          JSONB,
+
+    is_public       BOOLEAN DEFAULT FALSE,
+    is_built_in     BOOLEAN DEFAULT FALSE,
+    creator_id      VARCHAR(255),
+
+    -- Stats, rating is the average of the reviews
+    usage_count     BIGINT DEFAULT 0,
+    rating          REAL DEFAULT 0,
+    review_count    BIGINT DEFAULT 0,
+
+    -- Public templates are listed once approved, private ones have none
+    moderation      VARCHAR(20) NOT NULL DEFAULT '' CHECK (moderation IN ('', 'pending', 'approved', 'rejected')),
+    moderation_note TEXT,
+
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_templates_name ON workflow.templates(name);
+CREATE INDEX IF NOT EXISTS idx_templates_marketplace
+    ON workflow.templates(usage_count DESC) WHERE is_public AND moderation = 'approved';
+CREATE INDEX IF NOT EXISTS idx_templates_moderation
+    ON workflow.templates(moderation, created_at) WHERE is_public;
+
+CREATE TABLE IF NOT EXISTS workflow.template_reviews (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_id     VARCHAR(64) NOT NULL,
+    user_id         VARCHAR(255) NOT NULL,
+    rating          INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment         TEXT,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One review per user and template, built-in templates have no row in
+-- workflow.templates so template_id is not a foreign key
+CREATE UNIQUE INDEX IF NOT EXISTS idx_template_reviews_user ON workflow.template_reviews(template_id, user_id);
+
+CREATE TABLE IF NOT EXISTS workflow.template_uses (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_id     VARCHAR(64) NOT NULL,
+    user_id         VARCHAR(255),
+    workflow_id     VARCHAR(64),
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Trending templates rank by their uses of the last week
+CREATE INDEX IF NOT EXISTS idx_template_uses_template ON workflow.template_uses(template_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_template_uses_created_at ON workflow.template_uses(created_at);
+
+COMMIT;
@@ -0,0 +1,18 @@
+-- ============================================================================
+-- Migration: 000046_workflow_template_versions (ROLLBACK)
+-- Description: Drop the versions of workflow templates
+-- ============================================================================
+
+BEGIN;
+
+DROP INDEX IF EXISTS workflow.idx_template_uses_workflow;
+
+ALTER TABLE workflow.template_uses DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE workflow.template_uses DROP COLUMN IF EXISTS variables;
+ALTER TABLE workflow.template_uses DROP COLUMN IF EXISTS version;
+
+DROP TABLE IF EXISTS workflow.template_versions;
+
+ALTER TABLE workflow.templates DROP COLUMN IF EXISTS version;
+
+COMMIT;
@@ -0,0 +1,33 @@
+-- ============================================================================
+-- Migration: 000046_workflow_template_versions
+-- Description: Versions of workflow templates, and the version and variable
+--              values each workflow created from a template was last
+--              rendered with, so it can upgrade to newer versions
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE workflow.templates ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS workflow.template_versions (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_id     VARCHAR(64) NOT NULL,
+    version         INTEGER NOT NULL,
+    workflow        JSONB,
+    variables       JSONB,
+    changelog       TEXT,
+    created_by      VARCHAR(255),
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_template_versions_version ON workflow.template_versions(template_id, version);
+
+-- Secret values are never kept, they are given again on upgrade
+ALTER TABLE workflow.template_uses ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE workflow.template_uses ADD COLUMN IF NOT EXISTS variables JSONB;
+ALTER TABLE workflow.template_uses ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_template_uses_workflow ON workflow.template_uses(workflow_id);
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- ============================================================================
+-- Migration: 000047_workflow_environment_deployments (ROLLBACK)
+-- Description: Drop the deployments of workflow environments
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE execution.backfills DROP COLUMN IF EXISTS environment_id;
+ALTER TABLE execution.workflow_executions DROP COLUMN IF EXISTS environment_id;
+
+DROP TABLE IF EXISTS workflow.environment_deployments;
+
+DROP INDEX IF EXISTS workflow.idx_environments_name;
+
+ALTER TABLE workflow.environments DROP COLUMN IF EXISTS deployed_at;
+ALTER TABLE workflow.environments DROP COLUMN IF EXISTS deployed_by;
+ALTER TABLE workflow.environments DROP COLUMN IF EXISTS deployed_version;
+ALTER TABLE workflow.environments DROP COLUMN IF EXISTS promotes_from;
+ALTER TABLE workflow.environments DROP COLUMN IF EXISTS credentials;
+
+COMMIT;
@@ -0,0 +1,37 @@
+-- ============================================================================
+-- Migration: 000047_workflow_environment_deployments
+-- Description: Workflow versions deployed to environments, the order
+--              environments promote versions in, the credentials each
+--              environment binds and the environment executions ran in
+-- Schema: workflow, execution
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE workflow.environments ADD COLUMN IF NOT EXISTS credentials JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE workflow.environments ADD COLUMN IF NOT EXISTS promotes_from VARCHAR(36);
+ALTER TABLE workflow.environments ADD COLUMN IF NOT EXISTS deployed_version INTEGER;
+ALTER TABLE workflow.environments ADD COLUMN IF NOT EXISTS deployed_by VARCHAR(255);
+ALTER TABLE workflow.environments ADD COLUMN IF NOT EXISTS deployed_at TIMESTAMP;
+
+-- Executions and triggers name environments
+CREATE UNIQUE INDEX IF NOT EXISTS idx_environments_name ON workflow.environments(workflow_id, name);
+
+CREATE TABLE IF NOT EXISTS workflow.environment_deployments (
+    id                  UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workflow_id         UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+    environment_id      UUID NOT NULL REFERENCES workflow.environments(id) ON DELETE CASCADE,
+    version             INTEGER NOT NULL,
+    previous_version    INTEGER,
+    promoted_from       VARCHAR(36),
+    deployed_by         VARCHAR(255),
+    created_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_environment_deployments_environment
+    ON workflow.environment_deployments(environment_id, created_at DESC);
+
+ALTER TABLE execution.workflow_executions ADD COLUMN IF NOT EXISTS environment_id VARCHAR(36);
+ALTER TABLE execution.backfills ADD COLUMN IF NOT EXISTS environment_id VARCHAR(36);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000048_workflow_git_connections (ROLLBACK)
+-- Description: Drop the Git connections of workflows
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS workflow.git_connections;
+
+COMMIT;
@@ -0,0 +1,31 @@
+-- ============================================================================
+-- Migration: 000048_workflow_git_connections
+-- Description: Git repositories workflows are synced with, each version
+--              pushed there and changes pulled back once previewed
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS workflow.git_connections (
+    id                  UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workflow_id         UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+    repository_url      TEXT NOT NULL,
+    branch              VARCHAR(255) NOT NULL,
+    path                TEXT NOT NULL,
+    format              VARCHAR(10) NOT NULL DEFAULT 'json',
+    token               TEXT,
+    auto_push           BOOLEAN DEFAULT true,
+    last_pushed_version INTEGER,
+    last_pushed_commit  VARCHAR(64),
+    last_pulled_commit  VARCHAR(64),
+    last_synced_at      TIMESTAMP,
+    last_error          TEXT,
+    created_by          VARCHAR(255),
+    created_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_git_connections_workflow ON workflow.git_connections(workflow_id);
+
+COMMIT;
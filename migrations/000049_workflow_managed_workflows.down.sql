@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000049_workflow_managed_workflows (ROLLBACK)
+-- Description: Drop the records of workflows created by applies
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS workflow.managed_workflows;
+
+COMMIT;
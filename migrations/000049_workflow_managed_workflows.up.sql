@@ -0,0 +1,25 @@
+-- ============================================================================
+-- Migration: 000049_workflow_managed_workflows
+-- Description: Workflows created by declarative applies, keyed by namespace
+--              and key so later applies update or prune them
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS workflow.managed_workflows (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    workspace_id VARCHAR(255) NOT NULL,
+    namespace    VARCHAR(128) NOT NULL,
+    key          VARCHAR(128) NOT NULL,
+    workflow_id  UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+    spec         JSONB NOT NULL DEFAULT '{}',
+    applied_by   VARCHAR(255),
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_managed_workflows_key ON workflow.managed_workflows(workspace_id, namespace, key);
+CREATE INDEX IF NOT EXISTS idx_managed_workflows_workflow_id ON workflow.managed_workflows(workflow_id);
+
+COMMIT;
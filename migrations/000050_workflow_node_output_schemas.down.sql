@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000050_workflow_node_output_schemas (ROLLBACK)
+-- Description: Drop the inferred schemas of node outputs
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS workflow.node_output_schemas;
+
+COMMIT;
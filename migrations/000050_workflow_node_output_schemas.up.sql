@@ -0,0 +1,25 @@
+-- ============================================================================
+-- Migration: 000050_workflow_node_output_schemas
+-- Description: Schemas of node outputs inferred from test runs, checked
+--              against the fields the nodes after them read
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS workflow.node_output_schemas (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id   VARCHAR(64) NOT NULL DEFAULT 'default',
+    workflow_id UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+    node_id     VARCHAR(255) NOT NULL,
+    schema      JSONB NOT NULL DEFAULT '{}',
+    samples     INTEGER NOT NULL DEFAULT 0,
+    updated_by  VARCHAR(255),
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_node_output_schemas_node ON workflow.node_output_schemas(workflow_id, node_id);
+CREATE INDEX IF NOT EXISTS idx_node_output_schemas_tenant_id ON workflow.node_output_schemas(tenant_id);
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- ============================================================================
+-- Migration: 000051_workflow_annotations (ROLLBACK)
+-- Description: Drop the annotations of workflows
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE workflow.workflows
+    DROP COLUMN IF EXISTS annotations;
+
+COMMIT;
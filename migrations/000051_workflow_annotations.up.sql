@@ -0,0 +1,11 @@
+-- ============================================================================
+-- Migration: 000051_workflow_annotations
+-- Description: Store sticky notes, node descriptions and groups on workflows
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE workflow.workflows
+    ADD COLUMN IF NOT EXISTS annotations JSONB DEFAULT '[]';
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000052_workflow_changes (ROLLBACK)
+-- Description: Drop the change history of workflows
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS workflow.workflow_changes;
+
+COMMIT;
@@ -0,0 +1,27 @@
+-- ============================================================================
+-- Migration: 000052_workflow_changes
+-- Description: Field-level summaries of the saves of workflows, attributed
+--              to the users making them
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS workflow.workflow_changes (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id   VARCHAR(64) NOT NULL DEFAULT 'default',
+    workflow_id UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+    version     INTEGER NOT NULL,
+    user_id     VARCHAR(255) NOT NULL,
+    fields      JSONB NOT NULL DEFAULT '[]',
+    settings    JSONB,
+    nodes       JSONB,
+    connections JSONB,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_workflow_changes_workflow ON workflow.workflow_changes(workflow_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_workflow_changes_user_id ON workflow.workflow_changes(user_id);
+CREATE INDEX IF NOT EXISTS idx_workflow_changes_tenant_id ON workflow.workflow_changes(tenant_id);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000053_trigger_fixtures (ROLLBACK)
+-- Description: Drop the fixtures of triggers
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS workflow.trigger_fixtures;
+
+COMMIT;
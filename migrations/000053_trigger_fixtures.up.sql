@@ -0,0 +1,26 @@
+-- ============================================================================
+-- Migration: 000053_trigger_fixtures
+-- Description: Named payloads of triggers, captured or saved by users and
+--              replayed through trigger and workflow tests
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS workflow.trigger_fixtures (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id   VARCHAR(64) NOT NULL DEFAULT 'default',
+    workflow_id UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+    trigger_id  VARCHAR(36) NOT NULL,
+    name        VARCHAR(64) NOT NULL,
+    payload     JSONB NOT NULL DEFAULT '{}',
+    source      VARCHAR(20) NOT NULL,
+    created_by  VARCHAR(255),
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trigger_fixtures_name ON workflow.trigger_fixtures(trigger_id, name);
+CREATE INDEX IF NOT EXISTS idx_trigger_fixtures_workflow_id ON workflow.trigger_fixtures(workflow_id);
+CREATE INDEX IF NOT EXISTS idx_trigger_fixtures_tenant_id ON workflow.trigger_fixtures(tenant_id);
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- ============================================================================
+-- Migration: 000054_workflow_tests (ROLLBACK)
+-- Description: Drop the test cases of workflows and their runs
+-- ============================================================================
+
+BEGIN;
+
+DROP TABLE IF EXISTS workflow.workflow_test_runs;
+DROP TABLE IF EXISTS workflow.workflow_test_cases;
+
+COMMIT;
@@ -0,0 +1,46 @@
+-- ============================================================================
+-- Migration: 000054_workflow_tests
+-- Description: Test cases of workflows, simulated against mocks with
+--              assertions on node outputs, and the runs of their suites
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS workflow.workflow_test_cases (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id   VARCHAR(64) NOT NULL DEFAULT 'default',
+    workflow_id UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+    name        VARCHAR(100) NOT NULL,
+    description TEXT,
+    input       JSONB NOT NULL DEFAULT '{}',
+    mocks       JSONB NOT NULL DEFAULT '[]',
+    strict      BOOLEAN NOT NULL DEFAULT FALSE,
+    assertions  JSONB NOT NULL DEFAULT '[]',
+    created_by  VARCHAR(255),
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workflow_test_cases_name ON workflow.workflow_test_cases(workflow_id, name);
+CREATE INDEX IF NOT EXISTS idx_workflow_test_cases_tenant_id ON workflow.workflow_test_cases(tenant_id);
+
+CREATE TABLE IF NOT EXISTS workflow.workflow_test_runs (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id    VARCHAR(64) NOT NULL DEFAULT 'default',
+    workflow_id  UUID NOT NULL REFERENCES workflow.workflows(id) ON DELETE CASCADE,
+    version      INTEGER NOT NULL,
+    status       VARCHAR(20) NOT NULL,
+    cases        JSONB NOT NULL DEFAULT '[]',
+    passed       INTEGER NOT NULL DEFAULT 0,
+    failed       INTEGER NOT NULL DEFAULT 0,
+    error        TEXT,
+    requested_by VARCHAR(255),
+    started_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finished_at  TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_workflow_test_runs_workflow ON workflow.workflow_test_runs(workflow_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_workflow_test_runs_tenant_id ON workflow.workflow_test_runs(tenant_id);
+
+COMMIT;
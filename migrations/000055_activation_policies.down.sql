@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000055_activation_policies (ROLLBACK)
+-- Description: Drop the workspace activation policies
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE workflow.workspace_policies DROP COLUMN IF EXISTS activation;
+
+COMMIT;
@@ -0,0 +1,14 @@
+-- ============================================================================
+-- Migration: 000055_activation_policies
+-- Description: Workspace policies gating the activation of workflows, and
+--              deployments to protected environments, on validation and tests
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+-- {"requireValid": bool, "requireTests": bool, "environments": [...]}
+ALTER TABLE workflow.workspace_policies
+    ADD COLUMN IF NOT EXISTS activation JSONB NOT NULL DEFAULT '{}';
+
+COMMIT;
@@ -0,0 +1,17 @@
+-- ============================================================================
+-- Migration: 000056_blue_green_rollouts (ROLLBACK)
+-- Description: Drop blue/green rollouts, keeping canaries
+-- ============================================================================
+
+BEGIN;
+
+DELETE FROM workflow.workflow_canaries WHERE strategy = 'blue_green';
+
+ALTER TABLE workflow.workflow_canaries
+    DROP CONSTRAINT IF EXISTS workflow_canaries_percentage_check;
+ALTER TABLE workflow.workflow_canaries
+    ADD CONSTRAINT workflow_canaries_percentage_check CHECK (percentage BETWEEN 1 AND 99);
+
+ALTER TABLE workflow.workflow_canaries DROP COLUMN IF EXISTS strategy;
+
+COMMIT;
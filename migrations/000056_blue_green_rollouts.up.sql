@@ -0,0 +1,20 @@
+-- ============================================================================
+-- Migration: 000056_blue_green_rollouts
+-- Description: Blue/green rollouts running two versions side by side, with
+--              traffic shifted by hand between none and all executions
+-- Schema: workflow
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE workflow.workflow_canaries
+    ADD COLUMN IF NOT EXISTS strategy VARCHAR(20) NOT NULL DEFAULT 'canary'
+    CHECK (strategy IN ('canary', 'blue_green'));
+
+-- Blue/green rollouts route from none to all executions to the new version
+ALTER TABLE workflow.workflow_canaries
+    DROP CONSTRAINT IF EXISTS workflow_canaries_percentage_check;
+ALTER TABLE workflow.workflow_canaries
+    ADD CONSTRAINT workflow_canaries_percentage_check CHECK (percentage BETWEEN 0 AND 100);
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Migration: 000057_execution_bindings (ROLLBACK)
+-- Description: Drop the bindings of executions
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE execution.workflow_executions DROP COLUMN IF EXISTS bindings;
+
+COMMIT;
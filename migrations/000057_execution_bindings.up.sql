@@ -0,0 +1,12 @@
+-- ============================================================================
+-- Migration: 000057_execution_bindings
+-- Description: Credentials and variables executions were bound to when they
+--              started, kept with the version they are pinned to
+-- Schema: execution
+-- ============================================================================
+
+BEGIN;
+
+ALTER TABLE execution.workflow_executions ADD COLUMN IF NOT EXISTS bindings JSONB;
+
+COMMIT;
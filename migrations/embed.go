@@ -0,0 +1,33 @@
+// Package migrations embeds the SQL migrations so binaries can compare the
+// database schema with the version they were built for
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// Latest returns the highest migration version
+func Latest() int {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		return 0
+	}
+
+	latest := 0
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+		if version, err := strconv.Atoi(prefix); err == nil && version > latest {
+			latest = version
+		}
+	}
+	return latest
+}
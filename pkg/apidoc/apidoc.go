@@ -0,0 +1,302 @@
+// Package apidoc generates the OpenAPI 3 document of a service from the
+// routes it registers and the annotations in api/openapi, and reports where
+// the two have drifted apart.
+package apidoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	annotations "github.com/linkflow-go/api/openapi"
+	"github.com/linkflow-go/pkg/version"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the current version of the REST API
+const Version = "v1"
+
+// Prefix starts the path of every versioned REST route
+const Prefix = "/api/" + Version
+
+// SpecPath serves the document of a service
+const SpecPath = Prefix + "/openapi.json"
+
+// OpenAPIVersion is the version of the OpenAPI specification generated
+const OpenAPIVersion = "3.0.3"
+
+// Document is an OpenAPI document. It is kept untyped so annotations can
+// use any part of the specification.
+type Document map[string]interface{}
+
+// methods are the HTTP methods an OpenAPI path item may describe
+var methods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// internalPrefixes are served by every service for operators, they are not
+// part of its API
+var internalPrefixes = []string{"/health", "/metrics", "/debug/", "/api/docs", "/api/openapi.yaml", SpecPath}
+
+// pathParam matches gin path parameters, :id and *path
+var pathParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// specParam matches OpenAPI path parameters
+var specParam = regexp.MustCompile(`\{[^}]+\}`)
+
+// Drift lists the differences between the routes of a service and its
+// annotations
+type Drift struct {
+	// Undocumented routes have no annotated operation
+	Undocumented []string `json:"undocumented,omitempty"`
+	// Unrouted operations are annotated but no handler serves them
+	Unrouted []string `json:"unrouted,omitempty"`
+}
+
+// Empty reports whether routes and annotations agree
+func (d Drift) Empty() bool {
+	return len(d.Undocumented) == 0 && len(d.Unrouted) == 0
+}
+
+func (d Drift) String() string {
+	var parts []string
+	if len(d.Undocumented) > 0 {
+		parts = append(parts, "undocumented: "+strings.Join(d.Undocumented, ", "))
+	}
+	if len(d.Unrouted) > 0 {
+		parts = append(parts, "unrouted: "+strings.Join(d.Unrouted, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// LoadAnnotations reads the annotations of a service from api/openapi, nil
+// when the service has none
+func LoadAnnotations(service string) (Document, error) {
+	data, err := fs.ReadFile(annotations.FS, service+".yaml")
+	if err != nil {
+		return nil, nil
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid annotations of %s: %w", service, err)
+	}
+
+	// Decode again through JSON so nested objects are plain maps, as in the
+	// documents fetched from the services
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid annotations of %s: %w", service, err)
+	}
+	var doc Document
+	if err := json.Unmarshal(encoded, &doc); err != nil {
+		return nil, fmt.Errorf("invalid annotations of %s: %w", service, err)
+	}
+	return doc, nil
+}
+
+// Generate builds the document of a service from its routes. Annotated
+// operations are used as they are, the other routes get an operation
+// derived from their handler. Drift is only reported against annotations,
+// a service without any has nothing to drift from.
+func Generate(service string, annotated Document, routes gin.RoutesInfo) (Document, Drift) {
+	doc := Document{
+		"openapi": OpenAPIVersion,
+		"info": map[string]interface{}{
+			"title":         fmt.Sprintf("LinkFlow %s API", titleCase(service)),
+			"version":       version.Version,
+			"x-api-version": Version,
+		},
+	}
+	for key, value := range annotated {
+		if key != "paths" && key != "openapi" {
+			doc[key] = value
+		}
+	}
+	if info, ok := doc["info"].(map[string]interface{}); ok {
+		info["x-api-version"] = Version
+	}
+
+	// Annotated operations keyed by method and path with unnamed parameters
+	type operation struct {
+		path string
+		op   interface{}
+	}
+	known := make(map[string]operation)
+	annotatedPaths, _ := annotated["paths"].(map[string]interface{})
+	for path, item := range annotatedPaths {
+		ops, _ := item.(map[string]interface{})
+		for method, op := range ops {
+			if methods[method] && !internal(path) {
+				known[routeKey(method, specParam.ReplaceAllString(path, "{}"))] = operation{path, op}
+			}
+		}
+	}
+
+	var drift Drift
+	paths := make(map[string]interface{})
+	routed := make(map[string]bool)
+	operationIDs := make(map[string]int)
+	for _, route := range routes {
+		if internal(route.Path) {
+			continue
+		}
+		method := strings.ToLower(route.Method)
+		specPath := pathParam.ReplaceAllString(route.Path, "{$1}")
+		key := routeKey(method, specParam.ReplaceAllString(specPath, "{}"))
+
+		path, op := specPath, interface{}(nil)
+		if known, ok := known[key]; ok {
+			path, op = known.path, known.op
+			routed[key] = true
+		} else {
+			generatedOp := generated(service, route, specPath)
+			// Handlers shared by several routes would repeat their ID
+			id := generatedOp["operationId"].(string)
+			if operationIDs[id]++; operationIDs[id] > 1 {
+				generatedOp["operationId"] = fmt.Sprintf("%s%d", id, operationIDs[id])
+			}
+			op = generatedOp
+			if annotated != nil {
+				drift.Undocumented = append(drift.Undocumented, strings.ToUpper(method)+" "+route.Path)
+			}
+		}
+
+		item, _ := paths[path].(map[string]interface{})
+		if item == nil {
+			item = make(map[string]interface{})
+			// Keep what the annotations share across the methods of a path
+			shared, _ := annotatedPaths[path].(map[string]interface{})
+			for key, value := range shared {
+				if !methods[key] {
+					item[key] = value
+				}
+			}
+			paths[path] = item
+		}
+		item[method] = op
+	}
+	doc["paths"] = paths
+
+	for key, known := range known {
+		if !routed[key] {
+			method, _, _ := strings.Cut(key, " ")
+			drift.Unrouted = append(drift.Unrouted, strings.ToUpper(method)+" "+known.path)
+		}
+	}
+	sort.Strings(drift.Undocumented)
+	sort.Strings(drift.Unrouted)
+
+	return doc, drift
+}
+
+// generated describes a route without annotation from its handler
+func generated(service string, route gin.RouteInfo, specPath string) map[string]interface{} {
+	name := handlerName(route.Handler)
+
+	op := map[string]interface{}{
+		"operationId": lowerFirst(name),
+		"summary":     sentence(name),
+		"tags":        []string{titleCase(service)},
+		"responses": map[string]interface{}{
+			"default": map[string]interface{}{"description": "Response of " + name},
+		},
+		"x-generated": true,
+	}
+
+	var params []interface{}
+	for _, match := range specParam.FindAllString(specPath, -1) {
+		params = append(params, map[string]interface{}{
+			"name":     strings.Trim(match, "{}"),
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	return op
+}
+
+// handlerName returns the method name of a handler, e.g. GetWorkflow for
+// handlers.(*WorkflowHandlers).GetWorkflow-fm
+func handlerName(handler string) string {
+	name := handler[strings.LastIndex(handler, "/")+1:]
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	// Closures are named func1, func2, ... after their parent
+	if strings.HasPrefix(name, "func") {
+		parts := strings.Split(strings.TrimSuffix(handler, "-fm"), ".")
+		for i := len(parts) - 1; i >= 0; i-- {
+			if !strings.HasPrefix(parts[i], "func") {
+				return parts[i]
+			}
+		}
+	}
+	return name
+}
+
+func internal(path string) bool {
+	for _, prefix := range internalPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// sentence splits a CamelCase name into words, GetAPIKeyStats becomes
+// "Get API key stats"
+func sentence(name string) string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		// A word starts at an upper case letter after a lower case one, or
+		// at the last letter of an acronym followed by a lower case one
+		if !unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	words = append(words, string(runes[start:]))
+
+	for i, word := range words {
+		if i > 0 && word != strings.ToUpper(word) {
+			words[i] = strings.ToLower(word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
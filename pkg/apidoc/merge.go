@@ -0,0 +1,130 @@
+package apidoc
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/linkflow-go/pkg/version"
+)
+
+// componentKinds are renamed per service when documents are merged, two
+// services may both define a Workflow schema
+var componentKinds = []string{"schemas", "parameters", "responses", "requestBodies", "headers", "examples", "links", "callbacks"}
+
+// Merge combines the documents of the services into one. Each service owns
+// its paths, its components are prefixed with the service name and the
+// references to them rewritten. Security schemes are referenced by name, so
+// they are shared, the first service defining one wins.
+func Merge(docs map[string]Document) Document {
+	services := make([]string, 0, len(docs))
+	for service := range docs {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	paths := make(map[string]interface{})
+	components := make(map[string]interface{})
+	var tags []interface{}
+
+	for _, service := range services {
+		doc := docs[service]
+
+		// Rewrite the references before the components move
+		renames := make(map[string]string)
+		ownComponents, _ := doc["components"].(map[string]interface{})
+		for _, kind := range componentKinds {
+			entries, _ := ownComponents[kind].(map[string]interface{})
+			for name := range entries {
+				renames["#/components/"+kind+"/"+name] = "#/components/" + kind + "/" + service + "." + name
+			}
+		}
+		doc = rewriteRefs(doc, renames).(map[string]interface{})
+		ownComponents, _ = doc["components"].(map[string]interface{})
+
+		for kind, value := range ownComponents {
+			entries, _ := value.(map[string]interface{})
+			merged, _ := components[kind].(map[string]interface{})
+			if merged == nil {
+				merged = make(map[string]interface{})
+				components[kind] = merged
+			}
+			for name, entry := range entries {
+				if renamed(kind) {
+					merged[service+"."+name] = entry
+				} else if _, exists := merged[name]; !exists {
+					merged[name] = entry
+				}
+			}
+		}
+
+		docPaths, _ := doc["paths"].(map[string]interface{})
+		for path, item := range docPaths {
+			paths[path] = item
+		}
+		if docTags, ok := doc["tags"].([]interface{}); ok {
+			tags = append(tags, docTags...)
+		}
+	}
+
+	merged := Document{
+		"openapi": OpenAPIVersion,
+		"info": map[string]interface{}{
+			"title":         "LinkFlow API",
+			"version":       version.Version,
+			"x-api-version": Version,
+			"x-services":    services,
+		},
+		"paths":      paths,
+		"components": components,
+	}
+	if len(tags) > 0 {
+		merged["tags"] = tags
+	}
+	return merged
+}
+
+func renamed(kind string) bool {
+	for _, k := range componentKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// rewriteRefs returns a copy of value with the $ref values in renames
+// replaced
+func rewriteRefs(value interface{}, renames map[string]string) interface{} {
+	switch v := value.(type) {
+	case Document:
+		return rewriteRefs(map[string]interface{}(v), renames)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if ref, ok := item.(string); ok && key == "$ref" {
+				if target, ok := renames[ref]; ok {
+					out[key] = target
+					continue
+				}
+				// References into a component, e.g. .../Workflow/properties/id
+				for from, to := range renames {
+					if strings.HasPrefix(ref, from+"/") {
+						ref = to + strings.TrimPrefix(ref, from)
+						break
+					}
+				}
+				out[key] = ref
+				continue
+			}
+			out[key] = rewriteRefs(item, renames)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = rewriteRefs(item, renames)
+		}
+		return out
+	}
+	return value
+}
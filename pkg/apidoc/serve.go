@@ -0,0 +1,36 @@
+package apidoc
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/pkg/logger"
+)
+
+// Serve registers the document of the routes of router at SpecPath, so it
+// must be called once every route is registered. Drift from the annotations
+// is logged, and with strict set it is an error so the service refuses to
+// start with a spec that no longer describes it.
+func Serve(router *gin.Engine, service string, strict bool, log logger.Logger) error {
+	annotated, err := LoadAnnotations(service)
+	if err != nil {
+		return err
+	}
+
+	doc, drift := Generate(service, annotated, router.Routes())
+	if !drift.Empty() {
+		if strict {
+			return fmt.Errorf("routes of %s drifted from api/openapi/%s.yaml: %s", service, service, drift)
+		}
+		log.Warn("Routes drifted from their OpenAPI annotations",
+			"undocumented", drift.Undocumented,
+			"unrouted", drift.Unrouted,
+		)
+	}
+
+	router.GET(SpecPath, func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	})
+	return nil
+}
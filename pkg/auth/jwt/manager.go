@@ -30,11 +30,17 @@ type Claims struct {
 	Email       string   `json:"email"`
 	Roles       []string `json:"roles"`
 	Permissions []string `json:"permissions"`
+	TokenScope
 }
 
+// TokenUseRefresh marks refresh tokens so they can be told apart from
+// access tokens signed with the same key
+const TokenUseRefresh = "refresh"
+
 type RefreshClaims struct {
 	jwt.RegisteredClaims
-	UserID string `json:"userId"`
+	UserID   string `json:"userId"`
+	TokenUse string `json:"tokenUse,omitempty"`
 }
 
 func NewManager(cfg config.AuthConfig) (*Manager, error) {
@@ -78,6 +84,12 @@ func NewManager(cfg config.AuthConfig) (*Manager, error) {
 }
 
 func (m *Manager) GenerateToken(userID, email string, roles, permissions []string) (string, error) {
+	return m.GenerateScopedToken(userID, email, roles, permissions, TokenScope{})
+}
+
+// GenerateScopedToken issues an access token carrying workspace, plan and
+// quota hints for fast-path allow/deny decisions
+func (m *Manager) GenerateScopedToken(userID, email string, roles, permissions []string, scope TokenScope) (string, error) {
 	claims := Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    m.issuer,
@@ -90,6 +102,7 @@ func (m *Manager) GenerateToken(userID, email string, roles, permissions []strin
 		Email:       email,
 		Roles:       roles,
 		Permissions: permissions,
+		TokenScope:  scope,
 	}
 
 	var token *jwt.Token
@@ -112,7 +125,8 @@ func (m *Manager) GenerateRefreshToken(userID string) (string, error) {
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.refreshExpiry)),
 			ID:        uuid.New().String(),
 		},
-		UserID: userID,
+		UserID:   userID,
+		TokenUse: TokenUseRefresh,
 	}
 
 	var token *jwt.Token
@@ -156,6 +170,16 @@ func (m *Manager) ValidateToken(tokenString string) (*Claims, error) {
 }
 
 func (m *Manager) ValidateRefreshToken(tokenString string) (string, error) {
+	claims, err := m.ParseRefreshToken(tokenString)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.UserID, nil
+}
+
+// ParseRefreshToken validates a refresh token and returns its claims
+func (m *Manager) ParseRefreshToken(tokenString string) (*RefreshClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &RefreshClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Validate signing method based on configured algorithm
 		if m.algorithm == "RS256" {
@@ -173,15 +197,15 @@ func (m *Manager) ValidateRefreshToken(tokenString string) (string, error) {
 	})
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	claims, ok := token.Claims.(*RefreshClaims)
 	if !ok || !token.Valid {
-		return "", errors.New("invalid refresh token")
+		return nil, errors.New("invalid refresh token")
 	}
 
-	return claims.UserID, nil
+	return claims, nil
 }
 
 // RefreshToken generates a new access token using a valid refresh token
@@ -205,7 +229,7 @@ func (m *Manager) RefreshToken(oldToken string) (string, error) {
 	}
 
 	// Generate new token with same claims but new expiry
-	return m.GenerateToken(claims.UserID, claims.Email, claims.Roles, claims.Permissions)
+	return m.GenerateScopedToken(claims.UserID, claims.Email, claims.Roles, claims.Permissions, claims.TokenScope)
 }
 
 // LoadPrivateKey loads RSA private key from file (for production)
@@ -0,0 +1,88 @@
+package jwt
+
+import "strings"
+
+// Scopes checked by fast-path authorization at the edge
+const (
+	ScopeAll             = "*"
+	ScopeWebhooksTrigger = "webhooks:trigger"
+	ScopeExecutionsRun   = "executions:run"
+	ScopeWorkflowsRead   = "workflows:read"
+	ScopeWorkflowsWrite  = "workflows:write"
+	ScopeAPIKeysManage   = "apikeys:manage"
+)
+
+// Quota kinds carried in QuotaHints
+const (
+	QuotaWorkflows   = "workflows"
+	QuotaExecutions  = "executions"
+	QuotaAPIRequests = "apiRequests"
+	QuotaWebhooks    = "webhooks"
+)
+
+// QuotaHints carries the plan limits of a workspace so edge services can
+// deny requests the plan does not include without calling the auth service.
+// A limit of -1 means unlimited and 0 means the plan does not include it.
+// Hints are not usage counters; metered limits are still enforced upstream.
+type QuotaHints struct {
+	Workflows           int `json:"workflows"`
+	ExecutionsPerMonth  int `json:"executionsPerMonth"`
+	APIRequestsPerMonth int `json:"apiRequestsPerMonth"`
+	WebhooksPerDay      int `json:"webhooksPerDay"`
+}
+
+// Allows reports whether the plan includes the given quota kind. Missing
+// hints never deny so the decision falls back to the full check.
+func (q *QuotaHints) Allows(kind string) bool {
+	if q == nil {
+		return true
+	}
+
+	var limit int
+	switch kind {
+	case QuotaWorkflows:
+		limit = q.Workflows
+	case QuotaExecutions:
+		limit = q.ExecutionsPerMonth
+	case QuotaAPIRequests:
+		limit = q.APIRequestsPerMonth
+	case QuotaWebhooks:
+		limit = q.WebhooksPerDay
+	default:
+		return true
+	}
+
+	return limit != 0
+}
+
+// TokenScope describes the tenant, workspace, plan and scopes a token or
+// API key is issued for
+type TokenScope struct {
+	TenantID    string      `json:"tenantId,omitempty"`
+	WorkspaceID string      `json:"workspaceId,omitempty"`
+	Plan        string      `json:"plan,omitempty"`
+	Scopes      []string    `json:"scopes,omitempty"`
+	Quota       *QuotaHints `json:"quota,omitempty"`
+}
+
+// HasScope reports whether the scope is granted. Tokens issued without
+// scopes are unrestricted to stay compatible with existing tokens.
+// "resource:*" grants every action on a resource.
+func (s TokenScope) HasScope(scope string) bool {
+	if len(s.Scopes) == 0 {
+		return true
+	}
+
+	resource, _, _ := strings.Cut(scope, ":")
+	for _, granted := range s.Scopes {
+		if granted == scope || granted == ScopeAll || granted == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows combines the scope and quota checks for a fast-path decision
+func (s TokenScope) Allows(scope, quotaKind string) bool {
+	return s.HasScope(scope) && s.Quota.Allows(quotaKind)
+}
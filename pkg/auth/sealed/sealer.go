@@ -0,0 +1,72 @@
+package sealed
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrInvalidKey       = errors.New("sealing key must be 32 bytes")
+	ErrMalformedSealing = errors.New("sealed value is malformed")
+)
+
+// Sealer encrypts values at rest with AES-256-GCM under the credential
+// encryption key, in the format the local credential backend uses: the
+// base64 of the nonce followed by the ciphertext. A value sealed by one
+// service is opened by any other configured with the same key.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// NewSealer creates a sealer for a 32 byte key
+func NewSealer(key string) (*Sealer, error) {
+	if len(key) != 32 {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal encrypts plaintext
+func (s *Sealer) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := s.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value sealed with the same key
+func (s *Sealer) Open(sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", ErrMalformedSealing
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return "", ErrMalformedSealing
+	}
+
+	plaintext, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,263 @@
+// Package balancer spreads the HTTP calls of the services to each other
+// over the instances of the called service, so one bad pod does not take
+// down its callers without a service mesh in front of it. Instances are
+// resolved from DNS or the discovery registry, calls go to the less busy of
+// two random healthy instances, and instances failing too many calls in a
+// row are ejected for a while.
+package balancer
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/discovery"
+	"github.com/linkflow-go/pkg/logger"
+)
+
+// maxEjectionTime caps the ejection of an instance failing again and again
+const maxEjectionTime = 5 * time.Minute
+
+// ErrNoEndpoints is returned when a target resolves to no instance
+var ErrNoEndpoints = errors.New("no endpoints available")
+
+// Options tune the ejection of failing instances
+type Options struct {
+	RefreshInterval     time.Duration
+	ConsecutiveFailures int
+	EjectionTime        time.Duration
+	MaxEjectionPercent  int
+}
+
+// Endpoint is an instance of a target, host:port
+type Endpoint struct {
+	Addr string
+
+	inflight     int
+	failures     int
+	ejections    int
+	ejectedUntil time.Time
+}
+
+// target are the instances behind a host:port the clients call
+type target struct {
+	endpoints  []*Endpoint
+	resolvedAt time.Time
+	refreshing bool
+}
+
+// Balancer picks the instance of each call and tracks how they fare
+type Balancer struct {
+	resolver Resolver
+	opts     Options
+	logger   logger.Logger
+
+	mu      sync.Mutex
+	targets map[string]*target
+}
+
+// New creates a balancer resolving targets with resolver
+func New(resolver Resolver, opts Options, log logger.Logger) *Balancer {
+	return &Balancer{
+		resolver: resolver,
+		opts:     opts,
+		logger:   log,
+		targets:  make(map[string]*target),
+	}
+}
+
+// FromConfig creates the balancer of a load balancing config, the registry
+// resolver reads the instances announced in d
+func FromConfig(cfg config.LoadBalancingConfig, d discovery.ServiceDiscovery, log logger.Logger) *Balancer {
+	var resolver Resolver = NewDNSResolver()
+	if cfg.Resolver == "registry" {
+		resolver = NewRegistryResolver(d)
+	}
+	return New(resolver, Options{
+		RefreshInterval:     time.Duration(cfg.RefreshInterval) * time.Second,
+		ConsecutiveFailures: cfg.ConsecutiveFailures,
+		EjectionTime:        time.Duration(cfg.EjectionTime) * time.Second,
+		MaxEjectionPercent:  cfg.MaxEjectionPercent,
+	}, log)
+}
+
+// Pick returns the instance to call for hostport, skipping the instances
+// in exclude. Callers must Done the endpoint once the call is over.
+func (b *Balancer) Pick(ctx context.Context, hostport string, exclude ...string) (*Endpoint, error) {
+	b.mu.Lock()
+	t, ok := b.targets[hostport]
+	b.mu.Unlock()
+	if !ok {
+		var err error
+		if t, err = b.resolve(ctx, hostport); err != nil {
+			return nil, err
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if time.Since(t.resolvedAt) > b.opts.RefreshInterval && !t.refreshing {
+		t.refreshing = true
+		go b.refresh(hostport)
+	}
+
+	now := time.Now()
+	var healthy, rest []*Endpoint
+	for _, ep := range t.endpoints {
+		if excluded(ep.Addr, exclude) {
+			continue
+		}
+		if ep.ejectedUntil.After(now) {
+			rest = append(rest, ep)
+			continue
+		}
+		healthy = append(healthy, ep)
+	}
+	// Calling an ejected instance beats failing the call outright
+	if len(healthy) == 0 {
+		healthy = rest
+	}
+	if len(healthy) == 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	// The less busy of two random instances, which avoids herding onto
+	// the single least busy one
+	ep := healthy[rand.IntN(len(healthy))]
+	if len(healthy) > 1 {
+		if other := healthy[rand.IntN(len(healthy))]; other.inflight < ep.inflight {
+			ep = other
+		}
+	}
+	ep.inflight++
+	return ep, nil
+}
+
+// Done records the outcome of a call to an endpoint picked for hostport.
+// Enough failures in a row eject the instance, a success clears its record.
+func (b *Balancer) Done(hostport string, ep *Endpoint, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ep.inflight--
+	if !failed {
+		ep.failures = 0
+		if ep.ejectedUntil.Before(time.Now()) {
+			ep.ejections = 0
+		}
+		return
+	}
+
+	ep.failures++
+	if ep.failures < b.opts.ConsecutiveFailures || ep.ejectedUntil.After(time.Now()) {
+		return
+	}
+
+	t := b.targets[hostport]
+	if t == nil || !b.canEject(t) {
+		return
+	}
+
+	ep.ejections++
+	ep.failures = 0
+	ejection := min(b.opts.EjectionTime*time.Duration(ep.ejections), maxEjectionTime)
+	ep.ejectedUntil = time.Now().Add(ejection)
+	b.logger.Warn("Ejected failing instance",
+		"target", hostport,
+		"instance", ep.Addr,
+		"ejection", ejection.String(),
+	)
+}
+
+// canEject reports whether one more instance of a target may be ejected.
+// Callers must hold b.mu.
+func (b *Balancer) canEject(t *target) bool {
+	now := time.Now()
+	ejected := 0
+	for _, ep := range t.endpoints {
+		if ep.ejectedUntil.After(now) {
+			ejected++
+		}
+	}
+	return (ejected+1)*100 <= len(t.endpoints)*b.opts.MaxEjectionPercent
+}
+
+// resolve looks up the instances of a target seen for the first time
+func (b *Balancer) resolve(ctx context.Context, hostport string) (*target, error) {
+	addrs, err := b.resolver.Resolve(ctx, hostport)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Another call may have resolved it meanwhile
+	if t, ok := b.targets[hostport]; ok {
+		return t, nil
+	}
+	t := &target{resolvedAt: time.Now()}
+	for _, addr := range addrs {
+		t.endpoints = append(t.endpoints, &Endpoint{Addr: addr})
+	}
+	b.targets[hostport] = t
+	return t, nil
+}
+
+// refresh re-resolves the instances of a target, keeping the record of
+// the instances still there. Failing lookups keep the known instances.
+func (b *Balancer) refresh(hostport string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addrs, err := b.resolver.Resolve(ctx, hostport)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.targets[hostport]
+	t.refreshing = false
+	t.resolvedAt = time.Now()
+	if err != nil || len(addrs) == 0 {
+		if err != nil {
+			b.logger.Warn("Failed to resolve instances", "target", hostport, "error", err)
+		}
+		return
+	}
+
+	known := make(map[string]*Endpoint, len(t.endpoints))
+	for _, ep := range t.endpoints {
+		known[ep.Addr] = ep
+	}
+	endpoints := make([]*Endpoint, 0, len(addrs))
+	for _, addr := range addrs {
+		if ep, ok := known[addr]; ok {
+			endpoints = append(endpoints, ep)
+			continue
+		}
+		endpoints = append(endpoints, &Endpoint{Addr: addr})
+	}
+	t.endpoints = endpoints
+}
+
+func excluded(addr string, exclude []string) bool {
+	for _, e := range exclude {
+		if e == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// splitHostPort splits a target, a missing port is left empty
+func splitHostPort(hostport string) (string, string) {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return hostport, ""
+	}
+	return host, port
+}
@@ -0,0 +1,90 @@
+package balancer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/linkflow-go/pkg/discovery"
+)
+
+// Resolver returns the instances, host:port, behind a target the clients
+// call
+type Resolver interface {
+	Resolve(ctx context.Context, hostport string) ([]string, error)
+}
+
+// DNSResolver returns every address the host name of a target resolves
+// to, one per pod behind a headless Service. A host name resolving to a
+// single virtual IP is called as before.
+type DNSResolver struct {
+	resolver *net.Resolver
+}
+
+// NewDNSResolver creates a resolver using the resolver of the system
+func NewDNSResolver() *DNSResolver {
+	return &DNSResolver{resolver: net.DefaultResolver}
+}
+
+func (r *DNSResolver) Resolve(ctx context.Context, hostport string) ([]string, error) {
+	host, port := splitHostPort(hostport)
+	if net.ParseIP(host) != nil {
+		return []string{hostport}, nil
+	}
+
+	ips, err := r.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+
+	addrs := make([]string, len(ips))
+	for i, ip := range ips {
+		addrs[i] = join(ip, port)
+	}
+	return addrs, nil
+}
+
+// RegistryResolver returns the instances announced under the host name of
+// a target, such as execution-service. Targets without announced instances
+// are called as given.
+type RegistryResolver struct {
+	discovery discovery.ServiceDiscovery
+}
+
+// NewRegistryResolver creates a resolver reading the instances of d
+func NewRegistryResolver(d discovery.ServiceDiscovery) *RegistryResolver {
+	return &RegistryResolver{discovery: d}
+}
+
+func (r *RegistryResolver) Resolve(ctx context.Context, hostport string) ([]string, error) {
+	host, _ := splitHostPort(hostport)
+	instances, err := r.discovery.Discover(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []string
+	for _, instance := range instances {
+		if instance.Health != discovery.HealthHealthy {
+			continue
+		}
+		// Pod IPs are routable where pod host names are not
+		address := instance.Metadata[discovery.MetadataAddress]
+		if address == "" {
+			address = instance.Host
+		}
+		addrs = append(addrs, join(address, strconv.Itoa(instance.Port)))
+	}
+	if len(addrs) == 0 {
+		return []string{hostport}, nil
+	}
+	return addrs, nil
+}
+
+func join(host, port string) string {
+	if port == "" {
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
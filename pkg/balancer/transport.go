@@ -0,0 +1,70 @@
+package balancer
+
+import (
+	"net/http"
+)
+
+// Transport sends each request to an instance picked by a balancer. An
+// idempotent request failing to reach its instance is sent once more to
+// another one.
+type Transport struct {
+	base     http.RoundTripper
+	balancer *Balancer
+}
+
+// NewTransport wraps base, http.DefaultTransport when nil
+func NewTransport(b *Balancer, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{base: base, balancer: b}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	hostport := req.URL.Host
+
+	var tried []string
+	for attempt := 0; ; attempt++ {
+		ep, err := t.balancer.Pick(req.Context(), hostport, tried...)
+		if err != nil {
+			if attempt > 0 {
+				return nil, err
+			}
+			// Unresolvable targets are left to the base transport
+			return t.base.RoundTrip(req)
+		}
+		tried = append(tried, ep.Addr)
+
+		// Requests must not be modified by a RoundTripper, send a clone.
+		// The Host header keeps naming the service.
+		out := req.Clone(req.Context())
+		out.URL.Host = ep.Addr
+		if out.Host == "" {
+			out.Host = hostport
+		}
+		if attempt > 0 && req.GetBody != nil {
+			if out.Body, err = req.GetBody(); err != nil {
+				t.balancer.Done(hostport, ep, false)
+				return nil, err
+			}
+		}
+
+		resp, err := t.base.RoundTrip(out)
+		canceled := req.Context().Err() != nil
+		t.balancer.Done(hostport, ep, !canceled && (err != nil || resp.StatusCode >= http.StatusInternalServerError))
+		if err == nil || canceled || attempt > 0 || !replayable(req) {
+			return resp, err
+		}
+	}
+}
+
+// replayable reports whether a failed request can be sent again
+func replayable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
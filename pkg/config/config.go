@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/linkflow-go/pkg/database"
 	"github.com/linkflow-go/pkg/events"
@@ -19,6 +22,286 @@ type Config struct {
 	Telemetry     TelemetryConfig     `mapstructure:"telemetry"`
 	Logger        LoggerConfig        `mapstructure:"logger"`
 	Elasticsearch ElasticsearchConfig `mapstructure:"elasticsearch"`
+	Executor      ExecutorConfig      `mapstructure:"executor"`
+	Execution     ExecutionConfig     `mapstructure:"execution"`
+	RateLimit     RateLimitConfig     `mapstructure:"rate_limit"`
+	Reload        ReloadConfig        `mapstructure:"reload"`
+	Credential    CredentialConfig    `mapstructure:"credential"`
+	Storage       StorageConfig       `mapstructure:"storage"`
+	Gateway       GatewayConfig       `mapstructure:"gateway"`
+	RPC           RPCConfig           `mapstructure:"rpc"`
+	LoadBalancing LoadBalancingConfig `mapstructure:"load_balancing"`
+	Quota         QuotaConfig         `mapstructure:"quota"`
+	Billing       BillingConfig       `mapstructure:"billing"`
+	Notification  NotificationConfig  `mapstructure:"notification"`
+	GitSync       GitSyncConfig       `mapstructure:"git_sync"`
+}
+
+// NotificationConfig tunes the delivery of notifications
+type NotificationConfig struct {
+	// DigestInterval is how often the digests due are delivered, in
+	// seconds
+	DigestInterval int `mapstructure:"digest_interval"`
+	// RetentionDays keeps each notification this many days, and
+	// ReadRetentionDays those read or delivered within a digest
+	RetentionDays     int `mapstructure:"retention_days"`
+	ReadRetentionDays int `mapstructure:"read_retention_days"`
+	// PruneInterval is how often notifications past their retention are
+	// removed, in seconds
+	PruneInterval int `mapstructure:"prune_interval"`
+	// ActivityDigestInterval is how often the activity digests due are
+	// emailed, in seconds
+	ActivityDigestInterval int `mapstructure:"activity_digest_interval"`
+	// Incidents pages critical events to the on-call of the platform
+	Incidents IncidentConfig `mapstructure:"incidents"`
+}
+
+// IncidentConfig routes the critical events of the platform to PagerDuty
+// or Opsgenie, each paged when its key is set. Owners of workflows route
+// the incidents of their workflows through their own notification
+// channels besides.
+type IncidentConfig struct {
+	PagerDutyRoutingKey string `mapstructure:"pagerduty_routing_key"`
+	PagerDutyRegion     string `mapstructure:"pagerduty_region"`
+	OpsgenieAPIKey      string `mapstructure:"opsgenie_api_key"`
+	OpsgenieRegion      string `mapstructure:"opsgenie_region"`
+	// FailureThreshold is how many executions of a workflow failing in a
+	// row open an incident
+	FailureThreshold int `mapstructure:"failure_threshold"`
+}
+
+// QuotaConfig bounds what each tenant uses by its plan. Tenants are
+// assigned a plan through the admin API, tenants without one get
+// DefaultPlan.
+type QuotaConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Enforce refuses work over a limit, otherwise it is only warned about
+	Enforce bool `mapstructure:"enforce"`
+	// WarnPercent of a limit warns that a tenant nears it
+	WarnPercent int    `mapstructure:"warn_percent"`
+	DefaultPlan string `mapstructure:"default_plan"`
+	// StorageInterval is how often the stored execution data of each
+	// tenant is measured, in seconds
+	StorageInterval int                  `mapstructure:"storage_interval"`
+	Plans           map[string]QuotaPlan `mapstructure:"plans"`
+}
+
+// QuotaPlan bounds the usage of the tenants of a plan, 0 is unlimited
+type QuotaPlan struct {
+	ActiveWorkflows      int `mapstructure:"active_workflows" json:"activeWorkflows"`
+	ExecutionsPerMonth   int `mapstructure:"executions_per_month" json:"executionsPerMonth"`
+	ConcurrentExecutions int `mapstructure:"concurrent_executions" json:"concurrentExecutions"`
+	StorageMB            int `mapstructure:"storage_mb" json:"storageMb"`
+}
+
+// BillingConfig charges executions by the resources they use. The
+// execution service prices each completed execution, the billing service
+// invoices the priced executions of each subscription per period.
+type BillingConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Currency string `mapstructure:"currency"`
+	// Unit prices of the cost model
+	ComputeCostPerSecond float64 `mapstructure:"compute_cost_per_second"`
+	MemoryCostPerGB      float64 `mapstructure:"memory_cost_per_gb"`
+	StorageCostPerGB     float64 `mapstructure:"storage_cost_per_gb"`
+	NetworkCostPerGB     float64 `mapstructure:"network_cost_per_gb"`
+	APICallCost          float64 `mapstructure:"api_call_cost"`
+	DatabaseQueryCost    float64 `mapstructure:"database_query_cost"`
+	// InvoiceInterval is how often ended periods are invoiced and usage is
+	// reported to Stripe, in seconds
+	InvoiceInterval int `mapstructure:"invoice_interval"`
+	// CostRollupInterval is how often the execution costs are summed into
+	// the daily rollups cost reports read, in seconds
+	CostRollupInterval int          `mapstructure:"cost_rollup_interval"`
+	Stripe             StripeConfig `mapstructure:"stripe"`
+}
+
+// StripeConfig reports the usage of Stripe subscriptions, which Stripe
+// invoices itself
+type StripeConfig struct {
+	SecretKey     string `mapstructure:"secret_key"`
+	WebhookSecret string `mapstructure:"webhook_secret"`
+}
+
+// LoadBalancingConfig spreads the HTTP calls of the services to each other
+// over the instances of the called service, ejecting the instances that
+// keep failing
+type LoadBalancingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Resolver finds the instances of a service: dns looks up every address
+	// of its host name (a headless Service), registry reads the instances
+	// the services announce in Redis
+	Resolver string `mapstructure:"resolver"`
+	// RefreshInterval re-resolves the instances every this many seconds
+	RefreshInterval int `mapstructure:"refresh_interval"`
+	// ConsecutiveFailures ejects an instance after this many failed calls
+	// in a row
+	ConsecutiveFailures int `mapstructure:"consecutive_failures"`
+	// EjectionTime is the seconds of the first ejection of an instance,
+	// each ejection in a row lasts longer
+	EjectionTime int `mapstructure:"ejection_time"`
+	// MaxEjectionPercent bounds the share of the instances of a service
+	// ejected at once
+	MaxEjectionPercent int `mapstructure:"max_ejection_percent"`
+}
+
+// RPCConfig configures the gRPC APIs the services call each other through
+type RPCConfig struct {
+	// Port serves the API of this service, 0 leaves it off
+	Port int `mapstructure:"port"`
+	// Token is shared by the services, calls without it are refused
+	Token string `mapstructure:"token"`
+	// Services are the host:port of the APIs this service calls, keyed by
+	// service name such as credential. Services left out are not called
+	// over gRPC.
+	Services map[string]string `mapstructure:"services"`
+}
+
+// GatewayConfig overrides the base URLs of the services the gateway calls,
+// keyed by service name such as workflow. An empty URL leaves the service
+// out.
+type GatewayConfig struct {
+	Services map[string]string `mapstructure:"services"`
+	// CacheTTL keeps the responses of cached routes for this many seconds,
+	// 0 leaves the routes off
+	CacheTTL  int                    `mapstructure:"cache_ttl"`
+	RateLimit GatewayRateLimitConfig `mapstructure:"rate_limit"`
+	Security  GatewaySecurityConfig  `mapstructure:"security"`
+}
+
+// GatewaySecurityConfig hardens the gateway for the browsers calling it
+type GatewaySecurityConfig struct {
+	CORS    CORSConfig            `mapstructure:"cors"`
+	CSRF    CSRFConfig            `mapstructure:"csrf"`
+	Headers SecurityHeadersConfig `mapstructure:"headers"`
+}
+
+// CORSConfig selects the origins whose pages may call the API
+type CORSConfig struct {
+	// AllowedOrigins are exact origins, "*" for any or wildcard subdomains
+	// such as https://*.example.com
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
+	AllowedMethods []string `mapstructure:"allowed_methods"`
+	AllowedHeaders []string `mapstructure:"allowed_headers"`
+	ExposedHeaders []string `mapstructure:"exposed_headers"`
+	// AllowCredentials lets pages send cookies, never with any origin
+	AllowCredentials bool `mapstructure:"allow_credentials"`
+	// MaxAge caches preflight responses for this many seconds
+	MaxAge int `mapstructure:"max_age"`
+}
+
+// CSRFConfig protects the sessions held in cookies with a double submit
+// token. Requests authenticated by the Authorization header are not
+// exposed to CSRF and are let through.
+type CSRFConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// SessionCookies name the cookies authenticating a request
+	SessionCookies []string `mapstructure:"session_cookies"`
+	CookieName     string   `mapstructure:"cookie_name"`
+	HeaderName     string   `mapstructure:"header_name"`
+	// Secure sends the token cookie over HTTPS only
+	Secure bool `mapstructure:"secure"`
+}
+
+// SecurityHeadersConfig sets the standard security headers of responses,
+// empty values leave a header out
+type SecurityHeadersConfig struct {
+	Enabled               bool   `mapstructure:"enabled"`
+	ContentSecurityPolicy string `mapstructure:"content_security_policy"`
+	FrameOptions          string `mapstructure:"frame_options"`
+	ReferrerPolicy        string `mapstructure:"referrer_policy"`
+	// HSTSMaxAge is the Strict-Transport-Security max-age in seconds, 0
+	// leaves HSTS off
+	HSTSMaxAge int `mapstructure:"hsts_max_age"`
+}
+
+// GatewayRateLimitConfig limits the requests each client sends through the
+// gateway, by the plan of its user. Anonymous clients are limited by IP
+// address under the anonymous plan, users of an unknown plan get free.
+type GatewayRateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Plans are the default limits, the admin API overrides them
+	Plans map[string]RateLimitPlan `mapstructure:"plans"`
+}
+
+// RateLimitPlan limits the requests of a client, 0 is unlimited
+type RateLimitPlan struct {
+	RequestsPerMinute int `mapstructure:"requests_per_minute" json:"requestsPerMinute"`
+	// RequestsPerDay is the daily quota
+	RequestsPerDay int `mapstructure:"requests_per_day" json:"requestsPerDay"`
+}
+
+// StorageConfig addresses the S3 compatible object storage, credentials
+// come from the default AWS chain
+type StorageConfig struct {
+	Endpoint string `mapstructure:"endpoint"`
+	Region   string `mapstructure:"region"`
+}
+
+// CredentialConfig selects where the sensitive fields of credentials are
+// kept, encrypted in the database (local) or in HashiCorp Vault (vault)
+type CredentialConfig struct {
+	Backend       string      `mapstructure:"backend"`
+	EncryptionKey string      `mapstructure:"encryption_key"` // 32 bytes, AES-256
+	Vault         VaultConfig `mapstructure:"vault"`
+}
+
+// VaultConfig addresses the KV v2 secrets engine of a HashiCorp Vault
+type VaultConfig struct {
+	Address string `mapstructure:"address"`
+	Token   string `mapstructure:"token"`
+	Mount   string `mapstructure:"mount"`
+	Path    string `mapstructure:"path"`
+}
+
+// ExecutorConfig sizes the node executor worker pool, reloadable
+type ExecutorConfig struct {
+	Workers int `mapstructure:"workers"` // 0 sizes the pool by CPU count
+}
+
+// ExecutionConfig tunes the execution service
+type ExecutionConfig struct {
+	// LogRetentionDays keeps the log lines of an execution this many days
+	LogRetentionDays int `mapstructure:"log_retention_days"`
+	// SamplingGrace is how long a successful execution dropped by the
+	// sampling policy of its workflow stays stored, in seconds
+	SamplingGrace int `mapstructure:"sampling_grace"`
+	// SamplingPruneInterval is how often dropped executions are deleted,
+	// in seconds
+	SamplingPruneInterval int `mapstructure:"sampling_prune_interval"`
+	// SLAInterval is how often the SLAs of the workflows are evaluated, in
+	// seconds
+	SLAInterval int `mapstructure:"sla_interval"`
+	// MaxParallelNodes bounds how many nodes of an execution run at once,
+	// independent branches run side by side up to it
+	MaxParallelNodes int `mapstructure:"max_parallel_nodes"`
+	// PayloadBucket keeps the node data spilled to the object storage,
+	// empty passes all data inline
+	PayloadBucket string `mapstructure:"payload_bucket"`
+	// PayloadSpillBytes is the size from which a field of node data is
+	// spilled to the payload bucket and passed by reference, 0 never spills
+	PayloadSpillBytes int64 `mapstructure:"payload_spill_bytes"`
+}
+
+// GitSyncConfig tunes the sync of workflows with Git repositories
+type GitSyncConfig struct {
+	// WorkDir holds a checkout per connected workflow
+	WorkDir string `mapstructure:"work_dir"`
+	// Timeout bounds each git command, in seconds
+	Timeout int `mapstructure:"timeout"`
+}
+
+// RateLimitConfig holds request limits that can be tuned without a restart
+type RateLimitConfig struct {
+	LoginAttempts int `mapstructure:"login_attempts"`
+	LoginWindow   int `mapstructure:"login_window"` // seconds
+}
+
+// ReloadConfig controls how a running service picks up config changes.
+// SIGHUP always triggers a reload, WatchFile also reloads when the config
+// file changes on disk
+type ReloadConfig struct {
+	WatchFile bool `mapstructure:"watch_file"`
 }
 
 type ElasticsearchConfig struct {
@@ -33,9 +316,22 @@ type ServerConfig struct {
 	ReadTimeout     int    `mapstructure:"read_timeout"`
 	WriteTimeout    int    `mapstructure:"write_timeout"`
 	ShutdownTimeout int    `mapstructure:"shutdown_timeout"`
+	// TriggerDrainTimeout bounds how long the workflow service waits on
+	// shutdown for in-flight trigger fires, in seconds
+	TriggerDrainTimeout int `mapstructure:"trigger_drain_timeout"`
+	// MinScheduleInterval is the shortest interval between the runs of a
+	// cron trigger workflow linting allows, in seconds
+	MinScheduleInterval int    `mapstructure:"min_schedule_interval"`
+	AdminPort           int    `mapstructure:"admin_port"`
+	AdminToken          string `mapstructure:"admin_token"`
+	// StrictAPISpec refuses to start when the routes drift from their
+	// OpenAPI annotations
+	StrictAPISpec bool `mapstructure:"strict_api_spec"`
 }
 
 type DatabaseConfig struct {
+	Driver       string `mapstructure:"driver"` // postgres or sqlite
+	Path         string `mapstructure:"path"`   // sqlite only
 	Host         string `mapstructure:"host"`
 	Port         int    `mapstructure:"port"`
 	User         string `mapstructure:"user"`
@@ -52,21 +348,92 @@ type RedisConfig struct {
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
 	PoolSize int    `mapstructure:"pool_size"`
+	// GCInterval is how often orphaned keys are reclaimed, in seconds
+	GCInterval int `mapstructure:"gc_interval"`
+	// TenantQuota bounds the Redis usage of each tenant
+	TenantQuota TenantQuotaConfig `mapstructure:"tenant_quota"`
+}
+
+// TenantQuotaConfig bounds the commands and memory of the keys of each
+// tenant, zero limits are unbounded
+type TenantQuotaConfig struct {
+	// OpsPerSecond bounds the commands on the keys of a tenant per replica
+	OpsPerSecond int `mapstructure:"ops_per_second"`
+	// MaxMemoryMB bounds the memory of the keys of a tenant, writes of a
+	// tenant over it are refused
+	MaxMemoryMB int `mapstructure:"max_memory_mb"`
+	// UsageInterval is how often the memory of the tenants is measured, in
+	// seconds
+	UsageInterval int `mapstructure:"usage_interval"`
 }
 
 type KafkaConfig struct {
+	// Backend is kafka, nats for NATS JetStream, or memory to deliver
+	// events within the process
+	Backend       string   `mapstructure:"backend"`
 	Brokers       []string `mapstructure:"brokers"`
 	ConsumerGroup string   `mapstructure:"consumer_group"`
 	Topic         string   `mapstructure:"topic"`
+	// Consumer is the name of the service, set by Load
+	Consumer string     `mapstructure:"consumer"`
+	NATS     NATSConfig `mapstructure:"nats"`
+}
+
+// NATSConfig configures the nats event bus backend
+type NATSConfig struct {
+	URL           string `mapstructure:"url"`
+	SubjectPrefix string `mapstructure:"subject_prefix"`
+	// AckWait is in seconds
+	AckWait    int `mapstructure:"ack_wait"`
+	MaxDeliver int `mapstructure:"max_deliver"`
+	// Stream configures the stream of each event domain, such as workflow
+	// or execution, Streams overrides it per domain
+	Stream  NATSStreamConfig            `mapstructure:"stream"`
+	Streams map[string]NATSStreamConfig `mapstructure:"streams"`
+}
+
+// NATSStreamConfig configures a JetStream stream, zero fields of the
+// overrides in Streams take the value of Stream
+type NATSStreamConfig struct {
+	// MaxAge is in seconds
+	MaxAge   int    `mapstructure:"max_age"`
+	MaxBytes int64  `mapstructure:"max_bytes"`
+	Replicas int    `mapstructure:"replicas"`
+	Storage  string `mapstructure:"storage"`
 }
 
 type AuthConfig struct {
-	JWTSecret      string    `mapstructure:"jwt_secret"`
-	JWTExpiry      int       `mapstructure:"jwt_expiry"`
-	RefreshExpiry  int       `mapstructure:"refresh_expiry"`
-	PrivateKeyPath string    `mapstructure:"private_key_path"`
-	PublicKeyPath  string    `mapstructure:"public_key_path"`
-	JWT            JWTConfig `mapstructure:"jwt"`
+	JWTSecret      string          `mapstructure:"jwt_secret"`
+	JWTExpiry      int             `mapstructure:"jwt_expiry"`
+	RefreshExpiry  int             `mapstructure:"refresh_expiry"`
+	PrivateKeyPath string          `mapstructure:"private_key_path"`
+	PublicKeyPath  string          `mapstructure:"public_key_path"`
+	JWT            JWTConfig       `mapstructure:"jwt"`
+	SignedURL      SignedURLConfig `mapstructure:"signed_url"`
+	// EvidenceKey signs the manifests of execution evidence bundles
+	EvidenceKey string `mapstructure:"evidence_key"`
+	// TemplateBundles signs the templates exported and verifies those
+	// imported from other deployments
+	TemplateBundles TemplateBundleConfig `mapstructure:"template_bundles"`
+	// IsolateSignups gives each user registering a tenant of their own,
+	// otherwise they join the default tenant
+	IsolateSignups bool `mapstructure:"isolate_signups"`
+}
+
+// TemplateBundleConfig holds the Ed25519 keys of template bundles, base64
+// encoded. Bundles are signed with SigningKey, a private key, and imported
+// when signed by one of TrustedKeys or by SigningKey itself.
+type TemplateBundleConfig struct {
+	SigningKey  string   `mapstructure:"signing_key"`
+	TrustedKeys []string `mapstructure:"trusted_keys"`
+}
+
+// SignedURLConfig configures time-limited download links for exports and
+// artifacts
+type SignedURLConfig struct {
+	SecretKey     string `mapstructure:"secret_key"`
+	BaseURL       string `mapstructure:"base_url"`
+	ExpiryMinutes int    `mapstructure:"expiry_minutes"`
 }
 
 type JWTConfig struct {
@@ -79,7 +446,10 @@ type JWTConfig struct {
 
 type TelemetryConfig struct {
 	Enabled      bool    `mapstructure:"enabled"`
+	Exporter     string  `mapstructure:"exporter"` // otlp or jaeger
 	JaegerURL    string  `mapstructure:"jaeger_url"`
+	OTLPEndpoint string  `mapstructure:"otlp_endpoint"`
+	OTLPInsecure bool    `mapstructure:"otlp_insecure"`
 	ServiceName  string  `mapstructure:"service_name"`
 	SamplingRate float64 `mapstructure:"sampling_rate"`
 }
@@ -90,8 +460,18 @@ type LoggerConfig struct {
 	Output     string `mapstructure:"output"`
 	AddCaller  bool   `mapstructure:"add_caller"`
 	Stacktrace bool   `mapstructure:"stacktrace"`
+	// Service is set by Load to the name of the service
+	Service string `mapstructure:"service"`
+	// Sinks selects stdout, file, loki and elasticsearch outputs, empty
+	// writes to Output
+	Sinks         []string                   `mapstructure:"sinks"`
+	File          logger.FileConfig          `mapstructure:"file"`
+	Loki          logger.LokiConfig          `mapstructure:"loki"`
+	Elasticsearch logger.ElasticsearchConfig `mapstructure:"elasticsearch"`
 }
 
+// Load reads the config of a service from its YAML file, defaults and
+// LINKFLOW_ prefixed environment variables and validates the result
 func Load(serviceName string) (*Config, error) {
 	viper.SetConfigName(serviceName)
 	viper.SetConfigType("yaml")
@@ -100,13 +480,33 @@ func Load(serviceName string) (*Config, error) {
 
 	// Set defaults
 	setDefaults()
+	viper.Set("logger.service", serviceName)
+	viper.Set("kafka.consumer", serviceName)
 
 	// Enable environment variables
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.SetEnvPrefix("LINKFLOW")
 
-	// Read config file
+	config, err := read()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// FileUsed returns the config file Load read, empty if none was found
+func FileUsed() string {
+	return viper.ConfigFileUsed()
+}
+
+// read loads the config from the file and environment configured on viper
+func read() (*Config, error) {
 	if err := viper.ReadInConfig(); err != nil {
 		// It's okay if config file doesn't exist, we'll use defaults and env vars
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -122,6 +522,11 @@ func Load(serviceName string) (*Config, error) {
 	// Override with environment variables
 	overrideFromEnv(&config)
 
+	// Replace env://, file:// and vault:// references with their secrets
+	if err := resolveSecrets(&config); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
@@ -132,8 +537,86 @@ func setDefaults() {
 	viper.SetDefault("server.read_timeout", 30)
 	viper.SetDefault("server.write_timeout", 30)
 	viper.SetDefault("server.shutdown_timeout", 30)
+	viper.SetDefault("server.trigger_drain_timeout", 10)
+	viper.SetDefault("server.min_schedule_interval", 300)
+	viper.SetDefault("server.admin_port", 0)
+	viper.SetDefault("server.admin_token", "")
+	viper.SetDefault("server.strict_api_spec", false)
+
+	// Gateway defaults
+	viper.SetDefault("gateway.cache_ttl", 60)
+	viper.SetDefault("gateway.rate_limit.enabled", true)
+	viper.SetDefault("gateway.rate_limit.plans", map[string]interface{}{
+		"anonymous":  map[string]interface{}{"requests_per_minute": 60, "requests_per_day": 1000},
+		"free":       map[string]interface{}{"requests_per_minute": 120, "requests_per_day": 10000},
+		"basic":      map[string]interface{}{"requests_per_minute": 600, "requests_per_day": 100000},
+		"premium":    map[string]interface{}{"requests_per_minute": 3000, "requests_per_day": 1000000},
+		"enterprise": map[string]interface{}{"requests_per_minute": 0, "requests_per_day": 0},
+	})
+	viper.SetDefault("gateway.security.cors.allowed_origins", []string{"*"})
+	viper.SetDefault("gateway.security.cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	viper.SetDefault("gateway.security.cors.allowed_headers", []string{
+		"Authorization", "Content-Type", "Accept", "Cache-Control", "If-None-Match",
+		"Idempotency-Key", "X-API-Key", "X-CSRF-Token", "X-Request-ID", "X-Requested-With",
+	})
+	viper.SetDefault("gateway.security.cors.exposed_headers", []string{
+		"ETag", "Retry-After", "X-Request-ID", "X-Cache",
+		"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset",
+		"X-Quota-Limit", "X-Quota-Remaining", "X-Quota-Reset",
+	})
+	viper.SetDefault("gateway.security.cors.allow_credentials", false)
+	viper.SetDefault("gateway.security.cors.max_age", 600)
+	viper.SetDefault("gateway.security.csrf.enabled", true)
+	viper.SetDefault("gateway.security.csrf.session_cookies", []string{"session", "access_token"})
+	viper.SetDefault("gateway.security.csrf.cookie_name", "csrf_token")
+	viper.SetDefault("gateway.security.csrf.header_name", "X-CSRF-Token")
+	viper.SetDefault("gateway.security.csrf.secure", true)
+	viper.SetDefault("gateway.security.headers.enabled", true)
+	viper.SetDefault("gateway.security.headers.content_security_policy", "default-src 'none'; frame-ancestors 'none'")
+	viper.SetDefault("gateway.security.headers.frame_options", "DENY")
+	viper.SetDefault("gateway.security.headers.referrer_policy", "no-referrer")
+	viper.SetDefault("gateway.security.headers.hsts_max_age", 31536000)
+
+	// Tenant quota defaults
+	viper.SetDefault("quota.enabled", false)
+	viper.SetDefault("quota.enforce", true)
+	viper.SetDefault("quota.warn_percent", 80)
+	viper.SetDefault("quota.default_plan", "free")
+	viper.SetDefault("quota.storage_interval", 600) // 10 minutes
+	viper.SetDefault("quota.plans", map[string]interface{}{
+		"free":       map[string]interface{}{"active_workflows": 5, "executions_per_month": 1000, "concurrent_executions": 2, "storage_mb": 100},
+		"basic":      map[string]interface{}{"active_workflows": 20, "executions_per_month": 10000, "concurrent_executions": 10, "storage_mb": 1024},
+		"premium":    map[string]interface{}{"active_workflows": 100, "executions_per_month": 100000, "concurrent_executions": 50, "storage_mb": 10240},
+		"enterprise": map[string]interface{}{"active_workflows": 0, "executions_per_month": 0, "concurrent_executions": 0, "storage_mb": 0},
+	})
+
+	// Billing defaults
+	viper.SetDefault("billing.enabled", false)
+	viper.SetDefault("billing.currency", "USD")
+	viper.SetDefault("billing.compute_cost_per_second", 0.0001)
+	viper.SetDefault("billing.memory_cost_per_gb", 0.001)
+	viper.SetDefault("billing.storage_cost_per_gb", 0.0005)
+	viper.SetDefault("billing.network_cost_per_gb", 0.01)
+	viper.SetDefault("billing.api_call_cost", 0.00001)
+	viper.SetDefault("billing.database_query_cost", 0.000001)
+	viper.SetDefault("billing.invoice_interval", 3600)    // 1 hour
+	viper.SetDefault("billing.cost_rollup_interval", 300) // 5 minutes
+
+	// Client-side load balancing defaults
+	viper.SetDefault("load_balancing.enabled", true)
+	viper.SetDefault("load_balancing.resolver", "dns")
+	viper.SetDefault("load_balancing.refresh_interval", 10)
+	viper.SetDefault("load_balancing.consecutive_failures", 5)
+	viper.SetDefault("load_balancing.ejection_time", 30)
+	viper.SetDefault("load_balancing.max_ejection_percent", 50)
+
+	// Inter-service gRPC defaults
+	viper.SetDefault("rpc.port", 0)
+	viper.SetDefault("rpc.token", "")
 
 	// Database defaults
+	viper.SetDefault("database.driver", database.DriverPostgres)
+	viper.SetDefault("database.path", "")
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
 	viper.SetDefault("database.user", "linkflow")
@@ -146,25 +629,47 @@ func setDefaults() {
 	// Redis defaults
 	viper.SetDefault("redis.host", "localhost")
 	viper.SetDefault("redis.port", 6379)
+	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", 0)
 	viper.SetDefault("redis.pool_size", 10)
+	viper.SetDefault("redis.gc_interval", 900) // 15 minutes
+	viper.SetDefault("redis.tenant_quota.ops_per_second", 0)
+	viper.SetDefault("redis.tenant_quota.max_memory_mb", 0)
+	viper.SetDefault("redis.tenant_quota.usage_interval", 300) // 5 minutes
 
 	// Kafka defaults
+	viper.SetDefault("kafka.backend", events.BackendKafka)
 	viper.SetDefault("kafka.brokers", []string{"localhost:9092"})
 	viper.SetDefault("kafka.consumer_group", "linkflow-group")
+	viper.SetDefault("kafka.nats.url", "nats://localhost:4222")
+	viper.SetDefault("kafka.nats.subject_prefix", events.DefaultNATSSubjectPrefix)
+	viper.SetDefault("kafka.nats.ack_wait", 30)
+	viper.SetDefault("kafka.nats.max_deliver", events.DefaultNATSMaxDeliver)
+	viper.SetDefault("kafka.nats.stream.max_age", 604800) // 7 days
+	viper.SetDefault("kafka.nats.stream.replicas", 1)
+	viper.SetDefault("kafka.nats.stream.storage", "file")
 
 	// Auth defaults
 	viper.SetDefault("auth.jwt_expiry", 900)        // 15 minutes
 	viper.SetDefault("auth.refresh_expiry", 604800) // 7 days
+	viper.SetDefault("auth.private_key_path", "")
+	viper.SetDefault("auth.public_key_path", "")
 	viper.SetDefault("auth.jwt.secret_key", "development-secret-key-change-in-production")
 	viper.SetDefault("auth.jwt.expiry_hours", 1) // 1 hour for access token
 	viper.SetDefault("auth.jwt.refresh_days", 7) // 7 days for refresh token
 	viper.SetDefault("auth.jwt.issuer", "linkflow-auth")
 	viper.SetDefault("auth.jwt.algorithm", "HS256") // HS256 for dev, RS256 for prod
+	viper.SetDefault("auth.signed_url.secret_key", "development-url-signing-key-change-in-production")
+	viper.SetDefault("auth.signed_url.expiry_minutes", 15)
+	viper.SetDefault("auth.evidence_key", "development-evidence-key-change-in-production")
+	viper.SetDefault("auth.isolate_signups", false)
 
 	// Telemetry defaults
 	viper.SetDefault("telemetry.enabled", true)
+	viper.SetDefault("telemetry.exporter", "otlp")
 	viper.SetDefault("telemetry.jaeger_url", "http://localhost:14268/api/traces")
+	viper.SetDefault("telemetry.otlp_endpoint", "localhost:4318")
+	viper.SetDefault("telemetry.otlp_insecure", true)
 	viper.SetDefault("telemetry.sampling_rate", 1.0)
 
 	// Logger defaults
@@ -173,14 +678,79 @@ func setDefaults() {
 	viper.SetDefault("logger.output", "stdout")
 	viper.SetDefault("logger.add_caller", true)
 	viper.SetDefault("logger.stacktrace", false)
+	viper.SetDefault("logger.sinks", []string{})
+	viper.SetDefault("logger.file.path", "")
+	viper.SetDefault("logger.file.max_size_mb", logger.DefaultMaxSizeMB)
+	viper.SetDefault("logger.file.max_backups", logger.DefaultMaxBackups)
+	viper.SetDefault("logger.file.max_age_days", 14)
+	viper.SetDefault("logger.loki.url", "http://localhost:3100")
+	viper.SetDefault("logger.loki.tenant_id", "")
+	viper.SetDefault("logger.loki.username", "")
+	viper.SetDefault("logger.loki.password", "")
+	viper.SetDefault("logger.loki.batch_size", logger.DefaultBatchSize)
+	viper.SetDefault("logger.loki.flush_interval", logger.DefaultFlushInterval)
+	viper.SetDefault("logger.elasticsearch.url", "http://localhost:9200")
+	viper.SetDefault("logger.elasticsearch.username", "")
+	viper.SetDefault("logger.elasticsearch.password", "")
+	viper.SetDefault("logger.elasticsearch.index", logger.DefaultIndex)
+	viper.SetDefault("logger.elasticsearch.batch_size", logger.DefaultBatchSize)
+	viper.SetDefault("logger.elasticsearch.flush_interval", logger.DefaultFlushInterval)
 
 	// Elasticsearch defaults
 	viper.SetDefault("elasticsearch.url", "http://localhost:9200")
+	viper.SetDefault("elasticsearch.username", "")
+	viper.SetDefault("elasticsearch.password", "")
+
+	// Executor defaults
+	viper.SetDefault("executor.workers", 0)
+
+	// Execution defaults
+	viper.SetDefault("execution.log_retention_days", 7)
+	viper.SetDefault("execution.sampling_grace", 600)         // 10 minutes
+	viper.SetDefault("execution.sampling_prune_interval", 60) // 1 minute
+	viper.SetDefault("execution.sla_interval", 60)            // 1 minute
+	viper.SetDefault("execution.max_parallel_nodes", 4)
+	viper.SetDefault("execution.payload_bucket", "linkflow-payloads")
+	viper.SetDefault("execution.payload_spill_bytes", 262144) // 256 KiB
+
+	// Git sync defaults
+	viper.SetDefault("git_sync.work_dir", filepath.Join(os.TempDir(), "linkflow-git"))
+	viper.SetDefault("git_sync.timeout", 60)
+
+	// Notification defaults
+	viper.SetDefault("notification.digest_interval", 300) // 5 minutes
+	viper.SetDefault("notification.retention_days", 90)
+	viper.SetDefault("notification.read_retention_days", 30)
+	viper.SetDefault("notification.prune_interval", 3600)          // 1 hour
+	viper.SetDefault("notification.activity_digest_interval", 900) // 15 minutes
+	viper.SetDefault("notification.incidents.pagerduty_region", "us")
+	viper.SetDefault("notification.incidents.opsgenie_region", "us")
+	viper.SetDefault("notification.incidents.failure_threshold", 3)
+
+	// Rate limit defaults, 5 login attempts per 15 minutes
+	viper.SetDefault("rate_limit.login_attempts", 5)
+	viper.SetDefault("rate_limit.login_window", 900)
+
+	// Reload defaults
+	viper.SetDefault("reload.watch_file", false)
+
+	// Credential defaults
+	viper.SetDefault("credential.backend", "local")
+	viper.SetDefault("credential.encryption_key", "temporary-32-byte-encryption-key")
+	viper.SetDefault("credential.vault.address", "")
+	viper.SetDefault("credential.vault.token", "")
+	viper.SetDefault("credential.vault.mount", "secret")
+	viper.SetDefault("credential.vault.path", "linkflow/credentials")
+
+	// Storage defaults, MinIO for local development
+	viper.SetDefault("storage.endpoint", "http://localhost:9000")
+	viper.SetDefault("storage.region", "us-east-1")
 }
 
 func overrideFromEnv(cfg *Config) {
 	// Override specific fields from environment variables
 	// Viper automatically reads LINKFLOW_DATABASE_HOST, LINKFLOW_DATABASE_PORT, etc
+	// for every key with a default, secrets default to empty for that reason
 	if host := viper.GetString("DATABASE_HOST"); host != "" {
 		cfg.Database.Host = host
 	}
@@ -212,9 +782,64 @@ func overrideFromEnv(cfg *Config) {
 		cfg.Server.Port = servicePort
 	}
 
+	if adminToken := viper.GetString("ADMIN_TOKEN"); adminToken != "" {
+		cfg.Server.AdminToken = adminToken
+	}
+
+	if rpcPort := viper.GetInt("RPC_PORT"); rpcPort != 0 {
+		cfg.RPC.Port = rpcPort
+	}
+	if rpcToken := viper.GetString("RPC_TOKEN"); rpcToken != "" {
+		cfg.RPC.Token = rpcToken
+	}
+
+	if otlpEndpoint := viper.GetString("TELEMETRY_OTLP_ENDPOINT"); otlpEndpoint != "" {
+		cfg.Telemetry.OTLPEndpoint = otlpEndpoint
+	}
+
+	if urlSecret := viper.GetString("SIGNED_URL_SECRET"); urlSecret != "" {
+		cfg.Auth.SignedURL.SecretKey = urlSecret
+	}
+
+	if evidenceKey := viper.GetString("EVIDENCE_KEY"); evidenceKey != "" {
+		cfg.Auth.EvidenceKey = evidenceKey
+	}
+
+	if signingKey := viper.GetString("TEMPLATE_SIGNING_KEY"); signingKey != "" {
+		cfg.Auth.TemplateBundles.SigningKey = signingKey
+	}
+	if trustedKeys := viper.GetString("TEMPLATE_TRUSTED_KEYS"); trustedKeys != "" {
+		cfg.Auth.TemplateBundles.TrustedKeys = strings.Split(trustedKeys, ",")
+	}
+
+	if stripeKey := viper.GetString("STRIPE_SECRET_KEY"); stripeKey != "" {
+		cfg.Billing.Stripe.SecretKey = stripeKey
+	}
+	if stripeSecret := viper.GetString("STRIPE_WEBHOOK_SECRET"); stripeSecret != "" {
+		cfg.Billing.Stripe.WebhookSecret = stripeSecret
+	}
+
+	if routingKey := viper.GetString("PAGERDUTY_ROUTING_KEY"); routingKey != "" {
+		cfg.Notification.Incidents.PagerDutyRoutingKey = routingKey
+	}
+	if opsgenieKey := viper.GetString("OPSGENIE_API_KEY"); opsgenieKey != "" {
+		cfg.Notification.Incidents.OpsgenieAPIKey = opsgenieKey
+	}
+
 	if esURL := viper.GetString("ELASTICSEARCH_URL"); esURL != "" {
 		cfg.Elasticsearch.URL = esURL
 	}
+
+	if vaultAddr := viper.GetString("VAULT_ADDR"); vaultAddr != "" {
+		cfg.Credential.Vault.Address = vaultAddr
+	}
+	if vaultToken := viper.GetString("VAULT_TOKEN"); vaultToken != "" {
+		cfg.Credential.Vault.Token = vaultToken
+	}
+
+	if logLevel := viper.GetString("LOG_LEVEL"); logLevel != "" {
+		cfg.Logger.Level = logLevel
+	}
 }
 
 func (c *DatabaseConfig) DSN() string {
@@ -229,6 +854,8 @@ func (c *RedisConfig) Addr() string {
 // ToDatabaseConfig converts DatabaseConfig to database.Config
 func (c *DatabaseConfig) ToDatabaseConfig() database.Config {
 	return database.Config{
+		Driver:       c.Driver,
+		Path:         c.Path,
 		Host:         c.Host,
 		Port:         c.Port,
 		User:         c.User,
@@ -243,19 +870,51 @@ func (c *DatabaseConfig) ToDatabaseConfig() database.Config {
 // ToKafkaConfig converts KafkaConfig to events.KafkaConfig
 func (c *KafkaConfig) ToKafkaConfig() events.KafkaConfig {
 	return events.KafkaConfig{
+		Backend:       c.Backend,
 		Brokers:       c.Brokers,
 		Topic:         c.Topic,
 		ConsumerGroup: c.ConsumerGroup,
+		Consumer:      c.Consumer,
+		NATS:          c.NATS.toNATSConfig(),
+	}
+}
+
+func (c *NATSConfig) toNATSConfig() events.NATSConfig {
+	streams := make(map[string]events.NATSStreamConfig, len(c.Streams))
+	for domain, stream := range c.Streams {
+		streams[domain] = stream.toNATSStreamConfig()
+	}
+	return events.NATSConfig{
+		URL:           c.URL,
+		SubjectPrefix: c.SubjectPrefix,
+		Stream:        c.Stream.toNATSStreamConfig(),
+		Streams:       streams,
+		AckWait:       time.Duration(c.AckWait) * time.Second,
+		MaxDeliver:    c.MaxDeliver,
+	}
+}
+
+func (c NATSStreamConfig) toNATSStreamConfig() events.NATSStreamConfig {
+	return events.NATSStreamConfig{
+		MaxAge:   time.Duration(c.MaxAge) * time.Second,
+		MaxBytes: c.MaxBytes,
+		Replicas: c.Replicas,
+		Storage:  c.Storage,
 	}
 }
 
 // ToLoggerConfig converts LoggerConfig to logger.Config
 func (c *LoggerConfig) ToLoggerConfig() logger.Config {
 	return logger.Config{
-		Level:      c.Level,
-		Format:     c.Format,
-		Output:     c.Output,
-		AddCaller:  c.AddCaller,
-		Stacktrace: c.Stacktrace,
+		Level:         c.Level,
+		Format:        c.Format,
+		Output:        c.Output,
+		AddCaller:     c.AddCaller,
+		Stacktrace:    c.Stacktrace,
+		Service:       c.Service,
+		Sinks:         c.Sinks,
+		File:          c.File,
+		Loki:          c.Loki,
+		Elasticsearch: c.Elasticsearch,
 	}
 }
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/spf13/viper"
+)
+
+// ChangeFunc is notified with the previous and the reloaded config
+type ChangeFunc func(prev, next *Config)
+
+// Watcher reloads the config of a running service on SIGHUP and, when
+// reload.watch_file is set, whenever the config file changes. Only
+// tunables are applied live: the log level here, worker counts and rate
+// limits by the services through OnChange. Changes to anything else are
+// logged and need a restart
+type Watcher struct {
+	logger logger.Logger
+
+	mu        sync.Mutex
+	current   *Config
+	listeners []ChangeFunc
+
+	signals chan os.Signal
+	stopCh  chan struct{}
+	once    sync.Once
+}
+
+// NewWatcher creates a watcher for the config returned by Load
+func NewWatcher(cfg *Config, log logger.Logger) *Watcher {
+	return &Watcher{
+		logger:  log,
+		current: cfg,
+		signals: make(chan os.Signal, 1),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// OnChange registers a function called after every successful reload
+func (w *Watcher) OnChange(fn ChangeFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.listeners = append(w.listeners, fn)
+}
+
+// Current returns the last valid config
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Start listens for SIGHUP and file changes until Stop is called
+func (w *Watcher) Start() {
+	signal.Notify(w.signals, syscall.SIGHUP)
+
+	if w.Current().Reload.WatchFile {
+		if viper.ConfigFileUsed() == "" {
+			w.logger.Warn("Config file watching enabled but no config file was loaded")
+		} else {
+			viper.OnConfigChange(func(e fsnotify.Event) {
+				w.logger.Info("Config file changed", "file", e.Name)
+				w.Reload()
+			})
+			viper.WatchConfig()
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case <-w.signals:
+				w.logger.Info("Received SIGHUP, reloading config")
+				w.Reload()
+			case <-w.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops listening for SIGHUP. viper offers no way to stop watching
+// the file, changes after Stop are ignored instead
+func (w *Watcher) Stop() {
+	w.once.Do(func() {
+		signal.Stop(w.signals)
+		close(w.stopCh)
+	})
+}
+
+// Reload reads and validates the config again and applies it. An invalid
+// config is rejected and the previous one stays in effect
+func (w *Watcher) Reload() {
+	select {
+	case <-w.stopCh:
+		return
+	default:
+	}
+
+	next, err := read()
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		w.logger.Error("Config reload rejected, keeping previous config", "error", err)
+		return
+	}
+
+	w.mu.Lock()
+	prev := w.current
+	w.current = next
+	listeners := append([]ChangeFunc(nil), w.listeners...)
+	w.mu.Unlock()
+
+	if next.Logger.Level != prev.Logger.Level {
+		if err := logger.SetLevel(w.logger, next.Logger.Level); err != nil {
+			w.logger.Error("Failed to change log level", "error", err)
+		} else {
+			w.logger.Info("Log level changed", "from", prev.Logger.Level, "to", next.Logger.Level)
+		}
+	}
+
+	if sections := restartRequired(prev, next); len(sections) > 0 {
+		w.logger.Warn("Config changes require a restart to take effect", "sections", sections)
+	}
+
+	for _, fn := range listeners {
+		fn(prev, next)
+	}
+
+	w.logger.Info("Config reloaded")
+}
+
+// restartRequired lists the sections that changed outside of the tunables
+// applied at runtime
+func restartRequired(prev, next *Config) []string {
+	a, b := *prev, *next
+	for _, c := range []*Config{&a, &b} {
+		c.Logger.Level = ""
+		c.Executor = ExecutorConfig{}
+		c.RateLimit = RateLimitConfig{}
+		c.Reload = ReloadConfig{}
+	}
+
+	var sections []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			sections = append(sections, va.Type().Field(i).Tag.Get("mapstructure"))
+		}
+	}
+	return sections
+}
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Config values can reference secrets instead of holding them, resolved
+// when the config is loaded:
+//
+//	env://NAME                    environment variable NAME
+//	file:///run/secrets/db        contents of a file, trailing newline trimmed
+//	vault://secret/linkflow/db#password
+//	                              key of a KV v2 secret, the first path
+//	                              segment is the mount
+//
+// Vault is reached with credential.vault.address and credential.vault.token,
+// or VAULT_ADDR and VAULT_TOKEN. Both may themselves be env:// or file://
+// references.
+const (
+	envScheme   = "env://"
+	fileScheme  = "file://"
+	vaultScheme = "vault://"
+)
+
+// vaultTimeout bounds each secret read from Vault
+const vaultTimeout = 10 * time.Second
+
+// secretField is a config string holding a reference
+type secretField struct {
+	key   string
+	value reflect.Value
+}
+
+// resolveSecrets replaces every reference in the config with the secret
+// it points to, reporting all unresolvable references at once
+func resolveSecrets(cfg *Config) error {
+	var fields []secretField
+	collectSecretFields(reflect.ValueOf(cfg).Elem(), "", &fields)
+
+	v := &validator{}
+
+	// Local references first, they may hold the Vault address or token
+	var vaultRefs []secretField
+	for _, f := range fields {
+		ref := f.value.String()
+		switch {
+		case strings.HasPrefix(ref, envScheme):
+			name := strings.TrimPrefix(ref, envScheme)
+			secret, ok := os.LookupEnv(name)
+			if !ok {
+				v.fail(f.key, "references environment variable %s which is not set", name)
+				continue
+			}
+			f.value.SetString(secret)
+		case strings.HasPrefix(ref, fileScheme):
+			path := strings.TrimPrefix(ref, fileScheme)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				v.fail(f.key, "references unreadable file %s: %v", path, err)
+				continue
+			}
+			f.value.SetString(strings.TrimRight(string(data), "\r\n"))
+		case strings.HasPrefix(ref, vaultScheme):
+			vaultRefs = append(vaultRefs, f)
+		}
+	}
+
+	if len(vaultRefs) > 0 {
+		client := newVaultReader(cfg.Credential.Vault)
+		for _, f := range vaultRefs {
+			secret, err := client.read(f.value.String())
+			if err != nil {
+				v.fail(f.key, "%v", err)
+				continue
+			}
+			f.value.SetString(secret)
+		}
+	}
+
+	if len(v.issues) > 0 {
+		return &ValidationError{Issues: v.issues}
+	}
+	return nil
+}
+
+// collectSecretFields finds the strings of the config holding a reference,
+// keyed by their mapstructure path
+func collectSecretFields(v reflect.Value, prefix string, fields *[]secretField) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		name := t.Field(i).Tag.Get("mapstructure")
+		if name == "" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			collectSecretFields(field, key, fields)
+		case reflect.String:
+			if isSecretRef(field.String()) {
+				*fields = append(*fields, secretField{key: key, value: field})
+			}
+		case reflect.Slice:
+			if field.Type().Elem().Kind() != reflect.String {
+				continue
+			}
+			for j := 0; j < field.Len(); j++ {
+				if isSecretRef(field.Index(j).String()) {
+					*fields = append(*fields, secretField{key: fmt.Sprintf("%s[%d]", key, j), value: field.Index(j)})
+				}
+			}
+		}
+	}
+}
+
+func isSecretRef(value string) bool {
+	return strings.HasPrefix(value, envScheme) ||
+		strings.HasPrefix(value, fileScheme) ||
+		strings.HasPrefix(value, vaultScheme)
+}
+
+// vaultReader reads keys of KV v2 secrets
+type vaultReader struct {
+	address string
+	token   string
+	client  *http.Client
+	// cache avoids reading a secret once per key
+	cache map[string]map[string]interface{}
+}
+
+func newVaultReader(cfg VaultConfig) *vaultReader {
+	address, token := cfg.Address, cfg.Token
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+
+	return &vaultReader{
+		address: strings.TrimRight(address, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: vaultTimeout},
+		cache:   make(map[string]map[string]interface{}),
+	}
+}
+
+func (r *vaultReader) read(ref string) (string, error) {
+	if r.address == "" || r.token == "" {
+		return "", fmt.Errorf("references %s but no Vault address and token are configured", ref)
+	}
+
+	path, key, ok := strings.Cut(strings.TrimPrefix(ref, vaultScheme), "#")
+	mount, secretPath, hasPath := strings.Cut(strings.Trim(path, "/"), "/")
+	if !ok || key == "" || !hasPath || secretPath == "" {
+		return "", fmt.Errorf("invalid reference %s, expected vault://<mount>/<path>#<key>", ref)
+	}
+
+	data, cached := r.cache[path]
+	if !cached {
+		var err error
+		data, err = r.fetch(mount, secretPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %v", ref, err)
+		}
+		r.cache[path] = data
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("references missing key %q of vault secret %s", key, path)
+	}
+	secret, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("references key %q of vault secret %s which is not a string", key, path)
+	}
+	return secret, nil
+}
+
+func (r *vaultReader) fetch(mount, path string) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), vaultTimeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", r.address, mount, path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", r.token)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Data.Data, nil
+}
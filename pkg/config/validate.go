@@ -0,0 +1,292 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/linkflow-go/pkg/database"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxExecutorWorkers caps the executor worker pool
+const maxExecutorWorkers = 100
+
+// ValidationError lists every invalid field of a config so all of them can
+// be fixed in one go
+type ValidationError struct {
+	Issues []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration:\n  - %s", strings.Join(e.Issues, "\n  - "))
+}
+
+// validator collects issues keyed by the viper path of the field
+type validator struct {
+	issues []string
+}
+
+// fail records an issue along with the environment variable that sets the field
+func (v *validator) fail(key, format string, args ...interface{}) {
+	env := "LINKFLOW_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	v.issues = append(v.issues, fmt.Sprintf("%s: %s (%s)", key, fmt.Sprintf(format, args...), env))
+}
+
+func (v *validator) port(key string, port int) {
+	if port < 1 || port > 65535 {
+		v.fail(key, "must be between 1 and 65535, got %d", port)
+	}
+}
+
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.fail(key, "is required")
+	}
+}
+
+func (v *validator) nonNegative(key string, value int) {
+	if value < 0 {
+		v.fail(key, "must not be negative, got %d", value)
+	}
+}
+
+func (v *validator) positive(key string, value int) {
+	if value <= 0 {
+		v.fail(key, "must be greater than 0, got %d", value)
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.fail(key, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+// Validate checks the config for values the services cannot start with and
+// reports all of them at once
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	// Server
+	v.port("server.port", c.Server.Port)
+	if c.Server.AdminPort != 0 {
+		v.port("server.admin_port", c.Server.AdminPort)
+		if c.Server.AdminPort == c.Server.Port {
+			v.fail("server.admin_port", "must differ from server.port %d", c.Server.Port)
+		}
+		if strings.TrimSpace(c.Server.AdminToken) == "" {
+			v.fail("server.admin_token", "is required when server.admin_port is set")
+		}
+	}
+	v.nonNegative("server.read_timeout", c.Server.ReadTimeout)
+	v.nonNegative("server.write_timeout", c.Server.WriteTimeout)
+	v.nonNegative("server.shutdown_timeout", c.Server.ShutdownTimeout)
+
+	// Inter-service gRPC
+	if c.RPC.Port != 0 {
+		v.port("rpc.port", c.RPC.Port)
+		v.required("rpc.token", c.RPC.Token)
+	}
+
+	// Database
+	v.oneOf("database.driver", c.Database.Driver, database.DriverPostgres, database.DriverSQLite)
+	if c.Database.Driver == database.DriverSQLite {
+		v.required("database.path", c.Database.Path)
+	} else {
+		v.required("database.host", c.Database.Host)
+		v.port("database.port", c.Database.Port)
+		v.required("database.name", c.Database.Name)
+		v.required("database.user", c.Database.User)
+		v.oneOf("database.ssl_mode", c.Database.SSLMode,
+			"disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	}
+	v.nonNegative("database.max_open_conns", c.Database.MaxOpenConns)
+	v.nonNegative("database.max_idle_conns", c.Database.MaxIdleConns)
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		v.fail("database.max_idle_conns", "must not exceed database.max_open_conns %d, got %d",
+			c.Database.MaxOpenConns, c.Database.MaxIdleConns)
+	}
+
+	// Redis
+	v.required("redis.host", c.Redis.Host)
+	v.port("redis.port", c.Redis.Port)
+	if c.Redis.DB < 0 || c.Redis.DB > 15 {
+		v.fail("redis.db", "must be between 0 and 15, got %d", c.Redis.DB)
+	}
+	v.nonNegative("redis.pool_size", c.Redis.PoolSize)
+	v.nonNegative("redis.tenant_quota.ops_per_second", c.Redis.TenantQuota.OpsPerSecond)
+	v.nonNegative("redis.tenant_quota.max_memory_mb", c.Redis.TenantQuota.MaxMemoryMB)
+	if c.Redis.TenantQuota.MaxMemoryMB > 0 {
+		v.positive("redis.tenant_quota.usage_interval", c.Redis.TenantQuota.UsageInterval)
+	}
+
+	// Kafka
+	v.oneOf("kafka.backend", c.Kafka.Backend, events.BackendKafka, events.BackendMemory, events.BackendNATS)
+	if c.Kafka.Backend == events.BackendNATS {
+		v.required("kafka.nats.url", c.Kafka.NATS.URL)
+		v.nonNegative("kafka.nats.ack_wait", c.Kafka.NATS.AckWait)
+		v.nonNegative("kafka.nats.max_deliver", c.Kafka.NATS.MaxDeliver)
+		if c.Kafka.NATS.Stream.Storage != "" {
+			v.oneOf("kafka.nats.stream.storage", c.Kafka.NATS.Stream.Storage, "file", "memory")
+		}
+		for domain, stream := range c.Kafka.NATS.Streams {
+			if stream.Storage != "" {
+				v.oneOf("kafka.nats.streams."+domain+".storage", stream.Storage, "file", "memory")
+			}
+		}
+	}
+	if c.Kafka.Backend == events.BackendKafka {
+		if len(c.Kafka.Brokers) == 0 {
+			v.fail("kafka.brokers", "at least one broker is required")
+		}
+		for i, broker := range c.Kafka.Brokers {
+			if strings.TrimSpace(broker) == "" {
+				v.fail("kafka.brokers", "broker %d is empty", i)
+			}
+		}
+	}
+
+	// Auth
+	v.oneOf("auth.jwt.algorithm", c.Auth.JWT.Algorithm, "HS256", "RS256")
+	switch c.Auth.JWT.Algorithm {
+	case "HS256":
+		v.required("auth.jwt.secret_key", c.Auth.JWT.SecretKey)
+	case "RS256":
+		v.required("auth.private_key_path", c.Auth.PrivateKeyPath)
+		v.required("auth.public_key_path", c.Auth.PublicKeyPath)
+	}
+	v.positive("auth.jwt.expiry_hours", c.Auth.JWT.ExpiryHours)
+	v.positive("auth.jwt.refresh_days", c.Auth.JWT.RefreshDays)
+	v.positive("auth.signed_url.expiry_minutes", c.Auth.SignedURL.ExpiryMinutes)
+
+	// Telemetry
+	if c.Telemetry.Enabled {
+		v.oneOf("telemetry.exporter", c.Telemetry.Exporter, "otlp", "jaeger")
+	}
+	if c.Telemetry.SamplingRate < 0 || c.Telemetry.SamplingRate > 1 {
+		v.fail("telemetry.sampling_rate", "must be between 0 and 1, got %g", c.Telemetry.SamplingRate)
+	}
+
+	// Logger
+	if _, err := zapcore.ParseLevel(c.Logger.Level); err != nil {
+		v.fail("logger.level", "must be one of debug, info, warn, error, got %q", c.Logger.Level)
+	}
+	v.oneOf("logger.format", c.Logger.Format, "json", "console")
+	for _, sink := range c.Logger.Sinks {
+		v.oneOf("logger.sinks", sink,
+			logger.SinkStdout, logger.SinkFile, logger.SinkLoki, logger.SinkElasticsearch)
+
+		switch sink {
+		case logger.SinkFile:
+			v.required("logger.file.path", c.Logger.File.Path)
+			v.positive("logger.file.max_size_mb", c.Logger.File.MaxSizeMB)
+			v.positive("logger.file.max_backups", c.Logger.File.MaxBackups)
+			v.nonNegative("logger.file.max_age_days", c.Logger.File.MaxAgeDays)
+		case logger.SinkLoki:
+			v.required("logger.loki.url", c.Logger.Loki.URL)
+			v.positive("logger.loki.batch_size", c.Logger.Loki.BatchSize)
+			v.positive("logger.loki.flush_interval", c.Logger.Loki.FlushInterval)
+		case logger.SinkElasticsearch:
+			v.required("logger.elasticsearch.url", c.Logger.Elasticsearch.URL)
+			v.required("logger.elasticsearch.index", c.Logger.Elasticsearch.Index)
+			v.positive("logger.elasticsearch.batch_size", c.Logger.Elasticsearch.BatchSize)
+			v.positive("logger.elasticsearch.flush_interval", c.Logger.Elasticsearch.FlushInterval)
+		}
+	}
+
+	// Executor
+	if c.Executor.Workers < 0 || c.Executor.Workers > maxExecutorWorkers {
+		v.fail("executor.workers", "must be between 0 (one per CPU) and %d, got %d",
+			maxExecutorWorkers, c.Executor.Workers)
+	}
+
+	// Rate limits
+	v.positive("rate_limit.login_attempts", c.RateLimit.LoginAttempts)
+	v.positive("rate_limit.login_window", c.RateLimit.LoginWindow)
+	for name, plan := range c.Gateway.RateLimit.Plans {
+		if plan.RequestsPerMinute < 0 || plan.RequestsPerDay < 0 {
+			v.fail("gateway.rate_limit.plans."+name, "limits must be 0 (unlimited) or more")
+		}
+	}
+
+	// Load balancing
+	if c.LoadBalancing.Enabled {
+		v.oneOf("load_balancing.resolver", c.LoadBalancing.Resolver, "dns", "registry")
+		v.positive("load_balancing.refresh_interval", c.LoadBalancing.RefreshInterval)
+		v.positive("load_balancing.consecutive_failures", c.LoadBalancing.ConsecutiveFailures)
+		v.positive("load_balancing.ejection_time", c.LoadBalancing.EjectionTime)
+		if p := c.LoadBalancing.MaxEjectionPercent; p < 0 || p > 100 {
+			v.fail("load_balancing.max_ejection_percent", "must be between 0 and 100, got %d", p)
+		}
+	}
+
+	// Tenant quotas
+	if c.Quota.Enabled {
+		if p := c.Quota.WarnPercent; p < 0 || p > 100 {
+			v.fail("quota.warn_percent", "must be between 0 and 100, got %d", p)
+		}
+		v.positive("quota.storage_interval", c.Quota.StorageInterval)
+		if _, ok := c.Quota.Plans[c.Quota.DefaultPlan]; !ok {
+			v.fail("quota.default_plan", "must name one of quota.plans, got %q", c.Quota.DefaultPlan)
+		}
+	}
+	for name, plan := range c.Quota.Plans {
+		if plan.ActiveWorkflows < 0 || plan.ExecutionsPerMonth < 0 || plan.ConcurrentExecutions < 0 || plan.StorageMB < 0 {
+			v.fail("quota.plans."+name, "limits must be 0 (unlimited) or more")
+		}
+	}
+
+	// Billing
+	if c.Billing.Enabled {
+		v.required("billing.currency", c.Billing.Currency)
+		v.positive("billing.invoice_interval", c.Billing.InvoiceInterval)
+		b := c.Billing
+		if b.ComputeCostPerSecond < 0 || b.MemoryCostPerGB < 0 || b.StorageCostPerGB < 0 ||
+			b.NetworkCostPerGB < 0 || b.APICallCost < 0 || b.DatabaseQueryCost < 0 {
+			v.fail("billing", "unit prices must be 0 or more")
+		}
+	}
+
+	// Notification incidents
+	incidents := c.Notification.Incidents
+	v.oneOf("notification.incidents.pagerduty_region", incidents.PagerDutyRegion, "us", "eu")
+	v.oneOf("notification.incidents.opsgenie_region", incidents.OpsgenieRegion, "us", "eu")
+	v.positive("notification.incidents.failure_threshold", incidents.FailureThreshold)
+
+	// Gateway security
+	security := c.Gateway.Security
+	if security.CORS.AllowCredentials {
+		for _, origin := range security.CORS.AllowedOrigins {
+			if origin == "*" {
+				v.fail("gateway.security.cors.allowed_origins", "must list the origins when credentials are allowed")
+			}
+		}
+	}
+	v.nonNegative("gateway.security.cors.max_age", security.CORS.MaxAge)
+	if security.CSRF.Enabled {
+		v.required("gateway.security.csrf.cookie_name", security.CSRF.CookieName)
+		v.required("gateway.security.csrf.header_name", security.CSRF.HeaderName)
+	}
+	v.nonNegative("gateway.security.headers.hsts_max_age", security.Headers.HSTSMaxAge)
+
+	// Credential secrets
+	v.oneOf("credential.backend", c.Credential.Backend, "local", "vault")
+	if len(c.Credential.EncryptionKey) != 32 {
+		v.fail("credential.encryption_key", "must be 32 bytes, got %d", len(c.Credential.EncryptionKey))
+	}
+	if c.Credential.Backend == "vault" {
+		v.required("credential.vault.address", c.Credential.Vault.Address)
+		v.required("credential.vault.token", c.Credential.Vault.Token)
+		v.required("credential.vault.mount", c.Credential.Vault.Mount)
+	}
+
+	if len(v.issues) > 0 {
+		return &ValidationError{Issues: v.issues}
+	}
+	return nil
+}
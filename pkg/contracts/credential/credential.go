@@ -8,20 +8,22 @@ import (
 )
 
 type Credential struct {
-	ID          string                 `json:"id" gorm:"primaryKey"`
-	Name        string                 `json:"name" gorm:"not null"`
-	Type        string                 `json:"type" gorm:"not null"`
-	UserID      string                 `json:"userId" gorm:"not null;index"`
-	TeamID      string                 `json:"teamId" gorm:"index"`
-	Data        map[string]interface{} `json:"data" gorm:"serializer:json"`
-	Description string                 `json:"description"`
-	Tags        []string               `json:"tags" gorm:"serializer:json"`
-	IsShared    bool                   `json:"isShared" gorm:"default:false"`
-	IsActive    bool                   `json:"isActive" gorm:"default:true"`
-	LastUsedAt  *time.Time             `json:"lastUsedAt"`
-	ExpiresAt   *time.Time             `json:"expiresAt"`
-	CreatedAt   time.Time              `json:"createdAt"`
-	UpdatedAt   time.Time              `json:"updatedAt"`
+	ID            string                 `json:"id" gorm:"primaryKey"`
+	TenantID      string                 `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
+	Name          string                 `json:"name" gorm:"not null"`
+	Type          string                 `json:"type" gorm:"not null"`
+	UserID        string                 `json:"userId" gorm:"not null;index"`
+	TeamID        string                 `json:"teamId" gorm:"index"`
+	Data          map[string]interface{} `json:"data" gorm:"serializer:json"`
+	Description   string                 `json:"description"`
+	Tags          []string               `json:"tags" gorm:"serializer:json"`
+	IsShared      bool                   `json:"isShared" gorm:"default:false"`
+	IsActive      bool                   `json:"isActive" gorm:"default:true"`
+	SecretBackend string                 `json:"secretBackend" gorm:"default:local"`
+	LastUsedAt    *time.Time             `json:"lastUsedAt"`
+	ExpiresAt     *time.Time             `json:"expiresAt"`
+	CreatedAt     time.Time              `json:"createdAt"`
+	UpdatedAt     time.Time              `json:"updatedAt"`
 }
 
 // TableName specifies the table name for GORM
@@ -75,6 +77,12 @@ const (
 	TypeCustom      = "custom"
 )
 
+// Secret backends
+const (
+	BackendLocal = "local"
+	BackendVault = "vault"
+)
+
 // OAuth2 auth flows
 const (
 	AuthFlowClientCredentials = "client_credentials"
@@ -0,0 +1,143 @@
+package execution
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/robfig/cron/v3"
+)
+
+// Backfill statuses
+const (
+	BackfillRunning   = "running"
+	BackfillCompleted = "completed"
+	BackfillCancelled = "cancelled"
+	BackfillFailed    = "failed"
+)
+
+const (
+	// DefaultBackfillConcurrency is how many executions of a backfill run at
+	// once when the request names no concurrency
+	DefaultBackfillConcurrency = 1
+	// MaxBackfillConcurrency bounds the executions of a backfill running at
+	// once
+	MaxBackfillConcurrency = 50
+	// MaxBackfillRuns bounds the fire times of a backfill
+	MaxBackfillRuns = 10000
+)
+
+var (
+	ErrBackfillNotFound = apperrors.New(apperrors.CategoryNotFound, "BACKFILL_NOT_FOUND", "backfill not found")
+	ErrInvalidBackfill  = apperrors.New(apperrors.CategoryValidation, "INVALID_BACKFILL", "invalid backfill")
+)
+
+// Backfill runs a workflow once per time its schedule trigger would have
+// fired over a past range, oldest first, with the fire time as input.
+// Executions are started as earlier ones finish, so at most Concurrency of
+// them run at once.
+type Backfill struct {
+	ID             string    `json:"id" gorm:"primaryKey"`
+	TenantID       string    `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
+	WorkflowID     string    `json:"workflowId" gorm:"not null;index"`
+	TriggerID      string    `json:"triggerId" gorm:"not null"`
+	CronExpression string    `json:"cronExpression"`
+	Start          time.Time `json:"start" gorm:"column:range_start"`
+	End            time.Time `json:"end" gorm:"column:range_end"`
+	Concurrency    int       `json:"concurrency"`
+	Status         string    `json:"status" gorm:"default:'running';index"`
+	Total          int       `json:"total"`
+	Enqueued       int       `json:"enqueued"`
+	Completed      int       `json:"completed"`
+	Failed         int       `json:"failed"`
+	// NextFireAt is the next fire time to start, nil once all are started
+	NextFireAt *time.Time `json:"nextFireAt,omitempty"`
+	// Running are the started executions that have not finished yet
+	Running    []string   `json:"running" gorm:"serializer:json"`
+	Error      string     `json:"error,omitempty"`
+	CreatedBy  string     `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// EnvironmentID is the environment of the trigger, the executions run
+	// in it
+	EnvironmentID string `json:"environmentId,omitempty"`
+}
+
+// TableName specifies the table name for GORM
+func (Backfill) TableName() string {
+	return "execution.backfills"
+}
+
+// StartBackfillRequest backfills a schedule trigger over a past range, both
+// ends included
+type StartBackfillRequest struct {
+	TriggerID   string    `json:"triggerId" binding:"required"`
+	Start       time.Time `json:"start" binding:"required"`
+	End         time.Time `json:"end" binding:"required"`
+	Concurrency int       `json:"concurrency" binding:"omitempty,min=1"`
+}
+
+// NewBackfill creates a running backfill of a schedule trigger of a
+// workflow. The range must be in the past and hold at most MaxBackfillRuns
+// fire times of the cron expression, read in UTC like live schedules.
+func NewBackfill(workflowID, triggerID, cronExpression string, req StartBackfillRequest, createdBy string) (*Backfill, error) {
+	start, end := req.Start.UTC(), req.End.UTC()
+	if !end.After(start) {
+		return nil, ErrInvalidBackfill.WithMessage("end must be after start")
+	}
+	if end.After(time.Now()) {
+		return nil, ErrInvalidBackfill.WithMessage("end must be in the past")
+	}
+	concurrency := req.Concurrency
+	if concurrency == 0 {
+		concurrency = DefaultBackfillConcurrency
+	}
+	if concurrency > MaxBackfillConcurrency {
+		return nil, ErrInvalidBackfill.WithMessage("concurrency must be at most %d", MaxBackfillConcurrency)
+	}
+
+	b := &Backfill{
+		ID:             uuid.New().String(),
+		WorkflowID:     workflowID,
+		TriggerID:      triggerID,
+		CronExpression: cronExpression,
+		Start:          start,
+		End:            end,
+		Concurrency:    concurrency,
+		Status:         BackfillRunning,
+		Running:        []string{},
+		CreatedBy:      createdBy,
+		CreatedAt:      time.Now().UTC(),
+	}
+	schedule, err := b.Schedule()
+	if err != nil {
+		return nil, ErrInvalidBackfill.WithMessage("invalid cron expression: %v", err)
+	}
+
+	// Next returns the times after its argument, the start is a fire time too
+	for t := schedule.Next(start.Add(-time.Second)); !t.After(end); t = schedule.Next(t) {
+		if b.Total == 0 {
+			first := t
+			b.NextFireAt = &first
+		}
+		b.Total++
+		if b.Total > MaxBackfillRuns {
+			return nil, ErrInvalidBackfill.WithMessage("range holds more than %d fire times", MaxBackfillRuns)
+		}
+	}
+	if b.Total == 0 {
+		return nil, ErrInvalidBackfill.WithMessage("schedule does not fire within the range")
+	}
+	return b, nil
+}
+
+// Schedule parses the cron expression of the backfill
+func (b *Backfill) Schedule() (cron.Schedule, error) {
+	return cron.ParseStandard(b.CronExpression)
+}
+
+// IsRunning reports whether executions are still started
+func (b *Backfill) IsRunning() bool {
+	return b.Status == BackfillRunning
+}
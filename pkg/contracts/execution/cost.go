@@ -0,0 +1,165 @@
+package execution
+
+import (
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// Cost report groupings
+const (
+	CostByWorkflow = "workflow"
+	CostByDay      = "day"
+	CostByNodeType = "nodeType"
+	CostByTeam     = "team"
+)
+
+const (
+	// DefaultCostReportDays is the range of a cost report naming no start
+	DefaultCostReportDays = 30
+	// MaxCostReportDays bounds the range of a cost report
+	MaxCostReportDays = 366
+)
+
+var ErrInvalidCostReport = apperrors.New(apperrors.CategoryValidation, "INVALID_COST_REPORT", "invalid cost report")
+
+// ExecutionCost is the price of a completed execution, as calculated by the
+// cost calculator, attributed to the user and team owning its workflow
+type ExecutionCost struct {
+	ExecutionID    string    `json:"executionId" gorm:"primaryKey"`
+	TenantID       string    `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
+	WorkflowID     string    `json:"workflowId" gorm:"index"`
+	UserID         string    `json:"userId"`
+	TeamID         string    `json:"teamId"`
+	ComputeSeconds float64   `json:"computeSeconds"`
+	Subtotal       float64   `json:"subtotal"`
+	Discount       float64   `json:"discount"`
+	Total          float64   `json:"total"`
+	Currency       string    `json:"currency"`
+	CalculatedAt   time.Time `json:"calculatedAt" gorm:"index"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// TableName specifies the table name for GORM
+func (ExecutionCost) TableName() string {
+	return "execution.execution_costs"
+}
+
+// NodeTypeCost is the share of the cost of an execution spent in its nodes
+// of a type, split by the time each node ran
+type NodeTypeCost struct {
+	ExecutionID  string    `json:"executionId" gorm:"primaryKey"`
+	NodeType     string    `json:"nodeType" gorm:"primaryKey;size:100"`
+	TenantID     string    `json:"tenantId" gorm:"size:64;not null;default:'default'"`
+	WorkflowID   string    `json:"workflowId"`
+	TeamID       string    `json:"teamId"`
+	Nodes        int       `json:"nodes"`
+	Cost         float64   `json:"cost"`
+	CalculatedAt time.Time `json:"calculatedAt" gorm:"index"`
+}
+
+// TableName specifies the table name for GORM
+func (NodeTypeCost) TableName() string {
+	return "execution.node_type_costs"
+}
+
+// CostRollup sums the execution costs of a UTC day per workflow and owner,
+// cost reports are read from the rollups
+type CostRollup struct {
+	TenantID       string    `json:"tenantId" gorm:"size:64;primaryKey;default:'default'"`
+	Day            string    `json:"day" gorm:"primaryKey;size:10"`
+	WorkflowID     string    `json:"workflowId" gorm:"primaryKey"`
+	UserID         string    `json:"userId" gorm:"primaryKey"`
+	TeamID         string    `json:"teamId" gorm:"primaryKey"`
+	Executions     int64     `json:"executions"`
+	ComputeSeconds float64   `json:"computeSeconds"`
+	Total          float64   `json:"total"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (CostRollup) TableName() string {
+	return "execution.cost_rollups"
+}
+
+// NodeTypeCostRollup sums the node type costs of a UTC day per workflow
+type NodeTypeCostRollup struct {
+	TenantID   string    `json:"tenantId" gorm:"size:64;primaryKey;default:'default'"`
+	Day        string    `json:"day" gorm:"primaryKey;size:10"`
+	WorkflowID string    `json:"workflowId" gorm:"primaryKey"`
+	TeamID     string    `json:"teamId" gorm:"primaryKey"`
+	NodeType   string    `json:"nodeType" gorm:"primaryKey;size:100"`
+	Executions int64     `json:"executions"`
+	Nodes      int64     `json:"nodes"`
+	Cost       float64   `json:"cost"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (NodeTypeCostRollup) TableName() string {
+	return "execution.node_type_cost_rollups"
+}
+
+// CostReportFilter selects the rolled up costs of a report, from the day of
+// From to the day of To included, of one workflow or team when set
+type CostReportFilter struct {
+	GroupBy    string
+	From       time.Time
+	To         time.Time
+	WorkflowID string
+	TeamID     string
+}
+
+// Validate checks the grouping and range of the filter, defaulting the
+// range to the last DefaultCostReportDays days
+func (f *CostReportFilter) Validate(now time.Time) error {
+	switch f.GroupBy {
+	case "":
+		f.GroupBy = CostByWorkflow
+	case CostByWorkflow, CostByDay, CostByNodeType, CostByTeam:
+	default:
+		return ErrInvalidCostReport.WithMessage("groupBy must be workflow, day, nodeType or team")
+	}
+
+	if f.To.IsZero() {
+		f.To = now
+	}
+	if f.From.IsZero() {
+		f.From = f.To.AddDate(0, 0, -(DefaultCostReportDays - 1))
+	}
+	f.From, f.To = f.From.UTC(), f.To.UTC()
+	if f.To.Before(f.From) {
+		return ErrInvalidCostReport.WithMessage("to must not be before from")
+	}
+	if f.To.Sub(f.From) > MaxCostReportDays*24*time.Hour {
+		return ErrInvalidCostReport.WithMessage("range must be at most %d days", MaxCostReportDays)
+	}
+	return nil
+}
+
+// CostReport sums the costs of a range per workflow, day, node type or team
+type CostReport struct {
+	GroupBy    string           `json:"groupBy"`
+	From       string           `json:"from"`
+	To         string           `json:"to"`
+	WorkflowID string           `json:"workflowId,omitempty"`
+	TeamID     string           `json:"teamId,omitempty"`
+	Rows       []*CostReportRow `json:"rows"`
+	Executions int64            `json:"executions"`
+	Total      float64          `json:"total"`
+}
+
+// CostReportRow is the cost of one workflow, day, node type or team. Nodes
+// is only counted per node type, compute time only for the other groupings.
+type CostReportRow struct {
+	Key            string  `json:"key"`
+	Executions     int64   `json:"executions"`
+	Nodes          int64   `json:"nodes,omitempty"`
+	ComputeSeconds float64 `json:"computeSeconds,omitempty"`
+	Total          float64 `json:"total"`
+}
+
+// CostDay is the day a cost calculated at t is rolled up in
+func CostDay(t time.Time) string {
+	return t.UTC().Format(KPIDayFormat)
+}
@@ -0,0 +1,67 @@
+package execution
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Evidence bundle statuses
+const (
+	EvidencePending = "pending"
+	EvidenceReady   = "ready"
+	EvidenceFailed  = "failed"
+)
+
+// EvidenceBundle is a signed archive of everything about one execution,
+// assembled for auditors. The archive is kept with the record so it can be
+// downloaded until the execution itself is cleaned up.
+type EvidenceBundle struct {
+	ID          string     `json:"id" gorm:"primaryKey"`
+	ExecutionID string     `json:"executionId" gorm:"not null;index"`
+	RequestedBy string     `json:"requestedBy"`
+	Status      string     `json:"status" gorm:"default:'pending'"`
+	Archive     []byte     `json:"-"`
+	Size        int64      `json:"size"`
+	Checksum    string     `json:"checksum,omitempty"`
+	Signature   string     `json:"signature,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// TableName specifies the table name for GORM
+func (EvidenceBundle) TableName() string {
+	return "execution.evidence_bundles"
+}
+
+// NewEvidenceBundle creates a pending bundle for an execution
+func NewEvidenceBundle(executionID, requestedBy string) *EvidenceBundle {
+	return &EvidenceBundle{
+		ID:          uuid.New().String(),
+		ExecutionID: executionID,
+		RequestedBy: requestedBy,
+		Status:      EvidencePending,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+// CredentialUse is a credential accessed during an execution, with the
+// version it was at. Credentials are versioned by their last update.
+type CredentialUse struct {
+	CredentialID string    `json:"credentialId"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	Version      time.Time `json:"version"`
+	Action       string    `json:"action"`
+	UsedAt       time.Time `json:"usedAt"`
+}
+
+// Approval is a paused execution being resumed, with who resumed it
+type Approval struct {
+	ApprovedBy string    `json:"approvedBy,omitempty"`
+	FromState  string    `json:"fromState"`
+	ToState    string    `json:"toState"`
+	Comment    string    `json:"comment,omitempty"`
+	At         time.Time `json:"at"`
+}
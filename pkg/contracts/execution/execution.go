@@ -7,8 +7,13 @@ import (
 )
 
 // Execution represents a workflow execution instance
+//
+// Deprecated: executions are recorded with workflow.WorkflowExecution, the
+// shared workflow contract. Execution only still describes the columns of
+// the table for its schema and existing reads.
 type Execution struct {
 	ID            string                 `json:"id" gorm:"primaryKey"`
+	TenantID      string                 `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
 	WorkflowID    string                 `json:"workflowId" gorm:"not null;index"`
 	WorkflowName  string                 `json:"workflowName"`
 	Version       int                    `json:"version"`
@@ -36,6 +41,9 @@ func (Execution) TableName() string {
 }
 
 // NodeExecution represents the execution of a single node
+//
+// Deprecated: node executions are recorded with workflow.NodeExecution, the
+// shared workflow contract.
 type NodeExecution struct {
 	ID            string                 `json:"id" gorm:"primaryKey"`
 	ExecutionID   string                 `json:"executionId" gorm:"not null;index"`
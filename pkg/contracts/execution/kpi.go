@@ -0,0 +1,29 @@
+package execution
+
+import "time"
+
+// KPIDayFormat is the form of the days KPIs are aggregated over, in UTC
+const KPIDayFormat = "2006-01-02"
+
+// KPI aggregates a business metric emitted by the metric nodes of a
+// workflow over a day: the sum of the emitted values and how many were
+// emitted
+type KPI struct {
+	TenantID   string    `json:"tenantId" gorm:"size:64;primaryKey;default:'default'"`
+	WorkflowID string    `json:"workflowId" gorm:"primaryKey"`
+	Day        string    `json:"date" gorm:"primaryKey;size:10"`
+	Name       string    `json:"name" gorm:"primaryKey;size:63"`
+	Value      float64   `json:"value"`
+	Samples    int64     `json:"samples"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (KPI) TableName() string {
+	return "execution.workflow_kpis"
+}
+
+// KPIDay is the day a metric emitted at t is aggregated in
+func KPIDay(t time.Time) string {
+	return t.UTC().Format(KPIDayFormat)
+}
@@ -0,0 +1,10 @@
+package execution
+
+// Levels of the log lines nodes emit while they execute, published as
+// node.log events
+const (
+	LogLevelDebug   = "debug"
+	LogLevelInfo    = "info"
+	LogLevelWarning = "warning"
+	LogLevelError   = "error"
+)
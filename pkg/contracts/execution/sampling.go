@@ -0,0 +1,63 @@
+package execution
+
+import "time"
+
+// SampledOut counts the successful executions of a workflow in a UTC day
+// that its sampling policy dropped
+type SampledOut struct {
+	TenantID   string    `json:"tenantId" gorm:"size:64;primaryKey;default:'default'"`
+	WorkflowID string    `json:"workflowId" gorm:"primaryKey"`
+	Day        string    `json:"day" gorm:"primaryKey;size:10"`
+	Executions int64     `json:"executions"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (SampledOut) TableName() string {
+	return "execution.sampled_out"
+}
+
+// Stats summarizes the stored executions of a workflow
+type Stats struct {
+	WorkflowID           string     `json:"workflowId"`
+	Total                int64      `json:"total"`
+	Successful           int64      `json:"successful"`
+	Failed               int64      `json:"failed"`
+	Running              int64      `json:"running"`
+	AverageExecutionTime float64    `json:"averageExecutionTime"`
+	LastExecutionAt      *time.Time `json:"lastExecutionAt"`
+	// Sampling is set when successful executions of the workflow are
+	// sampled, or were
+	Sampling *SamplingStats `json:"sampling,omitempty"`
+}
+
+// SamplingStats reports the successful executions a sampling policy did not
+// store, so the stored counts can be scaled back to the executions run
+type SamplingStats struct {
+	// SuccessRate is the percent of successful executions the policy
+	// stores, 100 once the policy is removed
+	SuccessRate float64 `json:"successRate"`
+	// Dropped is how many successful executions were not stored
+	Dropped int64 `json:"dropped"`
+	// SampleRate is the percent of the successful executions run that are
+	// stored, the first and last of the day included
+	SampleRate float64 `json:"sampleRate"`
+	// EstimatedTotal is the executions run, stored or not
+	EstimatedTotal int64 `json:"estimatedTotal"`
+}
+
+// NewSamplingStats reports the sampling of a workflow with total stored
+// executions, stored of them successful, and dropped successful executions
+// not stored
+func NewSamplingStats(successRate float64, stored, dropped, total int64) *SamplingStats {
+	s := &SamplingStats{
+		SuccessRate:    successRate,
+		Dropped:        dropped,
+		SampleRate:     100,
+		EstimatedTotal: total + dropped,
+	}
+	if run := stored + dropped; run > 0 {
+		s.SampleRate = float64(stored) / float64(run) * 100
+	}
+	return s
+}
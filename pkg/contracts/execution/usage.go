@@ -0,0 +1,36 @@
+package execution
+
+// Usage is the resources used running nodes, measured by the executor
+// workers for each node and summed per execution for the cost calculator.
+// Allocations and the memory watermark are those of the worker process
+// while the node ran, nodes running at the same time on a worker share them.
+type Usage struct {
+	// WallTimeMs is how long the nodes ran on the workers
+	WallTimeMs int64 `json:"wallTimeMs"`
+	// AllocatedBytes is the heap memory allocated while the nodes ran
+	AllocatedBytes int64 `json:"allocatedBytes"`
+	// MemoryPeakBytes is the highest heap in use while a node ran
+	MemoryPeakBytes int64 `json:"memoryPeakBytes"`
+	// BytesSent and BytesReceived are the HTTP bodies sent and received
+	BytesSent     int64 `json:"bytesSent"`
+	BytesReceived int64 `json:"bytesReceived"`
+	// APICalls is how many HTTP requests the nodes made
+	APICalls int `json:"apiCalls"`
+}
+
+// Add adds the usage of a node, the watermark is the highest of both
+func (u *Usage) Add(node Usage) {
+	u.WallTimeMs += node.WallTimeMs
+	u.AllocatedBytes += node.AllocatedBytes
+	if node.MemoryPeakBytes > u.MemoryPeakBytes {
+		u.MemoryPeakBytes = node.MemoryPeakBytes
+	}
+	u.BytesSent += node.BytesSent
+	u.BytesReceived += node.BytesReceived
+	u.APICalls += node.APICalls
+}
+
+// NetworkBytes is the HTTP bodies sent and received
+func (u Usage) NetworkBytes() int64 {
+	return u.BytesSent + u.BytesReceived
+}
@@ -0,0 +1,122 @@
+package execution
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// Export destinations
+const (
+	ExportDestinationDownload  = "download"
+	ExportDestinationWarehouse = "warehouse"
+	ExportDestinationArchive   = "archive"
+)
+
+// WatermarkPrefix starts every watermark line embedded in a CSV export
+const WatermarkPrefix = "# linkflow-"
+
+// ExportScope describes which executions an export covers
+type ExportScope struct {
+	WorkflowID string     `json:"workflowId,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+}
+
+// Watermark is embedded in every execution data export and recorded by the
+// audit service, so a file found outside the platform can be traced back to
+// who exported it, when and what it contained
+type Watermark struct {
+	ExportID    string      `json:"exportId"`
+	ExportedBy  string      `json:"exportedBy"`
+	ExportedAt  time.Time   `json:"exportedAt"`
+	Format      string      `json:"format"`
+	Destination string      `json:"destination"`
+	Scope       ExportScope `json:"scope"`
+	RecordCount int         `json:"recordCount"`
+	Checksum    string      `json:"checksum"`
+}
+
+// NewWatermark creates a watermark for an export about to be written
+func NewWatermark(exportedBy, format, destination string, scope ExportScope) *Watermark {
+	return &Watermark{
+		ExportID:    uuid.New().String(),
+		ExportedBy:  exportedBy,
+		ExportedAt:  time.Now().UTC(),
+		Format:      format,
+		Destination: destination,
+		Scope:       scope,
+	}
+}
+
+// Seal records the number of exported records and the checksum of the data
+// following the watermark
+func (w *Watermark) Seal(records int, data []byte) {
+	sum := sha256.Sum256(data)
+	w.RecordCount = records
+	w.Checksum = "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// CSVHeader renders the watermark as comment lines placed before the CSV
+// column header
+func (w *Watermark) CSVHeader() string {
+	var b strings.Builder
+	line := func(key, value string) {
+		fmt.Fprintf(&b, "%s%s: %s\n", WatermarkPrefix, key, value)
+	}
+
+	line("export-id", w.ExportID)
+	line("exported-by", w.ExportedBy)
+	line("exported-at", w.ExportedAt.Format(time.RFC3339))
+	line("scope", w.Scope.String())
+	line("records", fmt.Sprintf("%d", w.RecordCount))
+	line("checksum", w.Checksum)
+
+	return b.String()
+}
+
+// EventPayload describes the watermark for the audit event
+func (w *Watermark) EventPayload() map[string]interface{} {
+	return map[string]interface{}{
+		"exportId":    w.ExportID,
+		"exportedBy":  w.ExportedBy,
+		"exportedAt":  w.ExportedAt,
+		"format":      w.Format,
+		"destination": w.Destination,
+		"scope":       w.Scope,
+		"recordCount": w.RecordCount,
+		"checksum":    w.Checksum,
+	}
+}
+
+// String renders the scope as space separated key=value pairs
+func (s ExportScope) String() string {
+	var parts []string
+	if s.WorkflowID != "" {
+		parts = append(parts, "workflow="+s.WorkflowID)
+	}
+	if s.Status != "" {
+		parts = append(parts, "status="+s.Status)
+	}
+	if s.From != nil {
+		parts = append(parts, "from="+s.From.UTC().Format(time.RFC3339))
+	}
+	if s.To != nil {
+		parts = append(parts, "to="+s.To.UTC().Format(time.RFC3339))
+	}
+	if len(parts) == 0 {
+		return "all"
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"time"
+)
+
+// Activity digest frequencies
+const (
+	ActivityDigestOff    = "off"
+	ActivityDigestDaily  = "daily"
+	ActivityDigestWeekly = "weekly"
+)
+
+// subscribeActivityDigest opts the user back into the weekly activity
+// digest, keeping the frequency they chose, or out of it
+func (p *Preferences) subscribeActivityDigest(subscribed bool) {
+	switch {
+	case !subscribed:
+		p.ActivityDigest = ActivityDigestOff
+	case p.ActivityDigest == ActivityDigestOff || p.ActivityDigest == "":
+		p.ActivityDigest = ActivityDigestWeekly
+	}
+	p.UpdatedAt = time.Now()
+}
+
+// ActivityPeriod returns the period the last activity digest due by now
+// summarizes, and whether it is still to be sent. Daily digests are due at
+// DigestHour and weekly ones at DigestHour on Mondays, in the timezone of
+// the user.
+func (p *Preferences) ActivityPeriod(now time.Time) (from, to time.Time, due bool) {
+	if p.ActivityDigest != ActivityDigestDaily && p.ActivityDigest != ActivityDigestWeekly {
+		return time.Time{}, time.Time{}, false
+	}
+
+	local := now.In(p.Location())
+	to = time.Date(local.Year(), local.Month(), local.Day(), p.DigestHour, 0, 0, 0, local.Location())
+	days := 1
+	if p.ActivityDigest == ActivityDigestWeekly {
+		days = 7
+		to = to.AddDate(0, 0, -int((to.Weekday()+6)%7))
+	}
+	if to.After(local) {
+		to = to.AddDate(0, 0, -days)
+	}
+	from = to.AddDate(0, 0, -days)
+
+	due = p.ActivityDigestSentAt == nil || p.ActivityDigestSentAt.Before(to)
+	return from, to, due
+}
+
+// ActivitySummary is the activity of the workflows of a user over the
+// period of an activity digest
+type ActivitySummary struct {
+	UserID    string    `json:"userId"`
+	Frequency string    `json:"frequency"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+
+	Executions int64   `json:"executions"`
+	Succeeded  int64   `json:"succeeded"`
+	Failed     int64   `json:"failed"`
+	Cost       float64 `json:"cost"`
+	Currency   string  `json:"currency"`
+
+	// Workflows are those which ran, most failures first
+	Workflows []WorkflowActivity `json:"workflows"`
+
+	// PendingApprovals counts the executions paused for an approval now,
+	// Approvals lists the oldest of them
+	PendingApprovals int64             `json:"pendingApprovals"`
+	Approvals        []PendingApproval `json:"approvals"`
+}
+
+// WorkflowActivity is the activity of one workflow over the period
+type WorkflowActivity struct {
+	WorkflowID string `json:"workflowId"`
+	Name       string `json:"name"`
+	Executions int64  `json:"executions"`
+	Succeeded  int64  `json:"succeeded"`
+	Failed     int64  `json:"failed"`
+}
+
+// PendingApproval is an execution paused for an approval
+type PendingApproval struct {
+	ExecutionID  string    `json:"executionId"`
+	WorkflowID   string    `json:"workflowId"`
+	WorkflowName string    `json:"workflowName"`
+	Since        time.Time `json:"since"`
+}
+
+// Empty reports whether nothing happened worth a digest: no execution ran
+// and none waits for an approval
+func (s *ActivitySummary) Empty() bool {
+	return s.Executions == 0 && s.PendingApprovals == 0
+}
+
+// Email is an email message rendered for a channel
+type Email struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
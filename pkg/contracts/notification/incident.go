@@ -0,0 +1,132 @@
+package notification
+
+import (
+	"strings"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// Incident channel types, incident management services critical events
+// are paged to
+const (
+	ChannelTypePagerDuty = "pagerduty"
+	ChannelTypeOpsgenie  = "opsgenie"
+)
+
+// Configuration keys of incident channels
+const (
+	// ConfigRoutingKey is the integration key of a PagerDuty service
+	ConfigRoutingKey = "routingKey"
+	// ConfigAPIKey is the key of an Opsgenie API integration
+	ConfigAPIKey = "apiKey"
+	// ConfigRegion is the instance of the service the account is on,
+	// RegionUS by default or RegionEU
+	ConfigRegion = "region"
+)
+
+// Regions of incident management services
+const (
+	RegionUS = "us"
+	RegionEU = "eu"
+)
+
+// Incident sources
+const (
+	IncidentSLABreach         = "sla_breach"
+	IncidentWorkerPool        = "worker_pool_exhausted"
+	IncidentExecutionFailures = "execution_failures"
+	// IncidentTest checks an incident channel pages
+	IncidentTest = "test"
+)
+
+// Incident severities
+const (
+	SeverityCritical = "critical"
+	SeverityError    = "error"
+	SeverityWarning  = "warning"
+)
+
+var ErrInvalidChannelConfig = apperrors.New(apperrors.CategoryValidation, "INVALID_CHANNEL_CONFIG", "invalid notification channel configuration")
+
+// IsIncidentChannel reports whether a channel type pages incidents
+func IsIncidentChannel(channelType string) bool {
+	return channelType == ChannelTypePagerDuty || channelType == ChannelTypeOpsgenie
+}
+
+// Validate checks the configuration of an incident channel has what its
+// type needs
+func (c *Channel) Validate() error {
+	if !IsIncidentChannel(c.Type) {
+		return ErrInvalidChannel.WithMessage("not an incident channel: %s", c.Type)
+	}
+	switch c.Type {
+	case ChannelTypePagerDuty:
+		if c.Config[ConfigRoutingKey] == "" {
+			return ErrInvalidChannelConfig.WithMessage("pagerduty needs a %s", ConfigRoutingKey)
+		}
+	case ChannelTypeOpsgenie:
+		if c.Config[ConfigAPIKey] == "" {
+			return ErrInvalidChannelConfig.WithMessage("opsgenie needs an %s", ConfigAPIKey)
+		}
+	}
+	if region := c.Config[ConfigRegion]; region != "" && region != RegionUS && region != RegionEU {
+		return ErrInvalidChannelConfig.WithMessage("%s is us or eu", ConfigRegion)
+	}
+	return nil
+}
+
+// Masked returns the configuration of the channel with its keys hidden
+// but their last four characters
+func (c *Channel) Masked() map[string]string {
+	masked := make(map[string]string, len(c.Config))
+	for k, v := range c.Config {
+		if (k == ConfigRoutingKey || k == ConfigAPIKey) && v != "" {
+			if len(v) > 4 {
+				v = "****" + v[len(v)-4:]
+			} else {
+				v = "****"
+			}
+		}
+		masked[k] = v
+	}
+	return masked
+}
+
+// Incident is a critical event paged to incident management services.
+// Events of the same incident share its DedupKey: the services group them
+// into one alert, and the event resolving it closes the alert.
+type Incident struct {
+	DedupKey string `json:"dedupKey"`
+	Source   string `json:"source"`
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	// Resolved closes the incident opened under DedupKey
+	Resolved bool `json:"resolved"`
+	// UserID owns what the incident is about, its incident channels are
+	// paged besides the platform ones. Empty for incidents of the
+	// platform.
+	UserID    string                 `json:"userId,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// NewIncident creates an incident of a source, deduplicated by the parts
+// naming what it is about
+func NewIncident(source, severity, summary string, parts ...string) *Incident {
+	return &Incident{
+		DedupKey:  "linkflow/" + strings.Join(append([]string{source}, parts...), "/"),
+		Source:    source,
+		Severity:  severity,
+		Summary:   summary,
+		Details:   make(map[string]interface{}),
+		Timestamp: time.Now(),
+	}
+}
+
+// Resolve turns the incident into the event closing it
+func (i *Incident) Resolve(summary string) *Incident {
+	i.Resolved = true
+	i.Summary = summary
+	return i
+}
@@ -1,15 +1,19 @@
 package notification
 
 import (
-	"errors"
 	"time"
 
 	"github.com/google/uuid"
+	apperrors "github.com/linkflow-go/pkg/errors"
 )
 
 var (
-	ErrChannelNotFound = errors.New("notification channel not found")
-	ErrInvalidChannel  = errors.New("invalid notification channel")
+	ErrChannelNotFound = apperrors.New(apperrors.CategoryNotFound, "NOTIFICATION_CHANNEL_NOT_FOUND", "notification channel not found")
+	ErrInvalidChannel  = apperrors.New(apperrors.CategoryValidation, "INVALID_NOTIFICATION_CHANNEL", "invalid notification channel")
+	ErrNotFound        = apperrors.New(apperrors.CategoryNotFound, "NOTIFICATION_NOT_FOUND", "notification not found")
+	// ErrPreferencesNotFound is returned for users who never set their
+	// preferences, they receive notifications by NewPreferences
+	ErrPreferencesNotFound = apperrors.New(apperrors.CategoryNotFound, "NOTIFICATION_PREFERENCES_NOT_FOUND", "notification preferences not found")
 )
 
 // Channel represents a notification channel
@@ -31,22 +35,41 @@ func (Channel) TableName() string {
 	return "notification.channels"
 }
 
-// Preferences represents user notification preferences
+// Preferences represents user notification preferences: the channels
+// notifications are delivered through, the types the user receives, when
+// they are held and whether they are delivered at once or in a digest
 type Preferences struct {
-	ID               string    `json:"id" gorm:"primaryKey"`
-	UserID           string    `json:"userId" gorm:"column:user_id;uniqueIndex;not null"`
-	EmailEnabled     bool      `json:"emailEnabled" gorm:"column:email_enabled;default:true"`
-	PushEnabled      bool      `json:"pushEnabled" gorm:"column:push_enabled;default:true"`
-	SlackEnabled     bool      `json:"slackEnabled" gorm:"column:slack_enabled;default:false"`
-	WebhookEnabled   bool      `json:"webhookEnabled" gorm:"column:webhook_enabled;default:false"`
-	ExecutionSuccess bool      `json:"executionSuccess" gorm:"column:execution_success;default:false"`
-	ExecutionFailure bool      `json:"executionFailure" gorm:"column:execution_failure;default:true"`
-	WorkflowShared   bool      `json:"workflowShared" gorm:"column:workflow_shared;default:true"`
-	TeamInvite       bool      `json:"teamInvite" gorm:"column:team_invite;default:true"`
-	BillingAlerts    bool      `json:"billingAlerts" gorm:"column:billing_alerts;default:true"`
-	WeeklyDigest     bool      `json:"weeklyDigest" gorm:"column:weekly_digest;default:true"`
-	CreatedAt        time.Time `json:"createdAt" gorm:"column:created_at"`
-	UpdatedAt        time.Time `json:"updatedAt" gorm:"column:updated_at"`
+	ID               string `json:"id" gorm:"primaryKey"`
+	UserID           string `json:"userId" gorm:"column:user_id;uniqueIndex;not null"`
+	EmailEnabled     bool   `json:"emailEnabled" gorm:"column:email_enabled;default:true"`
+	PushEnabled      bool   `json:"pushEnabled" gorm:"column:push_enabled;default:true"`
+	SlackEnabled     bool   `json:"slackEnabled" gorm:"column:slack_enabled;default:false"`
+	WebhookEnabled   bool   `json:"webhookEnabled" gorm:"column:webhook_enabled;default:false"`
+	ExecutionSuccess bool   `json:"executionSuccess" gorm:"column:execution_success;default:false"`
+	ExecutionFailure bool   `json:"executionFailure" gorm:"column:execution_failure;default:true"`
+	WorkflowShared   bool   `json:"workflowShared" gorm:"column:workflow_shared;default:true"`
+	TeamInvite       bool   `json:"teamInvite" gorm:"column:team_invite;default:true"`
+	BillingAlerts    bool   `json:"billingAlerts" gorm:"column:billing_alerts;default:true"`
+	SLAAlerts        bool   `json:"slaAlerts" gorm:"column:sla_alerts;default:true"`
+	AnomalyAlerts    bool   `json:"anomalyAlerts" gorm:"column:anomaly_alerts;default:true"`
+	ApprovalRequests bool   `json:"approvalRequests" gorm:"column:approval_requests;default:true"`
+	SecurityAlerts   bool   `json:"securityAlerts" gorm:"column:security_alerts;default:true"`
+	// ActivityDigest is how often the activity of the workflows of the
+	// user is summarized by email, ActivityDigestOff opts out
+	ActivityDigest       string     `json:"activityDigest" gorm:"column:activity_digest;default:'weekly'"`
+	ActivityDigestSentAt *time.Time `json:"activityDigestSentAt" gorm:"column:activity_digest_sent_at"`
+	// Delivery is DeliveryImmediate, or DeliveryDigest to receive the
+	// notifications of a day together at DigestHour
+	Delivery   string `json:"delivery" gorm:"column:delivery;default:'immediate'"`
+	DigestHour int    `json:"digestHour" gorm:"column:digest_hour;default:8"`
+	// Notifications created within the quiet hours are held until they
+	// end, urgent ones excepted. Start and end are HH:MM in Timezone.
+	QuietHoursEnabled bool      `json:"quietHoursEnabled" gorm:"column:quiet_hours_enabled;default:false"`
+	QuietHoursStart   string    `json:"quietHoursStart" gorm:"column:quiet_hours_start"`
+	QuietHoursEnd     string    `json:"quietHoursEnd" gorm:"column:quiet_hours_end"`
+	Timezone          string    `json:"timezone" gorm:"column:quiet_hours_timezone;default:'UTC'"`
+	CreatedAt         time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt         time.Time `json:"updatedAt" gorm:"column:updated_at"`
 }
 
 // TableName specifies the table name for GORM
@@ -71,7 +94,10 @@ type Notification struct {
 	SentAt      *time.Time             `json:"sentAt" gorm:"column:sent_at"`
 	ReadAt      *time.Time             `json:"readAt" gorm:"column:read_at"`
 	Error       string                 `json:"error" gorm:"column:error_message"`
-	CreatedAt   time.Time              `json:"createdAt" gorm:"column:created_at"`
+	// Channels are the channel types the notification is delivered
+	// through besides the inbox, from the preferences of the user
+	Channels  []string  `json:"channels" gorm:"serializer:json"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
 }
 
 // TableName specifies the table name for GORM
@@ -95,7 +121,12 @@ const (
 	TypeWorkflowShared   = "workflow_shared"
 	TypeTeamInvite       = "team_invite"
 	TypeBillingAlert     = "billing_alert"
-	TypeWeeklyDigest     = "weekly_digest"
+	TypeSLAAlert         = "sla_alert"
+	TypeAnomalyAlert     = "anomaly_alert"
+	TypeApprovalRequest  = "approval_request"
+	TypeSecurityAlert    = "security_alert"
+	TypeDigest           = "digest"
+	TypeActivityDigest   = "activity_digest"
 	TypeSystemAlert      = "system_alert"
 	TypeCustom           = "custom"
 )
@@ -116,6 +147,8 @@ const (
 	StatusDelivered = "delivered"
 	StatusFailed    = "failed"
 	StatusRead      = "read"
+	// StatusDigested notifications were delivered within a digest
+	StatusDigested = "digested"
 )
 
 // NewChannel creates a new notification channel
@@ -169,6 +202,16 @@ func (n *Notification) MarkAsFailed(err string) {
 	n.Attempts++
 }
 
+// InInbox reports whether the notification is in the inbox of its user at
+// now: neither held for a digest or quiet hours nor delivered within a
+// digest
+func (n *Notification) InInbox(now time.Time) bool {
+	if n.Status == StatusQueued || n.Status == StatusDigested {
+		return false
+	}
+	return n.ScheduledAt == nil || !n.ScheduledAt.After(now)
+}
+
 // CanRetry checks if the notification can be retried
 func (n *Notification) CanRetry() bool {
 	return n.Status == StatusFailed && n.Attempts < n.MaxAttempts
@@ -185,7 +228,14 @@ func NewPreferences(userID string) *Preferences {
 		WorkflowShared:   true,
 		TeamInvite:       true,
 		BillingAlerts:    true,
-		WeeklyDigest:     true,
+		SLAAlerts:        true,
+		AnomalyAlerts:    true,
+		ApprovalRequests: true,
+		SecurityAlerts:   true,
+		ActivityDigest:   ActivityDigestWeekly,
+		Delivery:         DeliveryImmediate,
+		DigestHour:       DefaultDigestHour,
+		Timezone:         "UTC",
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 	}
@@ -0,0 +1,287 @@
+package notification
+
+import (
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// Delivery modes
+const (
+	// DeliveryImmediate delivers each notification as it is created
+	DeliveryImmediate = "immediate"
+	// DeliveryDigest holds notifications and delivers those of a day
+	// together, in one digest notification
+	DeliveryDigest = "digest"
+)
+
+// DefaultDigestHour is the hour of the day digests are delivered at
+const DefaultDigestHour = 8
+
+// clockLayout is the format of the start and end of quiet hours
+const clockLayout = "15:04"
+
+var (
+	ErrInvalidPreferences      = apperrors.New(apperrors.CategoryValidation, "INVALID_NOTIFICATION_PREFERENCES", "invalid notification preferences")
+	ErrUnknownNotificationType = apperrors.New(apperrors.CategoryValidation, "UNKNOWN_NOTIFICATION_TYPE", "notification type cannot be subscribed to")
+)
+
+// SetPreferencesRequest sets the notification preferences of a user
+type SetPreferencesRequest struct {
+	EmailEnabled      bool   `json:"emailEnabled"`
+	PushEnabled       bool   `json:"pushEnabled"`
+	SlackEnabled      bool   `json:"slackEnabled"`
+	WebhookEnabled    bool   `json:"webhookEnabled"`
+	ExecutionSuccess  bool   `json:"executionSuccess"`
+	ExecutionFailure  bool   `json:"executionFailure"`
+	WorkflowShared    bool   `json:"workflowShared"`
+	TeamInvite        bool   `json:"teamInvite"`
+	BillingAlerts     bool   `json:"billingAlerts"`
+	SLAAlerts         bool   `json:"slaAlerts"`
+	AnomalyAlerts     bool   `json:"anomalyAlerts"`
+	ApprovalRequests  bool   `json:"approvalRequests"`
+	SecurityAlerts    bool   `json:"securityAlerts"`
+	ActivityDigest    string `json:"activityDigest"`
+	Delivery          string `json:"delivery"`
+	DigestHour        int    `json:"digestHour"`
+	QuietHoursEnabled bool   `json:"quietHoursEnabled"`
+	QuietHoursStart   string `json:"quietHoursStart"`
+	QuietHoursEnd     string `json:"quietHoursEnd"`
+	Timezone          string `json:"timezone"`
+}
+
+// Request returns the request setting the preferences as they are, the
+// base a partial update is decoded onto
+func (p *Preferences) Request() SetPreferencesRequest {
+	return SetPreferencesRequest{
+		EmailEnabled:      p.EmailEnabled,
+		PushEnabled:       p.PushEnabled,
+		SlackEnabled:      p.SlackEnabled,
+		WebhookEnabled:    p.WebhookEnabled,
+		ExecutionSuccess:  p.ExecutionSuccess,
+		ExecutionFailure:  p.ExecutionFailure,
+		WorkflowShared:    p.WorkflowShared,
+		TeamInvite:        p.TeamInvite,
+		BillingAlerts:     p.BillingAlerts,
+		SLAAlerts:         p.SLAAlerts,
+		AnomalyAlerts:     p.AnomalyAlerts,
+		ApprovalRequests:  p.ApprovalRequests,
+		SecurityAlerts:    p.SecurityAlerts,
+		ActivityDigest:    p.ActivityDigest,
+		Delivery:          p.Delivery,
+		DigestHour:        p.DigestHour,
+		QuietHoursEnabled: p.QuietHoursEnabled,
+		QuietHoursStart:   p.QuietHoursStart,
+		QuietHoursEnd:     p.QuietHoursEnd,
+		Timezone:          p.Timezone,
+	}
+}
+
+// Apply sets the preferences of a request
+func (p *Preferences) Apply(req SetPreferencesRequest) error {
+	if req.Delivery == "" {
+		req.Delivery = DeliveryImmediate
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if req.ActivityDigest == "" {
+		req.ActivityDigest = ActivityDigestWeekly
+	}
+	switch {
+	case req.Delivery != DeliveryImmediate && req.Delivery != DeliveryDigest:
+		return ErrInvalidPreferences.WithMessage("delivery is immediate or digest")
+	case req.ActivityDigest != ActivityDigestOff && req.ActivityDigest != ActivityDigestDaily && req.ActivityDigest != ActivityDigestWeekly:
+		return ErrInvalidPreferences.WithMessage("activityDigest is off, daily or weekly")
+	case req.DigestHour < 0 || req.DigestHour > 23:
+		return ErrInvalidPreferences.WithMessage("digestHour is an hour of the day")
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		return ErrInvalidPreferences.WithMessage("unknown timezone %s", req.Timezone)
+	}
+	if req.QuietHoursEnabled {
+		start, err := time.Parse(clockLayout, req.QuietHoursStart)
+		if err != nil {
+			return ErrInvalidPreferences.WithMessage("quietHoursStart is HH:MM")
+		}
+		end, err := time.Parse(clockLayout, req.QuietHoursEnd)
+		if err != nil {
+			return ErrInvalidPreferences.WithMessage("quietHoursEnd is HH:MM")
+		}
+		if start.Equal(end) {
+			return ErrInvalidPreferences.WithMessage("quiet hours cannot start when they end")
+		}
+	}
+
+	p.EmailEnabled = req.EmailEnabled
+	p.PushEnabled = req.PushEnabled
+	p.SlackEnabled = req.SlackEnabled
+	p.WebhookEnabled = req.WebhookEnabled
+	p.ExecutionSuccess = req.ExecutionSuccess
+	p.ExecutionFailure = req.ExecutionFailure
+	p.WorkflowShared = req.WorkflowShared
+	p.TeamInvite = req.TeamInvite
+	p.BillingAlerts = req.BillingAlerts
+	p.SLAAlerts = req.SLAAlerts
+	p.AnomalyAlerts = req.AnomalyAlerts
+	p.ApprovalRequests = req.ApprovalRequests
+	p.SecurityAlerts = req.SecurityAlerts
+	p.ActivityDigest = req.ActivityDigest
+	p.Delivery = req.Delivery
+	p.DigestHour = req.DigestHour
+	p.QuietHoursEnabled = req.QuietHoursEnabled
+	p.QuietHoursStart = req.QuietHoursStart
+	p.QuietHoursEnd = req.QuietHoursEnd
+	p.Timezone = req.Timezone
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// subscription returns the preference receiving a notification type, nil
+// for types every user receives
+func (p *Preferences) subscription(notifType string) *bool {
+	switch notifType {
+	case TypeExecutionSuccess:
+		return &p.ExecutionSuccess
+	case TypeExecutionFailure:
+		return &p.ExecutionFailure
+	case TypeWorkflowShared:
+		return &p.WorkflowShared
+	case TypeTeamInvite:
+		return &p.TeamInvite
+	case TypeBillingAlert:
+		return &p.BillingAlerts
+	case TypeSLAAlert:
+		return &p.SLAAlerts
+	case TypeAnomalyAlert:
+		return &p.AnomalyAlerts
+	case TypeApprovalRequest:
+		return &p.ApprovalRequests
+	case TypeSecurityAlert:
+		return &p.SecurityAlerts
+	}
+	return nil
+}
+
+// Wants reports whether the user receives notifications of a type
+func (p *Preferences) Wants(notifType string) bool {
+	if notifType == TypeActivityDigest {
+		return p.ActivityDigest != ActivityDigestOff
+	}
+	if subscribed := p.subscription(notifType); subscribed != nil {
+		return *subscribed
+	}
+	return true
+}
+
+// SetSubscribed subscribes the user to a notification type, or
+// unsubscribes them
+func (p *Preferences) SetSubscribed(notifType string, subscribed bool) error {
+	if notifType == TypeActivityDigest {
+		p.subscribeActivityDigest(subscribed)
+		return nil
+	}
+	preference := p.subscription(notifType)
+	if preference == nil {
+		return ErrUnknownNotificationType.WithMessage("notification type cannot be subscribed to: %s", notifType)
+	}
+	*preference = subscribed
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// ChannelTypes are the channels enabled besides the inbox
+func (p *Preferences) ChannelTypes() []string {
+	channels := []string{}
+	if p.EmailEnabled {
+		channels = append(channels, ChannelTypeEmail)
+	}
+	if p.PushEnabled {
+		channels = append(channels, ChannelTypePush)
+	}
+	if p.SlackEnabled {
+		channels = append(channels, ChannelTypeSlack)
+	}
+	if p.WebhookEnabled {
+		channels = append(channels, ChannelTypeWebhook)
+	}
+	return channels
+}
+
+// Location is the timezone of the user, UTC when unknown
+func (p *Preferences) Location() *time.Location {
+	if loc, err := time.LoadLocation(p.Timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// QuietUntil returns when the quiet hours around t end, and false when t is
+// not within quiet hours. Quiet hours ending before they start span
+// midnight.
+func (p *Preferences) QuietUntil(t time.Time) (time.Time, bool) {
+	if !p.QuietHoursEnabled {
+		return time.Time{}, false
+	}
+	start, err := parseClock(p.QuietHoursStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := parseClock(p.QuietHoursEnd)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := t.In(p.Location())
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	now := local.Sub(midnight)
+	switch {
+	case start < end && now >= start && now < end:
+		return midnight.Add(end), true
+	case start > end && now >= start:
+		return midnight.AddDate(0, 0, 1).Add(end), true
+	case start > end && now < end:
+		return midnight.Add(end), true
+	}
+	return time.Time{}, false
+}
+
+// NextDigest returns when the first digest after t is delivered
+func (p *Preferences) NextDigest(t time.Time) time.Time {
+	local := t.In(p.Location())
+	next := time.Date(local.Year(), local.Month(), local.Day(), p.DigestHour, 0, 0, 0, local.Location())
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Schedule sets the channels of a notification created at now, and holds it
+// for the digest or until the quiet hours end. Urgent notifications are not
+// held.
+func (p *Preferences) Schedule(n *Notification, now time.Time) {
+	n.Channels = p.ChannelTypes()
+	if n.Priority == PriorityUrgent {
+		return
+	}
+
+	if p.Delivery == DeliveryDigest {
+		at := p.NextDigest(now).UTC()
+		n.Status = StatusQueued
+		n.ScheduledAt = &at
+		return
+	}
+	if until, quiet := p.QuietUntil(now); quiet {
+		at := until.UTC()
+		n.ScheduledAt = &at
+	}
+}
+
+// parseClock parses HH:MM into the time since midnight
+func parseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse(clockLayout, clock)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -10,6 +10,7 @@ import (
 
 type User struct {
 	ID               string     `json:"id" gorm:"primaryKey"`
+	TenantID         string     `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
 	Email            string     `json:"email" gorm:"uniqueIndex;not null"`
 	Username         string     `json:"username" gorm:"uniqueIndex"`
 	Password         string     `json:"-" gorm:"column:password_hash;not null"`
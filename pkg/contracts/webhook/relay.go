@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"net/http"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var ErrRelayForbidden = apperrors.New(apperrors.CategoryPermission, "WEBHOOK_RELAY_FORBIDDEN", "only the owner of a webhook relays its requests")
+
+// relayRedactedHeaders authenticate the caller of a webhook, relays do not
+// receive them
+var relayRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Api-Key",
+}
+
+// RelayedRequest is a copy of a request a webhook accepted, sent to the
+// developers relaying the webhook. The request is still handled by the
+// webhook, the copy only lets them replay it locally.
+type RelayedRequest struct {
+	// ID is the webhook execution recording the request
+	ID          string            `json:"id"`
+	WebhookID   string            `json:"webhookId"`
+	Method      string            `json:"method"`
+	Path        string            `json:"path"`
+	Headers     map[string]string `json:"headers"`
+	QueryParams map[string]string `json:"queryParams"`
+	ContentType string            `json:"contentType"`
+	Body        string            `json:"body"`
+	ReceivedAt  time.Time         `json:"receivedAt"`
+}
+
+// NewRelayedRequest copies the request recorded by execution without the
+// headers authenticating its caller to the webhook
+func NewRelayedRequest(wh *Webhook, execution *WebhookExecution) *RelayedRequest {
+	headers := make(map[string]string, len(execution.Headers))
+	for name, value := range execution.Headers {
+		headers[name] = value
+	}
+	redacted := relayRedactedHeaders
+	if name := wh.AuthConfig["headerName"]; name != "" {
+		redacted = append([]string{name}, redacted...)
+	}
+	for _, name := range redacted {
+		delete(headers, http.CanonicalHeaderKey(name))
+	}
+
+	return &RelayedRequest{
+		ID:          execution.ID,
+		WebhookID:   wh.ID,
+		Method:      execution.Method,
+		Path:        execution.Path,
+		Headers:     headers,
+		QueryParams: execution.QueryParams,
+		ContentType: execution.ContentType,
+		Body:        execution.Body,
+		ReceivedAt:  execution.CreatedAt,
+	}
+}
+
+// RelayChannel are the parts of the Redis channel, within the namespace of
+// the tenant, the requests of a webhook are published on for its relays
+func RelayChannel(webhookID string) []string {
+	return []string{"webhook", "relay", webhookID}
+}
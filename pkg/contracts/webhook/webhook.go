@@ -8,14 +8,16 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	apperrors "github.com/linkflow-go/pkg/errors"
 )
 
 var (
-	ErrWebhookNotFound   = errors.New("webhook not found")
-	ErrWebhookDisabled   = errors.New("webhook is disabled")
-	ErrInvalidSignature  = errors.New("invalid webhook signature")
-	ErrWebhookExpired    = errors.New("webhook has expired")
-	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+	ErrWebhookNotFound   = apperrors.New(apperrors.CategoryNotFound, "WEBHOOK_NOT_FOUND", "webhook not found")
+	ErrWebhookDisabled   = apperrors.New(apperrors.CategoryConflict, "WEBHOOK_DISABLED", "webhook is disabled")
+	ErrInvalidSignature  = apperrors.New(apperrors.CategoryAuth, "INVALID_WEBHOOK_SIGNATURE", "invalid webhook signature")
+	ErrWebhookExpired    = apperrors.New(apperrors.CategoryConflict, "WEBHOOK_EXPIRED", "webhook has expired")
+	ErrRateLimitExceeded = apperrors.New(apperrors.CategoryRateLimit, "RATE_LIMITED", "rate limit exceeded")
+	ErrQuotaNotIncluded  = apperrors.New(apperrors.CategoryPermission, "PLAN_FEATURE_UNAVAILABLE", "plan does not include webhooks")
 )
 
 // Webhook represents a registered webhook endpoint
@@ -30,7 +32,7 @@ type Webhook struct {
 	Secret       string            `json:"secret"` // For HMAC signature verification
 	IsActive     bool              `json:"isActive" gorm:"default:true"`
 	RequireAuth  bool              `json:"requireAuth" gorm:"default:false"`
-	AuthType     string            `json:"authType"` // none, header, basic, bearer
+	AuthType     string            `json:"authType"` // none, header, basic, bearer, jwt
 	AuthConfig   map[string]string `json:"authConfig" gorm:"serializer:json"`
 	Headers      map[string]string `json:"headers" gorm:"column:headers_config;serializer:json"` // Required headers
 	RateLimit    int               `json:"rateLimit" gorm:"default:100"`                         // requests per minute
@@ -60,7 +62,7 @@ type WebhookExecution struct {
 	ContentType  string                 `json:"contentType"`
 	IPAddress    string                 `json:"ipAddress"`
 	UserAgent    string                 `json:"userAgent"`
-	Status       string                 `json:"status"` // received, processed, failed
+	Status       string                 `json:"status"` // received, processed, cached, failed
 	ResponseCode int                    `json:"responseCode"`
 	ResponseBody string                 `json:"responseBody"`
 	Error        string                 `json:"error"`
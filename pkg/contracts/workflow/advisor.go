@@ -0,0 +1,242 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kinds of performance advice
+const (
+	AdviceParallelChain = "parallel_chain"
+	AdviceNPlusOneLoop  = "n_plus_one_loop"
+)
+
+// estimatedNodeDurations are typical run times of node types, the basis of
+// the estimated duration of a workflow
+var estimatedNodeDurations = map[string]time.Duration{
+	NodeTypeHTTPRequest: 300 * time.Millisecond,
+	NodeTypeDatabase:    50 * time.Millisecond,
+	NodeTypeEmail:       500 * time.Millisecond,
+	NodeTypeSlack:       300 * time.Millisecond,
+	NodeTypeAction:      100 * time.Millisecond,
+	NodeTypeCode:        20 * time.Millisecond,
+}
+
+// defaultNodeDuration is the estimate of node types not listed, control
+// nodes mostly
+const defaultNodeDuration = 5 * time.Millisecond
+
+// ioNodeTypes are the node types waiting on another system, those gaining
+// from running in parallel or in batches
+var ioNodeTypes = map[string]bool{
+	NodeTypeHTTPRequest: true,
+	NodeTypeDatabase:    true,
+	NodeTypeEmail:       true,
+	NodeTypeSlack:       true,
+	NodeTypeAction:      true,
+}
+
+// Advice is a change that would make a workflow run faster
+type Advice struct {
+	Kind       string   `json:"kind"`
+	Message    string   `json:"message"`
+	Suggestion string   `json:"suggestion"`
+	NodeIDs    []string `json:"nodeIds"`
+}
+
+// PerformanceReport estimates how long a workflow runs and how to make it
+// faster
+type PerformanceReport struct {
+	// CriticalPath is the slowest chain of nodes, the one bounding the run
+	CriticalPath        []string `json:"criticalPath"`
+	EstimatedDurationMs int64    `json:"estimatedDurationMs"`
+	Advice              []Advice `json:"advice"`
+}
+
+// EstimatedNodeDuration is the typical run time of a node of a type
+func EstimatedNodeDuration(nodeType string) time.Duration {
+	if duration, ok := estimatedNodeDurations[nodeType]; ok {
+		return duration
+	}
+	return defaultNodeDuration
+}
+
+// Advise estimates the critical path of the workflow from the typical run
+// time of its nodes, for one item per loop, and looks for serial chains
+// that could run in parallel and loops calling a service once per item
+func (d *DAG) Advise() *PerformanceReport {
+	report := &PerformanceReport{CriticalPath: []string{}, Advice: []Advice{}}
+
+	path, duration := d.weightedCriticalPath()
+	report.CriticalPath = path
+	report.EstimatedDurationMs = duration.Milliseconds()
+
+	report.Advice = append(report.Advice, d.parallelChains()...)
+	report.Advice = append(report.Advice, d.nPlusOneLoops()...)
+	return report
+}
+
+// weightedCriticalPath returns the chain of nodes with the longest estimated
+// run time, nothing for a graph with a cycle
+func (d *DAG) weightedCriticalPath() ([]string, time.Duration) {
+	order, err := d.GetTopologicalOrder()
+	if err != nil {
+		return []string{}, 0
+	}
+
+	finish := make(map[string]time.Duration)
+	parent := make(map[string]string)
+	var last string
+	for _, nodeID := range order {
+		node, ok := d.Nodes[nodeID]
+		if !ok || node.Disabled {
+			continue
+		}
+		finish[nodeID] += EstimatedNodeDuration(node.Type)
+		if last == "" || finish[nodeID] > finish[last] {
+			last = nodeID
+		}
+		for _, next := range d.Edges[nodeID] {
+			if _, ok := d.Nodes[next]; !ok {
+				continue
+			}
+			if finish[nodeID] > finish[next] {
+				finish[next] = finish[nodeID]
+				parent[next] = nodeID
+			}
+		}
+	}
+
+	path := []string{}
+	for current := last; current != ""; current = parent[current] {
+		path = append([]string{current}, path...)
+	}
+	return path, finish[last]
+}
+
+// parallelChains finds chains of service calls where each only follows the
+// one before it and reads none of its output. They wait on each other for
+// nothing and could run side by side.
+func (d *DAG) parallelChains() []Advice {
+	predecessors := make(map[string][]string)
+	for source, targets := range d.Edges {
+		for _, target := range targets {
+			predecessors[target] = append(predecessors[target], source)
+		}
+	}
+
+	// follows reports whether a call only runs after the call prev and
+	// ignores its output
+	follows := func(prev, nodeID string) bool {
+		previous, ok := d.Nodes[prev]
+		if !ok || !ioNodeTypes[previous.Type] {
+			return false
+		}
+		node, ok := d.Nodes[nodeID]
+		if !ok || !ioNodeTypes[node.Type] || node.Disabled {
+			return false
+		}
+		return len(d.Edges[prev]) == 1 && len(predecessors[nodeID]) == 1 && !readsInput(node.Parameters)
+	}
+
+	var advice []Advice
+	inChain := make(map[string]bool)
+	for _, node := range d.workflow.Nodes {
+		if inChain[node.ID] || !ioNodeTypes[node.Type] || node.Disabled {
+			continue
+		}
+		// Start chains at their first call
+		if preds := predecessors[node.ID]; len(preds) == 1 && follows(preds[0], node.ID) {
+			continue
+		}
+
+		chain := []string{node.ID}
+		for current := node.ID; len(d.Edges[current]) == 1; {
+			next := d.Edges[current][0]
+			if !follows(current, next) || inChain[next] {
+				break
+			}
+			chain = append(chain, next)
+			current = next
+		}
+		if len(chain) < 2 {
+			continue
+		}
+
+		var saved time.Duration
+		for _, nodeID := range chain[1:] {
+			saved += EstimatedNodeDuration(d.Nodes[nodeID].Type)
+		}
+		for _, nodeID := range chain {
+			inChain[nodeID] = true
+		}
+		advice = append(advice, Advice{
+			Kind: AdviceParallelChain,
+			Message: fmt.Sprintf("Nodes %s run one after another but none reads the output of the node before it",
+				strings.Join(chain, ", ")),
+			Suggestion: fmt.Sprintf("Connect them side by side from the node before %s and join them with a merge node, about %dms faster",
+				chain[0], saved.Milliseconds()),
+			NodeIDs: chain,
+		})
+	}
+	return advice
+}
+
+// readsInput reports whether node parameters use expressions, other than
+// secret references, reading the data of earlier nodes
+func readsInput(parameters map[string]interface{}) bool {
+	reads := false
+	walkStrings(parameters, func(s string) {
+		if strings.Contains(secretReferencePattern.ReplaceAllString(s, ""), "{{") {
+			reads = true
+		}
+	})
+	return reads
+}
+
+// nPlusOneLoops finds loops handling one item at a time that call services
+// for each item, where batching would save a round trip per item
+func (d *DAG) nPlusOneLoops() []Advice {
+	var advice []Advice
+	for _, node := range d.workflow.Nodes {
+		if node.Type != NodeTypeLoop || node.Disabled {
+			continue
+		}
+		if batchSize, _ := node.Parameters["batchSize"].(float64); batchSize > 1 {
+			continue
+		}
+
+		var calls []string
+		databases := 0
+		for _, nodeID := range d.GetDescendants(node.ID) {
+			body, ok := d.Nodes[nodeID]
+			if !ok || body.Disabled {
+				continue
+			}
+			switch body.Type {
+			case NodeTypeHTTPRequest:
+				calls = append(calls, nodeID)
+			case NodeTypeDatabase:
+				calls = append(calls, nodeID)
+				databases++
+			}
+		}
+		if len(calls) == 0 {
+			continue
+		}
+
+		suggestion := "Set a batchSize on the loop and call a bulk endpoint once per batch"
+		if databases == len(calls) {
+			suggestion = "Set a batchSize on the loop and write each batch with one database operation"
+		}
+		advice = append(advice, Advice{
+			Kind: AdviceNPlusOneLoop,
+			Message: fmt.Sprintf("Loop %s calls %s once per item",
+				node.ID, strings.Join(calls, ", ")),
+			Suggestion: suggestion,
+			NodeIDs:    append([]string{node.ID}, calls...),
+		})
+	}
+	return advice
+}
@@ -0,0 +1,133 @@
+package workflow
+
+import (
+	"regexp"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var ErrInvalidAnnotation = apperrors.New(apperrors.CategoryValidation, "INVALID_ANNOTATION", "invalid workflow annotation")
+
+// Kinds of annotations documenting a workflow in the editor
+const (
+	// AnnotationNote is a sticky note placed on the canvas
+	AnnotationNote = "note"
+	// AnnotationDescription describes a node, shown alongside it
+	AnnotationDescription = "description"
+	// AnnotationGroup frames nodes together under a title and a color
+	AnnotationGroup = "group"
+)
+
+// annotationColor is a hex color, such as #ffd54f
+var annotationColor = regexp.MustCompile(`^#([0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$`)
+
+// Annotation documents a workflow without taking part in its runs. It is
+// saved and versioned with the nodes, so notes follow the workflow through
+// exports, imports and rollbacks, but is left out of the checksum of the
+// definition.
+type Annotation struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// Text is the content of a note or description, the title of a group
+	Text  string `json:"text,omitempty"`
+	Color string `json:"color,omitempty"`
+	// Position and Size place notes and groups on the canvas
+	Position Position `json:"position"`
+	Size     *Size    `json:"size,omitempty"`
+	// NodeID is the node a description describes
+	NodeID string `json:"nodeId,omitempty"`
+	// NodeIDs are the nodes of a group
+	NodeIDs []string `json:"nodeIds,omitempty"`
+}
+
+// Size is the width and height of an annotation on the canvas
+type Size struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// ValidateAnnotations checks the annotations are of a known kind, unique
+// and describe or group nodes of the workflow
+func (w *Workflow) ValidateAnnotations() error {
+	if len(w.Annotations) == 0 {
+		return nil
+	}
+
+	nodes := make(map[string]bool, len(w.Nodes))
+	for _, node := range w.Nodes {
+		nodes[node.ID] = true
+	}
+
+	ids := make(map[string]bool, len(w.Annotations))
+	described := make(map[string]bool)
+	for _, annotation := range w.Annotations {
+		if annotation.ID == "" {
+			return ErrInvalidAnnotation.WithMessage("annotation has no ID")
+		}
+		if ids[annotation.ID] {
+			return ErrInvalidAnnotation.WithMessage("annotation %s is declared twice", annotation.ID)
+		}
+		ids[annotation.ID] = true
+
+		if annotation.Color != "" && !annotationColor.MatchString(annotation.Color) {
+			return ErrInvalidAnnotation.WithMessage("annotation %s has invalid color %q", annotation.ID, annotation.Color)
+		}
+		if annotation.Size != nil && (annotation.Size.Width < 0 || annotation.Size.Height < 0) {
+			return ErrInvalidAnnotation.WithMessage("annotation %s has a negative size", annotation.ID)
+		}
+
+		switch annotation.Type {
+		case AnnotationNote:
+		case AnnotationDescription:
+			if !nodes[annotation.NodeID] {
+				return ErrInvalidAnnotation.WithMessage("annotation %s describes unknown node %q", annotation.ID, annotation.NodeID)
+			}
+			if described[annotation.NodeID] {
+				return ErrInvalidAnnotation.WithMessage("node %s has more than one description", annotation.NodeID)
+			}
+			described[annotation.NodeID] = true
+		case AnnotationGroup:
+			for _, nodeID := range annotation.NodeIDs {
+				if !nodes[nodeID] {
+					return ErrInvalidAnnotation.WithMessage("group %s holds unknown node %q", annotation.ID, nodeID)
+				}
+			}
+		default:
+			return ErrInvalidAnnotation.WithMessage("annotation %s is of unknown type %q", annotation.ID, annotation.Type)
+		}
+	}
+	return nil
+}
+
+// PruneAnnotations drops the descriptions of nodes the workflow no longer
+// has and removes them from groups, for saves changing the nodes alone
+func (w *Workflow) PruneAnnotations() {
+	if len(w.Annotations) == 0 {
+		return
+	}
+
+	nodes := make(map[string]bool, len(w.Nodes))
+	for _, node := range w.Nodes {
+		nodes[node.ID] = true
+	}
+
+	pruned := make([]Annotation, 0, len(w.Annotations))
+	for _, annotation := range w.Annotations {
+		switch annotation.Type {
+		case AnnotationDescription:
+			if !nodes[annotation.NodeID] {
+				continue
+			}
+		case AnnotationGroup:
+			kept := make([]string, 0, len(annotation.NodeIDs))
+			for _, nodeID := range annotation.NodeIDs {
+				if nodes[nodeID] {
+					kept = append(kept, nodeID)
+				}
+			}
+			annotation.NodeIDs = kept
+		}
+		pruned = append(pruned, annotation)
+	}
+	w.Annotations = pruned
+}
@@ -0,0 +1,223 @@
+package workflow
+
+import (
+	"regexp"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// Actions of the changes an apply plans
+const (
+	ApplyCreate = "create"
+	ApplyUpdate = "update"
+	ApplyDelete = "delete"
+	ApplyNoop   = "noop"
+)
+
+// Kinds of the resources an apply reconciles
+const (
+	ApplyKindWorkflow = "workflow"
+	ApplyKindTrigger  = "trigger"
+	ApplyKindVariable = "variable"
+)
+
+// DefaultApplyNamespace is the namespace of a bundle that names none
+const DefaultApplyNamespace = "default"
+
+var (
+	ErrInvalidApplyBundle = apperrors.New(apperrors.CategoryValidation, "INVALID_APPLY_BUNDLE", "invalid apply bundle")
+	ErrUnknownCredential  = apperrors.New(apperrors.CategoryValidation, "UNKNOWN_CREDENTIAL", "credential not found")
+)
+
+// applyKeyPattern keeps namespaces and workflow keys to identifiers a
+// Terraform configuration can hold
+var applyKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// ApplyBundle is the declared state of the workflows of a namespace. An
+// apply reconciles the workflows created by earlier applies of the
+// namespace with it, so applying the same bundle again changes nothing.
+type ApplyBundle struct {
+	// Namespace isolates bundles from each other, as the states of
+	// separate Terraform configurations
+	Namespace string          `json:"namespace"`
+	Workflows []ApplyWorkflow `json:"workflows"`
+	// Prune deletes the workflows of the namespace the bundle leaves out
+	Prune bool `json:"prune"`
+}
+
+// ApplyWorkflow declares a workflow with its triggers and variables. Key
+// identifies it within its namespace, the name can change.
+type ApplyWorkflow struct {
+	Key         string                 `json:"key"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Nodes       []Node                 `json:"nodes"`
+	Connections []Connection           `json:"connections"`
+	Settings    map[string]interface{} `json:"settings,omitempty"`
+	// Credentials name the credential of nodes, keyed by node ID, resolved
+	// among the credentials of the caller
+	Credentials map[string]string `json:"credentials,omitempty"`
+	// Active activates or deactivates the workflow, left as it is when nil
+	Active    *bool           `json:"active,omitempty"`
+	Triggers  []ApplyTrigger  `json:"triggers,omitempty"`
+	Variables []ApplyVariable `json:"variables,omitempty"`
+}
+
+// ApplyTrigger declares a trigger of a workflow, identified by its name
+type ApplyTrigger struct {
+	Name   string                 `json:"name"`
+	Type   string                 `json:"type"`
+	Config map[string]interface{} `json:"config,omitempty"`
+}
+
+// ApplyVariable declares a variable of a workflow, identified by its key.
+// Secret values are given in plaintext and sealed like any secret variable.
+type ApplyVariable struct {
+	Key         string      `json:"key"`
+	Type        string      `json:"type"`
+	Value       interface{} `json:"value"`
+	Description string      `json:"description,omitempty"`
+}
+
+// ManagedWorkflow records a workflow created by an apply, so the later
+// applies of its namespace update or prune it
+type ManagedWorkflow struct {
+	ID          string `json:"id" gorm:"primaryKey"`
+	WorkspaceID string `json:"workspaceId" gorm:"not null;uniqueIndex:idx_managed_workflows_key"`
+	Namespace   string `json:"namespace" gorm:"not null;uniqueIndex:idx_managed_workflows_key"`
+	Key         string `json:"key" gorm:"not null;uniqueIndex:idx_managed_workflows_key"`
+	WorkflowID  string `json:"workflowId" gorm:"not null;index"`
+	// Spec is the declaration last applied. Triggers are compared with it,
+	// the configuration they keep is not the one they were declared with.
+	Spec      ApplyWorkflow `json:"spec" gorm:"serializer:json"`
+	AppliedBy string        `json:"appliedBy"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (ManagedWorkflow) TableName() string {
+	return "workflow.managed_workflows"
+}
+
+// ApplyChange is a change an apply makes, or would make, to a resource
+type ApplyChange struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	// Key is the key of the workflow, Name the name of the workflow,
+	// trigger or variable changed
+	Key        string `json:"key"`
+	Name       string `json:"name"`
+	WorkflowID string `json:"workflowId,omitempty"`
+	// Fields are the properties an update changes
+	Fields []string `json:"fields,omitempty"`
+}
+
+// ApplyPlan lists the changes reconciling a namespace with a bundle, made
+// unless the apply was a dry run
+type ApplyPlan struct {
+	Namespace string        `json:"namespace"`
+	Applied   bool          `json:"applied"`
+	Changes   []ApplyChange `json:"changes"`
+	Creates   int           `json:"creates"`
+	Updates   int           `json:"updates"`
+	Deletes   int           `json:"deletes"`
+}
+
+// Add records a change, counting it by action
+func (p *ApplyPlan) Add(change ApplyChange) {
+	switch change.Action {
+	case ApplyCreate:
+		p.Creates++
+	case ApplyUpdate:
+		p.Updates++
+	case ApplyDelete:
+		p.Deletes++
+	}
+	p.Changes = append(p.Changes, change)
+}
+
+// Empty reports whether the plan changes nothing
+func (p *ApplyPlan) Empty() bool {
+	return p.Creates == 0 && p.Updates == 0 && p.Deletes == 0
+}
+
+// Validate checks the namespace, keys and names of a bundle, defaulting
+// the namespace. Definitions are validated as workflows once resolved.
+func (b *ApplyBundle) Validate() error {
+	if b.Namespace == "" {
+		b.Namespace = DefaultApplyNamespace
+	}
+	if !applyKeyPattern.MatchString(b.Namespace) {
+		return ErrInvalidApplyBundle.WithMessage("invalid namespace %q", b.Namespace)
+	}
+
+	keys := make(map[string]bool, len(b.Workflows))
+	for _, wf := range b.Workflows {
+		if !applyKeyPattern.MatchString(wf.Key) {
+			return ErrInvalidApplyBundle.WithMessage("invalid workflow key %q", wf.Key)
+		}
+		if keys[wf.Key] {
+			return ErrInvalidApplyBundle.WithMessage("workflow key %q is declared twice", wf.Key)
+		}
+		keys[wf.Key] = true
+		if err := wf.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *ApplyWorkflow) validate() error {
+	if w.Name == "" {
+		return ErrInvalidApplyBundle.WithMessage("workflow %s has no name", w.Key)
+	}
+
+	nodes := make(map[string]bool, len(w.Nodes))
+	for _, node := range w.Nodes {
+		nodes[node.ID] = true
+	}
+	for nodeID, name := range w.Credentials {
+		if !nodes[nodeID] {
+			return ErrInvalidApplyBundle.WithMessage("workflow %s names a credential for unknown node %s", w.Key, nodeID)
+		}
+		if name == "" {
+			return ErrInvalidApplyBundle.WithMessage("workflow %s names no credential for node %s", w.Key, nodeID)
+		}
+	}
+
+	triggers := make(map[string]bool, len(w.Triggers))
+	for _, trigger := range w.Triggers {
+		if trigger.Name == "" || trigger.Type == "" {
+			return ErrInvalidApplyBundle.WithMessage("triggers of workflow %s need a name and a type", w.Key)
+		}
+		if triggers[trigger.Name] {
+			return ErrInvalidApplyBundle.WithMessage("trigger %q of workflow %s is declared twice", trigger.Name, w.Key)
+		}
+		triggers[trigger.Name] = true
+	}
+
+	variables := make(map[string]bool, len(w.Variables))
+	for _, variable := range w.Variables {
+		if err := ValidateVariableName(variable.Key); err != nil {
+			return ErrInvalidApplyBundle.WithMessage("workflow %s: %v", w.Key, err)
+		}
+		if variables[variable.Key] {
+			return ErrInvalidApplyBundle.WithMessage("variable %q of workflow %s is declared twice", variable.Key, w.Key)
+		}
+		variables[variable.Key] = true
+	}
+	return nil
+}
+
+// Trigger returns the declared trigger named name, nil when there is none
+func (w *ApplyWorkflow) Trigger(name string) *ApplyTrigger {
+	for i := range w.Triggers {
+		if w.Triggers[i].Name == name {
+			return &w.Triggers[i]
+		}
+	}
+	return nil
+}
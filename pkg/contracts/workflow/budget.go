@@ -0,0 +1,123 @@
+package workflow
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// Budget scopes
+const (
+	// BudgetScopeWorkflow bounds the executions of one workflow
+	BudgetScopeWorkflow = "workflow"
+	// BudgetScopeWorkspace bounds the executions of all the workflows of a
+	// team, or of a user outside any team
+	BudgetScopeWorkspace = "workspace"
+)
+
+// BudgetThresholds are the percents of its monthly limit at which the spend
+// of a budget is reported, once per month each
+var BudgetThresholds = []int{50, 80, 100}
+
+var (
+	ErrBudgetNotFound = apperrors.New(apperrors.CategoryNotFound, "BUDGET_NOT_FOUND", "budget not found")
+	ErrInvalidBudget  = apperrors.New(apperrors.CategoryValidation, "INVALID_BUDGET", "invalid budget")
+)
+
+// Budget is a monthly cost limit on the executions of a workflow or of a
+// workspace, in the currency of the cost model. Spend is counted per
+// calendar month in UTC. Exceeding the limit only alerts, unless HardCap is
+// set, then the workflows of the scope are deactivated.
+type Budget struct {
+	ID           string    `json:"id" gorm:"primaryKey"`
+	TenantID     string    `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
+	Scope        string    `json:"scope" gorm:"not null;uniqueIndex:idx_budgets_scope"`
+	ScopeID      string    `json:"scopeId" gorm:"not null;uniqueIndex:idx_budgets_scope"`
+	MonthlyLimit float64   `json:"monthlyLimit"`
+	HardCap      bool      `json:"hardCap"`
+	UpdatedBy    string    `json:"updatedBy"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (Budget) TableName() string {
+	return "workflow.budgets"
+}
+
+// SetBudgetRequest sets the budget of a workflow or workspace
+type SetBudgetRequest struct {
+	MonthlyLimit float64 `json:"monthlyLimit" binding:"required"`
+	HardCap      bool    `json:"hardCap"`
+}
+
+// BudgetStatus is a budget with its spend in the current month
+type BudgetStatus struct {
+	*Budget
+	Month string  `json:"month"`
+	Spent float64 `json:"spent"`
+	// Percent is the share of the monthly limit spent
+	Percent float64 `json:"percent"`
+}
+
+// NewBudget creates the budget of a scope
+func NewBudget(scope, scopeID string, req SetBudgetRequest, updatedBy string) (*Budget, error) {
+	now := time.Now().UTC()
+	b := &Budget{
+		ID:        uuid.New().String(),
+		Scope:     scope,
+		ScopeID:   scopeID,
+		CreatedAt: now,
+	}
+	if err := b.Apply(req, updatedBy); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Apply sets the limit and cap of a request on the budget
+func (b *Budget) Apply(req SetBudgetRequest, updatedBy string) error {
+	if req.MonthlyLimit <= 0 {
+		return ErrInvalidBudget.WithMessage("monthlyLimit must be positive")
+	}
+	b.MonthlyLimit = req.MonthlyLimit
+	b.HardCap = req.HardCap
+	b.UpdatedBy = updatedBy
+	b.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Crossed returns the thresholds reached when the spend of the month went
+// from before to after
+func (b *Budget) Crossed(before, after float64) []int {
+	var crossed []int
+	for _, threshold := range BudgetThresholds {
+		at := b.MonthlyLimit * float64(threshold) / 100
+		if before < at && after >= at {
+			crossed = append(crossed, threshold)
+		}
+	}
+	return crossed
+}
+
+// Status reports the spend of the budget in month
+func (b *Budget) Status(month string, spent float64) *BudgetStatus {
+	return &BudgetStatus{
+		Budget:  b,
+		Month:   month,
+		Spent:   spent,
+		Percent: spent / b.MonthlyLimit * 100,
+	}
+}
+
+// BudgetMonth is the month spend at t is counted in
+func BudgetMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// BudgetSpendKey are the parts of the Redis key, within the namespace of the
+// tenant, counting the spend of a scope in a month
+func BudgetSpendKey(scope, scopeID, month string) []string {
+	return []string{"budget", "spend", scope, scopeID, month}
+}
@@ -0,0 +1,242 @@
+package workflow
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// Canary statuses
+const (
+	CanaryRunning    = "running"
+	CanaryPromoted   = "promoted"
+	CanaryRolledBack = "rolled_back"
+	CanaryCancelled  = "cancelled"
+)
+
+// Rollout strategies. A canary compares a small share of executions of the
+// new version against the stable one and decides on its own; a blue/green
+// rollout runs both versions side by side, traffic shifted between them by
+// hand, and is only decided automatically when the new version fails too
+// often.
+const (
+	RolloutCanary    = "canary"
+	RolloutBlueGreen = "blue_green"
+)
+
+// Canary decisions returned by Evaluate
+const (
+	CanaryContinue = ""
+	CanaryPromote  = "promote"
+	CanaryRollback = "rollback"
+)
+
+// Default canary thresholds
+const (
+	DefaultCanaryMinExecutions     = 20
+	DefaultCanaryMaxErrorRateDelta = 0.05
+	DefaultCanaryMaxDurationRatio  = 1.5
+	// DefaultBlueGreenMaxFailureRate is the failure rate of the new version
+	// rolling back a blue/green rollout
+	DefaultBlueGreenMaxFailureRate = 0.1
+)
+
+var (
+	ErrCanaryNotFound      = apperrors.New(apperrors.CategoryNotFound, "CANARY_NOT_FOUND", "no canary is running for this workflow")
+	ErrCanaryAlreadyActive = apperrors.New(apperrors.CategoryConflict, "CANARY_ALREADY_RUNNING", "a canary is already running for this workflow")
+	ErrInvalidCanary       = apperrors.New(apperrors.CategoryValidation, "INVALID_CANARY", "invalid canary configuration")
+)
+
+// Canary routes a share of trigger fires to a new workflow version while the
+// rest keep running the previous release, until the versions are compared
+// and the new one is promoted or rolled back
+type Canary struct {
+	ID            string           `json:"id" gorm:"primaryKey"`
+	WorkflowID    string           `json:"workflowId" gorm:"not null;index"`
+	CanaryVersion int              `json:"canaryVersion"`
+	StableVersion int              `json:"stableVersion"`
+	Strategy      string           `json:"strategy" gorm:"default:'canary'"`
+	Percentage    int              `json:"percentage"`
+	Thresholds    CanaryThresholds `json:"thresholds" gorm:"serializer:json"`
+	Status        string           `json:"status" gorm:"default:'running'"`
+	Reason        string           `json:"reason,omitempty"`
+	CreatedBy     string           `json:"createdBy"`
+	StartedAt     time.Time        `json:"startedAt"`
+	DecidedAt     *time.Time       `json:"decidedAt,omitempty"`
+}
+
+// TableName specifies the table name for GORM
+func (Canary) TableName() string {
+	return "workflow.workflow_canaries"
+}
+
+// CanaryThresholds decide when a canary is compared and whether it passes
+type CanaryThresholds struct {
+	// MinExecutions is the number of finished executions each version needs
+	// before they are compared
+	MinExecutions int `json:"minExecutions"`
+	// MaxErrorRateDelta is how much higher, in absolute terms, the canary
+	// error rate may be than the stable one
+	MaxErrorRateDelta float64 `json:"maxErrorRateDelta"`
+	// MaxDurationRatio is how many times the stable average duration the
+	// canary average duration may be
+	MaxDurationRatio float64 `json:"maxDurationRatio"`
+	// MaxFailureRate rolls back once the failure rate of the new version,
+	// over at least MinExecutions, exceeds it whatever the stable one does.
+	// 0 disables it for canaries.
+	MaxFailureRate float64 `json:"maxFailureRate,omitempty"`
+}
+
+// CanaryStats summarizes the finished executions of one version
+type CanaryStats struct {
+	Version     int     `json:"version"`
+	Executions  int64   `json:"executions"`
+	Failures    int64   `json:"failures"`
+	ErrorRate   float64 `json:"errorRate"`
+	AvgDuration float64 `json:"avgDurationMs"`
+}
+
+// CanaryReport is a canary with the current stats of both versions
+type CanaryReport struct {
+	Canary *Canary      `json:"canary"`
+	Stable *CanaryStats `json:"stable"`
+	New    *CanaryStats `json:"new"`
+}
+
+// StartCanaryRequest starts a canary for the current version of a workflow
+type StartCanaryRequest struct {
+	// StableVersion defaults to the latest saved version before the current one
+	StableVersion int `json:"stableVersion"`
+	// Strategy is canary, the default, or blue_green
+	Strategy   string           `json:"strategy"`
+	Percentage int              `json:"percentage" binding:"min=0,max=100"`
+	Thresholds CanaryThresholds `json:"thresholds"`
+}
+
+// CanaryTrafficRequest shifts the share of executions running the new
+// version
+type CanaryTrafficRequest struct {
+	Percentage *int `json:"percentage" binding:"required,min=0,max=100"`
+}
+
+// ApplyDefaults fills unset thresholds
+func (t *CanaryThresholds) ApplyDefaults() {
+	if t.MinExecutions <= 0 {
+		t.MinExecutions = DefaultCanaryMinExecutions
+	}
+	if t.MaxErrorRateDelta <= 0 {
+		t.MaxErrorRateDelta = DefaultCanaryMaxErrorRateDelta
+	}
+	if t.MaxDurationRatio <= 0 {
+		t.MaxDurationRatio = DefaultCanaryMaxDurationRatio
+	}
+}
+
+// ApplyDefaults fills the unset strategy and thresholds
+func (c *Canary) ApplyDefaults() {
+	if c.Strategy == "" {
+		c.Strategy = RolloutCanary
+	}
+	c.Thresholds.ApplyDefaults()
+	if c.Strategy == RolloutBlueGreen && c.Thresholds.MaxFailureRate <= 0 {
+		c.Thresholds.MaxFailureRate = DefaultBlueGreenMaxFailureRate
+	}
+}
+
+// Validate checks the canary can route and be evaluated. Blue/green rollouts
+// may route no execution or every execution to the new version, canaries
+// always compare both.
+func (c *Canary) Validate() error {
+	switch c.Strategy {
+	case RolloutCanary:
+		if c.Percentage < 1 || c.Percentage > 99 {
+			return ErrInvalidCanary.WithMessage("percentage must be between 1 and 99")
+		}
+	case RolloutBlueGreen:
+		if c.Percentage < 0 || c.Percentage > 100 {
+			return ErrInvalidCanary.WithMessage("percentage must be between 0 and 100")
+		}
+	default:
+		return ErrInvalidCanary.WithMessage("unknown strategy %q, expected %s or %s", c.Strategy, RolloutCanary, RolloutBlueGreen)
+	}
+	if c.Thresholds.MaxFailureRate < 0 || c.Thresholds.MaxFailureRate > 1 {
+		return ErrInvalidCanary.WithMessage("maxFailureRate must be between 0 and 1")
+	}
+	if c.StableVersion >= c.CanaryVersion {
+		return ErrInvalidCanary.WithMessage("stable version %d must be older than canary version %d", c.StableVersion, c.CanaryVersion)
+	}
+	return nil
+}
+
+// IsRunning reports whether the canary still routes executions
+func (c *Canary) IsRunning() bool {
+	return c.Status == CanaryRunning
+}
+
+// Route returns the version to run for key. Routing hashes the key so the
+// same execution always lands on the same version.
+func (c *Canary) Route(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	if int(h.Sum32()%100) < c.Percentage {
+		return c.CanaryVersion
+	}
+	return c.StableVersion
+}
+
+// Evaluate compares the two versions against the thresholds and returns a
+// decision with the reason for it
+func (c *Canary) Evaluate(stable, canary *CanaryStats) (string, string) {
+	required := int64(c.Thresholds.MinExecutions)
+
+	// The failure rate of the new version is judged on its own
+	if limit := c.Thresholds.MaxFailureRate; limit > 0 && canary.Executions >= required && canary.ErrorRate > limit {
+		return CanaryRollback, fmt.Sprintf("failure rate %.1f%% exceeds %.1f%%", canary.ErrorRate*100, limit*100)
+	}
+
+	// Blue/green rollouts are promoted by hand
+	if c.Strategy == RolloutBlueGreen {
+		return CanaryContinue, ""
+	}
+	if stable.Executions < required || canary.Executions < required {
+		return CanaryContinue, ""
+	}
+
+	stableRate, canaryRate := stable.ErrorRate, canary.ErrorRate
+	if canaryRate > stableRate+c.Thresholds.MaxErrorRateDelta {
+		return CanaryRollback, fmt.Sprintf("error rate %.1f%% exceeds stable %.1f%% by more than %.1f points",
+			canaryRate*100, stableRate*100, c.Thresholds.MaxErrorRateDelta*100)
+	}
+
+	if stable.AvgDuration > 0 && canary.AvgDuration > stable.AvgDuration*c.Thresholds.MaxDurationRatio {
+		return CanaryRollback, fmt.Sprintf("average duration %.0fms exceeds %.1fx stable %.0fms",
+			canary.AvgDuration, c.Thresholds.MaxDurationRatio, stable.AvgDuration)
+	}
+
+	return CanaryPromote, fmt.Sprintf("error rate %.1f%% and average duration %.0fms within thresholds",
+		canaryRate*100, canary.AvgDuration)
+}
+
+// Decide records the outcome of the canary
+func (c *Canary) Decide(status, reason string) {
+	now := time.Now()
+	c.Status = status
+	c.Reason = reason
+	c.DecidedAt = &now
+}
+
+// NewCanaryStats builds the stats of a version, deriving its error rate
+func NewCanaryStats(version int, executions, failures int64, avgDuration float64) *CanaryStats {
+	stats := &CanaryStats{
+		Version:     version,
+		Executions:  executions,
+		Failures:    failures,
+		AvgDuration: avgDuration,
+	}
+	if executions > 0 {
+		stats.ErrorRate = float64(failures) / float64(executions)
+	}
+	return stats
+}
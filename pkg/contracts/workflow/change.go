@@ -0,0 +1,86 @@
+package workflow
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// WorkflowChange records what a save of a workflow changed and who saved
+// it, field by field. Versions keep the full definitions, changes the
+// summary of each edit for a history feed.
+type WorkflowChange struct {
+	ID         string `json:"id" gorm:"primaryKey"`
+	TenantID   string `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
+	WorkflowID string `json:"workflowId" gorm:"not null;index:idx_workflow_changes_workflow"`
+	// Version is the version the save made
+	Version int    `json:"version"`
+	UserID  string `json:"userId" gorm:"not null;index"`
+	// Fields names the properties of the workflow that changed, nodes and
+	// connections included
+	Fields []string `json:"fields" gorm:"serializer:json"`
+	// Settings names the settings that changed
+	Settings    []string           `json:"settings,omitempty" gorm:"serializer:json"`
+	Nodes       []NodeChange       `json:"nodes,omitempty" gorm:"serializer:json"`
+	Connections []ConnectionChange `json:"connections,omitempty" gorm:"serializer:json"`
+	CreatedAt   time.Time          `json:"createdAt" gorm:"index:idx_workflow_changes_workflow"`
+}
+
+// TableName specifies the table name for GORM
+func (WorkflowChange) TableName() string {
+	return "workflow.workflow_changes"
+}
+
+// NewWorkflowChange summarizes the changes from one definition of a
+// workflow to the next, made by a user. Nil when nothing changed.
+func NewWorkflowChange(from, to *Workflow, userID string) *WorkflowChange {
+	diff := DiffDefinitions(from, to)
+	change := &WorkflowChange{
+		WorkflowID:  to.ID,
+		Version:     to.Version,
+		UserID:      userID,
+		Fields:      ChangedFields(from, to),
+		Nodes:       diff.Nodes,
+		Connections: diff.Connections,
+		CreatedAt:   time.Now(),
+	}
+	if len(diff.Nodes) > 0 {
+		change.Fields = append(change.Fields, "nodes")
+	}
+	if len(diff.Connections) > 0 {
+		change.Fields = append(change.Fields, "connections")
+	}
+	for _, field := range change.Fields {
+		if field == "settings" {
+			change.Settings = changedSettings(from.Settings, to.Settings)
+		}
+	}
+
+	if len(change.Fields) == 0 {
+		return nil
+	}
+	return change
+}
+
+// changedSettings names the settings that differ, by their JSON keys
+func changedSettings(from, to Settings) []string {
+	var before, after map[string]interface{}
+	raw, _ := json.Marshal(from)
+	json.Unmarshal(raw, &before)
+	raw, _ = json.Marshal(to)
+	json.Unmarshal(raw, &after)
+
+	changed := []string{}
+	for key, value := range after {
+		if !sameJSON(before[key], value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
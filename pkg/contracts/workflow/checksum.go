@@ -0,0 +1,91 @@
+package workflow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// ChecksumAlgorithm prefixes checksums so the algorithm can be rotated
+const ChecksumAlgorithm = "sha256"
+
+// ErrChecksumMismatch is returned when a stored definition no longer matches
+// the checksum recorded for it
+var ErrChecksumMismatch = apperrors.New(apperrors.CategoryConflict, "WORKFLOW_CHECKSUM_MISMATCH", "workflow definition does not match its recorded checksum")
+
+// Integrity issue kinds
+const (
+	IntegrityWorkflowMismatch  = "workflow_checksum_mismatch"
+	IntegrityVersionMismatch   = "version_checksum_mismatch"
+	IntegrityExecutionMismatch = "execution_checksum_mismatch"
+)
+
+// definition is the part of a workflow covered by its checksum. Name, status
+// and other metadata can change without changing what an execution runs.
+type definition struct {
+	Nodes       []Node       `json:"nodes"`
+	Connections []Connection `json:"connections"`
+	Settings    Settings     `json:"settings"`
+}
+
+// IntegrityReport is the result of verifying a workflow's stored definitions
+// against their recorded checksums
+type IntegrityReport struct {
+	WorkflowID string           `json:"workflowId"`
+	Version    int              `json:"version"`
+	Checksum   string           `json:"checksum"`
+	Valid      bool             `json:"valid"`
+	Verified   int              `json:"verified"`
+	Unverified int              `json:"unverified"`
+	Issues     []IntegrityIssue `json:"issues"`
+	CheckedAt  time.Time        `json:"checkedAt"`
+}
+
+// IntegrityIssue is a single mismatch between a stored definition and the
+// checksum recorded for it
+type IntegrityIssue struct {
+	Kind        string `json:"kind"`
+	Version     int    `json:"version,omitempty"`
+	ExecutionID string `json:"executionId,omitempty"`
+	Expected    string `json:"expected"`
+	Actual      string `json:"actual"`
+}
+
+// ComputeChecksum hashes the workflow definition. Struct fields marshal in
+// declaration order and map keys sorted, so equal definitions hash equally.
+func (w *Workflow) ComputeChecksum() (string, error) {
+	data, err := json.Marshal(definition{
+		Nodes:       w.Nodes,
+		Connections: w.Connections,
+		Settings:    w.Settings,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal definition: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return ChecksumAlgorithm + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// UpdateChecksum recomputes and stores the workflow checksum
+func (w *Workflow) UpdateChecksum() error {
+	checksum, err := w.ComputeChecksum()
+	if err != nil {
+		return err
+	}
+	w.Checksum = checksum
+	return nil
+}
+
+// ComputeChecksum hashes the definition stored in the version snapshot
+func (v *WorkflowVersion) ComputeChecksum() (string, error) {
+	var w Workflow
+	if err := json.Unmarshal([]byte(v.Data), &w); err != nil {
+		return "", fmt.Errorf("failed to parse version data: %w", err)
+	}
+	return w.ComputeChecksum()
+}
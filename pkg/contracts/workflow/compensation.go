@@ -0,0 +1,60 @@
+package workflow
+
+import (
+	"fmt"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var ErrInvalidCompensation = apperrors.New(apperrors.CategoryValidation, "INVALID_COMPENSATION", "invalid compensation node")
+
+// IsCompensationNode reports whether any node declares the given node as its
+// compensation. Compensation nodes only run during rollback.
+func (w *Workflow) IsCompensationNode(nodeID string) bool {
+	for _, node := range w.Nodes {
+		if node.CompensationNode == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateCompensations checks that every declared compensation node exists,
+// is not the node itself or a trigger, and is kept out of the regular flow so
+// it only runs when a downstream failure triggers a rollback
+func (w *Workflow) ValidateCompensations() error {
+	nodes := make(map[string]*Node, len(w.Nodes))
+	for i := range w.Nodes {
+		nodes[w.Nodes[i].ID] = &w.Nodes[i]
+	}
+
+	hasIncoming := make(map[string]bool)
+	for _, conn := range w.Connections {
+		hasIncoming[conn.Target] = true
+	}
+
+	for _, node := range w.Nodes {
+		if node.CompensationNode == "" {
+			continue
+		}
+
+		compensation, ok := nodes[node.CompensationNode]
+		if !ok {
+			return fmt.Errorf("%w: node %s compensation %s not found", ErrInvalidCompensation, node.ID, node.CompensationNode)
+		}
+		if compensation.ID == node.ID {
+			return fmt.Errorf("%w: node %s compensates itself", ErrInvalidCompensation, node.ID)
+		}
+		if compensation.Type == NodeTypeTrigger || compensation.Type == NodeTypeWebhook {
+			return fmt.Errorf("%w: node %s compensation %s is a trigger", ErrInvalidCompensation, node.ID, compensation.ID)
+		}
+		if hasIncoming[compensation.ID] {
+			return fmt.Errorf("%w: node %s compensation %s is part of the regular flow", ErrInvalidCompensation, node.ID, compensation.ID)
+		}
+		if compensation.CompensationNode != "" {
+			return fmt.Errorf("%w: compensation %s declares its own compensation", ErrInvalidCompensation, compensation.ID)
+		}
+	}
+
+	return nil
+}
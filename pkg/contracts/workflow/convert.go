@@ -0,0 +1,214 @@
+package workflow
+
+import (
+	"encoding/json"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/rpc"
+	"github.com/linkflow-go/pkg/rpc/executionv1"
+	"github.com/linkflow-go/pkg/rpc/workflowv1"
+)
+
+// ToMessage converts a workflow to its message
+func ToMessage(wf *Workflow) (*workflowv1.Workflow, error) {
+	// Settings are sent as the REST API renders them
+	settings, err := rpc.Struct(wf.Settings)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	msg := &workflowv1.Workflow{
+		Id:          wf.ID,
+		Name:        wf.Name,
+		Description: wf.Description,
+		UserId:      wf.UserID,
+		TeamId:      wf.TeamID,
+		Settings:    settings,
+		Status:      wf.Status,
+		IsActive:    wf.IsActive,
+		Version:     int32(wf.Version),
+		Checksum:    wf.Checksum,
+		Tags:        wf.Tags,
+		CreatedAt:   rpc.Timestamp(&wf.CreatedAt),
+		UpdatedAt:   rpc.Timestamp(&wf.UpdatedAt),
+	}
+	for _, node := range wf.Nodes {
+		parameters, err := rpc.Struct(node.Parameters)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		msg.Nodes = append(msg.Nodes, &workflowv1.Node{
+			Id:               node.ID,
+			Name:             node.Name,
+			Type:             node.Type,
+			Position:         &workflowv1.Position{X: node.Position.X, Y: node.Position.Y},
+			Parameters:       parameters,
+			Disabled:         node.Disabled,
+			RetryCount:       int32(node.RetryCount),
+			Timeout:          int32(node.Timeout),
+			ContinueOnFail:   node.ContinueOnFail,
+			CompensationNode: node.CompensationNode,
+		})
+	}
+	for _, conn := range wf.Connections {
+		data, err := rpc.Struct(conn.Data)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		msg.Connections = append(msg.Connections, &workflowv1.Connection{
+			Id:         conn.ID,
+			Source:     conn.Source,
+			Target:     conn.Target,
+			SourcePort: conn.SourcePort,
+			TargetPort: conn.TargetPort,
+			Data:       data,
+		})
+	}
+	return msg, nil
+}
+
+// FromMessage converts a workflow message back to the workflow it was
+// converted from
+func FromMessage(msg *workflowv1.Workflow) (*Workflow, error) {
+	wf := &Workflow{
+		ID:          msg.GetId(),
+		Name:        msg.GetName(),
+		Description: msg.GetDescription(),
+		UserID:      msg.GetUserId(),
+		TeamID:      msg.GetTeamId(),
+		Status:      msg.GetStatus(),
+		IsActive:    msg.GetIsActive(),
+		Version:     int(msg.GetVersion()),
+		Checksum:    msg.GetChecksum(),
+		Tags:        msg.GetTags(),
+	}
+	if t := rpc.Time(msg.GetCreatedAt()); t != nil {
+		wf.CreatedAt = *t
+	}
+	if t := rpc.Time(msg.GetUpdatedAt()); t != nil {
+		wf.UpdatedAt = *t
+	}
+
+	// Settings were sent as the REST API renders them, so they decode the
+	// same way
+	if settings := rpc.Map(msg.GetSettings()); settings != nil {
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		if err := json.Unmarshal(data, &wf.Settings); err != nil {
+			return nil, apperrors.Internal(err)
+		}
+	}
+
+	for _, node := range msg.GetNodes() {
+		wf.Nodes = append(wf.Nodes, Node{
+			ID:               node.GetId(),
+			Name:             node.GetName(),
+			Type:             node.GetType(),
+			Position:         Position{X: node.GetPosition().GetX(), Y: node.GetPosition().GetY()},
+			Parameters:       rpc.Map(node.GetParameters()),
+			Disabled:         node.GetDisabled(),
+			RetryCount:       int(node.GetRetryCount()),
+			Timeout:          int(node.GetTimeout()),
+			ContinueOnFail:   node.GetContinueOnFail(),
+			CompensationNode: node.GetCompensationNode(),
+		})
+	}
+	for _, conn := range msg.GetConnections() {
+		wf.Connections = append(wf.Connections, Connection{
+			ID:         conn.GetId(),
+			Source:     conn.GetSource(),
+			Target:     conn.GetTarget(),
+			SourcePort: conn.GetSourcePort(),
+			TargetPort: conn.GetTargetPort(),
+			Data:       rpc.Map(conn.GetData()),
+		})
+	}
+	return wf, nil
+}
+
+// ExecutionToMessage converts an execution to its message
+func ExecutionToMessage(e *WorkflowExecution) (*executionv1.Execution, error) {
+	data, err := rpc.Struct(e.Data)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	msg := &executionv1.Execution{
+		Id:               e.ID,
+		WorkflowId:       e.WorkflowID,
+		Version:          int32(e.Version),
+		WorkflowChecksum: e.WorkflowChecksum,
+		Status:           e.Status,
+		StartedAt:        rpc.Timestamp(&e.StartedAt),
+		FinishedAt:       rpc.Timestamp(e.FinishedAt),
+		ExecutionTime:    e.ExecutionTime,
+		Data:             data,
+		Error:            e.Error,
+		CreatedBy:        e.CreatedBy,
+		CreatedAt:        rpc.Timestamp(&e.CreatedAt),
+	}
+	for _, node := range e.NodeExecutions {
+		input, err := rpc.Struct(node.InputData)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		output, err := rpc.Struct(node.OutputData)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		msg.NodeExecutions = append(msg.NodeExecutions, &executionv1.NodeExecution{
+			Id:         node.ID,
+			NodeId:     node.NodeID,
+			Status:     node.Status,
+			StartedAt:  rpc.Timestamp(&node.StartedAt),
+			FinishedAt: rpc.Timestamp(node.FinishedAt),
+			InputData:  input,
+			OutputData: output,
+			Error:      node.Error,
+		})
+	}
+	return msg, nil
+}
+
+// ExecutionFromMessage converts an execution message back to the execution
+// it was converted from
+func ExecutionFromMessage(msg *executionv1.Execution) *WorkflowExecution {
+	e := &WorkflowExecution{
+		ID:               msg.GetId(),
+		WorkflowID:       msg.GetWorkflowId(),
+		Version:          int(msg.GetVersion()),
+		WorkflowChecksum: msg.GetWorkflowChecksum(),
+		Status:           msg.GetStatus(),
+		FinishedAt:       rpc.Time(msg.GetFinishedAt()),
+		ExecutionTime:    msg.GetExecutionTime(),
+		Data:             rpc.Map(msg.GetData()),
+		Error:            msg.GetError(),
+		CreatedBy:        msg.GetCreatedBy(),
+	}
+	if t := rpc.Time(msg.GetStartedAt()); t != nil {
+		e.StartedAt = *t
+	}
+	if t := rpc.Time(msg.GetCreatedAt()); t != nil {
+		e.CreatedAt = *t
+	}
+
+	for _, node := range msg.GetNodeExecutions() {
+		nodeExec := NodeExecution{
+			ID:          node.GetId(),
+			ExecutionID: e.ID,
+			NodeID:      node.GetNodeId(),
+			Status:      node.GetStatus(),
+			FinishedAt:  rpc.Time(node.GetFinishedAt()),
+			InputData:   rpc.Map(node.GetInputData()),
+			OutputData:  rpc.Map(node.GetOutputData()),
+			Error:       node.GetError(),
+		}
+		if t := rpc.Time(node.GetStartedAt()); t != nil {
+			nodeExec.StartedAt = *t
+		}
+		e.NodeExecutions = append(e.NodeExecutions, nodeExec)
+	}
+	return e
+}
@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"fmt"
+	"strings"
 )
 
 // DAG represents a Directed Acyclic Graph for workflow execution
@@ -63,8 +64,8 @@ func (d *DAG) build() {
 // Validate performs comprehensive DAG validation
 func (d *DAG) Validate() error {
 	// Check for cycles
-	if d.HasCycle() {
-		return ErrWorkflowHasCycle
+	if cycles := d.FindCycles(); len(cycles) > 0 {
+		return fmt.Errorf("%w: %s", ErrWorkflowHasCycle, strings.Join(cycles[0], " -> "))
 	}
 
 	// Validate all connections
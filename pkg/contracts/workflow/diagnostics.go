@@ -0,0 +1,256 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var (
+	ErrDanglingConnection = apperrors.New(apperrors.CategoryValidation, "DANGLING_CONNECTION", "connection points at a node that does not exist")
+	ErrAmbiguousStart     = apperrors.New(apperrors.CategoryValidation, "AMBIGUOUS_START", "workflow has start nodes that are not triggers")
+)
+
+// Diagnostic is a problem of the graph of a workflow with the nodes and
+// connections it concerns, for the editor to highlight
+type Diagnostic struct {
+	// Code is the code of the matching validation error
+	Code          string   `json:"code"`
+	Severity      string   `json:"severity"`
+	Message       string   `json:"message"`
+	NodeIDs       []string `json:"nodeIds,omitempty"`
+	ConnectionIDs []string `json:"connectionIds,omitempty"`
+}
+
+// Diagnose reports the dangling connections, cycles, orphaned nodes and
+// ambiguous start nodes of the graph. Cycles are listed node by node in the
+// order they loop, closed by their first node.
+func (d *DAG) Diagnose() []Diagnostic {
+	diagnostics := []Diagnostic{}
+
+	for _, conn := range d.workflow.Connections {
+		var missing []string
+		if _, ok := d.Nodes[conn.Source]; !ok {
+			missing = append(missing, conn.Source)
+		}
+		if _, ok := d.Nodes[conn.Target]; !ok && conn.Target != conn.Source {
+			missing = append(missing, conn.Target)
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Code:          ErrDanglingConnection.Code,
+			Severity:      LintSeverityError,
+			Message:       fmt.Sprintf("Connection %s points at missing node %s", conn.ID, strings.Join(missing, ", ")),
+			NodeIDs:       d.existingNodes(conn.Source, conn.Target),
+			ConnectionIDs: []string{conn.ID},
+		})
+	}
+
+	for _, cycle := range d.FindCycles() {
+		diagnostics = append(diagnostics, Diagnostic{
+			Code:          ErrWorkflowHasCycle.Code,
+			Severity:      LintSeverityError,
+			Message:       fmt.Sprintf("Nodes form a cycle: %s", strings.Join(cycle, " -> ")),
+			NodeIDs:       cycle[:len(cycle)-1],
+			ConnectionIDs: d.pathConnections(cycle),
+		})
+	}
+
+	incoming := make(map[string]int)
+	outgoing := make(map[string]int)
+	for _, conn := range d.workflow.Connections {
+		if _, ok := d.Nodes[conn.Source]; !ok {
+			continue
+		}
+		if _, ok := d.Nodes[conn.Target]; !ok {
+			continue
+		}
+		outgoing[conn.Source]++
+		incoming[conn.Target]++
+	}
+
+	// Catch nodes run on failures and compensation nodes on rollbacks, they
+	// need no incoming connection
+	entered := make(map[string]bool)
+	for _, boundary := range d.workflow.Settings.ErrorBoundaries {
+		entered[boundary.CatchNode] = true
+	}
+	for _, node := range d.workflow.Nodes {
+		if node.CompensationNode != "" {
+			entered[node.CompensationNode] = true
+		}
+	}
+
+	var triggers, roots []string
+	for _, node := range d.workflow.Nodes {
+		if node.Disabled {
+			continue
+		}
+		if node.Type == NodeTypeTrigger || node.Type == NodeTypeWebhook {
+			triggers = append(triggers, node.ID)
+			continue
+		}
+		if entered[node.ID] || incoming[node.ID] > 0 {
+			continue
+		}
+		if outgoing[node.ID] == 0 {
+			diagnostics = append(diagnostics, Diagnostic{
+				Code:     ErrOrphanedNode.Code,
+				Severity: LintSeverityWarning,
+				Message:  fmt.Sprintf("Node %s (%s) has no connections", node.ID, node.Name),
+				NodeIDs:  []string{node.ID},
+			})
+			continue
+		}
+		roots = append(roots, node.ID)
+	}
+
+	// Executions start at triggers only, other roots and what follows them
+	// never run
+	if len(roots) > 0 {
+		diagnostics = append(diagnostics, Diagnostic{
+			Code:     ErrAmbiguousStart.Code,
+			Severity: LintSeverityWarning,
+			Message: fmt.Sprintf("Branches start at %s without a trigger, executions only start at triggers (%s)",
+				strings.Join(roots, ", "), strings.Join(triggers, ", ")),
+			NodeIDs: append(roots, triggers...),
+		})
+	}
+
+	return diagnostics
+}
+
+// FindCycles returns a cycle of every strongly connected group of nodes, as
+// the node IDs along it closed by the first one. Every node of a group lies
+// on a cycle, fixing the one reported may reveal others.
+func (d *DAG) FindCycles() [][]string {
+	index := make(map[string]int)
+	lowLink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+	next := 0
+
+	// Tarjan's algorithm, visiting nodes in workflow order for stable output
+	var connect func(nodeID string)
+	connect = func(nodeID string) {
+		index[nodeID] = next
+		lowLink[nodeID] = next
+		next++
+		stack = append(stack, nodeID)
+		onStack[nodeID] = true
+
+		for _, neighbor := range d.Edges[nodeID] {
+			if _, ok := d.Nodes[neighbor]; !ok {
+				continue
+			}
+			if _, visited := index[neighbor]; !visited {
+				connect(neighbor)
+				lowLink[nodeID] = min(lowLink[nodeID], lowLink[neighbor])
+			} else if onStack[neighbor] {
+				lowLink[nodeID] = min(lowLink[nodeID], index[neighbor])
+			}
+		}
+
+		if lowLink[nodeID] == index[nodeID] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == nodeID {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for _, node := range d.workflow.Nodes {
+		if _, visited := index[node.ID]; !visited {
+			connect(node.ID)
+		}
+	}
+
+	var cycles [][]string
+	for _, component := range components {
+		if cycle := d.shortestCycle(component); cycle != nil {
+			cycles = append(cycles, cycle)
+		}
+	}
+	return cycles
+}
+
+// shortestCycle returns the shortest cycle through the earliest node of a
+// strongly connected group, nil for a single node without a self loop
+func (d *DAG) shortestCycle(component []string) []string {
+	members := make(map[string]bool, len(component))
+	for _, nodeID := range component {
+		members[nodeID] = true
+	}
+
+	var start string
+	for _, node := range d.workflow.Nodes {
+		if members[node.ID] {
+			start = node.ID
+			break
+		}
+	}
+
+	// Breadth first from the start node back to it, inside the group
+	parent := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, neighbor := range d.Edges[current] {
+			if neighbor == start {
+				cycle := []string{start}
+				for nodeID := current; nodeID != start; nodeID = parent[nodeID] {
+					cycle = append(cycle, nodeID)
+				}
+				// Collected backwards from the closing node, reverse all
+				// but the leading start node
+				for i, j := 1, len(cycle)-1; i < j; i, j = i+1, j-1 {
+					cycle[i], cycle[j] = cycle[j], cycle[i]
+				}
+				return append(cycle, start)
+			}
+			if _, seen := parent[neighbor]; seen || !members[neighbor] {
+				continue
+			}
+			parent[neighbor] = current
+			queue = append(queue, neighbor)
+		}
+	}
+	return nil
+}
+
+// pathConnections returns the IDs of the connections along a path of nodes
+func (d *DAG) pathConnections(path []string) []string {
+	var ids []string
+	for i := 0; i+1 < len(path); i++ {
+		for _, conn := range d.workflow.Connections {
+			if conn.Source == path[i] && conn.Target == path[i+1] {
+				ids = append(ids, conn.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// existingNodes filters node IDs down to the nodes of the graph
+func (d *DAG) existingNodes(nodeIDs ...string) []string {
+	var existing []string
+	for _, nodeID := range nodeIDs {
+		if _, ok := d.Nodes[nodeID]; ok {
+			existing = append(existing, nodeID)
+		}
+	}
+	return existing
+}
@@ -0,0 +1,143 @@
+package workflow
+
+import "sort"
+
+// Kinds of changes between two definitions of a workflow
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// DefinitionDiff lists how the nodes and connections of a workflow change
+// from one definition to another
+type DefinitionDiff struct {
+	Nodes       []NodeChange       `json:"nodes"`
+	Connections []ConnectionChange `json:"connections"`
+}
+
+// NodeChange is a node added, removed or changed. Fields names the
+// properties of a changed node that differ, its position aside.
+type NodeChange struct {
+	NodeID string   `json:"nodeId"`
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Kind   string   `json:"kind"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+// ConnectionChange is a connection added or removed, connections being
+// identified by the ports they link
+type ConnectionChange struct {
+	Source     string `json:"source"`
+	SourcePort string `json:"sourcePort,omitempty"`
+	Target     string `json:"target"`
+	TargetPort string `json:"targetPort,omitempty"`
+	Kind       string `json:"kind"`
+}
+
+// Empty reports whether the definitions have the same nodes and
+// connections
+func (d *DefinitionDiff) Empty() bool {
+	return len(d.Nodes) == 0 && len(d.Connections) == 0
+}
+
+// DiffDefinitions compares the nodes and connections of two definitions of
+// a workflow. Nodes are matched by ID, connections by the ports they link.
+func DiffDefinitions(from, to *Workflow) *DefinitionDiff {
+	diff := &DefinitionDiff{Nodes: []NodeChange{}, Connections: []ConnectionChange{}}
+
+	before := make(map[string]Node, len(from.Nodes))
+	for _, node := range from.Nodes {
+		before[node.ID] = node
+	}
+	after := make(map[string]bool, len(to.Nodes))
+	for _, node := range to.Nodes {
+		after[node.ID] = true
+		previous, ok := before[node.ID]
+		if !ok {
+			diff.Nodes = append(diff.Nodes, NodeChange{NodeID: node.ID, Name: node.Name, Type: node.Type, Kind: ChangeAdded})
+			continue
+		}
+		if fields := nodeFields(previous, node); len(fields) > 0 {
+			diff.Nodes = append(diff.Nodes, NodeChange{NodeID: node.ID, Name: node.Name, Type: node.Type, Kind: ChangeChanged, Fields: fields})
+		}
+	}
+	for _, node := range from.Nodes {
+		if !after[node.ID] {
+			diff.Nodes = append(diff.Nodes, NodeChange{NodeID: node.ID, Name: node.Name, Type: node.Type, Kind: ChangeRemoved})
+		}
+	}
+	sort.SliceStable(diff.Nodes, func(i, j int) bool {
+		return diff.Nodes[i].NodeID < diff.Nodes[j].NodeID
+	})
+
+	linked := func(connections []Connection) map[ConnectionChange]bool {
+		set := make(map[ConnectionChange]bool, len(connections))
+		for _, conn := range connections {
+			set[ConnectionChange{Source: conn.Source, SourcePort: conn.SourcePort, Target: conn.Target, TargetPort: conn.TargetPort}] = true
+		}
+		return set
+	}
+	was, is := linked(from.Connections), linked(to.Connections)
+	for conn := range is {
+		if !was[conn] {
+			conn.Kind = ChangeAdded
+			diff.Connections = append(diff.Connections, conn)
+		}
+	}
+	for conn := range was {
+		if !is[conn] {
+			conn.Kind = ChangeRemoved
+			diff.Connections = append(diff.Connections, conn)
+		}
+	}
+	sort.Slice(diff.Connections, func(i, j int) bool {
+		a, b := diff.Connections[i], diff.Connections[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		if a.SourcePort != b.SourcePort {
+			return a.SourcePort < b.SourcePort
+		}
+		if a.TargetPort != b.TargetPort {
+			return a.TargetPort < b.TargetPort
+		}
+		return a.Kind < b.Kind
+	})
+
+	return diff
+}
+
+// nodeFields names the properties two versions of a node differ by
+func nodeFields(a, b Node) []string {
+	var fields []string
+	if a.Name != b.Name {
+		fields = append(fields, "name")
+	}
+	if a.Type != b.Type {
+		fields = append(fields, "type")
+	}
+	if !sameJSON(a.Parameters, b.Parameters) {
+		fields = append(fields, "parameters")
+	}
+	if a.Disabled != b.Disabled {
+		fields = append(fields, "disabled")
+	}
+	if a.RetryCount != b.RetryCount {
+		fields = append(fields, "retryCount")
+	}
+	if a.Timeout != b.Timeout {
+		fields = append(fields, "timeout")
+	}
+	if a.ContinueOnFail != b.ContinueOnFail {
+		fields = append(fields, "continueOnFail")
+	}
+	if a.CompensationNode != b.CompensationNode {
+		fields = append(fields, "compensationNode")
+	}
+	return fields
+}
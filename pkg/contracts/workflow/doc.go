@@ -0,0 +1,9 @@
+// Package workflow is the workflow contract shared by the services: the
+// definition of a workflow and the record of its executions. Services land
+// new fields here once, then convert to the messages of the versioned gRPC
+// API with the helpers of convert.go rather than keeping types of their own.
+package workflow
+
+// ContractVersion is the version of the gRPC messages the contract converts
+// to, those of the workflowv1 and executionv1 packages
+const ContractVersion = "v1"
@@ -0,0 +1,220 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var (
+	ErrEditConflict = apperrors.New(apperrors.CategoryConflict, "EDIT_CONFLICT", "workflow was changed by another user in the same places")
+	ErrNodeLocked   = apperrors.New(apperrors.CategoryConflict, "NODE_LOCKED", "node is being edited by another user")
+)
+
+// EditSessionTTL is how long an edit session lasts without a heartbeat.
+// Editors renew their session more often than that while the editor is
+// open, the claims of closed editors lapse on their own.
+const EditSessionTTL = 30 * time.Second
+
+// EditSession is a user editing a workflow. Nodes are the nodes the user
+// has open, claimed for the length of the session: other users cannot save
+// changes to them until it ends.
+type EditSession struct {
+	UserID    string    `json:"userId"`
+	Nodes     []string  `json:"nodes"`
+	StartedAt time.Time `json:"startedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// EditSessionRequest joins or renews an edit session, claiming nodes
+type EditSessionRequest struct {
+	Nodes []string `json:"nodes"`
+}
+
+// EditSessionsKey are the parts of the Redis key, within the namespace of
+// the tenant, of the hash holding the edit sessions of a workflow by user
+func EditSessionsKey(workflowID string) []string {
+	return []string{"edit", "sessions", workflowID}
+}
+
+// Live reports whether the session has not expired at now
+func (s *EditSession) Live(now time.Time) bool {
+	return now.Before(s.ExpiresAt)
+}
+
+// Holds reports whether the session claims a node
+func (s *EditSession) Holds(nodeID string) bool {
+	for _, id := range s.Nodes {
+		if id == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// EditConflict is a place two users changed differently since the version
+// they both started from
+type EditConflict struct {
+	// Kind is node, connection, annotation or field
+	Kind string `json:"kind"`
+	// ID is the node, annotation or workflow field, or the source and
+	// target of the connection
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// MergeDefinitions merges the changes made to a workflow since a base
+// version by two users, theirs already saved and ours being saved. Nodes
+// and annotations merge by ID, connections by the ports they link and the
+// other fields one by one. A place both changed differently is a conflict,
+// the merged definition is only meaningful without conflicts.
+func MergeDefinitions(base, theirs, ours *Workflow) (*Workflow, []EditConflict) {
+	merged := *theirs
+	var conflicts []EditConflict
+
+	field := func(name string, b, t, o interface{}) bool {
+		switch {
+		case sameJSON(o, b), sameJSON(o, t):
+			return false
+		case sameJSON(t, b):
+			return true
+		}
+		conflicts = append(conflicts, EditConflict{Kind: "field", ID: name, Reason: "changed by both"})
+		return false
+	}
+	if field("name", base.Name, theirs.Name, ours.Name) {
+		merged.Name = ours.Name
+	}
+	if field("description", base.Description, theirs.Description, ours.Description) {
+		merged.Description = ours.Description
+	}
+	if field("tags", base.Tags, theirs.Tags, ours.Tags) {
+		merged.Tags = ours.Tags
+	}
+	if field("settings", base.Settings, theirs.Settings, ours.Settings) {
+		merged.Settings = ours.Settings
+	}
+
+	var nodeConflicts, annotationConflicts []EditConflict
+	merged.Nodes, nodeConflicts = mergeByID(base.Nodes, theirs.Nodes, ours.Nodes, "node", func(n Node) string { return n.ID })
+	merged.Annotations, annotationConflicts = mergeByID(base.Annotations, theirs.Annotations, ours.Annotations, "annotation", func(a Annotation) string { return a.ID })
+	conflicts = append(conflicts, nodeConflicts...)
+	conflicts = append(conflicts, annotationConflicts...)
+
+	merged.Connections = mergeConnections(base.Connections, theirs.Connections, ours.Connections)
+	nodes := make(map[string]bool, len(merged.Nodes))
+	for _, node := range merged.Nodes {
+		nodes[node.ID] = true
+	}
+	for _, conn := range merged.Connections {
+		if !nodes[conn.Source] || !nodes[conn.Target] {
+			conflicts = append(conflicts, EditConflict{
+				Kind:   "connection",
+				ID:     conn.Source + "->" + conn.Target,
+				Reason: "links a node removed by the other user",
+			})
+		}
+	}
+	merged.PruneAnnotations()
+
+	return &merged, conflicts
+}
+
+// mergeByID merges lists of items identified by ID, in the order of
+// theirs followed by the items only ours added
+func mergeByID[T any](base, theirs, ours []T, kind string, id func(T) string) ([]T, []EditConflict) {
+	index := func(items []T) map[string]T {
+		byID := make(map[string]T, len(items))
+		for _, item := range items {
+			byID[id(item)] = item
+		}
+		return byID
+	}
+	b, t, o := index(base), index(theirs), index(ours)
+
+	order := make([]string, 0, len(theirs)+len(ours))
+	seen := make(map[string]bool, len(theirs)+len(ours))
+	for _, items := range [][]T{theirs, ours} {
+		for _, item := range items {
+			if !seen[id(item)] {
+				seen[id(item)] = true
+				order = append(order, id(item))
+			}
+		}
+	}
+	// Items of the base both removed are gone, those one removed are
+	// weighed below
+	var merged []T
+	var conflicts []EditConflict
+	for _, key := range order {
+		baseItem, inBase := b[key]
+		theirItem, inTheirs := t[key]
+		ourItem, inOurs := o[key]
+
+		same := func(x T, inX bool, y T, inY bool) bool {
+			return inX == inY && (!inX || sameJSON(x, y))
+		}
+		switch {
+		case same(ourItem, inOurs, baseItem, inBase), same(ourItem, inOurs, theirItem, inTheirs):
+			// Ours left it alone or agrees, theirs stands
+			if inTheirs {
+				merged = append(merged, theirItem)
+			}
+		case same(theirItem, inTheirs, baseItem, inBase):
+			if inOurs {
+				merged = append(merged, ourItem)
+			}
+		default:
+			reason := "changed by both"
+			if !inTheirs || !inOurs {
+				reason = "removed by one user and changed by the other"
+			}
+			conflicts = append(conflicts, EditConflict{Kind: kind, ID: key, Reason: reason})
+			if inTheirs {
+				merged = append(merged, theirItem)
+			}
+		}
+	}
+	return merged, conflicts
+}
+
+// mergeConnections keeps the connections both kept and those either added,
+// a connection either removed is removed
+func mergeConnections(base, theirs, ours []Connection) []Connection {
+	key := func(c Connection) string {
+		return fmt.Sprintf("%s:%s->%s:%s", c.Source, c.SourcePort, c.Target, c.TargetPort)
+	}
+	set := func(connections []Connection) map[string]bool {
+		keys := make(map[string]bool, len(connections))
+		for _, c := range connections {
+			keys[key(c)] = true
+		}
+		return keys
+	}
+	b, t, o := set(base), set(theirs), set(ours)
+
+	merged := make([]Connection, 0, len(theirs)+len(ours))
+	added := make(map[string]bool)
+	for _, c := range theirs {
+		if o[key(c)] || !b[key(c)] {
+			merged = append(merged, c)
+			added[key(c)] = true
+		}
+	}
+	for _, c := range ours {
+		if !added[key(c)] && !b[key(c)] && !t[key(c)] {
+			merged = append(merged, c)
+			added[key(c)] = true
+		}
+	}
+	return merged
+}
+
+// SortEditSessions orders sessions by when they started
+func SortEditSessions(sessions []*EditSession) {
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartedAt.Before(sessions[j].StartedAt)
+	})
+}
@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// EnvironmentVariablesKey is the key of the execution input holding the
+// variables of the environment an execution runs in
+const EnvironmentVariablesKey = "env"
+
+var (
+	ErrEnvironmentNotDeployed = apperrors.New(apperrors.CategoryConflict, "ENVIRONMENT_NOT_DEPLOYED", "no workflow version is deployed to this environment")
+	ErrPromotionOrder         = apperrors.New(apperrors.CategoryConflict, "ENVIRONMENT_PROMOTION_ORDER", "environment only accepts the version deployed to the environment it promotes from")
+	ErrInvalidPromotion       = apperrors.New(apperrors.CategoryValidation, "INVALID_ENVIRONMENT_PROMOTION", "invalid environment promotion")
+)
+
+// Deployment records a workflow version deployed to an environment, the
+// history of an environment and the audit of its promotions
+type Deployment struct {
+	ID              string `json:"id" gorm:"primaryKey"`
+	WorkflowID      string `json:"workflowId" gorm:"not null;index"`
+	EnvironmentID   string `json:"environmentId" gorm:"not null;index"`
+	Version         int    `json:"version"`
+	PreviousVersion int    `json:"previousVersion,omitempty"`
+	// PromotedFrom is the environment the version was promoted from, empty
+	// for a version deployed directly
+	PromotedFrom string    `json:"promotedFrom,omitempty"`
+	DeployedBy   string    `json:"deployedBy"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// TableName specifies the table name for GORM
+func (Deployment) TableName() string {
+	return "workflow.environment_deployments"
+}
+
+// Bind makes a definition run in the environment: the credentials its nodes
+// name are replaced with those of the environment. The definition is
+// modified in place.
+func (e *Environment) Bind(wf *Workflow) {
+	if len(e.Credentials) == 0 {
+		return
+	}
+	for i := range wf.Nodes {
+		parameters := wf.Nodes[i].Parameters
+		credentialID, ok := parameters["credentialId"].(string)
+		if !ok {
+			continue
+		}
+		if bound, ok := e.Credentials[credentialID]; ok && bound != "" {
+			parameters["credentialId"] = bound
+		}
+	}
+}
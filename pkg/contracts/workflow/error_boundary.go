@@ -0,0 +1,108 @@
+package workflow
+
+import (
+	"fmt"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var (
+	ErrInvalidErrorBoundary = apperrors.New(apperrors.CategoryValidation, "INVALID_ERROR_BOUNDARY", "invalid error boundary")
+	ErrCatchLoopsIntoTry    = apperrors.New(apperrors.CategoryValidation, "CATCH_LOOPS_INTO_TRY", "catch branch loops back into try block")
+)
+
+// ErrorBoundary groups nodes into a try block. When any node in the block
+// fails, the rest of the block is skipped and execution continues at the
+// catch node with the error object instead of failing the whole execution.
+type ErrorBoundary struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	TryNodes  []string `json:"tryNodes"`
+	CatchNode string   `json:"catchNode"`
+}
+
+// Contains reports whether the node belongs to the try block
+func (b *ErrorBoundary) Contains(nodeID string) bool {
+	for _, id := range b.TryNodes {
+		if id == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// FindErrorBoundary returns the error boundary guarding a node, if any
+func (w *Workflow) FindErrorBoundary(nodeID string) *ErrorBoundary {
+	for i := range w.Settings.ErrorBoundaries {
+		boundary := &w.Settings.ErrorBoundaries[i]
+		if boundary.Contains(nodeID) {
+			return boundary
+		}
+	}
+	return nil
+}
+
+// ValidateErrorBoundaries checks that every boundary references existing
+// nodes, that a node is guarded by at most one boundary, and that the catch
+// branch never flows back into its own try block
+func (w *Workflow) ValidateErrorBoundaries() error {
+	if len(w.Settings.ErrorBoundaries) == 0 {
+		return nil
+	}
+
+	nodes := make(map[string]bool, len(w.Nodes))
+	for _, node := range w.Nodes {
+		nodes[node.ID] = true
+	}
+
+	graph := make(map[string][]string)
+	for _, conn := range w.Connections {
+		graph[conn.Source] = append(graph[conn.Source], conn.Target)
+	}
+
+	guardedBy := make(map[string]string)
+	for _, boundary := range w.Settings.ErrorBoundaries {
+		if len(boundary.TryNodes) == 0 {
+			return fmt.Errorf("%w: boundary %s has no try nodes", ErrInvalidErrorBoundary, boundary.ID)
+		}
+		if boundary.CatchNode == "" {
+			return fmt.Errorf("%w: boundary %s has no catch node", ErrInvalidErrorBoundary, boundary.ID)
+		}
+		if !nodes[boundary.CatchNode] {
+			return fmt.Errorf("%w: boundary %s catch node %s not found", ErrInvalidErrorBoundary, boundary.ID, boundary.CatchNode)
+		}
+		if boundary.Contains(boundary.CatchNode) {
+			return fmt.Errorf("%w: boundary %s catch node %s is inside its try block", ErrInvalidErrorBoundary, boundary.ID, boundary.CatchNode)
+		}
+
+		for _, nodeID := range boundary.TryNodes {
+			if !nodes[nodeID] {
+				return fmt.Errorf("%w: boundary %s try node %s not found", ErrInvalidErrorBoundary, boundary.ID, nodeID)
+			}
+			if other, ok := guardedBy[nodeID]; ok {
+				return fmt.Errorf("%w: node %s is guarded by boundaries %s and %s", ErrInvalidErrorBoundary, nodeID, other, boundary.ID)
+			}
+			guardedBy[nodeID] = boundary.ID
+		}
+
+		// Walk the catch branch and make sure it never reaches the try block
+		visited := map[string]bool{boundary.CatchNode: true}
+		queue := []string{boundary.CatchNode}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+
+			for _, next := range graph[current] {
+				if boundary.Contains(next) {
+					return fmt.Errorf("%w: boundary %s, %s -> %s", ErrCatchLoopsIntoTry, boundary.ID, current, next)
+				}
+				if !visited[next] {
+					visited[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+
+	return nil
+}
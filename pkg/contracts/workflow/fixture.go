@@ -0,0 +1,144 @@
+package workflow
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var (
+	ErrFixtureNotFound = apperrors.New(apperrors.CategoryNotFound, "FIXTURE_NOT_FOUND", "trigger fixture not found")
+	ErrFixtureExists   = apperrors.New(apperrors.CategoryConflict, "FIXTURE_EXISTS", "trigger fixture already exists")
+	ErrInvalidFixture  = apperrors.New(apperrors.CategoryValidation, "INVALID_FIXTURE", "invalid trigger fixture")
+)
+
+// Sources of trigger fixtures
+const (
+	// FixtureCaptured is a payload the trigger received
+	FixtureCaptured = "captured"
+	// FixtureManual is a payload saved by a user
+	FixtureManual = "manual"
+)
+
+// fixtureRedactedHeaders authenticate the caller of a webhook, captured
+// payloads do not keep them
+var fixtureRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Api-Key",
+	"X-Webhook-Signature",
+}
+
+// FixtureCaptureTTL is how long a capture waits for the trigger to receive
+// a payload
+const FixtureCaptureTTL = 15 * time.Minute
+
+// fixtureName is a name usable in a URL, such as order-created.v2
+var fixtureName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// TriggerFixture is a named payload of a trigger, replayed through the
+// trigger and the workflow tests to catch regressions in how its payloads
+// are handled after edits
+type TriggerFixture struct {
+	ID         string                 `json:"id" gorm:"primaryKey"`
+	TenantID   string                 `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
+	WorkflowID string                 `json:"workflowId" gorm:"not null;index"`
+	TriggerID  string                 `json:"triggerId" gorm:"not null;uniqueIndex:idx_trigger_fixtures_name"`
+	Name       string                 `json:"name" gorm:"not null;uniqueIndex:idx_trigger_fixtures_name"`
+	Payload    map[string]interface{} `json:"payload" gorm:"serializer:json"`
+	// Source is captured or manual
+	Source    string    `json:"source"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName specifies the table name for GORM
+func (TriggerFixture) TableName() string {
+	return "workflow.trigger_fixtures"
+}
+
+// FixtureCapture is a pending capture of the next payload of a trigger as a
+// fixture
+type FixtureCapture struct {
+	Name      string    `json:"name"`
+	UserID    string    `json:"userId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CaptureFixtureRequest captures the next payload of a trigger under a name
+type CaptureFixtureRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateFixtureRequest saves a payload of a trigger under a name
+type CreateFixtureRequest struct {
+	Name    string                 `json:"name" binding:"required"`
+	Payload map[string]interface{} `json:"payload" binding:"required"`
+}
+
+// FixtureCapturesKey are the parts of the Redis key, within the namespace
+// of the tenant, of the hash holding the pending captures of the triggers
+// of a workflow by trigger ID
+func FixtureCapturesKey(workflowID string) []string {
+	return []string{"trigger", "captures", workflowID}
+}
+
+// Live reports whether the capture has not expired at now
+func (c *FixtureCapture) Live(now time.Time) bool {
+	return now.Before(c.ExpiresAt)
+}
+
+// CapturedPayload copies a payload received by a trigger without the
+// headers authenticating the caller of a webhook
+func CapturedPayload(payload map[string]interface{}) map[string]interface{} {
+	captured := make(map[string]interface{}, len(payload))
+	for k, v := range payload {
+		captured[k] = v
+	}
+
+	request, ok := payload["_webhook"].(map[string]interface{})
+	if !ok {
+		return captured
+	}
+	headers, ok := request["headers"].(map[string]interface{})
+	if !ok {
+		return captured
+	}
+	kept := make(map[string]interface{}, len(headers))
+	for name, value := range headers {
+		kept[name] = value
+	}
+	for _, name := range fixtureRedactedHeaders {
+		delete(kept, http.CanonicalHeaderKey(name))
+	}
+	copied := make(map[string]interface{}, len(request))
+	for k, v := range request {
+		copied[k] = v
+	}
+	copied["headers"] = kept
+	captured["_webhook"] = copied
+	return captured
+}
+
+// ValidateFixtureName checks a fixture name is usable in a URL
+func ValidateFixtureName(name string) error {
+	if !fixtureName.MatchString(name) {
+		return ErrInvalidFixture.WithMessage("fixture name %q must be 1 to 64 letters, digits, dots, dashes or underscores", name)
+	}
+	return nil
+}
+
+// FixtureReplay is the outcome of a fixture replayed through its trigger
+// and the test of its workflow. It passes when the trigger fires on the
+// payload and the workflow validates with it.
+type FixtureReplay struct {
+	Fixture   string   `json:"fixture"`
+	Passed    bool     `json:"passed"`
+	WouldFire bool     `json:"wouldFire"`
+	Valid     bool     `json:"valid"`
+	Errors    []string `json:"errors,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
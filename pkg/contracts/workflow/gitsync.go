@@ -0,0 +1,291 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of the workflow documents kept in Git
+const (
+	GitFormatJSON = "json"
+	GitFormatYAML = "yaml"
+)
+
+// DefaultGitBranch is the branch a connection syncs when none is given
+const DefaultGitBranch = "main"
+
+var (
+	ErrGitConnectionNotFound = apperrors.New(apperrors.CategoryNotFound, "GIT_CONNECTION_NOT_FOUND", "workflow is not connected to a Git repository")
+	ErrInvalidGitConnection  = apperrors.New(apperrors.CategoryValidation, "INVALID_GIT_CONNECTION", "invalid Git connection")
+	ErrInvalidGitDocument    = apperrors.New(apperrors.CategoryValidation, "INVALID_GIT_DOCUMENT", "invalid workflow document in Git")
+	ErrGitDocumentNotFound   = apperrors.New(apperrors.CategoryNotFound, "GIT_DOCUMENT_NOT_FOUND", "workflow document not found in Git")
+	ErrGitPullStale          = apperrors.New(apperrors.CategoryConflict, "GIT_PULL_STALE", "the branch moved since the pull was previewed")
+	ErrGitSyncFailed         = apperrors.New(apperrors.CategoryUpstream, "GIT_SYNC_FAILED", "Git sync failed")
+	ErrGitSyncDisabled       = apperrors.New(apperrors.CategoryInternal, "GIT_SYNC_DISABLED", "Git sync is not available")
+)
+
+var (
+	// gitBranchPattern keeps branches to plain ref names, never options
+	gitBranchPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+	// gitSSHPattern matches the scp-like syntax of SSH remotes
+	gitSSHPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[A-Za-z0-9._/~-]+$`)
+)
+
+// GitConnection keeps a workflow in sync with a document in a Git
+// repository: every version is pushed to it, changes made in the
+// repository are pulled once reviewed
+type GitConnection struct {
+	ID            string `json:"id" gorm:"primaryKey"`
+	WorkflowID    string `json:"workflowId" gorm:"not null;uniqueIndex"`
+	RepositoryURL string `json:"repositoryUrl" gorm:"not null"`
+	Branch        string `json:"branch" gorm:"not null"`
+	Path          string `json:"path" gorm:"not null"`
+	Format        string `json:"format" gorm:"not null;default:'json'"`
+	// Token authenticates HTTPS remotes, sealed with the credential
+	// encryption key and never returned
+	Token             string     `json:"-"`
+	HasToken          bool       `json:"hasToken" gorm:"-"`
+	AutoPush          bool       `json:"autoPush"`
+	LastPushedVersion int        `json:"lastPushedVersion"`
+	LastPushedCommit  string     `json:"lastPushedCommit,omitempty"`
+	LastPulledCommit  string     `json:"lastPulledCommit,omitempty"`
+	LastSyncedAt      *time.Time `json:"lastSyncedAt,omitempty"`
+	LastError         string     `json:"lastError,omitempty"`
+	CreatedBy         string     `json:"createdBy"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (GitConnection) TableName() string {
+	return "workflow.git_connections"
+}
+
+// ConnectGitRequest connects a workflow to a Git repository
+type ConnectGitRequest struct {
+	RepositoryURL string `json:"repositoryUrl" binding:"required"`
+	Branch        string `json:"branch"`
+	Path          string `json:"path"`
+	Format        string `json:"format"`
+	Token         string `json:"token"`
+	AutoPush      *bool  `json:"autoPush"`
+}
+
+// NewGitConnection creates the connection of a workflow from a request,
+// the branch, format and path defaulted
+func NewGitConnection(workflowID, userID string, req *ConnectGitRequest) (*GitConnection, error) {
+	conn := &GitConnection{
+		WorkflowID:    workflowID,
+		RepositoryURL: strings.TrimSpace(req.RepositoryURL),
+		Branch:        req.Branch,
+		Path:          strings.TrimPrefix(req.Path, "/"),
+		Format:        req.Format,
+		AutoPush:      req.AutoPush == nil || *req.AutoPush,
+		CreatedBy:     userID,
+	}
+	if conn.Branch == "" {
+		conn.Branch = DefaultGitBranch
+	}
+	if conn.Format == "" {
+		conn.Format = GitFormatJSON
+	}
+	if conn.Path == "" {
+		conn.Path = fmt.Sprintf("workflows/%s.%s", workflowID, conn.Format)
+	}
+
+	if err := conn.Validate(req.Token != ""); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Validate checks the repository, branch, path and format of a connection.
+// Only HTTPS and SSH remotes are accepted, a token only over HTTPS.
+func (c *GitConnection) Validate(withToken bool) error {
+	switch {
+	case strings.HasPrefix(c.RepositoryURL, "https://"):
+	case strings.HasPrefix(c.RepositoryURL, "ssh://"), gitSSHPattern.MatchString(c.RepositoryURL):
+		if withToken {
+			return ErrInvalidGitConnection.WithMessage("a token only authenticates HTTPS repositories")
+		}
+	default:
+		return ErrInvalidGitConnection.WithMessage("repository must be an HTTPS or SSH URL")
+	}
+
+	if !gitBranchPattern.MatchString(c.Branch) || strings.Contains(c.Branch, "..") || strings.HasSuffix(c.Branch, ".lock") {
+		return ErrInvalidGitConnection.WithMessage("invalid branch %q", c.Branch)
+	}
+
+	if c.Format != GitFormatJSON && c.Format != GitFormatYAML {
+		return ErrInvalidGitConnection.WithMessage("format must be json or yaml")
+	}
+
+	cleaned := path.Clean(c.Path)
+	if cleaned != c.Path || strings.HasPrefix(cleaned, "../") || cleaned == ".." || strings.HasPrefix(cleaned, ".git/") || strings.HasPrefix(cleaned, "-") {
+		return ErrInvalidGitConnection.WithMessage("invalid path %q", c.Path)
+	}
+	switch path.Ext(cleaned) {
+	case ".json", ".yaml", ".yml":
+	default:
+		return ErrInvalidGitConnection.WithMessage("path must end in .json, .yaml or .yml")
+	}
+
+	return nil
+}
+
+// WorkflowDocument is the definition of a workflow as kept in Git. Keys
+// are those of the workflow API in both formats, so a document reads the
+// same whichever format the repository keeps. The version is left out: a
+// version that does not change the definition does not change the document.
+type WorkflowDocument struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Nodes       []Node       `json:"nodes"`
+	Connections []Connection `json:"connections"`
+	Settings    Settings     `json:"settings"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+}
+
+// Document returns the definition of a workflow kept in Git
+func (w *Workflow) Document() *WorkflowDocument {
+	return &WorkflowDocument{
+		ID:          w.ID,
+		Name:        w.Name,
+		Description: w.Description,
+		Tags:        w.Tags,
+		Nodes:       w.Nodes,
+		Connections: w.Connections,
+		Settings:    w.Settings,
+		Annotations: w.Annotations,
+	}
+}
+
+// EncodeWorkflowDocument renders a document in a format, JSON indented or
+// YAML with sorted keys, so unchanged definitions render the same
+func EncodeWorkflowDocument(doc *WorkflowDocument, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode workflow document: %w", err)
+	}
+	if format != GitFormatYAML {
+		return append(data, '\n'), nil
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("failed to encode workflow document: %w", err)
+	}
+	return yaml.Marshal(generic)
+}
+
+// DecodeWorkflowDocument parses a document in a format
+func DecodeWorkflowDocument(data []byte, format string) (*WorkflowDocument, error) {
+	return decodeWorkflowDocument(data, format, false)
+}
+
+// DecodeWorkflowDocumentStrict parses a document in a format, refusing the
+// keys a workflow does not have so a misspelt key is reported, not dropped
+func DecodeWorkflowDocumentStrict(data []byte, format string) (*WorkflowDocument, error) {
+	return decodeWorkflowDocument(data, format, true)
+}
+
+func decodeWorkflowDocument(data []byte, format string, strict bool) (*WorkflowDocument, error) {
+	if format == GitFormatYAML {
+		var generic interface{}
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return nil, ErrInvalidGitDocument.WithMessage("invalid YAML: %v", err)
+		}
+		converted, err := json.Marshal(generic)
+		if err != nil {
+			return nil, ErrInvalidGitDocument.WithMessage("invalid YAML: %v", err)
+		}
+		data = converted
+	}
+
+	var doc WorkflowDocument
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, ErrInvalidGitDocument.WithMessage("invalid document: %v", err)
+	}
+	if doc.Name == "" {
+		return nil, ErrInvalidGitDocument.WithMessage("document has no name")
+	}
+	return &doc, nil
+}
+
+// Apply returns a copy of a workflow with the definition of a document,
+// its identity, owner and status kept
+func (d *WorkflowDocument) Apply(wf *Workflow) *Workflow {
+	applied := *wf
+	applied.Name = d.Name
+	applied.Description = d.Description
+	applied.Tags = d.Tags
+	applied.Nodes = d.Nodes
+	applied.Connections = d.Connections
+	applied.Settings = d.Settings
+	applied.Annotations = d.Annotations
+	return &applied
+}
+
+// GitPullPreview shows what pulling a document from Git would change,
+// before it is applied
+type GitPullPreview struct {
+	Commit   string          `json:"commit"`
+	UpToDate bool            `json:"upToDate"`
+	Fields   []string        `json:"fields"`
+	Diff     *DefinitionDiff `json:"diff"`
+	Valid    bool            `json:"valid"`
+	Errors   []string        `json:"errors"`
+	Warnings []string        `json:"warnings"`
+	Workflow *Workflow       `json:"workflow"`
+}
+
+// ChangedFields names the properties outside nodes and connections that
+// differ between two definitions
+func ChangedFields(from, to *Workflow) []string {
+	fields := []string{}
+	if from.Name != to.Name {
+		fields = append(fields, "name")
+	}
+	if from.Description != to.Description {
+		fields = append(fields, "description")
+	}
+	if !reflect.DeepEqual(normalizeTags(from.Tags), normalizeTags(to.Tags)) {
+		fields = append(fields, "tags")
+	}
+	// Compared as encoded, unset and empty settings being the same
+	before, _ := json.Marshal(from.Settings)
+	after, _ := json.Marshal(to.Settings)
+	if string(before) != string(after) {
+		fields = append(fields, "settings")
+	}
+	if len(from.Annotations) > 0 || len(to.Annotations) > 0 {
+		before, _ = json.Marshal(from.Annotations)
+		after, _ = json.Marshal(to.Annotations)
+		if string(before) != string(after) {
+			fields = append(fields, "annotations")
+		}
+	}
+	return fields
+}
+
+func normalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
@@ -0,0 +1,218 @@
+package workflow
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Variable sources, from the one every other overrides to the one that
+// overrides them all
+const (
+	SourceGlobal      = "global"
+	SourceTeam        = "team"
+	SourceWorkflow    = "workflow"
+	SourceEnvironment = "environment"
+	SourceInput       = "input"
+)
+
+// MaskedValue replaces the value of a secret variable in API responses
+const MaskedValue = "••••••••"
+
+// VariableLayer holds the variables a source defines
+type VariableLayer struct {
+	Source string
+	// Name tells sources of the same kind apart, as the team ID or the
+	// environment name
+	Name      string
+	Variables map[string]interface{}
+	// Secrets are the keys whose values are secret
+	Secrets map[string]bool
+}
+
+// VariableOverride is a value a variable took from a source before a later
+// source overrode it
+type VariableOverride struct {
+	Source     string      `json:"source"`
+	SourceName string      `json:"sourceName,omitempty"`
+	Value      interface{} `json:"value"`
+	Secret     bool        `json:"secret,omitempty"`
+}
+
+// ResolvedVariable is the effective value of a variable and the source it
+// comes from
+type ResolvedVariable struct {
+	Key        string      `json:"key"`
+	Value      interface{} `json:"value"`
+	Source     string      `json:"source"`
+	SourceName string      `json:"sourceName,omitempty"`
+	Secret     bool        `json:"secret,omitempty"`
+	// Overrides are the values of the sources this one overrides, the
+	// lowest first
+	Overrides []VariableOverride `json:"overrides,omitempty"`
+}
+
+// SharedVariable is a variable of the variable service, global when it has
+// no team. Secret values are encrypted with a key only that service holds.
+type SharedVariable struct {
+	ID     string  `json:"id" gorm:"primaryKey"`
+	Key    string  `json:"key"`
+	Value  string  `json:"value"`
+	Type   string  `json:"type"`
+	TeamID *string `json:"teamId,omitempty"`
+}
+
+// TableName specifies the table name for GORM
+func (SharedVariable) TableName() string {
+	return "variable.variables"
+}
+
+// Resolve merges layers given in the order global, team, workflow,
+// environment and execution input: a key defined by a layer overrides the
+// value of the layers before it. Variables are sorted by key.
+func (vm *VariableManager) Resolve(layers ...VariableLayer) []*ResolvedVariable {
+	resolved := make(map[string]*ResolvedVariable)
+	for _, layer := range layers {
+		for key, value := range layer.Variables {
+			variable, ok := resolved[key]
+			if !ok {
+				variable = &ResolvedVariable{Key: key}
+				resolved[key] = variable
+			} else {
+				variable.Overrides = append(variable.Overrides, VariableOverride{
+					Source:     variable.Source,
+					SourceName: variable.SourceName,
+					Value:      variable.Value,
+					Secret:     variable.Secret,
+				})
+			}
+			variable.Value = value
+			variable.Source = layer.Source
+			variable.SourceName = layer.Name
+			variable.Secret = layer.Secrets[key]
+		}
+	}
+
+	result := make([]*ResolvedVariable, 0, len(resolved))
+	for _, variable := range resolved {
+		result = append(result, variable)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// MaskSecrets hides the secret values of resolved variables, for showing
+// them
+func MaskSecrets(resolved []*ResolvedVariable) {
+	for _, variable := range resolved {
+		if variable.Secret {
+			variable.Value = MaskedValue
+		}
+		for i := range variable.Overrides {
+			if variable.Overrides[i].Secret {
+				variable.Overrides[i].Value = MaskedValue
+			}
+		}
+	}
+}
+
+// ResolvedValues returns the values of resolved variables by key. Secret
+// variables are left out: executions read secrets through credentials.
+func ResolvedValues(resolved []*ResolvedVariable) map[string]interface{} {
+	values := make(map[string]interface{}, len(resolved))
+	for _, variable := range resolved {
+		if !variable.Secret {
+			values[variable.Key] = variable.Value
+		}
+	}
+	return values
+}
+
+// SharedVariableLayers splits the variables of the variable service into
+// the global layer and the layer of a team. Secret values never leave the
+// variable service, they are only marked as defined.
+func SharedVariableLayers(variables []SharedVariable, teamID string) []VariableLayer {
+	global := VariableLayer{Source: SourceGlobal, Variables: map[string]interface{}{}, Secrets: map[string]bool{}}
+	team := VariableLayer{Source: SourceTeam, Name: teamID, Variables: map[string]interface{}{}, Secrets: map[string]bool{}}
+
+	for _, v := range variables {
+		layer := &global
+		if v.TeamID != nil && *v.TeamID != "" {
+			if *v.TeamID != teamID {
+				continue
+			}
+			layer = &team
+		}
+
+		if v.Type == VarTypeSecret {
+			layer.Variables[v.Key] = nil
+			layer.Secrets[v.Key] = true
+			continue
+		}
+		layer.Variables[v.Key] = sharedValue(v)
+	}
+	return []VariableLayer{global, team}
+}
+
+// sharedValue converts the stored text of a variable to its type
+func sharedValue(v SharedVariable) interface{} {
+	if v.Type == VarTypeJSON {
+		var value interface{}
+		if err := json.Unmarshal([]byte(v.Value), &value); err == nil {
+			return value
+		}
+		return v.Value
+	}
+	value, err := CoerceVariableType(v.Value, v.Type)
+	if err != nil {
+		return v.Value
+	}
+	return value
+}
+
+// WorkflowVariableLayer is the layer of the variables of a workflow. Those
+// bound to an environment belong to the layer of that environment.
+func WorkflowVariableLayer(workflowID string, variables []*WorkflowVariable) VariableLayer {
+	layer := VariableLayer{Source: SourceWorkflow, Name: workflowID, Variables: map[string]interface{}{}, Secrets: map[string]bool{}}
+	for _, v := range variables {
+		if v.Environment == "" {
+			layer.add(v)
+		}
+	}
+	return layer
+}
+
+// VariableLayer is the layer of the variables of the environment: its own
+// and the workflow variables bound to it, by ID or by name, which take
+// precedence
+func (e *Environment) VariableLayer(variables []*WorkflowVariable) VariableLayer {
+	layer := VariableLayer{Source: SourceEnvironment, Name: e.Name, Variables: map[string]interface{}{}, Secrets: map[string]bool{}}
+	for k, v := range e.Variables {
+		layer.Variables[k] = v
+	}
+	for _, v := range variables {
+		if v.Environment != "" && (v.Environment == e.ID || v.Environment == e.Name) {
+			layer.add(v)
+		}
+	}
+	return layer
+}
+
+// add sets a workflow variable in the layer
+func (l *VariableLayer) add(v *WorkflowVariable) {
+	l.Variables[v.Key] = v.Value
+	if v.Encrypted || v.Type == VarTypeSecret {
+		l.Secrets[v.Key] = true
+	}
+}
+
+// InputVariableLayer is the layer of the overrides an execution input gives
+// under EnvironmentVariablesKey
+func InputVariableLayer(inputData map[string]interface{}) VariableLayer {
+	layer := VariableLayer{Source: SourceInput, Variables: map[string]interface{}{}}
+	if overrides, ok := inputData[EnvironmentVariablesKey].(map[string]interface{}); ok {
+		for k, v := range overrides {
+			layer.Variables[k] = v
+		}
+	}
+	return layer
+}
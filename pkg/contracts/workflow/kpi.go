@@ -0,0 +1,76 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// metricName is the form of the names of business metrics, such as
+// invoices_processed
+var metricName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// MetricValues resolves the metrics a metric node emits. Its metrics
+// parameter maps each metric name to a number, or to the dot path of a
+// numeric field of the node input, such as "invoice.total".
+func MetricValues(params map[string]interface{}, input map[string]interface{}) (map[string]float64, error) {
+	metrics, ok := params["metrics"].(map[string]interface{})
+	if !ok || len(metrics) == 0 {
+		return nil, fmt.Errorf("metric node requires a 'metrics' parameter")
+	}
+
+	values := make(map[string]float64, len(metrics))
+	for name, value := range metrics {
+		if !metricName.MatchString(name) {
+			return nil, fmt.Errorf("invalid metric name %q: use lowercase letters, digits and underscores", name)
+		}
+
+		number, ok := toMetricNumber(value)
+		if !ok {
+			path, isPath := value.(string)
+			if !isPath {
+				return nil, fmt.Errorf("metric %s must be a number or the path of a numeric input field", name)
+			}
+			number, ok = toMetricNumber(inputField(input, path))
+			if !ok {
+				return nil, fmt.Errorf("metric %s: input field %s is not a number", name, path)
+			}
+		}
+		values[name] = number
+	}
+	return values, nil
+}
+
+// toMetricNumber converts the numbers of JSON documents and their string
+// forms
+func toMetricNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// inputField returns the field of input at a dot path
+func inputField(input map[string]interface{}, path string) interface{} {
+	var current interface{} = input
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
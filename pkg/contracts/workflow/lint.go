@@ -0,0 +1,482 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Lint severities, errors fail validation while warnings are only reported
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+)
+
+// Built-in lint rule IDs, the IDs suppressions refer to
+const (
+	LintRuleHardcodedSecrets = "no-hardcoded-secrets"
+	LintRuleUnreachableNode  = "unreachable-node"
+	LintRuleHTTPErrorBranch  = "http-error-branch"
+	LintRuleCronFrequency    = "cron-frequency"
+	// LintRuleSuppression reports suppressions that are not applied
+	LintRuleSuppression = "lint-suppression"
+)
+
+// DefaultMinScheduleInterval is the shortest interval between the runs of a
+// cron trigger cron-frequency allows unless configured otherwise
+const DefaultMinScheduleInterval = 5 * time.Minute
+
+// LintSettings configures the linting of a workflow
+type LintSettings struct {
+	Suppressions []LintSuppression `json:"suppressions,omitempty"`
+}
+
+// LintSuppression silences a lint rule for a node, or for the whole workflow
+// when NodeID is empty. A suppression without a comment is not applied.
+type LintSuppression struct {
+	Rule   string `json:"rule"`
+	NodeID string `json:"nodeId,omitempty"`
+	// Comment explains why the finding is acceptable
+	Comment string `json:"comment"`
+}
+
+// LintFinding is a problem a lint rule found in a workflow
+type LintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	NodeID   string `json:"nodeId,omitempty"`
+	Message  string `json:"message"`
+	// Comment is the comment of the suppression silencing the finding
+	Comment string `json:"comment,omitempty"`
+}
+
+// String formats the finding as validation messages are
+func (f LintFinding) String() string {
+	return fmt.Sprintf("[%s] %s", f.Rule, f.Message)
+}
+
+// LintReport lists the findings of a lint run, suppressed findings apart
+type LintReport struct {
+	Findings   []LintFinding `json:"findings"`
+	Suppressed []LintFinding `json:"suppressed"`
+}
+
+// Errors formats the findings of error severity
+func (r *LintReport) Errors() []string {
+	return r.messages(LintSeverityError)
+}
+
+// Warnings formats the findings of warning severity
+func (r *LintReport) Warnings() []string {
+	return r.messages(LintSeverityWarning)
+}
+
+func (r *LintReport) messages(severity string) []string {
+	messages := []string{}
+	for _, finding := range r.Findings {
+		if finding.Severity == severity {
+			messages = append(messages, finding.String())
+		}
+	}
+	return messages
+}
+
+// LintRule checks a workflow for one kind of problem. The linter fills the
+// rule ID and severity of the findings Check returns.
+type LintRule interface {
+	ID() string
+	Severity() string
+	Description() string
+	Check(w *Workflow) []LintFinding
+}
+
+// LintOptions tunes the built-in lint rules
+type LintOptions struct {
+	// MinScheduleInterval is the shortest interval allowed between the runs
+	// of a cron trigger
+	MinScheduleInterval time.Duration
+}
+
+// DefaultLintRules returns the built-in lint rules
+func DefaultLintRules(opts LintOptions) []LintRule {
+	if opts.MinScheduleInterval <= 0 {
+		opts.MinScheduleInterval = DefaultMinScheduleInterval
+	}
+	return []LintRule{
+		hardcodedSecretsRule{},
+		unreachableNodeRule{},
+		httpErrorBranchRule{},
+		cronFrequencyRule{minInterval: opts.MinScheduleInterval},
+	}
+}
+
+// Linter runs lint rules against workflows, honoring the suppressions in
+// their settings
+type Linter struct {
+	rules []LintRule
+}
+
+// NewLinter creates a linter running the given rules
+func NewLinter(rules ...LintRule) *Linter {
+	l := &Linter{}
+	for _, rule := range rules {
+		l.Register(rule)
+	}
+	return l
+}
+
+// NewDefaultLinter creates a linter running the built-in rules
+func NewDefaultLinter(opts LintOptions) *Linter {
+	return NewLinter(DefaultLintRules(opts)...)
+}
+
+// Register adds a rule, replacing a rule with the same ID
+func (l *Linter) Register(rule LintRule) {
+	for i, existing := range l.rules {
+		if existing.ID() == rule.ID() {
+			l.rules[i] = rule
+			return
+		}
+	}
+	l.rules = append(l.rules, rule)
+}
+
+// Rules returns the rules of the linter in the order they run
+func (l *Linter) Rules() []LintRule {
+	return append([]LintRule{}, l.rules...)
+}
+
+// Lint runs every rule against a workflow
+func (l *Linter) Lint(w *Workflow) *LintReport {
+	report := &LintReport{Findings: []LintFinding{}, Suppressed: []LintFinding{}}
+	suppressions := l.suppressions(w, report)
+
+	for _, rule := range l.rules {
+		for _, finding := range rule.Check(w) {
+			finding.Rule = rule.ID()
+			finding.Severity = rule.Severity()
+			if comment, ok := suppressions.match(finding); ok {
+				finding.Comment = comment
+				report.Suppressed = append(report.Suppressed, finding)
+				continue
+			}
+			report.Findings = append(report.Findings, finding)
+		}
+	}
+	return report
+}
+
+// lintSuppressions maps rule IDs to the comments of their suppressions, by
+// node ID, the empty node ID for the whole workflow
+type lintSuppressions map[string]map[string]string
+
+func (s lintSuppressions) match(finding LintFinding) (string, bool) {
+	byNode := s[finding.Rule]
+	if comment, ok := byNode[""]; ok {
+		return comment, true
+	}
+	if finding.NodeID == "" {
+		return "", false
+	}
+	comment, ok := byNode[finding.NodeID]
+	return comment, ok
+}
+
+// suppressions collects the applicable suppressions of a workflow, reporting
+// those naming unknown rules or missing a comment
+func (l *Linter) suppressions(w *Workflow, report *LintReport) lintSuppressions {
+	result := lintSuppressions{}
+	if w.Settings.Lint == nil {
+		return result
+	}
+
+	known := make(map[string]bool, len(l.rules))
+	for _, rule := range l.rules {
+		known[rule.ID()] = true
+	}
+
+	for _, suppression := range w.Settings.Lint.Suppressions {
+		var problem string
+		switch {
+		case !known[suppression.Rule]:
+			problem = fmt.Sprintf("suppression of unknown rule %q", suppression.Rule)
+		case strings.TrimSpace(suppression.Comment) == "":
+			problem = fmt.Sprintf("suppression of %s has no comment and is ignored", suppression.Rule)
+		}
+		if problem != "" {
+			report.Findings = append(report.Findings, LintFinding{
+				Rule:     LintRuleSuppression,
+				Severity: LintSeverityWarning,
+				NodeID:   suppression.NodeID,
+				Message:  problem,
+			})
+			continue
+		}
+
+		if result[suppression.Rule] == nil {
+			result[suppression.Rule] = map[string]string{}
+		}
+		result[suppression.Rule][suppression.NodeID] = suppression.Comment
+	}
+	return result
+}
+
+// secretValuePatterns match credentials of well known formats wherever they
+// appear in parameters
+var secretValuePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\b[sr]k_live_[0-9A-Za-z]{10,}`),
+	regexp.MustCompile(`\bgh[pousr]_[0-9A-Za-z]{30,}`),
+	regexp.MustCompile(`\bxox[abprs]-[0-9A-Za-z-]{10,}`),
+	regexp.MustCompile(`(?i)\bbearer\s+[0-9A-Za-z._~+/-]{16,}`),
+}
+
+// secretKeySuffixes are the endings, lower cased without separators, of
+// parameter names holding credentials
+var secretKeySuffixes = []string{
+	"password", "passwd", "secret", "token", "apikey", "authorization",
+	"privatekey", "accesskey",
+}
+
+// hardcodedSecretsRule flags credentials written into node parameters rather
+// than referenced from secret variables or credentials
+type hardcodedSecretsRule struct{}
+
+func (hardcodedSecretsRule) ID() string       { return LintRuleHardcodedSecrets }
+func (hardcodedSecretsRule) Severity() string { return LintSeverityError }
+func (hardcodedSecretsRule) Description() string {
+	return "Node parameters must reference secrets with {{ $secrets.KEY }} or credentials, not hold them"
+}
+
+func (hardcodedSecretsRule) Check(w *Workflow) []LintFinding {
+	var findings []LintFinding
+	for _, node := range w.Nodes {
+		walkParameters("", node.Parameters, func(path, value string) {
+			if value == "" || value == MaskedValue || strings.Contains(value, "{{") {
+				return
+			}
+			if isSecretKey(path) || matchesSecretPattern(value) {
+				findings = append(findings, LintFinding{
+					NodeID:  node.ID,
+					Message: fmt.Sprintf("Node %s parameter %s holds a hard-coded secret", node.ID, path),
+				})
+			}
+		})
+	}
+	return findings
+}
+
+// isSecretKey reports whether the last segment of a parameter path names a
+// credential
+func isSecretKey(path string) bool {
+	key := path[strings.LastIndex(path, ".")+1:]
+	key = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	for _, suffix := range secretKeySuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesSecretPattern(value string) bool {
+	for _, pattern := range secretValuePatterns {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkParameters calls fn with every string in a parameter value and its
+// dotted path, items of lists keyed by their parent
+func walkParameters(path string, value interface{}, fn func(path, value string)) {
+	switch v := value.(type) {
+	case string:
+		fn(path, v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			walkParameters(child, v[key], fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkParameters(path, item, fn)
+		}
+	}
+}
+
+// unreachableNodeRule flags nodes no trigger leads to, which never run
+type unreachableNodeRule struct{}
+
+func (unreachableNodeRule) ID() string       { return LintRuleUnreachableNode }
+func (unreachableNodeRule) Severity() string { return LintSeverityWarning }
+func (unreachableNodeRule) Description() string {
+	return "Every enabled node must be reachable from a trigger"
+}
+
+func (unreachableNodeRule) Check(w *Workflow) []LintFinding {
+	graph := make(map[string][]string)
+	for _, conn := range w.Connections {
+		graph[conn.Source] = append(graph[conn.Source], conn.Target)
+	}
+
+	reachable := make(map[string]bool)
+	var queue []string
+	visit := func(nodeID string) {
+		if !reachable[nodeID] {
+			reachable[nodeID] = true
+			queue = append(queue, nodeID)
+		}
+	}
+	for _, node := range w.Nodes {
+		if node.Type == NodeTypeTrigger || node.Type == NodeTypeWebhook {
+			visit(node.ID)
+		}
+	}
+	// Without a trigger nothing runs, the validator reports that
+	if len(queue) == 0 {
+		return nil
+	}
+
+	for len(queue) > 0 {
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, next := range graph[current] {
+				visit(next)
+			}
+		}
+		// Catch nodes run when a node of their try block fails
+		for _, boundary := range w.Settings.ErrorBoundaries {
+			for _, nodeID := range boundary.TryNodes {
+				if reachable[nodeID] {
+					visit(boundary.CatchNode)
+					break
+				}
+			}
+		}
+	}
+
+	var findings []LintFinding
+	for _, node := range w.Nodes {
+		if reachable[node.ID] || node.Disabled || w.IsCompensationNode(node.ID) {
+			continue
+		}
+		findings = append(findings, LintFinding{
+			NodeID:  node.ID,
+			Message: fmt.Sprintf("Node %s (%s) is not reachable from any trigger", node.ID, node.Name),
+		})
+	}
+	return findings
+}
+
+// httpErrorBranchRule flags HTTP nodes whose failure fails the execution
+// with nothing handling it
+type httpErrorBranchRule struct{}
+
+func (httpErrorBranchRule) ID() string       { return LintRuleHTTPErrorBranch }
+func (httpErrorBranchRule) Severity() string { return LintSeverityWarning }
+func (httpErrorBranchRule) Description() string {
+	return "HTTP nodes must continue on fail, sit in an error boundary or the workflow must have an error workflow"
+}
+
+func (httpErrorBranchRule) Check(w *Workflow) []LintFinding {
+	if w.Settings.ErrorHandling.ContinueOnFail || w.Settings.ErrorHandling.ErrorWorkflow != "" {
+		return nil
+	}
+
+	var findings []LintFinding
+	for _, node := range w.Nodes {
+		if node.Type != NodeTypeHTTPRequest || node.Disabled || node.ContinueOnFail {
+			continue
+		}
+		if w.FindErrorBoundary(node.ID) != nil {
+			continue
+		}
+		findings = append(findings, LintFinding{
+			NodeID:  node.ID,
+			Message: fmt.Sprintf("HTTP node %s has no error branch", node.ID),
+		})
+	}
+	return findings
+}
+
+// cronScheduleParser parses cron expressions as schedule triggers do
+var cronScheduleParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// cronSampleRuns is how many runs of a schedule are compared to find its
+// shortest interval
+const cronSampleRuns = 100
+
+// cronFrequencyRule flags cron triggers running more often than allowed
+type cronFrequencyRule struct {
+	minInterval time.Duration
+}
+
+func (cronFrequencyRule) ID() string       { return LintRuleCronFrequency }
+func (cronFrequencyRule) Severity() string { return LintSeverityWarning }
+func (r cronFrequencyRule) Description() string {
+	return fmt.Sprintf("Cron triggers must not run more often than every %s", r.minInterval)
+}
+
+func (r cronFrequencyRule) Check(w *Workflow) []LintFinding {
+	var findings []LintFinding
+	for _, node := range w.Nodes {
+		if node.Disabled {
+			continue
+		}
+		expression, _ := node.Parameters["cronExpression"].(string)
+		if expression == "" {
+			expression, _ = node.Parameters["cron"].(string)
+		}
+		if expression == "" {
+			continue
+		}
+
+		// Invalid expressions are reported when the trigger is saved
+		schedule, err := cronScheduleParser.Parse(expression)
+		if err != nil {
+			continue
+		}
+		if interval := shortestInterval(schedule); interval > 0 && interval < r.minInterval {
+			findings = append(findings, LintFinding{
+				NodeID: node.ID,
+				Message: fmt.Sprintf("Node %s cron %q runs every %s, more often than the allowed %s",
+					node.ID, expression, interval, r.minInterval),
+			})
+		}
+	}
+	return findings
+}
+
+// shortestInterval is the shortest time between consecutive runs of a
+// schedule over its first runs of a fixed year
+func shortestInterval(schedule cron.Schedule) time.Duration {
+	var shortest time.Duration
+	previous := schedule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	for i := 0; i < cronSampleRuns && !previous.IsZero(); i++ {
+		next := schedule.Next(previous)
+		if next.IsZero() {
+			break
+		}
+		if interval := next.Sub(previous); shortest == 0 || interval < shortest {
+			shortest = interval
+		}
+		previous = next
+	}
+	return shortest
+}
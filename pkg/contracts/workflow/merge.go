@@ -0,0 +1,184 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var (
+	ErrInvalidMerge = apperrors.New(apperrors.CategoryValidation, "INVALID_MERGE", "invalid merge node parameters")
+	ErrMergeTimeout = apperrors.New(apperrors.CategoryUpstream, "MERGE_TIMEOUT", "merge node timed out waiting for its branches")
+)
+
+// Strategies of merge nodes, how they wait for the branches joining at them
+// and combine their outputs
+const (
+	// MergeWaitAll waits for every branch and combines their outputs, later
+	// connections winning on the same field
+	MergeWaitAll = "wait-all"
+	// MergeWaitAny runs with the first branch to finish, ignoring the others
+	MergeWaitAny = "wait-any"
+	// MergeAppend waits for every branch and concatenates their items
+	MergeAppend = "append"
+	// MergeByKey waits for every branch and merges their items sharing the
+	// value of a key field
+	MergeByKey = "merge-by-key"
+)
+
+// What a merge node does when its wait timeout passes before every branch
+// finished
+const (
+	MergeTimeoutFail     = "fail"
+	MergeTimeoutContinue = "continue"
+)
+
+// DefaultMergeField is the field of branch outputs holding the items to
+// append or merge by key
+const DefaultMergeField = "items"
+
+// MergeParameters are the parameters of a merge node
+type MergeParameters struct {
+	Strategy string
+	// WaitTimeout bounds the wait for the other branches once the first
+	// finished, 0 waits as long as they run
+	WaitTimeout time.Duration
+	OnTimeout   string
+	// Field holds the items of each branch for append and merge-by-key
+	Field string
+	// Key is the field of the items identifying them for merge-by-key
+	Key string
+}
+
+// MergeInput is the output of a branch joining at a merge node
+type MergeInput struct {
+	NodeID string
+	Data   map[string]interface{}
+}
+
+// ParseMergeParameters reads the strategy, waitTimeout (seconds),
+// onTimeout, field and key parameters of a merge node. Without a strategy
+// it waits for all its branches and fails when the wait times out.
+func ParseMergeParameters(params map[string]interface{}) (MergeParameters, error) {
+	merge := MergeParameters{
+		Strategy:  MergeWaitAll,
+		OnTimeout: MergeTimeoutFail,
+		Field:     DefaultMergeField,
+	}
+
+	if strategy, ok := params["strategy"].(string); ok && strategy != "" {
+		merge.Strategy = strategy
+	}
+	switch merge.Strategy {
+	case MergeWaitAll, MergeWaitAny, MergeAppend, MergeByKey:
+	default:
+		return merge, fmt.Errorf("%w: unknown strategy %q", ErrInvalidMerge, merge.Strategy)
+	}
+
+	if value, ok := params["waitTimeout"]; ok {
+		seconds, isNumber := toMetricNumber(value)
+		if !isNumber || seconds < 0 {
+			return merge, fmt.Errorf("%w: waitTimeout must be a number of seconds", ErrInvalidMerge)
+		}
+		merge.WaitTimeout = time.Duration(seconds * float64(time.Second))
+	}
+
+	if onTimeout, ok := params["onTimeout"].(string); ok && onTimeout != "" {
+		if onTimeout != MergeTimeoutFail && onTimeout != MergeTimeoutContinue {
+			return merge, fmt.Errorf("%w: onTimeout must be %s or %s", ErrInvalidMerge, MergeTimeoutFail, MergeTimeoutContinue)
+		}
+		merge.OnTimeout = onTimeout
+	}
+
+	if field, ok := params["field"].(string); ok && field != "" {
+		merge.Field = field
+	}
+	merge.Key, _ = params["key"].(string)
+	if merge.Strategy == MergeByKey && merge.Key == "" {
+		return merge, fmt.Errorf("%w: merge-by-key requires a 'key' parameter", ErrInvalidMerge)
+	}
+	return merge, nil
+}
+
+// WaitsForAll reports whether the merge node runs only once every branch
+// finished, or its wait timed out
+func (m MergeParameters) WaitsForAll() bool {
+	return m.Strategy != MergeWaitAny
+}
+
+// Merge combines the outputs of the branches joining at a merge node, given
+// in the order of their connections
+func (m MergeParameters) Merge(inputs []MergeInput) map[string]interface{} {
+	switch m.Strategy {
+	case MergeWaitAny:
+		output := make(map[string]interface{})
+		if len(inputs) > 0 {
+			for k, v := range inputs[0].Data {
+				output[k] = v
+			}
+		}
+		return output
+
+	case MergeAppend:
+		items := make([]interface{}, 0)
+		for _, input := range inputs {
+			items = append(items, m.items(input)...)
+		}
+		return map[string]interface{}{m.Field: items, "count": len(items)}
+
+	case MergeByKey:
+		return m.mergeByKey(inputs)
+
+	default:
+		output := make(map[string]interface{})
+		for _, input := range inputs {
+			for k, v := range input.Data {
+				output[k] = v
+			}
+		}
+		return output
+	}
+}
+
+// items returns the items of a branch output, the output itself when it
+// holds no list under the merge field
+func (m MergeParameters) items(input MergeInput) []interface{} {
+	if items, ok := input.Data[m.Field].([]interface{}); ok {
+		return items
+	}
+	if input.Data == nil {
+		return nil
+	}
+	return []interface{}{input.Data}
+}
+
+// mergeByKey merges the items of the branches sharing the value of the key
+// field, in the order they first appear. Items without the key are kept as
+// they are.
+func (m MergeParameters) mergeByKey(inputs []MergeInput) map[string]interface{} {
+	items := make([]interface{}, 0)
+	byKey := make(map[string]map[string]interface{})
+
+	for _, input := range inputs {
+		for _, item := range m.items(input) {
+			record, ok := item.(map[string]interface{})
+			if !ok || record[m.Key] == nil {
+				items = append(items, item)
+				continue
+			}
+
+			key := fmt.Sprint(record[m.Key])
+			merged, seen := byKey[key]
+			if !seen {
+				merged = make(map[string]interface{}, len(record))
+				byKey[key] = merged
+				items = append(items, merged)
+			}
+			for k, v := range record {
+				merged[k] = v
+			}
+		}
+	}
+	return map[string]interface{}{m.Field: items, "count": len(items)}
+}
@@ -0,0 +1,245 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Types of the fields of an output schema, those of JSON Schema
+const (
+	SchemaTypeObject  = "object"
+	SchemaTypeArray   = "array"
+	SchemaTypeString  = "string"
+	SchemaTypeNumber  = "number"
+	SchemaTypeBoolean = "boolean"
+	SchemaTypeNull    = "null"
+)
+
+// fieldPath is an expression reading a field of the node input, such as
+// {{ customer.email }}
+var fieldPath = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z0-9_]+)*$`)
+
+// passThroughNodeTypes output their input, the fields of the nodes before
+// them reach the nodes after them
+var passThroughNodeTypes = map[string]bool{
+	NodeTypeCondition: true,
+	NodeTypeLoop:      true,
+	NodeTypeMetric:    true,
+}
+
+// FieldSchema is the JSON Schema of a value inferred from samples. A field
+// seen with values of different types has no type.
+type FieldSchema struct {
+	Type       string                  `json:"type,omitempty"`
+	Properties map[string]*FieldSchema `json:"properties,omitempty"`
+	Items      *FieldSchema            `json:"items,omitempty"`
+}
+
+// NodeOutputSchema is the schema of the output of a node, inferred from the
+// outputs of test runs
+type NodeOutputSchema struct {
+	ID         string       `json:"id" gorm:"primaryKey"`
+	TenantID   string       `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
+	WorkflowID string       `json:"workflowId" gorm:"not null;uniqueIndex:idx_node_output_schemas_node"`
+	NodeID     string       `json:"nodeId" gorm:"not null;uniqueIndex:idx_node_output_schemas_node"`
+	Schema     *FieldSchema `json:"schema" gorm:"serializer:json"`
+	// Samples counts the outputs the schema was inferred from
+	Samples   int       `json:"samples"`
+	UpdatedBy string    `json:"updatedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (NodeOutputSchema) TableName() string {
+	return "workflow.node_output_schemas"
+}
+
+// TestWorkflowRequest is a test run of a workflow
+type TestWorkflowRequest struct {
+	Data  map[string]interface{} `json:"data"`
+	Debug bool                   `json:"debug"`
+	// NodeOutputs are sample outputs of nodes by node ID, their schemas are
+	// inferred and stored
+	NodeOutputs map[string]map[string]interface{} `json:"nodeOutputs"`
+	// ResetSchemas replaces the stored output schemas of the sampled nodes
+	// instead of merging into them
+	ResetSchemas bool `json:"resetSchemas"`
+	// TriggerID and Fixture replay a fixture of a trigger of the workflow
+	// as the data
+	TriggerID string `json:"triggerId"`
+	Fixture   string `json:"fixture"`
+}
+
+// InferSchema infers the schema of a sample value
+func InferSchema(value interface{}) *FieldSchema {
+	switch v := value.(type) {
+	case nil:
+		return &FieldSchema{Type: SchemaTypeNull}
+	case string:
+		return &FieldSchema{Type: SchemaTypeString}
+	case bool:
+		return &FieldSchema{Type: SchemaTypeBoolean}
+	case float64, float32, int, int32, int64, uint, uint32, uint64:
+		return &FieldSchema{Type: SchemaTypeNumber}
+	case []interface{}:
+		schema := &FieldSchema{Type: SchemaTypeArray}
+		for _, item := range v {
+			schema.Items = MergeSchemas(schema.Items, InferSchema(item))
+		}
+		return schema
+	case map[string]interface{}:
+		if _, spilled := AsPayloadRef(v); spilled {
+			// The content of spilled fields is not known
+			return &FieldSchema{}
+		}
+		schema := &FieldSchema{Type: SchemaTypeObject, Properties: make(map[string]*FieldSchema, len(v))}
+		for field, item := range v {
+			schema.Properties[field] = InferSchema(item)
+		}
+		return schema
+	default:
+		return &FieldSchema{}
+	}
+}
+
+// MergeSchemas combines the schemas of two samples of a value. Objects
+// have the fields of both, nulls take the type of the other sample.
+func MergeSchemas(a, b *FieldSchema) *FieldSchema {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.Type == SchemaTypeNull:
+		return b
+	case b.Type == SchemaTypeNull:
+		return a
+	case a.Type != b.Type:
+		return &FieldSchema{}
+	}
+
+	merged := &FieldSchema{Type: a.Type, Items: MergeSchemas(a.Items, b.Items)}
+	if a.Properties != nil || b.Properties != nil {
+		merged.Properties = make(map[string]*FieldSchema, len(a.Properties)+len(b.Properties))
+		for field, schema := range a.Properties {
+			merged.Properties[field] = schema
+		}
+		for field, schema := range b.Properties {
+			merged.Properties[field] = MergeSchemas(merged.Properties[field], schema)
+		}
+	}
+	return merged
+}
+
+// HasField reports whether the schema may hold a field at a dot path.
+// Fields without a type, of lists included, may hold anything.
+func (s *FieldSchema) HasField(path string) bool {
+	current := s
+	for _, part := range strings.Split(path, ".") {
+		for current.Type == SchemaTypeArray {
+			if current.Items == nil {
+				return true
+			}
+			current = current.Items
+		}
+		if current.Type == "" {
+			return true
+		}
+		if current.Type != SchemaTypeObject {
+			return false
+		}
+		next, ok := current.Properties[part]
+		if !ok {
+			return false
+		}
+		current = next
+	}
+	return true
+}
+
+// InputFields lists the input fields a node reads, from the {{ path }}
+// expressions of its parameters and the sources of transform mappings
+func (n *Node) InputFields() []string {
+	seen := make(map[string]bool)
+	walkStrings(n.Parameters, func(s string) {
+		for _, match := range transformExpression.FindAllStringSubmatch(s, -1) {
+			if fieldPath.MatchString(match[1]) {
+				seen[match[1]] = true
+			}
+		}
+	})
+	if n.Type == NodeTypeTransform {
+		if transform, err := ParseTransformParameters(n.Parameters); err == nil {
+			for _, mapping := range transform.Mappings {
+				if mapping.Source != "" {
+					seen[mapping.Source] = true
+				}
+			}
+		}
+	}
+
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// CheckOutputContracts warns of the input fields nodes read that no node
+// before them outputs, according to the output schemas of the nodes keyed
+// by node ID. A node is only checked when the schema of every node its
+// input comes from is known.
+func CheckOutputContracts(w *Workflow, schemas map[string]*FieldSchema) []string {
+	if len(schemas) == 0 {
+		return nil
+	}
+
+	dag := NewDAG(w)
+	var warnings []string
+	for _, node := range w.Nodes {
+		if node.Disabled {
+			continue
+		}
+		fields := node.InputFields()
+		if len(fields) == 0 {
+			continue
+		}
+
+		var sources []*FieldSchema
+		known := true
+		for _, ancestorID := range dag.GetAncestors(node.ID) {
+			ancestor, ok := dag.Nodes[ancestorID]
+			if !ok || ancestor.Disabled || passThroughNodeTypes[ancestor.Type] {
+				continue
+			}
+			schema, ok := schemas[ancestorID]
+			if !ok {
+				known = false
+				break
+			}
+			sources = append(sources, schema)
+		}
+		if !known || len(sources) == 0 {
+			continue
+		}
+
+		for _, field := range fields {
+			found := false
+			for _, schema := range sources {
+				if schema.HasField(field) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				warnings = append(warnings, fmt.Sprintf("Node %s reads field %s that no node before it outputs", node.ID, field))
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,290 @@
+package workflow
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+)
+
+// PayloadRefKey marks a field spilled to the payload store, its value is
+// an object holding the PayloadRef under this key
+const PayloadRefKey = "$payload"
+
+// PayloadRef points at a field of node data kept in the payload store
+// instead of being passed between nodes inline
+type PayloadRef struct {
+	Key string `json:"key"`
+	// Size is the size of the JSON form of the field, in bytes
+	Size int64 `json:"size"`
+}
+
+// PayloadStore keeps node data too large to pass between nodes inline.
+// Payloads are written and read as streams of their JSON form so neither
+// side needs them in memory as a whole.
+type PayloadStore interface {
+	// Put stores the body under key and returns its size in bytes
+	Put(ctx context.Context, key string, body io.Reader) (int64, error)
+	// Open streams the payload stored under key
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes every payload stored under a key starting with prefix
+	Delete(ctx context.Context, prefix string) error
+}
+
+// PayloadKey is where a field of the output of a node is spilled
+func PayloadKey(executionID, nodeID, field string) string {
+	return path.Join(PayloadPrefix(executionID), nodeID, field)
+}
+
+// PayloadPrefix starts the keys of the fields spilled by the nodes of an
+// execution
+func PayloadPrefix(executionID string) string {
+	return path.Join("executions", executionID) + "/"
+}
+
+// DeletePayloads removes the fields spilled by the nodes of an execution,
+// once the execution is deleted
+func DeletePayloads(ctx context.Context, store PayloadStore, executionID string) error {
+	// An empty ID would name the payloads of every execution
+	if store == nil || executionID == "" {
+		return nil
+	}
+	return store.Delete(ctx, PayloadPrefix(executionID))
+}
+
+// AsPayloadRef reports whether a value is a reference to a spilled field
+func AsPayloadRef(value interface{}) (PayloadRef, bool) {
+	wrapper, ok := value.(map[string]interface{})
+	if !ok || len(wrapper) != 1 {
+		return PayloadRef{}, false
+	}
+	switch ref := wrapper[PayloadRefKey].(type) {
+	case PayloadRef:
+		return ref, true
+	case map[string]interface{}:
+		// Decoded from the wire or a stored record
+		key, _ := ref["key"].(string)
+		size, _ := toMetricNumber(ref["size"])
+		return PayloadRef{Key: key, Size: int64(size)}, key != ""
+	}
+	return PayloadRef{}, false
+}
+
+// SpillPayloads moves the fields of node data whose JSON form is larger
+// than threshold bytes to the store, under keyOf(field), and returns the
+// data with references in their place. Fields already spilled are kept as
+// they are.
+func SpillPayloads(ctx context.Context, store PayloadStore, data map[string]interface{}, threshold int64, keyOf func(field string) string) (map[string]interface{}, error) {
+	if store == nil || threshold <= 0 || data == nil {
+		return data, nil
+	}
+
+	var spilled map[string]interface{}
+	for _, field := range sortedFields(data) {
+		value := data[field]
+		if _, isRef := AsPayloadRef(value); isRef || estimateJSONSize(value, threshold) <= threshold {
+			continue
+		}
+
+		key := keyOf(field)
+		size, err := putJSON(ctx, store, key, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to spill field %s: %w", field, err)
+		}
+
+		if spilled == nil {
+			spilled = make(map[string]interface{}, len(data))
+			for k, v := range data {
+				spilled[k] = v
+			}
+		}
+		spilled[field] = map[string]interface{}{PayloadRefKey: PayloadRef{Key: key, Size: size}}
+	}
+
+	if spilled == nil {
+		return data, nil
+	}
+	return spilled, nil
+}
+
+// ResolvePayloads returns node data with its spilled fields read back from
+// the store, for nodes computing on their content
+func ResolvePayloads(ctx context.Context, store PayloadStore, data map[string]interface{}) (map[string]interface{}, error) {
+	return resolvePayloads(ctx, store, data, func(string) bool { return true })
+}
+
+// ResolvePayloadFields returns node data with the listed fields read back
+// from the store when they are spilled, for nodes computing on some fields
+// only. The other fields stay references.
+func ResolvePayloadFields(ctx context.Context, store PayloadStore, data map[string]interface{}, fields []string) (map[string]interface{}, error) {
+	if len(fields) == 0 {
+		return data, nil
+	}
+	wanted := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		wanted[field] = true
+	}
+	return resolvePayloads(ctx, store, data, func(field string) bool { return wanted[field] })
+}
+
+// resolvePayloads reads back the spilled fields of node data wanted by
+// resolve
+func resolvePayloads(ctx context.Context, store PayloadStore, data map[string]interface{}, resolve func(field string) bool) (map[string]interface{}, error) {
+	var resolved map[string]interface{}
+	for field, value := range data {
+		ref, ok := AsPayloadRef(value)
+		if !ok || !resolve(field) {
+			continue
+		}
+		if store == nil {
+			return nil, fmt.Errorf("field %s is spilled but no payload store is configured", field)
+		}
+
+		content, err := OpenPayload(ctx, store, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read spilled field %s: %w", field, err)
+		}
+
+		if resolved == nil {
+			resolved = make(map[string]interface{}, len(data))
+			for k, v := range data {
+				resolved[k] = v
+			}
+		}
+		resolved[field] = content
+	}
+
+	if resolved == nil {
+		return data, nil
+	}
+	return resolved, nil
+}
+
+// OpenPayload decodes a spilled field from its stream
+func OpenPayload(ctx context.Context, store PayloadStore, ref PayloadRef) (interface{}, error) {
+	body, err := store.Open(ctx, ref.Key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var content interface{}
+	if err := json.NewDecoder(bufio.NewReader(body)).Decode(&content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// putJSON streams the JSON form of a value to the store, encoded element by
+// element so it is never held in memory as a whole
+func putJSON(ctx context.Context, store PayloadStore, key string, value interface{}) (int64, error) {
+	reader, writer := io.Pipe()
+	go func() {
+		buffered := bufio.NewWriter(writer)
+		err := encodeJSON(buffered, value)
+		if err == nil {
+			err = buffered.Flush()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	size, err := store.Put(ctx, key, reader)
+	// Unblock the encoder when the store stopped reading early
+	reader.CloseWithError(io.ErrClosedPipe)
+	return size, err
+}
+
+// encodeJSON writes the JSON form of a value, lists and objects one
+// element at a time
+func encodeJSON(w *bufio.Writer, value interface{}) error {
+	switch v := value.(type) {
+	case []interface{}:
+		w.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := encodeJSON(w, item); err != nil {
+				return err
+			}
+		}
+		return w.WriteByte(']')
+
+	case map[string]interface{}:
+		w.WriteByte('{')
+		for i, field := range sortedFields(v) {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			key, _ := json.Marshal(field)
+			w.Write(key)
+			w.WriteByte(':')
+			if err := encodeJSON(w, v[field]); err != nil {
+				return err
+			}
+		}
+		return w.WriteByte('}')
+
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(raw)
+		return err
+	}
+}
+
+// estimateJSONSize approximates the size of the JSON form of a value,
+// walking it only until the estimate passes limit
+func estimateJSONSize(value interface{}, limit int64) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 4
+	case string:
+		return int64(len(v)) + 2
+	case bool:
+		return 5
+	case float64, float32, int, int32, int64, uint, uint32, uint64:
+		return 8
+	case []byte:
+		// Base64 encoded
+		return int64(len(v))*4/3 + 2
+	case []interface{}:
+		size := int64(2)
+		for _, item := range v {
+			size += estimateJSONSize(item, limit-size) + 1
+			if size > limit {
+				break
+			}
+		}
+		return size
+	case map[string]interface{}:
+		size := int64(2)
+		for field, item := range v {
+			size += int64(len(field)) + 4 + estimateJSONSize(item, limit-size)
+			if size > limit {
+				break
+			}
+		}
+		return size
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return 0
+		}
+		return int64(len(raw))
+	}
+}
+
+func sortedFields(data map[string]interface{}) []string {
+	fields := make([]string, 0, len(data))
+	for field := range data {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
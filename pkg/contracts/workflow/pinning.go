@@ -0,0 +1,21 @@
+package workflow
+
+import (
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// ErrVersionUnavailable is returned when the stored snapshot of the version
+// an execution was requested with cannot be loaded: the execution does not
+// fall back to another definition
+var ErrVersionUnavailable = apperrors.New(apperrors.CategoryNotFound, "WORKFLOW_VERSION_UNAVAILABLE", "workflow version snapshot not found")
+
+// ExecutionBindings are what an execution was bound to when it started: the
+// credentials its environment swapped into the definition, by the
+// credential ID the definition names, and the variables it inherited.
+// Secret variables are left out, they stay sealed with the workers. The
+// execution keeps them for its whole run, edits of the workflow, its
+// variables or its environment only reach later executions.
+type ExecutionBindings struct {
+	Credentials map[string]string      `json:"credentials,omitempty"`
+	Variables   map[string]interface{} `json:"variables,omitempty"`
+}
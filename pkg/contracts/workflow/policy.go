@@ -0,0 +1,351 @@
+package workflow
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// Policy settings, the names used to lock them and to report deviations
+const (
+	PolicyTimeout     = "timeout"
+	PolicyRetryPolicy = "retryPolicy"
+	PolicyWorkerClass = "workerClass"
+	PolicyRetention   = "retention"
+)
+
+// PolicySettings lists the settings a workspace policy covers
+var PolicySettings = []string{
+	PolicyTimeout, PolicyRetryPolicy, PolicyWorkerClass, PolicyRetention,
+}
+
+var (
+	ErrPolicyNotFound  = apperrors.New(apperrors.CategoryNotFound, "POLICY_NOT_FOUND", "workspace has no policy")
+	ErrInvalidPolicy   = apperrors.New(apperrors.CategoryValidation, "INVALID_POLICY", "invalid workspace policy")
+	ErrPolicyLocked    = apperrors.New(apperrors.CategoryPermission, "POLICY_LOCKED", "workflow overrides a setting locked by the workspace policy")
+	ErrNodeTypeBlocked = apperrors.New(apperrors.CategoryPermission, "NODE_TYPE_BLOCKED", "workflow uses node types blocked by the workspace policy")
+	// ErrActivationBlocked lists, in the failures detail, the checks of the
+	// activation policy a version failed
+	ErrActivationBlocked = apperrors.New(apperrors.CategoryValidation, "ACTIVATION_BLOCKED", "workflow version does not pass the activation policy")
+	ErrOverrideDenied    = apperrors.New(apperrors.CategoryPermission, "ACTIVATION_OVERRIDE_DENIED", "only platform admins may override the activation policy")
+)
+
+// WorkspacePolicy holds the defaults new workflows of a workspace inherit.
+// Workflows may override a default unless an admin locked it, overrides are
+// reported as deviations.
+type WorkspacePolicy struct {
+	WorkspaceID string         `json:"workspaceId" gorm:"primaryKey"`
+	Defaults    PolicyDefaults `json:"defaults" gorm:"serializer:json"`
+	// Locked lists the settings workflows cannot override
+	Locked []string `json:"locked" gorm:"serializer:json"`
+	// NodeTypes restricts the node types workflows may use
+	NodeTypes NodeTypePolicy `json:"nodeTypes" gorm:"serializer:json"`
+	// Activation gates activations and deployments on validation and tests
+	Activation ActivationPolicy `json:"activation" gorm:"serializer:json"`
+	UpdatedBy  string           `json:"updatedBy"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	UpdatedAt  time.Time        `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (WorkspacePolicy) TableName() string {
+	return "workflow.workspace_policies"
+}
+
+// PolicyDefaults are the settings given to new workflows, zero fields keep
+// the built-in default and are not enforced
+type PolicyDefaults struct {
+	// Timeout is in seconds
+	Timeout     int          `json:"timeout,omitempty"`
+	RetryPolicy *PolicyRetry `json:"retryPolicy,omitempty"`
+	WorkerClass string       `json:"workerClass,omitempty"`
+	// RetentionDays is how long executions are kept
+	RetentionDays int `json:"retentionDays,omitempty"`
+}
+
+// NodeTypePolicy blocks node types, such as code or SSH nodes, for a
+// workspace. Unlike the defaults it cannot be overridden, it is enforced when
+// a workflow is saved, imported, activated and executed.
+type NodeTypePolicy struct {
+	// Allow lists the only node types workflows may use, empty allows all
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists node types workflows may not use, it wins over Allow
+	Deny []string `json:"deny,omitempty"`
+}
+
+// ActivationPolicy keeps workflow versions that fail validation or their
+// tests from being activated, or deployed to the protected environments.
+// Admins of a workflow may override it.
+type ActivationPolicy struct {
+	// RequireValid refuses versions whose validation reports errors
+	RequireValid bool `json:"requireValid,omitempty"`
+	// RequireTests refuses versions whose latest finished test run did not
+	// pass, or that have none
+	RequireTests bool `json:"requireTests,omitempty"`
+	// Environments names the environments, such as prod, deployments to
+	// which are gated like activations
+	Environments []string `json:"environments,omitempty"`
+}
+
+// Enabled reports whether the policy checks anything
+func (a *ActivationPolicy) Enabled() bool {
+	return a.RequireValid || a.RequireTests
+}
+
+// Gates reports whether deployments to the environment named env are
+// checked
+func (a *ActivationPolicy) Gates(env string) bool {
+	if !a.Enabled() {
+		return false
+	}
+	for _, name := range a.Environments {
+		if strings.EqualFold(name, env) {
+			return true
+		}
+	}
+	return false
+}
+
+// BlockedNode is a node whose type the workspace policy blocks
+type BlockedNode struct {
+	NodeID string `json:"nodeId"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+}
+
+// PolicyRetry is how failed executions of a workflow are retried
+type PolicyRetry struct {
+	RetryOnFailure bool `json:"retryOnFailure"`
+	MaxRetries     int  `json:"maxRetries"`
+	// RetryInterval is in seconds
+	RetryInterval int `json:"retryInterval"`
+}
+
+// UpdatePolicyRequest replaces the policy of a workspace
+type UpdatePolicyRequest struct {
+	Defaults   PolicyDefaults   `json:"defaults"`
+	Locked     []string         `json:"locked"`
+	NodeTypes  NodeTypePolicy   `json:"nodeTypes"`
+	Activation ActivationPolicy `json:"activation"`
+}
+
+// PolicyDeviation is a setting of a workflow that differs from the default
+// of its workspace
+type PolicyDeviation struct {
+	Setting string      `json:"setting"`
+	Default interface{} `json:"default"`
+	Value   interface{} `json:"value"`
+	Locked  bool        `json:"locked"`
+}
+
+// ComplianceReport lists the workflows of a workspace deviating from its
+// policy. Violations are deviations from locked settings and blocked nodes,
+// left by workflows saved before the policy changed.
+type ComplianceReport struct {
+	WorkspaceID string               `json:"workspaceId"`
+	Policy      *WorkspacePolicy     `json:"policy"`
+	Workflows   int                  `json:"workflows"`
+	Compliant   int                  `json:"compliant"`
+	Violations  int                  `json:"violations"`
+	Deviating   []WorkflowCompliance `json:"deviating"`
+	GeneratedAt time.Time            `json:"generatedAt"`
+}
+
+// WorkflowCompliance lists the deviations of one workflow
+type WorkflowCompliance struct {
+	WorkflowID string            `json:"workflowId"`
+	Name       string            `json:"name"`
+	Deviations []PolicyDeviation `json:"deviations"`
+	// BlockedNodes are the nodes keeping the workflow from being activated
+	// and executed
+	BlockedNodes []BlockedNode `json:"blockedNodes,omitempty"`
+	Violation    bool          `json:"violation"`
+}
+
+// Validate checks the defaults and that only set defaults are locked
+func (p *WorkspacePolicy) Validate() error {
+	d := p.Defaults
+	if d.Timeout < 0 || d.RetentionDays < 0 {
+		return ErrInvalidPolicy.WithMessage("timeout and retentionDays must not be negative")
+	}
+	if r := d.RetryPolicy; r != nil && (r.MaxRetries < 0 || r.RetryInterval < 0) {
+		return ErrInvalidPolicy.WithMessage("maxRetries and retryInterval must not be negative")
+	}
+
+	for _, types := range [][]string{p.NodeTypes.Allow, p.NodeTypes.Deny} {
+		for _, t := range types {
+			if strings.TrimSpace(t) == "" {
+				return ErrInvalidPolicy.WithMessage("node types must not be empty")
+			}
+		}
+	}
+
+	for _, env := range p.Activation.Environments {
+		if strings.TrimSpace(env) == "" {
+			return ErrInvalidPolicy.WithMessage("gated environments must not be empty")
+		}
+	}
+
+	for _, setting := range p.Locked {
+		set, ok := p.hasDefault(setting)
+		if !ok {
+			return ErrInvalidPolicy.WithMessage("unknown setting %q, expected one of %v", setting, PolicySettings)
+		}
+		if !set {
+			return ErrInvalidPolicy.WithMessage("setting %q has no default to lock", setting)
+		}
+	}
+	return nil
+}
+
+// hasDefault reports whether the policy sets setting, and whether setting
+// is known
+func (p *WorkspacePolicy) hasDefault(setting string) (set, known bool) {
+	d := p.Defaults
+	switch setting {
+	case PolicyTimeout:
+		return d.Timeout > 0, true
+	case PolicyRetryPolicy:
+		return d.RetryPolicy != nil, true
+	case PolicyWorkerClass:
+		return d.WorkerClass != "", true
+	case PolicyRetention:
+		return d.RetentionDays > 0, true
+	}
+	return false, false
+}
+
+// IsLocked reports whether workflows cannot override setting
+func (p *WorkspacePolicy) IsLocked(setting string) bool {
+	for _, locked := range p.Locked {
+		if locked == setting {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply sets the defaults of the policy on the settings of a new workflow
+func (p *WorkspacePolicy) Apply(s *Settings) {
+	d := p.Defaults
+	if d.Timeout > 0 {
+		s.Timeout = d.Timeout
+	}
+	if r := d.RetryPolicy; r != nil {
+		s.RetryOnFailure = r.RetryOnFailure
+		s.MaxRetries = r.MaxRetries
+		s.ErrorHandling.RetryInterval = r.RetryInterval
+	}
+	if d.WorkerClass != "" {
+		s.WorkerClass = d.WorkerClass
+	}
+	if d.RetentionDays > 0 {
+		s.RetentionDays = d.RetentionDays
+	}
+}
+
+// Deviations lists the settings of w that differ from the defaults
+func (p *WorkspacePolicy) Deviations(w *Workflow) []PolicyDeviation {
+	d := p.Defaults
+	s := w.Settings
+
+	var deviations []PolicyDeviation
+	add := func(setting string, def, value interface{}) {
+		deviations = append(deviations, PolicyDeviation{
+			Setting: setting,
+			Default: def,
+			Value:   value,
+			Locked:  p.IsLocked(setting),
+		})
+	}
+
+	if d.Timeout > 0 && s.Timeout != d.Timeout {
+		add(PolicyTimeout, d.Timeout, s.Timeout)
+	}
+	if d.RetryPolicy != nil {
+		value := PolicyRetry{
+			RetryOnFailure: s.RetryOnFailure,
+			MaxRetries:     s.MaxRetries,
+			RetryInterval:  s.ErrorHandling.RetryInterval,
+		}
+		if !reflect.DeepEqual(value, *d.RetryPolicy) {
+			add(PolicyRetryPolicy, *d.RetryPolicy, value)
+		}
+	}
+	if d.WorkerClass != "" && s.WorkerClass != d.WorkerClass {
+		add(PolicyWorkerClass, d.WorkerClass, s.WorkerClass)
+	}
+	if d.RetentionDays > 0 && s.RetentionDays != d.RetentionDays {
+		add(PolicyRetention, d.RetentionDays, s.RetentionDays)
+	}
+	return deviations
+}
+
+// CheckLocked fails when w deviates from a locked setting that previous,
+// the workflow before the change, did not deviate from. Nil previous checks
+// a new workflow.
+func (p *WorkspacePolicy) CheckLocked(w, previous *Workflow) error {
+	existing := make(map[string]bool)
+	if previous != nil {
+		for _, dev := range p.Deviations(previous) {
+			existing[dev.Setting] = true
+		}
+	}
+
+	for _, dev := range p.Deviations(w) {
+		if dev.Locked && !existing[dev.Setting] {
+			return ErrPolicyLocked.
+				WithMessage("setting %q is locked by the workspace policy to %v, got %v", dev.Setting, dev.Default, dev.Value).
+				WithDetail("setting", dev.Setting)
+		}
+	}
+	return nil
+}
+
+// Blocks reports whether workflows may not use nodeType
+func (n *NodeTypePolicy) Blocks(nodeType string) bool {
+	for _, t := range n.Deny {
+		if t == nodeType {
+			return true
+		}
+	}
+	if len(n.Allow) == 0 {
+		return false
+	}
+	for _, t := range n.Allow {
+		if t == nodeType {
+			return false
+		}
+	}
+	return true
+}
+
+// BlockedNodes returns the nodes of w whose type the policy blocks
+func (p *WorkspacePolicy) BlockedNodes(w *Workflow) []BlockedNode {
+	var blocked []BlockedNode
+	for _, node := range w.Nodes {
+		if p.NodeTypes.Blocks(node.Type) {
+			blocked = append(blocked, BlockedNode{NodeID: node.ID, Name: node.Name, Type: node.Type})
+		}
+	}
+	return blocked
+}
+
+// CheckNodeTypes fails listing the nodes of w whose type the policy blocks
+func (p *WorkspacePolicy) CheckNodeTypes(w *Workflow) error {
+	blocked := p.BlockedNodes(w)
+	if len(blocked) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(blocked))
+	for _, node := range blocked {
+		names = append(names, fmt.Sprintf("%s (%s)", node.Name, node.Type))
+	}
+	return ErrNodeTypeBlocked.
+		WithMessage("node types blocked by the workspace policy: %s", strings.Join(names, ", ")).
+		WithDetail("blockedNodes", blocked)
+}
@@ -0,0 +1,156 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// RedactedValue replaces the value of a sensitive field wherever execution
+// data is persisted, logged or streamed
+const RedactedValue = "[REDACTED]"
+
+var (
+	ErrInvalidSensitiveField = apperrors.New(apperrors.CategoryValidation, "INVALID_SENSITIVE_FIELD", "invalid sensitive field")
+)
+
+// SensitivePaths returns the sensitive fields of the output of a node
+func (w *Workflow) SensitivePaths(nodeID string) []string {
+	return w.Settings.SensitiveFields[nodeID]
+}
+
+// RedactNodeOutput returns the output of a node with its sensitive fields
+// redacted. The output itself is left untouched, downstream nodes still
+// read the values.
+func (w *Workflow) RedactNodeOutput(nodeID string, output map[string]interface{}) map[string]interface{} {
+	return RedactFields(output, w.SensitivePaths(nodeID))
+}
+
+// RedactNodeOutputs returns node outputs keyed by node ID, each with its
+// sensitive fields redacted
+func (w *Workflow) RedactNodeOutputs(outputs map[string]interface{}) map[string]interface{} {
+	return RedactOutputs(outputs, w.Settings.SensitiveFields)
+}
+
+// RedactData returns the data of an execution with the sensitive fields of
+// every node redacted. Node outputs are merged into the execution data, so
+// a field sensitive in one output is redacted wherever it lands.
+func (w *Workflow) RedactData(data map[string]interface{}) map[string]interface{} {
+	if len(w.Settings.SensitiveFields) == 0 {
+		return data
+	}
+	var paths []string
+	for _, fields := range w.Settings.SensitiveFields {
+		paths = append(paths, fields...)
+	}
+	return RedactFields(data, paths)
+}
+
+// ValidateSensitiveFields checks that sensitive fields name existing nodes
+// and non-empty paths
+func (w *Workflow) ValidateSensitiveFields() error {
+	if len(w.Settings.SensitiveFields) == 0 {
+		return nil
+	}
+
+	nodes := make(map[string]bool, len(w.Nodes))
+	for _, node := range w.Nodes {
+		nodes[node.ID] = true
+	}
+
+	for nodeID, paths := range w.Settings.SensitiveFields {
+		if !nodes[nodeID] {
+			return fmt.Errorf("%w: node %s not found", ErrInvalidSensitiveField, nodeID)
+		}
+		for _, path := range paths {
+			for _, segment := range strings.Split(path, ".") {
+				if segment == "" {
+					return fmt.Errorf("%w: node %s has malformed path %q", ErrInvalidSensitiveField, nodeID, path)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// RedactFields returns a copy of data with the fields at paths replaced by
+// RedactedValue. A path is dotted, "customer.email"; a "*" segment matches
+// every key of an object or every item of a list. Only the objects and
+// lists along the paths are copied.
+func RedactFields(data map[string]interface{}, paths []string) map[string]interface{} {
+	if len(paths) == 0 || data == nil {
+		return data
+	}
+	var redacted interface{} = data
+	for _, path := range paths {
+		redacted = redactPath(redacted, strings.Split(path, "."))
+	}
+	return redacted.(map[string]interface{})
+}
+
+// redactPath redacts the fields at segments of value, copying what it
+// changes
+func redactPath(value interface{}, segments []string) interface{} {
+	segment, rest := segments[0], segments[1:]
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		var copied map[string]interface{}
+		for key, item := range v {
+			if segment != "*" && segment != key {
+				continue
+			}
+			if copied == nil {
+				copied = make(map[string]interface{}, len(v))
+				for k, val := range v {
+					copied[k] = val
+				}
+			}
+			if len(rest) == 0 {
+				copied[key] = RedactedValue
+			} else {
+				copied[key] = redactPath(item, rest)
+			}
+		}
+		if copied == nil {
+			return v
+		}
+		return copied
+
+	case []interface{}:
+		if segment != "*" {
+			return v
+		}
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			if len(rest) == 0 {
+				copied[i] = RedactedValue
+			} else {
+				copied[i] = redactPath(item, rest)
+			}
+		}
+		return copied
+
+	default:
+		return value
+	}
+}
+
+// RedactOutputs returns node outputs keyed by node ID with the fields
+// sensitive for each node redacted
+func RedactOutputs(outputs map[string]interface{}, sensitiveFields map[string][]string) map[string]interface{} {
+	if len(sensitiveFields) == 0 {
+		return outputs
+	}
+	redacted := make(map[string]interface{}, len(outputs))
+	for nodeID, output := range outputs {
+		if fields, ok := output.(map[string]interface{}); ok {
+			redacted[nodeID] = RedactFields(fields, sensitiveFields[nodeID])
+			continue
+		}
+		redacted[nodeID] = output
+	}
+	return redacted
+}
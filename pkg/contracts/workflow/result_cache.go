@@ -0,0 +1,64 @@
+package workflow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// DefaultResultCacheTTL is how long the output of an idempotent workflow is
+// cached when its settings name no TTL
+const DefaultResultCacheTTL = time.Hour
+
+const resultCacheKeyPrefix = "execution:result"
+
+var ErrInvalidResultCache = apperrors.New(apperrors.CategoryValidation, "INVALID_RESULT_CACHE", "invalid result cache settings")
+
+// CachedResult is the output of a completed run of an idempotent workflow,
+// returned for later runs of the same input
+type CachedResult struct {
+	ExecutionID string                 `json:"executionId"`
+	Output      map[string]interface{} `json:"output"`
+	CompletedAt time.Time              `json:"completedAt"`
+}
+
+// ResultCacheTTL returns how long the output of the workflow is cached, zero
+// when the workflow is not idempotent
+func (w *Workflow) ResultCacheTTL() time.Duration {
+	if !w.Settings.Idempotent {
+		return 0
+	}
+	if w.Settings.ResultCacheTTL > 0 {
+		return time.Duration(w.Settings.ResultCacheTTL) * time.Second
+	}
+	return DefaultResultCacheTTL
+}
+
+// InputHash identifies the input of a run. Top level fields starting with
+// an underscore, such as the _webhook metadata of a delivery, differ
+// between deliveries of the same payload and are left out.
+func InputHash(input map[string]interface{}) (string, error) {
+	payload := make(map[string]interface{}, len(input))
+	for k, v := range input {
+		if !strings.HasPrefix(k, "_") {
+			payload[k] = v
+		}
+	}
+	// Map keys are marshalled sorted, equal inputs give equal bytes
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ResultCacheKey is the Redis key of the cached output of a workflow for an
+// input hash
+func ResultCacheKey(workflowID, inputHash string) string {
+	return resultCacheKeyPrefix + ":" + workflowID + ":" + inputHash
+}
@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"hash/fnv"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// SamplingDayFormat is the form of the UTC days the first and last
+// successful execution of are kept
+const SamplingDayFormat = "2006-01-02"
+
+var ErrInvalidSampling = apperrors.New(apperrors.CategoryValidation, "INVALID_SAMPLING", "invalid sampling policy")
+
+// SamplingPolicy bounds the executions of a high volume workflow that are
+// stored. Failed, cancelled and timed out executions are always stored, only
+// a share of the successful ones is.
+type SamplingPolicy struct {
+	// SuccessRate is the percent of successful executions stored
+	SuccessRate float64 `json:"successRate"`
+	// KeepFirstOfDay stores the first successful execution of each UTC day
+	KeepFirstOfDay bool `json:"keepFirstOfDay,omitempty"`
+	// KeepLastOfDay stores the last successful execution of each UTC day
+	KeepLastOfDay bool `json:"keepLastOfDay,omitempty"`
+}
+
+// Sampling returns the sampling policy of the workflow, nil when every
+// execution is stored
+func (w *Workflow) Sampling() *SamplingPolicy {
+	p := w.Settings.Sampling
+	if p == nil || p.SuccessRate >= 100 {
+		return nil
+	}
+	return p
+}
+
+// Validate checks the rate of the policy
+func (p *SamplingPolicy) Validate() error {
+	if p.SuccessRate < 0 || p.SuccessRate > 100 {
+		return ErrInvalidSampling.WithMessage("successRate must be between 0 and 100, got %g", p.SuccessRate)
+	}
+	return nil
+}
+
+// Sampled reports whether a successful execution is within the stored
+// share. The decision is a hash of its ID, a redelivered execution gets the
+// same one.
+func (p *SamplingPolicy) Sampled(executionID string) bool {
+	h := fnv.New32a()
+	h.Write([]byte(executionID))
+	return float64(h.Sum32()%10000) < p.SuccessRate*100
+}
+
+// SamplingDay is the day an execution finished at t is sampled in
+func SamplingDay(t time.Time) string {
+	return t.UTC().Format(SamplingDayFormat)
+}
+
+// SamplingKey are the parts of the Redis key, within the namespace of the
+// tenant, holding the first or last successful execution of a workflow in a
+// day
+func SamplingKey(workflowID, day, which string) []string {
+	return []string{"sampling", which, workflowID, day}
+}
@@ -0,0 +1,117 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var (
+	ErrSecretsUnavailable = apperrors.New(apperrors.CategoryInternal, "SECRETS_UNAVAILABLE", "secret variables require the credential encryption key")
+)
+
+// secretReferencePattern matches {{ $secrets.KEY }} in node parameters, the
+// reference to a workflow variable of type secret
+var secretReferencePattern = regexp.MustCompile(`\{\{\s*\$secrets\.([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+
+// Masked returns a copy of the variable safe for read APIs, the value of a
+// secret replaced with MaskedValue
+func (v *WorkflowVariable) Masked() *WorkflowVariable {
+	masked := *v
+	if v.Encrypted {
+		masked.Value = MaskedValue
+	}
+	return &masked
+}
+
+// SecretPlaintext is the text a secret variable seals: strings as they are,
+// other values as JSON
+func SecretPlaintext(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode secret value: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// SecretReferences lists the secret variables node parameters reference
+func SecretReferences(parameters map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var keys []string
+	walkStrings(parameters, func(s string) {
+		for _, match := range secretReferencePattern.FindAllStringSubmatch(s, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				keys = append(keys, match[1])
+			}
+		}
+	})
+	return keys
+}
+
+// ResolveSecretReferences returns a copy of node parameters with their
+// secret references replaced by the values open returns for them
+func ResolveSecretReferences(parameters map[string]interface{}, open func(key string) (string, error)) (map[string]interface{}, error) {
+	var resolveErr error
+	resolved := mapStrings(parameters, func(s string) string {
+		return secretReferencePattern.ReplaceAllStringFunc(s, func(match string) string {
+			if resolveErr != nil {
+				return match
+			}
+			key := secretReferencePattern.FindStringSubmatch(match)[1]
+			value, err := open(key)
+			if err != nil {
+				resolveErr = fmt.Errorf("secret %s: %w", key, err)
+				return match
+			}
+			return value
+		})
+	})
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+	return resolved.(map[string]interface{}), nil
+}
+
+// walkStrings calls fn with every string in a parameter value
+func walkStrings(value interface{}, fn func(string)) {
+	switch v := value.(type) {
+	case string:
+		fn(v)
+	case map[string]interface{}:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	}
+}
+
+// mapStrings copies a parameter value with every string replaced by fn
+func mapStrings(value interface{}, fn func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = mapStrings(item, fn)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = mapStrings(item, fn)
+		}
+		return result
+	default:
+		return value
+	}
+}
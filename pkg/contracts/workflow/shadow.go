@@ -0,0 +1,245 @@
+package workflow
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// Shadow statuses
+const (
+	ShadowRunning = "running"
+	ShadowStopped = "stopped"
+)
+
+// Shadow divergence kinds
+const (
+	DivergenceOutputChanged   = "output_changed"
+	DivergenceMissingInShadow = "missing_in_shadow"
+	DivergenceMissingInLive   = "missing_in_live"
+	DivergenceStatusChanged   = "status_changed"
+)
+
+// ShadowReportSampleSize is how many of the latest comparisons a shadow
+// report summarizes
+const ShadowReportSampleSize = 1000
+
+var (
+	ErrShadowNotFound      = apperrors.New(apperrors.CategoryNotFound, "SHADOW_NOT_FOUND", "no shadow run exists for this workflow")
+	ErrShadowAlreadyActive = apperrors.New(apperrors.CategoryConflict, "SHADOW_ALREADY_RUNNING", "a shadow run is already active for this workflow")
+	ErrInvalidShadow       = apperrors.New(apperrors.CategoryValidation, "INVALID_SHADOW", "invalid shadow configuration")
+)
+
+// Shadow runs a stored version of a workflow next to the live one on the
+// same trigger input. Nodes with side effects are not run by the shadow:
+// their live output is replayed, or a mock output is used when the live run
+// has no such node. Outputs of both runs are diffed per node.
+type Shadow struct {
+	ID            string     `json:"id" gorm:"primaryKey"`
+	WorkflowID    string     `json:"workflowId" gorm:"not null;index"`
+	ShadowVersion int        `json:"shadowVersion"`
+	Percentage    int        `json:"percentage"`
+	Status        string     `json:"status" gorm:"default:'running'"`
+	CreatedBy     string     `json:"createdBy"`
+	StartedAt     time.Time  `json:"startedAt"`
+	StoppedAt     *time.Time `json:"stoppedAt,omitempty"`
+}
+
+// TableName specifies the table name for GORM
+func (Shadow) TableName() string {
+	return "workflow.workflow_shadows"
+}
+
+// ShadowComparison is the diff of one live execution and its shadow run
+type ShadowComparison struct {
+	ID              string             `json:"id" gorm:"primaryKey"`
+	ShadowID        string             `json:"shadowId" gorm:"not null;index"`
+	WorkflowID      string             `json:"workflowId" gorm:"not null;index"`
+	LiveExecutionID string             `json:"liveExecutionId"`
+	LiveVersion     int                `json:"liveVersion"`
+	ShadowVersion   int                `json:"shadowVersion"`
+	LiveStatus      string             `json:"liveStatus"`
+	ShadowStatus    string             `json:"shadowStatus"`
+	ShadowError     string             `json:"shadowError,omitempty"`
+	Diverged        bool               `json:"diverged"`
+	Divergences     []ShadowDivergence `json:"divergences" gorm:"serializer:json"`
+	ReplayedNodes   []string           `json:"replayedNodes" gorm:"serializer:json"`
+	MockedNodes     []string           `json:"mockedNodes" gorm:"serializer:json"`
+	LiveDuration    int64              `json:"liveDurationMs"`
+	ShadowDuration  int64              `json:"shadowDurationMs"`
+	CreatedAt       time.Time          `json:"createdAt"`
+}
+
+// TableName specifies the table name for GORM
+func (ShadowComparison) TableName() string {
+	return "workflow.workflow_shadow_comparisons"
+}
+
+// ShadowDivergence is a difference between the live and shadow run
+type ShadowDivergence struct {
+	NodeID string      `json:"nodeId,omitempty"`
+	Kind   string      `json:"kind"`
+	Live   interface{} `json:"live,omitempty"`
+	Shadow interface{} `json:"shadow,omitempty"`
+}
+
+// ShadowNodeDivergence counts the comparisons in which a node diverged
+type ShadowNodeDivergence struct {
+	NodeID string `json:"nodeId"`
+	Count  int    `json:"count"`
+}
+
+// ShadowReport summarizes the latest comparisons of a shadow
+type ShadowReport struct {
+	Shadow         *Shadow                `json:"shadow"`
+	Compared       int                    `json:"compared"`
+	Matched        int                    `json:"matched"`
+	Diverged       int                    `json:"diverged"`
+	ShadowFailures int                    `json:"shadowFailures"`
+	DivergenceRate float64                `json:"divergenceRate"`
+	Nodes          []ShadowNodeDivergence `json:"nodes"`
+	Recent         []*ShadowComparison    `json:"recent"`
+}
+
+// StartShadowRequest starts shadowing a stored version of a workflow
+type StartShadowRequest struct {
+	Version int `json:"version" binding:"required,min=1"`
+	// Percentage of live executions shadowed, defaults to all of them
+	Percentage int `json:"percentage" binding:"omitempty,min=1,max=100"`
+}
+
+// IsRunning reports whether live executions are still shadowed
+func (s *Shadow) IsRunning() bool {
+	return s.Status == ShadowRunning
+}
+
+// Samples reports whether the live execution with the given ID is shadowed.
+// Sampling hashes the ID so the decision is stable for an execution.
+func (s *Shadow) Samples(executionID string) bool {
+	if s.Percentage <= 0 || s.Percentage >= 100 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(executionID))
+	return int(h.Sum32()%100) < s.Percentage
+}
+
+// Stop ends the shadow
+func (s *Shadow) Stop() {
+	now := time.Now()
+	s.Status = ShadowStopped
+	s.StoppedAt = &now
+}
+
+// IsSideEffectFree reports whether a node of the given type only computes
+// its output from its input, so a shadow run may execute it. Other nodes
+// reach external systems and are replayed or mocked instead.
+func IsSideEffectFree(nodeType string) bool {
+	switch nodeType {
+	case NodeTypeTrigger, NodeTypeWebhook, NodeTypeCondition, NodeTypeLoop,
+		NodeTypeMerge, NodeTypeSplit, NodeTypeCode, NodeTypeValidate, NodeTypeTransform:
+		return true
+	default:
+		return false
+	}
+}
+
+// DiffNodeOutputs compares the node outputs of a live and a shadow run.
+// Outputs are compared by their JSON form so numbers decoded differently
+// do not count as divergences.
+func DiffNodeOutputs(live, shadow map[string]interface{}) []ShadowDivergence {
+	nodeIDs := make([]string, 0, len(live)+len(shadow))
+	for nodeID := range live {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	for nodeID := range shadow {
+		if _, ok := live[nodeID]; !ok {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+	}
+	sort.Strings(nodeIDs)
+
+	divergences := []ShadowDivergence{}
+	for _, nodeID := range nodeIDs {
+		liveOutput, inLive := live[nodeID]
+		shadowOutput, inShadow := shadow[nodeID]
+
+		switch {
+		case !inShadow:
+			divergences = append(divergences, ShadowDivergence{NodeID: nodeID, Kind: DivergenceMissingInShadow, Live: liveOutput})
+		case !inLive:
+			divergences = append(divergences, ShadowDivergence{NodeID: nodeID, Kind: DivergenceMissingInLive, Shadow: shadowOutput})
+		case !sameJSON(liveOutput, shadowOutput):
+			divergences = append(divergences, ShadowDivergence{NodeID: nodeID, Kind: DivergenceOutputChanged, Live: liveOutput, Shadow: shadowOutput})
+		}
+	}
+	return divergences
+}
+
+// BuildShadowReport summarizes comparisons of a shadow, newest first
+func BuildShadowReport(shadow *Shadow, comparisons []*ShadowComparison, recent int) *ShadowReport {
+	report := &ShadowReport{
+		Shadow:   shadow,
+		Compared: len(comparisons),
+		Nodes:    []ShadowNodeDivergence{},
+		Recent:   []*ShadowComparison{},
+	}
+
+	counts := make(map[string]int)
+	for _, comparison := range comparisons {
+		if comparison.ShadowError != "" {
+			report.ShadowFailures++
+		}
+		if !comparison.Diverged {
+			report.Matched++
+			continue
+		}
+
+		report.Diverged++
+		if len(report.Recent) < recent {
+			report.Recent = append(report.Recent, comparison)
+		}
+
+		seen := make(map[string]bool)
+		for _, divergence := range comparison.Divergences {
+			if divergence.NodeID == "" || seen[divergence.NodeID] {
+				continue
+			}
+			seen[divergence.NodeID] = true
+			counts[divergence.NodeID]++
+		}
+	}
+
+	for nodeID, count := range counts {
+		report.Nodes = append(report.Nodes, ShadowNodeDivergence{NodeID: nodeID, Count: count})
+	}
+	sort.Slice(report.Nodes, func(i, j int) bool {
+		if report.Nodes[i].Count != report.Nodes[j].Count {
+			return report.Nodes[i].Count > report.Nodes[j].Count
+		}
+		return report.Nodes[i].NodeID < report.Nodes[j].NodeID
+	})
+
+	if report.Compared > 0 {
+		report.DivergenceRate = float64(report.Diverged) / float64(report.Compared)
+	}
+	return report
+}
+
+func sameJSON(a, b interface{}) bool {
+	left, errLeft := json.Marshal(a)
+	right, errRight := json.Marshal(b)
+	if errLeft != nil || errRight != nil {
+		return reflect.DeepEqual(a, b)
+	}
+
+	var leftValue, rightValue interface{}
+	if json.Unmarshal(left, &leftValue) != nil || json.Unmarshal(right, &rightValue) != nil {
+		return string(left) == string(right)
+	}
+	return reflect.DeepEqual(leftValue, rightValue)
+}
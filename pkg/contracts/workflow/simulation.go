@@ -0,0 +1,118 @@
+package workflow
+
+import (
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var (
+	ErrInvalidSimulation = apperrors.New(apperrors.CategoryValidation, "INVALID_SIMULATION", "invalid simulation")
+	ErrNodeNotMocked     = apperrors.New(apperrors.CategoryValidation, "NODE_NOT_MOCKED", "node with side effects has no mock")
+)
+
+// NodeMock is the response a node gives in a simulation instead of running.
+// It replaces one node by ID or every node of a type, a mock by ID taking
+// precedence. A mock with an error fails the node with it, to exercise the
+// error handling of the workflow.
+type NodeMock struct {
+	NodeID   string                 `json:"nodeId,omitempty"`
+	NodeType string                 `json:"nodeType,omitempty"`
+	Output   map[string]interface{} `json:"output,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// SimulationRequest runs a workflow on an input with its nodes replaced by
+// mocks. Nodes without side effects run for real unless mocked, the others
+// never reach external systems.
+type SimulationRequest struct {
+	WorkflowID string `json:"workflowId" binding:"required"`
+	// Version simulates a stored version, the current definition when 0
+	Version int                    `json:"version,omitempty"`
+	Data    map[string]interface{} `json:"data"`
+	Mocks   []NodeMock             `json:"mocks"`
+	// Strict fails nodes with side effects no mock covers instead of giving
+	// them a placeholder output
+	Strict bool `json:"strict"`
+}
+
+// Validate checks each mock names a node or a node type, and is not
+// declared twice
+func (r *SimulationRequest) Validate() error {
+	if r.Version < 0 {
+		return ErrInvalidSimulation.WithMessage("version must not be negative")
+	}
+	seen := make(map[string]bool, len(r.Mocks))
+	for i, mock := range r.Mocks {
+		var key string
+		switch {
+		case mock.NodeID != "" && mock.NodeType != "":
+			return ErrInvalidSimulation.WithMessage("mock %d names both a node and a node type", i)
+		case mock.NodeID != "":
+			key = "node:" + mock.NodeID
+		case mock.NodeType != "":
+			key = "type:" + mock.NodeType
+		default:
+			return ErrInvalidSimulation.WithMessage("mock %d names neither a node nor a node type", i)
+		}
+		if seen[key] {
+			return ErrInvalidSimulation.WithMessage("mock %d is declared twice", i)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// MockFor returns the mock replacing a node, nil when none does
+func (r *SimulationRequest) MockFor(node *Node) *NodeMock {
+	var byType *NodeMock
+	for i := range r.Mocks {
+		mock := &r.Mocks[i]
+		if mock.NodeID == node.ID {
+			return mock
+		}
+		if mock.NodeID == "" && mock.NodeType == node.Type && byType == nil {
+			byType = mock
+		}
+	}
+	return byType
+}
+
+// SimulatedNode is the outcome of a node in a simulation
+type SimulatedNode struct {
+	NodeID   string `json:"nodeId"`
+	NodeType string `json:"nodeType"`
+	Status   string `json:"status"`
+	// Mocked is set when the node answered with a mock, or a placeholder
+	// when unmocked
+	Mocked   bool                   `json:"mocked"`
+	Output   map[string]interface{} `json:"output,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+	Duration int64                  `json:"duration"` // in milliseconds
+}
+
+// SimulationResult reports a simulation node by node, in the order the
+// nodes finished. Nothing of it is stored.
+type SimulationResult struct {
+	WorkflowID string          `json:"workflowId"`
+	Version    int             `json:"version"`
+	Status     string          `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	Nodes      []SimulatedNode `json:"nodes"`
+	// Skipped are the nodes the run never reached, such as the branches
+	// not taken
+	Skipped []string `json:"skipped"`
+	// Unmocked are the nodes with side effects no mock covered, given a
+	// placeholder output
+	Unmocked []string               `json:"unmocked"`
+	Output   map[string]interface{} `json:"output,omitempty"`
+	Duration int64                  `json:"duration"` // in milliseconds
+}
+
+// Node returns the outcome of a node, nil when it did not run
+func (r *SimulationResult) Node(nodeID string) *SimulatedNode {
+	for i := range r.Nodes {
+		if r.Nodes[i].NodeID == nodeID {
+			return &r.Nodes[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package workflow
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// SLA objectives
+const (
+	// SLAObjectiveDuration bounds how long an execution runs
+	SLAObjectiveDuration = "duration"
+	// SLAObjectiveFailureRate bounds the share of the executions finished
+	// over a window that failed
+	SLAObjectiveFailureRate = "failure_rate"
+	// SLAObjectiveSchedule bounds the time between two executions starting
+	SLAObjectiveSchedule = "schedule"
+)
+
+const (
+	// DefaultSLAWindow is the failure rate window of SLAs naming none
+	DefaultSLAWindow = 60
+	// MaxSLAWindow bounds the failure rate window, in minutes
+	MaxSLAWindow = 24 * 60
+)
+
+var (
+	ErrSLANotFound = apperrors.New(apperrors.CategoryNotFound, "SLA_NOT_FOUND", "SLA not found")
+	ErrInvalidSLA  = apperrors.New(apperrors.CategoryValidation, "INVALID_SLA", "invalid SLA")
+)
+
+// SLA are the objectives the executions of a workflow are held to. Each
+// objective left at zero is not monitored.
+type SLA struct {
+	ID         string `json:"id" gorm:"primaryKey"`
+	TenantID   string `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
+	WorkflowID string `json:"workflowId" gorm:"not null;uniqueIndex"`
+	// MaxDurationMs bounds the duration of each execution, running ones
+	// included
+	MaxDurationMs int64 `json:"maxDurationMs"`
+	// MaxFailureRate is the percent of the executions finished over the
+	// window that may fail, once MinExecutions finished
+	MaxFailureRate float64 `json:"maxFailureRate"`
+	WindowMinutes  int     `json:"windowMinutes"`
+	MinExecutions  int     `json:"minExecutions"`
+	// ExpectedIntervalSeconds is the longest time between two executions
+	// starting, from the schedule of the workflow plus some slack
+	ExpectedIntervalSeconds int64     `json:"expectedIntervalSeconds"`
+	UpdatedBy               string    `json:"updatedBy"`
+	CreatedAt               time.Time `json:"createdAt"`
+	UpdatedAt               time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (SLA) TableName() string {
+	return "workflow.slas"
+}
+
+// SetSLARequest sets the SLA of a workflow
+type SetSLARequest struct {
+	MaxDurationMs           int64   `json:"maxDurationMs"`
+	MaxFailureRate          float64 `json:"maxFailureRate"`
+	WindowMinutes           int     `json:"windowMinutes"`
+	MinExecutions           int     `json:"minExecutions"`
+	ExpectedIntervalSeconds int64   `json:"expectedIntervalSeconds"`
+}
+
+// NewSLA creates the SLA of a workflow
+func NewSLA(workflowID string, req SetSLARequest, updatedBy string) (*SLA, error) {
+	sla := &SLA{
+		ID:         uuid.New().String(),
+		WorkflowID: workflowID,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := sla.Apply(req, updatedBy); err != nil {
+		return nil, err
+	}
+	return sla, nil
+}
+
+// Apply sets the objectives of a request on the SLA
+func (s *SLA) Apply(req SetSLARequest, updatedBy string) error {
+	switch {
+	case req.MaxDurationMs < 0 || req.ExpectedIntervalSeconds < 0 || req.MinExecutions < 0:
+		return ErrInvalidSLA.WithMessage("objectives cannot be negative")
+	case req.MaxFailureRate < 0 || req.MaxFailureRate > 100:
+		return ErrInvalidSLA.WithMessage("maxFailureRate is a percent")
+	case req.WindowMinutes < 0 || req.WindowMinutes > MaxSLAWindow:
+		return ErrInvalidSLA.WithMessage("windowMinutes is at most 1440")
+	case req.MaxDurationMs == 0 && req.MaxFailureRate == 0 && req.ExpectedIntervalSeconds == 0:
+		return ErrInvalidSLA.WithMessage("set maxDurationMs, maxFailureRate or expectedIntervalSeconds")
+	}
+
+	s.MaxDurationMs = req.MaxDurationMs
+	s.MaxFailureRate = req.MaxFailureRate
+	s.WindowMinutes = req.WindowMinutes
+	if s.WindowMinutes == 0 {
+		s.WindowMinutes = DefaultSLAWindow
+	}
+	s.MinExecutions = max(req.MinExecutions, 1)
+	s.ExpectedIntervalSeconds = req.ExpectedIntervalSeconds
+	s.UpdatedBy = updatedBy
+	s.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// SLAMeasures are what the executions of a workflow did, as measured by the
+// SLA monitor
+type SLAMeasures struct {
+	// LongestDurationMs is the longest of the last finished execution and
+	// the executions still running
+	LongestDurationMs int64
+	Finished          int64
+	Failed            int64
+	// LastStartedAt is zero when no execution started since the SLA was set
+	LastStartedAt time.Time
+}
+
+// SLAObjectiveStatus compares the measure of an objective with its target
+type SLAObjectiveStatus struct {
+	Objective string  `json:"objective"`
+	Target    float64 `json:"target"`
+	Actual    float64 `json:"actual"`
+	Breached  bool    `json:"breached"`
+	// Since is when the objective was last breached or met
+	Since time.Time `json:"since"`
+}
+
+// SLAStatus is the latest evaluation of an SLA
+type SLAStatus struct {
+	SLA         *SLA                 `json:"sla"`
+	Breached    bool                 `json:"breached"`
+	Objectives  []SLAObjectiveStatus `json:"objectives"`
+	EvaluatedAt time.Time            `json:"evaluatedAt"`
+}
+
+// Evaluate compares the measures with the objectives of the SLA at now.
+// Objectives whose state did not change since previous keep its Since.
+func (s *SLA) Evaluate(m SLAMeasures, previous *SLAStatus, now time.Time) *SLAStatus {
+	status := &SLAStatus{SLA: s, EvaluatedAt: now}
+	add := func(objective string, target, actual float64, breached bool) {
+		since := now
+		if previous != nil {
+			if prev := previous.Objective(objective); prev != nil && prev.Breached == breached {
+				since = prev.Since
+			}
+		}
+		status.Objectives = append(status.Objectives, SLAObjectiveStatus{
+			Objective: objective,
+			Target:    target,
+			Actual:    actual,
+			Breached:  breached,
+			Since:     since,
+		})
+		status.Breached = status.Breached || breached
+	}
+
+	if s.MaxDurationMs > 0 {
+		add(SLAObjectiveDuration, float64(s.MaxDurationMs), float64(m.LongestDurationMs),
+			m.LongestDurationMs > s.MaxDurationMs)
+	}
+	if s.MaxFailureRate > 0 {
+		var rate float64
+		if m.Finished > 0 {
+			rate = float64(m.Failed) / float64(m.Finished) * 100
+		}
+		add(SLAObjectiveFailureRate, s.MaxFailureRate, rate,
+			m.Finished >= int64(s.MinExecutions) && rate > s.MaxFailureRate)
+	}
+	if s.ExpectedIntervalSeconds > 0 {
+		last := m.LastStartedAt
+		if last.Before(s.UpdatedAt) {
+			last = s.UpdatedAt
+		}
+		gap := now.Sub(last).Seconds()
+		add(SLAObjectiveSchedule, float64(s.ExpectedIntervalSeconds), gap,
+			gap > float64(s.ExpectedIntervalSeconds))
+	}
+	return status
+}
+
+// Objective returns the status of an objective, nil when it is not
+// monitored
+func (s *SLAStatus) Objective(objective string) *SLAObjectiveStatus {
+	for i := range s.Objectives {
+		if s.Objectives[i].Objective == objective {
+			return &s.Objectives[i]
+		}
+	}
+	return nil
+}
+
+// SLAMinute is the minute finished executions are counted in for the
+// failure rate
+func SLAMinute(t time.Time) string {
+	return t.UTC().Format("200601021504")
+}
+
+// SLAOutcomesKey are the parts of the Redis key, within the namespace of the
+// tenant, counting the executions of a workflow finished and failed in a
+// minute
+func SLAOutcomesKey(workflowID, minute string) []string {
+	return []string{"sla", "outcomes", workflowID, minute}
+}
+
+// SLALastKey are the parts of the Redis key, within the namespace of the
+// tenant, holding when the last execution of a workflow started and how
+// long the last finished one ran
+func SLALastKey(workflowID string) []string {
+	return []string{"sla", "last", workflowID}
+}
+
+// SLAStatusKey are the parts of the Redis key, within the namespace of the
+// tenant, holding the latest SLAStatus of a workflow
+func SLAStatusKey(workflowID string) []string {
+	return []string{"sla", "status", workflowID}
+}
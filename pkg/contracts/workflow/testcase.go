@@ -0,0 +1,220 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var (
+	ErrTestCaseNotFound = apperrors.New(apperrors.CategoryNotFound, "TEST_CASE_NOT_FOUND", "workflow test case not found")
+	ErrTestCaseExists   = apperrors.New(apperrors.CategoryConflict, "TEST_CASE_EXISTS", "workflow test case already exists")
+	ErrInvalidTestCase  = apperrors.New(apperrors.CategoryValidation, "INVALID_TEST_CASE", "invalid workflow test case")
+	ErrTestRunNotFound  = apperrors.New(apperrors.CategoryNotFound, "TEST_RUN_NOT_FOUND", "workflow test run not found")
+	ErrNoTestCases      = apperrors.New(apperrors.CategoryValidation, "NO_TEST_CASES", "workflow has no test cases")
+)
+
+// Statuses of workflow test runs
+const (
+	TestRunRunning = "running"
+	TestRunPassed  = "passed"
+	TestRunFailed  = "failed"
+)
+
+// WorkflowTestCase is a test of a workflow: the run of an input against
+// mocks, simulated as by SimulationRequest, and what the run must produce
+type WorkflowTestCase struct {
+	ID          string                 `json:"id" gorm:"primaryKey"`
+	TenantID    string                 `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
+	WorkflowID  string                 `json:"workflowId" gorm:"not null;uniqueIndex:idx_workflow_test_cases_name"`
+	Name        string                 `json:"name" gorm:"not null;uniqueIndex:idx_workflow_test_cases_name"`
+	Description string                 `json:"description"`
+	Input       map[string]interface{} `json:"input" gorm:"serializer:json"`
+	Mocks       []NodeMock             `json:"mocks" gorm:"serializer:json"`
+	Strict      bool                   `json:"strict"`
+	Assertions  []TestAssertion        `json:"assertions" gorm:"serializer:json"`
+	CreatedBy   string                 `json:"createdBy"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (WorkflowTestCase) TableName() string {
+	return "workflow.workflow_test_cases"
+}
+
+// TestAssertion is an expectation on the run of a test case, on a node or
+// on the whole run when NodeID is empty. Status is the expected status:
+// completed or failed, or skipped for a node the run must not reach. Path
+// is a dot path into the output, such as body.customer.id, whose value
+// must equal Equals, compared by JSON, or only be set when Equals is
+// omitted.
+type TestAssertion struct {
+	NodeID string      `json:"nodeId,omitempty"`
+	Status string      `json:"status,omitempty"`
+	Path   string      `json:"path,omitempty"`
+	Equals interface{} `json:"equals,omitempty"`
+}
+
+// TestCaseRequest creates or replaces a test case
+type TestCaseRequest struct {
+	Name        string                 `json:"name" binding:"required"`
+	Description string                 `json:"description"`
+	Input       map[string]interface{} `json:"input"`
+	Mocks       []NodeMock             `json:"mocks"`
+	Strict      bool                   `json:"strict"`
+	Assertions  []TestAssertion        `json:"assertions"`
+}
+
+// Validate checks the test case asserts something, on nodes of the
+// workflow, and its mocks are valid
+func (r *TestCaseRequest) Validate(wf *Workflow) error {
+	if len(r.Name) > 100 {
+		return ErrInvalidTestCase.WithMessage("test case name must be at most 100 characters")
+	}
+	if len(r.Assertions) == 0 {
+		return ErrInvalidTestCase.WithMessage("test case %s has no assertions", r.Name)
+	}
+	simulation := SimulationRequest{Mocks: r.Mocks}
+	if err := simulation.Validate(); err != nil {
+		return ErrInvalidTestCase.WithMessage("test case %s: %s", r.Name, apperrors.From(err).Message)
+	}
+
+	nodes := make(map[string]bool, len(wf.Nodes))
+	for _, node := range wf.Nodes {
+		nodes[node.ID] = true
+	}
+	for i, assertion := range r.Assertions {
+		if assertion.NodeID != "" && !nodes[assertion.NodeID] {
+			return ErrInvalidTestCase.WithMessage("assertion %d is on unknown node %q", i, assertion.NodeID)
+		}
+		switch assertion.Status {
+		case "", string(ExecutionCompleted), string(ExecutionFailed):
+		case string(NodeExecutionSkipped):
+			if assertion.NodeID == "" {
+				return ErrInvalidTestCase.WithMessage("assertion %d expects the run to be skipped", i)
+			}
+		default:
+			return ErrInvalidTestCase.WithMessage("assertion %d expects unknown status %q", i, assertion.Status)
+		}
+		if assertion.Status == "" && assertion.Path == "" {
+			return ErrInvalidTestCase.WithMessage("assertion %d expects neither a status nor a value", i)
+		}
+		if assertion.Path != "" && assertion.Status == string(NodeExecutionSkipped) {
+			return ErrInvalidTestCase.WithMessage("assertion %d expects a value from a skipped node", i)
+		}
+	}
+	return nil
+}
+
+// Simulation is the simulation running the test case on a version of its
+// workflow
+func (c *WorkflowTestCase) Simulation(version int) *SimulationRequest {
+	return &SimulationRequest{
+		WorkflowID: c.WorkflowID,
+		Version:    version,
+		Data:       c.Input,
+		Mocks:      c.Mocks,
+		Strict:     c.Strict,
+	}
+}
+
+// Check evaluates the assertions of the test case against the run of its
+// simulation and describes those failing
+func (c *WorkflowTestCase) Check(result *SimulationResult) []string {
+	failures := []string{}
+	for _, assertion := range c.Assertions {
+		subject, status, output := "run", result.Status, result.Output
+		if assertion.NodeID != "" {
+			subject = "node " + assertion.NodeID
+			status, output = string(NodeExecutionSkipped), nil
+			if node := result.Node(assertion.NodeID); node != nil {
+				status, output = node.Status, node.Output
+			}
+		}
+
+		if assertion.Status != "" && assertion.Status != status {
+			failures = append(failures, fmt.Sprintf("%s is %s, expected %s", subject, status, assertion.Status))
+			continue
+		}
+		if assertion.Path == "" {
+			continue
+		}
+
+		actual := inputField(output, assertion.Path)
+		switch {
+		case assertion.Equals == nil && actual == nil:
+			failures = append(failures, fmt.Sprintf("%s has no %s", subject, assertion.Path))
+		case assertion.Equals != nil && !sameJSON(actual, assertion.Equals):
+			failures = append(failures, fmt.Sprintf("%s has %s = %s, expected %s", subject, assertion.Path, compactJSON(actual), compactJSON(assertion.Equals)))
+		}
+	}
+	return failures
+}
+
+// compactJSON renders a value in assertion failures
+func compactJSON(value interface{}) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(raw)
+}
+
+// WorkflowTestRun is a run of every test case of a workflow on one of its
+// versions. The execution service simulates the cases and records their
+// results.
+type WorkflowTestRun struct {
+	ID         string           `json:"id" gorm:"primaryKey"`
+	TenantID   string           `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
+	WorkflowID string           `json:"workflowId" gorm:"not null;index:idx_workflow_test_runs_workflow"`
+	Version    int              `json:"version"`
+	Status     string           `json:"status"`
+	Cases      []TestCaseResult `json:"cases" gorm:"serializer:json"`
+	Passed     int              `json:"passed"`
+	Failed     int              `json:"failed"`
+	// Error is why the run could not simulate the cases
+	Error       string     `json:"error,omitempty"`
+	RequestedBy string     `json:"requestedBy"`
+	StartedAt   time.Time  `json:"startedAt" gorm:"index:idx_workflow_test_runs_workflow"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+}
+
+// TableName specifies the table name for GORM
+func (WorkflowTestRun) TableName() string {
+	return "workflow.workflow_test_runs"
+}
+
+// TestCaseResult is the outcome of a test case in a run
+type TestCaseResult struct {
+	CaseID string `json:"caseId"`
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// Status is the status of the simulated run
+	Status   string   `json:"status"`
+	Failures []string `json:"failures,omitempty"`
+	// Error is why the case could not be simulated
+	Error    string `json:"error,omitempty"`
+	Duration int64  `json:"duration"` // in milliseconds
+}
+
+// Finish records the results of the cases, the run passing when all did
+func (r *WorkflowTestRun) Finish(results []TestCaseResult) {
+	now := time.Now()
+	r.Cases = results
+	r.Passed, r.Failed = 0, 0
+	for _, result := range results {
+		if result.Passed {
+			r.Passed++
+		} else {
+			r.Failed++
+		}
+	}
+	r.Status = TestRunPassed
+	if r.Failed > 0 || r.Error != "" {
+		r.Status = TestRunFailed
+	}
+	r.FinishedAt = &now
+}
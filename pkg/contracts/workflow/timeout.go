@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var (
+	ErrNodeTimeout        = apperrors.New(apperrors.CategoryUpstream, "NODE_TIMEOUT", "node execution timed out")
+	ErrInvalidNodeTimeout = apperrors.New(apperrors.CategoryValidation, "INVALID_NODE_TIMEOUT", "invalid node timeout")
+)
+
+// NodeTimeout returns the effective timeout of a node. An entry in the
+// workflow settings overrides the timeout declared on the node itself.
+func (w *Workflow) NodeTimeout(nodeID string) time.Duration {
+	if seconds, ok := w.Settings.NodeTimeouts[nodeID]; ok && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	for _, node := range w.Nodes {
+		if node.ID == nodeID && node.Timeout > 0 {
+			return time.Duration(node.Timeout) * time.Second
+		}
+	}
+	return 0
+}
+
+// NodeTimeouts returns the effective timeout of every node that has one
+func (w *Workflow) NodeTimeouts() map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for _, node := range w.Nodes {
+		if timeout := w.NodeTimeout(node.ID); timeout > 0 {
+			timeouts[node.ID] = timeout
+		}
+	}
+	return timeouts
+}
+
+// ValidateNodeTimeouts checks that node timeout settings reference existing
+// nodes and never exceed the workflow timeout
+func (w *Workflow) ValidateNodeTimeouts() error {
+	if len(w.Settings.NodeTimeouts) == 0 {
+		return nil
+	}
+
+	nodes := make(map[string]bool, len(w.Nodes))
+	for _, node := range w.Nodes {
+		nodes[node.ID] = true
+	}
+
+	for nodeID, seconds := range w.Settings.NodeTimeouts {
+		if !nodes[nodeID] {
+			return fmt.Errorf("%w: node %s not found", ErrInvalidNodeTimeout, nodeID)
+		}
+		if seconds < 0 {
+			return fmt.Errorf("%w: node %s has negative timeout %d", ErrInvalidNodeTimeout, nodeID, seconds)
+		}
+		if w.Settings.Timeout > 0 && seconds > w.Settings.Timeout {
+			return fmt.Errorf("%w: node %s timeout %ds exceeds workflow timeout %ds", ErrInvalidNodeTimeout, nodeID, seconds, w.Settings.Timeout)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,353 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var ErrInvalidTransform = apperrors.New(apperrors.CategoryValidation, "INVALID_TRANSFORM", "invalid transform node mappings")
+
+// Types a field mapping casts its value to
+const (
+	CastString  = "string"
+	CastNumber  = "number"
+	CastInteger = "integer"
+	CastBoolean = "boolean"
+)
+
+// transformExpression matches the {{ path }} placeholders of computed fields
+var transformExpression = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+// FieldMapping maps a field of the input of a transform node to a field of
+// its output. The value is read from Source, or computed from Expression,
+// falls back to Default when missing and is cast to Cast. Paths are dot
+// separated, such as "customer.email".
+type FieldMapping struct {
+	Target string `json:"target"`
+	// Source is the input field, the target field itself when neither it
+	// nor an expression is set
+	Source string `json:"source,omitempty"`
+	// Expression computes the value from {{ path }} placeholders of input
+	// fields. A lone placeholder keeps the type of the field, otherwise
+	// the fields are written into the text.
+	Expression string      `json:"expression,omitempty"`
+	Default    interface{} `json:"default,omitempty"`
+	Cast       string      `json:"cast,omitempty"`
+}
+
+// TransformParameters are the parameters of a transform node
+type TransformParameters struct {
+	Mappings []FieldMapping `json:"mappings"`
+	// KeepUnmapped copies the input fields no mapping reads into the
+	// output, renamed fields move instead of being copied
+	KeepUnmapped bool `json:"keepUnmapped,omitempty"`
+	// ItemsField maps each item of the list under it instead of the input
+	// as a whole
+	ItemsField string `json:"itemsField,omitempty"`
+}
+
+// TransformPreviewResult is a sample input of a transform node and its
+// output, or the error mapping it
+type TransformPreviewResult struct {
+	Input  map[string]interface{} `json:"input"`
+	Output map[string]interface{} `json:"output,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+}
+
+// TransformPreview is a dry run of a transform node over sample inputs.
+// Schema lists the JSON type of each output field seen in the samples, what
+// the downstream nodes receive.
+type TransformPreview struct {
+	Results []TransformPreviewResult `json:"results"`
+	Schema  map[string]string        `json:"schema"`
+}
+
+// ParseTransformParameters reads the mappings, keepUnmapped and itemsField
+// parameters of a transform node
+func ParseTransformParameters(params map[string]interface{}) (TransformParameters, error) {
+	var transform TransformParameters
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return transform, fmt.Errorf("%w: %v", ErrInvalidTransform, err)
+	}
+	if err := json.Unmarshal(raw, &transform); err != nil {
+		return transform, fmt.Errorf("%w: %v", ErrInvalidTransform, err)
+	}
+
+	if len(transform.Mappings) == 0 {
+		return transform, fmt.Errorf("%w: transform node requires a 'mappings' parameter", ErrInvalidTransform)
+	}
+	targets := make(map[string]bool, len(transform.Mappings))
+	for i, mapping := range transform.Mappings {
+		if mapping.Target == "" {
+			return transform, fmt.Errorf("%w: mapping %d has no target", ErrInvalidTransform, i)
+		}
+		if targets[mapping.Target] {
+			return transform, fmt.Errorf("%w: target %s is mapped twice", ErrInvalidTransform, mapping.Target)
+		}
+		targets[mapping.Target] = true
+
+		if mapping.Source != "" && mapping.Expression != "" {
+			return transform, fmt.Errorf("%w: mapping of %s has both a source and an expression", ErrInvalidTransform, mapping.Target)
+		}
+		switch mapping.Cast {
+		case "", CastString, CastNumber, CastInteger, CastBoolean:
+		default:
+			return transform, fmt.Errorf("%w: mapping of %s casts to unknown type %q", ErrInvalidTransform, mapping.Target, mapping.Cast)
+		}
+	}
+	return transform, nil
+}
+
+// Apply maps an input to the output of the transform node
+func (t TransformParameters) Apply(input map[string]interface{}) (map[string]interface{}, error) {
+	if t.ItemsField == "" {
+		return t.applyRecord(input)
+	}
+
+	items, ok := input[t.ItemsField].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("input field %s is not a list", t.ItemsField)
+	}
+	mapped := make([]interface{}, 0, len(items))
+	for i, item := range items {
+		record, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("item %d of %s is not an object", i, t.ItemsField)
+		}
+		output, err := t.applyRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("item %d of %s: %w", i, t.ItemsField, err)
+		}
+		mapped = append(mapped, output)
+	}
+
+	output := make(map[string]interface{}, len(input))
+	for k, v := range input {
+		output[k] = v
+	}
+	output[t.ItemsField] = mapped
+	return output, nil
+}
+
+// Preview applies the mappings to each sample, recording the errors
+// instead of stopping at the first
+func (t TransformParameters) Preview(samples []map[string]interface{}) *TransformPreview {
+	preview := &TransformPreview{
+		Results: make([]TransformPreviewResult, 0, len(samples)),
+		Schema:  make(map[string]string),
+	}
+	for _, sample := range samples {
+		result := TransformPreviewResult{Input: sample}
+		output, err := t.Apply(sample)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Output = output
+			describeSchema(preview.Schema, "", output)
+		}
+		preview.Results = append(preview.Results, result)
+	}
+	return preview
+}
+
+func (t TransformParameters) applyRecord(input map[string]interface{}) (map[string]interface{}, error) {
+	output := make(map[string]interface{})
+	if t.KeepUnmapped {
+		output = copyRecord(input)
+	}
+
+	for _, mapping := range t.Mappings {
+		value, err := mapping.value(input)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", mapping.Target, err)
+		}
+		if t.KeepUnmapped && mapping.Source != "" && mapping.Source != mapping.Target {
+			deleteField(output, mapping.Source)
+		}
+		setField(output, mapping.Target, value)
+	}
+	return output, nil
+}
+
+// value resolves the value of the target field from an input
+func (m FieldMapping) value(input map[string]interface{}) (interface{}, error) {
+	var value interface{}
+	switch {
+	case m.Expression != "":
+		value = evaluateTransformExpression(m.Expression, input)
+	case m.Source != "":
+		value = inputField(input, m.Source)
+	default:
+		value = inputField(input, m.Target)
+	}
+
+	if value == nil || value == "" {
+		if m.Default != nil {
+			value = m.Default
+		}
+	}
+	if value == nil || m.Cast == "" {
+		return value, nil
+	}
+	return castField(value, m.Cast)
+}
+
+// evaluateTransformExpression fills the {{ path }} placeholders of an
+// expression with input fields. A lone placeholder returns the field as it
+// is, missing fields read as empty text.
+func evaluateTransformExpression(expression string, input map[string]interface{}) interface{} {
+	trimmed := strings.TrimSpace(expression)
+	if match := transformExpression.FindStringSubmatchIndex(trimmed); match != nil && match[0] == 0 && match[1] == len(trimmed) {
+		return inputField(input, trimmed[match[2]:match[3]])
+	}
+
+	return transformExpression.ReplaceAllStringFunc(expression, func(placeholder string) string {
+		path := transformExpression.FindStringSubmatch(placeholder)[1]
+		switch value := inputField(input, path).(type) {
+		case nil:
+			return ""
+		case string:
+			return value
+		case map[string]interface{}, []interface{}:
+			raw, _ := json.Marshal(value)
+			return string(raw)
+		default:
+			return fmt.Sprint(value)
+		}
+	})
+}
+
+// castField converts a value to the type of a mapping
+func castField(value interface{}, cast string) (interface{}, error) {
+	switch cast {
+	case CastString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		if _, complex := value.(map[string]interface{}); complex {
+			raw, _ := json.Marshal(value)
+			return string(raw), nil
+		}
+		return fmt.Sprint(value), nil
+
+	case CastNumber, CastInteger:
+		number, ok := toMetricNumber(value)
+		if b, isBool := value.(bool); isBool {
+			number, ok = 0, true
+			if b {
+				number = 1
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("cannot cast %v to %s", value, cast)
+		}
+		if cast == CastInteger {
+			return int64(math.Trunc(number)), nil
+		}
+		return number, nil
+
+	case CastBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("cannot cast %q to boolean", v)
+			}
+			return b, nil
+		}
+		if number, ok := toMetricNumber(value); ok {
+			return number != 0, nil
+		}
+		return nil, fmt.Errorf("cannot cast %v to boolean", value)
+	}
+	return value, nil
+}
+
+// copyRecord copies a record and the objects nested in it, so fields set
+// or deleted in the copy leave the original alone
+func copyRecord(record map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(record))
+	for k, v := range record {
+		if nested, ok := v.(map[string]interface{}); ok {
+			v = copyRecord(nested)
+		}
+		copied[k] = v
+	}
+	return copied
+}
+
+// setField sets the field of a record at a dot path, creating the objects
+// along it
+func setField(record map[string]interface{}, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	current := record
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+}
+
+// deleteField removes the field of a record at a dot path
+func deleteField(record map[string]interface{}, path string) {
+	parts := strings.Split(path, ".")
+	current := record
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = next
+	}
+	delete(current, parts[len(parts)-1])
+}
+
+// describeSchema records the JSON type of each field of a record by dot
+// path. Fields seen with different types are "mixed".
+func describeSchema(schema map[string]string, prefix string, record map[string]interface{}) {
+	for k, v := range record {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		var kind string
+		switch value := v.(type) {
+		case nil:
+			kind = "null"
+		case string:
+			kind = "string"
+		case bool:
+			kind = "boolean"
+		case []interface{}:
+			kind = "array"
+		case map[string]interface{}:
+			kind = "object"
+			describeSchema(schema, path, value)
+		default:
+			kind = "number"
+		}
+
+		// Nulls say nothing of the type of a field seen with values
+		switch seen, ok := schema[path]; {
+		case !ok || seen == "null":
+			schema[path] = kind
+		case seen != kind && kind != "null":
+			schema[path] = "mixed"
+		}
+	}
+}
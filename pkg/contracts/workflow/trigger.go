@@ -37,6 +37,7 @@ type Trigger interface {
 	ShouldFire(event interface{}) bool
 	IsActive() bool
 	GetStatus() string
+	SetStatus(status string)
 }
 
 // BaseTrigger contains common trigger fields
@@ -68,6 +69,9 @@ type WorkflowTrigger struct {
 	FireCount   int64           `json:"fireCount" gorm:"default:0"`
 	ErrorCount  int64           `json:"errorCount" gorm:"default:0"`
 	LastError   string          `json:"lastError"`
+	// EnvironmentID is the environment the executions the trigger starts
+	// run in, the default environment when empty
+	EnvironmentID string `json:"environmentId,omitempty"`
 }
 
 // GetID returns the trigger ID
@@ -95,6 +99,11 @@ func (t *BaseTrigger) GetStatus() string {
 	return t.Status
 }
 
+// SetStatus sets the trigger status
+func (t *BaseTrigger) SetStatus(status string) {
+	t.Status = status
+}
+
 // GetConfig returns the trigger configuration
 func (t *BaseTrigger) GetConfig() map[string]interface{} {
 	return t.Config
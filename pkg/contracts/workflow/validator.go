@@ -1,19 +1,21 @@
 package workflow
 
 import (
-	"errors"
 	"fmt"
+	"strings"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
 )
 
 var (
-	ErrWorkflowHasCycle      = errors.New("workflow contains a cycle")
-	ErrInvalidConnection     = errors.New("invalid connection: node not found")
-	ErrNoTriggerNode         = errors.New("workflow must have at least one trigger node")
-	ErrOrphanedNode          = errors.New("workflow contains orphaned nodes")
-	ErrInvalidNodeType       = errors.New("invalid node type")
-	ErrDuplicateNodeID       = errors.New("duplicate node ID found")
-	ErrInvalidPortConnection = errors.New("invalid port connection")
-	ErrMissingRequiredInputs = errors.New("node is missing required inputs")
+	ErrWorkflowHasCycle      = apperrors.New(apperrors.CategoryValidation, "WORKFLOW_HAS_CYCLE", "workflow contains a cycle")
+	ErrInvalidConnection     = apperrors.New(apperrors.CategoryValidation, "INVALID_CONNECTION", "invalid connection: node not found")
+	ErrNoTriggerNode         = apperrors.New(apperrors.CategoryValidation, "NO_TRIGGER_NODE", "workflow must have at least one trigger node")
+	ErrOrphanedNode          = apperrors.New(apperrors.CategoryValidation, "ORPHANED_NODE", "workflow contains orphaned nodes")
+	ErrInvalidNodeType       = apperrors.New(apperrors.CategoryValidation, "INVALID_NODE_TYPE", "invalid node type")
+	ErrDuplicateNodeID       = apperrors.New(apperrors.CategoryValidation, "DUPLICATE_NODE_ID", "duplicate node ID found")
+	ErrInvalidPortConnection = apperrors.New(apperrors.CategoryValidation, "INVALID_PORT_CONNECTION", "invalid port connection")
+	ErrMissingRequiredInputs = apperrors.New(apperrors.CategoryValidation, "MISSING_REQUIRED_INPUTS", "node is missing required inputs")
 )
 
 // Validator provides comprehensive workflow validation
@@ -61,6 +63,26 @@ func (v *Validator) Validate() ([]string, []string, error) {
 		v.errors = append(v.errors, err.Error())
 	}
 
+	// Check try/catch error boundaries
+	if err := v.workflow.ValidateErrorBoundaries(); err != nil {
+		v.errors = append(v.errors, err.Error())
+	}
+
+	// Check saga compensation nodes
+	if err := v.workflow.ValidateCompensations(); err != nil {
+		v.errors = append(v.errors, err.Error())
+	}
+
+	// Check per-node timeout settings
+	if err := v.workflow.ValidateNodeTimeouts(); err != nil {
+		v.errors = append(v.errors, err.Error())
+	}
+
+	// Check sensitive field settings
+	if err := v.workflow.ValidateSensitiveFields(); err != nil {
+		v.errors = append(v.errors, err.Error())
+	}
+
 	// Check for orphaned nodes
 	if err := v.validateNoOrphanedNodes(); err != nil {
 		v.warnings = append(v.warnings, err.Error())
@@ -107,13 +129,13 @@ func (v *Validator) validateConnections() error {
 		// Check source node exists
 		sourceNode, sourceExists := v.nodeMap[conn.Source]
 		if !sourceExists {
-			return fmt.Errorf("%w: source node '%s' not found", ErrInvalidConnection, conn.Source)
+			return fmt.Errorf("%w: connection %s source node '%s' not found", ErrInvalidConnection, conn.ID, conn.Source)
 		}
 
 		// Check target node exists
 		targetNode, targetExists := v.nodeMap[conn.Target]
 		if !targetExists {
-			return fmt.Errorf("%w: target node '%s' not found", ErrInvalidConnection, conn.Target)
+			return fmt.Errorf("%w: connection %s target node '%s' not found", ErrInvalidConnection, conn.ID, conn.Target)
 		}
 
 		// Validate port compatibility
@@ -124,49 +146,11 @@ func (v *Validator) validateConnections() error {
 	return nil
 }
 
-// validateNoCycles uses DFS to detect cycles in the workflow
+// validateNoCycles reports the first cycle of the workflow node by node
 func (v *Validator) validateNoCycles() error {
-	// Build adjacency list
-	graph := make(map[string][]string)
-	for _, conn := range v.workflow.Connections {
-		graph[conn.Source] = append(graph[conn.Source], conn.Target)
-	}
-
-	// Track visited and recursion stack
-	visited := make(map[string]bool)
-	recStack := make(map[string]bool)
-
-	// DFS helper function
-	var hasCycleDFS func(nodeID string) bool
-	hasCycleDFS = func(nodeID string) bool {
-		visited[nodeID] = true
-		recStack[nodeID] = true
-
-		// Check all neighbors
-		for _, neighbor := range graph[nodeID] {
-			if !visited[neighbor] {
-				if hasCycleDFS(neighbor) {
-					return true
-				}
-			} else if recStack[neighbor] {
-				// Found a back edge (cycle)
-				return true
-			}
-		}
-
-		recStack[nodeID] = false
-		return false
-	}
-
-	// Check all nodes
-	for nodeID := range v.nodeMap {
-		if !visited[nodeID] {
-			if hasCycleDFS(nodeID) {
-				return ErrWorkflowHasCycle
-			}
-		}
+	if cycles := NewDAG(v.workflow).FindCycles(); len(cycles) > 0 {
+		return fmt.Errorf("%w: %s", ErrWorkflowHasCycle, strings.Join(cycles[0], " -> "))
 	}
-
 	return nil
 }
 
@@ -188,6 +172,11 @@ func (v *Validator) validateNoOrphanedNodes() error {
 			continue
 		}
 
+		// Compensation nodes are reached through rollback, not connections
+		if v.workflow.IsCompensationNode(nodeID) {
+			continue
+		}
+
 		if !connected[nodeID] {
 			orphaned = append(orphaned, nodeID)
 		}
@@ -215,6 +204,9 @@ func (v *Validator) validateNodeConfigurations() {
 		NodeTypeCode:        true,
 		NodeTypeEmail:       true,
 		NodeTypeSlack:       true,
+		NodeTypeValidate:    true,
+		NodeTypeMetric:      true,
+		NodeTypeTransform:   true,
 	}
 
 	for _, node := range v.workflow.Nodes {
@@ -231,6 +223,14 @@ func (v *Validator) validateNodeConfigurations() {
 			v.validateDatabaseNode(&node)
 		case NodeTypeEmail:
 			v.validateEmailNode(&node)
+		case NodeTypeValidate:
+			v.validateValidateNode(&node)
+		case NodeTypeMetric:
+			v.validateMetricNode(&node)
+		case NodeTypeMerge:
+			v.validateMergeNode(&node)
+		case NodeTypeTransform:
+			v.validateTransformNode(&node)
 		}
 
 		// Check timeout values
@@ -299,6 +299,50 @@ func (v *Validator) validateEmailNode(node *Node) {
 	}
 }
 
+// validateValidateNode validates validate node parameters
+func (v *Validator) validateValidateNode(node *Node) {
+	if node.Parameters == nil {
+		v.errors = append(v.errors, fmt.Sprintf("Validate node %s missing parameters", node.ID))
+		return
+	}
+
+	_, hasSchema := node.Parameters["schema"]
+	_, hasRules := node.Parameters["rules"]
+	if !hasSchema && !hasRules {
+		v.errors = append(v.errors, fmt.Sprintf("Validate node %s requires a 'schema' or 'rules' parameter", node.ID))
+	}
+}
+
+// validateMetricNode validates metric node parameters, values read from
+// the input are only checked when the node runs
+func (v *Validator) validateMetricNode(node *Node) {
+	metrics, ok := node.Parameters["metrics"].(map[string]interface{})
+	if !ok || len(metrics) == 0 {
+		v.errors = append(v.errors, fmt.Sprintf("Metric node %s requires a 'metrics' parameter", node.ID))
+		return
+	}
+
+	for name := range metrics {
+		if !metricName.MatchString(name) {
+			v.errors = append(v.errors, fmt.Sprintf("Metric node %s has invalid metric name: %s", node.ID, name))
+		}
+	}
+}
+
+// validateMergeNode validates merge node parameters
+func (v *Validator) validateMergeNode(node *Node) {
+	if _, err := ParseMergeParameters(node.Parameters); err != nil {
+		v.errors = append(v.errors, fmt.Sprintf("Merge node %s: %v", node.ID, err))
+	}
+}
+
+// validateTransformNode validates transform node mappings
+func (v *Validator) validateTransformNode(node *Node) {
+	if _, err := ParseTransformParameters(node.Parameters); err != nil {
+		v.errors = append(v.errors, fmt.Sprintf("Transform node %s: %v", node.ID, err))
+	}
+}
+
 // validateNodeDependencies checks if all node inputs are satisfied
 func (v *Validator) validateNodeDependencies() {
 	// Build incoming connections map
@@ -341,6 +385,10 @@ func (v *Validator) validatePortCompatibility(source, target *Node, conn *Connec
 		return fmt.Errorf("split node %s has invalid output port: %s", source.ID, conn.SourcePort)
 	}
 
+	if source.Type == NodeTypeValidate && conn.SourcePort != "output" && conn.SourcePort != "valid" && conn.SourcePort != "invalid" {
+		return fmt.Errorf("validate node %s has invalid output port: %s", source.ID, conn.SourcePort)
+	}
+
 	return nil
 }
 
@@ -7,6 +7,9 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
 )
 
 // Variable types
@@ -29,11 +32,13 @@ const (
 )
 
 var (
-	ErrVariableNotFound    = errors.New("variable not found")
-	ErrInvalidVariableType = errors.New("invalid variable type")
-	ErrVariableReadOnly    = errors.New("variable is read-only")
-	ErrCircularReference   = errors.New("circular variable reference detected")
-	ErrInvalidVariableName = errors.New("invalid variable name")
+	ErrVariableNotFound    = apperrors.New(apperrors.CategoryNotFound, "VARIABLE_NOT_FOUND", "variable not found")
+	ErrInvalidVariableType = apperrors.New(apperrors.CategoryValidation, "INVALID_VARIABLE_TYPE", "invalid variable type")
+	ErrVariableReadOnly    = apperrors.New(apperrors.CategoryValidation, "VARIABLE_READ_ONLY", "variable is read-only")
+	ErrCircularReference   = apperrors.New(apperrors.CategoryValidation, "CIRCULAR_VARIABLE_REFERENCE", "circular variable reference detected")
+	ErrInvalidVariableName = apperrors.New(apperrors.CategoryValidation, "INVALID_VARIABLE_NAME", "invalid variable name")
+	ErrEnvironmentNotFound = apperrors.New(apperrors.CategoryNotFound, "ENVIRONMENT_NOT_FOUND", "environment not found")
+	ErrDefaultEnvironment  = apperrors.New(apperrors.CategoryConflict, "DEFAULT_ENVIRONMENT", "cannot delete default environment")
 )
 
 // Variable represents a workflow variable
@@ -53,16 +58,37 @@ type WorkflowVariable struct {
 	UpdatedAt   string      `json:"updatedAt"`
 }
 
-// Environment represents an execution environment
+// TableName specifies the table name for GORM
+func (WorkflowVariable) TableName() string {
+	return "workflow.workflow_variables"
+}
+
+// Environment represents an execution environment. A workflow version is
+// deployed to an environment, see Deployment; executions in it run that
+// version with the variables and credentials of the environment.
 type Environment struct {
 	ID          string                 `json:"id" gorm:"primaryKey"`
 	WorkflowID  string                 `json:"workflowId" gorm:"index"`
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
 	Variables   map[string]interface{} `json:"variables" gorm:"serializer:json"`
-	IsDefault   bool                   `json:"isDefault"`
-	CreatedAt   string                 `json:"createdAt"`
-	UpdatedAt   string                 `json:"updatedAt"`
+	// Credentials replace the credentials the nodes of the workflow name,
+	// keyed by the credential ID in the definition
+	Credentials map[string]string `json:"credentials" gorm:"serializer:json"`
+	// PromotesFrom is the environment whose deployed version is the only
+	// one this environment may be deployed, as staging promotes from dev
+	PromotesFrom    string     `json:"promotesFrom,omitempty"`
+	DeployedVersion int        `json:"deployedVersion,omitempty"`
+	DeployedBy      string     `json:"deployedBy,omitempty"`
+	DeployedAt      *time.Time `json:"deployedAt,omitempty"`
+	IsDefault       bool       `json:"isDefault"`
+	CreatedAt       string     `json:"createdAt"`
+	UpdatedAt       string     `json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (Environment) TableName() string {
+	return "workflow.environments"
 }
 
 // VariableContext manages variables during workflow execution
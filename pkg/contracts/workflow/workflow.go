@@ -10,6 +10,7 @@ import (
 
 type Workflow struct {
 	ID          string       `json:"id" gorm:"primaryKey"`
+	TenantID    string       `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
 	Name        string       `json:"name" gorm:"not null"`
 	Description string       `json:"description"`
 	UserID      string       `json:"userId" gorm:"not null;index"`
@@ -17,9 +18,11 @@ type Workflow struct {
 	Nodes       []Node       `json:"nodes" gorm:"serializer:json"`
 	Connections []Connection `json:"connections" gorm:"serializer:json"`
 	Settings    Settings     `json:"settings" gorm:"serializer:json"`
+	Annotations []Annotation `json:"annotations" gorm:"serializer:json"`
 	Status      string       `json:"status" gorm:"default:'inactive'"`
 	IsActive    bool         `json:"isActive" gorm:"default:false"`
 	Version     int          `json:"version" gorm:"default:1"`
+	Checksum    string       `json:"checksum"`
 	Tags        []string     `json:"tags" gorm:"serializer:json"`
 	CreatedAt   time.Time    `json:"createdAt"`
 	UpdatedAt   time.Time    `json:"updatedAt"`
@@ -31,15 +34,26 @@ func (Workflow) TableName() string {
 	return "workflow.workflows"
 }
 
+// WorkspaceID is the team of the workflow, or its owner when it belongs to
+// no team
+func (w *Workflow) WorkspaceID() string {
+	if w.TeamID != "" {
+		return w.TeamID
+	}
+	return w.UserID
+}
+
 type Node struct {
-	ID         string                 `json:"id"`
-	Name       string                 `json:"name"`
-	Type       string                 `json:"type"`
-	Position   Position               `json:"position"`
-	Parameters map[string]interface{} `json:"parameters"`
-	Disabled   bool                   `json:"disabled"`
-	RetryCount int                    `json:"retryCount"`
-	Timeout    int                    `json:"timeout"`
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name"`
+	Type             string                 `json:"type"`
+	Position         Position               `json:"position"`
+	Parameters       map[string]interface{} `json:"parameters"`
+	Disabled         bool                   `json:"disabled"`
+	RetryCount       int                    `json:"retryCount"`
+	Timeout          int                    `json:"timeout"`
+	ContinueOnFail   bool                   `json:"continueOnFail"`
+	CompensationNode string                 `json:"compensationNode,omitempty"`
 }
 
 type Connection struct {
@@ -57,12 +71,36 @@ type Position struct {
 }
 
 type Settings struct {
-	ErrorHandling   ErrorHandling `json:"errorHandling"`
-	Timeout         int           `json:"timeout"`
-	RetryOnFailure  bool          `json:"retryOnFailure"`
-	MaxRetries      int           `json:"maxRetries"`
-	SaveDataOnError bool          `json:"saveDataOnError"`
-	Timezone        string        `json:"timezone"`
+	ErrorHandling   ErrorHandling   `json:"errorHandling"`
+	Timeout         int             `json:"timeout"`
+	RetryOnFailure  bool            `json:"retryOnFailure"`
+	MaxRetries      int             `json:"maxRetries"`
+	SaveDataOnError bool            `json:"saveDataOnError"`
+	Timezone        string          `json:"timezone"`
+	ErrorBoundaries []ErrorBoundary `json:"errorBoundaries,omitempty"`
+	// NodeTimeouts overrides node timeouts in seconds, keyed by node ID
+	NodeTimeouts map[string]int `json:"nodeTimeouts,omitempty"`
+	// WorkerClass is the class of executors the workflow runs on
+	WorkerClass string `json:"workerClass,omitempty"`
+	// RetentionDays is how long executions are kept
+	RetentionDays int `json:"retentionDays,omitempty"`
+	// Idempotent marks workflows whose output only depends on their input,
+	// repeat deliveries of an input get the cached output of its last run
+	Idempotent bool `json:"idempotent,omitempty"`
+	// ResultCacheTTL is how long the output of an idempotent workflow is
+	// cached, in seconds
+	ResultCacheTTL int `json:"resultCacheTtl,omitempty"`
+	// Sampling stores only a share of the successful executions
+	Sampling *SamplingPolicy `json:"sampling,omitempty"`
+	// SensitiveFields are the fields of node outputs redacted wherever
+	// execution data is persisted, logged or streamed, dotted paths keyed
+	// by node ID
+	SensitiveFields map[string][]string `json:"sensitiveFields,omitempty"`
+	// Lint holds the suppressions of lint rules
+	Lint *LintSettings `json:"lint,omitempty"`
+	// MaxParallelNodes lowers how many nodes of an execution run at once,
+	// 0 keeps the limit of the execution service
+	MaxParallelNodes int `json:"maxParallelNodes,omitempty"`
 }
 
 type ErrorHandling struct {
@@ -77,30 +115,39 @@ type WorkflowVersion struct {
 	WorkflowID string    `json:"workflowId" gorm:"not null;index"`
 	Version    int       `json:"version" gorm:"not null"`
 	Data       string    `json:"data" gorm:"type:jsonb"`
+	Checksum   string    `json:"checksum"`
 	ChangedBy  string    `json:"changedBy"`
 	ChangeNote string    `json:"changeNote"`
 	CreatedAt  time.Time `json:"createdAt"`
 }
 
+// WorkflowExecution is the record of a run of a workflow, shared by the
+// services reading executions
 type WorkflowExecution struct {
-	ID             string                 `json:"id" gorm:"primaryKey"`
-	WorkflowID     string                 `json:"workflowId" gorm:"not null;index"`
-	Version        int                    `json:"version"`
-	Status         string                 `json:"status" gorm:"default:'pending'"`
-	StartedAt      time.Time              `json:"startedAt"`
-	FinishedAt     *time.Time             `json:"finishedAt"`
-	ExecutionTime  int64                  `json:"executionTime"`
-	Data           map[string]interface{} `json:"data" gorm:"serializer:json"`
-	Error          string                 `json:"error"`
-	NodeExecutions []NodeExecution        `json:"nodeExecutions" gorm:"foreignKey:ExecutionID"`
-	CreatedBy      string                 `json:"createdBy"`
-	CreatedAt      time.Time              `json:"createdAt"`
+	ID               string                 `json:"id" gorm:"primaryKey"`
+	TenantID         string                 `json:"tenantId" gorm:"size:64;not null;default:'default';index"`
+	WorkflowID       string                 `json:"workflowId" gorm:"not null;index"`
+	Version          int                    `json:"version"`
+	WorkflowChecksum string                 `json:"workflowChecksum"`
+	EnvironmentID    string                 `json:"environmentId,omitempty"`
+	Bindings         *ExecutionBindings     `json:"bindings,omitempty" gorm:"serializer:json"`
+	Status           string                 `json:"status" gorm:"default:'pending'"`
+	StartedAt        time.Time              `json:"startedAt"`
+	FinishedAt       *time.Time             `json:"finishedAt"`
+	ExecutionTime    int64                  `json:"executionTime"`
+	Data             map[string]interface{} `json:"data" gorm:"serializer:json"`
+	Error            string                 `json:"error"`
+	NodeExecutions   []NodeExecution        `json:"nodeExecutions" gorm:"foreignKey:ExecutionID"`
+	CreatedBy        string                 `json:"createdBy"`
+	CreatedAt        time.Time              `json:"createdAt"`
 }
 
+// NodeExecution is the record of a run of a node within an execution
 type NodeExecution struct {
 	ID          string                 `json:"id" gorm:"primaryKey"`
 	ExecutionID string                 `json:"executionId" gorm:"not null;index"`
 	NodeID      string                 `json:"nodeId" gorm:"not null"`
+	NodeType    string                 `json:"nodeType"`
 	Status      string                 `json:"status"`
 	StartedAt   time.Time              `json:"startedAt"`
 	FinishedAt  *time.Time             `json:"finishedAt"`
@@ -134,6 +181,9 @@ const (
 	NodeTypeCode        = "code"
 	NodeTypeEmail       = "email"
 	NodeTypeSlack       = "slack"
+	NodeTypeValidate    = "validate"
+	NodeTypeMetric      = "metric"
+	NodeTypeTransform   = "transform"
 )
 
 // NewWorkflow creates a new workflow
@@ -190,6 +240,39 @@ func (w *Workflow) Validate() error {
 		return errors.New("workflow contains a cycle")
 	}
 
+	// Validate try/catch error boundaries
+	if err := w.ValidateErrorBoundaries(); err != nil {
+		return err
+	}
+
+	// Validate saga compensation nodes
+	if err := w.ValidateCompensations(); err != nil {
+		return err
+	}
+
+	// Validate per-node timeouts
+	if err := w.ValidateNodeTimeouts(); err != nil {
+		return err
+	}
+
+	if err := w.ValidateSensitiveFields(); err != nil {
+		return err
+	}
+
+	if err := w.ValidateAnnotations(); err != nil {
+		return err
+	}
+
+	if w.Settings.ResultCacheTTL < 0 {
+		return ErrInvalidResultCache.WithMessage("negative result cache TTL %d", w.Settings.ResultCacheTTL)
+	}
+
+	if w.Settings.Sampling != nil {
+		if err := w.Settings.Sampling.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -267,6 +350,7 @@ func (w *Workflow) Clone(newName string) *Workflow {
 		Nodes:       make([]Node, len(w.Nodes)),
 		Connections: make([]Connection, len(w.Connections)),
 		Settings:    w.Settings,
+		Annotations: make([]Annotation, len(w.Annotations)),
 		Status:      StatusInactive,
 		IsActive:    false,
 		Version:     1,
@@ -277,6 +361,7 @@ func (w *Workflow) Clone(newName string) *Workflow {
 
 	copy(clone.Nodes, w.Nodes)
 	copy(clone.Connections, w.Connections)
+	copy(clone.Annotations, w.Annotations)
 
 	return clone
 }
@@ -293,12 +378,14 @@ func (w *Workflow) ToJSON() (string, error) {
 // Request types for workflow operations
 type CreateWorkflowRequest struct {
 	UserID      string                 `json:"-"`
+	WorkspaceID string                 `json:"-"`
 	Name        string                 `json:"name" binding:"required"`
 	Description string                 `json:"description"`
 	Nodes       []Node                 `json:"nodes"`
 	Connections []Connection           `json:"connections"`
 	Settings    map[string]interface{} `json:"settings"`
 	Tags        []string               `json:"tags"`
+	Annotations []Annotation           `json:"annotations"`
 }
 
 type UpdateWorkflowRequest struct {
@@ -310,6 +397,7 @@ type UpdateWorkflowRequest struct {
 	Connections []Connection           `json:"connections"`
 	Settings    map[string]interface{} `json:"settings"`
 	Tags        []string               `json:"tags"`
+	Annotations []Annotation           `json:"annotations"`
 	Version     int                    `json:"version"`
 }
 
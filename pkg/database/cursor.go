@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCursor is returned for cursors not issued by PaginateCursor
+var ErrInvalidCursor = apperrors.New(apperrors.CategoryValidation, "INVALID_CURSOR", "invalid cursor")
+
+// CursorPage is the keyset counterpart of Pagination. Rows are ordered by
+// Sort and their id, a page starts after the row the cursor points to, so
+// deep pages cost the same as the first one where OFFSET scans every row it
+// skips.
+type CursorPage struct {
+	Limit int
+	// After is the cursor of the previous page, empty for the first one
+	After string
+	// Sort is the column ordering the rows, created_at unless set. It must
+	// not be NULL, rows with NULL would be skipped.
+	Sort string
+	Desc bool
+	// Next is set to the cursor of the following page, empty on the last one
+	Next string
+}
+
+// NewCursorPage returns the page of limit rows after cursor, newest first,
+// with limit defaulted and capped like the page sizes of the handlers
+func NewCursorPage(limit int, after string) *CursorPage {
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return &CursorPage{Limit: limit, After: after, Desc: true}
+}
+
+// cursor is the position of a row, the time or other value of its sort
+// column and its id breaking ties
+type cursor struct {
+	Time  *time.Time  `json:"t,omitempty"`
+	Value interface{} `json:"v,omitempty"`
+	ID    string      `json:"id"`
+}
+
+// CursorOf returns the cursor pointing after the row with the sort value
+// and id given, the cursor PaginateCursor would issue for it
+func CursorOf(value interface{}, id string) string {
+	c := cursor{ID: id}
+	switch v := value.(type) {
+	case time.Time:
+		c.Time = &v
+	case *time.Time:
+		c.Time = v
+	default:
+		c.Value = v
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (interface{}, string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, "", ErrInvalidCursor.Wrap(err)
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, "", ErrInvalidCursor.Wrap(err)
+	}
+	if c.ID == "" {
+		return nil, "", ErrInvalidCursor
+	}
+	if c.Time != nil {
+		return *c.Time, c.ID, nil
+	}
+	return c.Value, c.ID, nil
+}
+
+// PaginateCursor finds the rows of page matching query into dest, a pointer
+// to a slice of models, and sets page.Next. Ordering is left to page, query
+// must not order the rows itself.
+func (db *DB) PaginateCursor(ctx context.Context, dest interface{}, page *CursorPage, query *gorm.DB) error {
+	if query == nil {
+		query = db.DB
+	}
+	query = query.WithContext(ctx)
+
+	column := page.Sort
+	if column == "" {
+		column = "created_at"
+	}
+
+	if page.After != "" {
+		value, id, err := decodeCursor(page.After)
+		if err != nil {
+			return err
+		}
+		// (column, id) past the cursor, spelled out so any driver uses the
+		// index on column
+		var past, pastID clause.Expression = clause.Gt{Column: clause.Column{Name: column}, Value: value},
+			clause.Gt{Column: clause.Column{Name: "id"}, Value: id}
+		if page.Desc {
+			past, pastID = clause.Lt{Column: clause.Column{Name: column}, Value: value},
+				clause.Lt{Column: clause.Column{Name: "id"}, Value: id}
+		}
+		query = query.Where(clause.Or(past, clause.And(clause.Eq{Column: clause.Column{Name: column}, Value: value}, pastID)))
+	}
+
+	// One row more than the page tells whether another page follows
+	query = query.Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: column}, Desc: page.Desc},
+		{Column: clause.Column{Name: "id"}, Desc: page.Desc},
+	}})
+	if page.Limit > 0 {
+		query = query.Limit(page.Limit + 1)
+	}
+	if err := query.Find(dest).Error; err != nil {
+		return err
+	}
+
+	page.Next = ""
+	rows := reflect.ValueOf(dest).Elem()
+	if page.Limit <= 0 || rows.Len() <= page.Limit {
+		return nil
+	}
+	rows.SetLen(page.Limit)
+
+	stmt := &gorm.Statement{DB: query}
+	if err := stmt.Parse(dest); err != nil {
+		return err
+	}
+	sortField, idField := stmt.Schema.LookUpField(column), stmt.Schema.LookUpField("id")
+	if sortField == nil || idField == nil {
+		return fmt.Errorf("cannot page %s by %s", stmt.Schema.Table, column)
+	}
+	last := reflect.Indirect(rows.Index(page.Limit - 1))
+	value, _ := sortField.ValueOf(ctx, last)
+	id, _ := idField.ValueOf(ctx, last)
+	idString, _ := id.(string)
+	page.Next = CursorOf(value, idString)
+
+	return nil
+}
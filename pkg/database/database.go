@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/linkflow-go/pkg/tenant"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -14,7 +15,17 @@ type DB struct {
 	*gorm.DB
 }
 
+// Drivers selectable with Config.Driver
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 type Config struct {
+	// Driver is postgres unless set, sqlite keeps each schema in a file
+	// next to Path and is meant for local development
+	Driver       string
+	Path         string
 	Host         string
 	Port         int
 	User         string
@@ -26,9 +37,6 @@ type Config struct {
 }
 
 func New(cfg Config) (*DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode)
-
 	gormConfig := &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 		NowFunc: func() time.Time {
@@ -37,11 +45,32 @@ func New(cfg Config) (*DB, error) {
 		QueryFields: true,
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case "", DriverPostgres:
+		dialector = postgres.Open(fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode))
+	case DriverSQLite:
+		var err error
+		if dialector, err = openSQLite(cfg.Path); err != nil {
+			return nil, err
+		}
+		// SQLite cannot reference tables of other attached files
+		gormConfig.DisableForeignKeyConstraintWhenMigrating = true
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+
+	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Confine the queries of tenant scoped requests to the rows of the tenant
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register tenant plugin: %w", err)
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database instance: %w", err)
@@ -79,7 +108,24 @@ func (db *DB) Transaction(fn func(*gorm.DB) error) error {
 	return db.DB.Transaction(fn)
 }
 
+// txKey carries the transaction started by RunInTx
+type txKey struct{}
+
+// RunInTx runs fn in a transaction. Queries made through WithContext with
+// the context passed to fn join the transaction, so repositories and the
+// event outbox commit or roll back together.
+func (db *DB) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// WithContext returns a session bound to ctx, inside the transaction of
+// RunInTx when ctx carries one
 func (db *DB) WithContext(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
 	return db.DB.WithContext(ctx)
 }
 
@@ -0,0 +1,64 @@
+package database
+
+import "fmt"
+
+// The helpers below return the SQL of the driver for the expressions
+// repositories write by hand. The expressions take the same arguments on
+// every driver, so queries keep a single argument list.
+
+// IsSQLite reports whether db is a SQLite database
+func (db *DB) IsSQLite() bool {
+	return db.Dialector.Name() == DriverSQLite
+}
+
+// ILike returns a case insensitive LIKE condition on column taking the
+// pattern as argument. LIKE of SQLite ignores the case of ASCII letters.
+func (db *DB) ILike(column string) string {
+	if db.IsSQLite() {
+		return column + " LIKE ?"
+	}
+	return column + " ILIKE ?"
+}
+
+// DateTrunc truncates the timestamp column to the start of its minute,
+// hour or day
+func (db *DB) DateTrunc(unit, column string) string {
+	if !db.IsSQLite() {
+		return fmt.Sprintf("date_trunc('%s', %s)", unit, column)
+	}
+
+	format := "%Y-%m-%d 00:00:00"
+	switch unit {
+	case "minute":
+		format = "%Y-%m-%d %H:%M:00"
+	case "hour":
+		format = "%Y-%m-%d %H:00:00"
+	}
+	return fmt.Sprintf("strftime('%s', %s)", format, column)
+}
+
+// Day returns the date of the timestamp column as YYYY-MM-DD text
+func (db *DB) Day(column string) string {
+	if db.IsSQLite() {
+		return fmt.Sprintf("strftime('%%Y-%%m-%%d', %s)", column)
+	}
+	return fmt.Sprintf("to_char(%s, 'YYYY-MM-DD')", column)
+}
+
+// JSONArrayElements returns a table of the elements of the JSON array
+// column, for use in FROM. Each element is in the value column of alias.
+func (db *DB) JSONArrayElements(column, alias string) string {
+	if db.IsSQLite() {
+		return fmt.Sprintf("json_each(%s) AS %s", column, alias)
+	}
+	return fmt.Sprintf("jsonb_array_elements(%s) AS %s(value)", column, alias)
+}
+
+// Percentile returns the continuous percentile aggregate of column. SQLite
+// has no percentile aggregate, the expression is NULL there.
+func (db *DB) Percentile(fraction float64, column string) string {
+	if db.IsSQLite() {
+		return "NULL"
+	}
+	return fmt.Sprintf("PERCENTILE_CONT(%g) WITHIN GROUP (ORDER BY %s)", fraction, column)
+}
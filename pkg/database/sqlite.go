@@ -0,0 +1,256 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	sqlitedriver "github.com/glebarez/go-sqlite"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Schemas are attached to every SQLite connection, so the schema qualified
+// table names of the services resolve as they do on Postgres. SQLite
+// attaches at most 10 databases, the schemas of the other services are left
+// out.
+var Schemas = []string{
+	"audit", "auth", "billing", "credential", "execution", "node", "schedule",
+	"variable", "webhook", "workflow",
+}
+
+// sqliteBusyTimeout is how long a connection waits for the write lock held
+// by another service of the process
+const sqliteBusyTimeout = 5000 // milliseconds
+
+var registerFunctions sync.Once
+
+// openSQLite opens path as the main database and each schema as a file
+// named after it, e.g. linkflow.db attaches linkflow.auth.db as auth
+func openSQLite(path string) (gorm.Dialector, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite database needs a path")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	// Postgres functions used in queries of the services
+	registerFunctions.Do(func() {
+		sqlitedriver.MustRegisterScalarFunction("now", 0, func(*sqlitedriver.FunctionContext, []driver.Value) (driver.Value, error) {
+			return time.Now().UTC(), nil
+		})
+	})
+
+	ext := filepath.Ext(path)
+	var attach []string
+	for _, schema := range Schemas {
+		file := strings.TrimSuffix(path, ext) + "." + schema + ext
+		attach = append(attach,
+			fmt.Sprintf("ATTACH DATABASE '%s' AS %s", strings.ReplaceAll(file, "'", "''"), schema),
+			fmt.Sprintf("PRAGMA %s.journal_mode = WAL", schema))
+	}
+
+	base, err := sql.Open(sqlite.DriverName, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	connector := &attachConnector{
+		driver: base.Driver(),
+		dsn:    fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)", path, sqliteBusyTimeout),
+		attach: attach,
+	}
+	base.Close()
+
+	return sqliteDialector{&sqlite.Dialector{Conn: sql.OpenDB(connector)}}, nil
+}
+
+// attachConnector attaches the schemas to each new connection, attachments
+// do not outlive the connection that made them
+type attachConnector struct {
+	driver driver.Driver
+	dsn    string
+	attach []string
+}
+
+func (c *attachConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("sqlite driver cannot execute statements")
+	}
+	for _, stmt := range c.attach {
+		if _, err := execer.ExecContext(ctx, stmt, nil); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to run %q: %w", stmt, err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *attachConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// sqliteDialector hands out a migrator that understands attached schemas
+type sqliteDialector struct {
+	*sqlite.Dialector
+}
+
+// Initialize has inserts name the table with its schema, the INSERT clause
+// of the driver writes the bare table name, which resolves to the first
+// attached schema holding a table of that name
+func (d sqliteDialector) Initialize(db *gorm.DB) error {
+	if err := d.Dialector.Initialize(db); err != nil {
+		return err
+	}
+	insert := db.ClauseBuilders["INSERT"]
+	db.ClauseBuilders["INSERT"] = func(c clause.Clause, builder clause.Builder) {
+		if expr, ok := c.Expression.(clause.Insert); ok && expr.Table.Name == "" {
+			expr.Table = clause.Table{Name: clause.CurrentTable}
+			c.Expression = expr
+		}
+		insert(c, builder)
+	}
+	return nil
+}
+
+func (d sqliteDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return sqliteMigrator{d.Dialector.Migrator(db).(sqlite.Migrator)}
+}
+
+// sqliteMigrator looks tables and indexes up in the catalog of their schema
+// and creates indexes with the schema on the index name, as SQLite expects
+type sqliteMigrator struct {
+	sqlite.Migrator
+}
+
+// AutoMigrate creates the missing tables of the models and their join
+// tables, and adds the missing columns of existing tables. Existing columns
+// are left as they are, SQLite cannot alter them.
+func (m sqliteMigrator) AutoMigrate(values ...interface{}) error {
+	for _, value := range values {
+		stmt := &gorm.Statement{DB: m.DB}
+		if err := stmt.Parse(value); err != nil {
+			return err
+		}
+
+		if !m.HasTable(value) {
+			if err := m.CreateTable(value); err != nil {
+				return err
+			}
+		} else {
+			for _, field := range stmt.Schema.Fields {
+				if field.DBName == "" || m.HasColumn(value, field.DBName) {
+					continue
+				}
+				if err := m.AddColumn(value, field.DBName); err != nil {
+					return err
+				}
+			}
+		}
+		for _, rel := range stmt.Schema.Relationships.Relations {
+			if rel.JoinTable == nil || m.HasTable(rel.JoinTable.Table) {
+				continue
+			}
+			joinTable := reflect.New(rel.JoinTable.ModelType).Interface()
+			if err := m.DB.Table(rel.JoinTable.Table).Migrator().CreateTable(joinTable); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m sqliteMigrator) HasTable(value interface{}) bool {
+	var count int
+	m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		schema, table := tableName(stmt)
+		return m.DB.Raw("SELECT count(*) FROM "+schema+".sqlite_master WHERE type = 'table' AND name = ?", table).
+			Row().Scan(&count)
+	})
+	return count > 0
+}
+
+func (m sqliteMigrator) HasColumn(value interface{}, name string) bool {
+	var count int
+	m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if field := stmt.Schema.LookUpField(name); field != nil {
+			name = field.DBName
+		}
+		schema, table := tableName(stmt)
+		return m.DB.Raw("SELECT count(*) FROM pragma_table_info(?, ?) WHERE name = ?", table, schema, name).
+			Row().Scan(&count)
+	})
+	return count > 0
+}
+
+func (m sqliteMigrator) HasIndex(value interface{}, name string) bool {
+	var count int
+	m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if idx := stmt.Schema.LookIndex(name); idx != nil {
+			name = idx.Name
+		}
+		schema, table := tableName(stmt)
+		return m.DB.Raw("SELECT count(*) FROM "+schema+".sqlite_master WHERE type = 'index' AND tbl_name = ? AND name = ?", table, name).
+			Row().Scan(&count)
+	})
+	return count > 0
+}
+
+func (m sqliteMigrator) CreateIndex(value interface{}, name string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		idx := stmt.Schema.LookIndex(name)
+		if idx == nil {
+			return fmt.Errorf("failed to create index with name %v", name)
+		}
+
+		schema, table := tableName(stmt)
+		sql := "CREATE "
+		if idx.Class != "" {
+			sql += idx.Class + " "
+		}
+		sql += "INDEX IF NOT EXISTS ? ON ??"
+		if idx.Where != "" {
+			sql += " WHERE " + idx.Where
+		}
+
+		return m.DB.Exec(sql,
+			clause.Table{Name: schema + "." + idx.Name},
+			clause.Table{Name: table},
+			m.BuildIndexOptions(idx.Fields, stmt),
+		).Error
+	})
+}
+
+// tableName returns the schema and table a statement targets, gorm keeps
+// the schema only in TableExpr once it split a qualified name
+func tableName(stmt *gorm.Statement) (string, string) {
+	name := stmt.Table
+	if stmt.TableExpr != nil {
+		name = strings.NewReplacer("`", "", `"`, "").Replace(stmt.TableExpr.SQL)
+	}
+	return splitTable(name)
+}
+
+// splitTable separates the schema of a table name, unqualified tables live
+// in the main database
+func splitTable(name string) (string, string) {
+	if schema, table, ok := strings.Cut(name, "."); ok {
+		return schema, table
+	}
+	return "main", name
+}
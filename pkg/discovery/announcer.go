@@ -0,0 +1,191 @@
+package discovery
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/version"
+	"github.com/redis/go-redis/v9"
+)
+
+// Metadata keys every announced instance carries
+const (
+	MetadataVersion   = "version"
+	MetadataCommit    = "commit"
+	MetadataBuildTime = "buildTime"
+	MetadataStartedAt = "startedAt"
+	MetadataEventBus  = "eventBus"
+	// MetadataFeatures lists the enabled features, comma separated
+	MetadataFeatures = "features"
+	// MetadataWorkers is the worker pool size of executor instances
+	MetadataWorkers = "workers"
+	// MetadataDesiredReplicas is the replica count the deployment asks for
+	MetadataDesiredReplicas = "desiredReplicas"
+	// MetadataAddress is the IP other services reach the instance at
+	MetadataAddress = "address"
+)
+
+// deploymentEnv maps metadata keys to the environment variables the Helm
+// chart sets on every pod
+var deploymentEnv = map[string]string{
+	"release":               "LINKFLOW_RELEASE",
+	"chart":                 "LINKFLOW_CHART",
+	"namespace":             "LINKFLOW_NAMESPACE",
+	"node":                  "LINKFLOW_NODE_NAME",
+	MetadataDesiredReplicas: "LINKFLOW_DESIRED_REPLICAS",
+	MetadataAddress:         "LINKFLOW_POD_IP",
+}
+
+// Announcer registers the running instance of a service and keeps it alive
+// with heartbeats, so the gateway can report what is running
+type Announcer struct {
+	discovery *RedisDiscovery
+	client    *redis.Client
+	logger    logger.Logger
+	interval  time.Duration
+
+	mu       sync.Mutex
+	instance *ServiceInstance
+
+	stopCh chan struct{}
+	done   chan struct{}
+}
+
+// NewAnnouncer describes the instance of service from its config and build
+func NewAnnouncer(cfg *config.Config, service string, log logger.Logger) *Announcer {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	host, _ := os.Hostname()
+	instance := &ServiceInstance{
+		// Pod names repeat across restarts of a StatefulSet, the suffix
+		// tells the restarted instance apart
+		ID:   host + "-" + uuid.New().String()[:8],
+		Name: service,
+		Host: host,
+		Port: cfg.Server.Port,
+		Metadata: map[string]string{
+			MetadataVersion:   version.Version,
+			MetadataCommit:    version.CommitHash,
+			MetadataBuildTime: version.BuildTime,
+			MetadataStartedAt: time.Now().UTC().Format(time.RFC3339),
+			MetadataEventBus:  cfg.Kafka.Backend,
+			MetadataFeatures:  strings.Join(Features(cfg), ","),
+		},
+	}
+
+	for key, env := range deploymentEnv {
+		if value := os.Getenv(env); value != "" {
+			instance.Metadata[key] = value
+		}
+	}
+
+	return &Announcer{
+		discovery: NewRedisDiscovery(client, DefaultInstanceTTL),
+		client:    client,
+		logger:    log,
+		interval:  DefaultInstanceTTL / 3,
+		instance:  instance,
+		stopCh:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// SetMetadata adds or replaces a metadata entry, announced with the next
+// heartbeat
+func (a *Announcer) SetMetadata(key, value string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Copy so an announcement in flight keeps a consistent instance
+	next := *a.instance
+	next.Metadata = make(map[string]string, len(a.instance.Metadata)+1)
+	for k, v := range a.instance.Metadata {
+		next.Metadata[k] = v
+	}
+	next.Metadata[key] = value
+	a.instance = &next
+}
+
+// Start announces the instance in the background. Redis being unavailable
+// only hides the instance from the topology, it never fails the service.
+func (a *Announcer) Start() {
+	go func() {
+		defer close(a.done)
+
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+
+		failing := false
+		for {
+			// Register stamps the instance, announce a copy
+			a.mu.Lock()
+			instance := *a.instance
+			a.mu.Unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), a.interval)
+			err := a.discovery.Register(ctx, &instance)
+			cancel()
+			switch {
+			case err != nil && !failing:
+				a.logger.Warn("Failed to announce instance, retrying", "instance", instance.ID, "error", err)
+				failing = true
+			case err == nil && failing:
+				a.logger.Info("Instance announced", "instance", instance.ID)
+				failing = false
+			}
+
+			select {
+			case <-a.stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop deregisters the instance so it leaves the topology right away
+func (a *Announcer) Stop() {
+	close(a.stopCh)
+	<-a.done
+
+	a.mu.Lock()
+	id := a.instance.ID
+	a.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.discovery.Deregister(ctx, id); err != nil {
+		a.logger.Warn("Failed to deregister instance", "instance", id, "error", err)
+	}
+	a.client.Close()
+}
+
+// Features lists the optional features enabled by a config
+func Features(cfg *config.Config) []string {
+	enabled := map[string]bool{
+		"tracing":           cfg.Telemetry.Enabled,
+		"vault_credentials": cfg.Credential.Backend == "vault",
+		"config_watch":      cfg.Reload.WatchFile,
+		"admin_api":         cfg.Server.AdminPort != 0 && cfg.Server.AdminToken != "",
+	}
+
+	var features []string
+	for name, on := range enabled {
+		if on {
+			features = append(features, name)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
@@ -0,0 +1,182 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisServicesKey = "discovery:services"
+	redisInstanceKey = "discovery:instances:%s"
+)
+
+// DefaultInstanceTTL is how long an instance stays discoverable without a
+// heartbeat
+const DefaultInstanceTTL = 45 * time.Second
+
+// RedisDiscovery keeps the instances of each service in a Redis hash so
+// every replica of every service shares one view. Instances missing their
+// heartbeat for longer than the TTL are dropped on read.
+type RedisDiscovery struct {
+	client *redis.Client
+	ttl    time.Duration
+
+	mu sync.Mutex
+	// registered are the instances of this process, kept to heartbeat them
+	registered map[string]*ServiceInstance
+}
+
+// NewRedisDiscovery creates a discovery backed by client
+func NewRedisDiscovery(client *redis.Client, ttl time.Duration) *RedisDiscovery {
+	if ttl <= 0 {
+		ttl = DefaultInstanceTTL
+	}
+	return &RedisDiscovery{
+		client:     client,
+		ttl:        ttl,
+		registered: make(map[string]*ServiceInstance),
+	}
+}
+
+func (d *RedisDiscovery) Register(ctx context.Context, instance *ServiceInstance) error {
+	instance.LastSeen = time.Now().UTC()
+	instance.Health = HealthHealthy
+
+	if err := d.write(ctx, instance); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.registered[instance.ID] = instance
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *RedisDiscovery) Deregister(ctx context.Context, instanceID string) error {
+	d.mu.Lock()
+	instance, ok := d.registered[instanceID]
+	delete(d.registered, instanceID)
+	d.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	return d.client.HDel(ctx, fmt.Sprintf(redisInstanceKey, instance.Name), instanceID).Err()
+}
+
+func (d *RedisDiscovery) Discover(ctx context.Context, serviceName string) ([]*ServiceInstance, error) {
+	key := fmt.Sprintf(redisInstanceKey, serviceName)
+	entries, err := d.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances of %s: %w", serviceName, err)
+	}
+
+	var instances []*ServiceInstance
+	var expired []string
+	for id, data := range entries {
+		var instance ServiceInstance
+		if err := json.Unmarshal([]byte(data), &instance); err != nil {
+			expired = append(expired, id)
+			continue
+		}
+		if time.Since(instance.LastSeen) > d.ttl {
+			expired = append(expired, id)
+			continue
+		}
+		instances = append(instances, &instance)
+	}
+
+	// Instances that crashed never deregister, clean them up
+	if len(expired) > 0 {
+		d.client.HDel(ctx, key, expired...)
+	}
+	return instances, nil
+}
+
+// Services lists the names of all services that ever registered
+func (d *RedisDiscovery) Services(ctx context.Context) ([]string, error) {
+	names, err := d.client.SMembers(ctx, redisServicesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	return names, nil
+}
+
+// Watch polls the instances of a service and sends them whenever they change
+func (d *RedisDiscovery) Watch(ctx context.Context, serviceName string) (<-chan []*ServiceInstance, error) {
+	ch := make(chan []*ServiceInstance, 10)
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(d.ttl / 3)
+		defer ticker.Stop()
+
+		var last []string
+		for {
+			if instances, err := d.Discover(ctx, serviceName); err == nil {
+				ids := make([]string, len(instances))
+				for i, instance := range instances {
+					ids[i] = instance.ID
+				}
+				sort.Strings(ids)
+				if !reflect.DeepEqual(ids, last) {
+					last = ids
+					select {
+					case ch <- instances:
+					default:
+					}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (d *RedisDiscovery) Heartbeat(ctx context.Context, instanceID string) error {
+	d.mu.Lock()
+	instance, ok := d.registered[instanceID]
+	if ok {
+		instance.LastSeen = time.Now().UTC()
+	}
+	d.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("instance %s is not registered", instanceID)
+	}
+
+	// Rewriting the whole instance also restores it after a Redis flush
+	return d.write(ctx, instance)
+}
+
+func (d *RedisDiscovery) write(ctx context.Context, instance *ServiceInstance) error {
+	d.mu.Lock()
+	data, err := json.Marshal(instance)
+	d.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal instance: %w", err)
+	}
+
+	_, err = d.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, redisServicesKey, instance.Name)
+		pipe.HSet(ctx, fmt.Sprintf(redisInstanceKey, instance.Name), instance.ID, data)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register instance %s: %w", instance.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/linkflow-go/migrations"
+	"github.com/linkflow-go/pkg/database"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Clock skew above these breaks token expiry, schedules and leases
+const (
+	clockSkewWarn = time.Second
+	clockSkewFail = 30 * time.Second
+)
+
+func (d *Doctor) checkDatabase(ctx context.Context) []Finding {
+	if d.cfg.Database.Driver == database.DriverSQLite {
+		db, err := database.New(d.cfg.Database.ToDatabaseConfig())
+		if err != nil {
+			return []Finding{fail(envHint("check", "database.path"), "cannot open %s: %v", d.cfg.Database.Path, err)}
+		}
+		d.db.Store(db)
+		return []Finding{ok("opened SQLite database %s", d.cfg.Database.Path)}
+	}
+
+	db, err := database.New(d.cfg.Database.ToDatabaseConfig())
+	if err != nil {
+		return []Finding{fail(envHint("check PostgreSQL is running and",
+			"database.host", "database.port", "database.user", "database.password", "database.name"),
+			"cannot connect to %s:%d/%s: %v", d.cfg.Database.Host, d.cfg.Database.Port, d.cfg.Database.Name, err)}
+	}
+	d.db.Store(db)
+
+	var version string
+	if err := d.query(ctx).Raw("SHOW server_version").Scan(&version).Error; err != nil {
+		return []Finding{warn("", "connected but the server version is unknown: %v", err)}
+	}
+	return []Finding{ok("connected to PostgreSQL %s at %s:%d/%s",
+		version, d.cfg.Database.Host, d.cfg.Database.Port, d.cfg.Database.Name)}
+}
+
+// checkMigrations compares the schema version recorded by golang-migrate
+// with the migrations this binary was built with
+func (d *Doctor) checkMigrations(ctx context.Context) []Finding {
+	var state struct {
+		Version int
+		Dirty   bool
+	}
+	err := d.query(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&state).Error
+	if err != nil {
+		return []Finding{fail(migrateHint(d.cfg.Database.Driver), "no migration state found: %v", err)}
+	}
+
+	latest := migrations.Latest()
+	switch {
+	case state.Dirty:
+		return []Finding{fail(fmt.Sprintf("fix the schema by hand, then `make migrate-force V=%d` and `make migrate-up`", state.Version),
+			"migration %d failed halfway, the schema is dirty", state.Version)}
+	case state.Version < latest:
+		return []Finding{fail(migrateHint(d.cfg.Database.Driver),
+			"schema is at version %d, this build expects %d", state.Version, latest)}
+	case state.Version > latest:
+		return []Finding{warn("upgrade the service, it may not know newer tables and columns",
+			"schema is at version %d, newer than this build (%d)", state.Version, latest)}
+	}
+	return []Finding{ok("schema is at the latest version %d", latest)}
+}
+
+// migrateHint tells how the schema of driver is brought up to date
+func migrateHint(driver string) string {
+	if driver == database.DriverSQLite {
+		return "run `linkflow migrate`"
+	}
+	return "run `make migrate-up`"
+}
+
+// checkSchemaPermissions verifies the database user can use the schema of
+// the service and read and write all of its tables
+func (d *Doctor) checkSchemaPermissions(ctx context.Context) []Finding {
+	schema := d.needs.Schema
+	user := d.cfg.Database.User
+
+	var usage bool
+	err := d.query(ctx).Raw("SELECT has_schema_privilege(current_user, ?, 'USAGE')", schema).Scan(&usage).Error
+	if err != nil {
+		return []Finding{fail("run `make migrate-up` to create the schema", "schema %s is missing: %v", schema, err)}
+	}
+	if !usage {
+		return []Finding{fail(fmt.Sprintf("GRANT USAGE ON SCHEMA %s TO %s", schema, user),
+			"user %s cannot use schema %s", user, schema)}
+	}
+
+	var denied []string
+	err = d.query(ctx).Raw(`
+		SELECT table_name FROM information_schema.tables
+		WHERE table_schema = ? AND table_type = 'BASE TABLE'
+		AND NOT has_table_privilege(current_user,
+			quote_ident(table_schema) || '.' || quote_ident(table_name),
+			'SELECT, INSERT, UPDATE, DELETE')
+		ORDER BY table_name`, schema).Scan(&denied).Error
+	if err != nil {
+		return []Finding{warn("", "table privileges could not be checked: %v", err)}
+	}
+	if len(denied) > 0 {
+		return []Finding{fail(
+			fmt.Sprintf("GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA %s TO %s", schema, user),
+			"user %s cannot read and write %s tables: %s", user, schema, strings.Join(denied, ", "))}
+	}
+	return []Finding{ok("user %s can read and write schema %s", user, schema)}
+}
+
+func (d *Doctor) checkRedis(ctx context.Context) []Finding {
+	client := redis.NewClient(&redis.Options{
+		Addr:     d.cfg.Redis.Addr(),
+		Password: d.cfg.Redis.Password,
+		DB:       d.cfg.Redis.DB,
+	})
+	defer client.Close()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return []Finding{fail(envHint("check Redis is running and", "redis.host", "redis.port", "redis.password"),
+			"cannot reach %s: %v", d.cfg.Redis.Addr(), err)}
+	}
+
+	// Services cache, lock and rate limit in Redis, ACLs must allow writes
+	key := fmt.Sprintf("linkflow:doctor:%s", d.service)
+	if err := client.Set(ctx, key, "1", time.Minute).Err(); err != nil {
+		return []Finding{fail("allow the Redis user to write keys (ACL +@write)",
+			"connected to %s but cannot write: %v", d.cfg.Redis.Addr(), err)}
+	}
+	client.Del(ctx, key)
+
+	return []Finding{ok("connected to %s db %d, writable", d.cfg.Redis.Addr(), d.cfg.Redis.DB)}
+}
+
+func (d *Doctor) checkEventBus(ctx context.Context) []Finding {
+	switch d.cfg.Kafka.Backend {
+	case events.BackendMemory:
+		return []Finding{ok("events are delivered within the process")}
+	case events.BackendNATS:
+		return d.checkNATS(ctx)
+	}
+
+	bus, err := events.NewKafkaEventBus(d.cfg.Kafka.ToKafkaConfig())
+	if err != nil {
+		return []Finding{fail(envHint("check", "kafka.brokers"), "cannot create the event bus: %v", err)}
+	}
+	defer bus.Close()
+
+	if err := bus.Ping(ctx); err != nil {
+		return []Finding{fail(envHint("check Kafka is running and", "kafka.brokers"),
+			"cannot reach %s: %v", strings.Join(d.cfg.Kafka.Brokers, ", "), err)}
+	}
+	return []Finding{ok("reached a Kafka broker of %s", strings.Join(d.cfg.Kafka.Brokers, ", "))}
+}
+
+func (d *Doctor) checkNATS(ctx context.Context) []Finding {
+	url := d.cfg.Kafka.NATS.URL
+	bus, err := events.NewNATSEventBus(d.cfg.Kafka.ToKafkaConfig())
+	if err != nil {
+		return []Finding{fail(envHint("check NATS is running and", "kafka.nats.url"), "cannot reach %s: %v", url, err)}
+	}
+	defer bus.Close()
+
+	if err := bus.Ping(ctx); err != nil {
+		return []Finding{fail("enable JetStream on the NATS server (nats-server -js)", "connected to %s: %v", url, err)}
+	}
+	return []Finding{ok("connected to %s with JetStream", url)}
+}
+
+func (d *Doctor) checkElasticsearch(ctx context.Context) []Finding {
+	es := d.cfg.Elasticsearch
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, es.URL, nil)
+	if err != nil {
+		return []Finding{fail(envHint("check", "elasticsearch.url"), "invalid URL %q: %v", es.URL, err)}
+	}
+	if es.Username != "" {
+		req.SetBasicAuth(es.Username, es.Password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return []Finding{fail(envHint("check Elasticsearch is running and", "elasticsearch.url"),
+			"cannot reach %s: %v", es.URL, err)}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return []Finding{fail(envHint("check", "elasticsearch.username", "elasticsearch.password"),
+			"%s rejected the credentials with status %d", es.URL, resp.StatusCode)}
+	case resp.StatusCode >= http.StatusBadRequest:
+		return []Finding{fail("", "%s answered with status %d", es.URL, resp.StatusCode)}
+	}
+	return []Finding{ok("reached %s", es.URL)}
+}
+
+func (d *Doctor) checkObjectStorage(ctx context.Context) []Finding {
+	sess, err := session.NewSession(&aws.Config{
+		Region:   aws.String(d.cfg.Storage.Region),
+		Endpoint: aws.String(d.cfg.Storage.Endpoint),
+	})
+	if err != nil {
+		return []Finding{fail(envHint("check", "storage.endpoint", "storage.region"), "invalid storage config: %v", err)}
+	}
+
+	out, err := s3.New(sess).ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		return []Finding{fail("check the endpoint is reachable and AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY may list buckets",
+			"cannot list buckets at %s: %v", d.cfg.Storage.Endpoint, err)}
+	}
+	return []Finding{ok("listed %d buckets at %s", len(out.Buckets), d.cfg.Storage.Endpoint)}
+}
+
+// checkSecretBackend verifies Vault is unsealed and the token is valid when
+// credentials are stored in Vault
+func (d *Doctor) checkSecretBackend(ctx context.Context) []Finding {
+	cred := d.cfg.Credential
+	if cred.Backend != "vault" {
+		return []Finding{ok("credentials are encrypted in the database")}
+	}
+
+	vault := strings.TrimRight(cred.Vault.Address, "/")
+	status, err := d.vaultGet(ctx, vault+"/v1/sys/health", "")
+	if err != nil {
+		return []Finding{fail(envHint("check Vault is running and", "credential.vault.address"),
+			"cannot reach %s: %v", vault, err)}
+	}
+	if status >= http.StatusInternalServerError {
+		return []Finding{fail("unseal Vault or point to an active node",
+			"%s is sealed or uninitialized (status %d)", vault, status)}
+	}
+
+	status, err = d.vaultGet(ctx, vault+"/v1/auth/token/lookup-self", cred.Vault.Token)
+	if err != nil {
+		return []Finding{fail("", "cannot look up the token at %s: %v", vault, err)}
+	}
+	if status != http.StatusOK {
+		return []Finding{fail(envHint("renew the token or set", "credential.vault.token"),
+			"%s rejected the token (status %d)", vault, status)}
+	}
+	return []Finding{ok("%s is unsealed and the token is valid", vault)}
+}
+
+func (d *Doctor) vaultGet(ctx context.Context, url, token string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// checkClock compares the local clock with the database and Redis, the
+// round trip is split evenly between both directions
+func (d *Doctor) checkClock(ctx context.Context) []Finding {
+	var findings []Finding
+
+	if db := d.db.Load(); db != nil {
+		var remote time.Time
+		sent := time.Now()
+		err := d.query(ctx).Raw("SELECT now()").Scan(&remote).Error
+		if err == nil {
+			findings = append(findings, clockFinding("database", remote, sent, time.Now()))
+		}
+	}
+
+	if d.needs.Redis {
+		client := redis.NewClient(&redis.Options{Addr: d.cfg.Redis.Addr(), Password: d.cfg.Redis.Password})
+		defer client.Close()
+
+		sent := time.Now()
+		remote, err := client.Time(ctx).Result()
+		if err == nil {
+			findings = append(findings, clockFinding("redis", remote, sent, time.Now()))
+		}
+	}
+
+	if len(findings) == 0 {
+		return []Finding{{Severity: SeveritySkip, Message: "no database or Redis to compare the clock with"}}
+	}
+	return findings
+}
+
+func clockFinding(source string, remote, sent, received time.Time) Finding {
+	local := sent.Add(received.Sub(sent) / 2)
+	skew := remote.Sub(local)
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+
+	hint := "sync the clocks with NTP, tokens, schedules and leases depend on them"
+	switch {
+	case abs > clockSkewFail:
+		return fail(hint, "%s clock is off by %s", source, skew.Round(time.Millisecond))
+	case abs > clockSkewWarn:
+		return warn(hint, "%s clock is off by %s", source, skew.Round(time.Millisecond))
+	}
+	return ok("in sync with %s (%s)", source, skew.Round(time.Millisecond))
+}
+
+// query runs doctor queries without the SQL logging of the service
+func (d *Doctor) query(ctx context.Context) *gorm.DB {
+	return d.db.Load().Session(&gorm.Session{Logger: gormlogger.Discard}).WithContext(ctx)
+}
@@ -0,0 +1,215 @@
+// Package doctor implements the `doctor` subcommand of the service binaries.
+// It checks the config and everything a service needs at runtime, printing
+// what is wrong and how to fix it.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/database"
+)
+
+// DefaultTimeout bounds each check
+const DefaultTimeout = 10 * time.Second
+
+// Severity of a finding
+type Severity string
+
+const (
+	SeverityOK   Severity = "ok"
+	SeverityWarn Severity = "warn"
+	SeverityFail Severity = "fail"
+	SeveritySkip Severity = "skip"
+)
+
+// Finding is the outcome of a check with a hint on how to fix it
+type Finding struct {
+	Check    string
+	Severity Severity
+	Message  string
+	Hint     string
+}
+
+// Requirements lists the dependencies of a service
+type Requirements struct {
+	// Schema is the database schema the service owns, empty if it uses no
+	// database
+	Schema        string
+	Redis         bool
+	EventBus      bool
+	Elasticsearch bool
+	ObjectStorage bool
+	SecretBackend bool
+}
+
+// Doctor runs the checks of one service
+type Doctor struct {
+	service string
+	needs   Requirements
+	timeout time.Duration
+	out     io.Writer
+
+	cfg *config.Config
+	// db is set by the database check, which may outlive its timeout
+	db       atomic.Pointer[database.DB]
+	findings []Finding
+}
+
+// Run implements `<service> doctor [-timeout 10s]` and returns the exit code,
+// 1 if any check failed
+func Run(service string, args []string, needs Requirements) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	timeout := flags.Duration("timeout", DefaultTimeout, "timeout of each check")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	d := &Doctor{service: service, needs: needs, timeout: *timeout, out: os.Stdout}
+	if d.Check(context.Background()) {
+		return 0
+	}
+	return 1
+}
+
+// Check runs every check and prints the findings, reporting whether all of
+// them passed
+func (d *Doctor) Check(ctx context.Context) bool {
+	fmt.Fprintf(d.out, "linkflow doctor: %s\n\n", d.service)
+
+	if !d.checkConfig() {
+		// Nothing else can be checked without a config
+		return d.summary()
+	}
+
+	if d.needs.Schema != "" {
+		d.run(ctx, "database", d.checkDatabase)
+		if db := d.db.Load(); db != nil {
+			defer db.Close()
+			d.run(ctx, "migrations", d.checkMigrations)
+			// SQLite has no users to grant privileges to
+			if d.cfg.Database.Driver != database.DriverSQLite {
+				d.run(ctx, "permissions", d.checkSchemaPermissions)
+			}
+		}
+	}
+	if d.needs.Redis {
+		d.run(ctx, "redis", d.checkRedis)
+	}
+	if d.needs.EventBus {
+		d.run(ctx, "event_bus", d.checkEventBus)
+	}
+	if d.needs.Elasticsearch {
+		d.run(ctx, "elasticsearch", d.checkElasticsearch)
+	}
+	if d.needs.ObjectStorage {
+		d.run(ctx, "object_storage", d.checkObjectStorage)
+	}
+	if d.needs.SecretBackend {
+		d.run(ctx, "secret_backend", d.checkSecretBackend)
+	}
+	d.run(ctx, "clock", d.checkClock)
+
+	return d.summary()
+}
+
+// checkConfig loads and validates the config, listing every invalid field
+func (d *Doctor) checkConfig() bool {
+	cfg, err := config.Load(d.service)
+	if err != nil {
+		var invalid *config.ValidationError
+		if errors.As(err, &invalid) {
+			for _, issue := range invalid.Issues {
+				d.add(Finding{Check: "config", Severity: SeverityFail, Message: issue})
+			}
+		} else {
+			d.add(Finding{Check: "config", Severity: SeverityFail, Message: err.Error(),
+				Hint: "check the YAML syntax of the config file"})
+		}
+		return false
+	}
+
+	d.cfg = cfg
+	d.add(Finding{Check: "config", Severity: SeverityOK, Message: "valid, loaded from " + configSource()})
+	return true
+}
+
+// run executes a check with a timeout, a check that does not return in
+// time fails
+func (d *Doctor) run(ctx context.Context, name string, check func(ctx context.Context) []Finding) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	done := make(chan []Finding, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	select {
+	case findings := <-done:
+		for _, f := range findings {
+			f.Check = name
+			d.add(f)
+		}
+	case <-ctx.Done():
+		d.add(Finding{Check: name, Severity: SeverityFail,
+			Message: fmt.Sprintf("no answer within %s", d.timeout),
+			Hint:    "check the address is reachable from this host, or raise -timeout"})
+	}
+}
+
+func (d *Doctor) add(f Finding) {
+	d.findings = append(d.findings, f)
+
+	fmt.Fprintf(d.out, "  %-6s %-15s %s\n", "["+string(f.Severity)+"]", f.Check, f.Message)
+	if f.Hint != "" && f.Severity != SeverityOK {
+		fmt.Fprintf(d.out, "  %-6s %-15s -> %s\n", "", "", f.Hint)
+	}
+}
+
+func (d *Doctor) summary() bool {
+	counts := make(map[Severity]int)
+	for _, f := range d.findings {
+		counts[f.Severity]++
+	}
+
+	fmt.Fprintf(d.out, "\n%d ok, %d warnings, %d failures\n",
+		counts[SeverityOK], counts[SeverityWarn], counts[SeverityFail])
+	return counts[SeverityFail] == 0
+}
+
+func configSource() string {
+	if file := config.FileUsed(); file != "" {
+		return file
+	}
+	return "defaults and environment (no config file found)"
+}
+
+func ok(format string, args ...interface{}) Finding {
+	return Finding{Severity: SeverityOK, Message: fmt.Sprintf(format, args...)}
+}
+
+func warn(hint, format string, args ...interface{}) Finding {
+	return Finding{Severity: SeverityWarn, Message: fmt.Sprintf(format, args...), Hint: hint}
+}
+
+func fail(hint, format string, args ...interface{}) Finding {
+	return Finding{Severity: SeverityFail, Message: fmt.Sprintf(format, args...), Hint: hint}
+}
+
+// envHint names the settings and their environment variables
+func envHint(prefix string, keys ...string) string {
+	vars := make([]string, len(keys))
+	for i, key := range keys {
+		vars[i] = "LINKFLOW_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	}
+	return fmt.Sprintf("%s %s (%s)", prefix, strings.Join(keys, ", "), strings.Join(vars, ", "))
+}
@@ -0,0 +1,166 @@
+// Package errors defines coded, categorized errors shared by all services.
+// Clients branch on Code, which is stable, rather than on Message text.
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Category groups error codes by how callers should react to them
+type Category string
+
+const (
+	CategoryValidation Category = "validation"
+	CategoryAuth       Category = "auth"
+	CategoryPermission Category = "permission"
+	CategoryNotFound   Category = "not_found"
+	CategoryConflict   Category = "conflict"
+	CategoryRateLimit  Category = "rate_limit"
+	CategoryUpstream   Category = "upstream"
+	CategoryInternal   Category = "internal"
+)
+
+// Generic codes for errors without a more specific one
+const (
+	CodeInvalidRequest   = "INVALID_REQUEST"
+	CodeUnauthenticated  = "UNAUTHENTICATED"
+	CodePermissionDenied = "PERMISSION_DENIED"
+	CodeNotFound         = "NOT_FOUND"
+	CodeConflict         = "CONFLICT"
+	CodeRateLimited      = "RATE_LIMITED"
+	CodeUpstream         = "UPSTREAM_ERROR"
+	CodeInternal         = "INTERNAL"
+)
+
+// Error is a coded error. Message is safe to show to clients, the wrapped
+// cause is kept for logs only.
+type Error struct {
+	Code     string
+	Category Category
+	Message  string
+	Details  map[string]interface{}
+	cause    error
+}
+
+// New creates a coded error, typically assigned to a package level sentinel
+func New(category Category, code, message string) *Error {
+	return &Error{
+		Code:     code,
+		Category: category,
+		Message:  message,
+	}
+}
+
+// Error returns the message followed by the cause, if any
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.cause)
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is matches any error with the same code, so wrapped copies of a sentinel
+// still satisfy errors.Is against it
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of e carrying cause
+func (e *Error) Wrap(cause error) *Error {
+	c := e.clone()
+	c.cause = cause
+	return c
+}
+
+// WithMessage returns a copy of e with a more specific client message
+func (e *Error) WithMessage(format string, args ...interface{}) *Error {
+	c := e.clone()
+	c.Message = fmt.Sprintf(format, args...)
+	return c
+}
+
+// WithDetail returns a copy of e with an extra detail field
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	c := e.clone()
+	c.Details = make(map[string]interface{}, len(e.Details)+1)
+	for k, v := range e.Details {
+		c.Details[k] = v
+	}
+	c.Details[key] = value
+	return c
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+	return &c
+}
+
+// InvalidRequest reports a malformed request, the cause is shown to the
+// client since it describes what to fix
+func InvalidRequest(err error) *Error {
+	return &Error{
+		Code:     CodeInvalidRequest,
+		Category: CategoryValidation,
+		Message:  err.Error(),
+		cause:    err,
+	}
+}
+
+// Internal wraps an unexpected error behind a generic message
+func Internal(err error) *Error {
+	return &Error{
+		Code:     CodeInternal,
+		Category: CategoryInternal,
+		Message:  "internal error",
+		cause:    err,
+	}
+}
+
+// From returns the coded error in err's chain, or an internal error
+// wrapping err when it carries no code
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded
+	}
+	return Internal(err)
+}
+
+// CodeOf returns the code of err, CodeInternal for uncoded errors
+func CodeOf(err error) string {
+	if err == nil {
+		return ""
+	}
+	return From(err).Code
+}
+
+// HasCategory reports whether err carries a code in category
+func HasCategory(err error, category Category) bool {
+	var coded *Error
+	return errors.As(err, &coded) && coded.Category == category
+}
+
+// EventFields describes err for inclusion in event payloads
+func EventFields(err error) map[string]interface{} {
+	coded := From(err)
+	fields := map[string]interface{}{
+		"error":         err.Error(),
+		"errorCode":     coded.Code,
+		"errorCategory": string(coded.Category),
+	}
+	if len(coded.Details) > 0 {
+		fields["errorDetails"] = coded.Details
+	}
+	return fields
+}
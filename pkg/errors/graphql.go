@@ -0,0 +1,24 @@
+package errors
+
+import (
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ToGraphQL converts err to a GraphQL error whose extensions carry the code
+// and category, mirroring the HTTP error body
+func ToGraphQL(err error) *gqlerror.Error {
+	coded := From(err)
+
+	extensions := map[string]interface{}{
+		"code":     coded.Code,
+		"category": string(coded.Category),
+	}
+	if len(coded.Details) > 0 {
+		extensions["details"] = coded.Details
+	}
+
+	return &gqlerror.Error{
+		Message:    coded.Message,
+		Extensions: extensions,
+	}
+}
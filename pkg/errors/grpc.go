@@ -0,0 +1,104 @@
+package errors
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcDomain is the ErrorInfo domain of coded errors sent over gRPC
+const grpcDomain = "linkflow"
+
+// GRPCCode maps a category to its gRPC status code
+func GRPCCode(category Category) codes.Code {
+	switch category {
+	case CategoryValidation:
+		return codes.InvalidArgument
+	case CategoryAuth:
+		return codes.Unauthenticated
+	case CategoryPermission:
+		return codes.PermissionDenied
+	case CategoryNotFound:
+		return codes.NotFound
+	case CategoryConflict:
+		return codes.AlreadyExists
+	case CategoryRateLimit:
+		return codes.ResourceExhausted
+	case CategoryUpstream:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// ToGRPC returns the status error for err. The code and category travel in
+// an ErrorInfo detail so FromGRPC can rebuild the coded error.
+func ToGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	coded := From(err)
+	info := &errdetails.ErrorInfo{
+		Reason:   coded.Code,
+		Domain:   grpcDomain,
+		Metadata: map[string]string{"category": string(coded.Category)},
+	}
+	st, detailErr := status.New(GRPCCode(coded.Category), coded.Message).WithDetails(info)
+	if detailErr != nil {
+		return status.Error(GRPCCode(coded.Category), coded.Message)
+	}
+	return st.Err()
+}
+
+// FromGRPC rebuilds the coded error of a gRPC call so codes survive the hop
+// between services. Statuses without one are classified by their code.
+func FromGRPC(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return New(CategoryUpstream, CodeUpstream, "upstream call failed").Wrap(err)
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == grpcDomain {
+			return &Error{
+				Code:     info.Reason,
+				Category: Category(info.Metadata["category"]),
+				Message:  st.Message(),
+			}
+		}
+	}
+
+	category, code := categoryForGRPCCode(st.Code())
+	return &Error{
+		Code:     code,
+		Category: category,
+		Message:  st.Message(),
+		Details:  map[string]interface{}{"grpcCode": st.Code().String()},
+	}
+}
+
+func categoryForGRPCCode(code codes.Code) (Category, string) {
+	switch code {
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return CategoryValidation, CodeInvalidRequest
+	case codes.Unauthenticated:
+		return CategoryAuth, CodeUnauthenticated
+	case codes.PermissionDenied:
+		return CategoryPermission, CodePermissionDenied
+	case codes.NotFound:
+		return CategoryNotFound, CodeNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return CategoryConflict, CodeConflict
+	case codes.ResourceExhausted:
+		return CategoryRateLimit, CodeRateLimited
+	default:
+		return CategoryUpstream, CodeUpstream
+	}
+}
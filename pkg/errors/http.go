@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Response is the JSON body of an error response
+type Response struct {
+	Error    string                 `json:"error"`
+	Code     string                 `json:"code"`
+	Category Category               `json:"category"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+}
+
+// HTTPStatus maps a category to its HTTP status code
+func HTTPStatus(category Category) int {
+	switch category {
+	case CategoryValidation:
+		return http.StatusBadRequest
+	case CategoryAuth:
+		return http.StatusUnauthorized
+	case CategoryPermission:
+		return http.StatusForbidden
+	case CategoryNotFound:
+		return http.StatusNotFound
+	case CategoryConflict:
+		return http.StatusConflict
+	case CategoryRateLimit:
+		return http.StatusTooManyRequests
+	case CategoryUpstream:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ToHTTP returns the status and body for err, so handlers can write
+// c.JSON(errors.ToHTTP(err))
+func ToHTTP(err error) (int, Response) {
+	coded := From(err)
+	return HTTPStatus(coded.Category), Response{
+		Error:    coded.Message,
+		Code:     coded.Code,
+		Category: coded.Category,
+		Details:  coded.Details,
+	}
+}
+
+// FromResponse rebuilds the coded error of a downstream service response so
+// codes survive a hop through the gateway. Bodies without a code are
+// classified by status.
+func FromResponse(resp *http.Response) *Error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	var decoded Response
+	if err := json.Unmarshal(body, &decoded); err == nil && decoded.Code != "" {
+		return &Error{
+			Code:     decoded.Code,
+			Category: decoded.Category,
+			Message:  decoded.Error,
+			Details:  decoded.Details,
+		}
+	}
+
+	category, code := categoryForStatus(resp.StatusCode)
+	message := decoded.Error
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+
+	return &Error{
+		Code:     code,
+		Category: category,
+		Message:  message,
+		Details:  map[string]interface{}{"status": resp.StatusCode},
+	}
+}
+
+func categoryForStatus(status int) (Category, string) {
+	switch {
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		return CategoryValidation, CodeInvalidRequest
+	case status == http.StatusUnauthorized:
+		return CategoryAuth, CodeUnauthenticated
+	case status == http.StatusForbidden:
+		return CategoryPermission, CodePermissionDenied
+	case status == http.StatusNotFound:
+		return CategoryNotFound, CodeNotFound
+	case status == http.StatusConflict:
+		return CategoryConflict, CodeConflict
+	case status == http.StatusTooManyRequests:
+		return CategoryRateLimit, CodeRateLimited
+	default:
+		return CategoryUpstream, CodeUpstream
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/logger"
 	"github.com/segmentio/kafka-go"
 )
 
@@ -27,6 +29,21 @@ type EventMetadata struct {
 	CausationID   string `json:"causationId"`
 	TraceID       string `json:"traceId"`
 	SpanID        string `json:"spanId"`
+	// TraceContext carries the W3C propagation fields (traceparent,
+	// tracestate, baggage) so consumers continue the publisher's trace
+	TraceContext map[string]string `json:"traceContext,omitempty"`
+	// ReplayTo is set on events replayed from the event store, only the
+	// consumer it names handles them
+	ReplayTo string `json:"replayTo,omitempty"`
+	// TenantID is the tenant of the publisher, consumers handle the event
+	// scoped to it
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// deliveredTo reports whether consumer handles the event, every consumer
+// handles events that are not replays
+func (e Event) deliveredTo(consumer string) bool {
+	return e.Metadata.ReplayTo == "" || e.Metadata.ReplayTo == consumer
 }
 
 type EventBus interface {
@@ -37,10 +54,28 @@ type EventBus interface {
 
 type EventHandler func(ctx context.Context, event Event) error
 
+// Event bus backends selectable with KafkaConfig.Backend
+const (
+	BackendKafka  = "kafka"
+	BackendMemory = "memory"
+	BackendNATS   = "nats"
+)
+
 type KafkaConfig struct {
+	Backend       string
 	Brokers       []string
 	Topic         string
 	ConsumerGroup string
+	// Consumer names the subscribing service, replayed events addressed
+	// to another consumer are skipped
+	Consumer string
+	// Broadcast delivers every event to each instance of the service
+	// rather than sharing them between its replicas, for consumers holding
+	// per-connection state such as subscriptions. Events published while an
+	// instance is down are not delivered to it.
+	Broadcast bool
+	// NATS configures the nats backend
+	NATS NATSConfig
 }
 
 type KafkaEventBus struct {
@@ -49,6 +84,25 @@ type KafkaEventBus struct {
 	readers  map[string]*kafka.Reader
 	handlers map[string]EventHandler
 	logger   interface{} // Use interface to avoid circular dependency
+	// instance tells the consumer group of a broadcast bus apart
+	instance string
+}
+
+// New creates the event bus of the configured backend, Kafka unless the
+// backend is memory or nats
+func New(config KafkaConfig) (EventBus, error) {
+	switch config.Backend {
+	case "", BackendKafka:
+		return NewKafkaEventBus(config)
+	case BackendMemory:
+		bus := NewMemoryEventBus()
+		bus.consumer = config.Consumer
+		return bus, nil
+	case BackendNATS:
+		return NewNATSEventBus(config)
+	default:
+		return nil, fmt.Errorf("unknown event bus backend %q", config.Backend)
+	}
 }
 
 func NewKafkaEventBus(config KafkaConfig) (*KafkaEventBus, error) {
@@ -66,10 +120,15 @@ func NewKafkaEventBus(config KafkaConfig) (*KafkaEventBus, error) {
 		writer:   writer,
 		readers:  make(map[string]*kafka.Reader),
 		handlers: make(map[string]EventHandler),
+		instance: uuid.New().String(),
 	}, nil
 }
 
 func (k *KafkaEventBus) Publish(ctx context.Context, event Event) error {
+	if err := Validate(event); err != nil {
+		return err
+	}
+
 	// Ensure event has an ID
 	if event.ID == "" {
 		event.ID = uuid.New().String()
@@ -80,8 +139,16 @@ func (k *KafkaEventBus) Publish(ctx context.Context, event Event) error {
 		event.Timestamp = time.Now().UTC()
 	}
 
+	// Events published while handling a request or event share its ID
+	if event.Metadata.CorrelationID == "" {
+		event.Metadata.CorrelationID = logger.CorrelationID(ctx)
+	}
+
+	ctx, span := startPublishSpan(ctx, &event)
+
 	data, err := json.Marshal(event)
 	if err != nil {
+		endSpan(span, err)
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
@@ -91,18 +158,27 @@ func (k *KafkaEventBus) Publish(ctx context.Context, event Event) error {
 		Headers: []kafka.Header{
 			{Key: "event-type", Value: []byte(event.Type)},
 			{Key: "trace-id", Value: []byte(event.Metadata.TraceID)},
+			{Key: "traceparent", Value: []byte(event.Metadata.TraceContext["traceparent"])},
 			{Key: "correlation-id", Value: []byte(event.Metadata.CorrelationID)},
 		},
 	}
 
-	return k.writer.WriteMessages(ctx, msg)
+	err = k.writer.WriteMessages(ctx, msg)
+	endSpan(span, err)
+	return err
 }
 
 func (k *KafkaEventBus) Subscribe(topic string, handler EventHandler) error {
+	// A group of its own gives the instance every partition
+	groupID := k.config.ConsumerGroup
+	if k.config.Broadcast {
+		groupID += "-" + k.instance
+	}
+
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:     k.config.Brokers,
 		Topic:       topic,
-		GroupID:     k.config.ConsumerGroup,
+		GroupID:     groupID,
 		MinBytes:    1,
 		MaxBytes:    10e6,
 		StartOffset: kafka.LastOffset,
@@ -136,15 +212,45 @@ func (k *KafkaEventBus) consume(reader *kafka.Reader, handler EventHandler) {
 			fmt.Printf("Failed to unmarshal event: %v\n", err)
 			continue
 		}
+		if !event.deliveredTo(k.config.Consumer) {
+			continue
+		}
+		if err := Validate(event); err != nil {
+			fmt.Printf("Skipping invalid event %s: %v\n", event.ID, err)
+			continue
+		}
 
-		// Handle event
-		if err := handler(context.Background(), event); err != nil {
+		// Handle event within the trace and correlation ID of the publisher
+		ctx := logger.WithCorrelationID(context.Background(), event.Metadata.CorrelationID)
+		ctx, span := startConsumeSpan(ctx, event)
+		err = handler(ctx, event)
+		endSpan(span, err)
+		if err != nil {
 			fmt.Printf("Failed to handle event: %v\n", err)
 			// Implement retry logic here if needed
 		}
 	}
 }
 
+// Ping dials the configured brokers and succeeds once one of them answers
+func (k *KafkaEventBus) Ping(ctx context.Context) error {
+	if len(k.config.Brokers) == 0 {
+		return fmt.Errorf("no brokers configured")
+	}
+
+	var lastErr error
+	for _, broker := range k.config.Brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		conn.Close()
+		return nil
+	}
+	return fmt.Errorf("no broker reachable: %w", lastErr)
+}
+
 func (k *KafkaEventBus) Close() error {
 	// Close writer
 	if err := k.writer.Close(); err != nil {
@@ -199,6 +305,15 @@ func (b *EventBuilder) WithPayload(key string, value interface{}) *EventBuilder
 	return b
 }
 
+// WithError adds the error message with its code and category so
+// consumers can branch on the code
+func (b *EventBuilder) WithError(err error) *EventBuilder {
+	for key, value := range apperrors.EventFields(err) {
+		b.event.Payload[key] = value
+	}
+	return b
+}
+
 func (b *EventBuilder) WithCorrelationID(id string) *EventBuilder {
 	b.event.Metadata.CorrelationID = id
 	return b
@@ -231,22 +346,57 @@ const (
 	UserDeleted    = "user.deleted"
 
 	// Workflow events
-	WorkflowCreated     = "workflow.created"
-	WorkflowUpdated     = "workflow.updated"
-	WorkflowDeleted     = "workflow.deleted"
-	WorkflowActivated   = "workflow.activated"
-	WorkflowDeactivated = "workflow.deactivated"
+	WorkflowCreated            = "workflow.created"
+	WorkflowUpdated            = "workflow.updated"
+	WorkflowDeleted            = "workflow.deleted"
+	WorkflowActivated          = "workflow.activated"
+	WorkflowDeactivated        = "workflow.deactivated"
+	WorkflowIntegrityViolation = "workflow.integrity.violation"
+	WorkflowCanaryStarted      = "workflow.canary.started"
+	WorkflowCanaryPromoted     = "workflow.canary.promoted"
+	WorkflowCanaryRolledBack   = "workflow.canary.rolled_back"
+	WorkflowCanaryCancelled    = "workflow.canary.cancelled"
+	WorkflowCanaryShifted      = "workflow.canary.shifted"
+	WorkflowShadowStarted      = "workflow.shadow.started"
+	WorkflowShadowStopped      = "workflow.shadow.stopped"
+	WorkflowAnomalyDetected    = "workflow.anomaly.detected"
+	WorkflowTestsRequested     = "workflow.tests.requested"
+	WorkflowTestsCompleted     = "workflow.tests.completed"
 
 	// Execution events
-	ExecutionStarted      = "execution.started"
-	ExecutionCompleted    = "execution.completed"
-	ExecutionFailed       = "execution.failed"
-	ExecutionCancelled    = "execution.cancelled"
-	ExecutionStateChanged = "execution.state_changed"
-	ExecutionQueued       = "execution.queued"
+	ExecutionRequested      = "execution.requested"
+	ExecutionStarted        = "execution.started"
+	ExecutionCompleted      = "execution.completed"
+	ExecutionFailed         = "execution.failed"
+	ExecutionCancelled      = "execution.cancelled"
+	ExecutionStateChanged   = "execution.state_changed"
+	ExecutionQueued         = "execution.queued"
+	ExecutionErrorCaught    = "execution.error_caught"
+	ExecutionCompensating   = "execution.compensating"
+	ExecutionCompensated    = "execution.compensated"
+	ExecutionDataExported   = "execution.data_exported"
+	ExecutionShadowDiverged = "execution.shadow.diverged"
+	ExecutionEvidenceReady  = "execution.evidence_ready"
+
+	// Budget events
+	BudgetThreshold = "budget.threshold"
+
+	// SLA events
+	SLABreached  = "sla.breached"
+	SLARecovered = "sla.recovered"
+
+	// Worker pool events
+	WorkerPoolExhausted = "worker_pool.exhausted"
+	WorkerPoolRecovered = "worker_pool.recovered"
+
+	// Notification events
+	NotificationCreated       = "notification.created"
+	NotificationUnreadChanged = "notification.unread_changed"
 
 	// Node events
 	NodeExecutionStarted   = "node.execution.started"
 	NodeExecutionCompleted = "node.execution.completed"
 	NodeExecutionFailed    = "node.execution.failed"
+	NodeLog                = "node.log"
+	NodesStopRequest       = "nodes.stop.request"
 )
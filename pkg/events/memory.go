@@ -0,0 +1,183 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/logger"
+)
+
+// memoryQueueSize bounds the events waiting for a handler, publishers
+// block once it is full
+const memoryQueueSize = 1024
+
+// broker routes events between the memory event buses of the process, so
+// services running side by side see each other's events
+var broker = &memoryBroker{subscriptions: make(map[string][]*memorySubscription)}
+
+type memoryBroker struct {
+	mu            sync.RWMutex
+	subscriptions map[string][]*memorySubscription
+}
+
+type memorySubscription struct {
+	topic    string
+	consumer string
+	handler  EventHandler
+	events   chan Event
+	done     chan struct{}
+}
+
+// MemoryEventBus delivers events within the process, to the handlers
+// subscribed to the event type on any memory event bus. Events are not
+// persisted and are lost on exit, use it for local development only.
+type MemoryEventBus struct {
+	mu            sync.Mutex
+	consumer      string
+	subscriptions []*memorySubscription
+	closed        bool
+}
+
+func NewMemoryEventBus() *MemoryEventBus {
+	return &MemoryEventBus{}
+}
+
+func (m *MemoryEventBus) Publish(ctx context.Context, event Event) error {
+	if err := Validate(event); err != nil {
+		return err
+	}
+
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	if event.Metadata.CorrelationID == "" {
+		event.Metadata.CorrelationID = logger.CorrelationID(ctx)
+	}
+
+	ctx, span := startPublishSpan(ctx, &event)
+
+	// Handlers get the event as decoded from the wire, as they do with Kafka
+	data, err := json.Marshal(event)
+	if err != nil {
+		endSpan(span, err)
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	err = broker.publish(ctx, event.Type, data)
+	endSpan(span, err)
+	return err
+}
+
+func (m *MemoryEventBus) Subscribe(topic string, handler EventHandler) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return fmt.Errorf("event bus is closed")
+	}
+
+	sub := &memorySubscription{
+		topic:    topic,
+		consumer: m.consumer,
+		handler:  handler,
+		events:   make(chan Event, memoryQueueSize),
+		done:     make(chan struct{}),
+	}
+	m.subscriptions = append(m.subscriptions, sub)
+	broker.subscribe(sub)
+
+	go sub.consume()
+	return nil
+}
+
+// Close stops the handlers of this bus, the other buses of the process keep
+// receiving events
+func (m *MemoryEventBus) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+
+	for _, sub := range m.subscriptions {
+		broker.unsubscribe(sub)
+		close(sub.done)
+	}
+	return nil
+}
+
+func (b *memoryBroker) subscribe(sub *memorySubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions[sub.topic] = append(b.subscriptions[sub.topic], sub)
+}
+
+func (b *memoryBroker) unsubscribe(sub *memorySubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs := b.subscriptions[sub.topic]
+	for i, s := range subs {
+		if s == sub {
+			b.subscriptions[sub.topic] = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+}
+
+func (b *memoryBroker) publish(ctx context.Context, topic string, data []byte) error {
+	b.mu.RLock()
+	subs := b.subscriptions[topic]
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		// Each handler gets its own copy of the payload
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal event: %w", err)
+		}
+
+		select {
+		case sub.events <- event:
+		case <-sub.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *memorySubscription) consume() {
+	for {
+		select {
+		case event := <-s.events:
+			if !event.deliveredTo(s.consumer) {
+				continue
+			}
+			if err := Validate(event); err != nil {
+				fmt.Printf("Skipping invalid event %s: %v\n", event.ID, err)
+				continue
+			}
+
+			// Handle event within the trace and correlation ID of the publisher
+			ctx := logger.WithCorrelationID(context.Background(), event.Metadata.CorrelationID)
+			ctx, span := startConsumeSpan(ctx, event)
+			err := s.handler(ctx, event)
+			endSpan(span, err)
+			if err != nil {
+				fmt.Printf("Failed to handle event: %v\n", err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
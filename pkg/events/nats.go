@@ -0,0 +1,349 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATS defaults, used for the zero fields of NATSConfig
+const (
+	DefaultNATSSubjectPrefix = "linkflow"
+	DefaultNATSAckWait       = 30 * time.Second
+	DefaultNATSMaxDeliver    = 5
+	DefaultNATSMaxAge        = 7 * 24 * time.Hour
+
+	// natsConnectTimeout bounds the first connection, natsRetryDelay the
+	// wait before a failed event is redelivered, doubled per attempt
+	natsConnectTimeout = 5 * time.Second
+	natsRetryDelay     = time.Second
+	natsMaxRetryDelay  = time.Minute
+
+	// natsEphemeralTTL is how long the consumer of a broadcast bus outlives
+	// a lost connection
+	natsEphemeralTTL = time.Minute
+)
+
+// NATSConfig configures the NATS JetStream event bus
+type NATSConfig struct {
+	// URL lists the servers, comma separated
+	URL string
+	// SubjectPrefix is put before the event type, events of type
+	// workflow.created are published on linkflow.workflow.created
+	SubjectPrefix string
+	// Stream configures the stream of each event domain, the first segment
+	// of the event type. Streams overrides it per domain.
+	Stream  NATSStreamConfig
+	Streams map[string]NATSStreamConfig
+	// AckWait is how long a consumer has to handle an event, MaxDeliver how
+	// often a failing event is delivered before it is dropped
+	AckWait    time.Duration
+	MaxDeliver int
+}
+
+// NATSStreamConfig configures a JetStream stream, zero fields take the
+// value of the default stream
+type NATSStreamConfig struct {
+	MaxAge   time.Duration
+	MaxBytes int64
+	Replicas int
+	// Storage is file or memory
+	Storage string
+}
+
+// NATSEventBus publishes events to NATS JetStream. Each event domain has a
+// stream, and each subscription a durable consumer named after the
+// subscribing service, so replicas of a service share its events and resume
+// where they left off after a restart. A broadcast bus uses ephemeral
+// consumers instead.
+type NATSEventBus struct {
+	config   KafkaConfig
+	conn     *nats.Conn
+	js       jetstream.JetStream
+	consumer string
+
+	mu       sync.Mutex
+	streams  map[string]bool
+	consumes []jetstream.ConsumeContext
+}
+
+// invalidDurable matches the characters not allowed in consumer names
+var invalidDurable = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// NewNATSEventBus connects to the NATS servers of config. Lost connections
+// are reestablished in the background.
+func NewNATSEventBus(config KafkaConfig) (*NATSEventBus, error) {
+	nc := &config.NATS
+	if nc.SubjectPrefix == "" {
+		nc.SubjectPrefix = DefaultNATSSubjectPrefix
+	}
+	if nc.AckWait <= 0 {
+		nc.AckWait = DefaultNATSAckWait
+	}
+	if nc.MaxDeliver <= 0 {
+		nc.MaxDeliver = DefaultNATSMaxDeliver
+	}
+	if nc.Stream.MaxAge <= 0 {
+		nc.Stream.MaxAge = DefaultNATSMaxAge
+	}
+
+	consumer := config.Consumer
+	if consumer == "" {
+		consumer = config.ConsumerGroup
+	}
+
+	conn, err := nats.Connect(nc.URL,
+		nats.Name(consumer),
+		nats.Timeout(natsConnectTimeout),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	return &NATSEventBus{
+		config:   config,
+		conn:     conn,
+		js:       js,
+		consumer: consumer,
+		streams:  make(map[string]bool),
+	}, nil
+}
+
+func (n *NATSEventBus) Publish(ctx context.Context, event Event) error {
+	if err := Validate(event); err != nil {
+		return err
+	}
+
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	if event.Metadata.CorrelationID == "" {
+		event.Metadata.CorrelationID = logger.CorrelationID(ctx)
+	}
+
+	stream, err := n.ensureStream(ctx, event.Type)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := startPublishSpan(ctx, &event)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		endSpan(span, err)
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	msg := nats.NewMsg(n.subject(event.Type))
+	msg.Data = data
+	msg.Header.Set("event-type", event.Type)
+	msg.Header.Set("trace-id", event.Metadata.TraceID)
+	msg.Header.Set("traceparent", event.Metadata.TraceContext["traceparent"])
+	msg.Header.Set("correlation-id", event.Metadata.CorrelationID)
+
+	// JetStream drops a message whose ID it saw within the duplicate window,
+	// so a retried publish stores the event once. Replays keep the ID of the
+	// event and are told apart by their consumer.
+	msgID := event.ID
+	if event.Metadata.ReplayTo != "" {
+		msgID += ":" + event.Metadata.ReplayTo
+	}
+
+	_, err = n.js.PublishMsg(ctx, msg, jetstream.WithMsgID(msgID), jetstream.WithExpectStream(stream))
+	endSpan(span, err)
+	return err
+}
+
+// Subscribe creates or resumes the durable consumer of the service for the
+// event type topic
+func (n *NATSEventBus) Subscribe(topic string, handler EventHandler) error {
+	ctx, cancel := context.WithTimeout(context.Background(), natsConnectTimeout)
+	defer cancel()
+
+	stream, err := n.ensureStream(ctx, topic)
+	if err != nil {
+		return err
+	}
+
+	cfg := jetstream.ConsumerConfig{
+		FilterSubject: n.subject(topic),
+		// Like a new Kafka consumer group, start with the events published
+		// from now on
+		DeliverPolicy: jetstream.DeliverNewPolicy,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       n.config.NATS.AckWait,
+		MaxDeliver:    n.config.NATS.MaxDeliver,
+	}
+
+	// A broadcast bus gets an ephemeral consumer of its own, removed by the
+	// server once the instance is gone
+	var cons jetstream.Consumer
+	if n.config.Broadcast {
+		cfg.InactiveThreshold = natsEphemeralTTL
+		cons, err = n.js.CreateConsumer(ctx, stream, cfg)
+	} else {
+		cfg.Durable = invalidDurable.ReplaceAllString(n.consumer+"_"+topic, "_")
+		cons, err = n.js.CreateOrUpdateConsumer(ctx, stream, cfg)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create consumer for %s: %w", topic, err)
+	}
+
+	consume, err := cons.Consume(func(msg jetstream.Msg) {
+		n.handle(msg, handler)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", topic, err)
+	}
+
+	n.mu.Lock()
+	n.consumes = append(n.consumes, consume)
+	n.mu.Unlock()
+	return nil
+}
+
+// handle passes a delivered event to handler. Failed events are redelivered
+// with backoff until MaxDeliver, events that cannot be handled are dropped.
+func (n *NATSEventBus) handle(msg jetstream.Msg, handler EventHandler) {
+	var event Event
+	if err := json.Unmarshal(msg.Data(), &event); err != nil {
+		fmt.Printf("Failed to unmarshal event: %v\n", err)
+		msg.Term()
+		return
+	}
+	if !event.deliveredTo(n.consumer) {
+		msg.Ack()
+		return
+	}
+	if err := Validate(event); err != nil {
+		fmt.Printf("Skipping invalid event %s: %v\n", event.ID, err)
+		msg.Term()
+		return
+	}
+
+	// Handle event within the trace and correlation ID of the publisher
+	ctx := logger.WithCorrelationID(context.Background(), event.Metadata.CorrelationID)
+	ctx, span := startConsumeSpan(ctx, event)
+	err := handler(ctx, event)
+	endSpan(span, err)
+	if err == nil {
+		msg.Ack()
+		return
+	}
+
+	fmt.Printf("Failed to handle event: %v\n", err)
+	delay := natsRetryDelay
+	if meta, metaErr := msg.Metadata(); metaErr == nil && meta.NumDelivered > 1 {
+		delay = min(natsRetryDelay<<min(meta.NumDelivered-1, 16), natsMaxRetryDelay)
+	}
+	msg.NakWithDelay(delay)
+}
+
+// subject is the NATS subject of the event type topic
+func (n *NATSEventBus) subject(topic string) string {
+	return n.config.NATS.SubjectPrefix + "." + topic
+}
+
+// ensureStream creates or updates the stream of the domain of the event type
+// topic once per bus and returns its name
+func (n *NATSEventBus) ensureStream(ctx context.Context, topic string) (string, error) {
+	domain, _, _ := strings.Cut(topic, ".")
+	if domain == "" || strings.ContainsAny(domain, "*>") {
+		return "", fmt.Errorf("event type %q has no domain", topic)
+	}
+
+	prefix := n.config.NATS.SubjectPrefix
+	name := invalidDurable.ReplaceAllString(strings.ToUpper(prefix+"_"+domain), "_")
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.streams[domain] {
+		return name, nil
+	}
+
+	cfg := n.config.NATS.Stream
+	if override, ok := n.config.NATS.Streams[domain]; ok {
+		cfg = mergeStreamConfig(override, cfg)
+	}
+
+	storage := jetstream.FileStorage
+	if cfg.Storage == "memory" {
+		storage = jetstream.MemoryStorage
+	}
+
+	_, err := n.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     name,
+		Subjects: []string{prefix + "." + domain, prefix + "." + domain + ".>"},
+		MaxAge:   cfg.MaxAge,
+		MaxBytes: cfg.MaxBytes,
+		Replicas: cfg.Replicas,
+		Storage:  storage,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create stream %s: %w", name, err)
+	}
+
+	n.streams[domain] = true
+	return name, nil
+}
+
+// mergeStreamConfig fills the zero fields of cfg from defaults
+func mergeStreamConfig(cfg, defaults NATSStreamConfig) NATSStreamConfig {
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = defaults.MaxAge
+	}
+	if cfg.MaxBytes == 0 {
+		cfg.MaxBytes = defaults.MaxBytes
+	}
+	if cfg.Replicas == 0 {
+		cfg.Replicas = defaults.Replicas
+	}
+	if cfg.Storage == "" {
+		cfg.Storage = defaults.Storage
+	}
+	return cfg
+}
+
+// Ping succeeds while the bus is connected to a server with JetStream
+// enabled
+func (n *NATSEventBus) Ping(ctx context.Context) error {
+	if !n.conn.IsConnected() {
+		return fmt.Errorf("not connected to NATS: %s", n.conn.Status())
+	}
+	if _, err := n.js.AccountInfo(ctx); err != nil {
+		return fmt.Errorf("JetStream unavailable: %w", err)
+	}
+	return nil
+}
+
+// Close stops the consumers and flushes pending publishes. The durable
+// consumers stay on the server for the next start.
+func (n *NATSEventBus) Close() error {
+	n.mu.Lock()
+	for _, consume := range n.consumes {
+		consume.Stop()
+	}
+	n.consumes = nil
+	n.mu.Unlock()
+
+	return n.conn.Drain()
+}
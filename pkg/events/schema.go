@@ -0,0 +1,29 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/linkflow-go/pkg/events/schema"
+)
+
+// Validate checks the payload of event against the schema of its type and
+// version in schema.Default. The event buses validate on publish and before
+// handing an event to subscribers.
+func Validate(event Event) error {
+	return schema.Default.Validate(event.Type, event.Version, event.Payload)
+}
+
+// DecodePayload decodes the payload into v, typically a struct with json
+// tags matching the schema of the event, so consumers read typed fields
+// rather than asserting map values
+func (e Event) DecodePayload(v interface{}) error {
+	data, err := json.Marshal(e.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s payload: %w", e.Type, err)
+	}
+	return nil
+}
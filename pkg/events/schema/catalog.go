@@ -0,0 +1,544 @@
+package schema
+
+// Default is the registry the event buses validate against
+var Default = NewRegistry()
+
+// Payload schemas of the events published by the services. Fields are
+// required when every publisher of the type sets them. Add a version by
+// appending a schema with the next version number, Register rejects
+// versions that would break consumers.
+func init() {
+	Default.MustRegister(
+		// User events
+		Schema{Type: "user.registered", Version: 1, Fields: []Field{
+			Required("email", String),
+			Required("firstName", String),
+			Required("lastName", String),
+		}},
+		Schema{Type: "user.logged_in", Version: 1, Fields: []Field{
+			Required("ipAddress", String),
+			Required("userAgent", String),
+		}},
+		Schema{Type: "user.logged_out", Version: 1},
+		Schema{Type: "user.updated", Version: 1, Fields: []Field{
+			Optional("userId", String),
+			Optional("updates", Object),
+		}},
+		Schema{Type: "user.deleted", Version: 1},
+		Schema{Type: "user.email.verified", Version: 1, Fields: []Field{
+			Required("email", String),
+		}},
+		Schema{Type: "user.role.assigned", Version: 1, Fields: []Field{
+			Required("role", String),
+		}},
+		Schema{Type: "user.role.removed", Version: 1, Fields: []Field{
+			Required("role", String),
+		}},
+		Schema{Type: "user.session.revoked", Version: 1, Fields: []Field{
+			Required("sessionId", String),
+		}},
+		Schema{Type: "user.sessions.all.revoked", Version: 1},
+
+		// Auth events
+		Schema{Type: "auth.login.failed", Version: 1, Fields: []Field{
+			Required("email", String),
+			Required("ipAddress", String),
+			Required("attempts", Number),
+		}},
+		Schema{Type: "auth.account.locked", Version: 1, Fields: []Field{
+			Required("email", String),
+			Required("ipAddress", String),
+			Required("attempts", Number),
+			Required("lockedUntil", String),
+		}},
+		Schema{Type: "auth.token.refreshed", Version: 1},
+		Schema{Type: "auth.token.revoked", Version: 1, Fields: []Field{
+			Required("tokenType", String),
+		}},
+
+		// Workflow events
+		Schema{Type: "workflow.created", Version: 1, Fields: []Field{
+			Required("workflow_id", String),
+			Required("user_id", String),
+			Required("name", String),
+		}},
+		Schema{Type: "workflow.created_from_template", Version: 1, Fields: []Field{
+			Required("workflow_id", String),
+			Required("template_id", String),
+			Required("user_id", String),
+		}},
+		Schema{Type: "workflow.updated", Version: 1, Fields: []Field{
+			Required("workflow_id", String),
+			Required("user_id", String),
+			Required("version", Number),
+			Required("previous_version", Number),
+			Optional("template_id", String),
+			Optional("template_version", Number),
+		}},
+		Schema{Type: "workflow.deleted", Version: 1, Fields: []Field{
+			Required("workflow_id", String),
+			Required("user_id", String),
+			Required("name", String),
+		}},
+		Schema{Type: "workflow.activated", Version: 1, Fields: []Field{
+			Required("workflow_id", String),
+			Required("user_id", String),
+		}},
+		Schema{Type: "workflow.deactivated", Version: 1, Fields: []Field{
+			Required("workflow_id", String),
+			Required("user_id", String),
+		}},
+		Schema{Type: "workflow.duplicated", Version: 1, Fields: []Field{
+			Required("original_id", String),
+			Required("clone_id", String),
+			Required("user_id", String),
+		}},
+		Schema{Type: "workflow.validated", Version: 1, Fields: []Field{
+			Required("workflow_id", String),
+			Required("valid", Bool),
+			Required("errors", Number),
+			Required("warnings", Number),
+		}},
+		Schema{Type: "workflow.version.rollback", Version: 1, Fields: []Field{
+			Required("workflow_id", String),
+			Required("version", Number),
+			Required("user_id", String),
+		}},
+		Schema{Type: "workflow.integrity.violation", Version: 1, Fields: []Field{
+			Required("workflow_id", String),
+			Required("user_id", String),
+			Required("issues", Array),
+		}},
+		canary("workflow.canary.started"),
+		canary("workflow.canary.promoted"),
+		canary("workflow.canary.rolled_back"),
+		canary("workflow.canary.cancelled"),
+		canary("workflow.canary.shifted"),
+		shadow("workflow.shadow.started"),
+		shadow("workflow.shadow.stopped"),
+		Schema{Type: "workflow.anomaly.detected", Version: 1, Fields: []Field{
+			Required("kind", String),
+			Required("workflowId", String),
+			Optional("userId", String),
+			Optional("executionId", String),
+			Required("value", Number),
+			Required("baseline", Number),
+			Required("threshold", Number),
+			Required("sigma", Number),
+			Required("detectedAt", String),
+			Optional("windowStart", String),
+			Optional("failed", Number),
+			Optional("finished", Number),
+		}},
+		Schema{Type: "workflow.tests.requested", Version: 1, Fields: []Field{
+			Required("runId", String),
+			Required("workflowId", String),
+			Required("version", Number),
+		}},
+		Schema{Type: "workflow.tests.completed", Version: 1, Fields: []Field{
+			Required("runId", String),
+			Required("workflowId", String),
+			Required("version", Number),
+			Required("status", String),
+			Required("passed", Number),
+			Required("failed", Number),
+		}},
+
+		// Trigger events
+		Schema{Type: "trigger.created", Version: 1, Fields: []Field{
+			Required("trigger_id", String),
+			Required("workflow_id", String),
+			Required("type", String),
+		}},
+		trigger("trigger.updated"),
+		trigger("trigger.deleted"),
+		trigger("trigger.activated"),
+		trigger("trigger.deactivated"),
+		Schema{Type: "trigger.fired", Version: 1, Fields: []Field{
+			Required("trigger_id", String),
+			Required("workflow_id", String),
+			Required("type", String),
+			Required("idempotencyKey", String),
+			Optional("data", Object),
+			Optional("environment", String),
+		}},
+
+		// Execution events
+		Schema{Type: "execution.requested", Version: 1, Fields: []Field{
+			Required("execution_id", String),
+			Required("workflow_id", String),
+			Required("user_id", String),
+			Required("version", Number),
+			Optional("input_data", Any),
+			Optional("debug", Bool),
+			Optional("environment", String),
+		}},
+		Schema{Type: "execution.queued", Version: 1, Fields: []Field{
+			Required("workflowId", String),
+			Required("priority", String),
+		}},
+		Schema{Type: "execution.started", Version: 1, Fields: []Field{
+			Required("workflowId", String),
+			Required("executionId", String),
+			Optional("debug", Bool),
+		}},
+		Schema{Type: "execution.completed", Version: 1, Fields: []Field{
+			Required("workflowId", String),
+			Required("duration", Number),
+			Required("completedNodes", Number),
+			Required("failedNodes", Number),
+			Required("continuedOnFail", Number),
+			Optional("usage", Object),
+			Optional("startedAt", String),
+		}},
+		Schema{Type: "execution.failed", Version: 1, Fields: []Field{
+			Required("workflowId", String),
+			Required("error", String),
+			Required("errorCode", String),
+			Required("errorCategory", String),
+			Optional("errorDetails", Object),
+			Optional("cause", String),
+			Optional("startedAt", String),
+			Optional("duration", Number),
+		}},
+		Schema{Type: "execution.cancelled", Version: 1, Fields: []Field{
+			Optional("workflowId", String),
+			Optional("discardedNodes", Array),
+			Optional("reason", String),
+			Optional("requestedBy", String),
+			Optional("startedAt", String),
+			Optional("duration", Number),
+		}},
+		Schema{Type: "execution.state_changed", Version: 1, Fields: []Field{
+			Optional("workflowId", String),
+			Optional("fromState", String),
+			Optional("toState", String),
+			Optional("event", String),
+			Optional("metadata", Object),
+			Optional("state", String),
+			Optional("reason", String),
+		}},
+		Schema{Type: "execution.error_caught", Version: 1, Fields: []Field{
+			Required("workflowId", String),
+			Required("nodeId", String),
+			Required("boundaryId", String),
+			Required("catchNode", String),
+		}},
+		Schema{Type: "execution.compensating", Version: 1, Fields: []Field{
+			Required("workflowId", String),
+			Required("nodes", Array),
+		}},
+		Schema{Type: "execution.compensated", Version: 1, Fields: []Field{
+			Required("workflowId", String),
+			Required("nodes", Array),
+			Required("failed", Array),
+		}},
+		Schema{Type: "execution.data_exported", Version: 1, Fields: []Field{
+			Optional("exportId", String),
+			Optional("exportedBy", String),
+			Optional("format", String),
+			Optional("recordCount", Number),
+			Optional("checksum", String),
+			Optional("storageKey", String),
+		}},
+		Schema{Type: "execution.evidence_ready", Version: 1, Fields: []Field{
+			Required("bundleId", String),
+			Required("executionId", String),
+			Required("requestedBy", String),
+			Required("checksum", String),
+			Required("size", Number),
+		}},
+		Schema{Type: "execution.shadow.diverged", Version: 1, Fields: []Field{
+			Required("workflowId", String),
+			Required("shadowId", String),
+			Required("comparisonId", String),
+			Required("liveVersion", Number),
+			Required("shadowVersion", Number),
+			Required("divergences", Number),
+		}},
+		Schema{Type: "execution.timeout", Version: 1, Fields: []Field{
+			Required("nodeId", String),
+			Required("timeout", Number),
+			Required("cause", String),
+		}},
+		Schema{Type: "execution.timeout.warning", Version: 1},
+		Schema{Type: "execution.error", Version: 1, Fields: []Field{
+			Required("error", Object),
+		}},
+		Schema{Type: "execution.log", Version: 1, Fields: []Field{
+			Required("log", Object),
+		}},
+		Schema{Type: "execution.metrics", Version: 1, Fields: []Field{
+			Required("metrics", Object),
+		}},
+		Schema{Type: "error.workflow.trigger", Version: 1, Fields: []Field{
+			Required("workflowId", String),
+			Optional("error", Object),
+		}},
+		Schema{Type: "timeout.retry.trigger", Version: 1, Fields: []Field{
+			Required("nodeId", String),
+		}},
+		Schema{Type: "checkpoint.saved", Version: 1, Fields: []Field{
+			Required("checkpointId", String),
+			Required("nodeId", String),
+		}},
+		Schema{Type: "cost.calculated", Version: 1, Fields: []Field{
+			Required("cost", Object),
+		}},
+		Schema{Type: "budget.threshold", Version: 1, Fields: []Field{
+			Required("budgetId", String),
+			Required("scope", String),
+			Required("scopeId", String),
+			Required("workflowId", String),
+			Optional("userId", String),
+			Optional("updatedBy", String),
+			Required("threshold", Number),
+			Required("spent", Number),
+			Required("limit", Number),
+			Required("month", String),
+			Required("hardCap", Bool),
+		}},
+		sla("sla.breached"),
+		sla("sla.recovered"),
+		workerPool("worker_pool.exhausted"),
+		workerPool("worker_pool.recovered"),
+		Schema{Type: "notification.created", Version: 1, Fields: []Field{
+			Required("notificationId", String),
+			Required("userId", String),
+			Required("type", String),
+			Required("priority", String),
+			Required("subject", String),
+			Required("body", String),
+			Optional("data", Object),
+			Required("unreadCount", Number),
+			Required("createdAt", String),
+		}},
+		Schema{Type: "notification.unread_changed", Version: 1, Fields: []Field{
+			Required("userId", String),
+			Required("unreadCount", Number),
+		}},
+		Schema{Type: "recovery.completed", Version: 1, Fields: []Field{
+			Required("strategy", String),
+			Required("attempts", Number),
+		}},
+		Schema{Type: "recovery.failed", Version: 1, Fields: []Field{
+			Required("strategy", String),
+			Required("attempts", Number),
+			Optional("error", String),
+		}},
+		Schema{Type: "queue.metrics", Version: 1, Fields: []Field{
+			Required("status", Object),
+		}},
+		Schema{Type: "recovery.metrics", Version: 1, Fields: []Field{
+			Required("metrics", Object),
+		}},
+		Schema{Type: "task.completed", Version: 1, Fields: []Field{
+			Required("success", Bool),
+			Required("duration", Number),
+			Required("workerId", String),
+		}},
+
+		// Node events
+		Schema{Type: "node.execution.started", Version: 1, Fields: []Field{
+			Required("executionId", String),
+			Required("nodeId", String),
+			Required("nodeType", String),
+		}},
+		Schema{Type: "node.execution.completed", Version: 1, Fields: []Field{
+			Required("status", String),
+			Optional("executionId", String),
+			Optional("nodeId", String),
+		}},
+		Schema{Type: "node.log", Version: 1, Fields: []Field{
+			Required("executionId", String),
+			Required("nodeId", String),
+			Required("level", String),
+			Required("message", String),
+			Optional("nodeExecutionId", String),
+			Optional("data", Object),
+		}},
+		Schema{Type: "nodes.stop.request", Version: 1, Fields: []Field{
+			Required("executionId", String),
+			Required("reason", String),
+			Optional("requestId", String),
+			Optional("force", Bool),
+		}},
+		Schema{Type: "node.execute.request", Version: 1, Fields: []Field{
+			Required("requestId", String),
+			Required("nodeId", String),
+			Required("nodeType", String),
+			Required("parameters", Object),
+			Required("inputData", Object),
+			Optional("userId", String),
+			Optional("nodeExecutionId", String),
+			Optional("debug", Bool),
+		}},
+		Schema{Type: "node.execute.response", Version: 1, Fields: []Field{
+			Required("requestId", String),
+			Required("nodeId", String),
+			Required("result", Object),
+		}},
+
+		// Executor events
+		Schema{Type: "worker.registered", Version: 1, Fields: []Field{
+			Required("address", String),
+			Required("capacity", Number),
+		}},
+		Schema{Type: "worker.unregistered", Version: 1},
+		Schema{Type: "work.assigned", Version: 1, Fields: []Field{
+			Required("workerId", String),
+			Required("workflowId", String),
+		}},
+		Schema{Type: "work.reassigned", Version: 1, Fields: []Field{
+			Required("fromWorkerId", String),
+			Required("toWorkerId", String),
+			Optional("reason", String),
+		}},
+		Schema{Type: "work.queued", Version: 1, Fields: []Field{
+			Required("workerId", String),
+		}},
+		Schema{Type: "work.started", Version: 1, Fields: []Field{
+			Required("workerId", String),
+		}},
+		Schema{Type: "work.completed", Version: 1, Fields: []Field{
+			Required("executionId", String),
+			Required("workerId", String),
+		}},
+		Schema{Type: "worker.heartbeat", Version: 1, Fields: []Field{
+			Required("workerId", String),
+			Required("metrics", Object),
+		}},
+		Schema{Type: "work.handoff.requested", Version: 1, Fields: []Field{
+			Required("fromWorkerId", String),
+			Required("toWorkerId", String),
+		}},
+		Schema{Type: "work.handoff.acknowledged", Version: 1, Fields: []Field{
+			Required("workerId", String),
+			Required("released", Bool),
+		}},
+		Schema{Type: "workerpool.metrics", Version: 1, Fields: []Field{
+			Required("metrics", Object),
+		}},
+		Schema{Type: "coordinator.metrics", Version: 1, Fields: []Field{
+			Required("metrics", Object),
+		}},
+
+		// Credential events
+		Schema{Type: "credential.created", Version: 1, Fields: []Field{
+			Required("name", String),
+			Required("type", String),
+		}},
+		Schema{Type: "credential.updated", Version: 1},
+		Schema{Type: "credential.deleted", Version: 1},
+		Schema{Type: "credential.shared", Version: 1, Fields: []Field{
+			Required("sharedWith", String),
+		}},
+
+		// Schedule events
+		Schema{Type: "schedule.created", Version: 1, Fields: []Field{
+			Required("workflowId", String),
+			Required("cron", String),
+			Optional("scheduleId", String),
+		}},
+		Schema{Type: "schedule.updated", Version: 1},
+		Schema{Type: "schedule.deleted", Version: 1},
+		scheduleChange("schedule.paused"),
+		scheduleChange("schedule.resumed"),
+		Schema{Type: "schedule.triggered", Version: 1, Fields: []Field{
+			Required("workflowId", String),
+			Required("data", Object),
+			Optional("scheduleId", String),
+			Optional("executionId", String),
+			Optional("idempotencyKey", String),
+			Optional("manual", Bool),
+		}},
+
+		// Webhook events
+		Schema{Type: "webhook.created", Version: 1, Fields: []Field{
+			Required("path", String),
+			Required("workflowId", String),
+		}},
+		Schema{Type: "webhook.deleted", Version: 1, Fields: []Field{
+			Required("path", String),
+		}},
+		Schema{Type: "webhook.received", Version: 1, Fields: []Field{
+			Required("webhookId", String),
+			Required("workflowId", String),
+			Required("nodeId", String),
+			Required("executionId", String),
+			Required("idempotencyKey", String),
+			Required("data", Any),
+		}},
+
+		// Variable events
+		variable("variable.created"),
+		variable("variable.updated"),
+		variable("variable.deleted"),
+	)
+}
+
+func canary(eventType string) Schema {
+	return Schema{Type: eventType, Version: 1, Fields: []Field{
+		Required("workflow_id", String),
+		Required("canary_id", String),
+		Required("canary_version", Number),
+		Required("stable_version", Number),
+		Optional("strategy", String),
+		Required("percentage", Number),
+		Optional("reason", String),
+	}}
+}
+
+func shadow(eventType string) Schema {
+	return Schema{Type: eventType, Version: 1, Fields: []Field{
+		Required("workflow_id", String),
+		Required("shadow_id", String),
+		Required("shadow_version", Number),
+		Required("percentage", Number),
+	}}
+}
+
+func trigger(eventType string) Schema {
+	return Schema{Type: eventType, Version: 1, Fields: []Field{
+		Required("trigger_id", String),
+		Required("workflow_id", String),
+	}}
+}
+
+func scheduleChange(eventType string) Schema {
+	return Schema{Type: eventType, Version: 1, Fields: []Field{
+		Required("scheduleId", String),
+		Required("workflowId", String),
+		Required("data", Object),
+		Required("manual", Bool),
+	}}
+}
+
+func sla(eventType string) Schema {
+	return Schema{Type: eventType, Version: 1, Fields: []Field{
+		Required("slaId", String),
+		Required("workflowId", String),
+		Optional("userId", String),
+		Optional("updatedBy", String),
+		Required("objective", String),
+		Required("target", Number),
+		Required("actual", Number),
+		Required("since", String),
+	}}
+}
+
+func workerPool(eventType string) Schema {
+	return Schema{Type: eventType, Version: 1, Fields: []Field{
+		Required("instance", String),
+		Required("workers", Number),
+		Required("busyWorkers", Number),
+		Required("queueDepth", Number),
+		Required("queueCapacity", Number),
+	}}
+}
+
+func variable(eventType string) Schema {
+	return Schema{Type: eventType, Version: 1, Fields: []Field{
+		Required("key", String),
+	}}
+}
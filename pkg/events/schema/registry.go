@@ -0,0 +1,268 @@
+// Package schema defines the versioned payload schemas of the events
+// exchanged between services. The event buses validate payloads against the
+// Default registry when publishing and before handing events to
+// subscribers, so a publisher cannot silently drop a field its consumers
+// read.
+//
+// A new version of a schema must stay compatible with the previous one, in
+// both directions: consumers of the previous version keep finding the
+// fields they read, and events of the previous version still validate.
+// Changes that cannot meet this need a new event type.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+var (
+	ErrInvalidPayload     = apperrors.New(apperrors.CategoryValidation, "INVALID_EVENT_PAYLOAD", "event payload does not match its schema")
+	ErrIncompatibleSchema = apperrors.New(apperrors.CategoryValidation, "INCOMPATIBLE_EVENT_SCHEMA", "event schema is not compatible with its previous version")
+)
+
+// Kind is the JSON type of a payload field
+type Kind string
+
+const (
+	String Kind = "string"
+	Number Kind = "number"
+	Bool   Kind = "boolean"
+	Object Kind = "object"
+	Array  Kind = "array"
+	Any    Kind = "any"
+)
+
+// Field is a payload field. A required field must be present, a null value
+// satisfies any kind so nil slices and maps can be published.
+type Field struct {
+	Name     string
+	Kind     Kind
+	Required bool
+}
+
+// Required declares a field every event of the schema carries
+func Required(name string, kind Kind) Field {
+	return Field{Name: name, Kind: kind, Required: true}
+}
+
+// Optional declares a field some events of the schema carry
+func Optional(name string, kind Kind) Field {
+	return Field{Name: name, Kind: kind}
+}
+
+// Schema describes the payload of one version of an event type. Fields not
+// declared are allowed, so publishers can add context without a new
+// version.
+type Schema struct {
+	Type    string
+	Version int
+	Fields  []Field
+}
+
+func (s Schema) field(name string) (Field, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Validate checks payload against the schema
+func (s Schema) Validate(payload map[string]interface{}) error {
+	// Check the payload as consumers decode it
+	normalized, err := normalize(payload)
+	if err != nil {
+		return ErrInvalidPayload.Wrap(err).WithDetail("type", s.Type)
+	}
+
+	for _, f := range s.Fields {
+		value, ok := normalized[f.Name]
+		if !ok {
+			if f.Required {
+				return ErrInvalidPayload.
+					WithMessage("%s v%d payload is missing %s", s.Type, s.Version, f.Name).
+					WithDetail("type", s.Type).
+					WithDetail("field", f.Name)
+			}
+			continue
+		}
+		if kind := kindOf(value); value != nil && f.Kind != Any && kind != f.Kind {
+			return ErrInvalidPayload.
+				WithMessage("%s v%d payload field %s is %s, expected %s", s.Type, s.Version, f.Name, kind, f.Kind).
+				WithDetail("type", s.Type).
+				WithDetail("field", f.Name)
+		}
+	}
+	return nil
+}
+
+// Registry holds the schema versions of event types
+type Registry struct {
+	mu      sync.RWMutex
+	schemas map[string][]Schema // ascending versions
+}
+
+func NewRegistry() *Registry {
+	return &Registry{schemas: make(map[string][]Schema)}
+}
+
+// Register adds the next version of an event type, which must follow the
+// latest registered one and be compatible with it
+func (r *Registry) Register(s Schema) error {
+	if s.Type == "" {
+		return fmt.Errorf("schema has no event type")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions := r.schemas[s.Type]
+	if len(versions) == 0 {
+		if s.Version != 1 {
+			return fmt.Errorf("first schema of %s must be version 1, got %d", s.Type, s.Version)
+		}
+	} else {
+		latest := versions[len(versions)-1]
+		if s.Version != latest.Version+1 {
+			return fmt.Errorf("next schema of %s must be version %d, got %d", s.Type, latest.Version+1, s.Version)
+		}
+		if err := Compatible(latest, s); err != nil {
+			return err
+		}
+	}
+
+	r.schemas[s.Type] = append(versions, s)
+	return nil
+}
+
+// MustRegister registers schemas and panics on the first one that is
+// rejected, for catalogs defined at init
+func (r *Registry) MustRegister(schemas ...Schema) {
+	for _, s := range schemas {
+		if err := r.Register(s); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Lookup returns the schema of an event version. Version 0 is the version
+// of events published before versioning, 1. Versions newer than the
+// registry knows resolve to the latest one, which they are compatible with.
+func (r *Registry) Lookup(eventType string, version int) (Schema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := r.schemas[eventType]
+	if len(versions) == 0 {
+		return Schema{}, false
+	}
+	if version <= 0 {
+		version = 1
+	}
+	if version > len(versions) {
+		version = len(versions)
+	}
+	return versions[version-1], true
+}
+
+// Latest returns the newest schema of an event type
+func (r *Registry) Latest(eventType string) (Schema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := r.schemas[eventType]
+	if len(versions) == 0 {
+		return Schema{}, false
+	}
+	return versions[len(versions)-1], true
+}
+
+// Types lists the registered event types in order
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.schemas))
+	for t := range r.schemas {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Validate checks a payload against the schema of its event version. Event
+// types without a schema are not checked.
+func (r *Registry) Validate(eventType string, version int, payload map[string]interface{}) error {
+	s, ok := r.Lookup(eventType, version)
+	if !ok {
+		return nil
+	}
+	return s.Validate(payload)
+}
+
+// Compatible reports whether next can replace prev: both require the same
+// fields, and fields declared by both have the same kind
+func Compatible(prev, next Schema) error {
+	incompatible := func(format string, args ...interface{}) error {
+		return ErrIncompatibleSchema.
+			WithMessage("%s v%d: "+format, append([]interface{}{next.Type, next.Version}, args...)...).
+			WithDetail("type", next.Type)
+	}
+
+	for _, p := range prev.Fields {
+		n, ok := next.field(p.Name)
+		switch {
+		case !ok && p.Required:
+			return incompatible("removes required field %s", p.Name)
+		case !ok:
+			continue
+		case n.Kind != p.Kind:
+			return incompatible("changes field %s from %s to %s", p.Name, p.Kind, n.Kind)
+		case n.Required && !p.Required:
+			return incompatible("makes optional field %s required", p.Name)
+		case !n.Required && p.Required:
+			return incompatible("makes required field %s optional", p.Name)
+		}
+	}
+	for _, n := range next.Fields {
+		if _, ok := prev.field(n.Name); !ok && n.Required {
+			return incompatible("adds required field %s", n.Name)
+		}
+	}
+	return nil
+}
+
+// normalize converts a payload to the JSON types consumers decode
+func normalize(payload map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+func kindOf(value interface{}) Kind {
+	switch value.(type) {
+	case string:
+		return String
+	case float64:
+		return Number
+	case bool:
+		return Bool
+	case map[string]interface{}:
+		return Object
+	case []interface{}:
+		return Array
+	default:
+		return Any
+	}
+}
@@ -0,0 +1,23 @@
+package events
+
+import (
+	"context"
+
+	"github.com/linkflow-go/pkg/tenant"
+)
+
+// InjectTenant stores the tenant of ctx in the event metadata, keeping the
+// tenant of events published on behalf of another
+func InjectTenant(ctx context.Context, event *Event) {
+	if event.Metadata.TenantID == "" {
+		event.Metadata.TenantID = tenant.FromContext(ctx)
+	}
+}
+
+// ExtractTenant returns ctx scoped to the tenant stored in the event
+func ExtractTenant(ctx context.Context, event Event) context.Context {
+	if event.Metadata.TenantID == "" {
+		return ctx
+	}
+	return tenant.WithTenant(ctx, event.Metadata.TenantID)
+}
@@ -0,0 +1,74 @@
+package events
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "linkflow/events"
+
+// InjectTraceContext stores the trace of ctx in the event metadata
+func InjectTraceContext(ctx context.Context, event *Event) {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	event.Metadata.TraceID = spanCtx.TraceID().String()
+	event.Metadata.SpanID = spanCtx.SpanID().String()
+	event.Metadata.TraceContext = carrier
+}
+
+// ExtractTraceContext returns ctx continuing the trace stored in the event
+func ExtractTraceContext(ctx context.Context, event Event) context.Context {
+	if len(event.Metadata.TraceContext) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(event.Metadata.TraceContext))
+}
+
+// startPublishSpan starts a producer span and stores it in the event so the
+// consumer span becomes its child
+func startPublishSpan(ctx context.Context, event *Event) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "publish "+event.Type,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(eventAttributes(*event)...),
+	)
+	InjectTraceContext(ctx, event)
+	InjectTenant(ctx, event)
+	return ctx, span
+}
+
+// startConsumeSpan starts a consumer span continuing the publisher's trace
+func startConsumeSpan(ctx context.Context, event Event) (context.Context, trace.Span) {
+	ctx = ExtractTenant(ExtractTraceContext(ctx, event), event)
+	return otel.Tracer(tracerName).Start(ctx, "consume "+event.Type,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(eventAttributes(event)...),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func eventAttributes(event Event) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.message.id", event.ID),
+		attribute.String("event.type", event.Type),
+		attribute.String("event.aggregate_id", event.AggregateID),
+	}
+}
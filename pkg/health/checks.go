@@ -0,0 +1,69 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/linkflow-go/pkg/database"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/redis/go-redis/v9"
+)
+
+// Pinger is implemented by dependencies that can report their own health
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Database pings the database connection pool
+func Database(db *database.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// Redis pings the Redis server
+func Redis(client *redis.Client) CheckFunc {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// EventBus pings the event bus brokers. Buses that cannot ping are assumed
+// up.
+func EventBus(bus events.EventBus) CheckFunc {
+	return func(ctx context.Context) error {
+		if p, ok := bus.(Pinger); ok {
+			return p.Ping(ctx)
+		}
+		return nil
+	}
+}
+
+// HTTP checks a downstream service answers url without a server error
+func HTTP(client *http.Client, url string) CheckFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
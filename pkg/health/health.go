@@ -0,0 +1,183 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Status of a dependency or of a whole service
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// DefaultTimeout bounds each dependency check so a hung dependency cannot
+// hang the readiness probe
+const DefaultTimeout = 2 * time.Second
+
+// CheckFunc checks a single dependency, returning nil when it is usable
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of a single dependency check
+type CheckResult struct {
+	Status    Status  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the readiness of a service with the detail of each dependency
+type Report struct {
+	Service   string                 `json:"service"`
+	Status    Status                 `json:"status"`
+	Checks    map[string]CheckResult `json:"checks"`
+	CheckedAt time.Time              `json:"checkedAt"`
+}
+
+type check struct {
+	name     string
+	fn       CheckFunc
+	critical bool
+}
+
+// Checker runs the dependency checks of a service. A failing critical
+// dependency takes the service down, a failing optional one only degrades
+// it so it keeps receiving traffic.
+type Checker struct {
+	service string
+	timeout time.Duration
+	mu      sync.RWMutex
+	checks  []check
+}
+
+// NewChecker creates a checker for service
+func NewChecker(service string) *Checker {
+	return &Checker{
+		service: service,
+		timeout: DefaultTimeout,
+	}
+}
+
+// SetTimeout overrides the per-check timeout
+func (c *Checker) SetTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeout = timeout
+}
+
+// Critical registers a dependency the service cannot serve without
+func (c *Checker) Critical(name string, fn CheckFunc) {
+	c.add(check{name: name, fn: fn, critical: true})
+}
+
+// Optional registers a dependency the service degrades without
+func (c *Checker) Optional(name string, fn CheckFunc) {
+	c.add(check{name: name, fn: fn})
+}
+
+func (c *Checker) add(ch check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, ch)
+}
+
+// Check runs all dependency checks concurrently and aggregates them
+func (c *Checker) Check(ctx context.Context) *Report {
+	c.mu.RLock()
+	checks := make([]check, len(c.checks))
+	copy(checks, c.checks)
+	timeout := c.timeout
+	c.mu.RUnlock()
+
+	report := &Report{
+		Service:   c.service,
+		Status:    StatusUp,
+		Checks:    make(map[string]CheckResult, len(checks)),
+		CheckedAt: time.Now(),
+	}
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, ch := range checks {
+		wg.Add(1)
+		go func(i int, ch check) {
+			defer wg.Done()
+			results[i] = run(ctx, ch, timeout)
+		}(i, ch)
+	}
+	wg.Wait()
+
+	for i, ch := range checks {
+		result := results[i]
+		report.Checks[ch.name] = result
+
+		if result.Status != StatusDown {
+			continue
+		}
+		if ch.critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+// ReadyHandler serves the readiness report. Degraded services still answer
+// 200 so load balancers keep routing to them.
+func (c *Checker) ReadyHandler() gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		report := c.Check(gc.Request.Context())
+
+		status := http.StatusOK
+		if report.Status == StatusDown {
+			status = http.StatusServiceUnavailable
+		}
+		gc.JSON(status, report)
+	}
+}
+
+// run executes a check, giving up after timeout even if the check ignores
+// its context
+func run(ctx context.Context, ch check, timeout time.Duration) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("check panicked: %v", r)
+			}
+		}()
+		done <- ch.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+
+	result := CheckResult{
+		Status:    StatusUp,
+		Critical:  ch.critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// batchBuffer bounds the lines waiting to be shipped, lines beyond it are
+// dropped rather than blocking the service
+const batchBuffer = 10000
+
+// shipTimeout bounds a single push to a remote sink
+const shipTimeout = 10 * time.Second
+
+// logLine is an encoded log line and the time it was written
+type logLine struct {
+	time time.Time
+	data []byte
+}
+
+// shipper delivers a batch of lines to a remote sink
+type shipper interface {
+	ship(ctx context.Context, lines []logLine) error
+}
+
+// batchWriter buffers lines and ships them in batches from a background
+// goroutine, so a slow or unreachable sink never slows down logging
+type batchWriter struct {
+	shipper   shipper
+	batchSize int
+	interval  time.Duration
+
+	lines  chan logLine
+	syncCh chan chan struct{}
+
+	mu      sync.Mutex
+	dropped int
+}
+
+func newBatchWriter(s shipper, batchSize, flushInterval int) *batchWriter {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = DefaultFlushInterval
+	}
+
+	w := &batchWriter{
+		shipper:   s,
+		batchSize: batchSize,
+		interval:  time.Duration(flushInterval) * time.Second,
+		lines:     make(chan logLine, batchBuffer),
+		syncCh:    make(chan chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write implements zapcore.WriteSyncer, p is reused by zap and is copied
+func (w *batchWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	select {
+	case w.lines <- logLine{time: time.Now(), data: data}:
+	default:
+		w.mu.Lock()
+		w.dropped++
+		w.mu.Unlock()
+	}
+	return len(p), nil
+}
+
+// Sync ships the buffered lines, waiting at most one push
+func (w *batchWriter) Sync() error {
+	done := make(chan struct{})
+	select {
+	case w.syncCh <- done:
+	case <-time.After(shipTimeout):
+		return fmt.Errorf("log sink did not flush within %s", shipTimeout)
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(shipTimeout):
+		return fmt.Errorf("log sink did not flush within %s", shipTimeout)
+	}
+}
+
+func (w *batchWriter) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	batch := make([]logLine, 0, w.batchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			w.ship(batch)
+			batch = make([]logLine, 0, w.batchSize)
+		}
+		w.reportDropped()
+	}
+
+	for {
+		select {
+		case line := <-w.lines:
+			batch = append(batch, line)
+			if len(batch) >= w.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case done := <-w.syncCh:
+			// Take what is buffered at the time of the sync
+			for n := len(w.lines); n > 0; n-- {
+				batch = append(batch, <-w.lines)
+			}
+			flush()
+			close(done)
+		}
+	}
+}
+
+// ship reports failures on stderr, logging them would feed the failing sink
+func (w *batchWriter) ship(batch []logLine) {
+	ctx, cancel := context.WithTimeout(context.Background(), shipTimeout)
+	defer cancel()
+
+	if err := w.shipper.ship(ctx, batch); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to ship %d log lines: %v\n", len(batch), err)
+	}
+}
+
+func (w *batchWriter) reportDropped() {
+	w.mu.Lock()
+	dropped := w.dropped
+	w.dropped = 0
+	w.mu.Unlock()
+
+	if dropped > 0 {
+		fmt.Fprintf(os.Stderr, "dropped %d log lines, the log sink cannot keep up\n", dropped)
+	}
+}
@@ -0,0 +1,75 @@
+package logger
+
+import "context"
+
+// CorrelationIDHeader carries the correlation ID of a request between
+// services and back to the client
+const CorrelationIDHeader = "X-Request-ID"
+
+type fieldsKey struct{}
+
+type correlationIDKey struct{}
+
+type debugKey struct{}
+
+// WithFields returns a context whose logs carry fields in addition to the
+// fields already stored in ctx
+func WithFields(ctx context.Context, fields ...interface{}) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	existing, _ := ctx.Value(fieldsKey{}).([]interface{})
+	merged := make([]interface{}, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// WithCorrelationID returns a context carrying the correlation ID, logged
+// as correlationId and copied into published events
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" || CorrelationID(ctx) == id {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, correlationIDKey{}, id)
+	return WithFields(ctx, "correlationId", id)
+}
+
+// CorrelationID returns the correlation ID of ctx, empty if there is none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// WithDebug returns a context whose debug logs are written whatever the
+// level of the logger, logged with debug set. It raises the verbosity of a
+// single run without touching the level of the service, the run ends and
+// its context with it.
+func WithDebug(ctx context.Context) context.Context {
+	if Debugging(ctx) {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, debugKey{}, true)
+	return WithFields(ctx, "debug", true)
+}
+
+// Debugging reports whether ctx asks for debug logs
+func Debugging(ctx context.Context) bool {
+	debug, _ := ctx.Value(debugKey{}).(bool)
+	return debug
+}
+
+// FromContext returns l with the fields stored in ctx
+func FromContext(ctx context.Context, l Logger) Logger {
+	fields, _ := ctx.Value(fieldsKey{}).([]interface{})
+	if len(fields) > 0 {
+		l = l.With(fields...)
+	}
+	if Debugging(ctx) {
+		if zl, ok := l.(*zapLogger); ok {
+			l = &zapLogger{logger: zl.logger, level: zl.level, debug: true}
+		}
+	}
+	return l
+}
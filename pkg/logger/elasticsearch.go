@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultIndex prefixes the daily log indices in Elasticsearch
+const DefaultIndex = "linkflow-logs"
+
+// elasticsearchIndexer ships lines with the bulk API, each line becomes a
+// document of the index of its day
+type elasticsearchIndexer struct {
+	url    string
+	cfg    ElasticsearchConfig
+	client *http.Client
+}
+
+func newElasticsearchIndexer(cfg ElasticsearchConfig) *elasticsearchIndexer {
+	if cfg.Index == "" {
+		cfg.Index = DefaultIndex
+	}
+
+	return &elasticsearchIndexer{
+		url:    strings.TrimRight(cfg.URL, "/") + "/_bulk",
+		cfg:    cfg,
+		client: &http.Client{Timeout: shipTimeout},
+	}
+}
+
+func (e *elasticsearchIndexer) ship(ctx context.Context, lines []logLine) error {
+	var body bytes.Buffer
+	for _, line := range lines {
+		index := e.cfg.Index + "-" + line.time.UTC().Format("2006.01.02")
+		fmt.Fprintf(&body, `{"create":{"_index":%q}}`+"\n", index)
+		body.Write(bytes.TrimRight(line.data, "\n"))
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if e.cfg.Username != "" {
+		req.SetBasicAuth(e.cfg.Username, e.cfg.Password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("elasticsearch returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	// The bulk API answers 200 even when documents are rejected
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  struct {
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	failed, reason := 0, ""
+	for _, item := range result.Items {
+		for _, op := range item {
+			if op.Status >= http.StatusMultipleChoices {
+				failed++
+				reason = op.Error.Reason
+			}
+		}
+	}
+	return fmt.Errorf("elasticsearch rejected %d of %d lines: %s", failed, len(lines), reason)
+}
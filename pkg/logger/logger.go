@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"fmt"
 	"os"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -18,6 +20,10 @@ type Logger interface {
 
 type zapLogger struct {
 	logger *zap.SugaredLogger
+	level  zap.AtomicLevel
+	// debug writes debug lines at info level so they pass the level of
+	// the service, see WithDebug
+	debug bool
 }
 
 type Config struct {
@@ -26,56 +32,49 @@ type Config struct {
 	Output     string `mapstructure:"output"`
 	AddCaller  bool   `mapstructure:"add_caller"`
 	Stacktrace bool   `mapstructure:"stacktrace"`
+	// Service labels the lines shipped to remote sinks
+	Service string `mapstructure:"service"`
+	// Sinks selects the outputs, any of stdout, file, loki and
+	// elasticsearch. Without sinks logs are written to Output.
+	Sinks         []string            `mapstructure:"sinks"`
+	File          FileConfig          `mapstructure:"file"`
+	Loki          LokiConfig          `mapstructure:"loki"`
+	Elasticsearch ElasticsearchConfig `mapstructure:"elasticsearch"`
 }
 
 func New(cfg Config) Logger {
-	config := zap.NewProductionConfig()
-
 	// Set log level
 	level, err := zapcore.ParseLevel(cfg.Level)
 	if err != nil {
 		level = zapcore.InfoLevel
 	}
-	config.Level = zap.NewAtomicLevelAt(level)
+	atomicLevel := zap.NewAtomicLevelAt(level)
 
-	// Set output format
-	if cfg.Format == "console" {
-		config.Encoding = "console"
-		config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-	} else {
-		config.Encoding = "json"
-	}
-
-	// Set output
-	if cfg.Output == "stdout" {
-		config.OutputPaths = []string{"stdout"}
-		config.ErrorOutputPaths = []string{"stderr"}
-	} else {
-		config.OutputPaths = []string{cfg.Output}
-		config.ErrorOutputPaths = []string{cfg.Output}
+	cores, err := buildCores(cfg, atomicLevel)
+	if err != nil {
+		// Fallback to stdout rather than running blind
+		fmt.Fprintf(os.Stderr, "failed to set up log sinks, logging to stdout: %v\n", err)
+		cores = []zapcore.Core{zapcore.NewCore(outputEncoder(cfg), stdout(), atomicLevel)}
 	}
+	core := zapcore.NewTee(cores...)
 
-	// Add caller info
+	// Skip the wrapper methods of zapLogger when reporting the caller
+	opts := []zap.Option{zap.ErrorOutput(zapcore.Lock(os.Stderr)), zap.AddCallerSkip(1)}
 	if cfg.AddCaller {
-		config.EncoderConfig.CallerKey = "caller"
-		config.EncoderConfig.EncodeCaller = zapcore.ShortCallerEncoder
+		opts = append(opts, zap.AddCaller())
 	}
 
-	// Add stacktrace
 	if cfg.Stacktrace {
-		config.Development = true
-	}
-
-	// Build logger
-	logger, err := config.Build()
-	if err != nil {
-		// Fallback to default logger
-		logger = zap.NewExample()
+		opts = append(opts, zap.Development(), zap.AddStacktrace(zapcore.WarnLevel))
+	} else {
+		// Same sampling as the zap production preset
+		opts = append(opts, zap.AddStacktrace(zapcore.ErrorLevel))
+		core = zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
 	}
 
 	return &zapLogger{
-		logger: logger.Sugar(),
+		logger: zap.New(core, opts...).Sugar(),
+		level:  atomicLevel,
 	}
 }
 
@@ -91,10 +90,15 @@ func NewDefault() Logger {
 func NewNop() Logger {
 	return &zapLogger{
 		logger: zap.NewNop().Sugar(),
+		level:  zap.NewAtomicLevel(),
 	}
 }
 
 func (l *zapLogger) Debug(msg string, fields ...interface{}) {
+	if l.debug {
+		l.logger.Infow(msg, fields...)
+		return
+	}
 	l.logger.Debugw(msg, fields...)
 }
 
@@ -118,7 +122,36 @@ func (l *zapLogger) Fatal(msg string, fields ...interface{}) {
 func (l *zapLogger) With(fields ...interface{}) Logger {
 	return &zapLogger{
 		logger: l.logger.With(fields...),
+		level:  l.level,
+		debug:  l.debug,
+	}
+}
+
+// SetLevel changes the level of a logger at runtime. Loggers derived with
+// With share the level of their parent, so setting it on the root logger
+// applies to the whole service
+func SetLevel(l Logger, level string) error {
+	zl, ok := l.(*zapLogger)
+	if !ok {
+		return fmt.Errorf("logger does not support changing the level")
+	}
+
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	zl.level.SetLevel(parsed)
+	return nil
+}
+
+// Sync flushes the lines buffered by the sinks of a logger, call it before
+// the service exits
+func Sync(l Logger) error {
+	zl, ok := l.(*zapLogger)
+	if !ok {
+		return nil
 	}
+	return zl.logger.Sync()
 }
 
 // Helper functions for structured logging
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// lokiPusher ships lines to the Loki push API as a single stream labeled
+// with the service and the configured labels. Levels and fields stay in the
+// JSON line, query them with `| json`.
+type lokiPusher struct {
+	url    string
+	cfg    LokiConfig
+	labels map[string]string
+	client *http.Client
+}
+
+func newLokiPusher(cfg LokiConfig, service string) *lokiPusher {
+	labels := map[string]string{"app": "linkflow"}
+	if service != "" {
+		labels["service"] = service
+	}
+	for k, v := range cfg.Labels {
+		labels[k] = v
+	}
+
+	return &lokiPusher{
+		url:    strings.TrimRight(cfg.URL, "/") + "/loki/api/v1/push",
+		cfg:    cfg,
+		labels: labels,
+		client: &http.Client{Timeout: shipTimeout},
+	}
+}
+
+func (p *lokiPusher) ship(ctx context.Context, lines []logLine) error {
+	values := make([][2]string, len(lines))
+	for i, line := range lines {
+		values[i] = [2]string{
+			strconv.FormatInt(line.time.UnixNano(), 10),
+			string(bytes.TrimRight(line.data, "\n")),
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"streams": []map[string]interface{}{
+			{"stream": p.labels, "values": values},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.cfg.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", p.cfg.TenantID)
+	}
+	if p.cfg.Username != "" {
+		req.SetBasicAuth(p.cfg.Username, p.cfg.Password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("loki returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
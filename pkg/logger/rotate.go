@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotation defaults of the file sink
+const (
+	DefaultMaxSizeMB  = 100
+	DefaultMaxBackups = 7
+)
+
+// backupTimeFormat sorts backups chronologically by name
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotatingFile writes to a file and moves it aside once it reaches the
+// maximum size, e.g. service.log becomes service-2024-01-02T15-04-05.000.log.
+// Backups beyond the maximum count or age are removed.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+
+	file *os.File
+	size int64
+}
+
+func newRotatingFile(cfg FileConfig) (*rotatingFile, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("file log sink needs a path")
+	}
+	if cfg.MaxSizeMB <= 0 {
+		cfg.MaxSizeMB = DefaultMaxSizeMB
+	}
+	if cfg.MaxBackups <= 0 {
+		cfg.MaxBackups = DefaultMaxBackups
+	}
+
+	f := &rotatingFile{
+		path:       cfg.Path,
+		maxSize:    int64(cfg.MaxSizeMB) * 1024 * 1024,
+		maxBackups: cfg.MaxBackups,
+		maxAge:     time.Duration(cfg.MaxAgeDays) * 24 * time.Hour,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			// Keep writing to the current file rather than losing lines
+			fmt.Fprintf(os.Stderr, "failed to rotate %s: %v\n", f.path, err)
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Sync()
+}
+
+func (f *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *rotatingFile) rotate() error {
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(f.path, ext) + "-"
+	backup := prefix + time.Now().UTC().Format(backupTimeFormat) + ext
+
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.path, backup); err != nil {
+		// Reopen so the sink keeps working
+		if openErr := f.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.prune(prefix, ext)
+	return nil
+}
+
+// prune removes the oldest backups beyond the limits
+func (f *rotatingFile) prune(prefix, ext string) {
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return
+	}
+
+	// Leave files that merely share the prefix alone
+	var backups []string
+	for _, match := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(match, prefix), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	for i, backup := range backups {
+		expired := false
+		if f.maxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && time.Since(info.ModTime()) > f.maxAge {
+				expired = true
+			}
+		}
+		if i >= f.maxBackups || expired {
+			os.Remove(backup)
+		}
+	}
+}
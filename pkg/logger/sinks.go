@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Sinks a logger can write to, selected with Config.Sinks
+const (
+	SinkStdout        = "stdout"
+	SinkFile          = "file"
+	SinkLoki          = "loki"
+	SinkElasticsearch = "elasticsearch"
+)
+
+// Batching defaults of the Loki and Elasticsearch sinks
+const (
+	DefaultBatchSize     = 500
+	DefaultFlushInterval = 2 // seconds
+)
+
+// FileConfig writes logs to a file rotated by size
+type FileConfig struct {
+	Path       string `mapstructure:"path"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`
+	MaxAgeDays int    `mapstructure:"max_age_days"` // 0 keeps backups regardless of age
+}
+
+// LokiConfig pushes logs to the Loki push API
+type LokiConfig struct {
+	URL      string            `mapstructure:"url"`
+	TenantID string            `mapstructure:"tenant_id"`
+	Username string            `mapstructure:"username"`
+	Password string            `mapstructure:"password"`
+	Labels   map[string]string `mapstructure:"labels"`
+	// BatchSize and FlushInterval (seconds) bound how long lines are buffered
+	BatchSize     int `mapstructure:"batch_size"`
+	FlushInterval int `mapstructure:"flush_interval"`
+}
+
+// ElasticsearchConfig indexes logs with the bulk API into daily indices
+// named <index>-YYYY.MM.DD
+type ElasticsearchConfig struct {
+	URL           string `mapstructure:"url"`
+	Username      string `mapstructure:"username"`
+	Password      string `mapstructure:"password"`
+	Index         string `mapstructure:"index"`
+	BatchSize     int    `mapstructure:"batch_size"`
+	FlushInterval int    `mapstructure:"flush_interval"`
+}
+
+// buildCores creates a core per configured sink. Without sinks the logger
+// writes to Output as before sinks existed.
+func buildCores(cfg Config, level zapcore.LevelEnabler) ([]zapcore.Core, error) {
+	if len(cfg.Sinks) == 0 {
+		out, _, err := zap.Open(cfg.Output)
+		if err != nil {
+			return nil, err
+		}
+		return []zapcore.Core{zapcore.NewCore(outputEncoder(cfg), out, level)}, nil
+	}
+
+	var cores []zapcore.Core
+	for _, sink := range cfg.Sinks {
+		switch sink {
+		case SinkStdout:
+			cores = append(cores, zapcore.NewCore(outputEncoder(cfg), stdout(), level))
+		case SinkFile:
+			file, err := newRotatingFile(cfg.File)
+			if err != nil {
+				return nil, err
+			}
+			cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(jsonEncoderConfig()), file, level))
+		case SinkLoki:
+			writer := newBatchWriter(newLokiPusher(cfg.Loki, cfg.Service), cfg.Loki.BatchSize, cfg.Loki.FlushInterval)
+			cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(jsonEncoderConfig()), writer, level))
+		case SinkElasticsearch:
+			writer := newBatchWriter(newElasticsearchIndexer(cfg.Elasticsearch), cfg.Elasticsearch.BatchSize, cfg.Elasticsearch.FlushInterval)
+			cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(elasticsearchEncoderConfig()), writer, level))
+		default:
+			return nil, fmt.Errorf("unknown log sink %q", sink)
+		}
+	}
+	return cores, nil
+}
+
+// stdout hides Sync of os.Stdout, which fails on pipes and terminals and
+// would make flushing the remote sinks look like it failed
+func stdout() zapcore.WriteSyncer {
+	return zapcore.Lock(zapcore.AddSync(struct{ io.Writer }{os.Stdout}))
+}
+
+// outputEncoder encodes stdout and Output lines in the configured format
+func outputEncoder(cfg Config) zapcore.Encoder {
+	if cfg.Format == "console" {
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		return zapcore.NewConsoleEncoder(encoderConfig)
+	}
+	return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+}
+
+// jsonEncoderConfig is used by sinks read by machines, which always get JSON
+func jsonEncoderConfig() zapcore.EncoderConfig {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	return encoderConfig
+}
+
+// elasticsearchEncoderConfig follows the field names of the Elastic Common
+// Schema so Kibana picks up time and message without mapping
+func elasticsearchEncoderConfig() zapcore.EncoderConfig {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "@timestamp"
+	encoderConfig.MessageKey = "message"
+	encoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout(time.RFC3339Nano)
+	return encoderConfig
+}
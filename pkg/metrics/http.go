@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UnmatchedRoute labels requests that matched no route, so scanners probing
+// random paths cannot blow up label cardinality
+const UnmatchedRoute = "unmatched"
+
+// HTTPMiddleware records request counts, latencies and in-flight requests
+// per route for service. Paths are labeled with the route template, e.g.
+// /api/v1/workflows/:id, not the raw URL.
+func HTTPMiddleware(service string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = UnmatchedRoute
+		}
+		method := c.Request.Method
+
+		inFlight := HTTPRequestsInFlight.WithLabelValues(service, method, route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		c.Next()
+
+		RecordHTTPRequest(service, method, route, strconv.Itoa(c.Writer.Status()))
+		RecordHTTPDuration(service, method, route, time.Since(start).Seconds())
+	}
+}
@@ -25,6 +25,14 @@ var (
 		[]string{"service", "method", "path"},
 	)
 
+	HTTPRequestsInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests currently being served",
+		},
+		[]string{"service", "method", "path"},
+	)
+
 	// Workflow metrics
 	WorkflowsTotal = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -69,6 +77,65 @@ var (
 		[]string{"node_type"},
 	)
 
+	// Executor concurrency metrics, component is "pool" for the local worker
+	// pool and "coordinator" for distributed assignment
+	ExecutorQueueDepth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "executor_queue_depth",
+			Help: "Number of work items waiting for a worker",
+		},
+		[]string{"component"},
+	)
+
+	ExecutorRunningExecutions = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "executor_running_executions",
+			Help: "Number of executions or node requests currently running",
+		},
+		[]string{"component"},
+	)
+
+	ExecutorWorkerLoad = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "executor_worker_load",
+			Help: "Number of work items currently held by a worker",
+		},
+		[]string{"component", "worker_id"},
+	)
+
+	ExecutorWorkerCapacity = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "executor_worker_capacity",
+			Help: "Maximum number of work items a worker accepts",
+		},
+		[]string{"component", "worker_id"},
+	)
+
+	ExecutorWorkers = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "executor_workers",
+			Help: "Number of workers by status",
+		},
+		[]string{"component", "status"},
+	)
+
+	ExecutorAssignmentsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "executor_assignments_total",
+			Help: "Total number of work assignments by result",
+		},
+		[]string{"component", "result"},
+	)
+
+	ExecutorAssignmentLatency = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "executor_assignment_latency_seconds",
+			Help:    "Time from receiving work until a worker picks it up",
+			Buckets: []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+		},
+		[]string{"component"},
+	)
+
 	// Database metrics
 	DatabaseConnectionsActive = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -120,6 +187,151 @@ var (
 		},
 		[]string{"cache"},
 	)
+
+	// Soak test metrics, exported by the synthetic workload generator
+	SoakOperationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "soak_operations_total",
+			Help: "Total number of soak test operations by result",
+		},
+		[]string{"operation", "result"},
+	)
+
+	SoakOperationDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "soak_operation_duration_seconds",
+			Help:    "Soak test operation duration in seconds",
+			Buckets: []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"operation"},
+	)
+
+	SoakCyclesInFlight = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "soak_cycles_in_flight",
+			Help: "Number of soak test workflows being created, executed or deleted",
+		},
+	)
+
+	// Redis garbage collection metrics
+	RedisGCKeysScanned = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "redis_gc_keys_scanned_total",
+			Help: "Total number of Redis keys checked for a live owner",
+		},
+		[]string{"owner"},
+	)
+
+	RedisGCKeysReclaimed = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "redis_gc_keys_reclaimed_total",
+			Help: "Total number of orphaned Redis keys deleted",
+		},
+		[]string{"owner"},
+	)
+
+	RedisGCPassDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "redis_gc_pass_duration_seconds",
+			Help:    "Redis garbage collection pass duration in seconds",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60},
+		},
+		[]string{"collector"},
+	)
+
+	ExecutionResultCache = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "execution_result_cache_total",
+			Help: "Total number of idempotent workflow runs served from, missing or stored in the result cache",
+		},
+		[]string{"source", "result"},
+	)
+
+	ExecutionSampling = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "execution_sampling_total",
+			Help: "Total number of successful executions of sampled workflows by sampling decision",
+		},
+		[]string{"decision"},
+	)
+
+	BackfillExecutions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "execution_backfill_executions_total",
+			Help: "Total number of backfill executions started, completed and failed",
+		},
+		[]string{"result"},
+	)
+
+	BillingInvoices = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "billing_invoices_generated_total",
+			Help: "Total number of invoices generated for ended subscription periods",
+		},
+	)
+
+	BillingUsageReports = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "billing_usage_reports_total",
+			Help: "Total number of metered usage reports to payment providers",
+		},
+		[]string{"result"},
+	)
+
+	BudgetThresholds = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "budget_thresholds_reached_total",
+			Help: "Total number of budget thresholds reached by the monthly spend of workflows and workspaces",
+		},
+		[]string{"threshold"},
+	)
+
+	WorkflowAnomalies = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "workflow_anomalies_total",
+			Help: "Total number of anomalous execution durations and error bursts detected",
+		},
+		[]string{"kind"},
+	)
+
+	SLABreaches = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sla_breaches_total",
+			Help: "Total number of SLA objectives of workflows breached",
+		},
+		[]string{"objective"},
+	)
+
+	TenantQuotaWarnings = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenant_quota_warnings_total",
+			Help: "Total number of warnings of tenants nearing a limit of their plan",
+		},
+		[]string{"kind"},
+	)
+
+	TenantQuotaExceeded = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tenant_quota_exceeded_total",
+			Help: "Total number of workflow activations and executions over a limit of the plan of their tenant",
+		},
+		[]string{"kind"},
+	)
+
+	RedisTenantCommandsRejected = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "redis_tenant_commands_rejected_total",
+			Help: "Total number of Redis commands refused by a tenant quota",
+		},
+		[]string{"quota"},
+	)
+
+	RedisTenantsOverMemory = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "redis_tenants_over_memory",
+			Help: "Number of tenants whose Redis keys exceed their memory quota",
+		},
+	)
 )
 
 // RecordHTTPRequest records an HTTP request metric
@@ -151,3 +363,21 @@ func RecordNodeExecution(nodeType, status string) {
 func RecordNodeDuration(nodeType string, duration float64) {
 	NodeExecutionDuration.WithLabelValues(nodeType).Observe(duration)
 }
+
+// RecordAssignment records a work assignment and how long it took
+func RecordAssignment(component, result string, latency float64) {
+	ExecutorAssignmentsTotal.WithLabelValues(component, result).Inc()
+	ExecutorAssignmentLatency.WithLabelValues(component).Observe(latency)
+}
+
+// RecordSoakOperation records a soak test operation and how long it took
+func RecordSoakOperation(operation, result string, duration float64) {
+	SoakOperationsTotal.WithLabelValues(operation, result).Inc()
+	SoakOperationDuration.WithLabelValues(operation).Observe(duration)
+}
+
+// RecordRedisGC records the keys of an owner scanned and reclaimed by a pass
+func RecordRedisGC(owner string, scanned, reclaimed int) {
+	RedisGCKeysScanned.WithLabelValues(owner).Add(float64(scanned))
+	RedisGCKeysReclaimed.WithLabelValues(owner).Add(float64(reclaimed))
+}
@@ -43,8 +43,12 @@ func APIKeyMiddleware(apiKeyValidator APIKeyValidator) gin.HandlerFunc {
 
 		// Set user context from API key
 		c.Set("userId", key.UserID)
+		c.Set("tenantId", key.TenantID)
 		c.Set("apiKeyId", key.ID)
 		c.Set("apiKeyPermissions", key.Permissions)
+		c.Set("workspaceId", key.Scope.WorkspaceID)
+		c.Set("plan", key.Scope.Plan)
+		c.Set("tokenScope", key.Scope)
 		c.Set("authMethod", "apikey")
 
 		c.Next()
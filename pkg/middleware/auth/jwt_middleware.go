@@ -90,12 +90,44 @@ func (m *JWTMiddleware) Handle() gin.HandlerFunc {
 		c.Set("email", claims.Email)
 		c.Set("roles", claims.Roles)
 		c.Set("permissions", claims.Permissions)
+		c.Set("tenantId", claims.TenantID)
+		c.Set("workspaceId", claims.WorkspaceID)
+		c.Set("plan", claims.Plan)
+		c.Set("tokenScope", claims.TokenScope)
 		c.Set("token", token)
 
 		c.Next()
 	}
 }
 
+// RequireScope creates a middleware that makes a fast-path allow/deny
+// decision from the scope and quota hints embedded in the token. An empty
+// quota kind only checks the scope.
+func RequireScope(scope, quotaKind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenScope, ok := GetTokenScope(c)
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "no token scope found in context"})
+			c.Abort()
+			return
+		}
+
+		if !tokenScope.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "token scope does not allow this operation"})
+			c.Abort()
+			return
+		}
+
+		if quotaKind != "" && !tokenScope.Quota.Allows(quotaKind) {
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": "plan does not include " + quotaKind})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // RequireRoles creates a middleware that checks if user has any of the required roles
 func RequireRoles(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -218,3 +250,14 @@ func GetUserPermissions(c *gin.Context) ([]string, bool) {
 	permsList, ok := permissions.([]string)
 	return permsList, ok
 }
+
+// GetTokenScope extracts the token scope from context
+func GetTokenScope(c *gin.Context) (jwt.TokenScope, bool) {
+	scope, exists := c.Get("tokenScope")
+	if !exists {
+		return jwt.TokenScope{}, false
+	}
+
+	tokenScope, ok := scope.(jwt.TokenScope)
+	return tokenScope, ok
+}
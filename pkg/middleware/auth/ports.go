@@ -1,12 +1,19 @@
 package auth
 
-import "context"
+import (
+	"context"
+
+	"github.com/linkflow-go/pkg/auth/jwt"
+)
 
 // APIKeyInfo is the minimal API key payload needed by middleware.
 type APIKeyInfo struct {
 	ID          string
 	UserID      string
+	TenantID    string
 	Permissions []string
+	// Scope is checked by RequireScope as the scope of a token is
+	Scope jwt.TokenScope
 }
 
 // APIKeyValidator validates an API key and returns key metadata for request context.
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/pkg/auth/signedurl"
+)
+
+// SignedURLMiddleware authorizes downloads through time-limited signed URLs
+// instead of bearer credentials
+func SignedURLMiddleware(signer *signedurl.Signer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if signer == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "signed urls are not configured"})
+			c.Abort()
+			return
+		}
+
+		if err := signer.Verify(c.Request.URL.Path, c.Request.URL.Query()); err != nil {
+			status := http.StatusForbidden
+			if errors.Is(err, signedurl.ErrExpired) {
+				status = http.StatusGone
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("authMethod", "signedurl")
+		c.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package correlation
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/logger"
+)
+
+// maxIDLength bounds client supplied IDs, they end up in every log line
+const maxIDLength = 128
+
+// Middleware assigns each request a correlation ID, reusing the one sent by
+// the caller. The ID is returned in the response, stored in the request
+// context for logs and events, and forwarded by traced HTTP clients.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(logger.CorrelationIDHeader)
+		if !valid(id) {
+			id = uuid.New().String()
+		}
+
+		c.Set("correlationId", id)
+		c.Header(logger.CorrelationIDHeader, id)
+		c.Request = c.Request.WithContext(logger.WithCorrelationID(c.Request.Context(), id))
+
+		c.Next()
+	}
+}
+
+// valid rejects IDs that could forge log lines or bloat them
+func valid(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,193 @@
+// Package security hardens HTTP APIs called from browsers: CORS restricted
+// to the configured origins, CSRF protection of cookie sessions with a
+// double submit token and the standard security headers.
+package security
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/pkg/config"
+	apperrors "github.com/linkflow-go/pkg/errors"
+)
+
+// ErrCSRF is returned to requests of a cookie session without a valid token
+var ErrCSRF = apperrors.New(apperrors.CategoryPermission, "CSRF_TOKEN_INVALID", "missing or invalid CSRF token")
+
+// CORS answers preflight requests and lets the pages of the allowed origins
+// read responses. Requests from other origins get no CORS headers, so
+// browsers keep their pages from reading the responses.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(cfg.MaxAge)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		// Responses differ by origin, caches must tell them apart
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		if !originAllowed(cfg.AllowedOrigins, origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if cfg.AllowCredentials || !anyOrigin(cfg.AllowedOrigins) {
+			c.Header("Access-Control-Allow-Origin", origin)
+		} else {
+			c.Header("Access-Control-Allow-Origin", "*")
+		}
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			if cfg.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if exposed != "" {
+			c.Header("Access-Control-Expose-Headers", exposed)
+		}
+		c.Next()
+	}
+}
+
+// originAllowed matches an origin against exact origins, "*" and wildcard
+// subdomains such as https://*.example.com
+func originAllowed(allowed []string, origin string) bool {
+	for _, pattern := range allowed {
+		if pattern == "*" || strings.EqualFold(pattern, origin) {
+			return true
+		}
+		scheme, host, ok := strings.Cut(pattern, "://*.")
+		if !ok {
+			continue
+		}
+		prefix := scheme + "://"
+		if strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, "."+host) &&
+			len(origin) > len(prefix)+len(host)+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func anyOrigin(allowed []string) bool {
+	for _, pattern := range allowed {
+		if pattern == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// CSRF protects the sessions held in cookies. Safe requests are handed a
+// token in a cookie readable by the pages of the app, which send it back
+// in a header with every unsafe request of the session. Another site can
+// make the browser send the cookie but cannot read it to set the header.
+// Requests without a session cookie carry no ambient credentials and pass.
+func CSRF(cfg config.CSRFConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, _ := c.Cookie(cfg.CookieName)
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			if token == "" {
+				if token = newToken(); token != "" {
+					setTokenCookie(c, cfg, token)
+				}
+			}
+			c.Next()
+			return
+		}
+
+		if !hasSession(c, cfg.SessionCookies) {
+			c.Next()
+			return
+		}
+
+		sent := c.GetHeader(cfg.HeaderName)
+		if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(apperrors.ToHTTP(ErrCSRF))
+			return
+		}
+		c.Next()
+	}
+}
+
+func hasSession(c *gin.Context, cookies []string) bool {
+	for _, name := range cookies {
+		if value, err := c.Cookie(name); err == nil && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// setTokenCookie stores the token for the pages of the app, it is not
+// HttpOnly so scripts can copy it into the header
+func setTokenCookie(c *gin.Context, cfg config.CSRFConfig, token string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     cfg.CookieName,
+		Value:    token,
+		Path:     "/",
+		Secure:   cfg.Secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// newToken returns a random token, empty when the system has no entropy
+func newToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// Headers sets the standard security headers of every response. Handlers
+// serving pages may replace the Content-Security-Policy with their own.
+func Headers(cfg config.SecurityHeadersConfig) gin.HandlerFunc {
+	hsts := "max-age=" + strconv.Itoa(cfg.HSTSMaxAge) + "; includeSubDomains"
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		if cfg.FrameOptions != "" {
+			h.Set("X-Frame-Options", cfg.FrameOptions)
+		}
+		if cfg.ReferrerPolicy != "" {
+			h.Set("Referrer-Policy", cfg.ReferrerPolicy)
+		}
+		if cfg.ContentSecurityPolicy != "" {
+			h.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+		// Browsers ignore HSTS received over plain HTTP
+		if cfg.HSTSMaxAge > 0 && (c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https") {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package tenant
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/pkg/tenant"
+)
+
+// Middleware scopes the request to a tenant, so the queries of its handlers
+// only see the rows of the tenant. The tenant is the one of the token or API
+// key validated by the auth middleware before, which sets tenantId from its
+// claims; callers authenticated so cannot pick another tenant. Otherwise it
+// is the one the gateway or the calling service names in
+// tenant.InternalHeader, else the default tenant. Tokens issued before
+// tenancy belong to the default tenant.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id string
+		if claimed, authenticated := c.Get("tenantId"); authenticated {
+			id, _ = claimed.(string)
+		} else {
+			id = c.GetHeader(tenant.InternalHeader)
+		}
+		if !tenant.Valid(id) {
+			id = tenant.DefaultTenant
+		}
+
+		c.Set("tenantId", id)
+		c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), id))
+
+		c.Next()
+	}
+}
+
+// StripInternal removes the headers only services may set from the requests
+// of clients, mounted first on the gateway
+func StripInternal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Header.Del(tenant.InternalHeader)
+		c.Next()
+	}
+}
@@ -0,0 +1,142 @@
+// Package outbox stores events in the database with the state change that
+// raised them and relays them to the event bus in the background, so an
+// event is published once its transaction commits even when the bus is
+// briefly unreachable.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/database"
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/logger"
+)
+
+// Message is an event waiting in the outbox or already relayed
+type Message struct {
+	ID          string     `gorm:"primaryKey"`
+	Source      string     `gorm:"not null"`
+	EventType   string     `gorm:"column:event_type;not null"`
+	AggregateID string     `gorm:"column:aggregate_id"`
+	Event       string     `gorm:"type:jsonb;not null"`
+	Attempts    int        `gorm:"not null;default:0"`
+	LastError   string     `gorm:"column:last_error"`
+	AvailableAt time.Time  `gorm:"column:available_at;not null"`
+	CreatedAt   time.Time  `gorm:"column:created_at;not null"`
+	PublishedAt *time.Time `gorm:"column:published_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Message) TableName() string {
+	return "event_outbox"
+}
+
+// Outbox is an events.EventBus whose Publish stores the event in the
+// database, inside the transaction of database.RunInTx when the context
+// carries one. Subscriptions go to the underlying bus.
+type Outbox struct {
+	db     *database.DB
+	bus    events.EventBus
+	source string
+	logger logger.Logger
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// New creates the outbox of source, the service whose events it relays
+func New(db *database.DB, bus events.EventBus, source string, log logger.Logger) *Outbox {
+	return &Outbox{
+		db:     db,
+		bus:    bus,
+		source: source,
+		logger: log,
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Publish stores the event for relaying. The event carries the correlation
+// ID and trace of ctx, so consumers see the request that raised it.
+func (o *Outbox) Publish(ctx context.Context, event events.Event) error {
+	// Reject invalid events before they commit, the relay could never
+	// publish them
+	if err := events.Validate(event); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = now
+	}
+	if event.Metadata.CorrelationID == "" {
+		event.Metadata.CorrelationID = logger.CorrelationID(ctx)
+	}
+	events.InjectTraceContext(ctx, &event)
+	events.InjectTenant(ctx, &event)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	msg := &Message{
+		ID:          uuid.New().String(),
+		Source:      o.source,
+		EventType:   event.Type,
+		AggregateID: event.AggregateID,
+		Event:       string(data),
+		AvailableAt: now,
+		CreatedAt:   now,
+	}
+	if err := o.db.WithContext(ctx).Create(msg).Error; err != nil {
+		return fmt.Errorf("failed to store event in outbox: %w", err)
+	}
+	return nil
+}
+
+// Subscribe subscribes to the underlying bus, consuming is not affected by
+// the outbox
+func (o *Outbox) Subscribe(topic string, handler events.EventHandler) error {
+	return o.bus.Subscribe(topic, handler)
+}
+
+// Ping reports the health of the underlying bus
+func (o *Outbox) Ping(ctx context.Context) error {
+	if p, ok := o.bus.(interface{ Ping(context.Context) error }); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// Start relays stored events to the bus until Close
+func (o *Outbox) Start() {
+	o.startOnce.Do(func() {
+		go o.run()
+	})
+}
+
+// Close stops relaying and closes the underlying bus. Events not relayed yet
+// stay in the outbox for the next start.
+func (o *Outbox) Close() error {
+	o.stopOnce.Do(func() {
+		close(o.stopCh)
+	})
+	// A relay that never started has nothing to finish
+	o.startOnce.Do(func() {
+		close(o.done)
+	})
+	<-o.done
+
+	return o.bus.Close()
+}
@@ -0,0 +1,153 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/linkflow-go/pkg/events"
+	"github.com/linkflow-go/pkg/logger"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// pollInterval is how often the relay looks for stored events
+	pollInterval = time.Second
+
+	// batchSize bounds the events relayed per transaction
+	batchSize = 100
+
+	// publishTimeout bounds the publish of a single event
+	publishTimeout = 10 * time.Second
+
+	// Failed events are retried with exponential backoff, up to maxBackoff
+	// between attempts. They are never dropped.
+	baseBackoff = time.Second
+	maxBackoff  = 5 * time.Minute
+
+	// retention is how long relayed events are kept for inspection
+	retention     = 24 * time.Hour
+	pruneInterval = time.Hour
+)
+
+func (o *Outbox) run() {
+	defer close(o.done)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	lastPrune := time.Time{}
+	for {
+		// Keep going while full batches come back, there is a backlog
+		for {
+			n, err := o.relayBatch()
+			if err != nil {
+				o.logger.Error("Failed to relay outbox events", "source", o.source, "error", err)
+				break
+			}
+			if n < batchSize {
+				break
+			}
+			select {
+			case <-o.stopCh:
+				return
+			default:
+			}
+		}
+
+		if time.Since(lastPrune) >= pruneInterval {
+			o.prune()
+			lastPrune = time.Now()
+		}
+
+		select {
+		case <-ticker.C:
+		case <-o.stopCh:
+			return
+		}
+	}
+}
+
+// relayBatch publishes the due events of the source and returns how many it
+// claimed. Claimed rows stay locked until the batch commits, so replicas of
+// a service relay disjoint events.
+func (o *Outbox) relayBatch() (int, error) {
+	var claimed int
+	err := o.db.RunInTx(context.Background(), func(ctx context.Context) error {
+		var messages []Message
+		err := o.db.WithContext(ctx).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("source = ? AND published_at IS NULL AND available_at <= ?", o.source, time.Now().UTC()).
+			Order("created_at").
+			Limit(batchSize).
+			Find(&messages).Error
+		if err != nil {
+			return err
+		}
+		claimed = len(messages)
+
+		for i := range messages {
+			msg := &messages[i]
+			updates := o.publish(msg)
+			if err := o.db.WithContext(ctx).Model(msg).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+// publish sends a stored event to the bus and returns the columns recording
+// the outcome
+func (o *Outbox) publish(msg *Message) map[string]interface{} {
+	now := time.Now().UTC()
+
+	var event events.Event
+	err := json.Unmarshal([]byte(msg.Event), &event)
+	if err == nil {
+		// Publish within the trace and correlation ID of the request that
+		// stored the event
+		ctx := logger.WithCorrelationID(context.Background(), event.Metadata.CorrelationID)
+		ctx = events.ExtractTraceContext(ctx, event)
+		ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+		err = o.bus.Publish(ctx, event)
+		cancel()
+	}
+	if err == nil {
+		return map[string]interface{}{"published_at": now}
+	}
+
+	attempts := msg.Attempts + 1
+	backoff := maxBackoff
+	if attempts < 20 {
+		backoff = min(baseBackoff<<(attempts-1), maxBackoff)
+	}
+	o.logger.Error("Failed to publish outbox event",
+		"source", o.source,
+		"eventId", event.ID,
+		"eventType", msg.EventType,
+		"attempts", attempts,
+		"retryIn", backoff,
+		"error", err)
+
+	return map[string]interface{}{
+		"attempts":     attempts,
+		"last_error":   err.Error(),
+		"available_at": now.Add(backoff),
+	}
+}
+
+// prune deletes the events relayed longer than the retention ago
+func (o *Outbox) prune() {
+	result := o.db.WithContext(context.Background()).
+		Where("source = ? AND published_at < ?", o.source, time.Now().UTC().Add(-retention)).
+		Delete(&Message{})
+	if result.Error != nil {
+		o.logger.Warn("Failed to prune outbox", "source", o.source, "error", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		o.logger.Debug("Pruned outbox", "source", o.source, "deleted", result.RowsAffected)
+	}
+}
@@ -0,0 +1,98 @@
+// Package payload keeps node data too large to pass between nodes inline in
+// the S3 compatible object storage
+package payload
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/contracts/workflow"
+)
+
+// S3Store implements workflow.PayloadStore on an S3 bucket. Payloads are
+// uploaded in parts as they are encoded and downloaded as streams.
+type S3Store struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+}
+
+var _ workflow.PayloadStore = (*S3Store)(nil)
+
+// NewS3Store creates a payload store on a bucket
+func NewS3Store(sess *session.Session, bucket string) *S3Store {
+	return &S3Store{
+		client:   s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+		bucket:   bucket,
+	}
+}
+
+// New creates the payload store of the configured object storage, nil
+// when no bucket is configured
+func New(storage config.StorageConfig, bucket string) (*S3Store, error) {
+	if bucket == "" {
+		return nil, nil
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String(storage.Region),
+		Endpoint:         aws.String(storage.Endpoint),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 session: %w", err)
+	}
+	return NewS3Store(sess, bucket), nil
+}
+
+// Put uploads the body under key
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader) (int64, error) {
+	counted := &countingReader{reader: body}
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        counted,
+		ContentType: aws.String("application/json"),
+	})
+	return counted.n, err
+}
+
+// Open streams the payload under key
+func (s *S3Store) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	result, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Body, nil
+}
+
+// Delete removes the objects under prefix, listed and deleted in batches
+func (s *S3Store) Delete(ctx context.Context, prefix string) error {
+	objects := s3manager.NewDeleteListIterator(s.client, &s3.ListObjectsInput{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	return s3manager.NewBatchDeleteWithClient(s.client).Delete(ctx, objects)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,85 @@
+package quota
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/tenant"
+)
+
+var errInvalidTenant = apperrors.New(apperrors.CategoryValidation, apperrors.CodeInvalidRequest, "invalid tenant id")
+
+// Handlers serves the usage of the tenants and the admin API assigning
+// their plans
+type Handlers struct {
+	enforcer *Enforcer
+}
+
+// NewHandlers creates the API of an enforcer, nil when quotas are disabled
+func NewHandlers(enforcer *Enforcer) *Handlers {
+	if enforcer == nil {
+		return nil
+	}
+	return &Handlers{enforcer: enforcer}
+}
+
+// Usage returns the usage of the tenant of the request against its plan
+func (h *Handlers) Usage(c *gin.Context) {
+	h.report(c, c.GetString("tenantId"))
+}
+
+// GetTenant returns the usage of a tenant against its plan
+func (h *Handlers) GetTenant(c *gin.Context) {
+	id := c.Param("tenantId")
+	if !tenant.Valid(id) {
+		c.JSON(apperrors.ToHTTP(errInvalidTenant))
+		return
+	}
+	h.report(c, id)
+}
+
+func (h *Handlers) report(c *gin.Context, id string) {
+	report, err := h.enforcer.Report(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// SetTenantPlan assigns a plan to a tenant
+func (h *Handlers) SetTenantPlan(c *gin.Context) {
+	var req struct {
+		Plan string `json:"plan" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(apperrors.ToHTTP(apperrors.InvalidRequest(err)))
+		return
+	}
+	id := c.Param("tenantId")
+	if !tenant.Valid(id) {
+		c.JSON(apperrors.ToHTTP(errInvalidTenant))
+		return
+	}
+
+	if err := h.enforcer.SetPlan(c.Request.Context(), id, req.Plan); err != nil {
+		c.JSON(apperrors.ToHTTP(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tenant": id, "plan": req.Plan})
+}
+
+// ResetTenantPlan returns a tenant to the default plan
+func (h *Handlers) ResetTenantPlan(c *gin.Context) {
+	found, err := h.enforcer.ResetPlan(c.Request.Context(), c.Param("tenantId"))
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tenant has no plan assigned"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
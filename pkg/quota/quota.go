@@ -0,0 +1,364 @@
+// Package quota bounds what each tenant uses by the plan of the tenant:
+// active workflows, executions per month, concurrent executions and the
+// storage of execution data. Counters live in Redis, in the namespace of
+// the tenant, so every replica sees the same usage. Usage past the warning
+// percent of a limit is warned about, work at a limit is refused unless
+// enforcement is off.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/linkflow-go/pkg/config"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/linkflow-go/pkg/rediskey"
+	"github.com/linkflow-go/pkg/tenant"
+	"github.com/redis/go-redis/v9"
+)
+
+// Kinds of usage bounded by a plan
+const (
+	KindActiveWorkflows      = "activeWorkflows"
+	KindExecutions           = "executionsPerMonth"
+	KindConcurrentExecutions = "concurrentExecutions"
+	KindStorage              = "storageBytes"
+)
+
+const (
+	// plansKey holds the plan of each tenant assigned through the admin API
+	plansKey = "quota:plans"
+	// storageKey holds the bytes of execution data of each tenant
+	storageKey = "quota:storage"
+
+	// runningStaleAfter drops executions that never reported their end,
+	// such as those of a replica that died, from the concurrent count
+	runningStaleAfter = 24 * time.Hour
+
+	// warnInterval is how often a tenant nearing a limit is warned about
+	warnInterval = time.Hour
+
+	// refreshInterval bounds how long a change of the plan of a tenant
+	// takes to reach every replica
+	refreshInterval = 10 * time.Second
+)
+
+// ErrQuotaExceeded is returned for work refused by the plan of a tenant
+var ErrQuotaExceeded = apperrors.New(apperrors.CategoryRateLimit, "QUOTA_EXCEEDED", "plan quota exceeded")
+
+// Usage is the use of a tenant of one kind against the limit of its plan
+type Usage struct {
+	Kind  string `json:"kind"`
+	Used  int64  `json:"used"`
+	Limit int64  `json:"limit"` // 0 is unlimited
+	// Warning is set past the warning percent of the limit
+	Warning  bool `json:"warning"`
+	Exceeded bool `json:"exceeded"`
+}
+
+// Report is the usage of a tenant
+type Report struct {
+	Tenant string  `json:"tenant"`
+	Plan   string  `json:"plan"`
+	Usage  []Usage `json:"usage"`
+}
+
+// Enforcer checks and counts the usage of the tenants. A nil Enforcer
+// allows everything.
+type Enforcer struct {
+	redis  *redis.Client
+	cfg    config.QuotaConfig
+	logger logger.Logger
+
+	mu        sync.Mutex
+	plans     map[string]string
+	refreshed time.Time
+
+	storage   StorageFunc
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// New creates the enforcer of cfg, nil when quotas are disabled
+func New(client *redis.Client, cfg config.QuotaConfig, log logger.Logger) *Enforcer {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Enforcer{
+		redis:  client,
+		cfg:    cfg,
+		logger: log,
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// tenantOf returns the tenant of ctx, work scoped to no tenant belongs to
+// the default tenant
+func tenantOf(ctx context.Context) string {
+	if id := tenant.FromContext(ctx); id != "" {
+		return id
+	}
+	return tenant.DefaultTenant
+}
+
+// limit returns the limit of a kind in a plan, 0 is unlimited
+func limit(plan config.QuotaPlan, kind string) int64 {
+	switch kind {
+	case KindActiveWorkflows:
+		return int64(plan.ActiveWorkflows)
+	case KindExecutions:
+		return int64(plan.ExecutionsPerMonth)
+	case KindConcurrentExecutions:
+		return int64(plan.ConcurrentExecutions)
+	case KindStorage:
+		return int64(plan.StorageMB) << 20
+	}
+	return 0
+}
+
+// evaluate compares used with the limit of its kind
+func (e *Enforcer) evaluate(kind string, used, max int64) Usage {
+	u := Usage{Kind: kind, Used: used, Limit: max}
+	if max > 0 {
+		u.Exceeded = used >= max
+		u.Warning = e.cfg.WarnPercent > 0 && used*100 >= max*int64(e.cfg.WarnPercent)
+	}
+	return u
+}
+
+// check refuses the work adding to used when it reached the limit of the
+// tenant, and warns when it nears it
+func (e *Enforcer) check(ctx context.Context, id, kind string, used int64) error {
+	planName, plan := e.Plan(ctx, id)
+	u := e.evaluate(kind, used, limit(plan, kind))
+	if u.Exceeded {
+		metrics.TenantQuotaExceeded.WithLabelValues(kind).Inc()
+		if e.cfg.Enforce {
+			return ErrQuotaExceeded.
+				WithMessage("plan %s allows %d %s", planName, u.Limit, kind).
+				WithDetail("kind", kind).
+				WithDetail("limit", u.Limit).
+				WithDetail("used", u.Used)
+		}
+		e.logger.Warn("Tenant over its plan quota, enforcement is off",
+			"tenant", id, "plan", planName, "kind", kind, "used", u.Used, "limit", u.Limit)
+		return nil
+	}
+	if u.Warning {
+		e.warn(ctx, id, planName, u)
+	}
+	return nil
+}
+
+// warn logs that a tenant nears a limit, once per warnInterval across the
+// replicas
+func (e *Enforcer) warn(ctx context.Context, id, planName string, u Usage) {
+	first, err := e.redis.SetNX(ctx, rediskey.In(id, "quota", "warned", u.Kind), planName, warnInterval).Result()
+	if err != nil || !first {
+		return
+	}
+	metrics.TenantQuotaWarnings.WithLabelValues(u.Kind).Inc()
+	e.logger.Warn("Tenant nearing its plan quota",
+		"tenant", id, "plan", planName, "kind", u.Kind, "used", u.Used, "limit", u.Limit)
+}
+
+// Plan returns the plan of a tenant and its limits. Tenants of a plan that
+// is not configured get the default plan.
+func (e *Enforcer) Plan(ctx context.Context, id string) (string, config.QuotaPlan) {
+	name := e.planName(ctx, id)
+	if plan, ok := e.cfg.Plans[name]; ok {
+		return name, plan
+	}
+	return e.cfg.DefaultPlan, e.cfg.Plans[e.cfg.DefaultPlan]
+}
+
+// planName returns the plan assigned to a tenant, read from Redis at most
+// every refreshInterval
+func (e *Enforcer) planName(ctx context.Context, id string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.plans == nil || time.Since(e.refreshed) > refreshInterval {
+		plans, err := e.redis.HGetAll(ctx, plansKey).Result()
+		if err != nil {
+			e.logger.Warn("Failed to load tenant plans", "error", err)
+			plans = e.plans
+		}
+		e.plans = plans
+		e.refreshed = time.Now()
+	}
+
+	if name, ok := e.plans[id]; ok {
+		return name
+	}
+	return e.cfg.DefaultPlan
+}
+
+// SetPlan assigns a plan to a tenant on every replica
+func (e *Enforcer) SetPlan(ctx context.Context, id, plan string) error {
+	if _, ok := e.cfg.Plans[plan]; !ok {
+		return apperrors.New(apperrors.CategoryValidation, apperrors.CodeInvalidRequest,
+			fmt.Sprintf("unknown plan %q", plan))
+	}
+	if err := e.redis.HSet(ctx, plansKey, id, plan).Err(); err != nil {
+		return fmt.Errorf("failed to set tenant plan: %w", err)
+	}
+	e.invalidate()
+	return nil
+}
+
+// ResetPlan returns a tenant to the default plan. It reports whether the
+// tenant had a plan assigned.
+func (e *Enforcer) ResetPlan(ctx context.Context, id string) (bool, error) {
+	n, err := e.redis.HDel(ctx, plansKey, id).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to reset tenant plan: %w", err)
+	}
+	e.invalidate()
+	return n > 0, nil
+}
+
+// invalidate makes the next check of this replica read the plans
+func (e *Enforcer) invalidate() {
+	e.mu.Lock()
+	e.plans = nil
+	e.mu.Unlock()
+}
+
+func executionsKey(id string, now time.Time) string {
+	return rediskey.In(id, "quota", "executions", now.UTC().Format("2006-01"))
+}
+
+func runningKey(id string) string {
+	return rediskey.In(id, "quota", "running")
+}
+
+func workflowsKey(id string) string {
+	return rediskey.In(id, "quota", "workflows")
+}
+
+// CheckExecution admits an execution of the tenant of ctx without counting
+// it, for work that leads to an execution started elsewhere. It is refused
+// when the tenant ran all the executions of its month, runs all the
+// concurrent executions of its plan or stores all the execution data it
+// may. Checks fail open, executions run when Redis is unavailable.
+func (e *Enforcer) CheckExecution(ctx context.Context) error {
+	if e == nil {
+		return nil
+	}
+	return e.checkExecution(ctx, tenantOf(ctx), time.Now())
+}
+
+func (e *Enforcer) checkExecution(ctx context.Context, id string, now time.Time) error {
+	pipe := e.redis.Pipeline()
+	monthly := pipe.Get(ctx, executionsKey(id, now))
+	pipe.ZRemRangeByScore(ctx, runningKey(id), "-inf", strconv.FormatInt(now.Add(-runningStaleAfter).Unix(), 10))
+	running := pipe.ZCard(ctx, runningKey(id))
+	stored := pipe.HGet(ctx, storageKey, id)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		e.logger.Warn("Failed to read tenant usage", "tenant", id, "error", err)
+		return nil
+	}
+
+	executions, _ := monthly.Int64()
+	if err := e.check(ctx, id, KindExecutions, executions); err != nil {
+		return err
+	}
+	if err := e.check(ctx, id, KindConcurrentExecutions, running.Val()); err != nil {
+		return err
+	}
+	storage, _ := stored.Int64()
+	return e.check(ctx, id, KindStorage, storage)
+}
+
+// StartExecution admits an execution of the tenant of ctx like
+// CheckExecution, then counts it until FinishExecution
+func (e *Enforcer) StartExecution(ctx context.Context, executionID string) error {
+	if e == nil {
+		return nil
+	}
+	id := tenantOf(ctx)
+	now := time.Now()
+	if err := e.checkExecution(ctx, id, now); err != nil {
+		return err
+	}
+
+	pipe := e.redis.TxPipeline()
+	pipe.Incr(ctx, executionsKey(id, now))
+	// Kept past the end of the month for the usage of the last one
+	pipe.Expire(ctx, executionsKey(id, now), 62*24*time.Hour)
+	pipe.ZAdd(ctx, runningKey(id), redis.Z{Score: float64(now.Unix()), Member: executionID})
+	pipe.Expire(ctx, runningKey(id), runningStaleAfter)
+	if _, err := pipe.Exec(ctx); err != nil {
+		e.logger.Warn("Failed to count tenant execution", "tenant", id, "error", err)
+	}
+	return nil
+}
+
+// FinishExecution stops counting an execution of the tenant of ctx as
+// running
+func (e *Enforcer) FinishExecution(ctx context.Context, executionID string) {
+	if e == nil {
+		return
+	}
+	if err := e.redis.ZRem(ctx, runningKey(tenantOf(ctx)), executionID).Err(); err != nil {
+		e.logger.Warn("Failed to uncount tenant execution", "executionId", executionID, "error", err)
+	}
+}
+
+// CheckActivation admits the activation of a workflow of the tenant of ctx
+// having active workflows already
+func (e *Enforcer) CheckActivation(ctx context.Context, active int64) error {
+	if e == nil {
+		return nil
+	}
+	return e.check(ctx, tenantOf(ctx), KindActiveWorkflows, active)
+}
+
+// SetActiveWorkflows records the active workflows of the tenant of ctx for
+// its usage report
+func (e *Enforcer) SetActiveWorkflows(ctx context.Context, active int64) {
+	if e == nil {
+		return
+	}
+	if err := e.redis.Set(ctx, workflowsKey(tenantOf(ctx)), active, 0).Err(); err != nil {
+		e.logger.Warn("Failed to record active workflows", "error", err)
+	}
+}
+
+// Report returns the usage of a tenant against its plan
+func (e *Enforcer) Report(ctx context.Context, id string) (*Report, error) {
+	now := time.Now()
+	pipe := e.redis.Pipeline()
+	workflows := pipe.Get(ctx, workflowsKey(id))
+	monthly := pipe.Get(ctx, executionsKey(id, now))
+	pipe.ZRemRangeByScore(ctx, runningKey(id), "-inf", strconv.FormatInt(now.Add(-runningStaleAfter).Unix(), 10))
+	running := pipe.ZCard(ctx, runningKey(id))
+	stored := pipe.HGet(ctx, storageKey, id)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read tenant usage: %w", err)
+	}
+
+	planName, plan := e.Plan(ctx, id)
+	active, _ := workflows.Int64()
+	executions, _ := monthly.Int64()
+	storage, _ := stored.Int64()
+	return &Report{
+		Tenant: id,
+		Plan:   planName,
+		Usage: []Usage{
+			e.evaluate(KindActiveWorkflows, active, limit(plan, KindActiveWorkflows)),
+			e.evaluate(KindExecutions, executions, limit(plan, KindExecutions)),
+			e.evaluate(KindConcurrentExecutions, running.Val(), limit(plan, KindConcurrentExecutions)),
+			e.evaluate(KindStorage, storage, limit(plan, KindStorage)),
+		},
+	}, nil
+}
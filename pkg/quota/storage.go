@@ -0,0 +1,108 @@
+package quota
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// storageLock lets one replica measure the storage of every tenant
+const storageLock = "quota:storage:lock"
+
+// StorageFunc returns the bytes of execution data stored by each tenant
+type StorageFunc func(ctx context.Context) (map[string]int64, error)
+
+// MeasureStorage sets how the storage of the tenants is measured, storage
+// is not bounded without it
+func (e *Enforcer) MeasureStorage(fn StorageFunc) {
+	if e == nil {
+		return
+	}
+	e.storage = fn
+}
+
+// Start measures the storage of the tenants every storage interval until
+// Stop. It does nothing without a way to measure it.
+func (e *Enforcer) Start() {
+	if e == nil || e.storage == nil {
+		return
+	}
+	e.startOnce.Do(func() {
+		go e.run()
+	})
+}
+
+// Stop ends the background measures and waits for the running one
+func (e *Enforcer) Stop() {
+	if e == nil {
+		return
+	}
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+	})
+	e.startOnce.Do(func() {
+		close(e.done)
+	})
+	<-e.done
+}
+
+func (e *Enforcer) interval() time.Duration {
+	return time.Duration(e.cfg.StorageInterval) * time.Second
+}
+
+func (e *Enforcer) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.interval())
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-e.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		if err := e.RefreshStorage(ctx); err != nil {
+			e.logger.Error("Failed to measure tenant storage", "error", err)
+		}
+		cancel()
+
+		select {
+		case <-ticker.C:
+		case <-e.stopCh:
+			return
+		}
+	}
+}
+
+// RefreshStorage measures the storage of the tenants unless another
+// replica measured it within the storage interval
+func (e *Enforcer) RefreshStorage(ctx context.Context) error {
+	// The lock expires before the next measure, a replica that died holding
+	// it does not stop measuring
+	ok, err := e.redis.SetNX(ctx, storageLock, uuid.New().String(), e.interval()/2).Result()
+	if err != nil || !ok {
+		return err
+	}
+
+	storage, err := e.storage(ctx)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]interface{}, len(storage))
+	for id, bytes := range storage {
+		values[id] = bytes
+	}
+	pipe := e.redis.TxPipeline()
+	pipe.Del(ctx, storageKey)
+	if len(values) > 0 {
+		pipe.HSet(ctx, storageKey, values)
+	}
+	pipe.Expire(ctx, storageKey, 2*e.interval())
+	_, err = pipe.Exec(ctx)
+	return err
+}
@@ -3,6 +3,7 @@ package ratelimit
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"sync"
@@ -154,6 +155,55 @@ func (s *SlidingWindowLimiter) Allow(ctx context.Context, key string) (bool, err
 	return true, nil
 }
 
+// Quota is the state of a sliding window once a request was checked
+type Quota struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is when the current window ends and the count starts to decay
+	Reset time.Time
+}
+
+// slidingWindowScript counts a request in the current window unless the
+// weighted count of the current and previous windows reached the limit.
+// KEYS: current, previous. ARGV: weight of previous, limit, expiry in ms.
+var slidingWindowScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+if previous * tonumber(ARGV[1]) + current >= tonumber(ARGV[2]) then
+	return {0, current, previous}
+end
+current = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {1, current, previous}
+`)
+
+// Check counts a request against the window of a key and reports the
+// quota left. Unlike Allow it is atomic across instances and denied
+// requests are not counted.
+func (s *SlidingWindowLimiter) Check(ctx context.Context, key string) (Quota, error) {
+	now := time.Now()
+	windowMs := s.window.Milliseconds()
+	currentWindow := now.UnixMilli() / windowMs
+	progress := float64(now.UnixMilli()%windowMs) / float64(windowMs)
+
+	res, err := slidingWindowScript.Run(ctx, s.redis,
+		[]string{fmt.Sprintf("%s:%d", key, currentWindow), fmt.Sprintf("%s:%d", key, currentWindow-1)},
+		strconv.FormatFloat(1-progress, 'f', 6, 64), s.limit, 2*windowMs,
+	).Int64Slice()
+	if err != nil {
+		return Quota{}, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	weighted := float64(res[2])*(1-progress) + float64(res[1])
+	return Quota{
+		Allowed:   res[0] == 1,
+		Limit:     s.limit,
+		Remaining: max(s.limit-int(math.Ceil(weighted)), 0),
+		Reset:     time.UnixMilli((currentWindow + 1) * windowMs),
+	}, nil
+}
+
 func (s *SlidingWindowLimiter) Limit() rate.Limit {
 	return rate.Limit(float64(s.limit) / s.window.Seconds())
 }
@@ -360,8 +410,8 @@ func (r *InMemoryRateLimiter) Allow(ctx context.Context, key string) (bool, erro
 
 	// Check if blocked
 	if info.blocked {
-		// Check if block period has expired (15 minutes)
-		if now.Sub(info.blockTime) > 15*time.Minute {
+		// Check if block period (one window) has expired
+		if now.Sub(info.blockTime) > r.window {
 			// Reset the block
 			info.blocked = false
 			info.count = 1
@@ -393,13 +443,26 @@ func (r *InMemoryRateLimiter) Allow(ctx context.Context, key string) (bool, erro
 }
 
 func (r *InMemoryRateLimiter) Limit() rate.Limit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return rate.Limit(float64(r.maxRetries) / r.window.Seconds())
 }
 
 func (r *InMemoryRateLimiter) Burst() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.maxRetries
 }
 
+// SetLimits changes the limits at runtime, tracked attempts are kept and
+// checked against the new limits
+func (r *InMemoryRateLimiter) SetLimits(maxRetries int, window time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxRetries = maxRetries
+	r.window = window
+}
+
 // Reset clears rate limit state for a key (e.g., after successful login)
 func (r *InMemoryRateLimiter) Reset(key string) {
 	r.mu.Lock()
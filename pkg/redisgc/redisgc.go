@@ -0,0 +1,249 @@
+// Package redisgc reclaims Redis keys whose owner is gone. Each key family a
+// service writes is tagged with its owner by a Rule, which tells the
+// collector how to find the owning record of a key. Keys whose record no
+// longer exists are orphans, kept forever when the family has no TTL.
+package redisgc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultInterval is how often a collector looks for orphans
+	DefaultInterval = 15 * time.Minute
+
+	// scanCount is the SCAN hint and batchSize bounds the owner IDs looked
+	// up at once
+	scanCount = 1000
+	batchSize = 500
+
+	lockPrefix = "redisgc:lock:"
+)
+
+// Rule tags a key family with its owner
+type Rule struct {
+	// Owner names the records owning the keys, e.g. workflow.trigger. It is
+	// the owner label of the metrics.
+	Owner string
+
+	// Pattern is the SCAN pattern matching the keys of the family
+	Pattern string
+
+	// OwnerID returns the ID of the record owning key, empty to leave the
+	// key alone
+	OwnerID func(key string) string
+
+	// Live returns the IDs among ids whose records still own keys
+	Live func(ctx context.Context, ids []string) ([]string, error)
+}
+
+// Collector deletes the orphaned keys of its rules in the background. A key
+// is deleted once it was found orphaned by two passes in a row, so keys
+// written just before their record is committed survive. One replica per
+// collector name runs a pass at a time.
+type Collector struct {
+	redis    *redis.Client
+	name     string
+	rules    []Rule
+	interval time.Duration
+	logger   logger.Logger
+
+	// suspects are the keys found orphaned by the last pass
+	suspects map[string]struct{}
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// New creates the collector of the service name. An interval of zero or less
+// uses DefaultInterval.
+func New(client *redis.Client, name string, rules []Rule, interval time.Duration, log logger.Logger) *Collector {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Collector{
+		redis:    client,
+		name:     name,
+		rules:    rules,
+		interval: interval,
+		logger:   log,
+		suspects: make(map[string]struct{}),
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs a pass every interval until Stop
+func (c *Collector) Start() {
+	c.startOnce.Do(func() {
+		go c.run()
+	})
+}
+
+// Stop ends the background passes and waits for the running one
+func (c *Collector) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+	})
+	c.startOnce.Do(func() {
+		close(c.done)
+	})
+	<-c.done
+}
+
+func (c *Collector) run() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.stopCh:
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-c.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		if err := c.Collect(ctx); err != nil {
+			c.logger.Error("Redis garbage collection failed", "collector", c.name, "error", err)
+		}
+		cancel()
+	}
+}
+
+// Collect runs a pass over every rule unless another replica holds the
+// lock of the collector
+func (c *Collector) Collect(ctx context.Context) error {
+	// The lock expires before the next pass, a replica that died holding it
+	// does not stop collection
+	ok, err := c.redis.SetNX(ctx, lockPrefix+c.name, uuid.New().String(), c.interval/2).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	start := time.Now()
+	defer func() {
+		metrics.RedisGCPassDuration.WithLabelValues(c.name).Observe(time.Since(start).Seconds())
+	}()
+
+	suspects := make(map[string]struct{})
+	for _, rule := range c.rules {
+		if err := c.collectRule(ctx, rule, suspects); err != nil {
+			// Keep the suspects of the failed rule, they are confirmed by
+			// the next pass
+			for key := range c.suspects {
+				suspects[key] = struct{}{}
+			}
+			c.suspects = suspects
+			return err
+		}
+	}
+	c.suspects = suspects
+	return nil
+}
+
+// collectRule scans the keys of rule, deletes those orphaned on the last
+// pass as well and adds the newly orphaned ones to suspects
+func (c *Collector) collectRule(ctx context.Context, rule Rule, suspects map[string]struct{}) error {
+	var scanned, reclaimed int
+
+	batch := make(map[string][]string, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		orphans, err := c.orphans(ctx, rule, batch)
+		if err != nil {
+			return err
+		}
+
+		var confirmed []string
+		for _, key := range orphans {
+			if _, ok := c.suspects[key]; ok {
+				confirmed = append(confirmed, key)
+			} else {
+				suspects[key] = struct{}{}
+			}
+		}
+		if len(confirmed) > 0 {
+			n, err := c.redis.Unlink(ctx, confirmed...).Result()
+			if err != nil {
+				return err
+			}
+			reclaimed += int(n)
+		}
+		batch = make(map[string][]string, batchSize)
+		return nil
+	}
+
+	iter := c.redis.Scan(ctx, 0, rule.Pattern, scanCount).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		scanned++
+
+		id := rule.OwnerID(key)
+		if id == "" {
+			continue
+		}
+		batch[id] = append(batch[id], key)
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	metrics.RecordRedisGC(rule.Owner, scanned, reclaimed)
+	if reclaimed > 0 {
+		c.logger.Info("Reclaimed orphaned Redis keys", "collector", c.name, "owner", rule.Owner, "scanned", scanned, "reclaimed", reclaimed)
+	}
+	return nil
+}
+
+// orphans returns the keys of batch, keyed by owner ID, whose owner is gone
+func (c *Collector) orphans(ctx context.Context, rule Rule, batch map[string][]string) ([]string, error) {
+	ids := make([]string, 0, len(batch))
+	for id := range batch {
+		ids = append(ids, id)
+	}
+
+	live, err := rule.Live(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range live {
+		delete(batch, id)
+	}
+
+	var orphans []string
+	for _, keys := range batch {
+		orphans = append(orphans, keys...)
+	}
+	return orphans, nil
+}
@@ -0,0 +1,57 @@
+package rediskey
+
+import (
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Moved counts the flat keys of a family moved into a tenant namespace.
+// Dropped are flat keys whose namespaced key was already written, the flat
+// one is stale.
+type Moved struct {
+	Family  string
+	Moved   int
+	Dropped int
+}
+
+// Namespace moves the flat keys of the Families, written before namespacing,
+// into the namespace of tenantID. Renames keep the TTL of the keys. With
+// dryRun the keys are only counted.
+func Namespace(ctx context.Context, client *redis.Client, tenantID string, dryRun bool) ([]Moved, error) {
+	result := make([]Moved, 0, len(Families))
+	for _, family := range Families {
+		moved := Moved{Family: strings.TrimSuffix(family, ":*")}
+
+		iter := client.Scan(ctx, 0, family, scanCount).Iterator()
+		for iter.Next(ctx) {
+			key := iter.Val()
+			if dryRun {
+				moved.Moved++
+				continue
+			}
+			ok, err := client.RenameNX(ctx, key, In(tenantID, key)).Result()
+			if err != nil && strings.Contains(err.Error(), "no such key") {
+				// Expired since the scan
+				continue
+			}
+			if err != nil {
+				return result, err
+			}
+			if ok {
+				moved.Moved++
+				continue
+			}
+			if err := client.Del(ctx, key).Err(); err != nil {
+				return result, err
+			}
+			moved.Dropped++
+		}
+		if err := iter.Err(); err != nil {
+			return result, err
+		}
+		result = append(result, moved)
+	}
+	return result, nil
+}
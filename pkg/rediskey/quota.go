@@ -0,0 +1,317 @@
+package rediskey
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/metrics"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultUsageInterval is how often the memory of the tenants is measured
+	DefaultUsageInterval = 5 * time.Minute
+
+	// usageKey holds the bytes used by the keys of each tenant, measured by
+	// one replica for all of them
+	usageKey  = "rediskey:usage"
+	usageLock = "rediskey:usage:lock"
+	scanCount = 1000
+)
+
+// ErrQuotaExceeded is returned for the commands refused by a tenant quota
+var ErrQuotaExceeded = apperrors.New(apperrors.CategoryRateLimit, "TENANT_REDIS_QUOTA",
+	"tenant exceeded its Redis quota")
+
+// writeCommands grow the memory of their key and are refused for tenants
+// over their memory quota. Deletes and expiries stay allowed, so a tenant
+// can shrink back under it.
+var writeCommands = map[string]struct{}{
+	"set": {}, "setex": {}, "psetex": {}, "setnx": {}, "setrange": {}, "append": {},
+	"incr": {}, "incrby": {}, "incrbyfloat": {}, "decr": {}, "decrby": {},
+	"lpush": {}, "rpush": {}, "lpushx": {}, "rpushx": {}, "linsert": {}, "lset": {},
+	"sadd": {}, "smove": {}, "hset": {}, "hsetnx": {}, "hmset": {}, "hincrby": {},
+	"zadd": {}, "zincrby": {}, "xadd": {}, "pfadd": {}, "setbit": {}, "copy": {},
+}
+
+// Quota is a Redis hook bounding the usage of each tenant, so one tenant
+// cannot degrade the Redis shared by all of them. Commands on the keys of a
+// tenant are rate limited per replica, and writes are refused while the keys
+// of the tenant hold more memory than allowed. Commands are attributed by
+// their first key, shared keys are never limited.
+type Quota struct {
+	redis        *redis.Client
+	opsPerSecond int
+	maxMemory    int64
+	interval     time.Duration
+	logger       logger.Logger
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+
+	overMu sync.RWMutex
+	over   map[string]struct{}
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	done      chan struct{}
+}
+
+// NewQuota creates the quota of the tenants using client. Zero limits are
+// unbounded, an interval of zero or less uses DefaultUsageInterval.
+func NewQuota(client *redis.Client, opsPerSecond, maxMemoryMB int, interval time.Duration, log logger.Logger) *Quota {
+	if interval <= 0 {
+		interval = DefaultUsageInterval
+	}
+	return &Quota{
+		redis:        client,
+		opsPerSecond: opsPerSecond,
+		maxMemory:    int64(maxMemoryMB) << 20,
+		interval:     interval,
+		logger:       log,
+		limiters:     make(map[string]*rate.Limiter),
+		over:         make(map[string]struct{}),
+		stopCh:       make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+func (q *Quota) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (q *Quota) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := q.admit(cmd); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+// ProcessPipelineHook refuses a whole pipeline when one of its commands is
+// refused, its other commands may depend on it
+func (q *Quota) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if err := q.admit(cmd); err != nil {
+				for _, c := range cmds {
+					c.SetErr(err)
+				}
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// admit checks cmd against the quota of the tenant of its first key
+func (q *Quota) admit(cmd redis.Cmder) error {
+	if q.opsPerSecond <= 0 && q.maxMemory <= 0 {
+		return nil
+	}
+	args := cmd.Args()
+	if len(args) < 2 {
+		return nil
+	}
+	key, ok := args[1].(string)
+	if !ok {
+		return nil
+	}
+	id, ok := TenantOf(key)
+	if !ok {
+		return nil
+	}
+
+	if q.maxMemory > 0 && q.isOver(id) {
+		if _, write := writeCommands[strings.ToLower(cmd.Name())]; write {
+			metrics.RedisTenantCommandsRejected.WithLabelValues("memory").Inc()
+			return ErrQuotaExceeded
+		}
+	}
+	if q.opsPerSecond > 0 && !q.limiter(id).Allow() {
+		metrics.RedisTenantCommandsRejected.WithLabelValues("ops").Inc()
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+func (q *Quota) limiter(id string) *rate.Limiter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	l, ok := q.limiters[id]
+	if !ok {
+		l = rate.NewLimiter(rate.Limit(q.opsPerSecond), q.opsPerSecond)
+		q.limiters[id] = l
+	}
+	return l
+}
+
+func (q *Quota) isOver(id string) bool {
+	q.overMu.RLock()
+	defer q.overMu.RUnlock()
+	_, ok := q.over[id]
+	return ok
+}
+
+// Start measures the memory of the tenants every interval until Stop. It
+// does nothing without a memory quota.
+func (q *Quota) Start() {
+	if q.maxMemory <= 0 {
+		return
+	}
+	q.startOnce.Do(func() {
+		go q.run()
+	})
+}
+
+// Stop ends the background measures and waits for the running one
+func (q *Quota) Stop() {
+	q.stopOnce.Do(func() {
+		close(q.stopCh)
+	})
+	q.startOnce.Do(func() {
+		close(q.done)
+	})
+	<-q.done
+}
+
+func (q *Quota) run() {
+	defer close(q.done)
+
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			select {
+			case <-q.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		if err := q.Refresh(ctx); err != nil {
+			q.logger.Error("Failed to measure tenant Redis usage", "error", err)
+		}
+		cancel()
+
+		select {
+		case <-ticker.C:
+		case <-q.stopCh:
+			return
+		}
+	}
+}
+
+// Refresh measures the memory of the tenants unless another replica holds
+// the lock, then loads the tenants over the memory quota
+func (q *Quota) Refresh(ctx context.Context) error {
+	// The lock expires before the next measure, a replica that died holding
+	// it does not stop measuring
+	ok, err := q.redis.SetNX(ctx, usageLock, uuid.New().String(), q.interval/2).Result()
+	if err != nil {
+		return err
+	}
+	if ok {
+		if err := q.measure(ctx); err != nil {
+			return err
+		}
+	}
+
+	usage, err := q.redis.HGetAll(ctx, usageKey).Result()
+	if err != nil {
+		return err
+	}
+	over := make(map[string]struct{})
+	for id, value := range usage {
+		bytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || bytes <= q.maxMemory {
+			continue
+		}
+		over[id] = struct{}{}
+		if !q.isOver(id) {
+			q.logger.Warn("Tenant exceeded its Redis memory quota, refusing its writes",
+				"tenant", id, "usedBytes", bytes, "maxBytes", q.maxMemory)
+		}
+	}
+
+	q.overMu.Lock()
+	q.over = over
+	q.overMu.Unlock()
+	metrics.RedisTenantsOverMemory.Set(float64(len(over)))
+	return nil
+}
+
+// measure sums the memory of the keys of each tenant into usageKey
+func (q *Quota) measure(ctx context.Context) error {
+	usage := make(map[string]int64)
+	keys := make([]string, 0, scanCount)
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		pipe := q.redis.Pipeline()
+		cmds := make([]*redis.IntCmd, len(keys))
+		for i, key := range keys {
+			cmds[i] = pipe.MemoryUsage(ctx, key)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return err
+		}
+		for i, key := range keys {
+			// A key may expire between the scan and its measure
+			if bytes, err := cmds[i].Result(); err == nil {
+				id, _ := TenantOf(key)
+				usage[id] += bytes
+			}
+		}
+		keys = keys[:0]
+		return nil
+	}
+
+	iter := q.redis.Scan(ctx, 0, Prefix+"*", scanCount).Iterator()
+	for iter.Next(ctx) {
+		if _, ok := TenantOf(iter.Val()); !ok {
+			continue
+		}
+		keys = append(keys, iter.Val())
+		if len(keys) == scanCount {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	values := make(map[string]interface{}, len(usage))
+	for id, bytes := range usage {
+		values[id] = bytes
+	}
+	pipe := q.redis.TxPipeline()
+	pipe.Del(ctx, usageKey)
+	if len(values) > 0 {
+		pipe.HSet(ctx, usageKey, values)
+	}
+	pipe.Expire(ctx, usageKey, 2*q.interval)
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,63 @@
+// Package rediskey builds the Redis keys of the services. Keys of data owned
+// by a tenant live under the namespace of the tenant, tenant:<id>:, so the
+// keys of a tenant can be measured, bounded and moved together. Keys shared
+// by every tenant, such as the execution queues, lockouts and the token
+// blacklist, stay flat.
+package rediskey
+
+import (
+	"context"
+	"strings"
+
+	"github.com/linkflow-go/pkg/tenant"
+)
+
+// Prefix starts the namespace of every tenant
+const Prefix = "tenant:"
+
+// Families are the flat patterns of the key families kept in the namespace
+// of their tenant, keys written before namespacing match them
+var Families = []string{
+	"credential:*",
+	"team:*",
+	"debug:session:*",
+	"validation:*",
+	"stats:workflow:*",
+	"timeseries:*",
+	"checkpoint:*",
+	"logs:execution:*",
+}
+
+// Tenant returns the key of parts in the namespace of the tenant of ctx.
+// Work scoped to no tenant uses the namespace of the default tenant, like
+// the rows it stores.
+func Tenant(ctx context.Context, parts ...string) string {
+	id := tenant.FromContext(ctx)
+	if id == "" {
+		id = tenant.DefaultTenant
+	}
+	return In(id, parts...)
+}
+
+// In returns the key of parts in the namespace of a tenant
+func In(tenantID string, parts ...string) string {
+	return Prefix + tenantID + ":" + strings.Join(parts, ":")
+}
+
+// Pattern matches every key of a tenant
+func Pattern(tenantID string) string {
+	return Prefix + tenantID + ":*"
+}
+
+// TenantOf returns the tenant of a namespaced key, false for shared keys
+func TenantOf(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, Prefix)
+	if !ok {
+		return "", false
+	}
+	id, _, ok := strings.Cut(rest, ":")
+	if !ok || id == "" {
+		return "", false
+	}
+	return id, true
+}
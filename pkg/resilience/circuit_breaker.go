@@ -2,16 +2,16 @@ package resilience
 
 import (
 	"context"
-	"errors"
 	"sync"
 	"time"
 
+	apperrors "github.com/linkflow-go/pkg/errors"
 	"github.com/sony/gobreaker"
 )
 
 var (
-	ErrCircuitOpen     = errors.New("circuit breaker is open")
-	ErrTooManyRequests = errors.New("too many requests")
+	ErrCircuitOpen     = apperrors.New(apperrors.CategoryUpstream, "CIRCUIT_OPEN", "circuit breaker is open")
+	ErrTooManyRequests = apperrors.New(apperrors.CategoryUpstream, "CIRCUIT_HALF_OPEN_LIMIT", "too many requests")
 )
 
 // CircuitBreaker wraps sony/gobreaker with additional functionality
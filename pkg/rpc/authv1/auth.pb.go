@@ -0,0 +1,272 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: linkflow/auth/v1/auth.proto
+
+package authv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type IntrospectTokenRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Token string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// Either access_token or refresh_token, both are tried when empty
+	TokenTypeHint string `protobuf:"bytes,2,opt,name=token_type_hint,json=tokenTypeHint,proto3" json:"token_type_hint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IntrospectTokenRequest) Reset() {
+	*x = IntrospectTokenRequest{}
+	mi := &file_linkflow_auth_v1_auth_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntrospectTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntrospectTokenRequest) ProtoMessage() {}
+
+func (x *IntrospectTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_linkflow_auth_v1_auth_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IntrospectTokenRequest.ProtoReflect.Descriptor instead.
+func (*IntrospectTokenRequest) Descriptor() ([]byte, []int) {
+	return file_linkflow_auth_v1_auth_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IntrospectTokenRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *IntrospectTokenRequest) GetTokenTypeHint() string {
+	if x != nil {
+		return x.TokenTypeHint
+	}
+	return ""
+}
+
+type IntrospectTokenResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Active bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`
+	// The remaining fields are only set for active tokens
+	TokenType   string                 `protobuf:"bytes,2,opt,name=token_type,json=tokenType,proto3" json:"token_type,omitempty"`
+	UserId      string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username    string                 `protobuf:"bytes,4,opt,name=username,proto3" json:"username,omitempty"`
+	WorkspaceId string                 `protobuf:"bytes,5,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	Plan        string                 `protobuf:"bytes,6,opt,name=plan,proto3" json:"plan,omitempty"`
+	Roles       []string               `protobuf:"bytes,7,rep,name=roles,proto3" json:"roles,omitempty"`
+	Scope       string                 `protobuf:"bytes,8,opt,name=scope,proto3" json:"scope,omitempty"`
+	ExpiresAt   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Tenant of the user, the default tenant for tokens issued before tenancy
+	TenantId      string `protobuf:"bytes,10,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IntrospectTokenResponse) Reset() {
+	*x = IntrospectTokenResponse{}
+	mi := &file_linkflow_auth_v1_auth_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntrospectTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntrospectTokenResponse) ProtoMessage() {}
+
+func (x *IntrospectTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_linkflow_auth_v1_auth_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IntrospectTokenResponse.ProtoReflect.Descriptor instead.
+func (*IntrospectTokenResponse) Descriptor() ([]byte, []int) {
+	return file_linkflow_auth_v1_auth_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IntrospectTokenResponse) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *IntrospectTokenResponse) GetTokenType() string {
+	if x != nil {
+		return x.TokenType
+	}
+	return ""
+}
+
+func (x *IntrospectTokenResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *IntrospectTokenResponse) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *IntrospectTokenResponse) GetWorkspaceId() string {
+	if x != nil {
+		return x.WorkspaceId
+	}
+	return ""
+}
+
+func (x *IntrospectTokenResponse) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+func (x *IntrospectTokenResponse) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *IntrospectTokenResponse) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *IntrospectTokenResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *IntrospectTokenResponse) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+var File_linkflow_auth_v1_auth_proto protoreflect.FileDescriptor
+
+const file_linkflow_auth_v1_auth_proto_rawDesc = "" +
+	"\n" +
+	"\x1blinkflow/auth/v1/auth.proto\x12\x10linkflow.auth.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"V\n" +
+	"\x16IntrospectTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12&\n" +
+	"\x0ftoken_type_hint\x18\x02 \x01(\tR\rtokenTypeHint\"\xc0\x02\n" +
+	"\x17IntrospectTokenResponse\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x1d\n" +
+	"\n" +
+	"token_type\x18\x02 \x01(\tR\ttokenType\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x1a\n" +
+	"\busername\x18\x04 \x01(\tR\busername\x12!\n" +
+	"\fworkspace_id\x18\x05 \x01(\tR\vworkspaceId\x12\x12\n" +
+	"\x04plan\x18\x06 \x01(\tR\x04plan\x12\x14\n" +
+	"\x05roles\x18\a \x03(\tR\x05roles\x12\x14\n" +
+	"\x05scope\x18\b \x01(\tR\x05scope\x129\n" +
+	"\n" +
+	"expires_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x1b\n" +
+	"\ttenant_id\x18\n" +
+	" \x01(\tR\btenantId2u\n" +
+	"\vAuthService\x12f\n" +
+	"\x0fIntrospectToken\x12(.linkflow.auth.v1.IntrospectTokenRequest\x1a).linkflow.auth.v1.IntrospectTokenResponseB.Z,github.com/linkflow-go/pkg/rpc/authv1;authv1b\x06proto3"
+
+var (
+	file_linkflow_auth_v1_auth_proto_rawDescOnce sync.Once
+	file_linkflow_auth_v1_auth_proto_rawDescData []byte
+)
+
+func file_linkflow_auth_v1_auth_proto_rawDescGZIP() []byte {
+	file_linkflow_auth_v1_auth_proto_rawDescOnce.Do(func() {
+		file_linkflow_auth_v1_auth_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_linkflow_auth_v1_auth_proto_rawDesc), len(file_linkflow_auth_v1_auth_proto_rawDesc)))
+	})
+	return file_linkflow_auth_v1_auth_proto_rawDescData
+}
+
+var file_linkflow_auth_v1_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_linkflow_auth_v1_auth_proto_goTypes = []any{
+	(*IntrospectTokenRequest)(nil),  // 0: linkflow.auth.v1.IntrospectTokenRequest
+	(*IntrospectTokenResponse)(nil), // 1: linkflow.auth.v1.IntrospectTokenResponse
+	(*timestamppb.Timestamp)(nil),   // 2: google.protobuf.Timestamp
+}
+var file_linkflow_auth_v1_auth_proto_depIdxs = []int32{
+	2, // 0: linkflow.auth.v1.IntrospectTokenResponse.expires_at:type_name -> google.protobuf.Timestamp
+	0, // 1: linkflow.auth.v1.AuthService.IntrospectToken:input_type -> linkflow.auth.v1.IntrospectTokenRequest
+	1, // 2: linkflow.auth.v1.AuthService.IntrospectToken:output_type -> linkflow.auth.v1.IntrospectTokenResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_linkflow_auth_v1_auth_proto_init() }
+func file_linkflow_auth_v1_auth_proto_init() {
+	if File_linkflow_auth_v1_auth_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_linkflow_auth_v1_auth_proto_rawDesc), len(file_linkflow_auth_v1_auth_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_linkflow_auth_v1_auth_proto_goTypes,
+		DependencyIndexes: file_linkflow_auth_v1_auth_proto_depIdxs,
+		MessageInfos:      file_linkflow_auth_v1_auth_proto_msgTypes,
+	}.Build()
+	File_linkflow_auth_v1_auth_proto = out.File
+	file_linkflow_auth_v1_auth_proto_goTypes = nil
+	file_linkflow_auth_v1_auth_proto_depIdxs = nil
+}
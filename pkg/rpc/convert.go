@@ -0,0 +1,52 @@
+package rpc
+
+import (
+	"encoding/json"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Struct converts a value encoding to a JSON object, such as a map or the
+// settings of a workflow, to a Struct. It goes through JSON so anything the
+// REST API renders converts the same way, nil and empty objects give nil.
+func Struct(v interface{}) (*structpb.Struct, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var plain map[string]interface{}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return nil, err
+	}
+	if len(plain) == 0 {
+		return nil, nil
+	}
+	return structpb.NewStruct(plain)
+}
+
+// Map converts a Struct back to a JSON object, nil for a nil Struct
+func Map(s *structpb.Struct) map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+	return s.AsMap()
+}
+
+// Timestamp converts an optional time, nil stays nil
+func Timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// Time converts an optional timestamp, nil stays nil
+func Time(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
@@ -0,0 +1,254 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: linkflow/credential/v1/credential.proto
+
+package credentialv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ResolveCredentialRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CredentialId  string                 `protobuf:"bytes,1,opt,name=credential_id,json=credentialId,proto3" json:"credential_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveCredentialRequest) Reset() {
+	*x = ResolveCredentialRequest{}
+	mi := &file_linkflow_credential_v1_credential_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveCredentialRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveCredentialRequest) ProtoMessage() {}
+
+func (x *ResolveCredentialRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_linkflow_credential_v1_credential_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveCredentialRequest.ProtoReflect.Descriptor instead.
+func (*ResolveCredentialRequest) Descriptor() ([]byte, []int) {
+	return file_linkflow_credential_v1_credential_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ResolveCredentialRequest) GetCredentialId() string {
+	if x != nil {
+		return x.CredentialId
+	}
+	return ""
+}
+
+func (x *ResolveCredentialRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type Credential struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name   string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type   string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	UserId string                 `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	TeamId string                 `protobuf:"bytes,5,opt,name=team_id,json=teamId,proto3" json:"team_id,omitempty"`
+	// Decrypted data, e.g. an apiKey or the tokens of an OAuth2 credential
+	Data          *structpb.Struct       `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"`
+	IsActive      bool                   `protobuf:"varint,7,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Credential) Reset() {
+	*x = Credential{}
+	mi := &file_linkflow_credential_v1_credential_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Credential) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Credential) ProtoMessage() {}
+
+func (x *Credential) ProtoReflect() protoreflect.Message {
+	mi := &file_linkflow_credential_v1_credential_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Credential.ProtoReflect.Descriptor instead.
+func (*Credential) Descriptor() ([]byte, []int) {
+	return file_linkflow_credential_v1_credential_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Credential) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Credential) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Credential) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Credential) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Credential) GetTeamId() string {
+	if x != nil {
+		return x.TeamId
+	}
+	return ""
+}
+
+func (x *Credential) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Credential) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *Credential) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+var File_linkflow_credential_v1_credential_proto protoreflect.FileDescriptor
+
+const file_linkflow_credential_v1_credential_proto_rawDesc = "" +
+	"\n" +
+	"'linkflow/credential/v1/credential.proto\x12\x16linkflow.credential.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"X\n" +
+	"\x18ResolveCredentialRequest\x12#\n" +
+	"\rcredential_id\x18\x01 \x01(\tR\fcredentialId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\xfb\x01\n" +
+	"\n" +
+	"Credential\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\tR\x06userId\x12\x17\n" +
+	"\ateam_id\x18\x05 \x01(\tR\x06teamId\x12+\n" +
+	"\x04data\x18\x06 \x01(\v2\x17.google.protobuf.StructR\x04data\x12\x1b\n" +
+	"\tis_active\x18\a \x01(\bR\bisActive\x129\n" +
+	"\n" +
+	"expires_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt2~\n" +
+	"\x11CredentialService\x12i\n" +
+	"\x11ResolveCredential\x120.linkflow.credential.v1.ResolveCredentialRequest\x1a\".linkflow.credential.v1.CredentialB:Z8github.com/linkflow-go/pkg/rpc/credentialv1;credentialv1b\x06proto3"
+
+var (
+	file_linkflow_credential_v1_credential_proto_rawDescOnce sync.Once
+	file_linkflow_credential_v1_credential_proto_rawDescData []byte
+)
+
+func file_linkflow_credential_v1_credential_proto_rawDescGZIP() []byte {
+	file_linkflow_credential_v1_credential_proto_rawDescOnce.Do(func() {
+		file_linkflow_credential_v1_credential_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_linkflow_credential_v1_credential_proto_rawDesc), len(file_linkflow_credential_v1_credential_proto_rawDesc)))
+	})
+	return file_linkflow_credential_v1_credential_proto_rawDescData
+}
+
+var file_linkflow_credential_v1_credential_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_linkflow_credential_v1_credential_proto_goTypes = []any{
+	(*ResolveCredentialRequest)(nil), // 0: linkflow.credential.v1.ResolveCredentialRequest
+	(*Credential)(nil),               // 1: linkflow.credential.v1.Credential
+	(*structpb.Struct)(nil),          // 2: google.protobuf.Struct
+	(*timestamppb.Timestamp)(nil),    // 3: google.protobuf.Timestamp
+}
+var file_linkflow_credential_v1_credential_proto_depIdxs = []int32{
+	2, // 0: linkflow.credential.v1.Credential.data:type_name -> google.protobuf.Struct
+	3, // 1: linkflow.credential.v1.Credential.expires_at:type_name -> google.protobuf.Timestamp
+	0, // 2: linkflow.credential.v1.CredentialService.ResolveCredential:input_type -> linkflow.credential.v1.ResolveCredentialRequest
+	1, // 3: linkflow.credential.v1.CredentialService.ResolveCredential:output_type -> linkflow.credential.v1.Credential
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_linkflow_credential_v1_credential_proto_init() }
+func file_linkflow_credential_v1_credential_proto_init() {
+	if File_linkflow_credential_v1_credential_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_linkflow_credential_v1_credential_proto_rawDesc), len(file_linkflow_credential_v1_credential_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_linkflow_credential_v1_credential_proto_goTypes,
+		DependencyIndexes: file_linkflow_credential_v1_credential_proto_depIdxs,
+		MessageInfos:      file_linkflow_credential_v1_credential_proto_msgTypes,
+	}.Build()
+	File_linkflow_credential_v1_credential_proto = out.File
+	file_linkflow_credential_v1_credential_proto_goTypes = nil
+	file_linkflow_credential_v1_credential_proto_depIdxs = nil
+}
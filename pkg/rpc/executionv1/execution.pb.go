@@ -0,0 +1,630 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: linkflow/execution/v1/execution.proto
+
+package executionv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StartExecutionRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	WorkflowId     string                 `protobuf:"bytes,1,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Data           *structpb.Struct       `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StartExecutionRequest) Reset() {
+	*x = StartExecutionRequest{}
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartExecutionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartExecutionRequest) ProtoMessage() {}
+
+func (x *StartExecutionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartExecutionRequest.ProtoReflect.Descriptor instead.
+func (*StartExecutionRequest) Descriptor() ([]byte, []int) {
+	return file_linkflow_execution_v1_execution_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StartExecutionRequest) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+func (x *StartExecutionRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *StartExecutionRequest) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type StartExecutionResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	// False when the idempotency key matched an earlier execution
+	Created       bool `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartExecutionResponse) Reset() {
+	*x = StartExecutionResponse{}
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartExecutionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartExecutionResponse) ProtoMessage() {}
+
+func (x *StartExecutionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartExecutionResponse.ProtoReflect.Descriptor instead.
+func (*StartExecutionResponse) Descriptor() ([]byte, []int) {
+	return file_linkflow_execution_v1_execution_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StartExecutionResponse) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+func (x *StartExecutionResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+type GetExecutionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId   string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetExecutionRequest) Reset() {
+	*x = GetExecutionRequest{}
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetExecutionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetExecutionRequest) ProtoMessage() {}
+
+func (x *GetExecutionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetExecutionRequest.ProtoReflect.Descriptor instead.
+func (*GetExecutionRequest) Descriptor() ([]byte, []int) {
+	return file_linkflow_execution_v1_execution_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetExecutionRequest) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+type StopExecutionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExecutionId   string                 `protobuf:"bytes,1,opt,name=execution_id,json=executionId,proto3" json:"execution_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopExecutionRequest) Reset() {
+	*x = StopExecutionRequest{}
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopExecutionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopExecutionRequest) ProtoMessage() {}
+
+func (x *StopExecutionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopExecutionRequest.ProtoReflect.Descriptor instead.
+func (*StopExecutionRequest) Descriptor() ([]byte, []int) {
+	return file_linkflow_execution_v1_execution_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StopExecutionRequest) GetExecutionId() string {
+	if x != nil {
+		return x.ExecutionId
+	}
+	return ""
+}
+
+type StopExecutionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopExecutionResponse) Reset() {
+	*x = StopExecutionResponse{}
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopExecutionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopExecutionResponse) ProtoMessage() {}
+
+func (x *StopExecutionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopExecutionResponse.ProtoReflect.Descriptor instead.
+func (*StopExecutionResponse) Descriptor() ([]byte, []int) {
+	return file_linkflow_execution_v1_execution_proto_rawDescGZIP(), []int{4}
+}
+
+type Execution struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	WorkflowId       string                 `protobuf:"bytes,2,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	Version          int32                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	WorkflowChecksum string                 `protobuf:"bytes,4,opt,name=workflow_checksum,json=workflowChecksum,proto3" json:"workflow_checksum,omitempty"`
+	Status           string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	StartedAt        *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	// Duration in milliseconds
+	ExecutionTime  int64                  `protobuf:"varint,8,opt,name=execution_time,json=executionTime,proto3" json:"execution_time,omitempty"`
+	Data           *structpb.Struct       `protobuf:"bytes,9,opt,name=data,proto3" json:"data,omitempty"`
+	Error          string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	NodeExecutions []*NodeExecution       `protobuf:"bytes,11,rep,name=node_executions,json=nodeExecutions,proto3" json:"node_executions,omitempty"`
+	CreatedBy      string                 `protobuf:"bytes,12,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Execution) Reset() {
+	*x = Execution{}
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Execution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Execution) ProtoMessage() {}
+
+func (x *Execution) ProtoReflect() protoreflect.Message {
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Execution.ProtoReflect.Descriptor instead.
+func (*Execution) Descriptor() ([]byte, []int) {
+	return file_linkflow_execution_v1_execution_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Execution) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Execution) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+func (x *Execution) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Execution) GetWorkflowChecksum() string {
+	if x != nil {
+		return x.WorkflowChecksum
+	}
+	return ""
+}
+
+func (x *Execution) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Execution) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Execution) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *Execution) GetExecutionTime() int64 {
+	if x != nil {
+		return x.ExecutionTime
+	}
+	return 0
+}
+
+func (x *Execution) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Execution) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Execution) GetNodeExecutions() []*NodeExecution {
+	if x != nil {
+		return x.NodeExecutions
+	}
+	return nil
+}
+
+func (x *Execution) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *Execution) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type NodeExecution struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	NodeId        string                 `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	InputData     *structpb.Struct       `protobuf:"bytes,6,opt,name=input_data,json=inputData,proto3" json:"input_data,omitempty"`
+	OutputData    *structpb.Struct       `protobuf:"bytes,7,opt,name=output_data,json=outputData,proto3" json:"output_data,omitempty"`
+	Error         string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeExecution) Reset() {
+	*x = NodeExecution{}
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeExecution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeExecution) ProtoMessage() {}
+
+func (x *NodeExecution) ProtoReflect() protoreflect.Message {
+	mi := &file_linkflow_execution_v1_execution_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeExecution.ProtoReflect.Descriptor instead.
+func (*NodeExecution) Descriptor() ([]byte, []int) {
+	return file_linkflow_execution_v1_execution_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *NodeExecution) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *NodeExecution) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *NodeExecution) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *NodeExecution) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *NodeExecution) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *NodeExecution) GetInputData() *structpb.Struct {
+	if x != nil {
+		return x.InputData
+	}
+	return nil
+}
+
+func (x *NodeExecution) GetOutputData() *structpb.Struct {
+	if x != nil {
+		return x.OutputData
+	}
+	return nil
+}
+
+func (x *NodeExecution) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_linkflow_execution_v1_execution_proto protoreflect.FileDescriptor
+
+const file_linkflow_execution_v1_execution_proto_rawDesc = "" +
+	"\n" +
+	"%linkflow/execution/v1/execution.proto\x12\x15linkflow.execution.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8e\x01\n" +
+	"\x15StartExecutionRequest\x12\x1f\n" +
+	"\vworkflow_id\x18\x01 \x01(\tR\n" +
+	"workflowId\x12'\n" +
+	"\x0fidempotency_key\x18\x02 \x01(\tR\x0eidempotencyKey\x12+\n" +
+	"\x04data\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x04data\"U\n" +
+	"\x16StartExecutionResponse\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\bR\acreated\"8\n" +
+	"\x13GetExecutionRequest\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\"9\n" +
+	"\x14StopExecutionRequest\x12!\n" +
+	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\"\x17\n" +
+	"\x15StopExecutionResponse\"\xa6\x04\n" +
+	"\tExecution\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vworkflow_id\x18\x02 \x01(\tR\n" +
+	"workflowId\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x05R\aversion\x12+\n" +
+	"\x11workflow_checksum\x18\x04 \x01(\tR\x10workflowChecksum\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"started_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x12%\n" +
+	"\x0eexecution_time\x18\b \x01(\x03R\rexecutionTime\x12+\n" +
+	"\x04data\x18\t \x01(\v2\x17.google.protobuf.StructR\x04data\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\x12M\n" +
+	"\x0fnode_executions\x18\v \x03(\v2$.linkflow.execution.v1.NodeExecutionR\x0enodeExecutions\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\f \x01(\tR\tcreatedBy\x129\n" +
+	"\n" +
+	"created_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xd0\x02\n" +
+	"\rNodeExecution\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\anode_id\x18\x02 \x01(\tR\x06nodeId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"started_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x126\n" +
+	"\n" +
+	"input_data\x18\x06 \x01(\v2\x17.google.protobuf.StructR\tinputData\x128\n" +
+	"\voutput_data\x18\a \x01(\v2\x17.google.protobuf.StructR\n" +
+	"outputData\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error2\xcb\x02\n" +
+	"\x10ExecutionService\x12m\n" +
+	"\x0eStartExecution\x12,.linkflow.execution.v1.StartExecutionRequest\x1a-.linkflow.execution.v1.StartExecutionResponse\x12\\\n" +
+	"\fGetExecution\x12*.linkflow.execution.v1.GetExecutionRequest\x1a .linkflow.execution.v1.Execution\x12j\n" +
+	"\rStopExecution\x12+.linkflow.execution.v1.StopExecutionRequest\x1a,.linkflow.execution.v1.StopExecutionResponseB8Z6github.com/linkflow-go/pkg/rpc/executionv1;executionv1b\x06proto3"
+
+var (
+	file_linkflow_execution_v1_execution_proto_rawDescOnce sync.Once
+	file_linkflow_execution_v1_execution_proto_rawDescData []byte
+)
+
+func file_linkflow_execution_v1_execution_proto_rawDescGZIP() []byte {
+	file_linkflow_execution_v1_execution_proto_rawDescOnce.Do(func() {
+		file_linkflow_execution_v1_execution_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_linkflow_execution_v1_execution_proto_rawDesc), len(file_linkflow_execution_v1_execution_proto_rawDesc)))
+	})
+	return file_linkflow_execution_v1_execution_proto_rawDescData
+}
+
+var file_linkflow_execution_v1_execution_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_linkflow_execution_v1_execution_proto_goTypes = []any{
+	(*StartExecutionRequest)(nil),  // 0: linkflow.execution.v1.StartExecutionRequest
+	(*StartExecutionResponse)(nil), // 1: linkflow.execution.v1.StartExecutionResponse
+	(*GetExecutionRequest)(nil),    // 2: linkflow.execution.v1.GetExecutionRequest
+	(*StopExecutionRequest)(nil),   // 3: linkflow.execution.v1.StopExecutionRequest
+	(*StopExecutionResponse)(nil),  // 4: linkflow.execution.v1.StopExecutionResponse
+	(*Execution)(nil),              // 5: linkflow.execution.v1.Execution
+	(*NodeExecution)(nil),          // 6: linkflow.execution.v1.NodeExecution
+	(*structpb.Struct)(nil),        // 7: google.protobuf.Struct
+	(*timestamppb.Timestamp)(nil),  // 8: google.protobuf.Timestamp
+}
+var file_linkflow_execution_v1_execution_proto_depIdxs = []int32{
+	7,  // 0: linkflow.execution.v1.StartExecutionRequest.data:type_name -> google.protobuf.Struct
+	8,  // 1: linkflow.execution.v1.Execution.started_at:type_name -> google.protobuf.Timestamp
+	8,  // 2: linkflow.execution.v1.Execution.finished_at:type_name -> google.protobuf.Timestamp
+	7,  // 3: linkflow.execution.v1.Execution.data:type_name -> google.protobuf.Struct
+	6,  // 4: linkflow.execution.v1.Execution.node_executions:type_name -> linkflow.execution.v1.NodeExecution
+	8,  // 5: linkflow.execution.v1.Execution.created_at:type_name -> google.protobuf.Timestamp
+	8,  // 6: linkflow.execution.v1.NodeExecution.started_at:type_name -> google.protobuf.Timestamp
+	8,  // 7: linkflow.execution.v1.NodeExecution.finished_at:type_name -> google.protobuf.Timestamp
+	7,  // 8: linkflow.execution.v1.NodeExecution.input_data:type_name -> google.protobuf.Struct
+	7,  // 9: linkflow.execution.v1.NodeExecution.output_data:type_name -> google.protobuf.Struct
+	0,  // 10: linkflow.execution.v1.ExecutionService.StartExecution:input_type -> linkflow.execution.v1.StartExecutionRequest
+	2,  // 11: linkflow.execution.v1.ExecutionService.GetExecution:input_type -> linkflow.execution.v1.GetExecutionRequest
+	3,  // 12: linkflow.execution.v1.ExecutionService.StopExecution:input_type -> linkflow.execution.v1.StopExecutionRequest
+	1,  // 13: linkflow.execution.v1.ExecutionService.StartExecution:output_type -> linkflow.execution.v1.StartExecutionResponse
+	5,  // 14: linkflow.execution.v1.ExecutionService.GetExecution:output_type -> linkflow.execution.v1.Execution
+	4,  // 15: linkflow.execution.v1.ExecutionService.StopExecution:output_type -> linkflow.execution.v1.StopExecutionResponse
+	13, // [13:16] is the sub-list for method output_type
+	10, // [10:13] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_linkflow_execution_v1_execution_proto_init() }
+func file_linkflow_execution_v1_execution_proto_init() {
+	if File_linkflow_execution_v1_execution_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_linkflow_execution_v1_execution_proto_rawDesc), len(file_linkflow_execution_v1_execution_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_linkflow_execution_v1_execution_proto_goTypes,
+		DependencyIndexes: file_linkflow_execution_v1_execution_proto_depIdxs,
+		MessageInfos:      file_linkflow_execution_v1_execution_proto_msgTypes,
+	}.Build()
+	File_linkflow_execution_v1_execution_proto = out.File
+	file_linkflow_execution_v1_execution_proto_goTypes = nil
+	file_linkflow_execution_v1_execution_proto_depIdxs = nil
+}
@@ -0,0 +1,209 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: linkflow/execution/v1/execution.proto
+
+package executionv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ExecutionService_StartExecution_FullMethodName = "/linkflow.execution.v1.ExecutionService/StartExecution"
+	ExecutionService_GetExecution_FullMethodName   = "/linkflow.execution.v1.ExecutionService/GetExecution"
+	ExecutionService_StopExecution_FullMethodName  = "/linkflow.execution.v1.ExecutionService/StopExecution"
+)
+
+// ExecutionServiceClient is the client API for ExecutionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ExecutionService starts, inspects and stops workflow executions.
+type ExecutionServiceClient interface {
+	// StartExecution starts a workflow. With an idempotency key, a retried
+	// call returns the execution the first call started.
+	StartExecution(ctx context.Context, in *StartExecutionRequest, opts ...grpc.CallOption) (*StartExecutionResponse, error)
+	// GetExecution returns an execution and the executions of its nodes
+	GetExecution(ctx context.Context, in *GetExecutionRequest, opts ...grpc.CallOption) (*Execution, error)
+	// StopExecution cancels a running execution
+	StopExecution(ctx context.Context, in *StopExecutionRequest, opts ...grpc.CallOption) (*StopExecutionResponse, error)
+}
+
+type executionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExecutionServiceClient(cc grpc.ClientConnInterface) ExecutionServiceClient {
+	return &executionServiceClient{cc}
+}
+
+func (c *executionServiceClient) StartExecution(ctx context.Context, in *StartExecutionRequest, opts ...grpc.CallOption) (*StartExecutionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartExecutionResponse)
+	err := c.cc.Invoke(ctx, ExecutionService_StartExecution_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *executionServiceClient) GetExecution(ctx context.Context, in *GetExecutionRequest, opts ...grpc.CallOption) (*Execution, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Execution)
+	err := c.cc.Invoke(ctx, ExecutionService_GetExecution_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *executionServiceClient) StopExecution(ctx context.Context, in *StopExecutionRequest, opts ...grpc.CallOption) (*StopExecutionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopExecutionResponse)
+	err := c.cc.Invoke(ctx, ExecutionService_StopExecution_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExecutionServiceServer is the server API for ExecutionService service.
+// All implementations must embed UnimplementedExecutionServiceServer
+// for forward compatibility.
+//
+// ExecutionService starts, inspects and stops workflow executions.
+type ExecutionServiceServer interface {
+	// StartExecution starts a workflow. With an idempotency key, a retried
+	// call returns the execution the first call started.
+	StartExecution(context.Context, *StartExecutionRequest) (*StartExecutionResponse, error)
+	// GetExecution returns an execution and the executions of its nodes
+	GetExecution(context.Context, *GetExecutionRequest) (*Execution, error)
+	// StopExecution cancels a running execution
+	StopExecution(context.Context, *StopExecutionRequest) (*StopExecutionResponse, error)
+	mustEmbedUnimplementedExecutionServiceServer()
+}
+
+// UnimplementedExecutionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedExecutionServiceServer struct{}
+
+func (UnimplementedExecutionServiceServer) StartExecution(context.Context, *StartExecutionRequest) (*StartExecutionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartExecution not implemented")
+}
+func (UnimplementedExecutionServiceServer) GetExecution(context.Context, *GetExecutionRequest) (*Execution, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetExecution not implemented")
+}
+func (UnimplementedExecutionServiceServer) StopExecution(context.Context, *StopExecutionRequest) (*StopExecutionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopExecution not implemented")
+}
+func (UnimplementedExecutionServiceServer) mustEmbedUnimplementedExecutionServiceServer() {}
+func (UnimplementedExecutionServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeExecutionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExecutionServiceServer will
+// result in compilation errors.
+type UnsafeExecutionServiceServer interface {
+	mustEmbedUnimplementedExecutionServiceServer()
+}
+
+func RegisterExecutionServiceServer(s grpc.ServiceRegistrar, srv ExecutionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedExecutionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ExecutionService_ServiceDesc, srv)
+}
+
+func _ExecutionService_StartExecution_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartExecutionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExecutionServiceServer).StartExecution(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExecutionService_StartExecution_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExecutionServiceServer).StartExecution(ctx, req.(*StartExecutionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExecutionService_GetExecution_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetExecutionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExecutionServiceServer).GetExecution(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExecutionService_GetExecution_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExecutionServiceServer).GetExecution(ctx, req.(*GetExecutionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExecutionService_StopExecution_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopExecutionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExecutionServiceServer).StopExecution(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExecutionService_StopExecution_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExecutionServiceServer).StopExecution(ctx, req.(*StopExecutionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ExecutionService_ServiceDesc is the grpc.ServiceDesc for ExecutionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ExecutionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "linkflow.execution.v1.ExecutionService",
+	HandlerType: (*ExecutionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartExecution",
+			Handler:    _ExecutionService_StartExecution_Handler,
+		},
+		{
+			MethodName: "GetExecution",
+			Handler:    _ExecutionService_GetExecution_Handler,
+		},
+		{
+			MethodName: "StopExecution",
+			Handler:    _ExecutionService_StopExecution_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "linkflow/execution/v1/execution.proto",
+}
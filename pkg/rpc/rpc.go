@@ -0,0 +1,163 @@
+// Package rpc serves and dials the gRPC APIs the services call each other
+// through. The APIs are defined in proto/ and generated into the v1
+// packages below this one with make proto.
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"strings"
+
+	apperrors "github.com/linkflow-go/pkg/errors"
+	"github.com/linkflow-go/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Service names, the keys of rpc.services in the config
+const (
+	ServiceAuth       = "auth"
+	ServiceWorkflow   = "workflow"
+	ServiceExecution  = "execution"
+	ServiceCredential = "credential"
+)
+
+// serviceConfig spreads the calls of a connection over every address its
+// host name resolves to, one per pod behind a headless Service, skipping
+// the instances failing their health checks
+const serviceConfig = `{
+	"loadBalancingConfig": [{"round_robin": {}}],
+	"healthCheckConfig": {"serviceName": ""}
+}`
+
+// NewServer returns a gRPC server refusing calls without token, an empty
+// token refuses every call. Handlers return coded errors, they reach the
+// client as statuses it turns back into the same codes. The server reports
+// its health to the clients balancing their calls.
+func NewServer(token string, log logger.Logger) *grpc.Server {
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		recoveryInterceptor(log),
+		authInterceptor(token),
+		statusInterceptor(),
+	))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	return srv
+}
+
+// Serve accepts calls to srv on port in the background until it is stopped
+func Serve(srv *grpc.Server, port int, log logger.Logger) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on rpc port: %w", err)
+	}
+
+	log.Info("Starting gRPC server", "port", port)
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			log.Error("gRPC server stopped", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Stop waits for in-flight calls to finish, or aborts them once ctx is done
+func Stop(ctx context.Context, srv *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		srv.Stop()
+	}
+}
+
+// Dial returns a connection to the API of a service at addr, host:port.
+// Calls carry token and fail with the coded errors of the service, and are
+// balanced over the healthy instances of the service. Connections are not
+// encrypted, the APIs must only be reachable on the private network of the
+// services.
+func Dial(addr, token string) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithChainUnaryInterceptor(tokenInterceptor(token), errorInterceptor()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// authInterceptor requires the shared token on every call
+func authInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if token == "" {
+			return nil, status.Error(codes.Unauthenticated, "rpc token not configured")
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "missing rpc token")
+		}
+
+		provided := strings.TrimPrefix(values[0], "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			return nil, status.Error(codes.PermissionDenied, "invalid rpc token")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// statusInterceptor turns the coded errors of handlers into statuses
+func statusInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, apperrors.ToGRPC(err)
+	}
+}
+
+// recoveryInterceptor turns handler panics into Internal errors
+func recoveryInterceptor(log logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("gRPC call panicked", "method", info.FullMethod, "panic", r)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// tokenInterceptor attaches the shared token to every call
+func tokenInterceptor(token string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// errorInterceptor turns statuses back into coded errors
+func errorInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			return apperrors.FromGRPC(err)
+		}
+		return nil
+	}
+}
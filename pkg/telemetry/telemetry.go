@@ -5,10 +5,13 @@ import (
 	"fmt"
 
 	"github.com/gin-gonic/gin"
+	"github.com/linkflow-go/pkg/config"
+	"github.com/linkflow-go/pkg/logger"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -23,24 +26,60 @@ type Telemetry struct {
 
 type Config struct {
 	Enabled      bool
+	Exporter     string // otlp (default) or jaeger
 	JaegerURL    string
+	OTLPEndpoint string
+	OTLPInsecure bool
 	ServiceName  string
 	SamplingRate float64
 }
 
+// FromConfig builds the telemetry configuration of a service. serviceName
+// is used when the config does not name the service.
+func FromConfig(cfg config.TelemetryConfig, serviceName string) Config {
+	if cfg.ServiceName != "" {
+		serviceName = cfg.ServiceName
+	}
+
+	return Config{
+		Enabled:      cfg.Enabled,
+		Exporter:     cfg.Exporter,
+		JaegerURL:    cfg.JaegerURL,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		OTLPInsecure: cfg.OTLPInsecure,
+		ServiceName:  serviceName,
+		SamplingRate: cfg.SamplingRate,
+	}
+}
+
+// Setup initializes tracing for a service. Exporter failures are logged and
+// fall back to a no-op tracer so tracing never blocks startup.
+func Setup(cfg config.TelemetryConfig, serviceName string, log logger.Logger) *Telemetry {
+	t, err := New(FromConfig(cfg, serviceName))
+	if err != nil {
+		log.Warn("Tracing disabled", "service", serviceName, "error", err)
+		return NewNop()
+	}
+	return t
+}
+
 func New(cfg Config) (*Telemetry, error) {
+	// Always propagate W3C trace context so traces stay connected through
+	// services that do not export spans themselves
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
 	if !cfg.Enabled {
 		return &Telemetry{
 			tracer: otel.Tracer("noop"),
 		}, nil
 	}
 
-	// Create Jaeger exporter
-	exporter, err := jaeger.New(
-		jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(cfg.JaegerURL)),
-	)
+	exporter, err := newExporter(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Jaeger exporter: %w", err)
+		return nil, err
 	}
 
 	// Create resource
@@ -66,10 +105,6 @@ func New(cfg Config) (*Telemetry, error) {
 
 	// Set global provider
 	otel.SetTracerProvider(provider)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
 
 	return &Telemetry{
 		tracer:   otel.Tracer(cfg.ServiceName),
@@ -77,6 +112,36 @@ func New(cfg Config) (*Telemetry, error) {
 	}, nil
 }
 
+// newExporter creates the span exporter selected by the configuration
+func newExporter(cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "jaeger":
+		exporter, err := jaeger.New(
+			jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(cfg.JaegerURL)),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Jaeger exporter: %w", err)
+		}
+		return exporter, nil
+	case "", "otlp":
+		opts := []otlptracehttp.Option{}
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.OTLPEndpoint))
+		}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err := otlptracehttp.New(context.Background(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter: %s", cfg.Exporter)
+	}
+}
+
 func (t *Telemetry) Close() error {
 	if t.provider != nil {
 		return t.provider.Shutdown(context.Background())
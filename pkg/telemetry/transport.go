@@ -0,0 +1,70 @@
+package telemetry
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/linkflow-go/pkg/logger"
+	"github.com/linkflow-go/pkg/tenant"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport traces outgoing HTTP calls and propagates the trace context,
+// correlation ID and tenant to the called service
+type Transport struct {
+	base   http.RoundTripper
+	tracer trace.Tracer
+}
+
+// NewTransport wraps base, http.DefaultTransport when nil
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{
+		base:   base,
+		tracer: otel.Tracer("linkflow/http"),
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := t.tracer.Start(req.Context(), fmt.Sprintf("HTTP %s", req.Method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPMethodKey.String(req.Method),
+			semconv.HTTPURLKey.String(req.URL.String()),
+			semconv.NetPeerNameKey.String(req.URL.Hostname()),
+		),
+	)
+	defer span.End()
+
+	// Requests must not be modified by a RoundTripper, inject into a clone
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if id := logger.CorrelationID(ctx); id != "" && req.Header.Get(logger.CorrelationIDHeader) == "" {
+		req.Header.Set(logger.CorrelationIDHeader, id)
+	}
+	if id := tenant.FromContext(ctx); id != "" {
+		req.Header.Set(tenant.InternalHeader, id)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	}
+
+	return resp, nil
+}
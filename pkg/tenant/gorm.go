@@ -0,0 +1,143 @@
+package tenant
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// fieldName is the field marking the models owned by a tenant
+const fieldName = "TenantID"
+
+// Plugin confines the statements on models with a TenantID field to the
+// tenant of their context. Queries, updates and deletes only see the rows
+// of the tenant and created rows are assigned to it. Statements whose
+// context has no tenant and raw SQL are left as they are.
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "tenant"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:create", assign); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", restrict); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:row", restrict); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", restrictUpdate); err != nil {
+		return err
+	}
+	return callbacks.Delete().Before("gorm:delete").Register("tenant:delete", restrictDelete)
+}
+
+// scoped returns the tenant field of the statement model and the tenant of
+// its context, a nil field when the statement is not scoped
+func scoped(db *gorm.DB) (*schema.Field, string) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || stmt.SQL.Len() > 0 {
+		return nil, ""
+	}
+	id := FromContext(stmt.Context)
+	if id == "" {
+		return nil, ""
+	}
+	return stmt.Schema.LookUpField(fieldName), id
+}
+
+func condition(field *schema.Field, id string) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: id}
+}
+
+func restrict(db *gorm.DB) {
+	field, id := scoped(db)
+	if field == nil {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{condition(field, id)}})
+}
+
+// restrictUpdate scopes updates, a saved row keeps the tenant it was
+// read from
+func restrictUpdate(db *gorm.DB) {
+	field, id := scoped(db)
+	if field == nil || !targeted(db) {
+		return
+	}
+	setTenants(db, field, id)
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{condition(field, id)}})
+}
+
+func restrictDelete(db *gorm.DB) {
+	field, id := scoped(db)
+	if field == nil || !targeted(db) {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{condition(field, id)}})
+}
+
+// targeted reports whether a write has a condition. Writes with none are
+// left for gorm to refuse rather than turned into writes to every row of
+// the tenant.
+func targeted(db *gorm.DB) bool {
+	_, ok := db.Statement.Clauses["WHERE"]
+	return ok || db.AllowGlobalUpdate || hasPrimaryKey(db.Statement)
+}
+
+// assign sets the tenant of the created rows. An upsert only overwrites a
+// conflicting row of the same tenant.
+func assign(db *gorm.DB) {
+	field, id := scoped(db)
+	if field == nil {
+		return
+	}
+	setTenants(db, field, id)
+
+	stmt := db.Statement
+	if c, ok := stmt.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && !onConflict.DoNothing {
+			onConflict.Where.Exprs = append(onConflict.Where.Exprs, condition(field, id))
+			stmt.AddClause(onConflict)
+		}
+	}
+}
+
+// setTenants sets the tenant field of the values of the statement
+func setTenants(db *gorm.DB, field *schema.Field, id string) {
+	rv := reflect.Indirect(db.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			setTenant(db, field, reflect.Indirect(rv.Index(i)), id)
+		}
+	case reflect.Struct:
+		setTenant(db, field, rv, id)
+	}
+}
+
+func setTenant(db *gorm.DB, field *schema.Field, rv reflect.Value, id string) {
+	if rv.Kind() != reflect.Struct || !rv.CanAddr() {
+		return
+	}
+	if err := field.Set(db.Statement.Context, rv, id); err != nil {
+		db.AddError(err)
+	}
+}
+
+// hasPrimaryKey reports whether the statement targets a row by the primary
+// key of its model, which gorm turns into the condition of the statement
+func hasPrimaryKey(stmt *gorm.Statement) bool {
+	rv := reflect.Indirect(stmt.ReflectValue)
+	if rv.Kind() != reflect.Struct || stmt.Schema.PrioritizedPrimaryField == nil {
+		return false
+	}
+	_, zero := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, rv)
+	return !zero
+}
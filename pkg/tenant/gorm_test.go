@@ -0,0 +1,199 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+type record struct {
+	ID       string `gorm:"primaryKey"`
+	TenantID string
+	Name     string
+}
+
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	// Every connection to :memory: opens a database of its own
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.Use(Plugin{}); err != nil {
+		t.Fatalf("plugin: %v", err)
+	}
+	if err := db.AutoMigrate(&record{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// seed creates a row of tenant a and returns the contexts of a and of
+// another tenant b
+func seed(t *testing.T, db *gorm.DB) (context.Context, context.Context) {
+	t.Helper()
+	a := WithTenant(context.Background(), "a")
+	b := WithTenant(context.Background(), "b")
+	if err := db.WithContext(a).Create(&record{ID: "r1", Name: "original"}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return a, b
+}
+
+// stored returns the row as it is in the database, read without a tenant
+func stored(t *testing.T, db *gorm.DB) record {
+	t.Helper()
+	var r record
+	if err := db.First(&r, "id = ?", "r1").Error; err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return r
+}
+
+func TestCreateAssignsTenant(t *testing.T) {
+	db := openDB(t)
+	ctx := WithTenant(context.Background(), "a")
+
+	r := record{ID: "r1", TenantID: "b"}
+	if err := db.WithContext(ctx).Create(&r).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if r.TenantID != "a" {
+		t.Errorf("created value has tenant %q, want a", r.TenantID)
+	}
+	if got := stored(t, db).TenantID; got != "a" {
+		t.Errorf("stored row has tenant %q, want a", got)
+	}
+
+	batch := []record{{ID: "r2"}, {ID: "r3", TenantID: "b"}}
+	if err := db.WithContext(ctx).Create(&batch).Error; err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	var count int64
+	db.Model(&record{}).Where("tenant_id = ?", "a").Count(&count)
+	if count != 3 {
+		t.Errorf("tenant a has %d rows, want 3", count)
+	}
+}
+
+func TestFindMissesOtherTenant(t *testing.T) {
+	db := openDB(t)
+	a, b := seed(t, db)
+
+	var r record
+	err := db.WithContext(b).First(&r, "id = ?", "r1").Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("First of another tenant returned %v, want not found", err)
+	}
+
+	var rows []record
+	if err := db.WithContext(b).Find(&rows).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("Find of another tenant returned %d rows", len(rows))
+	}
+
+	var count int64
+	db.WithContext(b).Model(&record{}).Count(&count)
+	if count != 0 {
+		t.Errorf("Count of another tenant returned %d", count)
+	}
+
+	if err := db.WithContext(a).First(&r, "id = ?", "r1").Error; err != nil {
+		t.Errorf("First of the owning tenant: %v", err)
+	}
+}
+
+func TestUpdateMissesOtherTenant(t *testing.T) {
+	db := openDB(t)
+	a, b := seed(t, db)
+
+	res := db.WithContext(b).Model(&record{}).Where("id = ?", "r1").Update("name", "changed")
+	if res.Error != nil || res.RowsAffected != 0 {
+		t.Errorf("Update of another tenant affected %d rows (%v)", res.RowsAffected, res.Error)
+	}
+	res = db.WithContext(b).Model(&record{ID: "r1"}).Updates(map[string]interface{}{"name": "changed"})
+	if res.Error != nil || res.RowsAffected != 0 {
+		t.Errorf("Updates by key of another tenant affected %d rows (%v)", res.RowsAffected, res.Error)
+	}
+	if got := stored(t, db); got.Name != "original" || got.TenantID != "a" {
+		t.Errorf("row changed to %+v", got)
+	}
+
+	res = db.WithContext(a).Model(&record{}).Where("id = ?", "r1").Update("name", "changed")
+	if res.Error != nil || res.RowsAffected != 1 {
+		t.Errorf("Update of the owning tenant affected %d rows (%v)", res.RowsAffected, res.Error)
+	}
+}
+
+func TestSaveMissesOtherTenant(t *testing.T) {
+	db := openDB(t)
+	_, b := seed(t, db)
+
+	// Save falls back to an upsert when the update misses, which must not
+	// take over the row either
+	db.WithContext(b).Save(&record{ID: "r1", TenantID: "a", Name: "changed"})
+	if got := stored(t, db); got.Name != "original" || got.TenantID != "a" {
+		t.Errorf("row changed to %+v", got)
+	}
+}
+
+func TestUpsertMissesOtherTenant(t *testing.T) {
+	db := openDB(t)
+	_, b := seed(t, db)
+
+	db.WithContext(b).Clauses(clause.OnConflict{UpdateAll: true}).Create(&record{ID: "r1", Name: "changed"})
+	db.WithContext(b).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "tenant_id"}),
+	}).Create(&record{ID: "r1", Name: "changed"})
+	if got := stored(t, db); got.Name != "original" || got.TenantID != "a" {
+		t.Errorf("row changed to %+v", got)
+	}
+}
+
+func TestDeleteMissesOtherTenant(t *testing.T) {
+	db := openDB(t)
+	a, b := seed(t, db)
+
+	res := db.WithContext(b).Delete(&record{ID: "r1"})
+	if res.Error != nil || res.RowsAffected != 0 {
+		t.Errorf("Delete by key of another tenant affected %d rows (%v)", res.RowsAffected, res.Error)
+	}
+	res = db.WithContext(b).Where("id = ?", "r1").Delete(&record{})
+	if res.Error != nil || res.RowsAffected != 0 {
+		t.Errorf("Delete of another tenant affected %d rows (%v)", res.RowsAffected, res.Error)
+	}
+	stored(t, db)
+
+	res = db.WithContext(a).Delete(&record{ID: "r1"})
+	if res.Error != nil || res.RowsAffected != 1 {
+		t.Errorf("Delete of the owning tenant affected %d rows (%v)", res.RowsAffected, res.Error)
+	}
+}
+
+func TestUnscopedContextIsLeftAlone(t *testing.T) {
+	db := openDB(t)
+	seed(t, db)
+
+	var rows []record
+	if err := db.Find(&rows).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Errorf("Find without a tenant returned %d rows, want 1", len(rows))
+	}
+}
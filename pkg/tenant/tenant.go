@@ -0,0 +1,47 @@
+// Package tenant isolates the data of the tenants sharing a deployment. The
+// tenant of a request travels in its context, and the database plugin of
+// this package confines every query on a model with a TenantID field to the
+// rows of that tenant.
+package tenant
+
+import "context"
+
+const (
+	// DefaultTenant owns the rows created before tenancy and the requests
+	// naming no tenant
+	DefaultTenant = "default"
+	// InternalHeader names the tenant of calls between services carrying no
+	// token. The gateway sets it from the claims of the caller and strips it
+	// from the requests of clients, which cannot pick their tenant.
+	InternalHeader = "X-Internal-Tenant-ID"
+	// maxIDLength bounds the tenant IDs taken from requests
+	maxIDLength = 64
+)
+
+type tenantKey struct{}
+
+// WithTenant returns ctx scoped to a tenant
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext returns the tenant of ctx, empty for the background work of
+// the services, which is not scoped to a tenant
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(tenantKey{}).(string)
+	return id
+}
+
+// Valid reports whether id can name a tenant. Colons are refused, they
+// separate the tenant from the rest of its Redis keys.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e || r == ':' {
+			return false
+		}
+	}
+	return true
+}
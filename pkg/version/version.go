@@ -0,0 +1,11 @@
+// Package version holds the build information of a service binary, set by
+// the linker:
+//
+//	go build -ldflags "-X github.com/linkflow-go/pkg/version.Version=v1.4.0"
+package version
+
+var (
+	Version    = "dev"
+	CommitHash = "unknown"
+	BuildTime  = "unknown"
+)
@@ -215,6 +215,7 @@ DROP SCHEMA IF EXISTS search CASCADE;
 DROP SCHEMA IF EXISTS storage CASCADE;
 DROP SCHEMA IF EXISTS billing CASCADE;
 DROP SCHEMA IF EXISTS template CASCADE;
+DROP TABLE IF EXISTS public.event_outbox CASCADE;
 DROP TABLE IF EXISTS schema_migrations CASCADE;
 EOF
 
@@ -258,6 +259,7 @@ DROP SCHEMA IF EXISTS search CASCADE;
 DROP SCHEMA IF EXISTS storage CASCADE;
 DROP SCHEMA IF EXISTS billing CASCADE;
 DROP SCHEMA IF EXISTS template CASCADE;
+DROP TABLE IF EXISTS public.event_outbox CASCADE;
 DROP TABLE IF EXISTS schema_migrations CASCADE;
 EOF
 